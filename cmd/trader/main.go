@@ -2,29 +2,48 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
 	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
+	"unicode"
 
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shopspring/decimal"
 
+	"github.com/crypto-trading/trading/internal/admin"
+	"github.com/crypto-trading/trading/internal/backtest"
+	"github.com/crypto-trading/trading/internal/compliance"
 	"github.com/crypto-trading/trading/internal/config"
 	"github.com/crypto-trading/trading/internal/costmodel"
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/eventbus"
 	"github.com/crypto-trading/trading/internal/execution"
 	"github.com/crypto-trading/trading/internal/gateway"
+	"github.com/crypto-trading/trading/internal/gateway/binance"
+	"github.com/crypto-trading/trading/internal/gateway/bybit"
 	"github.com/crypto-trading/trading/internal/gateway/dryrun"
+	"github.com/crypto-trading/trading/internal/gateway/fix"
+	"github.com/crypto-trading/trading/internal/gateway/grpcplugin"
 	"github.com/crypto-trading/trading/internal/gateway/kcex"
+	"github.com/crypto-trading/trading/internal/gateway/metered"
 	"github.com/crypto-trading/trading/internal/gateway/nobitex"
+	"github.com/crypto-trading/trading/internal/gateway/okx"
 	"github.com/crypto-trading/trading/internal/gateway/simulated"
 	"github.com/crypto-trading/trading/internal/gateway/wallex"
 	"github.com/crypto-trading/trading/internal/marketdata"
@@ -34,11 +53,27 @@ import (
 	"github.com/crypto-trading/trading/internal/portfolio"
 	"github.com/crypto-trading/trading/internal/risk"
 	"github.com/crypto-trading/trading/internal/strategy"
+	"github.com/crypto-trading/trading/internal/strategy/external"
 )
 
 func main() {
 	configPath := flag.String("config", "configs/config.yaml", "Path to configuration file")
 	confirmLive := flag.Bool("confirm-live", false, "Confirm live trading mode")
+	importSince := flag.String("import-since", "", "Import account history from this date (YYYY-MM-DD) and exit")
+	importUntil := flag.String("import-until", "", "End date (exclusive) for -import-since; defaults to now")
+	compareBaseline := flag.String("compare-baseline", "", "Baseline date range FROM,TO (YYYY-MM-DD, TO exclusive) for a latency and slippage regression report")
+	compareCandidate := flag.String("compare-candidate", "", "Candidate date range FROM,TO to compare against -compare-baseline; prints the report and exits")
+	timeline := flag.String("timeline", "", "Print the post-incident timeline for FROM,TO (RFC 3339 times or YYYY-MM-DD dates, TO exclusive) and exit")
+	mode := flag.String("mode", "", "Trading mode overriding system.trading_mode: live, dry_run, backtest or replay")
+	download := flag.String("download", "", "Download historical klines, trades and funding for FROM,TO (RFC 3339 times or YYYY-MM-DD dates, TO exclusive) into the cold store and exit")
+	downloadInterval := flag.Duration("download-interval", time.Minute, "Kline interval for -download")
+	downloadKinds := flag.String("download-kinds", "klines,trades,funding", "Comma-separated kinds of history for -download")
+	ingest := flag.String("ingest", "", "Ingest a third-party history dataset (a .csv or .zip file, or a directory of them) into the cold store and exit")
+	ingestFormat := flag.String("ingest-format", backtest.FormatBinance, "Dataset format for -ingest: binance or generic")
+	ingestKind := flag.String("ingest-kind", string(backtest.IngestKlines), "Kind of history in the -ingest dataset: klines, trades or funding")
+	ingestVenue := flag.String("ingest-venue", "", "Venue the -ingest rows are stored under")
+	ingestSymbol := flag.String("ingest-symbol", "", "Internal symbol the -ingest rows are stored under")
+	ingestInterval := flag.Duration("ingest-interval", time.Minute, "Kline interval of the -ingest dataset")
 	flag.Parse()
 
 	logger := initLogger("INFO")
@@ -48,12 +83,46 @@ func main() {
 		logger.Error("failed to load configuration", "error", err)
 		os.Exit(1)
 	}
+	if *mode != "" {
+		if err := cfg.SetTradingMode(*mode); err != nil {
+			logger.Error("invalid -mode", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	logger = initLogger(cfg.System.LogLevel)
+	build := monitor.ReadBuildInfo()
 	logger.Info("configuration loaded",
 		"instance_id", cfg.System.InstanceID,
 		"trading_mode", cfg.System.TradingMode,
+		"version", build.Version,
+		"commit", build.ShortCommit(),
+		"config_hash", cfg.Hash(),
 	)
+	if err := domain.SetPriceScales(cfg.Strategies.PriceScaleMap()); err != nil {
+		logger.Error("invalid price scales", "error", err)
+		os.Exit(1)
+	}
+
+	if *compareBaseline != "" || *compareCandidate != "" {
+		regressed, err := runRegressionReport(cfg, *compareBaseline, *compareCandidate, os.Stdout, logger)
+		if err != nil {
+			logger.Error("regression report failed", "error", err)
+			os.Exit(1)
+		}
+		if regressed {
+			os.Exit(2)
+		}
+		return
+	}
+
+	if *timeline != "" {
+		if err := runTimelineReport(cfg, *timeline, os.Stdout, logger); err != nil {
+			logger.Error("timeline report failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	tradingMode := domain.TradingMode(cfg.System.TradingMode)
 	if tradingMode == domain.TradingModeLive {
@@ -66,7 +135,18 @@ func main() {
 		logger.Info("running in mode", "mode", cfg.System.TradingMode)
 	}
 
-	configureRuntime(cfg.Runtime, logger)
+	memLimit := configureRuntime(cfg.Runtime, logger)
+
+	if err := applySymbolMaps(cfg); err != nil {
+		logger.Error("invalid symbol configuration", "error", err)
+		os.Exit(1)
+	}
+
+	tradingLoc, err := time.LoadLocation(cfg.System.Timezone)
+	if err != nil {
+		logger.Error("invalid system timezone", "timezone", cfg.System.Timezone, "error", err)
+		os.Exit(1)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -76,7 +156,6 @@ func main() {
 
 	reg := prometheus.DefaultRegisterer
 	metrics := monitor.NewMetrics(reg)
-	_ = metrics
 
 	tracerShutdown, err := monitor.InitTracer(cfg.System.InstanceID, logger)
 	if err != nil {
@@ -109,6 +188,16 @@ func main() {
 
 	asyncWriter := persistence.NewAsyncWriter(sqliteStore, pgStore, 10000, logger)
 	asyncWriter.Run()
+	alertMgr.SetObserver(func(a monitor.Alert) {
+		asyncWriter.Write(persistence.WriteRequest{Type: persistence.WriteTypeAlert, Payload: &persistence.AlertRecord{
+			Level:     string(a.Level),
+			Name:      a.Name,
+			Condition: a.Condition,
+			Message:   a.Message,
+			FiredAt:   a.FiredAt,
+		}})
+	})
+	recordStartupConfig(sqliteStore, cfg, logger)
 
 	mdService := marketdata.NewService(
 		bus,
@@ -116,8 +205,53 @@ func main() {
 		cfg.Risk.DataFreshness.BlockDuration(),
 		logger,
 	)
+	configureFreshness(mdService, cfg.Risk.DataFreshness)
+
+	if *download != "" {
+		if err := runDownload(ctx, cfg, mdService, pgStore, *download, *downloadInterval, *downloadKinds, tradingLoc, logger); err != nil {
+			logger.Error("market history download failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *ingest != "" {
+		req := backtest.IngestRequest{
+			Path:     *ingest,
+			Format:   *ingestFormat,
+			Kind:     backtest.IngestKind(*ingestKind),
+			Venue:    *ingestVenue,
+			Symbol:   *ingestSymbol,
+			Interval: *ingestInterval,
+		}
+		if err := runIngest(ctx, pgStore, req, logger); err != nil {
+			logger.Error("market history ingest failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	gateways := buildGateways(cfg, mdService, tradingMode, logger)
+	if *importSince != "" {
+		if err := runAccountImport(ctx, cfg, mdService, sqliteStore, *importSince, *importUntil, tradingLoc, logger); err != nil {
+			logger.Error("account history import failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	metricsListener, err := listenMetrics(cfg.Monitoring.Metrics)
+	if err != nil {
+		logger.Error("failed to start metrics server", "addr", cfg.Monitoring.Metrics.Addr, "error", err)
+		os.Exit(1)
+	}
+	metricsPassword := os.Getenv("METRICS_PASSWORD")
+	if cfg.Monitoring.Metrics.BasicAuthUser != "" && metricsPassword == "" {
+		logger.Error("monitoring.metrics.basic_auth_user is set but METRICS_PASSWORD is not")
+		os.Exit(1)
+	}
+
+	latency := gateway.NewLatencyTracker(cfg.Monitoring.Latency.WindowSize, cfg.Monitoring.Latency.Window())
+	gateways, accountGateways := buildGateways(cfg, mdService, tradingMode, metrics, latency, logger)
 
 	costSvc := costmodel.NewService(
 		gateways,
@@ -125,15 +259,28 @@ func main() {
 		cfg.CostModel.FundingRateLookbackIntervals,
 		logger,
 	)
+	costSvc.SetTransferCostBps(decimal.NewFromInt(int64(cfg.CostModel.TransferCostAmortizationBps)))
 
+	// A backtest or replay tripping its kill switch must not halt live
+	// trading.
+	killSwitchPath := "data/killswitch.json"
+	if tradingMode == domain.TradingModeBacktest || tradingMode == domain.TradingModeReplay {
+		killSwitchPath = "data/killswitch_" + string(tradingMode) + ".json"
+	}
 	riskMgr := risk.NewManager(
 		&cfg.Risk,
 		mdService,
-		"data/killswitch.json",
+		killSwitchPath,
 		logger,
 	)
 
 	orderMgr := order.NewManager(gateways, bus, logger)
+	orderMgr.SetArchive(sqliteStore, cfg.Persistence.MaxOrdersInMemory)
+	// A strategy routed to a sub-account whose gateway failed to build has
+	// its orders fail rather than land on the default account.
+	orderMgr.SetAccounts(accountGateways, strategyAccountRoute(cfg.Venues))
+	instruments := domain.NewInstrumentRegistry()
+	orderMgr.SetInstruments(instruments)
 
 	execEngine := execution.NewEngine(
 		orderMgr,
@@ -145,9 +292,97 @@ func main() {
 		logger,
 	)
 
+	execEngine.SetFeeSource(costSvc.FeeTier)
+	execEngine.SetMinAtomicity(domain.StrategyTriArb, decimal.NewFromFloat(cfg.Strategies.TriangularArb.MinAtomicity))
+	execEngine.SetMinAtomicity(domain.StrategyBasisArb, decimal.NewFromFloat(cfg.Strategies.BasisArb.MinAtomicity))
+	execEngine.SetMinAtomicity(domain.StrategyCrossVenueArb, decimal.NewFromFloat(cfg.Strategies.CrossVenueArb.MinAtomicity))
+	execEngine.SetCrossVenueArbFillTimeout(cfg.Strategies.CrossVenueArb.FillTimeout())
+	// Tier names were checked when the config was loaded.
+	triTimeouts, _ := cfg.Strategies.AssetFillTimeouts(cfg.Strategies.TriangularArb.TierFillTimeoutsMs)
+	execEngine.SetAssetFillTimeouts(domain.StrategyTriArb, triTimeouts)
+	basisTimeouts, _ := cfg.Strategies.AssetFillTimeouts(cfg.Strategies.BasisArb.TierFillTimeoutsMs)
+	execEngine.SetAssetFillTimeouts(domain.StrategyBasisArb, basisTimeouts)
+	if passive := cfg.Strategies.BasisArb.PassiveEntry; passive.Enabled {
+		execEngine.SetPassiveBasisEntry(execution.PassiveEntryConfig{
+			MinNotional: decimal.NewFromFloat(passive.MinNotionalUSDT),
+			Refresh:     passive.Refresh(),
+			Timeout:     passive.Timeout(),
+		}, mdService.GetOrderBook)
+		if passive.RebateMinFillProbability > 0 {
+			execEngine.SetRebatePassiveEntry(decimal.NewFromFloat(passive.RebateMinFillProbability), costSvc)
+		}
+	}
+	if ft := cfg.Strategies.BasisArb.FundingTiming; ft.Delay || ft.Accelerate {
+		execEngine.SetFundingTiming(domain.StrategyBasisArb, execution.FundingTimingConfig{
+			Window:     ft.Window(),
+			Settle:     ft.Settle(),
+			Delay:      ft.Delay,
+			Accelerate: ft.Accelerate,
+		}, mdService.GetFundingRate)
+	}
+	if lc := cfg.Strategies.LatencyCompensation; lc.Enabled {
+		execEngine.SetLatencyCompensation(execution.LatencyCompensationConfig{
+			MaxBps:     decimal.NewFromFloat(lc.MaxBps),
+			Samples:    lc.Samples,
+			MinSamples: lc.MinSamples,
+		}, mdService.GetOrderBook)
+	}
+
+	execEngine.SetRateLimitSource(func(ctx context.Context, venue string) ([]domain.RateLimitStatus, error) {
+		gw, ok := gateways[venue]
+		if !ok {
+			return nil, nil
+		}
+		return gw.GetRateLimitStatus(ctx)
+	})
+
+	var webhooks *monitor.WebhookPublisher
+	if wh := cfg.Monitoring.Webhooks; wh.Enabled {
+		if secret := os.Getenv("WEBHOOK_SECRET"); secret == "" {
+			logger.Error("webhooks disabled: WEBHOOK_SECRET not set")
+		} else {
+			webhooks = monitor.NewWebhookPublisher(wh.URLs, secret, wh.Timeout(), logger)
+			execEngine.SetSignalObserver(webhooks.PublishSignal)
+		}
+	}
+
+	var profiler *monitor.LatencyProfiler
+	if pc := cfg.Monitoring.Profiling; pc.Enabled {
+		profiler = monitor.NewLatencyProfiler(monitor.ProfilerConfig{
+			Dir:           pc.Dir,
+			Threshold:     pc.Threshold(),
+			Window:        pc.Window(),
+			MinSamples:    pc.MinSamples,
+			CheckInterval: pc.CheckInterval(),
+			Duration:      pc.ProfileDuration(),
+			Cooldown:      pc.Cooldown(),
+			MaxProfiles:   pc.MaxProfiles,
+		}, logger)
+	}
+	execEngine.SetAckObserver(func(signal domain.TradeSignal, symbol string, tickToAck time.Duration) {
+		metrics.E2ETickToAckLatency.WithLabelValues(string(signal.Strategy), signal.Venue, symbol).
+			Observe(float64(tickToAck.Microseconds()) / 1000)
+		if profiler != nil {
+			profiler.Observe(tickToAck)
+		}
+	})
+
+	execEngine.SetRejectionObserver(func(r domain.RiskRejection) {
+		asyncWriter.Write(persistence.WriteRequest{Type: persistence.WriteTypeRiskRejection, Payload: &r})
+	})
+
 	riskMgr.SetKillSwitchCallback(execEngine.KillSwitchHandler(ctx))
+	riskMgr.SetScopedKillSwitchCallback(execEngine.ScopedKillSwitchHandler(ctx))
+	riskMgr.SetTradingLocation(tradingLoc)
 
 	portfolioMgr := portfolio.NewManager(mdService, cfg.System.TradingMode, logger)
+	portfolioMgr.SetTradingLocation(tradingLoc)
+	if trades, err := sqliteStore.ListAccountActivity(domain.ActivityTrade); err != nil {
+		logger.Warn("failed to load imported trades", "error", err)
+	} else if len(trades) > 0 {
+		portfolioMgr.LoadTrades(trades)
+		logger.Info("cost basis loaded from imported trades", "trades", len(trades))
+	}
 
 	reconciler := portfolio.NewReconciler(
 		portfolioMgr,
@@ -156,6 +391,7 @@ func main() {
 		cfg.Risk.Reconciliation.MismatchThresholdPct,
 		logger,
 	)
+	reconciler.SetAccountGateways(accountGateways)
 	reconciler.SetMismatchCallback(func(venue string) {
 		alertMgr.Fire(monitor.AlertLevelP1, "reconciliation_mismatch",
 			fmt.Sprintf("position diff > %.1f%% on %s", cfg.Risk.Reconciliation.MismatchThresholdPct, venue),
@@ -164,41 +400,181 @@ func main() {
 
 	stratEngine := strategy.NewEngine(bus, logger)
 
+	var conservative *strategy.ConservativeMode
+	if cfg.Risk.ErrorBudget.Enabled {
+		conservative = &strategy.ConservativeMode{
+			Active:         riskMgr.IsConservative,
+			EdgeMultiplier: cfg.Risk.ErrorBudget.EdgeMultiplier,
+			SizeFactor:     cfg.Risk.ErrorBudget.SizeFactor,
+		}
+	}
+
+	var dynamicEdge *strategy.DynamicEdge
+	if vc := cfg.Risk.Volatility; vc.Enabled {
+		volatility := marketdata.NewVolatility(vc.HalfLife(), vc.MinTrades)
+		go volatility.Run(ctx, bus.SubscribeTrade())
+		riskMgr.SetVolatilitySource(volatility.Annualized)
+		if vc.EdgeBpsPerVolPct > 0 {
+			dynamicEdge = &strategy.DynamicEdge{
+				Volatility:   volatility.Annualized,
+				BpsPerVolPct: vc.EdgeBpsPerVolPct,
+				MaxExtraBps:  int64(vc.MaxExtraEdgeBps),
+			}
+		}
+	}
+
+	var flowGate *strategy.AdverseFlowGate
+	if af := cfg.Strategies.TriangularArb.AdverseFlow; af.Enabled {
+		analytics := marketdata.NewAnalytics(mdService.View(), af.DepthLevels, af.Window())
+		flowGate = &strategy.AdverseFlowGate{
+			Stats:        analytics.Stats,
+			MaxImbalance: af.MaxImbalance,
+			MaxPressure:  af.MaxPressure,
+		}
+	}
+
+	triMods := make(map[string]*strategy.TriArbModule)
 	if cfg.Strategies.TriangularArb.Enabled {
 		for venueName := range gateways {
 			paths := strategy.DefaultTriangularPaths(venueName)
+			for _, fiat := range fiatCurrencies(cfg.Venues[venueName].Symbols.Spot) {
+				paths = append(paths, strategy.FiatTriangularPaths(venueName, fiat)...)
+			}
 			triMod := strategy.NewTriArbModule(
 				venueName,
 				paths,
+				mdService.View(),
 				costSvc,
 				bus,
 				cfg.Strategies.TriangularArb.MinEdgeBps,
 				logger,
 			)
+			triMod.SetConservativeMode(conservative)
+			triMod.SetAdverseFlowGate(flowGate)
+			triMod.SetDynamicEdge(dynamicEdge)
 			stratEngine.RegisterModule(triMod)
+			triMods[venueName] = triMod
 		}
 	}
 
+	// Only live and dry-run feeds are worth keeping across restarts; backtests
+	// and replays neither load nor save them.
+	liveFeeds := tradingMode == domain.TradingModeLive || tradingMode == domain.TradingModeDryRun
+	fundingHistory := cfg.Persistence.FundingHistory.Enabled && liveFeeds && pgStore != nil
+
 	if cfg.Strategies.BasisArb.Enabled {
 		venues := make([]string, 0)
 		for v := range gateways {
 			venues = append(venues, v)
 		}
+		sort.Strings(venues)
 		basisMod := strategy.NewBasisArbModule(
 			venues,
 			[]string{"BTC", "ETH", "SOL"},
+			mdService.View(),
 			costSvc,
 			bus,
 			cfg.Strategies.BasisArb.MinNetEdgeBps,
 			cfg.Strategies.BasisArb.HoldingHorizonHours,
 			logger,
 		)
+		basisMod.SetConservativeMode(conservative)
+		basisMod.SetDynamicEdge(dynamicEdge)
+		if cv := cfg.Strategies.BasisArb.CrossVenue; cv.Enabled {
+			basisMod.SetCrossVenue(cv.ExtraEdgeBps, func(venue, symbol string) bool {
+				return !mdService.IsDataBlocked(venue, symbol) && !riskMgr.IsSymbolBlocked(venue, symbol)
+			})
+		}
 		stratEngine.RegisterModule(basisMod)
+		if fh := cfg.Persistence.FundingHistory; fundingHistory && fh.WarmStartHours > 0 {
+			// The cost model's funding estimate starts from the same history.
+			warmStartFunding(ctx, pgStore, venues, basisMod.PerpSymbols(), time.Now().Add(-fh.WarmStart()), func(rate domain.FundingRate) {
+				costSvc.AddFundingRate(rate.Venue, rate.Symbol, rate)
+				basisMod.OnFundingRateUpdate(rate)
+			}, logger)
+		}
+	}
+
+	// Cross-venue arb trades off the consolidated book, which leaves out
+	// venues whose feeds are blocked.
+	if cva := cfg.Strategies.CrossVenueArb; cva.Enabled {
+		crossMod := strategy.NewCrossVenueArbModule(
+			cva.Symbols,
+			mdService.View(),
+			costSvc,
+			bus,
+			cva.MinNetEdgeBps,
+			decimal.NewFromFloat(cva.MaxNotionalUSDT),
+			logger,
+		)
+		crossMod.SetConservativeMode(conservative)
+		crossMod.SetDynamicEdge(dynamicEdge)
+		stratEngine.RegisterModule(crossMod)
+	}
+
+	// Live cycles are compared with shadow ones, if any arrive, to catch the
+	// simulation drifting from what the venues fill.
+	var recordShadow func(domain.ExecutionReport)
+	if sd := cfg.DryRun.ShadowDivergence; sd.Enabled && tradingMode == domain.TradingModeLive {
+		divergence := execution.NewDivergenceMonitor(sd.Window, sd.MinSamples, sd.ThresholdBps, sd.Sustain(), logger)
+		divergence.SetDivergenceCallback(func(d execution.Divergence) {
+			alertMgr.Fire(monitor.AlertLevelP2, "shadow_live_divergence",
+				fmt.Sprintf("%s shadow edge capture %.2f bps vs live %.2f bps since %s",
+					d.Strategy, d.ShadowBps, d.LiveBps, d.Since.Format(time.RFC3339)),
+				"Recalibrate the dry-run fill and cost models before trusting shadow or backtest results for this strategy")
+		})
+		recordShadow = func(report domain.ExecutionReport) {
+			setDivergenceGauge(metrics, divergence.RecordShadow(report))
+		}
+		go runDivergenceFeed(ctx, bus.SubscribeExecutionReport(), divergence, metrics)
+	}
+
+	// The shadow engine is only started once something sends it signals.
+	shadowExecution := sync.OnceValue(func() func(domain.TradeSignal) {
+		return runShadowExecution(ctx, cfg, gateways, mdService, bus, riskMgr, instruments, recordShadow, logger)
+	})
+
+	for _, ext := range cfg.Strategies.External {
+		publish := bus.PublishSignal
+		if !ext.Live {
+			publish = func(signal domain.TradeSignal) { shadowExecution()(signal) }
+		}
+		extMod, err := external.New(external.Config{
+			Name:      ext.Name,
+			Addr:      ext.Addr,
+			TLS:       ext.TLS,
+			QueueSize: ext.QueueSize,
+			Sandbox: external.Sandbox{
+				Venues:              ext.Venues,
+				Symbols:             ext.Symbols,
+				MaxLegs:             ext.MaxLegs,
+				MaxNotional:         decimal.NewFromFloat(ext.MaxNotionalUSDT),
+				MaxSignalsPerMinute: ext.MaxSignalsPerMinute,
+				SignalTimeout:       ext.SignalTimeout(),
+			},
+		}, publish, logger)
+		if err != nil {
+			logger.Error("failed to set up external strategy", "strategy", ext.Name, "error", err)
+			os.Exit(1)
+		}
+		stratEngine.RegisterModule(extMod)
+		go extMod.Run(ctx)
+		logger.Info("external strategy registered", "strategy", ext.Name, "addr", ext.Addr, "live", ext.Live)
 	}
 
 	if riskMgr.IsKillSwitchActive() {
 		logger.Warn("KILL SWITCH IS ACTIVE - system will remain halted until manually resumed")
 	}
+	for _, scope := range riskMgr.KillSwitchScopes() {
+		logger.Warn("KILL SWITCH IS ACTIVE for one scope - it will remain halted until manually resumed", "scope", scope.String())
+	}
+
+	// Books and funding rates from the last run give marks and views a
+	// starting point; they count as stale until the feeds deliver.
+	warmStart := cfg.Persistence.MarketSnapshot.Enabled && liveFeeds
+	if warmStart {
+		restoreMarketSnapshot(sqliteStore, mdService, cfg.Persistence.MarketSnapshot.MaxAge(), logger)
+	}
 
 	for name, gw := range gateways {
 		if err := gw.Connect(ctx); err != nil {
@@ -207,25 +583,255 @@ func main() {
 		}
 		logger.Info("venue connected", "venue", name)
 	}
+	for key, gw := range accountGateways {
+		if err := gw.Connect(ctx); err != nil {
+			logger.Error("failed to connect to venue sub-account", "venue", key.Venue, "account", key.Account, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("venue sub-account connected", "venue", key.Venue, "account", key.Account)
+	}
+	if tradingMode == domain.TradingModeDryRun && cfg.DryRun.SeedFromLive {
+		if err := seedDryRunAccounts(ctx, gateways, cfg.DryRun, logger); err != nil {
+			logger.Error("failed to seed dry-run accounts from live", "error", err)
+			os.Exit(1)
+		}
+	}
+	// A symbol the venue stops trading is taken out of rotation rather than
+	// left to fail every order sent to it: signals through it are rejected,
+	// its tri-arb paths dropped and its open orders cancelled.
+	symbolUnavailable := func(venue, symbol, reason string) {
+		if !riskMgr.BlockSymbol(venue, symbol, reason) {
+			return
+		}
+		removed := 0
+		if m := triMods[venue]; m != nil {
+			removed = m.RemoveSymbol(symbol)
+		}
+		alertMgr.Fire(monitor.AlertLevelP2, "symbol_unavailable",
+			fmt.Sprintf("%s on %s can no longer be traded: %s", symbol, venue, reason),
+			fmt.Sprintf("Symbol blocked for the session, %d tri-arb paths removed, open orders cancelled", removed))
+		go func() {
+			if err := orderMgr.CancelSymbolOrders(ctx, venue, symbol, "symbol unavailable"); err != nil {
+				logger.Error("failed to cancel orders on unavailable symbol", "venue", venue, "symbol", symbol, "error", err)
+			}
+		}()
+	}
+	orderMgr.SetSymbolUnavailableCallback(func(venue, symbol string, err error) {
+		symbolUnavailable(venue, symbol, err.Error())
+	})
+	refreshInstruments(ctx, gateways, instruments, symbolUnavailable, logger)
+	go runInstrumentRefresher(ctx, gateways, instruments, time.Hour, symbolUnavailable, logger)
 
 	go costSvc.RunFeeTierRefresher(ctx)
+	mdService.SetBlockCallback(func(venue, symbol string, blocked bool) {
+		riskMgr.SetFeedBlocked(venue, symbol, blocked)
+		if !blocked {
+			metrics.MarketDataBlocked.WithLabelValues(venue, symbol).Set(0)
+			return
+		}
+		metrics.MarketDataBlocked.WithLabelValues(venue, symbol).Set(1)
+		alertMgr.Fire(monitor.AlertLevelP1, "market_data_blocked",
+			fmt.Sprintf("%s %s book past its block threshold", symbol, venue),
+			"Entries on it blocked and risk mode DATA_STALE until it updates; investigate the feed")
+	})
 	go mdService.RunHeartbeatMonitor(ctx)
+	for name, gw := range gateways {
+		if p, ok := gw.(gateway.OrderBookSnapshotProvider); ok {
+			mdService.SetSnapshotSource(name, p.GetOrderBookSnapshot)
+		}
+	}
+	mdService.SetChecksumValidation(cfg.Risk.DataFreshness.ChecksumEvery, func(venue, symbol string) {
+		alertMgr.Fire(monitor.AlertLevelP2, "book_checksum_mismatch",
+			fmt.Sprintf("order book checksum mismatch on %s %s", venue, symbol),
+			"Book resyncing from a REST snapshot; entries blocked until it completes")
+	})
+	for name, vc := range cfg.Venues {
+		if vc.MaxBookDepth > 0 {
+			mdService.SetMaxDepth(name, "", vc.MaxBookDepth)
+		}
+		for _, d := range vc.BookDepth {
+			mdService.SetMaxDepth(name, d.Symbol, d.MaxLevels)
+		}
+	}
+	if sc := cfg.Risk.DataFreshness.Sanity; sc.Enabled {
+		mdService.SetSanityFilter(sc.MaxTradeDeviationPct, sc.MaxTradeAge(), func(venue, symbol string, anomaly marketdata.Anomaly) {
+			metrics.MarketDataAnomaly.WithLabelValues(venue, symbol, string(anomaly)).Inc()
+		})
+	}
+	if mp := cfg.Runtime.MemoryPressure; mp.Enabled && memLimit > 0 {
+		memMonitor := monitor.NewMemoryMonitor(monitor.MemoryMonitorConfig{
+			Limit:         memLimit,
+			HighPct:       mp.HighPct,
+			CriticalPct:   mp.CriticalPct,
+			CheckInterval: mp.CheckInterval(),
+		}, logger)
+		memMonitor.SetUtilizationGauge(metrics.MemoryLimitUtilization)
+		memMonitor.SetPressureCallback(func(level monitor.MemoryPressure, inUse uint64) {
+			usage := fmt.Sprintf("memory in use %d MiB of the %d MiB limit", inUse>>20, memLimit>>20)
+			switch level {
+			case monitor.MemoryCritical:
+				mdService.SetConflation(mp.Conflate())
+				alertMgr.Fire(monitor.AlertLevelP1, "memory_pressure_critical", usage,
+					"Book events conflated to shed load; take a heap profile and find what is holding memory before the process runs out")
+			case monitor.MemoryHigh:
+				alertMgr.Fire(monitor.AlertLevelP2, "memory_pressure_high", usage,
+					"Memory nearing the limit; the GC is working harder and book events will be conflated if it keeps growing")
+			default:
+				mdService.SetConflation(0)
+			}
+		})
+		go memMonitor.Run(ctx)
+	}
+	if fb := cfg.Risk.DataFreshness.RESTFallback; fb.Enabled {
+		go mdService.RunRESTFallback(ctx, restFallbackFeeds(cfg, gateways), fb.PollInterval())
+	}
 	go riskMgr.RunPeriodicCheck(ctx)
+	go riskMgr.RunKillSwitchWatcher(ctx)
+	go runOrderStateFeed(ctx, bus.SubscribeOrderState(), riskMgr, costSvc)
+	if cfg.Risk.Surveillance.Enabled {
+		surveillance := compliance.NewSurveillance(cfg.Risk.Surveillance, logger)
+		surveillance.SetAlertCallback(func(v compliance.Violation) {
+			alertMgr.Fire(monitor.AlertLevelP2, "surveillance_"+string(v.Rule),
+				fmt.Sprintf("%s on %s %s: %s", v.Rule, v.Venue, v.Symbol, v.Details),
+				"Orders showing the pattern are refused before they reach the venue")
+		})
+		orderMgr.SetPreTradeCheck(surveillance.Check)
+		go surveillance.Run(ctx, bus.SubscribeOrderState())
+	}
 	go reconciler.Run(ctx)
+	consolidated := marketdata.NewConsolidatedBook(bus, mdService.IsDataBlocked, logger)
+	go consolidated.Run(ctx, bus.SubscribeOrderBook())
 	go stratEngine.Run(ctx)
 	go execEngine.Run(ctx)
+	if profiler != nil {
+		go profiler.Run(ctx)
+	}
+	go orderMgr.RunOrderUpdates(ctx)
+	go orderMgr.RunSpiller(ctx)
+	var backtestRecorder *backtest.Recorder
+	if tradingMode == domain.TradingModeBacktest {
+		backtestRecorder = &backtest.Recorder{}
+		go backtestRecorder.Run(ctx, bus.SubscribeExecutionReport())
+	}
+	runRestingMatchers(ctx, gateways, bus)
+	runFundingAccrual(ctx, gateways, bus, func(p domain.AccountActivity) {
+		riskMgr.OnFundingPayment(p.Amount)
+		portfolioMgr.AddRealizedPnL(p.Amount)
+		if backtestRecorder != nil {
+			backtestRecorder.AddPnL(p.Timestamp, p.Amount)
+		}
+	})
+	runMarginChecks(ctx, gateways, func(l simulated.Liquidation) {
+		riskMgr.OnLiquidation(l.PnL)
+		portfolioMgr.AddRealizedPnL(l.PnL)
+		if backtestRecorder != nil {
+			backtestRecorder.AddPnL(l.Timestamp, l.PnL)
+		}
+		alertMgr.Fire(monitor.AlertLevelP1, "simulated_liquidation",
+			"perp equity at or below maintenance margin",
+			fmt.Sprintf("%s: %d perp positions liquidated, equity %s, maintenance %s, pnl %s",
+				l.Venue, len(l.Positions), l.Status.Equity.StringFixed(2), l.Status.Maintenance.StringFixed(2), l.PnL.StringFixed(2)))
+		asyncWriter.Write(persistence.WriteRequest{Type: persistence.WriteTypeRiskEvent, Payload: &domain.RiskEvent{
+			ID:        uuid.New(),
+			Type:      domain.RiskEventLiquidation,
+			Severity:  domain.AlertP1,
+			Details:   l,
+			CreatedAt: l.Timestamp,
+		}})
+	})
+
+	if webhooks != nil {
+		go webhooks.Run(ctx, bus.SubscribeExecutionReport())
+	}
+	go runReportRecorder(ctx, bus.SubscribeExecutionReport(), asyncWriter)
+	go runCycleActivityFeed(ctx, bus.SubscribeExecutionReport(), riskMgr)
+	go runOrderEventRecorder(ctx, bus.SubscribeOrderState(), asyncWriter)
+	go runRateLimitGauges(ctx, gateways, metrics, 5*time.Second)
 
+	healthMon := gateway.NewHealthMonitor(gateways, cfg.Monitoring.Health.Interval(), cfg.Monitoring.Health.MaxMessageAge(), logger)
+	healthMon.SetUnhealthyCallback(func(venue, reason string) {
+		alertMgr.Fire(monitor.AlertLevelP1, "venue_unhealthy",
+			fmt.Sprintf("%s health check failed: %s", venue, reason),
+			fmt.Sprintf("Check connectivity to %s; readiness fails until it recovers", venue))
+	})
+	if after := cfg.Monitoring.Health.CancelOnDisconnect(); after > 0 {
+		healthMon.SetOutageCallback(after, func(venue string) error {
+			return orderMgr.CancelVenueOrders(ctx, venue, "venue disconnected")
+		})
+		go runCancelOnDisconnect(ctx, gateways, after, logger)
+	}
+	go healthMon.Run(ctx)
 	go runCheckpointer(ctx, riskMgr, asyncWriter, cfg.Risk.CheckpointInterval(), logger)
+	go runCostFundingFeed(ctx, bus.SubscribeFundingRate(), costSvc)
+	if fundingHistory {
+		go runFundingRecorder(ctx, bus.SubscribeFundingRate(), asyncWriter, cfg.Persistence.FundingHistory.MinInterval())
+	}
+	if warmStart {
+		go runMarketSnapshotter(ctx, sqliteStore, mdService, cfg.Persistence.MarketSnapshot.Interval(), logger)
+	}
+	go runNightlyStressReport(ctx, riskMgr, asyncWriter, alertMgr, cfg.Risk.Stress.NightlyReportHour, tradingLoc, logger)
+	go runDailyRollover(ctx, riskMgr, portfolioMgr, asyncWriter, sqliteStore, tradingLoc, logger)
 
-	metricsServer := newMetricsServer(logger)
-	go func() {
-		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("metrics server error", "error", err)
+	var intake *admin.SignalIntake
+	if ext := cfg.Strategies.ExternalSignals; ext.Enabled {
+		intake = &admin.SignalIntake{Live: bus.PublishSignal}
+		for _, src := range ext.Sources {
+			secret := os.Getenv(src.SecretEnv)
+			if secret == "" {
+				logger.Error("external signal source disabled: secret not set",
+					"source", src.Name, "env", src.SecretEnv)
+				continue
+			}
+			intake.Sources = append(intake.Sources, admin.SignalSource{Name: src.Name, Secret: []byte(secret), Live: src.Live})
+		}
+		for v := range gateways {
+			intake.Venues = append(intake.Venues, v)
 		}
+		intake.Shadow = shadowExecution()
+	}
+
+	previewer := execution.NewPreviewer(execEngine, mdService.GetOrderBook, costSvc)
+	venueNames := make([]string, 0, len(gateways))
+	for v := range gateways {
+		venueNames = append(venueNames, v)
+	}
+	sort.Strings(venueNames)
+
+	info := admin.InstanceInfo{
+		InstanceID:  cfg.System.InstanceID,
+		Build:       build,
+		ConfigHash:  cfg.Hash(),
+		TradingMode: cfg.System.TradingMode,
+		Strategies:  cfg.EnabledStrategies(),
+		Venues:      venueNames,
+		StartedAt:   time.Now(),
+	}
+	metrics.BuildInfo.WithLabelValues(info.InstanceID, info.Build.Version, info.Build.ShortCommit(), info.ConfigHash,
+		info.TradingMode, strings.Join(info.Strategies, ","), strings.Join(info.Venues, ",")).Set(1)
+
+	metricsServer := newMetricsServer(sqliteStore, riskMgr, intake, healthMon, previewer, latency, info,
+		mdService.View(), orderMgr, cfg.Monitoring.Metrics.BookSampleInterval(), logger)
+	if user := cfg.Monitoring.Metrics.BasicAuthUser; user != "" {
+		metricsServer.Handler = admin.RequireBasicAuth(metricsServer.Handler, user, metricsPassword, "/health", "/ready")
+	}
+	logger.Info("metrics server starting", "addr", metricsListener.Addr().String(),
+		"tls", cfg.Monitoring.Metrics.TLSCertFile != "", "basic_auth", cfg.Monitoring.Metrics.BasicAuthUser != "")
+	metricsDone := make(chan struct{})
+	go func() {
+		defer close(metricsDone)
+		serveMetrics(ctx, metricsServer, metricsListener, logger)
 	}()
 
+	lastCfg := cfg
 	if err := config.WatchAndReload(*configPath, func(newCfg *config.Config) {
-		logger.Info("configuration reloaded")
+		change, err := newConfigChange(lastCfg, newCfg, "reload")
+		lastCfg = newCfg
+		if err != nil {
+			logger.Warn("failed to record config change", "error", err)
+			return
+		}
+		logger.Info("configuration reloaded", "config_hash", change.Hash, "changed_keys", change.Keys)
+		asyncWriter.Write(persistence.WriteRequest{Type: persistence.WriteTypeConfigChange, Payload: change})
 	}); err != nil {
 		logger.Warn("config hot-reload setup failed", "error", err)
 	}
@@ -236,6 +842,23 @@ func main() {
 		"venues", len(gateways),
 	)
 
+	if backtestRecorder != nil {
+		go func() {
+			if err := runBacktest(ctx, cfg, mdService, backtestRecorder, pgStore, os.Stdout, logger); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("backtest failed", "error", err)
+			}
+			cancel()
+		}()
+	}
+	if tradingMode == domain.TradingModeReplay {
+		go func() {
+			if err := runReplay(ctx, cfg, mdService, logger); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("replay failed", "error", err)
+			}
+			cancel()
+		}()
+	}
+
 	select {
 	case sig := <-sigCh:
 		logger.Info("received shutdown signal", "signal", sig)
@@ -255,11 +878,18 @@ func main() {
 			logger.Error("failed to close venue gateway", "venue", name, "error", err)
 		}
 	}
+	for key, gw := range accountGateways {
+		if err := gw.Close(); err != nil {
+			logger.Error("failed to close venue gateway", "venue", key.Venue, "account", key.Account, "error", err)
+		}
+	}
 
-	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
-		logger.Error("failed to shut down metrics server", "error", err)
+	if warmStart {
+		saveMarketSnapshot(sqliteStore, mdService, logger)
 	}
 
+	<-metricsDone
+
 	bus.Close()
 	asyncWriter.Stop()
 
@@ -295,103 +925,1476 @@ func initLogger(level string) *slog.Logger {
 	return logger
 }
 
-func configureRuntime(cfg config.RuntimeConfig, logger *slog.Logger) {
+// configureRuntime applies the runtime settings and returns the memory
+// limit in force, in bytes, or 0 if there is none.
+func configureRuntime(cfg config.RuntimeConfig, logger *slog.Logger) int64 {
 	if cfg.GoMaxProcs > 0 {
 		runtime.GOMAXPROCS(cfg.GoMaxProcs)
 	}
+	if cfg.GOGC > 0 {
+		debug.SetGCPercent(cfg.GOGC)
+	}
+	// Load has validated the limit. One set in the environment was applied
+	// by the runtime at startup and is left alone.
+	if limit, _ := cfg.MemoryLimit(); limit > 0 && os.Getenv("GOMEMLIMIT") == "" {
+		debug.SetMemoryLimit(limit)
+	}
+	limit := debug.SetMemoryLimit(-1)
+	if limit == 1<<63-1 {
+		limit = 0
+	}
 	logger.Info("runtime configured",
 		"GOMAXPROCS", runtime.GOMAXPROCS(0),
 		"GOGC", cfg.GOGC,
-		"GOMEMLIMIT", cfg.GoMemLimit,
+		"GOMEMLIMIT_MB", limit>>20,
 	)
+	return limit
+}
 
-	if cfg.GOGC > 0 {
-		debug.SetGCPercent(cfg.GOGC)
+// configureFreshness applies the funding-feed thresholds and per-venue and
+// per-symbol overrides on top of the global data freshness thresholds.
+func configureFreshness(mdService *marketdata.Service, cfg config.DataFreshnessConfig) {
+	freshness := func(t config.FreshnessThresholds) marketdata.Freshness {
+		return marketdata.Freshness{Stale: t.WarningDuration(), Block: t.BlockDuration()}
+	}
+	mdService.SetFreshness(marketdata.FeedFunding, "", "", freshness(cfg.Funding))
+	for _, o := range cfg.Overrides {
+		feed := marketdata.FeedBook
+		if o.Feed != "" {
+			feed = marketdata.FeedType(o.Feed)
+		}
+		mdService.SetFreshness(feed, o.Venue, o.Symbol, freshness(o.Thresholds()))
+	}
+}
+
+// applySymbolMaps registers each enabled venue's configured symbol mappings
+// and checks that every symbol the venue is configured to trade resolves on
+// it, so a typo fails startup instead of subscribing to a book that never
+// updates.
+func applySymbolMaps(cfg *config.Config) error {
+	names := make([]string, 0, len(cfg.Venues))
+	for name, v := range cfg.Venues {
+		if v.Enabled {
+			names = append(names, name)
+		}
 	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		v := cfg.Venues[name]
+		for _, m := range v.SymbolMap {
+			if v.Protocol != "" {
+				errs = append(errs, fmt.Errorf("venue %s: symbol_map is not supported with protocol %s", name, v.Protocol))
+				break
+			}
+			if err := domain.RegisterSymbol(name, domain.InstrumentType(strings.ToUpper(m.Type)), m.Symbol, m.VenueSymbol); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, name := range names {
+		symbols := cfg.Venues[name].Symbols
+		for _, s := range symbols.Spot {
+			if _, ok := domain.ResolveSymbol(name, domain.InstrumentSpot, s); !ok {
+				errs = append(errs, fmt.Errorf("venue %s: spot symbol %s has no mapping", name, s))
+			}
+		}
+		for _, s := range symbols.Perp {
+			if _, ok := domain.ResolveSymbol(name, domain.InstrumentPerp, s); !ok {
+				errs = append(errs, fmt.Errorf("venue %s: perp symbol %s has no mapping", name, s))
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func buildGateways(cfg *config.Config, mdService *marketdata.Service, mode domain.TradingMode, logger *slog.Logger) map[string]gateway.VenueGateway {
+// buildGateways creates the enabled venues' gateways, and a gateway for
+// every sub-account a strategy is routed to other than the venue's own
+// sub_account. metrics and latency may be nil.
+func buildGateways(cfg *config.Config, mdService *marketdata.Service, mode domain.TradingMode, metrics *monitor.Metrics, latency *gateway.LatencyTracker, logger *slog.Logger) (map[string]gateway.VenueGateway, map[domain.VenueAccount]gateway.VenueGateway) {
 	gateways := make(map[string]gateway.VenueGateway)
+	accounts := make(map[domain.VenueAccount]gateway.VenueGateway)
 
 	for venueName, venueCfg := range cfg.Venues {
 		if !venueCfg.Enabled {
 			continue
 		}
 
-		var gw gateway.VenueGateway
-		switch venueName {
-		case "nobitex":
-			// Nobitex uses token-based authentication (Authorization: Token xxx).
-			// Token is obtained from the Nobitex account panel or via /auth/login/.
-			token := os.Getenv("NOBITEX_API_TOKEN")
-			gw = nobitex.New(venueCfg.WsURL, venueCfg.RestURL, token, logger)
+		if gw, ok := buildGateway(cfg, venueName, venueCfg, mdService, mode, metrics, latency, logger); ok {
+			gateways[venueName] = gw
+		}
+
+		for _, sa := range venueCfg.StrategyAccounts {
+			key := domain.VenueAccount{Venue: venueName, Account: sa.SubAccount}
+			if sa.SubAccount == venueCfg.SubAccount || accounts[key] != nil {
+				continue
+			}
+			acctCfg := venueCfg
+			acctCfg.SubAccount = sa.SubAccount
+			if gw, ok := buildGateway(cfg, venueName, acctCfg, mdService, mode, metrics, latency, logger); ok {
+				accounts[key] = gw
+			}
+		}
+	}
+
+	return gateways, accounts
+}
+
+// strategyAccountRoute returns the order manager's account route: the
+// sub-account a venue's strategy_accounts puts a strategy on, or the empty
+// default account for strategies trading on the venue's own sub_account.
+func strategyAccountRoute(venues map[string]config.VenueConfig) func(venue string, strategy domain.StrategyType) string {
+	routes := make(map[string]map[domain.StrategyType]string)
+	for name, v := range venues {
+		for _, sa := range v.StrategyAccounts {
+			if sa.SubAccount == v.SubAccount {
+				continue
+			}
+			if routes[name] == nil {
+				routes[name] = make(map[domain.StrategyType]string)
+			}
+			routes[name][domain.StrategyType(sa.Strategy)] = sa.SubAccount
+		}
+	}
+	return func(venue string, strategy domain.StrategyType) string {
+		return routes[venue][strategy]
+	}
+}
 
-		case "kcex":
-			// KCEX uses KuCoin-style API key + secret + passphrase authentication.
-			apiKey := os.Getenv("KCEX_API_KEY")
-			apiSecret := os.Getenv("KCEX_API_SECRET")
-			passphrase := os.Getenv("KCEX_API_PASSPHRASE")
-			gw = kcex.New(venueCfg.WsURL, venueCfg.RestURL, apiKey, apiSecret, passphrase, logger)
+// buildGateway creates one venue gateway trading on venueCfg.SubAccount. It
+// reports false, having logged why, when the venue must be skipped.
+func buildGateway(cfg *config.Config, venueName string, venueCfg config.VenueConfig, mdService *marketdata.Service, mode domain.TradingMode, metrics *monitor.Metrics, latency *gateway.LatencyTracker, logger *slog.Logger) (gateway.VenueGateway, bool) {
+	env := func(name string) string { return venueEnv(venueName, venueCfg.SubAccount, name) }
 
-		case "wallex":
-			// Wallex uses API key authentication via x-api-key header.
-			// API keys are created in the Wallex API Management panel with max 90-day validity.
-			apiKey := os.Getenv("WALLEX_API_KEY")
-			gw = wallex.New(venueCfg.WsURL, venueCfg.RestURL, apiKey, logger)
+	// A venue reached through a generic protocol is built by protocol,
+	// whatever it is called.
+	kind := venueName
+	if venueCfg.Protocol != "" {
+		kind = venueCfg.Protocol
+	}
 
-		default:
-			logger.Warn("unknown venue, skipping", "venue", venueName)
-			continue
+	var gw gateway.VenueGateway
+	switch kind {
+	case "fix":
+		// FIX venues are configured entirely under fix:; the logon
+		// credentials come from <VENUE>_FIX_USERNAME and _PASSWORD.
+		f := venueCfg.FIX
+		fixGw, err := fix.New(fix.Config{
+			Venue:                  venueName,
+			MarketDataAddr:         f.MarketDataAddr,
+			OrderEntryAddr:         f.OrderEntryAddr,
+			SenderCompID:           f.SenderCompID,
+			TargetCompID:           f.TargetCompID,
+			MarketDataSenderCompID: f.MarketDataSenderCompID,
+			MarketDataTargetCompID: f.MarketDataTargetCompID,
+			Username:               env("FIX_USERNAME"),
+			Password:               env("FIX_PASSWORD"),
+			Account:                f.Account,
+			Heartbeat:              f.Heartbeat(),
+			TLS:                    f.TLS,
+			Depth:                  f.Depth,
+			MakerFeeBps:            decimal.NewFromFloat(f.MakerFeeBps),
+			TakerFeeBps:            decimal.NewFromFloat(f.TakerFeeBps),
+		}, logger)
+		if err != nil {
+			logger.Error("invalid FIX venue, skipping", "venue", venueName, "error", err)
+			return nil, false
 		}
+		gw = fixGw
 
-		if mode == domain.TradingModeDryRun {
-			fillSim := simulated.NewFillSimulator(
-				cfg.DryRun.SimulatedLatencyMs,
-				cfg.DryRun.RejectRatePct,
-				decimal.NewFromFloat(2),
-				decimal.NewFromFloat(5),
-			)
-			gw = dryrun.NewWrapper(gw, fillSim, mdService, logger)
-			logger.Info("venue wrapped in dry-run mode (real data, simulated orders)", "venue", venueName)
+	case "grpc":
+		// A plugin process serves the venue; it holds the venue
+		// credentials itself.
+		pluginGw, err := grpcplugin.New(grpcplugin.Config{
+			Venue: venueName,
+			Addr:  venueCfg.GRPC.Addr,
+			TLS:   venueCfg.GRPC.TLS,
+		}, logger)
+		if err != nil {
+			logger.Error("invalid gRPC plugin venue, skipping", "venue", venueName, "error", err)
+			return nil, false
 		}
+		gw = pluginGw
 
-		gateways[venueName] = gw
-	}
+	case "nobitex":
+		// Nobitex uses token-based authentication (Authorization: Token xxx).
+		// Token is obtained from the Nobitex account panel or via /auth/login/.
+		token := os.Getenv("NOBITEX_API_TOKEN")
+		gw = nobitex.New(venueCfg.WsURL, venueCfg.RestURL, token, logger)
 
-	return gateways
-}
+	case "kcex":
+		// KCEX uses KuCoin-style API key + secret + passphrase authentication.
+		apiKey := env("API_KEY")
+		apiSecret := env("API_SECRET")
+		passphrase := env("API_PASSPHRASE")
+		gw = kcex.New(venueCfg.WsURL, venueCfg.RestURL, apiKey, apiSecret, passphrase, logger)
 
-func runCheckpointer(ctx context.Context, riskMgr *risk.Manager, writer *persistence.AsyncWriter, interval time.Duration, logger *slog.Logger) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	case "wallex":
+		// Wallex uses API key authentication via x-api-key header.
+		// API keys are created in the Wallex API Management panel with max 90-day validity.
+		apiKey := os.Getenv("WALLEX_API_KEY")
+		gw = wallex.New(venueCfg.WsURL, venueCfg.RestURL, apiKey, logger)
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			state := riskMgr.GetCheckpointState()
-			writer.Write(persistence.WriteRequest{
-				Type:    persistence.WriteTypeRiskCheckpoint,
-				Payload: state,
+	case "binance":
+		// Binance signs query strings with HMAC-SHA256 and sends the key in X-MBX-APIKEY.
+		// Perpetuals are served from separate futures hosts.
+		apiKey := env("API_KEY")
+		apiSecret := env("API_SECRET")
+		gw = binance.New(venueCfg.WsURL, venueCfg.RestURL, venueCfg.FuturesWsURL, venueCfg.FuturesRestURL, apiKey, apiSecret, logger)
+
+	case "bybit":
+		// Bybit v5 signs timestamp + key + recvWindow + payload with HMAC-SHA256.
+		// Linear perpetuals stream from futures_ws_url; REST is shared.
+		apiKey := env("API_KEY")
+		apiSecret := env("API_SECRET")
+		gw = bybit.New(venueCfg.WsURL, venueCfg.FuturesWsURL, venueCfg.RestURL, apiKey, apiSecret, logger)
+
+	case "okx":
+		// OKX v5 signs timestamp + method + path + body (Base64 HMAC-SHA256)
+		// and requires a passphrase. Spot and swaps share one public stream.
+		apiKey := env("API_KEY")
+		apiSecret := env("API_SECRET")
+		passphrase := env("API_PASSPHRASE")
+		gw = okx.New(venueCfg.WsURL, venueCfg.RestURL, apiKey, apiSecret, passphrase, logger)
+
+	default:
+		logger.Warn("unknown venue, skipping", "venue", venueName)
+		return nil, false
+	}
+
+	if len(venueCfg.APIKeys) > 0 {
+		// Never fall back to the single key when a key set was asked
+		// for: it would carry the whole load on one budget.
+		rotator, ok := gw.(gateway.KeyRotator)
+		if !ok {
+			logger.Error("venue does not support multiple API keys, skipping", "venue", venueName)
+			return nil, false
+		}
+		keys := make([]gateway.APIKey, 0, len(venueCfg.APIKeys))
+		for _, k := range venueCfg.APIKeys {
+			keys = append(keys, gateway.APIKey{
+				Name:       k.Name,
+				Key:        env(k.Name + "_API_KEY"),
+				Secret:     env(k.Name + "_API_SECRET"),
+				Passphrase: env(k.Name + "_API_PASSPHRASE"),
+				Weight:     k.Weight,
+				Role:       gateway.KeyRole(k.Role),
 			})
-			logger.Debug("risk state checkpointed")
 		}
+		if err := rotator.SetAPIKeys(keys); err != nil {
+			logger.Error("invalid API keys, skipping", "venue", venueName, "error", err)
+			return nil, false
+		}
+		logger.Info("venue rotating API keys", "venue", venueName, "keys", len(keys))
 	}
-}
 
-func newMetricsServer(logger *slog.Logger) *http.Server {
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", monitor.MetricsHandler())
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("ok"))
-	})
+	if venueCfg.SubAccount != "" {
+		// Never fall back to the main account when a sub-account was asked for.
+		sub, ok := gw.(interface{ SetSubAccount(string) })
+		if !ok {
+			logger.Error("venue has no sub-accounts, skipping", "venue", venueName, "sub_account", venueCfg.SubAccount)
+			return nil, false
+		}
+		if env("API_KEY") == "" && len(venueCfg.APIKeys) == 0 {
+			logger.Error("no API key for sub-account, skipping",
+				"venue", venueName,
+				"sub_account", venueCfg.SubAccount,
+				"env", venueEnvName(venueName, venueCfg.SubAccount, "API_KEY"))
+			return nil, false
+		}
+		sub.SetSubAccount(venueCfg.SubAccount)
+		logger.Info("venue trading on sub-account", "venue", venueName, "sub_account", venueCfg.SubAccount)
+	}
 
-	logger.Info("metrics server starting", "addr", ":9090")
-	return &http.Server{
-		Addr:              ":9090",
-		Handler:           mux,
-		ReadHeaderTimeout: 10 * time.Second,
+	if venueCfg.WSConnections > 1 {
+		// More connections only spread the load, so a venue without
+		// them still runs on one.
+		if sh, ok := gw.(gateway.WSSharder); ok {
+			sh.SetWSConnections(venueCfg.WSConnections)
+			logger.Info("venue market data sharded", "venue", venueName, "connections", venueCfg.WSConnections)
+		} else {
+			logger.Warn("venue does not support several WebSocket connections, using one", "venue", venueName)
+		}
+	}
+
+	if venueCfg.Proxy != "" || venueCfg.Interface != "" {
+		// A venue that needs a specific egress must not fall back to the
+		// default route, which it may reject or flag.
+		egress, err := gateway.NewEgress(venueCfg.Proxy, venueCfg.Interface)
+		if err != nil {
+			logger.Error("invalid venue egress, skipping", "venue", venueName, "error", err)
+			return nil, false
+		}
+		ec, ok := gw.(gateway.EgressConfigurable)
+		if !ok {
+			logger.Error("venue does not support egress routing, skipping", "venue", venueName)
+			return nil, false
+		}
+		ec.SetEgress(egress)
+		logger.Info("venue egress configured", "venue", venueName, "route", egress.String())
+	}
+
+	if r, ok := gw.(gateway.APIErrorReporter); ok && metrics != nil {
+		r.SetAPIErrorObserver(func(venue string, category domain.EndpointCategory, code string) {
+			metrics.VenueAPIError.WithLabelValues(venue, string(category), code).Inc()
+		})
+	}
+	if r, ok := gw.(gateway.WSReconnectReporter); ok && metrics != nil {
+		r.SetWSReconnectObserver(func(venue string) {
+			metrics.VenueWSReconnect.WithLabelValues(venue).Inc()
+		})
+	}
+	if r, ok := gw.(gateway.ClockOffsetReporter); ok && metrics != nil {
+		r.SetClockOffsetObserver(func(venue string, offset time.Duration) {
+			metrics.VenueClockOffset.WithLabelValues(venue).Set(float64(offset.Milliseconds()))
+		})
+	}
+	if r, ok := gw.(gateway.LatencyReporter); ok && (metrics != nil || latency != nil) {
+		r.SetLatencyObserver(func(venue string, kind gateway.LatencyKind, endpoint string, elapsed time.Duration) {
+			if latency != nil {
+				latency.Observe(venue, kind, endpoint, elapsed)
+			}
+			if metrics == nil {
+				return
+			}
+			ms := float64(elapsed.Microseconds()) / 1000
+			switch kind {
+			case gateway.LatencyWS:
+				metrics.VenueWSLatency.WithLabelValues(venue, endpoint).Observe(ms)
+			case gateway.LatencyRateLimit:
+				metrics.VenueRateLimitWait.WithLabelValues(venue, endpoint).Observe(ms)
+			default:
+				metrics.VenueRESTLatency.WithLabelValues(venue, endpoint).Observe(ms)
+			}
+		})
+	}
+
+	if mode == domain.TradingModeBacktest || mode == domain.TradingModeReplay {
+		// Recorded data stands in for the venue's feeds, so a backtest
+		// or replay never connects to it: every order is simulated.
+		sim := simulated.New(venueName, newFillSimulator(cfg.DryRun, venueName, mdService), mdService,
+			cfg.DryRun.InitialCapitalUSDT, cfg.DryRun.SimulatedLatencyMs, logger)
+		if cfg.DryRun.Margin.Enabled {
+			sim.SetMargin(marginModel(cfg.DryRun))
+		}
+		logger.Info("venue simulated for recorded data", "venue", venueName, "mode", mode)
+		return sim, true
+	}
+
+	if mode == domain.TradingModeDryRun {
+		fillSim := newFillSimulator(cfg.DryRun, venueName, mdService)
+		w := dryrun.NewWrapper(gw, fillSim, mdService, logger)
+		if cfg.DryRun.Margin.Enabled {
+			w.SetMargin(marginModel(cfg.DryRun))
+		}
+		gw = w
+		logger.Info("venue wrapped in dry-run mode (real data, simulated orders)", "venue", venueName)
+	}
+
+	if metrics != nil {
+		gw = metered.NewWrapper(gw, venueName, func(venue, method string, elapsed time.Duration, err error, items int) {
+			result := "ok"
+			if err != nil {
+				result = "error"
+			}
+			metrics.VenueCallTotal.WithLabelValues(venue, method, result).Inc()
+			metrics.VenueCallLatency.WithLabelValues(venue, method).Observe(float64(elapsed.Microseconds()) / 1000)
+			if items > 0 {
+				metrics.VenueCallItems.WithLabelValues(venue, method).Observe(float64(items))
+			}
+		})
+	}
+
+	return gw, true
+}
+
+// fiatCurrencies returns the fiat currencies a venue converts to USDT on,
+// from its USDT/<fiat> spot symbols.
+func fiatCurrencies(spot []string) []string {
+	var fiats []string
+	for _, symbol := range spot {
+		if fiat, ok := strings.CutPrefix(symbol, "USDT/"); ok && (fiat == "IRT" || fiat == "TMN") {
+			fiats = append(fiats, fiat)
+		}
+	}
+	return fiats
+}
+
+// newFillSimulator builds a venue's fill simulator for dry-run and shadow
+// orders.
+func newFillSimulator(cfg config.DryRunConfig, venue string, mdService *marketdata.Service) *simulated.DefaultFillSimulator {
+	fillSim := simulated.NewFillSimulator(
+		cfg.SimulatedLatencyMs,
+		cfg.RejectRatePct,
+		decimal.NewFromFloat(2),
+		decimal.NewFromFloat(5),
+	)
+	if cfg.MakerQueue.Enabled {
+		fillSim.SetQueueModel(simulated.QueueModel{
+			Ahead:           cfg.MakerQueue.QueueAhead,
+			FillProbability: cfg.MakerQueue.FillProbability,
+		})
+	}
+	if cfg.Impact.Enabled {
+		impact := simulated.ImpactModel{CoefficientBps: cfg.Impact.CoefficientBps}
+		if cfg.Impact.TradeWindow > 0 {
+			impact.Trades = func(symbol string) []*domain.Trade {
+				return mdService.GetRecentTrades(venue, symbol, cfg.Impact.TradeWindow)
+			}
+		}
+		fillSim.SetImpactModel(impact)
+	}
+	return fillSim
+}
+
+// marginModel is the margin model dry-run perp positions are held to.
+func marginModel(cfg config.DryRunConfig) simulated.MarginModel {
+	collateral := cfg.Margin.CollateralUSDT
+	if collateral.IsZero() {
+		collateral = cfg.InitialCapitalUSDT
+	}
+	hundred := decimal.NewFromInt(100)
+	return simulated.MarginModel{
+		Collateral:         collateral,
+		Leverage:           decimal.NewFromFloat(cfg.Margin.Leverage),
+		MaintenanceRate:    decimal.NewFromFloat(cfg.Margin.MaintenancePct).Div(hundred),
+		LiquidationFeeRate: decimal.NewFromFloat(cfg.Margin.LiquidationFeePct).Div(hundred),
+	}
+}
+
+// restFallbackFeeds lists the configured books of every gateway that can
+// serve order book snapshots over REST.
+func restFallbackFeeds(cfg *config.Config, gateways map[string]gateway.VenueGateway) []marketdata.Feed {
+	var feeds []marketdata.Feed
+	for name, gw := range gateways {
+		p, ok := gw.(gateway.OrderBookSnapshotProvider)
+		if !ok {
+			continue
+		}
+		symbols := cfg.Venues[name].Symbols
+		for _, symbol := range append(append([]string(nil), symbols.Spot...), symbols.Perp...) {
+			feeds = append(feeds, marketdata.Feed{Venue: name, Symbol: symbol, Fetch: p.GetOrderBookSnapshot})
+		}
+	}
+	return feeds
+}
+
+// venueEnv reads a venue credential from the environment. Credentials for a
+// sub-account live under <VENUE>_<SUB_ACCOUNT>_<NAME>, e.g. BYBIT_BASIS_API_KEY,
+// and main-account ones under <VENUE>_<NAME>.
+func venueEnv(venue, subAccount, name string) string {
+	return os.Getenv(venueEnvName(venue, subAccount, name))
+}
+
+func venueEnvName(venue, subAccount, name string) string {
+	parts := []string{venue}
+	if subAccount != "" {
+		parts = append(parts, subAccount)
+	}
+	parts = append(parts, name)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, strings.Join(parts, "_"))
+}
+
+// runAccountImport backfills account history for [since, until) into the
+// SQLite store. Dates are calendar days in the trading timezone. Gateways are
+// built unwrapped even in dry-run mode, since history has to come from the
+// real account.
+func runAccountImport(ctx context.Context, cfg *config.Config, mdService *marketdata.Service, store *persistence.SQLiteStore, since, until string, loc *time.Location, logger *slog.Logger) error {
+	from, err := time.ParseInLocation("2006-01-02", since, loc)
+	if err != nil {
+		return fmt.Errorf("parse -import-since: %w", err)
+	}
+	to := time.Now()
+	if until != "" {
+		if to, err = time.ParseInLocation("2006-01-02", until, loc); err != nil {
+			return fmt.Errorf("parse -import-until: %w", err)
+		}
+	}
+
+	gateways, _ := buildGateways(cfg, mdService, domain.TradingModeLive, nil, nil, logger)
+	importer := portfolio.NewImporter(gateways, store, logger)
+	results, err := importer.Import(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, r := range results {
+		total += r.Inserted
+	}
+	logger.Info("account history import complete", "since", from, "until", to, "venues", len(results), "inserted", total)
+	return nil
+}
+
+// runDownload fetches the market history named by kinds over window, "FROM,TO"
+// as RFC 3339 times or as dates in loc, for every configured venue symbol
+// into the cold store. Gateways are built unwrapped, as history has to come
+// from the real venues.
+func runDownload(ctx context.Context, cfg *config.Config, mdService *marketdata.Service, store *persistence.PostgresStore, window string, interval time.Duration, kinds string, loc *time.Location, logger *slog.Logger) error {
+	if store == nil {
+		return errors.New("persistence.cold_store_dsn must point at a reachable PostgreSQL database")
+	}
+	from, to, err := parseTimeRange(window, loc)
+	if err != nil {
+		return fmt.Errorf("parse -download: %w", err)
+	}
+	req := backtest.DownloadRequest{From: from, To: to, Interval: interval}
+	for _, kind := range strings.Split(kinds, ",") {
+		switch strings.TrimSpace(kind) {
+		case "klines":
+			req.Klines = true
+		case "trades":
+			req.Trades = true
+		case "funding":
+			req.Funding = true
+		default:
+			return fmt.Errorf("unknown -download-kinds entry %q; want klines, trades or funding", kind)
+		}
+	}
+
+	symbols := make(map[string][]backtest.DownloadSymbol, len(cfg.Venues))
+	for name, venue := range cfg.Venues {
+		if !venue.Enabled {
+			continue
+		}
+		for _, s := range venue.Symbols.Spot {
+			symbols[name] = append(symbols[name], backtest.DownloadSymbol{Symbol: s})
+		}
+		for _, s := range venue.Symbols.Perp {
+			symbols[name] = append(symbols[name], backtest.DownloadSymbol{Symbol: s, Perp: true})
+		}
+	}
+
+	gateways, _ := buildGateways(cfg, mdService, domain.TradingModeLive, nil, nil, logger)
+	results, err := backtest.NewDownloader(gateways, store, logger).Download(ctx, symbols, req)
+	if err != nil {
+		return err
+	}
+
+	var klines, trades, funding int
+	for _, r := range results {
+		klines += r.Klines
+		trades += r.Trades
+		funding += r.Funding
+	}
+	logger.Info("market history download complete", "from", from, "to", to, "venues", len(results),
+		"klines", klines, "trades", trades, "funding", funding)
+	return nil
+}
+
+// runIngest loads the third-party dataset named by req into the cold store.
+func runIngest(ctx context.Context, store *persistence.PostgresStore, req backtest.IngestRequest, logger *slog.Logger) error {
+	if store == nil {
+		return errors.New("persistence.cold_store_dsn must point at a reachable PostgreSQL database")
+	}
+	result, err := backtest.NewIngester(store, logger).Ingest(ctx, req)
+	if err != nil {
+		return err
+	}
+	logger.Info("market history ingest complete", "path", req.Path, "kind", req.Kind, "venue", req.Venue, "symbol", req.Symbol,
+		"files", result.Files, "rows", result.Rows, "new", result.New)
+	return nil
+}
+
+// runRegressionReport compares the execution reports persisted in the two
+// date ranges, each "FROM,TO" in the trading timezone with TO exclusive,
+// writes the report to w and returns whether any metric regressed.
+func runRegressionReport(cfg *config.Config, baseline, candidate string, w io.Writer, logger *slog.Logger) (bool, error) {
+	loc, err := time.LoadLocation(cfg.System.Timezone)
+	if err != nil {
+		return false, fmt.Errorf("load timezone: %w", err)
+	}
+	baseFrom, baseTo, err := parseDateRange(baseline, loc)
+	if err != nil {
+		return false, fmt.Errorf("parse -compare-baseline: %w", err)
+	}
+	candFrom, candTo, err := parseDateRange(candidate, loc)
+	if err != nil {
+		return false, fmt.Errorf("parse -compare-candidate: %w", err)
+	}
+
+	store, err := persistence.NewSQLiteStore(cfg.Persistence.CheckpointDB, logger)
+	if err != nil {
+		return false, err
+	}
+	defer store.Close()
+
+	baseReports, err := store.ListExecutionReports(baseFrom, baseTo)
+	if err != nil {
+		return false, err
+	}
+	candReports, err := store.ListExecutionReports(candFrom, candTo)
+	if err != nil {
+		return false, err
+	}
+
+	report := execution.CompareReports(baseReports, candReports)
+	fmt.Fprintf(w, "baseline:  %s to %s, %d cycles\n", baseFrom.Format(time.DateOnly), baseTo.Format(time.DateOnly), len(baseReports))
+	fmt.Fprintf(w, "candidate: %s to %s, %d cycles\n", candFrom.Format(time.DateOnly), candTo.Format(time.DateOnly), len(candReports))
+	fmt.Fprintf(w, "regression: median worse and Mann-Whitney p < %.2f\n\n", execution.RegressionAlpha)
+	if err := report.WriteText(w); err != nil {
+		return false, err
+	}
+	return report.Regressed(), nil
+}
+
+// parseDateRange parses "FROM,TO" as dates in loc.
+func parseDateRange(s string, loc *time.Location) (from, to time.Time, err error) {
+	fromStr, toStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return from, to, fmt.Errorf("want FROM,TO, got %q", s)
+	}
+	if from, err = time.ParseInLocation(time.DateOnly, strings.TrimSpace(fromStr), loc); err != nil {
+		return from, to, err
+	}
+	if to, err = time.ParseInLocation(time.DateOnly, strings.TrimSpace(toStr), loc); err != nil {
+		return from, to, err
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("range %q ends before it starts", s)
+	}
+	return from, to, nil
+}
+
+// runTimelineReport writes the post-incident timeline for window, "FROM,TO"
+// as RFC 3339 times or as dates in the trading timezone, TO exclusive.
+func runTimelineReport(cfg *config.Config, window string, w io.Writer, logger *slog.Logger) error {
+	loc, err := time.LoadLocation(cfg.System.Timezone)
+	if err != nil {
+		return fmt.Errorf("load timezone: %w", err)
+	}
+	from, to, err := parseTimeRange(window, loc)
+	if err != nil {
+		return fmt.Errorf("parse -timeline: %w", err)
+	}
+
+	store, err := persistence.NewSQLiteStore(cfg.Persistence.CheckpointDB, logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	events, err := store.Timeline(from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "timeline: %s to %s, %d events\n\n", from.Format(time.RFC3339), to.Format(time.RFC3339), len(events))
+	return persistence.WriteTimeline(w, events, loc)
+}
+
+// parseTimeRange parses "FROM,TO", each an RFC 3339 time or a date in loc.
+func parseTimeRange(s string, loc *time.Location) (from, to time.Time, err error) {
+	fromStr, toStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return from, to, fmt.Errorf("want FROM,TO, got %q", s)
+	}
+	parse := func(v string) (time.Time, error) {
+		v = strings.TrimSpace(v)
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		return time.ParseInLocation(time.DateOnly, v, loc)
+	}
+	if from, err = parse(fromStr); err != nil {
+		return from, to, err
+	}
+	if to, err = parse(toStr); err != nil {
+		return from, to, err
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("range %q ends before it starts", s)
+	}
+	return from, to, nil
+}
+
+// runBacktest replays the configured data or synthetic market, lets
+// in-flight cycles drain, and then writes the report to w, the report CSV
+// and, if pgStore is set, backtest_runs.
+func runBacktest(ctx context.Context, cfg *config.Config, mdService *marketdata.Service, recorder *backtest.Recorder,
+	pgStore *persistence.PostgresStore, w io.Writer, logger *slog.Logger) error {
+	var src backtest.EventSource
+	if syn := cfg.Backtest.Synthetic; syn.Enabled {
+		s, err := backtest.NewSyntheticSource(syn)
+		if err != nil {
+			return err
+		}
+		logger.Info("backtesting against a synthetic market", "seed", syn.Seed, "duration", syn.Duration(),
+			"instruments", len(syn.Instruments), "regimes", len(syn.Regimes))
+		src = s
+	} else {
+		s, err := backtest.OpenSource(cfg.Backtest.DataPath)
+		if err != nil {
+			return fmt.Errorf("open backtest data: %w", err)
+		}
+		defer s.Close()
+		src = s
+	}
+
+	replay, err := backtest.NewReplayer(src, mdService, cfg.Backtest.Speed, logger).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	logger.Info("backtest replay finished", "events", replay.Events, "to", replay.To)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(cfg.Backtest.DrainMs) * time.Millisecond):
+	}
+
+	report := recorder.Report(replay)
+	fmt.Fprintln(w)
+	if err := report.WriteText(w); err != nil {
+		return err
+	}
+
+	if path := cfg.Backtest.ReportCSV; path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create report csv: %w", err)
+		}
+		err = report.WriteCSV(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("write report csv: %w", err)
+		}
+		logger.Info("backtest report written", "path", path)
+	}
+
+	if pgStore != nil {
+		raw, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("marshal backtest report: %w", err)
+		}
+		if err := pgStore.WriteBacktestRun(&persistence.BacktestRun{
+			ID:          uuid.New(),
+			ConfigHash:  cfg.Hash(),
+			DataFrom:    report.From,
+			DataTo:      report.To,
+			Events:      report.Events,
+			Cycles:      len(report.Cycles),
+			HitRate:     report.HitRate(),
+			TotalPnL:    report.TotalPnL,
+			MaxDrawdown: report.MaxDrawdown,
+			Report:      raw,
+			CreatedAt:   time.Now(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runReplay republishes the configured data onto the bus with its original
+// relative timing, scaled by the replay speed, and returns once in-flight
+// cycles have had time to drain.
+func runReplay(ctx context.Context, cfg *config.Config, mdService *marketdata.Service, logger *slog.Logger) error {
+	src, err := backtest.OpenSource(cfg.Replay.DataPath)
+	if err != nil {
+		return fmt.Errorf("open replay data: %w", err)
+	}
+	defer src.Close()
+
+	replay, err := backtest.NewReplayer(src, mdService, cfg.Replay.Speed, logger).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	logger.Info("replay finished", "events", replay.Events, "from", replay.From, "to", replay.To)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(cfg.Replay.DrainMs) * time.Millisecond):
+	}
+	return nil
+}
+
+// recordStartupConfig stores the configuration this process runs with when
+// it differs from the last one recorded, naming the keys that changed.
+func recordStartupConfig(store *persistence.SQLiteStore, cfg *config.Config, logger *slog.Logger) {
+	last, err := store.LatestConfigChange()
+	if err != nil {
+		logger.Warn("failed to load last config change", "error", err)
+	}
+	if last != nil && last.Hash == cfg.Hash() {
+		return
+	}
+	var prev *config.Config
+	if last != nil {
+		var c config.Config
+		if err := json.Unmarshal(last.Config, &c); err == nil {
+			prev = &c
+		}
+	}
+	change, err := newConfigChange(prev, cfg, "startup")
+	if err == nil {
+		err = store.WriteConfigChange(change)
+	}
+	if err != nil {
+		logger.Warn("failed to record config change", "error", err)
+		return
+	}
+	logger.Info("configuration changed since last run", "config_hash", change.Hash, "changed_keys", change.Keys)
+}
+
+// newConfigChange describes cfg for the config change log, with the keys
+// that differ from prev when there is one.
+func newConfigChange(prev, cfg *config.Config, source string) (*persistence.ConfigChange, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	change := &persistence.ConfigChange{Hash: cfg.Hash(), Source: source, Config: data, ChangedAt: time.Now()}
+	if prev != nil {
+		change.Keys = config.ChangedKeys(prev, cfg)
+	}
+	return change, nil
+}
+
+// runOrderEventRecorder persists every order state change for post-incident
+// timelines.
+func runOrderEventRecorder(ctx context.Context, changes <-chan domain.OrderStateChange, writer *persistence.AsyncWriter) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			writer.Write(persistence.WriteRequest{Type: persistence.WriteTypeOrderEvent, Payload: &change})
+		}
+	}
+}
+
+// runReportRecorder persists every execution report for later latency and
+// slippage comparisons.
+func runReportRecorder(ctx context.Context, reports <-chan domain.ExecutionReport, writer *persistence.AsyncWriter) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report, ok := <-reports:
+			if !ok {
+				return
+			}
+			writer.Write(persistence.WriteRequest{Type: persistence.WriteTypeCycle, Payload: report})
+		}
+	}
+}
+
+// runCycleActivityFeed counts execution cycles, their fills and fees
+// towards the risk manager's daily PnL snapshot.
+func runCycleActivityFeed(ctx context.Context, reports <-chan domain.ExecutionReport, riskMgr *risk.Manager) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report, ok := <-reports:
+			if !ok {
+				return
+			}
+			riskMgr.OnExecutionReport(report)
+		}
+	}
+}
+
+// runDivergenceFeed adds live execution reports to the shadow divergence
+// monitor.
+func runDivergenceFeed(ctx context.Context, reports <-chan domain.ExecutionReport, divergence *execution.DivergenceMonitor, metrics *monitor.Metrics) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report, ok := <-reports:
+			if !ok {
+				return
+			}
+			setDivergenceGauge(metrics, divergence.RecordLive(report))
+		}
+	}
+}
+
+// setDivergenceGauge exports a strategy's divergence once both shadow and
+// live cycles have been seen for it.
+func setDivergenceGauge(metrics *monitor.Metrics, d execution.Divergence) {
+	if d.ShadowSamples > 0 && d.LiveSamples > 0 {
+		metrics.ShadowDivergenceBps.WithLabelValues(string(d.Strategy)).Set(d.DivergenceBps)
+	}
+}
+
+// runOrderStateFeed hands order state changes to the risk manager, which
+// keeps open order counts and the error budget's order SLIs from them, and
+// records how orders ended for the cost model's fill rates.
+func runOrderStateFeed(ctx context.Context, changes <-chan domain.OrderStateChange, riskMgr *risk.Manager, costSvc *costmodel.Service) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			riskMgr.OnOrderStateChange(change)
+
+			// Rejects and failed submits say nothing about liquidity.
+			if !change.PrevStatus.IsTerminal() {
+				switch change.NewStatus {
+				case domain.OrderStatusFilled:
+					costSvc.RecordFillOutcome(change.Order.Venue, change.Order.Symbol, true)
+				case domain.OrderStatusCancelled:
+					costSvc.RecordFillOutcome(change.Order.Venue, change.Order.Symbol, false)
+				}
+			}
+		}
+	}
+}
+
+// runRateLimitGauges publishes each venue's remaining request budget per
+// endpoint category every interval.
+func runRateLimitGauges(ctx context.Context, gateways map[string]gateway.VenueGateway, metrics *monitor.Metrics, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for venue, gw := range gateways {
+				statuses, err := gw.GetRateLimitStatus(ctx)
+				if err != nil {
+					continue
+				}
+				for _, st := range statuses {
+					metrics.VenueRateLimitRemaining.WithLabelValues(venue, string(st.Category)).Set(st.Available(time.Now()))
+				}
+			}
+		}
+	}
+}
+
+// runRestingMatchers starts matching resting orders against the live books
+// and trades on bus for every simulated gateway in gateways, looking through wrappers
+// such as the metered one. Live gateways are left alone.
+func runRestingMatchers(ctx context.Context, gateways map[string]gateway.VenueGateway, bus *eventbus.EventBus) {
+	for _, gw := range gateways {
+		for gw != nil {
+			if m, ok := gw.(simulated.RestingMatcher); ok {
+				go m.RunMatching(ctx, bus.SubscribeOrderBook(), bus.SubscribeTrade())
+				break
+			}
+			w, ok := gw.(interface{ Inner() gateway.VenueGateway })
+			if !ok {
+				break
+			}
+			gw = w.Inner()
+		}
+	}
+}
+
+// runFundingAccrual starts settling funding on the perp positions of the
+// gateways that simulate fills, at the funding rates on bus; pay receives
+// each payment.
+func runFundingAccrual(ctx context.Context, gateways map[string]gateway.VenueGateway, bus *eventbus.EventBus, pay func(domain.AccountActivity)) {
+	for _, gw := range gateways {
+		for gw != nil {
+			if a, ok := gw.(simulated.FundingAccruer); ok {
+				go a.RunFunding(ctx, bus.SubscribeFundingRate(), pay)
+				break
+			}
+			w, ok := gw.(interface{ Inner() gateway.VenueGateway })
+			if !ok {
+				break
+			}
+			gw = w.Inner()
+		}
+	}
+}
+
+// seedDryRunAccounts starts every dry-run venue from a snapshot of its live
+// account. A venue without a configured margin collateral is margined
+// against the USDT it holds. Call before runFundingAccrual and
+// runMarginChecks.
+func seedDryRunAccounts(ctx context.Context, gateways map[string]gateway.VenueGateway, cfg config.DryRunConfig, logger *slog.Logger) error {
+	for name, gw := range gateways {
+		for gw != nil {
+			if w, ok := gw.(*dryrun.Wrapper); ok {
+				if err := w.SeedFromLive(ctx); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				usdt, _ := w.SeededCollateral()
+				if cfg.Margin.Enabled && cfg.Margin.CollateralUSDT.IsZero() {
+					model := marginModel(cfg)
+					model.Collateral = usdt
+					w.SetMargin(model)
+				}
+				logger.Info("dry-run account seeded from live", "venue", name, "usdt", usdt.String())
+				break
+			}
+			w, ok := gw.(interface{ Inner() gateway.VenueGateway })
+			if !ok {
+				break
+			}
+			gw = w.Inner()
+		}
+	}
+	return nil
+}
+
+// runMarginChecks starts margining the perp positions of the gateways that
+// simulate fills; liquidated receives each simulated liquidation.
+func runMarginChecks(ctx context.Context, gateways map[string]gateway.VenueGateway, liquidated func(simulated.Liquidation)) {
+	for _, gw := range gateways {
+		for gw != nil {
+			if m, ok := gw.(simulated.MarginSimulator); ok {
+				go m.RunMargin(ctx, liquidated)
+				break
+			}
+			w, ok := gw.(interface{ Inner() gateway.VenueGateway })
+			if !ok {
+				break
+			}
+			gw = w.Inner()
+		}
+	}
+}
+
+// refreshInstruments loads every venue's instrument rules into reg. A venue
+// that fails keeps its previous rules; one that cannot report them is left
+// unrounded. Symbols the venue has suspended or stopped listing are passed
+// to onUnavailable.
+func refreshInstruments(ctx context.Context, gateways map[string]gateway.VenueGateway, reg *domain.InstrumentRegistry, onUnavailable func(venue, symbol, reason string), logger *slog.Logger) {
+	for venue, gw := range gateways {
+		instruments, err := gw.GetInstruments(ctx)
+		if errors.Is(err, gateway.ErrInstrumentsUnsupported) {
+			continue
+		}
+		if err != nil {
+			logger.Error("failed to load instruments", "venue", venue, "error", err)
+			continue
+		}
+		// An empty list is more likely a venue glitch than every symbol
+		// delisted at once.
+		if len(instruments) > 0 {
+			for _, symbol := range reg.Unavailable(venue, instruments) {
+				onUnavailable(venue, symbol, "suspended or delisted by the venue")
+			}
+		}
+		reg.Set(venue, instruments)
+		logger.Debug("instruments loaded", "venue", venue, "count", len(instruments))
+	}
+}
+
+// runInstrumentRefresher reloads instrument rules every interval, since
+// venues change tick sizes and minimums without notice.
+func runInstrumentRefresher(ctx context.Context, gateways map[string]gateway.VenueGateway, reg *domain.InstrumentRegistry, interval time.Duration, onUnavailable func(venue, symbol, reason string), logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshInstruments(ctx, gateways, reg, onUnavailable, logger)
+		}
+	}
+}
+
+// runCancelOnDisconnect arms the venue-side cancel-on-disconnect timer on
+// every gateway that has one and keeps re-arming it, so the venue cancels the
+// trader's orders by itself if it stops hearing from us. Venues cap the
+// timeout they accept, so it is re-armed at least every 10s regardless of
+// timeout. Venues without one rely on the health monitor's sweep.
+func runCancelOnDisconnect(ctx context.Context, gateways map[string]gateway.VenueGateway, timeout time.Duration, logger *slog.Logger) {
+	armers := make(map[string]gateway.CancelOnDisconnectArmer)
+	for venue, gw := range gateways {
+		if a, ok := gw.(gateway.CancelOnDisconnectArmer); ok {
+			armers[venue] = a
+		}
+	}
+
+	arm := func() {
+		for venue, a := range armers {
+			err := a.ArmCancelOnDisconnect(ctx, timeout)
+			switch {
+			case errors.Is(err, gateway.ErrCancelOnDisconnectUnsupported):
+				delete(armers, venue)
+			case err != nil:
+				logger.Warn("failed to arm cancel on disconnect", "venue", venue, "error", err)
+			}
+		}
+	}
+
+	arm()
+	if len(armers) == 0 {
+		return
+	}
+	ticker := time.NewTicker(min(timeout, 30*time.Second) / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			arm()
+		}
+	}
+}
+
+func runCheckpointer(ctx context.Context, riskMgr *risk.Manager, writer *persistence.AsyncWriter, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			state := riskMgr.GetCheckpointState()
+			writer.Write(persistence.WriteRequest{
+				Type:    persistence.WriteTypeRiskCheckpoint,
+				Payload: state,
+			})
+			logger.Debug("risk state checkpointed")
+		}
+	}
+}
+
+// warmStartFunding passes apply the funding rate updates recorded since then
+// for each symbol on each venue, oldest first, so a lookback over funding
+// history does not start empty after a restart.
+func warmStartFunding(ctx context.Context, store *persistence.PostgresStore, venues, symbols []string, since time.Time, apply func(domain.FundingRate), logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	loaded := 0
+	for _, venue := range venues {
+		for _, symbol := range symbols {
+			rates, err := store.GetFundingHistory(ctx, venue, symbol, since)
+			if err != nil {
+				logger.Warn("failed to load funding history", "venue", venue, "symbol", symbol, "error", err)
+				continue
+			}
+			for _, rate := range rates {
+				apply(rate)
+			}
+			loaded += len(rates)
+		}
+	}
+	logger.Info("funding history loaded", "rates", loaded, "since", since)
+}
+
+// runCostFundingFeed records live funding rate updates in the cost model,
+// which estimates funding cost from them.
+func runCostFundingFeed(ctx context.Context, rates <-chan domain.FundingRate, costSvc *costmodel.Service) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rate, ok := <-rates:
+			if !ok {
+				return
+			}
+			costSvc.AddFundingRate(rate.Venue, rate.Symbol, rate)
+		}
+	}
+}
+
+// runFundingRecorder writes the funding rate updates on rates to the cold
+// store, at most one per feed every minInterval.
+func runFundingRecorder(ctx context.Context, rates <-chan domain.FundingRate, writer *persistence.AsyncWriter, minInterval time.Duration) {
+	written := make(map[string]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rate, ok := <-rates:
+			if !ok {
+				return
+			}
+			key := rate.Venue + ":" + rate.Symbol
+			if last, seen := written[key]; seen && rate.Timestamp.Sub(last) < minInterval {
+				continue
+			}
+			written[key] = rate.Timestamp
+			writer.Write(persistence.WriteRequest{Type: persistence.WriteTypeFundingRate, Payload: rate})
+		}
+	}
+}
+
+// restoreMarketSnapshot loads the books and funding rates saved by the last
+// run into mdService, unless they were saved more than maxAge ago.
+func restoreMarketSnapshot(store *persistence.SQLiteStore, mdService *marketdata.Service, maxAge time.Duration, logger *slog.Logger) {
+	snap, err := store.LoadMarketSnapshot()
+	if err != nil {
+		logger.Warn("failed to load market snapshot, starting without it", "error", err)
+		return
+	}
+	if snap == nil {
+		return
+	}
+	if age := time.Since(snap.SavedAt); age > maxAge {
+		logger.Info("market snapshot too old to restore", "saved_at", snap.SavedAt, "age", age.Round(time.Second))
+		return
+	}
+	mdService.Restore(snap.Books, snap.Funding)
+	logger.Info("market snapshot restored, stale until feeds refresh",
+		"books", len(snap.Books), "funding_rates", len(snap.Funding), "saved_at", snap.SavedAt)
+}
+
+// runMarketSnapshotter saves the latest books and funding rates every
+// interval until ctx is done. Shutdown saves them once more.
+func runMarketSnapshotter(ctx context.Context, store *persistence.SQLiteStore, mdService *marketdata.Service, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			saveMarketSnapshot(store, mdService, logger)
+		}
+	}
+}
+
+func saveMarketSnapshot(store *persistence.SQLiteStore, mdService *marketdata.Service, logger *slog.Logger) {
+	books, funding := mdService.Snapshot()
+	if len(books) == 0 && len(funding) == 0 {
+		return
+	}
+	snap := persistence.MarketSnapshot{Books: books, Funding: funding, SavedAt: time.Now()}
+	if err := store.SaveMarketSnapshot(snap); err != nil {
+		logger.Error("failed to save market snapshot", "error", err)
+		return
+	}
+	logger.Debug("market snapshot saved", "books", len(books), "funding_rates", len(funding))
+}
+
+// runNightlyStressReport runs the default stress scenarios once a day at the
+// given hour in loc, persists the report and raises a P2 alert on any breach.
+func runNightlyStressReport(ctx context.Context, riskMgr *risk.Manager, writer *persistence.AsyncWriter, alertMgr *monitor.AlertManager, hour int, loc *time.Location, logger *slog.Logger) {
+	for {
+		now := time.Now().In(loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, loc)
+		if !next.After(now) {
+			next = time.Date(now.Year(), now.Month(), now.Day()+1, hour, 0, 0, 0, loc)
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		report := riskMgr.RunStressTest(nil)
+		writer.Write(persistence.WriteRequest{
+			Type:    persistence.WriteTypeStressReport,
+			Payload: report,
+		})
+
+		for _, res := range report.Results {
+			logger.Info("stress scenario",
+				"scenario", res.Scenario.Name,
+				"projected_pnl", res.ProjectedPnL.String(),
+				"projected_mode", res.ProjectedMode,
+				"breaches", len(res.Breaches))
+			if len(res.Breaches) > 0 {
+				alertMgr.Fire(monitor.AlertLevelP2, "stress_limit_breach",
+					fmt.Sprintf("stress scenario %s breaches %d limit(s)", res.Scenario.Name, len(res.Breaches)),
+					fmt.Sprintf("Projected PnL %s, projected mode %s", res.ProjectedPnL.String(), res.ProjectedMode))
+			}
+		}
+	}
+}
+
+// runDailyRollover closes the trading day at each midnight in loc: it
+// snapshots the day's PnL into daily_pnl and resets the risk and portfolio
+// trackers together, then reports which risk limits rejected signals during
+// the day.
+func runDailyRollover(ctx context.Context, riskMgr *risk.Manager, portfolioMgr *portfolio.Manager, writer *persistence.AsyncWriter, store *persistence.SQLiteStore, loc *time.Location, logger *slog.Logger) {
+	for {
+		// AddDate keeps this on local midnight across DST changes.
+		next := domain.TradingDayStart(time.Now(), loc).AddDate(0, 0, 1)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		snap := riskMgr.RolloverDay(next, portfolioMgr)
+		writer.Write(persistence.WriteRequest{
+			Type:    persistence.WriteTypePnL,
+			Payload: &snap,
+		})
+		logger.Info("trading day closed",
+			"date", snap.Date.Format("2006-01-02"),
+			"total_pnl", snap.TotalPnL.String())
+		logBreachReport(store, next.AddDate(0, 0, -1), next, loc, logger)
+	}
+}
+
+// logBreachReport logs, per risk limit, how often it rejected signals within
+// [since, until), in which hour most often and the edge those signals
+// expected. Rejections still queued for the writer may be missed.
+func logBreachReport(store *persistence.SQLiteStore, since, until time.Time, loc *time.Location, logger *slog.Logger) {
+	rejections, err := store.ListRiskRejections(since, until)
+	if err != nil {
+		logger.Error("failed to load risk rejections", "error", err)
+		return
+	}
+	report := risk.BuildBreachReport(rejections, since, until, loc)
+	logger.Info("risk limit breaches",
+		"date", since.Format("2006-01-02"),
+		"rejections", report.Total)
+	for _, lb := range report.Limits {
+		logger.Info("risk limit breaches",
+			"date", since.Format("2006-01-02"),
+			"limit", lb.Reason,
+			"count", lb.Count,
+			"peak_hour", lb.PeakHour,
+			"peak_hour_count", lb.ByHour[lb.PeakHour],
+			"forgone_edge_usdt", lb.ForgoneEdgeUSDT.StringFixed(2),
+			"by_venue", lb.ByVenue)
+	}
+}
+
+// runShadowExecution starts an execution engine of its own that validates
+// signals with the live risk manager but fills them against simulated
+// gateways, and returns the function that submits a signal to it. Its
+// orders and reports stay on a separate event bus, so they never touch live
+// positions, the order limits or persistence. recordShadow, if set, is
+// handed every shadow execution report.
+func runShadowExecution(
+	ctx context.Context,
+	cfg *config.Config,
+	gateways map[string]gateway.VenueGateway,
+	mdService *marketdata.Service,
+	liveBus *eventbus.EventBus,
+	riskMgr *risk.Manager,
+	instruments *domain.InstrumentRegistry,
+	recordShadow func(domain.ExecutionReport),
+	logger *slog.Logger,
+) func(domain.TradeSignal) {
+	shadowLogger := logger.With("execution", "shadow")
+	shadowBus := eventbus.New(256, shadowLogger)
+
+	shadowGateways := make(map[string]gateway.VenueGateway, len(gateways))
+	for name, gw := range gateways {
+		shadowGateways[name] = dryrun.NewWrapper(gw, newFillSimulator(cfg.DryRun, name, mdService), mdService, shadowLogger)
+	}
+
+	orderMgr := order.NewManager(shadowGateways, shadowBus, shadowLogger)
+	orderMgr.SetInstruments(instruments)
+	engine := execution.NewEngine(
+		orderMgr,
+		riskMgr,
+		shadowBus,
+		cfg.Strategies.TriangularArb.FillTimeout(),
+		cfg.Strategies.BasisArb.FillTimeout(),
+		cfg.Strategies.TriangularArb.MaxRetries,
+		shadowLogger,
+	)
+	reports := shadowBus.SubscribeExecutionReport()
+
+	go engine.Run(ctx)
+	go orderMgr.RunOrderUpdates(ctx)
+	runRestingMatchers(ctx, shadowGateways, liveBus)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case report := <-reports:
+				shadowLogger.Info("shadow execution finished",
+					"signal_id", report.SignalID,
+					"status", report.Status,
+					"expected_edge_bps", report.ExpectedEdgeBps.String(),
+					"realized_edge_bps", report.RealizedEdgeBps.String())
+				if recordShadow != nil {
+					recordShadow(report)
+				}
+			}
+		}
+	}()
+
+	return shadowBus.PublishSignal
+}
+
+func newMetricsServer(checkpoints admin.CheckpointStore, stress admin.StressRunner, intake *admin.SignalIntake, health admin.HealthReporter, preview admin.SignalPreviewer, latency admin.LatencyReporter, info admin.InstanceInfo, books admin.BookReader, orders admin.ActiveOrderLister, bookSample time.Duration, logger *slog.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", monitor.MetricsHandler())
+	admin.RegisterCheckpointRoutes(mux, checkpoints, logger)
+	admin.RegisterStressRoutes(mux, stress, logger)
+	if intake != nil {
+		admin.RegisterSignalRoutes(mux, *intake, logger)
+	}
+	admin.RegisterPreviewRoutes(mux, preview, info.Venues, logger)
+	admin.RegisterHealthRoutes(mux, health)
+	admin.RegisterLatencyRoutes(mux, latency)
+	admin.RegisterInfoRoutes(mux, info)
+	admin.RegisterBookRoutes(mux, books, orders, bookSample)
+
+	return &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// listenMetrics opens the metrics server's listener, wrapped in TLS when a
+// certificate is configured. It runs before anything else starts so a bad
+// address or certificate stops the trader before it connects to any venue.
+func listenMetrics(cfg config.MetricsConfig) (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	return ln, nil
+}
+
+// serveMetrics serves srv on ln until ctx is cancelled, then shuts it down,
+// giving in-flight requests up to 5s to finish.
+func serveMetrics(ctx context.Context, srv *http.Server, ln net.Listener, logger *slog.Logger) {
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server error", "error", err)
+		}
+		return
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to shut down metrics server", "error", err)
 	}
 }
@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+)
+
+// RequireBasicAuth wraps next so that requests must carry HTTP basic
+// credentials matching user and password, except requests for openPaths.
+// Those are left open for orchestrator probes such as /health and /ready.
+func RequireBasicAuth(next http.Handler, user, password string, openPaths ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(openPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		u, p, ok := r.BasicAuth()
+		userOK := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+		if !ok || !userOK || !passOK {
+			w.Header().Set("WWW-Authenticate", `Basic realm="trader", charset="UTF-8"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireBasicAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := RequireBasicAuth(ok, "ops", "s3cret", "/health")
+
+	tests := []struct {
+		name       string
+		path       string
+		user, pass string
+		want       int
+	}{
+		{"open path", "/health", "", "", http.StatusOK},
+		{"no credentials", "/metrics", "", "", http.StatusUnauthorized},
+		{"wrong password", "/metrics", "ops", "nope", http.StatusUnauthorized},
+		{"wrong user", "/metrics", "root", "s3cret", http.StatusUnauthorized},
+		{"valid", "/metrics", "ops", "s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("got %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
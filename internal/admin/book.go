@@ -0,0 +1,159 @@
+package admin
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// bookViewLevels is how many levels per side the book view shows.
+const bookViewLevels = 20
+
+// BookReader returns a copy of a venue's book for a symbol.
+type BookReader interface {
+	GetBook(venue, symbol string) (*domain.OrderBookSnapshot, bool)
+}
+
+// ActiveOrderLister lists the orders that are not yet terminal.
+type ActiveOrderLister interface {
+	GetActiveOrders() []domain.Order
+}
+
+// BookViewLevel is one price level of the book view. OwnSize is how much of
+// the level's size is our resting orders.
+type BookViewLevel struct {
+	Price   decimal.Decimal `json:"price"`
+	Size    decimal.Decimal `json:"size"`
+	OwnSize decimal.Decimal `json:"own_size"`
+}
+
+// RestingOrder is one of our limit orders resting on the book.
+type RestingOrder struct {
+	InternalID uuid.UUID          `json:"internal_id"`
+	Side       domain.Side        `json:"side"`
+	Price      decimal.Decimal    `json:"price"`
+	Remaining  decimal.Decimal    `json:"remaining"`
+	Status     domain.OrderStatus `json:"status"`
+}
+
+// BookView is a sampled order book with our resting orders marked on it.
+// Orders lists every resting order of ours on the symbol, including those
+// priced outside the levels shown.
+type BookView struct {
+	Venue          string          `json:"venue"`
+	Symbol         string          `json:"symbol"`
+	Bids           []BookViewLevel `json:"bids"`
+	Asks           []BookViewLevel `json:"asks"`
+	Orders         []RestingOrder  `json:"orders"`
+	Sequence       uint64          `json:"sequence"`
+	VenueTimestamp time.Time       `json:"venue_timestamp"`
+	SampledAt      time.Time       `json:"sampled_at"`
+}
+
+// RegisterBookRoutes adds the order book view to mux:
+//
+//	GET /admin/book?venue=nobitex&symbol=BTC/USDT    top 20 levels per side with our resting orders
+//
+// A book is sampled at most once per interval and the sample is served to
+// every request in between, so a dashboard polling fast does not contend
+// with the market data feed.
+func RegisterBookRoutes(mux *http.ServeMux, books BookReader, orders ActiveOrderLister, interval time.Duration) {
+	h := &bookHandler{
+		books:    books,
+		orders:   orders,
+		interval: interval,
+		samples:  make(map[string]*BookView),
+		now:      time.Now,
+	}
+	mux.HandleFunc("GET /admin/book", h.get)
+}
+
+type bookHandler struct {
+	books    BookReader
+	orders   ActiveOrderLister
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples map[string]*BookView // key: "venue:symbol"
+	now     func() time.Time
+}
+
+func (h *bookHandler) get(w http.ResponseWriter, r *http.Request) {
+	venue, symbol := r.URL.Query().Get("venue"), r.URL.Query().Get("symbol")
+	if venue == "" || symbol == "" {
+		writeError(w, http.StatusBadRequest, "venue and symbol are required")
+		return
+	}
+	view, ok := h.sample(venue, symbol)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no book for "+symbol+" on "+venue)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// sample returns the cached view of the book if it is younger than the
+// interval, and takes a new one otherwise.
+func (h *bookHandler) sample(venue, symbol string) (*BookView, bool) {
+	key := venue + ":" + symbol
+	now := h.now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if view, ok := h.samples[key]; ok && now.Sub(view.SampledAt) < h.interval {
+		return view, true
+	}
+
+	book, ok := h.books.GetBook(venue, symbol)
+	if !ok {
+		return nil, false
+	}
+	view := &BookView{
+		Venue:          venue,
+		Symbol:         symbol,
+		Orders:         []RestingOrder{},
+		Sequence:       book.Sequence,
+		VenueTimestamp: book.VenueTimestamp,
+		SampledAt:      now,
+	}
+	own := map[domain.Side]map[string]decimal.Decimal{
+		domain.SideBuy:  {},
+		domain.SideSell: {},
+	}
+	for _, o := range h.orders.GetActiveOrders() {
+		if o.Venue != venue || o.Symbol != symbol || o.OrderType != domain.OrderTypeLimit {
+			continue
+		}
+		remaining := o.Size.Sub(o.FilledSize)
+		view.Orders = append(view.Orders, RestingOrder{
+			InternalID: o.InternalID,
+			Side:       o.Side,
+			Price:      o.Price,
+			Remaining:  remaining,
+			Status:     o.Status,
+		})
+		if levels, ok := own[o.Side]; ok {
+			levels[o.Price.String()] = levels[o.Price.String()].Add(remaining)
+		}
+	}
+	view.Bids = viewLevels(book.Bids, own[domain.SideBuy])
+	view.Asks = viewLevels(book.Asks, own[domain.SideSell])
+	h.samples[key] = view
+	return view, true
+}
+
+func viewLevels(levels []domain.PriceLevel, own map[string]decimal.Decimal) []BookViewLevel {
+	if len(levels) > bookViewLevels {
+		levels = levels[:bookViewLevels]
+	}
+	out := make([]BookViewLevel, len(levels))
+	for i, level := range levels {
+		out[i] = BookViewLevel{Price: level.Price, Size: level.Size, OwnSize: own[level.Price.String()]}
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+type stubBooks struct {
+	book  *domain.OrderBookSnapshot
+	reads int
+}
+
+func (s *stubBooks) GetBook(venue, symbol string) (*domain.OrderBookSnapshot, bool) {
+	if s.book == nil || s.book.Venue != venue || s.book.Symbol != symbol {
+		return nil, false
+	}
+	s.reads++
+	snap := *s.book
+	return &snap, true
+}
+
+type stubOrders []domain.Order
+
+func (s stubOrders) GetActiveOrders() []domain.Order { return s }
+
+func TestBookRoute(t *testing.T) {
+	book := &domain.OrderBookSnapshot{Venue: "nobitex", Symbol: "BTC/USDT", Sequence: 7}
+	for i := 0; i < 25; i++ {
+		book.Bids = append(book.Bids, domain.PriceLevel{Price: decimal.NewFromInt(int64(50000 - i)), Size: decimal.NewFromInt(1)})
+		book.Asks = append(book.Asks, domain.PriceLevel{Price: decimal.NewFromInt(int64(50001 + i)), Size: decimal.NewFromInt(1)})
+	}
+	books := &stubBooks{book: book}
+	orders := stubOrders{
+		{InternalID: uuid.New(), Venue: "nobitex", Symbol: "BTC/USDT", Side: domain.SideBuy, OrderType: domain.OrderTypeLimit,
+			Price: decimal.NewFromInt(49998), Size: decimal.RequireFromString("0.5"), FilledSize: decimal.RequireFromString("0.2"), Status: domain.OrderStatusPartialFill},
+		{InternalID: uuid.New(), Venue: "nobitex", Symbol: "BTC/USDT", Side: domain.SideSell, OrderType: domain.OrderTypeLimit,
+			Price: decimal.NewFromInt(51000), Size: decimal.NewFromInt(1), Status: domain.OrderStatusAcknowledged},
+		{InternalID: uuid.New(), Venue: "kcex", Symbol: "BTC/USDT", Side: domain.SideBuy, OrderType: domain.OrderTypeLimit,
+			Price: decimal.NewFromInt(49998), Size: decimal.NewFromInt(1), Status: domain.OrderStatusAcknowledged},
+	}
+	mux := http.NewServeMux()
+	RegisterBookRoutes(mux, books, orders, time.Minute)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/book?"+query, nil))
+		return rec
+	}
+
+	rec := get("venue=nobitex&symbol=BTC/USDT")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", rec.Code)
+	}
+	var view BookView
+	if err := json.NewDecoder(rec.Body).Decode(&view); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(view.Bids) != 20 || len(view.Asks) != 20 || view.Sequence != 7 {
+		t.Fatalf("expected 20 levels a side at sequence 7, got %d/%d at %d", len(view.Bids), len(view.Asks), view.Sequence)
+	}
+	if !view.Bids[2].OwnSize.Equal(decimal.RequireFromString("0.3")) || !view.Bids[0].OwnSize.IsZero() {
+		t.Errorf("expected 0.3 of ours at 49998 only, got %+v", view.Bids[:3])
+	}
+	if len(view.Orders) != 2 {
+		t.Errorf("expected our 2 resting orders on nobitex, got %+v", view.Orders)
+	}
+
+	// Served from the sample until the interval passes.
+	get("venue=nobitex&symbol=BTC/USDT")
+	if books.reads != 1 {
+		t.Errorf("expected one book read within the interval, got %d", books.reads)
+	}
+
+	if rec := get("venue=nobitex&symbol=ETH/USDT"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown book: got %d, want 404", rec.Code)
+	}
+	if rec := get("venue=nobitex"); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing symbol: got %d, want 400", rec.Code)
+	}
+}
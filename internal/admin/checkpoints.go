@@ -0,0 +1,287 @@
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/persistence"
+)
+
+const (
+	defaultCheckpointLimit  = 100
+	maxCheckpointLimit      = 1000
+	defaultCheckpointWindow = 24 * time.Hour
+)
+
+// CheckpointStore is the read side of the risk checkpoint history.
+type CheckpointStore interface {
+	ListCheckpoints(since, until time.Time, limit int) ([]persistence.CheckpointRecord, error)
+	GetCheckpoint(id int64) (*persistence.CheckpointRecord, error)
+}
+
+// CheckpointSummary is one row of the checkpoint listing.
+type CheckpointSummary struct {
+	ID                 int64           `json:"id"`
+	CreatedAt          time.Time       `json:"created_at"`
+	Mode               domain.RiskMode `json:"mode"`
+	DailyRealizedPnL   decimal.Decimal `json:"daily_realized_pnl"`
+	DailyUnrealizedPnL decimal.Decimal `json:"daily_unrealized_pnl"`
+	OpenOrders         int             `json:"open_orders"`
+	Positions          int             `json:"positions"`
+	KillSwitchActive   bool            `json:"kill_switch_active"`
+}
+
+// PositionDiff is the change in one venue/account/asset position between
+// checkpoints. Account is empty for the venue's default account.
+type PositionDiff struct {
+	Venue    string          `json:"venue"`
+	Account  string          `json:"account,omitempty"`
+	Asset    string          `json:"asset"`
+	FromSize decimal.Decimal `json:"from_size"`
+	ToSize   decimal.Decimal `json:"to_size"`
+	Delta    decimal.Decimal `json:"delta"`
+}
+
+// CountDiff is the change in an open-order counter between checkpoints.
+type CountDiff struct {
+	Key   string `json:"key"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Delta int    `json:"delta"`
+}
+
+// CheckpointDiff describes what changed between two checkpoints. Only
+// entries that differ are included.
+type CheckpointDiff struct {
+	From                 CheckpointSummary `json:"from"`
+	To                   CheckpointSummary `json:"to"`
+	ModeChanged          bool              `json:"mode_changed"`
+	RealizedPnLDelta     decimal.Decimal   `json:"realized_pnl_delta"`
+	UnrealizedPnLDelta   decimal.Decimal   `json:"unrealized_pnl_delta"`
+	Positions            []PositionDiff    `json:"positions"`
+	OrderCountsPerVenue  []CountDiff       `json:"order_counts_per_venue"`
+	OrderCountsPerSymbol []CountDiff       `json:"order_counts_per_symbol"`
+	GlobalOrderDelta     int               `json:"global_order_delta"`
+}
+
+// RegisterCheckpointRoutes adds the checkpoint browser endpoints to mux:
+//
+//	GET /admin/checkpoints?since=RFC3339&until=RFC3339&limit=N
+//	GET /admin/checkpoints/{id}
+//	GET /admin/checkpoints/diff?from=ID&to=ID
+func RegisterCheckpointRoutes(mux *http.ServeMux, store CheckpointStore, logger *slog.Logger) {
+	h := &checkpointHandler{store: store, logger: logger}
+	mux.HandleFunc("GET /admin/checkpoints", h.list)
+	mux.HandleFunc("GET /admin/checkpoints/diff", h.diff)
+	mux.HandleFunc("GET /admin/checkpoints/{id}", h.get)
+}
+
+type checkpointHandler struct {
+	store  CheckpointStore
+	logger *slog.Logger
+}
+
+func (h *checkpointHandler) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	until := time.Now()
+	if v := q.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid until: "+err.Error())
+			return
+		}
+		until = t
+	}
+	since := until.Add(-defaultCheckpointWindow)
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+			return
+		}
+		since = t
+	}
+
+	limit := defaultCheckpointLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, maxCheckpointLimit)
+	}
+
+	records, err := h.store.ListCheckpoints(since, until, limit)
+	if err != nil {
+		h.logger.Error("failed to list risk checkpoints", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list checkpoints")
+		return
+	}
+
+	summaries := make([]CheckpointSummary, 0, len(records))
+	for _, rec := range records {
+		summaries = append(summaries, summarize(rec))
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (h *checkpointHandler) get(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.load(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	state, err := domain.EncodeRiskState(rec.State)
+	if err != nil {
+		h.logger.Error("failed to encode risk checkpoint", "id", rec.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to encode checkpoint")
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		CheckpointSummary
+		State json.RawMessage `json:"state"`
+	}{
+		CheckpointSummary: summarize(*rec),
+		State:             state,
+	})
+}
+
+func (h *checkpointHandler) diff(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, ok := h.load(w, q.Get("from"))
+	if !ok {
+		return
+	}
+	to, ok := h.load(w, q.Get("to"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, DiffCheckpoints(*from, *to))
+}
+
+// load fetches a checkpoint by its string ID, writing an error response on failure.
+func (h *checkpointHandler) load(w http.ResponseWriter, rawID string) (*persistence.CheckpointRecord, bool) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid checkpoint id "+strconv.Quote(rawID))
+		return nil, false
+	}
+	rec, err := h.store.GetCheckpoint(id)
+	if err != nil {
+		h.logger.Error("failed to load risk checkpoint", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to load checkpoint")
+		return nil, false
+	}
+	if rec == nil {
+		writeError(w, http.StatusNotFound, "checkpoint not found")
+		return nil, false
+	}
+	return rec, true
+}
+
+func summarize(rec persistence.CheckpointRecord) CheckpointSummary {
+	s := rec.State
+	return CheckpointSummary{
+		ID:                 rec.ID,
+		CreatedAt:          rec.CreatedAt,
+		Mode:               s.Mode,
+		DailyRealizedPnL:   s.DailyRealizedPnL,
+		DailyUnrealizedPnL: s.DailyUnrealizedPnL,
+		OpenOrders:         s.OpenOrderCounts.Global,
+		Positions:          len(s.Positions),
+		KillSwitchActive:   s.KillSwitchActive,
+	}
+}
+
+// DiffCheckpoints compares two checkpoints' positions, order counts and PnL.
+func DiffCheckpoints(from, to persistence.CheckpointRecord) *CheckpointDiff {
+	a, b := from.State, to.State
+	d := &CheckpointDiff{
+		From:               summarize(from),
+		To:                 summarize(to),
+		ModeChanged:        a.Mode != b.Mode,
+		RealizedPnLDelta:   b.DailyRealizedPnL.Sub(a.DailyRealizedPnL),
+		UnrealizedPnLDelta: b.DailyUnrealizedPnL.Sub(a.DailyUnrealizedPnL),
+		GlobalOrderDelta:   b.OpenOrderCounts.Global - a.OpenOrderCounts.Global,
+	}
+
+	keys := make(map[domain.VenueAssetKey]struct{})
+	for k := range a.Positions {
+		keys[k] = struct{}{}
+	}
+	for k := range b.Positions {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		fromSize, toSize := positionSize(a, k), positionSize(b, k)
+		if fromSize.Equal(toSize) {
+			continue
+		}
+		d.Positions = append(d.Positions, PositionDiff{
+			Venue:    k.Venue,
+			Account:  k.Account,
+			Asset:    k.Asset,
+			FromSize: fromSize,
+			ToSize:   toSize,
+			Delta:    toSize.Sub(fromSize),
+		})
+	}
+	sort.Slice(d.Positions, func(i, j int) bool {
+		if d.Positions[i].Venue != d.Positions[j].Venue {
+			return d.Positions[i].Venue < d.Positions[j].Venue
+		}
+		if d.Positions[i].Asset != d.Positions[j].Asset {
+			return d.Positions[i].Asset < d.Positions[j].Asset
+		}
+		return d.Positions[i].Account < d.Positions[j].Account
+	})
+
+	d.OrderCountsPerVenue = diffCounts(a.OpenOrderCounts.PerVenue, b.OpenOrderCounts.PerVenue)
+	d.OrderCountsPerSymbol = diffCounts(a.OpenOrderCounts.PerSymbol, b.OpenOrderCounts.PerSymbol)
+	return d
+}
+
+func positionSize(s *domain.RiskState, k domain.VenueAssetKey) decimal.Decimal {
+	if p, ok := s.Positions[k]; ok && p != nil {
+		return p.Size
+	}
+	return decimal.Zero
+}
+
+func diffCounts(from, to map[string]int) []CountDiff {
+	keys := make(map[string]struct{}, len(from)+len(to))
+	for k := range from {
+		keys[k] = struct{}{}
+	}
+	for k := range to {
+		keys[k] = struct{}{}
+	}
+
+	var diffs []CountDiff
+	for k := range keys {
+		if from[k] == to[k] {
+			continue
+		}
+		diffs = append(diffs, CountDiff{Key: k, From: from[k], To: to[k], Delta: to[k] - from[k]})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
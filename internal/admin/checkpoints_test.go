@@ -0,0 +1,198 @@
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/persistence"
+)
+
+type fakeCheckpointStore struct {
+	records map[int64]persistence.CheckpointRecord
+}
+
+func (f *fakeCheckpointStore) ListCheckpoints(since, until time.Time, limit int) ([]persistence.CheckpointRecord, error) {
+	var out []persistence.CheckpointRecord
+	for id := int64(len(f.records)); id >= 1 && len(out) < limit; id-- {
+		rec := f.records[id]
+		if rec.CreatedAt.Before(since) || rec.CreatedAt.After(until) {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func (f *fakeCheckpointStore) GetCheckpoint(id int64) (*persistence.CheckpointRecord, error) {
+	rec, ok := f.records[id]
+	if !ok {
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+func newTestMux() *http.ServeMux {
+	btc := domain.VenueAssetKey{Venue: "kcex", Asset: "BTC"}
+	eth := domain.VenueAssetKey{Venue: "nobitex", Asset: "ETH"}
+	now := time.Now()
+
+	store := &fakeCheckpointStore{records: map[int64]persistence.CheckpointRecord{
+		1: {ID: 1, CreatedAt: now.Add(-2 * time.Hour), State: &domain.RiskState{
+			Mode:             domain.RiskModeNormal,
+			DailyRealizedPnL: decimal.NewFromInt(100),
+			Positions: map[domain.VenueAssetKey]*domain.Position{
+				btc: {Size: decimal.NewFromFloat(0.5)},
+				eth: {Size: decimal.NewFromInt(3)},
+			},
+			OpenOrderCounts: domain.OrderCountState{Global: 2, PerVenue: map[string]int{"kcex": 2}},
+		}},
+		2: {ID: 2, CreatedAt: now.Add(-time.Hour), State: &domain.RiskState{
+			Mode:             domain.RiskModeWarning,
+			DailyRealizedPnL: decimal.NewFromInt(40),
+			Positions: map[domain.VenueAssetKey]*domain.Position{
+				btc: {Size: decimal.NewFromFloat(1.25)},
+				eth: {Size: decimal.NewFromInt(3)},
+			},
+			OpenOrderCounts: domain.OrderCountState{Global: 5, PerVenue: map[string]int{"kcex": 4, "nobitex": 1}},
+		}},
+	}}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	mux := http.NewServeMux()
+	RegisterCheckpointRoutes(mux, store, logger)
+	return mux
+}
+
+func TestCheckpointList(t *testing.T) {
+	mux := newTestMux()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/checkpoints?limit=10", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+
+	var got []CheckpointSummary
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 checkpoints, got %d", len(got))
+	}
+	if got[0].ID != 2 || got[0].Mode != domain.RiskModeWarning || got[0].OpenOrders != 5 {
+		t.Errorf("unexpected newest summary: %+v", got[0])
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/checkpoints?since=not-a-time", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status for bad since: got %d, want 400", rec.Code)
+	}
+}
+
+func TestCheckpointGet(t *testing.T) {
+	mux := newTestMux()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/checkpoints/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+
+	var got struct {
+		ID    int64           `json:"id"`
+		State json.RawMessage `json:"state"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	state, err := domain.DecodeRiskState(got.State)
+	if err != nil {
+		t.Fatalf("decode embedded state: %v", err)
+	}
+	if len(state.Positions) != 2 {
+		t.Errorf("expected 2 positions in state, got %d", len(state.Positions))
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/checkpoints/99", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status for missing checkpoint: got %d, want 404", rec.Code)
+	}
+}
+
+func TestCheckpointDiff(t *testing.T) {
+	mux := newTestMux()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/checkpoints/diff?from=1&to=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+
+	var got CheckpointDiff
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if !got.ModeChanged {
+		t.Error("expected mode change NORMAL -> WARNING")
+	}
+	if !got.RealizedPnLDelta.Equal(decimal.NewFromInt(-60)) {
+		t.Errorf("realized pnl delta: got %s, want -60", got.RealizedPnLDelta)
+	}
+	if len(got.Positions) != 1 {
+		t.Fatalf("expected only the changed BTC position, got %+v", got.Positions)
+	}
+	if p := got.Positions[0]; p.Asset != "BTC" || !p.Delta.Equal(decimal.NewFromFloat(0.75)) {
+		t.Errorf("unexpected position diff: %+v", p)
+	}
+	if got.GlobalOrderDelta != 3 {
+		t.Errorf("global order delta: got %d, want 3", got.GlobalOrderDelta)
+	}
+	if len(got.OrderCountsPerVenue) != 2 {
+		t.Errorf("expected kcex and nobitex count diffs, got %+v", got.OrderCountsPerVenue)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/checkpoints/diff?from=1", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status for missing to: got %d, want 400", rec.Code)
+	}
+}
+
+func TestDiffCheckpointsSeparatesAccounts(t *testing.T) {
+	main := domain.VenueAssetKey{Venue: "kcex", Asset: "BTC"}
+	sub := domain.VenueAssetKey{Venue: "kcex", Account: "hedge", Asset: "BTC"}
+	from := persistence.CheckpointRecord{ID: 1, State: &domain.RiskState{
+		Positions: map[domain.VenueAssetKey]*domain.Position{
+			main: {Size: decimal.NewFromInt(1)},
+			sub:  {Size: decimal.NewFromInt(-1)},
+		},
+	}}
+	to := persistence.CheckpointRecord{ID: 2, State: &domain.RiskState{
+		Positions: map[domain.VenueAssetKey]*domain.Position{
+			main: {Size: decimal.NewFromInt(2)},
+			sub:  {Size: decimal.NewFromInt(-3)},
+		},
+	}}
+
+	d := DiffCheckpoints(from, to)
+	if len(d.Positions) != 2 {
+		t.Fatalf("expected a diff per account, got %+v", d.Positions)
+	}
+	if p := d.Positions[0]; p.Account != "" || !p.Delta.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the default account first with delta 1, got %+v", p)
+	}
+	if p := d.Positions[1]; p.Account != "hedge" || !p.Delta.Equal(decimal.NewFromInt(-2)) {
+		t.Errorf("expected the hedge account with delta -2, got %+v", p)
+	}
+}
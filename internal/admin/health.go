@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// HealthReporter holds the latest health verdict per venue.
+type HealthReporter interface {
+	Statuses() []gateway.HealthStatus
+	Ready() bool
+}
+
+// HealthResponse is the body of both health endpoints.
+type HealthResponse struct {
+	Ready  bool                   `json:"ready"`
+	Venues []gateway.HealthStatus `json:"venues"`
+}
+
+// RegisterHealthRoutes adds the health endpoints to mux:
+//
+//	GET /health    always 200 while the process is up, with each venue's health
+//	GET /ready     200 once every venue is healthy, 503 otherwise
+//
+// A venue outage fails readiness but not liveness, so an orchestrator stops
+// routing to the process rather than restarting it.
+func RegisterHealthRoutes(mux *http.ServeMux, health HealthReporter) {
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, healthResponse(health))
+	})
+	mux.HandleFunc("GET /ready", func(w http.ResponseWriter, r *http.Request) {
+		resp := healthResponse(health)
+		status := http.StatusOK
+		if !resp.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, resp)
+	})
+}
+
+func healthResponse(health HealthReporter) HealthResponse {
+	return HealthResponse{Ready: health.Ready(), Venues: health.Statuses()}
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+type fakeHealth struct {
+	statuses []gateway.HealthStatus
+}
+
+func (f *fakeHealth) Statuses() []gateway.HealthStatus { return f.statuses }
+
+func (f *fakeHealth) Ready() bool {
+	for _, s := range f.statuses {
+		if !s.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHealthRoutes(t *testing.T) {
+	health := &fakeHealth{statuses: []gateway.HealthStatus{
+		{VenueHealth: domain.VenueHealth{Venue: "kcex", WSConnected: true, RESTReachable: true}, Healthy: true},
+		{VenueHealth: domain.VenueHealth{Venue: "okx", RESTReachable: true}, Reason: "websocket disconnected"},
+	}}
+	mux := http.NewServeMux()
+	RegisterHealthRoutes(mux, health)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("liveness: got %d, want 200", rec.Code)
+	}
+	var got HealthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Ready || len(got.Venues) != 2 || got.Venues[1].Reason != "websocket disconnected" {
+		t.Errorf("unexpected body: %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("readiness with okx down: got %d, want 503", rec.Code)
+	}
+
+	health.statuses[1].Healthy = true
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("readiness once recovered: got %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/monitor"
+)
+
+// InstanceInfo identifies a running instance: the build, the configuration
+// it was started with and what it trades.
+type InstanceInfo struct {
+	InstanceID  string            `json:"instance_id"`
+	Build       monitor.BuildInfo `json:"build"`
+	ConfigHash  string            `json:"config_hash"`
+	TradingMode string            `json:"trading_mode"`
+	Strategies  []string          `json:"strategies"`
+	Venues      []string          `json:"venues"`
+	StartedAt   time.Time         `json:"started_at"`
+}
+
+// RegisterInfoRoutes adds the instance metadata endpoint to mux:
+//
+//	GET /info    build version and commit, config hash, mode, strategies and venues
+//
+// The same values label the trader_build_info metric.
+func RegisterInfoRoutes(mux *http.ServeMux, info InstanceInfo) {
+	mux.HandleFunc("GET /info", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, info)
+	})
+}
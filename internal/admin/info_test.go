@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crypto-trading/trading/internal/monitor"
+)
+
+func TestInfoRoute(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterInfoRoutes(mux, InstanceInfo{
+		InstanceID:  "trader-01",
+		Build:       monitor.BuildInfo{Version: "v1.4.0", Commit: "0123456789abcdef"},
+		ConfigHash:  "c0ffee",
+		TradingMode: "dry_run",
+		Strategies:  []string{"triangular_arb"},
+		Venues:      []string{"kcex", "nobitex"},
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/info", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", rec.Code)
+	}
+	var got InstanceInfo
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Build.Version != "v1.4.0" || got.ConfigHash != "c0ffee" || len(got.Venues) != 2 || got.Strategies[0] != "triangular_arb" {
+		t.Errorf("unexpected info %+v", got)
+	}
+}
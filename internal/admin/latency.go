@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// LatencyReporter holds rolling latency percentiles per venue endpoint.
+type LatencyReporter interface {
+	All() []gateway.LatencyStats
+}
+
+// RegisterLatencyRoutes adds the venue latency endpoint to mux:
+//
+//	GET /admin/latency             p50/p99/max per venue, kind and endpoint
+//	GET /admin/latency?venue=okx   the same, for one venue
+//
+// Windows with no samples inside the configured age are left out.
+func RegisterLatencyRoutes(mux *http.ServeMux, latency LatencyReporter) {
+	mux.HandleFunc("GET /admin/latency", func(w http.ResponseWriter, r *http.Request) {
+		stats := latency.All()
+		if venue := r.URL.Query().Get("venue"); venue != "" {
+			filtered := stats[:0]
+			for _, s := range stats {
+				if s.Venue == venue {
+					filtered = append(filtered, s)
+				}
+			}
+			stats = filtered
+		}
+		writeJSON(w, http.StatusOK, stats)
+	})
+}
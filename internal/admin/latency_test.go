@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+func TestLatencyRoute(t *testing.T) {
+	tracker := gateway.NewLatencyTracker(16, time.Minute)
+	tracker.Observe("okx", gateway.LatencyREST, "order", 20*time.Millisecond)
+	tracker.Observe("kcex", gateway.LatencyWS, "book", 5*time.Millisecond)
+	mux := http.NewServeMux()
+	RegisterLatencyRoutes(mux, tracker)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/latency?venue=okx", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", rec.Code)
+	}
+	var got []gateway.LatencyStats
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 1 || got[0].Venue != "okx" || got[0].Endpoint != "order" || got[0].P99Ms != 20 {
+		t.Errorf("unexpected stats %+v", got)
+	}
+}
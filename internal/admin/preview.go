@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/execution"
+)
+
+// SignalPreviewer evaluates a signal the way the execution engine would,
+// without executing it.
+type SignalPreviewer interface {
+	Preview(ctx context.Context, signal domain.TradeSignal) *execution.Preview
+}
+
+// RegisterPreviewRoutes adds the signal preview endpoint to mux:
+//
+//	POST /admin/preview    risk verdict, limit usage, cost and book prices for a signal
+//
+// The body has the same shape as POST /admin/signals but needs no signature,
+// since nothing is executed.
+func RegisterPreviewRoutes(mux *http.ServeMux, previewer SignalPreviewer, venues []string, logger *slog.Logger) {
+	h := &previewHandler{previewer: previewer, venues: make(map[string]bool), logger: logger}
+	for _, v := range venues {
+		h.venues[v] = true
+	}
+	mux.HandleFunc("POST /admin/preview", h.preview)
+}
+
+type previewHandler struct {
+	previewer SignalPreviewer
+	venues    map[string]bool
+	logger    *slog.Logger
+}
+
+func (h *previewHandler) preview(w http.ResponseWriter, r *http.Request) {
+	var req signalRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSignalBodyBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if msg := validateSignalRequest(req, h.venues); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	pv := h.previewer.Preview(r.Context(), req.signal())
+	h.logger.Info("signal previewed",
+		"strategy", pv.Strategy,
+		"venue", pv.Venue,
+		"would_execute", pv.WouldExecute,
+		"skip_reasons", pv.SkipReasons)
+	writeJSON(w, http.StatusOK, pv)
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/execution"
+)
+
+type fakePreviewer struct {
+	got *domain.TradeSignal
+}
+
+func (f *fakePreviewer) Preview(_ context.Context, signal domain.TradeSignal) *execution.Preview {
+	f.got = &signal
+	return &execution.Preview{SignalID: signal.SignalID, Venue: signal.Venue, WouldExecute: true}
+}
+
+func TestPreviewRoute(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	previewer := &fakePreviewer{}
+	mux := http.NewServeMux()
+	RegisterPreviewRoutes(mux, previewer, []string{"kcex"}, logger)
+
+	body := `{"strategy":"BASIS_ARB","venue":"kcex","expected_edge_bps":"12","legs":[
+		{"symbol":"BTC/USDT","side":"BUY","instrument_type":"SPOT","order_type":"LIMIT","price":"60000","size":"0.1"}]}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/preview", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	var got execution.Preview
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.WouldExecute || got.Venue != "kcex" || previewer.got == nil || len(previewer.got.Legs) != 1 {
+		t.Errorf("unexpected preview %+v for signal %+v", got, previewer.got)
+	}
+
+	previewer.got = nil
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/preview", strings.NewReader(strings.Replace(body, "kcex", "okx", 1))))
+	if rec.Code != http.StatusBadRequest || previewer.got != nil {
+		t.Errorf("expected an unknown venue rejected before previewing, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,292 @@
+package admin
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/monitor"
+)
+
+// Inbound signal request headers. The signature is "sha256=" followed by the
+// hex HMAC-SHA256 of "<timestamp>.<nonce>.<body>" under the source's secret,
+// the webhook scheme with the nonce signed as well. A nonce is accepted once
+// per source.
+const (
+	SignalSourceHeader    = "X-Signal-Source"
+	SignalTimestampHeader = "X-Signal-Timestamp"
+	SignalNonceHeader     = "X-Signal-Nonce"
+	SignalSignatureHeader = "X-Signal-Signature"
+)
+
+const (
+	// maxSignalClockSkew bounds how old a signed request may be, so seen
+	// nonces only need remembering for that long.
+	maxSignalClockSkew = 5 * time.Minute
+	maxSignalBodyBytes = 64 << 10
+	maxSignalNonceLen  = 128
+)
+
+// SignalSource is an external system allowed to submit signals.
+type SignalSource struct {
+	Name   string
+	Secret []byte
+	Live   bool // false keeps the source's signals in shadow execution
+}
+
+// SignalIntake routes externally submitted signals. Both paths validate the
+// signal with the risk manager like any strategy signal; Shadow fills
+// against simulated gateways and never reaches a venue.
+type SignalIntake struct {
+	Sources []SignalSource
+	Venues  []string
+	Live    func(domain.TradeSignal)
+	Shadow  func(domain.TradeSignal)
+}
+
+// RegisterSignalRoutes adds the inbound signal endpoint to mux:
+//
+//	POST /admin/signals    submits one signal, signed by a configured source
+func RegisterSignalRoutes(mux *http.ServeMux, intake SignalIntake, logger *slog.Logger) {
+	h := &signalHandler{
+		intake:  intake,
+		sources: make(map[string]SignalSource),
+		venues:  make(map[string]bool),
+		nonces:  make(map[signalNonce]time.Time),
+		logger:  logger,
+	}
+	for _, src := range intake.Sources {
+		h.sources[src.Name] = src
+	}
+	for _, v := range intake.Venues {
+		h.venues[v] = true
+	}
+	mux.HandleFunc("POST /admin/signals", h.submit)
+}
+
+type signalHandler struct {
+	intake  SignalIntake
+	sources map[string]SignalSource
+	venues  map[string]bool
+	logger  *slog.Logger
+
+	// nonces holds the nonces seen within the clock skew window and when
+	// each can be forgotten, because its timestamp is then too old anyway.
+	noncesMu  sync.Mutex
+	nonces    map[signalNonce]time.Time
+	nextSweep time.Time
+}
+
+type signalNonce struct {
+	source string
+	nonce  string
+}
+
+type signalLegRequest struct {
+	Symbol         string                `json:"symbol"`
+	Side           domain.Side           `json:"side"`
+	InstrumentType domain.InstrumentType `json:"instrument_type"`
+	OrderType      domain.OrderType      `json:"order_type"`
+	Price          decimal.Decimal       `json:"price"`
+	Size           decimal.Decimal       `json:"size"`
+	ReduceOnly     bool                  `json:"reduce_only,omitempty"`
+}
+
+type signalRequest struct {
+	Strategy        domain.StrategyType `json:"strategy"`
+	Venue           string              `json:"venue"`
+	Legs            []signalLegRequest  `json:"legs"`
+	ExpectedEdgeBps decimal.Decimal     `json:"expected_edge_bps"`
+	Confidence      decimal.Decimal     `json:"confidence"`
+}
+
+// SignalAccepted is the response to an accepted signal. Acceptance only
+// means the signal was queued; risk checks run afterwards.
+type SignalAccepted struct {
+	SignalID uuid.UUID `json:"signal_id"`
+	Mode     string    `json:"mode"` // "live" or "shadow"
+}
+
+func (h *signalHandler) submit(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignalBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	src, signedAt, ok := h.authenticate(r, body)
+	if !ok {
+		h.logger.Warn("inbound signal rejected: bad signature",
+			"source", r.Header.Get(SignalSourceHeader), "remote", r.RemoteAddr)
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+	if !h.claimNonce(src.Name, r.Header.Get(SignalNonceHeader), signedAt) {
+		h.logger.Warn("inbound signal rejected: replayed nonce",
+			"source", src.Name, "remote", r.RemoteAddr)
+		writeError(w, http.StatusUnauthorized, "replayed request")
+		return
+	}
+
+	var req signalRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if msg := validateSignalRequest(req, h.venues); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	signal := req.signal()
+
+	mode := "shadow"
+	if src.Live {
+		mode = "live"
+	}
+	h.logger.Info("inbound signal accepted",
+		"source", src.Name,
+		"signal_id", signal.SignalID,
+		"strategy", signal.Strategy,
+		"venue", signal.Venue,
+		"mode", mode)
+	if src.Live {
+		h.intake.Live(signal)
+	} else {
+		h.intake.Shadow(signal)
+	}
+	writeJSON(w, http.StatusAccepted, SignalAccepted{SignalID: signal.SignalID, Mode: mode})
+}
+
+// authenticate checks the request's source, timestamp and signature, and
+// returns the source and the signed timestamp.
+func (h *signalHandler) authenticate(r *http.Request, body []byte) (SignalSource, time.Time, bool) {
+	src, ok := h.sources[r.Header.Get(SignalSourceHeader)]
+	if !ok {
+		return SignalSource{}, time.Time{}, false
+	}
+	ts := r.Header.Get(SignalTimestampHeader)
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return SignalSource{}, time.Time{}, false
+	}
+	signedAt := time.Unix(secs, 0)
+	if skew := time.Since(signedAt); skew > maxSignalClockSkew || skew < -maxSignalClockSkew {
+		return SignalSource{}, time.Time{}, false
+	}
+	nonce := r.Header.Get(SignalNonceHeader)
+	if nonce == "" || len(nonce) > maxSignalNonceLen {
+		return SignalSource{}, time.Time{}, false
+	}
+	want := "sha256=" + SignSignal(src.Secret, ts, nonce, body)
+	if !hmac.Equal([]byte(r.Header.Get(SignalSignatureHeader)), []byte(want)) {
+		return SignalSource{}, time.Time{}, false
+	}
+	return src, signedAt, true
+}
+
+// claimNonce records source's nonce and reports whether it was unseen. A
+// nonce is remembered until its timestamp leaves the clock skew window, after
+// which authenticate rejects the request anyway.
+func (h *signalHandler) claimNonce(source, nonce string, signedAt time.Time) bool {
+	h.noncesMu.Lock()
+	defer h.noncesMu.Unlock()
+
+	now := time.Now()
+	if now.After(h.nextSweep) {
+		for k, expires := range h.nonces {
+			if now.After(expires) {
+				delete(h.nonces, k)
+			}
+		}
+		h.nextSweep = now.Add(time.Minute)
+	}
+
+	key := signalNonce{source: source, nonce: nonce}
+	if _, seen := h.nonces[key]; seen {
+		return false
+	}
+	h.nonces[key] = signedAt.Add(maxSignalClockSkew)
+	return true
+}
+
+// SignSignal returns the hex HMAC-SHA256 of "<timestamp>.<nonce>.<body>"
+// under secret, as sent in the signal signature header.
+func SignSignal(secret []byte, timestamp, nonce string, body []byte) string {
+	return monitor.SignWebhook(secret, timestamp+"."+nonce, body)
+}
+
+// signal builds the TradeSignal for a validated request.
+func (req signalRequest) signal() domain.TradeSignal {
+	signal := domain.TradeSignal{
+		SignalID:            uuid.New(),
+		Strategy:            req.Strategy,
+		Venue:               req.Venue,
+		ExpectedEdgeBps:     req.ExpectedEdgeBps,
+		Confidence:          req.Confidence,
+		Atomicity:           decimal.NewFromInt(1),
+		CreatedAt:           time.Now(),
+		MarketDataTimestamp: time.Now(),
+	}
+	for _, leg := range req.Legs {
+		signal.Legs = append(signal.Legs, domain.LegSpec{
+			Symbol:         leg.Symbol,
+			Side:           leg.Side,
+			InstrumentType: leg.InstrumentType,
+			OrderType:      leg.OrderType,
+			Price:          leg.Price,
+			Size:           leg.Size,
+			ReduceOnly:     leg.ReduceOnly,
+		})
+	}
+	return signal
+}
+
+// validateSignalRequest returns a reason the signal cannot be executed, or "".
+func validateSignalRequest(req signalRequest, venues map[string]bool) string {
+	switch req.Strategy {
+	case domain.StrategyTriArb, domain.StrategyBasisArb:
+	default:
+		return "strategy must be TRI_ARB or BASIS_ARB"
+	}
+	if !venues[req.Venue] {
+		return "unknown venue " + req.Venue
+	}
+	if len(req.Legs) == 0 {
+		return "at least one leg is required"
+	}
+	for _, leg := range req.Legs {
+		if leg.Symbol == "" {
+			return "every leg needs a symbol"
+		}
+		if leg.Side != domain.SideBuy && leg.Side != domain.SideSell {
+			return "leg side must be BUY or SELL"
+		}
+		if leg.InstrumentType != domain.InstrumentSpot && leg.InstrumentType != domain.InstrumentPerp {
+			return "leg instrument_type must be SPOT or PERP"
+		}
+		if leg.ReduceOnly && leg.InstrumentType != domain.InstrumentPerp {
+			return "only PERP legs can be reduce_only"
+		}
+		switch leg.OrderType {
+		case domain.OrderTypeMarket:
+		case domain.OrderTypeLimit:
+			if !leg.Price.IsPositive() {
+				return "limit legs need a positive price"
+			}
+		default:
+			return "leg order_type must be LIMIT or MARKET"
+		}
+		if !leg.Size.IsPositive() {
+			return "leg size must be positive"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/monitor"
+)
+
+const testSignalBody = `{"strategy":"TRI_ARB","venue":"kcex","expected_edge_bps":"25","confidence":"0.8",` +
+	`"legs":[{"symbol":"BTC/USDT","side":"BUY","instrument_type":"SPOT","order_type":"LIMIT","price":"60000","size":"0.1"}]}`
+
+type signalSink struct {
+	live, shadow []domain.TradeSignal
+}
+
+func newSignalTestMux(sink *signalSink) *http.ServeMux {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	mux := http.NewServeMux()
+	RegisterSignalRoutes(mux, SignalIntake{
+		Sources: []SignalSource{
+			{Name: "desk", Secret: []byte("desk-secret")},
+			{Name: "algo", Secret: []byte("algo-secret"), Live: true},
+		},
+		Venues: []string{"kcex"},
+		Live:   func(s domain.TradeSignal) { sink.live = append(sink.live, s) },
+		Shadow: func(s domain.TradeSignal) { sink.shadow = append(sink.shadow, s) },
+	}, logger)
+	return mux
+}
+
+func signedSignalRequest(source, secret, body string, at time.Time) *http.Request {
+	return signedSignalRequestWithNonce(source, secret, body, uuid.NewString(), at)
+}
+
+func signedSignalRequestWithNonce(source, secret, body, nonce string, at time.Time) *http.Request {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	req := httptest.NewRequest("POST", "/admin/signals", strings.NewReader(body))
+	req.Header.Set(SignalSourceHeader, source)
+	req.Header.Set(SignalTimestampHeader, ts)
+	req.Header.Set(SignalNonceHeader, nonce)
+	req.Header.Set(SignalSignatureHeader, "sha256="+SignSignal([]byte(secret), ts, nonce, []byte(body)))
+	return req
+}
+
+func TestSignalsRoutesBySource(t *testing.T) {
+	sink := &signalSink{}
+	mux := newSignalTestMux(sink)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, signedSignalRequest("desk", "desk-secret", testSignalBody, time.Now()))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want 202 (%s)", rec.Code, rec.Body.String())
+	}
+	var got SignalAccepted
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Mode != "shadow" || len(sink.shadow) != 1 || len(sink.live) != 0 {
+		t.Fatalf("expected the desk signal in shadow only, got mode %q, %d shadow, %d live",
+			got.Mode, len(sink.shadow), len(sink.live))
+	}
+	sig := sink.shadow[0]
+	if sig.SignalID != got.SignalID || sig.Venue != "kcex" || len(sig.Legs) != 1 || sig.Legs[0].Side != domain.SideBuy {
+		t.Errorf("unexpected signal: %+v", sig)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, signedSignalRequest("algo", "algo-secret", testSignalBody, time.Now()))
+	if rec.Code != http.StatusAccepted || len(sink.live) != 1 {
+		t.Errorf("expected the live source's signal published live, got %d (%s)", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSignalsRejectBadAuth(t *testing.T) {
+	cases := []struct {
+		name string
+		req  *http.Request
+	}{
+		{"unknown source", signedSignalRequest("nobody", "desk-secret", testSignalBody, time.Now())},
+		{"wrong secret", signedSignalRequest("desk", "algo-secret", testSignalBody, time.Now())},
+		{"stale timestamp", signedSignalRequest("desk", "desk-secret", testSignalBody, time.Now().Add(-10*time.Minute))},
+		{"missing nonce", signedSignalRequestWithNonce("desk", "desk-secret", testSignalBody, "", time.Now())},
+		{"unsigned nonce", func() *http.Request {
+			req := signedSignalRequest("desk", "desk-secret", testSignalBody, time.Now())
+			req.Header.Set(SignalNonceHeader, "swapped")
+			return req
+		}()},
+		{"webhook signature", func() *http.Request {
+			req := signedSignalRequest("desk", "desk-secret", testSignalBody, time.Now())
+			ts := req.Header.Get(SignalTimestampHeader)
+			req.Header.Set(SignalSignatureHeader, "sha256="+monitor.SignWebhook([]byte("desk-secret"), ts, []byte(testSignalBody)))
+			return req
+		}()},
+	}
+	for _, tc := range cases {
+		sink := &signalSink{}
+		rec := httptest.NewRecorder()
+		newSignalTestMux(sink).ServeHTTP(rec, tc.req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status %d, want 401", tc.name, rec.Code)
+		}
+		if len(sink.live)+len(sink.shadow) != 0 {
+			t.Errorf("%s: signal was published", tc.name)
+		}
+	}
+}
+
+func TestSignalsRejectReplayedNonce(t *testing.T) {
+	sink := &signalSink{}
+	mux := newSignalTestMux(sink)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, signedSignalRequestWithNonce("desk", "desk-secret", testSignalBody, "n-1", time.Now()))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("first request: status %d, want 202 (%s)", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, signedSignalRequestWithNonce("desk", "desk-secret", testSignalBody, "n-1", time.Now()))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("replay: status %d, want 401", rec.Code)
+	}
+	if len(sink.shadow) != 1 {
+		t.Errorf("expected the replay not to be published, got %d signals", len(sink.shadow))
+	}
+
+	// Nonces are per source.
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, signedSignalRequestWithNonce("algo", "algo-secret", testSignalBody, "n-1", time.Now()))
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("other source, same nonce: status %d, want 202", rec.Code)
+	}
+}
+
+func TestSignalsRejectInvalidSignal(t *testing.T) {
+	bodies := map[string]string{
+		"unknown venue": strings.Replace(testSignalBody, `"kcex"`, `"mexc"`, 1),
+		"zero size":     strings.Replace(testSignalBody, `"size":"0.1"`, `"size":"0"`, 1),
+		"bad side":      strings.Replace(testSignalBody, `"BUY"`, `"HOLD"`, 1),
+		"no legs":       `{"strategy":"TRI_ARB","venue":"kcex","legs":[]}`,
+	}
+	for name, body := range bodies {
+		sink := &signalSink{}
+		rec := httptest.NewRecorder()
+		newSignalTestMux(sink).ServeHTTP(rec, signedSignalRequest("desk", "desk-secret", body, time.Now()))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, rec.Code)
+		}
+		if len(sink.live)+len(sink.shadow) != 0 {
+			t.Errorf("%s: signal was published", name)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/crypto-trading/trading/internal/risk"
+)
+
+// StressRunner runs what-if scenarios against the live risk state.
+type StressRunner interface {
+	RunStressTest(scenarios []risk.StressScenario) *risk.StressReport
+}
+
+// RegisterStressRoutes adds the portfolio stress test endpoints to mux:
+//
+//	GET  /admin/stress    runs the configured default scenarios
+//	POST /admin/stress    runs {"scenarios": [...]} from the request body
+func RegisterStressRoutes(mux *http.ServeMux, runner StressRunner, logger *slog.Logger) {
+	h := &stressHandler{runner: runner, logger: logger}
+	mux.HandleFunc("GET /admin/stress", h.runDefault)
+	mux.HandleFunc("POST /admin/stress", h.runCustom)
+}
+
+type stressHandler struct {
+	runner StressRunner
+	logger *slog.Logger
+}
+
+func (h *stressHandler) runDefault(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.runner.RunStressTest(nil))
+}
+
+func (h *stressHandler) runCustom(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Scenarios []risk.StressScenario `json:"scenarios"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Scenarios) == 0 {
+		writeError(w, http.StatusBadRequest, "at least one scenario is required")
+		return
+	}
+	for _, sc := range req.Scenarios {
+		if sc.Name == "" {
+			writeError(w, http.StatusBadRequest, "every scenario needs a name")
+			return
+		}
+	}
+
+	h.logger.Info("running ad-hoc stress test", "scenarios", len(req.Scenarios))
+	writeJSON(w, http.StatusOK, h.runner.RunStressTest(req.Scenarios))
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/risk"
+)
+
+type fakeStressRunner struct {
+	got []risk.StressScenario
+}
+
+func (f *fakeStressRunner) RunStressTest(scenarios []risk.StressScenario) *risk.StressReport {
+	f.got = scenarios
+	if scenarios == nil {
+		scenarios = []risk.StressScenario{{Name: "default"}}
+	}
+	report := &risk.StressReport{}
+	for _, sc := range scenarios {
+		report.Results = append(report.Results, risk.StressResult{Scenario: sc})
+	}
+	return report
+}
+
+func newStressTestMux(runner StressRunner) *http.ServeMux {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	mux := http.NewServeMux()
+	RegisterStressRoutes(mux, runner, logger)
+	return mux
+}
+
+func TestStressDefault(t *testing.T) {
+	runner := &fakeStressRunner{}
+	mux := newStressTestMux(runner)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/stress", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	if runner.got != nil {
+		t.Errorf("expected nil scenarios for defaults, got %+v", runner.got)
+	}
+
+	var got risk.StressReport
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Results) != 1 || got.Results[0].Scenario.Name != "default" {
+		t.Errorf("unexpected report: %+v", got)
+	}
+}
+
+func TestStressCustom(t *testing.T) {
+	runner := &fakeStressRunner{}
+	mux := newStressTestMux(runner)
+
+	body := `{"scenarios":[{"name":"crash","price_shock_pct":"-25"},{"name":"frozen","price_shock_pct":"10","frozen_venue":"kcex"}]}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/stress", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	if len(runner.got) != 2 {
+		t.Fatalf("expected 2 scenarios passed to runner, got %d", len(runner.got))
+	}
+	if !runner.got[0].PriceShockPct.Equal(decimal.NewFromInt(-25)) || runner.got[1].FrozenVenue != "kcex" {
+		t.Errorf("unexpected scenarios: %+v", runner.got)
+	}
+
+	for _, bad := range []string{`not json`, `{"scenarios":[]}`, `{"scenarios":[{"price_shock_pct":"5"}]}`} {
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/stress", strings.NewReader(bad)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("body %q: got %d, want 400", bad, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package backtest replays recorded market data through the simulated venues
+// and reports how the strategies would have done.
+//
+// The rest of the system runs on the wall clock, so rather than making every
+// component clock-aware, a replay maps historical time onto wall time at an
+// accelerated rate and restamps each event as it is replayed: freshness
+// checks, fill timeouts and funding snapshots then behave as they would
+// live, only faster.
+package backtest
+
+import "time"
+
+// Clock maps historical time onto wall time, Speed historical seconds to the
+// wall second, from the moment the replay started.
+type Clock struct {
+	histStart time.Time
+	wallStart time.Time
+	speed     float64
+}
+
+// NewClock maps histStart to wallStart. speed must be positive.
+func NewClock(histStart, wallStart time.Time, speed float64) *Clock {
+	return &Clock{histStart: histStart, wallStart: wallStart, speed: speed}
+}
+
+// Wall returns the wall time at which historical time t is replayed.
+func (c *Clock) Wall(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return c.wallStart.Add(time.Duration(float64(t.Sub(c.histStart)) / c.speed))
+}
+
+// Historical returns the historical time being replayed at wall time t.
+func (c *Clock) Historical(t time.Time) time.Time {
+	return c.histStart.Add(time.Duration(float64(t.Sub(c.wallStart)) * c.speed))
+}
+
+// Now is the historical time being replayed now.
+func (c *Clock) Now() time.Time {
+	return c.Historical(time.Now())
+}
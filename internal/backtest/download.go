@@ -0,0 +1,190 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// HistoryStore persists downloaded market history, skipping rows it already
+// holds, and reports how many were new.
+type HistoryStore interface {
+	WriteKlines(ctx context.Context, klines []domain.Kline) (int, error)
+	WriteMarketTrades(ctx context.Context, trades []domain.Trade) (int, error)
+	WriteFundingRates(ctx context.Context, rates []domain.FundingRate) (int, error)
+}
+
+// DownloadSymbol is a venue symbol to download. Funding is only fetched for
+// perps.
+type DownloadSymbol struct {
+	Symbol string
+	Perp   bool
+}
+
+// DownloadRequest selects the range and kinds of history to download.
+type DownloadRequest struct {
+	From, To time.Time
+	// Interval is the kline interval; it must be one the venues serve.
+	Interval time.Duration
+	Klines   bool
+	Trades   bool
+	Funding  bool
+}
+
+// DownloadResult summarises one venue's download.
+type DownloadResult struct {
+	Venue   string
+	Klines  int
+	Trades  int
+	Funding int
+}
+
+// Downloader pulls historical klines, public trades and funding rates from
+// venue REST endpoints into the cold store to seed backtests. Requests go
+// through the gateways, so venue rate limits are respected.
+type Downloader struct {
+	gateways map[string]gateway.VenueGateway
+	store    HistoryStore
+	logger   *slog.Logger
+}
+
+func NewDownloader(gateways map[string]gateway.VenueGateway, store HistoryStore, logger *slog.Logger) *Downloader {
+	return &Downloader{
+		gateways: gateways,
+		store:    store,
+		logger:   logger,
+	}
+}
+
+// Download fetches req's history for each venue's symbols from every gateway
+// that implements gateway.MarketHistoryProvider. Venues without one, and
+// kinds of history a venue does not serve, are logged and skipped. The first
+// other error aborts the run; rows already written stay, and a rerun over the
+// same range only adds what is missing.
+func (d *Downloader) Download(ctx context.Context, symbols map[string][]DownloadSymbol, req DownloadRequest) ([]DownloadResult, error) {
+	if !req.From.Before(req.To) {
+		return nil, fmt.Errorf("download range is empty: %s to %s", req.From.Format(time.RFC3339), req.To.Format(time.RFC3339))
+	}
+	if req.Klines && req.Interval <= 0 {
+		return nil, fmt.Errorf("kline interval must be positive, got %s", req.Interval)
+	}
+
+	names := make([]string, 0, len(symbols))
+	for name := range symbols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []DownloadResult
+	for _, name := range names {
+		provider, ok := d.gateways[name].(gateway.MarketHistoryProvider)
+		if !ok {
+			d.logger.Info("venue has no market history endpoint, skipping download", "venue", name)
+			continue
+		}
+
+		res := DownloadResult{Venue: name}
+		for _, sym := range symbols[name] {
+			if err := d.downloadSymbol(ctx, name, provider, sym, req, &res); err != nil {
+				return results, fmt.Errorf("download %s %s: %w", name, sym.Symbol, err)
+			}
+		}
+		d.logger.Info("market history downloaded",
+			"venue", name,
+			"klines", res.Klines,
+			"trades", res.Trades,
+			"funding", res.Funding,
+		)
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (d *Downloader) downloadSymbol(ctx context.Context, venue string, provider gateway.MarketHistoryProvider, sym DownloadSymbol, req DownloadRequest, res *DownloadResult) error {
+	if req.Klines {
+		n, err := page(ctx, req.From, req.To,
+			func(from time.Time) ([]domain.Kline, error) {
+				return provider.GetKlines(ctx, sym.Symbol, req.Interval, from, req.To)
+			},
+			func(k domain.Kline) time.Time { return k.OpenTime.Add(req.Interval) },
+			d.store.WriteKlines)
+		if err := d.skipUnsupported(err, venue, sym.Symbol, "klines"); err != nil {
+			return fmt.Errorf("klines: %w", err)
+		}
+		res.Klines += n
+	}
+	if req.Trades {
+		n, err := page(ctx, req.From, req.To,
+			func(from time.Time) ([]domain.Trade, error) {
+				return provider.GetHistoricalTrades(ctx, sym.Symbol, from, req.To)
+			},
+			func(t domain.Trade) time.Time { return t.Timestamp },
+			d.store.WriteMarketTrades)
+		if err := d.skipUnsupported(err, venue, sym.Symbol, "trades"); err != nil {
+			return fmt.Errorf("trades: %w", err)
+		}
+		res.Trades += n
+	}
+	if req.Funding && sym.Perp {
+		n, err := page(ctx, req.From, req.To,
+			func(from time.Time) ([]domain.FundingRate, error) {
+				return provider.GetFundingHistory(ctx, sym.Symbol, from, req.To)
+			},
+			func(r domain.FundingRate) time.Time { return r.Timestamp.Add(time.Millisecond) },
+			d.store.WriteFundingRates)
+		if err := d.skipUnsupported(err, venue, sym.Symbol, "funding"); err != nil {
+			return fmt.Errorf("funding: %w", err)
+		}
+		res.Funding += n
+	}
+	return nil
+}
+
+// skipUnsupported logs and drops gateway.ErrHistoryUnsupported.
+func (d *Downloader) skipUnsupported(err error, venue, symbol, kind string) error {
+	if errors.Is(err, gateway.ErrHistoryUnsupported) {
+		d.logger.Info("venue does not serve this history, skipping", "venue", venue, "symbol", symbol, "kind", kind)
+		return nil
+	}
+	return err
+}
+
+// page fetches [from, to) one venue page at a time, storing each, until a
+// page comes back empty or reaches to. next is where the page after an item
+// starts; a page that does not move past from advances by a millisecond so
+// a venue holding many items at one instant cannot stall the download,
+// while trades sharing the last timestamp are fetched again and skipped by
+// the store. It returns how many rows the store inserted.
+func page[T any](ctx context.Context, from, to time.Time, fetch func(time.Time) ([]T, error), next func(T) time.Time, write func(context.Context, []T) (int, error)) (int, error) {
+	inserted := 0
+	for from.Before(to) {
+		if err := ctx.Err(); err != nil {
+			return inserted, err
+		}
+		items, err := fetch(from)
+		if err != nil {
+			return inserted, fmt.Errorf("fetch from %s: %w", from.Format(time.RFC3339), err)
+		}
+		if len(items) == 0 {
+			return inserted, nil
+		}
+		n, err := write(ctx, items)
+		if err != nil {
+			return inserted, fmt.Errorf("store: %w", err)
+		}
+		inserted += n
+
+		last := next(items[len(items)-1])
+		if !last.After(from) {
+			last = from.Add(time.Millisecond)
+		}
+		from = last
+	}
+	return inserted, nil
+}
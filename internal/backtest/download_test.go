@@ -0,0 +1,142 @@
+package backtest
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// historyGateway serves canned history two items a page. The embedded
+// interface covers the methods the downloader never calls.
+type historyGateway struct {
+	gateway.VenueGateway
+	klines  []domain.Kline
+	trades  []domain.Trade
+	funding []domain.FundingRate
+	calls   int
+}
+
+func (g *historyGateway) GetKlines(_ context.Context, _ string, _ time.Duration, from, to time.Time) ([]domain.Kline, error) {
+	return pageOf(g, g.klines, func(k domain.Kline) time.Time { return k.OpenTime }, from, to), nil
+}
+
+func (g *historyGateway) GetHistoricalTrades(_ context.Context, _ string, _, _ time.Time) ([]domain.Trade, error) {
+	return nil, gateway.ErrHistoryUnsupported
+}
+
+func (g *historyGateway) GetFundingHistory(_ context.Context, _ string, from, to time.Time) ([]domain.FundingRate, error) {
+	return pageOf(g, g.funding, func(r domain.FundingRate) time.Time { return r.Timestamp }, from, to), nil
+}
+
+func pageOf[T any](g *historyGateway, items []T, at func(T) time.Time, from, to time.Time) []T {
+	g.calls++
+	var out []T
+	for _, item := range items {
+		if ts := at(item); !ts.Before(from) && ts.Before(to) && len(out) < 2 {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+type memoryHistoryStore struct {
+	klines  map[time.Time]bool
+	funding map[time.Time]bool
+}
+
+func (s *memoryHistoryStore) WriteKlines(_ context.Context, klines []domain.Kline) (int, error) {
+	n := 0
+	for _, k := range klines {
+		if !s.klines[k.OpenTime] {
+			s.klines[k.OpenTime] = true
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memoryHistoryStore) WriteMarketTrades(_ context.Context, trades []domain.Trade) (int, error) {
+	return len(trades), nil
+}
+
+func (s *memoryHistoryStore) WriteFundingRates(_ context.Context, rates []domain.FundingRate) (int, error) {
+	n := 0
+	for _, r := range rates {
+		if !s.funding[r.Timestamp] {
+			s.funding[r.Timestamp] = true
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestDownloaderDownload(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	bybit := &historyGateway{}
+	for i := 0; i < 5; i++ {
+		bybit.klines = append(bybit.klines, domain.Kline{Venue: "bybit", Symbol: "BTCUSDT", Interval: time.Hour,
+			OpenTime: from.Add(time.Duration(i) * time.Hour), Close: decimal.NewFromInt(int64(50000 + i))})
+	}
+	for i := 0; i < 3; i++ {
+		bybit.funding = append(bybit.funding, domain.FundingRate{Venue: "bybit", Symbol: "BTCUSDT",
+			Timestamp: from.Add(time.Duration(i) * 8 * time.Hour), Rate: decimal.RequireFromString("0.0001")})
+	}
+	// Before the range.
+	bybit.funding = append(bybit.funding, domain.FundingRate{Venue: "bybit", Symbol: "BTCUSDT", Timestamp: from.Add(-8 * time.Hour)})
+
+	gateways := map[string]gateway.VenueGateway{
+		"bybit": bybit,
+		"kcex":  struct{ gateway.VenueGateway }{},
+	}
+	symbols := map[string][]DownloadSymbol{
+		"bybit": {{Symbol: "BTCUSDT", Perp: true}},
+		"kcex":  {{Symbol: "BTC_USDT"}},
+	}
+	store := &memoryHistoryStore{klines: make(map[time.Time]bool), funding: make(map[time.Time]bool)}
+	d := NewDownloader(gateways, store, logger)
+
+	req := DownloadRequest{From: from, To: to, Interval: time.Hour, Klines: true, Trades: true, Funding: true}
+	results, err := d.Download(context.Background(), symbols, req)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	if len(results) != 1 || results[0].Venue != "bybit" {
+		t.Fatalf("expected only bybit downloaded, got %+v", results)
+	}
+	if res := results[0]; res.Klines != 5 || res.Funding != 3 || res.Trades != 0 {
+		t.Errorf("expected 5 klines, 3 funding rates and no trades, got %+v", res)
+	}
+	// Three kline pages and the empty one ending them, then two funding
+	// pages and theirs.
+	if bybit.calls != 7 {
+		t.Errorf("expected 7 history requests, got %d", bybit.calls)
+	}
+
+	// A rerun only adds what is missing.
+	results, err = d.Download(context.Background(), symbols, req)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if res := results[0]; res.Klines != 0 || res.Funding != 0 {
+		t.Errorf("expected nothing new on a rerun, got %+v", res)
+	}
+}
+
+func TestDownloaderRejectsEmptyRange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	d := NewDownloader(nil, &memoryHistoryStore{}, logger)
+	now := time.Now()
+	if _, err := d.Download(context.Background(), nil, DownloadRequest{From: now, To: now, Klines: true, Interval: time.Minute}); err == nil {
+		t.Error("expected an empty range rejected")
+	}
+}
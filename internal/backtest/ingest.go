@@ -0,0 +1,440 @@
+package backtest
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// ingestBatch is how many rows are written to the store at a time.
+const ingestBatch = 5000
+
+// IngestKind is the kind of history a dataset holds.
+type IngestKind string
+
+const (
+	IngestKlines  IngestKind = "klines"
+	IngestTrades  IngestKind = "trades"
+	IngestFunding IngestKind = "funding"
+)
+
+// Dataset formats the ingester reads.
+const (
+	// FormatBinance is the CSV layout of Binance's public data dumps
+	// (data.binance.vision): klines, aggTrades and fundingRate files, with or
+	// without a header row.
+	FormatBinance = "binance"
+	// FormatGeneric is CSV with a header row naming the columns: open_time,
+	// open, high, low, close and volume for klines; time, price, size, side
+	// and optionally trade_id for trades; time and rate for funding. Times
+	// are RFC 3339 or Unix milliseconds.
+	FormatGeneric = "generic"
+)
+
+// IngestRequest names a dataset to load and what it holds. Path is a .csv or
+// .zip file, or a directory whose .csv and .zip files are all read; every
+// CSV in a zip is read. Venue and Symbol are the internal names the rows are
+// stored under.
+type IngestRequest struct {
+	Path     string
+	Format   string
+	Kind     IngestKind
+	Venue    string
+	Symbol   string
+	Interval time.Duration // klines only
+}
+
+// IngestResult summarises an ingest.
+type IngestResult struct {
+	Files int
+	Rows  int
+	New   int
+}
+
+// Ingester loads historical datasets from outside sources, such as exchange
+// data dumps or files exported from a data provider, into the same cold
+// store tables the Downloader fills, so backtests can cover periods from
+// before our own recordings.
+type Ingester struct {
+	store  HistoryStore
+	logger *slog.Logger
+}
+
+func NewIngester(store HistoryStore, logger *slog.Logger) *Ingester {
+	return &Ingester{store: store, logger: logger}
+}
+
+// Ingest reads every file of req and writes its rows to the store. Rows the
+// store already holds are skipped, so an ingest can be rerun or overlap a
+// download. The first malformed row aborts the run, naming its file and
+// line; rows already written stay.
+func (in *Ingester) Ingest(ctx context.Context, req IngestRequest) (IngestResult, error) {
+	var result IngestResult
+	if req.Venue == "" || req.Symbol == "" {
+		return result, errors.New("ingest needs a venue and a symbol")
+	}
+	if req.Kind == IngestKlines && req.Interval <= 0 {
+		return result, fmt.Errorf("kline interval must be positive, got %s", req.Interval)
+	}
+	parse, err := rowParser(req)
+	if err != nil {
+		return result, err
+	}
+	files, err := datasetFiles(req.Path)
+	if err != nil {
+		return result, err
+	}
+
+	w := &ingestWriter{store: in.store, kind: req.Kind}
+	for _, path := range files {
+		err := eachCSV(path, func(name string, r io.Reader) error {
+			result.Files++
+			rows, err := readRows(ctx, name, r, req.Format, parse, w)
+			result.Rows += rows
+			return err
+		})
+		if err == nil {
+			err = w.flush(ctx)
+		}
+		result.New += w.written
+		w.written = 0
+		if err != nil {
+			return result, err
+		}
+		in.logger.Info("dataset file ingested", "file", path, "kind", req.Kind, "venue", req.Venue, "symbol", req.Symbol)
+	}
+	return result, nil
+}
+
+// datasetFiles returns path, or the .csv and .zip files in it if it is a
+// directory.
+func datasetFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	for _, pattern := range []string{"*.csv", "*.zip"} {
+		matches, err := filepath.Glob(filepath.Join(path, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.csv or *.zip files in %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// eachCSV calls fn with every CSV in path: the file itself, or each .csv
+// inside it if it is a zip.
+func eachCSV(path string, fn func(name string, r io.Reader) error) error {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return fn(path, f)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, zf := range zr.File {
+		if !strings.EqualFold(filepath.Ext(zf.Name), ".csv") {
+			continue
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		err = fn(path+":"+zf.Name, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowParserFunc parses one CSV record into a row for w. cols maps header
+// names to indexes for the generic format.
+type rowParserFunc func(record []string, cols map[string]int, w *ingestWriter) error
+
+func rowParser(req IngestRequest) (rowParserFunc, error) {
+	switch req.Format {
+	case FormatBinance:
+		switch req.Kind {
+		case IngestKlines:
+			return func(rec []string, _ map[string]int, w *ingestWriter) error {
+				if len(rec) < 6 {
+					return fmt.Errorf("want at least 6 kline columns, got %d", len(rec))
+				}
+				return w.kline(req, rec[0], rec[1], rec[2], rec[3], rec[4], rec[5])
+			}, nil
+		case IngestTrades:
+			// agg_trade_id, price, quantity, first_trade_id, last_trade_id,
+			// transact_time, is_buyer_maker
+			return func(rec []string, _ map[string]int, w *ingestWriter) error {
+				if len(rec) < 7 {
+					return fmt.Errorf("want at least 7 aggTrades columns, got %d", len(rec))
+				}
+				side := domain.SideBuy
+				if maker, _ := strconv.ParseBool(rec[6]); maker {
+					side = domain.SideSell
+				}
+				return w.trade(req, rec[0], rec[5], rec[1], rec[2], side)
+			}, nil
+		case IngestFunding:
+			// calc_time, funding_interval_hours, last_funding_rate
+			return func(rec []string, _ map[string]int, w *ingestWriter) error {
+				if len(rec) < 3 {
+					return fmt.Errorf("want at least 3 fundingRate columns, got %d", len(rec))
+				}
+				return w.funding(req, rec[0], rec[2])
+			}, nil
+		}
+	case FormatGeneric:
+		switch req.Kind {
+		case IngestKlines:
+			return func(rec []string, cols map[string]int, w *ingestWriter) error {
+				return w.kline(req, rec[cols["open_time"]], rec[cols["open"]], rec[cols["high"]],
+					rec[cols["low"]], rec[cols["close"]], rec[cols["volume"]])
+			}, nil
+		case IngestTrades:
+			return func(rec []string, cols map[string]int, w *ingestWriter) error {
+				side := domain.Side(strings.ToUpper(rec[cols["side"]]))
+				if side != domain.SideBuy && side != domain.SideSell {
+					return fmt.Errorf("side %q is neither buy nor sell", rec[cols["side"]])
+				}
+				id := ""
+				if i, ok := cols["trade_id"]; ok {
+					id = rec[i]
+				}
+				return w.trade(req, id, rec[cols["time"]], rec[cols["price"]], rec[cols["size"]], side)
+			}, nil
+		case IngestFunding:
+			return func(rec []string, cols map[string]int, w *ingestWriter) error {
+				return w.funding(req, rec[cols["time"]], rec[cols["rate"]])
+			}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown dataset format %q; want %s or %s", req.Format, FormatBinance, FormatGeneric)
+	}
+	return nil, fmt.Errorf("unknown history kind %q; want klines, trades or funding", req.Kind)
+}
+
+// genericColumns are the header names the generic format needs per kind.
+var genericColumns = map[IngestKind][]string{
+	IngestKlines:  {"open_time", "open", "high", "low", "close", "volume"},
+	IngestTrades:  {"time", "price", "size", "side"},
+	IngestFunding: {"time", "rate"},
+}
+
+// readRows parses every record of r into w and returns how many rows it
+// read. A Binance header row is recognised by its first field not being a
+// number; the generic format always has one.
+func readRows(ctx context.Context, name string, r io.Reader, format string, parse rowParserFunc, w *ingestWriter) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	var cols map[string]int
+	width, rows := 0, 0
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, fmt.Errorf("%s: %w", name, err)
+		}
+		if line == 1 {
+			if format == FormatGeneric {
+				if cols, err = headerColumns(rec, genericColumns[w.kind]); err != nil {
+					return rows, fmt.Errorf("%s: %w", name, err)
+				}
+				width = len(rec)
+				continue
+			}
+			if _, err := strconv.ParseFloat(strings.TrimSpace(rec[0]), 64); err != nil {
+				continue
+			}
+		}
+		if len(rec) < width {
+			return rows, fmt.Errorf("%s:%d: want %d columns, got %d", name, line, width, len(rec))
+		}
+		if err := parse(rec, cols, w); err != nil {
+			return rows, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		rows++
+		if w.pending() >= ingestBatch {
+			if err := w.flush(ctx); err != nil {
+				return rows, err
+			}
+		}
+	}
+}
+
+func headerColumns(header []string, required []string) (map[string]int, error) {
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range required {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("header has no %q column", name)
+		}
+	}
+	return cols, nil
+}
+
+// ingestWriter collects parsed rows and writes them to the store in
+// batches.
+type ingestWriter struct {
+	store   HistoryStore
+	kind    IngestKind
+	klines  []domain.Kline
+	trades  []domain.Trade
+	rates   []domain.FundingRate
+	written int
+}
+
+func (w *ingestWriter) pending() int {
+	return len(w.klines) + len(w.trades) + len(w.rates)
+}
+
+func (w *ingestWriter) flush(ctx context.Context) error {
+	var n int
+	var err error
+	switch {
+	case len(w.klines) > 0:
+		n, err = w.store.WriteKlines(ctx, w.klines)
+		w.klines = w.klines[:0]
+	case len(w.trades) > 0:
+		n, err = w.store.WriteMarketTrades(ctx, w.trades)
+		w.trades = w.trades[:0]
+	case len(w.rates) > 0:
+		n, err = w.store.WriteFundingRates(ctx, w.rates)
+		w.rates = w.rates[:0]
+	}
+	w.written += n
+	return err
+}
+
+func (w *ingestWriter) kline(req IngestRequest, openTime, open, high, low, closePrice, volume string) error {
+	at, err := parseDatasetTime(openTime)
+	if err != nil {
+		return err
+	}
+	values, err := parseDecimals(open, high, low, closePrice, volume)
+	if err != nil {
+		return err
+	}
+	w.klines = append(w.klines, domain.Kline{
+		Venue:    req.Venue,
+		Symbol:   req.Symbol,
+		Interval: req.Interval,
+		OpenTime: at,
+		Open:     values[0],
+		High:     values[1],
+		Low:      values[2],
+		Close:    values[3],
+		Volume:   values[4],
+	})
+	return nil
+}
+
+func (w *ingestWriter) trade(req IngestRequest, id, at, price, size string, side domain.Side) error {
+	ts, err := parseDatasetTime(at)
+	if err != nil {
+		return err
+	}
+	values, err := parseDecimals(price, size)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		// Without an id, a trade is keyed by when and how it traded.
+		id = fmt.Sprintf("%d-%s-%s-%s", ts.UnixNano(), side, values[0], values[1])
+	}
+	w.trades = append(w.trades, domain.Trade{
+		Venue:     req.Venue,
+		Symbol:    req.Symbol,
+		Price:     values[0],
+		Size:      values[1],
+		Side:      side,
+		Timestamp: ts,
+		TradeID:   id,
+	})
+	return nil
+}
+
+func (w *ingestWriter) funding(req IngestRequest, at, rate string) error {
+	ts, err := parseDatasetTime(at)
+	if err != nil {
+		return err
+	}
+	values, err := parseDecimals(rate)
+	if err != nil {
+		return err
+	}
+	w.rates = append(w.rates, domain.FundingRate{
+		Venue:     req.Venue,
+		Symbol:    req.Symbol,
+		Rate:      values[0],
+		Timestamp: ts,
+	})
+	return nil
+}
+
+// parseDatasetTime reads an RFC 3339 time or Unix milliseconds. Some newer
+// Binance dumps are in microseconds, which are recognised by their length.
+func parseDatasetTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if len(s) >= 16 {
+			return time.UnixMicro(n).UTC(), nil
+		}
+		return time.UnixMilli(n).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time %q is neither RFC 3339 nor Unix milliseconds", s)
+	}
+	return t, nil
+}
+
+func parseDecimals(fields ...string) ([]decimal.Decimal, error) {
+	values := make([]decimal.Decimal, len(fields))
+	for i, f := range fields {
+		d, err := decimal.NewFromString(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("number %q: %w", f, err)
+		}
+		values[i] = d
+	}
+	return values, nil
+}
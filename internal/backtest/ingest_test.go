@@ -0,0 +1,148 @@
+package backtest
+
+import (
+	"archive/zip"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func writeZip(t *testing.T, path, name, content string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIngesterBinanceDumps(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	// One month with a header row, the next without, as Binance publishes
+	// them.
+	writeZip(t, filepath.Join(dir, "BTCUSDT-1h-2024-01.zip"), "BTCUSDT-1h-2024-01.csv",
+		"open_time,open,high,low,close,volume,close_time,quote_volume,count,taker_buy_volume,taker_buy_quote_volume,ignore\n"+
+			"1704067200000,42000,42100,41900,42050,12.5,1704070799999,525000,100,6,252000,0\n"+
+			"1704070800000,42050,42200,42000,42150,8.1,1704074399999,341000,80,4,168000,0\n")
+	writeZip(t, filepath.Join(dir, "BTCUSDT-1h-2024-02.zip"), "BTCUSDT-1h-2024-02.csv",
+		"1706745600000,43000,43100,42900,43050,3.2,1706749199999,137000,40,2,86000,0\n")
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), []byte("not a dataset"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &memoryHistoryStore{klines: map[time.Time]bool{}, funding: map[time.Time]bool{}}
+	in := NewIngester(store, logger)
+	req := IngestRequest{Path: dir, Format: FormatBinance, Kind: IngestKlines, Venue: "binance", Symbol: "BTCUSDT", Interval: time.Hour}
+	result, err := in.Ingest(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 2 || result.Rows != 3 || result.New != 3 {
+		t.Errorf("expected 3 new rows from 2 files, got %+v", result)
+	}
+	if !store.klines[time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)] {
+		t.Error("expected the February kline to be stored at its open time")
+	}
+
+	// Rerunning stores nothing new.
+	result, err = in.Ingest(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows != 3 || result.New != 0 {
+		t.Errorf("expected a rerun to add nothing, got %+v", result)
+	}
+
+	funding := filepath.Join(dir, "BTCUSDT-fundingRate-2024-01.csv")
+	if err := os.WriteFile(funding, []byte("calc_time,funding_interval_hours,last_funding_rate\n1704067200000,8,0.00010000\n1704096000000,8,-0.00002000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = in.Ingest(context.Background(), IngestRequest{Path: funding, Format: FormatBinance, Kind: IngestFunding, Venue: "binance", Symbol: "BTCUSDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.New != 2 {
+		t.Errorf("expected 2 funding rates, got %+v", result)
+	}
+}
+
+type tradeHistoryStore struct {
+	memoryHistoryStore
+	trades []string
+}
+
+func (s *tradeHistoryStore) WriteMarketTrades(_ context.Context, trades []domain.Trade) (int, error) {
+	for _, tr := range trades {
+		s.trades = append(s.trades, string(tr.Side)+" "+tr.Size.String()+"@"+tr.Price.String()+" "+tr.TradeID)
+	}
+	return len(trades), nil
+}
+
+func TestIngesterGenericTrades(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "trades.csv")
+	content := "time,side,price,size,trade_id\n" +
+		"2024-01-01T00:00:00Z,buy,42000.5,0.1,a1\n" +
+		"1704067201000,SELL,41999,0.25,a2\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &tradeHistoryStore{}
+	result, err := NewIngester(store, logger).Ingest(context.Background(),
+		IngestRequest{Path: path, Format: FormatGeneric, Kind: IngestTrades, Venue: "kcex", Symbol: "BTC/USDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.New != 2 {
+		t.Errorf("expected 2 trades, got %+v", result)
+	}
+	if got := strings.Join(store.trades, ", "); got != "BUY 0.1@42000.5 a1, SELL 0.25@41999 a2" {
+		t.Errorf("unexpected trades: %s", got)
+	}
+}
+
+func TestIngesterRejectsMalformedRows(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	store := &memoryHistoryStore{klines: map[time.Time]bool{}, funding: map[time.Time]bool{}}
+	in := NewIngester(store, logger)
+
+	missing := filepath.Join(dir, "missing.csv")
+	if err := os.WriteFile(missing, []byte("time,price\n2024-01-01T00:00:00Z,1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := in.Ingest(context.Background(), IngestRequest{Path: missing, Format: FormatGeneric, Kind: IngestFunding, Venue: "kcex", Symbol: "BTCUSDT"})
+	if err == nil || !strings.Contains(err.Error(), `"rate"`) {
+		t.Errorf("expected a missing rate column error, got %v", err)
+	}
+
+	bad := filepath.Join(dir, "bad.csv")
+	if err := os.WriteFile(bad, []byte("time,rate\n2024-01-01T00:00:00Z,0.0001\nyesterday,0.0002\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = in.Ingest(context.Background(), IngestRequest{Path: bad, Format: FormatGeneric, Kind: IngestFunding, Venue: "kcex", Symbol: "BTCUSDT"})
+	if err == nil || !strings.Contains(err.Error(), "bad.csv:3") {
+		t.Errorf("expected an error naming line 3, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/marketdata"
+)
+
+// Replay is what a finished replay covered.
+type Replay struct {
+	From, To time.Time // historical time of the first and last event
+	Events   int
+	Clock    *Clock
+}
+
+// Replayer feeds recorded events into the market data service in step with
+// an accelerated clock.
+type Replayer struct {
+	src    EventSource
+	md     *marketdata.Service
+	speed  float64
+	logger *slog.Logger
+}
+
+// NewReplayer replays src into md at speed historical seconds per wall
+// second; speed must be positive.
+func NewReplayer(src EventSource, md *marketdata.Service, speed float64, logger *slog.Logger) *Replayer {
+	return &Replayer{src: src, md: md, speed: speed, logger: logger}
+}
+
+// Run replays every event, each at the wall time the clock maps it to and
+// with its timestamps restamped to wall time, until the source is exhausted
+// or ctx is cancelled. The clock starts at the first event.
+func (r *Replayer) Run(ctx context.Context) (Replay, error) {
+	var replay Replay
+	progress := time.NewTicker(time.Minute)
+	defer progress.Stop()
+
+	for {
+		ev, err := r.src.Next()
+		if errors.Is(err, io.EOF) {
+			return replay, nil
+		}
+		if err != nil {
+			return replay, err
+		}
+		at := ev.At()
+		if replay.Clock == nil {
+			replay.Clock = NewClock(at, time.Now(), r.speed)
+			replay.From = at
+			r.logger.Info("replay started", "from", at, "speed", r.speed)
+		}
+		if wait := time.Until(replay.Clock.Wall(at)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return replay, ctx.Err()
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return replay, ctx.Err()
+		}
+
+		r.publish(ev, replay.Clock)
+		replay.To = at
+		replay.Events++
+
+		select {
+		case <-progress.C:
+			r.logger.Info("replay progress", "at", at, "events", replay.Events)
+		default:
+		}
+	}
+}
+
+// publish restamps ev to wall time and hands it to the market data service.
+func (r *Replayer) publish(ev Event, clock *Clock) {
+	switch {
+	case ev.Book != nil:
+		book := *ev.Book
+		book.VenueTimestamp = clock.Wall(book.VenueTimestamp)
+		r.md.UpdateOrderBook(book)
+	case ev.Trade != nil:
+		trade := *ev.Trade
+		trade.Timestamp = clock.Wall(trade.Timestamp)
+		r.md.RecordTrade(trade)
+	case ev.Funding != nil:
+		rate := *ev.Funding
+		rate.Timestamp = clock.Wall(rate.Timestamp)
+		rate.NextTime = clock.Wall(rate.NextTime)
+		r.md.UpdateFundingRate(rate)
+	}
+}
@@ -0,0 +1,224 @@
+package backtest
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Recorder collects the execution reports and other PnL, such as funding
+// and liquidations, a backtest run produces.
+type Recorder struct {
+	mu      sync.Mutex
+	reports []domain.ExecutionReport
+	other   []pnlEntry
+}
+
+type pnlEntry struct {
+	at     time.Time
+	amount decimal.Decimal
+}
+
+// Run records every execution report from reports until ctx is cancelled or
+// reports is closed.
+func (r *Recorder) Run(ctx context.Context, reports <-chan domain.ExecutionReport) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report, ok := <-reports:
+			if !ok {
+				return
+			}
+			r.mu.Lock()
+			r.reports = append(r.reports, report)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// AddPnL records PnL realized outside execution cycles at wall time at.
+func (r *Recorder) AddPnL(at time.Time, amount decimal.Decimal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.other = append(r.other, pnlEntry{at: at, amount: amount})
+}
+
+// Cycle is one execution cycle of a backtest, at historical time.
+type Cycle struct {
+	At              time.Time
+	Strategy        domain.StrategyType
+	Venue           string
+	Status          string
+	ExpectedEdgeBps decimal.Decimal
+	RealizedEdgeBps decimal.Decimal
+	Notional        decimal.Decimal // first leg's filled notional
+	PnL             decimal.Decimal // realized edge on Notional
+}
+
+// EdgeDistribution summarizes the realized edge, in bps, of filled cycles.
+type EdgeDistribution struct {
+	Mean, P5, P25, P50, P75, P95 float64
+}
+
+// Report is the outcome of a backtest run.
+type Report struct {
+	From, To    time.Time // historical span replayed
+	Events      int
+	Cycles      []Cycle // in historical time order
+	Filled      int     // cycles with a filled first leg
+	Wins        int     // filled cycles with positive PnL
+	CyclePnL    decimal.Decimal
+	OtherPnL    decimal.Decimal // funding and liquidations
+	TotalPnL    decimal.Decimal
+	MaxDrawdown decimal.Decimal // largest fall of cumulative PnL from a peak
+	Edge        EdgeDistribution
+}
+
+// HitRate is the share of filled cycles that made money.
+func (r Report) HitRate() float64 {
+	if r.Filled == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Filled)
+}
+
+// Report builds the report for replay from what has been recorded. A cycle's
+// PnL is its realized edge applied to its first leg's filled notional, the
+// size its expected edge was quoted on.
+func (r *Recorder) Report(replay Replay) Report {
+	r.mu.Lock()
+	reports := append([]domain.ExecutionReport(nil), r.reports...)
+	other := append([]pnlEntry(nil), r.other...)
+	r.mu.Unlock()
+
+	toHist := func(t time.Time) time.Time {
+		if replay.Clock == nil {
+			return t
+		}
+		return replay.Clock.Historical(t)
+	}
+	report := Report{From: replay.From, To: replay.To, Events: replay.Events}
+
+	var (
+		entries []pnlEntry
+		edges   []float64
+	)
+	for _, er := range reports {
+		c := Cycle{
+			At:              toHist(er.CompletedAt),
+			Strategy:        er.Strategy,
+			Venue:           er.Venue,
+			Status:          er.Status,
+			ExpectedEdgeBps: er.ExpectedEdgeBps,
+			RealizedEdgeBps: er.RealizedEdgeBps,
+		}
+		if len(er.Legs) > 0 {
+			c.Notional = er.Legs[0].ActualPrice.Mul(er.Legs[0].ActualSize)
+		}
+		if c.Notional.IsPositive() {
+			c.PnL = c.Notional.Mul(er.RealizedEdgeBps).Div(decimal.NewFromInt(10000))
+			report.Filled++
+			if c.PnL.IsPositive() {
+				report.Wins++
+			}
+			edge, _ := er.RealizedEdgeBps.Float64()
+			edges = append(edges, edge)
+		}
+		report.Cycles = append(report.Cycles, c)
+		report.CyclePnL = report.CyclePnL.Add(c.PnL)
+		entries = append(entries, pnlEntry{at: c.At, amount: c.PnL})
+	}
+	sort.SliceStable(report.Cycles, func(i, j int) bool { return report.Cycles[i].At.Before(report.Cycles[j].At) })
+	for _, e := range other {
+		report.OtherPnL = report.OtherPnL.Add(e.amount)
+		entries = append(entries, pnlEntry{at: toHist(e.at), amount: e.amount})
+	}
+	report.TotalPnL = report.CyclePnL.Add(report.OtherPnL)
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].at.Before(entries[j].at) })
+	cum, peak := decimal.Zero, decimal.Zero
+	for _, e := range entries {
+		cum = cum.Add(e.amount)
+		peak = decimal.Max(peak, cum)
+		report.MaxDrawdown = decimal.Max(report.MaxDrawdown, peak.Sub(cum))
+	}
+
+	report.Edge = edgeDistribution(edges)
+	return report
+}
+
+func edgeDistribution(edges []float64) EdgeDistribution {
+	if len(edges) == 0 {
+		return EdgeDistribution{}
+	}
+	sort.Float64s(edges)
+	sum := 0.0
+	for _, e := range edges {
+		sum += e
+	}
+	// Nearest-rank percentiles.
+	pct := func(p float64) float64 {
+		i := int(math.Ceil(p/100*float64(len(edges)))) - 1
+		return edges[max(i, 0)]
+	}
+	return EdgeDistribution{
+		Mean: sum / float64(len(edges)),
+		P5:   pct(5),
+		P25:  pct(25),
+		P50:  pct(50),
+		P75:  pct(75),
+		P95:  pct(95),
+	}
+}
+
+// WriteText writes the report's summary.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "period\t%s to %s\n", r.From.Format(time.RFC3339), r.To.Format(time.RFC3339))
+	fmt.Fprintf(tw, "events replayed\t%d\n", r.Events)
+	fmt.Fprintf(tw, "cycles\t%d (%d filled)\n", len(r.Cycles), r.Filled)
+	fmt.Fprintf(tw, "hit rate\t%.1f%%\n", r.HitRate()*100)
+	fmt.Fprintf(tw, "cycle pnl\t%s USDT\n", r.CyclePnL.StringFixed(2))
+	fmt.Fprintf(tw, "funding and liquidation pnl\t%s USDT\n", r.OtherPnL.StringFixed(2))
+	fmt.Fprintf(tw, "total pnl\t%s USDT\n", r.TotalPnL.StringFixed(2))
+	fmt.Fprintf(tw, "max drawdown\t%s USDT\n", r.MaxDrawdown.StringFixed(2))
+	e := r.Edge
+	fmt.Fprintf(tw, "realized edge bps\tmean %.2f  p5 %.2f  p25 %.2f  p50 %.2f  p75 %.2f  p95 %.2f\n",
+		e.Mean, e.P5, e.P25, e.P50, e.P75, e.P95)
+	return tw.Flush()
+}
+
+// WriteCSV writes one row per cycle, with the cumulative PnL of cycles so
+// far.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "strategy", "venue", "status", "expected_edge_bps", "realized_edge_bps", "notional_usdt", "pnl_usdt", "cumulative_pnl_usdt"})
+	cum := decimal.Zero
+	for _, c := range r.Cycles {
+		cum = cum.Add(c.PnL)
+		cw.Write([]string{
+			c.At.Format(time.RFC3339Nano),
+			string(c.Strategy),
+			c.Venue,
+			c.Status,
+			c.ExpectedEdgeBps.String(),
+			c.RealizedEdgeBps.String(),
+			c.Notional.StringFixed(2),
+			c.PnL.StringFixed(4),
+			cum.StringFixed(4),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,96 @@
+package backtest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestClockMapsHistoricalToWall(t *testing.T) {
+	hist := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	wall := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewClock(hist, wall, 60)
+
+	if got := clock.Wall(hist.Add(time.Hour)); !got.Equal(wall.Add(time.Minute)) {
+		t.Errorf("Wall(+1h) = %s, want %s", got, wall.Add(time.Minute))
+	}
+	if got := clock.Historical(wall.Add(time.Minute)); !got.Equal(hist.Add(time.Hour)) {
+		t.Errorf("Historical(+1m) = %s, want %s", got, hist.Add(time.Hour))
+	}
+	if !clock.Wall(time.Time{}).IsZero() {
+		t.Error("expected a zero time to stay zero")
+	}
+}
+
+func cycleReport(at time.Time, edgeBps int64) domain.ExecutionReport {
+	return domain.ExecutionReport{
+		Strategy:        domain.StrategyTriArb,
+		Venue:           "kcex",
+		Status:          "completed",
+		ExpectedEdgeBps: decimal.NewFromInt(10),
+		RealizedEdgeBps: decimal.NewFromInt(edgeBps),
+		Legs: []domain.LegExecution{{
+			Symbol:      "BTCUSDT",
+			ActualPrice: decimal.NewFromInt(50000),
+			ActualSize:  decimal.NewFromFloat(0.2),
+		}},
+		CompletedAt: at,
+	}
+}
+
+func TestRecorderReport(t *testing.T) {
+	hist := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	wall := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewClock(hist, wall, 60)
+
+	// Each cycle trades 10000 USDT of notional, so 1 bp is 1 USDT.
+	var r Recorder
+	r.reports = []domain.ExecutionReport{
+		cycleReport(wall.Add(1*time.Second), 10),
+		cycleReport(wall.Add(2*time.Second), -30),
+		cycleReport(wall.Add(4*time.Second), 5),
+		{Status: "aborted", CompletedAt: wall.Add(5 * time.Second)},
+	}
+	r.AddPnL(wall.Add(3*time.Second), decimal.NewFromInt(-5))
+
+	report := r.Report(Replay{From: hist, To: hist.Add(time.Hour), Events: 100, Clock: clock})
+
+	if len(report.Cycles) != 4 || report.Filled != 3 || report.Wins != 2 {
+		t.Fatalf("got %d cycles, %d filled, %d wins; want 4, 3, 2", len(report.Cycles), report.Filled, report.Wins)
+	}
+	if !report.Cycles[0].At.Equal(hist.Add(time.Minute)) {
+		t.Errorf("first cycle at %s, want historical %s", report.Cycles[0].At, hist.Add(time.Minute))
+	}
+	if !report.CyclePnL.Equal(decimal.NewFromInt(-15)) || !report.OtherPnL.Equal(decimal.NewFromInt(-5)) ||
+		!report.TotalPnL.Equal(decimal.NewFromInt(-20)) {
+		t.Errorf("pnl: cycles %s, other %s, total %s; want -15, -5, -20", report.CyclePnL, report.OtherPnL, report.TotalPnL)
+	}
+	// Cumulative 10, -20, -25, -20: the peak of 10 falls to -25.
+	if !report.MaxDrawdown.Equal(decimal.NewFromInt(35)) {
+		t.Errorf("max drawdown %s, want 35", report.MaxDrawdown)
+	}
+	if report.Edge.P50 != 5 || report.Edge.P5 != -30 || report.Edge.P95 != 10 {
+		t.Errorf("edge distribution %+v, want p5 -30, p50 5, p95 10", report.Edge)
+	}
+
+	var csv bytes.Buffer
+	if err := report.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 5 || !strings.HasSuffix(lines[3], ",-15.0000") {
+		t.Errorf("unexpected CSV:\n%s", csv.String())
+	}
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "66.7%") {
+		t.Errorf("expected the hit rate in the summary:\n%s", text.String())
+	}
+}
@@ -0,0 +1,144 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Event is one recorded market data update, a line of a data file. Exactly
+// one of Book, Trade and Funding is set. Books are full snapshots.
+type Event struct {
+	Book    *domain.OrderBookSnapshot `json:",omitempty"`
+	Trade   *domain.Trade             `json:",omitempty"`
+	Funding *domain.FundingRate       `json:",omitempty"`
+}
+
+// At is when the event happened: the book's venue timestamp, or the trade's
+// or funding rate's timestamp.
+func (e Event) At() time.Time {
+	switch {
+	case e.Book != nil:
+		return e.Book.VenueTimestamp
+	case e.Trade != nil:
+		return e.Trade.Timestamp
+	case e.Funding != nil:
+		return e.Funding.Timestamp
+	}
+	return time.Time{}
+}
+
+// EventSource is a time-ordered stream of market data events that ends with
+// io.EOF.
+type EventSource interface {
+	Next() (Event, error)
+}
+
+// Source reads recorded events from one or more JSON Lines files, each in
+// time order, and merges them into a single time-ordered stream.
+type Source struct {
+	files []*eventFile
+}
+
+type eventFile struct {
+	name    string
+	f       *os.File
+	scanner *bufio.Scanner
+	line    int
+	next    *Event // nil once the file is exhausted
+}
+
+// OpenSource opens path, a data file or a directory whose *.jsonl files are
+// all read.
+func OpenSource(path string) (*Source, error) {
+	names := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		if names, err = filepath.Glob(filepath.Join(path, "*.jsonl")); err != nil {
+			return nil, err
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no *.jsonl data files in %s", path)
+	}
+
+	s := &Source{}
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		ef := &eventFile{name: name, f: f, scanner: bufio.NewScanner(f)}
+		ef.scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		s.files = append(s.files, ef)
+		if err := ef.advance(); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Next returns the earliest event not yet returned, or io.EOF once every
+// file is exhausted.
+func (s *Source) Next() (Event, error) {
+	var earliest *eventFile
+	for _, ef := range s.files {
+		if ef.next != nil && (earliest == nil || ef.next.At().Before(earliest.next.At())) {
+			earliest = ef
+		}
+	}
+	if earliest == nil {
+		return Event{}, io.EOF
+	}
+	ev := *earliest.next
+	if err := earliest.advance(); err != nil {
+		return Event{}, err
+	}
+	return ev, nil
+}
+
+func (s *Source) Close() error {
+	var errs []error
+	for _, ef := range s.files {
+		errs = append(errs, ef.f.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// advance reads the file's next event, skipping blank lines.
+func (ef *eventFile) advance() error {
+	prev := ef.next
+	ef.next = nil
+	for ef.scanner.Scan() {
+		ef.line++
+		raw := ef.scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(raw, &ev); err != nil {
+			return fmt.Errorf("%s:%d: %w", ef.name, ef.line, err)
+		}
+		if ev.At().IsZero() {
+			return fmt.Errorf("%s:%d: event has no timestamp", ef.name, ef.line)
+		}
+		if prev != nil && ev.At().Before(prev.At()) {
+			return fmt.Errorf("%s:%d: event out of time order", ef.name, ef.line)
+		}
+		ef.next = &ev
+		return nil
+	}
+	return ef.scanner.Err()
+}
@@ -0,0 +1,86 @@
+package backtest
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeDataFile(t *testing.T, dir, name string, lines ...string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSourceMergesFilesInTimeOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeDataFile(t, dir, "books.jsonl",
+		`{"Book":{"Venue":"kcex","Symbol":"BTCUSDT","VenueTimestamp":"2026-01-01T00:00:00Z"}}`,
+		``,
+		`{"Book":{"Venue":"kcex","Symbol":"BTCUSDT","VenueTimestamp":"2026-01-01T00:00:02Z"}}`,
+	)
+	writeDataFile(t, dir, "trades.jsonl",
+		`{"Trade":{"Venue":"kcex","Symbol":"BTCUSDT","Price":"50000","Size":"1","Timestamp":"2026-01-01T00:00:01Z"}}`,
+		`{"Funding":{"Venue":"kcex","Symbol":"BTCUSDT","Rate":"0.0001","Timestamp":"2026-01-01T00:00:03Z"}}`,
+	)
+	writeDataFile(t, dir, "notes.txt", `not data`)
+
+	src, err := OpenSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var got []time.Duration
+	for {
+		ev, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ev.At().Sub(base))
+	}
+	want := []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second}
+	if len(got) != len(want) {
+		t.Fatalf("got events at %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got events at %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSourceRejectsBadData(t *testing.T) {
+	for name, lines := range map[string][]string{
+		"out of order": {
+			`{"Trade":{"Timestamp":"2026-01-01T00:00:02Z"}}`,
+			`{"Trade":{"Timestamp":"2026-01-01T00:00:01Z"}}`,
+		},
+		"no timestamp": {`{"Trade":{"Symbol":"BTCUSDT"}}`},
+		"bad json":     {`{"Trade":`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data.jsonl")
+			writeDataFile(t, filepath.Dir(path), filepath.Base(path), lines...)
+			src, err := OpenSource(path)
+			for err == nil {
+				_, err = src.Next()
+			}
+			if errors.Is(err, io.EOF) {
+				t.Fatal("expected an error, read to the end")
+			}
+			if src != nil {
+				src.Close()
+			}
+		})
+	}
+}
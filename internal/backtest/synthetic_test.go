@@ -0,0 +1,133 @@
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+)
+
+func syntheticConfig(seed uint64) config.SyntheticConfig {
+	return config.SyntheticConfig{
+		Enabled:   true,
+		Seed:      seed,
+		DurationS: 10,
+		StepMs:    1000,
+		Levels:    3,
+		Instruments: []config.SyntheticInstrumentConfig{
+			{Venue: "kcex", Symbol: "BTC/USDT", StartPrice: 50000},
+			{Venue: "kcex", Symbol: "BTCUSDT", StartPrice: 50000, BasisBps: 20, FundingRate: 0.0001},
+		},
+		Regimes: []config.SyntheticRegimeConfig{
+			{Name: "calm", DurationS: 5, VolatilityBps: 1, SpreadBps: 2, LevelNotional: 10000},
+			{Name: "crash", DurationS: 5, TrendBps: -50, SpreadBps: 20, LevelNotional: 1000, GapProbability: 1, GapBps: 100},
+		},
+	}
+}
+
+func drain(t *testing.T, src EventSource) []Event {
+	t.Helper()
+	var events []Event
+	for {
+		ev, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, ev)
+	}
+}
+
+func TestSyntheticSourceIsReproducible(t *testing.T) {
+	generate := func(seed uint64) string {
+		src, err := NewSyntheticSource(syntheticConfig(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := json.Marshal(drain(t, src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(raw)
+	}
+	if generate(7) != generate(7) {
+		t.Error("expected the same seed to generate the same market")
+	}
+	if generate(7) == generate(8) {
+		t.Error("expected different seeds to generate different markets")
+	}
+}
+
+func TestSyntheticSourceRegimes(t *testing.T) {
+	src, err := NewSyntheticSource(syntheticConfig(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := drain(t, src)
+
+	var prev time.Time
+	books, funding := 0, 0
+	for _, ev := range events {
+		if ev.At().Before(prev) {
+			t.Fatalf("event at %s out of time order", ev.At())
+		}
+		prev = ev.At()
+		switch {
+		case ev.Funding != nil:
+			funding++
+			if ev.Funding.Symbol != "BTCUSDT" {
+				t.Errorf("expected funding for the perp only, got %s", ev.Funding.Symbol)
+			}
+		case ev.Book != nil:
+			books++
+			b := ev.Book
+			if len(b.Bids) != 3 || len(b.Asks) != 3 || !b.Bids[0].Price.LessThan(b.Asks[0].Price) {
+				t.Fatalf("expected an uncrossed 3-level book, got %+v", b)
+			}
+		}
+	}
+	// The starting snapshot and one per step, for both instruments.
+	if books != 22 {
+		t.Errorf("expected 22 books, got %d", books)
+	}
+	if funding != 1 {
+		t.Errorf("expected one funding rate in 10 seconds, got %d", funding)
+	}
+
+	touch := func(at time.Duration) (spreadBps, mid decimal.Decimal) {
+		for _, ev := range events {
+			if ev.Book != nil && ev.Book.Symbol == "BTC/USDT" && ev.At().Equal(syntheticEpoch.Add(at)) {
+				m, _ := ev.Book.MidPrice()
+				return ev.Book.Asks[0].Price.Sub(ev.Book.Bids[0].Price).Div(m).Mul(decimal.NewFromInt(10000)), m
+			}
+		}
+		t.Fatalf("no BTC/USDT book at %s", at)
+		return
+	}
+	calmSpread, calmMid := touch(4 * time.Second)
+	crashSpread, crashMid := touch(9 * time.Second)
+	if calmSpread.Round(0).IntPart() != 2 || crashSpread.Round(0).IntPart() != 20 {
+		t.Errorf("expected spreads of 2 then 20 bps, got %s and %s", calmSpread, crashSpread)
+	}
+	// Each of the five crash steps moves -50 bps of trend and a 100 bps gap
+	// either way, so whichever way the gaps fall the price ends at least
+	// 50 bps from where the calm left it.
+	if moved := crashMid.Sub(calmMid).Div(calmMid).Abs(); moved.LessThan(decimal.RequireFromString("0.0045")) {
+		t.Errorf("expected the crash to move the price, %s -> %s", calmMid, crashMid)
+	}
+}
+
+func TestSyntheticSourceNeedsInstrumentsAndRegimes(t *testing.T) {
+	cfg := syntheticConfig(1)
+	cfg.Regimes = nil
+	if _, err := NewSyntheticSource(cfg); err == nil {
+		t.Error("expected an error without regimes")
+	}
+}
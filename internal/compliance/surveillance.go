@@ -0,0 +1,264 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Rule names a surveillance check.
+type Rule string
+
+const (
+	RuleCancelRatio Rule = "cancel_ratio"
+	RuleSelfMatch   Rule = "self_match"
+	RuleLayering    Rule = "layering"
+)
+
+// Violation is an order refused by a surveillance rule. It is returned as
+// the error from Check.
+type Violation struct {
+	Rule    Rule
+	Venue   string
+	Symbol  string
+	Details string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("surveillance %s on %s:%s: %s", v.Rule, v.Venue, v.Symbol, v.Details)
+}
+
+// restingOrder is one of our orders that can sit on a venue's book.
+type restingOrder struct {
+	side  domain.Side
+	price decimal.Decimal
+}
+
+// symbolActivity is what surveillance knows of our orders on one venue's
+// symbol.
+type symbolActivity struct {
+	placed    []time.Time // within the window, oldest first
+	cancelled []time.Time
+	resting   map[uuid.UUID]restingOrder
+
+	throttledUntil time.Time
+}
+
+// Surveillance watches our own order flow for the patterns venues flag as
+// manipulative, excessive cancelling, self-matching and layering, and
+// refuses orders that would show them before a venue acts. It learns our
+// orders from their state changes and is consulted through Check before an
+// order is sent.
+type Surveillance struct {
+	mu       sync.Mutex
+	cfg      config.SurveillanceConfig
+	activity map[string]*symbolActivity // key: "venue:symbol"
+
+	// lastAlert rate-limits alerts to one per rule and symbol per window.
+	lastAlert map[string]time.Time
+	onAlert   func(v Violation)
+
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+func NewSurveillance(cfg config.SurveillanceConfig, logger *slog.Logger) *Surveillance {
+	return &Surveillance{
+		cfg:       cfg,
+		activity:  make(map[string]*symbolActivity),
+		lastAlert: make(map[string]time.Time),
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// SetAlertCallback registers fn to be called, outside the lock, when a rule
+// trips. Call before orders are submitted.
+func (s *Surveillance) SetAlertCallback(fn func(v Violation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onAlert = fn
+}
+
+// Run feeds OnOrderStateChange from changes until ctx is cancelled or
+// changes is closed.
+func (s *Surveillance) Run(ctx context.Context, changes <-chan domain.OrderStateChange) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			s.OnOrderStateChange(change)
+		}
+	}
+}
+
+func (s *Surveillance) symbol(venue, symbol string) *symbolActivity {
+	key := venue + ":" + symbol
+	a, ok := s.activity[key]
+	if !ok {
+		a = &symbolActivity{resting: make(map[uuid.UUID]restingOrder)}
+		s.activity[key] = a
+	}
+	return a
+}
+
+// rests reports whether an order can sit on the book once placed.
+func rests(orderType domain.OrderType, tif domain.TimeInForce) bool {
+	return orderType == domain.OrderTypeLimit && tif != domain.TimeInForceIOC && tif != domain.TimeInForceFOK
+}
+
+// OnOrderStateChange records placements, cancels and the orders resting on
+// each book, and throttles a symbol whose cancel ratio has gone over the
+// limit.
+func (s *Surveillance) OnOrderStateChange(change domain.OrderStateChange) {
+	order := change.Order
+	now := s.now()
+
+	s.mu.Lock()
+	a := s.symbol(order.Venue, order.Symbol)
+	switch {
+	case change.NewStatus.IsTerminal():
+		delete(a.resting, order.InternalID)
+	case rests(order.OrderType, order.TimeInForce):
+		a.resting[order.InternalID] = restingOrder{side: order.Side, price: order.Price}
+	}
+
+	var tripped *Violation
+	if change.PrevStatus == "" && change.NewStatus == domain.OrderStatusPendingNew {
+		a.placed = append(a.placed, now)
+	}
+	if change.NewStatus == domain.OrderStatusCancelled && !change.PrevStatus.IsTerminal() {
+		a.cancelled = append(a.cancelled, now)
+		tripped = s.checkCancelRatio(order.Venue, order.Symbol, a, now)
+	}
+	alert := s.alertFor(tripped, now)
+	s.mu.Unlock()
+
+	if tripped != nil {
+		s.logger.Warn("surveillance throttling symbol",
+			"venue", tripped.Venue, "symbol", tripped.Symbol, "details", tripped.Details,
+			"throttle", s.cfg.Throttle())
+	}
+	if alert != nil {
+		alert(*tripped)
+	}
+}
+
+// checkCancelRatio throttles the symbol once it has seen MinOrders
+// placements in the window and cancelled more than MaxCancelRatio of them.
+// It returns the violation when the throttle starts. The caller holds s.mu.
+func (s *Surveillance) checkCancelRatio(venue, symbol string, a *symbolActivity, now time.Time) *Violation {
+	cutoff := now.Add(-s.cfg.Window())
+	a.placed = trimBefore(a.placed, cutoff)
+	a.cancelled = trimBefore(a.cancelled, cutoff)
+	if now.Before(a.throttledUntil) || len(a.placed) < s.cfg.MinOrders {
+		return nil
+	}
+	ratio := float64(len(a.cancelled)) / float64(len(a.placed))
+	if ratio <= s.cfg.MaxCancelRatio {
+		return nil
+	}
+	a.throttledUntil = now.Add(s.cfg.Throttle())
+	return &Violation{
+		Rule:    RuleCancelRatio,
+		Venue:   venue,
+		Symbol:  symbol,
+		Details: fmt.Sprintf("%d cancels for %d orders in %s, ratio %.2f > %.2f", len(a.cancelled), len(a.placed), s.cfg.Window(), ratio, s.cfg.MaxCancelRatio),
+	}
+}
+
+func trimBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := sort.Search(len(times), func(i int) bool { return !times[i].Before(cutoff) })
+	return times[i:]
+}
+
+// Check refuses req with a *Violation if it would be sent to a throttled
+// symbol, trade against one of our own resting orders or add a price level
+// past the layering limit. Reduce-only orders are exempt from the throttle
+// so positions can still be closed.
+func (s *Surveillance) Check(req domain.OrderRequest) error {
+	now := s.now()
+
+	s.mu.Lock()
+	v := s.check(req, now)
+	alert := s.alertFor(v, now)
+	s.mu.Unlock()
+
+	if v == nil {
+		return nil
+	}
+	if alert != nil {
+		alert(*v)
+	}
+	return v
+}
+
+func (s *Surveillance) check(req domain.OrderRequest, now time.Time) *Violation {
+	a, ok := s.activity[req.Venue+":"+req.Symbol]
+	if !ok {
+		return nil
+	}
+	violation := func(rule Rule, format string, args ...any) *Violation {
+		return &Violation{Rule: rule, Venue: req.Venue, Symbol: req.Symbol, Details: fmt.Sprintf(format, args...)}
+	}
+
+	if now.Before(a.throttledUntil) && !req.ReduceOnly {
+		return violation(RuleCancelRatio, "throttled for excessive cancels until %s", a.throttledUntil.Format(time.RFC3339))
+	}
+
+	takes := (req.OrderType == domain.OrderTypeLimit || req.OrderType == domain.OrderTypeMarket) && !req.PostOnly
+	if s.cfg.PreventSelfMatch && takes {
+		for _, o := range a.resting {
+			if o.side == req.Side {
+				continue
+			}
+			crosses := req.OrderType == domain.OrderTypeMarket ||
+				req.Side == domain.SideBuy && req.Price.GreaterThanOrEqual(o.price) ||
+				req.Side == domain.SideSell && req.Price.LessThanOrEqual(o.price)
+			if crosses {
+				return violation(RuleSelfMatch, "%s would trade against our resting %s at %s", req.Side, o.side, o.price)
+			}
+		}
+	}
+
+	if s.cfg.MaxRestingLevels > 0 && rests(req.OrderType, req.TimeInForce) {
+		levels := make(map[string]bool)
+		for _, o := range a.resting {
+			if o.side == req.Side {
+				levels[o.price.String()] = true
+			}
+		}
+		if !levels[req.Price.String()] && len(levels) >= s.cfg.MaxRestingLevels {
+			return violation(RuleLayering, "%s at %s would be a resting level past %d", req.Side, req.Price, s.cfg.MaxRestingLevels)
+		}
+	}
+	return nil
+}
+
+// alertFor returns the alert callback to call for v, or nil if there is no
+// violation or the same rule already alerted on the symbol within the
+// window. The caller holds s.mu.
+func (s *Surveillance) alertFor(v *Violation, now time.Time) func(Violation) {
+	if v == nil || s.onAlert == nil {
+		return nil
+	}
+	key := string(v.Rule) + ":" + v.Venue + ":" + v.Symbol
+	if last, ok := s.lastAlert[key]; ok && now.Sub(last) < s.cfg.Window() {
+		return nil
+	}
+	s.lastAlert[key] = now
+	return s.onAlert
+}
@@ -0,0 +1,171 @@
+package compliance
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func newTestSurveillance(cfg config.SurveillanceConfig) (*Surveillance, *time.Time, *[]Violation) {
+	s := NewSurveillance(cfg, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	var alerts []Violation
+	s.SetAlertCallback(func(v Violation) { alerts = append(alerts, v) })
+	return s, &now, &alerts
+}
+
+func limitOrder(side domain.Side, price int64) domain.Order {
+	return domain.Order{
+		InternalID: uuid.Must(uuid.NewV7()),
+		Venue:      "nobitex",
+		Symbol:     "BTC/USDT",
+		Side:       side,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(price),
+		Size:       decimal.NewFromFloat(0.1),
+	}
+}
+
+func place(s *Surveillance, order domain.Order) {
+	s.OnOrderStateChange(domain.OrderStateChange{Order: order, NewStatus: domain.OrderStatusPendingNew})
+	s.OnOrderStateChange(domain.OrderStateChange{Order: order, PrevStatus: domain.OrderStatusPendingNew, NewStatus: domain.OrderStatusAcknowledged})
+}
+
+func cancel(s *Surveillance, order domain.Order) {
+	s.OnOrderStateChange(domain.OrderStateChange{Order: order, PrevStatus: domain.OrderStatusAcknowledged, NewStatus: domain.OrderStatusCancelled})
+}
+
+func request(side domain.Side, price int64) domain.OrderRequest {
+	return domain.OrderRequest{
+		InternalID: uuid.Must(uuid.NewV7()),
+		Venue:      "nobitex",
+		Symbol:     "BTC/USDT",
+		Side:       side,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(price),
+		Size:       decimal.NewFromFloat(0.1),
+	}
+}
+
+func ruleOf(err error) Rule {
+	var v *Violation
+	if errors.As(err, &v) {
+		return v.Rule
+	}
+	return ""
+}
+
+func TestSurveillanceCancelRatio(t *testing.T) {
+	s, now, alerts := newTestSurveillance(config.SurveillanceConfig{
+		Enabled:         true,
+		WindowSeconds:   60,
+		MinOrders:       4,
+		MaxCancelRatio:  0.5,
+		ThrottleSeconds: 30,
+	})
+
+	var orders []domain.Order
+	for i := 0; i < 4; i++ {
+		orders = append(orders, limitOrder(domain.SideBuy, 50000))
+		place(s, orders[i])
+	}
+	for _, order := range orders[:3] {
+		cancel(s, order)
+	}
+	if err := s.Check(request(domain.SideBuy, 50000)); ruleOf(err) != RuleCancelRatio {
+		t.Fatalf("expected the symbol throttled, got %v", err)
+	}
+
+	exit := request(domain.SideSell, 50100)
+	exit.ReduceOnly = true
+	if err := s.Check(exit); err != nil {
+		t.Errorf("expected a reduce-only order let through, got %v", err)
+	}
+	other := request(domain.SideBuy, 3000)
+	other.Symbol = "ETH/USDT"
+	if err := s.Check(other); err != nil {
+		t.Errorf("expected other symbols unaffected, got %v", err)
+	}
+	if len(*alerts) != 1 || (*alerts)[0].Rule != RuleCancelRatio {
+		t.Errorf("expected one cancel ratio alert, got %+v", *alerts)
+	}
+
+	*now = now.Add(31 * time.Second)
+	if err := s.Check(request(domain.SideBuy, 50000)); err != nil {
+		t.Errorf("expected the throttle lifted, got %v", err)
+	}
+}
+
+func TestSurveillanceSelfMatch(t *testing.T) {
+	s, _, _ := newTestSurveillance(config.SurveillanceConfig{Enabled: true, WindowSeconds: 60, PreventSelfMatch: true})
+	place(s, limitOrder(domain.SideSell, 50100))
+
+	if err := s.Check(request(domain.SideBuy, 50100)); ruleOf(err) != RuleSelfMatch {
+		t.Errorf("expected a crossing buy refused, got %v", err)
+	}
+	market := request(domain.SideBuy, 0)
+	market.OrderType = domain.OrderTypeMarket
+	if err := s.Check(market); ruleOf(err) != RuleSelfMatch {
+		t.Errorf("expected a market buy refused, got %v", err)
+	}
+	if err := s.Check(request(domain.SideBuy, 50050)); err != nil {
+		t.Errorf("expected a buy below our offer let through, got %v", err)
+	}
+	maker := request(domain.SideBuy, 50100)
+	maker.PostOnly = true
+	if err := s.Check(maker); err != nil {
+		t.Errorf("expected a post-only buy let through, got %v", err)
+	}
+}
+
+func TestSurveillanceSelfMatchClearsOnFill(t *testing.T) {
+	s, _, _ := newTestSurveillance(config.SurveillanceConfig{Enabled: true, WindowSeconds: 60, PreventSelfMatch: true})
+	ask := limitOrder(domain.SideSell, 50100)
+	place(s, ask)
+	s.OnOrderStateChange(domain.OrderStateChange{Order: ask, PrevStatus: domain.OrderStatusAcknowledged, NewStatus: domain.OrderStatusFilled})
+
+	if err := s.Check(request(domain.SideBuy, 50100)); err != nil {
+		t.Errorf("expected no self-match once our offer filled, got %v", err)
+	}
+}
+
+func TestSurveillanceLayering(t *testing.T) {
+	s, now, alerts := newTestSurveillance(config.SurveillanceConfig{Enabled: true, WindowSeconds: 60, MaxRestingLevels: 2})
+	place(s, limitOrder(domain.SideBuy, 49900))
+	place(s, limitOrder(domain.SideBuy, 49800))
+
+	if err := s.Check(request(domain.SideBuy, 49700)); ruleOf(err) != RuleLayering {
+		t.Fatalf("expected a third bid level refused, got %v", err)
+	}
+	if err := s.Check(request(domain.SideBuy, 49800)); err != nil {
+		t.Errorf("expected joining an existing level let through, got %v", err)
+	}
+	if err := s.Check(request(domain.SideSell, 50200)); err != nil {
+		t.Errorf("expected the other side unaffected, got %v", err)
+	}
+	ioc := request(domain.SideBuy, 49700)
+	ioc.TimeInForce = domain.TimeInForceIOC
+	if err := s.Check(ioc); err != nil {
+		t.Errorf("expected an IOC order let through, got %v", err)
+	}
+
+	// A repeat within the window refuses the order but does not alert again.
+	s.Check(request(domain.SideBuy, 49600))
+	if len(*alerts) != 1 {
+		t.Errorf("expected one layering alert in the window, got %d", len(*alerts))
+	}
+	*now = now.Add(time.Minute)
+	s.Check(request(domain.SideBuy, 49600))
+	if len(*alerts) != 2 {
+		t.Errorf("expected a second alert after the window, got %d", len(*alerts))
+	}
+}
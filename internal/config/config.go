@@ -1,6 +1,16 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -14,13 +24,100 @@ type Config struct {
 	CostModel   CostModelConfig             `mapstructure:"cost_model" validate:"required"`
 	Monitoring  MonitoringConfig            `mapstructure:"monitoring" validate:"required"`
 	DryRun      DryRunConfig                `mapstructure:"dry_run"`
+	Backtest    BacktestConfig              `mapstructure:"backtest"`
+	Replay      ReplayConfig                `mapstructure:"replay"`
 	Persistence PersistenceConfig           `mapstructure:"persistence" validate:"required"`
 	Runtime     RuntimeConfig               `mapstructure:"runtime"`
 }
 
+// Hash fingerprints the effective configuration, after defaults and
+// environment overrides, so two instances report the same hash only if they
+// run with the same settings. Credentials live in the environment, not here.
+func (c *Config) Hash() string {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ChangedKeys returns the config keys, dotted as in the YAML file (for
+// example "risk.daily_loss_cap_usdt" or "venues.kcex.enabled"), whose values
+// differ between old and new, sorted. Only keys are reported, never values.
+func ChangedKeys(old, new *Config) []string {
+	var keys []string
+	diffValue(reflect.ValueOf(*old), reflect.ValueOf(*new), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+var configPkg = reflect.TypeOf(Config{}).PkgPath()
+
+func diffValue(a, b reflect.Value, key string, keys *[]string) {
+	t := a.Type()
+	switch {
+	case t.Kind() == reflect.Struct && t.PkgPath() == configPkg:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			diffValue(a.Field(i), b.Field(i), joinKey(key, name), keys)
+		}
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String &&
+		t.Elem().Kind() == reflect.Struct && t.Elem().PkgPath() == configPkg:
+		seen := make(map[string]bool)
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			name := k.String()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			if !av.IsValid() || !bv.IsValid() {
+				*keys = append(*keys, joinKey(key, name))
+				continue
+			}
+			diffValue(av, bv, joinKey(key, name), keys)
+		}
+	default:
+		// Leaves compare by their JSON encoding, as Hash does, so decimals
+		// that are equal but written differently are not reported.
+		aj, _ := json.Marshal(a.Interface())
+		bj, _ := json.Marshal(b.Interface())
+		if !bytes.Equal(aj, bj) {
+			*keys = append(*keys, key)
+		}
+	}
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// EnabledStrategies returns the config keys of the enabled strategies.
+func (c *Config) EnabledStrategies() []string {
+	var names []string
+	if c.Strategies.TriangularArb.Enabled {
+		names = append(names, "triangular_arb")
+	}
+	if c.Strategies.BasisArb.Enabled {
+		names = append(names, "basis_arb")
+	}
+	if c.Strategies.CrossVenueArb.Enabled {
+		names = append(names, "cross_venue_arb")
+	}
+	return names
+}
+
 type SystemConfig struct {
 	InstanceID              string `mapstructure:"instance_id" validate:"required"`
-	TradingMode             string `mapstructure:"trading_mode" validate:"required,oneof=live dry_run backtest"`
+	TradingMode             string `mapstructure:"trading_mode" validate:"required,oneof=live dry_run backtest replay"`
 	RequireLiveConfirmation bool   `mapstructure:"require_live_confirmation"`
 	LogLevel                string `mapstructure:"log_level" validate:"required,oneof=DEBUG INFO WARN ERROR FATAL"`
 	Timezone                string `mapstructure:"timezone" validate:"required"`
@@ -28,10 +125,80 @@ type SystemConfig struct {
 
 type VenueConfig struct {
 	Enabled    bool                          `mapstructure:"enabled"`
-	WsURL      string                        `mapstructure:"ws_url" validate:"required_if=Enabled true,omitempty,url"`
-	RestURL    string                        `mapstructure:"rest_url" validate:"required_if=Enabled true,omitempty,url"`
+	WsURL      string                        `mapstructure:"ws_url" validate:"required_if=Enabled true Protocol '',omitempty,url"`
+	RestURL    string                        `mapstructure:"rest_url" validate:"required_if=Enabled true Protocol '',omitempty,url"`
+	// FuturesWsURL and FuturesRestURL are used by venues that serve
+	// perpetuals from a separate host (e.g. Binance USD-M futures).
+	FuturesWsURL   string                    `mapstructure:"futures_ws_url" validate:"omitempty,url"`
+	FuturesRestURL string                    `mapstructure:"futures_rest_url" validate:"omitempty,url"`
 	RateLimits map[string]RateLimitConfig     `mapstructure:"rate_limits"`
 	Symbols    VenueSymbolsConfig            `mapstructure:"symbols"`
+	// SymbolMap adds to or overrides the venue's built-in symbol mappings,
+	// so a newly listed pair can be traded without a release.
+	SymbolMap  []SymbolMapping               `mapstructure:"symbol_map" validate:"dive"`
+	// SubAccount names the sub-account to trade on. Its credentials are read
+	// from <VENUE>_<SUB_ACCOUNT>_API_KEY etc. instead of the main-account ones.
+	SubAccount string                        `mapstructure:"sub_account"`
+	// StrategyAccounts routes a strategy's orders on this venue to its own
+	// sub-account; strategies not listed trade on SubAccount.
+	StrategyAccounts []StrategyAccountConfig `mapstructure:"strategy_accounts" validate:"dive"`
+	// APIKeys spreads the venue's REST calls over several API keys, each
+	// with its own rate limit budget. A key's credentials are read from
+	// <VENUE>[_<SUB_ACCOUNT>]_<NAME>_API_KEY etc. When empty, the single
+	// <VENUE>[_<SUB_ACCOUNT>]_API_KEY is used.
+	APIKeys    []APIKeyConfig                `mapstructure:"api_keys" validate:"dive"`
+	// Proxy routes the venue's REST and WebSocket traffic through an http,
+	// https or socks5 proxy, for venues that need egress from a given region.
+	Proxy      string                        `mapstructure:"proxy" validate:"omitempty,url"`
+	// Interface binds the venue's outbound connections to a local interface
+	// name or IP address.
+	Interface  string                        `mapstructure:"interface"`
+	// WSConnections spreads the venue's market data subscriptions over this
+	// many WebSocket connections, for venues where one connection falls
+	// behind with many books. 0 or 1 uses a single connection.
+	WSConnections int                        `mapstructure:"ws_connections" validate:"gte=0"`
+	// MaxBookDepth keeps at most this many levels a side of the venue's
+	// books, dropping deeper ones as they arrive; BookDepth sets it for
+	// individual symbols. 0 keeps every level. Books kept to fewer levels
+	// than the venue's checksum covers (20) are not checksum validated.
+	MaxBookDepth int               `mapstructure:"max_book_depth" validate:"gte=0"`
+	BookDepth    []BookDepthConfig `mapstructure:"book_depth" validate:"dive"`
+	// Protocol selects a generic gateway instead of the venue's native API:
+	// "fix" connects over FIX 4.4 as configured under FIX, "grpc" forwards
+	// to an out-of-process gateway plugin as configured under GRPC.
+	Protocol string     `mapstructure:"protocol" validate:"omitempty,oneof=fix grpc"`
+	FIX      FIXConfig  `mapstructure:"fix"`
+	GRPC     GRPCConfig `mapstructure:"grpc"`
+}
+
+// GRPCConfig locates a gateway plugin process serving the
+// trading.gateway.v1.VenueGateway service.
+type GRPCConfig struct {
+	Addr string `mapstructure:"addr" validate:"omitempty,hostname_port"`
+	TLS  bool   `mapstructure:"tls"`
+}
+
+// FIXConfig describes a FIX 4.4 counterparty. The logon username and
+// password are read from <VENUE>_FIX_USERNAME and <VENUE>_FIX_PASSWORD.
+type FIXConfig struct {
+	MarketDataAddr string `mapstructure:"market_data_addr" validate:"omitempty,hostname_port"`
+	OrderEntryAddr string `mapstructure:"order_entry_addr" validate:"omitempty,hostname_port"`
+	SenderCompID   string `mapstructure:"sender_comp_id"`
+	TargetCompID   string `mapstructure:"target_comp_id"`
+	// MarketDataSenderCompID and MarketDataTargetCompID default to the
+	// order entry CompIDs.
+	MarketDataSenderCompID string  `mapstructure:"market_data_sender_comp_id"`
+	MarketDataTargetCompID string  `mapstructure:"market_data_target_comp_id"`
+	Account                string  `mapstructure:"account"`
+	HeartbeatSeconds       int     `mapstructure:"heartbeat_seconds" validate:"gte=0"`
+	TLS                    bool    `mapstructure:"tls"`
+	Depth                  int     `mapstructure:"depth" validate:"gte=0"`
+	MakerFeeBps            float64 `mapstructure:"maker_fee_bps"`
+	TakerFeeBps            float64 `mapstructure:"taker_fee_bps"`
+}
+
+func (c FIXConfig) Heartbeat() time.Duration {
+	return time.Duration(c.HeartbeatSeconds) * time.Second
 }
 
 type RateLimitConfig struct {
@@ -39,14 +206,159 @@ type RateLimitConfig struct {
 	RefillPerSecond int `mapstructure:"refill_per_second" validate:"required,gt=0"`
 }
 
+// APIKeyConfig names one of a venue's API keys. Weight is the key's share
+// of the requests it can serve. Role "trade" keys only place and cancel
+// orders, "read" keys serve everything else, and keys without a role serve
+// both.
+// StrategyAccountConfig puts one strategy on a venue sub-account. The
+// account's credentials are read as for VenueConfig.SubAccount.
+type StrategyAccountConfig struct {
+	Strategy   string `mapstructure:"strategy" validate:"required,oneof=TRI_ARB BASIS_ARB CROSS_VENUE_ARB"`
+	SubAccount string `mapstructure:"sub_account" validate:"required"`
+}
+
+type APIKeyConfig struct {
+	Name   string `mapstructure:"name" validate:"required"`
+	Weight int    `mapstructure:"weight" validate:"gte=0"`
+	Role   string `mapstructure:"role" validate:"omitempty,oneof=trade read"`
+}
+
 type VenueSymbolsConfig struct {
 	Spot []string `mapstructure:"spot"`
 	Perp []string `mapstructure:"perp"`
 }
 
+// BookDepthConfig overrides the venue's max_book_depth for one symbol.
+type BookDepthConfig struct {
+	Symbol    string `mapstructure:"symbol" validate:"required"`
+	MaxLevels int    `mapstructure:"max_levels" validate:"gte=0"`
+}
+
+// SymbolMapping maps an internal symbol to the venue's symbol for it. It is
+// a list entry rather than a map key because config keys are lowercased.
+type SymbolMapping struct {
+	Symbol      string `mapstructure:"symbol" validate:"required"`
+	VenueSymbol string `mapstructure:"venue_symbol" validate:"required"`
+	Type        string `mapstructure:"type" validate:"required,oneof=spot perp"`
+}
+
 type StrategiesConfig struct {
 	TriangularArb TriArbConfig `mapstructure:"triangular_arb"`
 	BasisArb      BasisArbConfig `mapstructure:"basis_arb"`
+	CrossVenueArb CrossVenueArbConfig `mapstructure:"cross_venue_arb"`
+	// LiquidityTiers groups base assets by how fast their books fill, e.g.
+	// majors: [BTC, ETH]. Strategies set a fill timeout per tier; assets in
+	// no tier use the strategy's fill_timeout_ms.
+	LiquidityTiers map[string][]string `mapstructure:"liquidity_tiers"`
+	ExternalSignals ExternalSignalsConfig `mapstructure:"external_signals"`
+	LatencyCompensation LatencyCompensationConfig `mapstructure:"latency_compensation"`
+	// External lists strategies that run in processes of their own.
+	External []ExternalStrategyConfig `mapstructure:"external" validate:"dive"`
+	// PriceScales sets the decimal places strategies keep of a symbol's
+	// prices in fixed-point arithmetic, where the built-in scale does not
+	// suit it.
+	PriceScales []PriceScaleConfig `mapstructure:"price_scales" validate:"dive"`
+}
+
+// PriceScaleConfig is the fixed-point price scale of one symbol. It is a
+// list entry rather than a map key because config keys are lowercased.
+type PriceScaleConfig struct {
+	Symbol string `mapstructure:"symbol" validate:"required"`
+	Scale  int32  `mapstructure:"scale" validate:"gte=0,lte=18"`
+}
+
+// PriceScaleMap returns the configured price scales keyed by symbol.
+func (c StrategiesConfig) PriceScaleMap() map[string]int32 {
+	scales := make(map[string]int32, len(c.PriceScales))
+	for _, ps := range c.PriceScales {
+		scales[ps.Symbol] = ps.Scale
+	}
+	return scales
+}
+
+// CrossVenueArbConfig buys a spot symbol on the venue quoting the lowest
+// offer and sells it on the venue quoting the highest bid. Both legs' costs
+// and the cost model's transfer amortization come out of the edge before it
+// is held to MinNetEdgeBps. Signals are sized to the smaller touch, capped
+// at MaxNotionalUSDT (0 is uncapped).
+type CrossVenueArbConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`
+	Symbols         []string `mapstructure:"symbols" validate:"required_if=Enabled true"`
+	MinNetEdgeBps   int      `mapstructure:"min_net_edge_bps" validate:"gt=0"`
+	MaxNotionalUSDT float64  `mapstructure:"max_notional_usdt" validate:"gte=0"`
+	FillTimeoutMs   int      `mapstructure:"fill_timeout_ms" validate:"gt=0"`
+	MinAtomicity    float64  `mapstructure:"min_atomicity" validate:"gte=0,lte=1"`
+}
+
+func (c CrossVenueArbConfig) FillTimeout() time.Duration {
+	return time.Duration(c.FillTimeoutMs) * time.Millisecond
+}
+
+// ExternalStrategyConfig is a strategy running in another process, reached
+// over gRPC at Addr (host:port, or unix:///path for a Unix socket). It only
+// sees market data for Venues and Symbols (every symbol if empty), and its
+// signals must stay within them and the limits below, where zero means no
+// limit. Its signals run in shadow execution unless it is marked live.
+type ExternalStrategyConfig struct {
+	Name                string   `mapstructure:"name" validate:"required"`
+	Addr                string   `mapstructure:"addr" validate:"required"`
+	TLS                 bool     `mapstructure:"tls"`
+	Live                bool     `mapstructure:"live"`
+	Venues              []string `mapstructure:"venues" validate:"required,min=1"`
+	Symbols             []string `mapstructure:"symbols"`
+	MaxLegs             int      `mapstructure:"max_legs" validate:"gte=0"`
+	MaxNotionalUSDT     float64  `mapstructure:"max_notional_usdt" validate:"gte=0"`
+	MaxSignalsPerMinute int      `mapstructure:"max_signals_per_minute" validate:"gte=0"`
+	SignalTimeoutMs     int      `mapstructure:"signal_timeout_ms" validate:"gte=0"`
+	QueueSize           int      `mapstructure:"queue_size" validate:"gte=0"`
+}
+
+func (c ExternalStrategyConfig) SignalTimeout() time.Duration {
+	return time.Duration(c.SignalTimeoutMs) * time.Millisecond
+}
+
+// LatencyCompensationConfig moves the limit price of aggressive legs ahead
+// by the drift the venue's mid typically shows between sending an order and
+// its ack, measured over the last Samples acks. Nothing is shifted until
+// MinSamples acks are measured, and never by more than MaxBps or the
+// signal's expected edge.
+type LatencyCompensationConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	MaxBps     float64 `mapstructure:"max_bps" validate:"gte=0"`
+	Samples    int     `mapstructure:"samples" validate:"gt=0"`
+	MinSamples int     `mapstructure:"min_samples" validate:"gte=0"`
+}
+
+// ExternalSignalsConfig lets vetted external systems submit signals to
+// POST /admin/signals. A source's signals run in shadow execution (simulated
+// fills, no orders sent) unless it is marked live.
+type ExternalSignalsConfig struct {
+	Enabled bool                   `mapstructure:"enabled"`
+	Sources []ExternalSignalSource `mapstructure:"sources" validate:"required_if=Enabled true,dive"`
+}
+
+// ExternalSignalSource names a submitter and the environment variable that
+// holds its HMAC secret.
+type ExternalSignalSource struct {
+	Name      string `mapstructure:"name" validate:"required"`
+	SecretEnv string `mapstructure:"secret_env" validate:"required"`
+	Live      bool   `mapstructure:"live"`
+}
+
+// AssetFillTimeouts resolves per-tier fill timeouts to the assets in each
+// tier. It fails on a tier that is not defined in LiquidityTiers.
+func (c StrategiesConfig) AssetFillTimeouts(tierTimeoutsMs map[string]int) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for tier, ms := range tierTimeoutsMs {
+		assets, ok := c.LiquidityTiers[tier]
+		if !ok {
+			return nil, fmt.Errorf("unknown liquidity tier %q", tier)
+		}
+		for _, asset := range assets {
+			timeouts[asset] = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return timeouts, nil
 }
 
 type TriArbConfig struct {
@@ -56,13 +368,36 @@ type TriArbConfig struct {
 	SlippageBufferBps     int  `mapstructure:"slippage_buffer_bps" validate:"gte=0"`
 	ExecutionRiskBufferBps int `mapstructure:"execution_risk_buffer_bps" validate:"gte=0"`
 	FillTimeoutMs         int  `mapstructure:"fill_timeout_ms" validate:"gt=0"`
+	// TierFillTimeoutsMs overrides FillTimeoutMs for assets in a liquidity
+	// tier, keyed by tier name.
+	TierFillTimeoutsMs map[string]int `mapstructure:"tier_fill_timeouts_ms" validate:"dive,gt=0"`
 	MaxRetries            int  `mapstructure:"max_retries" validate:"gte=0"`
+	// MinAtomicity is the lowest estimated probability of all legs filling
+	// within the timeout at which a signal is still executed. 0 disables it.
+	MinAtomicity float64           `mapstructure:"min_atomicity" validate:"gte=0,lte=1"`
+	AdverseFlow  AdverseFlowConfig `mapstructure:"adverse_flow"`
 }
 
 func (c TriArbConfig) FillTimeout() time.Duration {
 	return time.Duration(c.FillTimeoutMs) * time.Millisecond
 }
 
+// AdverseFlowConfig holds tri-arb signals back while a leg's book leans
+// against the leg by more than MaxImbalance over its top DepthLevels, or
+// taker flow over the last WindowMs runs against it by more than
+// MaxPressure. Both are fractions from 0 to 1; 0 disables the check.
+type AdverseFlowConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	DepthLevels  int     `mapstructure:"depth_levels" validate:"gt=0"`
+	WindowMs     int     `mapstructure:"window_ms" validate:"gt=0"`
+	MaxImbalance float64 `mapstructure:"max_imbalance" validate:"gte=0,lte=1"`
+	MaxPressure  float64 `mapstructure:"max_pressure" validate:"gte=0,lte=1"`
+}
+
+func (c AdverseFlowConfig) Window() time.Duration {
+	return time.Duration(c.WindowMs) * time.Millisecond
+}
+
 type BasisArbConfig struct {
 	Enabled                        bool `mapstructure:"enabled"`
 	MinNetEdgeBps                  int  `mapstructure:"min_net_edge_bps" validate:"gt=0"`
@@ -71,13 +406,70 @@ type BasisArbConfig struct {
 	FundingUncertaintyBufferBps    int  `mapstructure:"funding_uncertainty_buffer_bps" validate:"gte=0"`
 	TransferCostAmortizationBps    int  `mapstructure:"transfer_cost_amortization_bps" validate:"gte=0"`
 	FillTimeoutMs                  int  `mapstructure:"fill_timeout_ms" validate:"gt=0"`
+	TierFillTimeoutsMs             map[string]int `mapstructure:"tier_fill_timeouts_ms" validate:"dive,gt=0"`
 	HoldingHorizonHours            int  `mapstructure:"holding_horizon_hours" validate:"gt=0"`
+	MinAtomicity                   float64 `mapstructure:"min_atomicity" validate:"gte=0,lte=1"`
+	PassiveEntry                   PassiveEntryConfig `mapstructure:"passive_entry"`
+	FundingTiming                  FundingTimingConfig `mapstructure:"funding_timing"`
+	CrossVenue                     CrossVenueConfig `mapstructure:"cross_venue"`
 }
 
 func (c BasisArbConfig) FillTimeout() time.Duration {
 	return time.Duration(c.FillTimeoutMs) * time.Millisecond
 }
 
+// PassiveEntryConfig controls passive basis entries: the spot leg rests as a
+// post-only quote at the touch and each fill is hedged on the perp as it
+// arrives. Signals below MinNotionalUSDT are still executed aggressively,
+// except on venues that pay a maker rebate where the quote is estimated to
+// fill with at least RebateMinFillProbability (0 disables that).
+type PassiveEntryConfig struct {
+	Enabled                  bool    `mapstructure:"enabled"`
+	MinNotionalUSDT          float64 `mapstructure:"min_notional_usdt" validate:"gte=0"`
+	RefreshMs                int     `mapstructure:"refresh_ms" validate:"gte=0"`
+	TimeoutMs                int     `mapstructure:"timeout_ms" validate:"gte=0"`
+	RebateMinFillProbability float64 `mapstructure:"rebate_min_fill_probability" validate:"gte=0,lte=1"`
+}
+
+// Refresh is how often the quote is repriced and new fills hedged.
+func (c PassiveEntryConfig) Refresh() time.Duration {
+	return time.Duration(c.RefreshMs) * time.Millisecond
+}
+
+// Timeout is how long the quote is worked before the rest is cancelled.
+func (c PassiveEntryConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+// FundingTimingConfig times perp legs that land within WindowMs before a
+// funding snapshot. Delay holds a cycle whose perp legs would pay the funding
+// until SettleMs after the snapshot; Accelerate sends a cycle whose perp legs
+// would collect it straight away instead of working it passively.
+type FundingTimingConfig struct {
+	Delay      bool `mapstructure:"delay"`
+	Accelerate bool `mapstructure:"accelerate"`
+	WindowMs   int  `mapstructure:"window_ms" validate:"gte=0"`
+	SettleMs   int  `mapstructure:"settle_ms" validate:"gte=0"`
+}
+
+// Window is how long before a snapshot perp legs are timed.
+func (c FundingTimingConfig) Window() time.Duration {
+	return time.Duration(c.WindowMs) * time.Millisecond
+}
+
+// Settle is how long after a snapshot a held cycle waits.
+func (c FundingTimingConfig) Settle() time.Duration {
+	return time.Duration(c.SettleMs) * time.Millisecond
+}
+
+// CrossVenueConfig lets a basis pair whose spot or perp market is blocked on
+// a venue take that leg on another venue where it is healthy. Such a trade
+// must clear ExtraEdgeBps more net edge than MinNetEdgeBps.
+type CrossVenueConfig struct {
+	Enabled      bool `mapstructure:"enabled"`
+	ExtraEdgeBps int  `mapstructure:"extra_edge_bps" validate:"gte=0"`
+}
+
 type RiskConfig struct {
 	MaxPosition          map[string]decimal.Decimal `mapstructure:"max_position" validate:"required"`
 	MaxNotionalPerVenue  map[string]decimal.Decimal `mapstructure:"max_notional_per_venue" validate:"required"`
@@ -87,12 +479,133 @@ type RiskConfig struct {
 	DataFreshness        DataFreshnessConfig        `mapstructure:"data_freshness" validate:"required"`
 	Reconciliation       ReconciliationConfig       `mapstructure:"reconciliation" validate:"required"`
 	CheckpointIntervalS  int                        `mapstructure:"checkpoint_interval_seconds" validate:"required,gt=0"`
+	Stress               StressConfig               `mapstructure:"stress"`
+	CorrelationGroups    map[string]CorrelationGroupConfig `mapstructure:"correlation_groups" validate:"dive"`
+	ErrorBudget          ErrorBudgetConfig          `mapstructure:"error_budget"`
+	MarginUtilization    MarginUtilizationConfig    `mapstructure:"margin_utilization"`
+	Surveillance         SurveillanceConfig         `mapstructure:"surveillance"`
+	Volatility           VolatilityConfig           `mapstructure:"volatility"`
+}
+
+// VolatilityConfig sets up the realized volatility estimator, an EWMA of
+// squared trade-to-trade returns with a half-life of HalfLifeSeconds that
+// reports a symbol once it has seen MinTrades trades, and what reads it.
+// Signals with a leg above MaxAnnualizedPct annualized volatility are
+// rejected (0 disables the circuit breaker), and strategy edge thresholds
+// rise by EdgeBpsPerVolPct for each point of annualized volatility, up to
+// MaxExtraEdgeBps (0 disables dynamic edge; a zero cap is uncapped).
+type VolatilityConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	HalfLifeSeconds  int     `mapstructure:"half_life_seconds" validate:"gt=0"`
+	MinTrades        int     `mapstructure:"min_trades" validate:"gte=1"`
+	MaxAnnualizedPct float64 `mapstructure:"max_annualized_pct" validate:"gte=0"`
+	EdgeBpsPerVolPct float64 `mapstructure:"edge_bps_per_vol_pct" validate:"gte=0"`
+	MaxExtraEdgeBps  int     `mapstructure:"max_extra_edge_bps" validate:"gte=0"`
+}
+
+func (c VolatilityConfig) HalfLife() time.Duration {
+	return time.Duration(c.HalfLifeSeconds) * time.Second
+}
+
+// SurveillanceConfig sets the self-checks run on our own orders for the
+// patterns venues flag as manipulative, so they are stopped before a venue
+// acts on them. Over WindowSeconds, once MinOrders orders have been placed
+// on a venue's symbol, a cancel ratio above MaxCancelRatio throttles new
+// orders there for ThrottleSeconds. Orders that would trade against one of
+// our own resting orders are refused when PreventSelfMatch is set, and
+// orders that would rest on more than MaxRestingLevels price levels of one
+// side are refused as layering (0 disables the check).
+type SurveillanceConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	WindowSeconds    int     `mapstructure:"window_seconds" validate:"gt=0"`
+	MinOrders        int     `mapstructure:"min_orders" validate:"gte=1"`
+	MaxCancelRatio   float64 `mapstructure:"max_cancel_ratio" validate:"gt=0,lte=1"`
+	ThrottleSeconds  int     `mapstructure:"throttle_seconds" validate:"gte=0"`
+	PreventSelfMatch bool    `mapstructure:"prevent_self_match"`
+	MaxRestingLevels int     `mapstructure:"max_resting_levels" validate:"gte=0"`
+}
+
+func (c SurveillanceConfig) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+func (c SurveillanceConfig) Throttle() time.Duration {
+	return time.Duration(c.ThrottleSeconds) * time.Second
+}
+
+// MarginUtilizationConfig rejects signals whose perp legs would take a
+// venue's initial margin past MaxUtilizationPct of its collateral, before
+// the venue rejects the orders. Venues without an entry are not checked.
+type MarginUtilizationConfig struct {
+	Enabled           bool                         `mapstructure:"enabled"`
+	MaxUtilizationPct float64                      `mapstructure:"max_utilization_pct" validate:"gt=0,lte=100"`
+	Venues            map[string]VenueMarginConfig `mapstructure:"venues" validate:"dive"`
+}
+
+// VenueMarginConfig is a venue's perp collateral and margin schedule. Each
+// position's margin is charged tier by tier, as venues' notional brackets
+// are: the rates of a tier apply to the part of the notional inside it.
+type VenueMarginConfig struct {
+	CollateralUSDT decimal.Decimal    `mapstructure:"collateral_usdt" validate:"required"`
+	Tiers          []MarginTierConfig `mapstructure:"tiers" validate:"required,min=1,dive"`
+}
+
+// MarginTierConfig is one notional bracket of a margin schedule, up to
+// MaxNotionalUSDT (unbounded for the last tier when zero).
+type MarginTierConfig struct {
+	MaxNotionalUSDT decimal.Decimal `mapstructure:"max_notional_usdt"`
+	InitialPct      float64         `mapstructure:"initial_pct" validate:"gt=0,lte=100"`
+	MaintenancePct  float64         `mapstructure:"maintenance_pct" validate:"gte=0,lte=100"`
+}
+
+// CorrelationGroupConfig caps the combined exposure of assets that tend to
+// move together, which per-asset limits understate.
+type CorrelationGroupConfig struct {
+	Assets          []string        `mapstructure:"assets" validate:"required,min=2"`
+	MaxExposureUSDT decimal.Decimal `mapstructure:"max_exposure_usdt" validate:"required"`
 }
 
 func (c RiskConfig) CheckpointInterval() time.Duration {
 	return time.Duration(c.CheckpointIntervalS) * time.Second
 }
 
+// StressConfig defines the default what-if scenarios run against current
+// positions, both on demand and in the nightly report.
+type StressConfig struct {
+	PriceShocksPct      []float64 `mapstructure:"price_shocks_pct"`
+	FundingFlip         bool      `mapstructure:"funding_flip"`
+	FrozenVenueShockPct float64   `mapstructure:"frozen_venue_shock_pct" validate:"gte=0"`
+	NightlyReportHour   int       `mapstructure:"nightly_report_hour" validate:"gte=0,lt=24"`
+}
+
+// ErrorBudgetConfig sets the SLOs whose combined error budget decides when
+// the system drops into conservative mode. Each target is the percentage of
+// events over the window that must be good: order acks within AckLatencyMs,
+// fresh market data samples, and orders not rejected by the venue.
+type ErrorBudgetConfig struct {
+	Enabled            bool    `mapstructure:"enabled"`
+	WindowMinutes      int     `mapstructure:"window_minutes" validate:"required_if=Enabled true,gte=0"`
+	AckLatencyMs       int     `mapstructure:"ack_latency_ms" validate:"required_if=Enabled true,gte=0"`
+	LatencyTargetPct   float64 `mapstructure:"latency_target_pct" validate:"gte=0,lt=100"`
+	FreshnessTargetPct float64 `mapstructure:"freshness_target_pct" validate:"gte=0,lt=100"`
+	RejectTargetPct    float64 `mapstructure:"reject_target_pct" validate:"gte=0,lt=100"`
+	// RecoverPct is the share of the budget that must be left again before
+	// conservative mode is lifted, so the mode does not flap at the edge.
+	RecoverPct float64 `mapstructure:"recover_pct" validate:"gte=0,lte=100"`
+	// In conservative mode strategy edge thresholds are multiplied by
+	// EdgeMultiplier and signal sizes by SizeFactor.
+	EdgeMultiplier float64 `mapstructure:"edge_multiplier" validate:"omitempty,gte=1"`
+	SizeFactor     float64 `mapstructure:"size_factor" validate:"omitempty,gt=0,lte=1"`
+}
+
+func (c ErrorBudgetConfig) Window() time.Duration {
+	return time.Duration(c.WindowMinutes) * time.Minute
+}
+
+func (c ErrorBudgetConfig) AckLatency() time.Duration {
+	return time.Duration(c.AckLatencyMs) * time.Millisecond
+}
+
 type MaxOpenOrdersConfig struct {
 	Global    int `mapstructure:"global" validate:"required,gt=0"`
 	PerVenue  int `mapstructure:"per_venue" validate:"required,gt=0"`
@@ -100,8 +613,72 @@ type MaxOpenOrdersConfig struct {
 }
 
 type DataFreshnessConfig struct {
-	WarningMs int `mapstructure:"warning_ms" validate:"required,gt=0"`
-	BlockMs   int `mapstructure:"block_ms" validate:"required,gt=0"`
+	WarningMs    int                `mapstructure:"warning_ms" validate:"required,gt=0"`
+	BlockMs      int                `mapstructure:"block_ms" validate:"required,gt=0"`
+	RESTFallback RESTFallbackConfig `mapstructure:"rest_fallback"`
+	// ChecksumEvery validates a book against the venue's checksum once per
+	// this many deltas; 0 disables validation.
+	ChecksumEvery int `mapstructure:"checksum_every" validate:"gte=0"`
+	// Sanity drops book updates that are crossed, priced at or below zero,
+	// or too far from the last trade.
+	Sanity SanityConfig `mapstructure:"sanity"`
+	// Funding replaces warning_ms and block_ms for funding-rate feeds,
+	// which venues refresh far less often than books.
+	Funding FreshnessThresholds `mapstructure:"funding"`
+	// Overrides set thresholds for individual venues and symbols.
+	Overrides []FreshnessOverride `mapstructure:"overrides" validate:"dive"`
+}
+
+type FreshnessThresholds struct {
+	WarningMs int `mapstructure:"warning_ms" validate:"gt=0"`
+	BlockMs   int `mapstructure:"block_ms" validate:"gt=0"`
+}
+
+func (c FreshnessThresholds) WarningDuration() time.Duration {
+	return time.Duration(c.WarningMs) * time.Millisecond
+}
+
+func (c FreshnessThresholds) BlockDuration() time.Duration {
+	return time.Duration(c.BlockMs) * time.Millisecond
+}
+
+// FreshnessOverride applies its thresholds to one feed type ("book" by
+// default, or "funding") on the given venue and symbol. Either may be left
+// empty to match all; the most specific matching override wins.
+type FreshnessOverride struct {
+	Feed      string `mapstructure:"feed" validate:"omitempty,oneof=book funding"`
+	Venue     string `mapstructure:"venue"`
+	Symbol    string `mapstructure:"symbol" validate:"required_without=Venue"`
+	WarningMs int    `mapstructure:"warning_ms" validate:"gt=0"`
+	BlockMs   int    `mapstructure:"block_ms" validate:"gt=0"`
+}
+
+func (c FreshnessOverride) Thresholds() FreshnessThresholds {
+	return FreshnessThresholds{WarningMs: c.WarningMs, BlockMs: c.BlockMs}
+}
+
+// RESTFallbackConfig controls polling REST depth for books whose stream is
+// blocked. Polled books keep marks current but never unblock entries.
+type RESTFallbackConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	PollMs  int  `mapstructure:"poll_ms" validate:"omitempty,gt=0"`
+}
+
+func (c RESTFallbackConfig) PollInterval() time.Duration {
+	return time.Duration(c.PollMs) * time.Millisecond
+}
+
+// SanityConfig sets up the market data sanity filter. A touch more than
+// MaxTradeDeviationPct from a trade at most MaxTradeAgeMs older than the book
+// counts as an outlier (0 for either disables the comparison).
+type SanityConfig struct {
+	Enabled              bool    `mapstructure:"enabled"`
+	MaxTradeDeviationPct float64 `mapstructure:"max_trade_deviation_pct" validate:"gte=0"`
+	MaxTradeAgeMs        int     `mapstructure:"max_trade_age_ms" validate:"gte=0"`
+}
+
+func (c SanityConfig) MaxTradeAge() time.Duration {
+	return time.Duration(c.MaxTradeAgeMs) * time.Millisecond
 }
 
 func (c DataFreshnessConfig) WarningDuration() time.Duration {
@@ -125,6 +702,10 @@ type CostModelConfig struct {
 	SlippageCurveLookbackFills   int `mapstructure:"slippage_curve_lookback_fills" validate:"required,gt=0"`
 	FeeTierRefreshIntervalS      int `mapstructure:"fee_tier_refresh_interval_seconds" validate:"required,gt=0"`
 	FundingRateLookbackIntervals int `mapstructure:"funding_rate_lookback_intervals" validate:"required,gt=0"`
+	// TransferCostAmortizationBps is what moving inventory between venues
+	// costs each trade that needs it: withdrawal and network fees spread
+	// over the trades one transfer rebalances.
+	TransferCostAmortizationBps int `mapstructure:"transfer_cost_amortization_bps" validate:"gte=0"`
 }
 
 func (c CostModelConfig) FeeTierRefreshInterval() time.Duration {
@@ -132,14 +713,112 @@ func (c CostModelConfig) FeeTierRefreshInterval() time.Duration {
 }
 
 type MonitoringConfig struct {
-	Metrics  MetricsConfig  `mapstructure:"metrics"`
-	Alerting AlertingConfig `mapstructure:"alerting"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
+	Metrics   MetricsConfig   `mapstructure:"metrics"`
+	Alerting  AlertingConfig  `mapstructure:"alerting"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+	Webhooks  WebhookConfig   `mapstructure:"webhooks"`
+	Health    HealthConfig    `mapstructure:"health"`
+	Latency   LatencyConfig   `mapstructure:"latency"`
+	Profiling ProfilingConfig `mapstructure:"profiling"`
+}
+
+// HealthConfig sets how often venue gateways are health-checked and how long
+// a venue's WebSocket may go without a message before it counts as unhealthy.
+// CancelOnDisconnectSeconds is how long a venue may stay unhealthy before its
+// open orders are cancelled; 0 leaves them alone.
+type HealthConfig struct {
+	IntervalSeconds           int `mapstructure:"interval_seconds" validate:"gt=0"`
+	MaxMessageAgeSeconds      int `mapstructure:"max_message_age_seconds" validate:"gte=0"`
+	CancelOnDisconnectSeconds int `mapstructure:"cancel_on_disconnect_seconds" validate:"gte=0"`
 }
 
+func (c HealthConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+func (c HealthConfig) MaxMessageAge() time.Duration {
+	return time.Duration(c.MaxMessageAgeSeconds) * time.Second
+}
+
+func (c HealthConfig) CancelOnDisconnect() time.Duration {
+	return time.Duration(c.CancelOnDisconnectSeconds) * time.Second
+}
+
+// LatencyConfig sizes the rolling windows behind the venue latency
+// percentiles served on /admin/latency.
+type LatencyConfig struct {
+	WindowSize    int `mapstructure:"window_size" validate:"gt=0"`
+	WindowSeconds int `mapstructure:"window_seconds" validate:"gt=0"`
+}
+
+func (c LatencyConfig) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+// ProfilingConfig enables CPU profiles captured automatically while the
+// tick-to-ack p99 is above P99ThresholdMs, kept in Dir for go tool pprof.
+type ProfilingConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	Dir                  string `mapstructure:"dir" validate:"required_if=Enabled true"`
+	P99ThresholdMs       int    `mapstructure:"p99_threshold_ms" validate:"gt=0"`
+	WindowSeconds        int    `mapstructure:"window_seconds" validate:"gt=0"`
+	MinSamples           int    `mapstructure:"min_samples" validate:"gte=1"`
+	CheckIntervalSeconds int    `mapstructure:"check_interval_seconds" validate:"gt=0"`
+	ProfileSeconds       int    `mapstructure:"profile_seconds" validate:"gt=0"`
+	CooldownSeconds      int    `mapstructure:"cooldown_seconds" validate:"gte=0"`
+	MaxProfiles          int    `mapstructure:"max_profiles" validate:"gte=0"`
+}
+
+func (c ProfilingConfig) Threshold() time.Duration {
+	return time.Duration(c.P99ThresholdMs) * time.Millisecond
+}
+
+func (c ProfilingConfig) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+func (c ProfilingConfig) CheckInterval() time.Duration {
+	return time.Duration(c.CheckIntervalSeconds) * time.Second
+}
+
+func (c ProfilingConfig) ProfileDuration() time.Duration {
+	return time.Duration(c.ProfileSeconds) * time.Second
+}
+
+func (c ProfilingConfig) Cooldown() time.Duration {
+	return time.Duration(c.CooldownSeconds) * time.Second
+}
+
+// WebhookConfig sets where executed signals and execution reports are
+// POSTed. Requests are signed with the secret in WEBHOOK_SECRET.
+type WebhookConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	URLs      []string `mapstructure:"urls" validate:"required_if=Enabled true,dive,url"`
+	TimeoutMs int      `mapstructure:"timeout_ms" validate:"omitempty,gt=0"`
+}
+
+func (c WebhookConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+// MetricsConfig also sets up the HTTP server for /metrics, the health checks
+// and the admin API. It serves HTTPS when tls_cert_file and tls_key_file are
+// set. With basic_auth_user set, every endpoint except /health and /ready
+// requires basic auth with the password in METRICS_PASSWORD.
 type MetricsConfig struct {
-	FlushIntervalS       int `mapstructure:"flush_interval_seconds" validate:"gt=0"`
-	IngestionDelaySLAS   int `mapstructure:"ingestion_delay_sla_seconds" validate:"gt=0"`
+	FlushIntervalS     int    `mapstructure:"flush_interval_seconds" validate:"gt=0"`
+	IngestionDelaySLAS int    `mapstructure:"ingestion_delay_sla_seconds" validate:"gt=0"`
+	Addr               string `mapstructure:"addr" validate:"required"`
+	TLSCertFile        string `mapstructure:"tls_cert_file" validate:"required_with=TLSKeyFile"`
+	TLSKeyFile         string `mapstructure:"tls_key_file" validate:"required_with=TLSCertFile"`
+	BasicAuthUser      string `mapstructure:"basic_auth_user"`
+	// BookSampleMs is how often GET /admin/book resamples a book; requests
+	// in between get the last sample. 0 resamples on every request.
+	BookSampleMs int `mapstructure:"book_sample_ms" validate:"gte=0"`
+}
+
+func (c MetricsConfig) BookSampleInterval() time.Duration {
+	return time.Duration(c.BookSampleMs) * time.Millisecond
 }
 
 type AlertingConfig struct {
@@ -160,6 +839,146 @@ type DryRunConfig struct {
 	RejectRatePct         float64         `mapstructure:"reject_rate_pct"`
 	UseLiveSlippageModel  bool            `mapstructure:"use_live_slippage_model"`
 	PersistToSeparateTable bool           `mapstructure:"persist_to_separate_table"`
+	SeedFromLive           bool           `mapstructure:"seed_from_live"`
+	MakerQueue             MakerQueueConfig `mapstructure:"maker_queue"`
+	Impact                 ImpactConfig `mapstructure:"impact"`
+	Margin                 MarginConfig `mapstructure:"margin"`
+	ShadowDivergence       ShadowDivergenceConfig `mapstructure:"shadow_divergence"`
+}
+
+// ShadowDivergenceConfig compares, per strategy, the edge capture (realized
+// minus expected edge) of shadow cycles with that of live ones over the last
+// Window completed cycles of each. Once both have MinSamples, a difference
+// of more than ThresholdBps held for SustainSeconds fires an alert: the
+// fill and cost models no longer match what live trading gets. Only used in
+// live mode, when shadow execution runs alongside it.
+type ShadowDivergenceConfig struct {
+	Enabled        bool    `mapstructure:"enabled"`
+	Window         int     `mapstructure:"window" validate:"gte=1"`
+	MinSamples     int     `mapstructure:"min_samples" validate:"gte=1"`
+	ThresholdBps   float64 `mapstructure:"threshold_bps" validate:"gt=0"`
+	SustainSeconds int     `mapstructure:"sustain_seconds" validate:"gte=0"`
+}
+
+func (c ShadowDivergenceConfig) Sustain() time.Duration {
+	return time.Duration(c.SustainSeconds) * time.Second
+}
+
+// BacktestConfig drives trading_mode backtest, which replays the recorded
+// market data at DataPath (a JSON Lines file, or a directory of them), or a
+// synthetic market, through the dry-run simulation, Speed historical
+// seconds to the wall second. Once the data runs out and DrainMs has passed for in-flight cycles
+// to finish, the report is printed, written to ReportCSV and, with Postgres
+// enabled, stored in backtest_runs.
+type BacktestConfig struct {
+	DataPath  string          `mapstructure:"data_path"`
+	Speed     float64         `mapstructure:"speed" validate:"gt=0"`
+	ReportCSV string          `mapstructure:"report_csv"`
+	DrainMs   int             `mapstructure:"drain_ms" validate:"gte=0"`
+	Synthetic SyntheticConfig `mapstructure:"synthetic"`
+}
+
+// SyntheticConfig backtests against a generated market instead of the data
+// at DataPath. Every StepMs of market time each path takes a random step
+// under the current regime, and every instrument on it publishes a book of
+// Levels levels a side and, now and then, a trade. The regimes run in turn
+// for their durations, over and over, until DurationS has been generated.
+// The same Seed always generates the same market.
+type SyntheticConfig struct {
+	Enabled     bool                        `mapstructure:"enabled"`
+	Seed        uint64                      `mapstructure:"seed"`
+	DurationS   int                         `mapstructure:"duration_seconds" validate:"gt=0"`
+	StepMs      int                         `mapstructure:"step_ms" validate:"gt=0"`
+	Levels      int                         `mapstructure:"levels" validate:"gt=0"`
+	Instruments []SyntheticInstrumentConfig `mapstructure:"instruments" validate:"dive"`
+	Regimes     []SyntheticRegimeConfig     `mapstructure:"regimes" validate:"dive"`
+}
+
+func (c SyntheticConfig) Duration() time.Duration {
+	return time.Duration(c.DurationS) * time.Second
+}
+
+func (c SyntheticConfig) Step() time.Duration {
+	return time.Duration(c.StepMs) * time.Millisecond
+}
+
+// SyntheticInstrumentConfig is one generated book. Instruments on the same
+// Path (the symbol's base asset when empty) move together; each is priced at
+// StartPrice times the path's move, offset by BasisBps, which is how a perp
+// trades rich or cheap to its spot. A non-zero FundingRate is published
+// every 8 hours of market time.
+type SyntheticInstrumentConfig struct {
+	Venue       string  `mapstructure:"venue" validate:"required"`
+	Symbol      string  `mapstructure:"symbol" validate:"required"`
+	Path        string  `mapstructure:"path"`
+	StartPrice  float64 `mapstructure:"start_price" validate:"gt=0"`
+	BasisBps    float64 `mapstructure:"basis_bps"`
+	FundingRate float64 `mapstructure:"funding_rate"`
+}
+
+// SyntheticRegimeConfig is one market regime. Each step a path moves by
+// TrendBps plus a normal draw with a standard deviation of VolatilityBps,
+// and with probability GapProbability jumps GapBps up or down on top.
+// Books are SpreadBps wide with levels SpreadBps apart, each holding about
+// LevelNotional of quote currency.
+type SyntheticRegimeConfig struct {
+	Name           string  `mapstructure:"name"`
+	DurationS      int     `mapstructure:"duration_seconds" validate:"gt=0"`
+	TrendBps       float64 `mapstructure:"trend_bps"`
+	VolatilityBps  float64 `mapstructure:"volatility_bps" validate:"gte=0"`
+	SpreadBps      float64 `mapstructure:"spread_bps" validate:"gt=0"`
+	LevelNotional  float64 `mapstructure:"level_notional" validate:"gt=0"`
+	GapProbability float64 `mapstructure:"gap_probability" validate:"gte=0,lte=1"`
+	GapBps         float64 `mapstructure:"gap_bps" validate:"gte=0"`
+}
+
+func (c SyntheticRegimeConfig) Duration() time.Duration {
+	return time.Duration(c.DurationS) * time.Second
+}
+
+// ReplayConfig drives trading_mode replay, which republishes the recorded
+// market data at DataPath (in the backtest data format) onto the bus with
+// its original relative timing scaled by Speed, against simulated venues, to
+// reproduce an incident. The process exits DrainMs after the data runs out.
+type ReplayConfig struct {
+	DataPath string  `mapstructure:"data_path"`
+	Speed    float64 `mapstructure:"speed" validate:"gt=0"`
+	DrainMs  int     `mapstructure:"drain_ms" validate:"gte=0"`
+}
+
+// MarginConfig cross-margins the perp positions dry-run fills open on each
+// venue, so leverage mistakes show up before live. Equity is CollateralUSDT
+// (InitialCapitalUSDT when zero) plus realized and unrealized perp PnL.
+// Notional over Leverage times equity is logged; equity at or below
+// MaintenancePct of notional liquidates every position at the mid, less
+// LiquidationFeePct of the notional closed.
+type MarginConfig struct {
+	Enabled           bool            `mapstructure:"enabled"`
+	CollateralUSDT    decimal.Decimal `mapstructure:"collateral_usdt"`
+	Leverage          float64         `mapstructure:"leverage" validate:"gte=0"`
+	MaintenancePct    float64         `mapstructure:"maintenance_pct" validate:"gte=0,lte=100"`
+	LiquidationFeePct float64         `mapstructure:"liquidation_fee_pct" validate:"gte=0,lte=100"`
+}
+
+// ImpactConfig prices simulated taking fills worse than the displayed book:
+// CoefficientBps × sqrt(size / displayed depth) of impact, plus the mid move
+// over the simulated latency drawn from the drift and volatility of the last
+// TradeWindow trades.
+type ImpactConfig struct {
+	Enabled        bool    `mapstructure:"enabled"`
+	CoefficientBps float64 `mapstructure:"coefficient_bps" validate:"gte=0"`
+	TradeWindow    int     `mapstructure:"trade_window" validate:"gte=0"`
+}
+
+// MakerQueueConfig makes simulated resting limit orders wait behind the size
+// displayed at their price. QueueAhead is the share of that size assumed to
+// be ahead of the order (1 is the back of the queue); trades at the price
+// work through it first, and each trade that reaches the order fills it
+// with FillProbability.
+type MakerQueueConfig struct {
+	Enabled         bool    `mapstructure:"enabled"`
+	QueueAhead      float64 `mapstructure:"queue_ahead" validate:"gte=0,lte=1"`
+	FillProbability float64 `mapstructure:"fill_probability" validate:"gte=0,lte=1"`
 }
 
 type PersistenceConfig struct {
@@ -167,10 +986,98 @@ type PersistenceConfig struct {
 	ColdStoreDSN           string `mapstructure:"cold_store_dsn"`
 	ColdStorePoolSize      int    `mapstructure:"cold_store_pool_size" validate:"gt=0"`
 	TradeLogRetentionDays  int    `mapstructure:"trade_log_retention_days" validate:"gt=0"`
+	// MaxOrdersInMemory caps the orders the order manager holds; past it the
+	// least recently updated finished orders are moved to the checkpoint DB.
+	MaxOrdersInMemory      int    `mapstructure:"max_orders_in_memory" validate:"gt=0"`
+	MarketSnapshot         MarketSnapshotConfig `mapstructure:"market_snapshot"`
+	FundingHistory         FundingHistoryConfig `mapstructure:"funding_history"`
+}
+
+// FundingHistoryConfig records live funding rate updates in the cold store,
+// at most one per feed every MinIntervalSeconds, and at startup loads the
+// last WarmStartHours of them into the basis strategy and cost model.
+type FundingHistoryConfig struct {
+	Enabled            bool `mapstructure:"enabled"`
+	MinIntervalSeconds int  `mapstructure:"min_interval_seconds" validate:"gte=0"`
+	WarmStartHours     int  `mapstructure:"warm_start_hours" validate:"gte=0"`
+}
+
+func (c FundingHistoryConfig) MinInterval() time.Duration {
+	return time.Duration(c.MinIntervalSeconds) * time.Second
 }
 
+func (c FundingHistoryConfig) WarmStart() time.Duration {
+	return time.Duration(c.WarmStartHours) * time.Hour
+}
+
+// MarketSnapshotConfig saves the latest books and funding rates to the
+// checkpoint DB every IntervalSeconds and at shutdown, and reloads them at
+// startup unless they are older than MaxAgeSeconds. Reloaded data counts as
+// stale until the feeds deliver.
+type MarketSnapshotConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	IntervalSeconds int  `mapstructure:"interval_seconds" validate:"gt=0"`
+	MaxAgeSeconds   int  `mapstructure:"max_age_seconds" validate:"gt=0"`
+}
+
+func (c MarketSnapshotConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+func (c MarketSnapshotConfig) MaxAge() time.Duration {
+	return time.Duration(c.MaxAgeSeconds) * time.Second
+}
+
+// RuntimeConfig tunes the Go runtime. GoMemLimit is the soft memory limit,
+// written as GOMEMLIMIT is: a byte count with an optional B, KiB, MiB, GiB
+// or TiB suffix, or "off" (or empty) for none. A GOMEMLIMIT environment
+// variable takes precedence over it.
 type RuntimeConfig struct {
-	GoMaxProcs int    `mapstructure:"gomaxprocs"`
-	GOGC       int    `mapstructure:"gogc"`
-	GoMemLimit string `mapstructure:"gomemlimit"`
+	GoMaxProcs     int                  `mapstructure:"gomaxprocs"`
+	GOGC           int                  `mapstructure:"gogc"`
+	GoMemLimit     string               `mapstructure:"gomemlimit"`
+	MemoryPressure MemoryPressureConfig `mapstructure:"memory_pressure"`
+}
+
+// MemoryLimit parses GoMemLimit into bytes; 0 means no limit.
+func (c RuntimeConfig) MemoryLimit() (int64, error) {
+	s := strings.TrimSpace(c.GoMemLimit)
+	if s == "" || s == "off" {
+		return 0, nil
+	}
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		bytes  int64
+	}{{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSuffix(s, u.suffix), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 || n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("runtime.gomemlimit %q: want a positive byte count with an optional B, KiB, MiB, GiB or TiB suffix, or off", c.GoMemLimit)
+	}
+	return n * unit, nil
+}
+
+// MemoryPressureConfig watches the memory the Go runtime holds against its
+// memory limit every CheckIntervalMs. At HighPct of the limit a P2 alert
+// fires. At CriticalPct a P1 alert fires and book events are conflated to
+// one per feed every ConflateMs, until use is back under HighPct.
+type MemoryPressureConfig struct {
+	Enabled         bool    `mapstructure:"enabled"`
+	CheckIntervalMs int     `mapstructure:"check_interval_ms" validate:"gt=0"`
+	HighPct         float64 `mapstructure:"high_pct" validate:"gt=0,lte=100"`
+	CriticalPct     float64 `mapstructure:"critical_pct" validate:"gtefield=HighPct,lte=100"`
+	ConflateMs      int     `mapstructure:"conflate_ms" validate:"gt=0"`
+}
+
+func (c MemoryPressureConfig) CheckInterval() time.Duration {
+	return time.Duration(c.CheckIntervalMs) * time.Millisecond
+}
+
+func (c MemoryPressureConfig) Conflate() time.Duration {
+	return time.Duration(c.ConflateMs) * time.Millisecond
 }
@@ -3,8 +3,13 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
 )
 
 func TestLoadValidConfig(t *testing.T) {
@@ -116,6 +121,9 @@ dry_run:
 	if cfg.Strategies.BasisArb.Enabled {
 		t.Error("expected basis arb to be disabled")
 	}
+	if cfg.Monitoring.Metrics.Addr != ":9090" {
+		t.Errorf("expected default metrics addr :9090, got %q", cfg.Monitoring.Metrics.Addr)
+	}
 }
 
 func TestLoadInvalidPath(t *testing.T) {
@@ -173,6 +181,22 @@ func TestBasisArbConfigFillTimeout(t *testing.T) {
 	}
 }
 
+func TestStrategiesConfigAssetFillTimeouts(t *testing.T) {
+	cfg := StrategiesConfig{LiquidityTiers: map[string][]string{"majors": {"BTC", "ETH"}}}
+
+	got, err := cfg.AssetFillTimeouts(map[string]int{"majors": 1500})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["BTC"] != 1500*time.Millisecond || got["ETH"] != 1500*time.Millisecond || len(got) != 2 {
+		t.Errorf("unexpected timeouts: %v", got)
+	}
+
+	if _, err := cfg.AssetFillTimeouts(map[string]int{"alts": 5000}); err == nil {
+		t.Error("expected an error for an undefined tier")
+	}
+}
+
 func TestDataFreshnessDurations(t *testing.T) {
 	cfg := DataFreshnessConfig{WarningMs: 3000, BlockMs: 5000}
 	if cfg.WarningDuration() != 3*time.Second {
@@ -183,6 +207,28 @@ func TestDataFreshnessDurations(t *testing.T) {
 	}
 }
 
+func TestRuntimeMemoryLimit(t *testing.T) {
+	for in, want := range map[string]int64{
+		"":        0,
+		"off":     0,
+		"2GiB":    2 << 30,
+		"512MiB":  512 << 20,
+		"1048576": 1 << 20,
+		"4096B":   4096,
+		" 16KiB ": 16 << 10,
+	} {
+		got, err := RuntimeConfig{GoMemLimit: in}.MemoryLimit()
+		if err != nil || got != want {
+			t.Errorf("%q: expected %d, got %d (%v)", in, want, got, err)
+		}
+	}
+	for _, in := range []string{"2GB", "-1GiB", "0", "lots", "9999999TiB"} {
+		if _, err := (RuntimeConfig{GoMemLimit: in}).MemoryLimit(); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
 func TestRiskConfigCheckpointInterval(t *testing.T) {
 	cfg := RiskConfig{CheckpointIntervalS: 30}
 	if cfg.CheckpointInterval() != 30*time.Second {
@@ -302,3 +348,149 @@ dry_run:
 		t.Errorf("expected instance_id get-test, got %s", got.System.InstanceID)
 	}
 }
+
+func TestFIXVenueNeedsNoURLs(t *testing.T) {
+	v := validator.New()
+	fix := VenueConfig{Enabled: true, Protocol: "fix", FIX: FIXConfig{MarketDataAddr: "fix.broker.example:9880", OrderEntryAddr: "fix.broker.example:9881"}}
+	if err := v.Struct(fix); err != nil {
+		t.Errorf("expected a FIX venue without ws_url and rest_url to validate, got %v", err)
+	}
+	if err := v.Struct(VenueConfig{Enabled: true}); err == nil {
+		t.Error("expected a native venue without URLs rejected")
+	}
+	fix.FIX.OrderEntryAddr = "no-port"
+	if err := v.Struct(fix); err == nil {
+		t.Error("expected a FIX address without a port rejected")
+	}
+}
+
+func TestGRPCPluginVenue(t *testing.T) {
+	v := validator.New()
+	plugin := VenueConfig{Enabled: true, Protocol: "grpc", GRPC: GRPCConfig{Addr: "127.0.0.1:50051"}}
+	if err := v.Struct(plugin); err != nil {
+		t.Errorf("expected a gRPC plugin venue without ws_url and rest_url to validate, got %v", err)
+	}
+	plugin.Protocol = "soap"
+	if err := v.Struct(plugin); err == nil {
+		t.Error("expected an unknown protocol rejected")
+	}
+}
+
+func TestVenueSymbolMap(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "configs", "config.yaml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := SymbolMapping{Symbol: "DOGE/USDT", VenueSymbol: "DOGEUSDT", Type: "spot"}
+	if m := cfg.Venues["wallex"].SymbolMap; len(m) != 1 || m[0] != want {
+		t.Errorf("expected wallex symbol_map %+v with case preserved, got %+v", want, m)
+	}
+
+	v := validator.New()
+	venue := VenueConfig{Protocol: "grpc", SymbolMap: []SymbolMapping{{Symbol: "BTC/USDT", VenueSymbol: "BTCUSDT", Type: "future"}}}
+	if err := v.Struct(venue); err == nil {
+		t.Error("expected an unknown instrument type rejected")
+	}
+}
+
+func TestMetricsServerTLS(t *testing.T) {
+	v := validator.New()
+	m := MetricsConfig{FlushIntervalS: 10, IngestionDelaySLAS: 15, Addr: ":9443", TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}
+	if err := v.Struct(m); err != nil {
+		t.Errorf("expected TLS with cert and key to validate, got %v", err)
+	}
+	m.TLSKeyFile = ""
+	if err := v.Struct(m); err == nil {
+		t.Error("expected a TLS cert without a key rejected")
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "configs", "config.yaml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	hash := cfg.Hash()
+	if len(hash) != 16 {
+		t.Fatalf("expected a 16-character hash, got %q", hash)
+	}
+	again, _ := Load(filepath.Join("..", "..", "configs", "config.yaml"))
+	if again.Hash() != hash {
+		t.Error("expected the same config to hash the same")
+	}
+	again.Risk.CheckpointIntervalS++
+	if again.Hash() == hash {
+		t.Error("expected a changed setting to change the hash")
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	old := &Config{
+		System: SystemConfig{LogLevel: "INFO", TradingMode: "dry_run"},
+		Venues: map[string]VenueConfig{"kcex": {Enabled: true}, "okx": {Enabled: true}},
+		Risk:   RiskConfig{DailyLossCapUSDT: decimal.NewFromInt(500)},
+	}
+	cp := *old
+	cp.Venues = map[string]VenueConfig{"kcex": {Enabled: false}, "nobitex": {Enabled: true}}
+	cp.System.LogLevel = "DEBUG"
+	cp.Risk.DailyLossCapUSDT = decimal.RequireFromString("500.00")
+
+	got := ChangedKeys(old, &cp)
+	want := []string{"system.log_level", "venues.kcex.enabled", "venues.nobitex", "venues.okx"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if keys := ChangedKeys(old, old); len(keys) != 0 {
+		t.Errorf("expected no changes against itself, got %v", keys)
+	}
+}
+
+func TestSetTradingMode(t *testing.T) {
+	cfg := &Config{System: SystemConfig{TradingMode: "dry_run"}}
+
+	if err := cfg.SetTradingMode("paper"); err == nil {
+		t.Error("expected an unknown mode to be rejected")
+	}
+	if err := cfg.SetTradingMode("replay"); err == nil {
+		t.Error("expected replay without replay.data_path to be rejected")
+	}
+
+	cfg.Replay.DataPath = "./data/incident"
+	if err := cfg.SetTradingMode("replay"); err != nil {
+		t.Fatalf("set replay mode: %v", err)
+	}
+	if cfg.System.TradingMode != "replay" {
+		t.Errorf("expected replay mode, got %s", cfg.System.TradingMode)
+	}
+}
+
+func TestLoadAcceptsRenamedNightlyReportHour(t *testing.T) {
+	base, err := os.ReadFile(filepath.Join("..", "..", "configs", "config.yaml"))
+	if err != nil {
+		t.Fatalf("read example config: %v", err)
+	}
+	if !strings.Contains(string(base), "nightly_report_hour: 0") {
+		t.Fatal("example config no longer sets nightly_report_hour: 0")
+	}
+
+	load := func(replacement string) *Config {
+		t.Helper()
+		cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+		content := strings.Replace(string(base), "nightly_report_hour: 0", replacement, 1)
+		if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		cfg, err := Load(cfgPath)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		return cfg
+	}
+
+	if got := load("nightly_report_hour_utc: 3").Risk.Stress.NightlyReportHour; got != 3 {
+		t.Errorf("old key: got hour %d, want 3", got)
+	}
+	if got := load("nightly_report_hour: 5\n    nightly_report_hour_utc: 3").Risk.Stress.NightlyReportHour; got != 5 {
+		t.Errorf("both keys: got hour %d, want the new key's 5", got)
+	}
+}
@@ -25,19 +25,7 @@ func Load(configPath string) (*Config, error) {
 	v.SetConfigType("yaml")
 	v.AutomaticEnv()
 
-	v.SetDefault("system.log_level", "INFO")
-	v.SetDefault("system.timezone", "UTC")
-	v.SetDefault("system.require_live_confirmation", true)
-	v.SetDefault("runtime.gomaxprocs", 0)
-	v.SetDefault("runtime.gogc", 400)
-	v.SetDefault("runtime.gomemlimit", "2GiB")
-	v.SetDefault("persistence.cold_store_pool_size", 10)
-	v.SetDefault("persistence.trade_log_retention_days", 30)
-	v.SetDefault("dry_run.initial_capital_usdt", 100000)
-	v.SetDefault("dry_run.simulated_latency_ms", 50)
-	v.SetDefault("dry_run.reject_rate_pct", 0.0)
-	v.SetDefault("dry_run.use_live_slippage_model", true)
-	v.SetDefault("dry_run.persist_to_separate_table", true)
+	setDefaults(v)
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
@@ -52,16 +40,151 @@ func Load(configPath string) (*Config, error) {
 	)); err != nil {
 		return nil, fmt.Errorf("unmarshal config: %w", err)
 	}
+	applyRenamedKeys(v, &cfg)
 
 	validate := validator.New()
 	if err := validate.Struct(&cfg); err != nil {
 		return nil, fmt.Errorf("validate config: %w", err)
 	}
+	for _, tiers := range []map[string]int{
+		cfg.Strategies.TriangularArb.TierFillTimeoutsMs,
+		cfg.Strategies.BasisArb.TierFillTimeoutsMs,
+	} {
+		if _, err := cfg.Strategies.AssetFillTimeouts(tiers); err != nil {
+			return nil, fmt.Errorf("validate config: %w", err)
+		}
+	}
+	if _, err := cfg.Runtime.MemoryLimit(); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	if err := cfg.validateModeData(); err != nil {
+		return nil, err
+	}
 
 	globalConfig.Store(&cfg)
 	return &cfg, nil
 }
 
+// setDefaults registers the defaults for settings the file may omit. A
+// reloaded file gets the same ones, so omitting a setting never reads as a
+// change.
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("system.log_level", "INFO")
+	v.SetDefault("system.timezone", "UTC")
+	v.SetDefault("system.require_live_confirmation", true)
+	v.SetDefault("runtime.gomaxprocs", 0)
+	v.SetDefault("runtime.gogc", 400)
+	v.SetDefault("runtime.gomemlimit", "2GiB")
+	v.SetDefault("runtime.memory_pressure.enabled", true)
+	v.SetDefault("runtime.memory_pressure.check_interval_ms", 1000)
+	v.SetDefault("runtime.memory_pressure.high_pct", 80)
+	v.SetDefault("runtime.memory_pressure.critical_pct", 90)
+	v.SetDefault("runtime.memory_pressure.conflate_ms", 100)
+	v.SetDefault("persistence.cold_store_pool_size", 10)
+	v.SetDefault("persistence.trade_log_retention_days", 30)
+	v.SetDefault("persistence.max_orders_in_memory", 20000)
+	v.SetDefault("persistence.market_snapshot.enabled", true)
+	v.SetDefault("persistence.market_snapshot.interval_seconds", 60)
+	v.SetDefault("persistence.market_snapshot.max_age_seconds", 600)
+	v.SetDefault("persistence.funding_history.enabled", true)
+	v.SetDefault("persistence.funding_history.min_interval_seconds", 60)
+	v.SetDefault("persistence.funding_history.warm_start_hours", 8)
+	v.SetDefault("dry_run.initial_capital_usdt", 100000)
+	v.SetDefault("dry_run.simulated_latency_ms", 50)
+	v.SetDefault("dry_run.reject_rate_pct", 0.0)
+	v.SetDefault("dry_run.use_live_slippage_model", true)
+	v.SetDefault("dry_run.persist_to_separate_table", true)
+	v.SetDefault("dry_run.seed_from_live", false)
+	v.SetDefault("dry_run.maker_queue.enabled", true)
+	v.SetDefault("dry_run.maker_queue.queue_ahead", 1.0)
+	v.SetDefault("dry_run.maker_queue.fill_probability", 1.0)
+	v.SetDefault("dry_run.impact.enabled", true)
+	v.SetDefault("dry_run.impact.coefficient_bps", 5)
+	v.SetDefault("dry_run.impact.trade_window", 200)
+	v.SetDefault("dry_run.margin.enabled", true)
+	v.SetDefault("dry_run.margin.leverage", 3)
+	v.SetDefault("dry_run.margin.maintenance_pct", 0.5)
+	v.SetDefault("dry_run.margin.liquidation_fee_pct", 0.5)
+	v.SetDefault("dry_run.shadow_divergence.enabled", true)
+	v.SetDefault("dry_run.shadow_divergence.window", 50)
+	v.SetDefault("dry_run.shadow_divergence.min_samples", 20)
+	v.SetDefault("dry_run.shadow_divergence.threshold_bps", 5)
+	v.SetDefault("dry_run.shadow_divergence.sustain_seconds", 900)
+	v.SetDefault("backtest.speed", 60)
+	v.SetDefault("backtest.report_csv", "./data/backtest_report.csv")
+	v.SetDefault("backtest.drain_ms", 5000)
+	v.SetDefault("backtest.synthetic.duration_seconds", 3600)
+	v.SetDefault("backtest.synthetic.step_ms", 250)
+	v.SetDefault("backtest.synthetic.levels", 10)
+	v.SetDefault("replay.speed", 1)
+	v.SetDefault("replay.drain_ms", 5000)
+	v.SetDefault("cost_model.transfer_cost_amortization_bps", 5)
+	v.SetDefault("risk.stress.price_shocks_pct", []float64{-10, -5, 5, 10})
+	v.SetDefault("risk.stress.funding_flip", true)
+	v.SetDefault("risk.stress.frozen_venue_shock_pct", 10)
+	v.SetDefault("risk.stress.nightly_report_hour", 0)
+	v.SetDefault("strategies.triangular_arb.adverse_flow.depth_levels", 5)
+	v.SetDefault("strategies.triangular_arb.adverse_flow.window_ms", 2000)
+	v.SetDefault("strategies.triangular_arb.adverse_flow.max_imbalance", 0.6)
+	v.SetDefault("strategies.triangular_arb.adverse_flow.max_pressure", 0.7)
+	v.SetDefault("strategies.basis_arb.passive_entry.min_notional_usdt", 50000)
+	v.SetDefault("strategies.basis_arb.passive_entry.refresh_ms", 250)
+	v.SetDefault("strategies.basis_arb.passive_entry.timeout_ms", 60000)
+	v.SetDefault("strategies.basis_arb.passive_entry.rebate_min_fill_probability", 0.6)
+	v.SetDefault("strategies.basis_arb.funding_timing.window_ms", 60000)
+	v.SetDefault("strategies.basis_arb.funding_timing.settle_ms", 2000)
+	v.SetDefault("strategies.basis_arb.cross_venue.extra_edge_bps", 5)
+	v.SetDefault("strategies.cross_venue_arb.min_net_edge_bps", 15)
+	v.SetDefault("strategies.cross_venue_arb.max_notional_usdt", 5000)
+	v.SetDefault("strategies.cross_venue_arb.fill_timeout_ms", 2000)
+	v.SetDefault("strategies.cross_venue_arb.min_atomicity", 0.7)
+	v.SetDefault("strategies.latency_compensation.max_bps", 5)
+	v.SetDefault("strategies.latency_compensation.samples", 200)
+	v.SetDefault("strategies.latency_compensation.min_samples", 20)
+	v.SetDefault("risk.data_freshness.rest_fallback.poll_ms", 1000)
+	v.SetDefault("risk.data_freshness.checksum_every", 50)
+	v.SetDefault("risk.data_freshness.sanity.enabled", true)
+	v.SetDefault("risk.data_freshness.sanity.max_trade_deviation_pct", 5)
+	v.SetDefault("risk.data_freshness.sanity.max_trade_age_ms", 60000)
+	v.SetDefault("risk.data_freshness.funding.warning_ms", 90000)
+	v.SetDefault("risk.data_freshness.funding.block_ms", 300000)
+	v.SetDefault("monitoring.webhooks.timeout_ms", 2000)
+	v.SetDefault("monitoring.metrics.addr", ":9090")
+	v.SetDefault("monitoring.metrics.book_sample_ms", 1000)
+	v.SetDefault("monitoring.health.interval_seconds", 15)
+	v.SetDefault("monitoring.health.max_message_age_seconds", 60)
+	v.SetDefault("monitoring.health.cancel_on_disconnect_seconds", 30)
+	v.SetDefault("monitoring.latency.window_size", 1024)
+	v.SetDefault("monitoring.latency.window_seconds", 300)
+	v.SetDefault("monitoring.profiling.dir", "./data/profiles")
+	v.SetDefault("monitoring.profiling.p99_threshold_ms", 250)
+	v.SetDefault("monitoring.profiling.window_seconds", 60)
+	v.SetDefault("monitoring.profiling.min_samples", 20)
+	v.SetDefault("monitoring.profiling.check_interval_seconds", 10)
+	v.SetDefault("monitoring.profiling.profile_seconds", 10)
+	v.SetDefault("monitoring.profiling.cooldown_seconds", 600)
+	v.SetDefault("monitoring.profiling.max_profiles", 20)
+	v.SetDefault("risk.error_budget.window_minutes", 60)
+	v.SetDefault("risk.error_budget.ack_latency_ms", 250)
+	v.SetDefault("risk.error_budget.latency_target_pct", 99)
+	v.SetDefault("risk.error_budget.freshness_target_pct", 99.5)
+	v.SetDefault("risk.error_budget.reject_target_pct", 98)
+	v.SetDefault("risk.error_budget.recover_pct", 50)
+	v.SetDefault("risk.error_budget.edge_multiplier", 2)
+	v.SetDefault("risk.error_budget.size_factor", 0.5)
+	v.SetDefault("risk.margin_utilization.max_utilization_pct", 80)
+	v.SetDefault("risk.surveillance.window_seconds", 300)
+	v.SetDefault("risk.surveillance.min_orders", 50)
+	v.SetDefault("risk.surveillance.max_cancel_ratio", 0.95)
+	v.SetDefault("risk.surveillance.throttle_seconds", 60)
+	v.SetDefault("risk.surveillance.prevent_self_match", true)
+	v.SetDefault("risk.surveillance.max_resting_levels", 5)
+	v.SetDefault("risk.volatility.half_life_seconds", 300)
+	v.SetDefault("risk.volatility.min_trades", 30)
+	v.SetDefault("risk.volatility.max_annualized_pct", 300)
+	v.SetDefault("risk.volatility.max_extra_edge_bps", 50)
+}
+
 // decimalDecodeHook converts numeric types to decimal.Decimal during config unmarshaling.
 func decimalDecodeHook() mapstructure.DecodeHookFuncType {
 	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
@@ -83,11 +206,25 @@ func decimalDecodeHook() mapstructure.DecodeHookFuncType {
 	}
 }
 
+// applyRenamedKeys reads config keys that have since been renamed into cfg,
+// warning that they are deprecated. The new key wins when both are set.
+func applyRenamedKeys(v *viper.Viper, cfg *Config) {
+	const oldKey, newKey = "risk.stress.nightly_report_hour_utc", "risk.stress.nightly_report_hour"
+	if !v.InConfig(oldKey) {
+		return
+	}
+	slog.Warn("deprecated config key, rename it", "key", oldKey, "replacement", newKey)
+	if !v.InConfig(newKey) {
+		cfg.Risk.Stress.NightlyReportHour = v.GetInt(oldKey)
+	}
+}
+
 func WatchAndReload(configPath string, onChange func(*Config)) error {
 	v := viper.New()
 	v.SetConfigFile(configPath)
 	v.SetConfigType("yaml")
 	v.AutomaticEnv()
+	setDefaults(v)
 
 	if err := v.ReadInConfig(); err != nil {
 		return fmt.Errorf("read config for watch: %w", err)
@@ -105,6 +242,7 @@ func WatchAndReload(configPath string, onChange func(*Config)) error {
 			slog.Error("failed to unmarshal reloaded config", "error", err)
 			return
 		}
+		applyRenamedKeys(v, &newCfg)
 
 		validate := validator.New()
 		if err := validate.Struct(&newCfg); err != nil {
@@ -126,6 +264,33 @@ func WatchAndReload(configPath string, onChange func(*Config)) error {
 	return nil
 }
 
+// SetTradingMode overrides system.trading_mode, as the -mode flag does.
+func (c *Config) SetTradingMode(mode string) error {
+	switch mode {
+	case "live", "dry_run", "backtest", "replay":
+	default:
+		return fmt.Errorf("validate config: unknown trading mode %q", mode)
+	}
+	c.System.TradingMode = mode
+	return c.validateModeData()
+}
+
+// validateModeData checks that the modes which replay recorded data have
+// some to replay.
+func (c *Config) validateModeData() error {
+	switch {
+	case c.System.TradingMode == "backtest" && c.Backtest.Synthetic.Enabled:
+		if len(c.Backtest.Synthetic.Instruments) == 0 || len(c.Backtest.Synthetic.Regimes) == 0 {
+			return fmt.Errorf("validate config: backtest.synthetic needs at least one instrument and one regime")
+		}
+	case c.System.TradingMode == "backtest" && c.Backtest.DataPath == "":
+		return fmt.Errorf("validate config: backtest.data_path is required in backtest mode")
+	case c.System.TradingMode == "replay" && c.Replay.DataPath == "":
+		return fmt.Errorf("validate config: replay.data_path is required in replay mode")
+	}
+	return nil
+}
+
 func logConfigChanges(old, new *Config) {
 	if old == nil || new == nil {
 		return
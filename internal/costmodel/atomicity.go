@@ -0,0 +1,157 @@
+package costmodel
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// fillHistoryLen is how many recent order outcomes per venue:symbol make up
+// its fill rate.
+const fillHistoryLen = 50
+
+// A symbol without history is assumed to fill at priorFillRate; the prior
+// counts as priorWeight outcomes so a few early misses don't dominate.
+const (
+	priorFillRate = 0.9
+	priorWeight   = 10
+)
+
+// spreadPenaltyBps is the spread width that halves a leg's fill probability:
+// a wide spread means a thin, fast-moving book where a resting price is
+// likely to be left behind before the timeout.
+const spreadPenaltyBps = 50
+
+// AtomicityEstimator estimates the probability that every leg of a signal
+// fills within its timeout. books is aligned with legs.
+type AtomicityEstimator interface {
+	AtomicityProbability(venue string, legs []domain.LegSpec, books []*domain.OrderBookSnapshot) decimal.Decimal
+}
+
+// PassiveFillEstimator estimates the probability that a quote resting at the
+// touch on venue:symbol fills within its timeout.
+type PassiveFillEstimator interface {
+	PassiveFillProbability(venue, symbol string, book *domain.OrderBookSnapshot) decimal.Decimal
+}
+
+// fillHistory is a ring of the latest order outcomes for one symbol.
+type fillHistory struct {
+	outcomes [fillHistoryLen]bool
+	next     int
+	count    int
+}
+
+func (h *fillHistory) add(filled bool) {
+	h.outcomes[h.next] = filled
+	h.next = (h.next + 1) % fillHistoryLen
+	if h.count < fillHistoryLen {
+		h.count++
+	}
+}
+
+func (h *fillHistory) rate() float64 {
+	filled := 0
+	for i := 0; i < h.count; i++ {
+		if h.outcomes[i] {
+			filled++
+		}
+	}
+	return (float64(filled) + priorFillRate*priorWeight) / float64(h.count+priorWeight)
+}
+
+// RecordFillOutcome records whether an order on venue:symbol filled
+// completely before it ended.
+func (s *Service) RecordFillOutcome(venue, symbol string, filled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := venue + ":" + symbol
+	h, ok := s.fillHistory[key]
+	if !ok {
+		h = &fillHistory{}
+		s.fillHistory[key] = h
+	}
+	h.add(filled)
+}
+
+// AtomicityProbability multiplies the per-leg fill probabilities, treating
+// legs as independent.
+func (s *Service) AtomicityProbability(venue string, legs []domain.LegSpec, books []*domain.OrderBookSnapshot) decimal.Decimal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p := 1.0
+	for i, leg := range legs {
+		if i >= len(books) || books[i] == nil {
+			return decimal.Zero
+		}
+		p *= s.legFillProbability(venue, leg, books[i])
+	}
+	return decimal.NewFromFloat(p).Round(4)
+}
+
+// legFillProbability combines how much of the leg the book can take at its
+// price, the spread, and the symbol's recent fill rate.
+func (s *Service) legFillProbability(venue string, leg domain.LegSpec, book *domain.OrderBookSnapshot) float64 {
+	bid, hasBid := book.BestBid()
+	ask, hasAsk := book.BestAsk()
+	if !hasBid || !hasAsk || !leg.Size.IsPositive() {
+		return 0
+	}
+
+	levels := book.Asks
+	if leg.Side == domain.SideSell {
+		levels = book.Bids
+	}
+	available := decimal.Zero
+	for _, level := range levels {
+		if leg.OrderType != domain.OrderTypeMarket && leg.Price.IsPositive() {
+			if leg.Side == domain.SideBuy && level.Price.GreaterThan(leg.Price) {
+				break
+			}
+			if leg.Side == domain.SideSell && level.Price.LessThan(leg.Price) {
+				break
+			}
+		}
+		available = available.Add(level.Size)
+	}
+	depth := available.Div(leg.Size).InexactFloat64()
+	if depth > 1 {
+		depth = 1
+	}
+
+	return depth * s.spreadFillRate(venue, leg.Symbol, bid.Price, ask.Price)
+}
+
+// PassiveFillProbability is a quote's chance of filling from the spread and
+// the symbol's recent fill rate alone: resting at the touch, it waits for
+// takers rather than for depth at its price.
+func (s *Service) PassiveFillProbability(venue, symbol string, book *domain.OrderBookSnapshot) decimal.Decimal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bid, hasBid := book.BestBid()
+	ask, hasAsk := book.BestAsk()
+	if !hasBid || !hasAsk {
+		return decimal.Zero
+	}
+	return decimal.NewFromFloat(s.spreadFillRate(venue, symbol, bid.Price, ask.Price)).Round(4)
+}
+
+// spreadFillRate combines the spread between bid and ask with the
+// symbol's recent fill rate.
+func (s *Service) spreadFillRate(venue, symbol string, bid, ask decimal.Decimal) float64 {
+	mid := bid.Add(ask).Div(decimal.NewFromInt(2))
+	spreadBps := 0.0
+	if mid.IsPositive() {
+		spreadBps = ask.Sub(bid).Div(mid).Mul(decimal.NewFromInt(10000)).InexactFloat64()
+	}
+	spread := 1 / (1 + spreadBps/spreadPenaltyBps)
+
+	fillRate := priorFillRate
+	if h, ok := s.fillHistory[venue+":"+symbol]; ok {
+		fillRate = h.rate()
+	}
+
+	return spread * fillRate
+}
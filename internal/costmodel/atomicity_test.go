@@ -0,0 +1,119 @@
+package costmodel
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func newAtomicityTestService() *Service {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewService(nil, 0, 0, logger)
+}
+
+func atomicityBook(bid, ask, size string) *domain.OrderBookSnapshot {
+	return &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{{Price: decimal.RequireFromString(bid), Size: decimal.RequireFromString(size)}},
+		Asks: []domain.PriceLevel{{Price: decimal.RequireFromString(ask), Size: decimal.RequireFromString(size)}},
+	}
+}
+
+func TestAtomicityProbability_DepthAndSpread(t *testing.T) {
+	svc := newAtomicityTestService()
+	leg := domain.LegSpec{Symbol: "BTC-USDT", Side: domain.SideBuy, Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeLimit}
+
+	deep := svc.AtomicityProbability("kcex", []domain.LegSpec{leg}, []*domain.OrderBookSnapshot{atomicityBook("99.99", "100", "5")})
+	thin := svc.AtomicityProbability("kcex", []domain.LegSpec{leg}, []*domain.OrderBookSnapshot{atomicityBook("99.99", "100", "0.5")})
+	wide := svc.AtomicityProbability("kcex", []domain.LegSpec{leg}, []*domain.OrderBookSnapshot{atomicityBook("99", "100", "5")})
+
+	if !deep.GreaterThan(thin) {
+		t.Errorf("deep book %s should score above thin book %s", deep, thin)
+	}
+	if !deep.GreaterThan(wide) {
+		t.Errorf("tight spread %s should score above wide spread %s", deep, wide)
+	}
+	if deep.GreaterThan(decimal.NewFromFloat(priorFillRate)) {
+		t.Errorf("single leg %s exceeds the prior fill rate", deep)
+	}
+
+	// A leg priced below the best ask has nothing available to take.
+	leg.Price = decimal.NewFromFloat(99.5)
+	if p := svc.AtomicityProbability("kcex", []domain.LegSpec{leg}, []*domain.OrderBookSnapshot{atomicityBook("99.99", "100", "5")}); !p.IsZero() {
+		t.Errorf("non-crossing leg: got %s, want 0", p)
+	}
+}
+
+func TestAtomicityProbability_MultipleLegsAndMissingBook(t *testing.T) {
+	svc := newAtomicityTestService()
+	leg := domain.LegSpec{Symbol: "BTC-USDT", Side: domain.SideSell, Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeMarket}
+	book := atomicityBook("99.99", "100", "5")
+
+	one := svc.AtomicityProbability("kcex", []domain.LegSpec{leg}, []*domain.OrderBookSnapshot{book})
+	three := svc.AtomicityProbability("kcex", []domain.LegSpec{leg, leg, leg}, []*domain.OrderBookSnapshot{book, book, book})
+	if !three.LessThan(one) {
+		t.Errorf("three legs %s should score below one leg %s", three, one)
+	}
+
+	if p := svc.AtomicityProbability("kcex", []domain.LegSpec{leg, leg}, []*domain.OrderBookSnapshot{book, nil}); !p.IsZero() {
+		t.Errorf("missing book: got %s, want 0", p)
+	}
+}
+
+func TestAtomicityProbability_FillHistory(t *testing.T) {
+	svc := newAtomicityTestService()
+	leg := domain.LegSpec{Symbol: "ETH-USDT", Side: domain.SideBuy, Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeMarket}
+	books := []*domain.OrderBookSnapshot{atomicityBook("99.99", "100", "5")}
+
+	before := svc.AtomicityProbability("kcex", []domain.LegSpec{leg}, books)
+	for i := 0; i < fillHistoryLen; i++ {
+		svc.RecordFillOutcome("kcex", "ETH-USDT", false)
+	}
+	after := svc.AtomicityProbability("kcex", []domain.LegSpec{leg}, books)
+	if !after.LessThan(before) {
+		t.Errorf("missed fills should lower the estimate: before %s, after %s", before, after)
+	}
+
+	// Outcomes on another venue don't count.
+	if other := svc.AtomicityProbability("okx", []domain.LegSpec{leg}, books); !other.Equal(before) {
+		t.Errorf("okx estimate changed to %s, want %s", other, before)
+	}
+}
+
+func TestPassiveFillProbability(t *testing.T) {
+	svc := newAtomicityTestService()
+
+	// Resting at the touch needs no depth at the quote's price.
+	tight := svc.PassiveFillProbability("kcex", "BTC-USDT", atomicityBook("99.99", "100", "0.01"))
+	wide := svc.PassiveFillProbability("kcex", "BTC-USDT", atomicityBook("99", "100", "0.01"))
+	if !tight.GreaterThan(wide) || tight.GreaterThan(decimal.NewFromFloat(priorFillRate)) {
+		t.Errorf("tight %s should score above wide %s and at most the prior", tight, wide)
+	}
+
+	for i := 0; i < fillHistoryLen; i++ {
+		svc.RecordFillOutcome("kcex", "BTC-USDT", false)
+	}
+	if after := svc.PassiveFillProbability("kcex", "BTC-USDT", atomicityBook("99.99", "100", "0.01")); !after.LessThan(tight) {
+		t.Errorf("missed fills should lower the estimate: before %s, after %s", tight, after)
+	}
+	if p := svc.PassiveFillProbability("kcex", "BTC-USDT", &domain.OrderBookSnapshot{}); !p.IsZero() {
+		t.Errorf("empty book: got %s, want 0", p)
+	}
+}
+
+func TestEstimateCost_MakerRebate(t *testing.T) {
+	svc := newAtomicityTestService()
+	svc.UpdateFeeTier("kcex", &domain.FeeTier{Venue: "kcex", MakerFeeBps: decimal.NewFromFloat(-1), TakerFeeBps: decimal.NewFromInt(4)})
+
+	maker, err := svc.EstimateCost("kcex", "BTC-USDT", domain.SideBuy, decimal.NewFromInt(1), domain.OrderTypeLimit)
+	if err != nil {
+		t.Fatalf("estimate: %v", err)
+	}
+	taker, _ := svc.EstimateCost("kcex", "BTC-USDT", domain.SideBuy, decimal.NewFromInt(1), domain.OrderTypeMarket)
+	if !maker.FeeBps.Equal(decimal.NewFromInt(-1)) || !maker.TotalBps.Equal(taker.TotalBps.Sub(decimal.NewFromInt(5))) {
+		t.Errorf("rebate should lower the cost: maker fee %s total %s, taker total %s", maker.FeeBps, maker.TotalBps, taker.TotalBps)
+	}
+}
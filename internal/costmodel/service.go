@@ -22,12 +22,14 @@ type Service struct {
 	feeTiers      map[string]*domain.FeeTier // keyed by venue
 	slippageCurves map[string]*SlippageCurve  // keyed by "venue:symbol"
 	fundingRates   map[string][]domain.FundingRate // keyed by "venue:symbol"
+	fillHistory    map[string]*fillHistory         // keyed by "venue:symbol"
 
 	gateways map[string]gateway.VenueGateway
 	logger   *slog.Logger
 
 	feeTierRefreshInterval time.Duration
 	fundingLookback        int
+	transferCostBps        decimal.Decimal
 }
 
 func NewService(
@@ -40,6 +42,7 @@ func NewService(
 		feeTiers:               make(map[string]*domain.FeeTier),
 		slippageCurves:         make(map[string]*SlippageCurve),
 		fundingRates:           make(map[string][]domain.FundingRate),
+		fillHistory:            make(map[string]*fillHistory),
 		gateways:               gateways,
 		logger:                 logger,
 		feeTierRefreshInterval: feeTierRefresh,
@@ -130,12 +133,29 @@ func (s *Service) UpdateFeeTier(venue string, tier *domain.FeeTier) {
 	s.feeTiers[venue] = tier
 }
 
+// FeeTier returns the fee tier last refreshed for venue.
+func (s *Service) FeeTier(venue string) (*domain.FeeTier, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tier, ok := s.feeTiers[venue]
+	return tier, ok
+}
+
+// AddFundingRate records a funding rate for symbol on venue. Venues push
+// the predicted rate many times per funding interval; an update for the
+// same next funding time as the last one replaces it, so the lookback
+// counts funding intervals rather than updates.
 func (s *Service) AddFundingRate(venue, symbol string, rate domain.FundingRate) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	key := venue + ":" + symbol
-	s.fundingRates[key] = append(s.fundingRates[key], rate)
+	rates := s.fundingRates[key]
+	if n := len(rates); n > 0 && !rate.NextTime.IsZero() && rates[n-1].NextTime.Equal(rate.NextTime) {
+		rates[n-1] = rate
+		return
+	}
+	s.fundingRates[key] = append(rates, rate)
 
 	maxLen := s.fundingLookback * 2
 	if len(s.fundingRates[key]) > maxLen {
@@ -0,0 +1,37 @@
+package costmodel
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestAddFundingRateKeepsOneRatePerInterval(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	svc := NewService(nil, 0, 2, logger)
+
+	first := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	rate := func(next time.Time, r string) domain.FundingRate {
+		return domain.FundingRate{Venue: "kcex", Symbol: "BTCUSDT", Rate: decimal.RequireFromString(r), NextTime: next}
+	}
+	// Many predictions for the 08:00 funding, the last one standing, then
+	// one for 16:00.
+	svc.AddFundingRate("kcex", "BTCUSDT", rate(first, "0.0005"))
+	svc.AddFundingRate("kcex", "BTCUSDT", rate(first, "0.0003"))
+	svc.AddFundingRate("kcex", "BTCUSDT", rate(first, "0.0001"))
+	svc.AddFundingRate("kcex", "BTCUSDT", rate(first.Add(8*time.Hour), "0.0004"))
+
+	if n := len(svc.fundingRates["kcex:BTCUSDT"]); n != 2 {
+		t.Fatalf("expected one rate per funding interval, got %d", n)
+	}
+	// Weighted 1:2 over the two intervals: (0.0001 + 2×0.0004) / 3 = 3 bps.
+	bps := svc.getFundingBps("kcex", "BTCUSDT")
+	if bps == nil || !bps.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("expected 3 bps of funding, got %v", bps)
+	}
+}
@@ -0,0 +1,32 @@
+package costmodel
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// TransferCostEstimator estimates what a trade that buys an asset on one
+// venue and sells it on another costs in rebalancing: the withdrawal fees
+// and network costs of moving the inventory back, spread over the trades
+// one transfer rebalances, in bps of the trade's notional.
+type TransferCostEstimator interface {
+	TransferCostBps(asset, fromVenue, toVenue string) decimal.Decimal
+}
+
+// SetTransferCostBps sets the amortized cost of moving inventory between
+// any two venues, in bps of notional.
+func (s *Service) SetTransferCostBps(bps decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transferCostBps = bps
+}
+
+// TransferCostBps returns the amortized cost of moving asset from fromVenue
+// to toVenue. Keeping inventory on one venue costs nothing to rebalance.
+func (s *Service) TransferCostBps(asset, fromVenue, toVenue string) decimal.Decimal {
+	if fromVenue == toVenue {
+		return decimal.Zero
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.transferCostBps
+}
@@ -0,0 +1,22 @@
+package costmodel
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTransferCostBps(t *testing.T) {
+	svc := newAtomicityTestService()
+	if bps := svc.TransferCostBps("BTC", "kcex", "nobitex"); !bps.IsZero() {
+		t.Errorf("expected no transfer cost before one is set, got %s", bps)
+	}
+
+	svc.SetTransferCostBps(decimal.NewFromInt(5))
+	if bps := svc.TransferCostBps("BTC", "kcex", "nobitex"); !bps.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected 5 bps between venues, got %s", bps)
+	}
+	if bps := svc.TransferCostBps("BTC", "kcex", "kcex"); !bps.IsZero() {
+		t.Errorf("expected no transfer cost within a venue, got %s", bps)
+	}
+}
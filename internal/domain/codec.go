@@ -0,0 +1,839 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Schema names identify the type carried by an Envelope.
+const (
+	SchemaTradeSignal     = "trade_signal"
+	SchemaExecutionReport = "execution_report"
+	SchemaRiskState       = "risk_state"
+	SchemaOrder           = "order"
+)
+
+// Current schema versions written by the Encode* functions. Bump a version
+// (and add a wire struct plus an upgrade path in the matching Decode*) when
+// a change to the domain struct would alter what is stored. The direct
+// struct conversions below stop compiling when a domain struct changes,
+// which is the cue to add a new version.
+const (
+	TradeSignalSchemaVersion     = 4
+	ExecutionReportSchemaVersion = 1
+	RiskStateSchemaVersion       = 2
+	OrderSchemaVersion           = 6
+)
+
+var (
+	ErrSchemaMismatch           = errors.New("schema mismatch")
+	ErrUnsupportedSchemaVersion = errors.New("unsupported schema version")
+)
+
+// Envelope is the versioned JSON container used for persisted and mirrored
+// domain values: SQLite risk checkpoints, archived orders and execution
+// reports, and webhook payloads. Data holds the version-specific wire
+// representation, which is decoupled from the in-memory struct layout.
+type Envelope struct {
+	Schema  string          `json:"schema"`
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func encodeEnvelope(schema string, version int, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s v%d: %w", schema, version, err)
+	}
+	return json.Marshal(Envelope{Schema: schema, Version: version, Data: data})
+}
+
+// decodeEnvelope parses the envelope and checks that it carries the expected schema.
+func decodeEnvelope(raw []byte, schema string) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, fmt.Errorf("parse envelope: %w", err)
+	}
+	if env.Schema != schema {
+		return nil, fmt.Errorf("%w: got %q, want %q", ErrSchemaMismatch, env.Schema, schema)
+	}
+	return &env, nil
+}
+
+// --- TradeSignal ---
+
+type legSpecV1 struct {
+	Symbol         string          `json:"symbol"`
+	Side           Side            `json:"side"`
+	InstrumentType InstrumentType  `json:"instrument_type"`
+	Price          decimal.Decimal `json:"price"`
+	Size           decimal.Decimal `json:"size"`
+	OrderType      OrderType       `json:"order_type"`
+}
+
+// legSpecV3 adds Venue.
+type legSpecV3 struct {
+	Symbol         string          `json:"symbol"`
+	Side           Side            `json:"side"`
+	InstrumentType InstrumentType  `json:"instrument_type"`
+	Price          decimal.Decimal `json:"price"`
+	Size           decimal.Decimal `json:"size"`
+	OrderType      OrderType       `json:"order_type"`
+	Venue          string          `json:"venue,omitempty"`
+}
+
+// legSpecV4 adds ReduceOnly.
+type legSpecV4 struct {
+	Symbol         string          `json:"symbol"`
+	Side           Side            `json:"side"`
+	InstrumentType InstrumentType  `json:"instrument_type"`
+	Price          decimal.Decimal `json:"price"`
+	Size           decimal.Decimal `json:"size"`
+	OrderType      OrderType       `json:"order_type"`
+	Venue          string          `json:"venue,omitempty"`
+	ReduceOnly     bool            `json:"reduce_only,omitempty"`
+}
+
+type costEstimateV1 struct {
+	FeeBps      decimal.Decimal  `json:"fee_bps"`
+	SlippageBps decimal.Decimal  `json:"slippage_bps"`
+	FundingBps  *decimal.Decimal `json:"funding_bps,omitempty"`
+	TotalBps    decimal.Decimal  `json:"total_bps"`
+	Confidence  decimal.Decimal  `json:"confidence"`
+}
+
+type tradeSignalV1 struct {
+	SignalID            uuid.UUID       `json:"signal_id"`
+	Strategy            StrategyType    `json:"strategy"`
+	Venue               string          `json:"venue"`
+	Legs                []legSpecV1     `json:"legs"`
+	ExpectedEdgeBps     decimal.Decimal `json:"expected_edge_bps"`
+	CostEstimate        costEstimateV1  `json:"cost_estimate"`
+	Confidence          decimal.Decimal `json:"confidence"`
+	CreatedAt           time.Time       `json:"created_at"`
+	MarketDataTimestamp time.Time       `json:"market_data_timestamp"`
+}
+
+// tradeSignalV2 adds Atomicity.
+type tradeSignalV2 struct {
+	SignalID            uuid.UUID       `json:"signal_id"`
+	Strategy            StrategyType    `json:"strategy"`
+	Venue               string          `json:"venue"`
+	Legs                []legSpecV1     `json:"legs"`
+	ExpectedEdgeBps     decimal.Decimal `json:"expected_edge_bps"`
+	CostEstimate        costEstimateV1  `json:"cost_estimate"`
+	Confidence          decimal.Decimal `json:"confidence"`
+	Atomicity           decimal.Decimal `json:"atomicity"`
+	CreatedAt           time.Time       `json:"created_at"`
+	MarketDataTimestamp time.Time       `json:"market_data_timestamp"`
+}
+
+// tradeSignalV3 adds per-leg venues.
+type tradeSignalV3 struct {
+	SignalID            uuid.UUID       `json:"signal_id"`
+	Strategy            StrategyType    `json:"strategy"`
+	Venue               string          `json:"venue"`
+	Legs                []legSpecV3     `json:"legs"`
+	ExpectedEdgeBps     decimal.Decimal `json:"expected_edge_bps"`
+	CostEstimate        costEstimateV1  `json:"cost_estimate"`
+	Confidence          decimal.Decimal `json:"confidence"`
+	Atomicity           decimal.Decimal `json:"atomicity"`
+	CreatedAt           time.Time       `json:"created_at"`
+	MarketDataTimestamp time.Time       `json:"market_data_timestamp"`
+}
+
+// tradeSignalV4 adds reduce-only legs.
+type tradeSignalV4 struct {
+	SignalID            uuid.UUID       `json:"signal_id"`
+	Strategy            StrategyType    `json:"strategy"`
+	Venue               string          `json:"venue"`
+	Legs                []legSpecV4     `json:"legs"`
+	ExpectedEdgeBps     decimal.Decimal `json:"expected_edge_bps"`
+	CostEstimate        costEstimateV1  `json:"cost_estimate"`
+	Confidence          decimal.Decimal `json:"confidence"`
+	Atomicity           decimal.Decimal `json:"atomicity"`
+	CreatedAt           time.Time       `json:"created_at"`
+	MarketDataTimestamp time.Time       `json:"market_data_timestamp"`
+}
+
+// upgradeLegsV1 converts v1 legs, which all trade on the signal's venue.
+func upgradeLegsV1(legs []legSpecV1) []legSpecV4 {
+	out := make([]legSpecV4, len(legs))
+	for i, l := range legs {
+		out[i] = legSpecV4{
+			Symbol:         l.Symbol,
+			Side:           l.Side,
+			InstrumentType: l.InstrumentType,
+			Price:          l.Price,
+			Size:           l.Size,
+			OrderType:      l.OrderType,
+		}
+	}
+	return out
+}
+
+// upgradeLegsV3 converts v3 legs, none of which are reduce-only.
+func upgradeLegsV3(legs []legSpecV3) []legSpecV4 {
+	out := make([]legSpecV4, len(legs))
+	for i, l := range legs {
+		out[i] = legSpecV4{
+			Symbol:         l.Symbol,
+			Side:           l.Side,
+			InstrumentType: l.InstrumentType,
+			Price:          l.Price,
+			Size:           l.Size,
+			OrderType:      l.OrderType,
+			Venue:          l.Venue,
+		}
+	}
+	return out
+}
+
+// EncodeTradeSignal serializes a TradeSignal into a versioned envelope.
+func EncodeTradeSignal(s *TradeSignal) ([]byte, error) {
+	w := tradeSignalV4{
+		SignalID:        s.SignalID,
+		Strategy:        s.Strategy,
+		Venue:           s.Venue,
+		Legs:            make([]legSpecV4, len(s.Legs)),
+		ExpectedEdgeBps: s.ExpectedEdgeBps,
+		CostEstimate: costEstimateV1{
+			FeeBps:      s.CostEstimate.FeeBps,
+			SlippageBps: s.CostEstimate.SlippageBps,
+			FundingBps:  s.CostEstimate.FundingBps,
+			TotalBps:    s.CostEstimate.TotalBps,
+			Confidence:  s.CostEstimate.Confidence,
+		},
+		Confidence:          s.Confidence,
+		Atomicity:           s.Atomicity,
+		CreatedAt:           s.CreatedAt,
+		MarketDataTimestamp: s.MarketDataTimestamp,
+	}
+	for i, l := range s.Legs {
+		w.Legs[i] = legSpecV4(l)
+	}
+	return encodeEnvelope(SchemaTradeSignal, TradeSignalSchemaVersion, w)
+}
+
+// DecodeTradeSignal parses a TradeSignal from a versioned envelope.
+func DecodeTradeSignal(raw []byte) (*TradeSignal, error) {
+	env, err := decodeEnvelope(raw, SchemaTradeSignal)
+	if err != nil {
+		return nil, err
+	}
+	switch env.Version {
+	case 1:
+		var w tradeSignalV1
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse trade signal v1: %w", err)
+		}
+		// v1 predates atomicity scoring, so Atomicity is zero.
+		return tradeSignalV4{
+			SignalID:            w.SignalID,
+			Strategy:            w.Strategy,
+			Venue:               w.Venue,
+			Legs:                upgradeLegsV1(w.Legs),
+			ExpectedEdgeBps:     w.ExpectedEdgeBps,
+			CostEstimate:        w.CostEstimate,
+			Confidence:          w.Confidence,
+			CreatedAt:           w.CreatedAt,
+			MarketDataTimestamp: w.MarketDataTimestamp,
+		}.signal(), nil
+	case 2:
+		var w tradeSignalV2
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse trade signal v2: %w", err)
+		}
+		return tradeSignalV4{
+			SignalID:            w.SignalID,
+			Strategy:            w.Strategy,
+			Venue:               w.Venue,
+			Legs:                upgradeLegsV1(w.Legs),
+			ExpectedEdgeBps:     w.ExpectedEdgeBps,
+			CostEstimate:        w.CostEstimate,
+			Confidence:          w.Confidence,
+			Atomicity:           w.Atomicity,
+			CreatedAt:           w.CreatedAt,
+			MarketDataTimestamp: w.MarketDataTimestamp,
+		}.signal(), nil
+	case 3:
+		var w tradeSignalV3
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse trade signal v3: %w", err)
+		}
+		return tradeSignalV4{
+			SignalID:            w.SignalID,
+			Strategy:            w.Strategy,
+			Venue:               w.Venue,
+			Legs:                upgradeLegsV3(w.Legs),
+			ExpectedEdgeBps:     w.ExpectedEdgeBps,
+			CostEstimate:        w.CostEstimate,
+			Confidence:          w.Confidence,
+			Atomicity:           w.Atomicity,
+			CreatedAt:           w.CreatedAt,
+			MarketDataTimestamp: w.MarketDataTimestamp,
+		}.signal(), nil
+	case 4:
+		var w tradeSignalV4
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse trade signal v4: %w", err)
+		}
+		return w.signal(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedSchemaVersion, env.Schema, env.Version)
+	}
+}
+
+func (w tradeSignalV4) signal() *TradeSignal {
+	s := &TradeSignal{
+		SignalID:        w.SignalID,
+		Strategy:        w.Strategy,
+		Venue:           w.Venue,
+		Legs:            make([]LegSpec, len(w.Legs)),
+		ExpectedEdgeBps: w.ExpectedEdgeBps,
+		CostEstimate: CostEstimate{
+			FeeBps:      w.CostEstimate.FeeBps,
+			SlippageBps: w.CostEstimate.SlippageBps,
+			FundingBps:  w.CostEstimate.FundingBps,
+			TotalBps:    w.CostEstimate.TotalBps,
+			Confidence:  w.CostEstimate.Confidence,
+		},
+		Confidence:          w.Confidence,
+		Atomicity:           w.Atomicity,
+		CreatedAt:           w.CreatedAt,
+		MarketDataTimestamp: w.MarketDataTimestamp,
+	}
+	for i, l := range w.Legs {
+		s.Legs[i] = LegSpec(l)
+	}
+	return s
+}
+
+// --- ExecutionReport ---
+
+type legExecutionV1 struct {
+	Symbol        string          `json:"symbol"`
+	Side          Side            `json:"side"`
+	ExpectedPrice decimal.Decimal `json:"expected_price"`
+	ActualPrice   decimal.Decimal `json:"actual_price"`
+	ExpectedSize  decimal.Decimal `json:"expected_size"`
+	ActualSize    decimal.Decimal `json:"actual_size"`
+	SlippageBps   decimal.Decimal `json:"slippage_bps"`
+	Fee           decimal.Decimal `json:"fee"`
+}
+
+type executionReportV1 struct {
+	SignalID        uuid.UUID        `json:"signal_id"`
+	Strategy        StrategyType     `json:"strategy"`
+	Venue           string           `json:"venue"`
+	Legs            []legExecutionV1 `json:"legs"`
+	ExpectedEdgeBps decimal.Decimal  `json:"expected_edge_bps"`
+	RealizedEdgeBps decimal.Decimal  `json:"realized_edge_bps"`
+	TotalFees       decimal.Decimal  `json:"total_fees"`
+	SlippageBps     decimal.Decimal  `json:"slippage_bps"`
+	Status          string           `json:"status"`
+	StartedAt       time.Time        `json:"started_at"`
+	CompletedAt     time.Time        `json:"completed_at"`
+}
+
+// EncodeExecutionReport serializes an ExecutionReport into a versioned envelope.
+func EncodeExecutionReport(r *ExecutionReport) ([]byte, error) {
+	w := executionReportV1{
+		SignalID:        r.SignalID,
+		Strategy:        r.Strategy,
+		Venue:           r.Venue,
+		Legs:            make([]legExecutionV1, len(r.Legs)),
+		ExpectedEdgeBps: r.ExpectedEdgeBps,
+		RealizedEdgeBps: r.RealizedEdgeBps,
+		TotalFees:       r.TotalFees,
+		SlippageBps:     r.SlippageBps,
+		Status:          r.Status,
+		StartedAt:       r.StartedAt,
+		CompletedAt:     r.CompletedAt,
+	}
+	for i, l := range r.Legs {
+		w.Legs[i] = legExecutionV1(l)
+	}
+	return encodeEnvelope(SchemaExecutionReport, ExecutionReportSchemaVersion, w)
+}
+
+// DecodeExecutionReport parses an ExecutionReport from a versioned envelope.
+func DecodeExecutionReport(raw []byte) (*ExecutionReport, error) {
+	env, err := decodeEnvelope(raw, SchemaExecutionReport)
+	if err != nil {
+		return nil, err
+	}
+	switch env.Version {
+	case 1:
+		var w executionReportV1
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse execution report v1: %w", err)
+		}
+		r := &ExecutionReport{
+			SignalID:        w.SignalID,
+			Strategy:        w.Strategy,
+			Venue:           w.Venue,
+			Legs:            make([]LegExecution, len(w.Legs)),
+			ExpectedEdgeBps: w.ExpectedEdgeBps,
+			RealizedEdgeBps: w.RealizedEdgeBps,
+			TotalFees:       w.TotalFees,
+			SlippageBps:     w.SlippageBps,
+			Status:          w.Status,
+			StartedAt:       w.StartedAt,
+			CompletedAt:     w.CompletedAt,
+		}
+		for i, l := range w.Legs {
+			r.Legs[i] = LegExecution(l)
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedSchemaVersion, env.Schema, env.Version)
+	}
+}
+
+// --- RiskState ---
+
+type positionV1 struct {
+	Venue          string          `json:"venue"`
+	Asset          string          `json:"asset"`
+	InstrumentType InstrumentType  `json:"instrument_type"`
+	Size           decimal.Decimal `json:"size"`
+	EntryPrice     decimal.Decimal `json:"entry_price"`
+	UnrealizedPnL  decimal.Decimal `json:"unrealized_pnl"`
+	MarginUsed     decimal.Decimal `json:"margin_used"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// positionV2 adds the sub-account the position is held in.
+type positionV2 struct {
+	Venue          string          `json:"venue"`
+	Account        string          `json:"account,omitempty"`
+	Asset          string          `json:"asset"`
+	InstrumentType InstrumentType  `json:"instrument_type"`
+	Size           decimal.Decimal `json:"size"`
+	EntryPrice     decimal.Decimal `json:"entry_price"`
+	UnrealizedPnL  decimal.Decimal `json:"unrealized_pnl"`
+	MarginUsed     decimal.Decimal `json:"margin_used"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type orderCountStateV1 struct {
+	Global    int            `json:"global"`
+	PerVenue  map[string]int `json:"per_venue"`
+	PerSymbol map[string]int `json:"per_symbol"`
+}
+
+// riskStateV1 stores positions as a list because the in-memory map is keyed
+// by a struct, which encoding/json cannot use as an object key.
+type riskStateV1 struct {
+	Mode               RiskMode                   `json:"mode"`
+	DailyRealizedPnL   decimal.Decimal            `json:"daily_realized_pnl"`
+	DailyUnrealizedPnL decimal.Decimal            `json:"daily_unrealized_pnl"`
+	Positions          []positionV1               `json:"positions"`
+	OpenOrderCounts    orderCountStateV1          `json:"open_order_counts"`
+	VenueNotionals     map[string]decimal.Decimal `json:"venue_notionals"`
+	LastCheckpoint     time.Time                  `json:"last_checkpoint"`
+	KillSwitchActive   bool                       `json:"kill_switch_active"`
+	KillSwitchReason   string                     `json:"kill_switch_reason,omitempty"`
+}
+
+// riskStateV2 stores positions as positionV2.
+type riskStateV2 struct {
+	Mode               RiskMode                   `json:"mode"`
+	DailyRealizedPnL   decimal.Decimal            `json:"daily_realized_pnl"`
+	DailyUnrealizedPnL decimal.Decimal            `json:"daily_unrealized_pnl"`
+	Positions          []positionV2               `json:"positions"`
+	OpenOrderCounts    orderCountStateV1          `json:"open_order_counts"`
+	VenueNotionals     map[string]decimal.Decimal `json:"venue_notionals"`
+	LastCheckpoint     time.Time                  `json:"last_checkpoint"`
+	KillSwitchActive   bool                       `json:"kill_switch_active"`
+	KillSwitchReason   string                     `json:"kill_switch_reason,omitempty"`
+}
+
+// EncodeRiskState serializes a RiskState into a versioned envelope.
+func EncodeRiskState(s *RiskState) ([]byte, error) {
+	w := riskStateV2{
+		Mode:               s.Mode,
+		DailyRealizedPnL:   s.DailyRealizedPnL,
+		DailyUnrealizedPnL: s.DailyUnrealizedPnL,
+		Positions:          make([]positionV2, 0, len(s.Positions)),
+		OpenOrderCounts: orderCountStateV1{
+			Global:    s.OpenOrderCounts.Global,
+			PerVenue:  s.OpenOrderCounts.PerVenue,
+			PerSymbol: s.OpenOrderCounts.PerSymbol,
+		},
+		VenueNotionals:   s.VenueNotionals,
+		LastCheckpoint:   s.LastCheckpoint,
+		KillSwitchActive: s.KillSwitchActive,
+		KillSwitchReason: s.KillSwitchReason,
+	}
+	for key, p := range s.Positions {
+		if p == nil {
+			continue
+		}
+		pos := positionV2(*p)
+		pos.Venue, pos.Account, pos.Asset = key.Venue, key.Account, key.Asset
+		w.Positions = append(w.Positions, pos)
+	}
+	return encodeEnvelope(SchemaRiskState, RiskStateSchemaVersion, w)
+}
+
+// DecodeRiskState parses a RiskState from a versioned envelope.
+func DecodeRiskState(raw []byte) (*RiskState, error) {
+	env, err := decodeEnvelope(raw, SchemaRiskState)
+	if err != nil {
+		return nil, err
+	}
+	switch env.Version {
+	case 1:
+		var w riskStateV1
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse risk state v1: %w", err)
+		}
+		// v1 predates sub-accounts, so every position is on the main account.
+		w2 := riskStateV2{
+			Mode:               w.Mode,
+			DailyRealizedPnL:   w.DailyRealizedPnL,
+			DailyUnrealizedPnL: w.DailyUnrealizedPnL,
+			Positions:          make([]positionV2, 0, len(w.Positions)),
+			OpenOrderCounts:    w.OpenOrderCounts,
+			VenueNotionals:     w.VenueNotionals,
+			LastCheckpoint:     w.LastCheckpoint,
+			KillSwitchActive:   w.KillSwitchActive,
+			KillSwitchReason:   w.KillSwitchReason,
+		}
+		for _, p := range w.Positions {
+			w2.Positions = append(w2.Positions, positionV2{
+				Venue:          p.Venue,
+				Asset:          p.Asset,
+				InstrumentType: p.InstrumentType,
+				Size:           p.Size,
+				EntryPrice:     p.EntryPrice,
+				UnrealizedPnL:  p.UnrealizedPnL,
+				MarginUsed:     p.MarginUsed,
+				UpdatedAt:      p.UpdatedAt,
+			})
+		}
+		return riskStateFromV2(w2), nil
+	case 2:
+		var w riskStateV2
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse risk state v2: %w", err)
+		}
+		return riskStateFromV2(w), nil
+	default:
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedSchemaVersion, env.Schema, env.Version)
+	}
+}
+
+func riskStateFromV2(w riskStateV2) *RiskState {
+	s := &RiskState{
+		Mode:               w.Mode,
+		DailyRealizedPnL:   w.DailyRealizedPnL,
+		DailyUnrealizedPnL: w.DailyUnrealizedPnL,
+		Positions:          make(map[VenueAssetKey]*Position, len(w.Positions)),
+		OpenOrderCounts: OrderCountState{
+			Global:    w.OpenOrderCounts.Global,
+			PerVenue:  w.OpenOrderCounts.PerVenue,
+			PerSymbol: w.OpenOrderCounts.PerSymbol,
+		},
+		VenueNotionals:   w.VenueNotionals,
+		LastCheckpoint:   w.LastCheckpoint,
+		KillSwitchActive: w.KillSwitchActive,
+		KillSwitchReason: w.KillSwitchReason,
+	}
+	for _, p := range w.Positions {
+		pos := Position(p)
+		s.Positions[VenueAssetKey{Venue: p.Venue, Account: p.Account, Asset: p.Asset}] = &pos
+	}
+	return s
+}
+
+// --- Order ---
+
+type orderV1 struct {
+	InternalID   uuid.UUID       `json:"internal_id"`
+	VenueID      string          `json:"venue_id"`
+	SignalID     uuid.UUID       `json:"signal_id"`
+	Venue        string          `json:"venue"`
+	Symbol       string          `json:"symbol"`
+	Side         Side            `json:"side"`
+	OrderType    OrderType       `json:"order_type"`
+	Price        decimal.Decimal `json:"price"`
+	Size         decimal.Decimal `json:"size"`
+	FilledSize   decimal.Decimal `json:"filled_size"`
+	AvgFillPrice decimal.Decimal `json:"avg_fill_price"`
+	Status       OrderStatus     `json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// orderV2 adds TimeInForce and PostOnly.
+type orderV2 struct {
+	InternalID   uuid.UUID       `json:"internal_id"`
+	VenueID      string          `json:"venue_id"`
+	SignalID     uuid.UUID       `json:"signal_id"`
+	Venue        string          `json:"venue"`
+	Symbol       string          `json:"symbol"`
+	Side         Side            `json:"side"`
+	OrderType    OrderType       `json:"order_type"`
+	TimeInForce  TimeInForce     `json:"time_in_force,omitempty"`
+	PostOnly     bool            `json:"post_only,omitempty"`
+	Price        decimal.Decimal `json:"price"`
+	Size         decimal.Decimal `json:"size"`
+	FilledSize   decimal.Decimal `json:"filled_size"`
+	AvgFillPrice decimal.Decimal `json:"avg_fill_price"`
+	Status       OrderStatus     `json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// orderV3 adds StopPrice.
+type orderV3 struct {
+	InternalID   uuid.UUID       `json:"internal_id"`
+	VenueID      string          `json:"venue_id"`
+	SignalID     uuid.UUID       `json:"signal_id"`
+	Venue        string          `json:"venue"`
+	Symbol       string          `json:"symbol"`
+	Side         Side            `json:"side"`
+	OrderType    OrderType       `json:"order_type"`
+	TimeInForce  TimeInForce     `json:"time_in_force,omitempty"`
+	PostOnly     bool            `json:"post_only,omitempty"`
+	Price        decimal.Decimal `json:"price"`
+	StopPrice    decimal.Decimal `json:"stop_price"`
+	Size         decimal.Decimal `json:"size"`
+	FilledSize   decimal.Decimal `json:"filled_size"`
+	AvgFillPrice decimal.Decimal `json:"avg_fill_price"`
+	Status       OrderStatus     `json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// orderV4 adds InstrumentType.
+type orderV4 struct {
+	InternalID     uuid.UUID       `json:"internal_id"`
+	VenueID        string          `json:"venue_id"`
+	SignalID       uuid.UUID       `json:"signal_id"`
+	Venue          string          `json:"venue"`
+	Symbol         string          `json:"symbol"`
+	InstrumentType InstrumentType  `json:"instrument_type,omitempty"`
+	Side           Side            `json:"side"`
+	OrderType      OrderType       `json:"order_type"`
+	TimeInForce    TimeInForce     `json:"time_in_force,omitempty"`
+	PostOnly       bool            `json:"post_only,omitempty"`
+	Price          decimal.Decimal `json:"price"`
+	StopPrice      decimal.Decimal `json:"stop_price"`
+	Size           decimal.Decimal `json:"size"`
+	FilledSize     decimal.Decimal `json:"filled_size"`
+	AvgFillPrice   decimal.Decimal `json:"avg_fill_price"`
+	Status         OrderStatus     `json:"status"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// orderV5 adds ReduceOnly.
+type orderV5 struct {
+	InternalID     uuid.UUID       `json:"internal_id"`
+	VenueID        string          `json:"venue_id"`
+	SignalID       uuid.UUID       `json:"signal_id"`
+	Venue          string          `json:"venue"`
+	Symbol         string          `json:"symbol"`
+	InstrumentType InstrumentType  `json:"instrument_type,omitempty"`
+	Side           Side            `json:"side"`
+	OrderType      OrderType       `json:"order_type"`
+	TimeInForce    TimeInForce     `json:"time_in_force,omitempty"`
+	PostOnly       bool            `json:"post_only,omitempty"`
+	ReduceOnly     bool            `json:"reduce_only,omitempty"`
+	Price          decimal.Decimal `json:"price"`
+	StopPrice      decimal.Decimal `json:"stop_price"`
+	Size           decimal.Decimal `json:"size"`
+	FilledSize     decimal.Decimal `json:"filled_size"`
+	AvgFillPrice   decimal.Decimal `json:"avg_fill_price"`
+	Status         OrderStatus     `json:"status"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// orderV6 adds the strategy that placed the order and the account it
+// trades on.
+type orderV6 struct {
+	InternalID     uuid.UUID       `json:"internal_id"`
+	VenueID        string          `json:"venue_id"`
+	SignalID       uuid.UUID       `json:"signal_id"`
+	Strategy       StrategyType    `json:"strategy,omitempty"`
+	Venue          string          `json:"venue"`
+	Account        string          `json:"account,omitempty"`
+	Symbol         string          `json:"symbol"`
+	InstrumentType InstrumentType  `json:"instrument_type,omitempty"`
+	Side           Side            `json:"side"`
+	OrderType      OrderType       `json:"order_type"`
+	TimeInForce    TimeInForce     `json:"time_in_force,omitempty"`
+	PostOnly       bool            `json:"post_only,omitempty"`
+	ReduceOnly     bool            `json:"reduce_only,omitempty"`
+	Price          decimal.Decimal `json:"price"`
+	StopPrice      decimal.Decimal `json:"stop_price"`
+	Size           decimal.Decimal `json:"size"`
+	FilledSize     decimal.Decimal `json:"filled_size"`
+	AvgFillPrice   decimal.Decimal `json:"avg_fill_price"`
+	Status         OrderStatus     `json:"status"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// EncodeOrder serializes an Order into a versioned envelope.
+func EncodeOrder(o *Order) ([]byte, error) {
+	return encodeEnvelope(SchemaOrder, OrderSchemaVersion, orderV6(*o))
+}
+
+// DecodeOrder parses an Order from a versioned envelope.
+func DecodeOrder(raw []byte) (*Order, error) {
+	env, err := decodeEnvelope(raw, SchemaOrder)
+	if err != nil {
+		return nil, err
+	}
+	switch env.Version {
+	case 1:
+		var w orderV1
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse order v1: %w", err)
+		}
+		// v1 predates TimeInForce/PostOnly; the zero values mean venue default.
+		o := Order{
+			InternalID:   w.InternalID,
+			VenueID:      w.VenueID,
+			SignalID:     w.SignalID,
+			Venue:        w.Venue,
+			Symbol:       w.Symbol,
+			Side:         w.Side,
+			OrderType:    w.OrderType,
+			Price:        w.Price,
+			Size:         w.Size,
+			FilledSize:   w.FilledSize,
+			AvgFillPrice: w.AvgFillPrice,
+			Status:       w.Status,
+			CreatedAt:    w.CreatedAt,
+			UpdatedAt:    w.UpdatedAt,
+		}
+		return &o, nil
+	case 2:
+		var w orderV2
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse order v2: %w", err)
+		}
+		// v2 predates stop orders, so StopPrice is zero.
+		o := Order{
+			InternalID:   w.InternalID,
+			VenueID:      w.VenueID,
+			SignalID:     w.SignalID,
+			Venue:        w.Venue,
+			Symbol:       w.Symbol,
+			Side:         w.Side,
+			OrderType:    w.OrderType,
+			TimeInForce:  w.TimeInForce,
+			PostOnly:     w.PostOnly,
+			Price:        w.Price,
+			Size:         w.Size,
+			FilledSize:   w.FilledSize,
+			AvgFillPrice: w.AvgFillPrice,
+			Status:       w.Status,
+			CreatedAt:    w.CreatedAt,
+			UpdatedAt:    w.UpdatedAt,
+		}
+		return &o, nil
+	case 3:
+		var w orderV3
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse order v3: %w", err)
+		}
+		// v3 predates InstrumentType, which stays unknown.
+		o := Order{
+			InternalID:   w.InternalID,
+			VenueID:      w.VenueID,
+			SignalID:     w.SignalID,
+			Venue:        w.Venue,
+			Symbol:       w.Symbol,
+			Side:         w.Side,
+			OrderType:    w.OrderType,
+			TimeInForce:  w.TimeInForce,
+			PostOnly:     w.PostOnly,
+			Price:        w.Price,
+			StopPrice:    w.StopPrice,
+			Size:         w.Size,
+			FilledSize:   w.FilledSize,
+			AvgFillPrice: w.AvgFillPrice,
+			Status:       w.Status,
+			CreatedAt:    w.CreatedAt,
+			UpdatedAt:    w.UpdatedAt,
+		}
+		return &o, nil
+	case 4:
+		var w orderV4
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse order v4: %w", err)
+		}
+		// v4 predates reduce-only orders.
+		o := Order{
+			InternalID:     w.InternalID,
+			VenueID:        w.VenueID,
+			SignalID:       w.SignalID,
+			Venue:          w.Venue,
+			Symbol:         w.Symbol,
+			InstrumentType: w.InstrumentType,
+			Side:           w.Side,
+			OrderType:      w.OrderType,
+			TimeInForce:    w.TimeInForce,
+			PostOnly:       w.PostOnly,
+			Price:          w.Price,
+			StopPrice:      w.StopPrice,
+			Size:           w.Size,
+			FilledSize:     w.FilledSize,
+			AvgFillPrice:   w.AvgFillPrice,
+			Status:         w.Status,
+			CreatedAt:      w.CreatedAt,
+			UpdatedAt:      w.UpdatedAt,
+		}
+		return &o, nil
+	case 5:
+		var w orderV5
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse order v5: %w", err)
+		}
+		// v5 predates sub-accounts, so the order is on the default account.
+		o := Order{
+			InternalID:     w.InternalID,
+			VenueID:        w.VenueID,
+			SignalID:       w.SignalID,
+			Venue:          w.Venue,
+			Symbol:         w.Symbol,
+			InstrumentType: w.InstrumentType,
+			Side:           w.Side,
+			OrderType:      w.OrderType,
+			TimeInForce:    w.TimeInForce,
+			PostOnly:       w.PostOnly,
+			ReduceOnly:     w.ReduceOnly,
+			Price:          w.Price,
+			StopPrice:      w.StopPrice,
+			Size:           w.Size,
+			FilledSize:     w.FilledSize,
+			AvgFillPrice:   w.AvgFillPrice,
+			Status:         w.Status,
+			CreatedAt:      w.CreatedAt,
+			UpdatedAt:      w.UpdatedAt,
+		}
+		return &o, nil
+	case 6:
+		var w orderV6
+		if err := json.Unmarshal(env.Data, &w); err != nil {
+			return nil, fmt.Errorf("parse order v6: %w", err)
+		}
+		o := Order(w)
+		return &o, nil
+	default:
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedSchemaVersion, env.Schema, env.Version)
+	}
+}
@@ -0,0 +1,318 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestTradeSignalCodecRoundTrip(t *testing.T) {
+	funding := decimal.NewFromFloat(1.25)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	sig := &TradeSignal{
+		SignalID: uuid.Must(uuid.NewV7()),
+		Strategy: StrategyBasisArb,
+		Venue:    "kcex",
+		Legs: []LegSpec{
+			{Symbol: "BTC/USDT", Side: SideBuy, InstrumentType: InstrumentSpot, Price: decimal.NewFromInt(60000), Size: decimal.NewFromFloat(0.1), OrderType: OrderTypeLimit},
+			{Symbol: "BTCUSDT", Side: SideSell, InstrumentType: InstrumentPerp, Price: decimal.NewFromInt(60100), Size: decimal.NewFromFloat(0.1), OrderType: OrderTypeLimit, Venue: "bybit", ReduceOnly: true},
+		},
+		ExpectedEdgeBps:     decimal.NewFromInt(25),
+		CostEstimate:        CostEstimate{FeeBps: decimal.NewFromInt(10), FundingBps: &funding, TotalBps: decimal.NewFromInt(16)},
+		Confidence:          decimal.NewFromFloat(0.8),
+		Atomicity:           decimal.NewFromFloat(0.72),
+		CreatedAt:           now,
+		MarketDataTimestamp: now.Add(-time.Millisecond),
+	}
+
+	data, err := EncodeTradeSignal(sig)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := DecodeTradeSignal(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if got.SignalID != sig.SignalID || got.Strategy != sig.Strategy || len(got.Legs) != 2 {
+		t.Fatalf("round trip mismatch: got %+v", got)
+	}
+	if got.Legs[1].InstrumentType != InstrumentPerp || !got.Legs[1].Price.Equal(sig.Legs[1].Price) {
+		t.Errorf("leg mismatch: got %+v, want %+v", got.Legs[1], sig.Legs[1])
+	}
+	if got.LegVenue(0) != "kcex" || got.LegVenue(1) != "bybit" || !got.CrossVenue() {
+		t.Errorf("leg venues mismatch: got %q and %q", got.LegVenue(0), got.LegVenue(1))
+	}
+	if got.Legs[0].ReduceOnly || !got.Legs[1].ReduceOnly {
+		t.Errorf("reduce-only mismatch: got %v and %v", got.Legs[0].ReduceOnly, got.Legs[1].ReduceOnly)
+	}
+	if got.CostEstimate.FundingBps == nil || !got.CostEstimate.FundingBps.Equal(funding) {
+		t.Errorf("funding bps mismatch: got %v, want %s", got.CostEstimate.FundingBps, funding)
+	}
+	if !got.CreatedAt.Equal(now) {
+		t.Errorf("created_at mismatch: got %s, want %s", got.CreatedAt, now)
+	}
+	if !got.Atomicity.Equal(sig.Atomicity) {
+		t.Errorf("atomicity mismatch: got %s, want %s", got.Atomicity, sig.Atomicity)
+	}
+}
+
+func TestTradeSignalCodecDecodesV1(t *testing.T) {
+	raw := []byte(`{"schema":"trade_signal","version":1,"data":{"strategy":"TRI_ARB","venue":"kcex","legs":[{"symbol":"BTC/USDT","side":"BUY","price":"60000","size":"0.1"}],"confidence":"0.8"}}`)
+
+	got, err := DecodeTradeSignal(raw)
+	if err != nil {
+		t.Fatalf("decode v1: %v", err)
+	}
+	if got.Venue != "kcex" || len(got.Legs) != 1 || !got.Confidence.Equal(decimal.NewFromFloat(0.8)) {
+		t.Errorf("unexpected v1 signal: %+v", got)
+	}
+	if !got.Atomicity.IsZero() {
+		t.Errorf("expected zero atomicity for v1 signal, got %s", got.Atomicity)
+	}
+}
+
+func TestExecutionReportCodecRoundTrip(t *testing.T) {
+	rep := &ExecutionReport{
+		SignalID:        uuid.Must(uuid.NewV7()),
+		Strategy:        StrategyTriArb,
+		Venue:           "nobitex",
+		Legs:            []LegExecution{{Symbol: "ETH/BTC", Side: SideSell, ActualPrice: decimal.NewFromFloat(0.052), Fee: decimal.NewFromFloat(0.0001)}},
+		RealizedEdgeBps: decimal.NewFromFloat(12.5),
+		Status:          "COMPLETED",
+	}
+
+	data, err := EncodeExecutionReport(rep)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := DecodeExecutionReport(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if got.Status != "COMPLETED" || !got.RealizedEdgeBps.Equal(rep.RealizedEdgeBps) {
+		t.Errorf("report mismatch: got %+v", got)
+	}
+	if len(got.Legs) != 1 || !got.Legs[0].Fee.Equal(rep.Legs[0].Fee) {
+		t.Errorf("leg mismatch: got %+v", got.Legs)
+	}
+}
+
+func TestRiskStateCodecRoundTrip(t *testing.T) {
+	key := VenueAssetKey{Venue: "kcex", Account: "basis", Asset: "BTC"}
+	state := &RiskState{
+		Mode:             RiskModeWarning,
+		DailyRealizedPnL: decimal.NewFromInt(-150),
+		Positions: map[VenueAssetKey]*Position{
+			key: {Venue: "kcex", Account: "basis", Asset: "BTC", InstrumentType: InstrumentPerp, Size: decimal.NewFromFloat(-0.5)},
+		},
+		OpenOrderCounts:  OrderCountState{Global: 3, PerVenue: map[string]int{"kcex": 3}},
+		VenueNotionals:   map[string]decimal.Decimal{"kcex": decimal.NewFromInt(30000)},
+		KillSwitchActive: true,
+		KillSwitchReason: "manual",
+	}
+
+	// encoding/json cannot marshal the struct-keyed positions map directly.
+	if _, err := json.Marshal(state); err == nil {
+		t.Fatal("expected plain json.Marshal of RiskState to fail")
+	}
+
+	data, err := EncodeRiskState(state)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := DecodeRiskState(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if got.Mode != RiskModeWarning || !got.KillSwitchActive || got.KillSwitchReason != "manual" {
+		t.Errorf("state mismatch: got %+v", got)
+	}
+	pos, ok := got.Positions[key]
+	if !ok {
+		t.Fatalf("expected position for %v", key)
+	}
+	if !pos.Size.Equal(decimal.NewFromFloat(-0.5)) {
+		t.Errorf("position size: got %s, want -0.5", pos.Size)
+	}
+	if pos.Account != "basis" {
+		t.Errorf("position account: got %q, want basis", pos.Account)
+	}
+	if got.OpenOrderCounts.PerVenue["kcex"] != 3 {
+		t.Errorf("per-venue count: got %d, want 3", got.OpenOrderCounts.PerVenue["kcex"])
+	}
+	if !got.VenueNotionals["kcex"].Equal(decimal.NewFromInt(30000)) {
+		t.Errorf("venue notional: got %s, want 30000", got.VenueNotionals["kcex"])
+	}
+}
+
+func TestRiskStateCodecDecodesV1(t *testing.T) {
+	raw := []byte(`{"schema":"risk_state","version":1,"data":{"mode":"NORMAL","positions":[{"venue":"bybit","asset":"ETH","instrument_type":"PERP","size":"2"}],"open_order_counts":{"global":1}}}`)
+
+	got, err := DecodeRiskState(raw)
+	if err != nil {
+		t.Fatalf("decode v1: %v", err)
+	}
+	pos, ok := got.Positions[VenueAssetKey{Venue: "bybit", Asset: "ETH"}]
+	if !ok || !pos.Size.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("unexpected v1 positions: %+v", got.Positions)
+	}
+	if pos.Account != "" {
+		t.Errorf("expected main account for v1 position, got %q", pos.Account)
+	}
+	if got.OpenOrderCounts.Global != 1 {
+		t.Errorf("global count: got %d, want 1", got.OpenOrderCounts.Global)
+	}
+}
+
+func TestOrderCodecRoundTrip(t *testing.T) {
+	o := &Order{
+		InternalID:     uuid.Must(uuid.NewV7()),
+		VenueID:        "abc",
+		Strategy:       StrategyBasisArb,
+		Venue:          "kcex",
+		Account:        "basis",
+		Symbol:         "BTC/USDT",
+		Side:           SideBuy,
+		OrderType:      OrderTypeStopLimit,
+		TimeInForce:    TimeInForceIOC,
+		ReduceOnly:     true,
+		InstrumentType: InstrumentPerp,
+		Price:          decimal.NewFromInt(60000),
+		StopPrice:      decimal.NewFromInt(59000),
+		Size:           decimal.NewFromFloat(0.25),
+		Status:         OrderStatusPartialFill,
+	}
+
+	data, err := EncodeOrder(o)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := DecodeOrder(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.InternalID != o.InternalID || got.Status != o.Status || !got.Size.Equal(o.Size) || got.TimeInForce != o.TimeInForce || !got.StopPrice.Equal(o.StopPrice) || got.InstrumentType != o.InstrumentType || !got.ReduceOnly || got.Strategy != o.Strategy || got.Account != o.Account {
+		t.Errorf("order mismatch: got %+v, want %+v", got, o)
+	}
+}
+
+func TestOrderCodecDecodesV1(t *testing.T) {
+	raw := []byte(`{"schema":"order","version":1,"data":{"venue":"kcex","symbol":"BTC/USDT","order_type":"LIMIT","price":"60000","size":"0.25","status":"ACKNOWLEDGED"}}`)
+
+	got, err := DecodeOrder(raw)
+	if err != nil {
+		t.Fatalf("decode v1: %v", err)
+	}
+	if got.Venue != "kcex" || !got.Price.Equal(decimal.NewFromInt(60000)) {
+		t.Errorf("unexpected v1 order: %+v", got)
+	}
+	if got.TimeInForce != "" || got.PostOnly {
+		t.Errorf("expected venue-default flags for v1 order, got %s/%v", got.TimeInForce, got.PostOnly)
+	}
+}
+
+func TestOrderCodecDecodesV2(t *testing.T) {
+	raw := []byte(`{"schema":"order","version":2,"data":{"venue":"kcex","symbol":"BTCUSDT","order_type":"LIMIT","post_only":true,"price":"60000","size":"0.25","status":"ACKNOWLEDGED"}}`)
+
+	got, err := DecodeOrder(raw)
+	if err != nil {
+		t.Fatalf("decode v2: %v", err)
+	}
+	if !got.PostOnly || !got.StopPrice.IsZero() {
+		t.Errorf("unexpected v2 order: %+v", got)
+	}
+}
+
+func TestOrderCodecDecodesV3(t *testing.T) {
+	raw := []byte(`{"schema":"order","version":3,"data":{"venue":"kcex","symbol":"BTCUSDT","order_type":"STOP_LIMIT","price":"60000","stop_price":"59000","size":"0.25","status":"ACKNOWLEDGED"}}`)
+
+	got, err := DecodeOrder(raw)
+	if err != nil {
+		t.Fatalf("decode v3: %v", err)
+	}
+	if !got.StopPrice.Equal(decimal.NewFromInt(59000)) || got.InstrumentType != "" {
+		t.Errorf("unexpected v3 order: %+v", got)
+	}
+}
+
+func TestOrderCodecDecodesV4(t *testing.T) {
+	raw := []byte(`{"schema":"order","version":4,"data":{"venue":"bybit","symbol":"BTCUSDT","instrument_type":"PERP","order_type":"LIMIT","price":"60000","size":"0.25","status":"ACKNOWLEDGED"}}`)
+
+	got, err := DecodeOrder(raw)
+	if err != nil {
+		t.Fatalf("decode v4: %v", err)
+	}
+	if got.InstrumentType != InstrumentPerp || got.ReduceOnly {
+		t.Errorf("unexpected v4 order: %+v", got)
+	}
+}
+
+func TestOrderCodecDecodesV5(t *testing.T) {
+	raw := []byte(`{"schema":"order","version":5,"data":{"venue":"bybit","symbol":"BTCUSDT","instrument_type":"PERP","order_type":"LIMIT","reduce_only":true,"price":"60000","size":"0.25","status":"ACKNOWLEDGED"}}`)
+
+	got, err := DecodeOrder(raw)
+	if err != nil {
+		t.Fatalf("decode v5: %v", err)
+	}
+	if !got.ReduceOnly || got.Account != "" || got.Strategy != "" {
+		t.Errorf("unexpected v5 order: %+v", got)
+	}
+}
+
+func TestCodecEnvelopeErrors(t *testing.T) {
+	data, err := EncodeOrder(&Order{Venue: "kcex"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("parse envelope: %v", err)
+	}
+	if env.Schema != SchemaOrder || env.Version != OrderSchemaVersion {
+		t.Errorf("envelope header: got %s v%d, want %s v%d", env.Schema, env.Version, SchemaOrder, OrderSchemaVersion)
+	}
+
+	if _, err := DecodeTradeSignal(data); !errors.Is(err, ErrSchemaMismatch) {
+		t.Errorf("expected ErrSchemaMismatch, got %v", err)
+	}
+
+	env.Version = OrderSchemaVersion + 1
+	future, _ := json.Marshal(env)
+	if _, err := DecodeOrder(future); !errors.Is(err, ErrUnsupportedSchemaVersion) {
+		t.Errorf("expected ErrUnsupportedSchemaVersion, got %v", err)
+	}
+}
+
+func TestRiskStateCloneIsDeep(t *testing.T) {
+	key := VenueAssetKey{Venue: "kcex", Asset: "BTC"}
+	orig := &RiskState{
+		Positions:       map[VenueAssetKey]*Position{key: {Venue: "kcex", Asset: "BTC", Size: decimal.NewFromInt(1)}},
+		OpenOrderCounts: OrderCountState{Global: 1, PerVenue: map[string]int{"kcex": 1}, PerSymbol: map[string]int{"BTC/USDT": 1}},
+		VenueNotionals:  map[string]decimal.Decimal{"kcex": decimal.NewFromInt(60000)},
+	}
+
+	cp := orig.Clone()
+	orig.Positions[key].Size = decimal.NewFromInt(2)
+	orig.Positions[VenueAssetKey{Venue: "kcex", Asset: "ETH"}] = &Position{}
+	orig.OpenOrderCounts.PerVenue["kcex"] = 5
+	orig.OpenOrderCounts.PerSymbol["ETH/USDT"] = 1
+	orig.VenueNotionals["kcex"] = decimal.Zero
+
+	if !cp.Positions[key].Size.Equal(decimal.NewFromInt(1)) || len(cp.Positions) != 1 {
+		t.Errorf("positions shared with the original: %+v", cp.Positions)
+	}
+	if cp.OpenOrderCounts.PerVenue["kcex"] != 1 || len(cp.OpenOrderCounts.PerSymbol) != 1 {
+		t.Errorf("order counts shared with the original: %+v", cp.OpenOrderCounts)
+	}
+	if !cp.VenueNotionals["kcex"].Equal(decimal.NewFromInt(60000)) {
+		t.Errorf("notionals shared with the original: %+v", cp.VenueNotionals)
+	}
+}
@@ -1,6 +1,15 @@
 package domain
 
-import "github.com/shopspring/decimal"
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/bits"
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+)
 
 const PricePrecision = 1_000_000_000 // 9 decimal places (nano-units)
 
@@ -43,3 +52,225 @@ func (f FixedPrice) LTE(other FixedPrice) bool { return f <= other }
 func FixedFromBps(bps int64) FixedPrice {
 	return FixedPrice(bps * PricePrecision / 10000)
 }
+
+// DefaultPriceScale matches FixedPrice's nine decimal places.
+const DefaultPriceScale int32 = 9
+
+const maxScale int32 = 18
+
+var ErrFixedOverflow = errors.New("fixed-point overflow")
+
+var pow10 = [maxScale + 1]int64{
+	1, 10, 100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000,
+	1_000_000_000, 10_000_000_000, 100_000_000_000, 1_000_000_000_000,
+	10_000_000_000_000, 100_000_000_000_000, 1_000_000_000_000_000,
+	10_000_000_000_000_000, 100_000_000_000_000_000, 1_000_000_000_000_000_000,
+}
+
+// defaultPriceScales are the decimal places of symbols whose prices do not
+// suit DefaultPriceScale. Cross pairs quoted in BTC or ETH trade far below
+// one unit and need more fractional digits than USDT-quoted pairs. Iranian
+// fiat pairs quote whole rials or tomans in the billions, which only fit
+// without fractions.
+var defaultPriceScales = map[string]int32{
+	"ETH/BTC":  12,
+	"SOL/BTC":  12,
+	"SOL/ETH":  12,
+	"BTC/IRT":  0,
+	"ETH/IRT":  0,
+	"USDT/IRT": 0,
+	"BTC/TMN":  0,
+	"ETH/TMN":  0,
+	"USDT/TMN": 0,
+}
+
+// priceScales holds the defaults merged with the configured scales once
+// SetPriceScales has run.
+var priceScales atomic.Pointer[map[string]int32]
+
+// SetPriceScales sets the decimal places used for symbols' prices, on top of
+// the defaults. Call at startup, before prices are scaled.
+func SetPriceScales(scales map[string]int32) error {
+	merged := maps.Clone(defaultPriceScales)
+	for symbol, scale := range scales {
+		if scale < 0 || scale > maxScale {
+			return fmt.Errorf("invalid price scale %d for %s", scale, symbol)
+		}
+		merged[symbol] = scale
+	}
+	priceScales.Store(&merged)
+	return nil
+}
+
+// PriceScale returns the decimal places for symbol's prices, falling back
+// to DefaultPriceScale.
+func PriceScale(symbol string) int32 {
+	scales := defaultPriceScales
+	if configured := priceScales.Load(); configured != nil {
+		scales = *configured
+	}
+	if s, ok := scales[symbol]; ok {
+		return s
+	}
+	return DefaultPriceScale
+}
+
+// ScaledPrice is a fixed-point value that carries its own decimal exponent.
+// Multiplication and division go through a 128-bit intermediate and return
+// ErrFixedOverflow instead of wrapping.
+type ScaledPrice struct {
+	Units int64
+	Scale int32
+}
+
+func NewScaledPrice(d decimal.Decimal, scale int32) (ScaledPrice, error) {
+	if scale < 0 || scale > maxScale {
+		return ScaledPrice{}, fmt.Errorf("invalid scale %d", scale)
+	}
+	units := d.Shift(scale).Truncate(0)
+	if !units.BigInt().IsInt64() {
+		return ScaledPrice{}, ErrFixedOverflow
+	}
+	return ScaledPrice{Units: units.IntPart(), Scale: scale}, nil
+}
+
+func ScaledFromBps(bps int64, scale int32) (ScaledPrice, error) {
+	return NewScaledPrice(decimal.New(bps, -4), scale)
+}
+
+func (f FixedPrice) ToScaled() ScaledPrice {
+	return ScaledPrice{Units: int64(f), Scale: DefaultPriceScale}
+}
+
+func (p ScaledPrice) ToDecimal() decimal.Decimal {
+	return decimal.New(p.Units, -p.Scale)
+}
+
+func (p ScaledPrice) IsZero() bool { return p.Units == 0 }
+
+// Rescale converts p to the given scale, truncating extra digits when the
+// scale shrinks.
+func (p ScaledPrice) Rescale(scale int32) (ScaledPrice, error) {
+	if scale < 0 || scale > maxScale {
+		return ScaledPrice{}, fmt.Errorf("invalid scale %d", scale)
+	}
+	switch {
+	case scale == p.Scale:
+		return p, nil
+	case scale > p.Scale:
+		units, ok := mulDiv(p.Units, pow10[scale-p.Scale], 1)
+		if !ok {
+			return ScaledPrice{}, ErrFixedOverflow
+		}
+		return ScaledPrice{Units: units, Scale: scale}, nil
+	default:
+		return ScaledPrice{Units: p.Units / pow10[p.Scale-scale], Scale: scale}, nil
+	}
+}
+
+// Add returns p+other at the larger of the two scales.
+func (p ScaledPrice) Add(other ScaledPrice) (ScaledPrice, error) {
+	a, b, err := alignScales(p, other)
+	if err != nil {
+		return ScaledPrice{}, err
+	}
+	sum := a.Units + b.Units
+	if (b.Units > 0 && sum < a.Units) || (b.Units < 0 && sum > a.Units) {
+		return ScaledPrice{}, ErrFixedOverflow
+	}
+	return ScaledPrice{Units: sum, Scale: a.Scale}, nil
+}
+
+// Sub returns p-other at the larger of the two scales.
+func (p ScaledPrice) Sub(other ScaledPrice) (ScaledPrice, error) {
+	if other.Units == math.MinInt64 {
+		return ScaledPrice{}, ErrFixedOverflow
+	}
+	return p.Add(ScaledPrice{Units: -other.Units, Scale: other.Scale})
+}
+
+// Mul returns p*other at p's scale.
+func (p ScaledPrice) Mul(other ScaledPrice) (ScaledPrice, error) {
+	units, ok := mulDiv(p.Units, other.Units, pow10[other.Scale])
+	if !ok {
+		return ScaledPrice{}, ErrFixedOverflow
+	}
+	return ScaledPrice{Units: units, Scale: p.Scale}, nil
+}
+
+// Div returns p/other at p's scale.
+func (p ScaledPrice) Div(other ScaledPrice) (ScaledPrice, error) {
+	if other.Units == 0 {
+		return ScaledPrice{}, errors.New("fixed-point division by zero")
+	}
+	units, ok := mulDiv(p.Units, pow10[other.Scale], other.Units)
+	if !ok {
+		return ScaledPrice{}, ErrFixedOverflow
+	}
+	return ScaledPrice{Units: units, Scale: p.Scale}, nil
+}
+
+// Cmp returns -1, 0 or +1 comparing p with other regardless of scale.
+func (p ScaledPrice) Cmp(other ScaledPrice) int {
+	a, b, err := alignScales(p, other)
+	if err != nil {
+		return p.ToDecimal().Cmp(other.ToDecimal())
+	}
+	switch {
+	case a.Units < b.Units:
+		return -1
+	case a.Units > b.Units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (p ScaledPrice) GT(other ScaledPrice) bool  { return p.Cmp(other) > 0 }
+func (p ScaledPrice) GTE(other ScaledPrice) bool { return p.Cmp(other) >= 0 }
+func (p ScaledPrice) LT(other ScaledPrice) bool  { return p.Cmp(other) < 0 }
+func (p ScaledPrice) LTE(other ScaledPrice) bool { return p.Cmp(other) <= 0 }
+
+func alignScales(a, b ScaledPrice) (ScaledPrice, ScaledPrice, error) {
+	if a.Scale == b.Scale {
+		return a, b, nil
+	}
+	if a.Scale < b.Scale {
+		ra, err := a.Rescale(b.Scale)
+		return ra, b, err
+	}
+	rb, err := b.Rescale(a.Scale)
+	return a, rb, err
+}
+
+// mulDiv computes a*b/c with a 128-bit intermediate product, truncating
+// toward zero. It reports false if the quotient does not fit in an int64.
+func mulDiv(a, b, c int64) (int64, bool) {
+	if c == 0 {
+		return 0, false
+	}
+	neg := (a < 0) != (b < 0) != (c < 0)
+	hi, lo := bits.Mul64(absU64(a), absU64(b))
+	uc := absU64(c)
+	if hi >= uc {
+		return 0, false
+	}
+	q, _ := bits.Div64(hi, lo, uc)
+	if neg {
+		if q > 1<<63 {
+			return 0, false
+		}
+		return int64(-q), true
+	}
+	if q > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(q), true
+}
+
+func absU64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
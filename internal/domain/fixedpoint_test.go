@@ -76,3 +76,101 @@ func TestFixedFromBps(t *testing.T) {
 		t.Errorf("FixedFromBps(18) = %d, want ~%d", bps18, expected)
 	}
 }
+
+func TestScaledPriceMulOverflowDetected(t *testing.T) {
+	notional, err := NewScaledPrice(decimal.NewFromInt(5_000_000), DefaultPriceScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := notional.Mul(notional); err != ErrFixedOverflow {
+		t.Errorf("expected ErrFixedOverflow, got %v", err)
+	}
+}
+
+func TestScaledPriceMulUses128BitIntermediate(t *testing.T) {
+	// 50000 * 2 at 9 decimals: the raw product of units is 1e23, which would
+	// wrap FixedPrice.Mul, but the result itself fits comfortably.
+	a, _ := NewScaledPrice(decimal.NewFromInt(50000), DefaultPriceScale)
+	b, _ := NewScaledPrice(decimal.NewFromInt(2), DefaultPriceScale)
+
+	got, err := a.Mul(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.ToDecimal().Equal(decimal.NewFromInt(100000)) {
+		t.Errorf("Mul: got %s, want 100000", got.ToDecimal())
+	}
+}
+
+func TestScaledPriceSmallPricePrecision(t *testing.T) {
+	price := decimal.RequireFromString("0.000000001234")
+
+	nine, _ := NewScaledPrice(price, DefaultPriceScale)
+	if !nine.ToDecimal().Equal(decimal.RequireFromString("0.000000001")) {
+		t.Errorf("expected 9-decimal truncation, got %s", nine.ToDecimal())
+	}
+
+	twelve, _ := NewScaledPrice(price, 12)
+	if !twelve.ToDecimal().Equal(price) {
+		t.Errorf("expected exact value at scale 12, got %s", twelve.ToDecimal())
+	}
+}
+
+func TestScaledPriceDivAndCompareAcrossScales(t *testing.T) {
+	one, _ := NewScaledPrice(decimal.NewFromInt(1), 12)
+	btc, _ := NewScaledPrice(decimal.NewFromInt(50000), PriceScale("BTC/USDT"))
+
+	rate, err := one.Div(btc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rate.ToDecimal().Equal(decimal.RequireFromString("0.00002")) {
+		t.Errorf("Div: got %s, want 0.00002", rate.ToDecimal())
+	}
+
+	if _, err := one.Div(ScaledPrice{Scale: 9}); err == nil {
+		t.Error("expected division by zero error")
+	}
+
+	if !btc.GT(one) || !one.LT(btc) {
+		t.Error("expected 50000 (scale 9) > 1 (scale 12)")
+	}
+
+	sum, err := one.Add(btc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.Scale != 12 || !sum.ToDecimal().Equal(decimal.NewFromInt(50001)) {
+		t.Errorf("Add: got %s at scale %d", sum.ToDecimal(), sum.Scale)
+	}
+}
+
+func TestPriceScaleDefaults(t *testing.T) {
+	if got := PriceScale("BTC/USDT"); got != DefaultPriceScale {
+		t.Errorf("PriceScale(BTC/USDT) = %d, want %d", got, DefaultPriceScale)
+	}
+	if got := PriceScale("SOL/BTC"); got != 12 {
+		t.Errorf("PriceScale(SOL/BTC) = %d, want 12", got)
+	}
+}
+
+func TestSetPriceScales(t *testing.T) {
+	t.Cleanup(func() { priceScales.Store(nil) })
+
+	if err := SetPriceScales(map[string]int32{"PEPE/USDT": 14, "ETH/BTC": 10}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := PriceScale("PEPE/USDT"); got != 14 {
+		t.Errorf("PriceScale(PEPE/USDT) = %d, want 14", got)
+	}
+	if got := PriceScale("ETH/BTC"); got != 10 {
+		t.Errorf("configured scale should override the default, got %d", got)
+	}
+	if got := PriceScale("BTC/IRT"); got != 0 {
+		t.Errorf("defaults should survive configuring others, got %d", got)
+	}
+	if err := SetPriceScales(map[string]int32{"X/USDT": maxScale + 1}); err == nil {
+		t.Error("expected a scale beyond the fixed-point range to be refused")
+	}
+}
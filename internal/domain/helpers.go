@@ -2,6 +2,7 @@ package domain
 
 import (
 	"strings"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -13,6 +14,13 @@ func ParseDecimal(s string) (decimal.Decimal, error) {
 	return decimal.NewFromString(s)
 }
 
+// TradingDayStart returns midnight of t's calendar day in loc. Daily PnL
+// windows and loss caps reset at this boundary.
+func TradingDayStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
 // ExtractAsset returns the base asset from a trading symbol.
 // For "BTC/USDT" it returns "BTC"; for "BTCUSDT" it returns "BTC".
 func ExtractAsset(symbol string) string {
@@ -68,6 +76,52 @@ var KCEXFuturesSymbolMap = map[string]string{
 	"SOLUSDT": "SOLUSDTM",
 }
 
+// BinanceSpotSymbolMap maps internal symbols to Binance spot symbols (concatenated).
+var BinanceSpotSymbolMap = map[string]string{
+	"BTC/USDT": "BTCUSDT",
+	"ETH/USDT": "ETHUSDT",
+	"SOL/USDT": "SOLUSDT",
+	"ETH/BTC":  "ETHBTC",
+	"SOL/BTC":  "SOLBTC",
+}
+
+// BinanceFuturesSymbolMap maps internal perp symbols to Binance USD-M futures symbols.
+var BinanceFuturesSymbolMap = map[string]string{
+	"BTCUSDT": "BTCUSDT",
+	"ETHUSDT": "ETHUSDT",
+	"SOLUSDT": "SOLUSDT",
+}
+
+// BybitSpotSymbolMap maps internal symbols to Bybit spot symbols (concatenated).
+var BybitSpotSymbolMap = map[string]string{
+	"BTC/USDT": "BTCUSDT",
+	"ETH/USDT": "ETHUSDT",
+	"SOL/USDT": "SOLUSDT",
+	"ETH/BTC":  "ETHBTC",
+}
+
+// BybitFuturesSymbolMap maps internal perp symbols to Bybit linear perpetual symbols.
+var BybitFuturesSymbolMap = map[string]string{
+	"BTCUSDT": "BTCUSDT",
+	"ETHUSDT": "ETHUSDT",
+	"SOLUSDT": "SOLUSDT",
+}
+
+// OKXSpotSymbolMap maps internal symbols to OKX spot instrument IDs (dash-separated).
+var OKXSpotSymbolMap = map[string]string{
+	"BTC/USDT": "BTC-USDT",
+	"ETH/USDT": "ETH-USDT",
+	"SOL/USDT": "SOL-USDT",
+	"ETH/BTC":  "ETH-BTC",
+}
+
+// OKXSwapSymbolMap maps internal perp symbols to OKX perpetual swap instrument IDs.
+var OKXSwapSymbolMap = map[string]string{
+	"BTCUSDT": "BTC-USDT-SWAP",
+	"ETHUSDT": "ETH-USDT-SWAP",
+	"SOLUSDT": "SOL-USDT-SWAP",
+}
+
 // WallexSymbolMap maps internal symbols to Wallex API symbols.
 // Wallex uses concatenated uppercase symbols (e.g., BTCUSDT, BTCTMN).
 var WallexSymbolMap = map[string]string{
@@ -95,13 +149,19 @@ func MapSymbol(internal string, mapping map[string]string) string {
 }
 
 // MapNobitexCurrencyPair returns the srcCurrency/dstCurrency pair for Nobitex orders.
+// Pairs not in NobitexCurrencyPairMap, such as ones added through config, are
+// split at the slash; Nobitex calls IRT "rls".
 func MapNobitexCurrencyPair(internal string) (src, dst string) {
 	if p, ok := NobitexCurrencyPairMap[internal]; ok {
 		return p.Src, p.Dst
 	}
 	parts := strings.SplitN(internal, "/", 2)
 	if len(parts) == 2 {
-		return strings.ToLower(parts[0]), strings.ToLower(parts[1])
+		dst = strings.ToLower(parts[1])
+		if dst == "irt" {
+			dst = "rls"
+		}
+		return strings.ToLower(parts[0]), dst
 	}
 	return strings.ToLower(internal), "usdt"
 }
@@ -133,3 +193,57 @@ func MapKCEXSymbol(internal string) string {
 	}
 	return internal
 }
+
+// IsBinanceFutures returns true if the internal symbol is a Binance USD-M perp symbol.
+func IsBinanceFutures(internal string) bool {
+	_, ok := BinanceFuturesSymbolMap[internal]
+	return ok
+}
+
+// MapBinanceSymbol maps an internal symbol to the Binance symbol for either
+// the spot or the USD-M futures market.
+func MapBinanceSymbol(internal string) string {
+	if v, ok := BinanceFuturesSymbolMap[internal]; ok {
+		return v
+	}
+	if v, ok := BinanceSpotSymbolMap[internal]; ok {
+		return v
+	}
+	return internal
+}
+
+// IsBybitFutures returns true if the internal symbol is a Bybit linear perp symbol.
+func IsBybitFutures(internal string) bool {
+	_, ok := BybitFuturesSymbolMap[internal]
+	return ok
+}
+
+// MapBybitSymbol maps an internal symbol to the Bybit symbol for either
+// the spot or the linear category.
+func MapBybitSymbol(internal string) string {
+	if v, ok := BybitFuturesSymbolMap[internal]; ok {
+		return v
+	}
+	if v, ok := BybitSpotSymbolMap[internal]; ok {
+		return v
+	}
+	return internal
+}
+
+// IsOKXSwap returns true if the internal symbol is an OKX perpetual swap symbol.
+func IsOKXSwap(internal string) bool {
+	_, ok := OKXSwapSymbolMap[internal]
+	return ok
+}
+
+// MapOKXSymbol maps an internal symbol to the OKX instrument ID,
+// automatically detecting whether it's spot or swap.
+func MapOKXSymbol(internal string) string {
+	if v, ok := OKXSwapSymbolMap[internal]; ok {
+		return v
+	}
+	if v, ok := OKXSpotSymbolMap[internal]; ok {
+		return v
+	}
+	return internal
+}
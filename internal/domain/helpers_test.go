@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestMapNobitexCurrencyPair(t *testing.T) {
 	tests := []struct {
@@ -13,6 +16,7 @@ func TestMapNobitexCurrencyPair(t *testing.T) {
 		{"SOL/USDT", "sol", "usdt"},
 		{"BTC/IRT", "btc", "rls"},
 		{"USDT/IRT", "usdt", "rls"},
+		{"DOGE/IRT", "doge", "rls"},
 		{"UNKNOWN/PAIR", "unknown", "pair"},
 	}
 
@@ -78,3 +82,99 @@ func TestNobitexOrderBookSymbolMap(t *testing.T) {
 		}
 	}
 }
+
+func TestMapBinanceSymbol(t *testing.T) {
+	tests := []struct {
+		internal string
+		want     string
+	}{
+		{"BTC/USDT", "BTCUSDT"},
+		{"ETH/BTC", "ETHBTC"},
+		{"BTCUSDT", "BTCUSDT"},
+		{"UNKNOWN", "UNKNOWN"},
+	}
+
+	for _, tt := range tests {
+		got := MapBinanceSymbol(tt.internal)
+		if got != tt.want {
+			t.Errorf("MapBinanceSymbol(%q) = %q, want %q", tt.internal, got, tt.want)
+		}
+	}
+
+	if !IsBinanceFutures("ETHUSDT") {
+		t.Error("expected ETHUSDT to be detected as futures")
+	}
+	if IsBinanceFutures("ETH/USDT") {
+		t.Error("expected ETH/USDT to NOT be detected as futures")
+	}
+}
+
+func TestMapBybitSymbol(t *testing.T) {
+	tests := []struct {
+		internal string
+		want     string
+	}{
+		{"BTC/USDT", "BTCUSDT"},
+		{"ETH/BTC", "ETHBTC"},
+		{"SOLUSDT", "SOLUSDT"},
+		{"UNKNOWN", "UNKNOWN"},
+	}
+
+	for _, tt := range tests {
+		got := MapBybitSymbol(tt.internal)
+		if got != tt.want {
+			t.Errorf("MapBybitSymbol(%q) = %q, want %q", tt.internal, got, tt.want)
+		}
+	}
+
+	if !IsBybitFutures("BTCUSDT") {
+		t.Error("expected BTCUSDT to be detected as futures")
+	}
+	if IsBybitFutures("BTC/USDT") {
+		t.Error("expected BTC/USDT to NOT be detected as futures")
+	}
+}
+
+func TestMapOKXSymbol(t *testing.T) {
+	tests := []struct {
+		internal string
+		want     string
+	}{
+		{"BTC/USDT", "BTC-USDT"},
+		{"ETH/BTC", "ETH-BTC"},
+		{"BTCUSDT", "BTC-USDT-SWAP"},
+		{"UNKNOWN", "UNKNOWN"},
+	}
+
+	for _, tt := range tests {
+		got := MapOKXSymbol(tt.internal)
+		if got != tt.want {
+			t.Errorf("MapOKXSymbol(%q) = %q, want %q", tt.internal, got, tt.want)
+		}
+	}
+
+	if !IsOKXSwap("SOLUSDT") {
+		t.Error("expected SOLUSDT to be detected as swap")
+	}
+	if IsOKXSwap("SOL/USDT") {
+		t.Error("expected SOL/USDT to NOT be detected as swap")
+	}
+}
+
+func TestTradingDayStart(t *testing.T) {
+	tehran, err := time.LoadLocation("Asia/Tehran")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	// 22:00 UTC on March 1 is already March 2 in Tehran (UTC+3:30).
+	ts := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+
+	if got := TradingDayStart(ts, time.UTC); !got.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("UTC day start: got %s", got)
+	}
+	want := time.Date(2024, 3, 2, 0, 0, 0, 0, tehran)
+	if got := TradingDayStart(ts, tehran); !got.Equal(want) {
+		t.Errorf("Tehran day start: got %s, want %s", got, want)
+	}
+}
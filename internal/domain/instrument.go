@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrBelowMinSize is returned when an order's size, once rounded down to the
+// venue's step size, is under the instrument's minimum.
+var ErrBelowMinSize = errors.New("order size below venue minimum")
+
+// ErrBelowMinNotional is returned when an order's price times size is under
+// the instrument's minimum notional.
+var ErrBelowMinNotional = errors.New("order notional below venue minimum")
+
+// Instrument is a venue's trading rules for one symbol. Sizes are in the
+// units OrderRequest.Size uses for the symbol: base units, except on venues
+// whose adapter sizes derivatives in contracts. A zero increment or minimum
+// means the venue does not restrict it.
+type Instrument struct {
+	Venue          string
+	Symbol         string // internal symbol
+	InstrumentType InstrumentType
+	TickSize       decimal.Decimal // price increment
+	StepSize       decimal.Decimal // size increment
+	MinSize        decimal.Decimal
+	MinNotional    decimal.Decimal // in the quote asset
+	// ContractMultiplier is the base quantity of one contract; 1 for spot
+	// and for derivatives sized in base units.
+	ContractMultiplier decimal.Decimal
+	// Suspended is set while the venue does not trade the instrument, for
+	// example during a halt or ahead of a delisting.
+	Suspended bool
+}
+
+// RoundPrice rounds price to the tick size towards the passive side: buys
+// down, sells up, so rounding never makes a limit price worse.
+func (i Instrument) RoundPrice(price decimal.Decimal, side Side) decimal.Decimal {
+	if !i.TickSize.IsPositive() || price.IsZero() {
+		return price
+	}
+	ticks := price.Div(i.TickSize)
+	if side == SideSell {
+		ticks = ticks.Ceil()
+	} else {
+		ticks = ticks.Floor()
+	}
+	return ticks.Mul(i.TickSize)
+}
+
+// RoundSize rounds size down to the step size, so an order never exceeds
+// what was asked for.
+func (i Instrument) RoundSize(size decimal.Decimal) decimal.Decimal {
+	if !i.StepSize.IsPositive() {
+		return size
+	}
+	return size.Div(i.StepSize).Floor().Mul(i.StepSize)
+}
+
+// Conform rounds req's prices and size to the instrument's increments and
+// checks the result against its minimums. Market orders, which carry no
+// price, are checked on size only.
+func (i Instrument) Conform(req OrderRequest) (OrderRequest, error) {
+	req.Price = i.RoundPrice(req.Price, req.Side)
+	req.StopPrice = i.RoundPrice(req.StopPrice, req.Side)
+	req.Size = i.RoundSize(req.Size)
+
+	if !req.Size.IsPositive() || req.Size.LessThan(i.MinSize) {
+		return req, fmt.Errorf("%w: %s %s size %s, min %s step %s",
+			ErrBelowMinSize, i.Venue, i.Symbol, req.Size, i.MinSize, i.StepSize)
+	}
+	if i.MinNotional.IsPositive() && req.Price.IsPositive() {
+		if notional := req.Price.Mul(req.Size); notional.LessThan(i.MinNotional) {
+			return req, fmt.Errorf("%w: %s %s notional %s, min %s",
+				ErrBelowMinNotional, i.Venue, i.Symbol, notional, i.MinNotional)
+		}
+	}
+	return req, nil
+}
+
+// InstrumentRegistry caches the instruments each venue reported, keyed by
+// venue and internal symbol. It is safe for concurrent use.
+type InstrumentRegistry struct {
+	mu      sync.RWMutex
+	venues  map[string]map[string]Instrument
+	updated map[string]time.Time
+}
+
+func NewInstrumentRegistry() *InstrumentRegistry {
+	return &InstrumentRegistry{
+		venues:  make(map[string]map[string]Instrument),
+		updated: make(map[string]time.Time),
+	}
+}
+
+// Set replaces everything known about venue with instruments.
+func (r *InstrumentRegistry) Set(venue string, instruments []Instrument) {
+	bySymbol := make(map[string]Instrument, len(instruments))
+	for _, inst := range instruments {
+		bySymbol[inst.Symbol] = inst
+	}
+	r.mu.Lock()
+	r.venues[venue] = bySymbol
+	r.updated[venue] = time.Now()
+	r.mu.Unlock()
+}
+
+// Unavailable returns the symbols a refreshed instrument list for venue
+// says can no longer be traded, in sorted order: those marked suspended and
+// those the registry knows that the list no longer has. Call it before Set.
+func (r *InstrumentRegistry) Unavailable(venue string, instruments []Instrument) []string {
+	listed := make(map[string]bool, len(instruments))
+	var out []string
+	for _, inst := range instruments {
+		listed[inst.Symbol] = true
+		if inst.Suspended {
+			out = append(out, inst.Symbol)
+		}
+	}
+	r.mu.RLock()
+	for symbol := range r.venues[venue] {
+		if !listed[symbol] {
+			out = append(out, symbol)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Strings(out)
+	return out
+}
+
+// Get returns the instrument for symbol on venue.
+func (r *InstrumentRegistry) Get(venue, symbol string) (Instrument, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	inst, ok := r.venues[venue][symbol]
+	return inst, ok
+}
+
+// Venue returns every instrument known for venue and when they were last
+// refreshed.
+func (r *InstrumentRegistry) Venue(venue string) ([]Instrument, time.Time) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Instrument, 0, len(r.venues[venue]))
+	for _, inst := range r.venues[venue] {
+		out = append(out, inst)
+	}
+	return out, r.updated[venue]
+}
+
+// Conform rounds req to its instrument's rules. Requests for instruments the
+// registry does not know are returned unchanged.
+func (r *InstrumentRegistry) Conform(req OrderRequest) (OrderRequest, error) {
+	inst, ok := r.Get(req.Venue, req.Symbol)
+	if !ok {
+		return req, nil
+	}
+	return inst.Conform(req)
+}
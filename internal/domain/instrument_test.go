@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func TestInstrumentConform(t *testing.T) {
+	inst := Instrument{
+		Venue:       "binance",
+		Symbol:      "BTC/USDT",
+		TickSize:    d("0.01"),
+		StepSize:    d("0.00001"),
+		MinSize:     d("0.0001"),
+		MinNotional: d("5"),
+	}
+
+	tests := []struct {
+		name      string
+		side      Side
+		price     string
+		size      string
+		wantPrice string
+		wantSize  string
+		wantErr   error
+	}{
+		{"buy rounds price down", SideBuy, "60000.129", "0.123456", "60000.12", "0.12345", nil},
+		{"sell rounds price up", SideSell, "60000.121", "0.123456", "60000.13", "0.12345", nil},
+		{"on-tick price unchanged", SideSell, "60000.12", "0.1", "60000.12", "0.1", nil},
+		{"size below minimum", SideBuy, "60000", "0.000099", "60000", "0.00009", ErrBelowMinSize},
+		{"notional below minimum", SideBuy, "10", "0.4", "10", "0.4", ErrBelowMinNotional},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := OrderRequest{Venue: "binance", Symbol: "BTC/USDT", Side: tt.side, Price: d(tt.price), Size: d(tt.size)}
+			got, err := inst.Conform(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !got.Price.Equal(d(tt.wantPrice)) || !got.Size.Equal(d(tt.wantSize)) {
+				t.Errorf("got price %s size %s, want %s %s", got.Price, got.Size, tt.wantPrice, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestInstrumentRegistryConform(t *testing.T) {
+	reg := NewInstrumentRegistry()
+	reg.Set("okx", []Instrument{{Venue: "okx", Symbol: "BTCUSDT", StepSize: d("0.01"), ContractMultiplier: d("0.01")}})
+
+	got, err := reg.Conform(OrderRequest{Venue: "okx", Symbol: "BTCUSDT", Size: d("0.057")})
+	if err != nil || !got.Size.Equal(d("0.05")) {
+		t.Errorf("expected size rounded to 0.05, got %s (%v)", got.Size, err)
+	}
+
+	// Unknown instruments pass through untouched.
+	got, err = reg.Conform(OrderRequest{Venue: "kcex", Symbol: "BTC/USDT", Size: d("0.057")})
+	if err != nil || !got.Size.Equal(d("0.057")) {
+		t.Errorf("expected an unknown instrument left as is, got %s (%v)", got.Size, err)
+	}
+
+	reg.Set("okx", nil)
+	if _, ok := reg.Get("okx", "BTCUSDT"); ok {
+		t.Error("expected Set to replace the venue's instruments")
+	}
+}
+
+func TestInstrumentRegistryUnavailable(t *testing.T) {
+	reg := NewInstrumentRegistry()
+	if got := reg.Unavailable("okx", []Instrument{{Symbol: "BTCUSDT"}, {Symbol: "ETH/BTC", Suspended: true}}); len(got) != 1 || got[0] != "ETH/BTC" {
+		t.Errorf("expected a suspended symbol reported on first load, got %v", got)
+	}
+
+	reg.Set("okx", []Instrument{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}, {Symbol: "SOLUSDT"}})
+	got := reg.Unavailable("okx", []Instrument{{Symbol: "SOLUSDT", Suspended: true}, {Symbol: "BTCUSDT"}})
+	if len(got) != 2 || got[0] != "ETHUSDT" || got[1] != "SOLUSDT" {
+		t.Errorf("expected the delisted ETHUSDT and suspended SOLUSDT, got %v", got)
+	}
+}
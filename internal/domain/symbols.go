@@ -0,0 +1,52 @@
+package domain
+
+import "fmt"
+
+// venueSymbolMaps indexes the native venues' symbol maps by instrument type.
+// They are the same maps the adapters read, so a mapping registered here is
+// what the adapter sends.
+var venueSymbolMaps = map[string]map[InstrumentType]map[string]string{
+	"nobitex": {InstrumentSpot: NobitexOrderBookSymbolMap},
+	"wallex":  {InstrumentSpot: WallexSymbolMap},
+	"kcex":    {InstrumentSpot: KCEXSpotSymbolMap, InstrumentPerp: KCEXFuturesSymbolMap},
+	"binance": {InstrumentSpot: BinanceSpotSymbolMap, InstrumentPerp: BinanceFuturesSymbolMap},
+	"bybit":   {InstrumentSpot: BybitSpotSymbolMap, InstrumentPerp: BybitFuturesSymbolMap},
+	"okx":     {InstrumentSpot: OKXSpotSymbolMap, InstrumentPerp: OKXSwapSymbolMap},
+}
+
+// RegisterSymbol maps the internal symbol to venueSymbol for instruments of
+// type t on venue, adding to or overriding the built-in mapping. Another
+// internal symbol mapped to the same venue symbol is dropped, so venue
+// symbols still map back to one internal symbol. Adapters read the maps
+// without locking: register every mapping at startup, before gateways are
+// built.
+func RegisterSymbol(venue string, t InstrumentType, internal, venueSymbol string) error {
+	maps, ok := venueSymbolMaps[venue]
+	if !ok {
+		return fmt.Errorf("venue %s has no symbol map", venue)
+	}
+	m, ok := maps[t]
+	if !ok {
+		return fmt.Errorf("venue %s has no %s instruments", venue, t)
+	}
+	for k, v := range m {
+		if v == venueSymbol && k != internal {
+			delete(m, k)
+		}
+	}
+	m[internal] = venueSymbol
+	return nil
+}
+
+// ResolveSymbol returns the venue symbol for an internal symbol of type t.
+// It reports false if the venue has a symbol map that does not cover it.
+// Venues without one (FIX counterparties and gRPC plugins) take internal
+// symbols as they are.
+func ResolveSymbol(venue string, t InstrumentType, internal string) (string, bool) {
+	maps, ok := venueSymbolMaps[venue]
+	if !ok {
+		return internal, true
+	}
+	v, ok := maps[t][internal]
+	return v, ok
+}
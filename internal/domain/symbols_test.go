@@ -0,0 +1,54 @@
+package domain
+
+import "testing"
+
+func TestRegisterSymbol(t *testing.T) {
+	saved := KCEXSpotSymbolMap["BTC/USDT"]
+	defer func() {
+		delete(KCEXSpotSymbolMap, "XBT/USDT")
+		KCEXSpotSymbolMap["BTC/USDT"] = saved
+	}()
+
+	if err := RegisterSymbol("kcex", InstrumentSpot, "XBT/USDT", "BTC-USDT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := MapKCEXSymbol("XBT/USDT"); got != "BTC-USDT" {
+		t.Errorf("expected the adapter map to pick up the mapping, got %q", got)
+	}
+	if _, ok := ResolveSymbol("kcex", InstrumentSpot, "BTC/USDT"); ok {
+		t.Error("expected the old internal symbol for BTC-USDT to be dropped")
+	}
+	if got := ReverseMapSymbol("BTC-USDT", KCEXSpotSymbolMap); got != "XBT/USDT" {
+		t.Errorf("expected BTC-USDT to map back to XBT/USDT, got %q", got)
+	}
+
+	if err := RegisterSymbol("nobitex", InstrumentPerp, "BTCUSDT", "BTCUSDT"); err == nil {
+		t.Error("expected an error for a venue without perps")
+	}
+	if err := RegisterSymbol("primebroker", InstrumentSpot, "BTC/USDT", "XBTUSD"); err == nil {
+		t.Error("expected an error for a venue without a symbol map")
+	}
+}
+
+func TestResolveSymbol(t *testing.T) {
+	tests := []struct {
+		venue    string
+		instType InstrumentType
+		internal string
+		want     string
+		wantOK   bool
+	}{
+		{"okx", InstrumentPerp, "BTCUSDT", "BTC-USDT-SWAP", true},
+		{"okx", InstrumentSpot, "BTCUSDT", "", false},
+		{"wallex", InstrumentSpot, "USDT/TMN", "USDTTMN", true},
+		{"nobitex", InstrumentSpot, "DOGE/IRT", "", false},
+		{"primebroker", InstrumentPerp, "BTCUSDT", "BTCUSDT", true},
+	}
+	for _, tt := range tests {
+		got, ok := ResolveSymbol(tt.venue, tt.instType, tt.internal)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ResolveSymbol(%s, %s, %s) = (%q, %v), want (%q, %v)",
+				tt.venue, tt.instType, tt.internal, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
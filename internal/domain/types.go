@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,8 +25,27 @@ const (
 type OrderType string
 
 const (
-	OrderTypeLimit  OrderType = "LIMIT"
-	OrderTypeMarket OrderType = "MARKET"
+	OrderTypeLimit      OrderType = "LIMIT"
+	OrderTypeMarket     OrderType = "MARKET"
+	OrderTypeStopMarket OrderType = "STOP_MARKET"
+	OrderTypeStopLimit  OrderType = "STOP_LIMIT"
+)
+
+// IsStop reports whether the order waits for its StopPrice to trade before
+// it becomes a market (stop-market) or limit (stop-limit) order. Sell stops
+// trigger when the price falls to StopPrice, buy stops when it rises to it.
+func (t OrderType) IsStop() bool {
+	return t == OrderTypeStopMarket || t == OrderTypeStopLimit
+}
+
+// TimeInForce controls how long an unfilled limit order stays on the book.
+// The zero value leaves it to the venue default, which is GTC.
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+	TimeInForceFOK TimeInForce = "FOK"
 )
 
 type OrderStatus string
@@ -49,8 +69,9 @@ func (s OrderStatus) IsTerminal() bool {
 type StrategyType string
 
 const (
-	StrategyTriArb   StrategyType = "TRI_ARB"
-	StrategyBasisArb StrategyType = "BASIS_ARB"
+	StrategyTriArb        StrategyType = "TRI_ARB"
+	StrategyBasisArb      StrategyType = "BASIS_ARB"
+	StrategyCrossVenueArb StrategyType = "CROSS_VENUE_ARB"
 )
 
 type RiskMode string
@@ -69,6 +90,7 @@ const (
 	TradingModeLive    TradingMode = "live"
 	TradingModeDryRun  TradingMode = "dry_run"
 	TradingModeBacktest TradingMode = "backtest"
+	TradingModeReplay  TradingMode = "replay"
 )
 
 type EndpointCategory string
@@ -81,6 +103,45 @@ const (
 	EndpointAccount     EndpointCategory = "account"
 )
 
+// RateLimitStatus is how much of a venue's request budget is left for one
+// endpoint category, as tracked by the gateway from its own usage and the
+// quota the venue reports back.
+type RateLimitStatus struct {
+	Category     EndpointCategory
+	Remaining    float64
+	Capacity     float64
+	BlockedUntil time.Time // set while the venue has paused the category
+}
+
+// Available returns the requests that can be sent at now: zero while the
+// category is blocked, Remaining otherwise.
+func (s RateLimitStatus) Available(now time.Time) float64 {
+	if now.Before(s.BlockedUntil) {
+		return 0
+	}
+	return s.Remaining
+}
+
+// VenueHealth is a point-in-time view of a venue's connections, as reported
+// by its gateway.
+type VenueHealth struct {
+	Venue         string    `json:"venue"`
+	WSConnected   bool      `json:"ws_connected"`
+	LastMessageAt time.Time `json:"last_message_at"` // zero until the first message arrives
+	RESTReachable bool      `json:"rest_reachable"`
+	RESTError     string    `json:"rest_error,omitempty"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// MessageAge returns how long before now the last WebSocket message arrived,
+// or zero if none has.
+func (h VenueHealth) MessageAge(now time.Time) time.Duration {
+	if h.LastMessageAt.IsZero() {
+		return 0
+	}
+	return now.Sub(h.LastMessageAt)
+}
+
 type PriceLevel struct {
 	Price decimal.Decimal
 	Size  decimal.Decimal
@@ -119,12 +180,101 @@ func (ob *OrderBookSnapshot) MidPrice() (decimal.Decimal, bool) {
 	return bid.Price.Add(ask.Price).Div(decimal.NewFromInt(2)), true
 }
 
+// levelsFor returns the levels an order on side trades against: the asks
+// for a buy, the bids for a sell.
+func (ob *OrderBookSnapshot) levelsFor(side Side) []PriceLevel {
+	if side == SideBuy {
+		return ob.Asks
+	}
+	return ob.Bids
+}
+
+// VWAPForSize walks the book the way a taking order of size on side would
+// and returns its volume-weighted average price and the size the book can
+// fill. filled is less than size when the book is too thin, and vwap is zero
+// when nothing can be filled.
+func (ob *OrderBookSnapshot) VWAPForSize(side Side, size decimal.Decimal) (vwap, filled decimal.Decimal) {
+	cost := decimal.Zero
+	for _, level := range ob.levelsFor(side) {
+		remaining := size.Sub(filled)
+		if !remaining.IsPositive() {
+			break
+		}
+		take := decimal.Min(remaining, level.Size)
+		cost = cost.Add(take.Mul(level.Price))
+		filled = filled.Add(take)
+	}
+	if filled.IsZero() {
+		return decimal.Zero, decimal.Zero
+	}
+	return cost.Div(filled), filled
+}
+
+// DepthWithinBps returns the size resting on each side within bps of that
+// side's best price: bids down to best bid × (1 − bps/10000), asks up to
+// best ask × (1 + bps/10000). It is what a taking order can fill without
+// slipping more than bps from the touch.
+func (ob *OrderBookSnapshot) DepthWithinBps(bps int) (bidSize, askSize decimal.Decimal) {
+	offset := decimal.NewFromInt(int64(bps)).Div(decimal.NewFromInt(10000))
+	if best, ok := ob.BestBid(); ok {
+		limit := best.Price.Mul(decimal.NewFromInt(1).Sub(offset))
+		for _, level := range ob.Bids {
+			if level.Price.LessThan(limit) {
+				break
+			}
+			bidSize = bidSize.Add(level.Size)
+		}
+	}
+	if best, ok := ob.BestAsk(); ok {
+		limit := best.Price.Mul(decimal.NewFromInt(1).Add(offset))
+		for _, level := range ob.Asks {
+			if level.Price.GreaterThan(limit) {
+				break
+			}
+			askSize = askSize.Add(level.Size)
+		}
+	}
+	return bidSize, askSize
+}
+
+// VenueQuote is one venue's best bid and offer for a symbol. A side the
+// venue's book is empty on has a zero price and size.
+type VenueQuote struct {
+	Venue     string
+	Bid       PriceLevel
+	Ask       PriceLevel
+	Timestamp time.Time
+}
+
+// ConsolidatedQuote is a symbol's best bid and offer on each venue and across
+// all of them, the NBBO. Venues is sorted by venue name. BidVenue and
+// AskVenue are empty when no venue quotes that side.
+type ConsolidatedQuote struct {
+	Symbol    string
+	Venues    []VenueQuote
+	BestBid   PriceLevel
+	BidVenue  string
+	BestAsk   PriceLevel
+	AskVenue  string
+	Timestamp time.Time
+}
+
+// Crossed reports whether the best bid is at or above the best offer, which
+// across venues means buying on AskVenue and selling on BidVenue does not
+// lose on price.
+func (q ConsolidatedQuote) Crossed() bool {
+	return q.BidVenue != "" && q.AskVenue != "" && q.BestBid.Price.GreaterThanOrEqual(q.BestAsk.Price)
+}
+
 type OrderBookDelta struct {
 	Venue          string
 	Symbol         string
 	Bids           []PriceLevel
 	Asks           []PriceLevel
 	Sequence       uint64
+	FirstSequence  uint64 // first sequence the delta covers; zero if the venue only sends Sequence
+	Checksum       uint32 // venue CRC32 of the top of book after this delta; zero if not sent
+	Snapshot       bool   // the levels are the whole book and replace it rather than update it
 	VenueTimestamp time.Time
 	LocalTimestamp  time.Time
 }
@@ -147,6 +297,20 @@ type FundingRate struct {
 	NextTime  time.Time
 }
 
+// Kline is one candle of a symbol's trading: the prices and base volume
+// traded in the Interval starting at OpenTime.
+type Kline struct {
+	Venue    string
+	Symbol   string
+	Interval time.Duration
+	OpenTime time.Time
+	Open     decimal.Decimal
+	High     decimal.Decimal
+	Low      decimal.Decimal
+	Close    decimal.Decimal
+	Volume   decimal.Decimal
+}
+
 type CostEstimate struct {
 	FeeBps      decimal.Decimal
 	SlippageBps decimal.Decimal
@@ -162,6 +326,12 @@ type LegSpec struct {
 	Price          decimal.Decimal
 	Size           decimal.Decimal
 	OrderType      OrderType
+	// Venue is where the leg trades when that is not the signal's venue,
+	// as in a cross-venue basis trade; empty means the signal's venue.
+	Venue string
+	// ReduceOnly marks a perp leg that exits or hedges an existing
+	// position, so its order may only reduce it.
+	ReduceOnly bool
 }
 
 type TradeSignal struct {
@@ -172,29 +342,68 @@ type TradeSignal struct {
 	ExpectedEdgeBps     decimal.Decimal
 	CostEstimate        CostEstimate
 	Confidence          decimal.Decimal
+	Atomicity           decimal.Decimal // probability all legs fill within the timeout; folded into Confidence
 	CreatedAt           time.Time
 	MarketDataTimestamp time.Time
 }
 
+// LegVenue is the venue leg i of s trades on.
+func (s TradeSignal) LegVenue(i int) string {
+	if v := s.Legs[i].Venue; v != "" {
+		return v
+	}
+	return s.Venue
+}
+
+// Venues returns the venues the legs of s trade on, the signal's venue
+// first.
+func (s TradeSignal) Venues() []string {
+	venues := []string{s.Venue}
+	for i := range s.Legs {
+		if v := s.LegVenue(i); !slices.Contains(venues, v) {
+			venues = append(venues, v)
+		}
+	}
+	return venues
+}
+
+// CrossVenue reports whether any leg of s trades off the signal's venue.
+func (s TradeSignal) CrossVenue() bool {
+	for i := range s.Legs {
+		if s.LegVenue(i) != s.Venue {
+			return true
+		}
+	}
+	return false
+}
+
 type Order struct {
-	InternalID   uuid.UUID
-	VenueID      string
-	SignalID     uuid.UUID
-	Venue        string
-	Symbol       string
-	Side         Side
-	OrderType    OrderType
-	Price        decimal.Decimal
-	Size         decimal.Decimal
-	FilledSize   decimal.Decimal
-	AvgFillPrice decimal.Decimal
-	Status       OrderStatus
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	InternalID     uuid.UUID
+	VenueID        string
+	SignalID       uuid.UUID
+	Strategy       StrategyType
+	Venue          string
+	Account        string // sub-account it trades on; empty for the venue's default account
+	Symbol         string
+	InstrumentType InstrumentType
+	Side           Side
+	OrderType      OrderType
+	TimeInForce    TimeInForce
+	PostOnly       bool
+	ReduceOnly     bool
+	Price          decimal.Decimal
+	StopPrice      decimal.Decimal
+	Size           decimal.Decimal
+	FilledSize     decimal.Decimal
+	AvgFillPrice   decimal.Decimal
+	Status         OrderStatus
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 type Position struct {
 	Venue          string
+	Account        string // sub-account holding it; empty for the main account
 	Asset          string
 	InstrumentType InstrumentType
 	Size           decimal.Decimal
@@ -205,16 +414,27 @@ type Position struct {
 }
 
 type Balance struct {
-	Venue string
-	Asset string
-	Free  decimal.Decimal
-	Locked decimal.Decimal
-	Total  decimal.Decimal
+	Venue   string
+	Account string // sub-account holding it; empty for the main account
+	Asset   string
+	Free    decimal.Decimal
+	Locked  decimal.Decimal
+	Total   decimal.Decimal
 }
 
+// VenueAssetKey identifies a holding. Account is the sub-account holding
+// it, empty for the venue's default account.
 type VenueAssetKey struct {
-	Venue string
-	Asset string
+	Venue   string
+	Account string
+	Asset   string
+}
+
+// VenueAccount identifies one account on a venue; an empty Account is the
+// venue's default account.
+type VenueAccount struct {
+	Venue   string
+	Account string
 }
 
 type OrderCountState struct {
@@ -235,15 +455,107 @@ type RiskState struct {
 	KillSwitchReason   string
 }
 
+// Clone returns a deep copy of s: its maps and positions are new, so the copy
+// stays as it was however s changes afterwards.
+func (s *RiskState) Clone() *RiskState {
+	cp := *s
+	if s.Positions != nil {
+		cp.Positions = make(map[VenueAssetKey]*Position, len(s.Positions))
+		for k, pos := range s.Positions {
+			if pos == nil {
+				cp.Positions[k] = nil
+				continue
+			}
+			p := *pos
+			cp.Positions[k] = &p
+		}
+	}
+	cp.OpenOrderCounts.PerVenue = cloneCounts(s.OpenOrderCounts.PerVenue)
+	cp.OpenOrderCounts.PerSymbol = cloneCounts(s.OpenOrderCounts.PerSymbol)
+	if s.VenueNotionals != nil {
+		cp.VenueNotionals = make(map[string]decimal.Decimal, len(s.VenueNotionals))
+		for k, v := range s.VenueNotionals {
+			cp.VenueNotionals[k] = v
+		}
+	}
+	return &cp
+}
+
+func cloneCounts(m map[string]int) map[string]int {
+	if m == nil {
+		return nil
+	}
+	cp := make(map[string]int, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
+
+// RiskEvent is a notable risk occurrence kept in the cold store's
+// risk_events table. Details is JSON-encoded with it.
+type RiskEvent struct {
+	ID        uuid.UUID
+	Type      RiskEventType
+	Severity  AlertSeverity
+	Details   interface{}
+	CreatedAt time.Time
+}
+
+type RiskEventType string
+
+const (
+	// RiskEventLiquidation is a simulated perp liquidation in dry run.
+	RiskEventLiquidation RiskEventType = "LIQUIDATION"
+)
+
+// RiskRejection records a signal the risk manager refused, so the limits
+// that bind most often can be reviewed later. NotionalUSDT is the signal's
+// first leg, the size its expected edge applies to.
+type RiskRejection struct {
+	SignalID        uuid.UUID
+	Strategy        StrategyType
+	Venue           string
+	Reason          string
+	Details         string
+	ExpectedEdgeBps decimal.Decimal
+	NotionalUSDT    decimal.Decimal
+	RejectedAt      time.Time
+}
+
+// ForgoneEdge is the USDT the signal expected to make.
+func (r RiskRejection) ForgoneEdge() decimal.Decimal {
+	return r.NotionalUSDT.Mul(r.ExpectedEdgeBps).Div(decimal.NewFromInt(10000))
+}
+
+// DailyPnLSnapshot is the closing record of one trading day, written to the
+// daily_pnl table when the day rolls over.
+type DailyPnLSnapshot struct {
+	Date          time.Time
+	RealizedPnL   decimal.Decimal
+	UnrealizedPnL decimal.Decimal
+	TotalPnL      decimal.Decimal
+	NumCycles     int
+	NumTrades     int
+	FeesPaid      decimal.Decimal
+	FundingNet    decimal.Decimal
+}
+
 type OrderRequest struct {
 	InternalID     uuid.UUID
 	SignalID       uuid.UUID
+	Strategy       StrategyType // picks the account the order is routed to
 	Venue          string
+	Account        string // set by the order manager from Strategy
 	Symbol         string
 	Side           Side
 	InstrumentType InstrumentType
 	OrderType      OrderType
+	TimeInForce    TimeInForce
+	PostOnly       bool // rejected instead of filled if it would take liquidity
+	ReduceOnly     bool // perp only; never opens or adds to a position
 	Price          decimal.Decimal
+	StopPrice      decimal.Decimal // trigger price; stop order types only
 	Size           decimal.Decimal
 	IdempotencyKey string
 }
@@ -255,6 +567,20 @@ type OrderAck struct {
 	Timestamp  time.Time
 }
 
+// AmendAck confirms a price/size change on a resting order. VenueID is the
+// order's ID after the amend, which differs from the original on venues that
+// implement amend as cancel-replace. Those report in FilledSize and
+// AvgFillPrice what the replaced order filled; zero when not known.
+type AmendAck struct {
+	VenueID      string
+	Price        decimal.Decimal
+	Size         decimal.Decimal
+	FilledSize   decimal.Decimal
+	AvgFillPrice decimal.Decimal
+	Status       OrderStatus
+	Timestamp    time.Time
+}
+
 type CancelAck struct {
 	InternalID uuid.UUID
 	VenueID    string
@@ -262,6 +588,101 @@ type CancelAck struct {
 	Timestamp  time.Time
 }
 
+// OrderUpdate is a venue-pushed change to one of our orders. FilledSize and
+// AvgFillPrice are cumulative for the order, not per fill. ClientOrderID
+// carries the idempotency key so updates that race ahead of the REST ack can
+// still be matched.
+type OrderUpdate struct {
+	Venue         string
+	VenueID       string
+	ClientOrderID string
+	Status        OrderStatus
+	FilledSize    decimal.Decimal
+	AvgFillPrice  decimal.Decimal
+	Timestamp     time.Time
+}
+
+type ActivityType string
+
+const (
+	ActivityTrade      ActivityType = "TRADE"
+	ActivityDeposit    ActivityType = "DEPOSIT"
+	ActivityWithdrawal ActivityType = "WITHDRAWAL"
+	ActivityFunding    ActivityType = "FUNDING"
+)
+
+// AccountActivity is one historical account event pulled from a venue. Trades
+// fill Symbol/Side/Price/Size; transfers and funding payments fill Asset and a
+// signed Amount. VenueRef is the venue's own ID for the event and, together
+// with Venue and Type, identifies it for de-duplication across imports.
+type AccountActivity struct {
+	Venue       string
+	Type        ActivityType
+	VenueRef    string
+	Symbol      string
+	Asset       string
+	Side        Side
+	Price       decimal.Decimal
+	Size        decimal.Decimal
+	Amount      decimal.Decimal
+	Fee         decimal.Decimal
+	FeeCurrency string
+	Timestamp   time.Time
+}
+
+type TransferStatus string
+
+const (
+	TransferStatusPending    TransferStatus = "PENDING"
+	TransferStatusProcessing TransferStatus = "PROCESSING"
+	TransferStatusCompleted  TransferStatus = "COMPLETED"
+	TransferStatusFailed     TransferStatus = "FAILED"
+	TransferStatusCancelled  TransferStatus = "CANCELLED"
+)
+
+func (s TransferStatus) IsTerminal() bool {
+	return s == TransferStatusCompleted || s == TransferStatusFailed || s == TransferStatusCancelled
+}
+
+// WithdrawRequest moves Amount of Asset off a venue to Address on Network
+// (the chain, e.g. "TRC20"). Memo is the destination tag some chains and
+// venues require; empty when not needed.
+type WithdrawRequest struct {
+	Asset   string
+	Network string
+	Address string
+	Memo    string
+	Amount  decimal.Decimal
+}
+
+// DepositAddress is where a venue credits deposits of Asset sent over Network.
+type DepositAddress struct {
+	Venue   string
+	Asset   string
+	Network string
+	Address string
+	Memo    string
+}
+
+// Transfer is a withdrawal initiated through a venue gateway. ID is the
+// venue's withdrawal ID and TxID the on-chain hash once broadcast.
+type Transfer struct {
+	ID        string
+	Venue     string
+	Asset     string
+	Network   string
+	Address   string
+	Memo      string
+	Amount    decimal.Decimal
+	Fee       decimal.Decimal
+	TxID      string
+	Status    TransferStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// FeeTier is what a venue charges per fill, in basis points of notional.
+// A negative rate is a rebate the venue pays; some venues pay makers.
 type FeeTier struct {
 	MakerFeeBps decimal.Decimal
 	TakerFeeBps decimal.Decimal
@@ -269,6 +690,25 @@ type FeeTier struct {
 	UpdatedAt   time.Time
 }
 
+// FeeBps returns the maker rate if maker is set, else the taker rate.
+func (t *FeeTier) FeeBps(maker bool) decimal.Decimal {
+	if maker {
+		return t.MakerFeeBps
+	}
+	return t.TakerFeeBps
+}
+
+// Fee is what a fill of notional costs as maker or taker. A rebate makes it
+// negative.
+func (t *FeeTier) Fee(notional decimal.Decimal, maker bool) decimal.Decimal {
+	return notional.Mul(t.FeeBps(maker)).Div(decimal.NewFromInt(10000))
+}
+
+// MakerRebate reports whether the venue pays makers for resting fills.
+func (t *FeeTier) MakerRebate() bool {
+	return t.MakerFeeBps.IsNegative()
+}
+
 type OrderStateChange struct {
 	Order      Order
 	PrevStatus OrderStatus
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func testBook() OrderBookSnapshot {
+	level := func(price, size string) PriceLevel {
+		return PriceLevel{Price: decimal.RequireFromString(price), Size: decimal.RequireFromString(size)}
+	}
+	return OrderBookSnapshot{
+		Bids: []PriceLevel{level("100", "1"), level("99.95", "2"), level("99", "5")},
+		Asks: []PriceLevel{level("101", "1"), level("101.05", "3"), level("102", "5")},
+	}
+}
+
+func TestVWAPForSize(t *testing.T) {
+	book := testBook()
+	cases := []struct {
+		side         Side
+		size         string
+		vwap, filled string
+	}{
+		{SideBuy, "0.5", "101", "0.5"},
+		// 1 at 101 and 1 at 101.05.
+		{SideBuy, "2", "101.025", "2"},
+		// 1 at 100 and 3 at 99.95.
+		{SideSell, "3", "99.9666666666666667", "3"},
+		// The whole ask side: 1×101 + 3×101.05 + 5×102 = 914.15 over 9.
+		{SideBuy, "20", "101.5722222222222222", "9"},
+	}
+	for _, tc := range cases {
+		vwap, filled := book.VWAPForSize(tc.side, decimal.RequireFromString(tc.size))
+		if !vwap.Equal(decimal.RequireFromString(tc.vwap)) || !filled.Equal(decimal.RequireFromString(tc.filled)) {
+			t.Errorf("%s %s: expected %s for %s, got %s for %s", tc.side, tc.size, tc.vwap, tc.filled, vwap, filled)
+		}
+	}
+
+	empty := OrderBookSnapshot{}
+	if vwap, filled := empty.VWAPForSize(SideBuy, decimal.NewFromInt(1)); !vwap.IsZero() || !filled.IsZero() {
+		t.Errorf("expected nothing filled on an empty book, got %s for %s", vwap, filled)
+	}
+}
+
+func TestDepthWithinBps(t *testing.T) {
+	book := testBook()
+	cases := []struct {
+		bps      int
+		bid, ask string
+	}{
+		{0, "1", "1"},
+		// 99.95 is 5 bps under 100; 101.05 is about 4.95 bps over 101.
+		{5, "3", "4"},
+		{200, "8", "9"},
+	}
+	for _, tc := range cases {
+		bid, ask := book.DepthWithinBps(tc.bps)
+		if !bid.Equal(decimal.RequireFromString(tc.bid)) || !ask.Equal(decimal.RequireFromString(tc.ask)) {
+			t.Errorf("%d bps: expected %s/%s, got %s/%s", tc.bps, tc.bid, tc.ask, bid, ask)
+		}
+	}
+}
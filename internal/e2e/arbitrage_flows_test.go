@@ -168,6 +168,10 @@ func (m *mockVenueGateway) Connect(_ context.Context) error { return nil }
 
 func (m *mockVenueGateway) Close() error { return nil }
 
+func (m *mockVenueGateway) Health(_ context.Context) domain.VenueHealth {
+	return domain.VenueHealth{Venue: m.name, WSConnected: true, RESTReachable: true}
+}
+
 func (m *mockVenueGateway) SubscribeOrderBook(_ context.Context, _ string) (<-chan domain.OrderBookDelta, error) {
 	return make(chan domain.OrderBookDelta), nil
 }
@@ -191,6 +195,21 @@ func (m *mockVenueGateway) CancelOrder(_ context.Context, _ string) (*domain.Can
 func (m *mockVenueGateway) GetOpenOrders(_ context.Context, _ string) ([]domain.Order, error) {
 	return nil, nil
 }
+func (m *mockVenueGateway) AmendOrder(_ context.Context, _ string, _, _ decimal.Decimal) (*domain.AmendAck, error) {
+	return nil, gateway.ErrAmendUnsupported
+}
+
+func (m *mockVenueGateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, m.PlaceOrder)
+}
+
+func (m *mockVenueGateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, m.CancelOrder)
+}
+
+func (m *mockVenueGateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
 
 func (m *mockVenueGateway) GetBalances(_ context.Context) (map[string]domain.Balance, error) {
 	return nil, nil
@@ -200,6 +219,10 @@ func (m *mockVenueGateway) GetPositions(_ context.Context) ([]domain.Position, e
 	return nil, nil
 }
 
+func (m *mockVenueGateway) GetInstruments(_ context.Context) ([]domain.Instrument, error) {
+	return nil, nil
+}
+
 func (m *mockVenueGateway) GetFeeTier(_ context.Context) (*domain.FeeTier, error) {
 	return &domain.FeeTier{
 		MakerFeeBps: decimal.NewFromFloat(1),
@@ -209,6 +232,22 @@ func (m *mockVenueGateway) GetFeeTier(_ context.Context) (*domain.FeeTier, error
 	}, nil
 }
 
+func (m *mockVenueGateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return nil, nil
+}
+
+func (m *mockVenueGateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (m *mockVenueGateway) GetDepositAddress(_ context.Context, _, _ string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (m *mockVenueGateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
 var _ gateway.VenueGateway = (*mockVenueGateway)(nil)
 
 // ---------------------------------------------------------------------------
@@ -222,6 +261,7 @@ func TestTriArbFlow_SignalDetectedAndExecuted(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1, // very low threshold (1 bps) to trigger easily
@@ -279,6 +319,7 @@ func TestTriArbFlow_NoSignalWhenInsufficientEdge(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		5000, // very high threshold (50% = 5000 bps) so no signal fires
@@ -324,6 +365,7 @@ func TestTriArbFlow_RiskRejection_KillSwitch(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -366,6 +408,7 @@ func TestTriArbFlow_MultipleCyclesSequential(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -414,6 +457,7 @@ func TestTriArbFlow_MissingOrderBook(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -451,6 +495,7 @@ func TestTriArbFlow_WrongVenueIgnored(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -494,6 +539,7 @@ func TestTriArbFlow_ExecutionReportContainsSlippage(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -564,6 +610,7 @@ func TestBasisArbFlow_SignalDetectedAndExecuted(t *testing.T) {
 	basisArb := strategy.NewBasisArbModule(
 		[]string{"nobitex"},
 		[]string{"BTC"},
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1, // very low threshold (1 bps)
@@ -621,6 +668,7 @@ func TestBasisArbFlow_NoSignalWhenBasisTooSmall(t *testing.T) {
 	basisArb := strategy.NewBasisArbModule(
 		[]string{"nobitex"},
 		[]string{"BTC"},
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		5000, // very high threshold (50%)
@@ -659,6 +707,7 @@ func TestBasisArbFlow_SpotBuyPerpSellDirection(t *testing.T) {
 	basisArb := strategy.NewBasisArbModule(
 		[]string{"nobitex"},
 		[]string{"ETH"},
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -719,6 +768,7 @@ func TestBasisArbFlow_MultipleAssets(t *testing.T) {
 	basisArb := strategy.NewBasisArbModule(
 		[]string{"nobitex"},
 		[]string{"BTC", "ETH"},
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -786,6 +836,7 @@ func TestBasisArbFlow_FundingRateHistoryAffectsEdge(t *testing.T) {
 	basisArb := strategy.NewBasisArbModule(
 		[]string{"nobitex"},
 		[]string{"BTC"},
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		100, // moderate threshold
@@ -842,6 +893,7 @@ func TestBothStrategiesRunConcurrently(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -851,6 +903,7 @@ func TestBothStrategiesRunConcurrently(t *testing.T) {
 	basisArb := strategy.NewBasisArbModule(
 		[]string{"nobitex"},
 		[]string{"BTC"},
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -961,7 +1014,7 @@ func TestRiskRejection_PositionLimitPreventsExecution(t *testing.T) {
 
 	triArb := strategy.NewTriArbModule("nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
-		costSvc, bus, 1, logger)
+		mdSvc.View(), costSvc, bus, 1, logger)
 	stratEng.RegisterModule(triArb)
 
 	reportCh := bus.SubscribeExecutionReport()
@@ -1010,6 +1063,7 @@ func TestTriArbFlow_SOLPath(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -1063,6 +1117,7 @@ func TestEventBusFlowIntegrity(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -1130,6 +1185,7 @@ func TestBasisArbFlow_ExecutionReportFields(t *testing.T) {
 	basisArb := strategy.NewBasisArbModule(
 		[]string{"nobitex"},
 		[]string{"BTC"},
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -1202,6 +1258,7 @@ func TestTriArbFlow_KillSwitchDeactivateResumesTrading(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -1260,6 +1317,7 @@ func TestTriArbFlow_ReversePath(t *testing.T) {
 	triArb := strategy.NewTriArbModule(
 		"nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
+		h.mdSvc.View(),
 		h.costSvc,
 		h.bus,
 		1,
@@ -1332,7 +1390,7 @@ func TestMarketDataStaleness_BlocksExecution(t *testing.T) {
 
 	triArb := strategy.NewTriArbModule("nobitex",
 		strategy.DefaultTriangularPaths("nobitex"),
-		costSvc, bus, 1, logger)
+		mdSvc.View(), costSvc, bus, 1, logger)
 	stratEng.RegisterModule(triArb)
 
 	reportCh := bus.SubscribeExecutionReport()
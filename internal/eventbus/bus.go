@@ -16,6 +16,7 @@ type EventBus struct {
 	signalSubs     []chan domain.TradeSignal
 	orderStateSubs []chan domain.OrderStateChange
 	execReportSubs []chan domain.ExecutionReport
+	quoteSubs      []chan domain.ConsolidatedQuote
 
 	bufferSize int
 	logger     *slog.Logger
@@ -154,6 +155,27 @@ func (eb *EventBus) PublishExecutionReport(report domain.ExecutionReport) {
 	}
 }
 
+func (eb *EventBus) SubscribeConsolidatedQuote() <-chan domain.ConsolidatedQuote {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	ch := make(chan domain.ConsolidatedQuote, eb.bufferSize)
+	eb.quoteSubs = append(eb.quoteSubs, ch)
+	return ch
+}
+
+func (eb *EventBus) PublishConsolidatedQuote(quote domain.ConsolidatedQuote) {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	for _, ch := range eb.quoteSubs {
+		select {
+		case ch <- quote:
+		default:
+			eb.logger.Warn("consolidated quote subscriber channel full, dropping event",
+				"symbol", quote.Symbol)
+		}
+	}
+}
+
 func (eb *EventBus) Close() {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
@@ -175,4 +197,7 @@ func (eb *EventBus) Close() {
 	for _, ch := range eb.execReportSubs {
 		close(ch)
 	}
+	for _, ch := range eb.quoteSubs {
+		close(ch)
+	}
 }
@@ -70,6 +70,24 @@ func TestEventBusSignal(t *testing.T) {
 	}
 }
 
+func TestEventBusConsolidatedQuote(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	bus := New(10, logger)
+	defer bus.Close()
+
+	ch := bus.SubscribeConsolidatedQuote()
+	bus.PublishConsolidatedQuote(domain.ConsolidatedQuote{Symbol: "BTC/USDT", BidVenue: "okx"})
+
+	select {
+	case q := <-ch:
+		if q.Symbol != "BTC/USDT" || q.BidVenue != "okx" {
+			t.Errorf("unexpected quote %+v", q)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for consolidated quote event")
+	}
+}
+
 func TestEventBusDropsOnFull(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	bus := New(1, logger)
@@ -0,0 +1,171 @@
+package execution
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Divergence is one strategy's comparison of shadow and live edge capture,
+// the realized minus the expected edge of its completed cycles. DivergenceBps
+// is ShadowBps minus LiveBps: positive when the simulation fills better than
+// the venues do.
+type Divergence struct {
+	Strategy      domain.StrategyType
+	ShadowBps     float64
+	LiveBps       float64
+	DivergenceBps float64
+	ShadowSamples int
+	LiveSamples   int
+	Since         time.Time // when it went over the threshold; zero if it is not
+}
+
+// DivergenceMonitor compares the edge capture of shadow execution with that
+// of live execution per strategy, so that a fill or cost model that has
+// drifted from reality is noticed. Both sides are the mean of their last
+// window completed cycles.
+type DivergenceMonitor struct {
+	mu         sync.Mutex
+	strategies map[domain.StrategyType]*divergenceState
+
+	window       int
+	minSamples   int
+	thresholdBps float64
+	sustain      time.Duration
+	onDivergence func(Divergence)
+	logger       *slog.Logger
+}
+
+type divergenceState struct {
+	shadow, live captureWindow
+	since        time.Time // zero while within the threshold
+	alerted      bool
+}
+
+// captureWindow is a ring of the last edge captures and their sum.
+type captureWindow struct {
+	samples []float64
+	next    int
+	sum     float64
+}
+
+func (w *captureWindow) add(v float64, size int) {
+	if len(w.samples) < size {
+		w.samples = append(w.samples, v)
+		w.sum += v
+		return
+	}
+	w.sum += v - w.samples[w.next]
+	w.samples[w.next] = v
+	w.next = (w.next + 1) % size
+}
+
+func (w *captureWindow) mean() float64 {
+	if len(w.samples) == 0 {
+		return 0
+	}
+	return w.sum / float64(len(w.samples))
+}
+
+// NewDivergenceMonitor compares the last window cycles of each side once
+// both have minSamples, and counts a strategy as diverged when the means
+// differ by more than thresholdBps for sustain.
+func NewDivergenceMonitor(window, minSamples int, thresholdBps float64, sustain time.Duration, logger *slog.Logger) *DivergenceMonitor {
+	return &DivergenceMonitor{
+		strategies:   make(map[domain.StrategyType]*divergenceState),
+		window:       window,
+		minSamples:   min(minSamples, window),
+		thresholdBps: thresholdBps,
+		sustain:      sustain,
+		logger:       logger,
+	}
+}
+
+// SetDivergenceCallback registers fn to be called once when a strategy has
+// stayed diverged for the sustain period, and again only after it has come
+// back within the threshold and diverged anew. Call before reports arrive.
+func (m *DivergenceMonitor) SetDivergenceCallback(fn func(Divergence)) {
+	m.onDivergence = fn
+}
+
+// RecordShadow adds a shadow execution report and returns its strategy's
+// comparison.
+func (m *DivergenceMonitor) RecordShadow(report domain.ExecutionReport) Divergence {
+	return m.record(report, true, time.Now())
+}
+
+// RecordLive adds a live execution report and returns its strategy's
+// comparison.
+func (m *DivergenceMonitor) RecordLive(report domain.ExecutionReport) Divergence {
+	return m.record(report, false, time.Now())
+}
+
+// record adds report's edge capture to its side and re-evaluates the
+// strategy at now. Only completed cycles count, since an aborted cycle's
+// edge measures the unwind rather than the fills; for others the zero
+// Divergence is returned.
+func (m *DivergenceMonitor) record(report domain.ExecutionReport, shadow bool, now time.Time) Divergence {
+	if report.Status != "completed" {
+		return Divergence{}
+	}
+	capture := report.RealizedEdgeBps.Sub(report.ExpectedEdgeBps).InexactFloat64()
+
+	m.mu.Lock()
+	st, seen := m.strategies[report.Strategy]
+	if !seen {
+		st = &divergenceState{}
+		m.strategies[report.Strategy] = st
+	}
+	if shadow {
+		st.shadow.add(capture, m.window)
+	} else {
+		st.live.add(capture, m.window)
+	}
+	d, fire := m.evaluate(report.Strategy, st, now)
+	m.mu.Unlock()
+
+	if fire {
+		m.logger.Warn("shadow execution diverged from live",
+			"strategy", d.Strategy,
+			"shadow_capture_bps", d.ShadowBps,
+			"live_capture_bps", d.LiveBps,
+			"divergence_bps", d.DivergenceBps,
+			"since", d.Since)
+		if m.onDivergence != nil {
+			m.onDivergence(d)
+		}
+	}
+	return d
+}
+
+// evaluate updates st's divergence state at now and reports whether it has
+// just become sustained. The caller holds m.mu.
+func (m *DivergenceMonitor) evaluate(strategy domain.StrategyType, st *divergenceState, now time.Time) (Divergence, bool) {
+	d := Divergence{
+		Strategy:      strategy,
+		ShadowBps:     st.shadow.mean(),
+		LiveBps:       st.live.mean(),
+		ShadowSamples: len(st.shadow.samples),
+		LiveSamples:   len(st.live.samples),
+	}
+	d.DivergenceBps = d.ShadowBps - d.LiveBps
+	if d.ShadowSamples < m.minSamples || d.LiveSamples < m.minSamples || math.Abs(d.DivergenceBps) <= m.thresholdBps {
+		if st.alerted {
+			m.logger.Info("shadow execution back in line with live", "strategy", strategy)
+		}
+		st.since, st.alerted = time.Time{}, false
+		return d, false
+	}
+	if st.since.IsZero() {
+		st.since = now
+	}
+	d.Since = st.since
+	if st.alerted || now.Sub(st.since) < m.sustain {
+		return d, false
+	}
+	st.alerted = true
+	return d, true
+}
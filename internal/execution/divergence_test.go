@@ -0,0 +1,73 @@
+package execution
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func edgeReport(strategy domain.StrategyType, expectedBps, realizedBps int64) domain.ExecutionReport {
+	return domain.ExecutionReport{
+		Strategy:        strategy,
+		Status:          "completed",
+		ExpectedEdgeBps: decimal.NewFromInt(expectedBps),
+		RealizedEdgeBps: decimal.NewFromInt(realizedBps),
+	}
+}
+
+func TestDivergenceMonitorAlertsOnSustainedDivergence(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	m := NewDivergenceMonitor(10, 5, 5, time.Minute, logger)
+	var alerts []Divergence
+	m.SetDivergenceCallback(func(d Divergence) { alerts = append(alerts, d) })
+
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	// Shadow keeps its expected edge; live gives up 8 bps of it.
+	for i := 0; i < 4; i++ {
+		m.record(edgeReport(domain.StrategyTriArb, 20, 20), true, start)
+		m.record(edgeReport(domain.StrategyTriArb, 20, 12), false, start)
+	}
+	if d := m.record(edgeReport(domain.StrategyTriArb, 20, 20), true, start); !d.Since.IsZero() {
+		t.Fatal("expected no comparison before min samples on both sides")
+	}
+	d := m.record(edgeReport(domain.StrategyTriArb, 20, 12), false, start)
+	if math.Abs(d.DivergenceBps-8) > 1e-9 || d.Since != start {
+		t.Fatalf("expected 8 bps divergence from %s, got %+v", start, d)
+	}
+
+	// Aborted cycles and other strategies do not count.
+	m.record(domain.ExecutionReport{Strategy: domain.StrategyTriArb, Status: "aborted", RealizedEdgeBps: decimal.NewFromInt(-100)}, false, start)
+	m.record(edgeReport(domain.StrategyBasisArb, 10, 10), false, start)
+
+	m.record(edgeReport(domain.StrategyTriArb, 20, 12), false, start.Add(30*time.Second))
+	if len(alerts) != 0 {
+		t.Fatal("expected no alert before the divergence is sustained")
+	}
+	m.record(edgeReport(domain.StrategyTriArb, 20, 12), false, start.Add(time.Minute))
+	m.record(edgeReport(domain.StrategyTriArb, 20, 12), false, start.Add(2*time.Minute))
+	if len(alerts) != 1 || alerts[0].Strategy != domain.StrategyTriArb {
+		t.Fatalf("expected one TRI_ARB alert, got %+v", alerts)
+	}
+
+	// Live back in line clears it, so the next sustained divergence alerts
+	// again.
+	for i := 0; i < 10; i++ {
+		m.record(edgeReport(domain.StrategyTriArb, 20, 19), false, start.Add(3*time.Minute))
+	}
+	if d := m.record(edgeReport(domain.StrategyTriArb, 20, 20), true, start.Add(3*time.Minute)); !d.Since.IsZero() {
+		t.Fatalf("expected divergence cleared, got %+v", d)
+	}
+	for i := 0; i < 10; i++ {
+		m.record(edgeReport(domain.StrategyTriArb, 20, 30), false, start.Add(4*time.Minute))
+	}
+	m.record(edgeReport(domain.StrategyTriArb, 20, 30), false, start.Add(5*time.Minute))
+	if len(alerts) != 2 || alerts[1].DivergenceBps >= 0 {
+		t.Fatalf("expected a second alert with live beating shadow, got %+v", alerts)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/gateway"
 	"github.com/crypto-trading/trading/internal/order"
 	"github.com/crypto-trading/trading/internal/risk"
 )
@@ -23,10 +25,41 @@ type Engine struct {
 
 	triArbFillTimeout  time.Duration
 	basisArbFillTimeout time.Duration
+	crossVenueArbFillTimeout time.Duration
 	maxRetries         int
 	retryBackoff       time.Duration
+
+	// minAtomicity holds per-strategy floors on TradeSignal.Atomicity.
+	minAtomicity map[domain.StrategyType]decimal.Decimal
+
+	// assetFillTimeouts overrides the strategy fill timeout by base asset.
+	assetFillTimeouts map[domain.StrategyType]map[string]time.Duration
+
+	passive *passiveEntry
+	rebate  *rebateEntry
+	fees    FeeSource
+
+	// fundingTiming holds per-strategy timing of perp legs around funding
+	// snapshots, read from funding.
+	fundingTiming map[domain.StrategyType]FundingTimingConfig
+	funding       FundingSource
+
+	latencyComp *latencyCompensation
+
+	rateLimits RateLimitSource
+
+	onExecute func(domain.TradeSignal)
+	onAck     AckObserver
+	onReject  func(domain.RiskRejection)
 }
 
+// AckObserver is told how long an order took to be acknowledged, counted
+// from the market data update its signal was built on.
+type AckObserver func(signal domain.TradeSignal, symbol string, tickToAck time.Duration)
+
+// RateLimitSource returns the request budget a venue has left.
+type RateLimitSource func(ctx context.Context, venue string) ([]domain.RateLimitStatus, error)
+
 func NewEngine(
 	orderMgr *order.Manager,
 	riskMgr *risk.Manager,
@@ -43,8 +76,134 @@ func NewEngine(
 		logger:             logger,
 		triArbFillTimeout:  triArbTimeout,
 		basisArbFillTimeout: basisArbTimeout,
+		crossVenueArbFillTimeout: basisArbTimeout,
 		maxRetries:         maxRetries,
 		retryBackoff:       50 * time.Millisecond,
+		minAtomicity:       make(map[domain.StrategyType]decimal.Decimal),
+		assetFillTimeouts:  make(map[domain.StrategyType]map[string]time.Duration),
+		fundingTiming:      make(map[domain.StrategyType]FundingTimingConfig),
+	}
+}
+
+// SetCrossVenueArbFillTimeout sets how long cross-venue arb legs may take to
+// fill, which is the basis-arb timeout until set. Call before Run.
+func (e *Engine) SetCrossVenueArbFillTimeout(timeout time.Duration) {
+	e.crossVenueArbFillTimeout = timeout
+}
+
+// SetAssetFillTimeouts sets strategy's fill timeout per base asset, so liquid
+// majors can be given up on sooner than thin alts. Assets not listed keep the
+// strategy default. Call before Run.
+func (e *Engine) SetAssetFillTimeouts(strategy domain.StrategyType, timeouts map[string]time.Duration) {
+	e.assetFillTimeouts[strategy] = timeouts
+}
+
+// fillTimeout is how long signal's legs may take to fill: the longest
+// timeout of any leg's base asset, since the least liquid leg sets the pace.
+func (e *Engine) fillTimeout(signal domain.TradeSignal, def time.Duration) time.Duration {
+	byAsset := e.assetFillTimeouts[signal.Strategy]
+	if len(byAsset) == 0 {
+		return def
+	}
+	var timeout time.Duration
+	for _, leg := range signal.Legs {
+		t, ok := byAsset[domain.ExtractAsset(leg.Symbol)]
+		if !ok {
+			t = def
+		}
+		if t > timeout {
+			timeout = t
+		}
+	}
+	if timeout == 0 {
+		return def
+	}
+	return timeout
+}
+
+// SetMinAtomicity skips signals of strategy whose estimated probability of
+// filling every leg is below floor. Call before Run.
+func (e *Engine) SetMinAtomicity(strategy domain.StrategyType, floor decimal.Decimal) {
+	e.minAtomicity[strategy] = floor
+}
+
+// SetRateLimitSource makes the engine check the venue's order placement
+// budget before executing a signal. Call before Run.
+func (e *Engine) SetRateLimitSource(src RateLimitSource) {
+	e.rateLimits = src
+}
+
+// orderBudget reports whether each venue signal trades on can take an order
+// per leg with as many again in reserve for retries and unwinding. A cycle
+// the venue throttles halfway through is left with an unhedged leg, so it is
+// better not started. A venue whose budget is unknown goes ahead. For a
+// cross-venue signal the venue shortest of budget is reported.
+func (e *Engine) orderBudget(ctx context.Context, signal domain.TradeSignal) (available float64, need int, ok bool) {
+	need = 2 * len(signal.Legs)
+	if e.rateLimits == nil {
+		return 0, need, true
+	}
+	ok = true
+	known := false
+	for _, venue := range signal.Venues() {
+		venueNeed := 0
+		for i := range signal.Legs {
+			if signal.LegVenue(i) == venue {
+				venueNeed += 2
+			}
+		}
+		venueAvailable, found := e.venueBudget(ctx, venue)
+		if !found {
+			continue
+		}
+		if !known || venueAvailable-float64(venueNeed) < available-float64(need) {
+			available, need = venueAvailable, venueNeed
+		}
+		known = true
+		ok = ok && venueAvailable >= float64(venueNeed)
+	}
+	return available, need, ok
+}
+
+// venueBudget returns the order placement budget available on venue, or
+// found=false when it is unknown.
+func (e *Engine) venueBudget(ctx context.Context, venue string) (available float64, found bool) {
+	statuses, err := e.rateLimits(ctx, venue)
+	if err != nil {
+		e.logger.Warn("rate limit status unavailable", "venue", venue, "error", err)
+		return 0, false
+	}
+	for _, st := range statuses {
+		if st.Category == domain.EndpointOrderPlace {
+			return st.Available(time.Now()), true
+		}
+	}
+	return 0, false
+}
+
+// SetSignalObserver registers fn to be told about each signal that passed
+// every check and is about to be executed. Call before Run.
+func (e *Engine) SetSignalObserver(fn func(domain.TradeSignal)) {
+	e.onExecute = fn
+}
+
+// SetRejectionObserver registers fn to be told about each signal the risk
+// manager rejects. Call before Run.
+func (e *Engine) SetRejectionObserver(fn func(domain.RiskRejection)) {
+	e.onReject = fn
+}
+
+// SetAckObserver registers fn to be told the tick-to-ack latency of every
+// order the engine places. Call before Run.
+func (e *Engine) SetAckObserver(fn AckObserver) {
+	e.onAck = fn
+}
+
+// observeAck reports the tick-to-ack latency of the order just acknowledged
+// for symbol. Signals without a market data timestamp are not measured.
+func (e *Engine) observeAck(signal domain.TradeSignal, symbol string) {
+	if e.onAck != nil && !signal.MarketDataTimestamp.IsZero() {
+		e.onAck(signal, symbol, time.Since(signal.MarketDataTimestamp))
 	}
 }
 
@@ -68,6 +227,47 @@ func (e *Engine) Run(ctx context.Context) {
 }
 
 func (e *Engine) executeSignal(ctx context.Context, signal domain.TradeSignal) {
+	if floor, ok := e.minAtomicity[signal.Strategy]; ok && signal.Atomicity.LessThan(floor) {
+		e.logger.Info("signal skipped: legs unlikely to all fill",
+			"signal_id", signal.SignalID,
+			"strategy", signal.Strategy,
+			"atomicity", signal.Atomicity.String(),
+			"floor", floor.String(),
+		)
+		return
+	}
+
+	// A held signal is checked against the order budget and risk limits as
+	// they stand when it resumes.
+	accelerate := false
+	switch action, resume := e.fundingAction(signal, time.Now()); action {
+	case fundingDelay:
+		e.logger.Info("signal held until after funding snapshot",
+			"signal_id", signal.SignalID,
+			"strategy", signal.Strategy,
+			"venue", signal.Venue,
+			"resume_at", resume,
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(resume)):
+		}
+	case fundingAccelerate:
+		accelerate = true
+	}
+
+	if available, need, ok := e.orderBudget(ctx, signal); !ok {
+		e.logger.Warn("signal skipped: venue order budget nearly exhausted",
+			"signal_id", signal.SignalID,
+			"strategy", signal.Strategy,
+			"venue", signal.Venue,
+			"available", available,
+			"needed", need,
+		)
+		return
+	}
+
 	result := e.riskMgr.ValidateSignal(signal)
 	if !result.Approved {
 		e.logger.Info("signal rejected by risk manager",
@@ -75,6 +275,9 @@ func (e *Engine) executeSignal(ctx context.Context, signal domain.TradeSignal) {
 			"reason", result.Reason,
 			"details", result.Details,
 		)
+		if e.onReject != nil {
+			e.onReject(rejection(signal, result))
+		}
 		return
 	}
 
@@ -85,114 +288,326 @@ func (e *Engine) executeSignal(ctx context.Context, signal domain.TradeSignal) {
 		"legs", len(signal.Legs),
 	)
 
+	if e.onExecute != nil {
+		e.onExecute(signal)
+	}
+
 	startedAt := time.Now()
 
 	switch signal.Strategy {
 	case domain.StrategyTriArb:
 		e.executeTriArb(ctx, signal, startedAt)
 	case domain.StrategyBasisArb:
-		e.executeBasisArb(ctx, signal, startedAt)
+		e.executeBasisArb(ctx, signal, startedAt, accelerate)
+	case domain.StrategyCrossVenueArb:
+		e.executeCrossVenueArb(ctx, signal, startedAt)
 	}
 }
 
+// rejection is the record of signal being refused with result.
+func rejection(signal domain.TradeSignal, result risk.ValidationResult) domain.RiskRejection {
+	r := domain.RiskRejection{
+		SignalID:        signal.SignalID,
+		Strategy:        signal.Strategy,
+		Venue:           signal.Venue,
+		Reason:          string(result.Reason),
+		Details:         result.Details,
+		ExpectedEdgeBps: signal.ExpectedEdgeBps,
+		RejectedAt:      time.Now(),
+	}
+	if len(signal.Legs) > 0 {
+		r.NotionalUSDT = signal.Legs[0].Price.Mul(signal.Legs[0].Size)
+	}
+	return r
+}
+
+// executeTriArb runs the legs one after another, each IOC, so the cycle
+// only carries on with what actually filled. Each leg is sized from the
+// previous leg's fill. Whatever of a leg's fill the next leg leaves is
+// unwound back through the earlier legs, as is everything held when a leg
+// fills nothing.
 func (e *Engine) executeTriArb(ctx context.Context, signal domain.TradeSignal, startedAt time.Time) {
-	timeout := e.triArbFillTimeout
+	timeout := e.fillTimeout(signal, e.triArbFillTimeout)
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	var legExecutions []domain.LegExecution
-	var allOrders []*domain.Order
+	var held []heldLeg
 	totalFees := decimal.Zero
 
+	// scale is the share of the signal's size still going through the
+	// cycle: the last leg's fill over its size in the signal.
+	scale := decimal.NewFromInt(1)
+	shift := e.driftShiftBps(signal)
 	for i, leg := range signal.Legs {
+		size := leg.Size.Mul(scale)
 		req := domain.OrderRequest{
 			InternalID:     order.NewOrderID(),
 			SignalID:       signal.SignalID,
+			Strategy:       signal.Strategy,
 			Venue:          signal.Venue,
 			Symbol:         leg.Symbol,
 			Side:           leg.Side,
 			InstrumentType: leg.InstrumentType,
 			OrderType:      leg.OrderType,
-			Price:          leg.Price,
-			Size:           leg.Size,
+			ReduceOnly:     leg.ReduceOnly,
+			Price:          compensatedPrice(leg, shift),
+			Size:           size,
 			IdempotencyKey: fmt.Sprintf("%s-leg-%d", signal.SignalID, i),
 		}
+		if leg.OrderType == domain.OrderTypeLimit {
+			// A tri-arb leg that does not fill now must not rest on the book.
+			req.TimeInForce = domain.TimeInForceIOC
+		}
 
+		mark := e.mark(signal.Venue, leg)
 		ord, err := e.submitWithRetry(execCtx, req)
 		if err != nil {
 			e.logger.Error("tri-arb leg failed",
 				"signal_id", signal.SignalID,
 				"leg", i,
 				"error", err)
-			e.abortCycle(ctx, allOrders)
+			e.unwindTriArb(ctx, signal, held, i, decimal.NewFromInt(1))
 			e.publishReport(signal, legExecutions, "aborted", startedAt, totalFees)
 			return
 		}
+		e.observeAck(signal, leg.Symbol)
+		e.recordDrift(signal.Venue, leg, mark)
 
-		allOrders = append(allOrders, ord)
-
-		slippageBps := decimal.Zero
-		if !leg.Price.IsZero() {
-			slippageBps = ord.AvgFillPrice.Sub(leg.Price).Div(leg.Price).Mul(decimal.NewFromInt(10000))
-		}
+		final := e.settle(ctx, execCtx, ord)
+		filled := filledSize(final)
 
 		legExec := domain.LegExecution{
 			Symbol:        leg.Symbol,
 			Side:          leg.Side,
 			ExpectedPrice: leg.Price,
-			ActualPrice:   ord.AvgFillPrice,
+			ActualPrice:   final.AvgFillPrice,
 			ExpectedSize:  leg.Size,
-			ActualSize:    ord.FilledSize,
-			SlippageBps:   slippageBps,
+			ActualSize:    filled,
+			SlippageBps:   slippageBps(leg.Price, final.AvgFillPrice, filled),
+			Fee:           e.orderFee(final),
 		}
 		legExecutions = append(legExecutions, legExec)
+		totalFees = totalFees.Add(legExec.Fee)
 
-		e.qualityTracker.RecordFill(leg.Symbol, string(leg.Side), leg.Price, ord.AvgFillPrice)
+		if !filled.IsPositive() {
+			e.logger.Warn("tri-arb leg did not fill, unwinding",
+				"signal_id", signal.SignalID,
+				"leg", i,
+				"status", final.Status)
+			e.unwindTriArb(ctx, signal, held, i, decimal.NewFromInt(1))
+			e.publishReport(signal, legExecutions, "aborted", startedAt, totalFees)
+			return
+		}
+		e.qualityTracker.RecordFill(leg.Symbol, string(leg.Side), leg.Price, final.AvgFillPrice)
+
+		if filled.LessThan(final.Size) {
+			e.logger.Warn("tri-arb leg partially filled, unwinding the remainder",
+				"signal_id", signal.SignalID,
+				"leg", i,
+				"size", final.Size.String(),
+				"filled", filled.String())
+			e.unwindTriArb(ctx, signal, held, i, decimal.NewFromInt(1).Sub(filled.Div(final.Size)))
+		}
+		held = append(held, heldLeg{leg: leg, size: filled})
+		scale = filled.Div(leg.Size)
 	}
 
 	e.publishReport(signal, legExecutions, "completed", startedAt, totalFees)
 }
 
-func (e *Engine) executeBasisArb(ctx context.Context, signal domain.TradeSignal, startedAt time.Time) {
-	timeout := e.basisArbFillTimeout
+// heldLeg is the part of a filled tri-arb leg's size whose proceeds are
+// still held by the cycle.
+type heldLeg struct {
+	leg  domain.LegSpec
+	size decimal.Decimal
+}
+
+// unwindTriArb reverses share of the held legs with market orders, last leg
+// first, so that part of the cycle ends back in the asset it started from.
+// stage is the leg that failed to take it, and held is reduced by what was
+// unwound.
+func (e *Engine) unwindTriArb(ctx context.Context, signal domain.TradeSignal, held []heldLeg, stage int, share decimal.Decimal) {
+	for k := len(held) - 1; k >= 0; k-- {
+		size := held[k].size.Mul(share)
+		if !size.IsPositive() {
+			continue
+		}
+		held[k].size = held[k].size.Sub(size)
+
+		leg := held[k].leg
+		_, err := e.submitWithRetry(ctx, domain.OrderRequest{
+			InternalID:     order.NewOrderID(),
+			SignalID:       signal.SignalID,
+			Strategy:       signal.Strategy,
+			Venue:          signal.Venue,
+			Symbol:         leg.Symbol,
+			Side:           reverseSide(leg.Side),
+			InstrumentType: leg.InstrumentType,
+			OrderType:      domain.OrderTypeMarket,
+			Size:           size,
+			IdempotencyKey: fmt.Sprintf("%s-unwind-%d-%d", signal.SignalID, stage, k),
+		})
+		if err != nil {
+			e.logger.Error("tri-arb unwind failed, position left open",
+				"signal_id", signal.SignalID,
+				"leg", k,
+				"size", size.String(),
+				"error", err)
+		}
+	}
+}
+
+func reverseSide(side domain.Side) domain.Side {
+	if side == domain.SideBuy {
+		return domain.SideSell
+	}
+	return domain.SideBuy
+}
+
+// fillPollInterval is how often a leg waiting to finish is read back from
+// the order manager.
+const fillPollInterval = 5 * time.Millisecond
+
+// settle waits for ord to reach a terminal state and returns its final
+// state. An order still open when execCtx ends is cancelled under ctx.
+func (e *Engine) settle(ctx, execCtx context.Context, ord *domain.Order) *domain.Order {
+	ticker := time.NewTicker(fillPollInterval)
+	defer ticker.Stop()
+	for {
+		cur := e.latest(ord)
+		if cur.Status.IsTerminal() {
+			return cur
+		}
+		select {
+		case <-execCtx.Done():
+			e.abortCycle(ctx, []*domain.Order{cur})
+			return e.latest(ord)
+		case <-ticker.C:
+		}
+	}
+}
+
+// filledSize is how much of ord filled. A venue can report an order filled
+// before, or without, the fill quantity; it then filled in full.
+func filledSize(ord *domain.Order) decimal.Decimal {
+	if ord.Status == domain.OrderStatusFilled && ord.FilledSize.IsZero() {
+		return ord.Size
+	}
+	return ord.FilledSize
+}
+
+// executeBasisArb sends both legs in one batch so the hedge goes out with
+// the entry instead of a round trip later. An accelerated cycle is never
+// worked passively.
+func (e *Engine) executeBasisArb(ctx context.Context, signal domain.TradeSignal, startedAt time.Time, accelerate bool) {
+	if spotLeg, perpLeg, ok := e.passiveLegs(signal); ok && !accelerate {
+		e.executeBasisPassive(ctx, signal, spotLeg, perpLeg, startedAt)
+		return
+	}
+	e.executeBatch(ctx, signal, startedAt, e.fillTimeout(signal, e.basisArbFillTimeout), "basis-arb", false)
+}
+
+// executeCrossVenueArb sends the buy and sell legs to their venues in one
+// batch. Limit legs are IOC: a leg left resting on one venue would leave the
+// other venue's fill unhedged.
+func (e *Engine) executeCrossVenueArb(ctx context.Context, signal domain.TradeSignal, startedAt time.Time) {
+	e.executeBatch(ctx, signal, startedAt, e.fillTimeout(signal, e.crossVenueArbFillTimeout), "cross-venue-arb", true)
+}
+
+// executeBatch submits signal's legs, each to its own venue, in one batch.
+// Legs the batch failed are retried one at a time before the cycle is given
+// up. name labels the strategy in logs. ioc makes limit legs IOC and waits
+// for the legs to finish, failing the cycle if they fill unevenly.
+func (e *Engine) executeBatch(ctx context.Context, signal domain.TradeSignal, startedAt time.Time, timeout time.Duration, name string, ioc bool) {
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	var legExecutions []domain.LegExecution
-	var allOrders []*domain.Order
 	totalFees := decimal.Zero
 
+	shift := e.driftShiftBps(signal)
+	reqs := make([]domain.OrderRequest, len(signal.Legs))
+	marks := make([]decimal.Decimal, len(signal.Legs))
 	for i, leg := range signal.Legs {
-		req := domain.OrderRequest{
+		reqs[i] = domain.OrderRequest{
 			InternalID:     order.NewOrderID(),
 			SignalID:       signal.SignalID,
-			Venue:          signal.Venue,
+			Strategy:       signal.Strategy,
+			Venue:          signal.LegVenue(i),
 			Symbol:         leg.Symbol,
 			Side:           leg.Side,
 			InstrumentType: leg.InstrumentType,
 			OrderType:      leg.OrderType,
-			Price:          leg.Price,
+			ReduceOnly:     leg.ReduceOnly,
+			Price:          compensatedPrice(leg, shift),
 			Size:           leg.Size,
 			IdempotencyKey: fmt.Sprintf("%s-leg-%d", signal.SignalID, i),
 		}
+		if ioc && leg.OrderType == domain.OrderTypeLimit {
+			reqs[i].TimeInForce = domain.TimeInForceIOC
+		}
+		marks[i] = e.mark(reqs[i].Venue, leg)
+	}
 
-		ord, err := e.submitWithRetry(execCtx, req)
+	results := e.orderMgr.SubmitOrders(execCtx, reqs)
+	allOrders := make([]*domain.Order, len(results))
+	for i, res := range results {
+		allOrders[i] = res.Order
+		if res.Err == nil {
+			e.observeAck(signal, reqs[i].Symbol)
+			e.recordDrift(reqs[i].Venue, signal.Legs[i], marks[i])
+		}
+	}
+
+	for i, res := range results {
+		if res.Err == nil {
+			continue
+		}
+		e.logger.Warn(name+" leg failed in batch, retrying alone",
+			"signal_id", signal.SignalID,
+			"leg", i,
+			"error", res.Err)
+
+		ord, err := e.submitWithRetry(execCtx, reqs[i])
 		if err != nil {
-			e.logger.Error("basis-arb leg failed",
+			e.logger.Error(name+" leg failed",
 				"signal_id", signal.SignalID,
 				"leg", i,
 				"error", err)
 			e.abortCycle(ctx, allOrders)
+			if ioc {
+				// The legs that went out may have filled before the
+				// cancels reached them.
+				e.unwindBatch(ctx, signal, name, e.settleBatch(ctx, execCtx, allOrders), decimal.Zero)
+			}
 			e.publishReport(signal, legExecutions, "aborted", startedAt, totalFees)
 			return
 		}
+		e.observeAck(signal, reqs[i].Symbol)
+		allOrders[i] = ord
+	}
 
-		allOrders = append(allOrders, ord)
-
-		slippageBps := decimal.Zero
-		if !leg.Price.IsZero() {
-			slippageBps = ord.AvgFillPrice.Sub(leg.Price).Div(leg.Price).Mul(decimal.NewFromInt(10000))
+	status := "completed"
+	if ioc {
+		// IOC legs are over within the timeout. Whatever one leg filled
+		// beyond the others is unhedged and is unwound.
+		allOrders = e.settleBatch(ctx, execCtx, allOrders)
+		matched := filledSize(allOrders[0])
+		for _, ord := range allOrders[1:] {
+			matched = decimal.Min(matched, filledSize(ord))
+		}
+		switch {
+		case e.unwindBatch(ctx, signal, name, allOrders, matched):
+			status = "failed"
+		case matched.IsZero():
+			status = "expired"
 		}
+	}
+
+	for i, leg := range signal.Legs {
+		ord := e.latest(allOrders[i])
+		filled := filledSize(ord)
 
 		legExec := domain.LegExecution{
 			Symbol:        leg.Symbol,
@@ -200,15 +615,73 @@ func (e *Engine) executeBasisArb(ctx context.Context, signal domain.TradeSignal,
 			ExpectedPrice: leg.Price,
 			ActualPrice:   ord.AvgFillPrice,
 			ExpectedSize:  leg.Size,
-			ActualSize:    ord.FilledSize,
-			SlippageBps:   slippageBps,
+			ActualSize:    filled,
+			SlippageBps:   slippageBps(leg.Price, ord.AvgFillPrice, filled),
+			Fee:           e.orderFee(ord),
 		}
 		legExecutions = append(legExecutions, legExec)
+		totalFees = totalFees.Add(legExec.Fee)
 
-		e.qualityTracker.RecordFill(leg.Symbol, string(leg.Side), leg.Price, ord.AvgFillPrice)
+		if filled.IsPositive() {
+			e.qualityTracker.RecordFill(leg.Symbol, string(leg.Side), leg.Price, ord.AvgFillPrice)
+		}
 	}
 
-	e.publishReport(signal, legExecutions, "completed", startedAt, totalFees)
+	e.publishReport(signal, legExecutions, status, startedAt, totalFees)
+}
+
+// settleBatch settles each of orders that was placed.
+func (e *Engine) settleBatch(ctx, execCtx context.Context, orders []*domain.Order) []*domain.Order {
+	settled := make([]*domain.Order, len(orders))
+	for i, ord := range orders {
+		if ord != nil {
+			settled[i] = e.settle(ctx, execCtx, ord)
+		}
+	}
+	return settled
+}
+
+// unwindBatch reverses what each of a batch's legs filled beyond matched
+// with a market order on its venue, and reports whether any leg had to be.
+func (e *Engine) unwindBatch(ctx context.Context, signal domain.TradeSignal, name string, orders []*domain.Order, matched decimal.Decimal) bool {
+	unwound := false
+	for i, ord := range orders {
+		if ord == nil {
+			continue
+		}
+		excess := filledSize(ord).Sub(matched)
+		if !excess.IsPositive() {
+			continue
+		}
+		unwound = true
+		e.logger.Warn(name+" legs filled unevenly, unwinding",
+			"signal_id", signal.SignalID,
+			"leg", i,
+			"venue", ord.Venue,
+			"excess", excess.String())
+
+		_, err := e.submitWithRetry(ctx, domain.OrderRequest{
+			InternalID:     order.NewOrderID(),
+			SignalID:       signal.SignalID,
+			Strategy:       signal.Strategy,
+			Venue:          ord.Venue,
+			Symbol:         ord.Symbol,
+			Side:           reverseSide(ord.Side),
+			InstrumentType: ord.InstrumentType,
+			OrderType:      domain.OrderTypeMarket,
+			Size:           excess,
+			IdempotencyKey: fmt.Sprintf("%s-unwind-%d", signal.SignalID, i),
+		})
+		if err != nil {
+			e.logger.Error(name+" unwind failed, position left open",
+				"signal_id", signal.SignalID,
+				"leg", i,
+				"venue", ord.Venue,
+				"size", excess.String(),
+				"error", err)
+		}
+	}
+	return unwound
 }
 
 func (e *Engine) submitWithRetry(ctx context.Context, req domain.OrderRequest) (*domain.Order, error) {
@@ -227,6 +700,12 @@ func (e *Engine) submitWithRetry(ctx context.Context, req domain.OrderRequest) (
 			return ord, nil
 		}
 
+		// A rejection that resending cannot fix, such as insufficient
+		// balance or a price off the tick size, ends the retries at once.
+		if category := gateway.ErrorCategoryOf(err); category.Final() {
+			return nil, fmt.Errorf("order rejected (%s): %w", category, err)
+		}
+
 		lastErr = err
 		e.logger.Warn("order submission failed, retrying",
 			"attempt", attempt+1,
@@ -237,16 +716,14 @@ func (e *Engine) submitWithRetry(ctx context.Context, req domain.OrderRequest) (
 }
 
 func (e *Engine) abortCycle(ctx context.Context, orders []*domain.Order) {
+	var ids []uuid.UUID
 	for _, ord := range orders {
 		if ord == nil || ord.Status.IsTerminal() {
 			continue
 		}
-		if err := e.orderMgr.CancelOrder(ctx, ord.InternalID); err != nil {
-			e.logger.Error("failed to cancel order during abort",
-				"order_id", ord.InternalID,
-				"error", err)
-		}
+		ids = append(ids, ord.InternalID)
 	}
+	e.orderMgr.CancelOrders(ctx, ids, "cycle abort")
 }
 
 func (e *Engine) publishReport(
@@ -298,3 +775,24 @@ func (e *Engine) KillSwitchHandler(ctx context.Context) func() {
 	}
 }
 
+// ScopedKillSwitchHandler cancels the open orders of a halted scope: every
+// order on its venue, or every order tagged with its strategy, narrowed to
+// its venue if it has one.
+func (e *Engine) ScopedKillSwitchHandler(ctx context.Context) func(risk.KillScope) {
+	return func(scope risk.KillScope) {
+		e.logger.Error("KILL SWITCH: cancelling orders", "scope", scope.String())
+		var err error
+		switch {
+		case scope.IsGlobal():
+			e.orderMgr.CancelAllOrders(ctx)
+		case scope.Strategy == "":
+			err = e.orderMgr.CancelVenueOrders(ctx, scope.Venue, "kill switch")
+		default:
+			err = e.orderMgr.CancelStrategyOrders(ctx, scope.Venue, scope.Strategy, "kill switch")
+		}
+		if err != nil {
+			e.logger.Error("KILL SWITCH: orders left open", "scope", scope.String(), "error", err)
+		}
+	}
+}
+
@@ -0,0 +1,449 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/gateway"
+	"github.com/crypto-trading/trading/internal/order"
+)
+
+func TestFillTimeoutByLiquidityTier(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	eng := NewEngine(nil, nil, eventbus.New(1, logger), 3*time.Second, 15*time.Second, 0, logger)
+
+	major := domain.TradeSignal{Strategy: domain.StrategyTriArb, Legs: []domain.LegSpec{
+		{Symbol: "BTC/USDT"}, {Symbol: "ETH/BTC"}, {Symbol: "ETH/USDT"},
+	}}
+	mixed := domain.TradeSignal{Strategy: domain.StrategyTriArb, Legs: []domain.LegSpec{
+		{Symbol: "BTC/USDT"}, {Symbol: "SOL/BTC"}, {Symbol: "SOL/USDT"},
+	}}
+
+	if got := eng.fillTimeout(major, 3*time.Second); got != 3*time.Second {
+		t.Errorf("expected strategy default without tiers, got %s", got)
+	}
+
+	eng.SetAssetFillTimeouts(domain.StrategyTriArb, map[string]time.Duration{
+		"BTC": 1500 * time.Millisecond,
+		"ETH": 1500 * time.Millisecond,
+	})
+	if got := eng.fillTimeout(major, 3*time.Second); got != 1500*time.Millisecond {
+		t.Errorf("expected majors timeout, got %s", got)
+	}
+	if got := eng.fillTimeout(mixed, 3*time.Second); got != 3*time.Second {
+		t.Errorf("expected the untiered alt leg to set the timeout, got %s", got)
+	}
+
+	basis := domain.TradeSignal{Strategy: domain.StrategyBasisArb, Legs: []domain.LegSpec{{Symbol: "BTC/USDT"}, {Symbol: "BTCUSDT"}}}
+	if got := eng.fillTimeout(basis, 15*time.Second); got != 15*time.Second {
+		t.Errorf("expected tiers to apply per strategy, got %s", got)
+	}
+}
+
+func TestOrderBudgetSkipsNearlyExhaustedVenues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	eng := NewEngine(nil, nil, eventbus.New(1, logger), 3*time.Second, 15*time.Second, 0, logger)
+
+	signal := domain.TradeSignal{Venue: "kcex", Legs: make([]domain.LegSpec, 3)}
+	if _, _, ok := eng.orderBudget(context.Background(), signal); !ok {
+		t.Error("expected signals to pass without a rate limit source")
+	}
+
+	remaining := 10.0
+	var blockedUntil time.Time
+	eng.SetRateLimitSource(func(_ context.Context, venue string) ([]domain.RateLimitStatus, error) {
+		return []domain.RateLimitStatus{
+			{Category: domain.EndpointOrderPlace, Remaining: remaining, Capacity: 20, BlockedUntil: blockedUntil},
+		}, nil
+	})
+
+	if _, _, ok := eng.orderBudget(context.Background(), signal); !ok {
+		t.Error("expected 10 requests to cover a 3-leg cycle")
+	}
+	remaining = 5
+	if available, need, ok := eng.orderBudget(context.Background(), signal); ok || need != 6 || available != 5 {
+		t.Errorf("expected 5 of 6 needed requests to skip the signal, got ok=%v available=%v need=%d", ok, available, need)
+	}
+	remaining, blockedUntil = 20, time.Now().Add(time.Minute)
+	if _, _, ok := eng.orderBudget(context.Background(), signal); ok {
+		t.Error("expected a throttled venue to skip the signal")
+	}
+}
+
+func TestOrderBudgetChecksEachVenueOfCrossVenueSignal(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	eng := NewEngine(nil, nil, eventbus.New(1, logger), 3*time.Second, 15*time.Second, 0, logger)
+
+	remaining := map[string]float64{"bybit": 10, "okx": 1}
+	eng.SetRateLimitSource(func(_ context.Context, venue string) ([]domain.RateLimitStatus, error) {
+		return []domain.RateLimitStatus{
+			{Category: domain.EndpointOrderPlace, Remaining: remaining[venue], Capacity: 20},
+		}, nil
+	})
+
+	signal := domain.TradeSignal{Venue: "bybit", Legs: []domain.LegSpec{{Symbol: "BTC/USDT"}, {Symbol: "BTCUSDT", Venue: "okx"}}}
+	if available, need, ok := eng.orderBudget(context.Background(), signal); ok || need != 2 || available != 1 {
+		t.Errorf("expected the perp venue's budget to skip the signal, got ok=%v available=%v need=%d", ok, available, need)
+	}
+	remaining["okx"] = 2
+	if _, _, ok := eng.orderBudget(context.Background(), signal); !ok {
+		t.Error("expected each venue's budget to cover its own leg")
+	}
+}
+
+func TestAckObserverMeasuresFromMarketData(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	eng := NewEngine(nil, nil, eventbus.New(1, logger), 3*time.Second, 15*time.Second, 0, logger)
+
+	var got []time.Duration
+	eng.SetAckObserver(func(_ domain.TradeSignal, symbol string, tickToAck time.Duration) {
+		if symbol != "BTC/USDT" {
+			t.Errorf("expected the leg's symbol, got %s", symbol)
+		}
+		got = append(got, tickToAck)
+	})
+
+	eng.observeAck(domain.TradeSignal{MarketDataTimestamp: time.Now().Add(-40 * time.Millisecond)}, "BTC/USDT")
+	eng.observeAck(domain.TradeSignal{}, "BTC/USDT")
+	if len(got) != 1 {
+		t.Fatalf("expected only the signal with a market data timestamp to be measured, got %d samples", len(got))
+	}
+	if got[0] < 40*time.Millisecond {
+		t.Errorf("expected at least 40ms from tick to ack, got %s", got[0])
+	}
+}
+
+func TestFundingActionTimesPerpLegs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	eng := NewEngine(nil, nil, eventbus.New(1, logger), 3*time.Second, 15*time.Second, 0, logger)
+
+	now := time.Date(2025, 3, 10, 7, 59, 50, 0, time.UTC)
+	snapshot := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	rate := decimal.NewFromFloat(0.0001)
+	signal := func(perpSide domain.Side) domain.TradeSignal {
+		spotSide := domain.SideBuy
+		if perpSide == domain.SideBuy {
+			spotSide = domain.SideSell
+		}
+		return domain.TradeSignal{Strategy: domain.StrategyBasisArb, Venue: "okx", Legs: []domain.LegSpec{
+			{Symbol: "BTC/USDT", Side: spotSide, InstrumentType: domain.InstrumentSpot, Size: decimal.NewFromInt(1)},
+			{Symbol: "BTCUSDT", Side: perpSide, InstrumentType: domain.InstrumentPerp, Size: decimal.NewFromInt(1)},
+		}}
+	}
+
+	if action, _ := eng.fundingAction(signal(domain.SideBuy), now); action != fundingNone {
+		t.Errorf("expected no timing without a config, got %v", action)
+	}
+
+	eng.SetFundingTiming(domain.StrategyBasisArb, FundingTimingConfig{
+		Window: 30 * time.Second, Settle: 2 * time.Second, Delay: true, Accelerate: true,
+	}, func(venue, symbol string) (*domain.FundingRate, bool) {
+		return &domain.FundingRate{Venue: venue, Symbol: symbol, Rate: rate, NextTime: snapshot}, true
+	})
+
+	// With a positive rate a long perp pays and a short one collects.
+	action, resume := eng.fundingAction(signal(domain.SideBuy), now)
+	if action != fundingDelay || !resume.Equal(snapshot.Add(2*time.Second)) {
+		t.Errorf("expected a long perp held until 2s after the snapshot, got %v until %s", action, resume)
+	}
+	if action, _ := eng.fundingAction(signal(domain.SideSell), now); action != fundingAccelerate {
+		t.Errorf("expected a short perp accelerated, got %v", action)
+	}
+	rate = rate.Neg()
+	if action, _ := eng.fundingAction(signal(domain.SideSell), now); action != fundingDelay {
+		t.Errorf("expected a short perp held when the rate is negative, got %v", action)
+	}
+
+	if action, _ := eng.fundingAction(signal(domain.SideSell), snapshot.Add(-time.Minute)); action != fundingNone {
+		t.Errorf("expected no timing outside the window, got %v", action)
+	}
+	if action, _ := eng.fundingAction(signal(domain.SideSell), snapshot.Add(time.Second)); action != fundingNone {
+		t.Errorf("expected no timing once the snapshot has passed, got %v", action)
+	}
+}
+
+// rejectGateway rejects every order with err, counting the attempts.
+type rejectGateway struct {
+	gateway.VenueGateway
+	err   error
+	calls int
+}
+
+func (g *rejectGateway) Name() string { return "okx" }
+
+func (g *rejectGateway) PlaceOrder(context.Context, domain.OrderRequest) (*domain.OrderAck, error) {
+	g.calls++
+	return nil, g.err
+}
+
+func TestSubmitWithRetryStopsOnFinalRejection(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(64, logger)
+
+	for _, tc := range []struct {
+		err   error
+		calls int
+	}{
+		{&gateway.VenueError{Venue: "okx", Code: "51008", Category: gateway.ErrorInsufficientBalance}, 1},
+		{&gateway.VenueError{Venue: "okx", Code: "51169", Category: gateway.ErrorReduceOnly}, 1},
+		{&gateway.VenueError{Venue: "okx", Code: "51000"}, 3},
+	} {
+		gw := &rejectGateway{err: tc.err}
+		orderMgr := order.NewManager(map[string]gateway.VenueGateway{"okx": gw}, bus, logger)
+		eng := NewEngine(orderMgr, nil, bus, time.Second, time.Second, 2, logger)
+		eng.retryBackoff = time.Millisecond
+
+		_, err := eng.submitWithRetry(context.Background(), domain.OrderRequest{
+			InternalID: order.NewOrderID(),
+			Venue:      "okx",
+			Symbol:     "BTC/USDT",
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(60000),
+			Size:       decimal.NewFromInt(1),
+		})
+		if !errors.Is(err, tc.err) {
+			t.Errorf("expected the venue error returned, got %v", err)
+		}
+		if gw.calls != tc.calls {
+			t.Errorf("%v: expected %d attempts, got %d", tc.err, tc.calls, gw.calls)
+		}
+	}
+}
+
+func TestExecuteCrossVenueArbSendsIOCLegsToEachVenue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	buyGw, sellGw := &fillGateway{name: "kcex"}, &fillGateway{name: "nobitex"}
+	orderMgr := order.NewManager(map[string]gateway.VenueGateway{"kcex": buyGw, "nobitex": sellGw}, bus, logger)
+	buyGw.mgr, sellGw.mgr = orderMgr, orderMgr
+	eng := NewEngine(orderMgr, nil, bus, time.Second, time.Second, 0, logger)
+	reports := bus.SubscribeExecutionReport()
+
+	eng.executeCrossVenueArb(context.Background(), crossVenueSignal(), time.Now())
+
+	for venue, gw := range map[string]*fillGateway{"kcex": buyGw, "nobitex": sellGw} {
+		placed := gw.sent()
+		if len(placed) != 1 || placed[0].Venue != venue || placed[0].TimeInForce != domain.TimeInForceIOC {
+			t.Errorf("expected one IOC leg on %s, got %+v", venue, placed)
+		}
+	}
+	select {
+	case report := <-reports:
+		if report.Status != "completed" || len(report.Legs) != 2 {
+			t.Errorf("expected a completed two-leg report, got %s with %d legs", report.Status, len(report.Legs))
+		}
+	default:
+		t.Fatal("no execution report published")
+	}
+}
+
+// fillGateway fills the orders it is sent by the fractions in fills, in
+// order, with orders past the end of fills filled in full. Like a venue's
+// order stream racing its REST ack, it reports each fill to mgr before
+// acking the order; an IOC or market order left short is cancelled.
+type fillGateway struct {
+	gateway.VenueGateway
+
+	name  string
+	mgr   *order.Manager
+	fills []decimal.Decimal
+
+	mu     sync.Mutex
+	placed []domain.OrderRequest
+}
+
+func (g *fillGateway) Name() string { return g.name }
+
+func (g *fillGateway) PlaceOrder(_ context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	g.mu.Lock()
+	g.placed = append(g.placed, req)
+	n := len(g.placed)
+	g.mu.Unlock()
+
+	share := decimal.NewFromInt(1)
+	if n <= len(g.fills) {
+		share = g.fills[n-1]
+	}
+	update := domain.OrderUpdate{
+		Venue:         g.name,
+		VenueID:       fmt.Sprintf("%s-%d", g.name, n),
+		ClientOrderID: req.IdempotencyKey,
+		Status:        domain.OrderStatusFilled,
+		FilledSize:    req.Size.Mul(share),
+		AvgFillPrice:  req.Price,
+		Timestamp:     time.Now(),
+	}
+	if share.LessThan(decimal.NewFromInt(1)) {
+		update.Status = domain.OrderStatusCancelled
+	}
+	g.mgr.HandleOrderUpdate(update)
+
+	return &domain.OrderAck{
+		InternalID: req.InternalID,
+		VenueID:    update.VenueID,
+		Status:     domain.OrderStatusAcknowledged,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+func (g *fillGateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, g.PlaceOrder)
+}
+
+func (g *fillGateway) CancelOrder(_ context.Context, orderID string) (*domain.CancelAck, error) {
+	return &domain.CancelAck{VenueID: orderID, Status: domain.OrderStatusCancelled}, nil
+}
+
+func (g *fillGateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.CancelOrder)
+}
+
+func (g *fillGateway) sent() []domain.OrderRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]domain.OrderRequest(nil), g.placed...)
+}
+
+func triArbSignal() domain.TradeSignal {
+	return domain.TradeSignal{
+		SignalID: uuid.New(),
+		Strategy: domain.StrategyTriArb,
+		Venue:    "kcex",
+		Legs: []domain.LegSpec{
+			{Symbol: "BTC/USDT", Side: domain.SideBuy, InstrumentType: domain.InstrumentSpot,
+				Price: decimal.NewFromInt(100000), Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeLimit},
+			{Symbol: "ETH/BTC", Side: domain.SideBuy, InstrumentType: domain.InstrumentSpot,
+				Price: decimal.RequireFromString("0.025"), Size: decimal.NewFromInt(40), OrderType: domain.OrderTypeLimit},
+			{Symbol: "ETH/USDT", Side: domain.SideSell, InstrumentType: domain.InstrumentSpot,
+				Price: decimal.NewFromInt(2510), Size: decimal.NewFromInt(40), OrderType: domain.OrderTypeLimit},
+		},
+	}
+}
+
+func TestExecuteTriArbAbortsWhenIOCLegFillsNothing(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	gw := &fillGateway{name: "kcex", fills: []decimal.Decimal{decimal.NewFromInt(1), decimal.Zero}}
+	orderMgr := order.NewManager(map[string]gateway.VenueGateway{"kcex": gw}, bus, logger)
+	gw.mgr = orderMgr
+	eng := NewEngine(orderMgr, nil, bus, time.Second, time.Second, 0, logger)
+	reports := bus.SubscribeExecutionReport()
+
+	eng.executeTriArb(context.Background(), triArbSignal(), time.Now())
+
+	// The BTC bought by leg 1 is sold back; leg 3 is never sent.
+	placed := gw.sent()
+	if len(placed) != 3 {
+		t.Fatalf("expected two legs and one unwind, got %d orders", len(placed))
+	}
+	unwind := placed[2]
+	if unwind.Symbol != "BTC/USDT" || unwind.Side != domain.SideSell || unwind.OrderType != domain.OrderTypeMarket ||
+		!unwind.Size.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected a market sell of 1 BTC/USDT, got %+v", unwind)
+	}
+	select {
+	case report := <-reports:
+		if report.Status != "aborted" || len(report.Legs) != 2 || !report.Legs[1].ActualSize.IsZero() {
+			t.Errorf("expected an aborted report with the unfilled leg, got %s with %d legs", report.Status, len(report.Legs))
+		}
+	default:
+		t.Fatal("no execution report published")
+	}
+}
+
+func TestExecuteTriArbSizesLegsFromFills(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	half := decimal.RequireFromString("0.5")
+	gw := &fillGateway{name: "kcex", fills: []decimal.Decimal{decimal.NewFromInt(1), half}}
+	orderMgr := order.NewManager(map[string]gateway.VenueGateway{"kcex": gw}, bus, logger)
+	gw.mgr = orderMgr
+	eng := NewEngine(orderMgr, nil, bus, time.Second, time.Second, 0, logger)
+	reports := bus.SubscribeExecutionReport()
+
+	eng.executeTriArb(context.Background(), triArbSignal(), time.Now())
+
+	// Leg 2 takes half the BTC: the other half is sold back and leg 3
+	// sells only the ETH that was bought.
+	placed := gw.sent()
+	if len(placed) != 4 {
+		t.Fatalf("expected three legs and one unwind, got %d orders", len(placed))
+	}
+	if unwind := placed[2]; unwind.Symbol != "BTC/USDT" || unwind.Side != domain.SideSell || !unwind.Size.Equal(half) {
+		t.Errorf("expected 0.5 BTC/USDT sold back, got %+v", unwind)
+	}
+	if last := placed[3]; last.Symbol != "ETH/USDT" || !last.Size.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("expected leg 3 sized to the 20 ETH bought, got %+v", last)
+	}
+	select {
+	case report := <-reports:
+		if report.Status != "completed" {
+			t.Errorf("expected a completed report, got %s", report.Status)
+		}
+	default:
+		t.Fatal("no execution report published")
+	}
+}
+
+func crossVenueSignal() domain.TradeSignal {
+	return domain.TradeSignal{
+		SignalID: uuid.New(),
+		Strategy: domain.StrategyCrossVenueArb,
+		Venue:    "kcex",
+		Legs: []domain.LegSpec{
+			{Symbol: "BTC/USDT", Side: domain.SideBuy, InstrumentType: domain.InstrumentSpot,
+				Price: decimal.NewFromInt(100000), Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeLimit},
+			{Venue: "nobitex", Symbol: "BTC/USDT", Side: domain.SideSell, InstrumentType: domain.InstrumentSpot,
+				Price: decimal.NewFromInt(100300), Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeLimit},
+		},
+	}
+}
+
+func TestExecuteCrossVenueArbUnwindsUnmatchedFill(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	buyGw := &fillGateway{name: "kcex"}
+	sellGw := &fillGateway{name: "nobitex", fills: []decimal.Decimal{decimal.Zero}}
+	orderMgr := order.NewManager(map[string]gateway.VenueGateway{"kcex": buyGw, "nobitex": sellGw}, bus, logger)
+	buyGw.mgr, sellGw.mgr = orderMgr, orderMgr
+	eng := NewEngine(orderMgr, nil, bus, time.Second, time.Second, 0, logger)
+	reports := bus.SubscribeExecutionReport()
+
+	eng.executeCrossVenueArb(context.Background(), crossVenueSignal(), time.Now())
+
+	// The sell missed, so the BTC bought on kcex is sold back there.
+	placed := buyGw.sent()
+	if len(placed) != 2 {
+		t.Fatalf("expected the buy and an unwind on kcex, got %d orders", len(placed))
+	}
+	if unwind := placed[1]; unwind.Side != domain.SideSell || unwind.OrderType != domain.OrderTypeMarket ||
+		!unwind.Size.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected a market sell of 1 BTC/USDT, got %+v", unwind)
+	}
+	if n := len(sellGw.sent()); n != 1 {
+		t.Errorf("expected only the sell leg on nobitex, got %d orders", n)
+	}
+	select {
+	case report := <-reports:
+		if report.Status != "failed" {
+			t.Errorf("expected a failed cycle, got %s", report.Status)
+		}
+		if len(report.Legs) != 2 || !report.Legs[0].ActualSize.Equal(decimal.NewFromInt(1)) || !report.Legs[1].ActualSize.IsZero() {
+			t.Errorf("expected the report to carry the real fills, got %+v", report.Legs)
+		}
+	default:
+		t.Fatal("no execution report published")
+	}
+}
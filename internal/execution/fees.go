@@ -0,0 +1,79 @@
+package execution
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/costmodel"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// FeeSource returns the fee tier a venue currently charges.
+type FeeSource func(venue string) (*domain.FeeTier, bool)
+
+// SetFeeSource makes execution reports carry each leg's fee at its venue's
+// tier: the maker rate for post-only orders, which only fill resting, and
+// the taker rate for everything else. A maker rebate is a negative fee and
+// lowers the cycle's total. Call before Run.
+func (e *Engine) SetFeeSource(fees FeeSource) {
+	e.fees = fees
+}
+
+// fee is what a fill of notional on venue costs as maker or taker, or zero
+// without a fee source.
+func (e *Engine) fee(venue string, notional decimal.Decimal, maker bool) decimal.Decimal {
+	if e.fees == nil {
+		return decimal.Zero
+	}
+	tier, ok := e.fees(venue)
+	if !ok {
+		return decimal.Zero
+	}
+	return tier.Fee(notional, maker)
+}
+
+// orderFee is what ord's fills so far cost.
+func (e *Engine) orderFee(ord *domain.Order) decimal.Decimal {
+	return e.fee(ord.Venue, ord.AvgFillPrice.Mul(ord.FilledSize), ord.PostOnly)
+}
+
+// sumFees totals the fees of legs.
+func sumFees(legs []domain.LegExecution) decimal.Decimal {
+	total := decimal.Zero
+	for _, leg := range legs {
+		total = total.Add(leg.Fee)
+	}
+	return total
+}
+
+// rebateEntry works passive basis entries below the minimum notional on
+// venues that pay makers.
+type rebateEntry struct {
+	minFillProbability decimal.Decimal
+	fills              costmodel.PassiveFillEstimator
+}
+
+// SetRebatePassiveEntry also works basis signals below the passive minimum
+// notional passively on venues whose maker fee is a rebate, as long as fills
+// estimates that a spot quote at the touch fills with at least
+// minFillProbability. Otherwise they cross and pay the taker fee. Needs
+// SetPassiveBasisEntry and SetFeeSource. Call before Run.
+func (e *Engine) SetRebatePassiveEntry(minFillProbability decimal.Decimal, fills costmodel.PassiveFillEstimator) {
+	e.rebate = &rebateEntry{minFillProbability: minFillProbability, fills: fills}
+}
+
+// rebatePassive reports whether spot, a basis spot leg on venue, is better
+// worked passively for the maker rebate.
+func (e *Engine) rebatePassive(venue string, spot domain.LegSpec) bool {
+	if e.rebate == nil || e.fees == nil {
+		return false
+	}
+	tier, ok := e.fees(venue)
+	if !ok || !tier.MakerRebate() {
+		return false
+	}
+	book, ok := e.passive.books(venue, spot.Symbol)
+	if !ok {
+		return false
+	}
+	return e.rebate.fills.PassiveFillProbability(venue, spot.Symbol, book).GreaterThanOrEqual(e.rebate.minFillProbability)
+}
@@ -0,0 +1,81 @@
+package execution
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// FundingSource returns a perp's latest funding rate, which carries the time
+// of its next funding snapshot.
+type FundingSource func(venue, symbol string) (*domain.FundingRate, bool)
+
+// FundingTimingConfig sets how a strategy's perp legs are timed around
+// funding snapshots. Only legs less than Window before a snapshot are
+// affected. With Delay, a cycle whose perp legs would pay the coming funding
+// waits until Settle after the snapshot. With Accelerate, a cycle whose perp
+// legs would collect it skips passive entry and crosses at once, so the
+// position is in place before the snapshot.
+type FundingTimingConfig struct {
+	Window     time.Duration
+	Settle     time.Duration
+	Delay      bool
+	Accelerate bool
+}
+
+type fundingAction int
+
+const (
+	fundingNone fundingAction = iota
+	fundingDelay
+	fundingAccelerate
+)
+
+// SetFundingTiming times strategy's perp legs around funding snapshots as
+// cfg describes, reading snapshot times from funding. Call before Run.
+func (e *Engine) SetFundingTiming(strategy domain.StrategyType, cfg FundingTimingConfig, funding FundingSource) {
+	e.fundingTiming[strategy] = cfg
+	e.funding = funding
+}
+
+// fundingAction decides how signal's perp legs are timed against the next
+// funding snapshot. A leg's funding is counted as if it were filled before
+// the snapshot: longs pay shorts when the rate is positive, so a buy pays
+// and a sell collects, whether it opens or closes a position. For delays it
+// also returns when the cycle may go ahead.
+func (e *Engine) fundingAction(signal domain.TradeSignal, now time.Time) (fundingAction, time.Time) {
+	cfg, ok := e.fundingTiming[signal.Strategy]
+	if !ok || e.funding == nil {
+		return fundingNone, time.Time{}
+	}
+
+	collected := decimal.Zero
+	var snapshot time.Time
+	for i, leg := range signal.Legs {
+		if leg.InstrumentType != domain.InstrumentPerp {
+			continue
+		}
+		rate, ok := e.funding(signal.LegVenue(i), leg.Symbol)
+		if !ok || !rate.NextTime.After(now) || rate.NextTime.Sub(now) > cfg.Window {
+			continue
+		}
+		flow := rate.Rate.Mul(leg.Size)
+		if leg.Side == domain.SideBuy {
+			flow = flow.Neg()
+		}
+		collected = collected.Add(flow)
+		if rate.NextTime.After(snapshot) {
+			snapshot = rate.NextTime
+		}
+	}
+
+	switch {
+	case collected.IsNegative() && cfg.Delay:
+		return fundingDelay, snapshot.Add(cfg.Settle)
+	case collected.IsPositive() && cfg.Accelerate:
+		return fundingAccelerate, time.Time{}
+	}
+	return fundingNone, time.Time{}
+}
@@ -0,0 +1,151 @@
+package execution
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// LatencyCompensationConfig sets how aggressive limit legs are priced ahead
+// of the market's drift while the order is in flight. Each venue's drift is
+// the median of its last Samples measurements; it is not applied until
+// MinSamples have been taken, and never by more than MaxBps.
+type LatencyCompensationConfig struct {
+	MaxBps     decimal.Decimal
+	Samples    int
+	MinSamples int
+}
+
+// latencyCompensation holds the drift measured per venue; nil disables it.
+type latencyCompensation struct {
+	cfg   LatencyCompensationConfig
+	books BookSource
+
+	mu    sync.Mutex
+	drift map[string][]decimal.Decimal // venue -> ring of adverse drift in bps
+	next  map[string]int
+}
+
+// SetLatencyCompensation makes the engine measure, per venue, how far the
+// mid moves against a leg between the decision to send it and its ack, and
+// move the limit price of later aggressive legs that far ahead: buys up,
+// sells down. The shift is capped at cfg.MaxBps and at the signal's expected
+// edge spread over its legs, so the cycle never pays away more than it
+// expects to make. Passive quotes and market orders are not shifted. Call
+// before Run.
+func (e *Engine) SetLatencyCompensation(cfg LatencyCompensationConfig, books BookSource) {
+	e.latencyComp = &latencyCompensation{
+		cfg:   cfg,
+		books: books,
+		drift: make(map[string][]decimal.Decimal),
+		next:  make(map[string]int),
+	}
+}
+
+// mark is the mid of leg's book when it is sent, or zero when the book is
+// unknown or compensation is off.
+func (e *Engine) mark(venue string, leg domain.LegSpec) decimal.Decimal {
+	if e.latencyComp == nil {
+		return decimal.Zero
+	}
+	book, ok := e.latencyComp.books(venue, leg.Symbol)
+	if !ok {
+		return decimal.Zero
+	}
+	mid, _ := book.MidPrice()
+	return mid
+}
+
+// recordDrift measures how far the mid has moved against leg since mark was
+// taken, now that the leg is acknowledged. A move in the leg's favour counts
+// as negative drift, so a market that only drifts at random estimates to
+// zero.
+func (e *Engine) recordDrift(venue string, leg domain.LegSpec, mark decimal.Decimal) {
+	lc := e.latencyComp
+	if lc == nil || !mark.IsPositive() || leg.OrderType != domain.OrderTypeLimit {
+		return
+	}
+	book, ok := lc.books(venue, leg.Symbol)
+	if !ok {
+		return
+	}
+	mid, ok := book.MidPrice()
+	if !ok {
+		return
+	}
+	drift := mid.Sub(mark).Div(mark).Mul(decimal.NewFromInt(10000))
+	if leg.Side == domain.SideSell {
+		drift = drift.Neg()
+	}
+	lc.add(venue, drift)
+}
+
+func (lc *latencyCompensation) add(venue string, driftBps decimal.Decimal) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	samples := lc.drift[venue]
+	if len(samples) < lc.cfg.Samples {
+		lc.drift[venue] = append(samples, driftBps)
+		return
+	}
+	i := lc.next[venue]
+	samples[i] = driftBps
+	lc.next[venue] = (i + 1) % len(samples)
+}
+
+// estimate is venue's median adverse drift over the decision-to-ack
+// latency, floored at zero, or ok=false until enough samples are in.
+func (lc *latencyCompensation) estimate(venue string) (decimal.Decimal, bool) {
+	lc.mu.Lock()
+	samples := append([]decimal.Decimal(nil), lc.drift[venue]...)
+	lc.mu.Unlock()
+	if len(samples) == 0 || len(samples) < lc.cfg.MinSamples {
+		return decimal.Zero, false
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].LessThan(samples[j]) })
+	mid := len(samples) / 2
+	median := samples[mid]
+	if len(samples)%2 == 0 {
+		median = samples[mid-1].Add(samples[mid]).Div(decimal.NewFromInt(2))
+	}
+	return decimal.Max(median, decimal.Zero), true
+}
+
+// driftShiftBps is how far each aggressive limit leg of signal is priced
+// ahead of the market: the venue's drift estimate, capped at MaxBps and at
+// an equal share of the expected edge across those legs.
+func (e *Engine) driftShiftBps(signal domain.TradeSignal) decimal.Decimal {
+	if e.latencyComp == nil {
+		return decimal.Zero
+	}
+	drift, ok := e.latencyComp.estimate(signal.Venue)
+	if !ok || !drift.IsPositive() {
+		return decimal.Zero
+	}
+	limitLegs := 0
+	for _, leg := range signal.Legs {
+		if leg.OrderType == domain.OrderTypeLimit {
+			limitLegs++
+		}
+	}
+	if limitLegs == 0 || !signal.ExpectedEdgeBps.IsPositive() {
+		return decimal.Zero
+	}
+	share := signal.ExpectedEdgeBps.Div(decimal.NewFromInt(int64(limitLegs)))
+	return decimal.Min(drift, e.latencyComp.cfg.MaxBps, share)
+}
+
+// compensatedPrice is leg's limit price moved shiftBps against the market.
+func compensatedPrice(leg domain.LegSpec, shiftBps decimal.Decimal) decimal.Decimal {
+	if leg.OrderType != domain.OrderTypeLimit || !shiftBps.IsPositive() {
+		return leg.Price
+	}
+	factor := shiftBps.Div(decimal.NewFromInt(10000))
+	if leg.Side == domain.SideSell {
+		factor = factor.Neg()
+	}
+	return leg.Price.Mul(decimal.NewFromInt(1).Add(factor))
+}
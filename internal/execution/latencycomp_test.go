@@ -0,0 +1,69 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestLatencyCompensationShiftsAggressiveLegs(t *testing.T) {
+	mid := decimal.NewFromInt(100)
+	books := func(venue, symbol string) (*domain.OrderBookSnapshot, bool) {
+		return &domain.OrderBookSnapshot{
+			Venue:  venue,
+			Symbol: symbol,
+			Bids:   []domain.PriceLevel{{Price: mid.Sub(decimal.NewFromFloat(0.01)), Size: decimal.NewFromInt(1)}},
+			Asks:   []domain.PriceLevel{{Price: mid.Add(decimal.NewFromFloat(0.01)), Size: decimal.NewFromInt(1)}},
+		}, true
+	}
+	e := &Engine{}
+	e.SetLatencyCompensation(LatencyCompensationConfig{
+		MaxBps:     decimal.NewFromInt(5),
+		Samples:    10,
+		MinSamples: 3,
+	}, books)
+
+	buy := domain.LegSpec{Symbol: "BTC/USDT", Side: domain.SideBuy, OrderType: domain.OrderTypeLimit, Price: decimal.NewFromInt(100)}
+	sell := domain.LegSpec{Symbol: "ETH/BTC", Side: domain.SideSell, OrderType: domain.OrderTypeLimit, Price: decimal.NewFromInt(100)}
+	signal := domain.TradeSignal{
+		Venue:           "kcex",
+		Legs:            []domain.LegSpec{buy, sell},
+		ExpectedEdgeBps: decimal.NewFromInt(20),
+	}
+
+	// Buys ack 2 bps after the mid moved up, sells 2 bps after it moved down.
+	for i := 0; i < 2; i++ {
+		mark := e.mark("kcex", buy)
+		mid = mark.Mul(decimal.NewFromFloat(1.0002))
+		e.recordDrift("kcex", buy, mark)
+	}
+	if shift := e.driftShiftBps(signal); !shift.IsZero() {
+		t.Fatalf("expected no shift before MinSamples, got %s", shift)
+	}
+	mark := e.mark("kcex", sell)
+	mid = mark.Mul(decimal.NewFromFloat(0.9998))
+	e.recordDrift("kcex", sell, mark)
+
+	shift := e.driftShiftBps(signal)
+	if !shift.Round(4).Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected a 2 bps shift, got %s", shift)
+	}
+	if got := compensatedPrice(buy, shift); !got.Equal(decimal.NewFromFloat(100.02)) {
+		t.Errorf("expected buy priced up to 100.02, got %s", got)
+	}
+	if got := compensatedPrice(sell, shift); !got.Equal(decimal.NewFromFloat(99.98)) {
+		t.Errorf("expected sell priced down to 99.98, got %s", got)
+	}
+	market := domain.LegSpec{Side: domain.SideBuy, OrderType: domain.OrderTypeMarket, Price: decimal.NewFromInt(100)}
+	if got := compensatedPrice(market, shift); !got.Equal(market.Price) {
+		t.Errorf("expected market legs left alone, got %s", got)
+	}
+
+	// The shift never spends more than the signal's edge across its legs.
+	signal.ExpectedEdgeBps = decimal.NewFromInt(2)
+	if shift := e.driftShiftBps(signal); !shift.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the shift capped at 1 bps of edge per leg, got %s", shift)
+	}
+}
@@ -0,0 +1,292 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+	"github.com/crypto-trading/trading/internal/order"
+)
+
+// BookSource returns the latest order book for a venue and symbol.
+type BookSource func(venue, symbol string) (*domain.OrderBookSnapshot, bool)
+
+// PassiveEntryConfig sets when and how basis entries are worked passively.
+type PassiveEntryConfig struct {
+	MinNotional decimal.Decimal
+	Refresh     time.Duration
+	Timeout     time.Duration
+}
+
+// passiveEntry holds the passive basis entry settings; nil disables it.
+type passiveEntry struct {
+	cfg   PassiveEntryConfig
+	books BookSource
+}
+
+// SetPassiveBasisEntry makes basis signals whose spot notional reaches
+// cfg.MinNotional rest the spot leg as a post-only quote at the touch instead
+// of crossing the spread, hedging each fill on the perp as it arrives. The
+// quote follows the touch but never past the signal's spot price, which the
+// edge was computed at. Call before Run.
+func (e *Engine) SetPassiveBasisEntry(cfg PassiveEntryConfig, books BookSource) {
+	e.passive = &passiveEntry{cfg: cfg, books: books}
+}
+
+// passiveLegs returns the spot and perp legs of a basis signal that should be
+// entered passively, or ok=false to execute it aggressively. Cross-venue
+// signals are always executed aggressively.
+func (e *Engine) passiveLegs(signal domain.TradeSignal) (spot, perp domain.LegSpec, ok bool) {
+	if e.passive == nil || len(signal.Legs) != 2 || signal.CrossVenue() {
+		return spot, perp, false
+	}
+	for _, leg := range signal.Legs {
+		switch leg.InstrumentType {
+		case domain.InstrumentSpot:
+			spot = leg
+		case domain.InstrumentPerp:
+			perp = leg
+		}
+	}
+	if spot.Symbol == "" || perp.Symbol == "" {
+		return spot, perp, false
+	}
+	if spot.Price.Mul(spot.Size).GreaterThanOrEqual(e.passive.cfg.MinNotional) {
+		return spot, perp, true
+	}
+	return spot, perp, e.rebatePassive(signal.Venue, spot)
+}
+
+// quotePrice is the passive price for leg: the best bid for a buy or best
+// ask for a sell, capped at the leg's own price. Without a usable book the
+// leg price itself is used.
+func (e *Engine) quotePrice(venue string, leg domain.LegSpec) decimal.Decimal {
+	book, ok := e.passive.books(venue, leg.Symbol)
+	if !ok {
+		return leg.Price
+	}
+	if leg.Side == domain.SideBuy {
+		if bid, ok := book.BestBid(); ok {
+			return decimal.Min(bid.Price, leg.Price)
+		}
+	} else {
+		if ask, ok := book.BestAsk(); ok {
+			return decimal.Max(ask.Price, leg.Price)
+		}
+	}
+	return leg.Price
+}
+
+// executeBasisPassive works the spot leg as a resting quote for the passive
+// timeout. Every refresh it hedges newly filled spot quantity with a market
+// order on the perp and moves the quote to the current touch. At the
+// timeout the unfilled remainder is cancelled and the last fills hedged.
+func (e *Engine) executeBasisPassive(ctx context.Context, signal domain.TradeSignal, spotLeg, perpLeg domain.LegSpec, startedAt time.Time) {
+	quoteCtx, cancel := context.WithTimeout(ctx, e.passive.cfg.Timeout)
+	defer cancel()
+
+	quote, err := e.orderMgr.SubmitOrder(quoteCtx, domain.OrderRequest{
+		InternalID:     order.NewOrderID(),
+		SignalID:       signal.SignalID,
+		Strategy:       signal.Strategy,
+		Venue:          signal.Venue,
+		Symbol:         spotLeg.Symbol,
+		Side:           spotLeg.Side,
+		InstrumentType: domain.InstrumentSpot,
+		OrderType:      domain.OrderTypeLimit,
+		TimeInForce:    domain.TimeInForceGTC,
+		PostOnly:       true,
+		Price:          e.quotePrice(signal.Venue, spotLeg),
+		Size:           spotLeg.Size,
+		IdempotencyKey: fmt.Sprintf("%s-quote", signal.SignalID),
+	})
+	if err != nil {
+		e.logger.Error("basis-arb passive quote failed",
+			"signal_id", signal.SignalID,
+			"error", err)
+		e.publishReport(signal, nil, "aborted", startedAt, decimal.Zero)
+		return
+	}
+	e.observeAck(signal, spotLeg.Symbol)
+
+	h := &hedger{engine: e, signal: signal, leg: perpLeg}
+	reprice := true
+
+	ticker := time.NewTicker(e.passive.cfg.Refresh)
+	defer ticker.Stop()
+
+working:
+	for {
+		select {
+		case <-quoteCtx.Done():
+			break working
+		case <-ticker.C:
+		}
+
+		cur, ok := e.orderMgr.GetOrder(quote.InternalID)
+		if !ok {
+			break working
+		}
+		if err := h.hedgeTo(ctx, cur.FilledSize); err != nil {
+			e.unhedged(ctx, signal, spotLeg, quote, h, err, startedAt)
+			return
+		}
+		if cur.Status.IsTerminal() {
+			break working
+		}
+
+		price := e.quotePrice(signal.Venue, spotLeg)
+		if !reprice || price.Equal(cur.Price) {
+			continue
+		}
+		if err := e.orderMgr.AmendOrder(quoteCtx, quote.InternalID, price, cur.Size); err != nil {
+			// Without amend the quote stays at its first price.
+			reprice = !errors.Is(err, gateway.ErrAmendUnsupported)
+			e.logger.Warn("basis-arb quote reprice failed",
+				"signal_id", signal.SignalID,
+				"price", price.String(),
+				"error", err)
+		}
+	}
+
+	if cur, ok := e.orderMgr.GetOrder(quote.InternalID); ok && !cur.Status.IsTerminal() {
+		if err := e.orderMgr.CancelOrder(ctx, quote.InternalID); err != nil {
+			e.logger.Error("basis-arb quote cancel failed",
+				"signal_id", signal.SignalID,
+				"error", err)
+		}
+	}
+
+	final := e.latest(quote)
+	if err := h.hedgeTo(ctx, final.FilledSize); err != nil {
+		e.unhedged(ctx, signal, spotLeg, quote, h, err, startedAt)
+		return
+	}
+
+	status := "completed"
+	if final.FilledSize.IsZero() {
+		status = "expired"
+	}
+	legs := e.passiveLegExecutions(spotLeg, final, h)
+	e.publishReport(signal, legs, status, startedAt, sumFees(legs))
+}
+
+// unhedged handles a hedge that could not be placed: the quote is pulled so
+// the exposure stops growing and the cycle is reported aborted.
+func (e *Engine) unhedged(ctx context.Context, signal domain.TradeSignal, spotLeg domain.LegSpec, quote *domain.Order, h *hedger, err error, startedAt time.Time) {
+	e.abortCycle(ctx, []*domain.Order{e.latest(quote)})
+	final := e.latest(quote)
+	e.logger.Error("basis-arb hedge failed, spot fill left unhedged",
+		"signal_id", signal.SignalID,
+		"filled", final.FilledSize.String(),
+		"hedged", h.hedged.String(),
+		"error", err)
+	legs := e.passiveLegExecutions(spotLeg, final, h)
+	e.publishReport(signal, legs, "aborted", startedAt, sumFees(legs))
+}
+
+// latest returns the order manager's current view of ord.
+func (e *Engine) latest(ord *domain.Order) *domain.Order {
+	if cur, ok := e.orderMgr.GetOrder(ord.InternalID); ok {
+		return cur
+	}
+	return ord
+}
+
+// hedger places perp market orders to keep the hedged size in line with the
+// spot quote's fills.
+type hedger struct {
+	engine   *Engine
+	signal   domain.TradeSignal
+	leg      domain.LegSpec
+	hedged   decimal.Decimal
+	notional decimal.Decimal // sum of hedge fill size × price
+	count    int
+}
+
+// hedgeTo sends a hedge for whatever of filled is not yet hedged and waits
+// for it to fill.
+func (h *hedger) hedgeTo(ctx context.Context, filled decimal.Decimal) error {
+	size := filled.Sub(h.hedged)
+	if !size.IsPositive() {
+		return nil
+	}
+
+	ord, err := h.engine.submitWithRetry(ctx, domain.OrderRequest{
+		InternalID:     order.NewOrderID(),
+		SignalID:       h.signal.SignalID,
+		Strategy:       h.signal.Strategy,
+		Venue:          h.signal.Venue,
+		Symbol:         h.leg.Symbol,
+		Side:           h.leg.Side,
+		InstrumentType: domain.InstrumentPerp,
+		OrderType:      domain.OrderTypeMarket,
+		ReduceOnly:     h.leg.ReduceOnly,
+		Size:           size,
+		IdempotencyKey: fmt.Sprintf("%s-hedge-%d", h.signal.SignalID, h.count),
+	})
+	h.count++
+	if err != nil {
+		return err
+	}
+
+	// Only what the hedge filled counts as hedged.
+	fillCtx, cancel := context.WithTimeout(ctx, h.engine.basisArbFillTimeout)
+	defer cancel()
+	final := h.engine.settle(ctx, fillCtx, ord)
+	done := filledSize(final)
+
+	h.hedged = h.hedged.Add(done)
+	price := final.AvgFillPrice
+	if price.IsZero() {
+		price = h.leg.Price
+	}
+	h.notional = h.notional.Add(done.Mul(price))
+	if done.LessThan(size) {
+		return fmt.Errorf("hedge filled %s of %s", done, size)
+	}
+	return nil
+}
+
+func (e *Engine) passiveLegExecutions(spotLeg domain.LegSpec, quote *domain.Order, h *hedger) []domain.LegExecution {
+	spot := domain.LegExecution{
+		Symbol:        spotLeg.Symbol,
+		Side:          spotLeg.Side,
+		ExpectedPrice: spotLeg.Price,
+		ActualPrice:   quote.AvgFillPrice,
+		ExpectedSize:  spotLeg.Size,
+		ActualSize:    quote.FilledSize,
+		SlippageBps:   slippageBps(spotLeg.Price, quote.AvgFillPrice, quote.FilledSize),
+		Fee:           e.orderFee(quote),
+	}
+
+	perpPrice := decimal.Zero
+	if h.hedged.IsPositive() {
+		perpPrice = h.notional.Div(h.hedged)
+	}
+	perp := domain.LegExecution{
+		Symbol:        h.leg.Symbol,
+		Side:          h.leg.Side,
+		ExpectedPrice: h.leg.Price,
+		ActualPrice:   perpPrice,
+		ExpectedSize:  h.leg.Size,
+		ActualSize:    h.hedged,
+		SlippageBps:   slippageBps(h.leg.Price, perpPrice, h.hedged),
+		Fee:           e.fee(h.signal.Venue, h.notional, false),
+	}
+	return []domain.LegExecution{spot, perp}
+}
+
+// slippageBps is the signed difference between the fill and expected price,
+// or zero when nothing filled.
+func slippageBps(expected, actual, filled decimal.Decimal) decimal.Decimal {
+	if expected.IsZero() || !filled.IsPositive() {
+		return decimal.Zero
+	}
+	return actual.Sub(expected).Div(expected).Mul(decimal.NewFromInt(10000))
+}
@@ -0,0 +1,288 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/gateway"
+	"github.com/crypto-trading/trading/internal/order"
+)
+
+// quoteGateway acks limit orders as resting and fills market orders
+// immediately, recording what it was sent. The embedded interface covers the
+// methods the engine never calls.
+type quoteGateway struct {
+	gateway.VenueGateway
+
+	mu      sync.Mutex
+	placed  []domain.OrderRequest
+	amends  []decimal.Decimal
+	cancels int
+}
+
+func (g *quoteGateway) Name() string { return "kcex" }
+
+func (g *quoteGateway) PlaceOrder(_ context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.placed = append(g.placed, req)
+	status := domain.OrderStatusAcknowledged
+	if req.OrderType == domain.OrderTypeMarket {
+		status = domain.OrderStatusFilled
+	}
+	return &domain.OrderAck{
+		InternalID: req.InternalID,
+		VenueID:    fmt.Sprintf("v-%d", len(g.placed)),
+		Status:     status,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+func (g *quoteGateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, g.PlaceOrder)
+}
+
+func (g *quoteGateway) AmendOrder(_ context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.amends = append(g.amends, newPrice)
+	return &domain.AmendAck{VenueID: orderID, Price: newPrice, Size: newSize, Status: domain.OrderStatusAcknowledged}, nil
+}
+
+func (g *quoteGateway) CancelOrder(_ context.Context, orderID string) (*domain.CancelAck, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cancels++
+	return &domain.CancelAck{VenueID: orderID, Status: domain.OrderStatusCancelled}, nil
+}
+
+func (g *quoteGateway) hedgeSizes() []decimal.Decimal {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var sizes []decimal.Decimal
+	for _, req := range g.placed {
+		if req.InstrumentType == domain.InstrumentPerp {
+			sizes = append(sizes, req.Size)
+		}
+	}
+	return sizes
+}
+
+func TestExecuteBasisPassive(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(64, logger)
+	gw := &quoteGateway{}
+	orderMgr := order.NewManager(map[string]gateway.VenueGateway{"kcex": gw}, bus, logger)
+	eng := NewEngine(orderMgr, nil, bus, time.Second, time.Second, 0, logger)
+	reports := bus.SubscribeExecutionReport()
+
+	var bookMu sync.Mutex
+	bid := decimal.NewFromInt(59990)
+	books := func(_, _ string) (*domain.OrderBookSnapshot, bool) {
+		bookMu.Lock()
+		defer bookMu.Unlock()
+		return &domain.OrderBookSnapshot{
+			Bids: []domain.PriceLevel{{Price: bid, Size: decimal.NewFromInt(1)}},
+			Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(60000), Size: decimal.NewFromInt(1)}},
+		}, true
+	}
+	eng.SetPassiveBasisEntry(PassiveEntryConfig{
+		MinNotional: decimal.NewFromInt(10000),
+		Refresh:     5 * time.Millisecond,
+		Timeout:     150 * time.Millisecond,
+	}, books)
+	eng.SetFeeSource(func(string) (*domain.FeeTier, bool) {
+		return &domain.FeeTier{MakerFeeBps: decimal.NewFromInt(-1), TakerFeeBps: decimal.NewFromInt(4)}, true
+	})
+
+	signal := domain.TradeSignal{
+		SignalID: uuid.New(),
+		Strategy: domain.StrategyBasisArb,
+		Venue:    "kcex",
+		Legs: []domain.LegSpec{
+			{Symbol: "BTC/USDT", Side: domain.SideBuy, InstrumentType: domain.InstrumentSpot,
+				Price: decimal.NewFromInt(60000), Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeLimit},
+			{Symbol: "BTCUSDT", Side: domain.SideSell, InstrumentType: domain.InstrumentPerp,
+				Price: decimal.NewFromInt(60300), Size: decimal.NewFromInt(1), OrderType: domain.OrderTypeLimit},
+		},
+	}
+	spotLeg, perpLeg, ok := eng.passiveLegs(signal)
+	if !ok {
+		t.Fatal("expected signal above the notional floor to be entered passively")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		eng.executeBasisPassive(context.Background(), signal, spotLeg, perpLeg, time.Now())
+		close(done)
+	}()
+
+	quoteID := waitForQuote(t, orderMgr, signal.SignalID)
+	orderMgr.UpdateOrderFill(quoteID, decimal.NewFromFloat(0.4), decimal.NewFromInt(59990))
+	time.Sleep(30 * time.Millisecond)
+
+	bookMu.Lock()
+	bid = decimal.NewFromInt(59995)
+	bookMu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	orderMgr.UpdateOrderFill(quoteID, decimal.NewFromFloat(0.7), decimal.NewFromInt(59993))
+
+	<-done
+
+	gw.mu.Lock()
+	quote := gw.placed[0]
+	amends := append([]decimal.Decimal(nil), gw.amends...)
+	cancels := gw.cancels
+	gw.mu.Unlock()
+
+	if !quote.PostOnly || !quote.Price.Equal(decimal.NewFromInt(59990)) {
+		t.Errorf("quote should rest post-only at the bid, got post_only=%v price=%s", quote.PostOnly, quote.Price)
+	}
+	if len(amends) == 0 || !amends[0].Equal(decimal.NewFromInt(59995)) {
+		t.Errorf("quote should follow the bid to 59995, amends %v", amends)
+	}
+	if cancels != 1 {
+		t.Errorf("expected the unfilled remainder cancelled once, got %d", cancels)
+	}
+
+	sizes := gw.hedgeSizes()
+	total := decimal.Zero
+	for _, s := range sizes {
+		total = total.Add(s)
+	}
+	if len(sizes) != 2 || !sizes[0].Equal(decimal.NewFromFloat(0.4)) || !total.Equal(decimal.NewFromFloat(0.7)) {
+		t.Errorf("expected hedges of 0.4 then 0.3, got %v", sizes)
+	}
+
+	select {
+	case report := <-reports:
+		if report.Status != "completed" {
+			t.Errorf("expected completed, got %s", report.Status)
+		}
+		if len(report.Legs) != 2 || !report.Legs[1].ActualSize.Equal(decimal.NewFromFloat(0.7)) {
+			t.Errorf("expected perp leg hedged 0.7, got %+v", report.Legs)
+		}
+		// The spot quote earns the maker rebate; the perp hedges pay taker on
+		// 0.7 at the leg price, as the test gateway reports no fill price.
+		if len(report.Legs) == 2 && (!report.Legs[0].Fee.IsNegative() || !report.Legs[1].Fee.Equal(decimal.RequireFromString("16.884"))) {
+			t.Errorf("expected a spot rebate and a 16.884 perp fee, got %s and %s", report.Legs[0].Fee, report.Legs[1].Fee)
+		}
+		if len(report.Legs) == 2 && !report.TotalFees.Equal(report.Legs[0].Fee.Add(report.Legs[1].Fee)) {
+			t.Errorf("total fees %s should sum the legs", report.TotalFees)
+		}
+	default:
+		t.Fatal("no execution report published")
+	}
+}
+
+func TestHedgerCountsOnlyFilledHedge(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	gw := &fillGateway{name: "kcex", fills: []decimal.Decimal{decimal.RequireFromString("0.4")}}
+	orderMgr := order.NewManager(map[string]gateway.VenueGateway{"kcex": gw}, bus, logger)
+	gw.mgr = orderMgr
+	eng := NewEngine(orderMgr, nil, bus, time.Second, time.Second, 0, logger)
+
+	h := &hedger{
+		engine: eng,
+		signal: domain.TradeSignal{SignalID: uuid.New(), Strategy: domain.StrategyBasisArb, Venue: "kcex"},
+		leg: domain.LegSpec{Symbol: "BTC/USDT", Side: domain.SideSell, InstrumentType: domain.InstrumentPerp,
+			Price: decimal.NewFromInt(60000), Size: decimal.NewFromInt(1)},
+	}
+	if err := h.hedgeTo(context.Background(), decimal.NewFromInt(1)); err == nil {
+		t.Error("expected a short hedge to be reported")
+	}
+	if !h.hedged.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("expected 0.4 hedged, got %s", h.hedged)
+	}
+
+	// The next call hedges the rest.
+	if err := h.hedgeTo(context.Background(), decimal.NewFromInt(1)); err != nil {
+		t.Fatalf("hedge: %v", err)
+	}
+	if sizes := gw.sent(); len(sizes) != 2 || !sizes[1].Size.Equal(decimal.RequireFromString("0.6")) {
+		t.Errorf("expected the 0.6 left to be hedged, got %+v", sizes)
+	}
+}
+
+func TestPassiveLegsBelowNotionalFloor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	eng := NewEngine(nil, nil, eventbus.New(1, logger), time.Second, time.Second, 0, logger)
+
+	signal := domain.TradeSignal{Legs: []domain.LegSpec{
+		{Symbol: "BTC/USDT", InstrumentType: domain.InstrumentSpot, Price: decimal.NewFromInt(60000), Size: decimal.NewFromFloat(0.01)},
+		{Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp, Price: decimal.NewFromInt(60300), Size: decimal.NewFromFloat(0.01)},
+	}}
+	if _, _, ok := eng.passiveLegs(signal); ok {
+		t.Error("passive entry should be off until configured")
+	}
+
+	eng.SetPassiveBasisEntry(PassiveEntryConfig{MinNotional: decimal.NewFromInt(10000)}, nil)
+	if _, _, ok := eng.passiveLegs(signal); ok {
+		t.Error("a 600 USDT entry is below the 10000 floor and should be taken aggressively")
+	}
+}
+
+type fixedFillEstimate decimal.Decimal
+
+func (p fixedFillEstimate) PassiveFillProbability(string, string, *domain.OrderBookSnapshot) decimal.Decimal {
+	return decimal.Decimal(p)
+}
+
+func TestPassiveLegsOnRebateVenue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	eng := NewEngine(nil, nil, eventbus.New(1, logger), time.Second, time.Second, 0, logger)
+
+	makerBps := decimal.NewFromInt(-1)
+	eng.SetFeeSource(func(string) (*domain.FeeTier, bool) {
+		return &domain.FeeTier{MakerFeeBps: makerBps, TakerFeeBps: decimal.NewFromInt(4)}, true
+	})
+	books := func(_, _ string) (*domain.OrderBookSnapshot, bool) { return &domain.OrderBookSnapshot{}, true }
+	eng.SetPassiveBasisEntry(PassiveEntryConfig{MinNotional: decimal.NewFromInt(10000)}, books)
+
+	signal := domain.TradeSignal{Venue: "kcex", Legs: []domain.LegSpec{
+		{Symbol: "BTC/USDT", InstrumentType: domain.InstrumentSpot, Price: decimal.NewFromInt(60000), Size: decimal.NewFromFloat(0.01)},
+		{Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp, Price: decimal.NewFromInt(60300), Size: decimal.NewFromFloat(0.01)},
+	}}
+
+	eng.SetRebatePassiveEntry(decimal.NewFromFloat(0.6), fixedFillEstimate(decimal.NewFromFloat(0.7)))
+	if _, _, ok := eng.passiveLegs(signal); !ok {
+		t.Error("a small entry on a rebate venue that is likely to fill should be worked passively")
+	}
+
+	eng.SetRebatePassiveEntry(decimal.NewFromFloat(0.8), fixedFillEstimate(decimal.NewFromFloat(0.7)))
+	if _, _, ok := eng.passiveLegs(signal); ok {
+		t.Error("a quote unlikely to fill should cross despite the rebate")
+	}
+
+	makerBps = decimal.NewFromInt(1)
+	eng.SetRebatePassiveEntry(decimal.NewFromFloat(0.6), fixedFillEstimate(decimal.NewFromFloat(0.7)))
+	if _, _, ok := eng.passiveLegs(signal); ok {
+		t.Error("without a rebate the notional floor applies")
+	}
+}
+
+func waitForQuote(t *testing.T, orderMgr *order.Manager, signalID uuid.UUID) uuid.UUID {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for _, o := range orderMgr.GetOrdersBySignal(signalID) {
+			if o.Symbol == "BTC/USDT" && o.Status == domain.OrderStatusAcknowledged {
+				return o.InternalID
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("quote was never placed")
+	return uuid.Nil
+}
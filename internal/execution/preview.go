@@ -0,0 +1,195 @@
+package execution
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/costmodel"
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/risk"
+)
+
+// Preview is what the engine would do with a signal right now. Every check
+// executeSignal applies is evaluated, so a preview lists all the reasons a
+// signal would be skipped, not only the first.
+type Preview struct {
+	SignalID     uuid.UUID           `json:"signal_id"`
+	Strategy     domain.StrategyType `json:"strategy"`
+	Venue        string              `json:"venue"`
+	GeneratedAt  time.Time           `json:"generated_at"`
+	WouldExecute bool                `json:"would_execute"`
+	SkipReasons  []string            `json:"skip_reasons,omitempty"`
+
+	Risk        RiskCheck         `json:"risk"`
+	Limits      []risk.LimitUsage `json:"limits"`
+	OrderBudget BudgetCheck       `json:"order_budget"`
+
+	Legs []LegPreview `json:"legs"`
+
+	// ExpectedEdgeBps is the signal's own estimate; CostBps sums the cost
+	// model's estimate over the legs, and NetEdgeBps is what is left.
+	ExpectedEdgeBps decimal.Decimal `json:"expected_edge_bps"`
+	CostBps         decimal.Decimal `json:"cost_bps"`
+	NetEdgeBps      decimal.Decimal `json:"net_edge_bps"`
+}
+
+// RiskCheck is the risk manager's verdict on a signal.
+type RiskCheck struct {
+	Approved bool                 `json:"approved"`
+	Reason   risk.RejectionReason `json:"reason,omitempty"`
+	Details  string               `json:"details,omitempty"`
+}
+
+// BudgetCheck is the venue's order placement budget against what a cycle
+// needs. Available is zero when the venue does not report its budget.
+type BudgetCheck struct {
+	Available float64 `json:"available"`
+	Needed    int     `json:"needed"`
+}
+
+// LegPreview prices one leg against the current book. ExpectedPrice is the
+// average over the levels the leg would take, capped at the limit price for
+// limit legs, and FillableSize how much of the leg those levels hold.
+// SlippageBps is ExpectedPrice's distance from the touch, against the leg.
+type LegPreview struct {
+	Symbol        string               `json:"symbol"`
+	Side          domain.Side          `json:"side"`
+	OrderType     domain.OrderType     `json:"order_type"`
+	Size          decimal.Decimal      `json:"size"`
+	LimitPrice    decimal.Decimal      `json:"limit_price"`
+	BookAvailable bool                 `json:"book_available"`
+	BestPrice     decimal.Decimal      `json:"best_price"`
+	ExpectedPrice decimal.Decimal      `json:"expected_price"`
+	FillableSize  decimal.Decimal      `json:"fillable_size"`
+	SlippageBps   decimal.Decimal      `json:"slippage_bps"`
+	Cost          *domain.CostEstimate `json:"cost,omitempty"`
+	CostError     string               `json:"cost_error,omitempty"`
+}
+
+// Previewer runs signals through the engine's pre-trade checks and prices
+// them against current books without placing anything.
+type Previewer struct {
+	engine *Engine
+	books  BookSource
+	costs  costmodel.CostModelService
+}
+
+// NewPreviewer creates a Previewer for engine's checks.
+func NewPreviewer(engine *Engine, books BookSource, costs costmodel.CostModelService) *Previewer {
+	return &Previewer{engine: engine, books: books, costs: costs}
+}
+
+// Preview evaluates signal. Nothing is submitted and no risk state changes.
+func (p *Previewer) Preview(ctx context.Context, signal domain.TradeSignal) *Preview {
+	e := p.engine
+	pv := &Preview{
+		SignalID:        signal.SignalID,
+		Strategy:        signal.Strategy,
+		Venue:           signal.Venue,
+		GeneratedAt:     time.Now(),
+		ExpectedEdgeBps: signal.ExpectedEdgeBps,
+		CostBps:         decimal.Zero,
+	}
+
+	if floor, ok := e.minAtomicity[signal.Strategy]; ok && signal.Atomicity.LessThan(floor) {
+		pv.SkipReasons = append(pv.SkipReasons, "atomicity "+signal.Atomicity.String()+" below floor "+floor.String())
+	}
+
+	available, need, ok := e.orderBudget(ctx, signal)
+	pv.OrderBudget = BudgetCheck{Available: available, Needed: need}
+	if !ok {
+		pv.SkipReasons = append(pv.SkipReasons, "venue order budget nearly exhausted")
+	}
+
+	result := e.riskMgr.ValidateSignal(signal)
+	pv.Risk = RiskCheck{Approved: result.Approved, Reason: result.Reason, Details: result.Details}
+	if !result.Approved {
+		pv.SkipReasons = append(pv.SkipReasons, "risk: "+string(result.Reason))
+	}
+	pv.Limits = e.riskMgr.LimitUsage(signal)
+
+	for i, leg := range signal.Legs {
+		lp := p.previewLeg(signal.LegVenue(i), leg)
+		if lp.Cost != nil {
+			pv.CostBps = pv.CostBps.Add(lp.Cost.TotalBps)
+		}
+		pv.Legs = append(pv.Legs, lp)
+	}
+	pv.NetEdgeBps = pv.ExpectedEdgeBps.Sub(pv.CostBps)
+
+	pv.WouldExecute = len(pv.SkipReasons) == 0
+	return pv
+}
+
+func (p *Previewer) previewLeg(venue string, leg domain.LegSpec) LegPreview {
+	lp := LegPreview{
+		Symbol:       leg.Symbol,
+		Side:         leg.Side,
+		OrderType:    leg.OrderType,
+		Size:         leg.Size,
+		LimitPrice:   leg.Price,
+		FillableSize: decimal.Zero,
+	}
+
+	if book, ok := p.books(venue, leg.Symbol); ok {
+		levels := book.Asks
+		if leg.Side == domain.SideSell {
+			levels = book.Bids
+		}
+		if len(levels) > 0 {
+			lp.BookAvailable = true
+			lp.BestPrice = levels[0].Price
+			limit := decimal.Zero
+			if leg.OrderType == domain.OrderTypeLimit {
+				limit = leg.Price
+			}
+			lp.ExpectedPrice, lp.FillableSize = walkLevels(levels, leg.Side, leg.Size, limit)
+			if lp.FillableSize.IsPositive() {
+				lp.SlippageBps = lp.ExpectedPrice.Sub(lp.BestPrice).Abs().Div(lp.BestPrice).Mul(decimal.NewFromInt(10000))
+			}
+		}
+	}
+
+	if p.costs != nil {
+		est, err := p.costs.EstimateCost(venue, leg.Symbol, leg.Side, leg.Size, leg.OrderType)
+		if err != nil {
+			lp.CostError = err.Error()
+		} else {
+			lp.Cost = &est
+		}
+	}
+	return lp
+}
+
+// walkLevels takes size from levels, best first, and returns the average
+// price and the size taken. A positive limit stops the walk at the first
+// level priced worse than it for side.
+func walkLevels(levels []domain.PriceLevel, side domain.Side, size, limit decimal.Decimal) (avgPrice, filled decimal.Decimal) {
+	remaining := size
+	notional := decimal.Zero
+	filled = decimal.Zero
+	for _, lvl := range levels {
+		if !remaining.IsPositive() {
+			break
+		}
+		if limit.IsPositive() {
+			if side == domain.SideBuy && lvl.Price.GreaterThan(limit) {
+				break
+			}
+			if side == domain.SideSell && lvl.Price.LessThan(limit) {
+				break
+			}
+		}
+		take := decimal.Min(remaining, lvl.Size)
+		notional = notional.Add(take.Mul(lvl.Price))
+		filled = filled.Add(take)
+		remaining = remaining.Sub(take)
+	}
+	if filled.IsZero() {
+		return decimal.Zero, filled
+	}
+	return notional.Div(filled), filled
+}
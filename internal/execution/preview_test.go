@@ -0,0 +1,91 @@
+package execution
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/marketdata"
+	"github.com/crypto-trading/trading/internal/risk"
+)
+
+type fixedCost struct{ bps decimal.Decimal }
+
+func (c fixedCost) EstimateCost(_, _ string, _ domain.Side, _ decimal.Decimal, _ domain.OrderType) (domain.CostEstimate, error) {
+	return domain.CostEstimate{FeeBps: c.bps, TotalBps: c.bps}, nil
+}
+
+func TestPreviewPricesLegsWithoutExecuting(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	md := marketdata.NewService(bus, time.Second, 5*time.Second, logger)
+	md.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "kcex",
+		Symbol: "BTC/USDT",
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(59990), Size: decimal.NewFromInt(1)}},
+		Asks: []domain.PriceLevel{
+			{Price: decimal.NewFromInt(60000), Size: decimal.RequireFromString("0.5")},
+			{Price: decimal.NewFromInt(60060), Size: decimal.RequireFromString("0.5")},
+			{Price: decimal.NewFromInt(60200), Size: decimal.NewFromInt(5)},
+		},
+	})
+	riskMgr := risk.NewManager(&config.RiskConfig{
+		MaxPosition:         map[string]decimal.Decimal{"BTC": decimal.NewFromInt(2)},
+		MaxNotionalPerVenue: map[string]decimal.Decimal{"kcex": decimal.NewFromInt(100000)},
+		DailyLossCapUSDT:    decimal.NewFromInt(1000),
+		MaxOpenOrders:       config.MaxOpenOrdersConfig{Global: 10, PerVenue: 10, PerSymbol: 10},
+	}, md, filepath.Join(t.TempDir(), "killswitch.json"), logger)
+
+	eng := NewEngine(nil, riskMgr, bus, time.Second, time.Second, 0, logger)
+	preview := NewPreviewer(eng, md.GetOrderBook, fixedCost{bps: decimal.NewFromInt(4)})
+
+	signal := domain.TradeSignal{
+		SignalID:        uuid.New(),
+		Strategy:        domain.StrategyTriArb,
+		Venue:           "kcex",
+		ExpectedEdgeBps: decimal.NewFromInt(15),
+		Legs: []domain.LegSpec{{
+			Symbol: "BTC/USDT", Side: domain.SideBuy, OrderType: domain.OrderTypeLimit,
+			Price: decimal.NewFromInt(60100), Size: decimal.NewFromInt(1),
+		}},
+	}
+
+	pv := preview.Preview(context.Background(), signal)
+	if !pv.WouldExecute || !pv.Risk.Approved {
+		t.Fatalf("expected the signal to pass, got skip reasons %v", pv.SkipReasons)
+	}
+	leg := pv.Legs[0]
+	if !leg.ExpectedPrice.Equal(decimal.NewFromInt(60030)) || !leg.FillableSize.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected 1 BTC at an average of 60030, got %s at %s", leg.FillableSize, leg.ExpectedPrice)
+	}
+	if !leg.SlippageBps.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected 5 bps from the touch, got %s", leg.SlippageBps)
+	}
+	if !pv.NetEdgeBps.Equal(decimal.NewFromInt(11)) {
+		t.Errorf("expected 15 - 4 = 11 bps net edge, got %s", pv.NetEdgeBps)
+	}
+	if len(pv.Limits) == 0 || pv.Limits[0].Limit != risk.RejectPositionLimit {
+		t.Errorf("expected the BTC position limit first, got %+v", pv.Limits)
+	}
+
+	signal.Legs[0].Size = decimal.NewFromInt(3)
+	eng.SetRateLimitSource(func(context.Context, string) ([]domain.RateLimitStatus, error) {
+		return []domain.RateLimitStatus{{Category: domain.EndpointOrderPlace, Remaining: 1, Capacity: 20}}, nil
+	})
+	pv = preview.Preview(context.Background(), signal)
+	if pv.WouldExecute || len(pv.SkipReasons) != 2 || pv.Risk.Reason != risk.RejectPositionLimit {
+		t.Errorf("expected both the budget and the position limit reported, got %v", pv.SkipReasons)
+	}
+	if !pv.Legs[0].FillableSize.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the walk to stop at the 60100 limit, got %s fillable", pv.Legs[0].FillableSize)
+	}
+}
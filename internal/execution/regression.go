@@ -0,0 +1,283 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// RegressionAlpha is the significance level below which a candidate
+// distribution that got worse is flagged as a regression.
+const RegressionAlpha = 0.05
+
+// Distribution summarises one metric's samples with nearest-rank percentiles.
+type Distribution struct {
+	N    int
+	Mean float64
+	P50  float64
+	P95  float64
+	P99  float64
+}
+
+// MetricComparison compares one metric between a baseline and a candidate
+// period. PValue is from a two-sided Mann-Whitney U test and is NaN when
+// either side has fewer than two samples. Regressed is set when the
+// candidate's median is higher (worse) and PValue is below RegressionAlpha.
+type MetricComparison struct {
+	Metric    string
+	Baseline  Distribution
+	Candidate Distribution
+	PValue    float64
+	Regressed bool
+}
+
+// GroupComparison holds the comparisons for one strategy on one venue. The
+// group covering every completed cycle has an empty Strategy and Venue.
+type GroupComparison struct {
+	Strategy domain.StrategyType
+	Venue    string
+	Latency  MetricComparison
+	Slippage MetricComparison
+}
+
+// Name returns "all" for the overall group and "strategy/venue" otherwise.
+func (g GroupComparison) Name() string {
+	if g.Strategy == "" && g.Venue == "" {
+		return "all"
+	}
+	return string(g.Strategy) + "/" + g.Venue
+}
+
+// RegressionReport compares execution latency and slippage between two sets
+// of execution reports, typically the periods before and after a deploy.
+type RegressionReport struct {
+	Groups []GroupComparison
+}
+
+// Regressed reports whether any metric of any group regressed.
+func (r RegressionReport) Regressed() bool {
+	for _, g := range r.Groups {
+		if g.Latency.Regressed || g.Slippage.Regressed {
+			return true
+		}
+	}
+	return false
+}
+
+type regressionKey struct {
+	strategy domain.StrategyType
+	venue    string
+}
+
+type regressionSamples struct {
+	latency  []float64
+	slippage []float64
+}
+
+// CompareReports builds a RegressionReport from the completed cycles in
+// baseline and candidate. Latency is each cycle's start to completion in
+// milliseconds. Slippage is taken per filled leg in basis points, signed so
+// that positive is worse for both buys and sells. Aborted cycles are left
+// out, as their latency measures the abort path.
+func CompareReports(baseline, candidate []domain.ExecutionReport) RegressionReport {
+	base := groupSamples(baseline)
+	cand := groupSamples(candidate)
+
+	keys := make([]regressionKey, 0, len(base)+len(cand))
+	for k := range base {
+		keys = append(keys, k)
+	}
+	for k := range cand {
+		if _, ok := base[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].strategy != keys[j].strategy {
+			return keys[i].strategy < keys[j].strategy
+		}
+		return keys[i].venue < keys[j].venue
+	})
+
+	var report RegressionReport
+	for _, k := range keys {
+		b, c := regressionSamples{}, regressionSamples{}
+		if s := base[k]; s != nil {
+			b = *s
+		}
+		if s := cand[k]; s != nil {
+			c = *s
+		}
+		report.Groups = append(report.Groups, GroupComparison{
+			Strategy: k.strategy,
+			Venue:    k.venue,
+			Latency:  compareMetric("latency_ms", b.latency, c.latency),
+			Slippage: compareMetric("slippage_bps", b.slippage, c.slippage),
+		})
+	}
+	return report
+}
+
+// groupSamples collects samples per strategy and venue, plus the overall
+// group under the zero key.
+func groupSamples(reports []domain.ExecutionReport) map[regressionKey]*regressionSamples {
+	groups := map[regressionKey]*regressionSamples{{}: {}}
+	for _, r := range reports {
+		if r.Status != "completed" {
+			continue
+		}
+		k := regressionKey{strategy: r.Strategy, venue: r.Venue}
+		if groups[k] == nil {
+			groups[k] = &regressionSamples{}
+		}
+		latency := float64(r.CompletedAt.Sub(r.StartedAt)) / float64(time.Millisecond)
+		var slippage []float64
+		for _, leg := range r.Legs {
+			if !leg.ActualSize.IsPositive() {
+				continue
+			}
+			bps := leg.SlippageBps.InexactFloat64()
+			if leg.Side == domain.SideSell {
+				bps = -bps
+			}
+			slippage = append(slippage, bps)
+		}
+		for _, s := range []*regressionSamples{groups[k], groups[regressionKey{}]} {
+			s.latency = append(s.latency, latency)
+			s.slippage = append(s.slippage, slippage...)
+		}
+	}
+	return groups
+}
+
+func compareMetric(metric string, baseline, candidate []float64) MetricComparison {
+	c := MetricComparison{
+		Metric:    metric,
+		Baseline:  summariseSamples(baseline),
+		Candidate: summariseSamples(candidate),
+		PValue:    mannWhitneyP(baseline, candidate),
+	}
+	c.Regressed = c.PValue < RegressionAlpha && c.Candidate.P50 > c.Baseline.P50
+	return c
+}
+
+// summariseSamples computes nearest-rank percentiles; it sorts samples in
+// place.
+func summariseSamples(samples []float64) Distribution {
+	if len(samples) == 0 {
+		return Distribution{}
+	}
+	sort.Float64s(samples)
+	rank := func(q float64) float64 {
+		i := int(math.Ceil(q*float64(len(samples)))) - 1
+		return samples[max(0, min(i, len(samples)-1))]
+	}
+	sum := 0.0
+	for _, v := range samples {
+		sum += v
+	}
+	return Distribution{
+		N:    len(samples),
+		Mean: sum / float64(len(samples)),
+		P50:  rank(0.50),
+		P95:  rank(0.95),
+		P99:  rank(0.99),
+	}
+}
+
+// mannWhitneyP returns the two-sided p-value of the Mann-Whitney U test for
+// a and b, using the normal approximation with tie and continuity
+// corrections. It returns NaN if either side has fewer than two samples and
+// 1 if every sample is tied.
+func mannWhitneyP(a, b []float64) float64 {
+	n1, n2 := len(a), len(b)
+	if n1 < 2 || n2 < 2 {
+		return math.NaN()
+	}
+
+	type sample struct {
+		v     float64
+		fromA bool
+	}
+	all := make([]sample, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range b {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Tied values share the average of their ranks.
+	var rankSumA, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankSumA += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	n := float64(n1 + n2)
+	u := rankSumA - float64(n1*(n1+1))/2
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := math.Max(math.Abs(u-mean)-0.5, 0) / math.Sqrt(variance)
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// WriteText writes the report as an aligned table, one row per group and
+// metric. Percentile columns show baseline -> candidate.
+func (r RegressionReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tMETRIC\tN\tMEAN\tP50\tP95\tP99\tP-VALUE\t")
+	for _, g := range r.Groups {
+		for _, m := range []MetricComparison{g.Latency, g.Slippage} {
+			verdict := ""
+			if m.Regressed {
+				verdict = "REGRESSED"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d -> %d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				g.Name(), m.Metric, m.Baseline.N, m.Candidate.N,
+				change(m.Baseline.Mean, m.Candidate.Mean, m),
+				change(m.Baseline.P50, m.Candidate.P50, m),
+				change(m.Baseline.P95, m.Candidate.P95, m),
+				change(m.Baseline.P99, m.Candidate.P99, m),
+				formatP(m.PValue), verdict)
+		}
+	}
+	return tw.Flush()
+}
+
+func change(before, after float64, m MetricComparison) string {
+	format := func(v float64, n int) string {
+		if n == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", v)
+	}
+	return format(before, m.Baseline.N) + " -> " + format(after, m.Candidate.N)
+}
+
+func formatP(p float64) string {
+	if math.IsNaN(p) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.4f", p)
+}
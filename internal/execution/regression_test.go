@@ -0,0 +1,102 @@
+package execution
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func cycle(strategy domain.StrategyType, venue string, latency time.Duration, sellSlippageBps int64) domain.ExecutionReport {
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	return domain.ExecutionReport{
+		Strategy: strategy,
+		Venue:    venue,
+		Status:   "completed",
+		Legs: []domain.LegExecution{
+			{Side: domain.SideSell, ActualSize: decimal.NewFromInt(1), SlippageBps: decimal.NewFromInt(sellSlippageBps)},
+			{Side: domain.SideBuy, ActualSize: decimal.Zero, SlippageBps: decimal.NewFromInt(500)},
+		},
+		StartedAt:   start,
+		CompletedAt: start.Add(latency),
+	}
+}
+
+func TestCompareReportsFlagsSlowerCandidate(t *testing.T) {
+	var baseline, candidate []domain.ExecutionReport
+	for i := 0; i < 30; i++ {
+		jitter := time.Duration(i%5) * time.Millisecond
+		baseline = append(baseline, cycle(domain.StrategyTriArb, "kcex", 100*time.Millisecond+jitter, -2))
+		candidate = append(candidate, cycle(domain.StrategyTriArb, "kcex", 140*time.Millisecond+jitter, -2))
+	}
+	aborted := cycle(domain.StrategyTriArb, "kcex", time.Minute, 0)
+	aborted.Status = "aborted"
+	candidate = append(candidate, aborted)
+
+	report := CompareReports(baseline, candidate)
+	if len(report.Groups) != 2 || report.Groups[0].Name() != "all" || report.Groups[1].Name() != "TRI_ARB/kcex" {
+		t.Fatalf("expected the overall group and TRI_ARB/kcex, got %+v", report.Groups)
+	}
+
+	g := report.Groups[1]
+	if g.Latency.Candidate.N != 30 {
+		t.Errorf("expected the aborted cycle left out, got %d candidate samples", g.Latency.Candidate.N)
+	}
+	if g.Latency.Baseline.P50 != 102 || g.Latency.Candidate.P50 != 142 {
+		t.Errorf("unexpected latency medians: %+v", g.Latency)
+	}
+	if !g.Latency.Regressed || g.Latency.PValue >= RegressionAlpha {
+		t.Errorf("expected a latency regression, got %+v", g.Latency)
+	}
+	if g.Slippage.Regressed || g.Slippage.Baseline.P50 != 2 || g.Slippage.Baseline.N != 30 {
+		t.Errorf("expected unchanged slippage of 2 bps against a sell, unfilled legs skipped, got %+v", g.Slippage)
+	}
+	if !report.Regressed() {
+		t.Error("expected the report to be regressed")
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.Contains(buf.String(), "REGRESSED") || !strings.Contains(buf.String(), "102.00 -> 142.00") {
+		t.Errorf("unexpected text report:\n%s", buf.String())
+	}
+}
+
+func TestCompareReportsFasterCandidateIsNotARegression(t *testing.T) {
+	var baseline, candidate []domain.ExecutionReport
+	for i := 0; i < 20; i++ {
+		baseline = append(baseline, cycle(domain.StrategyBasisArb, "okx", time.Duration(200+i)*time.Millisecond, 1))
+		candidate = append(candidate, cycle(domain.StrategyBasisArb, "okx", time.Duration(100+i)*time.Millisecond, 1))
+	}
+	report := CompareReports(baseline, candidate)
+	if report.Regressed() {
+		t.Errorf("expected no regression, got %+v", report.Groups)
+	}
+}
+
+func TestMannWhitneyP(t *testing.T) {
+	if p := mannWhitneyP([]float64{1}, []float64{1, 2}); !math.IsNaN(p) {
+		t.Errorf("expected NaN for a single sample, got %v", p)
+	}
+	if p := mannWhitneyP([]float64{5, 5, 5}, []float64{5, 5}); p != 1 {
+		t.Errorf("expected 1 when every sample is tied, got %v", p)
+	}
+	same := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	if p := mannWhitneyP(same, same); p < 0.9 {
+		t.Errorf("expected identical samples not to differ, got p=%v", p)
+	}
+	// Two fully separated groups of 10: U = 0, z = (50-0.5)/sqrt(175).
+	lo := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	hi := []float64{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	want := math.Erfc(49.5 / math.Sqrt(175) / math.Sqrt2)
+	if p := mannWhitneyP(lo, hi); math.Abs(p-want) > 1e-12 {
+		t.Errorf("got p=%v, want %v", p, want)
+	}
+}
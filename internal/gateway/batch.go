@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// PlaceResult is the outcome of one order in a PlaceOrders batch. Results are
+// returned in request order; exactly one of Ack and Err is set.
+type PlaceResult struct {
+	Ack *domain.OrderAck
+	Err error
+}
+
+// CancelResult is the outcome of one order in a CancelOrders batch.
+type CancelResult struct {
+	Ack *domain.CancelAck
+	Err error
+}
+
+// PlaceEach is the PlaceOrders fallback for venues without a batch endpoint:
+// it places the orders one at a time, in order.
+func PlaceEach(ctx context.Context, reqs []domain.OrderRequest, place func(context.Context, domain.OrderRequest) (*domain.OrderAck, error)) []PlaceResult {
+	results := make([]PlaceResult, len(reqs))
+	for i, req := range reqs {
+		results[i].Ack, results[i].Err = place(ctx, req)
+	}
+	return results
+}
+
+// CancelEach is the CancelOrders fallback for venues without a batch endpoint.
+func CancelEach(ctx context.Context, orderIDs []string, cancel func(context.Context, string) (*domain.CancelAck, error)) []CancelResult {
+	results := make([]CancelResult, len(orderIDs))
+	for i, id := range orderIDs {
+		results[i].Ack, results[i].Err = cancel(ctx, id)
+	}
+	return results
+}
@@ -0,0 +1,303 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// Gateway implements the VenueGateway interface for Binance.
+// Spot (BTC/USDT internal format) is served from the spot API and USD-M
+// perpetuals (BTCUSDT internal format) from the futures API; both use
+// HMAC-SHA256 (hex-encoded) query-string signatures with the X-MBX-APIKEY header.
+type Gateway struct {
+	spotWS    *wsClient
+	futuresWS *wsClient
+	rest      *restClient
+	logger    *slog.Logger
+}
+
+// New creates a new Binance gateway.
+// futuresWsURL and futuresRestURL may be empty, in which case only spot
+// symbols are available.
+func New(wsURL, restURL, futuresWsURL, futuresRestURL, apiKey, apiSecret string, logger *slog.Logger) *Gateway {
+	rl := gateway.NewRateLimiter()
+	rl.AddBucket(domain.EndpointPublicData, 50, 25)
+	rl.AddBucket(domain.EndpointPrivateData, 20, 10)
+	rl.AddBucket(domain.EndpointOrderPlace, 20, 10)
+	rl.AddBucket(domain.EndpointOrderCancel, 30, 15)
+	rl.AddBucket(domain.EndpointAccount, 10, 5)
+
+	g := &Gateway{
+		spotWS: newWSClient(wsURL, "spot", domain.BinanceSpotSymbolMap, logger),
+		rest:   newRESTClient(restURL, futuresRestURL, apiKey, apiSecret, rl, logger),
+		logger: logger,
+	}
+	if futuresWsURL != "" {
+		g.futuresWS = newWSClient(futuresWsURL, "perp", domain.BinanceFuturesSymbolMap, logger)
+	}
+	return g
+}
+
+func (g *Gateway) Name() string { return "binance" }
+
+// SetAPIErrorObserver implements gateway.APIErrorReporter.
+func (g *Gateway) SetAPIErrorObserver(fn gateway.APIErrorObserver) {
+	g.rest.retrier.OnError = fn
+}
+
+// SetWSReconnectObserver implements gateway.WSReconnectReporter.
+func (g *Gateway) SetWSReconnectObserver(fn gateway.WSReconnectObserver) {
+	for _, ws := range []*wsClient{g.spotWS, g.futuresWS} {
+		if ws != nil {
+			ws.onReconnect = func() { fn("binance") }
+		}
+	}
+}
+
+// SetLatencyObserver implements gateway.LatencyReporter. Stream delays are
+// measured against the venue-synced clock.
+func (g *Gateway) SetLatencyObserver(fn gateway.LatencyObserver) {
+	g.rest.retrier.OnLatency = fn
+	observe := gateway.WSLatency(func(stream string, venueTime time.Time) {
+		fn("binance", gateway.LatencyWS, stream, g.rest.clock.Now().Sub(venueTime))
+	})
+	for _, ws := range []*wsClient{g.spotWS, g.futuresWS} {
+		if ws != nil {
+			ws.latency = observe
+		}
+	}
+}
+
+// SetEgress implements gateway.EgressConfigurable.
+func (g *Gateway) SetEgress(e gateway.Egress) {
+	e.ApplyClient(g.rest.httpClient)
+	for _, ws := range []*wsClient{g.spotWS, g.futuresWS} {
+		if ws != nil {
+			ws.egress = e
+		}
+	}
+}
+
+// SetClockOffsetObserver implements gateway.ClockOffsetReporter.
+func (g *Gateway) SetClockOffsetObserver(fn gateway.ClockOffsetObserver) {
+	g.rest.clock.OnSync = fn
+}
+
+// SetSubAccount tags balances and positions with the Binance sub-account the
+// API key was issued under. Sub-account keys trade only their own wallets, so
+// no extra routing parameter is sent. Call before Connect.
+func (g *Gateway) SetSubAccount(id string) {
+	g.rest.subAccount = id
+}
+
+// SetAPIKeys implements gateway.KeyRotator.
+func (g *Gateway) SetAPIKeys(keys []gateway.APIKey) error {
+	return g.rest.keys.SetKeys(keys)
+}
+
+func (g *Gateway) Connect(ctx context.Context) error {
+	if g.rest.keys.Signed() {
+		go g.rest.clock.Run(ctx, gateway.ClockSyncInterval, g.logger)
+	}
+	if err := g.spotWS.connect(ctx); err != nil {
+		return err
+	}
+	if g.futuresWS != nil {
+		if err := g.futuresWS.connect(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Gateway) Close() error {
+	var errs []error
+	errs = append(errs, g.spotWS.close())
+	if g.futuresWS != nil {
+		errs = append(errs, g.futuresWS.close())
+	}
+	return errors.Join(errs...)
+}
+
+// Health implements gateway.VenueGateway. The venue counts as connected only
+// while every market's stream is up.
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	var states []*gateway.WSState
+	for _, ws := range []*wsClient{g.spotWS, g.futuresWS} {
+		if ws != nil {
+			states = append(states, &ws.state)
+		}
+	}
+	return gateway.ProbeHealth(ctx, "binance", g.rest.ping, states...)
+}
+
+// wsFor returns the stream connection serving the given internal symbol.
+func (g *Gateway) wsFor(symbol string) (*wsClient, error) {
+	if !domain.IsBinanceFutures(symbol) {
+		return g.spotWS, nil
+	}
+	if g.futuresWS == nil {
+		return nil, fmt.Errorf("binance futures not configured for %s", symbol)
+	}
+	return g.futuresWS, nil
+}
+
+// SubscribeOrderBook streams the symbol's depth diffs. They carry their
+// update id range, so the market data service seeds the book from
+// GetOrderBookSnapshot and resyncs it whenever a diff does not follow on.
+func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
+	ws, err := g.wsFor(symbol)
+	if err != nil {
+		return nil, err
+	}
+	venueSymbol := domain.MapBinanceSymbol(symbol)
+	ch := ws.subscribeOrderBook(venueSymbol)
+	if err := ws.subscribe(ctx, strings.ToLower(venueSymbol)+"@depth@100ms"); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
+	ws, err := g.wsFor(symbol)
+	if err != nil {
+		return nil, err
+	}
+	venueSymbol := domain.MapBinanceSymbol(symbol)
+	ch := ws.subscribeTrades(venueSymbol)
+	stream := "@trade"
+	if domain.IsBinanceFutures(symbol) {
+		stream = "@aggTrade"
+	}
+	if err := ws.subscribe(ctx, strings.ToLower(venueSymbol)+stream); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) SubscribeFunding(ctx context.Context, symbol string) (<-chan domain.FundingRate, error) {
+	if !domain.IsBinanceFutures(symbol) {
+		return nil, fmt.Errorf("binance funding only available for perp symbols, got %s", symbol)
+	}
+	ws, err := g.wsFor(symbol)
+	if err != nil {
+		return nil, err
+	}
+	venueSymbol := domain.MapBinanceSymbol(symbol)
+	ch := ws.subscribeFunding(venueSymbol)
+	if err := ws.subscribe(ctx, strings.ToLower(venueSymbol)+"@markPrice@1s"); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	return g.rest.placeOrder(ctx, req)
+}
+
+func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	return g.rest.cancelOrder(ctx, orderID)
+}
+
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrder(ctx, orderID)
+}
+
+// GetOrderByClientID implements gateway.ClientOrderLookup.
+func (g *Gateway) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrderByClientOrderID(ctx, symbol, clientOrderID)
+}
+
+// GetKlines implements gateway.MarketHistoryProvider.
+func (g *Gateway) GetKlines(ctx context.Context, symbol string, interval time.Duration, from, to time.Time) ([]domain.Kline, error) {
+	return g.rest.getKlines(ctx, symbol, interval, from, to)
+}
+
+// GetHistoricalTrades implements gateway.MarketHistoryProvider with
+// aggregate trades, at most an hour of them per call.
+func (g *Gateway) GetHistoricalTrades(ctx context.Context, symbol string, from, to time.Time) ([]domain.Trade, error) {
+	return g.rest.getHistoricalTrades(ctx, symbol, from, to)
+}
+
+// GetFundingHistory implements gateway.MarketHistoryProvider for futures
+// symbols.
+func (g *Gateway) GetFundingHistory(ctx context.Context, symbol string, from, to time.Time) ([]domain.FundingRate, error) {
+	return g.rest.getFundingHistory(ctx, symbol, from, to)
+}
+
+// PlaceOrders falls back to one request per order: only the futures market
+// has a batch endpoint, and legs usually span spot and futures.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, g.rest.placeOrder)
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.rest.cancelOrder)
+}
+
+// AmendOrder is unsupported: Binance spot can only reduce size in place
+// (order amend keep-priority), which is not enough to chase a price.
+func (g *Gateway) AmendOrder(_ context.Context, _ string, _, _ decimal.Decimal) (*domain.AmendAck, error) {
+	return nil, gateway.ErrAmendUnsupported
+}
+
+func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	return g.rest.getOpenOrders(ctx, symbol)
+}
+
+// SubscribeOrderUpdates is not implemented yet; fills are only seen via REST.
+func (g *Gateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
+
+func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	return g.rest.getBalances(ctx)
+}
+
+func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	if g.rest.futuresURL == "" {
+		return nil, nil
+	}
+	return g.rest.getPositions(ctx)
+}
+
+func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	return g.rest.getFeeTier(ctx)
+}
+
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return g.rest.getInstruments(ctx)
+}
+
+func (g *Gateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return g.rest.keys.Status(), nil
+}
+
+// GetOrderBookSnapshot implements gateway.OrderBookSnapshotProvider.
+func (g *Gateway) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	return g.rest.getOrderBook(ctx, symbol)
+}
+
+// Withdraw, GetDepositAddress and GetTransferStatus are not wired to the
+// /sapi/v1/capital endpoints yet.
+func (g *Gateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetDepositAddress(_ context.Context, _, _ string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
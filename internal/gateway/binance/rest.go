@@ -0,0 +1,900 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// recvWindow bounds how long after its timestamp a signed request stays valid.
+const recvWindow = "5000"
+
+// errorCategories maps the Binance spot and futures error codes callers
+// react to. -2010 is left out: spot uses it for any rejected order, from
+// insufficient balance to a duplicate client order ID.
+var errorCategories = map[int]gateway.ErrorCategory{
+	-1003: gateway.ErrorRateLimit,           // too many requests
+	-1015: gateway.ErrorRateLimit,           // too many new orders
+	-1022: gateway.ErrorAuth,                // invalid signature
+	-2014: gateway.ErrorAuth,                // API key format invalid
+	-2015: gateway.ErrorAuth,                // invalid API key, IP or permissions
+	-2018: gateway.ErrorInsufficientBalance, // futures balance insufficient
+	-2019: gateway.ErrorInsufficientBalance, // futures margin insufficient
+	-2011: gateway.ErrorOrderNotFound,       // cancel rejected, unknown order
+	-2013: gateway.ErrorOrderNotFound,       // order does not exist
+	-4014: gateway.ErrorInvalidPrice,        // price not a multiple of the tick size
+	-4016: gateway.ErrorInvalidPrice,        // price above the band
+	-4024: gateway.ErrorInvalidPrice,        // price below the band
+	-4003: gateway.ErrorInvalidSize,         // quantity not positive
+	-4164: gateway.ErrorInvalidSize,         // notional below the minimum
+	-4116: gateway.ErrorDuplicateOrder,      // client order ID is duplicated
+	-2022: gateway.ErrorReduceOnly,          // reduce-only order rejected
+	-1121: gateway.ErrorSymbolUnavailable,   // invalid symbol
+	-4140: gateway.ErrorSymbolUnavailable,   // invalid symbol status (futures)
+}
+
+type restClient struct {
+	spotURL    string
+	futuresURL string
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	// keys picks the API key, and with it the rate limit budget, for each
+	// request.
+	keys *gateway.KeyPool
+
+	// subAccount names the sub-account whose API key signs our requests.
+	subAccount string
+
+	// retrier retries transient REST failures and reports every failed attempt.
+	retrier gateway.RESTRetrier
+
+	// clock corrects the timestamp signed requests carry for drift from the
+	// venue's clock.
+	clock *gateway.ClockSync
+}
+
+func newRESTClient(spotURL, futuresURL, apiKey, apiSecret string, rl *gateway.RateLimiter, logger *slog.Logger) *restClient {
+	c := &restClient{
+		spotURL:    spotURL,
+		futuresURL: futuresURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:       10,
+				IdleConnTimeout:    90 * time.Second,
+				DisableCompression: true,
+			},
+		},
+		logger:  logger,
+		keys:    gateway.NewKeyPool(rl, gateway.APIKey{Key: apiKey, Secret: apiSecret}),
+		retrier: gateway.NewRESTRetrier("binance"),
+	}
+	c.clock = gateway.NewClockSync("binance", c.serverTime)
+	return c
+}
+
+// sign creates a hex-encoded HMAC-SHA256 signature of the query string.
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest sends a request to baseURL+path, retrying transient failures.
+func (c *restClient) doRequest(ctx context.Context, method, baseURL, path string, params url.Values, signed bool, category domain.EndpointCategory) ([]byte, error) {
+	var data []byte
+	err := c.retrier.Do(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, baseURL, path, params, signed, category)
+		return err
+	})
+	return data, err
+}
+
+// doRequestOnce is doRequest without retries, for order placements that
+// cannot be replayed.
+func (c *restClient) doRequestOnce(ctx context.Context, method, baseURL, path string, params url.Values, signed bool, category domain.EndpointCategory) ([]byte, error) {
+	var data []byte
+	err := c.retrier.Once(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, baseURL, path, params, signed, category)
+		return err
+	})
+	return data, err
+}
+
+// send makes one request attempt. All parameters travel in the query string;
+// signed requests append timestamp, recvWindow and signature.
+func (c *restClient) send(ctx context.Context, method, baseURL, path string, params url.Values, signed bool, category domain.EndpointCategory) ([]byte, error) {
+	waited := time.Now()
+	key, err := c.keys.Acquire(ctx, category, 1)
+	c.retrier.ObserveRateLimitWait(category, time.Since(waited))
+	if err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	if params == nil {
+		params = url.Values{}
+	}
+	query := params.Encode()
+	if signed {
+		params.Set("timestamp", strconv.FormatInt(c.clock.Now().UnixMilli(), 10))
+		params.Set("recvWindow", recvWindow)
+		query = params.Encode()
+		query += "&signature=" + sign(key.Secret, query)
+	}
+
+	reqURL := baseURL + path
+	if query != "" {
+		reqURL += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if key.Key != "" {
+		req.Header.Set("X-MBX-APIKEY", key.Key)
+	}
+
+	sent := time.Now()
+	resp, err := c.httpClient.Do(req)
+	c.retrier.ObserveLatency(category, time.Since(sent))
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	key.Limiter.ObserveResponse(category, resp)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if gateway.RetryableStatus(resp.StatusCode) {
+		return nil, gateway.NewHTTPError(resp, respBody)
+	}
+	if resp.StatusCode >= 400 {
+		// Binance reports failures as {"code": -1121, "msg": "..."}
+		var apiErr struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Code != 0 {
+			return nil, &gateway.VenueError{
+				Venue:    "binance",
+				Op:       "API error",
+				Code:     strconv.Itoa(apiErr.Code),
+				Message:  apiErr.Msg,
+				Category: errorCategories[apiErr.Code],
+			}
+		}
+		return nil, gateway.NewHTTPError(resp, respBody)
+	}
+
+	return respBody, nil
+}
+
+// market returns the base URL and API path prefix for an internal symbol.
+func (c *restClient) market(symbol string) (baseURL, prefix string, futures bool) {
+	if domain.IsBinanceFutures(symbol) {
+		return c.futuresURL, "/fapi/v1", true
+	}
+	return c.spotURL, "/api/v3", false
+}
+
+// formatVenueOrderID encodes the market and symbol alongside the Binance
+// order ID, since cancellation requires both.
+func formatVenueOrderID(futures bool, venueSymbol string, orderID int64) string {
+	market := "spot"
+	if futures {
+		market = "perp"
+	}
+	return fmt.Sprintf("%s:%s:%d", market, venueSymbol, orderID)
+}
+
+// parseVenueOrderID is the inverse of formatVenueOrderID.
+func parseVenueOrderID(venueID string) (futures bool, venueSymbol, orderID string, err error) {
+	parts := strings.SplitN(venueID, ":", 3)
+	if len(parts) != 3 || (parts[0] != "spot" && parts[0] != "perp") {
+		return false, "", "", fmt.Errorf("invalid binance order id %q", venueID)
+	}
+	return parts[0] == "perp", parts[1], parts[2], nil
+}
+
+func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	if req.OrderType.IsStop() {
+		// Stop orders are not mapped yet.
+		return nil, fmt.Errorf("%w: binance %s", gateway.ErrOrderTypeUnsupported, req.OrderType)
+	}
+
+	venueSymbol := domain.MapBinanceSymbol(req.Symbol)
+	baseURL, prefix, futures := c.market(req.Symbol)
+
+	side := "BUY"
+	if req.Side == domain.SideSell {
+		side = "SELL"
+	}
+
+	params := url.Values{}
+	params.Set("symbol", venueSymbol)
+	params.Set("side", side)
+	params.Set("quantity", req.Size.String())
+	params.Set("newClientOrderId", req.IdempotencyKey)
+
+	switch {
+	case req.OrderType == domain.OrderTypeLimit && req.PostOnly && !futures:
+		// Spot has a dedicated maker-only order type with no time in force.
+		params.Set("type", "LIMIT_MAKER")
+		params.Set("price", req.Price.String())
+	case req.OrderType == domain.OrderTypeLimit:
+		tif := "GTC"
+		if req.PostOnly {
+			tif = "GTX"
+		} else if req.TimeInForce != "" {
+			tif = string(req.TimeInForce)
+		}
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", tif)
+		params.Set("price", req.Price.String())
+	default:
+		params.Set("type", "MARKET")
+	}
+	if req.ReduceOnly && futures {
+		params.Set("reduceOnly", "true")
+	}
+
+	send := c.doRequest
+	if !gateway.Replayable(req) {
+		send = c.doRequestOnce
+	}
+	data, err := send(ctx, "POST", baseURL, prefix+"/order", params, true, domain.EndpointOrderPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		OrderID int64 `json:"orderId"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse order response: %w", err)
+	}
+
+	return &domain.OrderAck{
+		InternalID: req.InternalID,
+		VenueID:    formatVenueOrderID(futures, venueSymbol, result.OrderID),
+		Status:     domain.OrderStatusAcknowledged,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+func (c *restClient) cancelOrder(ctx context.Context, venueID string) (*domain.CancelAck, error) {
+	futures, venueSymbol, orderID, err := parseVenueOrderID(venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, prefix := c.spotURL, "/api/v3"
+	if futures {
+		baseURL, prefix = c.futuresURL, "/fapi/v1"
+	}
+
+	params := url.Values{}
+	params.Set("symbol", venueSymbol)
+	params.Set("orderId", orderID)
+
+	if _, err := c.doRequest(ctx, "DELETE", baseURL, prefix+"/order", params, true, domain.EndpointOrderCancel); err != nil {
+		return nil, err
+	}
+
+	return &domain.CancelAck{
+		VenueID:   venueID,
+		Status:    domain.OrderStatusCancelled,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// getOrder looks up one order. Spot reports the quote amount filled and
+// futures an average price, so the fill price is derived accordingly.
+func (c *restClient) getOrder(ctx context.Context, venueID string) (*domain.OrderUpdate, error) {
+	futures, venueSymbol, orderID, err := parseVenueOrderID(venueID)
+	if err != nil {
+		return nil, err
+	}
+	update, err := c.queryOrder(ctx, futures, venueSymbol, "orderId", orderID)
+	if err != nil {
+		return nil, err
+	}
+	update.VenueID = venueID
+	return update, nil
+}
+
+// getOrderByClientOrderID looks up the order placed on symbol with
+// clientOrderID.
+func (c *restClient) getOrderByClientOrderID(ctx context.Context, symbol, clientOrderID string) (*domain.OrderUpdate, error) {
+	return c.queryOrder(ctx, domain.IsBinanceFutures(symbol), domain.MapBinanceSymbol(symbol), "origClientOrderId", clientOrderID)
+}
+
+// queryOrder fetches the order whose orderId or origClientOrderId, as key
+// says, is id.
+func (c *restClient) queryOrder(ctx context.Context, futures bool, venueSymbol, key, id string) (*domain.OrderUpdate, error) {
+	baseURL, prefix := c.spotURL, "/api/v3"
+	if futures {
+		baseURL, prefix = c.futuresURL, "/fapi/v1"
+	}
+
+	params := url.Values{}
+	params.Set("symbol", venueSymbol)
+	params.Set(key, id)
+
+	data, err := c.doRequest(ctx, "GET", baseURL, prefix+"/order", params, true, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+
+	var o struct {
+		OrderID             int64  `json:"orderId"`
+		ClientOrderID       string `json:"clientOrderId"`
+		ExecutedQty         string `json:"executedQty"`
+		CummulativeQuoteQty string `json:"cummulativeQuoteQty"`
+		AvgPrice            string `json:"avgPrice"`
+		Status              string `json:"status"`
+		UpdateTime          int64  `json:"updateTime"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, fmt.Errorf("parse order: %w", err)
+	}
+
+	update := &domain.OrderUpdate{
+		Venue:         "binance",
+		VenueID:       formatVenueOrderID(futures, venueSymbol, o.OrderID),
+		ClientOrderID: o.ClientOrderID,
+		Timestamp:     time.Now(),
+	}
+	if o.UpdateTime > 0 {
+		update.Timestamp = time.UnixMilli(o.UpdateTime)
+	}
+	update.FilledSize, _ = domain.ParseDecimal(o.ExecutedQty)
+	if futures {
+		update.AvgFillPrice, _ = domain.ParseDecimal(o.AvgPrice)
+	} else if update.FilledSize.IsPositive() {
+		quote, _ := domain.ParseDecimal(o.CummulativeQuoteQty)
+		update.AvgFillPrice = quote.Div(update.FilledSize)
+	}
+
+	switch o.Status {
+	case "NEW":
+		update.Status = domain.OrderStatusAcknowledged
+	case "PARTIALLY_FILLED":
+		update.Status = domain.OrderStatusPartialFill
+	case "FILLED":
+		update.Status = domain.OrderStatusFilled
+	case "CANCELED", "EXPIRED", "EXPIRED_IN_MATCH":
+		update.Status = domain.OrderStatusCancelled
+	case "REJECTED":
+		update.Status = domain.OrderStatusRejected
+	default:
+		return nil, fmt.Errorf("unknown binance order status %q", o.Status)
+	}
+	return update, nil
+}
+
+func (c *restClient) getOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	venueSymbol := domain.MapBinanceSymbol(symbol)
+	baseURL, prefix, futures := c.market(symbol)
+
+	params := url.Values{}
+	params.Set("symbol", venueSymbol)
+
+	data, err := c.doRequest(ctx, "GET", baseURL, prefix+"/openOrders", params, true, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		OrderID     int64  `json:"orderId"`
+		Symbol      string `json:"symbol"`
+		Side        string `json:"side"`
+		Type        string `json:"type"`
+		Price       string `json:"price"`
+		OrigQty     string `json:"origQty"`
+		ExecutedQty string `json:"executedQty"`
+		Status      string `json:"status"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse open orders: %w", err)
+	}
+
+	orders := make([]domain.Order, 0, len(result))
+	for _, o := range result {
+		side := domain.SideBuy
+		if o.Side == "SELL" {
+			side = domain.SideSell
+		}
+
+		orderType := domain.OrderTypeLimit
+		if o.Type == "MARKET" {
+			orderType = domain.OrderTypeMarket
+		}
+
+		status := domain.OrderStatusAcknowledged
+		if o.Status == "PARTIALLY_FILLED" {
+			status = domain.OrderStatusPartialFill
+		}
+
+		order := domain.Order{
+			VenueID:   formatVenueOrderID(futures, o.Symbol, o.OrderID),
+			Venue:     "binance",
+			Symbol:    symbol,
+			Side:      side,
+			OrderType: orderType,
+			Status:    status,
+		}
+		order.Price, _ = domain.ParseDecimal(o.Price)
+		order.Size, _ = domain.ParseDecimal(o.OrigQty)
+		order.FilledSize, _ = domain.ParseDecimal(o.ExecutedQty)
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (c *restClient) getBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	data, err := c.doRequest(ctx, "GET", c.spotURL, "/api/v3/account", nil, true, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var account struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("parse account: %w", err)
+	}
+
+	balances := make(map[string]domain.Balance, len(account.Balances))
+	for _, b := range account.Balances {
+		bal := domain.Balance{
+			Venue:   "binance",
+			Account: c.subAccount,
+			Asset:   b.Asset,
+		}
+		bal.Free, _ = domain.ParseDecimal(b.Free)
+		bal.Locked, _ = domain.ParseDecimal(b.Locked)
+		bal.Total = bal.Free.Add(bal.Locked)
+		if bal.Total.IsZero() {
+			continue
+		}
+		balances[b.Asset] = bal
+	}
+
+	return balances, nil
+}
+
+func (c *restClient) getPositions(ctx context.Context) ([]domain.Position, error) {
+	data, err := c.doRequest(ctx, "GET", c.futuresURL, "/fapi/v2/positionRisk", nil, true, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []struct {
+		Symbol           string `json:"symbol"`
+		PositionAmt      string `json:"positionAmt"`
+		EntryPrice       string `json:"entryPrice"`
+		UnRealizedProfit string `json:"unRealizedProfit"`
+		IsolatedMargin   string `json:"isolatedMargin"`
+	}
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return nil, fmt.Errorf("parse positions: %w", err)
+	}
+
+	result := make([]domain.Position, 0, len(positions))
+	for _, p := range positions {
+		size, _ := domain.ParseDecimal(p.PositionAmt)
+		if size.IsZero() {
+			continue
+		}
+		pos := domain.Position{
+			Venue:          "binance",
+			Account:        c.subAccount,
+			Asset:          domain.ReverseMapSymbol(p.Symbol, domain.BinanceFuturesSymbolMap),
+			InstrumentType: domain.InstrumentPerp,
+			Size:           size,
+			UpdatedAt:      time.Now(),
+		}
+		pos.EntryPrice, _ = domain.ParseDecimal(p.EntryPrice)
+		pos.UnrealizedPnL, _ = domain.ParseDecimal(p.UnRealizedProfit)
+		pos.MarginUsed, _ = domain.ParseDecimal(p.IsolatedMargin)
+		result = append(result, pos)
+	}
+
+	return result, nil
+}
+
+func (c *restClient) getFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	params := url.Values{}
+	params.Set("symbol", "BTCUSDT")
+
+	data, err := c.doRequest(ctx, "GET", c.spotURL, "/sapi/v1/asset/tradeFee", params, true, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var fees []struct {
+		Symbol          string `json:"symbol"`
+		MakerCommission string `json:"makerCommission"`
+		TakerCommission string `json:"takerCommission"`
+	}
+	if err := json.Unmarshal(data, &fees); err != nil {
+		return nil, fmt.Errorf("parse fee tier: %w", err)
+	}
+
+	tier := &domain.FeeTier{
+		Venue:     "binance",
+		UpdatedAt: time.Now(),
+	}
+
+	// Commissions are fractions (0.001 = 10 bps).
+	bps := decimal.NewFromInt(10000)
+	if len(fees) > 0 {
+		maker, _ := domain.ParseDecimal(fees[0].MakerCommission)
+		taker, _ := domain.ParseDecimal(fees[0].TakerCommission)
+		tier.MakerFeeBps = maker.Mul(bps)
+		tier.TakerFeeBps = taker.Mul(bps)
+	}
+
+	return tier, nil
+}
+
+// getInstruments reads the price, lot and notional filters of every mapped
+// spot and USD-M futures symbol from exchangeInfo.
+func (c *restClient) getInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	spot, err := c.getExchangeInfo(ctx, c.spotURL, "/api/v3", domain.BinanceSpotSymbolMap, domain.InstrumentSpot)
+	if err != nil {
+		return nil, err
+	}
+	perp, err := c.getExchangeInfo(ctx, c.futuresURL, "/fapi/v1", domain.BinanceFuturesSymbolMap, domain.InstrumentPerp)
+	if err != nil {
+		return nil, err
+	}
+	return append(spot, perp...), nil
+}
+
+func (c *restClient) getExchangeInfo(ctx context.Context, baseURL, prefix string, symbols map[string]string, instType domain.InstrumentType) ([]domain.Instrument, error) {
+	data, err := c.doRequest(ctx, "GET", baseURL, prefix+"/exchangeInfo", nil, false, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Symbols []struct {
+			Symbol  string `json:"symbol"`
+			Status  string `json:"status"` // TRADING, BREAK, HALT, ...
+			Filters []struct {
+				FilterType  string `json:"filterType"`
+				TickSize    string `json:"tickSize"`
+				StepSize    string `json:"stepSize"`
+				MinQty      string `json:"minQty"`
+				MinNotional string `json:"minNotional"` // spot NOTIONAL and MIN_NOTIONAL
+				Notional    string `json:"notional"`    // futures MIN_NOTIONAL
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parse exchange info: %w", err)
+	}
+
+	internal := make(map[string]string, len(symbols))
+	for k, v := range symbols {
+		internal[v] = k
+	}
+
+	var out []domain.Instrument
+	for _, s := range resp.Symbols {
+		symbol, ok := internal[s.Symbol]
+		if !ok {
+			continue
+		}
+		inst := domain.Instrument{
+			Venue:              "binance",
+			Symbol:             symbol,
+			InstrumentType:     instType,
+			ContractMultiplier: decimal.NewFromInt(1),
+			Suspended:          s.Status != "TRADING",
+		}
+		for _, f := range s.Filters {
+			switch f.FilterType {
+			case "PRICE_FILTER":
+				inst.TickSize, _ = domain.ParseDecimal(f.TickSize)
+			case "LOT_SIZE":
+				inst.StepSize, _ = domain.ParseDecimal(f.StepSize)
+				inst.MinSize, _ = domain.ParseDecimal(f.MinQty)
+			case "NOTIONAL", "MIN_NOTIONAL":
+				n := f.MinNotional
+				if n == "" {
+					n = f.Notional
+				}
+				inst.MinNotional, _ = domain.ParseDecimal(n)
+			}
+		}
+		out = append(out, inst)
+	}
+	return out, nil
+}
+
+func (c *restClient) getOrderBook(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	baseURL, prefix, _ := c.market(symbol)
+
+	params := url.Values{}
+	params.Set("symbol", domain.MapBinanceSymbol(symbol))
+	// The snapshot seeds the book the depth diffs are applied to, so it
+	// must reach as deep as the levels they update.
+	params.Set("limit", "1000")
+
+	data, err := c.doRequest(ctx, "GET", baseURL, prefix+"/depth", params, false, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		LastUpdateID uint64     `json:"lastUpdateId"`
+		Bids         [][]string `json:"bids"`
+		Asks         [][]string `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse orderbook: %w", err)
+	}
+
+	book := &domain.OrderBookSnapshot{
+		Venue:          "binance",
+		Symbol:         symbol,
+		Sequence:       result.LastUpdateID,
+		LocalTimestamp: time.Now(),
+	}
+	book.Bids = parseLevels(result.Bids)
+	book.Asks = parseLevels(result.Asks)
+
+	return book, nil
+}
+
+func (c *restClient) getFundingRate(ctx context.Context, symbol string) (*domain.FundingRate, error) {
+	params := url.Values{}
+	params.Set("symbol", domain.MapBinanceSymbol(symbol))
+
+	data, err := c.doRequest(ctx, "GET", c.futuresURL, "/fapi/v1/premiumIndex", params, false, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+		Time            int64  `json:"time"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse funding rate: %w", err)
+	}
+
+	rate := &domain.FundingRate{
+		Venue:     "binance",
+		Symbol:    symbol,
+		Timestamp: time.UnixMilli(result.Time),
+		NextTime:  time.UnixMilli(result.NextFundingTime),
+	}
+	rate.Rate, _ = domain.ParseDecimal(result.LastFundingRate)
+
+	return rate, nil
+}
+
+// historyLimit is the most klines, trades or funding rates Binance returns
+// per history request.
+const historyLimit = 1000
+
+// aggTradesWindow is the widest time range aggTrades accepts.
+const aggTradesWindow = time.Hour
+
+// klineIntervals names the kline intervals Binance serves.
+var klineIntervals = map[time.Duration]string{
+	time.Minute:      "1m",
+	5 * time.Minute:  "5m",
+	15 * time.Minute: "15m",
+	time.Hour:        "1h",
+	4 * time.Hour:    "4h",
+	24 * time.Hour:   "1d",
+}
+
+// historyParams bounds a history request to [from, to); Binance's endTime
+// is inclusive.
+func historyParams(symbol string, from, to time.Time) url.Values {
+	params := url.Values{}
+	params.Set("symbol", domain.MapBinanceSymbol(symbol))
+	params.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+	params.Set("endTime", strconv.FormatInt(to.UnixMilli()-1, 10))
+	params.Set("limit", strconv.Itoa(historyLimit))
+	return params
+}
+
+func (c *restClient) getKlines(ctx context.Context, symbol string, interval time.Duration, from, to time.Time) ([]domain.Kline, error) {
+	name, ok := klineIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: binance %s klines", gateway.ErrHistoryUnsupported, interval)
+	}
+	baseURL, prefix, _ := c.market(symbol)
+	params := historyParams(symbol, from, to)
+	params.Set("interval", name)
+
+	data, err := c.doRequest(ctx, "GET", baseURL, prefix+"/klines", params, false, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each kline is [openTime, open, high, low, close, volume, closeTime, ...].
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("parse klines: %w", err)
+	}
+	klines := make([]domain.Kline, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			return nil, fmt.Errorf("parse klines: short row of %d fields", len(row))
+		}
+		var openTime int64
+		var fields [5]string
+		if err := json.Unmarshal(row[0], &openTime); err != nil {
+			return nil, fmt.Errorf("parse kline open time: %w", err)
+		}
+		for i := range fields {
+			if err := json.Unmarshal(row[i+1], &fields[i]); err != nil {
+				return nil, fmt.Errorf("parse kline: %w", err)
+			}
+		}
+		k := domain.Kline{
+			Venue:    "binance",
+			Symbol:   symbol,
+			Interval: interval,
+			OpenTime: time.UnixMilli(openTime),
+		}
+		k.Open, _ = domain.ParseDecimal(fields[0])
+		k.High, _ = domain.ParseDecimal(fields[1])
+		k.Low, _ = domain.ParseDecimal(fields[2])
+		k.Close, _ = domain.ParseDecimal(fields[3])
+		k.Volume, _ = domain.ParseDecimal(fields[4])
+		klines = append(klines, k)
+	}
+	return klines, nil
+}
+
+// getHistoricalTrades reads aggregate trades, which merge the fills of one
+// taker order at one price. Binance serves at most aggTradesWindow per
+// request, so windows without trades are skipped until one has some or the
+// range ends.
+func (c *restClient) getHistoricalTrades(ctx context.Context, symbol string, from, to time.Time) ([]domain.Trade, error) {
+	for from.Before(to) {
+		end := from.Add(aggTradesWindow)
+		if end.After(to) {
+			end = to
+		}
+		trades, err := c.getAggTrades(ctx, symbol, from, end)
+		if err != nil || len(trades) > 0 {
+			return trades, err
+		}
+		from = end
+	}
+	return nil, nil
+}
+
+func (c *restClient) getAggTrades(ctx context.Context, symbol string, from, to time.Time) ([]domain.Trade, error) {
+	baseURL, prefix, _ := c.market(symbol)
+
+	data, err := c.doRequest(ctx, "GET", baseURL, prefix+"/aggTrades", historyParams(symbol, from, to), false, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ID           int64  `json:"a"`
+		Price        string `json:"p"`
+		Quantity     string `json:"q"`
+		Time         int64  `json:"T"`
+		IsBuyerMaker bool   `json:"m"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("parse aggTrades: %w", err)
+	}
+	trades := make([]domain.Trade, 0, len(rows))
+	for _, row := range rows {
+		t := domain.Trade{
+			Venue:     "binance",
+			Symbol:    symbol,
+			Side:      domain.SideBuy,
+			Timestamp: time.UnixMilli(row.Time),
+			TradeID:   strconv.FormatInt(row.ID, 10),
+		}
+		if row.IsBuyerMaker {
+			t.Side = domain.SideSell
+		}
+		t.Price, _ = domain.ParseDecimal(row.Price)
+		t.Size, _ = domain.ParseDecimal(row.Quantity)
+		trades = append(trades, t)
+	}
+	return trades, nil
+}
+
+func (c *restClient) getFundingHistory(ctx context.Context, symbol string, from, to time.Time) ([]domain.FundingRate, error) {
+	if _, _, futures := c.market(symbol); !futures {
+		return nil, fmt.Errorf("%w: binance funding for spot %s", gateway.ErrHistoryUnsupported, symbol)
+	}
+
+	data, err := c.doRequest(ctx, "GET", c.futuresURL, "/fapi/v1/fundingRate", historyParams(symbol, from, to), false, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		FundingRate string `json:"fundingRate"`
+		FundingTime int64  `json:"fundingTime"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("parse funding history: %w", err)
+	}
+	rates := make([]domain.FundingRate, 0, len(rows))
+	for _, row := range rows {
+		r := domain.FundingRate{
+			Venue:     "binance",
+			Symbol:    symbol,
+			Timestamp: time.UnixMilli(row.FundingTime),
+		}
+		r.Rate, _ = domain.ParseDecimal(row.FundingRate)
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
+
+func parseLevels(raw [][]string) []domain.PriceLevel {
+	levels := make([]domain.PriceLevel, 0, len(raw))
+	for _, lvl := range raw {
+		if len(lvl) >= 2 {
+			price, _ := domain.ParseDecimal(lvl[0])
+			size, _ := domain.ParseDecimal(lvl[1])
+			levels = append(levels, domain.PriceLevel{Price: price, Size: size})
+		}
+	}
+	return levels
+}
+
+// ping checks that the public REST API answers.
+func (c *restClient) ping(ctx context.Context) error {
+	return gateway.ProbeREST(ctx, c.httpClient, c.spotURL+"/api/v3/ping")
+}
+
+// serverTime returns the spot API's clock, used to correct signing timestamps.
+func (c *restClient) serverTime(ctx context.Context) (time.Time, error) {
+	var resp struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := gateway.FetchJSON(ctx, c.httpClient, c.spotURL+"/api/v3/time", &resp); err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(resp.ServerTime), nil
+}
@@ -0,0 +1,539 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+func newTestRESTClient(handler http.Handler) (*restClient, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	rl := gateway.NewRateLimiter()
+	rl.AddBucket(domain.EndpointPublicData, 100, 100)
+	rl.AddBucket(domain.EndpointPrivateData, 100, 100)
+	rl.AddBucket(domain.EndpointOrderPlace, 100, 100)
+	rl.AddBucket(domain.EndpointOrderCancel, 100, 100)
+	rl.AddBucket(domain.EndpointAccount, 100, 100)
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	client := newRESTClient(server.URL, server.URL, "test-api-key", "test-api-secret", rl, logger)
+	return client, server
+}
+
+func TestBinanceRestClient_PlaceOrder_SpotLimitOrder(t *testing.T) {
+	var capturedReq *http.Request
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 28457})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID:     uuid.Must(uuid.NewV7()),
+		Symbol:         "BTC/USDT",
+		Side:           domain.SideBuy,
+		OrderType:      domain.OrderTypeLimit,
+		Price:          decimal.NewFromInt(50000),
+		Size:           decimal.NewFromFloat(0.1),
+		IdempotencyKey: "idem-123",
+	}
+
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedReq.URL.Path != "/api/v3/order" {
+		t.Errorf("expected path /api/v3/order, got %s", capturedReq.URL.Path)
+	}
+	if capturedReq.Method != "POST" {
+		t.Errorf("expected POST, got %s", capturedReq.Method)
+	}
+	if capturedReq.Header.Get("X-MBX-APIKEY") != "test-api-key" {
+		t.Errorf("expected X-MBX-APIKEY header, got %q", capturedReq.Header.Get("X-MBX-APIKEY"))
+	}
+
+	q := capturedReq.URL.Query()
+	if q.Get("symbol") != "BTCUSDT" {
+		t.Errorf("expected symbol BTCUSDT, got %s", q.Get("symbol"))
+	}
+	if q.Get("side") != "BUY" {
+		t.Errorf("expected side=BUY, got %s", q.Get("side"))
+	}
+	if q.Get("type") != "LIMIT" || q.Get("timeInForce") != "GTC" {
+		t.Errorf("expected LIMIT/GTC, got %s/%s", q.Get("type"), q.Get("timeInForce"))
+	}
+	if q.Get("newClientOrderId") != "idem-123" {
+		t.Errorf("expected newClientOrderId=idem-123, got %s", q.Get("newClientOrderId"))
+	}
+	if q.Get("timestamp") == "" || q.Get("signature") == "" {
+		t.Error("expected timestamp and signature to be set")
+	}
+
+	if ack.VenueID != "spot:BTCUSDT:28457" {
+		t.Errorf("expected VenueID spot:BTCUSDT:28457, got %s", ack.VenueID)
+	}
+	if ack.Status != domain.OrderStatusAcknowledged {
+		t.Errorf("expected ACKNOWLEDGED, got %s", ack.Status)
+	}
+}
+
+func TestBinanceRestClient_PlaceOrder_FuturesMarketOrder(t *testing.T) {
+	var capturedReq *http.Request
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 99})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID:     uuid.Must(uuid.NewV7()),
+		Symbol:         "ETHUSDT",
+		Side:           domain.SideSell,
+		OrderType:      domain.OrderTypeMarket,
+		Size:           decimal.NewFromInt(2),
+		ReduceOnly:     true,
+		IdempotencyKey: "idem-456",
+	}
+
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedReq.URL.Path != "/fapi/v1/order" {
+		t.Errorf("expected path /fapi/v1/order, got %s", capturedReq.URL.Path)
+	}
+	q := capturedReq.URL.Query()
+	if q.Get("type") != "MARKET" {
+		t.Errorf("expected type=MARKET, got %s", q.Get("type"))
+	}
+	if q.Get("price") != "" {
+		t.Errorf("expected no price on market order, got %s", q.Get("price"))
+	}
+	if q.Get("reduceOnly") != "true" {
+		t.Errorf("expected reduceOnly=true, got %s", q.Get("reduceOnly"))
+	}
+	if ack.VenueID != "perp:ETHUSDT:99" {
+		t.Errorf("expected VenueID perp:ETHUSDT:99, got %s", ack.VenueID)
+	}
+}
+
+func TestBinanceRestClient_CancelOrder(t *testing.T) {
+	var capturedReq *http.Request
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 99, "status": "CANCELED"})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	ack, err := client.cancelOrder(context.Background(), "perp:ETHUSDT:99")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedReq.Method != "DELETE" {
+		t.Errorf("expected DELETE, got %s", capturedReq.Method)
+	}
+	if capturedReq.URL.Path != "/fapi/v1/order" {
+		t.Errorf("expected path /fapi/v1/order, got %s", capturedReq.URL.Path)
+	}
+	q := capturedReq.URL.Query()
+	if q.Get("symbol") != "ETHUSDT" || q.Get("orderId") != "99" {
+		t.Errorf("expected symbol=ETHUSDT orderId=99, got %s/%s", q.Get("symbol"), q.Get("orderId"))
+	}
+	if ack.Status != domain.OrderStatusCancelled {
+		t.Errorf("expected CANCELLED, got %s", ack.Status)
+	}
+
+	if _, err := client.cancelOrder(context.Background(), "12345"); err == nil {
+		t.Error("expected error for order ID without market and symbol")
+	}
+}
+
+func TestBinanceRestClient_GetOrder(t *testing.T) {
+	var capturedReq *http.Request
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"orderId":             7,
+			"clientOrderId":       "idem-7",
+			"executedQty":         "0.5",
+			"cummulativeQuoteQty": "25005",
+			"status":              "CANCELED",
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	update, err := client.getOrder(context.Background(), "spot:BTCUSDT:7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedReq.Method != "GET" || capturedReq.URL.Path != "/api/v3/order" {
+		t.Errorf("expected GET /api/v3/order, got %s %s", capturedReq.Method, capturedReq.URL.Path)
+	}
+	if update.Status != domain.OrderStatusCancelled || update.VenueID != "spot:BTCUSDT:7" || update.ClientOrderID != "idem-7" {
+		t.Errorf("unexpected update: %+v", update)
+	}
+	if !update.FilledSize.Equal(decimal.RequireFromString("0.5")) || !update.AvgFillPrice.Equal(decimal.NewFromInt(50010)) {
+		t.Errorf("expected 0.5 filled at 50010, got %s at %s", update.FilledSize, update.AvgFillPrice)
+	}
+}
+
+func TestBinanceRestClient_GetBalances(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"balances": []map[string]interface{}{
+				{"asset": "BTC", "free": "1.5", "locked": "0.5"},
+				{"asset": "USDT", "free": "10000", "locked": "0"},
+				{"asset": "BNB", "free": "0", "locked": "0"},
+			},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	balances, err := client.getBalances(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(balances) != 2 {
+		t.Fatalf("expected 2 non-zero balances, got %d", len(balances))
+	}
+	btc := balances["BTC"]
+	if !btc.Total.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected BTC total 2, got %s", btc.Total)
+	}
+	if btc.Venue != "binance" {
+		t.Errorf("expected venue binance, got %s", btc.Venue)
+	}
+}
+
+func TestBinanceRestClient_SignsWithServerTime(t *testing.T) {
+	skew := 30 * time.Second
+	var signedAt int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/time" {
+			json.NewEncoder(w).Encode(map[string]int64{"serverTime": time.Now().Add(skew).UnixMilli()})
+			return
+		}
+		signedAt, _ = strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+		json.NewEncoder(w).Encode(map[string]interface{}{"balances": []interface{}{}})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	if err := client.clock.Sync(context.Background()); err != nil {
+		t.Fatalf("clock sync: %v", err)
+	}
+	if _, err := client.getBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := time.UnixMilli(signedAt).Sub(time.Now().Add(skew)).Abs(); d > time.Second {
+		t.Errorf("expected the signed timestamp on the venue's clock, off by %v", d)
+	}
+}
+
+func TestBinanceRestClient_GetPositions(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v2/positionRisk" {
+			t.Errorf("expected path /fapi/v2/positionRisk, got %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"symbol": "BTCUSDT", "positionAmt": "-0.25", "entryPrice": "60000", "unRealizedProfit": "12.5", "isolatedMargin": "0"},
+			{"symbol": "ETHUSDT", "positionAmt": "0", "entryPrice": "0", "unRealizedProfit": "0", "isolatedMargin": "0"},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	positions, err := client.getPositions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(positions) != 1 {
+		t.Fatalf("expected 1 open position, got %d", len(positions))
+	}
+	p := positions[0]
+	if p.Asset != "BTCUSDT" || p.InstrumentType != domain.InstrumentPerp {
+		t.Errorf("expected BTCUSDT perp, got %s %s", p.Asset, p.InstrumentType)
+	}
+	if !p.Size.Equal(decimal.NewFromFloat(-0.25)) {
+		t.Errorf("expected size -0.25, got %s", p.Size)
+	}
+}
+
+func TestBinanceRestClient_GetOpenOrders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"orderId": 7, "symbol": "ETHBTC", "side": "SELL", "type": "LIMIT", "price": "0.05", "origQty": "3", "executedQty": "1", "status": "PARTIALLY_FILLED"},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	orders, err := client.getOpenOrders(context.Background(), "ETH/BTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(orders) != 1 {
+		t.Fatalf("expected 1 order, got %d", len(orders))
+	}
+	o := orders[0]
+	if o.VenueID != "spot:ETHBTC:7" {
+		t.Errorf("expected VenueID spot:ETHBTC:7, got %s", o.VenueID)
+	}
+	if o.Symbol != "ETH/BTC" {
+		t.Errorf("expected internal symbol ETH/BTC, got %s", o.Symbol)
+	}
+	if o.Side != domain.SideSell || o.Status != domain.OrderStatusPartialFill {
+		t.Errorf("expected SELL PARTIAL_FILL, got %s %s", o.Side, o.Status)
+	}
+	if !o.FilledSize.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected filled 1, got %s", o.FilledSize)
+	}
+}
+
+func TestBinanceRestClient_GetFeeTier(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"symbol": "BTCUSDT", "makerCommission": "0.00075", "takerCommission": "0.001"},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	tier, err := client.getFeeTier(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !tier.MakerFeeBps.Equal(decimal.NewFromFloat(7.5)) {
+		t.Errorf("expected maker 7.5 bps, got %s", tier.MakerFeeBps)
+	}
+	if !tier.TakerFeeBps.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected taker 10 bps, got %s", tier.TakerFeeBps)
+	}
+}
+
+func TestBinanceRestClient_GetInstruments(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/fapi/") {
+			w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","status":"SETTLING","filters":[
+				{"filterType":"PRICE_FILTER","tickSize":"0.10"},
+				{"filterType":"LOT_SIZE","stepSize":"0.001","minQty":"0.001"},
+				{"filterType":"MIN_NOTIONAL","notional":"100"}]}]}`))
+			return
+		}
+		w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","status":"TRADING","filters":[
+			{"filterType":"PRICE_FILTER","tickSize":"0.01000000"},
+			{"filterType":"LOT_SIZE","stepSize":"0.00001000","minQty":"0.00001000"},
+			{"filterType":"NOTIONAL","minNotional":"5.00000000"}]},
+			{"symbol":"DOGEUSDT","filters":[]}]}`))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	instruments, err := client.getInstruments(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(instruments) != 2 {
+		t.Fatalf("expected 2 instruments (unmapped symbols skipped), got %d", len(instruments))
+	}
+
+	spot, perp := instruments[0], instruments[1]
+	if spot.Symbol != "BTC/USDT" || spot.InstrumentType != domain.InstrumentSpot {
+		t.Errorf("unexpected spot instrument %s %s", spot.Symbol, spot.InstrumentType)
+	}
+	if !spot.TickSize.Equal(decimal.RequireFromString("0.01")) || !spot.StepSize.Equal(decimal.RequireFromString("0.00001")) {
+		t.Errorf("unexpected spot increments tick %s step %s", spot.TickSize, spot.StepSize)
+	}
+	if !spot.MinNotional.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected spot min notional 5, got %s", spot.MinNotional)
+	}
+	if perp.Symbol != "BTCUSDT" || perp.InstrumentType != domain.InstrumentPerp {
+		t.Errorf("unexpected perp instrument %s %s", perp.Symbol, perp.InstrumentType)
+	}
+	if !perp.MinNotional.Equal(decimal.NewFromInt(100)) || !perp.MinSize.Equal(decimal.RequireFromString("0.001")) {
+		t.Errorf("unexpected perp minimums size %s notional %s", perp.MinSize, perp.MinNotional)
+	}
+	if spot.Suspended || !perp.Suspended {
+		t.Errorf("expected only the settling perp suspended, got spot %v perp %v", spot.Suspended, perp.Suspended)
+	}
+}
+
+func TestBinanceRestClient_APIError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": -2010,
+			"msg":  "Account has insufficient balance for requested action.",
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	_, err := client.getBalances(context.Background())
+	if err == nil {
+		t.Fatal("expected error for API error response")
+	}
+	if !strings.Contains(err.Error(), "code=-2010") {
+		t.Errorf("expected error to contain code=-2010, got %v", err)
+	}
+	// -2010 covers every kind of spot rejection, so it is not categorised.
+	if c := gateway.ErrorCategoryOf(err); c != gateway.ErrorUnknown {
+		t.Errorf("expected -2010 uncategorised, got %q", c)
+	}
+}
+
+func TestBinanceRestClient_SignatureFormat(t *testing.T) {
+	// Example from the Binance API documentation.
+	secret := "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j"
+	payload := "symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559"
+	want := "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71"
+	if got := sign(secret, payload); got != want {
+		t.Errorf("sign() = %s, want %s", got, want)
+	}
+}
+
+func TestBinanceRestClient_GetOrderBook(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-MBX-APIKEY") == "" {
+			t.Error("expected API key header on public request")
+		}
+		if r.URL.Query().Get("signature") != "" {
+			t.Error("expected public depth request to be unsigned")
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"lastUpdateId": 1027024,
+			"bids":         [][]string{{"50000.00", "1.5"}, {"49999.00", "2.0"}},
+			"asks":         [][]string{{"50001.00", "1.0"}},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	book, err := client.getOrderBook(context.Background(), "BTC/USDT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if book.Sequence != 1027024 {
+		t.Errorf("expected sequence 1027024, got %d", book.Sequence)
+	}
+	if len(book.Bids) != 2 || len(book.Asks) != 1 {
+		t.Fatalf("expected 2 bids and 1 ask, got %d/%d", len(book.Bids), len(book.Asks))
+	}
+	if !book.Bids[0].Price.Equal(decimal.NewFromInt(50000)) {
+		t.Errorf("expected best bid 50000, got %s", book.Bids[0].Price)
+	}
+}
+
+func TestBinanceRestClient_MarketHistory(t *testing.T) {
+	var captured []*http.Request
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = append(captured, r)
+		switch r.URL.Path {
+		case "/api/v3/klines":
+			w.Write([]byte(`[[1700000000000,"37000.1","37010","36990","37005.5","12.5",1700000059999,"0",10,"0","0","0"]]`))
+		case "/fapi/v1/aggTrades":
+			// The first hour is quiet.
+			if r.URL.Query().Get("startTime") == "1700000000000" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"a":26129,"p":"37001.2","q":"0.4","f":100,"l":105,"T":1700003601234,"m":true}]`))
+		case "/fapi/v1/fundingRate":
+			w.Write([]byte(`[{"symbol":"BTCUSDT","fundingRate":"0.00010000","fundingTime":1700006400000}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	from := time.UnixMilli(1700000000000)
+	to := from.Add(3 * time.Hour)
+	ctx := context.Background()
+
+	klines, err := client.getKlines(ctx, "BTC/USDT", time.Minute, from, to)
+	if err != nil {
+		t.Fatalf("klines: %v", err)
+	}
+	q := captured[0].URL.Query()
+	if q.Get("interval") != "1m" || q.Get("symbol") != "BTCUSDT" || q.Get("endTime") != "1700010799999" {
+		t.Errorf("unexpected klines query %s", captured[0].URL.RawQuery)
+	}
+	if len(klines) != 1 || !klines[0].OpenTime.Equal(from) || !klines[0].Close.Equal(decimal.RequireFromString("37005.5")) || !klines[0].Volume.Equal(decimal.RequireFromString("12.5")) {
+		t.Errorf("unexpected klines %+v", klines)
+	}
+	if _, err := client.getKlines(ctx, "BTC/USDT", 7*time.Minute, from, to); !errors.Is(err, gateway.ErrHistoryUnsupported) {
+		t.Errorf("expected an unsupported interval rejected, got %v", err)
+	}
+
+	trades, err := client.getHistoricalTrades(ctx, "BTCUSDT", from, to)
+	if err != nil {
+		t.Fatalf("trades: %v", err)
+	}
+	// aggTrades takes at most an hour, and the empty first hour is skipped.
+	if got := captured[1].URL.Query().Get("endTime"); got != "1700003599999" {
+		t.Errorf("expected the trades range cut to an hour, got endTime %s", got)
+	}
+	if got := captured[2].URL.Query().Get("startTime"); got != "1700003600000" || len(captured) != 3 {
+		t.Errorf("expected one more request from the second hour, got %d requests, startTime %s", len(captured), got)
+	}
+	if len(trades) != 1 || trades[0].Side != domain.SideSell || trades[0].TradeID != "26129" || !trades[0].Size.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("unexpected trades %+v", trades)
+	}
+
+	rates, err := client.getFundingHistory(ctx, "BTCUSDT", from, to)
+	if err != nil {
+		t.Fatalf("funding: %v", err)
+	}
+	if len(rates) != 1 || !rates[0].Rate.Equal(decimal.RequireFromString("0.0001")) || rates[0].Timestamp.UnixMilli() != 1700006400000 {
+		t.Errorf("unexpected funding rates %+v", rates)
+	}
+	if _, err := client.getFundingHistory(ctx, "BTC/USDT", from, to); !errors.Is(err, gateway.ErrHistoryUnsupported) {
+		t.Errorf("expected spot funding rejected, got %v", err)
+	}
+}
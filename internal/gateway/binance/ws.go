@@ -0,0 +1,385 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// wsClient manages one Binance combined-stream connection. Spot and USD-M
+// futures are served from different hosts, so the gateway runs one client
+// per market.
+type wsClient struct {
+	url       string
+	market    string
+	symbolMap map[string]string
+	conn      *websocket.Conn
+	mu        sync.Mutex
+	logger    *slog.Logger
+
+	reconnectMax  time.Duration
+	reconnectBase time.Duration
+	maxFailures   int
+
+	subscriptions []string
+	subMu         sync.Mutex
+	onReconnect   func() // called after each successful reconnect, if set
+	state         gateway.WSState
+	egress        gateway.Egress
+	latency       gateway.WSLatency
+	nextID        int64
+	pumpOnce      sync.Once
+
+	orderBookChans map[string]chan domain.OrderBookDelta
+	tradeChans     map[string]chan domain.Trade
+	fundingChans   map[string]chan domain.FundingRate
+	chanMu         sync.RWMutex
+}
+
+func newWSClient(url, market string, symbolMap map[string]string, logger *slog.Logger) *wsClient {
+	return &wsClient{
+		url:            url,
+		market:         market,
+		symbolMap:      symbolMap,
+		logger:         logger,
+		reconnectBase:  100 * time.Millisecond,
+		reconnectMax:   30 * time.Second,
+		maxFailures:    5,
+		orderBookChans: make(map[string]chan domain.OrderBookDelta),
+		tradeChans:     make(map[string]chan domain.Trade),
+		fundingChans:   make(map[string]chan domain.FundingRate),
+	}
+}
+
+func (ws *wsClient) connect(ctx context.Context) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	dialer := ws.egress.WSDialer()
+
+	conn, _, err := dialer.DialContext(ctx, ws.url, nil)
+	if err != nil {
+		return fmt.Errorf("websocket connect to %s: %w", ws.url, err)
+	}
+
+	ws.conn = conn
+	ws.state.SetConnected(true)
+	ws.logger.Info("binance websocket connected", "market", ws.market, "url", ws.url)
+	return nil
+}
+
+func (ws *wsClient) reconnect(ctx context.Context) error {
+	delay := ws.reconnectBase
+	for i := 0; i < ws.maxFailures; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if err := ws.connect(ctx); err != nil {
+			ws.logger.Warn("binance reconnect attempt failed",
+				"market", ws.market, "attempt", i+1, "error", err)
+			delay *= 2
+			if delay > ws.reconnectMax {
+				delay = ws.reconnectMax
+			}
+			continue
+		}
+		if subs := ws.activeSubscriptions(); len(subs) > 0 {
+			if err := ws.sendSubscribe(subs...); err != nil {
+				ws.logger.Warn("failed to resubscribe after reconnect",
+					"market", ws.market, "error", err)
+			}
+		}
+		if ws.onReconnect != nil {
+			ws.onReconnect()
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to reconnect after %d attempts", ws.maxFailures)
+}
+
+// activeSubscriptions returns a copy of the subscriptions to restore after a
+// reconnect.
+func (ws *wsClient) activeSubscriptions() []string {
+	ws.subMu.Lock()
+	defer ws.subMu.Unlock()
+	subs := make([]string, len(ws.subscriptions))
+	copy(subs, ws.subscriptions)
+	return subs
+}
+
+// subscribe registers a stream (e.g. "btcusdt@trade") and starts the read
+// pump on first use.
+func (ws *wsClient) subscribe(ctx context.Context, stream string) error {
+	ws.subMu.Lock()
+	ws.subscriptions = append(ws.subscriptions, stream)
+	ws.subMu.Unlock()
+	if err := ws.sendSubscribe(stream); err != nil {
+		return err
+	}
+	ws.pumpOnce.Do(func() { go ws.readPump(ctx) })
+	return nil
+}
+
+func (ws *wsClient) sendSubscribe(streams ...string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.conn == nil {
+		return fmt.Errorf("websocket not connected")
+	}
+
+	ws.nextID++
+	msg := map[string]interface{}{
+		"method": "SUBSCRIBE",
+		"params": streams,
+		"id":     ws.nextID,
+	}
+	return ws.conn.WriteJSON(msg)
+}
+
+func (ws *wsClient) readPump(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		ws.mu.Lock()
+		conn := ws.conn
+		ws.mu.Unlock()
+
+		if conn == nil {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			ws.state.SetConnected(false)
+			ws.logger.Error("binance websocket read error", "market", ws.market, "error", err)
+			if reconnErr := ws.reconnect(ctx); reconnErr != nil {
+				ws.logger.Error("binance reconnection failed permanently", "market", ws.market, "error", reconnErr)
+				return
+			}
+			continue
+		}
+
+		ws.state.Touch()
+		ws.handleMessage(message)
+	}
+}
+
+func (ws *wsClient) handleMessage(msg []byte) {
+	var raw struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		ws.logger.Debug("failed to parse binance websocket message", "error", err)
+		return
+	}
+
+	// Subscription acks ({"result":null,"id":1}) carry no stream.
+	if raw.Stream == "" {
+		return
+	}
+
+	// Stream format: "btcusdt@depth@100ms", "btcusdt@trade", "btcusdt@markPrice@1s"
+	venueSymbol, streamType, _ := strings.Cut(raw.Stream, "@")
+	venueSymbol = strings.ToUpper(venueSymbol)
+
+	switch {
+	case strings.HasPrefix(streamType, "depth"):
+		ws.handleOrderBookMessage(venueSymbol, raw.Data)
+	case streamType == "trade" || streamType == "aggTrade":
+		ws.handleTradeMessage(venueSymbol, raw.Data)
+	case strings.HasPrefix(streamType, "markPrice"):
+		ws.handleFundingMessage(venueSymbol, raw.Data)
+	}
+}
+
+func (ws *wsClient) handleOrderBookMessage(venueSymbol string, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.orderBookChans[venueSymbol]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var update struct {
+		EventType     string     `json:"e"` // keeps "e" from being matched to "E"
+		EventTime     int64      `json:"E"`
+		FirstUpdateID uint64     `json:"U"`
+		FinalUpdateID uint64     `json:"u"`
+		PrevUpdateID  uint64     `json:"pu"`
+		Bids          [][]string `json:"b"`
+		Asks          [][]string `json:"a"`
+	}
+	if err := json.Unmarshal(data, &update); err != nil {
+		ws.logger.Warn("failed to parse binance depth update", "error", err)
+		return
+	}
+
+	delta := domain.OrderBookDelta{
+		Venue:          "binance",
+		Symbol:         domain.ReverseMapSymbol(venueSymbol, ws.symbolMap),
+		Bids:           parseLevels(update.Bids),
+		Asks:           parseLevels(update.Asks),
+		Sequence:       update.FinalUpdateID,
+		FirstSequence:  update.FirstUpdateID,
+		VenueTimestamp: time.UnixMilli(update.EventTime),
+		LocalTimestamp: time.Now(),
+	}
+	// Spot diffs follow on when U is the previous u+1. Futures diffs can
+	// start anywhere after it and instead name the previous u in pu, so
+	// continuity is checked against that.
+	if ws.market == "perp" {
+		delta.FirstSequence = update.PrevUpdateID + 1
+	}
+
+	ws.latency.Observe("book", delta.VenueTimestamp)
+	select {
+	case ch <- delta:
+	default:
+		ws.logger.Debug("binance orderbook channel full, dropping update", "symbol", venueSymbol)
+	}
+}
+
+func (ws *wsClient) handleTradeMessage(venueSymbol string, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.tradeChans[venueSymbol]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	// Spot "trade" events carry the trade ID in "t"; futures "aggTrade"
+	// events carry the aggregate ID in "a".
+	var update struct {
+		TradeID      int64  `json:"t"`
+		AggTradeID   int64  `json:"a"`
+		Price        string `json:"p"`
+		Quantity     string `json:"q"`
+		TradeTime    int64  `json:"T"`
+		BuyerIsMaker bool   `json:"m"`
+		Ignore       bool   `json:"M"` // keeps "M" from being matched to "m"
+	}
+	if err := json.Unmarshal(data, &update); err != nil {
+		ws.logger.Warn("failed to parse binance trade update", "error", err)
+		return
+	}
+
+	// The aggressor is the seller when the buyer is the resting maker.
+	side := domain.SideBuy
+	if update.BuyerIsMaker {
+		side = domain.SideSell
+	}
+
+	tradeID := update.TradeID
+	if tradeID == 0 {
+		tradeID = update.AggTradeID
+	}
+
+	trade := domain.Trade{
+		Venue:     "binance",
+		Symbol:    domain.ReverseMapSymbol(venueSymbol, ws.symbolMap),
+		Side:      side,
+		Timestamp: time.UnixMilli(update.TradeTime),
+		TradeID:   strconv.FormatInt(tradeID, 10),
+	}
+	trade.Price, _ = domain.ParseDecimal(update.Price)
+	trade.Size, _ = domain.ParseDecimal(update.Quantity)
+
+	if update.TradeTime == 0 {
+		trade.Timestamp = time.Now()
+	}
+
+	ws.latency.Observe("trade", trade.Timestamp)
+	select {
+	case ch <- trade:
+	default:
+		ws.logger.Debug("binance trade channel full, dropping update", "symbol", venueSymbol)
+	}
+}
+
+func (ws *wsClient) handleFundingMessage(venueSymbol string, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.fundingChans[venueSymbol]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var update struct {
+		EventType       string `json:"e"` // keeps "e" from being matched to "E"
+		EventTime       int64  `json:"E"`
+		FundingRate     string `json:"r"`
+		NextFundingTime int64  `json:"T"`
+	}
+	if err := json.Unmarshal(data, &update); err != nil {
+		ws.logger.Warn("failed to parse binance mark price update", "error", err)
+		return
+	}
+
+	rate := domain.FundingRate{
+		Venue:     "binance",
+		Symbol:    domain.ReverseMapSymbol(venueSymbol, ws.symbolMap),
+		Timestamp: time.UnixMilli(update.EventTime),
+		NextTime:  time.UnixMilli(update.NextFundingTime),
+	}
+	rate.Rate, _ = domain.ParseDecimal(update.FundingRate)
+
+	select {
+	case ch <- rate:
+	default:
+		ws.logger.Debug("binance funding channel full, dropping update", "symbol", venueSymbol)
+	}
+}
+
+func (ws *wsClient) subscribeOrderBook(venueSymbol string) <-chan domain.OrderBookDelta {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.OrderBookDelta, 256)
+	ws.orderBookChans[venueSymbol] = ch
+	return ch
+}
+
+func (ws *wsClient) subscribeTrades(venueSymbol string) <-chan domain.Trade {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.Trade, 256)
+	ws.tradeChans[venueSymbol] = ch
+	return ch
+}
+
+func (ws *wsClient) subscribeFunding(venueSymbol string) <-chan domain.FundingRate {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.FundingRate, 256)
+	ws.fundingChans[venueSymbol] = ch
+	return ch
+}
+
+func (ws *wsClient) close() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.state.SetConnected(false)
+	if ws.conn != nil {
+		return ws.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package binance
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestBinanceWSClient_HandleOrderBookMessage_Sequences(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	spot := newWSClient("", "spot", domain.BinanceSpotSymbolMap, logger)
+	spotCh := spot.subscribeOrderBook("BTCUSDT")
+	spot.handleOrderBookMessage("BTCUSDT", json.RawMessage(`{"e":"depthUpdate","E":1700000000123,"s":"BTCUSDT","U":157,"u":160,"b":[["0.0024","10"]],"a":[]}`))
+
+	select {
+	case delta := <-spotCh:
+		if delta.FirstSequence != 157 || delta.Sequence != 160 {
+			t.Errorf("expected spot diff 157..160, got %d..%d", delta.FirstSequence, delta.Sequence)
+		}
+	default:
+		t.Fatal("expected the spot depth update to be parsed")
+	}
+
+	// Futures diffs chain on pu, the previous diff's u.
+	perp := newWSClient("", "perp", domain.BinanceFuturesSymbolMap, logger)
+	perpCh := perp.subscribeOrderBook("BTCUSDT")
+	perp.handleOrderBookMessage("BTCUSDT", json.RawMessage(`{"e":"depthUpdate","E":1700000000123,"s":"BTCUSDT","U":157,"u":160,"pu":149,"b":[],"a":[["0.0026","100"]]}`))
+
+	select {
+	case delta := <-perpCh:
+		if delta.FirstSequence != 150 || delta.Sequence != 160 {
+			t.Errorf("expected futures diff to follow on from 149, got %d..%d", delta.FirstSequence, delta.Sequence)
+		}
+	default:
+		t.Fatal("expected the futures depth update to be parsed")
+	}
+}
+
+func TestBinanceWSClient_HandleFundingMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", "perp", domain.BinanceFuturesSymbolMap, logger)
+	ch := ws.subscribeFunding("BTCUSDT")
+
+	ws.handleFundingMessage("BTCUSDT", json.RawMessage(`{"e":"markPriceUpdate","E":1700000000000,"s":"BTCUSDT","p":"50000.1","r":"0.00010000","T":1700006400000}`))
+
+	select {
+	case rate := <-ch:
+		if rate.Rate.String() != "0.0001" || rate.NextTime.UnixMilli() != 1700006400000 {
+			t.Errorf("unexpected funding rate: %+v", rate)
+		}
+	default:
+		t.Fatal("expected the mark price update to be parsed")
+	}
+}
@@ -0,0 +1,296 @@
+package bybit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// Gateway implements the VenueGateway interface for Bybit (v5 unified API).
+// Spot (BTC/USDT internal format) and linear perpetuals (BTCUSDT internal
+// format) share one REST host but stream from separate WebSocket endpoints.
+// Requests are signed with HMAC-SHA256 (hex-encoded) over
+// timestamp + apiKey + recvWindow + payload.
+type Gateway struct {
+	spotWS   *wsClient
+	linearWS *wsClient
+	rest     *restClient
+	logger   *slog.Logger
+}
+
+// New creates a new Bybit gateway.
+// wsURL is the spot public stream; linearWsURL is the linear public stream
+// and may be empty, in which case perp market data is unavailable.
+func New(wsURL, linearWsURL, restURL, apiKey, apiSecret string, logger *slog.Logger) *Gateway {
+	// Bybit limits order create/amend/cancel per UID at 10-20 req/s and
+	// other private endpoints at ~10 req/s.
+	rl := gateway.NewRateLimiter()
+	rl.AddBucket(domain.EndpointPublicData, 50, 25)
+	rl.AddBucket(domain.EndpointPrivateData, 20, 10)
+	rl.AddBucket(domain.EndpointOrderPlace, 20, 10)
+	rl.AddBucket(domain.EndpointOrderCancel, 20, 10)
+	rl.AddBucket(domain.EndpointAccount, 10, 5)
+
+	g := &Gateway{
+		spotWS: newWSClient(wsURL, categorySpot, domain.BybitSpotSymbolMap, logger),
+		rest:   newRESTClient(restURL, apiKey, apiSecret, rl, logger),
+		logger: logger,
+	}
+	if linearWsURL != "" {
+		g.linearWS = newWSClient(linearWsURL, categoryLinear, domain.BybitFuturesSymbolMap, logger)
+	}
+	return g
+}
+
+func (g *Gateway) Name() string { return "bybit" }
+
+// SetAPIErrorObserver implements gateway.APIErrorReporter.
+func (g *Gateway) SetAPIErrorObserver(fn gateway.APIErrorObserver) {
+	g.rest.retrier.OnError = fn
+}
+
+// SetWSReconnectObserver implements gateway.WSReconnectReporter.
+func (g *Gateway) SetWSReconnectObserver(fn gateway.WSReconnectObserver) {
+	for _, ws := range []*wsClient{g.spotWS, g.linearWS} {
+		if ws != nil {
+			ws.onReconnect = func() { fn("bybit") }
+		}
+	}
+}
+
+// SetLatencyObserver implements gateway.LatencyReporter. Stream delays are
+// measured against the venue-synced clock.
+func (g *Gateway) SetLatencyObserver(fn gateway.LatencyObserver) {
+	g.rest.retrier.OnLatency = fn
+	observe := gateway.WSLatency(func(stream string, venueTime time.Time) {
+		fn("bybit", gateway.LatencyWS, stream, g.rest.clock.Now().Sub(venueTime))
+	})
+	for _, ws := range []*wsClient{g.spotWS, g.linearWS} {
+		if ws != nil {
+			ws.latency = observe
+		}
+	}
+}
+
+// SetEgress implements gateway.EgressConfigurable.
+func (g *Gateway) SetEgress(e gateway.Egress) {
+	e.ApplyClient(g.rest.httpClient)
+	for _, ws := range []*wsClient{g.spotWS, g.linearWS} {
+		if ws != nil {
+			ws.egress = e
+		}
+	}
+}
+
+// SetClockOffsetObserver implements gateway.ClockOffsetReporter.
+func (g *Gateway) SetClockOffsetObserver(fn gateway.ClockOffsetObserver) {
+	g.rest.clock.OnSync = fn
+}
+
+// SetSubAccount tags balances and positions with the Bybit sub-member the
+// API key belongs to; requests signed with a sub-member key act on that
+// sub-member's unified account. Call before Connect.
+func (g *Gateway) SetSubAccount(id string) {
+	g.rest.subAccount = id
+}
+
+// SetAPIKeys implements gateway.KeyRotator.
+func (g *Gateway) SetAPIKeys(keys []gateway.APIKey) error {
+	return g.rest.keys.SetKeys(keys)
+}
+
+func (g *Gateway) Connect(ctx context.Context) error {
+	if g.rest.keys.Signed() {
+		go g.rest.clock.Run(ctx, gateway.ClockSyncInterval, g.logger)
+	}
+	if err := g.spotWS.connect(ctx); err != nil {
+		return err
+	}
+	if g.linearWS != nil {
+		if err := g.linearWS.connect(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Gateway) Close() error {
+	var errs []error
+	errs = append(errs, g.spotWS.close())
+	if g.linearWS != nil {
+		errs = append(errs, g.linearWS.close())
+	}
+	return errors.Join(errs...)
+}
+
+// Health implements gateway.VenueGateway. The venue counts as connected only
+// while every market's stream is up.
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	var states []*gateway.WSState
+	for _, ws := range []*wsClient{g.spotWS, g.linearWS} {
+		if ws != nil {
+			states = append(states, &ws.state)
+		}
+	}
+	return gateway.ProbeHealth(ctx, "bybit", g.rest.ping, states...)
+}
+
+// wsFor returns the stream connection serving the given internal symbol.
+func (g *Gateway) wsFor(symbol string) (*wsClient, error) {
+	if !domain.IsBybitFutures(symbol) {
+		return g.spotWS, nil
+	}
+	if g.linearWS == nil {
+		return nil, fmt.Errorf("bybit linear stream not configured for %s", symbol)
+	}
+	return g.linearWS, nil
+}
+
+// SubscribeOrderBook streams the symbol's book: a snapshot that replaces it
+// on each (re)subscribe, then deltas chained on the update id, so the market
+// data service resyncs from GetOrderBookSnapshot when one goes missing.
+func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
+	ws, err := g.wsFor(symbol)
+	if err != nil {
+		return nil, err
+	}
+	venueSymbol := domain.MapBybitSymbol(symbol)
+	ch := ws.subscribeOrderBook(venueSymbol)
+	if err := ws.subscribe(ctx, "orderbook.50."+venueSymbol); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
+	ws, err := g.wsFor(symbol)
+	if err != nil {
+		return nil, err
+	}
+	venueSymbol := domain.MapBybitSymbol(symbol)
+	ch := ws.subscribeTrades(venueSymbol)
+	if err := ws.subscribe(ctx, "publicTrade."+venueSymbol); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) SubscribeFunding(ctx context.Context, symbol string) (<-chan domain.FundingRate, error) {
+	if !domain.IsBybitFutures(symbol) {
+		return nil, fmt.Errorf("bybit funding only available for perp symbols, got %s", symbol)
+	}
+	ws, err := g.wsFor(symbol)
+	if err != nil {
+		return nil, err
+	}
+	venueSymbol := domain.MapBybitSymbol(symbol)
+	ch := ws.subscribeFunding(venueSymbol)
+	if err := ws.subscribe(ctx, "tickers."+venueSymbol); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	return g.rest.placeOrder(ctx, req)
+}
+
+func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	return g.rest.cancelOrder(ctx, orderID)
+}
+
+// GetKlines implements gateway.MarketHistoryProvider.
+func (g *Gateway) GetKlines(ctx context.Context, symbol string, interval time.Duration, from, to time.Time) ([]domain.Kline, error) {
+	return g.rest.getKlines(ctx, symbol, interval, from, to)
+}
+
+// GetHistoricalTrades is unsupported: Bybit only serves the most recent
+// public trades, not a time range.
+func (g *Gateway) GetHistoricalTrades(_ context.Context, _ string, _, _ time.Time) ([]domain.Trade, error) {
+	return nil, gateway.ErrHistoryUnsupported
+}
+
+// GetFundingHistory implements gateway.MarketHistoryProvider for linear
+// symbols.
+func (g *Gateway) GetFundingHistory(ctx context.Context, symbol string, from, to time.Time) ([]domain.FundingRate, error) {
+	return g.rest.getFundingHistory(ctx, symbol, from, to)
+}
+
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrder(ctx, orderID)
+}
+
+// GetOrderByClientID implements gateway.ClientOrderLookup.
+func (g *Gateway) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrderByLinkID(ctx, symbol, clientOrderID)
+}
+
+// PlaceOrders uses create-batch, split by category into chunks of 10.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return g.rest.placeOrders(ctx, reqs)
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return g.rest.cancelOrders(ctx, orderIDs)
+}
+
+// AmendOrder is not wired to /v5/order/amend yet.
+func (g *Gateway) AmendOrder(_ context.Context, _ string, _, _ decimal.Decimal) (*domain.AmendAck, error) {
+	return nil, gateway.ErrAmendUnsupported
+}
+
+func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	return g.rest.getOpenOrders(ctx, symbol)
+}
+
+// SubscribeOrderUpdates is not implemented yet; fills are only seen via REST.
+func (g *Gateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
+
+func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	return g.rest.getBalances(ctx)
+}
+
+func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	return g.rest.getPositions(ctx)
+}
+
+func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	return g.rest.getFeeTier(ctx)
+}
+
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return g.rest.getInstruments(ctx)
+}
+
+func (g *Gateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return g.rest.keys.Status(), nil
+}
+
+// GetOrderBookSnapshot implements gateway.OrderBookSnapshotProvider.
+func (g *Gateway) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	return g.rest.getOrderBook(ctx, symbol)
+}
+
+// Withdraw, GetDepositAddress and GetTransferStatus are not wired to the
+// /v5/asset endpoints yet.
+func (g *Gateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetDepositAddress(_ context.Context, _, _ string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
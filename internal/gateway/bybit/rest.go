@@ -0,0 +1,1097 @@
+package bybit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// recvWindow bounds how long after its timestamp a signed request stays valid.
+const recvWindow = "5000"
+
+// errorCategories maps the Bybit v5 retCodes callers react to, for both
+// the linear (11xxxx) and spot (17xxxx) order endpoints.
+var errorCategories = map[int]gateway.ErrorCategory{
+	10006:  gateway.ErrorRateLimit,           // too many visits
+	10018:  gateway.ErrorRateLimit,           // IP rate limit exceeded
+	10003:  gateway.ErrorAuth,                // invalid API key
+	10004:  gateway.ErrorAuth,                // signature error
+	10005:  gateway.ErrorAuth,                // permission denied
+	33004:  gateway.ErrorAuth,                // API key expired
+	110004: gateway.ErrorInsufficientBalance, // wallet balance insufficient
+	110007: gateway.ErrorInsufficientBalance, // available balance not enough
+	170131: gateway.ErrorInsufficientBalance, // spot balance insufficient
+	110003: gateway.ErrorInvalidPrice,        // price out of the permitted range
+	170132: gateway.ErrorInvalidPrice,        // spot price too high
+	170133: gateway.ErrorInvalidPrice,        // spot price too low
+	170134: gateway.ErrorInvalidPrice,        // spot price has too many decimals
+	170136: gateway.ErrorInvalidSize,         // spot quantity has too many decimals
+	170140: gateway.ErrorInvalidSize,         // spot order value below the minimum
+	110072: gateway.ErrorDuplicateOrder,      // orderLinkId is duplicated
+	110017: gateway.ErrorReduceOnly,          // reduce-only rule not satisfied
+	170141: gateway.ErrorDuplicateOrder,      // spot duplicate client order ID
+	110001: gateway.ErrorOrderNotFound,       // order does not exist
+	170213: gateway.ErrorOrderNotFound,       // spot order does not exist
+	170121: gateway.ErrorSymbolUnavailable,   // invalid symbol
+}
+
+// apiError builds the error for a non-zero Bybit retCode.
+func apiError(what string, code int, msg string) error {
+	return &gateway.VenueError{
+		Venue:    "bybit",
+		Op:       what,
+		Code:     strconv.Itoa(code),
+		Message:  msg,
+		Category: errorCategories[code],
+	}
+}
+
+// Bybit v5 categories.
+const (
+	categorySpot   = "spot"
+	categoryLinear = "linear"
+)
+
+type restClient struct {
+	baseURL    string
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	// keys picks the API key, and with it the rate limit budget, for each
+	// request.
+	keys *gateway.KeyPool
+
+	// subAccount names the sub-account whose API key signs our requests.
+	subAccount string
+
+	// retrier retries transient REST failures and reports every failed attempt.
+	retrier gateway.RESTRetrier
+
+	// clock corrects the timestamp signed requests carry for drift from the
+	// venue's clock.
+	clock *gateway.ClockSync
+}
+
+func newRESTClient(baseURL, apiKey, apiSecret string, rl *gateway.RateLimiter, logger *slog.Logger) *restClient {
+	c := &restClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:       10,
+				IdleConnTimeout:    90 * time.Second,
+				DisableCompression: true,
+			},
+		},
+		logger:  logger,
+		keys:    gateway.NewKeyPool(rl, gateway.APIKey{Key: apiKey, Secret: apiSecret}),
+		retrier: gateway.NewRESTRetrier("bybit"),
+	}
+	c.clock = gateway.NewClockSync("bybit", c.serverTime)
+	return c
+}
+
+// sign creates a hex-encoded HMAC-SHA256 signature for Bybit v5.
+// The signature string is: timestamp + apiKey + recvWindow + (queryString | body)
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// observeRateLimit feeds the response back into rl. Bybit
+// reports the requests left on the endpoint in X-Bapi-Limit-Status and the
+// window end as a millisecond timestamp in X-Bapi-Limit-Reset-Timestamp.
+func observeRateLimit(rl *gateway.RateLimiter, category domain.EndpointCategory, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-Bapi-Limit-Status"))
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests {
+		rl.ObserveResponse(category, resp)
+		return
+	}
+	var reset time.Duration
+	if ms, err := strconv.ParseInt(resp.Header.Get("X-Bapi-Limit-Reset-Timestamp"), 10, 64); err == nil {
+		reset = time.Until(time.UnixMilli(ms))
+	}
+	rl.Observe(category, remaining, reset)
+}
+
+// doRequest sends a v5 request. GET parameters travel in the query string,
+// POST parameters in a JSON body; both are covered by the signature.
+func (c *restClient) doRequest(ctx context.Context, method, path string, query url.Values, body interface{}, category domain.EndpointCategory) ([]byte, error) {
+	result, _, err := c.doRequestExt(ctx, method, path, query, body, category)
+	return result, err
+}
+
+// doRequestExt is doRequest that also returns retExtInfo, where batch
+// endpoints report the per-item outcome. Transient failures are retried.
+func (c *restClient) doRequestExt(ctx context.Context, method, path string, query url.Values, body interface{}, category domain.EndpointCategory) ([]byte, []byte, error) {
+	var result, extInfo []byte
+	err := c.retrier.Do(ctx, category, func() error {
+		var err error
+		result, extInfo, err = c.send(ctx, method, path, query, body, category)
+		return err
+	})
+	return result, extInfo, err
+}
+
+// doRequestOnce is doRequest without retries, for order placements that
+// cannot be replayed.
+func (c *restClient) doRequestOnce(ctx context.Context, method, path string, query url.Values, body interface{}, category domain.EndpointCategory) ([]byte, error) {
+	result, _, err := c.doRequestExtOnce(ctx, method, path, query, body, category)
+	return result, err
+}
+
+// doRequestExtOnce is doRequestExt without retries.
+func (c *restClient) doRequestExtOnce(ctx context.Context, method, path string, query url.Values, body interface{}, category domain.EndpointCategory) ([]byte, []byte, error) {
+	var result, extInfo []byte
+	err := c.retrier.Once(ctx, category, func() error {
+		var err error
+		result, extInfo, err = c.send(ctx, method, path, query, body, category)
+		return err
+	})
+	return result, extInfo, err
+}
+
+// send makes one request attempt.
+func (c *restClient) send(ctx context.Context, method, path string, query url.Values, body interface{}, category domain.EndpointCategory) ([]byte, []byte, error) {
+	waited := time.Now()
+	key, err := c.keys.Acquire(ctx, category, 1)
+	c.retrier.ObserveRateLimitWait(category, time.Since(waited))
+	if err != nil {
+		return nil, nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	reqURL := c.baseURL + path
+	queryString := query.Encode()
+	if queryString != "" {
+		reqURL += "?" + queryString
+	}
+
+	var reqBody io.Reader
+	payload := queryString
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal body: %w", err)
+		}
+		payload = string(data)
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if key.Key != "" {
+		timestamp := strconv.FormatInt(c.clock.Now().UnixMilli(), 10)
+		req.Header.Set("X-BAPI-API-KEY", key.Key)
+		req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+		req.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+		req.Header.Set("X-BAPI-SIGN", sign(key.Secret, timestamp+key.Key+recvWindow+payload))
+	}
+
+	sent := time.Now()
+	resp, err := c.httpClient.Do(req)
+	c.retrier.ObserveLatency(category, time.Since(sent))
+	if err != nil {
+		return nil, nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	observeRateLimit(key.Limiter, category, resp)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, nil, gateway.NewHTTPError(resp, respBody)
+	}
+
+	// Bybit wraps all responses in {"retCode": 0, "retMsg": "OK", "result": ...}
+	var baseResp struct {
+		RetCode    int             `json:"retCode"`
+		RetMsg     string          `json:"retMsg"`
+		Result     json.RawMessage `json:"result"`
+		RetExtInfo json.RawMessage `json:"retExtInfo"`
+	}
+	if err := json.Unmarshal(respBody, &baseResp); err != nil {
+		return nil, nil, fmt.Errorf("parse response wrapper: %w", err)
+	}
+
+	if baseResp.RetCode != 0 {
+		return nil, nil, apiError("API error", baseResp.RetCode, baseResp.RetMsg)
+	}
+
+	return baseResp.Result, baseResp.RetExtInfo, nil
+}
+
+func categoryFor(symbol string) string {
+	if domain.IsBybitFutures(symbol) {
+		return categoryLinear
+	}
+	return categorySpot
+}
+
+// formatVenueOrderID encodes the category and symbol alongside the Bybit
+// order ID, since cancellation requires both.
+func formatVenueOrderID(category, venueSymbol, orderID string) string {
+	return category + ":" + venueSymbol + ":" + orderID
+}
+
+// parseVenueOrderID is the inverse of formatVenueOrderID.
+func parseVenueOrderID(venueID string) (category, venueSymbol, orderID string, err error) {
+	parts := strings.SplitN(venueID, ":", 3)
+	if len(parts) != 3 || (parts[0] != categorySpot && parts[0] != categoryLinear) {
+		return "", "", "", fmt.Errorf("invalid bybit order id %q", venueID)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	body, err := orderBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	send := c.doRequest
+	if !gateway.Replayable(req) {
+		send = c.doRequestOnce
+	}
+	data, err := send(ctx, "POST", "/v5/order/create", nil, body, domain.EndpointOrderPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse order response: %w", err)
+	}
+
+	return &domain.OrderAck{
+		InternalID: req.InternalID,
+		VenueID:    formatVenueOrderID(body["category"].(string), body["symbol"].(string), result.OrderID),
+		Status:     domain.OrderStatusAcknowledged,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// orderBody builds the create-order parameters for req.
+func orderBody(req domain.OrderRequest) (map[string]interface{}, error) {
+	if req.OrderType.IsStop() {
+		// Conditional orders are not mapped yet.
+		return nil, fmt.Errorf("%w: bybit %s", gateway.ErrOrderTypeUnsupported, req.OrderType)
+	}
+
+	venueSymbol := domain.MapBybitSymbol(req.Symbol)
+	category := categoryFor(req.Symbol)
+
+	side := "Buy"
+	if req.Side == domain.SideSell {
+		side = "Sell"
+	}
+
+	body := map[string]interface{}{
+		"category":    category,
+		"symbol":      venueSymbol,
+		"side":        side,
+		"qty":         req.Size.String(),
+		"orderLinkId": req.IdempotencyKey,
+	}
+
+	if req.OrderType == domain.OrderTypeLimit {
+		body["orderType"] = "Limit"
+		body["price"] = req.Price.String()
+		body["timeInForce"] = "GTC"
+		if req.PostOnly {
+			body["timeInForce"] = "PostOnly"
+		} else if req.TimeInForce != "" {
+			body["timeInForce"] = string(req.TimeInForce)
+		}
+	} else {
+		body["orderType"] = "Market"
+		if category == categorySpot {
+			// Spot market orders default to quote-denominated qty.
+			body["marketUnit"] = "baseCoin"
+		}
+	}
+	if req.ReduceOnly && category != categorySpot {
+		body["reduceOnly"] = true
+	}
+
+	return body, nil
+}
+
+// batchLimit is the most orders Bybit accepts in one batch request; spot
+// caps at 10, lower than linear's 20.
+const batchLimit = 10
+
+// batchItem is one order in a batch request, remembered with its position
+// in the caller's slice.
+type batchItem struct {
+	index int
+	body  map[string]interface{}
+}
+
+// batchResults decodes the result and retExtInfo lists of a batch response,
+// which are index-aligned with the request.
+type batchResults struct {
+	List []struct {
+		OrderID string `json:"orderId"`
+	} `json:"list"`
+}
+
+type batchExtInfo struct {
+	List []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"list"`
+}
+
+// doBatch sends items of one category to path in batchLimit chunks and calls
+// done with each item's venue order ID or error.
+func (c *restClient) doBatch(ctx context.Context, path, category string, items []batchItem, endpoint domain.EndpointCategory, replayable bool, done func(item batchItem, orderID string, err error)) {
+	send := c.doRequestExt
+	if !replayable {
+		send = c.doRequestExtOnce
+	}
+	for start := 0; start < len(items); start += batchLimit {
+		chunk := items[start:min(start+batchLimit, len(items))]
+		request := make([]map[string]interface{}, len(chunk))
+		for j, it := range chunk {
+			request[j] = it.body
+		}
+
+		var result batchResults
+		var ext batchExtInfo
+		data, extData, err := send(ctx, "POST", path, nil, map[string]interface{}{
+			"category": category,
+			"request":  request,
+		}, endpoint)
+		if err == nil {
+			err = json.Unmarshal(data, &result)
+		}
+		if err == nil && len(extData) > 0 {
+			err = json.Unmarshal(extData, &ext)
+		}
+
+		for j, it := range chunk {
+			switch {
+			case err != nil:
+				done(it, "", err)
+			case j < len(ext.List) && ext.List[j].Code != 0:
+				done(it, "", apiError("batch item rejected", ext.List[j].Code, ext.List[j].Msg))
+			case j >= len(result.List):
+				done(it, "", fmt.Errorf("missing batch result"))
+			default:
+				done(it, result.List[j].OrderID, nil)
+			}
+		}
+	}
+}
+
+// placeOrders groups orders by category, since a batch must not mix spot and
+// linear, and submits each group through create-batch.
+func (c *restClient) placeOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	results := make([]gateway.PlaceResult, len(reqs))
+	groups := make(map[string][]batchItem)
+	for i, req := range reqs {
+		body, err := orderBody(req)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		category := body["category"].(string)
+		delete(body, "category")
+		groups[category] = append(groups[category], batchItem{index: i, body: body})
+	}
+
+	for category, items := range groups {
+		c.doBatch(ctx, "/v5/order/create-batch", category, items, domain.EndpointOrderPlace, gateway.Replayable(reqs...), func(it batchItem, orderID string, err error) {
+			if err != nil {
+				results[it.index].Err = err
+				return
+			}
+			results[it.index].Ack = &domain.OrderAck{
+				InternalID: reqs[it.index].InternalID,
+				VenueID:    formatVenueOrderID(category, it.body["symbol"].(string), orderID),
+				Status:     domain.OrderStatusAcknowledged,
+				Timestamp:  time.Now(),
+			}
+		})
+	}
+	return results
+}
+
+// cancelOrders groups orders by category and cancels each group through
+// cancel-batch.
+func (c *restClient) cancelOrders(ctx context.Context, venueIDs []string) []gateway.CancelResult {
+	results := make([]gateway.CancelResult, len(venueIDs))
+	groups := make(map[string][]batchItem)
+	for i, venueID := range venueIDs {
+		category, venueSymbol, orderID, err := parseVenueOrderID(venueID)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		groups[category] = append(groups[category], batchItem{index: i, body: map[string]interface{}{
+			"symbol":  venueSymbol,
+			"orderId": orderID,
+		}})
+	}
+
+	for category, items := range groups {
+		c.doBatch(ctx, "/v5/order/cancel-batch", category, items, domain.EndpointOrderCancel, true, func(it batchItem, _ string, err error) {
+			if err != nil {
+				results[it.index].Err = err
+				return
+			}
+			results[it.index].Ack = &domain.CancelAck{
+				VenueID:   venueIDs[it.index],
+				Status:    domain.OrderStatusCancelled,
+				Timestamp: time.Now(),
+			}
+		})
+	}
+	return results
+}
+
+func (c *restClient) cancelOrder(ctx context.Context, venueID string) (*domain.CancelAck, error) {
+	category, venueSymbol, orderID, err := parseVenueOrderID(venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"category": category,
+		"symbol":   venueSymbol,
+		"orderId":  orderID,
+	}
+
+	if _, err := c.doRequest(ctx, "POST", "/v5/order/cancel", nil, body, domain.EndpointOrderCancel); err != nil {
+		return nil, err
+	}
+
+	return &domain.CancelAck{
+		VenueID:   venueID,
+		Status:    domain.OrderStatusCancelled,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// getOrder looks up one order. The realtime endpoint also returns orders
+// that finished recently, which covers the cancel it is used to confirm.
+func (c *restClient) getOrder(ctx context.Context, venueID string) (*domain.OrderUpdate, error) {
+	category, venueSymbol, orderID, err := parseVenueOrderID(venueID)
+	if err != nil {
+		return nil, err
+	}
+	update, err := c.queryOrder(ctx, category, venueSymbol, "orderId", orderID)
+	if err != nil {
+		return nil, err
+	}
+	update.VenueID = venueID
+	return update, nil
+}
+
+// getOrderByLinkID looks up the order placed on symbol with orderLinkID.
+func (c *restClient) getOrderByLinkID(ctx context.Context, symbol, orderLinkID string) (*domain.OrderUpdate, error) {
+	return c.queryOrder(ctx, categoryFor(symbol), domain.MapBybitSymbol(symbol), "orderLinkId", orderLinkID)
+}
+
+// queryOrder fetches the order whose orderId or orderLinkId, as key says,
+// is id.
+func (c *restClient) queryOrder(ctx context.Context, category, venueSymbol, key, id string) (*domain.OrderUpdate, error) {
+	query := url.Values{}
+	query.Set("category", category)
+	query.Set("symbol", venueSymbol)
+	query.Set(key, id)
+
+	data, err := c.doRequest(ctx, "GET", "/v5/order/realtime", query, nil, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			OrderID     string `json:"orderId"`
+			OrderLinkID string `json:"orderLinkId"`
+			CumExecQty  string `json:"cumExecQty"`
+			AvgPrice    string `json:"avgPrice"`
+			OrderStatus string `json:"orderStatus"`
+			UpdatedTime string `json:"updatedTime"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse order: %w", err)
+	}
+	if len(result.List) == 0 {
+		return nil, fmt.Errorf("bybit order %s %s not found", venueSymbol, id)
+	}
+	o := result.List[0]
+
+	update := &domain.OrderUpdate{
+		Venue:         "bybit",
+		VenueID:       formatVenueOrderID(category, venueSymbol, o.OrderID),
+		ClientOrderID: o.OrderLinkID,
+		Timestamp:     time.Now(),
+	}
+	if ms, err := strconv.ParseInt(o.UpdatedTime, 10, 64); err == nil && ms > 0 {
+		update.Timestamp = time.UnixMilli(ms)
+	}
+	update.FilledSize, _ = domain.ParseDecimal(o.CumExecQty)
+	update.AvgFillPrice, _ = domain.ParseDecimal(o.AvgPrice)
+
+	switch o.OrderStatus {
+	case "New", "Untriggered", "Triggered":
+		update.Status = domain.OrderStatusAcknowledged
+	case "PartiallyFilled":
+		update.Status = domain.OrderStatusPartialFill
+	case "Filled":
+		update.Status = domain.OrderStatusFilled
+	case "Cancelled", "PartiallyFilledCanceled", "Deactivated":
+		update.Status = domain.OrderStatusCancelled
+	case "Rejected":
+		update.Status = domain.OrderStatusRejected
+	default:
+		return nil, fmt.Errorf("unknown bybit order status %q", o.OrderStatus)
+	}
+	return update, nil
+}
+
+func (c *restClient) getOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	category := categoryFor(symbol)
+	query := url.Values{}
+	query.Set("category", category)
+	query.Set("symbol", domain.MapBybitSymbol(symbol))
+
+	data, err := c.doRequest(ctx, "GET", "/v5/order/realtime", query, nil, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			OrderID     string `json:"orderId"`
+			Symbol      string `json:"symbol"`
+			Side        string `json:"side"`
+			OrderType   string `json:"orderType"`
+			Price       string `json:"price"`
+			Qty         string `json:"qty"`
+			CumExecQty  string `json:"cumExecQty"`
+			OrderStatus string `json:"orderStatus"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse open orders: %w", err)
+	}
+
+	orders := make([]domain.Order, 0, len(result.List))
+	for _, o := range result.List {
+		side := domain.SideBuy
+		if o.Side == "Sell" {
+			side = domain.SideSell
+		}
+
+		orderType := domain.OrderTypeLimit
+		if o.OrderType == "Market" {
+			orderType = domain.OrderTypeMarket
+		}
+
+		status := domain.OrderStatusAcknowledged
+		if o.OrderStatus == "PartiallyFilled" {
+			status = domain.OrderStatusPartialFill
+		}
+
+		order := domain.Order{
+			VenueID:   formatVenueOrderID(category, o.Symbol, o.OrderID),
+			Venue:     "bybit",
+			Symbol:    symbol,
+			Side:      side,
+			OrderType: orderType,
+			Status:    status,
+		}
+		order.Price, _ = domain.ParseDecimal(o.Price)
+		order.Size, _ = domain.ParseDecimal(o.Qty)
+		order.FilledSize, _ = domain.ParseDecimal(o.CumExecQty)
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (c *restClient) getBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	query := url.Values{}
+	query.Set("accountType", "UNIFIED")
+
+	data, err := c.doRequest(ctx, "GET", "/v5/account/wallet-balance", query, nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			AccountType string `json:"accountType"`
+			Coin        []struct {
+				Coin          string `json:"coin"`
+				WalletBalance string `json:"walletBalance"`
+				Locked        string `json:"locked"`
+			} `json:"coin"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse wallet balance: %w", err)
+	}
+
+	balances := make(map[string]domain.Balance)
+	for _, acct := range result.List {
+		for _, coin := range acct.Coin {
+			bal := domain.Balance{
+				Venue:   "bybit",
+				Account: c.subAccount,
+				Asset:   coin.Coin,
+			}
+			bal.Total, _ = domain.ParseDecimal(coin.WalletBalance)
+			bal.Locked, _ = domain.ParseDecimal(coin.Locked)
+			bal.Free = bal.Total.Sub(bal.Locked)
+			balances[coin.Coin] = bal
+		}
+	}
+
+	return balances, nil
+}
+
+func (c *restClient) getPositions(ctx context.Context) ([]domain.Position, error) {
+	query := url.Values{}
+	query.Set("category", categoryLinear)
+	query.Set("settleCoin", "USDT")
+
+	data, err := c.doRequest(ctx, "GET", "/v5/position/list", query, nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			Symbol        string `json:"symbol"`
+			Side          string `json:"side"`
+			Size          string `json:"size"`
+			AvgPrice      string `json:"avgPrice"`
+			UnrealisedPnl string `json:"unrealisedPnl"`
+			PositionIM    string `json:"positionIM"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse positions: %w", err)
+	}
+
+	positions := make([]domain.Position, 0, len(result.List))
+	for _, p := range result.List {
+		size, _ := domain.ParseDecimal(p.Size)
+		if size.IsZero() {
+			continue
+		}
+		// Bybit reports an unsigned size with a Buy/Sell side.
+		if p.Side == "Sell" {
+			size = size.Neg()
+		}
+		pos := domain.Position{
+			Venue:          "bybit",
+			Account:        c.subAccount,
+			Asset:          domain.ReverseMapSymbol(p.Symbol, domain.BybitFuturesSymbolMap),
+			InstrumentType: domain.InstrumentPerp,
+			Size:           size,
+			UpdatedAt:      time.Now(),
+		}
+		pos.EntryPrice, _ = domain.ParseDecimal(p.AvgPrice)
+		pos.UnrealizedPnL, _ = domain.ParseDecimal(p.UnrealisedPnl)
+		pos.MarginUsed, _ = domain.ParseDecimal(p.PositionIM)
+		positions = append(positions, pos)
+	}
+
+	return positions, nil
+}
+
+func (c *restClient) getFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	query := url.Values{}
+	query.Set("category", categorySpot)
+	query.Set("symbol", "BTCUSDT")
+
+	data, err := c.doRequest(ctx, "GET", "/v5/account/fee-rate", query, nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			Symbol       string `json:"symbol"`
+			TakerFeeRate string `json:"takerFeeRate"`
+			MakerFeeRate string `json:"makerFeeRate"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse fee tier: %w", err)
+	}
+
+	tier := &domain.FeeTier{
+		Venue:     "bybit",
+		UpdatedAt: time.Now(),
+	}
+
+	// Fee rates are fractions (0.001 = 10 bps).
+	bps := decimal.NewFromInt(10000)
+	if len(result.List) > 0 {
+		maker, _ := domain.ParseDecimal(result.List[0].MakerFeeRate)
+		taker, _ := domain.ParseDecimal(result.List[0].TakerFeeRate)
+		tier.MakerFeeBps = maker.Mul(bps)
+		tier.TakerFeeBps = taker.Mul(bps)
+	}
+
+	return tier, nil
+}
+
+// getInstruments reads the price and lot size filters of every mapped spot
+// and linear symbol.
+func (c *restClient) getInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	spot, err := c.getInstrumentsInfo(ctx, categorySpot, domain.BybitSpotSymbolMap, domain.InstrumentSpot)
+	if err != nil {
+		return nil, err
+	}
+	perp, err := c.getInstrumentsInfo(ctx, categoryLinear, domain.BybitFuturesSymbolMap, domain.InstrumentPerp)
+	if err != nil {
+		return nil, err
+	}
+	return append(spot, perp...), nil
+}
+
+func (c *restClient) getInstrumentsInfo(ctx context.Context, category string, symbols map[string]string, instType domain.InstrumentType) ([]domain.Instrument, error) {
+	query := url.Values{}
+	query.Set("category", category)
+	query.Set("limit", "1000")
+
+	data, err := c.doRequest(ctx, "GET", "/v5/market/instruments-info", query, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			Symbol      string `json:"symbol"`
+			Status      string `json:"status"` // Trading, PreLaunch, Delivering, Closed
+			PriceFilter struct {
+				TickSize string `json:"tickSize"`
+			} `json:"priceFilter"`
+			LotSizeFilter struct {
+				BasePrecision    string `json:"basePrecision"` // spot
+				QtyStep          string `json:"qtyStep"`       // linear
+				MinOrderQty      string `json:"minOrderQty"`
+				MinOrderAmt      string `json:"minOrderAmt"`      // spot
+				MinNotionalValue string `json:"minNotionalValue"` // linear
+			} `json:"lotSizeFilter"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse instruments: %w", err)
+	}
+
+	internal := make(map[string]string, len(symbols))
+	for k, v := range symbols {
+		internal[v] = k
+	}
+
+	var out []domain.Instrument
+	for _, s := range result.List {
+		symbol, ok := internal[s.Symbol]
+		if !ok {
+			continue
+		}
+		lot := s.LotSizeFilter
+		step, minNotional := lot.QtyStep, lot.MinNotionalValue
+		if category == categorySpot {
+			step, minNotional = lot.BasePrecision, lot.MinOrderAmt
+		}
+		inst := domain.Instrument{
+			Venue:              "bybit",
+			Symbol:             symbol,
+			InstrumentType:     instType,
+			ContractMultiplier: decimal.NewFromInt(1),
+			Suspended:          s.Status != "Trading",
+		}
+		inst.TickSize, _ = domain.ParseDecimal(s.PriceFilter.TickSize)
+		inst.StepSize, _ = domain.ParseDecimal(step)
+		inst.MinSize, _ = domain.ParseDecimal(lot.MinOrderQty)
+		inst.MinNotional, _ = domain.ParseDecimal(minNotional)
+		out = append(out, inst)
+	}
+	return out, nil
+}
+
+func (c *restClient) getOrderBook(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	query := url.Values{}
+	query.Set("category", categoryFor(symbol))
+	query.Set("symbol", domain.MapBybitSymbol(symbol))
+	query.Set("limit", "50")
+
+	data, err := c.doRequest(ctx, "GET", "/v5/market/orderbook", query, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Symbol   string     `json:"s"`
+		Bids     [][]string `json:"b"`
+		Asks     [][]string `json:"a"`
+		Ts       int64      `json:"ts"`
+		UpdateID uint64     `json:"u"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse orderbook: %w", err)
+	}
+
+	return &domain.OrderBookSnapshot{
+		Venue:          "bybit",
+		Symbol:         symbol,
+		Bids:           parseLevels(result.Bids),
+		Asks:           parseLevels(result.Asks),
+		Sequence:       result.UpdateID,
+		VenueTimestamp: time.UnixMilli(result.Ts),
+		LocalTimestamp: time.Now(),
+	}, nil
+}
+
+func (c *restClient) getFundingRate(ctx context.Context, symbol string) (*domain.FundingRate, error) {
+	query := url.Values{}
+	query.Set("category", categoryLinear)
+	query.Set("symbol", domain.MapBybitSymbol(symbol))
+
+	data, err := c.doRequest(ctx, "GET", "/v5/market/tickers", query, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			FundingRate     string `json:"fundingRate"`
+			NextFundingTime string `json:"nextFundingTime"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse funding rate: %w", err)
+	}
+	if len(result.List) == 0 {
+		return nil, fmt.Errorf("no ticker returned for %s", symbol)
+	}
+
+	rate := &domain.FundingRate{
+		Venue:     "bybit",
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+	}
+	rate.Rate, _ = domain.ParseDecimal(result.List[0].FundingRate)
+	if next, err := strconv.ParseInt(result.List[0].NextFundingTime, 10, 64); err == nil {
+		rate.NextTime = time.UnixMilli(next)
+	}
+
+	return rate, nil
+}
+
+// klineLimit and fundingLimit are the most klines and funding rates Bybit
+// returns per history request. It returns the latest of a range first, so
+// requests are cut to a window that fits in one page: for funding,
+// fundingLimit hours, the shortest funding interval Bybit uses.
+const (
+	klineLimit   = 1000
+	fundingLimit = 200
+)
+
+// klineIntervals names the kline intervals Bybit serves.
+var klineIntervals = map[time.Duration]string{
+	time.Minute:      "1",
+	5 * time.Minute:  "5",
+	15 * time.Minute: "15",
+	time.Hour:        "60",
+	4 * time.Hour:    "240",
+	24 * time.Hour:   "D",
+}
+
+// historyQuery starts a history request for symbol; callers add the range,
+// whose end Bybit takes as inclusive.
+func historyQuery(category, symbol string, limit int) url.Values {
+	query := url.Values{}
+	query.Set("category", category)
+	query.Set("symbol", domain.MapBybitSymbol(symbol))
+	query.Set("limit", strconv.Itoa(limit))
+	return query
+}
+
+// firstPage calls fetch on successive windows of [from, to) until one
+// returns something or the range ends.
+func firstPage[T any](from, to time.Time, window time.Duration, fetch func(from, to time.Time) ([]T, error)) ([]T, error) {
+	for from.Before(to) {
+		end := from.Add(window)
+		if end.After(to) {
+			end = to
+		}
+		items, err := fetch(from, end)
+		if err != nil || len(items) > 0 {
+			return items, err
+		}
+		from = end
+	}
+	return nil, nil
+}
+
+func (c *restClient) getKlines(ctx context.Context, symbol string, interval time.Duration, from, to time.Time) ([]domain.Kline, error) {
+	name, ok := klineIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: bybit %s klines", gateway.ErrHistoryUnsupported, interval)
+	}
+	return firstPage(from, to, klineLimit*interval, func(from, to time.Time) ([]domain.Kline, error) {
+		return c.getKlinePage(ctx, symbol, name, interval, from, to)
+	})
+}
+
+func (c *restClient) getKlinePage(ctx context.Context, symbol, name string, interval time.Duration, from, to time.Time) ([]domain.Kline, error) {
+	query := historyQuery(categoryFor(symbol), symbol, klineLimit)
+	query.Set("interval", name)
+	query.Set("start", strconv.FormatInt(from.UnixMilli(), 10))
+	query.Set("end", strconv.FormatInt(to.UnixMilli()-1, 10))
+
+	data, err := c.doRequest(ctx, "GET", "/v5/market/kline", query, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each kline is [startTime, open, high, low, close, volume, turnover].
+	var result struct {
+		List [][]string `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse klines: %w", err)
+	}
+	klines := make([]domain.Kline, 0, len(result.List))
+	for i := len(result.List) - 1; i >= 0; i-- {
+		row := result.List[i]
+		if len(row) < 6 {
+			return nil, fmt.Errorf("parse klines: short row of %d fields", len(row))
+		}
+		start, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse kline start time: %w", err)
+		}
+		k := domain.Kline{
+			Venue:    "bybit",
+			Symbol:   symbol,
+			Interval: interval,
+			OpenTime: time.UnixMilli(start),
+		}
+		k.Open, _ = domain.ParseDecimal(row[1])
+		k.High, _ = domain.ParseDecimal(row[2])
+		k.Low, _ = domain.ParseDecimal(row[3])
+		k.Close, _ = domain.ParseDecimal(row[4])
+		k.Volume, _ = domain.ParseDecimal(row[5])
+		klines = append(klines, k)
+	}
+	return klines, nil
+}
+
+func (c *restClient) getFundingHistory(ctx context.Context, symbol string, from, to time.Time) ([]domain.FundingRate, error) {
+	if categoryFor(symbol) != categoryLinear {
+		return nil, fmt.Errorf("%w: bybit funding for spot %s", gateway.ErrHistoryUnsupported, symbol)
+	}
+	return firstPage(from, to, fundingLimit*time.Hour, func(from, to time.Time) ([]domain.FundingRate, error) {
+		return c.getFundingPage(ctx, symbol, from, to)
+	})
+}
+
+func (c *restClient) getFundingPage(ctx context.Context, symbol string, from, to time.Time) ([]domain.FundingRate, error) {
+	query := historyQuery(categoryLinear, symbol, fundingLimit)
+	query.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+	query.Set("endTime", strconv.FormatInt(to.UnixMilli()-1, 10))
+
+	data, err := c.doRequest(ctx, "GET", "/v5/market/funding/history", query, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			FundingRate          string `json:"fundingRate"`
+			FundingRateTimestamp string `json:"fundingRateTimestamp"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse funding history: %w", err)
+	}
+	rates := make([]domain.FundingRate, 0, len(result.List))
+	for i := len(result.List) - 1; i >= 0; i-- {
+		row := result.List[i]
+		ts, err := strconv.ParseInt(row.FundingRateTimestamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse funding time: %w", err)
+		}
+		r := domain.FundingRate{
+			Venue:     "bybit",
+			Symbol:    symbol,
+			Timestamp: time.UnixMilli(ts),
+		}
+		r.Rate, _ = domain.ParseDecimal(row.FundingRate)
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
+
+func parseLevels(raw [][]string) []domain.PriceLevel {
+	levels := make([]domain.PriceLevel, 0, len(raw))
+	for _, lvl := range raw {
+		if len(lvl) >= 2 {
+			price, _ := domain.ParseDecimal(lvl[0])
+			size, _ := domain.ParseDecimal(lvl[1])
+			levels = append(levels, domain.PriceLevel{Price: price, Size: size})
+		}
+	}
+	return levels
+}
+
+// ping checks that the public REST API answers.
+func (c *restClient) ping(ctx context.Context) error {
+	return gateway.ProbeREST(ctx, c.httpClient, c.baseURL+"/v5/market/time")
+}
+
+// serverTime returns Bybit's clock, used to correct signing timestamps.
+func (c *restClient) serverTime(ctx context.Context) (time.Time, error) {
+	var resp struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Time    int64  `json:"time"`
+	}
+	if err := gateway.FetchJSON(ctx, c.httpClient, c.baseURL+"/v5/market/time", &resp); err != nil {
+		return time.Time{}, err
+	}
+	if resp.RetCode != 0 {
+		return time.Time{}, apiError("API error", resp.RetCode, resp.RetMsg)
+	}
+	return time.UnixMilli(resp.Time), nil
+}
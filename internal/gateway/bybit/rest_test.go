@@ -0,0 +1,499 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+func bybitOK(result interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"retCode": 0,
+		"retMsg":  "OK",
+		"result":  result,
+	}
+}
+
+func newTestRESTClient(handler http.Handler) (*restClient, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	rl := gateway.NewRateLimiter()
+	rl.AddBucket(domain.EndpointPublicData, 100, 100)
+	rl.AddBucket(domain.EndpointPrivateData, 100, 100)
+	rl.AddBucket(domain.EndpointOrderPlace, 100, 100)
+	rl.AddBucket(domain.EndpointOrderCancel, 100, 100)
+	rl.AddBucket(domain.EndpointAccount, 100, 100)
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	client := newRESTClient(server.URL, "test-api-key", "test-api-secret", rl, logger)
+	return client, server
+}
+
+func TestBybitRestClient_PlaceOrder_SpotLimitOrder(t *testing.T) {
+	var capturedReq *http.Request
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{
+			"orderId":     "1321003749386327552",
+			"orderLinkId": "idem-123",
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID:     uuid.Must(uuid.NewV7()),
+		Symbol:         "BTC/USDT",
+		Side:           domain.SideBuy,
+		OrderType:      domain.OrderTypeLimit,
+		Price:          decimal.NewFromInt(50000),
+		Size:           decimal.NewFromFloat(0.1),
+		IdempotencyKey: "idem-123",
+	}
+
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedReq.URL.Path != "/v5/order/create" {
+		t.Errorf("expected path /v5/order/create, got %s", capturedReq.URL.Path)
+	}
+	if capturedReq.Method != "POST" {
+		t.Errorf("expected POST, got %s", capturedReq.Method)
+	}
+
+	// Verify Bybit auth headers
+	if capturedReq.Header.Get("X-BAPI-API-KEY") != "test-api-key" {
+		t.Errorf("expected X-BAPI-API-KEY header, got %q", capturedReq.Header.Get("X-BAPI-API-KEY"))
+	}
+	if capturedReq.Header.Get("X-BAPI-SIGN") == "" {
+		t.Error("expected X-BAPI-SIGN header to be set")
+	}
+	if capturedReq.Header.Get("X-BAPI-TIMESTAMP") == "" {
+		t.Error("expected X-BAPI-TIMESTAMP header to be set")
+	}
+	if capturedReq.Header.Get("X-BAPI-RECV-WINDOW") != recvWindow {
+		t.Errorf("expected X-BAPI-RECV-WINDOW=%s, got %q", recvWindow, capturedReq.Header.Get("X-BAPI-RECV-WINDOW"))
+	}
+
+	if capturedBody["category"] != "spot" {
+		t.Errorf("expected category=spot, got %v", capturedBody["category"])
+	}
+	if capturedBody["symbol"] != "BTCUSDT" {
+		t.Errorf("expected symbol BTCUSDT, got %v", capturedBody["symbol"])
+	}
+	if capturedBody["side"] != "Buy" || capturedBody["orderType"] != "Limit" {
+		t.Errorf("expected Buy Limit, got %v %v", capturedBody["side"], capturedBody["orderType"])
+	}
+	if capturedBody["orderLinkId"] != "idem-123" {
+		t.Errorf("expected orderLinkId=idem-123, got %v", capturedBody["orderLinkId"])
+	}
+
+	if ack.VenueID != "spot:BTCUSDT:1321003749386327552" {
+		t.Errorf("expected encoded venue ID, got %s", ack.VenueID)
+	}
+	if ack.Status != domain.OrderStatusAcknowledged {
+		t.Errorf("expected ACKNOWLEDGED, got %s", ack.Status)
+	}
+}
+
+func TestBybitRestClient_PlaceOrder_LinearMarketOrder(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{"orderId": "fut-1"}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID: uuid.Must(uuid.NewV7()),
+		Symbol:     "BTCUSDT",
+		Side:       domain.SideSell,
+		OrderType:  domain.OrderTypeMarket,
+		Size:       decimal.NewFromFloat(0.5),
+		ReduceOnly: true,
+	}
+
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedBody["category"] != "linear" {
+		t.Errorf("expected category=linear, got %v", capturedBody["category"])
+	}
+	if capturedBody["orderType"] != "Market" || capturedBody["side"] != "Sell" {
+		t.Errorf("expected Sell Market, got %v %v", capturedBody["side"], capturedBody["orderType"])
+	}
+	if _, ok := capturedBody["price"]; ok {
+		t.Error("expected no price on market order")
+	}
+	if capturedBody["reduceOnly"] != true {
+		t.Errorf("expected reduceOnly=true, got %v", capturedBody["reduceOnly"])
+	}
+	if ack.VenueID != "linear:BTCUSDT:fut-1" {
+		t.Errorf("expected linear:BTCUSDT:fut-1, got %s", ack.VenueID)
+	}
+}
+
+func TestBybitRestClient_CancelOrder(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/order/cancel" {
+			t.Errorf("expected path /v5/order/cancel, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{"orderId": "fut-1"}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	ack, err := client.cancelOrder(context.Background(), "linear:BTCUSDT:fut-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedBody["category"] != "linear" || capturedBody["symbol"] != "BTCUSDT" || capturedBody["orderId"] != "fut-1" {
+		t.Errorf("unexpected cancel body: %v", capturedBody)
+	}
+	if ack.Status != domain.OrderStatusCancelled {
+		t.Errorf("expected CANCELLED, got %s", ack.Status)
+	}
+
+	if _, err := client.cancelOrder(context.Background(), "fut-1"); err == nil {
+		t.Error("expected error for order ID without category and symbol")
+	}
+}
+
+func TestBybitRestClient_PlaceOrders(t *testing.T) {
+	bodies := make(map[string]map[string]interface{})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/order/create-batch" {
+			t.Errorf("expected path /v5/order/create-batch, got %s", r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		category := body["category"].(string)
+		bodies[category] = body
+
+		resp := bybitOK(map[string]interface{}{
+			"list": []map[string]interface{}{{"orderId": category + "-1"}},
+		})
+		code := 0
+		if category == "linear" {
+			code = 110007
+		}
+		resp["retExtInfo"] = map[string]interface{}{
+			"list": []map[string]interface{}{{"code": code, "msg": "ab not enough for new order"}},
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	reqs := []domain.OrderRequest{
+		{
+			InternalID: uuid.Must(uuid.NewV7()),
+			Symbol:     "BTC/USDT",
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(50000),
+			Size:       decimal.NewFromFloat(0.1),
+		},
+		{
+			InternalID: uuid.Must(uuid.NewV7()),
+			Symbol:     "BTCUSDT",
+			Side:       domain.SideSell,
+			OrderType:  domain.OrderTypeMarket,
+			Size:       decimal.NewFromFloat(0.1),
+		},
+	}
+
+	results := client.placeOrders(context.Background(), reqs)
+
+	// Spot and linear cannot share a batch.
+	if len(bodies) != 2 {
+		t.Fatalf("expected one batch per category, got %d", len(bodies))
+	}
+	spot := bodies["spot"]["request"].([]interface{})
+	if len(spot) != 1 || spot[0].(map[string]interface{})["category"] != nil {
+		t.Errorf("unexpected spot batch: %v", spot)
+	}
+
+	if results[0].Err != nil || results[0].Ack.VenueID != "spot:BTCUSDT:spot-1" {
+		t.Errorf("leg 0: got %+v", results[0])
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "110007") {
+		t.Errorf("leg 1: expected per-item rejection, got %v", results[1].Err)
+	}
+}
+
+func TestBybitRestClient_GetBalances(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("accountType") != "UNIFIED" {
+			t.Errorf("expected accountType=UNIFIED, got %s", r.URL.Query().Get("accountType"))
+		}
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{
+			"list": []map[string]interface{}{
+				{
+					"accountType": "UNIFIED",
+					"coin": []map[string]interface{}{
+						{"coin": "USDT", "walletBalance": "10000", "locked": "2500"},
+						{"coin": "BTC", "walletBalance": "1.2", "locked": "0"},
+					},
+				},
+			},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	balances, err := client.getBalances(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	usdt := balances["USDT"]
+	if !usdt.Free.Equal(decimal.NewFromInt(7500)) {
+		t.Errorf("expected USDT free 7500, got %s", usdt.Free)
+	}
+	if !usdt.Total.Equal(decimal.NewFromInt(10000)) {
+		t.Errorf("expected USDT total 10000, got %s", usdt.Total)
+	}
+	if balances["BTC"].Venue != "bybit" {
+		t.Errorf("expected venue bybit, got %s", balances["BTC"].Venue)
+	}
+}
+
+func TestBybitRestClient_GetPositions(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{
+			"list": []map[string]interface{}{
+				{"symbol": "BTCUSDT", "side": "Sell", "size": "0.3", "avgPrice": "61000", "unrealisedPnl": "-4.2", "positionIM": "1830"},
+				{"symbol": "ETHUSDT", "side": "", "size": "0", "avgPrice": "0", "unrealisedPnl": "0", "positionIM": "0"},
+			},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+	client.subAccount = "basis"
+
+	positions, err := client.getPositions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(positions) != 1 {
+		t.Fatalf("expected 1 open position, got %d", len(positions))
+	}
+	p := positions[0]
+	if !p.Size.Equal(decimal.NewFromFloat(-0.3)) {
+		t.Errorf("expected short size -0.3, got %s", p.Size)
+	}
+	if !p.MarginUsed.Equal(decimal.NewFromInt(1830)) {
+		t.Errorf("expected margin 1830, got %s", p.MarginUsed)
+	}
+	if p.Account != "basis" {
+		t.Errorf("expected position tagged with sub-account basis, got %q", p.Account)
+	}
+}
+
+func TestBybitRestClient_GetOpenOrders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("category") != "spot" || q.Get("symbol") != "ETHUSDT" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{
+			"list": []map[string]interface{}{
+				{"orderId": "o1", "symbol": "ETHUSDT", "side": "Buy", "orderType": "Limit", "price": "3000", "qty": "2", "cumExecQty": "0.5", "orderStatus": "PartiallyFilled"},
+			},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	orders, err := client.getOpenOrders(context.Background(), "ETH/USDT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(orders) != 1 {
+		t.Fatalf("expected 1 order, got %d", len(orders))
+	}
+	if orders[0].VenueID != "spot:ETHUSDT:o1" || orders[0].Status != domain.OrderStatusPartialFill {
+		t.Errorf("unexpected order: %+v", orders[0])
+	}
+}
+
+func TestBybitRestClient_GetFeeTier(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{
+			"list": []map[string]interface{}{
+				{"symbol": "BTCUSDT", "takerFeeRate": "0.001", "makerFeeRate": "0.0008"},
+			},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	tier, err := client.getFeeTier(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !tier.MakerFeeBps.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("expected maker 8 bps, got %s", tier.MakerFeeBps)
+	}
+	if !tier.TakerFeeBps.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected taker 10 bps, got %s", tier.TakerFeeBps)
+	}
+}
+
+func TestBybitRestClient_APIError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"retCode": 10001,
+			"retMsg":  "params error",
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	_, err := client.getBalances(context.Background())
+	if err == nil {
+		t.Fatal("expected error for non-zero retCode")
+	}
+	if !strings.Contains(err.Error(), "code=10001") {
+		t.Errorf("expected error to contain code=10001, got %v", err)
+	}
+}
+
+func TestBybitRestClient_SignatureCoversQuery(t *testing.T) {
+	var capturedReq *http.Request
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{"list": []interface{}{}}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	if _, err := client.getPositions(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ts := capturedReq.Header.Get("X-BAPI-TIMESTAMP")
+	want := sign("test-api-secret", ts+"test-api-key"+recvWindow+capturedReq.URL.RawQuery)
+	if got := capturedReq.Header.Get("X-BAPI-SIGN"); got != want {
+		t.Errorf("X-BAPI-SIGN = %s, want %s", got, want)
+	}
+}
+
+func TestBybitRestClient_GetOrderBook(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{
+			"s":  "BTCUSDT",
+			"b":  [][]string{{"50000", "1.5"}},
+			"a":  [][]string{{"50001", "1.0"}, {"50002", "3.0"}},
+			"ts": 1700000000000,
+			"u":  42,
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	book, err := client.getOrderBook(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if book.Symbol != "BTCUSDT" || book.Sequence != 42 {
+		t.Errorf("unexpected book header: %s seq=%d", book.Symbol, book.Sequence)
+	}
+	if len(book.Bids) != 1 || len(book.Asks) != 2 {
+		t.Fatalf("expected 1 bid and 2 asks, got %d/%d", len(book.Bids), len(book.Asks))
+	}
+}
+
+func TestBybitRestClient_MarketHistory(t *testing.T) {
+	var captured []*http.Request
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = append(captured, r)
+		switch r.URL.Path {
+		case "/v5/market/kline":
+			json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{"list": [][]string{
+				{"1700000060000", "37005", "37020", "37000", "37010", "3.5", "129535"},
+				{"1700000000000", "37000", "37010", "36990", "37005", "2.5", "92512"},
+			}}))
+		case "/v5/market/funding/history":
+			json.NewEncoder(w).Encode(bybitOK(map[string]interface{}{"list": []map[string]string{
+				{"symbol": "BTCUSDT", "fundingRate": "-0.0002", "fundingRateTimestamp": "1700028800000"},
+				{"symbol": "BTCUSDT", "fundingRate": "0.0001", "fundingRateTimestamp": "1700000000000"},
+			}}))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	from := time.UnixMilli(1700000000000)
+	to := from.Add(30 * 24 * time.Hour)
+	ctx := context.Background()
+
+	klines, err := client.getKlines(ctx, "BTCUSDT", time.Minute, from, to)
+	if err != nil {
+		t.Fatalf("klines: %v", err)
+	}
+	q := captured[0].URL.Query()
+	// The range is cut to the 1000 minutes one page holds.
+	if q.Get("category") != "linear" || q.Get("interval") != "1" || q.Get("end") != "1700059999999" {
+		t.Errorf("unexpected klines query %s", captured[0].URL.RawQuery)
+	}
+	if len(klines) != 2 || !klines[0].OpenTime.Equal(from) || !klines[1].Close.Equal(decimal.NewFromInt(37010)) {
+		t.Errorf("expected klines oldest first, got %+v", klines)
+	}
+
+	rates, err := client.getFundingHistory(ctx, "BTCUSDT", from, to)
+	if err != nil {
+		t.Fatalf("funding: %v", err)
+	}
+	if got := captured[1].URL.Query().Get("endTime"); got != "1700719999999" {
+		t.Errorf("expected the funding range cut to 200 hours, got endTime %s", got)
+	}
+	if len(rates) != 2 || !rates[0].Timestamp.Equal(from) || !rates[1].Rate.Equal(decimal.RequireFromString("-0.0002")) {
+		t.Errorf("expected funding rates oldest first, got %+v", rates)
+	}
+}
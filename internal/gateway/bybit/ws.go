@@ -0,0 +1,422 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// wsClient manages one Bybit v5 public stream. Spot and linear perpetuals
+// are served from different endpoints, so the gateway runs one client per
+// category.
+type wsClient struct {
+	url       string
+	category  string
+	symbolMap map[string]string
+	conn      *websocket.Conn
+	mu        sync.Mutex
+	logger    *slog.Logger
+
+	reconnectMax  time.Duration
+	reconnectBase time.Duration
+	maxFailures   int
+
+	subscriptions []string
+	subMu         sync.Mutex
+	onReconnect   func() // called after each successful reconnect, if set
+	state         gateway.WSState
+	egress        gateway.Egress
+	latency       gateway.WSLatency
+	pingInterval  time.Duration
+	stopPing      chan struct{}
+	pumpOnce      sync.Once
+
+	orderBookChans map[string]chan domain.OrderBookDelta
+	tradeChans     map[string]chan domain.Trade
+	fundingChans   map[string]chan domain.FundingRate
+	chanMu         sync.RWMutex
+
+	// lastUpdateID is the last book update id seen per venue symbol, so each
+	// delta can say where it follows on from. Only the read loop touches it.
+	lastUpdateID map[string]uint64
+}
+
+func newWSClient(url, category string, symbolMap map[string]string, logger *slog.Logger) *wsClient {
+	return &wsClient{
+		url:            url,
+		category:       category,
+		symbolMap:      symbolMap,
+		logger:         logger,
+		reconnectBase:  100 * time.Millisecond,
+		reconnectMax:   30 * time.Second,
+		maxFailures:    5,
+		pingInterval:   20 * time.Second,
+		orderBookChans: make(map[string]chan domain.OrderBookDelta),
+		tradeChans:     make(map[string]chan domain.Trade),
+		fundingChans:   make(map[string]chan domain.FundingRate),
+		lastUpdateID:   make(map[string]uint64),
+	}
+}
+
+func (ws *wsClient) connect(ctx context.Context) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	dialer := ws.egress.WSDialer()
+
+	conn, _, err := dialer.DialContext(ctx, ws.url, nil)
+	if err != nil {
+		return fmt.Errorf("websocket connect to %s: %w", ws.url, err)
+	}
+
+	if ws.stopPing != nil {
+		close(ws.stopPing)
+	}
+	ws.conn = conn
+	ws.state.SetConnected(true)
+	ws.stopPing = make(chan struct{})
+	go ws.pingLoop(ws.stopPing)
+
+	ws.logger.Info("bybit websocket connected", "category", ws.category, "url", ws.url)
+	return nil
+}
+
+// pingLoop keeps the connection alive; Bybit drops idle connections after
+// missing application-level pings.
+func (ws *wsClient) pingLoop(stop chan struct{}) {
+	ticker := time.NewTicker(ws.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ws.mu.Lock()
+			if ws.conn != nil {
+				if err := ws.conn.WriteJSON(map[string]string{"op": "ping"}); err != nil {
+					ws.logger.Warn("bybit websocket ping failed", "category", ws.category, "error", err)
+				}
+			}
+			ws.mu.Unlock()
+		}
+	}
+}
+
+func (ws *wsClient) reconnect(ctx context.Context) error {
+	delay := ws.reconnectBase
+	for i := 0; i < ws.maxFailures; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if err := ws.connect(ctx); err != nil {
+			ws.logger.Warn("bybit reconnect attempt failed",
+				"category", ws.category, "attempt", i+1, "error", err)
+			delay *= 2
+			if delay > ws.reconnectMax {
+				delay = ws.reconnectMax
+			}
+			continue
+		}
+		if subs := ws.activeSubscriptions(); len(subs) > 0 {
+			if err := ws.sendSubscribe(subs...); err != nil {
+				ws.logger.Warn("failed to resubscribe after reconnect",
+					"category", ws.category, "error", err)
+			}
+		}
+		if ws.onReconnect != nil {
+			ws.onReconnect()
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to reconnect after %d attempts", ws.maxFailures)
+}
+
+// activeSubscriptions returns a copy of the subscriptions to restore after a
+// reconnect.
+func (ws *wsClient) activeSubscriptions() []string {
+	ws.subMu.Lock()
+	defer ws.subMu.Unlock()
+	subs := make([]string, len(ws.subscriptions))
+	copy(subs, ws.subscriptions)
+	return subs
+}
+
+// subscribe registers a topic (e.g. "orderbook.50.BTCUSDT") and starts the
+// read pump on first use.
+func (ws *wsClient) subscribe(ctx context.Context, topic string) error {
+	ws.subMu.Lock()
+	ws.subscriptions = append(ws.subscriptions, topic)
+	ws.subMu.Unlock()
+	if err := ws.sendSubscribe(topic); err != nil {
+		return err
+	}
+	ws.pumpOnce.Do(func() { go ws.readPump(ctx) })
+	return nil
+}
+
+func (ws *wsClient) sendSubscribe(topics ...string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.conn == nil {
+		return fmt.Errorf("websocket not connected")
+	}
+
+	msg := map[string]interface{}{
+		"op":   "subscribe",
+		"args": topics,
+	}
+	return ws.conn.WriteJSON(msg)
+}
+
+func (ws *wsClient) readPump(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		ws.mu.Lock()
+		conn := ws.conn
+		ws.mu.Unlock()
+
+		if conn == nil {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			ws.state.SetConnected(false)
+			ws.logger.Error("bybit websocket read error", "category", ws.category, "error", err)
+			if reconnErr := ws.reconnect(ctx); reconnErr != nil {
+				ws.logger.Error("bybit reconnection failed permanently", "category", ws.category, "error", reconnErr)
+				return
+			}
+			continue
+		}
+
+		ws.state.Touch()
+		ws.handleMessage(message)
+	}
+}
+
+func (ws *wsClient) handleMessage(msg []byte) {
+	var raw struct {
+		Topic string          `json:"topic"`
+		Type  string          `json:"type"`
+		Ts    int64           `json:"ts"`
+		Data  json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		ws.logger.Debug("failed to parse bybit websocket message", "error", err)
+		return
+	}
+
+	// Op responses (subscribe acks, pongs) carry no topic.
+	if raw.Topic == "" {
+		return
+	}
+
+	// Topic format: "orderbook.50.BTCUSDT", "publicTrade.BTCUSDT", "tickers.BTCUSDT"
+	idx := strings.LastIndexByte(raw.Topic, '.')
+	if idx < 0 {
+		return
+	}
+	venueSymbol := raw.Topic[idx+1:]
+
+	switch {
+	case strings.HasPrefix(raw.Topic, "orderbook."):
+		ws.handleOrderBookMessage(venueSymbol, raw.Type, raw.Ts, raw.Data)
+	case strings.HasPrefix(raw.Topic, "publicTrade."):
+		ws.handleTradeMessage(venueSymbol, raw.Data)
+	case strings.HasPrefix(raw.Topic, "tickers."):
+		ws.handleFundingMessage(venueSymbol, raw.Ts, raw.Data)
+	}
+}
+
+func (ws *wsClient) handleOrderBookMessage(venueSymbol, msgType string, ts int64, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.orderBookChans[venueSymbol]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	// Snapshots and deltas share a layout; a zero size removes the level.
+	var update struct {
+		Bids     [][]string `json:"b"`
+		Asks     [][]string `json:"a"`
+		UpdateID uint64     `json:"u"`
+	}
+	if err := json.Unmarshal(data, &update); err != nil {
+		ws.logger.Warn("failed to parse bybit orderbook update", "error", err)
+		return
+	}
+
+	delta := domain.OrderBookDelta{
+		Venue:          "bybit",
+		Symbol:         domain.ReverseMapSymbol(venueSymbol, ws.symbolMap),
+		Bids:           parseLevels(update.Bids),
+		Asks:           parseLevels(update.Asks),
+		Sequence:       update.UpdateID,
+		VenueTimestamp: time.UnixMilli(ts),
+		LocalTimestamp: time.Now(),
+	}
+	// A snapshot comes first on each (re)subscribe, and again with u=1 when
+	// Bybit restarts the book; each delta follows on from the message before
+	// it, so one dropped here shows up as a gap in the next.
+	if msgType == "snapshot" {
+		delta.Snapshot = true
+	} else if prev, ok := ws.lastUpdateID[venueSymbol]; ok {
+		delta.FirstSequence = prev + 1
+	} else {
+		delta.FirstSequence = update.UpdateID
+	}
+	ws.lastUpdateID[venueSymbol] = update.UpdateID
+
+	ws.latency.Observe("book", delta.VenueTimestamp)
+	select {
+	case ch <- delta:
+	default:
+		ws.logger.Debug("bybit orderbook channel full, dropping update", "symbol", venueSymbol)
+	}
+}
+
+func (ws *wsClient) handleTradeMessage(venueSymbol string, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.tradeChans[venueSymbol]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var trades []struct {
+		Time    int64  `json:"T"`
+		Side    string `json:"S"`
+		Size    string `json:"v"`
+		Price   string `json:"p"`
+		TradeID string `json:"i"`
+	}
+	if err := json.Unmarshal(data, &trades); err != nil {
+		ws.logger.Warn("failed to parse bybit trade update", "error", err)
+		return
+	}
+
+	symbol := domain.ReverseMapSymbol(venueSymbol, ws.symbolMap)
+	for _, t := range trades {
+		side := domain.SideBuy
+		if t.Side == "Sell" {
+			side = domain.SideSell
+		}
+
+		trade := domain.Trade{
+			Venue:     "bybit",
+			Symbol:    symbol,
+			Side:      side,
+			Timestamp: time.UnixMilli(t.Time),
+			TradeID:   t.TradeID,
+		}
+		trade.Price, _ = domain.ParseDecimal(t.Price)
+		trade.Size, _ = domain.ParseDecimal(t.Size)
+
+		ws.latency.Observe("trade", trade.Timestamp)
+		select {
+		case ch <- trade:
+		default:
+			ws.logger.Debug("bybit trade channel full, dropping update", "symbol", venueSymbol)
+		}
+	}
+}
+
+func (ws *wsClient) handleFundingMessage(venueSymbol string, ts int64, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.fundingChans[venueSymbol]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var update struct {
+		FundingRate     string `json:"fundingRate"`
+		NextFundingTime string `json:"nextFundingTime"`
+	}
+	if err := json.Unmarshal(data, &update); err != nil {
+		ws.logger.Warn("failed to parse bybit ticker update", "error", err)
+		return
+	}
+
+	// Ticker deltas only carry changed fields.
+	if update.FundingRate == "" {
+		return
+	}
+
+	rate := domain.FundingRate{
+		Venue:     "bybit",
+		Symbol:    domain.ReverseMapSymbol(venueSymbol, ws.symbolMap),
+		Timestamp: time.UnixMilli(ts),
+	}
+	rate.Rate, _ = domain.ParseDecimal(update.FundingRate)
+	if next, err := strconv.ParseInt(update.NextFundingTime, 10, 64); err == nil {
+		rate.NextTime = time.UnixMilli(next)
+	}
+
+	select {
+	case ch <- rate:
+	default:
+		ws.logger.Debug("bybit funding channel full, dropping update", "symbol", venueSymbol)
+	}
+}
+
+func (ws *wsClient) subscribeOrderBook(venueSymbol string) <-chan domain.OrderBookDelta {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.OrderBookDelta, 256)
+	ws.orderBookChans[venueSymbol] = ch
+	return ch
+}
+
+func (ws *wsClient) subscribeTrades(venueSymbol string) <-chan domain.Trade {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.Trade, 256)
+	ws.tradeChans[venueSymbol] = ch
+	return ch
+}
+
+func (ws *wsClient) subscribeFunding(venueSymbol string) <-chan domain.FundingRate {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.FundingRate, 256)
+	ws.fundingChans[venueSymbol] = ch
+	return ch
+}
+
+func (ws *wsClient) close() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.state.SetConnected(false)
+	if ws.stopPing != nil {
+		close(ws.stopPing)
+		ws.stopPing = nil
+	}
+	if ws.conn != nil {
+		return ws.conn.Close()
+	}
+	return nil
+}
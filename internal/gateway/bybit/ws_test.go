@@ -0,0 +1,61 @@
+package bybit
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestBybitWSClient_HandleOrderBookMessage_Sequences(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", categorySpot, domain.BybitSpotSymbolMap, logger)
+	ch := ws.subscribeOrderBook("BTCUSDT")
+
+	next := func() domain.OrderBookDelta {
+		t.Helper()
+		select {
+		case delta := <-ch:
+			return delta
+		default:
+			t.Fatal("expected an order book update")
+			return domain.OrderBookDelta{}
+		}
+	}
+
+	ws.handleMessage([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1700000000000,"data":{"s":"BTCUSDT","b":[["50000","1"]],"a":[["50001","2"]],"u":100}}`))
+	if delta := next(); !delta.Snapshot || delta.FirstSequence != 0 || delta.Sequence != 100 {
+		t.Errorf("expected a snapshot at 100, got snapshot=%v %d..%d", delta.Snapshot, delta.FirstSequence, delta.Sequence)
+	}
+
+	ws.handleMessage([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":1700000000100,"data":{"s":"BTCUSDT","b":[["50000","0"]],"a":[],"u":101}}`))
+	if delta := next(); delta.Snapshot || delta.FirstSequence != 101 || delta.Sequence != 101 {
+		t.Errorf("expected a delta following on from 100, got snapshot=%v %d..%d", delta.Snapshot, delta.FirstSequence, delta.Sequence)
+	}
+
+	// 102 is dropped on a full channel, so 103 no longer follows on from
+	// the book's 101.
+	full := make(chan domain.OrderBookDelta, 1)
+	full <- domain.OrderBookDelta{}
+	ws.chanMu.Lock()
+	ws.orderBookChans["BTCUSDT"] = full
+	ws.chanMu.Unlock()
+	ws.handleMessage([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":1700000000200,"data":{"s":"BTCUSDT","b":[],"a":[["50001","3"]],"u":102}}`))
+	<-full
+	ch = full
+	ws.handleMessage([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":1700000000300,"data":{"s":"BTCUSDT","b":[],"a":[["50002","1"]],"u":103}}`))
+	if delta := next(); delta.FirstSequence != 103 || delta.Sequence != 103 {
+		t.Errorf("expected the delta after a drop to start at 103, got %d..%d", delta.FirstSequence, delta.Sequence)
+	}
+
+	// After a reconnect, or a Bybit restart with u=1, the snapshot starts over.
+	ws.handleMessage([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1700000000400,"data":{"s":"BTCUSDT","b":[["49999","4"]],"a":[["50003","1"]],"u":1}}`))
+	if delta := next(); !delta.Snapshot || delta.Sequence != 1 || len(delta.Bids) != 1 || len(delta.Asks) != 1 {
+		t.Errorf("expected a replacement snapshot at 1, got %+v", delta)
+	}
+	ws.handleMessage([]byte(`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":1700000000500,"data":{"s":"BTCUSDT","b":[],"a":[],"u":2}}`))
+	if delta := next(); delta.FirstSequence != 2 || delta.Sequence != 2 {
+		t.Errorf("expected a delta following on from the new snapshot, got %d..%d", delta.FirstSequence, delta.Sequence)
+	}
+}
@@ -0,0 +1,127 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ClockSyncInterval is how often a venue's server time is re-sampled.
+const ClockSyncInterval = time.Minute
+
+// clockSkewWarn is the offset above which a sync is logged as a warning.
+const clockSkewWarn = time.Second
+
+// ServerTimeFunc fetches a venue's current server time.
+type ServerTimeFunc func(ctx context.Context) (time.Time, error)
+
+// ClockOffsetObserver is told the estimated venue clock offset after each
+// successful sync. A positive offset means the venue is ahead of us.
+type ClockOffsetObserver func(venue string, offset time.Duration)
+
+// ClockOffsetReporter is implemented by gateways that sign requests with a
+// timestamp and keep it in line with the venue's clock. Set the observer
+// before Connect.
+type ClockOffsetReporter interface {
+	SetClockOffsetObserver(fn ClockOffsetObserver)
+}
+
+// ClockSync estimates how far the local clock is from a venue's so signed
+// requests carry a timestamp the venue accepts even when the host drifts.
+// Until the first sync, Now is the local clock. It is safe for concurrent use.
+type ClockSync struct {
+	venue  string
+	fetch  ServerTimeFunc
+	offset atomic.Int64 // nanoseconds to add to the local clock
+
+	// OnSync, if set, is called with the offset after each successful sync.
+	OnSync ClockOffsetObserver
+}
+
+// NewClockSync creates a ClockSync that samples the venue's time with fetch.
+func NewClockSync(venue string, fetch ServerTimeFunc) *ClockSync {
+	return &ClockSync{venue: venue, fetch: fetch}
+}
+
+// Now returns the local time corrected by the estimated offset.
+func (c *ClockSync) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
+
+// Offset returns the estimated venue clock offset.
+func (c *ClockSync) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// Sync samples the venue's clock once. The server time is taken to be read
+// halfway through the round trip, so the estimate is good to within half the
+// request latency.
+func (c *ClockSync) Sync(ctx context.Context) error {
+	sent := time.Now()
+	server, err := c.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	rtt := time.Since(sent)
+	offset := server.Sub(sent.Add(rtt / 2))
+	c.offset.Store(int64(offset))
+	if c.OnSync != nil {
+		c.OnSync(c.venue, offset)
+	}
+	return nil
+}
+
+// Run syncs immediately and then every interval until ctx is done. A failed
+// sync keeps the previous estimate.
+func (c *ClockSync) Run(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Sync(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn("venue clock sync failed", "venue", c.venue, "error", err)
+		} else if offset := c.Offset(); offset.Abs() >= clockSkewWarn {
+			logger.Warn("venue clock skew", "venue", c.venue, "offset", offset)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FetchJSON sends an unauthenticated GET to url and decodes the JSON body
+// into v. Server-time lookups use it rather than the signed request path,
+// which would be rejected while the clock is off.
+func FetchJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return NewHTTPError(resp, body)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClockSyncCorrectsForSkew(t *testing.T) {
+	const skew = 3 * time.Second
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"serverTime": %d}`, time.Now().Add(skew).UnixMilli())
+	}))
+	defer srv.Close()
+
+	var observed time.Duration
+	c := NewClockSync("binance", func(ctx context.Context) (time.Time, error) {
+		var resp struct {
+			ServerTime int64 `json:"serverTime"`
+		}
+		if err := FetchJSON(ctx, srv.Client(), srv.URL, &resp); err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(resp.ServerTime), nil
+	})
+	c.OnSync = func(venue string, offset time.Duration) { observed = offset }
+
+	if c.Offset() != 0 {
+		t.Fatalf("expected no offset before the first sync, got %v", c.Offset())
+	}
+	if err := c.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if d := (c.Offset() - skew).Abs(); d > 50*time.Millisecond {
+		t.Errorf("expected an offset near %v, got %v", skew, c.Offset())
+	}
+	if observed != c.Offset() {
+		t.Errorf("observer saw %v, want %v", observed, c.Offset())
+	}
+	if d := (c.Now().Sub(time.Now()) - skew).Abs(); d > 50*time.Millisecond {
+		t.Errorf("expected Now to run %v ahead of the local clock", skew)
+	}
+}
+
+func TestClockSyncKeepsEstimateOnFailure(t *testing.T) {
+	fail := false
+	c := NewClockSync("okx", func(context.Context) (time.Time, error) {
+		if fail {
+			return time.Time{}, errors.New("venue down")
+		}
+		return time.Now().Add(-2 * time.Second), nil
+	})
+	if err := c.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	before := c.Offset()
+
+	fail = true
+	if err := c.Sync(context.Background()); err == nil {
+		t.Fatal("expected the failed sync to return an error")
+	}
+	if c.Offset() != before || before > -time.Second {
+		t.Errorf("expected the earlier offset of about -2s kept, got %v", c.Offset())
+	}
+}
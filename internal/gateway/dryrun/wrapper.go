@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/gateway"
@@ -16,16 +17,28 @@ import (
 )
 
 // Wrapper wraps a real VenueGateway so that all read operations (market data
-// subscriptions, balances, positions, fees) hit the live exchange while order
-// placement and cancellation are simulated locally.
+// subscriptions, balances, positions, fees, deposit addresses) hit the live
+// exchange while order placement, cancellation and withdrawals are simulated
+// locally.
 type Wrapper struct {
 	inner     gateway.VenueGateway
 	fillSim   simulated.FillSimulator
 	mdService *marketdata.Service
 	logger    *slog.Logger
 
-	mu         sync.RWMutex
-	openOrders map[string]*domain.Order
+	mu           sync.RWMutex
+	openOrders   map[string]*domain.Order
+	pendingStops map[string]domain.OrderRequest // untriggered stops by venue ID
+	transfers    map[string]*domain.Transfer
+	updates      chan domain.OrderUpdate // fills of resting orders
+	funding      *simulated.FundingLedger
+	margin       *simulated.MarginModel
+
+	// seeded is set once SeedFromLive has taken the live account's
+	// balances and positions; from then on they are served from here.
+	seeded    bool
+	balances  map[string]domain.Balance
+	positions []domain.Position
 }
 
 func NewWrapper(
@@ -35,11 +48,15 @@ func NewWrapper(
 	logger *slog.Logger,
 ) *Wrapper {
 	return &Wrapper{
-		inner:      inner,
-		fillSim:    fillSim,
-		mdService:  mdService,
-		logger:     logger,
-		openOrders: make(map[string]*domain.Order),
+		inner:        inner,
+		fillSim:      fillSim,
+		mdService:    mdService,
+		logger:       logger,
+		openOrders:   make(map[string]*domain.Order),
+		pendingStops: make(map[string]domain.OrderRequest),
+		transfers:    make(map[string]*domain.Transfer),
+		updates:      make(chan domain.OrderUpdate, 256),
+		funding:      simulated.NewFundingLedger(inner.Name()),
 	}
 }
 
@@ -55,6 +72,12 @@ func (w *Wrapper) Close() error {
 	return w.inner.Close()
 }
 
+// Health reports the live venue's connections, which the wrapper reads market
+// data from.
+func (w *Wrapper) Health(ctx context.Context) domain.VenueHealth {
+	return w.inner.Health(ctx)
+}
+
 // --- Live read operations delegated to the real gateway ---
 
 func (w *Wrapper) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
@@ -69,16 +92,121 @@ func (w *Wrapper) SubscribeFunding(ctx context.Context, symbol string) (<-chan d
 	return w.inner.SubscribeFunding(ctx, symbol)
 }
 
+// GetBalances reads the live venue's balances, or once seeded the snapshot
+// SeedFromLive took, with dry-run funding and liquidations settled into
+// USDT.
 func (w *Wrapper) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	w.mu.RLock()
+	if w.seeded {
+		defer w.mu.RUnlock()
+		result := make(map[string]domain.Balance, len(w.balances))
+		for k, v := range w.balances {
+			result[k] = v
+		}
+		return result, nil
+	}
+	w.mu.RUnlock()
 	return w.inner.GetBalances(ctx)
 }
 
+// GetPositions reads the live venue's positions, or once seeded the
+// snapshot SeedFromLive took.
 func (w *Wrapper) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	w.mu.RLock()
+	if w.seeded {
+		defer w.mu.RUnlock()
+		result := make([]domain.Position, len(w.positions))
+		copy(result, w.positions)
+		return result, nil
+	}
+	w.mu.RUnlock()
 	return w.inner.GetPositions(ctx)
 }
 
+// SeedFromLive snapshots the live account's balances and positions through
+// the venue's read-only API, so the dry run starts from the capital the
+// account actually holds. From then on GetBalances and GetPositions serve
+// the snapshot instead of the live account, and dry-run funding and
+// liquidations are settled into its USDT balance. Live perp positions are
+// added to the dry-run ledger, so they are funded, margined and can be
+// reduced like positions dry-run fills opened. Call after Connect and
+// before RunFunding and RunMargin.
+func (w *Wrapper) SeedFromLive(ctx context.Context) error {
+	balances, err := w.inner.GetBalances(ctx)
+	if err != nil {
+		return fmt.Errorf("read live balances: %w", err)
+	}
+	positions, err := w.inner.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("read live positions: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.balances = make(map[string]domain.Balance, len(balances))
+	for k, v := range balances {
+		w.balances[k] = v
+	}
+	w.positions = append([]domain.Position(nil), positions...)
+	w.seeded = true
+	for _, pos := range positions {
+		// Perp positions are reported under their internal symbol.
+		if pos.InstrumentType == domain.InstrumentPerp {
+			w.funding.Open(pos.Asset, pos.Size, pos.EntryPrice)
+		}
+	}
+	return nil
+}
+
+// SeededCollateral returns the USDT the seeded snapshot holds, or false
+// before SeedFromLive.
+func (w *Wrapper) SeededCollateral() (decimal.Decimal, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.seeded {
+		return decimal.Zero, false
+	}
+	return w.balances["USDT"].Total, true
+}
+
+// settle adds amount to the seeded USDT balance. Before SeedFromLive
+// balances are the live venue's and are left alone.
+func (w *Wrapper) settle(amount decimal.Decimal) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.seeded {
+		return
+	}
+	usdt := w.balances["USDT"]
+	usdt.Venue, usdt.Asset = w.inner.Name(), "USDT"
+	usdt.Free = usdt.Free.Add(amount)
+	usdt.Total = usdt.Total.Add(amount)
+	w.balances["USDT"] = usdt
+}
+
+// GetFeeTier reads the live venue's fees and has the fill simulator charge
+// them, maker rebates included, from then on.
 func (w *Wrapper) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
-	return w.inner.GetFeeTier(ctx)
+	tier, err := w.inner.GetFeeTier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := w.fillSim.(interface {
+		SetFees(maker, taker decimal.Decimal)
+	}); ok {
+		f.SetFees(tier.MakerFeeBps, tier.TakerFeeBps)
+	}
+	return tier, nil
+}
+
+func (w *Wrapper) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return w.inner.GetInstruments(ctx)
+}
+
+// GetRateLimitStatus reports the live venue's budget, which dry-run reads
+// still draw on.
+func (w *Wrapper) GetRateLimitStatus(ctx context.Context) ([]domain.RateLimitStatus, error) {
+	return w.inner.GetRateLimitStatus(ctx)
 }
 
 // --- Simulated write operations ---
@@ -111,6 +239,15 @@ func (w *Wrapper) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*dom
 		}, fmt.Errorf("no order book available for %s:%s", venueName, req.Symbol)
 	}
 
+	req, err := w.funding.LimitReduceOnly(req)
+	if err != nil {
+		return &domain.OrderAck{
+			InternalID: req.InternalID,
+			Status:     domain.OrderStatusRejected,
+			Timestamp:  time.Now(),
+		}, err
+	}
+
 	fill, err := w.fillSim.SimulateFill(req, book)
 	if err != nil {
 		return nil, err
@@ -120,25 +257,37 @@ func (w *Wrapper) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*dom
 
 	w.mu.Lock()
 	order := &domain.Order{
-		InternalID:   req.InternalID,
-		VenueID:      venueID,
-		SignalID:     req.SignalID,
-		Venue:        venueName,
-		Symbol:       req.Symbol,
-		Side:         req.Side,
-		OrderType:    req.OrderType,
-		Price:        req.Price,
-		Size:         req.Size,
-		FilledSize:   fill.FillSize,
-		AvgFillPrice: fill.FillPrice,
-		Status:       fill.Status,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		InternalID:     req.InternalID,
+		VenueID:        venueID,
+		SignalID:       req.SignalID,
+		Venue:          venueName,
+		Symbol:         req.Symbol,
+		InstrumentType: req.InstrumentType,
+		Side:           req.Side,
+		OrderType:      req.OrderType,
+		ReduceOnly:     req.ReduceOnly,
+		Price:          req.Price,
+		StopPrice:      req.StopPrice,
+		Size:           req.Size,
+		FilledSize:     fill.FillSize,
+		AvgFillPrice:   fill.FillPrice,
+		Status:         fill.Status,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 	if !fill.Status.IsTerminal() {
 		w.openOrders[venueID] = order
+		if req.OrderType.IsStop() && !simulated.StopTriggered(req, book) {
+			w.pendingStops[venueID] = req
+		} else {
+			w.fillSim.Rest(order, book)
+		}
 	}
+	w.funding.Track(order)
+	trimmed := simulated.TrimReduceOnly(w.fillSim, w.funding, w.openOrders, req.Symbol)
+	w.untrackFilled(trimmed)
 	w.mu.Unlock()
+	simulated.SendUpdates(ctx, w.updates, trimmed)
 
 	w.logger.Info("dry-run order simulated (no real order placed)",
 		"venue", venueName,
@@ -166,8 +315,10 @@ func (w *Wrapper) CancelOrder(_ context.Context, orderID string) (*domain.Cancel
 	if ok {
 		order.Status = domain.OrderStatusCancelled
 		delete(w.openOrders, orderID)
+		delete(w.pendingStops, orderID)
 	}
 	w.mu.Unlock()
+	w.fillSim.Forget(orderID)
 
 	w.logger.Info("dry-run order cancelled (no real cancel sent)",
 		"venue", w.inner.Name(),
@@ -182,6 +333,273 @@ func (w *Wrapper) CancelOrder(_ context.Context, orderID string) (*domain.Cancel
 	}, nil
 }
 
+// PlaceOrders simulates each order in turn against the live book.
+func (w *Wrapper) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, w.PlaceOrder)
+}
+
+// CancelOrders cancels locally tracked dry-run orders.
+func (w *Wrapper) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, w.CancelOrder)
+}
+
+// AmendOrder updates a locally tracked dry-run order; nothing is sent to the
+// exchange.
+func (w *Wrapper) AmendOrder(_ context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	w.mu.Lock()
+	order, ok := w.openOrders[orderID]
+	if !ok {
+		w.mu.Unlock()
+		return nil, fmt.Errorf("dry-run order %s not open", orderID)
+	}
+	if newSize.LessThanOrEqual(order.FilledSize) {
+		w.mu.Unlock()
+		return nil, fmt.Errorf("new size %s not above filled size %s", newSize, order.FilledSize)
+	}
+	order.Price = newPrice
+	order.Size = newSize
+	order.UpdatedAt = time.Now()
+	ack := &domain.AmendAck{
+		VenueID:   orderID,
+		Price:     newPrice,
+		Size:      newSize,
+		Status:    order.Status,
+		Timestamp: order.UpdatedAt,
+	}
+	w.mu.Unlock()
+
+	w.logger.Info("dry-run order amended (no real amend sent)",
+		"venue", w.inner.Name(),
+		"orderID", orderID,
+		"price", newPrice.String(),
+		"size", newSize.String(),
+		"mode", "dry_run",
+	)
+
+	return ack, nil
+}
+
+// RunMatching fills resting dry-run limit orders from the live venue's
+// books and trades, as the fill simulator decides, and triggers stops whose
+// trigger price a book reaches, until ctx is cancelled or either channel is
+// closed. Orders that fill completely stop being tracked.
+func (w *Wrapper) RunMatching(ctx context.Context, books <-chan domain.OrderBookSnapshot, trades <-chan domain.Trade) {
+	venueName := w.inner.Name()
+	for {
+		var updates, trimmed []domain.OrderUpdate
+		select {
+		case <-ctx.Done():
+			return
+		case book, ok := <-books:
+			if !ok {
+				return
+			}
+			if book.Venue != venueName {
+				continue
+			}
+			w.mu.Lock()
+			updates = append(w.triggerStops(&book), simulated.MatchOrders(w.fillSim, w.openOrders, &book)...)
+			w.funding.TrackUpdates(w.openOrders, updates)
+			trimmed = simulated.TrimReduceOnly(w.fillSim, w.funding, w.openOrders, book.Symbol)
+			w.untrackFilled(updates)
+			w.untrackFilled(trimmed)
+			w.mu.Unlock()
+		case trade, ok := <-trades:
+			if !ok {
+				return
+			}
+			if trade.Venue != venueName {
+				continue
+			}
+			w.mu.Lock()
+			updates = simulated.MatchOrdersOnTrade(w.fillSim, w.openOrders, trade)
+			w.funding.TrackUpdates(w.openOrders, updates)
+			trimmed = simulated.TrimReduceOnly(w.fillSim, w.funding, w.openOrders, trade.Symbol)
+			w.untrackFilled(updates)
+			w.untrackFilled(trimmed)
+			w.mu.Unlock()
+		}
+		for _, u := range updates {
+			w.logger.Info("dry-run resting order filled (no real order placed)",
+				"venue", venueName,
+				"orderID", u.VenueID,
+				"filled", u.FilledSize.String(),
+				"status", u.Status,
+				"mode", "dry_run",
+			)
+		}
+		simulated.SendUpdates(ctx, w.updates, updates)
+		simulated.SendUpdates(ctx, w.updates, trimmed)
+	}
+}
+
+// RunFunding settles funding on the perp positions dry-run fills have
+// opened, at the rates the live venue announces. The payments reach pay and,
+// once seeded, the snapshot's USDT balance; otherwise balances keep coming
+// from the live venue.
+func (w *Wrapper) RunFunding(ctx context.Context, rates <-chan domain.FundingRate, pay func(domain.AccountActivity)) {
+	venueName := w.inner.Name()
+	simulated.RunFundingLoop(ctx, w.funding, rates, simulated.MidMarks(w.mdService, venueName), func(p domain.AccountActivity) {
+		w.settle(p.Amount)
+		w.logger.Info("dry-run funding settled (no real position held)",
+			"venue", venueName,
+			"symbol", p.Symbol,
+			"position", p.Size.String(),
+			"amount", p.Amount.String(),
+			"mode", "dry_run",
+		)
+	}, pay)
+}
+
+// SetMargin margins the perp positions dry-run fills open with model. Call
+// before RunMargin.
+func (w *Wrapper) SetMargin(model simulated.MarginModel) {
+	w.margin = &model
+}
+
+// RunMargin checks the margin of the perp positions dry-run fills have
+// opened against the model set with SetMargin. Liquidations reach
+// liquidated and, once seeded, the snapshot's USDT balance: no real
+// position is closed.
+func (w *Wrapper) RunMargin(ctx context.Context, liquidated func(simulated.Liquidation)) {
+	if w.margin == nil {
+		return
+	}
+	venueName := w.inner.Name()
+	simulated.RunMarginLoop(ctx, w.funding, *w.margin, simulated.MidMarks(w.mdService, venueName), func(l simulated.Liquidation) {
+		w.settle(l.PnL)
+		w.logger.Warn("dry-run perp positions liquidated (no real position held)",
+			"venue", venueName,
+			"positions", len(l.Positions),
+			"equity", l.Status.Equity.StringFixed(2),
+			"maintenance_margin", l.Status.Maintenance.StringFixed(2),
+			"pnl", l.PnL.StringFixed(2),
+			"mode", "dry_run",
+		)
+	}, liquidated, w.logger)
+}
+
+// triggerStops fills the untriggered stops on book's symbol whose trigger
+// book has reached and returns an update for each. A stop-limit that does
+// not fill in full rests as the limit order it became. Callers hold w.mu.
+func (w *Wrapper) triggerStops(book *domain.OrderBookSnapshot) []domain.OrderUpdate {
+	var updates []domain.OrderUpdate
+	for venueID, req := range w.pendingStops {
+		if req.Symbol != book.Symbol || !simulated.StopTriggered(req, book) {
+			continue
+		}
+		fill, err := w.fillSim.SimulateFill(req, book)
+		if err != nil {
+			w.logger.Warn("dry-run stop trigger failed", "order_id", venueID, "error", err)
+			continue
+		}
+		delete(w.pendingStops, venueID)
+
+		order := w.openOrders[venueID]
+		if order.OrderType == domain.OrderTypeStopLimit {
+			order.OrderType = domain.OrderTypeLimit
+		}
+		order.FilledSize = fill.FillSize
+		order.AvgFillPrice = fill.FillPrice
+		order.Status = fill.Status
+		order.UpdatedAt = time.Now()
+		if !order.Status.IsTerminal() {
+			w.fillSim.Rest(order, book)
+		}
+		updates = append(updates, domain.OrderUpdate{
+			Venue:        order.Venue,
+			VenueID:      venueID,
+			Status:       order.Status,
+			FilledSize:   order.FilledSize,
+			AvgFillPrice: order.AvgFillPrice,
+			Timestamp:    order.UpdatedAt,
+		})
+
+		w.logger.Info("dry-run stop triggered (no real order placed)",
+			"venue", order.Venue,
+			"symbol", req.Symbol,
+			"stop_price", req.StopPrice.String(),
+			"fill_price", fill.FillPrice.String(),
+			"size", fill.FillSize.String(),
+			"status", fill.Status,
+			"mode", "dry_run",
+		)
+	}
+	return updates
+}
+
+// untrackFilled drops the orders updates report as done. Callers hold w.mu.
+func (w *Wrapper) untrackFilled(updates []domain.OrderUpdate) {
+	for _, u := range updates {
+		if u.Status.IsTerminal() {
+			delete(w.openOrders, u.VenueID)
+		}
+	}
+}
+
+// SubscribeOrderUpdates is not delegated: the live account stream would only
+// report real orders. It streams the fills RunMatching finds for resting
+// dry-run orders; fills at placement are final in the ack.
+func (w *Wrapper) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return w.updates, nil
+}
+
+func (w *Wrapper) GetDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	return w.inner.GetDepositAddress(ctx, asset, network)
+}
+
+// GetOrderBookSnapshot reads the live venue's REST book.
+func (w *Wrapper) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	p, ok := w.inner.(gateway.OrderBookSnapshotProvider)
+	if !ok {
+		return nil, gateway.ErrBookSnapshotUnsupported
+	}
+	return p.GetOrderBookSnapshot(ctx, symbol)
+}
+
+// Withdraw records a completed transfer without touching the live wallet.
+func (w *Wrapper) Withdraw(_ context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	now := time.Now()
+	t := &domain.Transfer{
+		ID:        uuid.New().String(),
+		Venue:     w.inner.Name(),
+		Asset:     req.Asset,
+		Network:   req.Network,
+		Address:   req.Address,
+		Memo:      req.Memo,
+		Amount:    req.Amount,
+		Status:    domain.TransferStatusCompleted,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	w.mu.Lock()
+	w.transfers[t.ID] = t
+	w.mu.Unlock()
+
+	w.logger.Info("dry-run withdrawal",
+		"venue", t.Venue,
+		"transfer_id", t.ID,
+		"asset", t.Asset,
+		"network", t.Network,
+		"amount", t.Amount.String(),
+	)
+	tc := *t
+	return &tc, nil
+}
+
+func (w *Wrapper) GetTransferStatus(_ context.Context, transferID string) (*domain.Transfer, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	t, ok := w.transfers[transferID]
+	if !ok {
+		return nil, fmt.Errorf("dry-run transfer %s not found", transferID)
+	}
+	tc := *t
+	return &tc, nil
+}
+
 // OpenOrderCount returns the number of locally tracked open orders (for metrics).
 func (w *Wrapper) OpenOrderCount() int {
 	w.mu.RLock()
@@ -194,4 +612,7 @@ func (w *Wrapper) Inner() gateway.VenueGateway {
 	return w.inner
 }
 
-var _ gateway.VenueGateway = (*Wrapper)(nil)
+var (
+	_ gateway.VenueGateway     = (*Wrapper)(nil)
+	_ simulated.RestingMatcher = (*Wrapper)(nil)
+)
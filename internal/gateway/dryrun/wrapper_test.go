@@ -12,6 +12,7 @@ import (
 
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/gateway"
 	"github.com/crypto-trading/trading/internal/gateway/simulated"
 	"github.com/crypto-trading/trading/internal/marketdata"
 )
@@ -26,6 +27,7 @@ type mockGateway struct {
 	openOrders        []domain.Order
 	placeOrderCalled  bool
 	cancelOrderCalled bool
+	withdrawCalled    bool
 }
 
 func newMockGateway(name string) *mockGateway {
@@ -52,6 +54,10 @@ func (m *mockGateway) Name() string                             { return m.name
 func (m *mockGateway) Connect(_ context.Context) error          { m.connectCalled = true; return nil }
 func (m *mockGateway) Close() error                             { m.closeCalled = true; return nil }
 
+func (m *mockGateway) Health(_ context.Context) domain.VenueHealth {
+	return domain.VenueHealth{Venue: m.name}
+}
+
 func (m *mockGateway) SubscribeOrderBook(_ context.Context, _ string) (<-chan domain.OrderBookDelta, error) {
 	ch := make(chan domain.OrderBookDelta, 16)
 	return ch, nil
@@ -80,6 +86,21 @@ func (m *mockGateway) CancelOrder(_ context.Context, _ string) (*domain.CancelAc
 func (m *mockGateway) GetOpenOrders(_ context.Context, _ string) ([]domain.Order, error) {
 	return m.openOrders, nil
 }
+func (m *mockGateway) AmendOrder(_ context.Context, _ string, _, _ decimal.Decimal) (*domain.AmendAck, error) {
+	return nil, gateway.ErrAmendUnsupported
+}
+
+func (m *mockGateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, m.PlaceOrder)
+}
+
+func (m *mockGateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, m.CancelOrder)
+}
+
+func (m *mockGateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
 
 func (m *mockGateway) GetBalances(_ context.Context) (map[string]domain.Balance, error) {
 	return m.balances, nil
@@ -89,10 +110,31 @@ func (m *mockGateway) GetPositions(_ context.Context) ([]domain.Position, error)
 	return m.positions, nil
 }
 
+func (m *mockGateway) GetInstruments(_ context.Context) ([]domain.Instrument, error) {
+	return nil, nil
+}
+
 func (m *mockGateway) GetFeeTier(_ context.Context) (*domain.FeeTier, error) {
 	return m.feeTier, nil
 }
 
+func (m *mockGateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return nil, nil
+}
+
+func (m *mockGateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	m.withdrawCalled = true
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (m *mockGateway) GetDepositAddress(_ context.Context, asset, network string) (*domain.DepositAddress, error) {
+	return &domain.DepositAddress{Venue: m.name, Asset: asset, Network: network, Address: "TXyz123"}, nil
+}
+
+func (m *mockGateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
 func newTestWrapper(mock *mockGateway) (*Wrapper, *marketdata.Service) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
 	bus := eventbus.New(64, logger)
@@ -162,6 +204,45 @@ func TestWrapper_DelegatesGetPositions(t *testing.T) {
 	}
 }
 
+func TestWrapper_SeedFromLive(t *testing.T) {
+	mock := newMockGateway("test_venue")
+	mock.positions = append(mock.positions, domain.Position{
+		Venue: "test_venue", Asset: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Size: decimal.NewFromFloat(-0.5), EntryPrice: decimal.NewFromInt(50000),
+	})
+	w, _ := newTestWrapper(mock)
+	ctx := context.Background()
+
+	if _, ok := w.SeededCollateral(); ok {
+		t.Fatal("expected no collateral before seeding")
+	}
+	if err := w.SeedFromLive(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usdt, ok := w.SeededCollateral(); !ok || !usdt.Equal(decimal.NewFromInt(5000)) {
+		t.Errorf("expected 5000 USDT of collateral, got %s", usdt)
+	}
+
+	// The live account moving no longer shows.
+	mock.balances["USDT"] = domain.Balance{Venue: "test_venue", Asset: "USDT", Total: decimal.NewFromInt(1)}
+	mock.positions = nil
+	positions, _ := w.GetPositions(ctx)
+	if len(positions) != 2 {
+		t.Errorf("expected the 2 seeded positions, got %d", len(positions))
+	}
+
+	// The live short is in the ledger, and funding settles into the
+	// snapshot's USDT.
+	if pos := w.funding.Position("BTCUSDT"); !pos.Equal(decimal.NewFromFloat(-0.5)) {
+		t.Errorf("expected the live -0.5 BTCUSDT in the ledger, got %s", pos)
+	}
+	w.settle(decimal.NewFromInt(-25))
+	balances, _ := w.GetBalances(ctx)
+	if usdt := balances["USDT"]; !usdt.Total.Equal(decimal.NewFromInt(4975)) || !usdt.Free.Equal(decimal.NewFromInt(4975)) {
+		t.Errorf("expected 4975 USDT after funding, got %+v", usdt)
+	}
+}
+
 func TestWrapper_DelegatesGetFeeTier(t *testing.T) {
 	mock := newMockGateway("test_venue")
 	w, _ := newTestWrapper(mock)
@@ -364,6 +445,135 @@ func TestWrapper_FilledOrderNotTrackedAsOpen(t *testing.T) {
 	}
 }
 
+func TestWrapper_RestingOrderFillsWhenBookCrosses(t *testing.T) {
+	mock := newMockGateway("test_venue")
+	w, mdService := newTestWrapper(mock)
+
+	book := domain.OrderBookSnapshot{
+		Venue:  "test_venue",
+		Symbol: "BTC/USDT",
+		Asks:   []domain.PriceLevel{{Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(10.0)}},
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(49900), Size: decimal.NewFromFloat(10.0)}},
+	}
+	mdService.UpdateOrderBook(book)
+
+	ack, err := w.PlaceOrder(context.Background(), domain.OrderRequest{
+		InternalID: uuid.Must(uuid.NewV7()),
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideBuy,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(49950),
+		Size:       decimal.NewFromFloat(1.0),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ack.Status != domain.OrderStatusAcknowledged {
+		t.Fatalf("expected the order to rest, got %s", ack.Status)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	books := make(chan domain.OrderBookSnapshot, 2)
+	go w.RunMatching(ctx, books, nil)
+	updates, err := w.SubscribeOrderUpdates(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The ask comes down to 49950 with 0.4 there: a partial fill at the
+	// order's price. Then it trades through with size to spare.
+	book.Asks = []domain.PriceLevel{{Price: decimal.NewFromInt(49950), Size: decimal.NewFromFloat(0.4)}}
+	books <- book
+	book.Asks = []domain.PriceLevel{{Price: decimal.NewFromInt(49800), Size: decimal.NewFromFloat(5.0)}}
+	books <- book
+
+	want := []struct {
+		status domain.OrderStatus
+		filled decimal.Decimal
+	}{
+		{domain.OrderStatusPartialFill, decimal.NewFromFloat(0.4)},
+		{domain.OrderStatusFilled, decimal.NewFromFloat(1.0)},
+	}
+	for _, wnt := range want {
+		select {
+		case u := <-updates:
+			if u.VenueID != ack.VenueID || u.Status != wnt.status || !u.FilledSize.Equal(wnt.filled) {
+				t.Fatalf("expected %s with %s filled on %s, got %+v", wnt.status, wnt.filled, ack.VenueID, u)
+			}
+			if !u.AvgFillPrice.Equal(decimal.NewFromInt(49950)) {
+				t.Errorf("expected fills at the limit price 49950, got %s", u.AvgFillPrice)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s update", wnt.status)
+		}
+	}
+
+	orders, _ := w.GetOpenOrders(context.Background(), "")
+	if len(orders) != 0 {
+		t.Errorf("expected the filled order to stop being tracked, got %d open", len(orders))
+	}
+}
+
+func TestWrapper_StopTriggersFromBookUpdates(t *testing.T) {
+	mock := newMockGateway("test_venue")
+	w, mdService := newTestWrapper(mock)
+
+	book := domain.OrderBookSnapshot{
+		Venue:  "test_venue",
+		Symbol: "BTC/USDT",
+		Asks:   []domain.PriceLevel{{Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(10.0)}},
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(49900), Size: decimal.NewFromFloat(10.0)}},
+	}
+	mdService.UpdateOrderBook(book)
+
+	// A protective sell stop below the market waits for the bid to fall.
+	ack, err := w.PlaceOrder(context.Background(), domain.OrderRequest{
+		InternalID: uuid.Must(uuid.NewV7()),
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideSell,
+		OrderType:  domain.OrderTypeStopMarket,
+		StopPrice:  decimal.NewFromInt(49000),
+		Size:       decimal.NewFromFloat(1.0),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ack.Status != domain.OrderStatusAcknowledged {
+		t.Fatalf("expected the stop to wait for its trigger, got %s", ack.Status)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	books := make(chan domain.OrderBookSnapshot, 2)
+	go w.RunMatching(ctx, books, nil)
+	updates, _ := w.SubscribeOrderUpdates(ctx)
+
+	// A book above the trigger leaves it alone; one through it sells at the
+	// bid.
+	book.Bids = []domain.PriceLevel{{Price: decimal.NewFromInt(49500), Size: decimal.NewFromFloat(10.0)}}
+	books <- book
+	book.Bids = []domain.PriceLevel{{Price: decimal.NewFromInt(48950), Size: decimal.NewFromFloat(10.0)}}
+	books <- book
+
+	select {
+	case u := <-updates:
+		if u.VenueID != ack.VenueID || u.Status != domain.OrderStatusFilled || !u.FilledSize.Equal(decimal.NewFromInt(1)) {
+			t.Fatalf("expected the stop to fill in full, got %+v", u)
+		}
+		if !u.AvgFillPrice.Equal(decimal.NewFromInt(48950)) {
+			t.Errorf("expected the stop to sell at the 48950 bid, got %s", u.AvgFillPrice)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stop never triggered")
+	}
+
+	orders, _ := w.GetOpenOrders(context.Background(), "")
+	if len(orders) != 0 {
+		t.Errorf("expected the triggered stop to stop being tracked, got %d open", len(orders))
+	}
+}
+
 func TestWrapper_Inner(t *testing.T) {
 	mock := newMockGateway("test_venue")
 	w, _ := newTestWrapper(mock)
@@ -373,3 +583,44 @@ func TestWrapper_Inner(t *testing.T) {
 		t.Errorf("Inner() should return the underlying gateway, got name '%s'", inner.Name())
 	}
 }
+
+func TestWrapper_WithdrawIsSimulated(t *testing.T) {
+	mock := newMockGateway("test_venue")
+	w, _ := newTestWrapper(mock)
+	ctx := context.Background()
+
+	transfer, err := w.Withdraw(ctx, domain.WithdrawRequest{
+		Asset:   "USDT",
+		Network: "TRC20",
+		Address: "TXyz123",
+		Amount:  decimal.NewFromInt(500),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.withdrawCalled {
+		t.Error("Withdraw must not reach the live venue in dry-run")
+	}
+	if transfer.Status != domain.TransferStatusCompleted || !transfer.Amount.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("unexpected transfer: %+v", transfer)
+	}
+
+	status, err := w.GetTransferStatus(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.ID != transfer.ID || status.Status != domain.TransferStatusCompleted {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if _, err := w.GetTransferStatus(ctx, "unknown"); err == nil {
+		t.Error("expected error for unknown transfer")
+	}
+
+	addr, err := w.GetDepositAddress(ctx, "USDT", "TRC20")
+	if err != nil {
+		t.Fatalf("deposit address: %v", err)
+	}
+	if addr.Address != "TXyz123" {
+		t.Errorf("expected deposit address from inner gateway, got %q", addr.Address)
+	}
+}
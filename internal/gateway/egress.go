@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Egress routes one venue's outbound REST and WebSocket connections. The
+// zero value connects directly from any local address.
+type Egress struct {
+	// ProxyURL is an http, https or socks5 proxy; nil connects directly.
+	ProxyURL *url.URL
+	// LocalAddr binds outbound connections to one local IP; nil lets the
+	// kernel choose. Connections to a proxy are bound too.
+	LocalAddr net.IP
+}
+
+// EgressConfigurable is implemented by gateways whose connections can be
+// routed through an Egress. Set it before Connect.
+type EgressConfigurable interface {
+	SetEgress(e Egress)
+}
+
+// NewEgress builds an Egress from a proxy URL and a local interface, either
+// of which may be empty. iface is an interface name such as "eth1", whose
+// first IPv4 address is used, or a literal IP address.
+func NewEgress(proxy, iface string) (Egress, error) {
+	var e Egress
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return Egress{}, fmt.Errorf("parse proxy url: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return Egress{}, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+		}
+		if u.Host == "" {
+			return Egress{}, fmt.Errorf("proxy url %q has no host", u.Redacted())
+		}
+		e.ProxyURL = u
+	}
+	if iface != "" {
+		ip, err := interfaceIP(iface)
+		if err != nil {
+			return Egress{}, err
+		}
+		e.LocalAddr = ip
+	}
+	return e, nil
+}
+
+// interfaceIP resolves iface to a local address, preferring IPv4.
+func interfaceIP(iface string) (net.IP, error) {
+	if ip := net.ParseIP(iface); ip != nil {
+		return ip, nil
+	}
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("outbound interface: %w", err)
+	}
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("outbound interface %s: %w", iface, err)
+	}
+	var fallback net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return ip4, nil
+		}
+		if fallback == nil {
+			fallback = ipNet.IP
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("outbound interface %s has no addresses", iface)
+	}
+	return fallback, nil
+}
+
+// String describes the route for logs, with proxy credentials redacted.
+func (e Egress) String() string {
+	s := "direct"
+	if e.ProxyURL != nil {
+		s = "proxy " + e.ProxyURL.Redacted()
+	}
+	if e.LocalAddr != nil {
+		s += " from " + e.LocalAddr.String()
+	}
+	return s
+}
+
+func (e Egress) proxy() func(*http.Request) (*url.URL, error) {
+	if e.ProxyURL == nil {
+		return nil
+	}
+	return http.ProxyURL(e.ProxyURL)
+}
+
+func (e Egress) dialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if e.LocalAddr != nil {
+		d.LocalAddr = &net.TCPAddr{IP: e.LocalAddr}
+	}
+	return d.DialContext
+}
+
+// ApplyClient routes client's requests through e. The client must use an
+// *http.Transport, as every venue REST client does.
+func (e Egress) ApplyClient(client *http.Client) {
+	t, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return
+	}
+	t.Proxy = e.proxy()
+	t.DialContext = e.dialContext()
+}
+
+// WSDialer returns a WebSocket dialer that connects through e.
+func (e Egress) WSDialer() *websocket.Dialer {
+	return &websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+		Proxy:            e.proxy(),
+		NetDialContext:   e.dialContext(),
+	}
+}
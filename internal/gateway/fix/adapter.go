@@ -0,0 +1,232 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// errAccountUnsupported is returned by GetBalances and GetPositions: FIX 4.4
+// has no balance query, and position reports are not offered uniformly
+// enough by counterparties to rely on.
+var errAccountUnsupported = errors.New("fix: balances and positions are not available over FIX 4.4")
+
+// Config describes a FIX counterparty. Market data and order entry run as
+// separate sessions, as most counterparties require; they may share an
+// address but need distinct CompIDs if so.
+type Config struct {
+	// Venue is the name the gateway reports and stamps on its data.
+	Venue string
+
+	MarketDataAddr string // host:port
+	OrderEntryAddr string // host:port
+	SenderCompID   string
+	TargetCompID   string
+	// MarketDataSenderCompID and MarketDataTargetCompID override the
+	// CompIDs for the market data session; empty uses the ones above.
+	MarketDataSenderCompID string
+	MarketDataTargetCompID string
+
+	// Username and Password are sent on Logon (tags 553 and 554) if set.
+	Username string
+	Password string
+	// Account is sent as tag 1 on orders if set.
+	Account string
+
+	Heartbeat time.Duration
+	TLS       bool
+	// Depth is the book depth requested; 0 asks for the full book.
+	Depth int
+
+	// MakerFeeBps and TakerFeeBps are the agreed fees, reported by
+	// GetFeeTier since FIX has no fee query.
+	MakerFeeBps decimal.Decimal
+	TakerFeeBps decimal.Decimal
+}
+
+// Gateway implements the VenueGateway interface over FIX 4.4, for brokers
+// and venues that offer no REST or WebSocket API. Symbols are sent as the
+// internal symbols, unmapped. Funding, balances, positions and transfers are
+// not available; open orders and order status cover orders placed through
+// this gateway since it started.
+type Gateway struct {
+	cfg    Config
+	md     *session
+	oe     *session
+	market *marketData
+	orders *orderEntry
+	logger *slog.Logger
+}
+
+// New creates a FIX gateway. It returns an error if cfg is incomplete.
+func New(cfg Config, logger *slog.Logger) (*Gateway, error) {
+	switch {
+	case cfg.Venue == "":
+		return nil, errors.New("fix: venue name is required")
+	case cfg.MarketDataAddr == "" || cfg.OrderEntryAddr == "":
+		return nil, errors.New("fix: market data and order entry addresses are required")
+	case cfg.SenderCompID == "" || cfg.TargetCompID == "":
+		return nil, errors.New("fix: SenderCompID and TargetCompID are required")
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = 30 * time.Second
+	}
+	mdSender, mdTarget := cfg.MarketDataSenderCompID, cfg.MarketDataTargetCompID
+	if mdSender == "" {
+		mdSender = cfg.SenderCompID
+	}
+	if mdTarget == "" {
+		mdTarget = cfg.TargetCompID
+	}
+
+	logger = logger.With("venue", cfg.Venue)
+	g := &Gateway{cfg: cfg, logger: logger}
+	g.md = newSession(sessionConfig{
+		name: "market data", addr: cfg.MarketDataAddr, sender: mdSender, target: mdTarget,
+		username: cfg.Username, password: cfg.Password, heartbeat: cfg.Heartbeat, tls: cfg.TLS,
+	}, logger)
+	g.oe = newSession(sessionConfig{
+		name: "order entry", addr: cfg.OrderEntryAddr, sender: cfg.SenderCompID, target: cfg.TargetCompID,
+		username: cfg.Username, password: cfg.Password, heartbeat: cfg.Heartbeat, tls: cfg.TLS,
+	}, logger)
+	g.market = newMarketData(cfg.Venue, cfg.Depth, g.md.send, logger)
+	g.orders = newOrderEntry(cfg.Venue, cfg.Account, g.oe.send, logger)
+	g.md.handle = g.market.handle
+	g.md.onLogon = g.market.resubscribe
+	g.oe.handle = g.orders.handle
+	return g, nil
+}
+
+func (g *Gateway) Name() string { return g.cfg.Venue }
+
+// Connect logs on both sessions. Each then reconnects on its own until ctx
+// ends.
+func (g *Gateway) Connect(ctx context.Context) error {
+	mdReader, err := g.md.connect(ctx)
+	if err != nil {
+		return err
+	}
+	oeReader, err := g.oe.connect(ctx)
+	if err != nil {
+		g.md.close()
+		return err
+	}
+	go g.md.run(ctx, mdReader)
+	go g.oe.run(ctx, oeReader)
+	return nil
+}
+
+func (g *Gateway) Close() error {
+	g.md.close()
+	return g.oe.close()
+}
+
+// Health reports both sessions as the venue's connections. With no REST API,
+// the order entry session being logged on stands in for REST reachability.
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	return gateway.ProbeHealth(ctx, g.cfg.Venue, func(context.Context) error {
+		if !g.oe.state.Connected() {
+			return errNotLoggedOn
+		}
+		return nil
+	}, &g.md.state, &g.oe.state)
+}
+
+func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
+	return g.market.subscribeBook(symbol)
+}
+
+func (g *Gateway) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
+	return g.market.subscribeTrades(symbol)
+}
+
+// SubscribeFunding returns a channel that never receives data: FIX 4.4 has
+// no funding rate message.
+func (g *Gateway) SubscribeFunding(ctx context.Context, symbol string) (<-chan domain.FundingRate, error) {
+	return make(chan domain.FundingRate), nil
+}
+
+func (g *Gateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	return g.orders.placeOrder(ctx, req)
+}
+
+func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	return g.orders.cancelOrder(ctx, orderID)
+}
+
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	return g.orders.orderStatus(ctx, orderID)
+}
+
+func (g *Gateway) AmendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	return g.orders.amendOrder(ctx, orderID, newPrice, newSize)
+}
+
+// PlaceOrders and CancelOrders send one message per order; FIX 4.4 list
+// orders are not widely supported.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, g.orders.placeOrder)
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.orders.cancelOrder)
+}
+
+func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	return g.orders.openOrders(symbol), nil
+}
+
+// SubscribeOrderUpdates streams every ExecutionReport on the order entry
+// session.
+func (g *Gateway) SubscribeOrderUpdates(ctx context.Context) (<-chan domain.OrderUpdate, error) {
+	return g.orders.subscribeOrderUpdates(), nil
+}
+
+func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	return nil, errAccountUnsupported
+}
+
+func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	return nil, errAccountUnsupported
+}
+
+// GetFeeTier returns the fees from the gateway's configuration.
+func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	return &domain.FeeTier{
+		MakerFeeBps: g.cfg.MakerFeeBps,
+		TakerFeeBps: g.cfg.TakerFeeBps,
+		Venue:       g.cfg.Venue,
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// GetInstruments is not supported: FIX 4.4 SecurityDefinition messages carry
+// no tick or lot sizes in a form counterparties agree on.
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return nil, gateway.ErrInstrumentsUnsupported
+}
+
+// GetRateLimitStatus returns no categories: FIX sessions are throttled by
+// the counterparty without a published budget.
+func (g *Gateway) GetRateLimitStatus(ctx context.Context) ([]domain.RateLimitStatus, error) {
+	return nil, nil
+}
+
+func (g *Gateway) Withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
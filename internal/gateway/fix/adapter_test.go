@@ -0,0 +1,237 @@
+package fix
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// acceptor is a minimal FIX counterparty: it answers Logon, and passes every
+// other message to respond, sending back whatever it returns.
+type acceptor struct {
+	ln      net.Listener
+	respond func(m *Message) []*Message
+
+	mu  sync.Mutex
+	got []*Message
+}
+
+func newAcceptor(t *testing.T, respond func(m *Message) []*Message) *acceptor {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	a := &acceptor{ln: ln, respond: respond}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go a.serve(conn)
+		}
+	}()
+	return a
+}
+
+func (a *acceptor) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	seq := 0
+	write := func(m *Message) {
+		seq++
+		conn.Write(m.encode([]field{{tagSenderCompID, "BROKER"}, {tagTargetCompID, "DESK"}, {tagMsgSeqNum, strconv.Itoa(seq)}, {tagSendingTime, utcTimestamp(time.Now())}}))
+	}
+	for {
+		raw, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		m, err := parseMessage(raw)
+		if err != nil {
+			return
+		}
+		a.mu.Lock()
+		a.got = append(a.got, m)
+		a.mu.Unlock()
+		if m.msgType() == msgLogon {
+			write(newMessage(msgLogon).set(tagEncryptMethod, "0").set(tagHeartBtInt, m.get(tagHeartBtInt)))
+			continue
+		}
+		for _, reply := range a.respond(m) {
+			write(reply)
+		}
+	}
+}
+
+func (a *acceptor) received(msgType string) []*Message {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var out []*Message
+	for _, m := range a.got {
+		if m.msgType() == msgType {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func newTestGateway(t *testing.T, a *acceptor) *Gateway {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	g, err := New(Config{
+		Venue:          "primebroker",
+		MarketDataAddr: a.ln.Addr().String(),
+		OrderEntryAddr: a.ln.Addr().String(),
+		SenderCompID:   "DESK",
+		TargetCompID:   "BROKER",
+		Username:       "desk",
+		Password:       "secret",
+		Depth:          10,
+	}, logger)
+	if err != nil {
+		t.Fatalf("new gateway: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := g.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	return g
+}
+
+func TestGatewayMarketData(t *testing.T) {
+	a := newAcceptor(t, func(m *Message) []*Message {
+		if m.msgType() != msgMarketDataRequest {
+			return nil
+		}
+		snap := newMessage(msgMarketDataSnapshot).set(tagMDReqID, m.get(tagMDReqID)).set(tagSymbol, "BTC/USDT").
+			set(tagNoMDEntries, "3").
+			set(tagMDEntryType, entryBid).set(tagMDEntryPx, "60000").set(tagMDEntrySize, "1").
+			set(tagMDEntryType, entryBid).set(tagMDEntryPx, "59999").set(tagMDEntrySize, "2").
+			set(tagMDEntryType, entryOffer).set(tagMDEntryPx, "60001").set(tagMDEntrySize, "1")
+		inc := newMessage(msgMarketDataIncrement).set(tagMDReqID, m.get(tagMDReqID)).
+			set(tagNoMDEntries, "2").
+			set(tagMDUpdateAction, actionDelete).set(tagMDEntryType, entryBid).set(tagSymbol, "BTC/USDT").set(tagMDEntryPx, "59999").
+			set(tagMDUpdateAction, actionNew).set(tagMDEntryType, entryOffer).set(tagSymbol, "BTC/USDT").set(tagMDEntryPx, "60002").set(tagMDEntrySize, "3")
+		return []*Message{snap, inc}
+	})
+	g := newTestGateway(t, a)
+
+	ch, err := g.SubscribeOrderBook(context.Background(), "BTC/USDT")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	snap := receive(t, ch)
+	if len(snap.Bids) != 2 || len(snap.Asks) != 1 || snap.Venue != "primebroker" || snap.VenueTimestamp.IsZero() {
+		t.Errorf("unexpected snapshot delta %+v", snap)
+	}
+	inc := receive(t, ch)
+	if len(inc.Bids) != 1 || !inc.Bids[0].Size.IsZero() || !inc.Bids[0].Price.Equal(decimal.NewFromInt(59999)) {
+		t.Errorf("expected the deleted bid as a zero-size level, got %+v", inc.Bids)
+	}
+	if len(inc.Asks) != 1 || !inc.Asks[0].Size.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("expected the new ask, got %+v", inc.Asks)
+	}
+
+	reqs := a.received(msgMarketDataRequest)
+	if len(reqs) != 1 || reqs[0].get(tagMarketDepth) != "10" || reqs[0].get(tagSymbol) != "BTC/USDT" {
+		t.Errorf("unexpected market data requests %v", reqs)
+	}
+	if logons := a.received(msgLogon); len(logons) != 2 || logons[0].get(tagPassword) != "secret" || logons[0].get(tagResetSeqNumFlag) != "Y" {
+		t.Errorf("expected both sessions to log on with credentials, got %v", logons)
+	}
+}
+
+func TestGatewayOrderLifecycle(t *testing.T) {
+	a := newAcceptor(t, func(m *Message) []*Message {
+		report := func(clOrdID, status string) *Message {
+			return newMessage(msgExecutionReport).set(tagOrderID, "PB-1").set(tagClOrdID, clOrdID).
+				set(tagOrigClOrdID, m.get(tagOrigClOrdID)).set(tagExecType, status).set(tagOrdStatus, status).
+				set(tagSymbol, "BTC/USDT").set(tagCumQty, "0.4").set(tagAvgPx, "60000")
+		}
+		switch m.msgType() {
+		case msgNewOrderSingle:
+			if m.get(tagExecInst) == "6" {
+				return []*Message{newMessage(msgExecutionReport).set(tagClOrdID, m.get(tagClOrdID)).
+					set(tagOrdStatus, "8").set(tagText, "would take liquidity")}
+			}
+			return []*Message{report(m.get(tagClOrdID), "1")}
+		case msgOrderCancelRequest:
+			return []*Message{report(m.get(tagClOrdID), "4")}
+		}
+		return nil
+	})
+	g := newTestGateway(t, a)
+	updates, _ := g.SubscribeOrderUpdates(context.Background())
+
+	req := domain.OrderRequest{
+		InternalID: uuid.New(), Symbol: "BTC/USDT", Side: domain.SideSell, OrderType: domain.OrderTypeLimit,
+		TimeInForce: domain.TimeInForceGTC, Price: decimal.NewFromInt(60000), Size: decimal.NewFromInt(1),
+		IdempotencyKey: "idem-1",
+	}
+	ack, err := g.PlaceOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("place: %v", err)
+	}
+	if ack.VenueID != "PB-1" || ack.Status != domain.OrderStatusPartialFill {
+		t.Errorf("unexpected ack %+v", ack)
+	}
+	if u := receive(t, updates); u.ClientOrderID != "idem-1" || !u.FilledSize.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("unexpected update %+v", u)
+	}
+	nos := a.received(msgNewOrderSingle)[0]
+	if nos.get(tagSide) != sideSell || nos.get(tagOrdType) != "2" || nos.get(tagPrice) != "60000" || nos.get(tagTimeInForce) != "1" {
+		t.Errorf("unexpected NewOrderSingle %s", nos)
+	}
+	if open, _ := g.GetOpenOrders(context.Background(), "BTC/USDT"); len(open) != 1 || open[0].VenueID != "PB-1" {
+		t.Errorf("expected the order listed as open, got %+v", open)
+	}
+
+	cancelAck, err := g.CancelOrder(context.Background(), "PB-1")
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancelAck.Status != domain.OrderStatusCancelled {
+		t.Errorf("unexpected cancel ack %+v", cancelAck)
+	}
+	if f := a.received(msgOrderCancelRequest)[0]; f.get(tagOrigClOrdID) != "idem-1" || f.get(tagOrderQty) != "1" {
+		t.Errorf("unexpected cancel request %s", f)
+	}
+	if open, _ := g.GetOpenOrders(context.Background(), ""); len(open) != 0 {
+		t.Errorf("expected no open orders after the cancel, got %+v", open)
+	}
+
+	req.InternalID, req.IdempotencyKey, req.PostOnly = uuid.New(), "idem-2", true
+	if _, err := g.PlaceOrder(context.Background(), req); err == nil {
+		t.Error("expected the rejected post-only order to fail")
+	}
+	if _, err := g.CancelOrder(context.Background(), "unknown"); err == nil {
+		t.Error("expected cancelling an order from another session to fail")
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a message")
+		var zero T
+		return zero
+	}
+}
@@ -0,0 +1,318 @@
+package fix
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// MDEntryType values.
+const (
+	entryBid   = "0"
+	entryOffer = "1"
+	entryTrade = "2"
+)
+
+// MDUpdateAction values.
+const (
+	actionNew    = "0"
+	actionChange = "1"
+	actionDelete = "2"
+)
+
+// The MDEntry fields the gateway reads. The first tag opens each entry:
+// MDEntryType in a snapshot, MDUpdateAction in an incremental refresh.
+var (
+	snapshotEntryTags    = []int{tagMDEntryType, tagMDEntryPx, tagMDEntrySize, tagMDEntryDate, tagMDEntryTime, tagSide}
+	incrementalEntryTags = []int{tagMDUpdateAction, tagMDEntryType, tagSymbol, tagMDEntryPx, tagMDEntrySize, tagMDEntryDate, tagMDEntryTime, tagSide}
+)
+
+// marketData turns MarketDataSnapshotFullRefresh and IncrementalRefresh
+// messages into book deltas and trades.
+type marketData struct {
+	venue  string
+	depth  int
+	send   func(*Message) (int, error)
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	books  map[string]*book // by symbol
+	bookCh map[string]chan domain.OrderBookDelta
+	tradeC map[string]chan domain.Trade
+	subs   []*Message // MarketDataRequests to repeat after a reconnect
+}
+
+// book is the last known state of one symbol's book, keyed by price string,
+// used to turn full refreshes into deltas.
+type book struct {
+	bids, asks map[string]domain.PriceLevel
+}
+
+func newMarketData(venue string, depth int, send func(*Message) (int, error), logger *slog.Logger) *marketData {
+	return &marketData{
+		venue:  venue,
+		depth:  depth,
+		send:   send,
+		logger: logger,
+		books:  make(map[string]*book),
+		bookCh: make(map[string]chan domain.OrderBookDelta),
+		tradeC: make(map[string]chan domain.Trade),
+	}
+}
+
+// subscribeBook requests a snapshot plus incremental updates of symbol's
+// book.
+func (md *marketData) subscribeBook(symbol string) (<-chan domain.OrderBookDelta, error) {
+	md.mu.Lock()
+	ch, ok := md.bookCh[symbol]
+	if !ok {
+		ch = make(chan domain.OrderBookDelta, 256)
+		md.bookCh[symbol] = ch
+	}
+	md.mu.Unlock()
+	if ok {
+		return ch, nil
+	}
+	return ch, md.request("book:"+symbol, symbol, md.depth, entryBid, entryOffer)
+}
+
+// subscribeTrades requests symbol's trades.
+func (md *marketData) subscribeTrades(symbol string) (<-chan domain.Trade, error) {
+	md.mu.Lock()
+	ch, ok := md.tradeC[symbol]
+	if !ok {
+		ch = make(chan domain.Trade, 256)
+		md.tradeC[symbol] = ch
+	}
+	md.mu.Unlock()
+	if ok {
+		return ch, nil
+	}
+	return ch, md.request("trades:"+symbol, symbol, 1, entryTrade)
+}
+
+// request sends a MarketDataRequest for snapshot plus incremental updates
+// and remembers it for resubscribing.
+func (md *marketData) request(reqID, symbol string, depth int, entryTypes ...string) error {
+	m := newMessage(msgMarketDataRequest).
+		set(tagMDReqID, reqID).
+		set(tagSubscriptionReqTyp, "1").
+		set(tagMarketDepth, strconv.Itoa(depth)).
+		set(tagMDUpdateType, "1").
+		set(tagNoMDEntryTypes, strconv.Itoa(len(entryTypes)))
+	for _, t := range entryTypes {
+		m.set(tagMDEntryType, t)
+	}
+	m.set(tagNoRelatedSym, "1").set(tagSymbol, symbol)
+
+	md.mu.Lock()
+	md.subs = append(md.subs, m)
+	md.mu.Unlock()
+	if _, err := md.send(m); err != nil {
+		return fmt.Errorf("fix market data request %s: %w", reqID, err)
+	}
+	return nil
+}
+
+// resubscribe repeats every MarketDataRequest. Books restart from the fresh
+// snapshots, so the old state is dropped.
+func (md *marketData) resubscribe() {
+	md.mu.Lock()
+	subs := append([]*Message(nil), md.subs...)
+	md.mu.Unlock()
+	for _, m := range subs {
+		if _, err := md.send(m); err != nil {
+			md.logger.Warn("failed to resubscribe fix market data", "md_req_id", m.get(tagMDReqID), "error", err)
+		}
+	}
+}
+
+func (md *marketData) handle(m *Message) {
+	switch m.msgType() {
+	case msgMarketDataSnapshot:
+		md.handleSnapshot(m)
+	case msgMarketDataIncrement:
+		md.handleIncrement(m)
+	case msgMarketDataReject:
+		md.logger.Warn("fix market data request rejected", "md_req_id", m.get(tagMDReqID), "text", m.get(tagText))
+	}
+}
+
+// handleSnapshot replaces a symbol's book. The delta carries every level in
+// the snapshot plus a zero-size level for each price that dropped out.
+func (md *marketData) handleSnapshot(m *Message) {
+	symbol := m.get(tagSymbol)
+	next := &book{bids: make(map[string]domain.PriceLevel), asks: make(map[string]domain.PriceLevel)}
+	for _, e := range m.groups(tagNoMDEntries, snapshotEntryTags...) {
+		lvl, err := parseLevel(e)
+		if err != nil {
+			md.logger.Warn("invalid fix snapshot level, dropping snapshot", "symbol", symbol, "error", err)
+			return
+		}
+		switch e.get(tagMDEntryType) {
+		case entryBid:
+			next.bids[lvl.Price.String()] = lvl
+		case entryOffer:
+			next.asks[lvl.Price.String()] = lvl
+		}
+	}
+
+	md.mu.Lock()
+	ch, ok := md.bookCh[symbol]
+	prev := md.books[symbol]
+	md.books[symbol] = next
+	md.mu.Unlock()
+	if !ok {
+		return
+	}
+	if prev == nil {
+		prev = &book{}
+	}
+	md.publish(ch, domain.OrderBookDelta{
+		Venue:          md.venue,
+		Symbol:         symbol,
+		Bids:           diffLevels(prev.bids, next.bids),
+		Asks:           diffLevels(prev.asks, next.asks),
+		VenueTimestamp: m.sendingTime(),
+		LocalTimestamp: time.Now(),
+	})
+}
+
+// handleIncrement applies an incremental refresh. Entries may cover several
+// symbols; each symbol's book changes become one delta, and trade entries
+// become trades.
+func (md *marketData) handleIncrement(m *Message) {
+	sent := m.sendingTime()
+	deltas := make(map[string]*domain.OrderBookDelta)
+	var order []string
+	symbol := m.get(tagSymbol) // entries without their own Symbol inherit the previous one
+
+	md.mu.Lock()
+	defer md.mu.Unlock()
+	for _, e := range m.groups(tagNoMDEntries, incrementalEntryTags...) {
+		if s := e.get(tagSymbol); s != "" {
+			symbol = s
+		}
+		lvl, err := parseLevel(e)
+		if err != nil {
+			md.logger.Warn("invalid fix incremental entry, skipping", "symbol", symbol, "error", err)
+			continue
+		}
+
+		if e.get(tagMDEntryType) == entryTrade {
+			if ch, ok := md.tradeC[symbol]; ok && e.get(tagMDUpdateAction) != actionDelete {
+				md.publishTrade(ch, tradeFrom(md.venue, symbol, lvl, e, sent))
+			}
+			continue
+		}
+
+		b := md.books[symbol]
+		if b == nil {
+			// Increments before the first snapshot have nothing to apply to.
+			continue
+		}
+		var side map[string]domain.PriceLevel
+		switch e.get(tagMDEntryType) {
+		case entryBid:
+			side = b.bids
+		case entryOffer:
+			side = b.asks
+		default:
+			continue
+		}
+		switch e.get(tagMDUpdateAction) {
+		case actionNew, actionChange:
+			side[lvl.Price.String()] = lvl
+		case actionDelete:
+			delete(side, lvl.Price.String())
+			lvl.Size = decimal.Zero
+		default:
+			continue
+		}
+
+		d, ok := deltas[symbol]
+		if !ok {
+			d = &domain.OrderBookDelta{Venue: md.venue, Symbol: symbol, VenueTimestamp: sent, LocalTimestamp: time.Now()}
+			deltas[symbol] = d
+			order = append(order, symbol)
+		}
+		if e.get(tagMDEntryType) == entryBid {
+			d.Bids = append(d.Bids, lvl)
+		} else {
+			d.Asks = append(d.Asks, lvl)
+		}
+	}
+	for _, s := range order {
+		if ch, ok := md.bookCh[s]; ok {
+			md.publish(ch, *deltas[s])
+		}
+	}
+}
+
+func (md *marketData) publish(ch chan domain.OrderBookDelta, d domain.OrderBookDelta) {
+	select {
+	case ch <- d:
+	default:
+		md.logger.Warn("fix orderbook channel full, dropping update", "symbol", d.Symbol)
+	}
+}
+
+func (md *marketData) publishTrade(ch chan domain.Trade, t domain.Trade) {
+	select {
+	case ch <- t:
+	default:
+		md.logger.Warn("fix trade channel full, dropping update", "symbol", t.Symbol)
+	}
+}
+
+// parseLevel reads an entry's price and size. A delete may omit the size.
+func parseLevel(e *Message) (domain.PriceLevel, error) {
+	price, err := decimal.NewFromString(e.get(tagMDEntryPx))
+	if err != nil {
+		return domain.PriceLevel{}, fmt.Errorf("MDEntryPx %q: %w", e.get(tagMDEntryPx), err)
+	}
+	size := decimal.Zero
+	if s := e.get(tagMDEntrySize); s != "" {
+		if size, err = decimal.NewFromString(s); err != nil {
+			return domain.PriceLevel{}, fmt.Errorf("MDEntrySize %q: %w", s, err)
+		}
+	}
+	return domain.PriceLevel{Price: price, Size: size}, nil
+}
+
+// tradeFrom builds a trade from an entry, timestamped from MDEntryDate and
+// MDEntryTime when given and the message's SendingTime otherwise. Side is
+// the aggressor side where the counterparty sends it, and buy otherwise.
+func tradeFrom(venue, symbol string, lvl domain.PriceLevel, e *Message, sent time.Time) domain.Trade {
+	t := domain.Trade{Venue: venue, Symbol: symbol, Price: lvl.Price, Size: lvl.Size, Side: domain.SideBuy, Timestamp: sent}
+	if e.get(tagSide) == sideSell {
+		t.Side = domain.SideSell
+	}
+	if date, clock := e.get(tagMDEntryDate), e.get(tagMDEntryTime); date != "" && clock != "" {
+		if ts, err := parseUTCTimestamp(date + "-" + clock); err == nil {
+			t.Timestamp = ts
+		}
+	}
+	return t
+}
+
+// diffLevels returns the levels of next plus a zero-size level for every
+// price in prev that is missing from next.
+func diffLevels(prev, next map[string]domain.PriceLevel) []domain.PriceLevel {
+	out := make([]domain.PriceLevel, 0, len(next)+len(prev))
+	for _, l := range next {
+		out = append(out, l)
+	}
+	for p, l := range prev {
+		if _, ok := next[p]; !ok {
+			out = append(out, domain.PriceLevel{Price: l.Price, Size: decimal.Zero})
+		}
+	}
+	return out
+}
@@ -0,0 +1,311 @@
+package fix
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	beginString = "FIX.4.4"
+	soh         = '\x01'
+
+	// sendingTimeLayout is the UTCTimestamp format with milliseconds.
+	sendingTimeLayout = "20060102-15:04:05.000"
+)
+
+// Tags used by the gateway.
+const (
+	tagAccount            = 1
+	tagAvgPx              = 6
+	tagBeginSeqNo         = 7
+	tagBeginString        = 8
+	tagBodyLength         = 9
+	tagCheckSum           = 10
+	tagClOrdID            = 11
+	tagCumQty             = 14
+	tagEndSeqNo           = 16
+	tagExecInst           = 18
+	tagHandlInst          = 21
+	tagMsgSeqNum          = 34
+	tagMsgType            = 35
+	tagNewSeqNo           = 36
+	tagOrderID            = 37
+	tagOrderQty           = 38
+	tagOrdStatus          = 39
+	tagOrdType            = 40
+	tagOrigClOrdID        = 41
+	tagPossDupFlag        = 43
+	tagPrice              = 44
+	tagRefSeqNum          = 45
+	tagSenderCompID       = 49
+	tagSendingTime        = 52
+	tagSide               = 54
+	tagSymbol             = 55
+	tagTargetCompID       = 56
+	tagText               = 58
+	tagTimeInForce        = 59
+	tagTransactTime       = 60
+	tagStopPx             = 99
+	tagEncryptMethod      = 98
+	tagCxlRejReason       = 102
+	tagOrdRejReason       = 103
+	tagHeartBtInt         = 108
+	tagTestReqID          = 112
+	tagGapFillFlag        = 123
+	tagResetSeqNumFlag    = 141
+	tagExecType           = 150
+	tagMDReqID            = 262
+	tagSubscriptionReqTyp = 263
+	tagMarketDepth        = 264
+	tagMDUpdateType       = 265
+	tagNoMDEntryTypes     = 267
+	tagNoMDEntries        = 268
+	tagMDEntryType        = 269
+	tagMDEntryPx          = 270
+	tagMDEntrySize        = 271
+	tagMDEntryDate        = 272
+	tagMDEntryTime        = 273
+	tagMDUpdateAction     = 279
+	tagNoRelatedSym       = 146
+	tagBusinessRejectRef  = 379
+	tagUsername           = 553
+	tagPassword           = 554
+	tagOrdStatusReqID     = 790
+)
+
+// Message types used by the gateway.
+const (
+	msgHeartbeat           = "0"
+	msgTestRequest         = "1"
+	msgResendRequest       = "2"
+	msgReject              = "3"
+	msgSequenceReset       = "4"
+	msgLogout              = "5"
+	msgExecutionReport     = "8"
+	msgOrderCancelReject   = "9"
+	msgLogon               = "A"
+	msgNewOrderSingle      = "D"
+	msgOrderCancelRequest  = "F"
+	msgOrderCancelReplace  = "G"
+	msgOrderStatusRequest  = "H"
+	msgMarketDataRequest   = "V"
+	msgMarketDataSnapshot  = "W"
+	msgMarketDataIncrement = "X"
+	msgMarketDataReject    = "Y"
+	msgBusinessReject      = "j"
+)
+
+type field struct {
+	tag   int
+	value string
+}
+
+// Message is a FIX message as an ordered list of fields. Messages built for
+// sending hold the type and body only; the session adds the rest of the
+// header and the trailer. Parsed messages hold every field.
+type Message struct {
+	fields []field
+}
+
+// newMessage starts a message of type msgType.
+func newMessage(msgType string) *Message {
+	return (&Message{}).set(tagMsgType, msgType)
+}
+
+// set appends a field. Repeating groups are written by setting the count tag
+// and then each entry's fields in order.
+func (m *Message) set(tag int, value string) *Message {
+	m.fields = append(m.fields, field{tag, value})
+	return m
+}
+
+// get returns the first value of tag, or "".
+func (m *Message) get(tag int) string {
+	for _, f := range m.fields {
+		if f.tag == tag {
+			return f.value
+		}
+	}
+	return ""
+}
+
+// has reports whether tag is present.
+func (m *Message) has(tag int) bool {
+	for _, f := range m.fields {
+		if f.tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Message) msgType() string { return m.get(tagMsgType) }
+
+func (m *Message) seqNum() int {
+	n, _ := strconv.Atoi(m.get(tagMsgSeqNum))
+	return n
+}
+
+// sendingTime returns the header's SendingTime, or the zero time.
+func (m *Message) sendingTime() time.Time {
+	t, _ := parseUTCTimestamp(m.get(tagSendingTime))
+	return t
+}
+
+// groups splits the repeating group counted by countTag into one Message
+// per entry. members lists the tags an entry may hold, delimiter first; the
+// group ends at the first tag not in members.
+func (m *Message) groups(countTag int, members ...int) []*Message {
+	in := make(map[int]bool, len(members))
+	for _, t := range members {
+		in[t] = true
+	}
+	start := -1
+	for i, f := range m.fields {
+		if f.tag == countTag {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	var out []*Message
+	for _, f := range m.fields[start:] {
+		if !in[f.tag] {
+			break
+		}
+		if f.tag == members[0] {
+			out = append(out, &Message{})
+		} else if len(out) == 0 {
+			break
+		}
+		out[len(out)-1].fields = append(out[len(out)-1].fields, f)
+	}
+	return out
+}
+
+// encode writes the message with a full standard header and trailer.
+// header holds the header fields after MsgType, in order.
+func (m *Message) encode(header []field) []byte {
+	var body bytes.Buffer
+	writeField := func(buf *bytes.Buffer, tag int, value string) {
+		buf.WriteString(strconv.Itoa(tag))
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte(soh)
+	}
+	writeField(&body, tagMsgType, m.msgType())
+	for _, f := range header {
+		writeField(&body, f.tag, f.value)
+	}
+	for _, f := range m.fields {
+		if f.tag != tagMsgType {
+			writeField(&body, f.tag, f.value)
+		}
+	}
+
+	var out bytes.Buffer
+	writeField(&out, tagBeginString, beginString)
+	writeField(&out, tagBodyLength, strconv.Itoa(body.Len()))
+	out.Write(body.Bytes())
+	writeField(&out, tagCheckSum, fmt.Sprintf("%03d", checksum(out.Bytes())))
+	return out.Bytes()
+}
+
+// String renders the message with | for SOH, for logs.
+func (m *Message) String() string {
+	var b bytes.Buffer
+	for _, f := range m.fields {
+		if f.tag == tagPassword {
+			f.value = "***"
+		}
+		fmt.Fprintf(&b, "%d=%s|", f.tag, f.value)
+	}
+	return b.String()
+}
+
+func checksum(b []byte) int {
+	sum := 0
+	for _, c := range b {
+		sum += int(c)
+	}
+	return sum % 256
+}
+
+// readMessage reads one raw message from r: the BeginString and BodyLength
+// fields, the body, and the CheckSum field.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	begin, err := r.ReadBytes(soh)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(begin, []byte("8=")) {
+		return nil, fmt.Errorf("expected BeginString, got %q", begin)
+	}
+	length, err := r.ReadBytes(soh)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(length, []byte("9=")) {
+		return nil, fmt.Errorf("expected BodyLength, got %q", length)
+	}
+	n, err := strconv.Atoi(string(length[2 : len(length)-1]))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid BodyLength %q", length)
+	}
+	// The body is followed by "10=nnn" and SOH.
+	rest := make([]byte, n+7)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, 0, len(begin)+len(length)+len(rest))
+	raw = append(raw, begin...)
+	raw = append(raw, length...)
+	return append(raw, rest...), nil
+}
+
+// parseMessage parses and verifies a raw message read by readMessage.
+func parseMessage(raw []byte) (*Message, error) {
+	if len(raw) < 7 || !bytes.HasPrefix(raw[len(raw)-7:], []byte("10=")) || raw[len(raw)-1] != soh {
+		return nil, fmt.Errorf("missing CheckSum")
+	}
+	want, err := strconv.Atoi(string(raw[len(raw)-4 : len(raw)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CheckSum: %w", err)
+	}
+	if got := checksum(raw[:len(raw)-7]); got != want {
+		return nil, fmt.Errorf("checksum mismatch: got %03d, message says %03d", got, want)
+	}
+
+	m := &Message{}
+	for _, part := range bytes.Split(raw[:len(raw)-1], []byte{soh}) {
+		eq := bytes.IndexByte(part, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("malformed field %q", part)
+		}
+		tag, err := strconv.Atoi(string(part[:eq]))
+		if err != nil {
+			return nil, fmt.Errorf("malformed tag %q", part[:eq])
+		}
+		m.fields = append(m.fields, field{tag, string(part[eq+1:])})
+	}
+	if m.get(tagBeginString) != beginString {
+		return nil, fmt.Errorf("unsupported BeginString %q", m.get(tagBeginString))
+	}
+	return m, nil
+}
+
+func utcTimestamp(t time.Time) string {
+	return t.UTC().Format(sendingTimeLayout)
+}
+
+// parseUTCTimestamp accepts UTCTimestamp values with or without fractional
+// seconds.
+func parseUTCTimestamp(s string) (time.Time, error) {
+	return time.Parse("20060102-15:04:05.999999999", s)
+}
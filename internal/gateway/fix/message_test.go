@@ -0,0 +1,49 @@
+package fix
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	m := newMessage(msgMarketDataSnapshot).
+		set(tagSymbol, "BTC/USDT").
+		set(tagNoMDEntries, "2").
+		set(tagMDEntryType, entryBid).set(tagMDEntryPx, "60000").set(tagMDEntrySize, "1.5").
+		set(tagMDEntryType, entryOffer).set(tagMDEntryPx, "60001").
+		set(tagText, "after the group")
+	raw := m.encode([]field{{tagSenderCompID, "BROKER"}, {tagTargetCompID, "US"}, {tagMsgSeqNum, "7"}})
+	if !bytes.HasPrefix(raw, []byte("8=FIX.4.4\x019=")) || !bytes.Contains(raw, []byte("\x0135=W\x0149=BROKER\x01")) {
+		t.Fatalf("unexpected header in %q", raw)
+	}
+
+	read, err := readMessage(bufio.NewReader(bytes.NewReader(append(raw, raw...))))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got, err := parseMessage(read)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got.msgType() != msgMarketDataSnapshot || got.seqNum() != 7 || got.get(tagSymbol) != "BTC/USDT" {
+		t.Errorf("unexpected message %s", got)
+	}
+	entries := got.groups(tagNoMDEntries, snapshotEntryTags...)
+	if len(entries) != 2 || entries[0].get(tagMDEntrySize) != "1.5" || entries[1].get(tagMDEntryPx) != "60001" || entries[1].has(tagMDEntrySize) {
+		t.Errorf("unexpected group entries %v", entries)
+	}
+
+	corrupt := bytes.Replace(read, []byte("60001"), []byte("60002"), 1)
+	if _, err := parseMessage(corrupt); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestMessageStringRedactsPassword(t *testing.T) {
+	m := newMessage(msgLogon).set(tagUsername, "desk").set(tagPassword, "hunter2")
+	if s := m.String(); strings.Contains(s, "hunter2") || !strings.Contains(s, "553=desk") {
+		t.Errorf("expected the password redacted, got %q", s)
+	}
+}
@@ -0,0 +1,499 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// Side values.
+const (
+	sideBuy  = "1"
+	sideSell = "2"
+)
+
+// ExecType values that answer a request rather than report a change.
+const execTypeOrderStatus = "I"
+
+// errUnknownOrder is returned for orders this session did not place: FIX
+// cancels and replaces need the order's ClOrdID, side and quantity.
+var errUnknownOrder = errors.New("order not placed through this fix session")
+
+// orderEntry places and tracks orders on the order entry session. Requests
+// wait for the counterparty's first answer: an ExecutionReport,
+// OrderCancelReject, session Reject or BusinessMessageReject.
+type orderEntry struct {
+	venue   string
+	account string
+	timeout time.Duration
+	send    func(*Message) (int, error)
+	logger  *slog.Logger
+
+	mu       sync.Mutex
+	orders   map[string]*order // by OrderID
+	byClOrd  map[string]*order // by every ClOrdID the order has had
+	waiters  map[string]chan *Message
+	bySeq    map[int]string // MsgSeqNum of a pending request to its waiter key
+	updateCh chan domain.OrderUpdate
+}
+
+// order is what a cancel or replace must repeat about an order.
+type order struct {
+	orderID   string
+	clOrdID   string // latest; changes with each cancel or replace request
+	clientID  string // the first ClOrdID, reported as ClientOrderID
+	symbol    string
+	side      domain.Side
+	orderType domain.OrderType
+	tif       domain.TimeInForce
+	postOnly  bool
+	price     decimal.Decimal
+	stop      decimal.Decimal
+	size      decimal.Decimal
+	filled    decimal.Decimal
+	avgPx     decimal.Decimal
+	status    domain.OrderStatus
+	created   time.Time
+	updated   time.Time
+}
+
+func newOrderEntry(venue, account string, send func(*Message) (int, error), logger *slog.Logger) *orderEntry {
+	return &orderEntry{
+		venue:   venue,
+		account: account,
+		timeout: 10 * time.Second,
+		send:    send,
+		logger:  logger,
+		orders:  make(map[string]*order),
+		byClOrd: make(map[string]*order),
+		waiters: make(map[string]chan *Message),
+		bySeq:   make(map[int]string),
+	}
+}
+
+// request sends m and waits for the answer routed to key.
+func (oe *orderEntry) request(ctx context.Context, key string, m *Message) (*Message, error) {
+	ch := make(chan *Message, 1)
+	oe.mu.Lock()
+	oe.waiters[key] = ch
+	oe.mu.Unlock()
+
+	seq, err := oe.send(m)
+	oe.mu.Lock()
+	if err == nil {
+		oe.bySeq[seq] = key
+	}
+	oe.mu.Unlock()
+	defer func() {
+		oe.mu.Lock()
+		delete(oe.waiters, key)
+		delete(oe.bySeq, seq)
+		oe.mu.Unlock()
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(oe.timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("no answer from %s within %s", oe.venue, oe.timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// rejection turns a reject answer into an error, or returns nil for an
+// ExecutionReport.
+func rejection(reply *Message) error {
+	switch reply.msgType() {
+	case msgExecutionReport:
+		if reply.get(tagOrdStatus) == "8" {
+			return fmt.Errorf("order rejected (reason %s): %s", reply.get(tagOrdRejReason), reply.get(tagText))
+		}
+		return nil
+	case msgOrderCancelReject:
+		return fmt.Errorf("request rejected (reason %s): %s", reply.get(tagCxlRejReason), reply.get(tagText))
+	default:
+		return fmt.Errorf("request rejected by session: %s", reply.get(tagText))
+	}
+}
+
+func (oe *orderEntry) placeOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	clOrdID := req.IdempotencyKey
+	if clOrdID == "" {
+		clOrdID = req.InternalID.String()
+	}
+	m := newMessage(msgNewOrderSingle).set(tagClOrdID, clOrdID)
+	if oe.account != "" {
+		m.set(tagAccount, oe.account)
+	}
+	m.set(tagHandlInst, "1").
+		set(tagSymbol, req.Symbol).
+		set(tagSide, fixSide(req.Side)).
+		set(tagTransactTime, utcTimestamp(time.Now())).
+		set(tagOrderQty, req.Size.String())
+	if err := setOrderType(m, req.OrderType, req.Price, req.StopPrice, req.TimeInForce, req.PostOnly); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	o := &order{
+		clOrdID: clOrdID, clientID: clOrdID, symbol: req.Symbol, side: req.Side,
+		orderType: req.OrderType, tif: req.TimeInForce, postOnly: req.PostOnly,
+		price: req.Price, stop: req.StopPrice, size: req.Size, status: domain.OrderStatusSubmitted,
+		created: now, updated: now,
+	}
+	oe.mu.Lock()
+	oe.byClOrd[clOrdID] = o
+	oe.mu.Unlock()
+
+	reply, err := oe.request(ctx, clOrdID, m)
+	if err != nil {
+		return nil, fmt.Errorf("fix place order: %w", err)
+	}
+	if err := rejection(reply); err != nil {
+		return nil, fmt.Errorf("fix place order: %w", err)
+	}
+	oe.mu.Lock()
+	status := o.status
+	oe.mu.Unlock()
+	return &domain.OrderAck{
+		InternalID: req.InternalID,
+		VenueID:    reply.get(tagOrderID),
+		Status:     status,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+func (oe *orderEntry) cancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	oe.mu.Lock()
+	o, ok := oe.orders[orderID]
+	var orig, symbol string
+	var side domain.Side
+	var size decimal.Decimal
+	if ok {
+		orig, symbol, side, size = o.clOrdID, o.symbol, o.side, o.size
+	}
+	oe.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("fix cancel order %s: %w", orderID, errUnknownOrder)
+	}
+
+	clOrdID := uuid.NewString()
+	m := newMessage(msgOrderCancelRequest).
+		set(tagOrigClOrdID, orig).
+		set(tagOrderID, orderID).
+		set(tagClOrdID, clOrdID).
+		set(tagSymbol, symbol).
+		set(tagSide, fixSide(side)).
+		set(tagTransactTime, utcTimestamp(time.Now())).
+		set(tagOrderQty, size.String())
+	oe.track(o, clOrdID)
+
+	reply, err := oe.request(ctx, clOrdID, m)
+	if err == nil {
+		err = rejection(reply)
+	}
+	if err != nil {
+		oe.untrack(o, clOrdID, orig)
+		return nil, fmt.Errorf("fix cancel order %s: %w", orderID, err)
+	}
+	oe.mu.Lock()
+	status := o.status
+	oe.mu.Unlock()
+	return &domain.CancelAck{VenueID: orderID, Status: status, Timestamp: time.Now()}, nil
+}
+
+// amendOrder sends an OrderCancelReplaceRequest. The counterparty may give
+// the replacement a new OrderID, which the ack reports.
+func (oe *orderEntry) amendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	oe.mu.Lock()
+	o, ok := oe.orders[orderID]
+	var snap order
+	if ok {
+		snap = *o
+	}
+	oe.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("fix amend order %s: %w", orderID, errUnknownOrder)
+	}
+
+	clOrdID := uuid.NewString()
+	m := newMessage(msgOrderCancelReplace).
+		set(tagOrigClOrdID, snap.clOrdID).
+		set(tagOrderID, orderID).
+		set(tagClOrdID, clOrdID)
+	if oe.account != "" {
+		m.set(tagAccount, oe.account)
+	}
+	m.set(tagHandlInst, "1").
+		set(tagSymbol, snap.symbol).
+		set(tagSide, fixSide(snap.side)).
+		set(tagTransactTime, utcTimestamp(time.Now())).
+		set(tagOrderQty, newSize.String())
+	if err := setOrderType(m, snap.orderType, newPrice, snap.stop, snap.tif, snap.postOnly); err != nil {
+		return nil, err
+	}
+	oe.track(o, clOrdID)
+
+	reply, err := oe.request(ctx, clOrdID, m)
+	if err == nil {
+		err = rejection(reply)
+	}
+	if err != nil {
+		oe.untrack(o, clOrdID, snap.clOrdID)
+		return nil, fmt.Errorf("fix amend order %s: %w", orderID, err)
+	}
+	venueID := reply.get(tagOrderID)
+	if venueID == "" {
+		venueID = orderID
+	}
+	oe.mu.Lock()
+	status := o.status
+	oe.mu.Unlock()
+	return &domain.AmendAck{VenueID: venueID, Price: newPrice, Size: newSize, Status: status, Timestamp: time.Now()}, nil
+}
+
+// orderStatus asks for an order's current state with an OrderStatusRequest.
+func (oe *orderEntry) orderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	oe.mu.Lock()
+	o, ok := oe.orders[orderID]
+	var clOrdID, symbol string
+	var side domain.Side
+	if ok {
+		clOrdID, symbol, side = o.clOrdID, o.symbol, o.side
+	}
+	oe.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("fix order status %s: %w", orderID, errUnknownOrder)
+	}
+
+	reqID := uuid.NewString()
+	m := newMessage(msgOrderStatusRequest).
+		set(tagOrderID, orderID).
+		set(tagClOrdID, clOrdID).
+		set(tagOrdStatusReqID, reqID).
+		set(tagSymbol, symbol).
+		set(tagSide, fixSide(side))
+	reply, err := oe.request(ctx, reqID, m)
+	if err != nil {
+		return nil, fmt.Errorf("fix order status %s: %w", orderID, err)
+	}
+	if reply.msgType() != msgExecutionReport {
+		return nil, fmt.Errorf("fix order status %s: %w", orderID, rejection(reply))
+	}
+	update := oe.orderUpdate(reply)
+	return &update, nil
+}
+
+// openOrders lists the orders placed in this session that are still open.
+// FIX 4.4 has no standard way to list orders placed elsewhere.
+func (oe *orderEntry) openOrders(symbol string) []domain.Order {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	var out []domain.Order
+	for id, o := range oe.orders {
+		if o.status.IsTerminal() || (symbol != "" && o.symbol != symbol) {
+			continue
+		}
+		out = append(out, domain.Order{
+			VenueID: id, Venue: oe.venue, Symbol: o.symbol, Side: o.side,
+			OrderType: o.orderType, TimeInForce: o.tif, PostOnly: o.postOnly,
+			Price: o.price, Size: o.size, FilledSize: o.filled, AvgFillPrice: o.avgPx,
+			Status: o.status, CreatedAt: o.created, UpdatedAt: o.updated,
+		})
+	}
+	return out
+}
+
+func (oe *orderEntry) subscribeOrderUpdates() <-chan domain.OrderUpdate {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	if oe.updateCh == nil {
+		oe.updateCh = make(chan domain.OrderUpdate, 256)
+	}
+	return oe.updateCh
+}
+
+// track records clOrdID as the latest ClOrdID of o.
+func (oe *orderEntry) track(o *order, clOrdID string) {
+	oe.mu.Lock()
+	o.clOrdID = clOrdID
+	oe.byClOrd[clOrdID] = o
+	oe.mu.Unlock()
+}
+
+// untrack restores prev as the ClOrdID of o after a request using clOrdID
+// failed, since later requests must name the last accepted one.
+func (oe *orderEntry) untrack(o *order, clOrdID, prev string) {
+	oe.mu.Lock()
+	if o.clOrdID == clOrdID {
+		o.clOrdID = prev
+	}
+	oe.mu.Unlock()
+}
+
+func (oe *orderEntry) handle(m *Message) {
+	var key string
+	switch m.msgType() {
+	case msgExecutionReport:
+		key = m.get(tagClOrdID)
+		if m.get(tagExecType) == execTypeOrderStatus {
+			key = m.get(tagOrdStatusReqID)
+		} else {
+			oe.apply(m)
+		}
+	case msgOrderCancelReject:
+		key = m.get(tagClOrdID)
+	case msgBusinessReject:
+		key = m.get(tagBusinessRejectRef)
+	case msgReject:
+		ref, _ := strconv.Atoi(m.get(tagRefSeqNum))
+		oe.mu.Lock()
+		key = oe.bySeq[ref]
+		oe.mu.Unlock()
+		oe.logger.Warn("fix message rejected by counterparty", "ref_seq_num", ref, "text", m.get(tagText))
+	default:
+		return
+	}
+
+	oe.mu.Lock()
+	ch, ok := oe.waiters[key]
+	if ok {
+		delete(oe.waiters, key)
+	}
+	oe.mu.Unlock()
+	if ok {
+		ch <- m
+	}
+}
+
+// apply updates the tracked order from an ExecutionReport and publishes the
+// change.
+func (oe *orderEntry) apply(m *Message) {
+	update := oe.orderUpdate(m)
+
+	oe.mu.Lock()
+	o := oe.byClOrd[m.get(tagClOrdID)]
+	if o == nil {
+		o = oe.byClOrd[m.get(tagOrigClOrdID)]
+	}
+	if o != nil {
+		if id := m.get(tagOrderID); id != "" && id != o.orderID {
+			delete(oe.orders, o.orderID)
+			o.orderID = id
+			oe.orders[id] = o
+		}
+		if p, err := decimal.NewFromString(m.get(tagPrice)); err == nil && p.IsPositive() {
+			o.price = p
+		}
+		if q, err := decimal.NewFromString(m.get(tagOrderQty)); err == nil && q.IsPositive() {
+			o.size = q
+		}
+		o.filled, o.avgPx, o.status, o.updated = update.FilledSize, update.AvgFillPrice, update.Status, update.Timestamp
+		update.ClientOrderID = o.clientID
+	}
+	ch := oe.updateCh
+	oe.mu.Unlock()
+
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- update:
+	default:
+		oe.logger.Warn("fix order update channel full, dropping update", "order_id", update.VenueID)
+	}
+}
+
+func (oe *orderEntry) orderUpdate(m *Message) domain.OrderUpdate {
+	u := domain.OrderUpdate{
+		Venue:         oe.venue,
+		VenueID:       m.get(tagOrderID),
+		ClientOrderID: m.get(tagClOrdID),
+		Status:        orderStatus(m.get(tagOrdStatus)),
+		Timestamp:     time.Now(),
+	}
+	u.FilledSize, _ = decimal.NewFromString(m.get(tagCumQty))
+	u.AvgFillPrice, _ = decimal.NewFromString(m.get(tagAvgPx))
+	if u.Status == domain.OrderStatusAcknowledged && u.FilledSize.IsPositive() {
+		u.Status = domain.OrderStatusPartialFill
+	}
+	if t, err := parseUTCTimestamp(m.get(tagTransactTime)); err == nil {
+		u.Timestamp = t
+	}
+	return u
+}
+
+// orderStatus maps OrdStatus (39). Pending and replaced states are live
+// orders; expired, done-for-day and stopped ones are finished without a
+// full fill.
+func orderStatus(s string) domain.OrderStatus {
+	switch s {
+	case "1":
+		return domain.OrderStatusPartialFill
+	case "2":
+		return domain.OrderStatusFilled
+	case "3", "4", "C":
+		return domain.OrderStatusCancelled
+	case "8":
+		return domain.OrderStatusRejected
+	case "A":
+		return domain.OrderStatusSubmitted
+	default: // 0 New, 5 Replaced, 6 Pending Cancel, E Pending Replace, ...
+		return domain.OrderStatusAcknowledged
+	}
+}
+
+func fixSide(s domain.Side) string {
+	if s == domain.SideSell {
+		return sideSell
+	}
+	return sideBuy
+}
+
+// setOrderType writes OrdType, Price, StopPx, TimeInForce and ExecInst.
+// Post-only is ExecInst 6, "participate don't initiate".
+func setOrderType(m *Message, t domain.OrderType, price, stop decimal.Decimal, tif domain.TimeInForce, postOnly bool) error {
+	switch t {
+	case domain.OrderTypeMarket:
+		m.set(tagOrdType, "1")
+	case domain.OrderTypeLimit:
+		m.set(tagOrdType, "2").set(tagPrice, price.String())
+	case domain.OrderTypeStopMarket:
+		m.set(tagOrdType, "3").set(tagStopPx, stop.String())
+	case domain.OrderTypeStopLimit:
+		m.set(tagOrdType, "4").set(tagPrice, price.String()).set(tagStopPx, stop.String())
+	default:
+		return fmt.Errorf("fix order type %q: %w", t, gateway.ErrOrderTypeUnsupported)
+	}
+	switch tif {
+	case "":
+	case domain.TimeInForceGTC:
+		m.set(tagTimeInForce, "1")
+	case domain.TimeInForceIOC:
+		m.set(tagTimeInForce, "3")
+	case domain.TimeInForceFOK:
+		m.set(tagTimeInForce, "4")
+	default:
+		return fmt.Errorf("fix time in force %q: %w", tif, gateway.ErrTimeInForceUnsupported)
+	}
+	if postOnly {
+		m.set(tagExecInst, "6")
+	}
+	return nil
+}
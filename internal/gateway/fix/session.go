@@ -0,0 +1,323 @@
+package fix
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// errNotLoggedOn is returned when sending on a session that is down.
+var errNotLoggedOn = errors.New("fix session not logged on")
+
+// sessionConfig identifies one FIX session with the counterparty.
+type sessionConfig struct {
+	name      string // "market data" or "order entry", for logs
+	addr      string
+	sender    string
+	target    string
+	username  string
+	password  string
+	heartbeat time.Duration
+	tls       bool
+}
+
+// session is a FIX 4.4 initiator session over one TCP connection. Every
+// logon resets sequence numbers (ResetSeqNumFlag=Y) and nothing is stored
+// for resend: a ResendRequest is answered with a SequenceReset, so orders
+// are never replayed late. run reconnects until its context ends.
+type session struct {
+	cfg    sessionConfig
+	logger *slog.Logger
+
+	mu     sync.Mutex // guards conn, outSeq and writes
+	conn   net.Conn
+	outSeq int
+	inSeq  int // next expected incoming MsgSeqNum; read loop only
+
+	state gateway.WSState
+
+	// handle receives every application message. onLogon runs after each
+	// reconnect, before handle sees any message, to restore subscriptions.
+	handle  func(m *Message)
+	onLogon func()
+
+	reconnectBase time.Duration
+	reconnectMax  time.Duration
+	logonTimeout  time.Duration
+}
+
+func newSession(cfg sessionConfig, logger *slog.Logger) *session {
+	return &session{
+		cfg:           cfg,
+		logger:        logger.With("fix_session", cfg.name, "target", cfg.target),
+		reconnectBase: 100 * time.Millisecond,
+		reconnectMax:  30 * time.Second,
+		logonTimeout:  10 * time.Second,
+	}
+}
+
+// connect dials the counterparty and logs on. On success the connection is
+// ready for serve.
+func (s *session) connect(ctx context.Context) (*bufio.Reader, error) {
+	d := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	if s.cfg.tls {
+		host, _, _ := net.SplitHostPort(s.cfg.addr)
+		td := &tls.Dialer{NetDialer: d, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		conn, err = td.DialContext(ctx, "tcp", s.cfg.addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", s.cfg.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fix connect to %s: %w", s.cfg.addr, err)
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.outSeq = 0
+	s.mu.Unlock()
+
+	logon := newMessage(msgLogon).
+		set(tagEncryptMethod, "0").
+		set(tagHeartBtInt, strconv.Itoa(int(s.cfg.heartbeat/time.Second))).
+		set(tagResetSeqNumFlag, "Y")
+	if s.cfg.username != "" {
+		logon.set(tagUsername, s.cfg.username).set(tagPassword, s.cfg.password)
+	}
+	if _, err := s.write(conn, logon, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("fix logon: %w", err)
+	}
+
+	r := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(s.logonTimeout))
+	reply, err := s.read(r)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("fix logon: %w", err)
+	}
+	if reply.msgType() != msgLogon {
+		conn.Close()
+		return nil, fmt.Errorf("fix logon rejected: %s", reply.get(tagText))
+	}
+	s.inSeq = reply.seqNum() + 1
+	s.state.SetConnected(true)
+	s.state.Touch()
+	s.logger.Info("fix session logged on", "addr", s.cfg.addr)
+	return r, nil
+}
+
+// send writes m with the next sequence number and returns that number.
+func (s *session) send(m *Message) (int, error) {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil || !s.state.Connected() {
+		return 0, errNotLoggedOn
+	}
+	return s.write(conn, m, nil)
+}
+
+// write sends m as the next message on conn. stamp, if set, is called with
+// the message's sequence number before it is encoded.
+func (s *session) write(conn net.Conn, m *Message, stamp func(seq int)) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outSeq++
+	if stamp != nil {
+		stamp(s.outSeq)
+	}
+	raw := m.encode([]field{
+		{tagSenderCompID, s.cfg.sender},
+		{tagTargetCompID, s.cfg.target},
+		{tagMsgSeqNum, strconv.Itoa(s.outSeq)},
+		{tagSendingTime, utcTimestamp(time.Now())},
+	})
+	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write(raw); err != nil {
+		return 0, err
+	}
+	return s.outSeq, nil
+}
+
+func (s *session) read(r *bufio.Reader) (*Message, error) {
+	raw, err := readMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseMessage(raw)
+}
+
+// run keeps the session logged on until ctx ends. r is the reader of the
+// connection connect opened; onLogon runs for each later logon.
+func (s *session) run(ctx context.Context, r *bufio.Reader) {
+	delay := s.reconnectBase
+	for first := true; ; first = false {
+		if r != nil {
+			delay = s.reconnectBase
+			if !first && s.onLogon != nil {
+				s.onLogon()
+			}
+			err := s.serve(ctx, r)
+			s.state.SetConnected(false)
+			s.closeConn()
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("fix session dropped", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		var err error
+		if r, err = s.connect(ctx); err != nil {
+			s.logger.Warn("fix reconnect attempt failed", "error", err)
+			delay = min(delay*2, s.reconnectMax)
+		}
+	}
+}
+
+// serve reads messages until the connection fails, handling the session
+// layer itself and passing application messages on.
+func (s *session) serve(ctx context.Context, r *bufio.Reader) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.heartbeatLoop(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.logout("shutting down")
+			s.closeConn()
+		case <-stop:
+		}
+	}()
+
+	for {
+		m, err := s.read(r)
+		if err != nil {
+			return err
+		}
+		s.state.Touch()
+
+		seq := m.seqNum()
+		switch {
+		case m.msgType() == msgSequenceReset && m.get(tagGapFillFlag) != "Y":
+			// Reset mode ignores MsgSeqNum.
+		case seq > s.inSeq:
+			s.logger.Warn("fix sequence gap, requesting resend", "expected", s.inSeq, "received", seq)
+			s.send(newMessage(msgResendRequest).
+				set(tagBeginSeqNo, strconv.Itoa(s.inSeq)).
+				set(tagEndSeqNo, "0"))
+		case seq < s.inSeq:
+			if m.get(tagPossDupFlag) != "Y" {
+				s.logout("MsgSeqNum too low")
+				return fmt.Errorf("MsgSeqNum %d below expected %d", seq, s.inSeq)
+			}
+			// A resent duplicate. Execution reports carry cumulative
+			// quantities, so handling one twice is harmless.
+		}
+		if seq >= s.inSeq {
+			s.inSeq = seq + 1
+		}
+
+		switch m.msgType() {
+		case msgHeartbeat:
+		case msgTestRequest:
+			s.send(newMessage(msgHeartbeat).set(tagTestReqID, m.get(tagTestReqID)))
+		case msgResendRequest:
+			// Nothing is stored for resend; skip the counterparty past
+			// everything sent so far.
+			s.sendReset()
+		case msgSequenceReset:
+			if n, err := strconv.Atoi(m.get(tagNewSeqNo)); err == nil && n > s.inSeq {
+				s.inSeq = n
+			}
+		case msgLogout:
+			s.logout("")
+			return fmt.Errorf("logged out by counterparty: %s", m.get(tagText))
+		case msgLogon:
+		default:
+			s.handle(m)
+		}
+	}
+}
+
+// sendReset sends a SequenceReset in reset mode whose NewSeqNo follows the
+// reset itself.
+func (s *session) sendReset() {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	reset := newMessage(msgSequenceReset)
+	s.write(conn, reset, func(seq int) { reset.set(tagNewSeqNo, strconv.Itoa(seq+1)) })
+}
+
+// heartbeatLoop sends a Heartbeat every interval, and a TestRequest when
+// nothing has arrived for longer than that. A counterparty silent for two
+// intervals after a TestRequest is treated as gone.
+func (s *session) heartbeatLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.cfg.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		silent := time.Since(s.state.LastMessage())
+		switch {
+		case silent > 2*s.cfg.heartbeat+s.cfg.heartbeat/5:
+			s.logger.Warn("fix counterparty silent, dropping connection", "silent_for", silent)
+			s.closeConn()
+			return
+		case silent > s.cfg.heartbeat+s.cfg.heartbeat/5:
+			s.send(newMessage(msgTestRequest).set(tagTestReqID, strconv.FormatInt(time.Now().UnixMilli(), 10)))
+		default:
+			s.send(newMessage(msgHeartbeat))
+		}
+	}
+}
+
+// logout sends a Logout; the connection is closed by the read loop ending.
+func (s *session) logout(text string) {
+	m := newMessage(msgLogout)
+	if text != "" {
+		m.set(tagText, text)
+	}
+	s.send(m)
+}
+
+func (s *session) closeConn() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// close logs out and closes the connection.
+func (s *session) close() error {
+	if s.state.Connected() {
+		s.logout("")
+	}
+	s.state.SetConnected(false)
+	s.closeConn()
+	return nil
+}
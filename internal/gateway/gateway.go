@@ -2,10 +2,64 @@ package gateway
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
 )
 
+// ErrOrderUpdatesUnsupported is returned by SubscribeOrderUpdates on venues
+// without a private order stream; fills there are only seen via REST.
+var ErrOrderUpdatesUnsupported = errors.New("order update stream not supported")
+
+// ErrTimeInForceUnsupported is returned by PlaceOrder when the venue cannot
+// honour the requested TimeInForce or PostOnly flag.
+var ErrTimeInForceUnsupported = errors.New("time-in-force not supported")
+
+// ErrOrderTypeUnsupported is returned by PlaceOrder for order types the
+// gateway does not implement, such as stop orders on some venues.
+var ErrOrderTypeUnsupported = errors.New("order type not supported")
+
+// ErrAmendUnsupported is returned by AmendOrder on venues where the gateway
+// cannot change a resting order; callers fall back to cancel and resubmit.
+var ErrAmendUnsupported = errors.New("order amend not supported")
+
+// ErrTransfersUnsupported is returned by Withdraw, GetDepositAddress and
+// GetTransferStatus on venues whose gateway has no wallet integration.
+var ErrTransfersUnsupported = errors.New("wallet transfers not supported")
+
+// ErrHistoryUnsupported is returned by MarketHistoryProvider methods for a
+// kind of history, symbol or kline interval the venue does not serve.
+var ErrHistoryUnsupported = errors.New("market history not supported")
+
+// ErrBookSnapshotUnsupported is returned by GetOrderBookSnapshot on wrappers
+// whose underlying venue has no REST depth endpoint.
+var ErrBookSnapshotUnsupported = errors.New("order book snapshot not supported")
+
+// ErrOrderStatusUnsupported is returned by GetOrderStatus on wrappers whose
+// underlying gateway cannot look up an order.
+var ErrOrderStatusUnsupported = errors.New("order status lookup not supported")
+
+// ErrClientOrderLookupUnsupported is returned by GetOrderByClientID on
+// wrappers whose underlying gateway cannot look orders up by client ID.
+var ErrClientOrderLookupUnsupported = errors.New("client order ID lookup not supported")
+
+// ErrInstrumentsUnsupported is returned by GetInstruments on venues with no
+// way to query trading rules; orders there are sent unrounded.
+var ErrInstrumentsUnsupported = errors.New("instrument metadata not supported")
+
+// ErrCancelOnDisconnectUnsupported is returned by ArmCancelOnDisconnect on
+// wrappers whose underlying gateway has no venue-side dead-man switch.
+var ErrCancelOnDisconnectUnsupported = errors.New("cancel on disconnect not supported")
+
+// ErrSymbolUnavailable is matched by venue rejections that mean the symbol
+// cannot be traded at all, because the venue does not know it or has
+// suspended or delisted it: VenueErrors of category ErrorSymbolUnavailable.
+// Sending the order again will not help.
+var ErrSymbolUnavailable = errors.New("symbol unavailable")
+
 type VenueGateway interface {
 	SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error)
 	SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error)
@@ -13,14 +67,101 @@ type VenueGateway interface {
 
 	PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error)
 	CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error)
+	AmendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error)
+	PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []PlaceResult
+	CancelOrders(ctx context.Context, orderIDs []string) []CancelResult
 	GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error)
+	SubscribeOrderUpdates(ctx context.Context) (<-chan domain.OrderUpdate, error)
 
 	GetBalances(ctx context.Context) (map[string]domain.Balance, error)
 	GetPositions(ctx context.Context) ([]domain.Position, error)
 	GetFeeTier(ctx context.Context) (*domain.FeeTier, error)
+	// GetInstruments returns the tick size, step size, minimums and
+	// contract multiplier of the venue's mapped symbols.
+	GetInstruments(ctx context.Context) ([]domain.Instrument, error)
+	// GetRateLimitStatus returns the request budget left per endpoint
+	// category. Gateways without venue limits return an empty slice.
+	GetRateLimitStatus(ctx context.Context) ([]domain.RateLimitStatus, error)
+
+	Withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error)
+	GetDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error)
+	GetTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error)
 
 	Connect(ctx context.Context) error
 	Close() error
+	// Health reports WebSocket connectivity, when the last message arrived
+	// and whether the REST API answers a public request.
+	Health(ctx context.Context) domain.VenueHealth
 
 	Name() string
 }
+
+// AccountHistoryProvider is implemented by gateways that can list past
+// account activity (fills, transfers, funding payments) for a time range.
+// It is optional; callers type-assert a VenueGateway to find out.
+type AccountHistoryProvider interface {
+	GetAccountActivity(ctx context.Context, since, until time.Time) ([]domain.AccountActivity, error)
+}
+
+// MarketHistoryProvider is implemented by gateways that can list a symbol's
+// past klines, public trades and funding rates over REST. Each call returns
+// one venue page, in time order, of what lies in [from, to); callers page on
+// from past the last item returned, and an empty page means nothing is left
+// in the range. A kind of history the venue does not serve fails with
+// ErrHistoryUnsupported. It is optional; callers
+// type-assert a VenueGateway to find out.
+type MarketHistoryProvider interface {
+	GetKlines(ctx context.Context, symbol string, interval time.Duration, from, to time.Time) ([]domain.Kline, error)
+	GetHistoricalTrades(ctx context.Context, symbol string, from, to time.Time) ([]domain.Trade, error)
+	GetFundingHistory(ctx context.Context, symbol string, from, to time.Time) ([]domain.FundingRate, error)
+}
+
+// OrderBookSnapshotProvider is implemented by gateways that can fetch an order
+// book over REST. The market data service polls it while a venue's stream is
+// down. It is optional; callers type-assert a VenueGateway to find out.
+type OrderBookSnapshotProvider interface {
+	GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error)
+}
+
+// OrderStatusProvider is implemented by gateways that can look up one order
+// by the venue ID PlaceOrder returned. The order manager uses it to confirm a
+// cancel before marking the order cancelled. It is optional; callers
+// type-assert a VenueGateway to find out.
+type OrderStatusProvider interface {
+	GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error)
+}
+
+// ClientOrderLookup is implemented by gateways that can look up one order by
+// the client order ID, the request's IdempotencyKey, it was placed with. The
+// order manager uses it to adopt the live order behind a duplicate client
+// order ID rejection. It is optional; callers type-assert a VenueGateway to
+// find out.
+type ClientOrderLookup interface {
+	GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*domain.OrderUpdate, error)
+}
+
+// CancelOnDisconnectArmer is implemented by gateways whose venue can cancel
+// all of the account's open orders by itself when it stops hearing from the
+// trader. ArmCancelOnDisconnect (re)starts that timer with the given timeout;
+// callers re-arm well before it runs out. It is optional; callers
+// type-assert a VenueGateway to find out.
+type CancelOnDisconnectArmer interface {
+	ArmCancelOnDisconnect(ctx context.Context, timeout time.Duration) error
+}
+
+// APIErrorReporter is implemented by gateways that can report failed REST
+// attempts, including ones a retry later recovered. Set the observer before
+// Connect.
+type APIErrorReporter interface {
+	SetAPIErrorObserver(fn APIErrorObserver)
+}
+
+// WSReconnectObserver is told each time a venue's WebSocket reconnects. The
+// gateway has already restored its subscriptions when it is called.
+type WSReconnectObserver func(venue string)
+
+// WSReconnectReporter is implemented by gateways that report WebSocket
+// reconnects. Set the observer before Connect.
+type WSReconnectReporter interface {
+	SetWSReconnectObserver(fn WSReconnectObserver)
+}
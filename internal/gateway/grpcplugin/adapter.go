@@ -0,0 +1,392 @@
+package grpcplugin
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// errStreamEnded is returned when a plugin ends a subscription without an
+// error before confirming it.
+var errStreamEnded = errors.New("plugin ended the stream")
+
+// Config describes how to reach a plugin process.
+type Config struct {
+	// Venue is the name the gateway reports and stamps on the plugin's data.
+	Venue string
+	Addr  string // host:port
+	TLS   bool
+}
+
+// Gateway implements the VenueGateway interface by forwarding every call to
+// a plugin process over gRPC. The plugin owns the venue connections; the
+// gateway only keeps its subscription streams open, reopening them with
+// backoff if the plugin goes away.
+type Gateway struct {
+	cfg    Config
+	conn   *grpc.ClientConn
+	logger *slog.Logger
+}
+
+// New creates a plugin gateway. The plugin is not contacted until Connect.
+func New(cfg Config, logger *slog.Logger) (*Gateway, error) {
+	switch {
+	case cfg.Venue == "":
+		return nil, errors.New("grpc plugin: venue name is required")
+	case cfg.Addr == "":
+		return nil, errors.New("grpc plugin: address is required")
+	}
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(cfg.Addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("grpc plugin %s: %w", cfg.Venue, err)
+	}
+	return &Gateway{cfg: cfg, conn: conn, logger: logger.With("venue", cfg.Venue)}, nil
+}
+
+func (g *Gateway) Name() string { return g.cfg.Venue }
+
+// Connect asks the plugin to connect to its venue. A plugin that is already
+// connected returns at once.
+func (g *Gateway) Connect(ctx context.Context) error {
+	return g.call(ctx, methodConnect, &empty{}, &empty{}, nil)
+}
+
+// Close drops the connection to the plugin; the plugin itself keeps running.
+func (g *Gateway) Close() error {
+	return g.conn.Close()
+}
+
+// Health returns the plugin's own report of its venue connections. A plugin
+// that does not answer is reported as an unreachable API.
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	var h domain.VenueHealth
+	if err := g.call(ctx, methodHealth, &empty{}, &h, nil); err != nil {
+		h = domain.VenueHealth{RESTError: err.Error(), CheckedAt: time.Now()}
+	}
+	h.Venue = g.cfg.Venue
+	return h
+}
+
+func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
+	return subscribe(g, ctx, methodSubscribeOrderBook, &symbolRequest{Symbol: symbol}, nil,
+		func(d *domain.OrderBookDelta) { d.Venue = g.cfg.Venue })
+}
+
+func (g *Gateway) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
+	return subscribe(g, ctx, methodSubscribeTrades, &symbolRequest{Symbol: symbol}, nil,
+		func(t *domain.Trade) { t.Venue = g.cfg.Venue })
+}
+
+func (g *Gateway) SubscribeFunding(ctx context.Context, symbol string) (<-chan domain.FundingRate, error) {
+	return subscribe(g, ctx, methodSubscribeFunding, &symbolRequest{Symbol: symbol}, nil,
+		func(f *domain.FundingRate) { f.Venue = g.cfg.Venue })
+}
+
+func (g *Gateway) SubscribeOrderUpdates(ctx context.Context) (<-chan domain.OrderUpdate, error) {
+	return subscribe(g, ctx, methodSubscribeOrderUpdates, &empty{}, gateway.ErrOrderUpdatesUnsupported,
+		func(u *domain.OrderUpdate) { u.Venue = g.cfg.Venue })
+}
+
+func (g *Gateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	var ack domain.OrderAck
+	if err := g.call(ctx, methodPlaceOrder, &req, &ack, nil); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	var ack domain.CancelAck
+	if err := g.call(ctx, methodCancelOrder, &orderIDRequest{OrderID: orderID}, &ack, nil); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+func (g *Gateway) AmendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	var ack domain.AmendAck
+	req := &amendRequest{OrderID: orderID, NewPrice: newPrice, NewSize: newSize}
+	if err := g.call(ctx, methodAmendOrder, req, &ack, gateway.ErrAmendUnsupported); err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+// PlaceOrders sends the batch in one call. If the call itself fails, every
+// order gets its error.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	var resp placeOrdersResponse
+	err := g.call(ctx, methodPlaceOrders, &placeOrdersRequest{Orders: reqs}, &resp, nil)
+	if err == nil && len(resp.Results) != len(reqs) {
+		err = fmt.Errorf("grpc plugin %s: %d results for %d orders", g.cfg.Venue, len(resp.Results), len(reqs))
+	}
+	results := make([]gateway.PlaceResult, len(reqs))
+	for i := range results {
+		switch {
+		case err != nil:
+			results[i].Err = err
+		case resp.Results[i].Error != "":
+			results[i].Err = fromText(resp.Results[i].Error)
+		default:
+			results[i].Ack = resp.Results[i].Ack
+		}
+	}
+	return results
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	var resp cancelOrdersResponse
+	err := g.call(ctx, methodCancelOrders, &cancelOrdersRequest{OrderIDs: orderIDs}, &resp, nil)
+	if err == nil && len(resp.Results) != len(orderIDs) {
+		err = fmt.Errorf("grpc plugin %s: %d results for %d orders", g.cfg.Venue, len(resp.Results), len(orderIDs))
+	}
+	results := make([]gateway.CancelResult, len(orderIDs))
+	for i := range results {
+		switch {
+		case err != nil:
+			results[i].Err = err
+		case resp.Results[i].Error != "":
+			results[i].Err = fromText(resp.Results[i].Error)
+		default:
+			results[i].Ack = resp.Results[i].Ack
+		}
+	}
+	return results
+}
+
+func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	var resp ordersResponse
+	if err := g.call(ctx, methodGetOpenOrders, &symbolRequest{Symbol: symbol}, &resp, nil); err != nil {
+		return nil, err
+	}
+	for i := range resp.Orders {
+		resp.Orders[i].Venue = g.cfg.Venue
+	}
+	return resp.Orders, nil
+}
+
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	var u domain.OrderUpdate
+	if err := g.call(ctx, methodGetOrderStatus, &orderIDRequest{OrderID: orderID}, &u, gateway.ErrOrderStatusUnsupported); err != nil {
+		return nil, err
+	}
+	u.Venue = g.cfg.Venue
+	return &u, nil
+}
+
+// GetOrderBookSnapshot implements gateway.OrderBookSnapshotProvider.
+func (g *Gateway) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	var ob domain.OrderBookSnapshot
+	if err := g.call(ctx, methodGetOrderBookSnapshot, &symbolRequest{Symbol: symbol}, &ob, gateway.ErrBookSnapshotUnsupported); err != nil {
+		return nil, err
+	}
+	ob.Venue = g.cfg.Venue
+	return &ob, nil
+}
+
+func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	var resp balancesResponse
+	if err := g.call(ctx, methodGetBalances, &empty{}, &resp, nil); err != nil {
+		return nil, err
+	}
+	for asset, b := range resp.Balances {
+		b.Venue = g.cfg.Venue
+		resp.Balances[asset] = b
+	}
+	return resp.Balances, nil
+}
+
+func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	var resp positionsResponse
+	if err := g.call(ctx, methodGetPositions, &empty{}, &resp, nil); err != nil {
+		return nil, err
+	}
+	for i := range resp.Positions {
+		resp.Positions[i].Venue = g.cfg.Venue
+	}
+	return resp.Positions, nil
+}
+
+func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	var tier domain.FeeTier
+	if err := g.call(ctx, methodGetFeeTier, &empty{}, &tier, nil); err != nil {
+		return nil, err
+	}
+	tier.Venue = g.cfg.Venue
+	return &tier, nil
+}
+
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	var resp instrumentsResponse
+	if err := g.call(ctx, methodGetInstruments, &empty{}, &resp, gateway.ErrInstrumentsUnsupported); err != nil {
+		return nil, err
+	}
+	for i := range resp.Instruments {
+		resp.Instruments[i].Venue = g.cfg.Venue
+	}
+	return resp.Instruments, nil
+}
+
+func (g *Gateway) GetRateLimitStatus(ctx context.Context) ([]domain.RateLimitStatus, error) {
+	var resp rateLimitResponse
+	if err := g.call(ctx, methodGetRateLimitStatus, &empty{}, &resp, nil); err != nil {
+		return nil, err
+	}
+	return resp.Statuses, nil
+}
+
+func (g *Gateway) Withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	var t domain.Transfer
+	if err := g.call(ctx, methodWithdraw, &req, &t, gateway.ErrTransfersUnsupported); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (g *Gateway) GetDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	var addr domain.DepositAddress
+	req := &depositAddressRequest{Asset: asset, Network: network}
+	if err := g.call(ctx, methodGetDepositAddress, req, &addr, gateway.ErrTransfersUnsupported); err != nil {
+		return nil, err
+	}
+	return &addr, nil
+}
+
+func (g *Gateway) GetTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error) {
+	var t domain.Transfer
+	if err := g.call(ctx, methodGetTransferStatus, &transferIDRequest{TransferID: transferID}, &t, gateway.ErrTransfersUnsupported); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// call invokes a unary method. notImplemented is the gateway error to
+// return if the plugin does not implement the method at all.
+func (g *Gateway) call(ctx context.Context, method string, req, resp any, notImplemented error) error {
+	return g.fromStatus(method, g.conn.Invoke(ctx, fullMethod(method), req, resp), notImplemented)
+}
+
+// fromStatus turns a plugin status back into the error a native gateway
+// would have returned.
+func (g *Gateway) fromStatus(method string, err error, notImplemented error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("grpc plugin %s %s: %w", g.cfg.Venue, method, err)
+	}
+	switch s.Code() {
+	case codes.Unimplemented:
+		for _, u := range unsupported {
+			if s.Message() == u.Error() {
+				return u
+			}
+		}
+		if notImplemented != nil {
+			return notImplemented
+		}
+	case codes.Canceled:
+		return context.Canceled
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	}
+	return fmt.Errorf("grpc plugin %s %s: %w", g.cfg.Venue, method, err)
+}
+
+// subscribe opens a server stream and forwards its messages to the returned
+// channel, after stamp sets the venue name. The first open must succeed;
+// after that the stream is reopened with backoff whenever it breaks, until
+// ctx ends and the channel is closed.
+func subscribe[T any](g *Gateway, ctx context.Context, method string, req any, notImplemented error, stamp func(*T)) (<-chan T, error) {
+	open := func() (grpc.ClientStream, error) {
+		desc := &grpc.StreamDesc{StreamName: method, ServerStreams: true}
+		cs, err := g.conn.NewStream(ctx, desc, fullMethod(method))
+		if err != nil {
+			return nil, err
+		}
+		if err := cs.SendMsg(req); err != nil {
+			return nil, err
+		}
+		if err := cs.CloseSend(); err != nil {
+			return nil, err
+		}
+		// The plugin sends the header once it has subscribed; a stream that
+		// ends without one failed, and RecvMsg returns why.
+		md, err := cs.Header()
+		if err != nil {
+			return nil, err
+		}
+		if md == nil {
+			if err := cs.RecvMsg(new(T)); err != io.EOF {
+				return nil, err
+			}
+			return nil, errStreamEnded
+		}
+		return cs, nil
+	}
+
+	cs, err := open()
+	if err != nil {
+		return nil, g.fromStatus(method, err, notImplemented)
+	}
+
+	ch := make(chan T, 256)
+	go func() {
+		defer close(ch)
+		backoff := time.Second
+		for {
+			for {
+				var v T
+				if err = cs.RecvMsg(&v); err != nil {
+					break
+				}
+				backoff = time.Second
+				stamp(&v)
+				select {
+				case ch <- v:
+				default:
+					g.logger.Warn("grpc plugin channel full, dropping update", "method", method)
+				}
+			}
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				g.logger.Warn("grpc plugin stream broke, reopening", "method", method, "error", err, "backoff", backoff)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, 30*time.Second)
+				if cs, err = open(); err == nil {
+					break
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
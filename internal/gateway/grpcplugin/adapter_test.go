@@ -0,0 +1,177 @@
+package grpcplugin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// fakeGateway implements only the methods exercised here; anything else
+// panics on the nil embedded interface.
+type fakeGateway struct {
+	gateway.VenueGateway
+	connects int
+	books    chan domain.OrderBookDelta
+}
+
+func (f *fakeGateway) Connect(context.Context) error {
+	f.connects++
+	return nil
+}
+
+func (f *fakeGateway) PlaceOrder(_ context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	if req.TimeInForce == domain.TimeInForceFOK {
+		return nil, gateway.ErrTimeInForceUnsupported
+	}
+	return &domain.OrderAck{InternalID: req.InternalID, VenueID: "v-" + req.Size.String(), Status: domain.OrderStatusAcknowledged}, nil
+}
+
+func (f *fakeGateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, f.PlaceOrder)
+}
+
+func (f *fakeGateway) AmendOrder(context.Context, string, decimal.Decimal, decimal.Decimal) (*domain.AmendAck, error) {
+	return nil, gateway.ErrAmendUnsupported
+}
+
+func (f *fakeGateway) GetBalances(context.Context) (map[string]domain.Balance, error) {
+	return map[string]domain.Balance{"USDT": {Venue: "inner", Asset: "USDT", Free: decimal.RequireFromString("1000.123456789")}}, nil
+}
+
+func (f *fakeGateway) SubscribeOrderBook(_ context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
+	return f.books, nil
+}
+
+func (f *fakeGateway) SubscribeOrderUpdates(context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
+
+func newTestPlugin(t *testing.T) (*fakeGateway, *Gateway) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	fake := &fakeGateway{books: make(chan domain.OrderBookDelta, 4)}
+	srv := grpc.NewServer()
+	Register(context.Background(), srv, fake)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	gw, err := New(Config{Venue: "exotic", Addr: ln.Addr().String()}, logger)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	t.Cleanup(func() { gw.Close() })
+	return fake, gw
+}
+
+func TestPluginForwardsCalls(t *testing.T) {
+	fake, gw := newTestPlugin(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := gw.Connect(ctx); err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+	}
+	if fake.connects != 1 {
+		t.Errorf("expected the plugin to connect its venue once, got %d", fake.connects)
+	}
+
+	id := uuid.New()
+	ack, err := gw.PlaceOrder(ctx, domain.OrderRequest{InternalID: id, Symbol: "BTC/USDT", Size: decimal.RequireFromString("0.015")})
+	if err != nil {
+		t.Fatalf("place: %v", err)
+	}
+	if ack.InternalID != id || ack.VenueID != "v-0.015" || ack.Status != domain.OrderStatusAcknowledged {
+		t.Errorf("unexpected ack %+v", ack)
+	}
+
+	balances, err := gw.GetBalances(ctx)
+	if err != nil {
+		t.Fatalf("balances: %v", err)
+	}
+	usdt := balances["USDT"]
+	if usdt.Venue != "exotic" || !usdt.Free.Equal(decimal.RequireFromString("1000.123456789")) {
+		t.Errorf("expected the balance stamped with the venue name and exact, got %+v", usdt)
+	}
+}
+
+func TestPluginMapsUnsupportedErrors(t *testing.T) {
+	_, gw := newTestPlugin(t)
+	ctx := context.Background()
+
+	if _, err := gw.AmendOrder(ctx, "1", decimal.NewFromInt(1), decimal.NewFromInt(1)); !errors.Is(err, gateway.ErrAmendUnsupported) {
+		t.Errorf("expected ErrAmendUnsupported, got %v", err)
+	}
+	if _, err := gw.PlaceOrder(ctx, domain.OrderRequest{TimeInForce: domain.TimeInForceFOK}); !errors.Is(err, gateway.ErrTimeInForceUnsupported) {
+		t.Errorf("expected ErrTimeInForceUnsupported, got %v", err)
+	}
+	// The fake has no order status lookup.
+	if _, err := gw.GetOrderStatus(ctx, "1"); !errors.Is(err, gateway.ErrOrderStatusUnsupported) {
+		t.Errorf("expected ErrOrderStatusUnsupported, got %v", err)
+	}
+	if _, err := gw.SubscribeOrderUpdates(ctx); !errors.Is(err, gateway.ErrOrderUpdatesUnsupported) {
+		t.Errorf("expected ErrOrderUpdatesUnsupported, got %v", err)
+	}
+
+	results := gw.PlaceOrders(ctx, []domain.OrderRequest{
+		{Size: decimal.NewFromInt(1)},
+		{Size: decimal.NewFromInt(2), TimeInForce: domain.TimeInForceFOK},
+	})
+	if len(results) != 2 || results[0].Err != nil || results[0].Ack.VenueID != "v-1" {
+		t.Fatalf("expected the first order placed, got %+v", results)
+	}
+	if !errors.Is(results[1].Err, gateway.ErrTimeInForceUnsupported) {
+		t.Errorf("expected the second order's error mapped back, got %v", results[1].Err)
+	}
+}
+
+func TestPluginStreamsOrderBook(t *testing.T) {
+	fake, gw := newTestPlugin(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := gw.SubscribeOrderBook(ctx, "BTC/USDT")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	fake.books <- domain.OrderBookDelta{
+		Venue:    "inner",
+		Symbol:   "BTC/USDT",
+		Bids:     []domain.PriceLevel{{Price: decimal.NewFromInt(50000), Size: decimal.RequireFromString("0.5")}},
+		Sequence: 7,
+	}
+
+	select {
+	case d := <-ch:
+		if d.Venue != "exotic" || d.Sequence != 7 || len(d.Bids) != 1 || !d.Bids[0].Size.Equal(decimal.RequireFromString("0.5")) {
+			t.Errorf("unexpected delta %+v", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the delta")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("expected no more deltas")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the channel closed once the context ended")
+	}
+}
@@ -0,0 +1,212 @@
+package grpcplugin
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// Register serves gw on s as a plugin, for plugins written in Go. ctx bounds
+// gw's venue connections: a Connect call only starts them, so they must
+// outlive it.
+func Register(ctx context.Context, s grpc.ServiceRegistrar, gw gateway.VenueGateway) {
+	p := &server{ctx: ctx, gw: gw}
+	s.RegisterService(serviceDesc(p.methods(), p.streams()), p)
+}
+
+type server struct {
+	ctx context.Context
+	gw  gateway.VenueGateway
+
+	mu        sync.Mutex
+	connected bool
+}
+
+// connect connects gw once; the trader calls Connect again after it
+// restarts, which must not open a second set of venue connections.
+func (p *server) connect() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.connected {
+		return nil
+	}
+	if err := p.gw.Connect(p.ctx); err != nil {
+		return err
+	}
+	p.connected = true
+	return nil
+}
+
+func (p *server) methods() []grpc.MethodDesc {
+	gw := p.gw
+	return []grpc.MethodDesc{
+		unary(methodConnect, func(ctx context.Context, _ *empty) (*empty, error) {
+			return &empty{}, p.connect()
+		}),
+		unary(methodHealth, func(ctx context.Context, _ *empty) (*domain.VenueHealth, error) {
+			h := gw.Health(ctx)
+			return &h, nil
+		}),
+		unary(methodPlaceOrder, func(ctx context.Context, req *domain.OrderRequest) (*domain.OrderAck, error) {
+			return gw.PlaceOrder(ctx, *req)
+		}),
+		unary(methodCancelOrder, func(ctx context.Context, req *orderIDRequest) (*domain.CancelAck, error) {
+			return gw.CancelOrder(ctx, req.OrderID)
+		}),
+		unary(methodAmendOrder, func(ctx context.Context, req *amendRequest) (*domain.AmendAck, error) {
+			return gw.AmendOrder(ctx, req.OrderID, req.NewPrice, req.NewSize)
+		}),
+		unary(methodPlaceOrders, func(ctx context.Context, req *placeOrdersRequest) (*placeOrdersResponse, error) {
+			results := gw.PlaceOrders(ctx, req.Orders)
+			resp := &placeOrdersResponse{Results: make([]batchResult[domain.OrderAck], len(results))}
+			for i, r := range results {
+				resp.Results[i].Ack = r.Ack
+				if r.Err != nil {
+					resp.Results[i].Error = r.Err.Error()
+				}
+			}
+			return resp, nil
+		}),
+		unary(methodCancelOrders, func(ctx context.Context, req *cancelOrdersRequest) (*cancelOrdersResponse, error) {
+			results := gw.CancelOrders(ctx, req.OrderIDs)
+			resp := &cancelOrdersResponse{Results: make([]batchResult[domain.CancelAck], len(results))}
+			for i, r := range results {
+				resp.Results[i].Ack = r.Ack
+				if r.Err != nil {
+					resp.Results[i].Error = r.Err.Error()
+				}
+			}
+			return resp, nil
+		}),
+		unary(methodGetOpenOrders, func(ctx context.Context, req *symbolRequest) (*ordersResponse, error) {
+			orders, err := gw.GetOpenOrders(ctx, req.Symbol)
+			return &ordersResponse{Orders: orders}, err
+		}),
+		unary(methodGetOrderStatus, func(ctx context.Context, req *orderIDRequest) (*domain.OrderUpdate, error) {
+			sp, ok := gw.(gateway.OrderStatusProvider)
+			if !ok {
+				return nil, gateway.ErrOrderStatusUnsupported
+			}
+			return sp.GetOrderStatus(ctx, req.OrderID)
+		}),
+		unary(methodGetOrderBookSnapshot, func(ctx context.Context, req *symbolRequest) (*domain.OrderBookSnapshot, error) {
+			sp, ok := gw.(gateway.OrderBookSnapshotProvider)
+			if !ok {
+				return nil, gateway.ErrBookSnapshotUnsupported
+			}
+			return sp.GetOrderBookSnapshot(ctx, req.Symbol)
+		}),
+		unary(methodGetBalances, func(ctx context.Context, _ *empty) (*balancesResponse, error) {
+			balances, err := gw.GetBalances(ctx)
+			return &balancesResponse{Balances: balances}, err
+		}),
+		unary(methodGetPositions, func(ctx context.Context, _ *empty) (*positionsResponse, error) {
+			positions, err := gw.GetPositions(ctx)
+			return &positionsResponse{Positions: positions}, err
+		}),
+		unary(methodGetFeeTier, func(ctx context.Context, _ *empty) (*domain.FeeTier, error) {
+			return gw.GetFeeTier(ctx)
+		}),
+		unary(methodGetInstruments, func(ctx context.Context, _ *empty) (*instrumentsResponse, error) {
+			instruments, err := gw.GetInstruments(ctx)
+			return &instrumentsResponse{Instruments: instruments}, err
+		}),
+		unary(methodGetRateLimitStatus, func(ctx context.Context, _ *empty) (*rateLimitResponse, error) {
+			statuses, err := gw.GetRateLimitStatus(ctx)
+			return &rateLimitResponse{Statuses: statuses}, err
+		}),
+		unary(methodWithdraw, func(ctx context.Context, req *domain.WithdrawRequest) (*domain.Transfer, error) {
+			return gw.Withdraw(ctx, *req)
+		}),
+		unary(methodGetDepositAddress, func(ctx context.Context, req *depositAddressRequest) (*domain.DepositAddress, error) {
+			return gw.GetDepositAddress(ctx, req.Asset, req.Network)
+		}),
+		unary(methodGetTransferStatus, func(ctx context.Context, req *transferIDRequest) (*domain.Transfer, error) {
+			return gw.GetTransferStatus(ctx, req.TransferID)
+		}),
+	}
+}
+
+func (p *server) streams() []grpc.StreamDesc {
+	gw := p.gw
+	return []grpc.StreamDesc{
+		stream(methodSubscribeOrderBook, func(ctx context.Context, req *symbolRequest) (<-chan domain.OrderBookDelta, error) {
+			return gw.SubscribeOrderBook(ctx, req.Symbol)
+		}),
+		stream(methodSubscribeTrades, func(ctx context.Context, req *symbolRequest) (<-chan domain.Trade, error) {
+			return gw.SubscribeTrades(ctx, req.Symbol)
+		}),
+		stream(methodSubscribeFunding, func(ctx context.Context, req *symbolRequest) (<-chan domain.FundingRate, error) {
+			return gw.SubscribeFunding(ctx, req.Symbol)
+		}),
+		stream(methodSubscribeOrderUpdates, func(ctx context.Context, _ *empty) (<-chan domain.OrderUpdate, error) {
+			return gw.SubscribeOrderUpdates(ctx)
+		}),
+	}
+}
+
+// unary adapts a typed handler to a grpc.MethodDesc.
+func unary[Req, Resp any](name string, call func(context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handle := func(ctx context.Context, req any) (any, error) {
+				resp, err := call(ctx, req.(*Req))
+				if err != nil {
+					return nil, toStatus(err)
+				}
+				return resp, nil
+			}
+			if interceptor == nil {
+				return handle(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: fullMethod(name)}, handle)
+		},
+	}
+}
+
+// stream adapts a subscription to a server-streaming grpc.StreamDesc. The
+// header is sent once the subscription is set up, so the trader learns
+// whether it succeeded before any data arrives.
+func stream[Req, T any](name string, subscribe func(context.Context, *Req) (<-chan T, error)) grpc.StreamDesc {
+	return grpc.StreamDesc{
+		StreamName:    name,
+		ServerStreams: true,
+		Handler: func(_ any, ss grpc.ServerStream) error {
+			req := new(Req)
+			if err := ss.RecvMsg(req); err != nil {
+				return err
+			}
+			ctx := ss.Context()
+			ch, err := subscribe(ctx, req)
+			if err != nil {
+				return toStatus(err)
+			}
+			if err := ss.SendHeader(metadata.MD{}); err != nil {
+				return err
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case v, ok := <-ch:
+					if !ok {
+						return nil
+					}
+					if err := ss.SendMsg(&v); err != nil {
+						return err
+					}
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,195 @@
+// Package grpcplugin runs a VenueGateway in another process. The trader
+// talks to the plugin over the gRPC service described here, so venues with
+// no native adapter (or connectors written in other languages) can be added
+// without rebuilding the trader.
+//
+// Messages are JSON-encoded domain types under the "json" content subtype
+// (application/grpc+json) rather than protobuf, so a plugin needs no
+// generated code: field names are the Go field names, decimals are strings
+// and times are RFC 3339.
+package grpcplugin
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// ServiceName is the fully qualified gRPC service a plugin must serve.
+const ServiceName = "trading.gateway.v1.VenueGateway"
+
+// Method names, one per VenueGateway method plus the optional order status
+// and book snapshot lookups. The Subscribe* methods are server streams.
+const (
+	methodConnect               = "Connect"
+	methodHealth                = "Health"
+	methodSubscribeOrderBook    = "SubscribeOrderBook"
+	methodSubscribeTrades       = "SubscribeTrades"
+	methodSubscribeFunding      = "SubscribeFunding"
+	methodSubscribeOrderUpdates = "SubscribeOrderUpdates"
+	methodPlaceOrder            = "PlaceOrder"
+	methodCancelOrder           = "CancelOrder"
+	methodAmendOrder            = "AmendOrder"
+	methodPlaceOrders           = "PlaceOrders"
+	methodCancelOrders          = "CancelOrders"
+	methodGetOpenOrders         = "GetOpenOrders"
+	methodGetOrderStatus        = "GetOrderStatus"
+	methodGetOrderBookSnapshot  = "GetOrderBookSnapshot"
+	methodGetBalances           = "GetBalances"
+	methodGetPositions          = "GetPositions"
+	methodGetFeeTier            = "GetFeeTier"
+	methodGetInstruments        = "GetInstruments"
+	methodGetRateLimitStatus    = "GetRateLimitStatus"
+	methodWithdraw              = "Withdraw"
+	methodGetDepositAddress     = "GetDepositAddress"
+	methodGetTransferStatus     = "GetTransferStatus"
+)
+
+func fullMethod(method string) string { return "/" + ServiceName + "/" + method }
+
+// codecName is the content subtype both sides use.
+const codecName = "json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return codecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// Request and response messages. Methods that take or return a single
+// domain value use the domain type itself.
+
+type empty struct{}
+
+type symbolRequest struct {
+	Symbol string
+}
+
+type orderIDRequest struct {
+	OrderID string
+}
+
+type amendRequest struct {
+	OrderID  string
+	NewPrice decimal.Decimal
+	NewSize  decimal.Decimal
+}
+
+type placeOrdersRequest struct {
+	Orders []domain.OrderRequest
+}
+
+type cancelOrdersRequest struct {
+	OrderIDs []string
+}
+
+// batchResult is one entry of a PlaceOrders or CancelOrders response:
+// either Ack or Error is set.
+type batchResult[T any] struct {
+	Ack   *T
+	Error string `json:",omitempty"`
+}
+
+type placeOrdersResponse struct {
+	Results []batchResult[domain.OrderAck]
+}
+
+type cancelOrdersResponse struct {
+	Results []batchResult[domain.CancelAck]
+}
+
+type ordersResponse struct {
+	Orders []domain.Order
+}
+
+type balancesResponse struct {
+	Balances map[string]domain.Balance
+}
+
+type positionsResponse struct {
+	Positions []domain.Position
+}
+
+type instrumentsResponse struct {
+	Instruments []domain.Instrument
+}
+
+type rateLimitResponse struct {
+	Statuses []domain.RateLimitStatus
+}
+
+type depositAddressRequest struct {
+	Asset   string
+	Network string
+}
+
+type transferIDRequest struct {
+	TransferID string
+}
+
+// unsupported are the gateway errors that mean "this venue cannot do that".
+// They cross the wire as codes.Unimplemented with the error text as the
+// message, which is how a plugin in another language reports them too.
+var unsupported = []error{
+	gateway.ErrOrderUpdatesUnsupported,
+	gateway.ErrTimeInForceUnsupported,
+	gateway.ErrOrderTypeUnsupported,
+	gateway.ErrAmendUnsupported,
+	gateway.ErrTransfersUnsupported,
+	gateway.ErrBookSnapshotUnsupported,
+	gateway.ErrOrderStatusUnsupported,
+	gateway.ErrInstrumentsUnsupported,
+}
+
+// toStatus converts a gateway error into the status returned to the trader.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, u := range unsupported {
+		if errors.Is(err, u) {
+			return status.Error(codes.Unimplemented, u.Error())
+		}
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Err()
+	}
+	if gateway.Retryable(err) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// fromText maps an error message back to the matching gateway error, for
+// batch results which carry errors as text.
+func fromText(msg string) error {
+	for _, u := range unsupported {
+		if msg == u.Error() {
+			return u
+		}
+	}
+	return errors.New(msg)
+}
+
+// serviceDesc is the hand-written equivalent of a protoc-generated
+// descriptor. Handlers are added by Register.
+func serviceDesc(unary []grpc.MethodDesc, streams []grpc.StreamDesc) *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*any)(nil),
+		Methods:     unary,
+		Streams:     streams,
+	}
+}
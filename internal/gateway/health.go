@@ -0,0 +1,278 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// WSState tracks whether a WebSocket connection is up and when it last
+// delivered a message. The zero value is a connection that was never opened.
+// It is safe for concurrent use.
+type WSState struct {
+	connected   atomic.Bool
+	lastMessage atomic.Int64 // unix nanoseconds, zero until the first message
+}
+
+// SetConnected records the connection going up or down.
+func (s *WSState) SetConnected(up bool) { s.connected.Store(up) }
+
+// Touch records a message arriving now.
+func (s *WSState) Touch() { s.lastMessage.Store(time.Now().UnixNano()) }
+
+// Connected reports whether the connection is up.
+func (s *WSState) Connected() bool { return s.connected.Load() }
+
+// LastMessage returns when the last message arrived, or the zero time.
+func (s *WSState) LastMessage() time.Time {
+	ns := s.lastMessage.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// ProbeREST sends an unauthenticated GET to url and returns nil if the venue
+// answered with a status below 500. Rejections such as 403 still show the API
+// is up; 5xx and transport failures do not.
+func ProbeREST(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 500 {
+		return NewHTTPError(resp, body)
+	}
+	return nil
+}
+
+// ProbeHealth builds a VenueHealth from the given WebSocket states and a REST
+// probe. With several connections, the venue is connected only if all of them
+// are, and the last message is the most recent across them.
+func ProbeHealth(ctx context.Context, venue string, probe func(context.Context) error, states ...*WSState) domain.VenueHealth {
+	h := domain.VenueHealth{Venue: venue, WSConnected: len(states) > 0}
+	for _, s := range states {
+		if !s.Connected() {
+			h.WSConnected = false
+		}
+		if last := s.LastMessage(); last.After(h.LastMessageAt) {
+			h.LastMessageAt = last
+		}
+	}
+	if err := probe(ctx); err != nil {
+		h.RESTError = err.Error()
+	} else {
+		h.RESTReachable = true
+	}
+	h.CheckedAt = time.Now()
+	return h
+}
+
+// HealthStatus is the monitor's verdict on one venue.
+type HealthStatus struct {
+	domain.VenueHealth
+	Healthy bool   `json:"healthy"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// HealthMonitor polls every gateway's Health and keeps the latest verdict per
+// venue. A venue is unhealthy when its WebSocket is down, has been silent for
+// longer than maxMessageAge, or its REST API does not answer.
+type HealthMonitor struct {
+	gateways      map[string]VenueGateway
+	interval      time.Duration
+	timeout       time.Duration
+	maxMessageAge time.Duration
+	onUnhealthy   func(venue, reason string)
+	outageAfter   time.Duration
+	onOutage      func(venue string) error
+	logger        *slog.Logger
+
+	mu       sync.RWMutex
+	statuses map[string]HealthStatus
+	outages  map[string]*outage
+}
+
+// outage tracks a venue from its first failed check until it is healthy and
+// the outage callback, if it was due, has succeeded.
+type outage struct {
+	since   time.Time
+	due     bool
+	handled bool
+}
+
+// NewHealthMonitor creates a monitor that checks gateways every interval.
+func NewHealthMonitor(gateways map[string]VenueGateway, interval, maxMessageAge time.Duration, logger *slog.Logger) *HealthMonitor {
+	return &HealthMonitor{
+		gateways:      gateways,
+		interval:      interval,
+		timeout:       5 * time.Second,
+		maxMessageAge: maxMessageAge,
+		logger:        logger,
+		statuses:      make(map[string]HealthStatus),
+		outages:       make(map[string]*outage),
+	}
+}
+
+// SetUnhealthyCallback sets fn to be called when a venue turns unhealthy. It
+// is not called again until the venue has recovered. Call before Run.
+func (m *HealthMonitor) SetUnhealthyCallback(fn func(venue, reason string)) {
+	m.onUnhealthy = fn
+}
+
+// SetOutageCallback sets fn to be called for a venue that has been unhealthy
+// for at least after. It runs on the first check from then on at which the
+// venue's REST API answers: during the outage if only the WebSocket is down,
+// otherwise once the venue is back. If fn fails it is tried again on the next
+// check. Call before Run.
+func (m *HealthMonitor) SetOutageCallback(after time.Duration, fn func(venue string) error) {
+	m.outageAfter = after
+	m.onOutage = fn
+}
+
+// Run checks all venues at once and then every interval until ctx is
+// cancelled.
+func (m *HealthMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.Check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check polls every venue concurrently and updates its verdict.
+func (m *HealthMonitor) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for venue, gw := range m.gateways {
+		wg.Add(1)
+		go func(venue string, gw VenueGateway) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, m.timeout)
+			h := gw.Health(checkCtx)
+			cancel()
+			h.Venue = venue
+			m.record(m.judge(h))
+		}(venue, gw)
+	}
+	wg.Wait()
+}
+
+func (m *HealthMonitor) judge(h domain.VenueHealth) HealthStatus {
+	status := HealthStatus{VenueHealth: h, Healthy: true}
+	switch {
+	case !h.WSConnected:
+		status.Reason = "websocket disconnected"
+	case m.maxMessageAge > 0 && h.MessageAge(h.CheckedAt) > m.maxMessageAge:
+		status.Reason = fmt.Sprintf("no websocket message for %s", h.MessageAge(h.CheckedAt).Round(time.Second))
+	case !h.RESTReachable:
+		status.Reason = "REST unreachable: " + h.RESTError
+	}
+	status.Healthy = status.Reason == ""
+	return status
+}
+
+func (m *HealthMonitor) record(status HealthStatus) {
+	m.mu.Lock()
+	prev, seen := m.statuses[status.Venue]
+	m.statuses[status.Venue] = status
+	due := m.trackOutage(status)
+	m.mu.Unlock()
+
+	wasHealthy := !seen || prev.Healthy
+	switch {
+	case wasHealthy && !status.Healthy:
+		m.logger.Warn("venue unhealthy", "venue", status.Venue, "reason", status.Reason)
+		if m.onUnhealthy != nil {
+			m.onUnhealthy(status.Venue, status.Reason)
+		}
+	case !wasHealthy && status.Healthy:
+		m.logger.Info("venue healthy again", "venue", status.Venue)
+	}
+
+	if due {
+		if err := m.onOutage(status.Venue); err != nil {
+			m.logger.Error("outage handling failed, retrying on next check",
+				"venue", status.Venue, "error", err)
+			return
+		}
+		m.mu.Lock()
+		if o := m.outages[status.Venue]; o != nil {
+			o.due, o.handled = false, true
+			if m.statuses[status.Venue].Healthy {
+				delete(m.outages, status.Venue)
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+// trackOutage updates how long the venue has been down and reports whether
+// the outage callback should run now. m.mu must be held.
+func (m *HealthMonitor) trackOutage(status HealthStatus) bool {
+	if m.onOutage == nil {
+		return false
+	}
+	o := m.outages[status.Venue]
+	if o == nil {
+		if !status.Healthy {
+			m.outages[status.Venue] = &outage{since: status.CheckedAt}
+		}
+		return false
+	}
+	if !o.handled && status.CheckedAt.Sub(o.since) >= m.outageAfter {
+		o.due = true
+	}
+	if status.Healthy && !o.due {
+		delete(m.outages, status.Venue)
+	}
+	return o.due && status.RESTReachable
+}
+
+// Statuses returns the latest verdict for every venue checked so far, sorted
+// by venue.
+func (m *HealthMonitor) Statuses() []HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]HealthStatus, 0, len(m.statuses))
+	for _, s := range m.statuses {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Venue < out[j].Venue })
+	return out
+}
+
+// Ready reports whether every venue has been checked and found healthy.
+func (m *HealthMonitor) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.statuses) < len(m.gateways) {
+		return false
+	}
+	for _, s := range m.statuses {
+		if !s.Healthy {
+			return false
+		}
+	}
+	return true
+}
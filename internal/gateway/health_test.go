@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+type healthGateway struct {
+	VenueGateway
+	health domain.VenueHealth
+}
+
+func (g *healthGateway) Health(context.Context) domain.VenueHealth {
+	h := g.health
+	h.CheckedAt = time.Now()
+	return h
+}
+
+func TestHealthMonitorAlertsOnceUntilRecovered(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	up := domain.VenueHealth{WSConnected: true, RESTReachable: true, LastMessageAt: time.Now()}
+	kcex := &healthGateway{health: up}
+	okx := &healthGateway{health: up}
+	m := NewHealthMonitor(map[string]VenueGateway{"kcex": kcex, "okx": okx}, time.Second, time.Minute, logger)
+	var alerts []string
+	m.SetUnhealthyCallback(func(venue, reason string) { alerts = append(alerts, venue+": "+reason) })
+
+	if m.Ready() {
+		t.Fatal("expected not ready before the first check")
+	}
+	m.Check(context.Background())
+	if !m.Ready() {
+		t.Fatalf("expected ready, got %+v", m.Statuses())
+	}
+
+	kcex.health.WSConnected = false
+	m.Check(context.Background())
+	m.Check(context.Background())
+	if m.Ready() || len(alerts) != 1 || alerts[0] != "kcex: websocket disconnected" {
+		t.Fatalf("expected one disconnect alert, got ready=%v alerts=%v", m.Ready(), alerts)
+	}
+
+	kcex.health = up
+	m.Check(context.Background())
+	okx.health.LastMessageAt = time.Now().Add(-2 * time.Minute)
+	m.Check(context.Background())
+	if len(alerts) != 2 || alerts[1] != "okx: no websocket message for 2m0s" {
+		t.Errorf("expected a stale stream alert, got %v", alerts)
+	}
+	statuses := m.Statuses()
+	if len(statuses) != 2 || statuses[0].Venue != "kcex" || !statuses[0].Healthy || statuses[1].Healthy {
+		t.Errorf("unexpected statuses: %+v", statuses)
+	}
+}
+
+func TestHealthMonitorOutageCallback(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	up := domain.VenueHealth{WSConnected: true, RESTReachable: true, LastMessageAt: time.Now()}
+	gw := &healthGateway{health: up}
+	m := NewHealthMonitor(map[string]VenueGateway{"okx": gw}, time.Second, 0, logger)
+	var calls int
+	var fail error
+	m.SetOutageCallback(50*time.Millisecond, func(venue string) error {
+		calls++
+		return fail
+	})
+
+	// A blip shorter than the threshold is ignored.
+	gw.health.WSConnected = false
+	m.Check(context.Background())
+	gw.health = up
+	m.Check(context.Background())
+	if calls != 0 {
+		t.Fatalf("expected no call for a short blip, got %d", calls)
+	}
+
+	// A longer outage with REST down waits for REST to come back.
+	gw.health = domain.VenueHealth{}
+	m.Check(context.Background())
+	time.Sleep(60 * time.Millisecond)
+	m.Check(context.Background())
+	if calls != 0 {
+		t.Fatalf("expected no call while REST is unreachable, got %d", calls)
+	}
+
+	// Only the stream is down now: the callback runs, fails, and is retried.
+	gw.health.RESTReachable = true
+	fail = errors.New("cancel failed")
+	m.Check(context.Background())
+	fail = nil
+	m.Check(context.Background())
+	gw.health = up
+	m.Check(context.Background())
+	if calls != 2 {
+		t.Errorf("expected a failed call and a retry, got %d", calls)
+	}
+}
+
+func TestProbeHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	var spot, perp WSState
+	spot.SetConnected(true)
+	perp.SetConnected(true)
+	perp.Touch()
+	probe := func(path string) func(context.Context) error {
+		return func(ctx context.Context) error { return ProbeREST(ctx, srv.Client(), srv.URL+path) }
+	}
+
+	h := ProbeHealth(context.Background(), "bybit", probe("/up"), &spot, &perp)
+	if !h.WSConnected || !h.RESTReachable || h.LastMessageAt.IsZero() {
+		t.Errorf("expected healthy with a 403 from REST, got %+v", h)
+	}
+
+	spot.SetConnected(false)
+	h = ProbeHealth(context.Background(), "bybit", probe("/down"), &spot, &perp)
+	if h.WSConnected || h.RESTReachable || h.RESTError == "" {
+		t.Errorf("expected spot down and REST unreachable on 502, got %+v", h)
+	}
+}
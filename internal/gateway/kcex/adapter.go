@@ -2,7 +2,12 @@ package kcex
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/gateway"
@@ -13,10 +18,14 @@ import (
 // API key, secret, and passphrase headers.
 // Supports both spot (BTC-USDT format) and futures (BTCUSDTM format).
 type Gateway struct {
-	ws     *wsClient
+	ws     *wsClient // carries order updates and the first market data shard
 	rest   *restClient
-	rl     *gateway.RateLimiter
 	logger *slog.Logger
+
+	// shards are the market data connections, ws first; assign picks the
+	// one each symbol's topics are subscribed on.
+	shards []*wsClient
+	assign *gateway.ShardAssigner
 }
 
 // New creates a new KCEX gateway.
@@ -31,53 +40,153 @@ func New(wsURL, restURL, apiKey, apiSecret, passphrase string, logger *slog.Logg
 	rl.AddBucket(domain.EndpointAccount, 10, 5)
 
 	rest := newRESTClient(restURL, apiKey, apiSecret, passphrase, rl, logger)
+	ws := newWSClient(wsURL, rest, logger)
 
 	return &Gateway{
-		ws:     newWSClient(wsURL, rest, logger),
+		ws:     ws,
 		rest:   rest,
-		rl:     rl,
 		logger: logger,
+		shards: []*wsClient{ws},
+		assign: gateway.NewShardAssigner(1),
 	}
 }
 
 func (g *Gateway) Name() string { return "kcex" }
 
+// SetAPIErrorObserver implements gateway.APIErrorReporter.
+func (g *Gateway) SetAPIErrorObserver(fn gateway.APIErrorObserver) {
+	g.rest.retrier.OnError = fn
+}
+
+// SetWSReconnectObserver implements gateway.WSReconnectReporter.
+func (g *Gateway) SetWSReconnectObserver(fn gateway.WSReconnectObserver) {
+	for _, ws := range g.shards {
+		ws.onReconnect = func() { fn("kcex") }
+	}
+}
+
+// SetLatencyObserver implements gateway.LatencyReporter. Stream delays are
+// measured against the venue-synced clock.
+func (g *Gateway) SetLatencyObserver(fn gateway.LatencyObserver) {
+	g.rest.retrier.OnLatency = fn
+	latency := gateway.WSLatency(func(stream string, venueTime time.Time) {
+		fn("kcex", gateway.LatencyWS, stream, g.rest.clock.Now().Sub(venueTime))
+	})
+	for _, ws := range g.shards {
+		ws.latency = latency
+	}
+}
+
+// SetEgress implements gateway.EgressConfigurable.
+func (g *Gateway) SetEgress(e gateway.Egress) {
+	e.ApplyClient(g.rest.httpClient)
+	for _, ws := range g.shards {
+		ws.egress = e
+	}
+}
+
+// SetWSConnections implements gateway.WSSharder. Symbols are spread over n
+// connections; the extra ones are public and carry market data only.
+func (g *Gateway) SetWSConnections(n int) {
+	for len(g.shards) < n {
+		ws := newWSClient(g.ws.fallbackURL, g.rest, g.logger)
+		ws.private = false
+		ws.onReconnect = g.ws.onReconnect
+		ws.latency = g.ws.latency
+		ws.egress = g.ws.egress
+		g.shards = append(g.shards, ws)
+	}
+	g.assign = gateway.NewShardAssigner(len(g.shards))
+}
+
+// shard returns the connection that carries venueSymbol's topics.
+func (g *Gateway) shard(venueSymbol string) *wsClient {
+	return g.shards[g.assign.Shard(venueSymbol)]
+}
+
+// SetClockOffsetObserver implements gateway.ClockOffsetReporter.
+func (g *Gateway) SetClockOffsetObserver(fn gateway.ClockOffsetObserver) {
+	g.rest.clock.OnSync = fn
+}
+
+// SetSubAccount tags balances and positions with the KCEX sub-account whose
+// API key signs requests. Withdrawals then draw on that sub-account's main
+// wallet. Call before Connect.
+func (g *Gateway) SetSubAccount(id string) {
+	g.rest.subAccount = id
+}
+
+// SetAPIKeys implements gateway.KeyRotator.
+func (g *Gateway) SetAPIKeys(keys []gateway.APIKey) error {
+	return g.rest.keys.SetKeys(keys)
+}
+
 func (g *Gateway) Connect(ctx context.Context) error {
-	return g.ws.connect(ctx)
+	if g.rest.keys.Signed() {
+		go g.rest.clock.Run(ctx, gateway.ClockSyncInterval, g.logger)
+		if err := g.rest.loadStopOrders(ctx); err != nil {
+			return fmt.Errorf("load kcex stop orders: %w", err)
+		}
+	}
+	for _, ws := range g.shards {
+		if err := ws.connect(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (g *Gateway) Close() error {
-	return g.ws.close()
+	var errs []error
+	for _, ws := range g.shards {
+		errs = append(errs, ws.close())
+	}
+	return errors.Join(errs...)
+}
+
+// Health implements gateway.VenueGateway. The venue counts as connected only
+// while every connection is up.
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	states := make([]*gateway.WSState, len(g.shards))
+	for i, ws := range g.shards {
+		states[i] = &ws.state
+	}
+	return gateway.ProbeHealth(ctx, "kcex", g.rest.ping, states...)
 }
 
 func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
 	venueSymbol := domain.MapKCEXSymbol(symbol)
-	ch := g.ws.subscribeOrderBook(venueSymbol)
+	ws := g.shard(venueSymbol)
+	ch := ws.subscribeOrderBook(venueSymbol)
 	topic := "/market/level2:" + venueSymbol
-	if err := g.ws.subscribe(topic, false); err != nil {
+	if err := ws.subscribe(topic, false); err != nil {
 		return nil, err
 	}
-	go g.ws.readPump(ctx)
+	ws.startReadPump(ctx)
 	return ch, nil
 }
 
 func (g *Gateway) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
 	venueSymbol := domain.MapKCEXSymbol(symbol)
-	ch := g.ws.subscribeTrades(venueSymbol)
+	ws := g.shard(venueSymbol)
+	ch := ws.subscribeTrades(venueSymbol)
 	topic := "/market/match:" + venueSymbol
-	if err := g.ws.subscribe(topic, false); err != nil {
+	if err := ws.subscribe(topic, false); err != nil {
 		return nil, err
 	}
+	ws.startReadPump(ctx)
 	return ch, nil
 }
 
 func (g *Gateway) SubscribeFunding(ctx context.Context, symbol string) (<-chan domain.FundingRate, error) {
 	venueSymbol := domain.MapKCEXSymbol(symbol)
-	ch := g.ws.subscribeFunding(venueSymbol)
+	ws := g.shard(venueSymbol)
+	ch := ws.subscribeFunding(venueSymbol)
 	topic := "/contract/instrument:" + venueSymbol
-	if err := g.ws.subscribe(topic, false); err != nil {
+	if err := ws.subscribe(topic, false); err != nil {
 		return nil, err
 	}
+	ws.startReadPump(ctx)
 	return ch, nil
 }
 
@@ -89,10 +198,49 @@ func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.Canc
 	return g.rest.cancelOrder(ctx, orderID)
 }
 
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrder(ctx, orderID)
+}
+
+// GetOrderByClientID implements gateway.ClientOrderLookup.
+func (g *Gateway) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrderByClientOid(ctx, symbol, clientOrderID)
+}
+
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return g.rest.placeOrders(ctx, reqs)
+}
+
+// CancelOrders cancels one at a time: the batch cancel endpoint cancels by
+// symbol rather than by order ID.
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.rest.cancelOrder)
+}
+
+func (g *Gateway) AmendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	return g.rest.amendOrder(ctx, orderID, newPrice, newSize)
+}
+
 func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
 	return g.rest.getOpenOrders(ctx, symbol)
 }
 
+// SubscribeOrderUpdates streams order lifecycle events and fills from the
+// private order topic. It requires API credentials, since the connection
+// must be opened with a private bullet token.
+func (g *Gateway) SubscribeOrderUpdates(ctx context.Context) (<-chan domain.OrderUpdate, error) {
+	if !g.ws.private {
+		return nil, fmt.Errorf("kcex order updates require API credentials")
+	}
+	ch := g.ws.subscribeOrderUpdates()
+	if err := g.ws.subscribe(orderTopic, true); err != nil {
+		return nil, err
+	}
+	g.ws.startReadPump(ctx)
+	return ch, nil
+}
+
 func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
 	return g.rest.getBalances(ctx)
 }
@@ -104,3 +252,34 @@ func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
 func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
 	return g.rest.getFeeTier(ctx)
 }
+
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return g.rest.getInstruments(ctx)
+}
+
+func (g *Gateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return g.rest.keys.Status(), nil
+}
+
+// GetOrderBookSnapshot implements gateway.OrderBookSnapshotProvider.
+func (g *Gateway) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	return g.rest.getOrderBook(ctx, symbol)
+}
+
+func (g *Gateway) Withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	return g.rest.withdraw(ctx, req)
+}
+
+func (g *Gateway) GetDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	return g.rest.getDepositAddress(ctx, asset, network)
+}
+
+func (g *Gateway) GetTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error) {
+	return g.rest.getTransferStatus(ctx, transferID)
+}
+
+// GetAccountActivity implements gateway.AccountHistoryProvider. The range
+// must fit in the 7-day window the KCEX history endpoints allow.
+func (g *Gateway) GetAccountActivity(ctx context.Context, since, until time.Time) ([]domain.AccountActivity, error) {
+	return g.rest.getAccountActivity(ctx, since, until)
+}
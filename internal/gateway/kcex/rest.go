@@ -12,28 +12,85 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/gateway"
 )
 
+// errorCategories maps the KuCoin-style codes KCEX returns that callers
+// react to.
+var errorCategories = map[string]gateway.ErrorCategory{
+	"429000": gateway.ErrorRateLimit,           // too many requests
+	"400001": gateway.ErrorAuth,                // missing KC-API headers
+	"400002": gateway.ErrorAuth,                // invalid KC-API-TIMESTAMP
+	"400003": gateway.ErrorAuth,                // KC-API-KEY does not exist
+	"400004": gateway.ErrorAuth,                // invalid KC-API-PASSPHRASE
+	"400005": gateway.ErrorAuth,                // invalid KC-API-SIGN
+	"400006": gateway.ErrorAuth,                // IP not whitelisted
+	"400007": gateway.ErrorAuth,                // key lacks permission
+	"200004": gateway.ErrorInsufficientBalance, // balance insufficient
+	"900001": gateway.ErrorSymbolUnavailable,   // symbol does not exist
+}
+
+// apiError builds the error for a failed KCEX code.
+func apiError(what, code, msg string) error {
+	category := errorCategories[code]
+	if category == gateway.ErrorUnknown && isDuplicateClientOid(msg) {
+		category = gateway.ErrorDuplicateOrder
+	}
+	return &gateway.VenueError{
+		Venue:    "kcex",
+		Op:       what,
+		Code:     code,
+		Message:  msg,
+		Category: category,
+	}
+}
+
+// isDuplicateClientOid recognizes a reused clientOid, which KCEX reports
+// under its generic parameter error code rather than one of its own.
+func isDuplicateClientOid(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "clientoid") &&
+		(strings.Contains(msg, "duplicat") || strings.Contains(msg, "exist") || strings.Contains(msg, "repeat"))
+}
+
 type restClient struct {
-	baseURL       string
-	apiKey        string
-	apiSecret     string
-	apiPassphrase string
-	httpClient    *http.Client
-	rateLimiter   *gateway.RateLimiter
-	logger        *slog.Logger
+	baseURL     string
+	httpClient  *http.Client
+	rateLimiter *gateway.RateLimiter
+	logger      *slog.Logger
+
+	// keys picks the API key, and with it the rate limit budget, for each
+	// signed request. Public requests use rateLimiter.
+	keys *gateway.KeyPool
+
+	// subAccount names the sub-account whose API key signs our requests.
+	subAccount string
+
+	// retrier retries transient REST failures and reports every failed attempt.
+	retrier gateway.RESTRetrier
+
+	// clock corrects the timestamp signed requests carry for drift from the
+	// venue's clock.
+	clock *gateway.ClockSync
+
+	// stopOrders holds IDs of untriggered spot stop orders, which must be
+	// cancelled through the stop-order endpoint: those placed by this client
+	// and those still open at Connect from earlier runs.
+	stopOrders sync.Map
 }
 
 func newRESTClient(baseURL, apiKey, apiSecret, passphrase string, rl *gateway.RateLimiter, logger *slog.Logger) *restClient {
-	return &restClient{
-		baseURL:       baseURL,
-		apiKey:        apiKey,
-		apiSecret:     apiSecret,
-		apiPassphrase: passphrase,
+	c := &restClient{
+		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 			Transport: &http.Transport{
@@ -44,26 +101,70 @@ func newRESTClient(baseURL, apiKey, apiSecret, passphrase string, rl *gateway.Ra
 		},
 		rateLimiter: rl,
 		logger:      logger,
+		keys:        gateway.NewKeyPool(rl, gateway.APIKey{Key: apiKey, Secret: apiSecret, Passphrase: passphrase}),
+		retrier:     gateway.NewRESTRetrier("kcex"),
 	}
+	c.clock = gateway.NewClockSync("kcex", c.serverTime)
+	return c
 }
 
 // sign creates a Base64-encoded HMAC-SHA256 signature for KCEX (KuCoin-style auth).
 // The signature string is: timestamp + method + endpoint + body
-func (c *restClient) sign(payload string) string {
-	mac := hmac.New(sha256.New, []byte(c.apiSecret))
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
 	mac.Write([]byte(payload))
 	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
 }
 
 // signPassphrase creates a Base64-encoded HMAC-SHA256 of the passphrase using the API secret.
-func (c *restClient) signPassphrase() string {
-	mac := hmac.New(sha256.New, []byte(c.apiSecret))
-	mac.Write([]byte(c.apiPassphrase))
+func signPassphrase(secret, passphrase string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(passphrase))
 	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
 }
 
+// observeRateLimit feeds the response back into rl. KCEX
+// follows KuCoin in sending gw-ratelimit-remaining and gw-ratelimit-reset,
+// the latter in milliseconds until the window resets.
+func observeRateLimit(rl *gateway.RateLimiter, category domain.EndpointCategory, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("gw-ratelimit-remaining"))
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests {
+		rl.ObserveResponse(category, resp)
+		return
+	}
+	ms, _ := strconv.ParseInt(resp.Header.Get("gw-ratelimit-reset"), 10, 64)
+	rl.Observe(category, remaining, time.Duration(ms)*time.Millisecond)
+}
+
+// doRequest sends a signed request, retrying transient failures.
 func (c *restClient) doRequest(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory) ([]byte, error) {
-	if err := c.rateLimiter.Acquire(ctx, category, 1); err != nil {
+	var data []byte
+	err := c.retrier.Do(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category)
+		return err
+	})
+	return data, err
+}
+
+// doRequestOnce is doRequest without retries, for calls that move funds or
+// place orders that cannot be replayed.
+func (c *restClient) doRequestOnce(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory) ([]byte, error) {
+	var data []byte
+	err := c.retrier.Once(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category)
+		return err
+	})
+	return data, err
+}
+
+// send makes one signed request attempt.
+func (c *restClient) send(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory) ([]byte, error) {
+	waited := time.Now()
+	key, err := c.keys.Acquire(ctx, category, 1)
+	c.retrier.ObserveRateLimitWait(category, time.Since(waited))
+	if err != nil {
 		return nil, fmt.Errorf("rate limit: %w", err)
 	}
 
@@ -87,23 +188,26 @@ func (c *restClient) doRequest(ctx context.Context, method, path string, body in
 
 	req.Header.Set("Content-Type", "application/json")
 
-	if c.apiKey != "" {
-		timestamp := fmt.Sprintf("%d", time.Now().UnixMilli())
+	if key.Key != "" {
+		timestamp := fmt.Sprintf("%d", c.clock.Now().UnixMilli())
 		signData := timestamp + method + path + payload
-		signature := c.sign(signData)
+		signature := sign(key.Secret, signData)
 
-		req.Header.Set("KC-API-KEY", c.apiKey)
+		req.Header.Set("KC-API-KEY", key.Key)
 		req.Header.Set("KC-API-SIGN", signature)
 		req.Header.Set("KC-API-TIMESTAMP", timestamp)
-		req.Header.Set("KC-API-PASSPHRASE", c.signPassphrase())
+		req.Header.Set("KC-API-PASSPHRASE", signPassphrase(key.Secret, key.Passphrase))
 		req.Header.Set("KC-API-KEY-VERSION", "2")
 	}
 
+	sent := time.Now()
 	resp, err := c.httpClient.Do(req)
+	c.retrier.ObserveLatency(category, time.Since(sent))
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
+	observeRateLimit(key.Limiter, category, resp)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -111,7 +215,15 @@ func (c *restClient) doRequest(ctx context.Context, method, path string, body in
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		// Rejections such as a 401 carry a KCEX code in the body too.
+		var apiErr struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if !gateway.RetryableStatus(resp.StatusCode) && json.Unmarshal(respBody, &apiErr) == nil && apiErr.Code != "" {
+			return nil, apiError("API error", apiErr.Code, apiErr.Msg)
+		}
+		return nil, gateway.NewHTTPError(resp, respBody)
 	}
 
 	// KCEX wraps all responses in {"code": "200000", "data": ...}
@@ -125,15 +237,29 @@ func (c *restClient) doRequest(ctx context.Context, method, path string, body in
 	}
 
 	if baseResp.Code != "200000" {
-		return nil, fmt.Errorf("KCEX API error: code=%s msg=%s", baseResp.Code, baseResp.Msg)
+		return nil, apiError("API error", baseResp.Code, baseResp.Msg)
 	}
 
 	return baseResp.Data, nil
 }
 
-// doPublicRequest performs a request without authentication for public endpoints.
+// doPublicRequest performs a request without authentication for public
+// endpoints, retrying transient failures.
 func (c *restClient) doPublicRequest(ctx context.Context, method, path string, category domain.EndpointCategory) ([]byte, error) {
-	if err := c.rateLimiter.Acquire(ctx, category, 1); err != nil {
+	var data []byte
+	err := c.retrier.Do(ctx, category, func() error {
+		var err error
+		data, err = c.sendPublic(ctx, method, path, category)
+		return err
+	})
+	return data, err
+}
+
+func (c *restClient) sendPublic(ctx context.Context, method, path string, category domain.EndpointCategory) ([]byte, error) {
+	waited := time.Now()
+	err := c.rateLimiter.Acquire(ctx, category, 1)
+	c.retrier.ObserveRateLimitWait(category, time.Since(waited))
+	if err != nil {
 		return nil, fmt.Errorf("rate limit: %w", err)
 	}
 
@@ -144,11 +270,14 @@ func (c *restClient) doPublicRequest(ctx context.Context, method, path string, c
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
+	sent := time.Now()
 	resp, err := c.httpClient.Do(req)
+	c.retrier.ObserveLatency(category, time.Since(sent))
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
+	observeRateLimit(c.rateLimiter, category, resp)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -156,7 +285,15 @@ func (c *restClient) doPublicRequest(ctx context.Context, method, path string, c
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		// Rejections such as a 401 carry a KCEX code in the body too.
+		var apiErr struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if !gateway.RetryableStatus(resp.StatusCode) && json.Unmarshal(respBody, &apiErr) == nil && apiErr.Code != "" {
+			return nil, apiError("API error", apiErr.Code, apiErr.Msg)
+		}
+		return nil, gateway.NewHTTPError(resp, respBody)
 	}
 
 	var baseResp struct {
@@ -169,7 +306,7 @@ func (c *restClient) doPublicRequest(ctx context.Context, method, path string, c
 	}
 
 	if baseResp.Code != "200000" {
-		return nil, fmt.Errorf("KCEX API error: code=%s msg=%s", baseResp.Code, baseResp.Msg)
+		return nil, apiError("API error", baseResp.Code, baseResp.Msg)
 	}
 
 	return baseResp.Data, nil
@@ -191,15 +328,24 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 		"size":      req.Size.String(),
 	}
 
-	if req.OrderType == domain.OrderTypeLimit {
+	if req.OrderType == domain.OrderTypeLimit || req.OrderType == domain.OrderTypeStopLimit {
 		body["type"] = "limit"
 		body["price"] = req.Price.String()
+		if req.TimeInForce != "" {
+			body["timeInForce"] = string(req.TimeInForce)
+		}
+		if req.PostOnly {
+			body["postOnly"] = true
+		}
 	} else {
 		body["type"] = "market"
 	}
 
 	if isFutures {
 		body["leverage"] = "1"
+		if req.ReduceOnly {
+			body["reduceOnly"] = true
+		}
 	}
 
 	var path string
@@ -209,7 +355,31 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 		path = "/api/v1/orders"
 	}
 
-	data, err := c.doRequest(ctx, "POST", path, body, domain.EndpointOrderPlace)
+	// Spot stops go to their own endpoint and name the trigger direction
+	// loss/entry; futures stops share the order endpoint and use down/up
+	// against the last traded price.
+	if req.OrderType.IsStop() {
+		body["stopPrice"] = req.StopPrice.String()
+		if isFutures {
+			body["stop"] = "up"
+			if req.Side == domain.SideSell {
+				body["stop"] = "down"
+			}
+			body["stopPriceType"] = "TP"
+		} else {
+			body["stop"] = "entry"
+			if req.Side == domain.SideSell {
+				body["stop"] = "loss"
+			}
+			path = "/api/v1/stop-order"
+		}
+	}
+
+	send := c.doRequest
+	if !gateway.Replayable(req) {
+		send = c.doRequestOnce
+	}
+	data, err := send(ctx, "POST", path, body, domain.EndpointOrderPlace)
 	if err != nil {
 		return nil, err
 	}
@@ -220,6 +390,9 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 	if err := json.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("parse order response: %w", err)
 	}
+	if path == "/api/v1/stop-order" {
+		c.stopOrders.Store(result.OrderID, struct{}{})
+	}
 
 	return &domain.OrderAck{
 		InternalID: req.InternalID,
@@ -229,12 +402,114 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 	}, nil
 }
 
+// multiOrderLimit is the most orders /api/v1/orders/multi accepts per call.
+const multiOrderLimit = 5
+
+// placeOrders batches plain spot limit orders per symbol through the
+// multi-order endpoint, which only takes spot limit orders. Everything else
+// is placed one at a time.
+func (c *restClient) placeOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	results := make([]gateway.PlaceResult, len(reqs))
+	bySymbol := make(map[string][]int)
+	var symbols []string
+	for i, req := range reqs {
+		if req.OrderType != domain.OrderTypeLimit || domain.IsKCEXFutures(req.Symbol) {
+			results[i].Ack, results[i].Err = c.placeOrder(ctx, req)
+			continue
+		}
+		venueSymbol := domain.MapKCEXSymbol(req.Symbol)
+		if _, ok := bySymbol[venueSymbol]; !ok {
+			symbols = append(symbols, venueSymbol)
+		}
+		bySymbol[venueSymbol] = append(bySymbol[venueSymbol], i)
+	}
+
+	for _, venueSymbol := range symbols {
+		idx := bySymbol[venueSymbol]
+		for start := 0; start < len(idx); start += multiOrderLimit {
+			c.placeMulti(ctx, venueSymbol, reqs, idx[start:min(start+multiOrderLimit, len(idx))], results)
+		}
+	}
+	return results
+}
+
+func (c *restClient) placeMulti(ctx context.Context, venueSymbol string, reqs []domain.OrderRequest, idx []int, results []gateway.PlaceResult) {
+	orderList := make([]map[string]interface{}, len(idx))
+	for j, i := range idx {
+		req := reqs[i]
+		side := "buy"
+		if req.Side == domain.SideSell {
+			side = "sell"
+		}
+		item := map[string]interface{}{
+			"clientOid": req.IdempotencyKey,
+			"side":      side,
+			"type":      "limit",
+			"price":     req.Price.String(),
+			"size":      req.Size.String(),
+		}
+		if req.TimeInForce != "" {
+			item["timeInForce"] = string(req.TimeInForce)
+		}
+		if req.PostOnly {
+			item["postOnly"] = true
+		}
+		orderList[j] = item
+	}
+
+	var result struct {
+		Data []struct {
+			ID      string `json:"id"`
+			Status  string `json:"status"`
+			FailMsg string `json:"failMsg"`
+		} `json:"data"`
+	}
+	send := c.doRequest
+	if !gateway.Replayable(reqs...) {
+		send = c.doRequestOnce
+	}
+	data, err := send(ctx, "POST", "/api/v1/orders/multi", map[string]interface{}{
+		"symbol":    venueSymbol,
+		"orderList": orderList,
+	}, domain.EndpointOrderPlace)
+	if err == nil {
+		if err = json.Unmarshal(data, &result); err != nil {
+			err = fmt.Errorf("parse multi-order response: %w", err)
+		}
+	}
+
+	for j, i := range idx {
+		switch {
+		case err != nil:
+			results[i].Err = err
+		case j >= len(result.Data):
+			results[i].Err = fmt.Errorf("missing multi-order result")
+		case result.Data[j].Status != "success":
+			results[i].Err = fmt.Errorf("KCEX order rejected: %s", result.Data[j].FailMsg)
+		default:
+			results[i].Ack = &domain.OrderAck{
+				InternalID: reqs[i].InternalID,
+				VenueID:    result.Data[j].ID,
+				Status:     domain.OrderStatusAcknowledged,
+				Timestamp:  time.Now(),
+			}
+		}
+	}
+}
+
 func (c *restClient) cancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
 	path := fmt.Sprintf("/api/v1/orders/%s", orderID)
+	_, isStop := c.stopOrders.Load(orderID)
+	if isStop {
+		path = fmt.Sprintf("/api/v1/stop-order/%s", orderID)
+	}
 	_, err := c.doRequest(ctx, "DELETE", path, nil, domain.EndpointOrderCancel)
 	if err != nil {
 		return nil, err
 	}
+	if isStop {
+		c.stopOrders.Delete(orderID)
+	}
 
 	return &domain.CancelAck{
 		Status:    domain.OrderStatusCancelled,
@@ -242,6 +517,153 @@ func (c *restClient) cancelOrder(ctx context.Context, orderID string) (*domain.C
 	}, nil
 }
 
+// stopOrderPageSize is the most stop orders /api/v1/stop-order lists per page.
+const stopOrderPageSize = 500
+
+// loadStopOrders records the account's untriggered spot stop orders, so
+// that stops placed before a restart are still cancelled through the
+// stop-order endpoint.
+func (c *restClient) loadStopOrders(ctx context.Context) error {
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("/api/v1/stop-order?currentPage=%d&pageSize=%d", page, stopOrderPageSize)
+		data, err := c.doRequest(ctx, "GET", path, nil, domain.EndpointPrivateData)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			TotalPage int `json:"totalPage"`
+			Items     []struct {
+				ID string `json:"id"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return fmt.Errorf("parse stop orders: %w", err)
+		}
+		for _, o := range result.Items {
+			c.stopOrders.Store(o.ID, struct{}{})
+		}
+		if page >= result.TotalPage || len(result.Items) == 0 {
+			return nil
+		}
+	}
+}
+
+// getOrder looks up one order. KCEX has no status field: an inactive order
+// was either cancelled, possibly after partial fills, or filled in full.
+// Untriggered stop orders live under a separate endpoint and are not looked
+// up.
+func (c *restClient) getOrder(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	if _, isStop := c.stopOrders.Load(orderID); isStop {
+		return nil, fmt.Errorf("%w: kcex stop order %s", gateway.ErrOrderStatusUnsupported, orderID)
+	}
+	data, err := c.doRequest(ctx, "GET", "/api/v1/orders/"+url.PathEscape(orderID), nil, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+	update, err := parseOrderStatus(data)
+	if err != nil {
+		return nil, err
+	}
+	update.VenueID = orderID
+	return update, nil
+}
+
+// getOrderByClientOid looks up the order placed with clientOid on symbol's
+// market.
+func (c *restClient) getOrderByClientOid(ctx context.Context, symbol, clientOid string) (*domain.OrderUpdate, error) {
+	path := "/api/v1/order/client-order/" + url.PathEscape(clientOid)
+	if domain.IsKCEXFutures(symbol) {
+		path = "/api/v1/orders/byClientOid?clientOid=" + url.QueryEscape(clientOid)
+	}
+	data, err := c.doRequest(ctx, "GET", path, nil, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+	update, err := parseOrderStatus(data)
+	if err != nil {
+		return nil, err
+	}
+	if update.VenueID == "" {
+		return nil, fmt.Errorf("kcex order with clientOid %s not found", clientOid)
+	}
+	return update, nil
+}
+
+// parseOrderStatus reads an order detail response.
+func parseOrderStatus(data []byte) (*domain.OrderUpdate, error) {
+	var o struct {
+		ID          string `json:"id"`
+		ClientOid   string `json:"clientOid"`
+		Size        string `json:"size"`
+		DealSize    string `json:"dealSize"`
+		DealFunds   string `json:"dealFunds"`
+		IsActive    bool   `json:"isActive"`
+		CancelExist bool   `json:"cancelExist"`
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, fmt.Errorf("parse order: %w", err)
+	}
+
+	update := &domain.OrderUpdate{
+		Venue:         "kcex",
+		VenueID:       o.ID,
+		ClientOrderID: o.ClientOid,
+		Timestamp:     time.Now(),
+	}
+	update.FilledSize, _ = domain.ParseDecimal(o.DealSize)
+	if update.FilledSize.IsPositive() {
+		funds, _ := domain.ParseDecimal(o.DealFunds)
+		update.AvgFillPrice = funds.Div(update.FilledSize)
+	}
+
+	switch {
+	case o.IsActive && update.FilledSize.IsPositive():
+		update.Status = domain.OrderStatusPartialFill
+	case o.IsActive:
+		update.Status = domain.OrderStatusAcknowledged
+	case o.CancelExist:
+		update.Status = domain.OrderStatusCancelled
+	default:
+		update.Status = domain.OrderStatusFilled
+	}
+	return update, nil
+}
+
+// amendOrder uses the alter endpoint, which cancel-replaces the order and
+// returns the new order ID. newSize is the new total size, including any
+// quantity already filled.
+func (c *restClient) amendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	body := map[string]interface{}{
+		"orderId":  orderID,
+		"newPrice": newPrice.String(),
+		"newSize":  newSize.String(),
+	}
+
+	// The replacement carries no client order ID to make a replay safe.
+	data, err := c.doRequestOnce(ctx, "POST", "/api/v1/orders/alter", body, domain.EndpointOrderPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		NewOrderID string `json:"newOrderId"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse alter response: %w", err)
+	}
+	if result.NewOrderID == "" {
+		result.NewOrderID = orderID
+	}
+
+	return &domain.AmendAck{
+		VenueID:   result.NewOrderID,
+		Price:     newPrice,
+		Size:      newSize,
+		Status:    domain.OrderStatusAcknowledged,
+		Timestamp: time.Now(),
+	}, nil
+}
+
 func (c *restClient) getBalances(ctx context.Context) (map[string]domain.Balance, error) {
 	data, err := c.doRequest(ctx, "GET", "/api/v1/accounts", nil, domain.EndpointAccount)
 	if err != nil {
@@ -266,8 +688,9 @@ func (c *restClient) getBalances(ctx context.Context) (map[string]domain.Balance
 			continue
 		}
 		bal := domain.Balance{
-			Venue: "kcex",
-			Asset: a.Currency,
+			Venue:   "kcex",
+			Account: c.subAccount,
+			Asset:   a.Currency,
 		}
 		bal.Free, _ = domain.ParseDecimal(a.Available)
 		bal.Locked, _ = domain.ParseDecimal(a.Holds)
@@ -304,6 +727,7 @@ func (c *restClient) getPositions(ctx context.Context) ([]domain.Position, error
 		}
 		pos := domain.Position{
 			Venue:          "kcex",
+			Account:        c.subAccount,
 			Asset:          p.Symbol,
 			InstrumentType: domain.InstrumentPerp,
 			UpdatedAt:      time.Now(),
@@ -427,6 +851,7 @@ func (c *restClient) getOrderBook(ctx context.Context, symbol string) (*domain.O
 		VenueTimestamp: time.UnixMilli(result.Time),
 		LocalTimestamp:  time.Now(),
 	}
+	book.Sequence, _ = strconv.ParseUint(result.Sequence, 10, 64)
 
 	for _, bid := range result.Bids {
 		if len(bid) >= 2 {
@@ -446,6 +871,83 @@ func (c *restClient) getOrderBook(ctx context.Context, symbol string) (*domain.O
 	return book, nil
 }
 
+// getInstruments reads the increments and minimums of every mapped spot
+// symbol and futures contract. Futures orders are sized in contracts
+// (placeOrder sends Size as is), so their step and minimum are in contracts
+// too, with the contract's base quantity as the multiplier.
+func (c *restClient) getInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	data, err := c.doPublicRequest(ctx, "GET", "/api/v1/symbols", domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []struct {
+		Symbol         string `json:"symbol"`
+		PriceIncrement string `json:"priceIncrement"`
+		BaseIncrement  string `json:"baseIncrement"`
+		BaseMinSize    string `json:"baseMinSize"`
+		MinFunds       string `json:"minFunds"`
+		EnableTrading  bool   `json:"enableTrading"`
+	}
+	if err := json.Unmarshal(data, &symbols); err != nil {
+		return nil, fmt.Errorf("parse symbols: %w", err)
+	}
+
+	var out []domain.Instrument
+	for _, s := range symbols {
+		symbol := domain.ReverseMapSymbol(s.Symbol, domain.KCEXSpotSymbolMap)
+		if symbol == s.Symbol {
+			continue
+		}
+		inst := domain.Instrument{
+			Venue:              "kcex",
+			Symbol:             symbol,
+			InstrumentType:     domain.InstrumentSpot,
+			ContractMultiplier: decimal.NewFromInt(1),
+			Suspended:          !s.EnableTrading,
+		}
+		inst.TickSize, _ = domain.ParseDecimal(s.PriceIncrement)
+		inst.StepSize, _ = domain.ParseDecimal(s.BaseIncrement)
+		inst.MinSize, _ = domain.ParseDecimal(s.BaseMinSize)
+		inst.MinNotional, _ = domain.ParseDecimal(s.MinFunds)
+		out = append(out, inst)
+	}
+
+	data, err = c.doPublicRequest(ctx, "GET", "/api/v1/contracts/active", domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var contracts []struct {
+		Symbol     string          `json:"symbol"`
+		TickSize   decimal.Decimal `json:"tickSize"`
+		LotSize    decimal.Decimal `json:"lotSize"`
+		Multiplier decimal.Decimal `json:"multiplier"`
+		Status     string          `json:"status"` // Open, Paused, ...
+	}
+	if err := json.Unmarshal(data, &contracts); err != nil {
+		return nil, fmt.Errorf("parse contracts: %w", err)
+	}
+
+	for _, ct := range contracts {
+		symbol := domain.ReverseMapSymbol(ct.Symbol, domain.KCEXFuturesSymbolMap)
+		if symbol == ct.Symbol {
+			continue
+		}
+		out = append(out, domain.Instrument{
+			Venue:              "kcex",
+			Symbol:             symbol,
+			InstrumentType:     domain.InstrumentPerp,
+			TickSize:           ct.TickSize,
+			StepSize:           ct.LotSize,
+			MinSize:            ct.LotSize,
+			ContractMultiplier: ct.Multiplier,
+			Suspended:          ct.Status != "Open",
+		})
+	}
+	return out, nil
+}
+
 // getWSToken requests a WebSocket connection token from the KCEX API.
 func (c *restClient) getWSToken(ctx context.Context, private bool) (*wsToken, error) {
 	path := "/api/v1/bullet-public"
@@ -519,3 +1021,362 @@ func (c *restClient) getFundingRate(ctx context.Context, symbol string) (*domain
 
 	return rate, nil
 }
+
+// activityPageSize is the largest page the KCEX history endpoints accept.
+const activityPageSize = 500
+
+// getPaged walks a paginated history endpoint, passing each page's items to
+// fn. The endpoints cap a query at a 7-day window; callers split longer
+// ranges themselves.
+func (c *restClient) getPaged(ctx context.Context, path string, params url.Values, fn func(items json.RawMessage) error) error {
+	for page := 1; ; page++ {
+		params.Set("currentPage", fmt.Sprintf("%d", page))
+		params.Set("pageSize", fmt.Sprintf("%d", activityPageSize))
+		data, err := c.doRequest(ctx, "GET", path+"?"+params.Encode(), nil, domain.EndpointAccount)
+		if err != nil {
+			return err
+		}
+
+		var result struct {
+			TotalPage int             `json:"totalPage"`
+			Items     json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return fmt.Errorf("parse %s page %d: %w", path, page, err)
+		}
+		if err := fn(result.Items); err != nil {
+			return err
+		}
+		if page >= result.TotalPage {
+			return nil
+		}
+	}
+}
+
+// getAccountActivity collects spot fills, deposits, withdrawals and futures
+// funding payments between since and until.
+func (c *restClient) getAccountActivity(ctx context.Context, since, until time.Time) ([]domain.AccountActivity, error) {
+	window := func() url.Values {
+		return url.Values{
+			"startAt": {fmt.Sprintf("%d", since.UnixMilli())},
+			"endAt":   {fmt.Sprintf("%d", until.UnixMilli())},
+		}
+	}
+
+	var out []domain.AccountActivity
+
+	err := c.getPaged(ctx, "/api/v1/fills", window(), func(items json.RawMessage) error {
+		var fills []struct {
+			TradeID     string `json:"tradeId"`
+			Symbol      string `json:"symbol"`
+			Side        string `json:"side"`
+			Price       string `json:"price"`
+			Size        string `json:"size"`
+			Fee         string `json:"fee"`
+			FeeCurrency string `json:"feeCurrency"`
+			CreatedAt   int64  `json:"createdAt"`
+		}
+		if err := json.Unmarshal(items, &fills); err != nil {
+			return fmt.Errorf("parse fills: %w", err)
+		}
+		for _, f := range fills {
+			side := domain.SideBuy
+			if f.Side == "sell" {
+				side = domain.SideSell
+			}
+			a := domain.AccountActivity{
+				Venue:       "kcex",
+				Type:        domain.ActivityTrade,
+				VenueRef:    f.TradeID,
+				Symbol:      domain.ReverseMapSymbol(f.Symbol, domain.KCEXSpotSymbolMap),
+				Side:        side,
+				FeeCurrency: f.FeeCurrency,
+				Timestamp:   time.UnixMilli(f.CreatedAt),
+			}
+			a.Price, _ = domain.ParseDecimal(f.Price)
+			a.Size, _ = domain.ParseDecimal(f.Size)
+			a.Fee, _ = domain.ParseDecimal(f.Fee)
+			out = append(out, a)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fills: %w", err)
+	}
+
+	transfers := []struct {
+		path string
+		typ  domain.ActivityType
+	}{
+		{"/api/v1/deposits", domain.ActivityDeposit},
+		{"/api/v1/withdrawals", domain.ActivityWithdrawal},
+	}
+	for _, t := range transfers {
+		params := window()
+		params.Set("status", "SUCCESS")
+		err := c.getPaged(ctx, t.path, params, func(items json.RawMessage) error {
+			var records []struct {
+				ID         string `json:"id"`
+				WalletTxID string `json:"walletTxId"`
+				Currency   string `json:"currency"`
+				Amount     string `json:"amount"`
+				Fee        string `json:"fee"`
+				CreatedAt  int64  `json:"createdAt"`
+			}
+			if err := json.Unmarshal(items, &records); err != nil {
+				return fmt.Errorf("parse transfers: %w", err)
+			}
+			for _, r := range records {
+				// Deposits have no id of their own; the chain tx hash is unique.
+				ref := r.ID
+				if ref == "" {
+					ref = r.WalletTxID
+				}
+				a := domain.AccountActivity{
+					Venue:       "kcex",
+					Type:        t.typ,
+					VenueRef:    ref,
+					Asset:       r.Currency,
+					FeeCurrency: r.Currency,
+					Timestamp:   time.UnixMilli(r.CreatedAt),
+				}
+				a.Amount, _ = domain.ParseDecimal(r.Amount)
+				a.Fee, _ = domain.ParseDecimal(r.Fee)
+				if t.typ == domain.ActivityWithdrawal {
+					a.Amount = a.Amount.Neg()
+				}
+				out = append(out, a)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.path, err)
+		}
+	}
+
+	for internal, venueSymbol := range domain.KCEXFuturesSymbolMap {
+		funding, err := c.getFundingHistory(ctx, internal, venueSymbol, since, until)
+		if err != nil {
+			return nil, fmt.Errorf("funding history %s: %w", venueSymbol, err)
+		}
+		out = append(out, funding...)
+	}
+
+	return out, nil
+}
+
+// getFundingHistory pages through settled funding payments for one contract.
+// Unlike the spot history endpoints it pages with offset/hasMore. Funding is
+// positive when received.
+func (c *restClient) getFundingHistory(ctx context.Context, symbol, venueSymbol string, since, until time.Time) ([]domain.AccountActivity, error) {
+	var out []domain.AccountActivity
+	offset := 0
+	for {
+		params := url.Values{
+			"symbol":   {venueSymbol},
+			"startAt":  {fmt.Sprintf("%d", since.UnixMilli())},
+			"endAt":    {fmt.Sprintf("%d", until.UnixMilli())},
+			"offset":   {fmt.Sprintf("%d", offset)},
+			"maxCount": {fmt.Sprintf("%d", activityPageSize)},
+			"forward":  {"true"},
+		}
+		data, err := c.doRequest(ctx, "GET", "/api/v1/funding-history?"+params.Encode(), nil, domain.EndpointAccount)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			DataList []struct {
+				ID             int64  `json:"id"`
+				TimePoint      int64  `json:"timePoint"`
+				Funding        string `json:"funding"`
+				SettleCurrency string `json:"settleCurrency"`
+			} `json:"dataList"`
+			HasMore bool `json:"hasMore"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("parse funding history: %w", err)
+		}
+
+		for _, f := range result.DataList {
+			a := domain.AccountActivity{
+				Venue:     "kcex",
+				Type:      domain.ActivityFunding,
+				VenueRef:  fmt.Sprintf("%d", f.ID),
+				Symbol:    symbol,
+				Asset:     f.SettleCurrency,
+				Timestamp: time.UnixMilli(f.TimePoint),
+			}
+			a.Amount, _ = domain.ParseDecimal(f.Funding)
+			out = append(out, a)
+		}
+
+		if !result.HasMore || len(result.DataList) == 0 {
+			return out, nil
+		}
+		offset += len(result.DataList)
+	}
+}
+
+// kcexWithdrawal is a withdrawal record from /api/v1/withdrawals.
+type kcexWithdrawal struct {
+	ID         string `json:"id"`
+	Currency   string `json:"currency"`
+	Chain      string `json:"chain"`
+	Address    string `json:"address"`
+	Memo       string `json:"memo"`
+	Amount     string `json:"amount"`
+	Fee        string `json:"fee"`
+	WalletTxID string `json:"walletTxId"`
+	Status     string `json:"status"`
+	CreatedAt  int64  `json:"createdAt"`
+	UpdatedAt  int64  `json:"updatedAt"`
+}
+
+func (w kcexWithdrawal) transfer() *domain.Transfer {
+	t := &domain.Transfer{
+		ID:        w.ID,
+		Venue:     "kcex",
+		Asset:     w.Currency,
+		Network:   w.Chain,
+		Address:   w.Address,
+		Memo:      w.Memo,
+		TxID:      w.WalletTxID,
+		Status:    transferStatus(w.Status),
+		CreatedAt: time.UnixMilli(w.CreatedAt),
+		UpdatedAt: time.UnixMilli(w.UpdatedAt),
+	}
+	t.Amount, _ = domain.ParseDecimal(w.Amount)
+	t.Fee, _ = domain.ParseDecimal(w.Fee)
+	return t
+}
+
+func transferStatus(s string) domain.TransferStatus {
+	switch s {
+	case "PROCESSING", "WALLET_PROCESSING":
+		return domain.TransferStatusProcessing
+	case "SUCCESS":
+		return domain.TransferStatusCompleted
+	case "FAILURE":
+		return domain.TransferStatusFailed
+	default:
+		return domain.TransferStatusPending
+	}
+}
+
+// withdraw moves the amount from the trade account, which is all the
+// gateway reports and trades from, to the main account that withdrawals are
+// paid from, then requests the withdrawal. If the withdrawal is refused the
+// funds are left in the main account.
+func (c *restClient) withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	inner := map[string]interface{}{
+		"clientOid": uuid.New().String(),
+		"currency":  req.Asset,
+		"from":      "trade",
+		"to":        "main",
+		"amount":    req.Amount.String(),
+	}
+	if _, err := c.doRequestOnce(ctx, "POST", "/api/v2/accounts/inner-transfer", inner, domain.EndpointAccount); err != nil {
+		return nil, fmt.Errorf("move %s to main account: %w", req.Asset, err)
+	}
+
+	body := map[string]interface{}{
+		"currency": req.Asset,
+		"address":  req.Address,
+		"amount":   req.Amount.String(),
+		"chain":    req.Network,
+	}
+	if req.Memo != "" {
+		body["memo"] = req.Memo
+	}
+	data, err := c.doRequestOnce(ctx, "POST", "/api/v1/withdrawals", body, domain.EndpointAccount)
+	if err != nil {
+		return nil, fmt.Errorf("withdraw (funds left in main account): %w", err)
+	}
+
+	var result struct {
+		WithdrawalID string `json:"withdrawalId"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse withdraw response: %w", err)
+	}
+
+	now := time.Now()
+	return &domain.Transfer{
+		ID:        result.WithdrawalID,
+		Venue:     "kcex",
+		Asset:     req.Asset,
+		Network:   req.Network,
+		Address:   req.Address,
+		Memo:      req.Memo,
+		Amount:    req.Amount,
+		Status:    domain.TransferStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (c *restClient) getDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	params := url.Values{"currency": {asset}}
+	if network != "" {
+		params.Set("chain", network)
+	}
+	data, err := c.doRequest(ctx, "GET", "/api/v1/deposit-addresses?"+params.Encode(), nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Address string `json:"address"`
+		Memo    string `json:"memo"`
+		Chain   string `json:"chain"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse deposit address: %w", err)
+	}
+	if result.Address == "" {
+		return nil, fmt.Errorf("no %s deposit address on %s", asset, network)
+	}
+
+	return &domain.DepositAddress{
+		Venue:   "kcex",
+		Asset:   asset,
+		Network: result.Chain,
+		Address: result.Address,
+		Memo:    result.Memo,
+	}, nil
+}
+
+func (c *restClient) getTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/v1/withdrawals/"+url.PathEscape(transferID), nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var w kcexWithdrawal
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("parse withdrawal: %w", err)
+	}
+	return w.transfer(), nil
+}
+
+// ping checks that the public REST API answers.
+func (c *restClient) ping(ctx context.Context) error {
+	return gateway.ProbeREST(ctx, c.httpClient, c.baseURL+"/api/v1/timestamp")
+}
+
+// serverTime returns KCEX's clock, used to correct signing timestamps.
+func (c *restClient) serverTime(ctx context.Context) (time.Time, error) {
+	var resp struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data int64  `json:"data"`
+	}
+	if err := gateway.FetchJSON(ctx, c.httpClient, c.baseURL+"/api/v1/timestamp", &resp); err != nil {
+		return time.Time{}, err
+	}
+	if resp.Code != "200000" {
+		return time.Time{}, apiError("API error", resp.Code, resp.Msg)
+	}
+	return time.UnixMilli(resp.Data), nil
+}
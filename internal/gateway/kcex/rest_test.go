@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -130,6 +131,7 @@ func TestKCEXRestClient_PlaceOrder_FuturesMarketOrder(t *testing.T) {
 		Side:       domain.SideSell,
 		OrderType:  domain.OrderTypeMarket,
 		Size:       decimal.NewFromFloat(0.5),
+		ReduceOnly: true,
 	}
 
 	_, err := client.placeOrder(context.Background(), req)
@@ -149,6 +151,107 @@ func TestKCEXRestClient_PlaceOrder_FuturesMarketOrder(t *testing.T) {
 	if capturedBody["leverage"] != "1" {
 		t.Errorf("expected leverage=1 for futures, got %v", capturedBody["leverage"])
 	}
+	if capturedBody["reduceOnly"] != true {
+		t.Errorf("expected reduceOnly=true, got %v", capturedBody["reduceOnly"])
+	}
+}
+
+func TestKCEXRestClient_StopOrders(t *testing.T) {
+	var paths []string
+	var bodies []map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{"orderId": "stop-1"}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+	ctx := context.Background()
+
+	// Protective stop on a perp short: buy back if the price rises.
+	_, err := client.placeOrder(ctx, domain.OrderRequest{
+		Symbol:    "BTCUSDT",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeStopMarket,
+		StopPrice: decimal.NewFromInt(65000),
+		Size:      decimal.NewFromInt(1),
+	})
+	if err != nil {
+		t.Fatalf("futures stop: %v", err)
+	}
+	if paths[0] != "POST /api/v1/futures/orders" {
+		t.Errorf("expected futures order endpoint, got %s", paths[0])
+	}
+	if bodies[0]["stop"] != "up" || bodies[0]["stopPrice"] != "65000" || bodies[0]["type"] != "market" {
+		t.Errorf("unexpected futures stop body: %v", bodies[0])
+	}
+
+	_, err = client.placeOrder(ctx, domain.OrderRequest{
+		Symbol:    "BTC/USDT",
+		Side:      domain.SideSell,
+		OrderType: domain.OrderTypeStopLimit,
+		Price:     decimal.NewFromInt(57900),
+		StopPrice: decimal.NewFromInt(58000),
+		Size:      decimal.NewFromFloat(0.1),
+	})
+	if err != nil {
+		t.Fatalf("spot stop: %v", err)
+	}
+	if paths[1] != "POST /api/v1/stop-order" {
+		t.Errorf("expected stop-order endpoint, got %s", paths[1])
+	}
+	if bodies[1]["stop"] != "loss" || bodies[1]["price"] != "57900" || bodies[1]["type"] != "limit" {
+		t.Errorf("unexpected spot stop body: %v", bodies[1])
+	}
+
+	// Cancelling the spot stop must go to the stop-order endpoint.
+	if _, err := client.cancelOrder(ctx, "stop-1"); err != nil {
+		t.Fatalf("cancel stop: %v", err)
+	}
+	if paths[2] != "DELETE /api/v1/stop-order/stop-1" {
+		t.Errorf("expected stop-order cancel, got %s", paths[2])
+	}
+}
+
+func TestKCEXRestClient_LoadStopOrders(t *testing.T) {
+	var paths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.URL.Path != "/api/v1/stop-order" {
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{}))
+			return
+		}
+		id := "stop-" + r.URL.Query().Get("currentPage")
+		json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+			"currentPage": r.URL.Query().Get("currentPage"),
+			"totalPage":   2,
+			"items":       []map[string]interface{}{{"id": id}},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+	ctx := context.Background()
+
+	if err := client.loadStopOrders(ctx); err != nil {
+		t.Fatalf("load stop orders: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("expected both pages to be read, got %v", paths)
+	}
+
+	// A stop left open by an earlier run is cancelled through the
+	// stop-order endpoint.
+	if _, err := client.cancelOrder(ctx, "stop-2"); err != nil {
+		t.Fatalf("cancel stop: %v", err)
+	}
+	if paths[2] != "DELETE /api/v1/stop-order/stop-2" {
+		t.Errorf("expected stop-order cancel, got %s", paths[2])
+	}
 }
 
 func TestKCEXRestClient_CancelOrder(t *testing.T) {
@@ -182,6 +285,59 @@ func TestKCEXRestClient_CancelOrder(t *testing.T) {
 	}
 }
 
+func TestKCEXRestClient_AmendOrder(t *testing.T) {
+	var capturedPath string
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+			"newOrderId": "order-790",
+			"clientOid":  "idem-1",
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	ack, err := client.amendOrder(context.Background(), "order-789", decimal.NewFromInt(50100), decimal.NewFromFloat(0.2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedPath != "/api/v1/orders/alter" {
+		t.Errorf("expected path /api/v1/orders/alter, got %s", capturedPath)
+	}
+	if capturedBody["orderId"] != "order-789" || capturedBody["newPrice"] != "50100" || capturedBody["newSize"] != "0.2" {
+		t.Errorf("unexpected alter body: %v", capturedBody)
+	}
+	if ack.VenueID != "order-790" {
+		t.Errorf("expected new venue ID order-790, got %s", ack.VenueID)
+	}
+}
+
+func TestKCEXRestClient_RateLimitHeaders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("gw-ratelimit-remaining", "0")
+		w.Header().Set("gw-ratelimit-reset", "30000")
+		json.NewEncoder(w).Encode(kcexOK([]map[string]interface{}{}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	if _, err := client.getBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.rateLimiter.TryAcquire(domain.EndpointAccount, 1) {
+		t.Error("expected account bucket blocked once the venue reports no requests left")
+	}
+	if !client.rateLimiter.TryAcquire(domain.EndpointOrderPlace, 1) {
+		t.Error("expected other categories unaffected")
+	}
+}
+
 func TestKCEXRestClient_GetBalances(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/v1/accounts" {
@@ -347,6 +503,46 @@ func TestKCEXRestClient_GetFeeTier(t *testing.T) {
 	}
 }
 
+func TestKCEXRestClient_GetInstruments(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/symbols":
+			json.NewEncoder(w).Encode(kcexOK([]map[string]interface{}{
+				{"symbol": "BTC-USDT", "priceIncrement": "0.1", "baseIncrement": "0.00000001", "baseMinSize": "0.00001", "minFunds": "0.1"},
+				{"symbol": "DOGE-USDT", "priceIncrement": "0.00001", "baseIncrement": "0.0001", "baseMinSize": "10", "minFunds": "0.1"},
+			}))
+		case "/api/v1/contracts/active":
+			json.NewEncoder(w).Encode(kcexOK([]map[string]interface{}{
+				{"symbol": "BTCUSDTM", "tickSize": 0.1, "lotSize": 1, "multiplier": 0.001},
+			}))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	instruments, err := client.getInstruments(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(instruments) != 2 {
+		t.Fatalf("expected 2 instruments (unmapped symbols skipped), got %d", len(instruments))
+	}
+
+	spot, perp := instruments[0], instruments[1]
+	if spot.Symbol != "BTC/USDT" || !spot.MinNotional.Equal(decimal.NewFromFloat(0.1)) {
+		t.Errorf("unexpected spot instrument %s min notional %s", spot.Symbol, spot.MinNotional)
+	}
+	if perp.Symbol != "BTCUSDT" || !perp.StepSize.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("unexpected perp instrument %s step %s", perp.Symbol, perp.StepSize)
+	}
+	if !perp.ContractMultiplier.Equal(decimal.NewFromFloat(0.001)) {
+		t.Errorf("expected contract multiplier 0.001, got %s", perp.ContractMultiplier)
+	}
+}
+
 func TestKCEXRestClient_APIError(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -372,17 +568,67 @@ func TestKCEXRestClient_APIError(t *testing.T) {
 	}
 }
 
-func TestKCEXRestClient_SignatureFormat(t *testing.T) {
-	rl := gateway.NewRateLimiter()
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
-	client := newRESTClient("https://api.kcex.com", "my-key", "my-secret", "my-pass", rl, logger)
+func TestKCEXRestClient_DuplicateClientOid(t *testing.T) {
+	var paths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		if r.Method == "POST" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": "400100",
+				"msg":  "The clientOid already exists",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+			"id":        "order-live",
+			"clientOid": "sig-leg-0",
+			"dealSize":  "0.05",
+			"dealFunds": "2500",
+			"isActive":  true,
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	_, err := client.placeOrder(context.Background(), domain.OrderRequest{
+		InternalID:     uuid.Must(uuid.NewV7()),
+		Symbol:         "BTC/USDT",
+		Side:           domain.SideBuy,
+		OrderType:      domain.OrderTypeLimit,
+		Price:          decimal.NewFromInt(50000),
+		Size:           decimal.NewFromFloat(0.1),
+		IdempotencyKey: "sig-leg-0",
+	})
+	if category := gateway.ErrorCategoryOf(err); category != gateway.ErrorDuplicateOrder {
+		t.Fatalf("expected a duplicate order rejection, got %v (%q)", err, category)
+	}
+
+	update, err := client.getOrderByClientOid(context.Background(), "BTC/USDT", "sig-leg-0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if update.VenueID != "order-live" || update.Status != domain.OrderStatusPartialFill ||
+		!update.AvgFillPrice.Equal(decimal.NewFromInt(50000)) {
+		t.Errorf("unexpected order %+v", update)
+	}
+	if last := paths[len(paths)-1]; last != "/api/v1/order/client-order/sig-leg-0" {
+		t.Errorf("expected the spot client order lookup, got %s", last)
+	}
 
-	sig := client.sign("1234567890POST/api/v1/orders{}")
+	client.getOrderByClientOid(context.Background(), "BTCUSDT", "sig-leg-1")
+	if last := paths[len(paths)-1]; last != "/api/v1/orders/byClientOid?clientOid=sig-leg-1" {
+		t.Errorf("expected the futures client order lookup, got %s", last)
+	}
+}
+
+func TestKCEXRestClient_SignatureFormat(t *testing.T) {
+	sig := sign("my-secret", "1234567890POST/api/v1/orders{}")
 	if sig == "" {
 		t.Error("expected non-empty signature")
 	}
 
-	passSig := client.signPassphrase()
+	passSig := signPassphrase("my-secret", "my-pass")
 	if passSig == "" {
 		t.Error("expected non-empty passphrase signature")
 	}
@@ -424,4 +670,244 @@ func TestKCEXRestClient_GetOrderBook(t *testing.T) {
 	if !book.Bids[0].Price.Equal(decimal.NewFromInt(49900)) {
 		t.Errorf("expected best bid 49900, got %s", book.Bids[0].Price)
 	}
+	if book.Sequence != 102931 {
+		t.Errorf("expected sequence 102931, got %d", book.Sequence)
+	}
+}
+
+func TestKCEXRestClient_GetAccountActivity(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v1/fills":
+			if q.Get("startAt") != "1709251200000" {
+				t.Errorf("expected startAt=1709251200000, got %s", q.Get("startAt"))
+			}
+			// Two pages, one fill each.
+			id := "trade-" + q.Get("currentPage")
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+				"currentPage": 1,
+				"totalPage":   2,
+				"items": []map[string]interface{}{
+					{"tradeId": id, "symbol": "BTC-USDT", "side": "sell", "price": "60000", "size": "0.1",
+						"fee": "6", "feeCurrency": "USDT", "createdAt": since.Add(time.Hour).UnixMilli()},
+				},
+			}))
+		case "/api/v1/deposits":
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+				"totalPage": 1,
+				"items": []map[string]interface{}{
+					{"walletTxId": "0xabc", "currency": "USDT", "amount": "1000", "fee": "0", "createdAt": since.UnixMilli()},
+				},
+			}))
+		case "/api/v1/withdrawals":
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+				"totalPage": 1,
+				"items": []map[string]interface{}{
+					{"id": "w-1", "currency": "BTC", "amount": "0.05", "fee": "0.0005", "createdAt": since.UnixMilli()},
+				},
+			}))
+		case "/api/v1/funding-history":
+			var list []map[string]interface{}
+			if q.Get("symbol") == "BTCUSDTM" {
+				list = append(list, map[string]interface{}{
+					"id": 77, "timePoint": since.Add(8 * time.Hour).UnixMilli(), "funding": "-1.25", "settleCurrency": "USDT",
+				})
+			}
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{"dataList": list, "hasMore": false}))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	activity, err := client.getAccountActivity(context.Background(), since, until)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byRef := make(map[string]domain.AccountActivity)
+	for _, a := range activity {
+		byRef[a.VenueRef] = a
+	}
+	if len(byRef) != 5 {
+		t.Fatalf("expected 5 activities, got %d: %+v", len(byRef), activity)
+	}
+
+	fill := byRef["trade-2"]
+	if fill.Type != domain.ActivityTrade || fill.Side != domain.SideSell || fill.Symbol != "BTC/USDT" {
+		t.Errorf("unexpected fill: %+v", fill)
+	}
+	if !fill.Fee.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("expected fee 6, got %s", fill.Fee)
+	}
+	if d := byRef["0xabc"]; d.Type != domain.ActivityDeposit || !d.Amount.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("unexpected deposit: %+v", d)
+	}
+	if w := byRef["w-1"]; w.Type != domain.ActivityWithdrawal || !w.Amount.Equal(decimal.NewFromFloat(-0.05)) {
+		t.Errorf("unexpected withdrawal: %+v", w)
+	}
+	if f := byRef["77"]; f.Type != domain.ActivityFunding || f.Symbol != "BTCUSDT" || !f.Amount.Equal(decimal.NewFromFloat(-1.25)) {
+		t.Errorf("unexpected funding: %+v", f)
+	}
+}
+
+func TestKCEXRestClient_RetriesTransientErrors(t *testing.T) {
+	var orderCalls, withdrawCalls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/orders":
+			orderCalls++
+			if orderCalls == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{"orderId": "o-1"}))
+		case "/api/v2/accounts/inner-transfer":
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{"orderId": "it-1"}))
+		case "/api/v1/withdrawals":
+			withdrawCalls++
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+	var codes []string
+	client.retrier.OnError = func(_ string, _ domain.EndpointCategory, code string) {
+		codes = append(codes, code)
+	}
+
+	_, err := client.placeOrder(context.Background(), domain.OrderRequest{
+		Symbol:         "BTC/USDT",
+		Side:           domain.SideBuy,
+		OrderType:      domain.OrderTypeLimit,
+		Price:          decimal.NewFromInt(60000),
+		Size:           decimal.NewFromFloat(0.1),
+		IdempotencyKey: "idem-1",
+	})
+	if err != nil {
+		t.Fatalf("expected the 502 to be retried, got %v", err)
+	}
+	if orderCalls != 2 {
+		t.Errorf("expected 2 order attempts, got %d", orderCalls)
+	}
+
+	// Without a client order ID a replay could place the order twice.
+	orderCalls = 0
+	_, err = client.placeOrder(context.Background(), domain.OrderRequest{
+		Symbol:    "BTC/USDT",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeLimit,
+		Price:     decimal.NewFromInt(60000),
+		Size:      decimal.NewFromFloat(0.1),
+	})
+	if err == nil || orderCalls != 1 {
+		t.Errorf("expected one failed attempt for an order without a client order ID, got %d (%v)", orderCalls, err)
+	}
+
+	_, err = client.withdraw(context.Background(), domain.WithdrawRequest{
+		Asset: "USDT", Network: "trc20", Address: "TXyz123", Amount: decimal.NewFromInt(250),
+	})
+	if err == nil {
+		t.Fatal("expected the withdrawal to fail")
+	}
+	if withdrawCalls != 1 {
+		t.Errorf("withdrawals must not be retried, got %d attempts", withdrawCalls)
+	}
+	if len(codes) != 3 || codes[0] != "502" || codes[1] != "502" || codes[2] != "502" {
+		t.Errorf("expected every 502 reported, got %v", codes)
+	}
+}
+
+func TestKCEXRestClient_Withdraw(t *testing.T) {
+	var paths []string
+	var innerBody, withdrawBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v2/accounts/inner-transfer":
+			json.NewDecoder(r.Body).Decode(&innerBody)
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{"orderId": "it-1"}))
+		case "/api/v1/withdrawals":
+			json.NewDecoder(r.Body).Decode(&withdrawBody)
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{"withdrawalId": "w-9"}))
+		case "/api/v1/withdrawals/w-9":
+			json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+				"id": "w-9", "currency": "USDT", "chain": "trc20", "address": "TXyz123", "amount": "250",
+				"fee": "1", "walletTxId": "0xdef", "status": "SUCCESS", "createdAt": 1709287200000, "updatedAt": 1709287800000,
+			}))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	transfer, err := client.withdraw(context.Background(), domain.WithdrawRequest{
+		Asset:   "USDT",
+		Network: "trc20",
+		Address: "TXyz123",
+		Memo:    "42",
+		Amount:  decimal.NewFromInt(250),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 2 || paths[0] != "/api/v2/accounts/inner-transfer" {
+		t.Fatalf("expected inner transfer before withdrawal, got %v", paths)
+	}
+	if innerBody["from"] != "trade" || innerBody["to"] != "main" || innerBody["amount"] != "250" {
+		t.Errorf("unexpected inner transfer body: %v", innerBody)
+	}
+	if withdrawBody["chain"] != "trc20" || withdrawBody["memo"] != "42" {
+		t.Errorf("unexpected withdraw body: %v", withdrawBody)
+	}
+	if transfer.ID != "w-9" || transfer.Status != domain.TransferStatusPending {
+		t.Errorf("unexpected transfer: %+v", transfer)
+	}
+
+	status, err := client.getTransferStatus(context.Background(), "w-9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Status != domain.TransferStatusCompleted || status.TxID != "0xdef" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if !status.Fee.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected fee 1, got %s", status.Fee)
+	}
+}
+
+func TestKCEXRestClient_GetDepositAddress(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/deposit-addresses" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("currency") != "USDT" || r.URL.Query().Get("chain") != "trc20" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(kcexOK(map[string]interface{}{
+			"address": "TXyz123", "memo": "", "chain": "TRC20",
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	addr, err := client.getDepositAddress(context.Background(), "USDT", "trc20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if addr.Address != "TXyz123" || addr.Network != "TRC20" {
+		t.Errorf("unexpected address: %+v", addr)
+	}
 }
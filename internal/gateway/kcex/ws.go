@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
 )
 
 // wsToken holds the connection details returned from the bullet endpoint.
@@ -35,8 +37,23 @@ type wsClient struct {
 	maxFailures   int
 
 	subscriptions []wsSubscription
+	subMu         sync.Mutex
+	onReconnect   func() // called after each successful reconnect, if set
+	state         gateway.WSState
+	egress        gateway.Egress
+	latency       gateway.WSLatency
 	pingInterval  time.Duration
 	stopPing      chan struct{}
+	pumpOnce      sync.Once
+	// pumpDone is closed when the read pump's context ends. Order updates
+	// wait for room on their channel until then rather than being dropped.
+	pumpDone <-chan struct{}
+
+	// private connections use a bullet-private token so that order
+	// topics can be subscribed alongside public market data.
+	private       bool
+	orderUpdateCh chan domain.OrderUpdate
+	fillNotional  map[string]decimal.Decimal // orderId → cumulative matched notional
 
 	orderBookChans map[string]chan domain.OrderBookDelta
 	tradeChans     map[string]chan domain.Trade
@@ -44,6 +61,9 @@ type wsClient struct {
 	chanMu         sync.RWMutex
 }
 
+// orderTopic is the private spot order stream (order lifecycle and matches).
+const orderTopic = "/spotMarket/tradeOrdersV2"
+
 type wsSubscription struct {
 	topic          string
 	privateChannel bool
@@ -61,6 +81,8 @@ func newWSClient(fallbackURL string, rest *restClient, logger *slog.Logger) *wsC
 		orderBookChans: make(map[string]chan domain.OrderBookDelta),
 		tradeChans:     make(map[string]chan domain.Trade),
 		fundingChans:   make(map[string]chan domain.FundingRate),
+		private:        rest.keys.Signed(),
+		fillNotional:   make(map[string]decimal.Decimal),
 	}
 }
 
@@ -69,7 +91,7 @@ func (ws *wsClient) connect(ctx context.Context) error {
 	defer ws.mu.Unlock()
 
 	// Get WS connection token from the REST API
-	token, err := ws.rest.getWSToken(ctx, false)
+	token, err := ws.rest.getWSToken(ctx, ws.private)
 	if err != nil {
 		ws.logger.Warn("failed to get KCEX WS token, using fallback URL", "error", err)
 		return ws.connectDirect(ctx, ws.fallbackURL)
@@ -86,9 +108,7 @@ func (ws *wsClient) connect(ctx context.Context) error {
 }
 
 func (ws *wsClient) connectDirect(ctx context.Context, url string) error {
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
+	dialer := ws.egress.WSDialer()
 
 	conn, _, err := dialer.DialContext(ctx, url, nil)
 	if err != nil {
@@ -115,6 +135,7 @@ func (ws *wsClient) connectDirect(ctx context.Context, url string) error {
 		ws.logger.Info("kcex websocket connected", "id", welcome.ID)
 	}
 
+	ws.state.SetConnected(true)
 	ws.stopPing = make(chan struct{})
 	go ws.pingLoop()
 
@@ -163,19 +184,34 @@ func (ws *wsClient) reconnect(ctx context.Context) error {
 			}
 			continue
 		}
-		for _, sub := range ws.subscriptions {
+		for _, sub := range ws.activeSubscriptions() {
 			if err := ws.sendSubscribe(sub.topic, sub.privateChannel); err != nil {
 				ws.logger.Warn("failed to resubscribe after reconnect",
 					"topic", sub.topic, "error", err)
 			}
 		}
+		if ws.onReconnect != nil {
+			ws.onReconnect()
+		}
 		return nil
 	}
 	return fmt.Errorf("failed to reconnect after %d attempts", ws.maxFailures)
 }
 
+// activeSubscriptions returns a copy of the subscriptions to restore after a
+// reconnect.
+func (ws *wsClient) activeSubscriptions() []wsSubscription {
+	ws.subMu.Lock()
+	defer ws.subMu.Unlock()
+	subs := make([]wsSubscription, len(ws.subscriptions))
+	copy(subs, ws.subscriptions)
+	return subs
+}
+
 func (ws *wsClient) subscribe(topic string, private bool) error {
+	ws.subMu.Lock()
 	ws.subscriptions = append(ws.subscriptions, wsSubscription{topic: topic, privateChannel: private})
+	ws.subMu.Unlock()
 	return ws.sendSubscribe(topic, private)
 }
 
@@ -197,6 +233,15 @@ func (ws *wsClient) sendSubscribe(topic string, private bool) error {
 	return ws.conn.WriteJSON(msg)
 }
 
+// startReadPump runs readPump once per client, however many subscriptions
+// need it.
+func (ws *wsClient) startReadPump(ctx context.Context) {
+	ws.pumpOnce.Do(func() {
+		ws.pumpDone = ctx.Done()
+		go ws.readPump(ctx)
+	})
+}
+
 func (ws *wsClient) readPump(ctx context.Context) {
 	for {
 		select {
@@ -216,6 +261,7 @@ func (ws *wsClient) readPump(ctx context.Context) {
 
 		_, message, err := conn.ReadMessage()
 		if err != nil {
+			ws.state.SetConnected(false)
 			ws.logger.Error("kcex websocket read error", "error", err)
 			if reconnErr := ws.reconnect(ctx); reconnErr != nil {
 				ws.logger.Error("kcex reconnection failed permanently", "error", reconnErr)
@@ -224,6 +270,7 @@ func (ws *wsClient) readPump(ctx context.Context) {
 			continue
 		}
 
+		ws.state.Touch()
 		ws.handleMessage(message)
 	}
 }
@@ -232,6 +279,7 @@ func (ws *wsClient) readPump(ctx context.Context) {
 // {"type":"message","topic":"/market/level2:BTC-USDT","subject":"trade.l2update","data":{...}}
 // {"type":"message","topic":"/market/match:BTC-USDT","subject":"trade.l3match","data":{...}}
 // {"type":"message","topic":"/contract/instrument:BTCUSDTM","subject":"funding.rate","data":{...}}
+// {"type":"message","topic":"/spotMarket/tradeOrdersV2","subject":"orderChange","channelType":"private","data":{...}}
 func (ws *wsClient) handleMessage(msg []byte) {
 	var raw struct {
 		Type    string          `json:"type"`
@@ -265,6 +313,8 @@ func (ws *wsClient) handleMessage(msg []byte) {
 	case matchPrefix(topic, "/contract/instrument:"):
 		symbol := topic[len("/contract/instrument:"):]
 		ws.handleFundingMessage(symbol, raw.Subject, raw.Data)
+	case topic == orderTopic:
+		ws.handleOrderChange(raw.Data)
 	}
 }
 
@@ -272,19 +322,21 @@ func matchPrefix(s, prefix string) bool {
 	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
 }
 
-func (ws *wsClient) handleOrderBookMessage(symbol string, data json.RawMessage) {
+func (ws *wsClient) handleOrderBookMessage(venueSymbol string, data json.RawMessage) {
 	ws.chanMu.RLock()
-	ch, ok := ws.orderBookChans[symbol]
+	ch, ok := ws.orderBookChans[venueSymbol]
 	ws.chanMu.RUnlock()
 	if !ok {
 		return
 	}
 
 	var update struct {
-		SequenceStart int64      `json:"sequenceStart"`
-		SequenceEnd   int64      `json:"sequenceEnd"`
+		SequenceStart int64 `json:"sequenceStart"`
+		SequenceEnd   int64 `json:"sequenceEnd"`
+		Checksum      int64 `json:"checksum"`
+		Time          int64 `json:"time"` // ms
 		Changes       struct {
-			Bids [][]string `json:"bids"`
+			Bids [][]string `json:"bids"` // [price, size, sequence]
 			Asks [][]string `json:"asks"`
 		} `json:"changes"`
 	}
@@ -295,119 +347,254 @@ func (ws *wsClient) handleOrderBookMessage(symbol string, data json.RawMessage)
 
 	delta := domain.OrderBookDelta{
 		Venue:          "kcex",
-		Symbol:         symbol,
+		Symbol:         internalSymbol(venueSymbol),
 		Sequence:       uint64(update.SequenceEnd),
-		LocalTimestamp:  time.Now(),
+		FirstSequence:  uint64(update.SequenceStart),
+		Checksum:       uint32(update.Checksum), // sent signed; keep the low 32 bits
+		LocalTimestamp: time.Now(),
+	}
+	if update.Time > 0 {
+		delta.VenueTimestamp = time.UnixMilli(update.Time)
 	}
 
-	for _, bid := range update.Changes.Bids {
-		if len(bid) >= 2 {
-			price, _ := decimal.NewFromString(bid[0])
-			size, _ := decimal.NewFromString(bid[1])
-			delta.Bids = append(delta.Bids, domain.PriceLevel{Price: price, Size: size})
-		}
+	// A malformed level drops the whole delta. The book then sees a sequence
+	// gap and resyncs, which beats applying a zero price.
+	var err error
+	if delta.Bids, err = parseLevels(update.Changes.Bids); err == nil {
+		delta.Asks, err = parseLevels(update.Changes.Asks)
 	}
-	for _, ask := range update.Changes.Asks {
-		if len(ask) >= 2 {
-			price, _ := decimal.NewFromString(ask[0])
-			size, _ := decimal.NewFromString(ask[1])
-			delta.Asks = append(delta.Asks, domain.PriceLevel{Price: price, Size: size})
-		}
+	if err != nil {
+		ws.logger.Warn("invalid kcex orderbook level, dropping update", "symbol", venueSymbol, "error", err)
+		return
 	}
 
+	ws.latency.Observe("book", delta.VenueTimestamp)
 	select {
 	case ch <- delta:
 	default:
-		ws.logger.Debug("kcex orderbook channel full, dropping update", "symbol", symbol)
+		ws.logger.Debug("kcex orderbook channel full, dropping update", "symbol", venueSymbol)
 	}
 }
 
-func (ws *wsClient) handleTradeMessage(symbol string, data json.RawMessage) {
+// parseLevels converts [price, size, ...] string tuples into price levels.
+func parseLevels(raw [][]string) ([]domain.PriceLevel, error) {
+	levels := make([]domain.PriceLevel, 0, len(raw))
+	for _, lvl := range raw {
+		if len(lvl) < 2 {
+			return nil, fmt.Errorf("level %v: want price and size", lvl)
+		}
+		price, err := decimal.NewFromString(lvl[0])
+		if err != nil {
+			return nil, fmt.Errorf("level price %q: %w", lvl[0], err)
+		}
+		size, err := decimal.NewFromString(lvl[1])
+		if err != nil {
+			return nil, fmt.Errorf("level size %q: %w", lvl[1], err)
+		}
+		levels = append(levels, domain.PriceLevel{Price: price, Size: size})
+	}
+	return levels, nil
+}
+
+// internalSymbol maps a KCEX spot or futures symbol back to the internal one.
+func internalSymbol(venueSymbol string) string {
+	if s := domain.ReverseMapSymbol(venueSymbol, domain.KCEXFuturesSymbolMap); s != venueSymbol {
+		return s
+	}
+	return domain.ReverseMapSymbol(venueSymbol, domain.KCEXSpotSymbolMap)
+}
+
+func (ws *wsClient) handleTradeMessage(venueSymbol string, data json.RawMessage) {
 	ws.chanMu.RLock()
-	ch, ok := ws.tradeChans[symbol]
+	ch, ok := ws.tradeChans[venueSymbol]
 	ws.chanMu.RUnlock()
 	if !ok {
 		return
 	}
 
 	var match struct {
-		Sequence    string `json:"sequence"`
-		Symbol      string `json:"symbol"`
-		Side        string `json:"side"`
-		Size        string `json:"size"`
-		Price       string `json:"price"`
-		TradeID     string `json:"tradeId"`
-		TakerOrdID  string `json:"takerOrderId"`
-		MakerOrdID  string `json:"makerOrderId"`
-		Time        string `json:"time"`
+		Sequence   string `json:"sequence"`
+		Symbol     string `json:"symbol"`
+		Side       string `json:"side"` // taker side
+		Size       string `json:"size"`
+		Price      string `json:"price"`
+		TradeID    string `json:"tradeId"`
+		TakerOrdID string `json:"takerOrderId"`
+		MakerOrdID string `json:"makerOrderId"`
+		Time       string `json:"time"` // ns
 	}
 	if err := json.Unmarshal(data, &match); err != nil {
 		ws.logger.Warn("failed to parse kcex trade match", "error", err)
 		return
 	}
 
+	price, err := decimal.NewFromString(match.Price)
+	if err != nil {
+		ws.logger.Warn("invalid kcex trade price", "symbol", venueSymbol, "price", match.Price)
+		return
+	}
+	size, err := decimal.NewFromString(match.Size)
+	if err != nil {
+		ws.logger.Warn("invalid kcex trade size", "symbol", venueSymbol, "size", match.Size)
+		return
+	}
+
 	side := domain.SideBuy
 	if match.Side == "sell" {
 		side = domain.SideSell
 	}
 
 	trade := domain.Trade{
-		Venue:   "kcex",
-		Symbol:  symbol,
-		Side:    side,
-		TradeID: match.TradeID,
+		Venue:     "kcex",
+		Symbol:    internalSymbol(venueSymbol),
+		Price:     price,
+		Size:      size,
+		Side:      side,
+		TradeID:   match.TradeID,
+		Timestamp: time.Now(),
 	}
-	trade.Price, _ = decimal.NewFromString(match.Price)
-	trade.Size, _ = decimal.NewFromString(match.Size)
-
-	ts, err := decimal.NewFromString(match.Time)
-	if err == nil {
-		trade.Timestamp = time.UnixMilli(ts.IntPart())
-	} else {
-		trade.Timestamp = time.Now()
+	if ns, err := strconv.ParseInt(match.Time, 10, 64); err == nil && ns > 0 {
+		trade.Timestamp = time.Unix(0, ns)
 	}
 
 	select {
 	case ch <- trade:
 	default:
-		ws.logger.Debug("kcex trade channel full, dropping update", "symbol", symbol)
+		ws.logger.Debug("kcex trade channel full, dropping update", "symbol", venueSymbol)
 	}
 }
 
-func (ws *wsClient) handleFundingMessage(symbol, subject string, data json.RawMessage) {
+func (ws *wsClient) handleFundingMessage(venueSymbol, subject string, data json.RawMessage) {
 	if subject != "funding.rate" {
 		return
 	}
 
 	ws.chanMu.RLock()
-	ch, ok := ws.fundingChans[symbol]
+	ch, ok := ws.fundingChans[venueSymbol]
 	ws.chanMu.RUnlock()
 	if !ok {
 		return
 	}
 
 	var update struct {
-		Granularity int     `json:"granularity"`
-		FundingRate float64 `json:"fundingRate"`
-		Timestamp   int64   `json:"timestamp"`
+		Granularity int         `json:"granularity"`
+		FundingRate json.Number `json:"fundingRate"`
+		Timestamp   int64       `json:"timestamp"` // ms
 	}
 	if err := json.Unmarshal(data, &update); err != nil {
 		ws.logger.Warn("failed to parse kcex funding rate", "error", err)
 		return
 	}
+	// Parsed from the literal so rates like 0.0001 keep their exact value.
+	fundingRate, err := decimal.NewFromString(update.FundingRate.String())
+	if err != nil {
+		ws.logger.Warn("invalid kcex funding rate", "symbol", venueSymbol, "rate", update.FundingRate)
+		return
+	}
 
 	rate := domain.FundingRate{
 		Venue:     "kcex",
-		Symbol:    symbol,
+		Symbol:    internalSymbol(venueSymbol),
+		Rate:      fundingRate,
 		Timestamp: time.UnixMilli(update.Timestamp),
 	}
-	rate.Rate = decimal.NewFromFloat(update.FundingRate)
 
 	select {
 	case ch <- rate:
 	default:
-		ws.logger.Debug("kcex funding channel full, dropping update", "symbol", symbol)
+		ws.logger.Debug("kcex funding channel full, dropping update", "symbol", venueSymbol)
+	}
+}
+
+// handleOrderChange converts a private order event into an OrderUpdate.
+// KCEX reports each match's price and size but not the running average, so
+// the matched notional is accumulated per order until it reaches a terminal
+// state.
+func (ws *wsClient) handleOrderChange(data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch := ws.orderUpdateCh
+	ws.chanMu.RUnlock()
+	if ch == nil {
+		return
+	}
+
+	var event struct {
+		OrderID    string `json:"orderId"`
+		ClientOid  string `json:"clientOid"`
+		Symbol     string `json:"symbol"`
+		Type       string `json:"type"`
+		Status     string `json:"status"`
+		Size       string `json:"size"`
+		FilledSize string `json:"filledSize"`
+		MatchPrice string `json:"matchPrice"`
+		MatchSize  string `json:"matchSize"`
+		Ts         int64  `json:"ts"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		ws.logger.Warn("failed to parse kcex order change", "error", err)
+		return
+	}
+
+	update := domain.OrderUpdate{
+		Venue:         "kcex",
+		VenueID:       event.OrderID,
+		ClientOrderID: event.ClientOid,
+		Timestamp:     time.Now(),
+	}
+	if event.Ts > 0 {
+		// ts is in nanoseconds
+		update.Timestamp = time.Unix(0, event.Ts)
+	}
+	update.FilledSize, _ = domain.ParseDecimal(event.FilledSize)
+
+	if event.Type == "match" {
+		matchPrice, _ := domain.ParseDecimal(event.MatchPrice)
+		matchSize, _ := domain.ParseDecimal(event.MatchSize)
+		ws.fillNotional[event.OrderID] = ws.fillNotional[event.OrderID].Add(matchPrice.Mul(matchSize))
+	}
+	if notional, ok := ws.fillNotional[event.OrderID]; ok && update.FilledSize.IsPositive() {
+		update.AvgFillPrice = notional.Div(update.FilledSize)
+	}
+
+	size, _ := domain.ParseDecimal(event.Size)
+	switch event.Type {
+	case "open":
+		update.Status = domain.OrderStatusAcknowledged
+	case "match", "update":
+		update.Status = domain.OrderStatusPartialFill
+		if !update.FilledSize.IsPositive() {
+			update.Status = domain.OrderStatusAcknowledged
+		}
+	case "filled":
+		update.Status = domain.OrderStatusFilled
+	case "canceled":
+		update.Status = domain.OrderStatusCancelled
+	default:
+		return
+	}
+	if update.Status == domain.OrderStatusPartialFill && size.IsPositive() && update.FilledSize.GreaterThanOrEqual(size) {
+		update.Status = domain.OrderStatusFilled
+	}
+	if update.Status.IsTerminal() {
+		delete(ws.fillNotional, event.OrderID)
+	}
+
+	// An order update is never dropped: a lost fill or cancel would leave
+	// the order manager's view of the order wrong until the next
+	// reconciliation.
+	select {
+	case ch <- update:
+	case <-ws.pumpDone:
+	}
+}
+
+func (ws *wsClient) subscribeOrderUpdates() <-chan domain.OrderUpdate {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	if ws.orderUpdateCh == nil {
+		ws.orderUpdateCh = make(chan domain.OrderUpdate, 256)
 	}
+	return ws.orderUpdateCh
 }
 
 func (ws *wsClient) subscribeOrderBook(symbol string) <-chan domain.OrderBookDelta {
@@ -437,6 +624,7 @@ func (ws *wsClient) subscribeFunding(symbol string) <-chan domain.FundingRate {
 func (ws *wsClient) close() error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
+	ws.state.SetConnected(false)
 
 	if ws.stopPing != nil {
 		close(ws.stopPing)
@@ -0,0 +1,161 @@
+package kcex
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+func TestKCEXWSClient_HandleOrderChange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", newRESTClient("", "test-api-key", "", "", gateway.NewRateLimiter(), logger), logger)
+	ch := ws.subscribeOrderUpdates()
+
+	msgs := []string{
+		`{"type":"message","topic":"/spotMarket/tradeOrdersV2","data":{"orderId":"o1","clientOid":"c1","type":"open","size":"1","filledSize":"0","ts":1700000000000000000}}`,
+		`{"type":"message","topic":"/spotMarket/tradeOrdersV2","data":{"orderId":"o1","clientOid":"c1","type":"match","size":"1","filledSize":"0.4","matchPrice":"100","matchSize":"0.4"}}`,
+		`{"type":"message","topic":"/spotMarket/tradeOrdersV2","data":{"orderId":"o1","clientOid":"c1","type":"match","size":"1","filledSize":"1","matchPrice":"110","matchSize":"0.6"}}`,
+	}
+	for _, m := range msgs {
+		ws.handleMessage([]byte(m))
+	}
+
+	open := <-ch
+	if open.Status != domain.OrderStatusAcknowledged || open.VenueID != "o1" || open.ClientOrderID != "c1" {
+		t.Errorf("unexpected open update: %+v", open)
+	}
+	if open.Timestamp.UnixMilli() != 1700000000000 {
+		t.Errorf("expected ns timestamp to be parsed, got %v", open.Timestamp)
+	}
+
+	partial := <-ch
+	if partial.Status != domain.OrderStatusPartialFill || !partial.AvgFillPrice.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("unexpected partial update: %+v", partial)
+	}
+
+	filled := <-ch
+	if filled.Status != domain.OrderStatusFilled || !filled.FilledSize.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("unexpected final update: %+v", filled)
+	}
+	// (0.4*100 + 0.6*110) / 1 = 106
+	if !filled.AvgFillPrice.Equal(decimal.NewFromInt(106)) {
+		t.Errorf("expected avg fill price 106, got %s", filled.AvgFillPrice)
+	}
+	if _, ok := ws.fillNotional["o1"]; ok {
+		t.Error("expected fill notional to be cleared on terminal state")
+	}
+}
+
+func TestKCEXWSClient_OrderUpdatesWaitForRoom(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", newRESTClient("", "test-api-key", "", "", gateway.NewRateLimiter(), logger), logger)
+	ch := ws.subscribeOrderUpdates()
+
+	// More updates than the channel holds: none may be dropped.
+	const n = 300
+	go func() {
+		for i := 0; i < n; i++ {
+			ws.handleMessage([]byte(fmt.Sprintf(`{"type":"message","topic":"/spotMarket/tradeOrdersV2","data":{"orderId":"o%d","type":"open","size":"1","filledSize":"0"}}`, i)))
+		}
+	}()
+	for i := 0; i < n; i++ {
+		select {
+		case update := <-ch:
+			if want := fmt.Sprintf("o%d", i); update.VenueID != want {
+				t.Fatalf("update %d is for %s, want %s", i, update.VenueID, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of %d order updates delivered", i, n)
+		}
+	}
+}
+
+func TestKCEXWSClient_HandleOrderBookMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", newRESTClient("", "", "", "", gateway.NewRateLimiter(), logger), logger)
+	ch := ws.subscribeOrderBook("BTC-USDT")
+
+	ws.handleMessage([]byte(`{"type":"message","topic":"/market/level2:BTC-USDT","subject":"trade.l2update","data":{"sequenceStart":101,"sequenceEnd":103,"checksum":-1194256470,"time":1700000000123,"changes":{"bids":[["49900.10","0.500"]],"asks":[]}}}`))
+
+	delta := <-ch
+	if delta.Symbol != "BTC/USDT" {
+		t.Errorf("expected internal symbol BTC/USDT, got %s", delta.Symbol)
+	}
+	if delta.VenueTimestamp.UnixMilli() != 1700000000123 {
+		t.Errorf("expected venue timestamp parsed, got %v", delta.VenueTimestamp)
+	}
+	if delta.FirstSequence != 101 || delta.Sequence != 103 {
+		t.Errorf("expected sequence range 101-103, got %d-%d", delta.FirstSequence, delta.Sequence)
+	}
+	if delta.Checksum != 3100710826 {
+		t.Errorf("expected signed checksum kept as its low 32 bits, got %d", delta.Checksum)
+	}
+	if len(delta.Bids) != 1 || delta.Bids[0].Price.String() != "49900.1" {
+		t.Errorf("unexpected bids: %v", delta.Bids)
+	}
+}
+
+func TestKCEXWSClient_DropsMalformedBookLevels(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	ws := newWSClient("", newRESTClient("", "", "", "", gateway.NewRateLimiter(), logger), logger)
+	ch := ws.subscribeOrderBook("BTC-USDT")
+
+	ws.handleMessage([]byte(`{"type":"message","topic":"/market/level2:BTC-USDT","data":{"sequenceStart":5,"sequenceEnd":5,"changes":{"bids":[["49900","0.5"]],"asks":[["oops","1"]]}}}`))
+
+	select {
+	case delta := <-ch:
+		t.Errorf("expected the delta dropped, got %+v", delta)
+	default:
+	}
+}
+
+func TestKCEXWSClient_HandleTradeMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", newRESTClient("", "", "", "", gateway.NewRateLimiter(), logger), logger)
+	ch := ws.subscribeTrades("ETH-USDT")
+
+	ws.handleMessage([]byte(`{"type":"message","topic":"/market/match:ETH-USDT","subject":"trade.l3match","data":{"sequence":"1545896669145","symbol":"ETH-USDT","side":"sell","size":"0.25","price":"3012.45","tradeId":"5c24c5da03aa673885cd67aa","time":"1700000000123456789"}}`))
+
+	trade := <-ch
+	if trade.Symbol != "ETH/USDT" || trade.Side != domain.SideSell || trade.TradeID != "5c24c5da03aa673885cd67aa" {
+		t.Errorf("unexpected trade: %+v", trade)
+	}
+	if !trade.Price.Equal(decimal.RequireFromString("3012.45")) || !trade.Size.Equal(decimal.RequireFromString("0.25")) {
+		t.Errorf("expected 0.25 @ 3012.45, got %s @ %s", trade.Size, trade.Price)
+	}
+	if trade.Timestamp.UnixNano() != 1700000000123456789 {
+		t.Errorf("expected ns trade time, got %v", trade.Timestamp)
+	}
+}
+
+func TestKCEXWSClient_HandleFundingMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", newRESTClient("", "", "", "", gateway.NewRateLimiter(), logger), logger)
+	ch := ws.subscribeFunding("BTCUSDTM")
+
+	ws.handleMessage([]byte(`{"type":"message","topic":"/contract/instrument:BTCUSDTM","subject":"mark.index.price","data":{"markPrice":60000}}`))
+	ws.handleMessage([]byte(`{"type":"message","topic":"/contract/instrument:BTCUSDTM","subject":"funding.rate","data":{"granularity":60000,"fundingRate":0.000123,"timestamp":1700000000000}}`))
+
+	rate := <-ch
+	if rate.Symbol != "BTCUSDT" {
+		t.Errorf("expected internal perp symbol BTCUSDT, got %s", rate.Symbol)
+	}
+	if !rate.Rate.Equal(decimal.RequireFromString("0.000123")) || rate.Rate.String() != "0.000123" {
+		t.Errorf("expected exact rate 0.000123, got %s", rate.Rate)
+	}
+	if rate.Timestamp.UnixMilli() != 1700000000000 {
+		t.Errorf("expected funding timestamp parsed, got %v", rate.Timestamp)
+	}
+	select {
+	case extra := <-ch:
+		t.Errorf("expected only funding.rate messages, got %+v", extra)
+	default:
+	}
+}
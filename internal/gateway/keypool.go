@@ -0,0 +1,234 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// KeyRole restricts what an API key in a KeyPool is used for.
+type KeyRole string
+
+const (
+	// KeyRoleAny keys serve every request.
+	KeyRoleAny KeyRole = ""
+	// KeyRoleTrade keys only place and cancel orders, so a leaked read
+	// key cannot trade and reads never eat into the order budget.
+	KeyRoleTrade KeyRole = "trade"
+	// KeyRoleRead keys serve everything except order placement and
+	// cancellation.
+	KeyRoleRead KeyRole = "read"
+)
+
+// APIKey is one set of venue credentials. Weight sets the key's share of the
+// requests it is eligible for; zero counts as one.
+type APIKey struct {
+	Name       string
+	Key        string
+	Secret     string
+	Passphrase string
+	Weight     int
+	Role       KeyRole
+}
+
+// KeyRotator is implemented by gateways that can spread their REST calls
+// over several API keys. Set the keys before Connect.
+type KeyRotator interface {
+	SetAPIKeys(keys []APIKey) error
+}
+
+// KeyLease is the key picked for one request, with the limiter that the
+// response has to be reported to.
+type KeyLease struct {
+	APIKey
+	Limiter *RateLimiter
+}
+
+// KeyPool hands out API keys for REST requests by smooth weighted
+// round-robin. Venues budget most endpoints per key, so with several keys
+// each gets a clone of the venue's rate limiter; public data is limited per
+// IP and stays on the shared one. A pool of one key uses the shared limiter
+// for everything.
+type KeyPool struct {
+	shared *RateLimiter
+
+	mu   sync.Mutex
+	keys []*pooledKey
+}
+
+type pooledKey struct {
+	APIKey
+	limiter *RateLimiter
+	current int // smooth weighted round-robin state
+}
+
+// NewKeyPool returns a pool holding key alone. key may be empty for a
+// gateway without credentials.
+func NewKeyPool(shared *RateLimiter, key APIKey) *KeyPool {
+	return &KeyPool{
+		shared: shared,
+		keys:   []*pooledKey{{APIKey: key, limiter: shared}},
+	}
+}
+
+// SetKeys replaces the pool's keys. At least one key must be able to trade
+// and one to read. Call before the gateway sends requests.
+func (p *KeyPool) SetKeys(keys []APIKey) error {
+	if len(keys) == 0 {
+		return errors.New("no API keys")
+	}
+	var trade, read bool
+	pooled := make([]*pooledKey, 0, len(keys))
+	for _, k := range keys {
+		if k.Key == "" || k.Secret == "" {
+			return fmt.Errorf("API key %q has no key or secret", k.Name)
+		}
+		switch k.Role {
+		case KeyRoleAny:
+			trade, read = true, true
+		case KeyRoleTrade:
+			trade = true
+		case KeyRoleRead:
+			read = true
+		default:
+			return fmt.Errorf("API key %q has unknown role %q", k.Name, k.Role)
+		}
+		if k.Weight <= 0 {
+			k.Weight = 1
+		}
+		limiter := p.shared
+		if len(keys) > 1 {
+			limiter = p.shared.Clone()
+		}
+		pooled = append(pooled, &pooledKey{APIKey: k, limiter: limiter})
+	}
+	if !trade || !read {
+		return errors.New("API keys need at least one key that can trade and one that can read")
+	}
+
+	p.mu.Lock()
+	p.keys = pooled
+	p.mu.Unlock()
+	return nil
+}
+
+// Signed reports whether the pool holds credentials.
+func (p *KeyPool) Signed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.keys[0].Key != ""
+}
+
+// Acquire picks the key for a request in category and takes weight from its
+// budget. The key next in rotation goes first; if its budget is spent, the
+// other eligible keys are tried before waiting on it.
+func (p *KeyPool) Acquire(ctx context.Context, category domain.EndpointCategory, weight int) (KeyLease, error) {
+	candidates := p.rotate(category)
+	if category == domain.EndpointPublicData {
+		lease := KeyLease{APIKey: candidates[0].APIKey, Limiter: p.shared}
+		return lease, p.shared.Acquire(ctx, category, weight)
+	}
+	for _, k := range candidates {
+		if k.limiter.TryAcquire(category, weight) {
+			return KeyLease{APIKey: k.APIKey, Limiter: k.limiter}, nil
+		}
+	}
+	k := candidates[0]
+	return KeyLease{APIKey: k.APIKey, Limiter: k.limiter}, k.limiter.Acquire(ctx, category, weight)
+}
+
+// rotate advances the rotation among the keys eligible for category and
+// returns them, the selected key first.
+func (p *KeyPool) rotate(category domain.EndpointCategory) []*pooledKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	eligible := make([]*pooledKey, 0, len(p.keys))
+	total := 0
+	best := -1
+	for _, k := range p.keys {
+		if !k.serves(category) {
+			continue
+		}
+		k.current += k.Weight
+		total += k.Weight
+		if best < 0 || k.current > eligible[best].current {
+			best = len(eligible)
+		}
+		eligible = append(eligible, k)
+	}
+	if best < 0 {
+		// SetKeys makes sure every category is served; keep the first key
+		// rather than fail.
+		return p.keys[:1]
+	}
+	eligible[best].current -= total
+	eligible[0], eligible[best] = eligible[best], eligible[0]
+	return eligible
+}
+
+// serves reports whether the key may be used for category. A pool of one
+// key, which may have no role, serves everything.
+func (k *pooledKey) serves(category domain.EndpointCategory) bool {
+	switch k.Role {
+	case KeyRoleTrade:
+		return category == domain.EndpointOrderPlace || category == domain.EndpointOrderCancel
+	case KeyRoleRead:
+		return category != domain.EndpointOrderPlace && category != domain.EndpointOrderCancel
+	}
+	return true
+}
+
+// Status reports the budget left per category, summed over the keys that
+// serve it. A category is only reported blocked when every key is, until
+// the first of them is released.
+func (p *KeyPool) Status() []domain.RateLimitStatus {
+	p.mu.Lock()
+	keys := append([]*pooledKey(nil), p.keys...)
+	p.mu.Unlock()
+
+	out := p.shared.Status()
+	if len(keys) == 1 && keys[0].limiter == p.shared {
+		return out
+	}
+
+	perKey := make([]map[domain.EndpointCategory]domain.RateLimitStatus, len(keys))
+	for i, k := range keys {
+		perKey[i] = make(map[domain.EndpointCategory]domain.RateLimitStatus)
+		for _, st := range k.limiter.Status() {
+			perKey[i][st.Category] = st
+		}
+	}
+	now := time.Now()
+	for i, st := range out {
+		if st.Category == domain.EndpointPublicData {
+			continue
+		}
+		sum := domain.RateLimitStatus{Category: st.Category}
+		blocked := true
+		for j, k := range keys {
+			if !k.serves(st.Category) {
+				continue
+			}
+			ks := perKey[j][st.Category]
+			sum.Capacity += ks.Capacity
+			if now.Before(ks.BlockedUntil) {
+				if sum.BlockedUntil.IsZero() || ks.BlockedUntil.Before(sum.BlockedUntil) {
+					sum.BlockedUntil = ks.BlockedUntil
+				}
+				continue
+			}
+			blocked = false
+			sum.Remaining += ks.Remaining
+		}
+		if !blocked {
+			sum.BlockedUntil = time.Time{}
+		}
+		out[i] = sum
+	}
+	return out
+}
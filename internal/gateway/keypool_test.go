@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func testPool(t *testing.T, capacity int, keys ...APIKey) *KeyPool {
+	t.Helper()
+	rl := NewRateLimiter()
+	for _, c := range []domain.EndpointCategory{domain.EndpointPublicData, domain.EndpointPrivateData, domain.EndpointOrderPlace} {
+		rl.AddBucket(c, capacity, 1)
+	}
+	pool := NewKeyPool(rl, APIKey{Key: "main", Secret: "s"})
+	if err := pool.SetKeys(keys); err != nil {
+		t.Fatalf("set keys: %v", err)
+	}
+	return pool
+}
+
+func TestKeyPoolWeightedRotation(t *testing.T) {
+	pool := testPool(t, 100,
+		APIKey{Name: "a", Key: "a", Secret: "s", Weight: 3},
+		APIKey{Name: "b", Key: "b", Secret: "s", Weight: 1},
+	)
+
+	var seq string
+	for i := 0; i < 8; i++ {
+		lease, err := pool.Acquire(context.Background(), domain.EndpointPrivateData, 1)
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		seq += lease.Name
+	}
+	if seq != "aabaaaba" {
+		t.Errorf("expected smooth 3:1 rotation, got %s", seq)
+	}
+}
+
+func TestKeyPoolRoles(t *testing.T) {
+	pool := testPool(t, 100,
+		APIKey{Name: "trade", Key: "t", Secret: "s", Role: KeyRoleTrade},
+		APIKey{Name: "read", Key: "r", Secret: "s", Role: KeyRoleRead},
+	)
+
+	for i := 0; i < 3; i++ {
+		if lease, _ := pool.Acquire(context.Background(), domain.EndpointOrderPlace, 1); lease.Name != "trade" {
+			t.Errorf("expected orders on the trade key, got %q", lease.Name)
+		}
+		if lease, _ := pool.Acquire(context.Background(), domain.EndpointPrivateData, 1); lease.Name != "read" {
+			t.Errorf("expected reads on the read key, got %q", lease.Name)
+		}
+	}
+
+	err := NewKeyPool(NewRateLimiter(), APIKey{}).SetKeys([]APIKey{{Name: "read", Key: "r", Secret: "s", Role: KeyRoleRead}})
+	if err == nil {
+		t.Error("expected a pool without a trading key rejected")
+	}
+}
+
+func TestKeyPoolBudgetPerKey(t *testing.T) {
+	pool := testPool(t, 2,
+		APIKey{Name: "a", Key: "a", Secret: "s"},
+		APIKey{Name: "b", Key: "b", Secret: "s"},
+	)
+
+	// Each key has its own budget of 2, so four requests go through.
+	used := map[string]int{}
+	for i := 0; i < 4; i++ {
+		lease, err := pool.Acquire(context.Background(), domain.EndpointOrderPlace, 1)
+		if err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+		used[lease.Name]++
+	}
+	if used["a"] != 2 || used["b"] != 2 {
+		t.Errorf("expected both budgets used, got %v", used)
+	}
+
+	for _, st := range pool.Status() {
+		switch st.Category {
+		case domain.EndpointOrderPlace:
+			if st.Capacity != 4 || st.Remaining >= 1 {
+				t.Errorf("expected the order budget summed over keys and spent, got %+v", st)
+			}
+		case domain.EndpointPublicData:
+			if st.Capacity != 2 {
+				t.Errorf("expected public data on the shared budget, got %+v", st)
+			}
+		}
+	}
+
+	// A throttled key is skipped while the other has budget left.
+	pool.keys[0].limiter.Throttle(domain.EndpointPrivateData, 0)
+	for i := 0; i < 2; i++ {
+		lease, err := pool.Acquire(context.Background(), domain.EndpointPrivateData, 1)
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		if lease.Key == pool.keys[0].Key {
+			t.Errorf("expected the throttled key %q skipped", lease.Name)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// LatencyKind separates REST round trips from WebSocket delivery delays.
+type LatencyKind string
+
+const (
+	// LatencyREST is the time from sending a request to receiving the
+	// response headers, for one attempt. Rate limiter waits are excluded.
+	LatencyREST LatencyKind = "rest"
+	// LatencyWS is the time from the venue stamping a stream event to it
+	// being parsed here, corrected for venue clock offset where it is known.
+	LatencyWS LatencyKind = "ws"
+	// LatencyRateLimit is the time a REST request waited for its rate
+	// limiter bucket before being sent.
+	LatencyRateLimit LatencyKind = "rate_limit"
+)
+
+// LatencyObserver is told about one latency sample. endpoint is the endpoint
+// category for REST and rate limit samples and the stream ("book", "trade")
+// for WS ones.
+type LatencyObserver func(venue string, kind LatencyKind, endpoint string, elapsed time.Duration)
+
+// LatencyReporter is implemented by gateways that measure their REST and
+// WebSocket latency. Set the observer before Connect.
+type LatencyReporter interface {
+	SetLatencyObserver(fn LatencyObserver)
+}
+
+// ObserveLatency reports one REST round trip for category, if an observer
+// is set.
+func (r RESTRetrier) ObserveLatency(category domain.EndpointCategory, elapsed time.Duration) {
+	if r.OnLatency != nil {
+		r.OnLatency(r.Venue, LatencyREST, string(category), elapsed)
+	}
+}
+
+// ObserveRateLimitWait reports how long a request waited for category's
+// rate limiter bucket, if an observer is set.
+func (r RESTRetrier) ObserveRateLimitWait(category domain.EndpointCategory, waited time.Duration) {
+	if r.OnLatency != nil {
+		r.OnLatency(r.Venue, LatencyRateLimit, string(category), waited)
+	}
+}
+
+// WSLatency reports how long a stream event took to arrive, given the venue's
+// timestamp on it. A nil WSLatency, or an event the venue did not stamp,
+// records nothing.
+type WSLatency func(stream string, venueTime time.Time)
+
+// Observe records one event on stream.
+func (f WSLatency) Observe(stream string, venueTime time.Time) {
+	if f != nil && venueTime.UnixMilli() > 0 {
+		f(stream, venueTime)
+	}
+}
+
+// LatencyStats summarises the samples in one window.
+type LatencyStats struct {
+	Venue    string      `json:"venue"`
+	Kind     LatencyKind `json:"kind"`
+	Endpoint string      `json:"endpoint"`
+	Samples  int         `json:"samples"`
+	P50Ms    float64     `json:"p50_ms"`
+	P99Ms    float64     `json:"p99_ms"`
+	MaxMs    float64     `json:"max_ms"`
+}
+
+type latencyKey struct {
+	venue    string
+	kind     LatencyKind
+	endpoint string
+}
+
+type latencySample struct {
+	at      time.Time
+	elapsed time.Duration
+}
+
+// LatencyTracker keeps a rolling window of latency samples per venue, kind
+// and endpoint: at most size samples, none older than maxAge. Components
+// that order legs by venue speed query it with Stats. It is safe for
+// concurrent use.
+type LatencyTracker struct {
+	mu      sync.Mutex
+	size    int
+	maxAge  time.Duration
+	windows map[latencyKey]*latencyWindow
+}
+
+type latencyWindow struct {
+	samples []latencySample
+	next    int
+}
+
+// NewLatencyTracker creates a tracker keeping size samples per window, up
+// to maxAge old.
+func NewLatencyTracker(size int, maxAge time.Duration) *LatencyTracker {
+	return &LatencyTracker{size: size, maxAge: maxAge, windows: make(map[latencyKey]*latencyWindow)}
+}
+
+// Observe records one sample. Its signature matches LatencyObserver.
+func (t *LatencyTracker) Observe(venue string, kind LatencyKind, endpoint string, elapsed time.Duration) {
+	key := latencyKey{venue, kind, endpoint}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w, ok := t.windows[key]
+	if !ok {
+		w = &latencyWindow{samples: make([]latencySample, 0, t.size)}
+		t.windows[key] = w
+	}
+	s := latencySample{at: time.Now(), elapsed: elapsed}
+	if len(w.samples) < t.size {
+		w.samples = append(w.samples, s)
+		return
+	}
+	w.samples[w.next] = s
+	w.next = (w.next + 1) % t.size
+}
+
+// Stats returns the rolling percentiles for one venue endpoint. ok is false
+// when there are no recent samples.
+func (t *LatencyTracker) Stats(venue string, kind LatencyKind, endpoint string) (LatencyStats, bool) {
+	t.mu.Lock()
+	w, ok := t.windows[latencyKey{venue, kind, endpoint}]
+	var recent []time.Duration
+	if ok {
+		recent = w.recent(time.Now().Add(-t.maxAge))
+	}
+	t.mu.Unlock()
+
+	if len(recent) == 0 {
+		return LatencyStats{}, false
+	}
+	return summarise(latencyKey{venue, kind, endpoint}, recent), true
+}
+
+// All returns the stats of every window with recent samples, ordered by
+// venue, kind and endpoint.
+func (t *LatencyTracker) All() []LatencyStats {
+	cutoff := time.Now().Add(-t.maxAge)
+	t.mu.Lock()
+	recent := make(map[latencyKey][]time.Duration, len(t.windows))
+	for key, w := range t.windows {
+		if r := w.recent(cutoff); len(r) > 0 {
+			recent[key] = r
+		}
+	}
+	t.mu.Unlock()
+
+	stats := make([]LatencyStats, 0, len(recent))
+	for key, r := range recent {
+		stats = append(stats, summarise(key, r))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Venue != b.Venue {
+			return a.Venue < b.Venue
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Endpoint < b.Endpoint
+	})
+	return stats
+}
+
+// recent copies the samples taken after cutoff.
+func (w *latencyWindow) recent(cutoff time.Time) []time.Duration {
+	out := make([]time.Duration, 0, len(w.samples))
+	for _, s := range w.samples {
+		if s.at.After(cutoff) {
+			out = append(out, s.elapsed)
+		}
+	}
+	return out
+}
+
+// summarise computes nearest-rank percentiles; it sorts samples in place.
+func summarise(key latencyKey, samples []time.Duration) LatencyStats {
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	rank := func(q float64) float64 {
+		i := int(math.Ceil(q*float64(len(samples)))) - 1
+		i = max(0, min(i, len(samples)-1))
+		return ms(samples[i])
+	}
+	return LatencyStats{
+		Venue:    key.venue,
+		Kind:     key.kind,
+		Endpoint: key.endpoint,
+		Samples:  len(samples),
+		P50Ms:    rank(0.50),
+		P99Ms:    rank(0.99),
+		MaxMs:    ms(samples[len(samples)-1]),
+	}
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyTrackerPercentiles(t *testing.T) {
+	tracker := NewLatencyTracker(100, time.Minute)
+	for i := 1; i <= 100; i++ {
+		tracker.Observe("okx", LatencyREST, "order", time.Duration(i)*time.Millisecond)
+	}
+	s, ok := tracker.Stats("okx", LatencyREST, "order")
+	if !ok {
+		t.Fatal("expected stats for a window with samples")
+	}
+	if s.Samples != 100 || s.P50Ms != 50 || s.P99Ms != 99 || s.MaxMs != 100 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	// The window keeps only the newest samples once full.
+	for i := 0; i < 100; i++ {
+		tracker.Observe("okx", LatencyREST, "order", 500*time.Millisecond)
+	}
+	if s, _ := tracker.Stats("okx", LatencyREST, "order"); s.P50Ms != 500 || s.Samples != 100 {
+		t.Errorf("expected old samples evicted, got %+v", s)
+	}
+	if _, ok := tracker.Stats("okx", LatencyWS, "order"); ok {
+		t.Error("expected no stats for an unobserved kind")
+	}
+}
+
+func TestLatencyTrackerExpiresOldSamples(t *testing.T) {
+	tracker := NewLatencyTracker(10, 20*time.Millisecond)
+	tracker.Observe("kcex", LatencyWS, "book", 3*time.Millisecond)
+	if len(tracker.All()) != 1 {
+		t.Fatal("expected one window with recent samples")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := tracker.Stats("kcex", LatencyWS, "book"); ok {
+		t.Error("expected samples older than maxAge ignored")
+	}
+	if len(tracker.All()) != 0 {
+		t.Error("expected stale windows left out of All")
+	}
+}
+
+func TestWSLatencySkipsUnstampedEvents(t *testing.T) {
+	var calls int
+	f := WSLatency(func(string, time.Time) { calls++ })
+	f.Observe("book", time.Time{})
+	f.Observe("book", time.Now())
+	var nilFn WSLatency
+	nilFn.Observe("book", time.Now())
+	if calls != 1 {
+		t.Errorf("expected only the stamped event observed, got %d", calls)
+	}
+}
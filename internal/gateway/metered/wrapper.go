@@ -0,0 +1,276 @@
+// Package metered decorates a VenueGateway with per-method call metrics so
+// every venue reports the same counts, latencies, errors and payload sizes
+// without code in its adapter.
+package metered
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// Observer is told about every gateway call once it returns. items is the
+// payload size in domain objects: orders sent or returned, balances,
+// positions or book levels.
+type Observer func(venue, method string, elapsed time.Duration, err error, items int)
+
+// Wrapper records every call made through it and passes it on to the inner
+// gateway unchanged. Subscriptions are timed until the stream is set up; the
+// messages on it are not counted.
+type Wrapper struct {
+	inner    gateway.VenueGateway
+	venue    string
+	observer Observer
+}
+
+// NewWrapper meters inner under the venue label venue.
+func NewWrapper(inner gateway.VenueGateway, venue string, observer Observer) *Wrapper {
+	return &Wrapper{inner: inner, venue: venue, observer: observer}
+}
+
+func (w *Wrapper) observe(method string, start time.Time, err error, items int) {
+	w.observer(w.venue, method, time.Since(start), err, items)
+}
+
+func (w *Wrapper) Name() string { return w.inner.Name() }
+
+func (w *Wrapper) Connect(ctx context.Context) error {
+	start := time.Now()
+	err := w.inner.Connect(ctx)
+	w.observe("connect", start, err, 0)
+	return err
+}
+
+func (w *Wrapper) Close() error {
+	start := time.Now()
+	err := w.inner.Close()
+	w.observe("close", start, err, 0)
+	return err
+}
+
+// Health is metered as failed when the REST probe fails.
+func (w *Wrapper) Health(ctx context.Context) domain.VenueHealth {
+	start := time.Now()
+	h := w.inner.Health(ctx)
+	var err error
+	if !h.RESTReachable {
+		err = errors.New(h.RESTError)
+	}
+	w.observe("health", start, err, 0)
+	return h
+}
+
+func (w *Wrapper) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
+	start := time.Now()
+	ch, err := w.inner.SubscribeOrderBook(ctx, symbol)
+	w.observe("subscribe_order_book", start, err, 0)
+	return ch, err
+}
+
+func (w *Wrapper) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
+	start := time.Now()
+	ch, err := w.inner.SubscribeTrades(ctx, symbol)
+	w.observe("subscribe_trades", start, err, 0)
+	return ch, err
+}
+
+func (w *Wrapper) SubscribeFunding(ctx context.Context, symbol string) (<-chan domain.FundingRate, error) {
+	start := time.Now()
+	ch, err := w.inner.SubscribeFunding(ctx, symbol)
+	w.observe("subscribe_funding", start, err, 0)
+	return ch, err
+}
+
+func (w *Wrapper) SubscribeOrderUpdates(ctx context.Context) (<-chan domain.OrderUpdate, error) {
+	start := time.Now()
+	ch, err := w.inner.SubscribeOrderUpdates(ctx)
+	w.observe("subscribe_order_updates", start, err, 0)
+	return ch, err
+}
+
+func (w *Wrapper) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	start := time.Now()
+	ack, err := w.inner.PlaceOrder(ctx, req)
+	w.observe("place_order", start, err, 1)
+	return ack, err
+}
+
+func (w *Wrapper) CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	start := time.Now()
+	ack, err := w.inner.CancelOrder(ctx, orderID)
+	w.observe("cancel_order", start, err, 1)
+	return ack, err
+}
+
+func (w *Wrapper) AmendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	start := time.Now()
+	ack, err := w.inner.AmendOrder(ctx, orderID, newPrice, newSize)
+	w.observe("amend_order", start, err, 1)
+	return ack, err
+}
+
+// PlaceOrders counts as failed if any order in the batch failed.
+func (w *Wrapper) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	start := time.Now()
+	results := w.inner.PlaceOrders(ctx, reqs)
+	var err error
+	for _, r := range results {
+		if r.Err != nil {
+			err = r.Err
+			break
+		}
+	}
+	w.observe("place_orders", start, err, len(reqs))
+	return results
+}
+
+// CancelOrders counts as failed if any order in the batch failed.
+func (w *Wrapper) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	start := time.Now()
+	results := w.inner.CancelOrders(ctx, orderIDs)
+	var err error
+	for _, r := range results {
+		if r.Err != nil {
+			err = r.Err
+			break
+		}
+	}
+	w.observe("cancel_orders", start, err, len(orderIDs))
+	return results
+}
+
+func (w *Wrapper) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	start := time.Now()
+	orders, err := w.inner.GetOpenOrders(ctx, symbol)
+	w.observe("get_open_orders", start, err, len(orders))
+	return orders, err
+}
+
+func (w *Wrapper) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	start := time.Now()
+	balances, err := w.inner.GetBalances(ctx)
+	w.observe("get_balances", start, err, len(balances))
+	return balances, err
+}
+
+func (w *Wrapper) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	start := time.Now()
+	positions, err := w.inner.GetPositions(ctx)
+	w.observe("get_positions", start, err, len(positions))
+	return positions, err
+}
+
+func (w *Wrapper) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	start := time.Now()
+	tier, err := w.inner.GetFeeTier(ctx)
+	w.observe("get_fee_tier", start, err, 0)
+	return tier, err
+}
+
+func (w *Wrapper) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	start := time.Now()
+	instruments, err := w.inner.GetInstruments(ctx)
+	w.observe("get_instruments", start, err, len(instruments))
+	return instruments, err
+}
+
+func (w *Wrapper) GetRateLimitStatus(ctx context.Context) ([]domain.RateLimitStatus, error) {
+	start := time.Now()
+	status, err := w.inner.GetRateLimitStatus(ctx)
+	w.observe("get_rate_limit_status", start, err, len(status))
+	return status, err
+}
+
+func (w *Wrapper) Withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	start := time.Now()
+	t, err := w.inner.Withdraw(ctx, req)
+	w.observe("withdraw", start, err, 1)
+	return t, err
+}
+
+func (w *Wrapper) GetDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	start := time.Now()
+	addr, err := w.inner.GetDepositAddress(ctx, asset, network)
+	w.observe("get_deposit_address", start, err, 0)
+	return addr, err
+}
+
+func (w *Wrapper) GetTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error) {
+	start := time.Now()
+	t, err := w.inner.GetTransferStatus(ctx, transferID)
+	w.observe("get_transfer_status", start, err, 0)
+	return t, err
+}
+
+// GetOrderBookSnapshot meters the inner gateway's REST book, if it has one.
+func (w *Wrapper) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	p, ok := w.inner.(gateway.OrderBookSnapshotProvider)
+	if !ok {
+		return nil, gateway.ErrBookSnapshotUnsupported
+	}
+	start := time.Now()
+	book, err := p.GetOrderBookSnapshot(ctx, symbol)
+	items := 0
+	if book != nil {
+		items = len(book.Bids) + len(book.Asks)
+	}
+	w.observe("get_order_book_snapshot", start, err, items)
+	return book, err
+}
+
+// GetOrderStatus meters the inner gateway's order lookup, if it has one.
+func (w *Wrapper) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	p, ok := w.inner.(gateway.OrderStatusProvider)
+	if !ok {
+		return nil, gateway.ErrOrderStatusUnsupported
+	}
+	start := time.Now()
+	update, err := p.GetOrderStatus(ctx, orderID)
+	w.observe("get_order_status", start, err, 1)
+	return update, err
+}
+
+// GetOrderByClientID meters the inner gateway's client order ID lookup, if
+// it has one.
+func (w *Wrapper) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*domain.OrderUpdate, error) {
+	l, ok := w.inner.(gateway.ClientOrderLookup)
+	if !ok {
+		return nil, gateway.ErrClientOrderLookupUnsupported
+	}
+	start := time.Now()
+	update, err := l.GetOrderByClientID(ctx, symbol, clientOrderID)
+	w.observe("get_order_by_client_id", start, err, 1)
+	return update, err
+}
+
+// ArmCancelOnDisconnect meters the inner gateway's cancel-on-disconnect
+// call, if it has one.
+func (w *Wrapper) ArmCancelOnDisconnect(ctx context.Context, timeout time.Duration) error {
+	a, ok := w.inner.(gateway.CancelOnDisconnectArmer)
+	if !ok {
+		return gateway.ErrCancelOnDisconnectUnsupported
+	}
+	start := time.Now()
+	err := a.ArmCancelOnDisconnect(ctx, timeout)
+	w.observe("arm_cancel_on_disconnect", start, err, 0)
+	return err
+}
+
+// Inner returns the wrapped gateway, so callers can look for optional
+// interfaces the wrapper does not forward.
+func (w *Wrapper) Inner() gateway.VenueGateway {
+	return w.inner
+}
+
+var (
+	_ gateway.VenueGateway              = (*Wrapper)(nil)
+	_ gateway.OrderBookSnapshotProvider = (*Wrapper)(nil)
+	_ gateway.OrderStatusProvider       = (*Wrapper)(nil)
+	_ gateway.ClientOrderLookup         = (*Wrapper)(nil)
+	_ gateway.CancelOnDisconnectArmer   = (*Wrapper)(nil)
+)
@@ -0,0 +1,108 @@
+package metered
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// fakeGateway implements only the methods exercised here; anything else
+// panics on the nil embedded interface.
+type fakeGateway struct {
+	gateway.VenueGateway
+	balances map[string]domain.Balance
+	placeErr error
+}
+
+func (f *fakeGateway) GetBalances(context.Context) (map[string]domain.Balance, error) {
+	time.Sleep(2 * time.Millisecond)
+	return f.balances, nil
+}
+
+func (f *fakeGateway) PlaceOrder(context.Context, domain.OrderRequest) (*domain.OrderAck, error) {
+	return nil, f.placeErr
+}
+
+func (f *fakeGateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, func(_ context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+		if req.Size.IsZero() {
+			return nil, errors.New("zero size")
+		}
+		return &domain.OrderAck{}, nil
+	})
+}
+
+type call struct {
+	venue, method string
+	elapsed       time.Duration
+	err           error
+	items         int
+}
+
+func newRecorded(inner gateway.VenueGateway) (*Wrapper, *[]call) {
+	var calls []call
+	w := NewWrapper(inner, "kcex", func(venue, method string, elapsed time.Duration, err error, items int) {
+		calls = append(calls, call{venue, method, elapsed, err, items})
+	})
+	return w, &calls
+}
+
+func TestWrapperRecordsCalls(t *testing.T) {
+	inner := &fakeGateway{
+		balances: map[string]domain.Balance{"USDT": {}, "BTC": {}},
+		placeErr: errors.New("rejected"),
+	}
+	w, calls := newRecorded(inner)
+
+	balances, err := w.GetBalances(context.Background())
+	if err != nil || len(balances) != 2 {
+		t.Fatalf("balances not passed through: %v, %v", balances, err)
+	}
+	if _, err := w.PlaceOrder(context.Background(), domain.OrderRequest{}); err != inner.placeErr {
+		t.Fatalf("place error not passed through: %v", err)
+	}
+
+	if len(*calls) != 2 {
+		t.Fatalf("expected 2 calls recorded, got %d", len(*calls))
+	}
+	got := (*calls)[0]
+	if got.venue != "kcex" || got.method != "get_balances" || got.err != nil || got.items != 2 {
+		t.Errorf("unexpected balances call: %+v", got)
+	}
+	if got.elapsed < 2*time.Millisecond {
+		t.Errorf("expected latency of at least 2ms, got %s", got.elapsed)
+	}
+	if got := (*calls)[1]; got.method != "place_order" || got.err == nil || got.items != 1 {
+		t.Errorf("unexpected place call: %+v", got)
+	}
+}
+
+func TestWrapperBatchFailsOnAnyItem(t *testing.T) {
+	w, calls := newRecorded(&fakeGateway{})
+
+	reqs := []domain.OrderRequest{{Size: decimal.NewFromInt(1)}, {Size: decimal.Zero}, {Size: decimal.NewFromInt(2)}}
+	results := w.PlaceOrders(context.Background(), reqs)
+	if len(results) != 3 || results[1].Err == nil {
+		t.Fatalf("results not passed through: %+v", results)
+	}
+	if got := (*calls)[0]; got.method != "place_orders" || got.err == nil || got.items != 3 {
+		t.Errorf("unexpected batch call: %+v", got)
+	}
+}
+
+func TestWrapperSnapshotUnsupported(t *testing.T) {
+	w, calls := newRecorded(&fakeGateway{})
+
+	if _, err := w.GetOrderBookSnapshot(context.Background(), "BTC/USDT"); !errors.Is(err, gateway.ErrBookSnapshotUnsupported) {
+		t.Fatalf("expected ErrBookSnapshotUnsupported, got %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("unsupported snapshot should not be recorded, got %+v", *calls)
+	}
+}
@@ -2,7 +2,12 @@ package nobitex
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/gateway"
@@ -13,10 +18,15 @@ import (
 // Authentication uses Token-based auth (Authorization: Token xxx).
 // The API base URL is https://api.nobitex.ir.
 type Gateway struct {
-	ws     *wsClient
+	ws     *wsClient // carries order updates and the first market data shard
 	rest   *restClient
 	rl     *gateway.RateLimiter
 	logger *slog.Logger
+
+	// shards are the market data connections, ws first; assign picks the
+	// one each symbol's channels are subscribed on.
+	shards []*wsClient
+	assign *gateway.ShardAssigner
 }
 
 // New creates a new Nobitex gateway.
@@ -31,40 +41,114 @@ func New(wsURL, restURL, token string, logger *slog.Logger) *Gateway {
 	rl.AddBucket(domain.EndpointOrderCancel, 20, 10)
 	rl.AddBucket(domain.EndpointAccount, 10, 5)
 
+	ws := newWSClient(wsURL, logger)
 	return &Gateway{
-		ws:     newWSClient(wsURL, logger),
+		ws:     ws,
 		rest:   newRESTClient(restURL, token, rl, logger),
 		rl:     rl,
 		logger: logger,
+		shards: []*wsClient{ws},
+		assign: gateway.NewShardAssigner(1),
 	}
 }
 
 func (g *Gateway) Name() string { return "nobitex" }
 
+// SetAPIErrorObserver implements gateway.APIErrorReporter.
+func (g *Gateway) SetAPIErrorObserver(fn gateway.APIErrorObserver) {
+	g.rest.retrier.OnError = fn
+}
+
+// SetWSReconnectObserver implements gateway.WSReconnectReporter.
+func (g *Gateway) SetWSReconnectObserver(fn gateway.WSReconnectObserver) {
+	for _, ws := range g.shards {
+		ws.onReconnect = func() { fn("nobitex") }
+	}
+}
+
+// SetLatencyObserver implements gateway.LatencyReporter.
+func (g *Gateway) SetLatencyObserver(fn gateway.LatencyObserver) {
+	g.rest.retrier.OnLatency = fn
+	latency := gateway.WSLatency(func(stream string, venueTime time.Time) {
+		fn("nobitex", gateway.LatencyWS, stream, time.Since(venueTime))
+	})
+	for _, ws := range g.shards {
+		ws.latency = latency
+	}
+}
+
+// SetEgress implements gateway.EgressConfigurable.
+func (g *Gateway) SetEgress(e gateway.Egress) {
+	e.ApplyClient(g.rest.httpClient)
+	for _, ws := range g.shards {
+		ws.egress = e
+	}
+}
+
+// SetWSConnections implements gateway.WSSharder. Symbols are spread over n
+// connections; order updates stay on the first.
+func (g *Gateway) SetWSConnections(n int) {
+	for len(g.shards) < n {
+		ws := newWSClient(g.ws.url, g.logger)
+		ws.onReconnect = g.ws.onReconnect
+		ws.latency = g.ws.latency
+		ws.egress = g.ws.egress
+		g.shards = append(g.shards, ws)
+	}
+	g.assign = gateway.NewShardAssigner(len(g.shards))
+}
+
+// shard returns the connection that carries venueSymbol's channels.
+func (g *Gateway) shard(venueSymbol string) *wsClient {
+	return g.shards[g.assign.Shard(venueSymbol)]
+}
+
 func (g *Gateway) Connect(ctx context.Context) error {
-	return g.ws.connect(ctx)
+	for _, ws := range g.shards {
+		if err := ws.connect(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (g *Gateway) Close() error {
-	return g.ws.close()
+	var errs []error
+	for _, ws := range g.shards {
+		errs = append(errs, ws.close())
+	}
+	return errors.Join(errs...)
+}
+
+// Health implements gateway.VenueGateway. The venue counts as connected only
+// while every connection is up.
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	states := make([]*gateway.WSState, len(g.shards))
+	for i, ws := range g.shards {
+		states[i] = &ws.state
+	}
+	return gateway.ProbeHealth(ctx, "nobitex", g.rest.ping, states...)
 }
 
 func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
 	venueSymbol := domain.MapSymbol(symbol, domain.NobitexOrderBookSymbolMap)
-	ch := g.ws.subscribeOrderBook(venueSymbol)
-	if err := g.ws.subscribe(venueSymbol, "orderbook"); err != nil {
+	ws := g.shard(venueSymbol)
+	ch := ws.subscribeOrderBook(venueSymbol)
+	if err := ws.subscribe(venueSymbol, "orderbook"); err != nil {
 		return nil, err
 	}
-	go g.ws.readPump(ctx)
+	ws.startReadPump(ctx)
 	return ch, nil
 }
 
 func (g *Gateway) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
 	venueSymbol := domain.MapSymbol(symbol, domain.NobitexOrderBookSymbolMap)
-	ch := g.ws.subscribeTrades(venueSymbol)
-	if err := g.ws.subscribe(venueSymbol, "trades"); err != nil {
+	ws := g.shard(venueSymbol)
+	ch := ws.subscribeTrades(venueSymbol)
+	if err := ws.subscribe(venueSymbol, "trades"); err != nil {
 		return nil, err
 	}
+	ws.startReadPump(ctx)
 	return ch, nil
 }
 
@@ -84,10 +168,51 @@ func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.Canc
 	return g.rest.cancelOrder(ctx, orderID)
 }
 
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrder(ctx, orderID)
+}
+
+// PlaceOrders and CancelOrders loop over the single-order endpoints; Nobitex
+// has no batch API.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, g.rest.placeOrder)
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.rest.cancelOrder)
+}
+
+func (g *Gateway) AmendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	return g.rest.amendOrder(ctx, orderID, newPrice, newSize)
+}
+
 func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
 	return g.rest.getOpenOrders(ctx, symbol)
 }
 
+// SubscribeOrderUpdates streams order status and fill changes from the
+// private orders channel, authorized with a WebSocket token from the REST API.
+func (g *Gateway) SubscribeOrderUpdates(ctx context.Context) (<-chan domain.OrderUpdate, error) {
+	if g.rest.token == "" {
+		return nil, fmt.Errorf("nobitex order updates require an API token")
+	}
+	ch := g.ws.subscribeOrderUpdates()
+	// The token expires, so each reconnect subscribes with a fresh one.
+	err := g.ws.subscribePrivate(ctx, "private", func(ctx context.Context) (string, error) {
+		authParam, err := g.rest.getWSAuthParam(ctx)
+		if err != nil {
+			return "", err
+		}
+		return "orders#" + authParam, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.ws.startReadPump(ctx)
+	return ch, nil
+}
+
 func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
 	return g.rest.getBalances(ctx)
 }
@@ -100,3 +225,33 @@ func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
 func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
 	return g.rest.getFeeTier(ctx)
 }
+
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return g.rest.getInstruments(ctx)
+}
+
+func (g *Gateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return g.rl.Status(), nil
+}
+
+// GetOrderBookSnapshot implements gateway.OrderBookSnapshotProvider.
+func (g *Gateway) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	return g.rest.getOrderBook(ctx, symbol)
+}
+
+func (g *Gateway) Withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	return g.rest.withdraw(ctx, req)
+}
+
+func (g *Gateway) GetDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	return g.rest.getDepositAddress(ctx, asset, network)
+}
+
+func (g *Gateway) GetTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error) {
+	return g.rest.getTransferStatus(ctx, transferID)
+}
+
+// GetAccountActivity implements gateway.AccountHistoryProvider.
+func (g *Gateway) GetAccountActivity(ctx context.Context, since, until time.Time) ([]domain.AccountActivity, error) {
+	return g.rest.getAccountActivity(ctx, since, until)
+}
@@ -10,8 +10,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/gateway"
 )
@@ -22,6 +25,11 @@ type restClient struct {
 	httpClient  *http.Client
 	rateLimiter *gateway.RateLimiter
 	logger      *slog.Logger
+
+	// retrier retries transient REST failures and reports every failed attempt.
+	retrier gateway.RESTRetrier
+
+	tradeHistory tradeHistoryCursor
 }
 
 func newRESTClient(baseURL, token string, rl *gateway.RateLimiter, logger *slog.Logger) *restClient {
@@ -38,9 +46,16 @@ func newRESTClient(baseURL, token string, rl *gateway.RateLimiter, logger *slog.
 		},
 		rateLimiter: rl,
 		logger:      logger,
+		retrier:     gateway.NewRESTRetrier("nobitex"),
 	}
 }
 
+// errorCategories maps the Nobitex error codes callers react to.
+var errorCategories = map[string]gateway.ErrorCategory{
+	"InsufficientBalance": gateway.ErrorInsufficientBalance,
+	"SmallOrder":          gateway.ErrorInvalidSize, // order value below the minimum
+}
+
 // nobitexResponse is the common wrapper for all Nobitex API responses.
 type nobitexResponse struct {
 	Status  string          `json:"status"`
@@ -49,8 +64,36 @@ type nobitexResponse struct {
 	Raw     json.RawMessage `json:"-"`
 }
 
+// doRequest sends a request, retrying transient failures.
 func (c *restClient) doRequest(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory, authenticated bool) ([]byte, error) {
-	if err := c.rateLimiter.Acquire(ctx, category, 1); err != nil {
+	var data []byte
+	err := c.retrier.Do(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category, authenticated)
+		return err
+	})
+	return data, err
+}
+
+// doRequestOnce is doRequest without retries. Withdrawals use it since a
+// retried withdrawal that had in fact gone through would pay out twice, as
+// do order placements without a client order ID.
+func (c *restClient) doRequestOnce(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory, authenticated bool) ([]byte, error) {
+	var data []byte
+	err := c.retrier.Once(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category, authenticated)
+		return err
+	})
+	return data, err
+}
+
+// send makes one request attempt.
+func (c *restClient) send(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory, authenticated bool) ([]byte, error) {
+	waited := time.Now()
+	err := c.rateLimiter.Acquire(ctx, category, 1)
+	c.retrier.ObserveRateLimitWait(category, time.Since(waited))
+	if err != nil {
 		return nil, fmt.Errorf("rate limit: %w", err)
 	}
 
@@ -76,11 +119,14 @@ func (c *restClient) doRequest(ctx context.Context, method, path string, body in
 		req.Header.Set("Authorization", "Token "+c.token)
 	}
 
+	sent := time.Now()
 	resp, err := c.httpClient.Do(req)
+	c.retrier.ObserveLatency(category, time.Since(sent))
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.rateLimiter.ObserveResponse(category, resp)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -88,20 +134,34 @@ func (c *restClient) doRequest(ctx context.Context, method, path string, body in
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		return nil, gateway.NewHTTPError(resp, respBody)
 	}
 
 	var baseResp nobitexResponse
 	if err := json.Unmarshal(respBody, &baseResp); err == nil {
 		if baseResp.Status == "failed" {
-			return nil, fmt.Errorf("nobitex API error: code=%s message=%s", baseResp.Code, baseResp.Message)
+			return nil, &gateway.VenueError{
+				Venue:    "nobitex",
+				Op:       "API error",
+				Code:     baseResp.Code,
+				Message:  baseResp.Message,
+				Category: errorCategories[baseResp.Code],
+			}
 		}
 	}
 
 	return respBody, nil
 }
 
+// placeOrder submits an order. Nobitex has no time-in-force or post-only
+// flags: IOC is emulated by cancelling whatever did not match on entry, and
+// FOK or post-only requests are refused. Stop orders use the stop_market and
+// stop_limit executions, which rest untriggered until stopPrice trades.
 func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	if req.PostOnly || req.TimeInForce == domain.TimeInForceFOK {
+		return nil, fmt.Errorf("%w: nobitex only supports GTC and IOC", gateway.ErrTimeInForceUnsupported)
+	}
+
 	srcCurrency, dstCurrency := domain.MapNobitexCurrencyPair(req.Symbol)
 
 	orderType := "buy"
@@ -117,16 +177,28 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 		"price":       req.Price.String(),
 	}
 
-	if req.OrderType == domain.OrderTypeMarket {
+	switch req.OrderType {
+	case domain.OrderTypeMarket:
 		body["execution"] = "market"
 		delete(body, "price")
+	case domain.OrderTypeStopMarket:
+		body["execution"] = "stop_market"
+		body["stopPrice"] = req.StopPrice.String()
+		delete(body, "price")
+	case domain.OrderTypeStopLimit:
+		body["execution"] = "stop_limit"
+		body["stopPrice"] = req.StopPrice.String()
 	}
 
 	if req.IdempotencyKey != "" {
 		body["clientOrderId"] = req.IdempotencyKey
 	}
 
-	respData, err := c.doRequest(ctx, "POST", "/market/orders/add", body, domain.EndpointOrderPlace, true)
+	send := c.doRequest
+	if !gateway.Replayable(req) {
+		send = c.doRequestOnce
+	}
+	respData, err := send(ctx, "POST", "/market/orders/add", body, domain.EndpointOrderPlace, true)
 	if err != nil {
 		return nil, err
 	}
@@ -149,10 +221,26 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 		return nil, fmt.Errorf("parse order response: %w", err)
 	}
 
+	venueID := strconv.Itoa(result.Order.ID)
+	status := domain.OrderStatusAcknowledged
+	if result.Order.Status == "Done" {
+		status = domain.OrderStatusFilled
+	}
+
+	if req.TimeInForce == domain.TimeInForceIOC && status != domain.OrderStatusFilled && req.OrderType == domain.OrderTypeLimit {
+		if _, err := c.cancelOrder(ctx, venueID); err != nil {
+			// The remainder is still resting; report it as open so the caller
+			// can cancel it rather than losing track of it.
+			c.logger.Error("failed to cancel IOC remainder", "order_id", venueID, "error", err)
+		} else {
+			status = domain.OrderStatusCancelled
+		}
+	}
+
 	return &domain.OrderAck{
 		InternalID: req.InternalID,
-		VenueID:    strconv.Itoa(result.Order.ID),
-		Status:     domain.OrderStatusAcknowledged,
+		VenueID:    venueID,
+		Status:     status,
 		Timestamp:  time.Now(),
 	}, nil
 }
@@ -179,6 +267,158 @@ func (c *restClient) cancelOrder(ctx context.Context, orderID string) (*domain.C
 	}, nil
 }
 
+// getOrder looks up one order. A market order that finished with an
+// unfilled remainder reports Done; it is treated as cancelled, matching the
+// order stream.
+func (c *restClient) getOrder(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	id, err := strconv.Atoi(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID %q: %w", orderID, err)
+	}
+
+	respData, err := c.doRequest(ctx, "POST", "/market/orders/status", map[string]interface{}{"id": id}, domain.EndpointPrivateData, true)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Order struct {
+			ClientOrderID string `json:"clientOrderId"`
+			Amount        string `json:"amount"`
+			MatchedAmount string `json:"matchedAmount"`
+			AveragePrice  string `json:"averagePrice"`
+			Status        string `json:"status"`
+		} `json:"order"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse order status: %w", err)
+	}
+	o := result.Order
+
+	update := &domain.OrderUpdate{
+		Venue:         "nobitex",
+		VenueID:       orderID,
+		ClientOrderID: o.ClientOrderID,
+		Timestamp:     time.Now(),
+	}
+	amount, _ := domain.ParseDecimal(o.Amount)
+	update.FilledSize, _ = domain.ParseDecimal(o.MatchedAmount)
+	update.AvgFillPrice, _ = domain.ParseDecimal(o.AveragePrice)
+
+	switch o.Status {
+	case "Active", "New", "Inactive":
+		update.Status = domain.OrderStatusAcknowledged
+		if update.FilledSize.IsPositive() {
+			update.Status = domain.OrderStatusPartialFill
+		}
+	case "Done":
+		update.Status = domain.OrderStatusFilled
+		if amount.IsPositive() && update.FilledSize.LessThan(amount) {
+			update.Status = domain.OrderStatusCancelled
+		}
+	case "Canceled":
+		update.Status = domain.OrderStatusCancelled
+	default:
+		return nil, fmt.Errorf("unknown nobitex order status %q", o.Status)
+	}
+	return update, nil
+}
+
+// amendOrder emulates amend, which Nobitex does not offer: it looks up the
+// resting order, cancels it and places a replacement for the part of newSize
+// it had not filled when cancelled, at newPrice. The replacement has a new
+// venue ID.
+func (c *restClient) amendOrder(ctx context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	id, err := strconv.Atoi(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID %q: %w", orderID, err)
+	}
+
+	respData, err := c.doRequest(ctx, "POST", "/market/orders/status", map[string]interface{}{"id": id}, domain.EndpointPrivateData, true)
+	if err != nil {
+		return nil, fmt.Errorf("get order status: %w", err)
+	}
+	var status struct {
+		Order struct {
+			Type        string `json:"type"`
+			SrcCurrency string `json:"srcCurrency"`
+			DstCurrency string `json:"dstCurrency"`
+			Status      string `json:"status"`
+		} `json:"order"`
+	}
+	if err := json.Unmarshal(respData, &status); err != nil {
+		return nil, fmt.Errorf("parse order status: %w", err)
+	}
+	if status.Order.Status != "Active" {
+		return nil, fmt.Errorf("order %s is %s, not active", orderID, status.Order.Status)
+	}
+
+	// The original can fill until the cancel lands, so the replacement is
+	// sized from what it filled once cancelled.
+	if _, err := c.cancelOrder(ctx, orderID); err != nil {
+		return nil, fmt.Errorf("cancel for amend: %w", err)
+	}
+	final, err := c.getOrder(ctx, orderID)
+	if err != nil {
+		return &domain.AmendAck{
+			VenueID:   orderID,
+			Status:    domain.OrderStatusCancelled,
+			Timestamp: time.Now(),
+		}, fmt.Errorf("get cancelled order: %w", err)
+	}
+	remaining := newSize.Sub(final.FilledSize)
+	if !remaining.IsPositive() {
+		// Filled up to the new size before the cancel: nothing to replace.
+		return &domain.AmendAck{
+			VenueID:      orderID,
+			Price:        newPrice,
+			Size:         newSize,
+			FilledSize:   final.FilledSize,
+			AvgFillPrice: final.AvgFillPrice,
+			Status:       domain.OrderStatusFilled,
+			Timestamp:    time.Now(),
+		}, nil
+	}
+
+	body := map[string]interface{}{
+		"type":        status.Order.Type,
+		"srcCurrency": status.Order.SrcCurrency,
+		"dstCurrency": status.Order.DstCurrency,
+		"amount":      remaining.String(),
+		"price":       newPrice.String(),
+	}
+	// The replacement carries no client order ID to make a replay safe.
+	respData, err = c.doRequestOnce(ctx, "POST", "/market/orders/add", body, domain.EndpointOrderPlace, true)
+	if err != nil {
+		// The original is already cancelled; surface that to the caller.
+		return &domain.AmendAck{
+			VenueID:      orderID,
+			FilledSize:   final.FilledSize,
+			AvgFillPrice: final.AvgFillPrice,
+			Status:       domain.OrderStatusCancelled,
+			Timestamp:    time.Now(),
+		}, fmt.Errorf("place replacement: %w", err)
+	}
+
+	var result struct {
+		Order struct {
+			ID int `json:"id"`
+		} `json:"order"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse order response: %w", err)
+	}
+
+	return &domain.AmendAck{
+		VenueID:      strconv.Itoa(result.Order.ID),
+		Price:        newPrice,
+		Size:         newSize,
+		FilledSize:   final.FilledSize,
+		AvgFillPrice: final.AvgFillPrice,
+		Status:       domain.OrderStatusAcknowledged,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
 func (c *restClient) getBalances(ctx context.Context) (map[string]domain.Balance, error) {
 	respData, err := c.doRequest(ctx, "POST", "/users/wallets/list", nil, domain.EndpointAccount, true)
 	if err != nil {
@@ -233,6 +473,26 @@ func (c *restClient) getFeeTier(ctx context.Context) (*domain.FeeTier, error) {
 	return tier, nil
 }
 
+// getWSAuthParam fetches the short-lived token that authorizes private
+// WebSocket channels (private:orders#<token>).
+func (c *restClient) getWSAuthParam(ctx context.Context) (string, error) {
+	respData, err := c.doRequest(ctx, "GET", "/auth/ws/token/", nil, domain.EndpointPrivateData, true)
+	if err != nil {
+		return "", fmt.Errorf("get ws token: %w", err)
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return "", fmt.Errorf("parse ws token: %w", err)
+	}
+	if result.Token == "" {
+		return "", fmt.Errorf("empty ws token")
+	}
+	return result.Token, nil
+}
+
 func (c *restClient) getOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
 	body := map[string]interface{}{
 		"status": "open",
@@ -338,6 +598,45 @@ func (c *restClient) getOrderBook(ctx context.Context, symbol string) (*domain.O
 	return book, nil
 }
 
+// getInstruments reads the price and amount precisions of every mapped
+// market from the public options endpoint. Nobitex publishes its minimum
+// order values per quote currency rather than per market, so only the
+// increments are reported.
+func (c *restClient) getInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	respData, err := c.doRequest(ctx, "GET", "/v2/options", nil, domain.EndpointPublicData, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Nobitex struct {
+			AmountPrecisions map[string]string `json:"amountPrecisions"`
+			PricePrecisions  map[string]string `json:"pricePrecisions"`
+		} `json:"nobitex"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse options: %w", err)
+	}
+
+	var out []domain.Instrument
+	for symbol, market := range domain.NobitexOrderBookSymbolMap {
+		step, ok := result.Nobitex.AmountPrecisions[market]
+		if !ok {
+			continue
+		}
+		inst := domain.Instrument{
+			Venue:              "nobitex",
+			Symbol:             symbol,
+			InstrumentType:     domain.InstrumentSpot,
+			ContractMultiplier: decimal.NewFromInt(1),
+		}
+		inst.StepSize, _ = domain.ParseDecimal(step)
+		inst.TickSize, _ = domain.ParseDecimal(result.Nobitex.PricePrecisions[market])
+		out = append(out, inst)
+	}
+	return out, nil
+}
+
 func (c *restClient) getRecentTrades(ctx context.Context, symbol string) ([]domain.Trade, error) {
 	venueSymbol := domain.MapSymbol(symbol, domain.NobitexOrderBookSymbolMap)
 	path := "/v3/trades/" + venueSymbol
@@ -380,3 +679,354 @@ func (c *restClient) getRecentTrades(ctx context.Context, symbol string) ([]doma
 
 	return trades, nil
 }
+
+// activityPageSize is the page size requested from the history endpoints.
+const activityPageSize = 100
+
+// getAccountActivity collects our trades and wallet transfers between since
+// and until. Nobitex is spot-only, so there are no funding payments.
+func (c *restClient) getAccountActivity(ctx context.Context, since, until time.Time) ([]domain.AccountActivity, error) {
+	trades, err := c.getTradeHistory(ctx, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("trades: %w", err)
+	}
+	transfers, err := c.getTransferHistory(ctx, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("transfers: %w", err)
+	}
+	return append(trades, transfers...), nil
+}
+
+// tradeHistoryCursor holds the trades read so far from /market/trades/list,
+// newest first, and the next page to read. The endpoint has no time filter,
+// so an import walking back window by window resumes from here instead of
+// paging from the newest trade again for every window.
+type tradeHistoryCursor struct {
+	mu        sync.Mutex
+	trades    []domain.AccountActivity
+	nextPage  int
+	done      bool
+	fetchedAt time.Time
+}
+
+// getTradeHistory returns our trades in [since, until). Pages are read until
+// one reaches back before since; a later call that asks for trades newer than
+// the first page starts over from page one.
+func (c *restClient) getTradeHistory(ctx context.Context, since, until time.Time) ([]domain.AccountActivity, error) {
+	cur := &c.tradeHistory
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	if cur.nextPage == 0 || until.After(cur.fetchedAt) {
+		cur.trades, cur.nextPage, cur.done, cur.fetchedAt = nil, 1, false, time.Now()
+	}
+	for !cur.done && (len(cur.trades) == 0 || !cur.trades[len(cur.trades)-1].Timestamp.Before(since)) {
+		trades, hasNext, err := c.getTradePage(ctx, cur.nextPage)
+		if err != nil {
+			return nil, err
+		}
+		cur.trades = append(cur.trades, trades...)
+		cur.nextPage++
+		cur.done = !hasNext || len(trades) == 0
+	}
+
+	var out []domain.AccountActivity
+	for _, t := range cur.trades {
+		if !t.Timestamp.Before(since) && t.Timestamp.Before(until) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// getTradePage reads one page of /market/trades/list, newest first.
+func (c *restClient) getTradePage(ctx context.Context, page int) ([]domain.AccountActivity, bool, error) {
+	body := map[string]interface{}{
+		"page":     page,
+		"pageSize": activityPageSize,
+	}
+	respData, err := c.doRequest(ctx, "POST", "/market/trades/list", body, domain.EndpointAccount, true)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result struct {
+		Trades []struct {
+			ID          int       `json:"id"`
+			SrcCurrency string    `json:"srcCurrency"`
+			DstCurrency string    `json:"dstCurrency"`
+			Timestamp   time.Time `json:"timestamp"`
+			Type        string    `json:"type"`
+			Price       string    `json:"price"`
+			Amount      string    `json:"amount"`
+			Fee         string    `json:"fee"`
+		} `json:"trades"`
+		HasNext bool `json:"hasNext"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, false, fmt.Errorf("parse trades: %w", err)
+	}
+
+	out := make([]domain.AccountActivity, 0, len(result.Trades))
+	for _, t := range result.Trades {
+		// The fee is charged in whatever currency the trade pays out.
+		side, feeCurrency := domain.SideBuy, t.SrcCurrency
+		if t.Type == "sell" {
+			side, feeCurrency = domain.SideSell, t.DstCurrency
+		}
+		a := domain.AccountActivity{
+			Venue:       "nobitex",
+			Type:        domain.ActivityTrade,
+			VenueRef:    strconv.Itoa(t.ID),
+			Symbol:      strings.ToUpper(t.SrcCurrency) + "/" + strings.ToUpper(t.DstCurrency),
+			Side:        side,
+			FeeCurrency: strings.ToUpper(feeCurrency),
+			Timestamp:   t.Timestamp,
+		}
+		a.Price, _ = domain.ParseDecimal(t.Price)
+		a.Size, _ = domain.ParseDecimal(t.Amount)
+		a.Fee, _ = domain.ParseDecimal(t.Fee)
+		out = append(out, a)
+	}
+	return out, result.HasNext, nil
+}
+
+// getTransferHistory pages through confirmed deposits and completed
+// withdrawals. Withdrawal amounts are returned negative.
+func (c *restClient) getTransferHistory(ctx context.Context, since, until time.Time) ([]domain.AccountActivity, error) {
+	var out []domain.AccountActivity
+	for page := 1; ; page++ {
+		body := map[string]interface{}{
+			"from":     since.UTC().Format(time.RFC3339),
+			"to":       until.UTC().Format(time.RFC3339),
+			"page":     page,
+			"pageSize": activityPageSize,
+		}
+		respData, err := c.doRequest(ctx, "POST", "/users/wallets/deposits/list", body, domain.EndpointAccount, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Deposits []struct {
+				ID        int       `json:"id"`
+				Currency  string    `json:"currency"`
+				Amount    string    `json:"amount"`
+				Date      time.Time `json:"date"`
+				Confirmed bool      `json:"confirmed"`
+			} `json:"deposits"`
+			Withdraws []struct {
+				ID        int       `json:"id"`
+				Currency  string    `json:"currency"`
+				Amount    string    `json:"amount"`
+				Fee       string    `json:"fee"`
+				CreatedAt time.Time `json:"createdAt"`
+				Status    string    `json:"status"`
+			} `json:"withdraws"`
+			HasNext bool `json:"hasNext"`
+		}
+		if err := json.Unmarshal(respData, &result); err != nil {
+			return nil, fmt.Errorf("parse transfers: %w", err)
+		}
+
+		for _, d := range result.Deposits {
+			if !d.Confirmed {
+				continue
+			}
+			a := domain.AccountActivity{
+				Venue:     "nobitex",
+				Type:      domain.ActivityDeposit,
+				VenueRef:  strconv.Itoa(d.ID),
+				Asset:     strings.ToUpper(d.Currency),
+				Timestamp: d.Date,
+			}
+			a.Amount, _ = domain.ParseDecimal(d.Amount)
+			out = append(out, a)
+		}
+		for _, w := range result.Withdraws {
+			if w.Status != "Done" {
+				continue
+			}
+			a := domain.AccountActivity{
+				Venue:       "nobitex",
+				Type:        domain.ActivityWithdrawal,
+				VenueRef:    strconv.Itoa(w.ID),
+				Asset:       strings.ToUpper(w.Currency),
+				FeeCurrency: strings.ToUpper(w.Currency),
+				Timestamp:   w.CreatedAt,
+			}
+			amount, _ := domain.ParseDecimal(w.Amount)
+			a.Amount = amount.Neg()
+			a.Fee, _ = domain.ParseDecimal(w.Fee)
+			out = append(out, a)
+		}
+
+		if !result.HasNext {
+			return out, nil
+		}
+	}
+}
+
+// nobitexWithdraw is a withdrawal as returned by the wallet endpoints. Nobitex
+// gives an explorer link rather than the transaction hash, so Transfer.TxID
+// stays empty.
+type nobitexWithdraw struct {
+	ID        int       `json:"id"`
+	Currency  string    `json:"currency"`
+	Network   string    `json:"network"`
+	Amount    string    `json:"amount"`
+	Fee       string    `json:"fee"`
+	Address   string    `json:"address"`
+	Tag       string    `json:"tag"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (w nobitexWithdraw) transfer() *domain.Transfer {
+	t := &domain.Transfer{
+		ID:        strconv.Itoa(w.ID),
+		Venue:     "nobitex",
+		Asset:     strings.ToUpper(w.Currency),
+		Network:   w.Network,
+		Address:   w.Address,
+		Memo:      w.Tag,
+		Status:    transferStatus(w.Status),
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: time.Now(),
+	}
+	t.Amount, _ = domain.ParseDecimal(w.Amount)
+	t.Fee, _ = domain.ParseDecimal(w.Fee)
+	return t
+}
+
+// transferStatus maps a Nobitex withdrawal status. New and Verified
+// withdrawals are waiting on confirmation or review.
+func transferStatus(s string) domain.TransferStatus {
+	switch s {
+	case "Accepted", "Processing", "Sent":
+		return domain.TransferStatusProcessing
+	case "Done":
+		return domain.TransferStatusCompleted
+	case "Rejected":
+		return domain.TransferStatusFailed
+	case "Canceled":
+		return domain.TransferStatusCancelled
+	default:
+		return domain.TransferStatusPending
+	}
+}
+
+// walletID looks up the ID of our wallet for asset, which the withdraw
+// endpoint takes instead of a currency code.
+func (c *restClient) walletID(ctx context.Context, asset string) (int, error) {
+	respData, err := c.doRequest(ctx, "POST", "/users/wallets/list", nil, domain.EndpointAccount, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Wallets []struct {
+			ID       int    `json:"id"`
+			Currency string `json:"currency"`
+		} `json:"wallets"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return 0, fmt.Errorf("parse wallets: %w", err)
+	}
+	for _, w := range result.Wallets {
+		if strings.EqualFold(w.Currency, asset) {
+			return w.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("no %s wallet", asset)
+}
+
+// withdraw requests a withdrawal from the asset's wallet. Nobitex only pays
+// out to addresses whitelisted in the account panel and holds new requests
+// until they are confirmed there, so the transfer starts out pending.
+func (c *restClient) withdraw(ctx context.Context, req domain.WithdrawRequest) (*domain.Transfer, error) {
+	walletID, err := c.walletID(ctx, req.Asset)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"wallet":  walletID,
+		"network": req.Network,
+		"amount":  req.Amount.String(),
+		"address": req.Address,
+	}
+	if req.Memo != "" {
+		body["tag"] = req.Memo
+	}
+
+	respData, err := c.doRequestOnce(ctx, "POST", "/users/wallets/withdraw", body, domain.EndpointAccount, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Withdraw nobitexWithdraw `json:"withdraw"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse withdraw response: %w", err)
+	}
+	return result.Withdraw.transfer(), nil
+}
+
+// getDepositAddress returns the wallet's deposit address on network,
+// generating one if the wallet has none yet.
+func (c *restClient) getDepositAddress(ctx context.Context, asset, network string) (*domain.DepositAddress, error) {
+	body := map[string]interface{}{
+		"currency": strings.ToLower(asset),
+		"network":  network,
+	}
+	respData, err := c.doRequest(ctx, "POST", "/users/wallets/generate-address", body, domain.EndpointAccount, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Address string `json:"address"`
+		Tag     string `json:"tag"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse deposit address: %w", err)
+	}
+	if result.Address == "" {
+		return nil, fmt.Errorf("no %s deposit address on %s", asset, network)
+	}
+
+	return &domain.DepositAddress{
+		Venue:   "nobitex",
+		Asset:   strings.ToUpper(asset),
+		Network: network,
+		Address: result.Address,
+		Memo:    result.Tag,
+	}, nil
+}
+
+func (c *restClient) getTransferStatus(ctx context.Context, transferID string) (*domain.Transfer, error) {
+	if _, err := strconv.Atoi(transferID); err != nil {
+		return nil, fmt.Errorf("invalid withdrawal ID %q: %w", transferID, err)
+	}
+
+	respData, err := c.doRequest(ctx, "GET", "/withdraws/"+transferID, nil, domain.EndpointAccount, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Withdraw nobitexWithdraw `json:"withdraw"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse withdrawal: %w", err)
+	}
+	return result.Withdraw.transfer(), nil
+}
+
+// ping checks that the public REST API answers. Nobitex has no ping
+// endpoint, so it fetches the USDT/IRT order book.
+func (c *restClient) ping(ctx context.Context) error {
+	return gateway.ProbeREST(ctx, c.httpClient, c.baseURL+"/v3/orderbook/USDTIRT")
+}
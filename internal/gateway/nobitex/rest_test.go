@@ -3,11 +3,14 @@ package nobitex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -136,6 +139,91 @@ func TestRestClient_PlaceOrder_MarketOrder(t *testing.T) {
 	}
 }
 
+func TestRestClient_PlaceOrder_StopLimit(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "ok",
+			"order":  map[string]interface{}{"id": 101, "status": "Inactive"},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID: uuid.Must(uuid.NewV7()),
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideSell,
+		OrderType:  domain.OrderTypeStopLimit,
+		Price:      decimal.NewFromInt(57900),
+		StopPrice:  decimal.NewFromInt(58000),
+		Size:       decimal.NewFromFloat(0.1),
+	}
+
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedBody["execution"] != "stop_limit" {
+		t.Errorf("expected execution=stop_limit, got %v", capturedBody["execution"])
+	}
+	if capturedBody["stopPrice"] != "58000" || capturedBody["price"] != "57900" {
+		t.Errorf("expected stopPrice=58000 price=57900, got %v/%v", capturedBody["stopPrice"], capturedBody["price"])
+	}
+	if ack.Status != domain.OrderStatusAcknowledged {
+		t.Errorf("expected untriggered stop to be ACKNOWLEDGED, got %s", ack.Status)
+	}
+}
+
+func TestRestClient_PlaceOrder_IOCCancelsRemainder(t *testing.T) {
+	var paths []string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/market/orders/add":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"order":  map[string]interface{}{"id": 7, "status": "Active", "matchedAmount": "0"},
+			})
+		case "/market/orders/update-status":
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "updatedStatus": "Canceled"})
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID:  uuid.Must(uuid.NewV7()),
+		Symbol:      "BTC/USDT",
+		Side:        domain.SideBuy,
+		OrderType:   domain.OrderTypeLimit,
+		TimeInForce: domain.TimeInForceIOC,
+		Price:       decimal.NewFromInt(50000),
+		Size:        decimal.NewFromFloat(0.1),
+	}
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 2 || paths[1] != "/market/orders/update-status" {
+		t.Errorf("expected place then cancel, got %v", paths)
+	}
+	if ack.Status != domain.OrderStatusCancelled {
+		t.Errorf("expected CANCELLED, got %s", ack.Status)
+	}
+
+	req.TimeInForce = domain.TimeInForceFOK
+	if _, err := client.placeOrder(context.Background(), req); !errors.Is(err, gateway.ErrTimeInForceUnsupported) {
+		t.Errorf("expected ErrTimeInForceUnsupported for FOK, got %v", err)
+	}
+}
+
 func TestRestClient_CancelOrder_CorrectEndpoint(t *testing.T) {
 	var capturedPath string
 	var capturedBody map[string]interface{}
@@ -172,6 +260,71 @@ func TestRestClient_CancelOrder_CorrectEndpoint(t *testing.T) {
 	}
 }
 
+func TestRestClient_AmendOrder_CancelReplace(t *testing.T) {
+	var paths []string
+	var placeBody map[string]interface{}
+
+	cancelled := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/market/orders/status":
+			// Another 0.05 fills before the cancel lands.
+			order := map[string]interface{}{
+				"type":          "buy",
+				"srcCurrency":   "btc",
+				"dstCurrency":   "usdt",
+				"amount":        "0.3",
+				"matchedAmount": "0.1",
+				"averagePrice":  "50000",
+				"status":        "Active",
+			}
+			if cancelled {
+				order["matchedAmount"] = "0.15"
+				order["status"] = "Canceled"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "order": order})
+		case "/market/orders/update-status":
+			cancelled = true
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "updatedStatus": "Canceled"})
+		case "/market/orders/add":
+			json.NewDecoder(r.Body).Decode(&placeBody)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"order":  map[string]interface{}{"id": 43},
+			})
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	ack, err := client.amendOrder(context.Background(), "42", decimal.NewFromInt(50100), decimal.NewFromFloat(0.5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"/market/orders/status", "/market/orders/update-status", "/market/orders/status", "/market/orders/add"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("call %d: expected %s, got %s", i, want[i], paths[i])
+		}
+	}
+	// Replacement only covers the 0.35 left unfilled after the cancel.
+	if placeBody["amount"] != "0.35" || placeBody["price"] != "50100" || placeBody["type"] != "buy" {
+		t.Errorf("unexpected replacement body: %v", placeBody)
+	}
+	if ack.VenueID != "43" {
+		t.Errorf("expected new venue ID 43, got %s", ack.VenueID)
+	}
+	if !ack.FilledSize.Equal(decimal.NewFromFloat(0.15)) {
+		t.Errorf("expected the original's final 0.15 fill reported, got %s", ack.FilledSize)
+	}
+}
+
 func TestRestClient_GetBalances_ParsesWallets(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/users/wallets/list" {
@@ -420,3 +573,213 @@ func TestRestClient_GetFeeTier(t *testing.T) {
 		t.Errorf("expected taker fee 15 bps, got %s", tier.TakerFeeBps)
 	}
 }
+
+func TestRestClient_GetAccountActivity(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/market/trades/list":
+			// Newest first; the last trade predates the range and ends paging.
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"trades": []map[string]interface{}{
+					{"id": 3, "srcCurrency": "btc", "dstCurrency": "usdt", "timestamp": until.Add(time.Hour).Format(time.RFC3339),
+						"type": "buy", "price": "61000", "amount": "0.1", "fee": "0.0001"},
+					{"id": 2, "srcCurrency": "btc", "dstCurrency": "usdt", "timestamp": since.Add(time.Hour).Format(time.RFC3339),
+						"type": "sell", "price": "60000", "amount": "0.1", "fee": "6"},
+					{"id": 1, "srcCurrency": "btc", "dstCurrency": "usdt", "timestamp": since.Add(-time.Hour).Format(time.RFC3339),
+						"type": "buy", "price": "59000", "amount": "0.1", "fee": "0.0001"},
+				},
+				"hasNext": true,
+			})
+		case "/users/wallets/deposits/list":
+			if body["from"] != since.Format(time.RFC3339) {
+				t.Errorf("expected from=%s, got %v", since.Format(time.RFC3339), body["from"])
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"deposits": []map[string]interface{}{
+					{"id": 10, "currency": "usdt", "amount": "500", "date": since.Format(time.RFC3339), "confirmed": true},
+					{"id": 11, "currency": "usdt", "amount": "700", "date": since.Format(time.RFC3339), "confirmed": false},
+				},
+				"withdraws": []map[string]interface{}{
+					{"id": 20, "currency": "btc", "amount": "0.01", "fee": "0.0002", "createdAt": since.Format(time.RFC3339), "status": "Done"},
+				},
+				"hasNext": false,
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	activity, err := client.getAccountActivity(context.Background(), since, until)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(activity) != 3 {
+		t.Fatalf("expected 3 activities, got %d: %+v", len(activity), activity)
+	}
+	trade := activity[0]
+	if trade.VenueRef != "2" || trade.Side != domain.SideSell || trade.Symbol != "BTC/USDT" || trade.FeeCurrency != "USDT" {
+		t.Errorf("unexpected trade: %+v", trade)
+	}
+	if d := activity[1]; d.Type != domain.ActivityDeposit || d.VenueRef != "10" || d.Asset != "USDT" {
+		t.Errorf("unexpected deposit: %+v", d)
+	}
+	if w := activity[2]; w.Type != domain.ActivityWithdrawal || !w.Amount.Equal(decimal.NewFromFloat(-0.01)) {
+		t.Errorf("unexpected withdrawal: %+v", w)
+	}
+}
+
+func TestRestClient_GetTradeHistoryResumesAcrossWindows(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	// One trade per page, newest first: day 2, day 1, day 0.
+	var pages []float64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		page, _ := body["page"].(float64)
+		pages = append(pages, page)
+		id := 3 - int(page)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "ok",
+			"trades": []map[string]interface{}{
+				{"id": id, "srcCurrency": "btc", "dstCurrency": "usdt", "timestamp": start.Add(time.Duration(id)*day + time.Hour).Format(time.RFC3339),
+					"type": "buy", "price": "60000", "amount": "0.1", "fee": "0.0001"},
+			},
+			"hasNext": page < 3,
+		})
+	})
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	// The importer walks windows oldest first.
+	for i := 0; i < 3; i++ {
+		from := start.Add(time.Duration(i) * day)
+		trades, err := client.getTradeHistory(context.Background(), from, from.Add(day))
+		if err != nil {
+			t.Fatalf("window %d: %v", i, err)
+		}
+		if len(trades) != 1 || trades[0].VenueRef != strconv.Itoa(i) {
+			t.Errorf("window %d: expected trade %d, got %+v", i, i, trades)
+		}
+	}
+	if len(pages) != 3 {
+		t.Errorf("expected each page read once, got pages %v", pages)
+	}
+}
+
+func TestRestClient_Withdraw(t *testing.T) {
+	var withdrawBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/wallets/list":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"wallets": []map[string]interface{}{
+					{"id": 11, "currency": "btc"},
+					{"id": 12, "currency": "usdt"},
+				},
+			})
+		case "/users/wallets/withdraw":
+			json.NewDecoder(r.Body).Decode(&withdrawBody)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"withdraw": map[string]interface{}{
+					"id": 5001, "currency": "usdt", "network": "TRX", "amount": "250", "fee": "1",
+					"address": "TXyz123", "status": "New", "createdAt": "2024-03-01T10:00:00Z",
+				},
+			})
+		case "/withdraws/5001":
+			if r.Method != "GET" {
+				t.Errorf("expected GET, got %s", r.Method)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "ok",
+				"withdraw": map[string]interface{}{
+					"id": 5001, "currency": "usdt", "network": "TRX", "amount": "250", "fee": "1",
+					"address": "TXyz123", "status": "Done", "createdAt": "2024-03-01T10:00:00Z",
+				},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	transfer, err := client.withdraw(context.Background(), domain.WithdrawRequest{
+		Asset:   "USDT",
+		Network: "TRX",
+		Address: "TXyz123",
+		Amount:  decimal.NewFromInt(250),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if withdrawBody["wallet"] != float64(12) {
+		t.Errorf("expected wallet 12, got %v", withdrawBody["wallet"])
+	}
+	if withdrawBody["amount"] != "250" || withdrawBody["network"] != "TRX" {
+		t.Errorf("unexpected withdraw body: %v", withdrawBody)
+	}
+	if _, ok := withdrawBody["tag"]; ok {
+		t.Error("tag should be omitted without a memo")
+	}
+	if transfer.ID != "5001" || transfer.Asset != "USDT" || transfer.Status != domain.TransferStatusPending {
+		t.Errorf("unexpected transfer: %+v", transfer)
+	}
+	if !transfer.Fee.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected fee 1, got %s", transfer.Fee)
+	}
+
+	status, err := client.getTransferStatus(context.Background(), transfer.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Status != domain.TransferStatusCompleted {
+		t.Errorf("expected COMPLETED, got %s", status.Status)
+	}
+}
+
+func TestRestClient_GetDepositAddress(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/wallets/generate-address" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "ok",
+			"address": "TXyz123",
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	addr, err := client.getDepositAddress(context.Background(), "USDT", "TRX")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if capturedBody["currency"] != "usdt" {
+		t.Errorf("expected currency usdt, got %v", capturedBody["currency"])
+	}
+	if addr.Address != "TXyz123" || addr.Asset != "USDT" || addr.Network != "TRX" {
+		t.Errorf("unexpected address: %+v", addr)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
 )
 
 type wsClient struct {
@@ -24,8 +27,25 @@ type wsClient struct {
 	reconnectBase time.Duration
 	maxFailures   int
 	failureCount  int
+	pingInterval  time.Duration
+	stopPing      chan struct{}
 
 	subscriptions []wsSubscription
+	subMu         sync.Mutex
+	onReconnect   func() // called after each successful reconnect, if set
+	state         gateway.WSState
+	egress        gateway.Egress
+	latency       gateway.WSLatency
+	pumpOnce      sync.Once
+	// pumpDone is closed when the read pump's context ends. Order updates
+	// wait for room on their channel until then rather than being dropped.
+	pumpDone <-chan struct{}
+
+	// books holds the last top of book pushed per venue symbol. Nobitex
+	// pushes whole books, which are diffed against it to produce deltas.
+	books map[string]*wsBook
+
+	orderUpdateCh chan domain.OrderUpdate
 
 	orderBookChans map[string]chan domain.OrderBookDelta
 	tradeChans     map[string]chan domain.Trade
@@ -36,6 +56,13 @@ type wsClient struct {
 type wsSubscription struct {
 	symbol  string
 	channel string
+	// resolve, if set, fetches a fresh symbol before each resubscribe, for
+	// private channels whose token expires.
+	resolve func(ctx context.Context) (string, error)
+}
+
+type wsBook struct {
+	bids, asks []domain.PriceLevel
 }
 
 func newWSClient(url string, logger *slog.Logger) *wsClient {
@@ -45,6 +72,8 @@ func newWSClient(url string, logger *slog.Logger) *wsClient {
 		reconnectBase:  100 * time.Millisecond,
 		reconnectMax:   30 * time.Second,
 		maxFailures:    5,
+		pingInterval:   20 * time.Second,
+		books:          make(map[string]*wsBook),
 		orderBookChans: make(map[string]chan domain.OrderBookDelta),
 		tradeChans:     make(map[string]chan domain.Trade),
 		fundingChans:   make(map[string]chan domain.FundingRate),
@@ -55,21 +84,50 @@ func (ws *wsClient) connect(ctx context.Context) error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
+	dialer := ws.egress.WSDialer()
 
 	conn, _, err := dialer.DialContext(ctx, ws.url, nil)
 	if err != nil {
 		return fmt.Errorf("websocket connect to %s: %w", ws.url, err)
 	}
 
+	if ws.conn != nil {
+		ws.conn.Close()
+	}
 	ws.conn = conn
+	ws.state.SetConnected(true)
 	ws.failureCount = 0
 	ws.logger.Info("nobitex websocket connected", "url", ws.url)
+
+	if ws.stopPing != nil {
+		close(ws.stopPing)
+	}
+	ws.stopPing = make(chan struct{})
+	go ws.pingLoop(conn, ws.stopPing)
 	return nil
 }
 
+// pingLoop sends WebSocket pings so a silently dropped connection fails the
+// read pump and gets reconnected instead of stalling the feed.
+func (ws *wsClient) pingLoop(conn *websocket.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(ws.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ws.mu.Lock()
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second))
+			ws.mu.Unlock()
+			if err != nil {
+				ws.logger.Warn("nobitex websocket ping failed", "error", err)
+			}
+		}
+	}
+}
+
 func (ws *wsClient) reconnect(ctx context.Context) error {
 	delay := ws.reconnectBase
 	for i := 0; i < ws.maxFailures; i++ {
@@ -88,11 +146,9 @@ func (ws *wsClient) reconnect(ctx context.Context) error {
 			}
 			continue
 		}
-		for _, sub := range ws.subscriptions {
-			if err := ws.sendSubscribe(sub.symbol, sub.channel); err != nil {
-				ws.logger.Warn("failed to resubscribe after reconnect",
-					"symbol", sub.symbol, "channel", sub.channel, "error", err)
-			}
+		ws.resubscribe(ctx)
+		if ws.onReconnect != nil {
+			ws.onReconnect()
 		}
 		return nil
 	}
@@ -100,8 +156,61 @@ func (ws *wsClient) reconnect(ctx context.Context) error {
 	return fmt.Errorf("failed to reconnect after %d attempts", ws.maxFailures)
 }
 
+// resubscribe restores every channel subscribed before a reconnect.
+func (ws *wsClient) resubscribe(ctx context.Context) {
+	ws.subMu.Lock()
+	subs := make([]wsSubscription, len(ws.subscriptions))
+	copy(subs, ws.subscriptions)
+	ws.subMu.Unlock()
+
+	for _, sub := range subs {
+		symbol := sub.symbol
+		if sub.resolve != nil {
+			var err error
+			if symbol, err = sub.resolve(ctx); err != nil {
+				ws.logger.Warn("failed to refresh subscription after reconnect",
+					"channel", sub.channel, "error", err)
+				continue
+			}
+		}
+		if err := ws.sendSubscribe(symbol, sub.channel); err != nil {
+			ws.logger.Warn("failed to resubscribe after reconnect",
+				"symbol", symbol, "channel", sub.channel, "error", err)
+		}
+	}
+}
+
+// subscribe subscribes to channel:symbol and remembers it for reconnects.
+// Subscribing to the same channel twice sends it once.
 func (ws *wsClient) subscribe(symbol, channel string) error {
+	ws.subMu.Lock()
+	for _, sub := range ws.subscriptions {
+		if sub.symbol == symbol && sub.channel == channel {
+			ws.subMu.Unlock()
+			return nil
+		}
+	}
 	ws.subscriptions = append(ws.subscriptions, wsSubscription{symbol: symbol, channel: channel})
+	ws.subMu.Unlock()
+	return ws.sendSubscribe(symbol, channel)
+}
+
+// subscribePrivate subscribes to a private channel whose symbol embeds a
+// short-lived token; resolve is called again for each reconnect.
+func (ws *wsClient) subscribePrivate(ctx context.Context, channel string, resolve func(ctx context.Context) (string, error)) error {
+	symbol, err := resolve(ctx)
+	if err != nil {
+		return err
+	}
+	ws.subMu.Lock()
+	for i, sub := range ws.subscriptions {
+		if sub.channel == channel && sub.resolve != nil {
+			ws.subscriptions = append(ws.subscriptions[:i], ws.subscriptions[i+1:]...)
+			break
+		}
+	}
+	ws.subscriptions = append(ws.subscriptions, wsSubscription{symbol: symbol, channel: channel, resolve: resolve})
+	ws.subMu.Unlock()
 	return ws.sendSubscribe(symbol, channel)
 }
 
@@ -123,6 +232,15 @@ func (ws *wsClient) sendSubscribe(symbol, channel string) error {
 	return ws.conn.WriteJSON(msg)
 }
 
+// startReadPump runs readPump once per client, however many subscriptions
+// need it.
+func (ws *wsClient) startReadPump(ctx context.Context) {
+	ws.pumpOnce.Do(func() {
+		ws.pumpDone = ctx.Done()
+		go ws.readPump(ctx)
+	})
+}
+
 func (ws *wsClient) readPump(ctx context.Context) {
 	for {
 		select {
@@ -142,6 +260,7 @@ func (ws *wsClient) readPump(ctx context.Context) {
 
 		_, message, err := conn.ReadMessage()
 		if err != nil {
+			ws.state.SetConnected(false)
 			ws.logger.Error("nobitex websocket read error", "error", err)
 			if reconnErr := ws.reconnect(ctx); reconnErr != nil {
 				ws.logger.Error("nobitex reconnection failed permanently", "error", reconnErr)
@@ -150,6 +269,7 @@ func (ws *wsClient) readPump(ctx context.Context) {
 			continue
 		}
 
+		ws.state.Touch()
 		ws.handleMessage(message)
 	}
 }
@@ -168,7 +288,7 @@ func (ws *wsClient) handleMessage(msg []byte) {
 		return
 	}
 
-	// Channel format: "orderbook:BTCUSDT", "trades:BTCUSDT"
+	// Channel format: "orderbook:BTCUSDT", "trades:BTCUSDT", "private:orders#<token>"
 	channelType, symbol := parseChannel(raw.Channel)
 
 	switch channelType {
@@ -176,6 +296,10 @@ func (ws *wsClient) handleMessage(msg []byte) {
 		ws.handleOrderBookMessage(symbol, raw.Data)
 	case "trades":
 		ws.handleTradeMessage(symbol, raw.Data)
+	case "private":
+		if strings.HasPrefix(symbol, "orders#") {
+			ws.handleOrderMessage(raw.Data)
+		}
 	}
 }
 
@@ -188,54 +312,116 @@ func parseChannel(ch string) (string, string) {
 	return ch, ""
 }
 
-func (ws *wsClient) handleOrderBookMessage(symbol string, data json.RawMessage) {
+// handleOrderBookMessage turns a pushed book into a delta. Nobitex pushes
+// the whole top of book each time rather than changes, so the delta carries
+// every current level plus a zero-size level for each price that dropped out
+// since the last push. A missed push therefore heals on the next one, and no
+// sequence is needed.
+func (ws *wsClient) handleOrderBookMessage(venueSymbol string, data json.RawMessage) {
 	ws.chanMu.RLock()
-	ch, ok := ws.orderBookChans[symbol]
+	ch, ok := ws.orderBookChans[venueSymbol]
 	ws.chanMu.RUnlock()
 	if !ok {
 		return
 	}
 
 	var update struct {
-		Bids [][]string `json:"bids"`
-		Asks [][]string `json:"asks"`
+		Bids       [][]string  `json:"bids"`
+		Asks       [][]string  `json:"asks"`
+		LastUpdate json.Number `json:"lastUpdate"` // ms
 	}
 	if err := json.Unmarshal(data, &update); err != nil {
 		ws.logger.Warn("failed to parse nobitex orderbook update", "error", err)
 		return
 	}
 
+	// A malformed level drops the push; the previous book stays in place
+	// until the next one.
+	bids, err := parseLevels(update.Bids)
+	var asks []domain.PriceLevel
+	if err == nil {
+		asks, err = parseLevels(update.Asks)
+	}
+	if err != nil {
+		ws.logger.Warn("invalid nobitex orderbook level, dropping update", "symbol", venueSymbol, "error", err)
+		return
+	}
+
 	delta := domain.OrderBookDelta{
 		Venue:          "nobitex",
-		Symbol:         symbol,
-		LocalTimestamp:  time.Now(),
+		Symbol:         internalSymbol(venueSymbol),
+		LocalTimestamp: time.Now(),
 	}
-
-	for _, bid := range update.Bids {
-		if len(bid) >= 2 {
-			price, _ := decimal.NewFromString(bid[0])
-			size, _ := decimal.NewFromString(bid[1])
-			delta.Bids = append(delta.Bids, domain.PriceLevel{Price: price, Size: size})
-		}
+	if ms, err := update.LastUpdate.Int64(); err == nil && ms > 0 {
+		delta.VenueTimestamp = time.UnixMilli(ms)
 	}
-	for _, ask := range update.Asks {
-		if len(ask) >= 2 {
-			price, _ := decimal.NewFromString(ask[0])
-			size, _ := decimal.NewFromString(ask[1])
-			delta.Asks = append(delta.Asks, domain.PriceLevel{Price: price, Size: size})
-		}
+
+	ws.chanMu.Lock()
+	prev := ws.books[venueSymbol]
+	if prev == nil {
+		prev = &wsBook{}
 	}
+	delta.Bids = diffLevels(prev.bids, bids)
+	delta.Asks = diffLevels(prev.asks, asks)
+	ws.books[venueSymbol] = &wsBook{bids: bids, asks: asks}
+	ws.chanMu.Unlock()
 
+	ws.latency.Observe("book", delta.VenueTimestamp)
 	select {
 	case ch <- delta:
 	default:
-		ws.logger.Debug("nobitex orderbook channel full, dropping update", "symbol", symbol)
+		ws.logger.Debug("nobitex orderbook channel full, dropping update", "symbol", venueSymbol)
 	}
 }
 
-func (ws *wsClient) handleTradeMessage(symbol string, data json.RawMessage) {
+// diffLevels returns next plus a zero-size level for every price in prev
+// that is missing from next.
+func diffLevels(prev, next []domain.PriceLevel) []domain.PriceLevel {
+	out := make([]domain.PriceLevel, 0, len(next)+len(prev))
+	out = append(out, next...)
+	for _, p := range prev {
+		found := false
+		for _, n := range next {
+			if n.Price.Equal(p.Price) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, domain.PriceLevel{Price: p.Price, Size: decimal.Zero})
+		}
+	}
+	return out
+}
+
+// parseLevels converts [price, size] string pairs into price levels.
+func parseLevels(raw [][]string) ([]domain.PriceLevel, error) {
+	levels := make([]domain.PriceLevel, 0, len(raw))
+	for _, lvl := range raw {
+		if len(lvl) < 2 {
+			return nil, fmt.Errorf("level %v: want price and size", lvl)
+		}
+		price, err := decimal.NewFromString(lvl[0])
+		if err != nil {
+			return nil, fmt.Errorf("level price %q: %w", lvl[0], err)
+		}
+		size, err := decimal.NewFromString(lvl[1])
+		if err != nil {
+			return nil, fmt.Errorf("level size %q: %w", lvl[1], err)
+		}
+		levels = append(levels, domain.PriceLevel{Price: price, Size: size})
+	}
+	return levels, nil
+}
+
+// internalSymbol maps a Nobitex market symbol back to the internal one.
+func internalSymbol(venueSymbol string) string {
+	return domain.ReverseMapSymbol(venueSymbol, domain.NobitexOrderBookSymbolMap)
+}
+
+func (ws *wsClient) handleTradeMessage(venueSymbol string, data json.RawMessage) {
 	ws.chanMu.RLock()
-	ch, ok := ws.tradeChans[symbol]
+	ch, ok := ws.tradeChans[venueSymbol]
 	ws.chanMu.RUnlock()
 	if !ok {
 		return
@@ -244,14 +430,25 @@ func (ws *wsClient) handleTradeMessage(symbol string, data json.RawMessage) {
 	var update struct {
 		Price  string `json:"price"`
 		Volume string `json:"volume"`
-		Type   string `json:"type"`
-		Time   int64  `json:"time"`
+		Type   string `json:"type"` // taker side
+		Time   int64  `json:"time"` // ms
 	}
 	if err := json.Unmarshal(data, &update); err != nil {
 		ws.logger.Warn("failed to parse nobitex trade update", "error", err)
 		return
 	}
 
+	price, err := decimal.NewFromString(update.Price)
+	if err != nil {
+		ws.logger.Warn("invalid nobitex trade price", "symbol", venueSymbol, "price", update.Price)
+		return
+	}
+	size, err := decimal.NewFromString(update.Volume)
+	if err != nil {
+		ws.logger.Warn("invalid nobitex trade size", "symbol", venueSymbol, "size", update.Volume)
+		return
+	}
+
 	side := domain.SideBuy
 	if update.Type == "sell" {
 		side = domain.SideSell
@@ -259,22 +456,95 @@ func (ws *wsClient) handleTradeMessage(symbol string, data json.RawMessage) {
 
 	trade := domain.Trade{
 		Venue:     "nobitex",
-		Symbol:    symbol,
+		Symbol:    internalSymbol(venueSymbol),
+		Price:     price,
+		Size:      size,
 		Side:      side,
-		Timestamp: time.UnixMilli(update.Time),
+		Timestamp: time.Now(),
 	}
-	trade.Price, _ = decimal.NewFromString(update.Price)
-	trade.Size, _ = decimal.NewFromString(update.Volume)
-
-	if trade.Timestamp.IsZero() {
-		trade.Timestamp = time.Now()
+	if update.Time > 0 {
+		trade.Timestamp = time.UnixMilli(update.Time)
 	}
 
 	select {
 	case ch <- trade:
 	default:
-		ws.logger.Debug("nobitex trade channel full, dropping update", "symbol", symbol)
+		ws.logger.Debug("nobitex trade channel full, dropping update", "symbol", venueSymbol)
+	}
+}
+
+// handleOrderMessage converts a private order event into an OrderUpdate.
+// Nobitex reports the cumulative matched amount and average price directly.
+func (ws *wsClient) handleOrderMessage(data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch := ws.orderUpdateCh
+	ws.chanMu.RUnlock()
+	if ch == nil {
+		return
+	}
+
+	var event struct {
+		ID            int64  `json:"id"`
+		ClientOrderID string `json:"clientOrderId"`
+		Status        string `json:"status"`
+		Amount        string `json:"amount"`
+		MatchedAmount string `json:"matchedAmount"`
+		AveragePrice  string `json:"averagePrice"`
+		UpdatedAt     int64  `json:"updatedAt"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		ws.logger.Warn("failed to parse nobitex order update", "error", err)
+		return
+	}
+
+	update := domain.OrderUpdate{
+		Venue:         "nobitex",
+		VenueID:       strconv.FormatInt(event.ID, 10),
+		ClientOrderID: event.ClientOrderID,
+		Timestamp:     time.Now(),
+	}
+	if event.UpdatedAt > 0 {
+		update.Timestamp = time.UnixMilli(event.UpdatedAt)
+	}
+	amount, _ := domain.ParseDecimal(event.Amount)
+	update.FilledSize, _ = domain.ParseDecimal(event.MatchedAmount)
+	update.AvgFillPrice, _ = domain.ParseDecimal(event.AveragePrice)
+
+	switch event.Status {
+	case "Active", "New", "Inactive": // Inactive is an untriggered stop
+		update.Status = domain.OrderStatusAcknowledged
+		if update.FilledSize.IsPositive() {
+			update.Status = domain.OrderStatusPartialFill
+		}
+	case "Done":
+		update.Status = domain.OrderStatusFilled
+		if amount.IsPositive() && update.FilledSize.LessThan(amount) {
+			// Market orders can finish with an unfilled remainder.
+			update.Status = domain.OrderStatusCancelled
+		}
+	case "Canceled":
+		update.Status = domain.OrderStatusCancelled
+	default:
+		return
+	}
+
+	// An order update is never dropped: a lost fill or cancel would leave
+	// the order manager's view of the order wrong until the next
+	// reconciliation.
+	select {
+	case ch <- update:
+	case <-ws.pumpDone:
+	}
+}
+
+func (ws *wsClient) subscribeOrderUpdates() <-chan domain.OrderUpdate {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+
+	if ws.orderUpdateCh == nil {
+		ws.orderUpdateCh = make(chan domain.OrderUpdate, 256)
 	}
+	return ws.orderUpdateCh
 }
 
 func (ws *wsClient) subscribeOrderBook(symbol string) <-chan domain.OrderBookDelta {
@@ -299,7 +569,7 @@ func (ws *wsClient) subscribeFunding(symbol string) <-chan domain.FundingRate {
 	ws.chanMu.Lock()
 	defer ws.chanMu.Unlock()
 
-	// Nobitex is spot-only; funding rate channel will never receive data
+	// Nobitex lists no perpetuals, so nothing is ever sent on this channel.
 	ch := make(chan domain.FundingRate, 8)
 	ws.fundingChans[symbol] = ch
 	return ch
@@ -308,6 +578,11 @@ func (ws *wsClient) subscribeFunding(symbol string) <-chan domain.FundingRate {
 func (ws *wsClient) close() error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
+	ws.state.SetConnected(false)
+	if ws.stopPing != nil {
+		close(ws.stopPing)
+		ws.stopPing = nil
+	}
 	if ws.conn != nil {
 		return ws.conn.Close()
 	}
@@ -0,0 +1,271 @@
+package nobitex
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestWSClient_HandleOrderMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", logger)
+	ch := ws.subscribeOrderUpdates()
+
+	msgs := []string{
+		`{"channel":"private:orders#tok","data":{"id":42,"clientOrderId":"c1","status":"Active","amount":"1","matchedAmount":"0.25","averagePrice":"100"}}`,
+		`{"channel":"private:orders#tok","data":{"id":42,"clientOrderId":"c1","status":"Done","amount":"1","matchedAmount":"1","averagePrice":"101"}}`,
+		`{"channel":"private:orders#tok","data":{"id":43,"status":"Canceled","amount":"2","matchedAmount":"0"}}`,
+	}
+	for _, m := range msgs {
+		ws.handleMessage([]byte(m))
+	}
+
+	partial := <-ch
+	if partial.Status != domain.OrderStatusPartialFill || partial.VenueID != "42" || partial.ClientOrderID != "c1" {
+		t.Errorf("unexpected partial update: %+v", partial)
+	}
+	if !partial.FilledSize.Equal(decimal.NewFromFloat(0.25)) {
+		t.Errorf("expected filled size 0.25, got %s", partial.FilledSize)
+	}
+
+	done := <-ch
+	if done.Status != domain.OrderStatusFilled || !done.AvgFillPrice.Equal(decimal.NewFromInt(101)) {
+		t.Errorf("unexpected done update: %+v", done)
+	}
+
+	cancelled := <-ch
+	if cancelled.Status != domain.OrderStatusCancelled || cancelled.VenueID != "43" {
+		t.Errorf("unexpected cancel update: %+v", cancelled)
+	}
+}
+
+func TestWSClient_HandleOrderBookMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	ws := newWSClient("", logger)
+	ch := ws.subscribeOrderBook("BTCUSDT")
+
+	ws.handleMessage([]byte(`{"channel":"orderbook:BTCUSDT","data":{"bids":[["60000","1"],["59990","2"]],"asks":[["60010","1.5"]],"lastUpdate":1700000000000}}`))
+	first := <-ch
+	if first.Symbol != "BTC/USDT" || len(first.Bids) != 2 || len(first.Asks) != 1 {
+		t.Fatalf("unexpected first delta: %+v", first)
+	}
+	if !first.VenueTimestamp.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("venue timestamp: got %s", first.VenueTimestamp)
+	}
+
+	// 59990 drops out of the next push and must be removed.
+	ws.handleMessage([]byte(`{"channel":"orderbook:BTCUSDT","data":{"bids":[["60000","0.5"]],"asks":[["60010","1.5"]],"lastUpdate":1700000000100}}`))
+	second := <-ch
+	var removed, updated bool
+	for _, b := range second.Bids {
+		switch {
+		case b.Price.Equal(decimal.NewFromInt(59990)) && b.Size.IsZero():
+			removed = true
+		case b.Price.Equal(decimal.NewFromInt(60000)) && b.Size.Equal(decimal.NewFromFloat(0.5)):
+			updated = true
+		}
+	}
+	if !removed || !updated {
+		t.Errorf("expected 59990 removed and 60000 updated, got bids %+v", second.Bids)
+	}
+
+	// A malformed level drops the push and leaves the last book as the base.
+	ws.handleMessage([]byte(`{"channel":"orderbook:BTCUSDT","data":{"bids":[["oops","1"]],"asks":[]}}`))
+	select {
+	case d := <-ch:
+		t.Fatalf("expected malformed push dropped, got %+v", d)
+	default:
+	}
+	if book := ws.books["BTCUSDT"]; len(book.bids) != 1 {
+		t.Errorf("expected last good book kept, got %+v", book)
+	}
+}
+
+func TestWSClient_HandleTradeMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	ws := newWSClient("", logger)
+	ch := ws.subscribeTrades("ETHUSDT")
+
+	ws.handleMessage([]byte(`{"channel":"trades:ETHUSDT","data":{"price":"3000.5","volume":"0.2","type":"sell","time":1700000000000}}`))
+	ws.handleMessage([]byte(`{"channel":"trades:ETHUSDT","data":{"price":"","volume":"0.2","type":"buy","time":1700000000001}}`))
+
+	trade := <-ch
+	if trade.Symbol != "ETH/USDT" || trade.Side != domain.SideSell || !trade.Price.Equal(decimal.RequireFromString("3000.5")) {
+		t.Errorf("unexpected trade: %+v", trade)
+	}
+	if !trade.Timestamp.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("trade timestamp: got %s", trade.Timestamp)
+	}
+	select {
+	case bad := <-ch:
+		t.Errorf("expected trade without a price dropped, got %+v", bad)
+	default:
+	}
+}
+
+func TestWSClient_ResubscribesAfterReconnect(t *testing.T) {
+	var (
+		conns atomic.Int32
+		subs  = make(chan string, 16)
+	)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		n := conns.Add(1)
+		for received := 1; ; received++ {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var sub struct {
+				Params struct {
+					Channel string `json:"channel"`
+				} `json:"params"`
+			}
+			json.Unmarshal(msg, &sub)
+			subs <- sub.Params.Channel
+			if n == 1 && received == 2 {
+				// Drop the first connection once both channels are subscribed.
+				conn.Close()
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	ws := newWSClient("ws"+strings.TrimPrefix(srv.URL, "http"), logger)
+	ws.reconnectBase = time.Millisecond
+	var reconnects atomic.Int32
+	ws.onReconnect = func() { reconnects.Add(1) }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer ws.close()
+
+	if err := ws.connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	var tokens atomic.Int32
+	if err := ws.subscribe("BTCUSDT", "orderbook"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	err := ws.subscribePrivate(ctx, "private", func(context.Context) (string, error) {
+		return "orders#tok" + string(rune('0'+tokens.Add(1))), nil
+	})
+	if err != nil {
+		t.Fatalf("subscribe private: %v", err)
+	}
+	ws.startReadPump(ctx)
+
+	want := []string{"orderbook:BTCUSDT", "private:orders#tok1", "orderbook:BTCUSDT", "private:orders#tok2"}
+	for i, w := range want {
+		select {
+		case got := <-subs:
+			if got != w {
+				t.Errorf("subscription %d: got %q, want %q", i, got, w)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for subscription %d (%s)", i, w)
+		}
+	}
+	if conns.Load() != 2 {
+		t.Errorf("expected one reconnect, got %d connections", conns.Load())
+	}
+	// The reconnect is reported after the resubscribes are sent.
+	for deadline := time.Now().Add(time.Second); reconnects.Load() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if reconnects.Load() != 1 {
+		t.Errorf("expected the reconnect reported once, got %d", reconnects.Load())
+	}
+}
+
+func TestGateway_ShardsSymbolsOverConnections(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		conns int
+		subs  = make(map[int][]string)
+		got   = make(chan struct{}, 16)
+	)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		n := conns
+		conns++
+		mu.Unlock()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var sub struct {
+				Params struct {
+					Channel string `json:"channel"`
+				} `json:"params"`
+			}
+			json.Unmarshal(msg, &sub)
+			mu.Lock()
+			subs[n] = append(subs[n], sub.Params.Channel)
+			mu.Unlock()
+			got <- struct{}{}
+		}
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	g := New("ws"+strings.TrimPrefix(srv.URL, "http"), srv.URL, "", logger)
+	g.SetWSConnections(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer g.Close()
+
+	if err := g.Connect(ctx); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	for _, symbol := range []string{"BTC/IRT", "ETH/IRT", "USDT/IRT"} {
+		if _, err := g.SubscribeOrderBook(ctx, symbol); err != nil {
+			t.Fatalf("subscribe book: %v", err)
+		}
+	}
+	if _, err := g.SubscribeTrades(ctx, "ETH/IRT"); err != nil {
+		t.Fatalf("subscribe trades: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for subscription %d", i)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 2 || len(subs[0]) != 2 || len(subs[1]) != 2 {
+		t.Fatalf("expected four subscriptions spread over two connections, got %v", subs)
+	}
+	for _, ch := range subs[1] {
+		if !strings.HasSuffix(ch, ":ETHIRT") {
+			t.Errorf("expected ETH's book and trades on the second connection, got %v", subs[1])
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// Gateway implements the VenueGateway interface for OKX (v5 API).
+// OKX authenticates with HMAC-SHA256 (Base64-encoded) plus API key and
+// passphrase headers. The gateway assumes a unified trading account: spot
+// (BTC-USDT format) and perpetual swaps (BTC-USDT-SWAP format) share one
+// balance sheet, so GetBalances returns account equity per currency and
+// GetPositions returns swap positions converted from contracts to base units.
+type Gateway struct {
+	ws     *wsClient
+	rest   *restClient
+	logger *slog.Logger
+}
+
+// New creates a new OKX gateway.
+// apiKey, apiSecret, and passphrase are the OKX API credentials.
+func New(wsURL, restURL, apiKey, apiSecret, passphrase string, logger *slog.Logger) *Gateway {
+	rl := gateway.NewRateLimiter()
+	rl.AddBucket(domain.EndpointPublicData, 40, 20)
+	rl.AddBucket(domain.EndpointPrivateData, 20, 10)
+	rl.AddBucket(domain.EndpointOrderPlace, 60, 30)
+	rl.AddBucket(domain.EndpointOrderCancel, 60, 30)
+	rl.AddBucket(domain.EndpointAccount, 10, 5)
+
+	return &Gateway{
+		ws:     newWSClient(wsURL, logger),
+		rest:   newRESTClient(restURL, apiKey, apiSecret, passphrase, rl, logger),
+		logger: logger,
+	}
+}
+
+func (g *Gateway) Name() string { return "okx" }
+
+// SetAPIErrorObserver implements gateway.APIErrorReporter.
+func (g *Gateway) SetAPIErrorObserver(fn gateway.APIErrorObserver) {
+	g.rest.retrier.OnError = fn
+}
+
+// SetWSReconnectObserver implements gateway.WSReconnectReporter.
+func (g *Gateway) SetWSReconnectObserver(fn gateway.WSReconnectObserver) {
+	g.ws.onReconnect = func() { fn("okx") }
+}
+
+// SetLatencyObserver implements gateway.LatencyReporter. Stream delays are
+// measured against the venue-synced clock.
+func (g *Gateway) SetLatencyObserver(fn gateway.LatencyObserver) {
+	g.rest.retrier.OnLatency = fn
+	g.ws.latency = gateway.WSLatency(func(stream string, venueTime time.Time) {
+		fn("okx", gateway.LatencyWS, stream, g.rest.clock.Now().Sub(venueTime))
+	})
+}
+
+// SetEgress implements gateway.EgressConfigurable.
+func (g *Gateway) SetEgress(e gateway.Egress) {
+	e.ApplyClient(g.rest.httpClient)
+	g.ws.egress = e
+}
+
+// SetClockOffsetObserver implements gateway.ClockOffsetReporter.
+func (g *Gateway) SetClockOffsetObserver(fn gateway.ClockOffsetObserver) {
+	g.rest.clock.OnSync = fn
+}
+
+// SetSubAccount tags balances and positions with the OKX sub-account the
+// API key was created for. OKX scopes every private request to the account
+// that owns the key. Call before Connect.
+func (g *Gateway) SetSubAccount(id string) {
+	g.rest.subAccount = id
+}
+
+// SetAPIKeys implements gateway.KeyRotator.
+func (g *Gateway) SetAPIKeys(keys []gateway.APIKey) error {
+	return g.rest.keys.SetKeys(keys)
+}
+
+func (g *Gateway) Connect(ctx context.Context) error {
+	if g.rest.keys.Signed() {
+		go g.rest.clock.Run(ctx, gateway.ClockSyncInterval, g.logger)
+	}
+	return g.ws.connect(ctx)
+}
+
+func (g *Gateway) Close() error {
+	return g.ws.close()
+}
+
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	return gateway.ProbeHealth(ctx, "okx", g.rest.ping, &g.ws.state)
+}
+
+// SubscribeOrderBook streams the instrument's `books` channel: a snapshot
+// that replaces the book on each (re)subscribe, then updates chained on
+// prevSeqId and carrying OKX's checksum of the top 25 levels, so the market
+// data service resyncs from GetOrderBookSnapshot when one goes missing.
+func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
+	instID := domain.MapOKXSymbol(symbol)
+	ch := g.ws.subscribeOrderBook(instID)
+	if err := g.ws.subscribe(ctx, "books", instID); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) SubscribeTrades(ctx context.Context, symbol string) (<-chan domain.Trade, error) {
+	instID := domain.MapOKXSymbol(symbol)
+	ch := g.ws.subscribeTrades(instID)
+	if err := g.ws.subscribe(ctx, "trades", instID); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) SubscribeFunding(ctx context.Context, symbol string) (<-chan domain.FundingRate, error) {
+	if !domain.IsOKXSwap(symbol) {
+		return nil, fmt.Errorf("okx funding only available for perp symbols, got %s", symbol)
+	}
+	instID := domain.MapOKXSymbol(symbol)
+	ch := g.ws.subscribeFunding(instID)
+	if err := g.ws.subscribe(ctx, "funding-rate", instID); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (g *Gateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	return g.rest.placeOrder(ctx, req)
+}
+
+func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	return g.rest.cancelOrder(ctx, orderID)
+}
+
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrder(ctx, orderID)
+}
+
+// GetOrderByClientID implements gateway.ClientOrderLookup.
+func (g *Gateway) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrderByClOrdID(ctx, symbol, clientOrderID)
+}
+
+// PlaceOrders uses the batch-orders endpoint, 20 orders per request.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return g.rest.placeOrders(ctx, reqs)
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return g.rest.cancelOrders(ctx, orderIDs)
+}
+
+// AmendOrder is not wired to /api/v5/trade/amend-order yet.
+func (g *Gateway) AmendOrder(_ context.Context, _ string, _, _ decimal.Decimal) (*domain.AmendAck, error) {
+	return nil, gateway.ErrAmendUnsupported
+}
+
+func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	return g.rest.getOpenOrders(ctx, symbol)
+}
+
+// SubscribeOrderUpdates is not implemented yet; fills are only seen via REST.
+func (g *Gateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
+
+func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	return g.rest.getBalances(ctx)
+}
+
+func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
+	return g.rest.getPositions(ctx)
+}
+
+func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	return g.rest.getFeeTier(ctx)
+}
+
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return g.rest.getInstruments(ctx)
+}
+
+func (g *Gateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return g.rest.keys.Status(), nil
+}
+
+// ArmCancelOnDisconnect implements gateway.CancelOnDisconnectArmer.
+func (g *Gateway) ArmCancelOnDisconnect(ctx context.Context, timeout time.Duration) error {
+	return g.rest.cancelAllAfter(ctx, timeout)
+}
+
+// GetOrderBookSnapshot implements gateway.OrderBookSnapshotProvider.
+func (g *Gateway) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	return g.rest.getOrderBook(ctx, symbol)
+}
+
+// Withdraw, GetDepositAddress and GetTransferStatus are not wired to the
+// /api/v5/asset endpoints yet.
+func (g *Gateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetDepositAddress(_ context.Context, _, _ string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
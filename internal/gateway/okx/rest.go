@@ -0,0 +1,912 @@
+package okx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// errorCategories maps the OKX codes callers react to. They apply to the
+// top-level code and to each order's sCode alike.
+var errorCategories = map[string]gateway.ErrorCategory{
+	"50011": gateway.ErrorRateLimit,           // rate limit reached
+	"50061": gateway.ErrorRateLimit,           // sub-account order rate limit
+	"50111": gateway.ErrorAuth,                // invalid OK-ACCESS-KEY
+	"50113": gateway.ErrorAuth,                // invalid sign
+	"51008": gateway.ErrorInsufficientBalance, // insufficient balance or margin
+	"51006": gateway.ErrorInvalidPrice,        // price outside the limit band
+	"51020": gateway.ErrorInvalidSize,         // size below the minimum
+	"51121": gateway.ErrorInvalidSize,         // size not a multiple of the lot size
+	"51016": gateway.ErrorDuplicateOrder,      // duplicate clOrdId
+	"51169": gateway.ErrorReduceOnly,          // no position in this direction to reduce
+	"51400": gateway.ErrorOrderNotFound,       // cancel failed, order does not exist
+	"51603": gateway.ErrorOrderNotFound,       // order does not exist
+	"51001": gateway.ErrorSymbolUnavailable,   // instrument does not exist
+	"51027": gateway.ErrorSymbolUnavailable,   // contract expired
+}
+
+// apiError builds the error for a failed OKX code.
+func apiError(what, code, msg string) error {
+	return &gateway.VenueError{
+		Venue:    "okx",
+		Op:       what,
+		Code:     code,
+		Message:  msg,
+		Category: errorCategories[code],
+	}
+}
+
+// contractValues is the base-asset quantity of one OKX swap contract.
+// Swap order and position sizes are quoted in contracts.
+var contractValues = map[string]decimal.Decimal{
+	"BTC-USDT-SWAP": decimal.RequireFromString("0.01"),
+	"ETH-USDT-SWAP": decimal.RequireFromString("0.1"),
+	"SOL-USDT-SWAP": decimal.RequireFromString("1"),
+}
+
+// contractValue returns the contract size for a swap instrument, or one
+// for spot instruments and unknown swaps.
+func contractValue(instID string) decimal.Decimal {
+	if v, ok := contractValues[instID]; ok {
+		return v
+	}
+	return decimal.NewFromInt(1)
+}
+
+type restClient struct {
+	baseURL    string
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	// keys picks the API key, and with it the rate limit budget, for each
+	// request.
+	keys *gateway.KeyPool
+
+	// subAccount names the sub-account whose API key signs our requests.
+	subAccount string
+
+	// retrier retries transient REST failures and reports every failed attempt.
+	retrier gateway.RESTRetrier
+
+	// clock corrects the timestamp signed requests carry for drift from the
+	// venue's clock.
+	clock *gateway.ClockSync
+}
+
+func newRESTClient(baseURL, apiKey, apiSecret, passphrase string, rl *gateway.RateLimiter, logger *slog.Logger) *restClient {
+	c := &restClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:       10,
+				IdleConnTimeout:    90 * time.Second,
+				DisableCompression: true,
+			},
+		},
+		logger:  logger,
+		keys:    gateway.NewKeyPool(rl, gateway.APIKey{Key: apiKey, Secret: apiSecret, Passphrase: passphrase}),
+		retrier: gateway.NewRESTRetrier("okx"),
+	}
+	c.clock = gateway.NewClockSync("okx", c.serverTime)
+	return c
+}
+
+// sign creates a Base64-encoded HMAC-SHA256 signature for OKX.
+// The signature string is: timestamp + method + requestPath + body
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest sends a v5 request, retrying transient failures. path includes
+// any query string, which is part of the signed request path.
+func (c *restClient) doRequest(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory) ([]byte, error) {
+	var data []byte
+	err := c.retrier.Do(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category, false)
+		return err
+	})
+	return data, err
+}
+
+// doRequestOnce is doRequest without retries, for order placements that
+// cannot be replayed.
+func (c *restClient) doRequestOnce(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory) ([]byte, error) {
+	var data []byte
+	err := c.retrier.Once(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category, false)
+		return err
+	})
+	return data, err
+}
+
+// doBatchRequest POSTs to a batch order endpoint, which answers a partly
+// successful batch with code 2; the caller must check each entry's sCode.
+// once sends it without retries, like doRequestOnce.
+func (c *restClient) doBatchRequest(ctx context.Context, path string, body interface{}, category domain.EndpointCategory, once bool) ([]byte, error) {
+	run := c.retrier.Do
+	if once {
+		run = c.retrier.Once
+	}
+	var data []byte
+	err := run(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, "POST", path, body, category, true)
+		return err
+	})
+	return data, err
+}
+
+// send makes one request attempt. batch accepts code 2, a partly successful
+// batch, as well as code 0.
+func (c *restClient) send(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory, batch bool) ([]byte, error) {
+	waited := time.Now()
+	key, err := c.keys.Acquire(ctx, category, 1)
+	c.retrier.ObserveRateLimitWait(category, time.Since(waited))
+	if err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	var reqBody io.Reader
+	var payload string
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal body: %w", err)
+		}
+		payload = string(data)
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if key.Key != "" {
+		timestamp := c.clock.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		req.Header.Set("OK-ACCESS-KEY", key.Key)
+		req.Header.Set("OK-ACCESS-SIGN", sign(key.Secret, timestamp+method+path+payload))
+		req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+		req.Header.Set("OK-ACCESS-PASSPHRASE", key.Passphrase)
+	}
+
+	sent := time.Now()
+	resp, err := c.httpClient.Do(req)
+	c.retrier.ObserveLatency(category, time.Since(sent))
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	key.Limiter.ObserveResponse(category, resp)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		// Rejections such as a 401 carry an OKX code in the body too.
+		var apiErr struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if !gateway.RetryableStatus(resp.StatusCode) && json.Unmarshal(respBody, &apiErr) == nil && apiErr.Code != "" {
+			return nil, apiError("API error", apiErr.Code, apiErr.Msg)
+		}
+		return nil, gateway.NewHTTPError(resp, respBody)
+	}
+
+	// OKX wraps all responses in {"code": "0", "msg": "", "data": [...]}
+	var baseResp struct {
+		Code string          `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &baseResp); err != nil {
+		return nil, fmt.Errorf("parse response wrapper: %w", err)
+	}
+
+	// Code 2 is a partially successful batch; per-order sCode says which.
+	// Other endpoints never answer with it.
+	if baseResp.Code != "0" && !(batch && baseResp.Code == "2") {
+		return nil, apiError("API error", baseResp.Code, baseResp.Msg)
+	}
+
+	return baseResp.Data, nil
+}
+
+// formatVenueOrderID encodes the instrument alongside the OKX order ID,
+// since cancellation requires both.
+func formatVenueOrderID(instID, ordID string) string {
+	return instID + ":" + ordID
+}
+
+// parseVenueOrderID is the inverse of formatVenueOrderID.
+func parseVenueOrderID(venueID string) (instID, ordID string, err error) {
+	instID, ordID, ok := strings.Cut(venueID, ":")
+	if !ok || instID == "" || ordID == "" {
+		return "", "", fmt.Errorf("invalid okx order id %q", venueID)
+	}
+	return instID, ordID, nil
+}
+
+func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	body, err := orderBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	send := c.doRequest
+	if !gateway.Replayable(req) {
+		send = c.doRequestOnce
+	}
+	data, err := send(ctx, "POST", "/api/v5/trade/order", body, domain.EndpointOrderPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []orderResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse order response: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty order response")
+	}
+	return result[0].ack(req, body["instId"].(string))
+}
+
+// orderResult is one entry of the data array returned by the order endpoints.
+type orderResult struct {
+	OrdID string `json:"ordId"`
+	SCode string `json:"sCode"`
+	SMsg  string `json:"sMsg"`
+}
+
+func (r orderResult) ack(req domain.OrderRequest, instID string) (*domain.OrderAck, error) {
+	if r.SCode != "0" {
+		return nil, apiError("order rejected", r.SCode, r.SMsg)
+	}
+	return &domain.OrderAck{
+		InternalID: req.InternalID,
+		VenueID:    formatVenueOrderID(instID, r.OrdID),
+		Status:     domain.OrderStatusAcknowledged,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// orderBody builds the place-order parameters for req.
+func orderBody(req domain.OrderRequest) (map[string]interface{}, error) {
+	if req.OrderType.IsStop() {
+		// Trigger orders use the separate algo-order API, which is not wired.
+		return nil, fmt.Errorf("%w: okx %s", gateway.ErrOrderTypeUnsupported, req.OrderType)
+	}
+
+	instID := domain.MapOKXSymbol(req.Symbol)
+	isSwap := domain.IsOKXSwap(req.Symbol)
+
+	side := "buy"
+	if req.Side == domain.SideSell {
+		side = "sell"
+	}
+
+	body := map[string]interface{}{
+		"instId":  instID,
+		"side":    side,
+		"clOrdId": req.IdempotencyKey,
+	}
+
+	// Unified account: spot trades settle in cash, swaps use cross margin.
+	if isSwap {
+		body["tdMode"] = "cross"
+		body["sz"] = req.Size.Div(contractValue(instID)).String()
+		if req.ReduceOnly {
+			body["reduceOnly"] = true
+		}
+	} else {
+		body["tdMode"] = "cash"
+		body["sz"] = req.Size.String()
+	}
+
+	if req.OrderType == domain.OrderTypeLimit {
+		// OKX folds time in force and post-only into the order type.
+		switch {
+		case req.PostOnly:
+			body["ordType"] = "post_only"
+		case req.TimeInForce == domain.TimeInForceIOC:
+			body["ordType"] = "ioc"
+		case req.TimeInForce == domain.TimeInForceFOK:
+			body["ordType"] = "fok"
+		default:
+			body["ordType"] = "limit"
+		}
+		body["px"] = req.Price.String()
+	} else {
+		body["ordType"] = "market"
+		if !isSwap {
+			// Spot market buys default to quote-denominated size.
+			body["tgtCcy"] = "base_ccy"
+		}
+	}
+
+	return body, nil
+}
+
+// batchLimit is the most orders OKX accepts in one batch request.
+const batchLimit = 20
+
+// placeOrders submits up to batchLimit orders per batch-orders request.
+// Requests that cannot be encoded fail individually without being sent.
+func (c *restClient) placeOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	results := make([]gateway.PlaceResult, len(reqs))
+
+	var bodies []map[string]interface{}
+	var index []int // position in reqs of each entry in bodies
+	for i, req := range reqs {
+		body, err := orderBody(req)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		bodies = append(bodies, body)
+		index = append(index, i)
+	}
+
+	once := !gateway.Replayable(reqs...)
+	for start := 0; start < len(bodies); start += batchLimit {
+		end := min(start+batchLimit, len(bodies))
+		chunk := bodies[start:end]
+
+		var entries []orderResult
+		data, err := c.doBatchRequest(ctx, "/api/v5/trade/batch-orders", chunk, domain.EndpointOrderPlace, once)
+		if err == nil {
+			err = json.Unmarshal(data, &entries)
+		}
+		for j := range chunk {
+			i := index[start+j]
+			switch {
+			case err != nil:
+				results[i].Err = err
+			case j >= len(entries):
+				results[i].Err = fmt.Errorf("missing batch order result")
+			default:
+				results[i].Ack, results[i].Err = entries[j].ack(reqs[i], chunk[j]["instId"].(string))
+			}
+		}
+	}
+	return results
+}
+
+// cancelOrders cancels up to batchLimit orders per cancel-batch-orders request.
+func (c *restClient) cancelOrders(ctx context.Context, venueIDs []string) []gateway.CancelResult {
+	results := make([]gateway.CancelResult, len(venueIDs))
+
+	var bodies []map[string]interface{}
+	var index []int
+	for i, venueID := range venueIDs {
+		instID, ordID, err := parseVenueOrderID(venueID)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		bodies = append(bodies, map[string]interface{}{"instId": instID, "ordId": ordID})
+		index = append(index, i)
+	}
+
+	for start := 0; start < len(bodies); start += batchLimit {
+		end := min(start+batchLimit, len(bodies))
+		chunk := bodies[start:end]
+
+		var entries []orderResult
+		data, err := c.doBatchRequest(ctx, "/api/v5/trade/cancel-batch-orders", chunk, domain.EndpointOrderCancel, false)
+		if err == nil {
+			err = json.Unmarshal(data, &entries)
+		}
+		for j := range chunk {
+			i := index[start+j]
+			switch {
+			case err != nil:
+				results[i].Err = err
+			case j >= len(entries):
+				results[i].Err = fmt.Errorf("missing batch cancel result")
+			case entries[j].SCode != "0":
+				results[i].Err = apiError("cancel rejected", entries[j].SCode, entries[j].SMsg)
+			default:
+				results[i].Ack = &domain.CancelAck{
+					VenueID:   venueIDs[i],
+					Status:    domain.OrderStatusCancelled,
+					Timestamp: time.Now(),
+				}
+			}
+		}
+	}
+	return results
+}
+
+func (c *restClient) cancelOrder(ctx context.Context, venueID string) (*domain.CancelAck, error) {
+	instID, ordID, err := parseVenueOrderID(venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"instId": instID,
+		"ordId":  ordID,
+	}
+	if _, err := c.doRequest(ctx, "POST", "/api/v5/trade/cancel-order", body, domain.EndpointOrderCancel); err != nil {
+		return nil, err
+	}
+
+	return &domain.CancelAck{
+		VenueID:   venueID,
+		Status:    domain.OrderStatusCancelled,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// cancelAllAfter arms OKX's dead-man switch: unless called again within
+// timeout, OKX cancels every open order on the account. OKX accepts 10s to
+// 120s; timeout is clamped to that range.
+func (c *restClient) cancelAllAfter(ctx context.Context, timeout time.Duration) error {
+	secs := int(timeout / time.Second)
+	secs = max(10, min(secs, 120))
+	body := map[string]interface{}{
+		"timeOut": strconv.Itoa(secs),
+	}
+	_, err := c.doRequest(ctx, "POST", "/api/v5/trade/cancel-all-after", body, domain.EndpointOrderCancel)
+	return err
+}
+
+// getOrder looks up one order. Swap sizes come back in contracts and are
+// converted to base units like everywhere else in the gateway.
+func (c *restClient) getOrder(ctx context.Context, venueID string) (*domain.OrderUpdate, error) {
+	instID, ordID, err := parseVenueOrderID(venueID)
+	if err != nil {
+		return nil, err
+	}
+	update, err := c.queryOrder(ctx, instID, "ordId", ordID)
+	if err != nil {
+		return nil, err
+	}
+	update.VenueID = venueID
+	return update, nil
+}
+
+// getOrderByClOrdID looks up the order placed on symbol with clOrdID.
+func (c *restClient) getOrderByClOrdID(ctx context.Context, symbol, clOrdID string) (*domain.OrderUpdate, error) {
+	return c.queryOrder(ctx, domain.MapOKXSymbol(symbol), "clOrdId", clOrdID)
+}
+
+// queryOrder fetches the order on instID whose ordId or clOrdId, as key
+// says, is id.
+func (c *restClient) queryOrder(ctx context.Context, instID, key, id string) (*domain.OrderUpdate, error) {
+	path := "/api/v5/trade/order?instId=" + url.QueryEscape(instID) + "&" + key + "=" + url.QueryEscape(id)
+	data, err := c.doRequest(ctx, "GET", path, nil, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		OrdID     string `json:"ordId"`
+		ClOrdID   string `json:"clOrdId"`
+		AccFillSz string `json:"accFillSz"`
+		AvgPx     string `json:"avgPx"`
+		State     string `json:"state"`
+		UTime     string `json:"uTime"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse order: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("okx order %s %s not found", instID, id)
+	}
+	o := result[0]
+
+	update := &domain.OrderUpdate{
+		Venue:         "okx",
+		VenueID:       formatVenueOrderID(instID, o.OrdID),
+		ClientOrderID: o.ClOrdID,
+		Timestamp:     time.Now(),
+	}
+	if ms, err := strconv.ParseInt(o.UTime, 10, 64); err == nil && ms > 0 {
+		update.Timestamp = time.UnixMilli(ms)
+	}
+	filled, _ := domain.ParseDecimal(o.AccFillSz)
+	update.FilledSize = filled.Mul(contractValue(instID))
+	update.AvgFillPrice, _ = domain.ParseDecimal(o.AvgPx)
+
+	switch o.State {
+	case "live":
+		update.Status = domain.OrderStatusAcknowledged
+	case "partially_filled":
+		update.Status = domain.OrderStatusPartialFill
+	case "filled":
+		update.Status = domain.OrderStatusFilled
+	case "canceled", "mmp_canceled":
+		update.Status = domain.OrderStatusCancelled
+	default:
+		return nil, fmt.Errorf("unknown okx order state %q", o.State)
+	}
+	return update, nil
+}
+
+func (c *restClient) getOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
+	instID := domain.MapOKXSymbol(symbol)
+	path := "/api/v5/trade/orders-pending?instId=" + url.QueryEscape(instID)
+	data, err := c.doRequest(ctx, "GET", path, nil, domain.EndpointPrivateData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		OrdID     string `json:"ordId"`
+		InstID    string `json:"instId"`
+		Side      string `json:"side"`
+		OrdType   string `json:"ordType"`
+		Px        string `json:"px"`
+		Sz        string `json:"sz"`
+		AccFillSz string `json:"accFillSz"`
+		State     string `json:"state"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse open orders: %w", err)
+	}
+
+	ctVal := decimal.NewFromInt(1)
+	if domain.IsOKXSwap(symbol) {
+		ctVal = contractValue(instID)
+	}
+
+	orders := make([]domain.Order, 0, len(result))
+	for _, o := range result {
+		side := domain.SideBuy
+		if o.Side == "sell" {
+			side = domain.SideSell
+		}
+
+		orderType := domain.OrderTypeLimit
+		if o.OrdType == "market" {
+			orderType = domain.OrderTypeMarket
+		}
+
+		status := domain.OrderStatusAcknowledged
+		if o.State == "partially_filled" {
+			status = domain.OrderStatusPartialFill
+		}
+
+		order := domain.Order{
+			VenueID:   formatVenueOrderID(o.InstID, o.OrdID),
+			Venue:     "okx",
+			Symbol:    symbol,
+			Side:      side,
+			OrderType: orderType,
+			Status:    status,
+		}
+		order.Price, _ = domain.ParseDecimal(o.Px)
+		sz, _ := domain.ParseDecimal(o.Sz)
+		filled, _ := domain.ParseDecimal(o.AccFillSz)
+		order.Size = sz.Mul(ctVal)
+		order.FilledSize = filled.Mul(ctVal)
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// getBalances reads the unified trading account. Spot holdings and swap
+// margin share one balance per currency.
+func (c *restClient) getBalances(ctx context.Context) (map[string]domain.Balance, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/v5/account/balance", nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Details []struct {
+			Ccy       string `json:"ccy"`
+			Eq        string `json:"eq"`
+			AvailBal  string `json:"availBal"`
+			FrozenBal string `json:"frozenBal"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse balance: %w", err)
+	}
+
+	balances := make(map[string]domain.Balance)
+	for _, acct := range result {
+		for _, d := range acct.Details {
+			bal := domain.Balance{
+				Venue:   "okx",
+				Account: c.subAccount,
+				Asset:   d.Ccy,
+			}
+			bal.Free, _ = domain.ParseDecimal(d.AvailBal)
+			bal.Locked, _ = domain.ParseDecimal(d.FrozenBal)
+			bal.Total, _ = domain.ParseDecimal(d.Eq)
+			balances[d.Ccy] = bal
+		}
+	}
+
+	return balances, nil
+}
+
+func (c *restClient) getPositions(ctx context.Context) ([]domain.Position, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/v5/account/positions?instType=SWAP", nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		InstID  string `json:"instId"`
+		PosSide string `json:"posSide"`
+		Pos     string `json:"pos"`
+		AvgPx   string `json:"avgPx"`
+		Upl     string `json:"upl"`
+		Imr     string `json:"imr"`
+		Margin  string `json:"margin"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse positions: %w", err)
+	}
+
+	positions := make([]domain.Position, 0, len(result))
+	for _, p := range result {
+		contracts, _ := domain.ParseDecimal(p.Pos)
+		if contracts.IsZero() {
+			continue
+		}
+		// Net mode reports a signed size; long/short mode reports an
+		// unsigned size with posSide.
+		if p.PosSide == "short" && contracts.IsPositive() {
+			contracts = contracts.Neg()
+		}
+		pos := domain.Position{
+			Venue:          "okx",
+			Account:        c.subAccount,
+			Asset:          domain.ReverseMapSymbol(p.InstID, domain.OKXSwapSymbolMap),
+			InstrumentType: domain.InstrumentPerp,
+			Size:           contracts.Mul(contractValue(p.InstID)),
+			UpdatedAt:      time.Now(),
+		}
+		pos.EntryPrice, _ = domain.ParseDecimal(p.AvgPx)
+		pos.UnrealizedPnL, _ = domain.ParseDecimal(p.Upl)
+		// Cross positions report initial margin in imr; isolated in margin.
+		pos.MarginUsed, _ = domain.ParseDecimal(p.Imr)
+		if pos.MarginUsed.IsZero() {
+			pos.MarginUsed, _ = domain.ParseDecimal(p.Margin)
+		}
+		positions = append(positions, pos)
+	}
+
+	return positions, nil
+}
+
+func (c *restClient) getFeeTier(ctx context.Context) (*domain.FeeTier, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/v5/account/trade-fee?instType=SPOT", nil, domain.EndpointAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Level string `json:"level"`
+		Maker string `json:"maker"`
+		Taker string `json:"taker"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse fee tier: %w", err)
+	}
+
+	tier := &domain.FeeTier{
+		Venue:     "okx",
+		UpdatedAt: time.Now(),
+	}
+
+	// OKX reports fees charged as negative fractions (-0.001 = 10 bps cost)
+	// and rebates as positive.
+	bps := decimal.NewFromInt(-10000)
+	if len(result) > 0 {
+		maker, _ := domain.ParseDecimal(result[0].Maker)
+		taker, _ := domain.ParseDecimal(result[0].Taker)
+		tier.MakerFeeBps = maker.Mul(bps)
+		tier.TakerFeeBps = taker.Mul(bps)
+	}
+
+	return tier, nil
+}
+
+// getInstruments reads the tick, lot and minimum sizes of every mapped spot
+// and swap instrument. Swap lot and minimum sizes are in contracts and are
+// converted to base units with the instrument's contract value.
+func (c *restClient) getInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	spot, err := c.getPublicInstruments(ctx, "SPOT", domain.OKXSpotSymbolMap, domain.InstrumentSpot)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := c.getPublicInstruments(ctx, "SWAP", domain.OKXSwapSymbolMap, domain.InstrumentPerp)
+	if err != nil {
+		return nil, err
+	}
+	return append(spot, swap...), nil
+}
+
+func (c *restClient) getPublicInstruments(ctx context.Context, instType string, symbols map[string]string, kind domain.InstrumentType) ([]domain.Instrument, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/v5/public/instruments?instType="+instType, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		InstID string `json:"instId"`
+		TickSz string `json:"tickSz"`
+		LotSz  string `json:"lotSz"`
+		MinSz  string `json:"minSz"`
+		CtVal  string `json:"ctVal"` // empty for spot
+		State  string `json:"state"` // live, suspend, preopen, test
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse instruments: %w", err)
+	}
+
+	internal := make(map[string]string, len(symbols))
+	for k, v := range symbols {
+		internal[v] = k
+	}
+
+	var out []domain.Instrument
+	for _, r := range result {
+		symbol, ok := internal[r.InstID]
+		if !ok {
+			continue
+		}
+		multiplier, _ := domain.ParseDecimal(r.CtVal)
+		if !multiplier.IsPositive() {
+			multiplier = decimal.NewFromInt(1)
+		}
+		inst := domain.Instrument{
+			Venue:              "okx",
+			Symbol:             symbol,
+			InstrumentType:     kind,
+			ContractMultiplier: multiplier,
+			Suspended:          r.State != "live",
+		}
+		inst.TickSize, _ = domain.ParseDecimal(r.TickSz)
+		lot, _ := domain.ParseDecimal(r.LotSz)
+		minSz, _ := domain.ParseDecimal(r.MinSz)
+		inst.StepSize = lot.Mul(multiplier)
+		inst.MinSize = minSz.Mul(multiplier)
+		out = append(out, inst)
+	}
+	return out, nil
+}
+
+func (c *restClient) getOrderBook(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	instID := domain.MapOKXSymbol(symbol)
+	path := "/api/v5/market/books?sz=400&instId=" + url.QueryEscape(instID)
+	data, err := c.doRequest(ctx, "GET", path, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Asks [][]string `json:"asks"`
+		Bids [][]string `json:"bids"`
+		Ts   string     `json:"ts"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse orderbook: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty orderbook for %s", symbol)
+	}
+
+	book := &domain.OrderBookSnapshot{
+		Venue:          "okx",
+		Symbol:         symbol,
+		Bids:           parseLevels(result[0].Bids, instID),
+		Asks:           parseLevels(result[0].Asks, instID),
+		LocalTimestamp: time.Now(),
+	}
+	if ts, err := strconv.ParseInt(result[0].Ts, 10, 64); err == nil {
+		book.VenueTimestamp = time.UnixMilli(ts)
+	}
+
+	return book, nil
+}
+
+func (c *restClient) getFundingRate(ctx context.Context, symbol string) (*domain.FundingRate, error) {
+	instID := domain.MapOKXSymbol(symbol)
+	path := "/api/v5/public/funding-rate?instId=" + url.QueryEscape(instID)
+	data, err := c.doRequest(ctx, "GET", path, nil, domain.EndpointPublicData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		FundingRate     string `json:"fundingRate"`
+		FundingTime     string `json:"fundingTime"`
+		NextFundingTime string `json:"nextFundingTime"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse funding rate: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no funding rate returned for %s", symbol)
+	}
+
+	rate := &domain.FundingRate{
+		Venue:     "okx",
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+	}
+	rate.Rate, _ = domain.ParseDecimal(result[0].FundingRate)
+	if next, err := strconv.ParseInt(result[0].FundingTime, 10, 64); err == nil {
+		rate.NextTime = time.UnixMilli(next)
+	}
+
+	return rate, nil
+}
+
+// parseLevels converts OKX [price, size, _, numOrders] levels, scaling swap
+// sizes from contracts to base units.
+func parseLevels(raw [][]string, instID string) []domain.PriceLevel {
+	ctVal := contractValue(instID)
+	levels := make([]domain.PriceLevel, 0, len(raw))
+	for _, lvl := range raw {
+		if len(lvl) >= 2 {
+			price, _ := domain.ParseDecimal(lvl[0])
+			size, _ := domain.ParseDecimal(lvl[1])
+			levels = append(levels, domain.PriceLevel{Price: price, Size: size.Mul(ctVal)})
+		}
+	}
+	return levels
+}
+
+// ping checks that the public REST API answers.
+func (c *restClient) ping(ctx context.Context) error {
+	return gateway.ProbeREST(ctx, c.httpClient, c.baseURL+"/api/v5/public/time")
+}
+
+// serverTime returns OKX's clock, used to correct signing timestamps.
+func (c *restClient) serverTime(ctx context.Context) (time.Time, error) {
+	var resp struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Ts string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := gateway.FetchJSON(ctx, c.httpClient, c.baseURL+"/api/v5/public/time", &resp); err != nil {
+		return time.Time{}, err
+	}
+	if resp.Code != "0" || len(resp.Data) == 0 {
+		return time.Time{}, apiError("API error", resp.Code, resp.Msg)
+	}
+	ms, err := strconv.ParseInt(resp.Data[0].Ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse server time: %w", err)
+	}
+	return time.UnixMilli(ms), nil
+}
@@ -0,0 +1,723 @@
+package okx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+func okxOK(data interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"code": "0",
+		"msg":  "",
+		"data": data,
+	}
+}
+
+func newTestRESTClient(handler http.Handler) (*restClient, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	rl := gateway.NewRateLimiter()
+	rl.AddBucket(domain.EndpointPublicData, 100, 100)
+	rl.AddBucket(domain.EndpointPrivateData, 100, 100)
+	rl.AddBucket(domain.EndpointOrderPlace, 100, 100)
+	rl.AddBucket(domain.EndpointOrderCancel, 100, 100)
+	rl.AddBucket(domain.EndpointAccount, 100, 100)
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	client := newRESTClient(server.URL, "test-api-key", "test-api-secret", "test-passphrase", rl, logger)
+	return client, server
+}
+
+func TestOKXRestClient_PlaceOrder_SpotLimitOrder(t *testing.T) {
+	var capturedReq *http.Request
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "312269865356374016", "clOrdId": "idem123", "sCode": "0", "sMsg": ""},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID:     uuid.Must(uuid.NewV7()),
+		Symbol:         "BTC/USDT",
+		Side:           domain.SideBuy,
+		OrderType:      domain.OrderTypeLimit,
+		Price:          decimal.NewFromInt(50000),
+		Size:           decimal.NewFromFloat(0.1),
+		IdempotencyKey: "idem123",
+	}
+
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedReq.URL.Path != "/api/v5/trade/order" {
+		t.Errorf("expected path /api/v5/trade/order, got %s", capturedReq.URL.Path)
+	}
+	if capturedReq.Method != "POST" {
+		t.Errorf("expected POST, got %s", capturedReq.Method)
+	}
+
+	// Verify OKX auth headers
+	if capturedReq.Header.Get("OK-ACCESS-KEY") != "test-api-key" {
+		t.Errorf("expected OK-ACCESS-KEY header, got %q", capturedReq.Header.Get("OK-ACCESS-KEY"))
+	}
+	if capturedReq.Header.Get("OK-ACCESS-PASSPHRASE") != "test-passphrase" {
+		t.Errorf("expected OK-ACCESS-PASSPHRASE header, got %q", capturedReq.Header.Get("OK-ACCESS-PASSPHRASE"))
+	}
+	if capturedReq.Header.Get("OK-ACCESS-SIGN") == "" {
+		t.Error("expected OK-ACCESS-SIGN header to be set")
+	}
+	if capturedReq.Header.Get("OK-ACCESS-TIMESTAMP") == "" {
+		t.Error("expected OK-ACCESS-TIMESTAMP header to be set")
+	}
+
+	if capturedBody["instId"] != "BTC-USDT" {
+		t.Errorf("expected instId BTC-USDT, got %v", capturedBody["instId"])
+	}
+	if capturedBody["tdMode"] != "cash" {
+		t.Errorf("expected tdMode=cash for spot, got %v", capturedBody["tdMode"])
+	}
+	if capturedBody["side"] != "buy" || capturedBody["ordType"] != "limit" {
+		t.Errorf("expected buy limit, got %v %v", capturedBody["side"], capturedBody["ordType"])
+	}
+	if capturedBody["sz"] != "0.1" || capturedBody["px"] != "50000" {
+		t.Errorf("expected sz=0.1 px=50000, got %v %v", capturedBody["sz"], capturedBody["px"])
+	}
+	if capturedBody["clOrdId"] != "idem123" {
+		t.Errorf("expected clOrdId=idem123, got %v", capturedBody["clOrdId"])
+	}
+
+	if ack.VenueID != "BTC-USDT:312269865356374016" {
+		t.Errorf("expected encoded venue ID, got %s", ack.VenueID)
+	}
+	if ack.Status != domain.OrderStatusAcknowledged {
+		t.Errorf("expected ACKNOWLEDGED, got %s", ack.Status)
+	}
+}
+
+func TestOKXRestClient_PlaceOrder_TimeInForce(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "1", "sCode": "0", "sMsg": ""},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	tests := []struct {
+		tif      domain.TimeInForce
+		postOnly bool
+		want     string
+	}{
+		{"", false, "limit"},
+		{domain.TimeInForceIOC, false, "ioc"},
+		{domain.TimeInForceFOK, false, "fok"},
+		{"", true, "post_only"},
+	}
+	for _, tt := range tests {
+		req := domain.OrderRequest{
+			InternalID:  uuid.Must(uuid.NewV7()),
+			Symbol:      "BTC/USDT",
+			Side:        domain.SideBuy,
+			OrderType:   domain.OrderTypeLimit,
+			TimeInForce: tt.tif,
+			PostOnly:    tt.postOnly,
+			Price:       decimal.NewFromInt(50000),
+			Size:        decimal.NewFromFloat(0.1),
+		}
+		if _, err := client.placeOrder(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if capturedBody["ordType"] != tt.want {
+			t.Errorf("tif=%q postOnly=%v: expected ordType %s, got %v", tt.tif, tt.postOnly, tt.want, capturedBody["ordType"])
+		}
+	}
+}
+
+func TestOKXRestClient_PlaceOrder_SwapMarketOrder(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "777", "sCode": "0", "sMsg": ""},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	req := domain.OrderRequest{
+		InternalID:     uuid.Must(uuid.NewV7()),
+		Symbol:         "BTCUSDT",
+		Side:           domain.SideSell,
+		OrderType:      domain.OrderTypeMarket,
+		Size:           decimal.NewFromFloat(0.25),
+		ReduceOnly:     true,
+		IdempotencyKey: "idem456",
+	}
+
+	ack, err := client.placeOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedBody["instId"] != "BTC-USDT-SWAP" {
+		t.Errorf("expected instId BTC-USDT-SWAP, got %v", capturedBody["instId"])
+	}
+	if capturedBody["tdMode"] != "cross" {
+		t.Errorf("expected tdMode=cross for swap, got %v", capturedBody["tdMode"])
+	}
+	// 0.25 BTC at 0.01 BTC per contract = 25 contracts
+	if capturedBody["sz"] != "25" {
+		t.Errorf("expected sz=25 contracts, got %v", capturedBody["sz"])
+	}
+	if _, ok := capturedBody["px"]; ok {
+		t.Error("market order should not carry px")
+	}
+	if _, ok := capturedBody["tgtCcy"]; ok {
+		t.Error("swap order should not carry tgtCcy")
+	}
+	if capturedBody["reduceOnly"] != true {
+		t.Errorf("expected reduceOnly=true, got %v", capturedBody["reduceOnly"])
+	}
+	if ack.VenueID != "BTC-USDT-SWAP:777" {
+		t.Errorf("expected encoded venue ID, got %s", ack.VenueID)
+	}
+}
+
+func TestOKXRestClient_PlaceOrder_Rejected(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "", "sCode": "51008", "sMsg": "Order failed. Insufficient balance"},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	_, err := client.placeOrder(context.Background(), domain.OrderRequest{
+		Symbol:    "ETH/USDT",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeLimit,
+		Price:     decimal.NewFromInt(3000),
+		Size:      decimal.NewFromInt(1),
+	})
+	if err == nil {
+		t.Fatal("expected error for rejected order")
+	}
+	if !strings.Contains(err.Error(), "code=51008") {
+		t.Errorf("expected error to contain code=51008, got %v", err)
+	}
+	if c := gateway.ErrorCategoryOf(err); c != gateway.ErrorInsufficientBalance {
+		t.Errorf("expected insufficient_balance, got %q", c)
+	}
+}
+
+func TestOKXRestClient_PlaceOrder_InstrumentGone(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "", "sCode": "51001", "sMsg": "Instrument ID does not exist"},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	_, err := client.placeOrder(context.Background(), domain.OrderRequest{
+		Symbol:    "ETH/USDT",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeLimit,
+		Price:     decimal.NewFromInt(3000),
+		Size:      decimal.NewFromInt(1),
+	})
+	if !errors.Is(err, gateway.ErrSymbolUnavailable) {
+		t.Errorf("expected ErrSymbolUnavailable, got %v", err)
+	}
+}
+
+func TestOKXRestClient_CancelOrder(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/trade/cancel-order" {
+			t.Errorf("expected path /api/v5/trade/cancel-order, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "777", "sCode": "0", "sMsg": ""},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	ack, err := client.cancelOrder(context.Background(), "BTC-USDT-SWAP:777")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedBody["instId"] != "BTC-USDT-SWAP" || capturedBody["ordId"] != "777" {
+		t.Errorf("unexpected cancel body: %v", capturedBody)
+	}
+	if ack.Status != domain.OrderStatusCancelled {
+		t.Errorf("expected CANCELLED, got %s", ack.Status)
+	}
+
+	if _, err := client.cancelOrder(context.Background(), "777"); err == nil {
+		t.Error("expected error for venue ID without instrument")
+	}
+}
+
+func TestOKXRestClient_CancelAllAfter(t *testing.T) {
+	var timeouts []interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/trade/cancel-all-after" {
+			t.Errorf("expected path /api/v5/trade/cancel-all-after, got %s", r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		timeouts = append(timeouts, body["timeOut"])
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{{"triggerTime": "0", "ts": "0"}}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	for _, d := range []time.Duration{30 * time.Second, 5 * time.Second, 10 * time.Minute} {
+		if err := client.cancelAllAfter(context.Background(), d); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(timeouts) != 3 || timeouts[0] != "30" || timeouts[1] != "10" || timeouts[2] != "120" {
+		t.Errorf("expected timeouts clamped to 10..120, got %v", timeouts)
+	}
+}
+
+func TestOKXRestClient_PlaceOrders(t *testing.T) {
+	var capturedBody []map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/trade/batch-orders" {
+			t.Errorf("expected path /api/v5/trade/batch-orders, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		// Code 2 is a partial success; each entry carries its own sCode.
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": "2",
+			"msg":  "",
+			"data": []map[string]interface{}{
+				{"ordId": "101", "sCode": "0", "sMsg": ""},
+				{"ordId": "", "sCode": "51008", "sMsg": "Insufficient balance"},
+			},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	reqs := []domain.OrderRequest{
+		{
+			InternalID: uuid.Must(uuid.NewV7()),
+			Symbol:     "BTC/USDT",
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(50000),
+			Size:       decimal.NewFromFloat(0.1),
+		},
+		{
+			InternalID: uuid.Must(uuid.NewV7()),
+			Symbol:     "BTCUSDT",
+			Side:       domain.SideSell,
+			OrderType:  domain.OrderTypeStopMarket,
+			StopPrice:  decimal.NewFromInt(48000),
+			Size:       decimal.NewFromFloat(0.1),
+		},
+		{
+			InternalID: uuid.Must(uuid.NewV7()),
+			Symbol:     "BTCUSDT",
+			Side:       domain.SideSell,
+			OrderType:  domain.OrderTypeMarket,
+			Size:       decimal.NewFromFloat(0.1),
+		},
+	}
+
+	results := client.placeOrders(context.Background(), reqs)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	// The unsupported stop is rejected locally and never sent.
+	if len(capturedBody) != 2 {
+		t.Fatalf("expected 2 orders in batch, got %d", len(capturedBody))
+	}
+	if capturedBody[0]["instId"] != "BTC-USDT" || capturedBody[1]["instId"] != "BTC-USDT-SWAP" {
+		t.Errorf("unexpected batch body: %v", capturedBody)
+	}
+
+	if results[0].Err != nil || results[0].Ack.VenueID != "BTC-USDT:101" {
+		t.Errorf("leg 0: got %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, gateway.ErrOrderTypeUnsupported) {
+		t.Errorf("leg 1: expected ErrOrderTypeUnsupported, got %v", results[1].Err)
+	}
+	if results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "51008") {
+		t.Errorf("leg 2: expected per-item rejection, got %v", results[2].Err)
+	}
+}
+
+func TestOKXRestClient_CancelOrders(t *testing.T) {
+	var capturedBody []map[string]interface{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/trade/cancel-batch-orders" {
+			t.Errorf("expected path /api/v5/trade/cancel-batch-orders, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&capturedBody)
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "777", "sCode": "0", "sMsg": ""},
+			{"ordId": "778", "sCode": "0", "sMsg": ""},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	results := client.cancelOrders(context.Background(), []string{"BTC-USDT-SWAP:777", "778", "BTC-USDT:778"})
+
+	if len(capturedBody) != 2 || capturedBody[1]["instId"] != "BTC-USDT" {
+		t.Errorf("unexpected cancel body: %v", capturedBody)
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("unexpected errors: %v, %v", results[0].Err, results[2].Err)
+	}
+	if results[1].Err == nil {
+		t.Error("expected error for venue ID without instrument")
+	}
+}
+
+func TestOKXRestClient_PartialCodeOnlyOnBatch(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": "2",
+			"msg":  "",
+			"data": []map[string]interface{}{
+				{"ordId": "777", "sCode": "0", "sMsg": ""},
+				{"ordId": "778", "sCode": "51400", "sMsg": "Order does not exist"},
+			},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	// A single-order endpoint never answers with code 2; it is an error.
+	_, err := client.placeOrder(context.Background(), domain.OrderRequest{
+		InternalID: uuid.Must(uuid.NewV7()),
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideBuy,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(50000),
+		Size:       decimal.NewFromFloat(0.1),
+	})
+	if err == nil {
+		t.Error("expected code 2 on the single-order endpoint to fail")
+	}
+
+	results := client.cancelOrders(context.Background(), []string{"BTC-USDT:777", "BTC-USDT:778"})
+	if results[0].Err != nil {
+		t.Errorf("cancel 0: unexpected error %v", results[0].Err)
+	}
+	if c := gateway.ErrorCategoryOf(results[1].Err); c != gateway.ErrorOrderNotFound {
+		t.Errorf("cancel 1: expected the per-item order_not_found, got %s (%v)", c, results[1].Err)
+	}
+}
+
+func TestOKXRestClient_GetBalances(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/account/balance" {
+			t.Errorf("expected path /api/v5/account/balance, got %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{
+				"totalEq": "75000",
+				"details": []map[string]interface{}{
+					{"ccy": "USDT", "eq": "10000", "availBal": "7500", "frozenBal": "2500"},
+					{"ccy": "BTC", "eq": "1.2", "availBal": "1.2", "frozenBal": "0"},
+				},
+			},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	balances, err := client.getBalances(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	usdt := balances["USDT"]
+	if !usdt.Free.Equal(decimal.NewFromInt(7500)) {
+		t.Errorf("expected USDT free 7500, got %s", usdt.Free)
+	}
+	if !usdt.Locked.Equal(decimal.NewFromInt(2500)) {
+		t.Errorf("expected USDT locked 2500, got %s", usdt.Locked)
+	}
+	if !usdt.Total.Equal(decimal.NewFromInt(10000)) {
+		t.Errorf("expected USDT total 10000, got %s", usdt.Total)
+	}
+	if balances["BTC"].Venue != "okx" {
+		t.Errorf("expected venue okx, got %s", balances["BTC"].Venue)
+	}
+}
+
+func TestOKXRestClient_GetPositions(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("instType") != "SWAP" {
+			t.Errorf("expected instType=SWAP, got %s", r.URL.Query().Get("instType"))
+		}
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"instId": "BTC-USDT-SWAP", "posSide": "net", "pos": "-30", "avgPx": "61000", "upl": "-4.2", "imr": "1830", "margin": ""},
+			{"instId": "ETH-USDT-SWAP", "posSide": "long", "pos": "20", "avgPx": "3000", "upl": "12", "imr": "", "margin": "600"},
+			{"instId": "SOL-USDT-SWAP", "posSide": "net", "pos": "0", "avgPx": "", "upl": "0", "imr": "0", "margin": "0"},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	positions, err := client.getPositions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 open positions, got %d", len(positions))
+	}
+
+	btc := positions[0]
+	// -30 contracts * 0.01 BTC = -0.3 BTC
+	if !btc.Size.Equal(decimal.NewFromFloat(-0.3)) {
+		t.Errorf("expected short size -0.3, got %s", btc.Size)
+	}
+	if !btc.MarginUsed.Equal(decimal.NewFromInt(1830)) {
+		t.Errorf("expected margin 1830, got %s", btc.MarginUsed)
+	}
+	if btc.Asset != "BTCUSDT" {
+		t.Errorf("expected asset BTCUSDT, got %s", btc.Asset)
+	}
+
+	eth := positions[1]
+	// 20 contracts * 0.1 ETH = 2 ETH
+	if !eth.Size.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected long size 2, got %s", eth.Size)
+	}
+	if !eth.MarginUsed.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("expected isolated margin 600, got %s", eth.MarginUsed)
+	}
+}
+
+func TestOKXRestClient_GetOpenOrders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("instId") != "ETH-USDT-SWAP" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "o1", "instId": "ETH-USDT-SWAP", "side": "buy", "ordType": "limit", "px": "3000", "sz": "20", "accFillSz": "5", "state": "partially_filled"},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	orders, err := client.getOpenOrders(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(orders) != 1 {
+		t.Fatalf("expected 1 order, got %d", len(orders))
+	}
+	o := orders[0]
+	if o.VenueID != "ETH-USDT-SWAP:o1" || o.Status != domain.OrderStatusPartialFill {
+		t.Errorf("unexpected order: %+v", o)
+	}
+	if !o.Size.Equal(decimal.NewFromInt(2)) || !o.FilledSize.Equal(decimal.NewFromFloat(0.5)) {
+		t.Errorf("expected size 2 filled 0.5 in base units, got %s filled %s", o.Size, o.FilledSize)
+	}
+}
+
+func TestOKXRestClient_GetFeeTier(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"level": "Lv1", "maker": "-0.0008", "taker": "-0.001"},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	tier, err := client.getFeeTier(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !tier.MakerFeeBps.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("expected maker 8 bps, got %s", tier.MakerFeeBps)
+	}
+	if !tier.TakerFeeBps.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected taker 10 bps, got %s", tier.TakerFeeBps)
+	}
+}
+
+func TestOKXRestClient_GetInstruments(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("instType") == "SWAP" {
+			json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+				{"instId": "BTC-USDT-SWAP", "tickSz": "0.1", "lotSz": "0.1", "minSz": "0.1", "ctVal": "0.01"},
+			}))
+			return
+		}
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"instId": "BTC-USDT", "tickSz": "0.1", "lotSz": "0.00000001", "minSz": "0.00001", "ctVal": ""},
+			{"instId": "DOGE-USDT", "tickSz": "0.00001", "lotSz": "0.000001", "minSz": "10", "ctVal": ""},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	instruments, err := client.getInstruments(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(instruments) != 2 {
+		t.Fatalf("expected 2 instruments (unmapped symbols skipped), got %d", len(instruments))
+	}
+
+	spot, swap := instruments[0], instruments[1]
+	if spot.Symbol != "BTC/USDT" || !spot.ContractMultiplier.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("unexpected spot instrument %s multiplier %s", spot.Symbol, spot.ContractMultiplier)
+	}
+	// 0.1 contracts * 0.01 BTC = 0.001 BTC
+	if swap.Symbol != "BTCUSDT" || !swap.StepSize.Equal(decimal.RequireFromString("0.001")) || !swap.MinSize.Equal(decimal.RequireFromString("0.001")) {
+		t.Errorf("unexpected swap instrument %s step %s min %s", swap.Symbol, swap.StepSize, swap.MinSize)
+	}
+	if !swap.ContractMultiplier.Equal(decimal.RequireFromString("0.01")) {
+		t.Errorf("expected contract multiplier 0.01, got %s", swap.ContractMultiplier)
+	}
+}
+
+func TestOKXRestClient_APIError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": "50113",
+			"msg":  "Invalid Sign",
+			"data": []interface{}{},
+		})
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	_, err := client.getBalances(context.Background())
+	if err == nil {
+		t.Fatal("expected error for non-zero code")
+	}
+	if !strings.Contains(err.Error(), "code=50113") {
+		t.Errorf("expected error to contain code=50113, got %v", err)
+	}
+}
+
+func TestOKXRestClient_SignatureCoversPathAndBody(t *testing.T) {
+	var capturedReq *http.Request
+	var capturedBody []byte
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r
+		capturedBody, _ = io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{"ordId": "1", "sCode": "0", "sMsg": ""},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	if _, err := client.cancelOrder(context.Background(), "BTC-USDT:1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ts := capturedReq.Header.Get("OK-ACCESS-TIMESTAMP")
+	mac := hmac.New(sha256.New, []byte("test-api-secret"))
+	mac.Write([]byte(ts + "POST" + "/api/v5/trade/cancel-order" + string(capturedBody)))
+	want := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if got := capturedReq.Header.Get("OK-ACCESS-SIGN"); got != want {
+		t.Errorf("signature mismatch: got %s, want %s", got, want)
+	}
+}
+
+func TestOKXRestClient_GetOrderBook(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("instId") != "BTC-USDT-SWAP" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(okxOK([]map[string]interface{}{
+			{
+				"asks": [][]string{{"61001", "12", "0", "3"}},
+				"bids": [][]string{{"61000", "40", "0", "5"}},
+				"ts":   "1700000000000",
+			},
+		}))
+	})
+
+	client, server := newTestRESTClient(handler)
+	defer server.Close()
+
+	book, err := client.getOrderBook(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(book.Bids) != 1 || len(book.Asks) != 1 {
+		t.Fatalf("expected one level per side, got %d bids %d asks", len(book.Bids), len(book.Asks))
+	}
+	// 40 contracts * 0.01 BTC = 0.4 BTC
+	if !book.Bids[0].Size.Equal(decimal.NewFromFloat(0.4)) {
+		t.Errorf("expected bid size 0.4, got %s", book.Bids[0].Size)
+	}
+	if book.Symbol != "BTCUSDT" {
+		t.Errorf("expected internal symbol BTCUSDT, got %s", book.Symbol)
+	}
+}
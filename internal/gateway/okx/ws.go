@@ -0,0 +1,439 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+type wsClient struct {
+	url    string
+	conn   *websocket.Conn
+	mu     sync.Mutex
+	logger *slog.Logger
+
+	reconnectMax  time.Duration
+	reconnectBase time.Duration
+	maxFailures   int
+
+	subscriptions []wsArg
+	subMu         sync.Mutex
+	onReconnect   func() // called after each successful reconnect, if set
+	state         gateway.WSState
+	egress        gateway.Egress
+	latency       gateway.WSLatency
+	pingInterval  time.Duration
+	stopPing      chan struct{}
+	pumpOnce      sync.Once
+
+	orderBookChans map[string]chan domain.OrderBookDelta
+	tradeChans     map[string]chan domain.Trade
+	fundingChans   map[string]chan domain.FundingRate
+	chanMu         sync.RWMutex
+}
+
+type wsArg struct {
+	Channel string `json:"channel"`
+	InstID  string `json:"instId"`
+}
+
+func newWSClient(url string, logger *slog.Logger) *wsClient {
+	return &wsClient{
+		url:            url,
+		logger:         logger,
+		reconnectBase:  100 * time.Millisecond,
+		reconnectMax:   30 * time.Second,
+		maxFailures:    5,
+		pingInterval:   25 * time.Second,
+		orderBookChans: make(map[string]chan domain.OrderBookDelta),
+		tradeChans:     make(map[string]chan domain.Trade),
+		fundingChans:   make(map[string]chan domain.FundingRate),
+	}
+}
+
+func (ws *wsClient) connect(ctx context.Context) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	dialer := ws.egress.WSDialer()
+
+	conn, _, err := dialer.DialContext(ctx, ws.url, nil)
+	if err != nil {
+		return fmt.Errorf("websocket connect to %s: %w", ws.url, err)
+	}
+
+	if ws.stopPing != nil {
+		close(ws.stopPing)
+	}
+	ws.conn = conn
+	ws.state.SetConnected(true)
+	ws.stopPing = make(chan struct{})
+	go ws.pingLoop(ws.stopPing)
+
+	ws.logger.Info("okx websocket connected", "url", ws.url)
+	return nil
+}
+
+// pingLoop sends the plain-text "ping" OKX expects; the server closes
+// connections that stay silent for 30 seconds.
+func (ws *wsClient) pingLoop(stop chan struct{}) {
+	ticker := time.NewTicker(ws.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ws.mu.Lock()
+			if ws.conn != nil {
+				if err := ws.conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+					ws.logger.Warn("okx websocket ping failed", "error", err)
+				}
+			}
+			ws.mu.Unlock()
+		}
+	}
+}
+
+func (ws *wsClient) reconnect(ctx context.Context) error {
+	delay := ws.reconnectBase
+	for i := 0; i < ws.maxFailures; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if err := ws.connect(ctx); err != nil {
+			ws.logger.Warn("okx reconnect attempt failed",
+				"attempt", i+1, "error", err)
+			delay *= 2
+			if delay > ws.reconnectMax {
+				delay = ws.reconnectMax
+			}
+			continue
+		}
+		if subs := ws.activeSubscriptions(); len(subs) > 0 {
+			if err := ws.sendSubscribe(subs...); err != nil {
+				ws.logger.Warn("failed to resubscribe after reconnect", "error", err)
+			}
+		}
+		if ws.onReconnect != nil {
+			ws.onReconnect()
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to reconnect after %d attempts", ws.maxFailures)
+}
+
+// activeSubscriptions returns a copy of the subscriptions to restore after a
+// reconnect.
+func (ws *wsClient) activeSubscriptions() []wsArg {
+	ws.subMu.Lock()
+	defer ws.subMu.Unlock()
+	subs := make([]wsArg, len(ws.subscriptions))
+	copy(subs, ws.subscriptions)
+	return subs
+}
+
+// subscribe registers a channel for an instrument and starts the read pump
+// on first use.
+func (ws *wsClient) subscribe(ctx context.Context, channel, instID string) error {
+	arg := wsArg{Channel: channel, InstID: instID}
+	ws.subMu.Lock()
+	ws.subscriptions = append(ws.subscriptions, arg)
+	ws.subMu.Unlock()
+	if err := ws.sendSubscribe(arg); err != nil {
+		return err
+	}
+	ws.pumpOnce.Do(func() { go ws.readPump(ctx) })
+	return nil
+}
+
+func (ws *wsClient) sendSubscribe(args ...wsArg) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.conn == nil {
+		return fmt.Errorf("websocket not connected")
+	}
+
+	msg := map[string]interface{}{
+		"op":   "subscribe",
+		"args": args,
+	}
+	return ws.conn.WriteJSON(msg)
+}
+
+func (ws *wsClient) readPump(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		ws.mu.Lock()
+		conn := ws.conn
+		ws.mu.Unlock()
+
+		if conn == nil {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			ws.state.SetConnected(false)
+			ws.logger.Error("okx websocket read error", "error", err)
+			if reconnErr := ws.reconnect(ctx); reconnErr != nil {
+				ws.logger.Error("okx reconnection failed permanently", "error", reconnErr)
+				return
+			}
+			continue
+		}
+
+		ws.state.Touch()
+		ws.handleMessage(message)
+	}
+}
+
+func (ws *wsClient) handleMessage(msg []byte) {
+	if string(msg) == "pong" {
+		return
+	}
+
+	var raw struct {
+		Event  string          `json:"event"`
+		Arg    wsArg           `json:"arg"`
+		Action string          `json:"action"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		ws.logger.Debug("failed to parse okx websocket message", "error", err)
+		return
+	}
+
+	// Event messages (subscribe, error) carry no data.
+	if raw.Event != "" {
+		if raw.Event == "error" {
+			ws.logger.Warn("okx websocket error event", "message", string(msg))
+		}
+		return
+	}
+
+	switch raw.Arg.Channel {
+	case "books":
+		ws.handleOrderBookMessage(raw.Arg.InstID, raw.Action, raw.Data)
+	case "trades":
+		ws.handleTradeMessage(raw.Arg.InstID, raw.Data)
+	case "funding-rate":
+		ws.handleFundingMessage(raw.Arg.InstID, raw.Data)
+	}
+}
+
+// internalSymbol maps an OKX instrument ID back to the internal symbol.
+func internalSymbol(instID string) string {
+	if s := domain.ReverseMapSymbol(instID, domain.OKXSwapSymbolMap); s != instID {
+		return s
+	}
+	return domain.ReverseMapSymbol(instID, domain.OKXSpotSymbolMap)
+}
+
+func (ws *wsClient) handleOrderBookMessage(instID, action string, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.orderBookChans[instID]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var updates []struct {
+		Asks      [][]string `json:"asks"`
+		Bids      [][]string `json:"bids"`
+		Ts        string     `json:"ts"`
+		Checksum  int32      `json:"checksum"`
+		SeqID     int64      `json:"seqId"`
+		PrevSeqID int64      `json:"prevSeqId"`
+	}
+	if err := json.Unmarshal(data, &updates); err != nil {
+		ws.logger.Warn("failed to parse okx orderbook update", "error", err)
+		return
+	}
+
+	symbol := internalSymbol(instID)
+	// Swap sizes are converted from contracts, so the book no longer holds
+	// the strings OKX's checksum is taken over.
+	checksummed := contractValue(instID).Equal(decimal.NewFromInt(1))
+	for _, u := range updates {
+		delta := domain.OrderBookDelta{
+			Venue:          "okx",
+			Symbol:         symbol,
+			Bids:           parseLevels(u.Bids, instID),
+			Asks:           parseLevels(u.Asks, instID),
+			Snapshot:       action == "snapshot",
+			LocalTimestamp: time.Now(),
+		}
+		if u.SeqID > 0 {
+			delta.Sequence = uint64(u.SeqID)
+		}
+		// An update follows on from prevSeqId. After maintenance OKX may
+		// restart seqId below it, and that update is applied unchecked.
+		if !delta.Snapshot && u.PrevSeqID >= 0 && u.SeqID >= u.PrevSeqID {
+			delta.FirstSequence = uint64(u.PrevSeqID) + 1
+		}
+		if checksummed {
+			delta.Checksum = uint32(u.Checksum)
+		}
+		if ts, err := strconv.ParseInt(u.Ts, 10, 64); err == nil {
+			delta.VenueTimestamp = time.UnixMilli(ts)
+		}
+
+		ws.latency.Observe("book", delta.VenueTimestamp)
+		select {
+		case ch <- delta:
+		default:
+			ws.logger.Debug("okx orderbook channel full, dropping update", "symbol", instID)
+		}
+	}
+}
+
+func (ws *wsClient) handleTradeMessage(instID string, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.tradeChans[instID]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var trades []struct {
+		TradeID string `json:"tradeId"`
+		Px      string `json:"px"`
+		Sz      string `json:"sz"`
+		Side    string `json:"side"`
+		Ts      string `json:"ts"`
+	}
+	if err := json.Unmarshal(data, &trades); err != nil {
+		ws.logger.Warn("failed to parse okx trade update", "error", err)
+		return
+	}
+
+	symbol := internalSymbol(instID)
+	ctVal := contractValue(instID)
+	for _, t := range trades {
+		side := domain.SideBuy
+		if t.Side == "sell" {
+			side = domain.SideSell
+		}
+
+		trade := domain.Trade{
+			Venue:     "okx",
+			Symbol:    symbol,
+			Side:      side,
+			TradeID:   t.TradeID,
+			Timestamp: time.Now(),
+		}
+		trade.Price, _ = domain.ParseDecimal(t.Px)
+		size, _ := domain.ParseDecimal(t.Sz)
+		trade.Size = size.Mul(ctVal)
+		if ts, err := strconv.ParseInt(t.Ts, 10, 64); err == nil {
+			trade.Timestamp = time.UnixMilli(ts)
+		}
+
+		select {
+		case ch <- trade:
+		default:
+			ws.logger.Debug("okx trade channel full, dropping update", "symbol", instID)
+		}
+	}
+}
+
+func (ws *wsClient) handleFundingMessage(instID string, data json.RawMessage) {
+	ws.chanMu.RLock()
+	ch, ok := ws.fundingChans[instID]
+	ws.chanMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var updates []struct {
+		FundingRate string `json:"fundingRate"`
+		FundingTime string `json:"fundingTime"`
+		Ts          string `json:"ts"`
+	}
+	if err := json.Unmarshal(data, &updates); err != nil {
+		ws.logger.Warn("failed to parse okx funding rate", "error", err)
+		return
+	}
+
+	symbol := internalSymbol(instID)
+	for _, u := range updates {
+		rate := domain.FundingRate{
+			Venue:     "okx",
+			Symbol:    symbol,
+			Timestamp: time.Now(),
+		}
+		rate.Rate, _ = domain.ParseDecimal(u.FundingRate)
+		if ts, err := strconv.ParseInt(u.Ts, 10, 64); err == nil {
+			rate.Timestamp = time.UnixMilli(ts)
+		}
+		if next, err := strconv.ParseInt(u.FundingTime, 10, 64); err == nil {
+			rate.NextTime = time.UnixMilli(next)
+		}
+
+		select {
+		case ch <- rate:
+		default:
+			ws.logger.Debug("okx funding channel full, dropping update", "symbol", instID)
+		}
+	}
+}
+
+func (ws *wsClient) subscribeOrderBook(instID string) <-chan domain.OrderBookDelta {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.OrderBookDelta, 256)
+	ws.orderBookChans[instID] = ch
+	return ch
+}
+
+func (ws *wsClient) subscribeTrades(instID string) <-chan domain.Trade {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.Trade, 256)
+	ws.tradeChans[instID] = ch
+	return ch
+}
+
+func (ws *wsClient) subscribeFunding(instID string) <-chan domain.FundingRate {
+	ws.chanMu.Lock()
+	defer ws.chanMu.Unlock()
+	ch := make(chan domain.FundingRate, 256)
+	ws.fundingChans[instID] = ch
+	return ch
+}
+
+func (ws *wsClient) close() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.state.SetConnected(false)
+	if ws.stopPing != nil {
+		close(ws.stopPing)
+		ws.stopPing = nil
+	}
+	if ws.conn != nil {
+		return ws.conn.Close()
+	}
+	return nil
+}
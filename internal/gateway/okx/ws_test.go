@@ -0,0 +1,59 @@
+package okx
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestOKXWSClient_HandleOrderBookMessage_Sequences(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	ws := newWSClient("", logger)
+	ch := ws.subscribeOrderBook("BTC-USDT")
+
+	next := func() domain.OrderBookDelta {
+		t.Helper()
+		select {
+		case delta := <-ch:
+			return delta
+		default:
+			t.Fatal("expected an order book update")
+			return domain.OrderBookDelta{}
+		}
+	}
+
+	ws.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["50001","2","0","1"]],"bids":[["50000","1","0","1"]],"ts":"1700000000000","checksum":-855196043,"prevSeqId":-1,"seqId":10}]}`))
+	delta := next()
+	if !delta.Snapshot || delta.FirstSequence != 0 || delta.Sequence != 10 {
+		t.Errorf("expected a snapshot at 10, got snapshot=%v %d..%d", delta.Snapshot, delta.FirstSequence, delta.Sequence)
+	}
+	if want := int32(-855196043); delta.Checksum != uint32(want) {
+		t.Errorf("expected the signed checksum carried as its CRC32 bits, got %d", delta.Checksum)
+	}
+
+	ws.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[["50000","0","0","0"]],"ts":"1700000000100","checksum":123,"prevSeqId":10,"seqId":12}]}`))
+	if delta := next(); delta.Snapshot || delta.FirstSequence != 11 || delta.Sequence != 12 || delta.Checksum != 123 {
+		t.Errorf("expected an update following on from 10, got snapshot=%v %d..%d checksum %d", delta.Snapshot, delta.FirstSequence, delta.Sequence, delta.Checksum)
+	}
+
+	// After maintenance seqId may restart below prevSeqId.
+	ws.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[],"bids":[],"ts":"1700000000200","checksum":5,"prevSeqId":12,"seqId":3}]}`))
+	if delta := next(); delta.FirstSequence != 0 || delta.Sequence != 3 {
+		t.Errorf("expected a reset update left unchecked, got %d..%d", delta.FirstSequence, delta.Sequence)
+	}
+
+	// Swap sizes are converted from contracts, so OKX's checksum cannot be
+	// reproduced over them.
+	swap := ws.subscribeOrderBook("BTC-USDT-SWAP")
+	ws.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT-SWAP"},"action":"update","data":[{"asks":[["50001","5","0","1"]],"bids":[],"ts":"1700000000300","checksum":77,"prevSeqId":40,"seqId":41}]}`))
+	select {
+	case delta := <-swap:
+		if delta.Checksum != 0 || delta.FirstSequence != 41 {
+			t.Errorf("expected a sequenced swap update without checksum, got %d..%d checksum %d", delta.FirstSequence, delta.Sequence, delta.Checksum)
+		}
+	default:
+		t.Fatal("expected the swap update to be parsed")
+	}
+}
@@ -2,32 +2,73 @@ package gateway
 
 import (
 	"context"
+	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/crypto-trading/trading/internal/domain"
 )
 
+const (
+	// capacityRecovery is how long a bucket shrunk by throttling takes to grow
+	// back to its configured capacity.
+	capacityRecovery = time.Minute
+	// defaultBackoff is the pause after a 429 without Retry-After. It doubles
+	// with each consecutive 429 up to maxBackoff.
+	defaultBackoff = time.Second
+	maxBackoff     = time.Minute
+	// idlePoll is how often a waiter rechecks a bucket that does not refill.
+	idlePoll = 10 * time.Millisecond
+)
+
+// TokenBucket limits request rate on one endpoint category. Besides refilling
+// at a fixed rate it adapts to what the venue reports: Observe pulls the
+// token count down to the venue's remaining quota, and Throttle pauses the
+// bucket and halves its capacity after a 429, which then recovers linearly
+// over capacityRecovery.
+//
+// Callers that have to wait queue in arrival order: only the caller at the
+// head of the queue takes tokens, sleeping until the refill rate makes them
+// available, so a heavy caller is not starved by light ones slipping in
+// ahead of it.
 type TokenBucket struct {
-	mu          sync.Mutex
-	tokens      float64
-	capacity    float64
-	refillRate  float64
-	lastRefill  time.Time
+	mu           sync.Mutex
+	tokens       float64
+	capacity     float64
+	baseCapacity float64
+	refillRate   float64
+	lastRefill   time.Time
+	blockedUntil time.Time
+	strikes      int       // consecutive 429s
+	waiters      []*waiter // in arrival order
+}
+
+// waiter is a caller queued in Acquire.
+type waiter struct {
+	turn chan struct{} // closed when the waiter reaches the head of the queue
 }
 
 func NewTokenBucket(capacity, refillPerSecond int) *TokenBucket {
 	return &TokenBucket{
-		tokens:     float64(capacity),
-		capacity:   float64(capacity),
-		refillRate: float64(refillPerSecond),
-		lastRefill: time.Now(),
+		tokens:       float64(capacity),
+		capacity:     float64(capacity),
+		baseCapacity: float64(capacity),
+		refillRate:   float64(refillPerSecond),
+		lastRefill:   time.Now(),
 	}
 }
 
 func (tb *TokenBucket) refill() {
 	now := time.Now()
 	elapsed := now.Sub(tb.lastRefill).Seconds()
+	if tb.capacity < tb.baseCapacity {
+		tb.capacity += elapsed * tb.baseCapacity / capacityRecovery.Seconds()
+		if tb.capacity > tb.baseCapacity {
+			tb.capacity = tb.baseCapacity
+		}
+	}
 	tb.tokens += elapsed * tb.refillRate
 	if tb.tokens > tb.capacity {
 		tb.tokens = tb.capacity
@@ -35,31 +76,180 @@ func (tb *TokenBucket) refill() {
 	tb.lastRefill = now
 }
 
+// TryAcquire takes weight tokens if they are available now and no caller is
+// queued for them.
 func (tb *TokenBucket) TryAcquire(weight int) bool {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 	tb.refill()
-	w := float64(weight)
-	if tb.tokens >= w {
-		tb.tokens -= w
-		return true
-	}
-	return false
+	return len(tb.waiters) == 0 && tb.take(float64(weight))
 }
 
+// Acquire takes weight tokens, queueing behind earlier callers until they
+// are available or ctx is done.
 func (tb *TokenBucket) Acquire(ctx context.Context, weight int) error {
+	w := float64(weight)
+	tb.mu.Lock()
+	tb.refill()
+	if len(tb.waiters) == 0 && tb.take(w) {
+		tb.mu.Unlock()
+		return nil
+	}
+	me := &waiter{turn: make(chan struct{})}
+	tb.waiters = append(tb.waiters, me)
+	if len(tb.waiters) == 1 {
+		close(me.turn)
+	}
+	tb.mu.Unlock()
+
+	select {
+	case <-me.turn:
+	case <-ctx.Done():
+		tb.leave(me)
+		return ctx.Err()
+	}
 	for {
-		if tb.TryAcquire(weight) {
+		tb.mu.Lock()
+		tb.refill()
+		if tb.take(w) {
+			tb.dequeue(me)
+			tb.mu.Unlock()
 			return nil
 		}
+		wait := tb.waitFor(w)
+		tb.mu.Unlock()
+
+		timer := time.NewTimer(wait)
 		select {
+		case <-timer.C:
 		case <-ctx.Done():
+			timer.Stop()
+			tb.leave(me)
 			return ctx.Err()
-		case <-time.After(10 * time.Millisecond):
 		}
 	}
 }
 
+// take removes w tokens if the bucket is open and holds them. The caller
+// holds tb.mu.
+func (tb *TokenBucket) take(w float64) bool {
+	if time.Now().Before(tb.blockedUntil) || tb.tokens < w {
+		return false
+	}
+	tb.tokens -= w
+	return true
+}
+
+// waitFor estimates how long until w tokens can be taken. Observe and
+// Throttle only ever push that further out, so the head waiter sleeps this
+// long and checks again. The caller holds tb.mu.
+func (tb *TokenBucket) waitFor(w float64) time.Duration {
+	if until := time.Until(tb.blockedUntil); until > 0 {
+		return until
+	}
+	if tb.refillRate <= 0 {
+		return idlePoll
+	}
+	wait := time.Duration((w - tb.tokens) / tb.refillRate * float64(time.Second))
+	if wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+	return wait
+}
+
+func (tb *TokenBucket) leave(w *waiter) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.dequeue(w)
+}
+
+// dequeue removes w from the queue and hands the head on if w held it. The
+// caller holds tb.mu.
+func (tb *TokenBucket) dequeue(w *waiter) {
+	for i, q := range tb.waiters {
+		if q != w {
+			continue
+		}
+		tb.waiters = append(tb.waiters[:i], tb.waiters[i+1:]...)
+		if i == 0 && len(tb.waiters) > 0 {
+			close(tb.waiters[0].turn)
+		}
+		return
+	}
+}
+
+// Observe reconciles the bucket with the venue's count of requests left in
+// its window. The bucket never holds more tokens than the venue allows, and
+// an exhausted window blocks the bucket until it resets.
+func (tb *TokenBucket) Observe(remaining int, reset time.Duration) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	tb.strikes = 0
+	if r := float64(remaining); r < tb.tokens {
+		tb.tokens = r
+	}
+	if remaining <= 0 && reset > 0 {
+		tb.block(reset)
+	}
+}
+
+// Throttle records a 429 from the venue: the bucket is drained, blocked for
+// retryAfter and its capacity halved. Without a retryAfter the block grows
+// exponentially with consecutive 429s.
+func (tb *TokenBucket) Throttle(retryAfter time.Duration) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	if retryAfter <= 0 {
+		retryAfter = defaultBackoff << tb.strikes
+		if retryAfter > maxBackoff || retryAfter <= 0 {
+			retryAfter = maxBackoff
+		}
+	}
+	tb.strikes++
+	tb.tokens = 0
+	tb.capacity /= 2
+	if tb.capacity < 1 {
+		tb.capacity = 1
+	}
+	tb.block(retryAfter)
+}
+
+// Capacity returns the bucket's current, possibly reduced, capacity.
+func (tb *TokenBucket) Capacity() float64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	return tb.capacity
+}
+
+// status snapshots the bucket for category after refilling it.
+func (tb *TokenBucket) status(category domain.EndpointCategory) domain.RateLimitStatus {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill()
+	return domain.RateLimitStatus{
+		Category:     category,
+		Remaining:    tb.tokens,
+		Capacity:     tb.capacity,
+		BlockedUntil: tb.blockedUntil,
+	}
+}
+
+// accepted clears the 429 streak once the venue takes a request again.
+func (tb *TokenBucket) accepted() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.strikes = 0
+}
+
+func (tb *TokenBucket) block(d time.Duration) {
+	if until := time.Now().Add(d); until.After(tb.blockedUntil) {
+		tb.blockedUntil = until
+	}
+}
+
 type RateLimiter struct {
 	mu      sync.RWMutex
 	buckets map[domain.EndpointCategory]*TokenBucket
@@ -77,6 +267,27 @@ func (rl *RateLimiter) AddBucket(category domain.EndpointCategory, capacity, ref
 	rl.buckets[category] = NewTokenBucket(capacity, refillPerSecond)
 }
 
+// Clone returns a limiter with the same buckets, full and unthrottled, for
+// a budget the venue tracks separately, such as another API key's.
+func (rl *RateLimiter) Clone() *RateLimiter {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	out := NewRateLimiter()
+	for category, bucket := range rl.buckets {
+		bucket.mu.Lock()
+		capacity, refill := bucket.baseCapacity, bucket.refillRate
+		bucket.mu.Unlock()
+		out.buckets[category] = &TokenBucket{
+			tokens:       capacity,
+			capacity:     capacity,
+			baseCapacity: capacity,
+			refillRate:   refill,
+			lastRefill:   time.Now(),
+		}
+	}
+	return out
+}
+
 func (rl *RateLimiter) Acquire(ctx context.Context, category domain.EndpointCategory, weight int) error {
 	rl.mu.RLock()
 	bucket, ok := rl.buckets[category]
@@ -96,3 +307,77 @@ func (rl *RateLimiter) TryAcquire(category domain.EndpointCategory, weight int)
 	}
 	return bucket.TryAcquire(weight)
 }
+
+// Observe passes the venue's remaining quota for category to its bucket.
+func (rl *RateLimiter) Observe(category domain.EndpointCategory, remaining int, reset time.Duration) {
+	if bucket, ok := rl.bucket(category); ok {
+		bucket.Observe(remaining, reset)
+	}
+}
+
+// Throttle backs category off after the venue rejected a request with 429.
+func (rl *RateLimiter) Throttle(category domain.EndpointCategory, retryAfter time.Duration) {
+	if bucket, ok := rl.bucket(category); ok {
+		bucket.Throttle(retryAfter)
+	}
+}
+
+// ObserveResponse feeds a REST response back into category's bucket. 429
+// and 418 (Binance's ban status) throttle the bucket using Retry-After; other
+// responses are reconciled against the conventional X-RateLimit-Remaining and
+// X-RateLimit-Reset (seconds) headers when the venue sends them. Venues with
+// their own header names call Observe directly.
+func (rl *RateLimiter) ObserveResponse(category domain.EndpointCategory, resp *http.Response) {
+	bucket, ok := rl.bucket(category)
+	if !ok {
+		return
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusTeapot:
+		bucket.Throttle(RetryAfter(resp.Header))
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		bucket.accepted()
+		return
+	}
+	reset, _ := strconv.ParseFloat(resp.Header.Get("X-RateLimit-Reset"), 64)
+	bucket.Observe(remaining, time.Duration(reset*float64(time.Second)))
+}
+
+// Status reports the remaining budget of every category, ordered by
+// category name.
+func (rl *RateLimiter) Status() []domain.RateLimitStatus {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	out := make([]domain.RateLimitStatus, 0, len(rl.buckets))
+	for category, bucket := range rl.buckets {
+		out = append(out, bucket.status(category))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Category < out[j].Category })
+	return out
+}
+
+func (rl *RateLimiter) bucket(category domain.EndpointCategory) (*TokenBucket, bool) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	bucket, ok := rl.buckets[category]
+	return bucket, ok
+}
+
+// RetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+// It returns zero when the header is absent or unparseable.
+func RetryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
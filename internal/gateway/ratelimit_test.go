@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -51,3 +52,174 @@ func TestRateLimiter_UnknownCategory(t *testing.T) {
 		t.Error("unknown category should always succeed")
 	}
 }
+
+func TestTokenBucket_ObserveClampsToVenueRemaining(t *testing.T) {
+	tb := NewTokenBucket(10, 1)
+
+	tb.Observe(2, time.Second)
+	if !tb.TryAcquire(2) {
+		t.Fatal("expected the two tokens the venue reported to be available")
+	}
+	if tb.TryAcquire(1) {
+		t.Error("expected bucket to hold no more than the venue's remaining quota")
+	}
+}
+
+func TestTokenBucket_ObserveExhaustedBlocksUntilReset(t *testing.T) {
+	tb := NewTokenBucket(10, 1000)
+
+	tb.Observe(0, 50*time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if tb.TryAcquire(1) {
+		t.Error("expected bucket blocked until the venue window resets")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !tb.TryAcquire(1) {
+		t.Error("expected bucket usable after the reset")
+	}
+}
+
+func TestTokenBucket_ThrottleHalvesCapacity(t *testing.T) {
+	tb := NewTokenBucket(10, 1000)
+
+	tb.Throttle(30 * time.Millisecond)
+	if tb.TryAcquire(1) {
+		t.Error("expected bucket blocked after a 429")
+	}
+	if c := tb.Capacity(); c < 5 || c > 5.1 {
+		t.Errorf("expected capacity halved to ~5, got %f", c)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		if !tb.TryAcquire(1) {
+			t.Fatalf("expected token %d after the block", i)
+		}
+	}
+	if tb.TryAcquire(1) {
+		t.Error("expected reduced capacity to cap the burst")
+	}
+}
+
+func TestRateLimiter_ObserveResponse(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.AddBucket(domain.EndpointOrderPlace, 10, 1000)
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "1")
+	rl.ObserveResponse(domain.EndpointOrderPlace, resp)
+	if rl.TryAcquire(domain.EndpointOrderPlace, 1) {
+		t.Error("expected category blocked for Retry-After")
+	}
+
+	rl.AddBucket(domain.EndpointAccount, 10, 1)
+	resp = &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", "1")
+	resp.Header.Set("X-RateLimit-Reset", "30")
+	rl.ObserveResponse(domain.EndpointAccount, resp)
+	if !rl.TryAcquire(domain.EndpointAccount, 1) || rl.TryAcquire(domain.EndpointAccount, 1) {
+		t.Error("expected X-RateLimit-Remaining to leave exactly one token")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	h := http.Header{}
+	if d := RetryAfter(h); d != 0 {
+		t.Errorf("expected zero without header, got %s", d)
+	}
+	h.Set("Retry-After", "3")
+	if d := RetryAfter(h); d != 3*time.Second {
+		t.Errorf("expected 3s, got %s", d)
+	}
+}
+
+func TestRateLimiter_Status(t *testing.T) {
+	rl := NewRateLimiter()
+	rl.AddBucket(domain.EndpointOrderPlace, 10, 0)
+	rl.AddBucket(domain.EndpointAccount, 5, 0)
+
+	rl.TryAcquire(domain.EndpointOrderPlace, 3)
+	rl.Throttle(domain.EndpointAccount, time.Minute)
+
+	statuses := rl.Status()
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(statuses))
+	}
+	account, place := statuses[0], statuses[1]
+	if account.Category != domain.EndpointAccount || place.Category != domain.EndpointOrderPlace {
+		t.Fatalf("expected categories sorted by name, got %s, %s", account.Category, place.Category)
+	}
+	if place.Remaining != 7 || place.Capacity != 10 {
+		t.Errorf("expected 7 of 10 order_place requests left, got %v of %v", place.Remaining, place.Capacity)
+	}
+	if got := account.Available(time.Now()); got != 0 {
+		t.Errorf("expected a throttled category to have nothing available, got %v", got)
+	}
+}
+
+func TestTokenBucket_AcquireQueuesInOrder(t *testing.T) {
+	tb := NewTokenBucket(4, 40)
+	tb.TryAcquire(4)
+
+	// A heavy caller queues first; light callers arriving after it wait
+	// their turn instead of taking each token as it refills.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	order := make(chan string, 4)
+	go func() {
+		if err := tb.Acquire(ctx, 4); err == nil {
+			order <- "heavy"
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		go func() {
+			if err := tb.Acquire(ctx, 1); err == nil {
+				order <- "light"
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if tb.TryAcquire(1) {
+		t.Error("expected TryAcquire to leave tokens to queued callers")
+	}
+
+	if first := <-order; first != "heavy" {
+		t.Fatalf("expected the heavy caller served first, got %s", first)
+	}
+	for i := 0; i < 3; i++ {
+		if got := <-order; got != "light" {
+			t.Fatalf("expected a light caller, got %s", got)
+		}
+	}
+}
+
+func TestTokenBucket_AcquireCancelLeavesQueue(t *testing.T) {
+	tb := NewTokenBucket(1, 0)
+	tb.TryAcquire(1)
+
+	// The head of the queue gives up; the caller behind it takes its place
+	// and gets the token once the venue hands it back.
+	head, cancelHead := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() { errs <- tb.Acquire(head, 1) }()
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() { errs <- tb.Acquire(ctx, 1) }()
+	time.Sleep(10 * time.Millisecond)
+
+	cancelHead()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("expected the cancelled caller to get context.Canceled, got %v", err)
+	}
+	tb.mu.Lock()
+	tb.tokens = 1
+	tb.mu.Unlock()
+	if err := <-errs; err != nil {
+		t.Fatalf("expected the next caller served, got %v", err)
+	}
+	if n := len(tb.waiters); n != 0 {
+		t.Errorf("expected an empty queue, got %d waiters", n)
+	}
+}
@@ -0,0 +1,189 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// HTTPError is a REST response with an error status.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header, if any
+}
+
+// NewHTTPError builds an HTTPError from resp and its already-read body.
+func NewHTTPError(resp *http.Response, body []byte) *HTTPError {
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: RetryAfter(resp.Header),
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// RetryableStatus reports whether an HTTP status means the venue did not
+// handle the request and it may be sent again: 429 and 5xx.
+func RetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// Retryable reports whether err is transient: a retryable HTTP status, a
+// rate limit rejection, a timeout, or a failure to reach the venue. Other
+// venue rejections and context cancellation are not.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var venueErr *VenueError
+	if errors.As(err, &venueErr) {
+		return venueErr.Category == ErrorRateLimit
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return RetryableStatus(httpErr.StatusCode)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// ErrorCode labels err for the venue_api_error_total metric: the HTTP status,
+// "timeout", "network", or for errors the venue reported in the body their
+// ErrorCategory, "api" if unknown.
+func ErrorCode(err error) string {
+	var venueErr *VenueError
+	if errors.As(err, &venueErr) && venueErr.Category != ErrorUnknown {
+		return string(venueErr.Category)
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return strconv.Itoa(httpErr.StatusCode)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return "network"
+	}
+	return "api"
+}
+
+// APIErrorObserver is told about every failed REST attempt against a venue.
+type APIErrorObserver func(venue string, category domain.EndpointCategory, code string)
+
+// RetryPolicy bounds how often and how fast a REST call is retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy gives a request three tries within roughly half a
+// second, short enough to stay inside a leg's fill timeout.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// delay is the pause before retry n (1-based): exponential from BaseDelay
+// with full jitter, capped at MaxDelay.
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
+
+// RESTRetrier retries a venue's REST calls on transient errors and reports
+// each failed attempt. Venue clients also report each attempt's round trip
+// through it with ObserveLatency.
+type RESTRetrier struct {
+	Venue     string
+	Policy    RetryPolicy
+	OnError   APIErrorObserver
+	OnLatency LatencyObserver
+}
+
+// NewRESTRetrier returns a retrier for venue using DefaultRetryPolicy.
+func NewRESTRetrier(venue string) RESTRetrier {
+	return RESTRetrier{Venue: venue, Policy: DefaultRetryPolicy}
+}
+
+// Do runs attempt until it succeeds, fails with a non-retryable error, or the
+// policy's attempts are used up, and returns the last error. A Retry-After
+// longer than the backoff is honoured. Each attempt must build its request
+// afresh so signatures and timestamps stay valid.
+func (r RESTRetrier) Do(ctx context.Context, category domain.EndpointCategory, attempt func() error) error {
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if r.OnError != nil {
+			r.OnError(r.Venue, category, ErrorCode(err))
+		}
+		if n >= r.Policy.MaxAttempts || !Retryable(err) {
+			return err
+		}
+
+		wait := r.Policy.delay(n)
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > wait {
+			wait = httpErr.RetryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Once runs attempt a single time, reporting a failure like Do. It is for
+// calls that must not be replayed, such as withdrawals, where a request that
+// timed out may still have been carried out.
+func (r RESTRetrier) Once(ctx context.Context, category domain.EndpointCategory, attempt func() error) error {
+	r.Policy.MaxAttempts = 1
+	return r.Do(ctx, category, attempt)
+}
+
+// Replayable reports whether placing reqs may be retried. A placement that
+// timed out may still have gone through, so it is only sent again when every
+// order carries a client order ID: the venue then rejects a replay of one it
+// accepted as a duplicate instead of placing it twice.
+func Replayable(reqs ...domain.OrderRequest) bool {
+	for _, req := range reqs {
+		if req.IdempotencyKey == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+		code string
+	}{
+		{"429", &HTTPError{StatusCode: 429}, true, "429"},
+		{"502 wrapped", fmt.Errorf("place order: %w", &HTTPError{StatusCode: 502}), true, "502"},
+		{"400", &HTTPError{StatusCode: 400}, false, "400"},
+		{"venue rejection", errors.New("OKX API error: code=51008"), false, "api"},
+		{"insufficient balance", fmt.Errorf("place order: %w", &VenueError{Venue: "okx", Code: "51008", Category: ErrorInsufficientBalance}), false, "insufficient_balance"},
+		{"unmapped rejection", &VenueError{Venue: "okx", Code: "51000"}, false, "api"},
+		{"rate limit rejection", &VenueError{Venue: "okx", Code: "50011", Category: ErrorRateLimit}, true, "rate_limit"},
+		{"timeout", fmt.Errorf("do request: %w", &net.DNSError{IsTimeout: true}), true, "timeout"},
+		{"connection refused", fmt.Errorf("do request: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true, "network"},
+		{"cancelled", fmt.Errorf("do request: %w", context.Canceled), false, "api"},
+	}
+	for _, tc := range cases {
+		if got := Retryable(tc.err); got != tc.want {
+			t.Errorf("%s: Retryable = %v, want %v", tc.name, got, tc.want)
+		}
+		if got := ErrorCode(tc.err); got != tc.code {
+			t.Errorf("%s: ErrorCode = %q, want %q", tc.name, got, tc.code)
+		}
+	}
+}
+
+func TestRESTRetrier_RetriesTransientErrors(t *testing.T) {
+	var reported []string
+	r := RESTRetrier{
+		Venue:  "okx",
+		Policy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+		OnError: func(venue string, category domain.EndpointCategory, code string) {
+			reported = append(reported, venue+":"+string(category)+":"+code)
+		},
+	}
+
+	calls := 0
+	err := r.Do(context.Background(), domain.EndpointOrderPlace, func() error {
+		calls++
+		if calls == 1 {
+			return &HTTPError{StatusCode: 502}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success after a retry, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+	if len(reported) != 1 || reported[0] != "okx:order_place:502" {
+		t.Errorf("expected the failed attempt reported, got %v", reported)
+	}
+
+	calls = 0
+	err = r.Do(context.Background(), domain.EndpointOrderPlace, func() error {
+		calls++
+		return &HTTPError{StatusCode: 503}
+	})
+	if err == nil || calls != 3 {
+		t.Errorf("expected to give up after 3 attempts, got %d attempts and err %v", calls, err)
+	}
+}
+
+func TestRESTRetrier_DoesNotRetryRejections(t *testing.T) {
+	r := NewRESTRetrier("kcex")
+
+	calls := 0
+	err := r.Do(context.Background(), domain.EndpointOrderPlace, func() error {
+		calls++
+		return &HTTPError{StatusCode: 400}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a single attempt for a 400, got %d", calls)
+	}
+}
+
+func TestReplayable(t *testing.T) {
+	keyed := domain.OrderRequest{IdempotencyKey: "sig-leg-0"}
+	if !Replayable(keyed, keyed) {
+		t.Error("expected orders with client order IDs to be replayable")
+	}
+	if Replayable(keyed, domain.OrderRequest{}) {
+		t.Error("expected a batch with an unkeyed order not to be replayable")
+	}
+}
+
+func TestRetryPolicyDelayIsJitteredAndCapped(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: 400 * time.Millisecond}
+	for n := 1; n <= 6; n++ {
+		ceiling := p.BaseDelay << (n - 1)
+		if ceiling > p.MaxDelay {
+			ceiling = p.MaxDelay
+		}
+		for i := 0; i < 50; i++ {
+			if d := p.delay(n); d <= 0 || d > ceiling {
+				t.Fatalf("retry %d: delay %s outside (0, %s]", n, d, ceiling)
+			}
+		}
+	}
+}
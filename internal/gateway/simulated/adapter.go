@@ -11,6 +11,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
 	"github.com/crypto-trading/trading/internal/marketdata"
 )
 
@@ -25,7 +26,11 @@ type Gateway struct {
 	balances     map[string]domain.Balance
 	positions    []domain.Position
 	openOrders   map[string]*domain.Order
+	pendingStops map[string]domain.OrderRequest // untriggered stops by venue ID
 	feeTier      *domain.FeeTier
+	updates      chan domain.OrderUpdate // fills of resting orders
+	funding      *FundingLedger
+	margin       *MarginModel
 
 	latencyMs    int
 }
@@ -42,19 +47,22 @@ func New(venueName string, fillSim FillSimulator, mdService *marketdata.Service,
 	}
 
 	return &Gateway{
-		venueName:  venueName,
-		fillSim:    fillSim,
-		mdService:  mdService,
-		logger:     logger,
-		balances:   balances,
-		positions:  make([]domain.Position, 0),
-		openOrders: make(map[string]*domain.Order),
+		venueName:    venueName,
+		fillSim:      fillSim,
+		mdService:    mdService,
+		logger:       logger,
+		balances:     balances,
+		positions:    make([]domain.Position, 0),
+		openOrders:   make(map[string]*domain.Order),
+		pendingStops: make(map[string]domain.OrderRequest),
 		feeTier: &domain.FeeTier{
 			Venue:       venueName,
 			MakerFeeBps: decimal.NewFromFloat(2),
 			TakerFeeBps: decimal.NewFromFloat(5),
 			UpdatedAt:   time.Now(),
 		},
+		updates:   make(chan domain.OrderUpdate, 256),
+		funding:   NewFundingLedger(venueName),
 		latencyMs: latencyMs,
 	}
 }
@@ -71,6 +79,17 @@ func (g *Gateway) Close() error {
 	return nil
 }
 
+// Health always reports the simulated venue as up; it has no connections
+// that can fail.
+func (g *Gateway) Health(_ context.Context) domain.VenueHealth {
+	return domain.VenueHealth{
+		Venue:         g.venueName,
+		WSConnected:   true,
+		RESTReachable: true,
+		CheckedAt:     time.Now(),
+	}
+}
+
 func (g *Gateway) SubscribeOrderBook(_ context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
 	ch := make(chan domain.OrderBookDelta, 256)
 	return ch, nil
@@ -105,6 +124,18 @@ func (g *Gateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*dom
 		}, fmt.Errorf("no order book available for %s:%s", g.venueName, req.Symbol)
 	}
 
+	// A reduce-only stop is checked when it triggers.
+	if !req.OrderType.IsStop() {
+		var err error
+		if req, err = g.funding.LimitReduceOnly(req); err != nil {
+			return &domain.OrderAck{
+				InternalID: req.InternalID,
+				Status:     domain.OrderStatusRejected,
+				Timestamp:  time.Now(),
+			}, err
+		}
+	}
+
 	fill, err := g.fillSim.SimulateFill(req, book)
 	if err != nil {
 		return nil, err
@@ -114,23 +145,33 @@ func (g *Gateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*dom
 
 	g.mu.Lock()
 	order := &domain.Order{
-		InternalID:   req.InternalID,
-		VenueID:      venueID,
-		SignalID:      req.SignalID,
-		Venue:        g.venueName,
-		Symbol:       req.Symbol,
-		Side:         req.Side,
-		OrderType:    req.OrderType,
-		Price:        req.Price,
-		Size:         req.Size,
-		FilledSize:   fill.FillSize,
-		AvgFillPrice: fill.FillPrice,
-		Status:       fill.Status,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		InternalID:     req.InternalID,
+		VenueID:        venueID,
+		SignalID:       req.SignalID,
+		Venue:          g.venueName,
+		Symbol:         req.Symbol,
+		InstrumentType: req.InstrumentType,
+		Side:           req.Side,
+		OrderType:      req.OrderType,
+		ReduceOnly:     req.ReduceOnly,
+		Price:          req.Price,
+		StopPrice:      req.StopPrice,
+		Size:           req.Size,
+		FilledSize:     fill.FillSize,
+		AvgFillPrice:   fill.FillPrice,
+		Status:         fill.Status,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 	g.openOrders[venueID] = order
+	g.fillSim.Rest(order, book)
+	g.funding.Track(order)
+	if req.OrderType.IsStop() && !StopTriggered(req, book) {
+		g.pendingStops[venueID] = req
+	}
+	trimmed := TrimReduceOnly(g.fillSim, g.funding, g.openOrders, req.Symbol)
 	g.mu.Unlock()
+	SendUpdates(ctx, g.updates, trimmed)
 
 	g.logger.Info("simulated order placed",
 		"venue", g.venueName,
@@ -157,8 +198,10 @@ func (g *Gateway) CancelOrder(_ context.Context, orderID string) (*domain.Cancel
 	if ok {
 		order.Status = domain.OrderStatusCancelled
 		delete(g.openOrders, orderID)
+		delete(g.pendingStops, orderID)
 	}
 	g.mu.Unlock()
+	g.fillSim.Forget(orderID)
 
 	return &domain.CancelAck{
 		Status:    domain.OrderStatusCancelled,
@@ -166,9 +209,49 @@ func (g *Gateway) CancelOrder(_ context.Context, orderID string) (*domain.Cancel
 	}, nil
 }
 
+// PlaceOrders simulates each order in turn; there is no rate limit to save.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, g.PlaceOrder)
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.CancelOrder)
+}
+
+// AmendOrder changes the price and total size of a resting simulated order in
+// place, keeping its venue ID.
+func (g *Gateway) AmendOrder(_ context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.openOrders[orderID]
+	if !ok || order.Status.IsTerminal() {
+		return nil, fmt.Errorf("simulated order %s not open", orderID)
+	}
+	if newSize.LessThanOrEqual(order.FilledSize) {
+		return nil, fmt.Errorf("new size %s not above filled size %s", newSize, order.FilledSize)
+	}
+
+	order.Price = newPrice
+	order.Size = newSize
+	order.UpdatedAt = time.Now()
+
+	return &domain.AmendAck{
+		VenueID:   orderID,
+		Price:     newPrice,
+		Size:      newSize,
+		Status:    order.Status,
+		Timestamp: order.UpdatedAt,
+	}, nil
+}
+
+// GetOpenOrders first triggers any resting stops whose trigger price the
+// current book has reached, so polling open orders drives stop execution.
 func (g *Gateway) GetOpenOrders(_ context.Context, symbol string) ([]domain.Order, error) {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.triggerStops(symbol)
 
 	orders := make([]domain.Order, 0)
 	for _, o := range g.openOrders {
@@ -179,6 +262,168 @@ func (g *Gateway) GetOpenOrders(_ context.Context, symbol string) ([]domain.Orde
 	return orders, nil
 }
 
+// triggerStops fills untriggered stops for symbol (all symbols if empty)
+// whose trigger has been reached. Callers hold g.mu.
+func (g *Gateway) triggerStops(symbol string) {
+	for venueID, req := range g.pendingStops {
+		if symbol != "" && req.Symbol != symbol {
+			continue
+		}
+		book, ok := g.mdService.GetOrderBook(g.venueName, req.Symbol)
+		if !ok || !StopTriggered(req, book) {
+			continue
+		}
+		order := g.openOrders[venueID]
+		req, err := g.funding.LimitReduceOnly(req)
+		if err != nil {
+			delete(g.pendingStops, venueID)
+			order.Status = domain.OrderStatusCancelled
+			order.UpdatedAt = time.Now()
+			g.logger.Info("simulated reduce-only stop cancelled",
+				"venue", g.venueName,
+				"order_id", venueID,
+				"error", err,
+				"mode", "dry_run",
+			)
+			continue
+		}
+		fill, err := g.fillSim.SimulateFill(req, book)
+		if err != nil {
+			g.logger.Warn("simulated stop trigger failed", "order_id", venueID, "error", err)
+			continue
+		}
+		delete(g.pendingStops, venueID)
+
+		order.Size = req.Size
+		order.FilledSize = fill.FillSize
+		order.AvgFillPrice = fill.FillPrice
+		order.Status = fill.Status
+		order.UpdatedAt = time.Now()
+		g.funding.Track(order)
+
+		g.logger.Info("simulated stop triggered",
+			"venue", g.venueName,
+			"symbol", req.Symbol,
+			"stop_price", req.StopPrice.String(),
+			"fill_price", fill.FillPrice.String(),
+			"size", fill.FillSize.String(),
+			"status", fill.Status,
+			"mode", "dry_run",
+		)
+	}
+}
+
+// RunMatching fills resting limit orders from the venue's books and trades,
+// as the fill simulator decides, until ctx is cancelled or either channel
+// is closed.
+func (g *Gateway) RunMatching(ctx context.Context, books <-chan domain.OrderBookSnapshot, trades <-chan domain.Trade) {
+	for {
+		var updates, trimmed []domain.OrderUpdate
+		select {
+		case <-ctx.Done():
+			return
+		case book, ok := <-books:
+			if !ok {
+				return
+			}
+			if book.Venue != g.venueName {
+				continue
+			}
+			g.mu.Lock()
+			updates = MatchOrders(g.fillSim, g.openOrders, &book)
+			g.funding.TrackUpdates(g.openOrders, updates)
+			trimmed = TrimReduceOnly(g.fillSim, g.funding, g.openOrders, book.Symbol)
+			g.mu.Unlock()
+		case trade, ok := <-trades:
+			if !ok {
+				return
+			}
+			if trade.Venue != g.venueName {
+				continue
+			}
+			g.mu.Lock()
+			updates = MatchOrdersOnTrade(g.fillSim, g.openOrders, trade)
+			g.funding.TrackUpdates(g.openOrders, updates)
+			trimmed = TrimReduceOnly(g.fillSim, g.funding, g.openOrders, trade.Symbol)
+			g.mu.Unlock()
+		}
+		for _, u := range updates {
+			g.logger.Info("simulated resting order filled",
+				"venue", g.venueName,
+				"order_id", u.VenueID,
+				"filled", u.FilledSize.String(),
+				"status", u.Status,
+				"mode", "dry_run",
+			)
+		}
+		SendUpdates(ctx, g.updates, updates)
+		SendUpdates(ctx, g.updates, trimmed)
+	}
+}
+
+// RunFunding settles funding on the perp positions simulated fills have
+// opened, at the rates announced for the venue, into the USDT balance.
+func (g *Gateway) RunFunding(ctx context.Context, rates <-chan domain.FundingRate, pay func(domain.AccountActivity)) {
+	RunFundingLoop(ctx, g.funding, rates, MidMarks(g.mdService, g.venueName), g.settleFunding, pay)
+}
+
+func (g *Gateway) settleFunding(p domain.AccountActivity) {
+	g.mu.Lock()
+	usdt := g.balances["USDT"]
+	usdt.Free = usdt.Free.Add(p.Amount)
+	usdt.Total = usdt.Total.Add(p.Amount)
+	g.balances["USDT"] = usdt
+	g.mu.Unlock()
+
+	g.logger.Info("simulated funding settled",
+		"venue", g.venueName,
+		"symbol", p.Symbol,
+		"position", p.Size.String(),
+		"amount", p.Amount.String(),
+		"mode", "dry_run",
+	)
+}
+
+// SetMargin margins the perp positions simulated fills open with model.
+// Call before RunMargin.
+func (g *Gateway) SetMargin(model MarginModel) {
+	g.margin = &model
+}
+
+// RunMargin checks the margin of the perp positions simulated fills have
+// opened against the model set with SetMargin, and settles liquidations
+// into the USDT balance.
+func (g *Gateway) RunMargin(ctx context.Context, liquidated func(Liquidation)) {
+	if g.margin == nil {
+		return
+	}
+	RunMarginLoop(ctx, g.funding, *g.margin, MidMarks(g.mdService, g.venueName), g.settleLiquidation, liquidated, g.logger)
+}
+
+func (g *Gateway) settleLiquidation(l Liquidation) {
+	g.mu.Lock()
+	usdt := g.balances["USDT"]
+	usdt.Free = usdt.Free.Add(l.PnL)
+	usdt.Total = usdt.Total.Add(l.PnL)
+	g.balances["USDT"] = usdt
+	g.mu.Unlock()
+
+	g.logger.Warn("simulated perp positions liquidated",
+		"venue", g.venueName,
+		"positions", len(l.Positions),
+		"equity", l.Status.Equity.StringFixed(2),
+		"maintenance_margin", l.Status.Maintenance.StringFixed(2),
+		"pnl", l.PnL.StringFixed(2),
+		"mode", "dry_run",
+	)
+}
+
+// SubscribeOrderUpdates streams the fills RunMatching finds for resting
+// orders; fills at placement are final in the ack.
+func (g *Gateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return g.updates, nil
+}
+
 func (g *Gateway) GetBalances(_ context.Context) (map[string]domain.Balance, error) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
@@ -202,3 +447,29 @@ func (g *Gateway) GetPositions(_ context.Context) ([]domain.Position, error) {
 func (g *Gateway) GetFeeTier(_ context.Context) (*domain.FeeTier, error) {
 	return g.feeTier, nil
 }
+
+// GetInstruments returns nothing: simulated venues accept any price and size.
+func (g *Gateway) GetInstruments(_ context.Context) ([]domain.Instrument, error) {
+	return nil, nil
+}
+
+// GetRateLimitStatus returns nothing: simulated venues are not rate limited.
+func (g *Gateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return nil, nil
+}
+
+// Simulated venues hold a single isolated balance; there is nowhere to
+// transfer to or from.
+func (g *Gateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetDepositAddress(_ context.Context, _, _ string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+var _ RestingMatcher = (*Gateway)(nil)
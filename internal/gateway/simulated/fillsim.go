@@ -1,7 +1,9 @@
 package simulated
 
 import (
+	"math"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -11,6 +13,14 @@ import (
 
 type FillSimulator interface {
 	SimulateFill(order domain.OrderRequest, book *domain.OrderBookSnapshot) (*SimulatedFill, error)
+	// Rest is told that order was left resting on book when it was placed.
+	Rest(order *domain.Order, book *domain.OrderBookSnapshot)
+	// MatchBook and MatchTrade fill a resting order from a later book update
+	// or trade print, updating it in place, and return the size filled.
+	MatchBook(order *domain.Order, book *domain.OrderBookSnapshot) decimal.Decimal
+	MatchTrade(order *domain.Order, trade domain.Trade) decimal.Decimal
+	// Forget drops what is kept about a resting order once it is done.
+	Forget(venueID string)
 }
 
 type SimulatedFill struct {
@@ -21,12 +31,45 @@ type SimulatedFill struct {
 	Status    domain.OrderStatus
 }
 
+// QueueModel sets how a resting limit order waits behind the size already
+// displayed at its price.
+type QueueModel struct {
+	// Ahead is the share of the size displayed at the order's price when it
+	// rests that is ahead of it: 1 joins the back of the queue, 0 the front.
+	Ahead float64
+	// FillProbability is the chance that a trade at the order's price which
+	// has worked through the queue ahead fills it. Below 1 it stands for size
+	// lost to hidden orders and queue jumping.
+	FillProbability float64
+}
+
+// ImpactModel prices fills that take liquidity worse than the displayed
+// book alone would. The walked average price is moved against the order by
+// CoefficientBps × sqrt(filled size / displayed depth on its side), for the
+// liquidity that reacts to a taker, and then by the mid move expected over
+// the simulated latency, drawn from the drift and volatility of Trades.
+type ImpactModel struct {
+	CoefficientBps float64
+	// Trades returns the recent trades on symbol, oldest first. Nil, or too
+	// few trades, leaves out the latency move.
+	Trades func(symbol string) []*domain.Trade
+}
+
+// minMoveTrades is how many trades the latency move is estimated from at
+// the least.
+const minMoveTrades = 10
+
 type DefaultFillSimulator struct {
 	latencyMs     int
 	rejectRatePct float64
 	makerFeeBps   decimal.Decimal
 	takerFeeBps   decimal.Decimal
 	rng           *rand.Rand
+
+	mu     sync.Mutex
+	queue  *QueueModel                // nil fills resting orders on touch
+	impact *ImpactModel               // nil fills at the walked book price
+	ahead  map[string]decimal.Decimal // venue ID -> size still queued ahead
 }
 
 func NewFillSimulator(latencyMs int, rejectRatePct float64, makerFeeBps, takerFeeBps decimal.Decimal) *DefaultFillSimulator {
@@ -36,7 +79,101 @@ func NewFillSimulator(latencyMs int, rejectRatePct float64, makerFeeBps, takerFe
 		makerFeeBps:   makerFeeBps,
 		takerFeeBps:   takerFeeBps,
 		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		ahead:         make(map[string]decimal.Decimal),
+	}
+}
+
+// SetFees replaces the maker and taker rates, in basis points; a negative
+// rate is a rebate.
+func (s *DefaultFillSimulator) SetFees(makerFeeBps, takerFeeBps decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.makerFeeBps, s.takerFeeBps = makerFeeBps, takerFeeBps
+}
+
+// SetQueueModel makes resting limit orders queue behind the size displayed
+// at their price instead of filling as soon as the market touches it. They
+// then fill from trades at their price once the trades have used up the
+// size ahead, or at once from size quoted through their price.
+func (s *DefaultFillSimulator) SetQueueModel(m QueueModel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = &m
+}
+
+// SetImpactModel prices market fills, and limit orders that cross the book
+// on arrival, with m's impact and latency move on top of the depth walk.
+func (s *DefaultFillSimulator) SetImpactModel(m ImpactModel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.impact = &m
+}
+
+// Rest records the size queued ahead of order: its share of what book
+// displays at the order's price on its own side. An order that took
+// liquidity on arrival has already cleared its price and queues first.
+func (s *DefaultFillSimulator) Rest(order *domain.Order, book *domain.OrderBookSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queue == nil || !resting(order) {
+		return
+	}
+	ahead := decimal.Zero
+	if order.FilledSize.IsZero() {
+		levels := book.Bids
+		if order.Side == domain.SideSell {
+			levels = book.Asks
+		}
+		for _, level := range levels {
+			if level.Price.Equal(order.Price) {
+				ahead = level.Size.Mul(decimal.NewFromFloat(s.queue.Ahead))
+				break
+			}
+		}
+	}
+	s.ahead[order.VenueID] = ahead
+}
+
+// MatchBook fills order from size quoted through its price. Without a queue
+// model size quoted at its price fills it too.
+func (s *DefaultFillSimulator) MatchBook(order *domain.Order, book *domain.OrderBookSnapshot) decimal.Decimal {
+	s.mu.Lock()
+	queued := s.queue != nil
+	s.mu.Unlock()
+	return matchBook(order, book, !queued)
+}
+
+// MatchTrade works a trade at order's price that hit its side of the book
+// through the size queued ahead, then fills order from what is left with
+// the queue model's fill probability. Without a queue model trades are
+// ignored; the book alone drives fills.
+func (s *DefaultFillSimulator) MatchTrade(order *domain.Order, trade domain.Trade) decimal.Decimal {
+	if !resting(order) || !trade.Price.Equal(order.Price) || trade.Side == order.Side {
+		return decimal.Zero
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queue == nil {
+		return decimal.Zero
 	}
+	ahead := s.ahead[order.VenueID]
+	left := trade.Size.Sub(ahead)
+	if !left.IsPositive() {
+		s.ahead[order.VenueID] = ahead.Sub(trade.Size)
+		return decimal.Zero
+	}
+	s.ahead[order.VenueID] = decimal.Zero
+	if s.rng.Float64() >= s.queue.FillProbability {
+		return decimal.Zero
+	}
+	return fillResting(order, left)
+}
+
+// Forget drops the queue position kept for a resting order.
+func (s *DefaultFillSimulator) Forget(venueID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ahead, venueID)
 }
 
 func (s *DefaultFillSimulator) SimulateFill(order domain.OrderRequest, book *domain.OrderBookSnapshot) (*SimulatedFill, error) {
@@ -54,13 +191,18 @@ func (s *DefaultFillSimulator) SimulateFill(order domain.OrderRequest, book *dom
 		}, nil
 	}
 
+	if order.OrderType.IsStop() {
+		if !StopTriggered(order, book) {
+			return &SimulatedFill{Status: domain.OrderStatusAcknowledged, LatencyMs: s.latencyMs}, nil
+		}
+		order = triggeredOrder(order)
+	}
+
 	var fillPrice decimal.Decimal
 	var fillSize decimal.Decimal
-	var feeBps decimal.Decimal
 
 	switch order.OrderType {
 	case domain.OrderTypeMarket:
-		feeBps = s.takerFeeBps
 		if order.Side == domain.SideBuy {
 			if len(book.Asks) == 0 {
 				return &SimulatedFill{Status: domain.OrderStatusRejected, LatencyMs: s.latencyMs}, nil
@@ -74,19 +216,16 @@ func (s *DefaultFillSimulator) SimulateFill(order domain.OrderRequest, book *dom
 		}
 
 	case domain.OrderTypeLimit:
-		feeBps = s.makerFeeBps
 		if order.Side == domain.SideBuy {
 			if len(book.Asks) == 0 {
 				return &SimulatedFill{Status: domain.OrderStatusRejected, LatencyMs: s.latencyMs}, nil
 			}
 			bestAsk := book.Asks[0].Price
 			if order.Price.LessThan(bestAsk) {
-				return &SimulatedFill{
-					FillPrice: order.Price,
-					FillSize:  decimal.Zero,
-					Status:    domain.OrderStatusAcknowledged,
-					LatencyMs: s.latencyMs,
-				}, nil
+				return s.restingFill(order), nil
+			}
+			if order.PostOnly {
+				return &SimulatedFill{Status: domain.OrderStatusRejected, LatencyMs: s.latencyMs}, nil
 			}
 			fillPrice, fillSize = simulateMarketFill(book.Asks, order.Size)
 		} else {
@@ -95,24 +234,40 @@ func (s *DefaultFillSimulator) SimulateFill(order domain.OrderRequest, book *dom
 			}
 			bestBid := book.Bids[0].Price
 			if order.Price.GreaterThan(bestBid) {
-				return &SimulatedFill{
-					FillPrice: order.Price,
-					FillSize:  decimal.Zero,
-					Status:    domain.OrderStatusAcknowledged,
-					LatencyMs: s.latencyMs,
-				}, nil
+				return s.restingFill(order), nil
+			}
+			if order.PostOnly {
+				return &SimulatedFill{Status: domain.OrderStatusRejected, LatencyMs: s.latencyMs}, nil
 			}
 			fillPrice, fillSize = simulateMarketFill(book.Bids, order.Size)
 		}
 	}
 
-	fee := fillPrice.Mul(fillSize).Mul(feeBps).Div(decimal.NewFromInt(10000))
+	if fillSize.IsPositive() {
+		fillPrice = s.applyImpact(order, book, fillPrice, fillSize)
+	}
 
 	status := domain.OrderStatusFilled
 	if fillSize.LessThan(order.Size) {
-		status = domain.OrderStatusPartialFill
+		switch order.TimeInForce {
+		case domain.TimeInForceFOK:
+			// Fill-or-kill: nothing executes unless all of it can.
+			fillPrice, fillSize = decimal.Zero, decimal.Zero
+			status = domain.OrderStatusCancelled
+		case domain.TimeInForceIOC:
+			status = domain.OrderStatusCancelled
+		default:
+			status = domain.OrderStatusPartialFill
+		}
 	}
 
+	// Whatever fills on arrival took liquidity, limit orders included, so it
+	// never earns a maker rebate.
+	s.mu.Lock()
+	feeBps := s.takerFeeBps
+	s.mu.Unlock()
+	fee := fillPrice.Mul(fillSize).Mul(feeBps).Div(decimal.NewFromInt(10000))
+
 	return &SimulatedFill{
 		FillPrice: fillPrice,
 		FillSize:  fillSize,
@@ -122,6 +277,131 @@ func (s *DefaultFillSimulator) SimulateFill(order domain.OrderRequest, book *dom
 	}, nil
 }
 
+// applyImpact moves the walked average price of a taking fill by the impact
+// model, if one is set. A limit order is never filled past its limit by
+// the move; a walk that already went past it is left as it was.
+func (s *DefaultFillSimulator) applyImpact(order domain.OrderRequest, book *domain.OrderBookSnapshot, price, size decimal.Decimal) decimal.Decimal {
+	s.mu.Lock()
+	impact := s.impact
+	s.mu.Unlock()
+	if impact == nil {
+		return price
+	}
+
+	levels, sign := book.Asks, 1.0
+	if order.Side == domain.SideSell {
+		levels, sign = book.Bids, -1.0
+	}
+	depth := decimal.Zero
+	for _, level := range levels {
+		depth = depth.Add(level.Size)
+	}
+	bps := 0.0
+	if depth.IsPositive() {
+		bps = sign * impact.CoefficientBps * math.Sqrt(size.Div(depth).InexactFloat64())
+	}
+	bps += s.latencyMoveBps(impact, order.Symbol)
+
+	adjusted := price.Mul(decimal.NewFromFloat(1 + bps/10000))
+	if !adjusted.IsPositive() {
+		return price
+	}
+	if order.OrderType == domain.OrderTypeLimit {
+		if order.Side == domain.SideBuy && adjusted.GreaterThan(order.Price) {
+			adjusted = decimal.Max(order.Price, price)
+		}
+		if order.Side == domain.SideSell && adjusted.LessThan(order.Price) {
+			adjusted = decimal.Min(order.Price, price)
+		}
+	}
+	return adjusted
+}
+
+// latencyMoveBps draws the mid's move over the simulated latency, in bps
+// (positive up), from the drift and volatility of the recent trades on
+// symbol.
+func (s *DefaultFillSimulator) latencyMoveBps(impact *ImpactModel, symbol string) float64 {
+	if s.latencyMs <= 0 || impact.Trades == nil {
+		return 0
+	}
+	drift, vol, ok := TradeDriftVolatility(impact.Trades(symbol))
+	if !ok {
+		return 0
+	}
+	secs := float64(s.latencyMs) / 1000
+	s.mu.Lock()
+	noise := s.rng.NormFloat64()
+	s.mu.Unlock()
+	return drift*secs + vol*math.Sqrt(secs)*noise
+}
+
+// TradeDriftVolatility estimates the drift (bps per second) and volatility
+// (bps per square-root second) of the price from trades, oldest first. It
+// needs at least minMoveTrades trades spread over some time.
+func TradeDriftVolatility(trades []*domain.Trade) (drift, vol float64, ok bool) {
+	if len(trades) < minMoveTrades {
+		return 0, 0, false
+	}
+	first, last := trades[0], trades[len(trades)-1]
+	elapsed := last.Timestamp.Sub(first.Timestamp).Seconds()
+	if elapsed <= 0 {
+		return 0, 0, false
+	}
+
+	var sumSq float64
+	prev := first.Price.InexactFloat64()
+	for _, t := range trades[1:] {
+		p := t.Price.InexactFloat64()
+		if prev > 0 && p > 0 {
+			r := math.Log(p/prev) * 10000
+			sumSq += r * r
+		}
+		prev = p
+	}
+	total := math.Log(last.Price.InexactFloat64()/first.Price.InexactFloat64()) * 10000
+	if math.IsNaN(total) || math.IsInf(total, 0) {
+		return 0, 0, false
+	}
+	return total / elapsed, math.Sqrt(sumSq / elapsed), true
+}
+
+// restingFill is the result for a limit order that does not cross the book:
+// it rests unless its time in force requires immediate execution.
+func (s *DefaultFillSimulator) restingFill(order domain.OrderRequest) *SimulatedFill {
+	status := domain.OrderStatusAcknowledged
+	if order.TimeInForce == domain.TimeInForceIOC || order.TimeInForce == domain.TimeInForceFOK {
+		status = domain.OrderStatusCancelled
+	}
+	return &SimulatedFill{
+		FillPrice: order.Price,
+		FillSize:  decimal.Zero,
+		Status:    status,
+		LatencyMs: s.latencyMs,
+	}
+}
+
+// StopTriggered reports whether a stop order's trigger has been reached on
+// book: a buy stop once the best ask is at or above StopPrice, a sell stop
+// once the best bid is at or below it. An empty side never triggers.
+func StopTriggered(order domain.OrderRequest, book *domain.OrderBookSnapshot) bool {
+	if order.Side == domain.SideBuy {
+		ask, ok := book.BestAsk()
+		return ok && ask.Price.GreaterThanOrEqual(order.StopPrice)
+	}
+	bid, ok := book.BestBid()
+	return ok && bid.Price.LessThanOrEqual(order.StopPrice)
+}
+
+// triggeredOrder is the order a stop turns into once triggered.
+func triggeredOrder(order domain.OrderRequest) domain.OrderRequest {
+	if order.OrderType == domain.OrderTypeStopLimit {
+		order.OrderType = domain.OrderTypeLimit
+	} else {
+		order.OrderType = domain.OrderTypeMarket
+	}
+	return order
+}
+
 func simulateMarketFill(levels []domain.PriceLevel, size decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
 	remaining := size
 	totalCost := decimal.Zero
@@ -1,7 +1,9 @@
 package simulated
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -77,6 +79,30 @@ func TestFillSimulator_MarketSell(t *testing.T) {
 	}
 }
 
+func TestFillSimulator_CrossingLimitPaysTaker(t *testing.T) {
+	sim := NewFillSimulator(0, 0, decimal.NewFromFloat(2), decimal.NewFromFloat(5))
+	// The venue pays makers 1 bp; a limit order filled on arrival is still a taker.
+	sim.SetFees(decimal.NewFromFloat(-1), decimal.NewFromFloat(4))
+
+	book := &domain.OrderBookSnapshot{
+		Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(50000), Size: decimal.NewFromInt(1)}},
+		Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(49990), Size: decimal.NewFromInt(1)}},
+	}
+	fill, err := sim.SimulateFill(domain.OrderRequest{
+		Symbol:    "BTC/USDT",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeLimit,
+		Price:     decimal.NewFromInt(50000),
+		Size:      decimal.NewFromInt(1),
+	}, book)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := decimal.NewFromInt(20); !fill.Fee.Equal(want) {
+		t.Errorf("expected taker fee %s, got %s", want, fill.Fee)
+	}
+}
+
 func TestFillSimulator_PartialFill(t *testing.T) {
 	sim := NewFillSimulator(0, 0, decimal.NewFromFloat(2), decimal.NewFromFloat(5))
 
@@ -108,6 +134,56 @@ func TestFillSimulator_PartialFill(t *testing.T) {
 	}
 }
 
+func TestFillSimulator_TimeInForce(t *testing.T) {
+	sim := NewFillSimulator(0, 0, decimal.NewFromFloat(2), decimal.NewFromFloat(5))
+
+	book := &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(49900), Size: decimal.NewFromFloat(1.0)}},
+		Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(0.3)}},
+	}
+
+	tests := []struct {
+		name       string
+		price      int64
+		tif        domain.TimeInForce
+		postOnly   bool
+		wantStatus domain.OrderStatus
+		wantSize   decimal.Decimal
+	}{
+		{"gtc rests", 49950, domain.TimeInForceGTC, false, domain.OrderStatusAcknowledged, decimal.Zero},
+		{"ioc below ask cancels", 49950, domain.TimeInForceIOC, false, domain.OrderStatusCancelled, decimal.Zero},
+		{"ioc keeps partial fill", 50000, domain.TimeInForceIOC, false, domain.OrderStatusCancelled, decimal.NewFromFloat(0.3)},
+		{"fok partial kills", 50000, domain.TimeInForceFOK, false, domain.OrderStatusCancelled, decimal.Zero},
+		{"post-only crossing rejects", 50000, "", true, domain.OrderStatusRejected, decimal.Zero},
+		{"post-only passive rests", 49950, "", true, domain.OrderStatusAcknowledged, decimal.Zero},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := domain.OrderRequest{
+				InternalID:  uuid.Must(uuid.NewV7()),
+				Symbol:      "BTC/USDT",
+				Side:        domain.SideBuy,
+				OrderType:   domain.OrderTypeLimit,
+				TimeInForce: tt.tif,
+				PostOnly:    tt.postOnly,
+				Price:       decimal.NewFromInt(tt.price),
+				Size:        decimal.NewFromFloat(1.0),
+			}
+			fill, err := sim.SimulateFill(req, book)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fill.Status != tt.wantStatus {
+				t.Errorf("status: got %s, want %s", fill.Status, tt.wantStatus)
+			}
+			if !fill.FillSize.Equal(tt.wantSize) {
+				t.Errorf("fill size: got %s, want %s", fill.FillSize, tt.wantSize)
+			}
+		})
+	}
+}
+
 func TestFillSimulator_Rejection(t *testing.T) {
 	sim := NewFillSimulator(0, 100, decimal.NewFromFloat(2), decimal.NewFromFloat(5))
 
@@ -155,3 +231,220 @@ func TestFillSimulator_NilBook(t *testing.T) {
 		t.Errorf("expected REJECTED with nil book, got %s", fill.Status)
 	}
 }
+
+func TestFillSimulator_StopOrders(t *testing.T) {
+	sim := NewFillSimulator(0, 0, decimal.NewFromFloat(2), decimal.NewFromFloat(5))
+
+	book := &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(49900), Size: decimal.NewFromInt(2)}},
+		Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(50000), Size: decimal.NewFromInt(2)}},
+	}
+
+	tests := []struct {
+		name       string
+		side       domain.Side
+		orderType  domain.OrderType
+		stopPrice  int64
+		limitPrice int64
+		wantStatus domain.OrderStatus
+		wantFilled bool
+	}{
+		{"sell stop above bid triggers", domain.SideSell, domain.OrderTypeStopMarket, 49950, 0, domain.OrderStatusFilled, true},
+		{"sell stop below bid waits", domain.SideSell, domain.OrderTypeStopMarket, 49000, 0, domain.OrderStatusAcknowledged, false},
+		{"buy stop below ask triggers", domain.SideBuy, domain.OrderTypeStopMarket, 49990, 0, domain.OrderStatusFilled, true},
+		{"buy stop above ask waits", domain.SideBuy, domain.OrderTypeStopMarket, 51000, 0, domain.OrderStatusAcknowledged, false},
+		{"triggered stop-limit crossing fills", domain.SideSell, domain.OrderTypeStopLimit, 49950, 49800, domain.OrderStatusFilled, true},
+		{"triggered stop-limit away rests", domain.SideSell, domain.OrderTypeStopLimit, 49950, 50500, domain.OrderStatusAcknowledged, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := domain.OrderRequest{
+				Symbol:    "BTC/USDT",
+				Side:      tt.side,
+				OrderType: tt.orderType,
+				Price:     decimal.NewFromInt(tt.limitPrice),
+				StopPrice: decimal.NewFromInt(tt.stopPrice),
+				Size:      decimal.NewFromFloat(0.5),
+			}
+			fill, err := sim.SimulateFill(req, book)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fill.Status != tt.wantStatus {
+				t.Errorf("status: got %s, want %s", fill.Status, tt.wantStatus)
+			}
+			if fill.FillSize.IsPositive() != tt.wantFilled {
+				t.Errorf("fill size: got %s, want filled=%v", fill.FillSize, tt.wantFilled)
+			}
+		})
+	}
+}
+
+func TestMatchResting(t *testing.T) {
+	order := &domain.Order{
+		Side:      domain.SideSell,
+		OrderType: domain.OrderTypeLimit,
+		Price:     decimal.NewFromInt(100),
+		Size:      decimal.NewFromInt(3),
+		Status:    domain.OrderStatusAcknowledged,
+	}
+	book := &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(99), Size: decimal.NewFromInt(10)}},
+	}
+	if fill := MatchResting(order, book); !fill.IsZero() {
+		t.Fatalf("expected no fill below the sell price, got %s", fill)
+	}
+
+	book.Bids = []domain.PriceLevel{
+		{Price: decimal.NewFromInt(101), Size: decimal.NewFromInt(1)},
+		{Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(1)},
+		{Price: decimal.NewFromInt(99), Size: decimal.NewFromInt(10)},
+	}
+	if fill := MatchResting(order, book); !fill.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected the 2 bid at or above 100 to fill, got %s", fill)
+	}
+	if order.Status != domain.OrderStatusPartialFill || !order.AvgFillPrice.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected a partial fill at 100, got %s at %s", order.Status, order.AvgFillPrice)
+	}
+
+	if fill := MatchResting(order, book); !fill.Equal(decimal.NewFromInt(1)) || order.Status != domain.OrderStatusFilled {
+		t.Errorf("expected the last 1 to fill the order, got %s and %s", fill, order.Status)
+	}
+}
+
+func TestFillSimulator_QueueModel(t *testing.T) {
+	sim := NewFillSimulator(0, 0, decimal.NewFromFloat(2), decimal.NewFromFloat(5))
+	sim.SetQueueModel(QueueModel{Ahead: 1, FillProbability: 1})
+
+	book := &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(5)}},
+		Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(101), Size: decimal.NewFromInt(5)}},
+	}
+	order := &domain.Order{
+		VenueID:   "v-1",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeLimit,
+		Price:     decimal.NewFromInt(100),
+		Size:      decimal.NewFromInt(2),
+		Status:    domain.OrderStatusAcknowledged,
+	}
+	sim.Rest(order, book)
+
+	// Touching the price is not enough while the queue is ahead.
+	book.Asks = []domain.PriceLevel{{Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(5)}}
+	if fill := sim.MatchBook(order, book); !fill.IsZero() {
+		t.Fatalf("expected no fill on touch, got %s", fill)
+	}
+
+	sell := func(size int64) domain.Trade {
+		return domain.Trade{Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(size), Side: domain.SideSell}
+	}
+	if fill := sim.MatchTrade(order, sell(4)); !fill.IsZero() {
+		t.Fatalf("expected the first 4 to go to the 5 queued ahead, got %s", fill)
+	}
+	buy := sell(10)
+	buy.Side = domain.SideBuy
+	if fill := sim.MatchTrade(order, buy); !fill.IsZero() {
+		t.Fatalf("expected a buy aggressor not to hit a resting buy, got %s", fill)
+	}
+	if fill := sim.MatchTrade(order, sell(2)); !fill.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("expected 1 left after the queue to fill, got %s", fill)
+	}
+
+	// Size quoted through the price fills the rest at once.
+	book.Asks = []domain.PriceLevel{{Price: decimal.NewFromInt(99), Size: decimal.NewFromInt(5)}}
+	if fill := sim.MatchBook(order, book); !fill.Equal(decimal.NewFromInt(1)) || order.Status != domain.OrderStatusFilled {
+		t.Errorf("expected the trade-through to fill the last 1, got %s and %s", fill, order.Status)
+	}
+
+	never := NewFillSimulator(0, 0, decimal.NewFromFloat(2), decimal.NewFromFloat(5))
+	never.SetQueueModel(QueueModel{Ahead: 0, FillProbability: 0})
+	front := &domain.Order{VenueID: "v-2", Side: domain.SideBuy, OrderType: domain.OrderTypeLimit,
+		Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(1), Status: domain.OrderStatusAcknowledged}
+	never.Rest(front, book)
+	if fill := never.MatchTrade(front, sell(10)); !fill.IsZero() {
+		t.Errorf("expected no fill with fill probability 0, got %s", fill)
+	}
+}
+
+func TestFillSimulator_ImpactModel(t *testing.T) {
+	sim := NewFillSimulator(0, 0, decimal.Zero, decimal.Zero)
+	sim.SetImpactModel(ImpactModel{CoefficientBps: 10})
+
+	book := &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(9990), Size: decimal.NewFromInt(4)}},
+		Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(10000), Size: decimal.NewFromInt(4)}},
+	}
+	fill := func(side domain.Side, orderType domain.OrderType, price int64) decimal.Decimal {
+		t.Helper()
+		f, err := sim.SimulateFill(domain.OrderRequest{
+			Symbol:    "BTC/USDT",
+			Side:      side,
+			OrderType: orderType,
+			Price:     decimal.NewFromInt(price),
+			Size:      decimal.NewFromInt(1),
+		}, book)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return f.FillPrice
+	}
+
+	// A quarter of the depth costs 10 × sqrt(0.25) = 5 bps over the walk.
+	if got := fill(domain.SideBuy, domain.OrderTypeMarket, 0); !got.Equal(decimal.NewFromInt(10005)) {
+		t.Errorf("market buy: expected 10005, got %s", got)
+	}
+	if got := fill(domain.SideSell, domain.OrderTypeMarket, 0); !got.Equal(decimal.NewFromFloat(9985.005)) {
+		t.Errorf("market sell: expected 9985.005, got %s", got)
+	}
+	if got := fill(domain.SideBuy, domain.OrderTypeLimit, 10002); !got.Equal(decimal.NewFromInt(10002)) {
+		t.Errorf("crossing limit buy: expected to stop at its limit 10002, got %s", got)
+	}
+}
+
+func TestTradeDriftVolatility(t *testing.T) {
+	start := time.Now()
+	var trades []*domain.Trade
+	price := 10000.0
+	for i := 0; i < 11; i++ {
+		trades = append(trades, &domain.Trade{
+			Price:     decimal.NewFromFloat(price),
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+		})
+		price *= 1.0001 // +1 bp a second
+	}
+
+	drift, vol, ok := TradeDriftVolatility(trades)
+	if !ok {
+		t.Fatal("expected an estimate from 11 trades over 10s")
+	}
+	if math.Abs(drift-1) > 0.01 {
+		t.Errorf("expected a drift of 1 bp/s, got %f", drift)
+	}
+	if math.Abs(vol-1) > 0.01 {
+		t.Errorf("expected a volatility of 1 bp/sqrt(s), got %f", vol)
+	}
+	if _, _, ok := TradeDriftVolatility(trades[:5]); ok {
+		t.Error("expected no estimate from 5 trades")
+	}
+
+	sim := NewFillSimulator(1000, 0, decimal.Zero, decimal.Zero)
+	sim.SetImpactModel(ImpactModel{Trades: func(string) []*domain.Trade { return trades }})
+	book := &domain.OrderBookSnapshot{
+		Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(10000), Size: decimal.NewFromInt(1)}},
+	}
+	f, err := sim.SimulateFill(domain.OrderRequest{
+		Symbol:    "BTC/USDT",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeMarket,
+		Size:      decimal.NewFromInt(1),
+	}, book)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 1 bp of drift plus at most 6 sigma of 1 bp noise over the second.
+	if f.FillPrice.Equal(decimal.NewFromInt(10000)) || f.FillPrice.Sub(decimal.NewFromInt(10001)).Abs().GreaterThan(decimal.NewFromInt(6)) {
+		t.Errorf("expected the fill moved by the latency drift, got %s", f.FillPrice)
+	}
+}
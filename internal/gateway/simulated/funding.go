@@ -0,0 +1,238 @@
+package simulated
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/marketdata"
+)
+
+// FundingAccruer is implemented by gateways that settle funding on the perp
+// positions their simulated fills open. RunFunding tracks the funding rates
+// it is given and, at each rate's funding time, charges or credits the
+// position on its symbol, reporting each payment to pay. It blocks until ctx
+// is cancelled or rates is closed.
+type FundingAccruer interface {
+	RunFunding(ctx context.Context, rates <-chan domain.FundingRate, pay func(domain.AccountActivity))
+}
+
+// FundingLedger keeps the perp positions opened by simulated fills and the
+// funding rate announced for each symbol's next snapshot, and settles the
+// funding due on them. It also keeps each position's entry price and the
+// PnL realized by fills and funding, for margining them (see margin.go).
+type FundingLedger struct {
+	venue string
+
+	mu        sync.Mutex
+	positions map[string]perpPosition       // symbol -> position
+	filled    map[string]fillCount          // venue ID -> fills already counted
+	rates     map[string]domain.FundingRate // symbol -> rate for the next snapshot
+	realized  decimal.Decimal               // PnL of closed size and funding
+}
+
+// perpPosition is a signed position, long positive, and its average entry
+// price.
+type perpPosition struct {
+	size  decimal.Decimal
+	entry decimal.Decimal
+}
+
+// fillCount is how much of an order the ledger has counted.
+type fillCount struct {
+	size     decimal.Decimal
+	notional decimal.Decimal
+}
+
+func NewFundingLedger(venue string) *FundingLedger {
+	return &FundingLedger{
+		venue:     venue,
+		positions: make(map[string]perpPosition),
+		filled:    make(map[string]fillCount),
+		rates:     make(map[string]domain.FundingRate),
+	}
+}
+
+// Track adds what order has filled since it was last tracked to the
+// position on its symbol. Only perp orders are counted. An order is
+// forgotten once it is done and must not be tracked again.
+func (l *FundingLedger) Track(order *domain.Order) {
+	if order.InstrumentType != domain.InstrumentPerp {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counted := l.filled[order.VenueID]
+	now := fillCount{size: order.FilledSize, notional: order.FilledSize.Mul(order.AvgFillPrice)}
+	if order.Status.IsTerminal() {
+		delete(l.filled, order.VenueID)
+	} else {
+		l.filled[order.VenueID] = now
+	}
+	delta := now.size.Sub(counted.size)
+	if !delta.IsPositive() {
+		return
+	}
+	price := now.notional.Sub(counted.notional).Div(delta)
+	if order.Side == domain.SideSell {
+		delta = delta.Neg()
+	}
+	l.fill(order.Symbol, delta, price)
+}
+
+// fill applies a signed fill of delta at price to the position on symbol,
+// realizing the PnL of any size it closes.
+func (l *FundingLedger) fill(symbol string, delta, price decimal.Decimal) {
+	pos := l.positions[symbol]
+	size := pos.size.Add(delta)
+	switch {
+	case pos.size.IsZero() || pos.size.Sign() == delta.Sign():
+		pos.entry = pos.size.Abs().Mul(pos.entry).Add(delta.Abs().Mul(price)).Div(size.Abs())
+	default:
+		closed := decimal.Min(delta.Abs(), pos.size.Abs())
+		if pos.size.IsNegative() {
+			closed = closed.Neg()
+		}
+		l.realized = l.realized.Add(closed.Mul(price.Sub(pos.entry)))
+		if size.IsZero() {
+			pos.entry = decimal.Zero
+		} else if size.Sign() != pos.size.Sign() {
+			pos.entry = price
+		}
+	}
+	pos.size = size
+	if size.IsZero() {
+		delete(l.positions, symbol)
+		return
+	}
+	l.positions[symbol] = pos
+}
+
+// Open adds a position held before the ledger started: size on symbol,
+// long positive, entered at entry. It is funded, margined and closed like
+// one simulated fills opened.
+func (l *FundingLedger) Open(symbol string, size, entry decimal.Decimal) {
+	if size.IsZero() {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fill(symbol, size, entry)
+}
+
+// TrackUpdates tracks the order behind each of updates. Call it before the
+// orders that updates report as done are dropped from orders.
+func (l *FundingLedger) TrackUpdates(orders map[string]*domain.Order, updates []domain.OrderUpdate) {
+	for _, u := range updates {
+		if order, ok := orders[u.VenueID]; ok {
+			l.Track(order)
+		}
+	}
+}
+
+// Position returns the perp position the ledger holds on symbol.
+func (l *FundingLedger) Position(symbol string) decimal.Decimal {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.positions[symbol].size
+}
+
+// Update records rate as the one the next snapshot on its symbol settles
+// at. Call Settle first, so a snapshot that has passed is settled at the
+// rate that was announced for it.
+func (l *FundingLedger) Update(rate domain.FundingRate) {
+	if rate.NextTime.IsZero() {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rates[rate.Symbol] = rate
+}
+
+// Settle settles every snapshot whose funding time is not after now, on the
+// positions held at that moment, and returns the payments: positive rates
+// charge longs and credit shorts. mark prices a symbol; a snapshot that
+// cannot be priced is dropped without payment.
+func (l *FundingLedger) Settle(now time.Time, mark func(symbol string) (decimal.Decimal, bool)) []domain.AccountActivity {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var payments []domain.AccountActivity
+	for symbol, rate := range l.rates {
+		if rate.NextTime.After(now) {
+			continue
+		}
+		delete(l.rates, symbol)
+		size := l.positions[symbol].size
+		if size.IsZero() {
+			continue
+		}
+		price, ok := mark(symbol)
+		if !ok {
+			continue
+		}
+		amount := size.Mul(price).Mul(rate.Rate).Neg()
+		l.realized = l.realized.Add(amount)
+		payments = append(payments, domain.AccountActivity{
+			Venue:     l.venue,
+			Type:      domain.ActivityFunding,
+			Symbol:    symbol,
+			Asset:     "USDT",
+			Price:     price,
+			Size:      size,
+			Amount:    amount,
+			Timestamp: rate.NextTime,
+		})
+	}
+	return payments
+}
+
+// RunFundingLoop drives ledger for one venue: rates on other venues are
+// ignored, and due snapshots are settled as rates arrive and once a second.
+// Each payment is passed to settle and then to pay, if set.
+func RunFundingLoop(ctx context.Context, ledger *FundingLedger, rates <-chan domain.FundingRate,
+	mark func(symbol string) (decimal.Decimal, bool), settle, pay func(domain.AccountActivity)) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rate, ok := <-rates:
+			if !ok {
+				return
+			}
+			if rate.Venue != ledger.venue {
+				continue
+			}
+			payFunding(ledger.Settle(time.Now(), mark), settle, pay)
+			ledger.Update(rate)
+		case now := <-ticker.C:
+			payFunding(ledger.Settle(now, mark), settle, pay)
+		}
+	}
+}
+
+// MidMarks prices symbols on venue at the mid of their latest book in md.
+func MidMarks(md *marketdata.Service, venue string) func(symbol string) (decimal.Decimal, bool) {
+	return func(symbol string) (decimal.Decimal, bool) {
+		book, ok := md.GetOrderBook(venue, symbol)
+		if !ok || len(book.Bids) == 0 || len(book.Asks) == 0 {
+			return decimal.Zero, false
+		}
+		return book.Bids[0].Price.Add(book.Asks[0].Price).Div(decimal.NewFromInt(2)), true
+	}
+}
+
+func payFunding(payments []domain.AccountActivity, settle, pay func(domain.AccountActivity)) {
+	for _, p := range payments {
+		settle(p)
+		if pay != nil {
+			pay(p)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package simulated
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestFundingLedger(t *testing.T) {
+	ledger := NewFundingLedger("kcex")
+	mark := func(string) (decimal.Decimal, bool) { return decimal.NewFromInt(50000), true }
+
+	// A resting short perp fills in two steps; a spot fill is not a position.
+	short := &domain.Order{VenueID: "1", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideSell, Size: decimal.NewFromInt(2), FilledSize: decimal.NewFromInt(1), Status: domain.OrderStatusPartialFill}
+	ledger.Track(short)
+	short.FilledSize, short.Status = decimal.NewFromInt(2), domain.OrderStatusFilled
+	ledger.Track(short)
+	ledger.Track(&domain.Order{VenueID: "2", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentSpot,
+		Side: domain.SideBuy, FilledSize: decimal.NewFromInt(5), Status: domain.OrderStatusFilled})
+	if got := ledger.Position("BTCUSDT"); !got.Equal(decimal.NewFromInt(-2)) {
+		t.Fatalf("position: got %s, want -2", got)
+	}
+
+	fundingTime := time.Now()
+	ledger.Update(domain.FundingRate{Venue: "kcex", Symbol: "BTCUSDT", Rate: decimal.NewFromFloat(0.0001), NextTime: fundingTime})
+	if p := ledger.Settle(fundingTime.Add(-time.Second), mark); len(p) != 0 {
+		t.Fatalf("settled before the funding time: %+v", p)
+	}
+
+	p := ledger.Settle(fundingTime, mark)
+	if len(p) != 1 {
+		t.Fatalf("expected one payment, got %d", len(p))
+	}
+	// The short receives a positive rate: 2 * 50000 * 0.0001.
+	if p[0].Type != domain.ActivityFunding || !p[0].Amount.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("payment: got %s %s, want FUNDING 10", p[0].Type, p[0].Amount)
+	}
+	if again := ledger.Settle(fundingTime.Add(time.Hour), mark); len(again) != 0 {
+		t.Errorf("snapshot settled twice: %+v", again)
+	}
+}
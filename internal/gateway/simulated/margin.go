@@ -0,0 +1,147 @@
+package simulated
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// MarginSimulator is implemented by gateways that margin the perp positions
+// their simulated fills open. RunMargin checks the margin once a second and
+// reports each liquidation to liquidated. It blocks until ctx is cancelled;
+// a gateway without a margin model returns at once.
+type MarginSimulator interface {
+	RunMargin(ctx context.Context, liquidated func(Liquidation))
+}
+
+// MarginModel cross-margins a venue's simulated perp positions. Their equity
+// is Collateral plus the PnL the ledger has realized plus the positions'
+// unrealized PnL at mark.
+type MarginModel struct {
+	Collateral decimal.Decimal
+	// Leverage is the notional the positions may reach as a multiple of
+	// equity. Going over it is reported, not enforced: the point is to see
+	// the mistake in dry run.
+	Leverage decimal.Decimal
+	// MaintenanceRate is the share of notional equity must stay above; at
+	// or below it every position is liquidated at mark.
+	MaintenanceRate decimal.Decimal
+	// LiquidationFeeRate is charged on the notional a liquidation closes.
+	LiquidationFeeRate decimal.Decimal
+}
+
+// MarginStatus is a venue's perp margin at one moment.
+type MarginStatus struct {
+	Equity      decimal.Decimal
+	Notional    decimal.Decimal // summed absolute size times mark
+	Maintenance decimal.Decimal // notional times the maintenance rate
+}
+
+// Leverage is notional over equity, or zero if equity is not positive.
+func (s MarginStatus) Leverage() decimal.Decimal {
+	if !s.Equity.IsPositive() {
+		return decimal.Zero
+	}
+	return s.Notional.Div(s.Equity)
+}
+
+// Liquidation is a simulated forced close of a venue's perp positions.
+type Liquidation struct {
+	Venue     string
+	Positions []domain.Position // as held before the close, marked
+	Status    MarginStatus      // at the breach
+	Fee       decimal.Decimal
+	PnL       decimal.Decimal // realized by the close, after Fee
+	Timestamp time.Time
+}
+
+// CheckMargin returns the margin of the ledger's positions under model,
+// priced by mark. When equity has fallen to the maintenance margin, every
+// position is closed at mark and the liquidation is returned too. ok is
+// false, and nothing is checked, if a position cannot be priced.
+func (l *FundingLedger) CheckMargin(model MarginModel, mark func(symbol string) (decimal.Decimal, bool), now time.Time) (status MarginStatus, liq *Liquidation, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	marks := make(map[string]decimal.Decimal, len(l.positions))
+	unrealized := decimal.Zero
+	for symbol, pos := range l.positions {
+		price, ok := mark(symbol)
+		if !ok {
+			return MarginStatus{}, nil, false
+		}
+		marks[symbol] = price
+		unrealized = unrealized.Add(pos.size.Mul(price.Sub(pos.entry)))
+		status.Notional = status.Notional.Add(pos.size.Abs().Mul(price))
+	}
+	status.Equity = model.Collateral.Add(l.realized).Add(unrealized)
+	status.Maintenance = status.Notional.Mul(model.MaintenanceRate)
+	if !status.Notional.IsPositive() || status.Equity.GreaterThan(status.Maintenance) {
+		return status, nil, true
+	}
+
+	liq = &Liquidation{Venue: l.venue, Status: status, Timestamp: now}
+	for symbol, pos := range l.positions {
+		price := marks[symbol]
+		pnl := pos.size.Mul(price.Sub(pos.entry))
+		liq.Positions = append(liq.Positions, domain.Position{
+			Venue:          l.venue,
+			Asset:          symbol,
+			InstrumentType: domain.InstrumentPerp,
+			Size:           pos.size,
+			EntryPrice:     pos.entry,
+			UnrealizedPnL:  pnl,
+			UpdatedAt:      now,
+		})
+		liq.Fee = liq.Fee.Add(pos.size.Abs().Mul(price).Mul(model.LiquidationFeeRate))
+		liq.PnL = liq.PnL.Add(pnl)
+		delete(l.positions, symbol)
+	}
+	liq.PnL = liq.PnL.Sub(liq.Fee)
+	l.realized = l.realized.Add(liq.PnL)
+	return status, liq, true
+}
+
+// RunMarginLoop checks ledger's margin under model once a second until ctx
+// is cancelled. Each liquidation is passed to settle and then to
+// liquidated, if set. Going over the model's leverage is logged once each
+// time it happens.
+func RunMarginLoop(ctx context.Context, ledger *FundingLedger, model MarginModel,
+	mark func(symbol string) (decimal.Decimal, bool), settle, liquidated func(Liquidation), logger *slog.Logger) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	overLeveraged := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			status, liq, ok := ledger.CheckMargin(model, mark, now)
+			if !ok {
+				continue
+			}
+			over := model.Leverage.IsPositive() && status.Notional.GreaterThan(status.Equity.Mul(model.Leverage))
+			if over && !overLeveraged {
+				logger.Warn("simulated perp positions over leverage",
+					"venue", ledger.venue,
+					"notional", status.Notional.StringFixed(2),
+					"equity", status.Equity.StringFixed(2),
+					"leverage", status.Leverage().StringFixed(2),
+					"max_leverage", model.Leverage.String(),
+				)
+			}
+			overLeveraged = over
+			if liq == nil {
+				continue
+			}
+			settle(*liq)
+			if liquidated != nil {
+				liquidated(*liq)
+			}
+		}
+	}
+}
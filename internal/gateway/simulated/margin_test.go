@@ -0,0 +1,75 @@
+package simulated
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestFundingLedgerMargin(t *testing.T) {
+	ledger := NewFundingLedger("kcex")
+	model := MarginModel{
+		Collateral:         decimal.NewFromInt(1000),
+		Leverage:           decimal.NewFromInt(10),
+		MaintenanceRate:    decimal.NewFromFloat(0.005),
+		LiquidationFeeRate: decimal.NewFromFloat(0.005),
+	}
+	price := decimal.NewFromInt(50000)
+	mark := func(string) (decimal.Decimal, bool) { return price, true }
+
+	ledger.Track(&domain.Order{VenueID: "1", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideSell, FilledSize: decimal.NewFromInt(1), AvgFillPrice: decimal.NewFromInt(50000),
+		Status: domain.OrderStatusFilled})
+
+	status, liq, ok := ledger.CheckMargin(model, mark, time.Now())
+	if !ok || liq != nil {
+		t.Fatalf("expected no liquidation at entry, got %+v, %v", liq, ok)
+	}
+	if !status.Equity.Equal(decimal.NewFromInt(1000)) || !status.Leverage().Equal(decimal.NewFromInt(50)) {
+		t.Errorf("status at entry: equity %s, leverage %s; want 1000, 50", status.Equity, status.Leverage())
+	}
+
+	// 800 against the short leaves 200 of equity, under 0.5% of 50800.
+	price = decimal.NewFromInt(50800)
+	status, liq, ok = ledger.CheckMargin(model, mark, time.Now())
+	if !ok || liq == nil {
+		t.Fatalf("expected a liquidation, got status %+v", status)
+	}
+	if !liq.Fee.Equal(decimal.NewFromInt(254)) || !liq.PnL.Equal(decimal.NewFromInt(-1054)) {
+		t.Errorf("liquidation: fee %s, pnl %s; want 254, -1054", liq.Fee, liq.PnL)
+	}
+	if len(liq.Positions) != 1 || !liq.Positions[0].Size.Equal(decimal.NewFromInt(-1)) {
+		t.Errorf("expected the short in the liquidation, got %+v", liq.Positions)
+	}
+	if !ledger.Position("BTCUSDT").IsZero() {
+		t.Errorf("expected the position closed, got %s", ledger.Position("BTCUSDT"))
+	}
+	status, liq, _ = ledger.CheckMargin(model, mark, time.Now())
+	if liq != nil || !status.Equity.Equal(decimal.NewFromInt(-54)) {
+		t.Errorf("after the liquidation: equity %s, liquidation %+v; want -54, none", status.Equity, liq)
+	}
+}
+
+func TestFundingLedgerRealizesClosedSize(t *testing.T) {
+	ledger := NewFundingLedger("kcex")
+	fill := func(id string, side domain.Side, size, price int64) {
+		ledger.Track(&domain.Order{VenueID: id, Symbol: "ETHUSDT", InstrumentType: domain.InstrumentPerp, Side: side,
+			FilledSize: decimal.NewFromInt(size), AvgFillPrice: decimal.NewFromInt(price), Status: domain.OrderStatusFilled})
+	}
+	fill("1", domain.SideBuy, 2, 100)
+	fill("2", domain.SideSell, 1, 110)
+	fill("3", domain.SideSell, 2, 90)
+
+	if got := ledger.Position("ETHUSDT"); !got.Equal(decimal.NewFromInt(-1)) {
+		t.Fatalf("position: got %s, want -1", got)
+	}
+	// +10 on the first close, -10 on the second; the flip opens a short at 90.
+	status, _, _ := ledger.CheckMargin(MarginModel{Collateral: decimal.NewFromInt(100)},
+		func(string) (decimal.Decimal, bool) { return decimal.NewFromInt(80), true }, time.Now())
+	if !status.Equity.Equal(decimal.NewFromInt(110)) {
+		t.Errorf("equity: got %s, want 110", status.Equity)
+	}
+}
@@ -0,0 +1,128 @@
+package simulated
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// RestingMatcher is implemented by gateways that keep simulated limit orders
+// resting and fill them from the book updates and trades they are given.
+// RunMatching blocks until ctx is cancelled or either channel is closed;
+// fills are pushed on the gateway's SubscribeOrderUpdates stream.
+type RestingMatcher interface {
+	RunMatching(ctx context.Context, books <-chan domain.OrderBookSnapshot, trades <-chan domain.Trade)
+}
+
+// MatchResting fills a resting limit order against book once the market
+// crosses its price: a buy against asks at or below it, a sell against bids
+// at or above it. Fills are at the order's own price, as a maker's would be,
+// for up to the size quoted through it. order is updated in place and the
+// size filled now is returned.
+func MatchResting(order *domain.Order, book *domain.OrderBookSnapshot) decimal.Decimal {
+	return matchBook(order, book, true)
+}
+
+// matchBook is MatchResting, counting size quoted at the order's own price
+// only if atPrice is set.
+func matchBook(order *domain.Order, book *domain.OrderBookSnapshot, atPrice bool) decimal.Decimal {
+	if !resting(order) {
+		return decimal.Zero
+	}
+
+	levels := book.Asks
+	crosses := func(p decimal.Decimal) bool { return p.LessThan(order.Price) }
+	if order.Side == domain.SideSell {
+		levels = book.Bids
+		crosses = func(p decimal.Decimal) bool { return p.GreaterThan(order.Price) }
+	}
+	available := decimal.Zero
+	for _, level := range levels {
+		if !crosses(level.Price) && !(atPrice && level.Price.Equal(order.Price)) {
+			break
+		}
+		available = available.Add(level.Size)
+	}
+	return fillResting(order, available)
+}
+
+// resting reports whether order is a limit order still waiting for size.
+func resting(order *domain.Order) bool {
+	return order.OrderType == domain.OrderTypeLimit && !order.Status.IsTerminal() &&
+		order.FilledSize.LessThan(order.Size)
+}
+
+// fillResting fills up to available of order's remaining size at its own
+// price and returns the size filled.
+func fillResting(order *domain.Order, available decimal.Decimal) decimal.Decimal {
+	fill := decimal.Min(order.Size.Sub(order.FilledSize), available)
+	if !fill.IsPositive() {
+		return decimal.Zero
+	}
+
+	filled := order.FilledSize.Add(fill)
+	order.AvgFillPrice = order.AvgFillPrice.Mul(order.FilledSize).Add(order.Price.Mul(fill)).Div(filled)
+	order.FilledSize = filled
+	order.Status = domain.OrderStatusPartialFill
+	if filled.GreaterThanOrEqual(order.Size) {
+		order.Status = domain.OrderStatusFilled
+	}
+	order.UpdatedAt = time.Now()
+	return fill
+}
+
+// MatchOrders matches every order in orders on book's symbol against book
+// with sim and returns an update for each one that filled.
+func MatchOrders(sim FillSimulator, orders map[string]*domain.Order, book *domain.OrderBookSnapshot) []domain.OrderUpdate {
+	return matchOrders(sim, orders, book.Symbol, func(order *domain.Order) decimal.Decimal {
+		return sim.MatchBook(order, book)
+	})
+}
+
+// MatchOrdersOnTrade matches every order in orders on trade's symbol against
+// trade with sim and returns an update for each one that filled.
+func MatchOrdersOnTrade(sim FillSimulator, orders map[string]*domain.Order, trade domain.Trade) []domain.OrderUpdate {
+	return matchOrders(sim, orders, trade.Symbol, func(order *domain.Order) decimal.Decimal {
+		return sim.MatchTrade(order, trade)
+	})
+}
+
+func matchOrders(sim FillSimulator, orders map[string]*domain.Order, symbol string, match func(*domain.Order) decimal.Decimal) []domain.OrderUpdate {
+	var updates []domain.OrderUpdate
+	for venueID, order := range orders {
+		if order.Symbol != symbol || !match(order).IsPositive() {
+			continue
+		}
+		if order.Status.IsTerminal() {
+			sim.Forget(venueID)
+		}
+		updates = append(updates, fillUpdate(order))
+	}
+	return updates
+}
+
+// fillUpdate is the order update that reports order's fills so far.
+func fillUpdate(order *domain.Order) domain.OrderUpdate {
+	return domain.OrderUpdate{
+		Venue:        order.Venue,
+		VenueID:      order.VenueID,
+		Status:       order.Status,
+		FilledSize:   order.FilledSize,
+		AvgFillPrice: order.AvgFillPrice,
+		Timestamp:    order.UpdatedAt,
+	}
+}
+
+// SendUpdates pushes updates onto ch, giving up if ctx is cancelled first.
+func SendUpdates(ctx context.Context, ch chan<- domain.OrderUpdate, updates []domain.OrderUpdate) {
+	for _, u := range updates {
+		select {
+		case ch <- u:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
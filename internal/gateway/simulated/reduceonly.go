@@ -0,0 +1,70 @@
+package simulated
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// reducible is how much of an order on side can fill against the signed
+// position without opening one the other way: all of the position if side
+// closes it, nothing otherwise.
+func reducible(side domain.Side, position decimal.Decimal) decimal.Decimal {
+	if side == domain.SideBuy {
+		position = position.Neg()
+	}
+	return decimal.Max(position, decimal.Zero)
+}
+
+// LimitReduceOnly caps a reduce-only request to the size that closes the
+// perp position the ledger holds on its symbol, as perp venues do. A
+// request with nothing to reduce is rejected with an ErrorReduceOnly
+// VenueError. Other requests are returned unchanged.
+func (l *FundingLedger) LimitReduceOnly(req domain.OrderRequest) (domain.OrderRequest, error) {
+	if !req.ReduceOnly {
+		return req, nil
+	}
+	room := reducible(req.Side, l.Position(req.Symbol))
+	if !room.IsPositive() {
+		return req, &gateway.VenueError{
+			Venue:    l.venue,
+			Op:       "order rejected",
+			Code:     "reduce_only",
+			Message:  fmt.Sprintf("no position on %s for a reduce-only %s to reduce", req.Symbol, req.Side),
+			Category: gateway.ErrorReduceOnly,
+		}
+	}
+	req.Size = decimal.Min(req.Size, room)
+	return req, nil
+}
+
+// TrimReduceOnly shrinks the resting reduce-only orders in orders on symbol
+// to what still reduces the position ledger holds, once other fills have
+// closed some of it, and cancels those left with nothing to reduce. It
+// returns an update for each order cancelled; sim forgets them.
+func TrimReduceOnly(sim FillSimulator, ledger *FundingLedger, orders map[string]*domain.Order, symbol string) []domain.OrderUpdate {
+	position := ledger.Position(symbol)
+	var updates []domain.OrderUpdate
+	for venueID, order := range orders {
+		if !order.ReduceOnly || order.Symbol != symbol || order.Status.IsTerminal() {
+			continue
+		}
+		room := reducible(order.Side, position)
+		if order.Size.Sub(order.FilledSize).LessThanOrEqual(room) {
+			continue
+		}
+		order.UpdatedAt = time.Now()
+		if room.IsPositive() {
+			order.Size = order.FilledSize.Add(room)
+			continue
+		}
+		order.Status = domain.OrderStatusCancelled
+		sim.Forget(venueID)
+		updates = append(updates, fillUpdate(order))
+	}
+	return updates
+}
@@ -0,0 +1,71 @@
+package simulated
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+func TestLimitReduceOnly(t *testing.T) {
+	ledger := NewFundingLedger("bybit")
+	ledger.Track(&domain.Order{VenueID: "1", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideSell, Size: decimal.NewFromInt(2), FilledSize: decimal.NewFromInt(2),
+		AvgFillPrice: decimal.NewFromInt(50000), Status: domain.OrderStatusFilled})
+
+	req := domain.OrderRequest{Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideBuy, Size: decimal.NewFromInt(3), ReduceOnly: true}
+	got, err := ledger.LimitReduceOnly(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Size.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected the buy capped to the 2 short, got %s", got.Size)
+	}
+
+	// Selling would add to the short.
+	req.Side = domain.SideSell
+	if _, err := ledger.LimitReduceOnly(req); gateway.ErrorCategoryOf(err) != gateway.ErrorReduceOnly {
+		t.Errorf("expected a reduce-only rejection, got %v", err)
+	}
+
+	req.ReduceOnly = false
+	if got, err := ledger.LimitReduceOnly(req); err != nil || !got.Size.Equal(req.Size) {
+		t.Errorf("expected a plain order untouched, got %s, %v", got.Size, err)
+	}
+}
+
+func TestTrimReduceOnly(t *testing.T) {
+	sim := NewFillSimulator(0, 0, decimal.Zero, decimal.Zero)
+	ledger := NewFundingLedger("bybit")
+	long := &domain.Order{VenueID: "1", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideBuy, Size: decimal.NewFromInt(2), FilledSize: decimal.NewFromInt(2),
+		AvgFillPrice: decimal.NewFromInt(50000), Status: domain.OrderStatusFilled}
+	ledger.Track(long)
+
+	exit := &domain.Order{VenueID: "2", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideSell, OrderType: domain.OrderTypeLimit, ReduceOnly: true,
+		Price: decimal.NewFromInt(51000), Size: decimal.NewFromInt(2), Status: domain.OrderStatusAcknowledged}
+	orders := map[string]*domain.Order{"1": long, "2": exit}
+
+	if updates := TrimReduceOnly(sim, ledger, orders, "BTCUSDT"); len(updates) != 0 || !exit.Size.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected the exit left alone while it fits the position, got %d updates, size %s", len(updates), exit.Size)
+	}
+
+	// Another sell closes half the long, so the exit shrinks to the rest.
+	ledger.Track(&domain.Order{VenueID: "3", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideSell, FilledSize: decimal.NewFromInt(1), AvgFillPrice: decimal.NewFromInt(50500), Status: domain.OrderStatusFilled})
+	if updates := TrimReduceOnly(sim, ledger, orders, "BTCUSDT"); len(updates) != 0 || !exit.Size.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("expected the exit shrunk to 1, got %d updates, size %s", len(updates), exit.Size)
+	}
+
+	// With the long gone there is nothing left to reduce.
+	ledger.Track(&domain.Order{VenueID: "4", Symbol: "BTCUSDT", InstrumentType: domain.InstrumentPerp,
+		Side: domain.SideSell, FilledSize: decimal.NewFromInt(1), AvgFillPrice: decimal.NewFromInt(50500), Status: domain.OrderStatusFilled})
+	updates := TrimReduceOnly(sim, ledger, orders, "BTCUSDT")
+	if len(updates) != 1 || updates[0].VenueID != "2" || updates[0].Status != domain.OrderStatusCancelled {
+		t.Fatalf("expected the exit cancelled, got %+v", updates)
+	}
+}
@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorCategory classifies why a venue rejected a request, so callers can
+// react to a rejection without parsing venue payloads.
+type ErrorCategory string
+
+const (
+	// ErrorUnknown is a rejection whose code the gateway does not map.
+	ErrorUnknown ErrorCategory = ""
+	// ErrorRateLimit means the request budget is spent; the same request
+	// may succeed later.
+	ErrorRateLimit ErrorCategory = "rate_limit"
+	// ErrorInsufficientBalance means the account cannot fund the order.
+	ErrorInsufficientBalance ErrorCategory = "insufficient_balance"
+	// ErrorInvalidPrice means the price is off the tick size or outside the
+	// venue's price band.
+	ErrorInvalidPrice ErrorCategory = "invalid_price"
+	// ErrorInvalidSize means the size is off the step size or below the
+	// venue's minimum size or notional.
+	ErrorInvalidSize ErrorCategory = "invalid_size"
+	// ErrorDuplicateOrder means the client order ID was already used, so
+	// the order may already be live.
+	ErrorDuplicateOrder ErrorCategory = "duplicate_order"
+	// ErrorOrderNotFound means the venue does not know the order, or it is
+	// no longer open.
+	ErrorOrderNotFound ErrorCategory = "order_not_found"
+	// ErrorSymbolUnavailable means the venue no longer trades the symbol;
+	// see ErrSymbolUnavailable.
+	ErrorSymbolUnavailable ErrorCategory = "symbol_unavailable"
+	// ErrorAuth means the credentials were refused: a bad key or signature,
+	// an expired key, or a missing permission.
+	ErrorAuth ErrorCategory = "auth"
+	// ErrorReduceOnly means a reduce-only order would not reduce the
+	// position: there is none on the order's side to close.
+	ErrorReduceOnly ErrorCategory = "reduce_only"
+)
+
+// Final reports whether sending the same request again cannot succeed
+// until something outside the request changes.
+func (c ErrorCategory) Final() bool {
+	switch c {
+	case ErrorInsufficientBalance, ErrorInvalidPrice, ErrorInvalidSize,
+		ErrorDuplicateOrder, ErrorOrderNotFound, ErrorSymbolUnavailable, ErrorAuth,
+		ErrorReduceOnly:
+		return true
+	}
+	return false
+}
+
+// VenueError is a request the venue rejected with an error code in its
+// response payload. Category is looked up from the code by the venue's
+// gateway.
+type VenueError struct {
+	Venue    string
+	Op       string // what was rejected, e.g. "API error" or "order rejected"
+	Code     string
+	Message  string
+	Category ErrorCategory
+}
+
+func (e *VenueError) Error() string {
+	return fmt.Sprintf("%s %s: code=%s msg=%s", e.Venue, e.Op, e.Code, e.Message)
+}
+
+// Is makes a symbol_unavailable rejection match ErrSymbolUnavailable.
+func (e *VenueError) Is(target error) bool {
+	return target == ErrSymbolUnavailable && e.Category == ErrorSymbolUnavailable
+}
+
+// ErrorCategoryOf returns the category of a venue rejection in err's chain.
+// An HTTP 429 counts as ErrorRateLimit and a 401 as ErrorAuth; anything
+// else that is not a VenueError is ErrorUnknown.
+func ErrorCategoryOf(err error) ErrorCategory {
+	var venueErr *VenueError
+	if errors.As(err, &venueErr) {
+		return venueErr.Category
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return ErrorRateLimit
+		case http.StatusUnauthorized:
+			return ErrorAuth
+		}
+	}
+	return ErrorUnknown
+}
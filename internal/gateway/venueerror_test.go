@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCategoryOf(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		want  ErrorCategory
+		final bool
+	}{
+		{"venue rejection", fmt.Errorf("place order: %w", &VenueError{Venue: "bybit", Code: "110007", Category: ErrorInsufficientBalance}), ErrorInsufficientBalance, true},
+		{"duplicate", &VenueError{Venue: "okx", Code: "51016", Category: ErrorDuplicateOrder}, ErrorDuplicateOrder, true},
+		{"rate limit", &VenueError{Venue: "okx", Code: "50011", Category: ErrorRateLimit}, ErrorRateLimit, false},
+		{"429", &HTTPError{StatusCode: 429}, ErrorRateLimit, false},
+		{"401", &HTTPError{StatusCode: 401}, ErrorAuth, true},
+		{"500", &HTTPError{StatusCode: 500}, ErrorUnknown, false},
+		{"plain error", errors.New("boom"), ErrorUnknown, false},
+	}
+	for _, tc := range cases {
+		got := ErrorCategoryOf(tc.err)
+		if got != tc.want {
+			t.Errorf("%s: category = %q, want %q", tc.name, got, tc.want)
+		}
+		if got.Final() != tc.final {
+			t.Errorf("%s: Final = %v, want %v", tc.name, got.Final(), tc.final)
+		}
+	}
+
+	gone := fmt.Errorf("place order: %w", &VenueError{Venue: "okx", Code: "51001", Category: ErrorSymbolUnavailable})
+	if !errors.Is(gone, ErrSymbolUnavailable) {
+		t.Error("expected a symbol_unavailable rejection to match ErrSymbolUnavailable")
+	}
+	if errors.Is(&VenueError{Category: ErrorInvalidPrice}, ErrSymbolUnavailable) {
+		t.Error("expected other rejections not to match ErrSymbolUnavailable")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/gateway"
 )
@@ -40,6 +42,28 @@ func New(wsURL, restURL, apiKey string, logger *slog.Logger) *Gateway {
 
 func (g *Gateway) Name() string { return "wallex" }
 
+// SetAPIErrorObserver implements gateway.APIErrorReporter.
+func (g *Gateway) SetAPIErrorObserver(fn gateway.APIErrorObserver) {
+	g.rest.retrier.OnError = fn
+}
+
+// SetWSReconnectObserver implements gateway.WSReconnectReporter.
+func (g *Gateway) SetWSReconnectObserver(fn gateway.WSReconnectObserver) {
+	g.ws.onReconnect = func() { fn("wallex") }
+}
+
+// SetLatencyObserver implements gateway.LatencyReporter.
+// Wallex does not stamp its stream events, so only REST is measured.
+func (g *Gateway) SetLatencyObserver(fn gateway.LatencyObserver) {
+	g.rest.retrier.OnLatency = fn
+}
+
+// SetEgress implements gateway.EgressConfigurable.
+func (g *Gateway) SetEgress(e gateway.Egress) {
+	e.ApplyClient(g.rest.httpClient)
+	g.ws.egress = e
+}
+
 func (g *Gateway) Connect(ctx context.Context) error {
 	return g.ws.connect(ctx)
 }
@@ -48,6 +72,10 @@ func (g *Gateway) Close() error {
 	return g.ws.close()
 }
 
+func (g *Gateway) Health(ctx context.Context) domain.VenueHealth {
+	return gateway.ProbeHealth(ctx, "wallex", g.rest.ping, &g.ws.state)
+}
+
 func (g *Gateway) SubscribeOrderBook(ctx context.Context, symbol string) (<-chan domain.OrderBookDelta, error) {
 	venueSymbol := domain.MapSymbol(symbol, domain.WallexSymbolMap)
 	ch := g.ws.subscribeOrderBook(venueSymbol)
@@ -87,10 +115,35 @@ func (g *Gateway) CancelOrder(ctx context.Context, orderID string) (*domain.Canc
 	return g.rest.cancelOrder(ctx, orderID)
 }
 
+// GetOrderStatus implements gateway.OrderStatusProvider.
+func (g *Gateway) GetOrderStatus(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	return g.rest.getOrder(ctx, orderID)
+}
+
+// PlaceOrders and CancelOrders loop over the single-order endpoints; Wallex
+// has no batch API.
+func (g *Gateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	return gateway.PlaceEach(ctx, reqs, g.rest.placeOrder)
+}
+
+func (g *Gateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.rest.cancelOrder)
+}
+
+// AmendOrder is unsupported: Wallex has no amend endpoint.
+func (g *Gateway) AmendOrder(_ context.Context, _ string, _, _ decimal.Decimal) (*domain.AmendAck, error) {
+	return nil, gateway.ErrAmendUnsupported
+}
+
 func (g *Gateway) GetOpenOrders(ctx context.Context, symbol string) ([]domain.Order, error) {
 	return g.rest.getOpenOrders(ctx, symbol)
 }
 
+// SubscribeOrderUpdates is not implemented yet; fills are only seen via REST.
+func (g *Gateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
+
 func (g *Gateway) GetBalances(ctx context.Context) (map[string]domain.Balance, error) {
 	return g.rest.getBalances(ctx)
 }
@@ -103,3 +156,30 @@ func (g *Gateway) GetPositions(ctx context.Context) ([]domain.Position, error) {
 func (g *Gateway) GetFeeTier(ctx context.Context) (*domain.FeeTier, error) {
 	return g.rest.getFeeTier(ctx)
 }
+
+func (g *Gateway) GetInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	return g.rest.getInstruments(ctx)
+}
+
+func (g *Gateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return g.rl.Status(), nil
+}
+
+// GetOrderBookSnapshot implements gateway.OrderBookSnapshotProvider.
+func (g *Gateway) GetOrderBookSnapshot(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+	return g.rest.getOrderBook(ctx, symbol)
+}
+
+// Withdraw, GetDepositAddress and GetTransferStatus are unsupported: the
+// Wallex API exposes no wallet endpoints to API keys.
+func (g *Gateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetDepositAddress(_ context.Context, _, _ string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+
+func (g *Gateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
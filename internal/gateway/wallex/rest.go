@@ -8,6 +8,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -23,6 +24,9 @@ type restClient struct {
 	httpClient  *http.Client
 	rateLimiter *gateway.RateLimiter
 	logger      *slog.Logger
+
+	// retrier retries transient REST failures and reports every failed attempt.
+	retrier gateway.RESTRetrier
 }
 
 func newRESTClient(baseURL, apiKey string, rl *gateway.RateLimiter, logger *slog.Logger) *restClient {
@@ -39,6 +43,7 @@ func newRESTClient(baseURL, apiKey string, rl *gateway.RateLimiter, logger *slog
 		},
 		rateLimiter: rl,
 		logger:      logger,
+		retrier:     gateway.NewRESTRetrier("wallex"),
 	}
 }
 
@@ -50,8 +55,35 @@ type wallexResponse struct {
 	Result  json.RawMessage `json:"result"`
 }
 
+// doRequest sends a request, retrying transient failures.
 func (c *restClient) doRequest(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory, authenticated bool) ([]byte, error) {
-	if err := c.rateLimiter.Acquire(ctx, category, 1); err != nil {
+	var data []byte
+	err := c.retrier.Do(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category, authenticated)
+		return err
+	})
+	return data, err
+}
+
+// doRequestOnce is doRequest without retries, for order placements that
+// cannot be replayed.
+func (c *restClient) doRequestOnce(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory, authenticated bool) ([]byte, error) {
+	var data []byte
+	err := c.retrier.Once(ctx, category, func() error {
+		var err error
+		data, err = c.send(ctx, method, path, body, category, authenticated)
+		return err
+	})
+	return data, err
+}
+
+// send makes one request attempt.
+func (c *restClient) send(ctx context.Context, method, path string, body interface{}, category domain.EndpointCategory, authenticated bool) ([]byte, error) {
+	waited := time.Now()
+	err := c.rateLimiter.Acquire(ctx, category, 1)
+	c.retrier.ObserveRateLimitWait(category, time.Since(waited))
+	if err != nil {
 		return nil, fmt.Errorf("rate limit: %w", err)
 	}
 
@@ -77,11 +109,14 @@ func (c *restClient) doRequest(ctx context.Context, method, path string, body in
 		req.Header.Set("x-api-key", c.apiKey)
 	}
 
+	sent := time.Now()
 	resp, err := c.httpClient.Do(req)
+	c.retrier.ObserveLatency(category, time.Since(sent))
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.rateLimiter.ObserveResponse(category, resp)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -89,23 +124,34 @@ func (c *restClient) doRequest(ctx context.Context, method, path string, body in
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		return nil, gateway.NewHTTPError(resp, respBody)
 	}
 
 	var baseResp wallexResponse
 	if err := json.Unmarshal(respBody, &baseResp); err == nil {
 		if !baseResp.Success {
-			return nil, fmt.Errorf("wallex API error: %s", baseResp.Message)
+			// Wallex reports no error codes, so its rejections stay
+			// uncategorised.
+			return nil, &gateway.VenueError{Venue: "wallex", Op: "API error", Message: baseResp.Message}
 		}
 	}
 
 	return respBody, nil
 }
 
-// placeOrder places a new order on Wallex.
+// placeOrder places a new order on Wallex. Wallex orders always rest GTC,
+// so an IOC request is followed by a cancel of any unmatched remainder.
 // POST https://api.wallex.ir/v1/account/orders
 // Body: {"symbol": "BTCUSDT", "side": "buy"|"sell", "type": "limit"|"market", "price": "...", "quantity": "...", "client_id": "..."}
 func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	if req.OrderType.IsStop() {
+		// Wallex has no stop orders.
+		return nil, fmt.Errorf("%w: wallex %s", gateway.ErrOrderTypeUnsupported, req.OrderType)
+	}
+	if req.PostOnly || req.TimeInForce == domain.TimeInForceFOK {
+		return nil, fmt.Errorf("%w: wallex only supports GTC and IOC", gateway.ErrTimeInForceUnsupported)
+	}
+
 	wallexSymbol := domain.MapSymbol(req.Symbol, domain.WallexSymbolMap)
 
 	side := "buy"
@@ -133,7 +179,11 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 		body["client_id"] = req.IdempotencyKey
 	}
 
-	respData, err := c.doRequest(ctx, "POST", "/v1/account/orders", body, domain.EndpointOrderPlace, true)
+	send := c.doRequest
+	if !gateway.Replayable(req) {
+		send = c.doRequestOnce
+	}
+	respData, err := send(ctx, "POST", "/v1/account/orders", body, domain.EndpointOrderPlace, true)
 	if err != nil {
 		return nil, err
 	}
@@ -158,10 +208,24 @@ func (c *restClient) placeOrder(ctx context.Context, req domain.OrderRequest) (*
 		return nil, fmt.Errorf("parse order response: %w", err)
 	}
 
+	venueID := result.Result.ClientOrderID
+	status := domain.OrderStatusAcknowledged
+	if !result.Result.Active && result.Result.Status == "FILLED" {
+		status = domain.OrderStatusFilled
+	}
+
+	if req.TimeInForce == domain.TimeInForceIOC && result.Result.Active && req.OrderType == domain.OrderTypeLimit {
+		if _, err := c.cancelOrder(ctx, venueID); err != nil {
+			c.logger.Error("failed to cancel IOC remainder", "order_id", venueID, "error", err)
+		} else {
+			status = domain.OrderStatusCancelled
+		}
+	}
+
 	return &domain.OrderAck{
 		InternalID: req.InternalID,
-		VenueID:    result.Result.ClientOrderID,
-		Status:     domain.OrderStatusAcknowledged,
+		VenueID:    venueID,
+		Status:     status,
 		Timestamp:  time.Now(),
 	}, nil
 }
@@ -185,6 +249,55 @@ func (c *restClient) cancelOrder(ctx context.Context, orderID string) (*domain.C
 	}, nil
 }
 
+// getOrder looks up one order by the client order ID Wallex keys orders by.
+// GET https://api.wallex.ir/v1/account/orders/{clientOrderId}
+func (c *restClient) getOrder(ctx context.Context, orderID string) (*domain.OrderUpdate, error) {
+	respData, err := c.doRequest(ctx, "GET", "/v1/account/orders/"+url.PathEscape(orderID), nil, domain.EndpointPrivateData, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Result struct {
+			ClientOrderID string `json:"clientOrderId"`
+			OrigQty       string `json:"origQty"`
+			ExecutedQty   string `json:"executedQty"`
+			ExecutedPrice string `json:"executedPrice"`
+			Status        string `json:"status"`
+			Active        bool   `json:"active"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse order: %w", err)
+	}
+	o := result.Result
+
+	update := &domain.OrderUpdate{
+		Venue:         "wallex",
+		VenueID:       orderID,
+		ClientOrderID: o.ClientOrderID,
+		Timestamp:     time.Now(),
+	}
+	update.FilledSize, _ = domain.ParseDecimal(o.ExecutedQty)
+	update.AvgFillPrice, _ = domain.ParseDecimal(o.ExecutedPrice)
+
+	switch {
+	case strings.EqualFold(o.Status, "FILLED"):
+		update.Status = domain.OrderStatusFilled
+	case strings.EqualFold(o.Status, "CANCELED"), strings.EqualFold(o.Status, "CANCELLED"), strings.EqualFold(o.Status, "EXPIRED"):
+		update.Status = domain.OrderStatusCancelled
+	case strings.EqualFold(o.Status, "REJECTED"):
+		update.Status = domain.OrderStatusRejected
+	case o.Active && update.FilledSize.IsPositive():
+		update.Status = domain.OrderStatusPartialFill
+	case o.Active:
+		update.Status = domain.OrderStatusAcknowledged
+	default:
+		return nil, fmt.Errorf("unknown wallex order status %q", o.Status)
+	}
+	return update, nil
+}
+
 // getBalances fetches all account balances from Wallex.
 // GET https://api.wallex.ir/v1/account/balances
 // Returns: {"result": {"balances": {"BTC": {"asset": "BTC", "value": "...", "locked": "..."}, ...}}}
@@ -397,6 +510,49 @@ func (c *restClient) getOrderBook(ctx context.Context, symbol string) (*domain.O
 	return book, nil
 }
 
+// getInstruments reads the trading rules of every mapped market. Wallex
+// gives tick and step sizes as a number of decimal places.
+// GET https://api.wallex.ir/v1/markets
+func (c *restClient) getInstruments(ctx context.Context) ([]domain.Instrument, error) {
+	respData, err := c.doRequest(ctx, "GET", "/v1/markets", nil, domain.EndpointPublicData, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Result struct {
+			Symbols map[string]struct {
+				MinQty      decimal.Decimal `json:"minQty"`
+				MinNotional decimal.Decimal `json:"minNotional"`
+				StepSize    int32           `json:"stepSize"`
+				TickSize    int32           `json:"tickSize"`
+			} `json:"symbols"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(respData, &result); err != nil {
+		return nil, fmt.Errorf("parse markets: %w", err)
+	}
+
+	var out []domain.Instrument
+	for symbol, market := range domain.WallexSymbolMap {
+		m, ok := result.Result.Symbols[market]
+		if !ok {
+			continue
+		}
+		out = append(out, domain.Instrument{
+			Venue:              "wallex",
+			Symbol:             symbol,
+			InstrumentType:     domain.InstrumentSpot,
+			TickSize:           decimal.New(1, -m.TickSize),
+			StepSize:           decimal.New(1, -m.StepSize),
+			MinSize:            m.MinQty,
+			MinNotional:        m.MinNotional,
+			ContractMultiplier: decimal.NewFromInt(1),
+		})
+	}
+	return out, nil
+}
+
 // getRecentTrades fetches recent trades from Wallex REST API.
 // GET https://api.wallex.ir/v1/trades?symbol=BTCUSDT
 func (c *restClient) getRecentTrades(ctx context.Context, symbol string) ([]domain.Trade, error) {
@@ -451,3 +607,9 @@ func (c *restClient) getRecentTrades(ctx context.Context, symbol string) ([]doma
 
 	return trades, nil
 }
+
+// ping checks that the public REST API answers. Wallex has no ping
+// endpoint, so it fetches the USDT/TMN order book.
+func (c *restClient) ping(ctx context.Context) error {
+	return gateway.ProbeREST(ctx, c.httpClient, c.baseURL+"/v1/depth?symbol=USDTTMN")
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
 )
 
 type wsClient struct {
@@ -27,6 +28,10 @@ type wsClient struct {
 	failureCount  int
 
 	subscriptions []wsSubscription
+	subMu         sync.Mutex
+	onReconnect   func() // called after each successful reconnect, if set
+	state         gateway.WSState
+	egress        gateway.Egress
 
 	orderBookChans map[string]chan domain.OrderBookDelta
 	tradeChans     map[string]chan domain.Trade
@@ -56,9 +61,7 @@ func (ws *wsClient) connect(ctx context.Context) error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
+	dialer := ws.egress.WSDialer()
 
 	conn, _, err := dialer.DialContext(ctx, ws.url, nil)
 	if err != nil {
@@ -66,6 +69,7 @@ func (ws *wsClient) connect(ctx context.Context) error {
 	}
 
 	ws.conn = conn
+	ws.state.SetConnected(true)
 	ws.failureCount = 0
 	ws.logger.Info("wallex websocket connected", "url", ws.url)
 	return nil
@@ -89,20 +93,35 @@ func (ws *wsClient) reconnect(ctx context.Context) error {
 			}
 			continue
 		}
-		for _, sub := range ws.subscriptions {
+		for _, sub := range ws.activeSubscriptions() {
 			if err := ws.sendSubscribe(sub.symbol, sub.channel); err != nil {
 				ws.logger.Warn("failed to resubscribe after reconnect",
 					"symbol", sub.symbol, "channel", sub.channel, "error", err)
 			}
 		}
+		if ws.onReconnect != nil {
+			ws.onReconnect()
+		}
 		return nil
 	}
 	ws.failureCount++
 	return fmt.Errorf("failed to reconnect after %d attempts", ws.maxFailures)
 }
 
+// activeSubscriptions returns a copy of the subscriptions to restore after a
+// reconnect.
+func (ws *wsClient) activeSubscriptions() []wsSubscription {
+	ws.subMu.Lock()
+	defer ws.subMu.Unlock()
+	subs := make([]wsSubscription, len(ws.subscriptions))
+	copy(subs, ws.subscriptions)
+	return subs
+}
+
 func (ws *wsClient) subscribe(symbol, channel string) error {
+	ws.subMu.Lock()
 	ws.subscriptions = append(ws.subscriptions, wsSubscription{symbol: symbol, channel: channel})
+	ws.subMu.Unlock()
 	return ws.sendSubscribe(symbol, channel)
 }
 
@@ -145,6 +164,7 @@ func (ws *wsClient) readPump(ctx context.Context) {
 
 		_, message, err := conn.ReadMessage()
 		if err != nil {
+			ws.state.SetConnected(false)
 			ws.logger.Error("wallex websocket read error", "error", err)
 			if reconnErr := ws.reconnect(ctx); reconnErr != nil {
 				ws.logger.Error("wallex reconnection failed permanently", "error", reconnErr)
@@ -153,6 +173,7 @@ func (ws *wsClient) readPump(ctx context.Context) {
 			continue
 		}
 
+		ws.state.Touch()
 		ws.handleMessage(message)
 	}
 }
@@ -338,6 +359,7 @@ func (ws *wsClient) subscribeFunding(symbol string) <-chan domain.FundingRate {
 func (ws *wsClient) close() error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
+	ws.state.SetConnected(false)
 	if ws.conn != nil {
 		return ws.conn.Close()
 	}
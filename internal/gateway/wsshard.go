@@ -0,0 +1,38 @@
+package gateway
+
+import "sync"
+
+// WSSharder is implemented by gateways that can spread their market data
+// subscriptions over several WebSocket connections, for venues where one
+// connection falls behind once it carries many books. Set it before Connect.
+type WSSharder interface {
+	SetWSConnections(n int)
+}
+
+// ShardAssigner spreads symbols over n connections round-robin in the order
+// they are first seen, so every stream of a symbol shares a connection and
+// the connections carry about as many symbols each. It is safe for
+// concurrent use.
+type ShardAssigner struct {
+	mu       sync.Mutex
+	n        int
+	assigned map[string]int
+}
+
+// NewShardAssigner returns an assigner over n connections; n below one
+// counts as one.
+func NewShardAssigner(n int) *ShardAssigner {
+	return &ShardAssigner{n: max(n, 1), assigned: make(map[string]int)}
+}
+
+// Shard returns the index of the connection that carries symbol.
+func (a *ShardAssigner) Shard(symbol string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	i, ok := a.assigned[symbol]
+	if !ok {
+		i = len(a.assigned) % a.n
+		a.assigned[symbol] = i
+	}
+	return i
+}
@@ -0,0 +1,19 @@
+package gateway
+
+import "testing"
+
+func TestShardAssigner(t *testing.T) {
+	a := NewShardAssigner(3)
+	got := []int{a.Shard("BTC"), a.Shard("ETH"), a.Shard("SOL"), a.Shard("XRP"), a.Shard("ETH")}
+	want := []int{0, 1, 2, 0, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected shards %v, got %v", want, got)
+			break
+		}
+	}
+
+	if s := NewShardAssigner(0).Shard("BTC"); s != 0 {
+		t.Errorf("expected a single connection for n=0, got shard %d", s)
+	}
+}
@@ -0,0 +1,115 @@
+package marketdata
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// analyticsTradeScan is how many recent trades Analytics reads to find the
+// ones inside its window: the whole trade buffer.
+const analyticsTradeScan = 1000
+
+// MicroStats is a short-horizon read of one book's microstructure.
+type MicroStats struct {
+	// Imbalance is bid size less ask size over their sum across the top
+	// levels: 1 when only bids rest there, −1 when only asks do.
+	Imbalance float64
+	// Microprice is the touch weighted by the size on the other side, so it
+	// sits nearer the side that is about to be taken out.
+	Microprice decimal.Decimal
+	// Pressure is taker buy volume less taker sell volume over their sum
+	// within the window, from −1 to 1. It is 0 when nothing traded.
+	Pressure float64
+	// Trades is how many trades Pressure is taken from.
+	Trades int
+}
+
+// Analytics derives microstructure statistics from the books and trades in
+// a market data view. It keeps no state of its own; every query reads the
+// view as it is.
+type Analytics struct {
+	md     View
+	depth  int
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewAnalytics returns Analytics measuring imbalance over depth levels a
+// side and trade pressure over window.
+func NewAnalytics(md View, depth int, window time.Duration) *Analytics {
+	return &Analytics{md: md, depth: depth, window: window, now: time.Now}
+}
+
+// Stats returns the statistics of venue's symbol, or false if there is no
+// book with both sides.
+func (a *Analytics) Stats(venue, symbol string) (MicroStats, bool) {
+	book, ok := a.md.GetBook(venue, symbol)
+	if !ok {
+		return MicroStats{}, false
+	}
+	microprice, ok := Microprice(book)
+	if !ok {
+		return MicroStats{}, false
+	}
+	stats := MicroStats{
+		Imbalance:  BookImbalance(book, a.depth),
+		Microprice: microprice,
+	}
+
+	cutoff := a.now().Add(-a.window)
+	var buys, sells decimal.Decimal
+	for _, t := range a.md.GetRecentTrades(venue, symbol, analyticsTradeScan) {
+		if t.Timestamp.Before(cutoff) {
+			continue
+		}
+		stats.Trades++
+		if t.Side == domain.SideBuy {
+			buys = buys.Add(t.Size)
+		} else {
+			sells = sells.Add(t.Size)
+		}
+	}
+	if total := buys.Add(sells); total.IsPositive() {
+		stats.Pressure = buys.Sub(sells).Div(total).InexactFloat64()
+	}
+	return stats, true
+}
+
+// BookImbalance returns bid size less ask size over their sum across the
+// top depth levels of each side, or 0 for an empty book.
+func BookImbalance(book *domain.OrderBookSnapshot, depth int) float64 {
+	sum := func(levels []domain.PriceLevel) decimal.Decimal {
+		if len(levels) > depth {
+			levels = levels[:depth]
+		}
+		total := decimal.Zero
+		for _, l := range levels {
+			total = total.Add(l.Size)
+		}
+		return total
+	}
+	bids, asks := sum(book.Bids), sum(book.Asks)
+	total := bids.Add(asks)
+	if !total.IsPositive() {
+		return 0
+	}
+	return bids.Sub(asks).Div(total).InexactFloat64()
+}
+
+// Microprice returns (bid × ask size + ask × bid size) / (bid size + ask
+// size) at the touch, or false if either side is empty.
+func Microprice(book *domain.OrderBookSnapshot) (decimal.Decimal, bool) {
+	bid, hasBid := book.BestBid()
+	ask, hasAsk := book.BestAsk()
+	if !hasBid || !hasAsk {
+		return decimal.Zero, false
+	}
+	total := bid.Size.Add(ask.Size)
+	if !total.IsPositive() {
+		return book.MidPrice()
+	}
+	return bid.Price.Mul(ask.Size).Add(ask.Price.Mul(bid.Size)).Div(total), true
+}
@@ -0,0 +1,63 @@
+package marketdata
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestAnalyticsStats(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	svc := NewService(eventbus.New(10, logger), 500*time.Millisecond, 2*time.Second, logger)
+	analytics := NewAnalytics(svc.View(), 2, 10*time.Second)
+	now := time.Now()
+	analytics.now = func() time.Time { return now }
+
+	if _, ok := analytics.Stats("nobitex", "BTC/USDT"); ok {
+		t.Fatal("expected no stats without a book")
+	}
+
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "nobitex",
+		Symbol: "BTC/USDT",
+		Bids: []domain.PriceLevel{
+			{Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(3)},
+			{Price: decimal.NewFromInt(99), Size: decimal.NewFromInt(3)},
+			{Price: decimal.NewFromInt(98), Size: decimal.NewFromInt(50)},
+		},
+		Asks: []domain.PriceLevel{
+			{Price: decimal.NewFromInt(102), Size: decimal.NewFromInt(1)},
+			{Price: decimal.NewFromInt(103), Size: decimal.NewFromInt(1)},
+		},
+	})
+	trade := func(side domain.Side, size int64, age time.Duration) {
+		svc.RecordTrade(domain.Trade{Venue: "nobitex", Symbol: "BTC/USDT", Side: side,
+			Price: decimal.NewFromInt(101), Size: decimal.NewFromInt(size), Timestamp: now.Add(-age)})
+	}
+	trade(domain.SideSell, 100, time.Minute) // outside the window
+	trade(domain.SideBuy, 3, time.Second)
+	trade(domain.SideSell, 1, time.Second)
+
+	stats, ok := analytics.Stats("nobitex", "BTC/USDT")
+	if !ok {
+		t.Fatal("expected stats")
+	}
+	// Two levels a side: 6 bid against 2 ask.
+	if math.Abs(stats.Imbalance-0.5) > 1e-9 {
+		t.Errorf("expected imbalance 0.5, got %v", stats.Imbalance)
+	}
+	// (100×1 + 102×3) / 4: the thin ask pulls the microprice up.
+	if !stats.Microprice.Equal(decimal.RequireFromString("101.5")) {
+		t.Errorf("expected microprice 101.5, got %s", stats.Microprice)
+	}
+	if stats.Trades != 2 || math.Abs(stats.Pressure-0.5) > 1e-9 {
+		t.Errorf("expected pressure 0.5 over 2 trades, got %v over %d", stats.Pressure, stats.Trades)
+	}
+}
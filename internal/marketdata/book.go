@@ -0,0 +1,135 @@
+package marketdata
+
+import (
+	"sort"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// bookCapacity is the number of levels a side is allocated for when a book
+// is first built from deltas: the 20 to 50 levels venues stream a side, with
+// room to spare, so the book does not grow while it fills.
+const bookCapacity = 64
+
+type depthKey struct {
+	venue  string
+	symbol string
+}
+
+// SetMaxDepth keeps at most levels levels a side of venue's books for
+// symbol, or of all its books if symbol is empty; a symbol's setting wins
+// over its venue's. Deeper levels are dropped from snapshots and deltas as
+// they arrive, so a venue streaming hundreds of levels costs no more memory
+// or sorting than the top ones strategies read. Once levels near the touch
+// are cancelled, the book can miss levels deeper down until they are
+// updated or the next snapshot arrives. 0 keeps every level. Call before
+// books arrive.
+func (s *Service) SetMaxDepth(venue, symbol string, levels int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxDepth[depthKey{venue, symbol}] = levels
+}
+
+// depthFor returns the feed's maximum depth, 0 if unlimited. The caller
+// holds s.mu.
+func (s *Service) depthFor(venue, symbol string) int {
+	if n, ok := s.maxDepth[depthKey{venue, symbol}]; ok {
+		return n
+	}
+	return s.maxDepth[depthKey{venue, ""}]
+}
+
+// applyDelta updates book's levels, sequence and venue time from delta,
+// keeping at most depth levels a side (0 for all). Both sides of book must
+// be sorted, bids descending and asks ascending; they stay so. A snapshot
+// delta clears the book first.
+func applyDelta(book *domain.OrderBookSnapshot, delta domain.OrderBookDelta, depth int) {
+	if delta.Snapshot {
+		book.Bids, book.Asks = book.Bids[:0], book.Asks[:0]
+	}
+	book.Bids = applyLevelDeltas(book.Bids, delta.Bids, true, depth)
+	book.Asks = applyLevelDeltas(book.Asks, delta.Asks, false, depth)
+	book.Sequence = delta.Sequence
+	book.VenueTimestamp = delta.VenueTimestamp
+}
+
+// applyLevelDeltas sets each delta's size at its price in the sorted levels,
+// removing the level at a zero size. Each delta is placed by binary search
+// and shifts the levels behind it by one, so a delta near the touch of a
+// deep book costs a short copy rather than a scan and a sort. With depth
+// set, a new level beyond it is ignored and one within it pushes out the
+// deepest level of a full side.
+func applyLevelDeltas(levels []domain.PriceLevel, deltas []domain.PriceLevel, descending bool, depth int) []domain.PriceLevel {
+	for _, d := range deltas {
+		i := searchLevel(levels, d, descending)
+		found := i < len(levels) && levels[i].Price.Equal(d.Price)
+		switch {
+		case found && d.Size.IsZero():
+			levels = append(levels[:i], levels[i+1:]...)
+		case found:
+			levels[i].Size = d.Size
+		case !d.Size.IsZero() && (depth <= 0 || i < depth):
+			if depth > 0 && len(levels) >= depth {
+				levels = levels[:depth-1]
+			}
+			levels = append(levels, domain.PriceLevel{})
+			copy(levels[i+1:], levels[i:])
+			levels[i] = d
+		}
+	}
+	return levels
+}
+
+// searchLevel returns the index of the first level at or behind d's price.
+func searchLevel(levels []domain.PriceLevel, d domain.PriceLevel, descending bool) int {
+	if descending {
+		return sort.Search(len(levels), func(i int) bool { return levels[i].Price.LessThanOrEqual(d.Price) })
+	}
+	return sort.Search(len(levels), func(i int) bool { return levels[i].Price.GreaterThanOrEqual(d.Price) })
+}
+
+// sortBook puts both sides of book in book order, so deltas can be applied
+// to it.
+func sortBook(book *domain.OrderBookSnapshot) {
+	sortLevels(book.Bids, true)
+	sortLevels(book.Asks, false)
+}
+
+// truncateBook keeps the best depth levels a side of a sorted book, copied
+// out so that the deeper levels' memory can be freed. depth 0 keeps all.
+func truncateBook(book *domain.OrderBookSnapshot, depth int) {
+	if depth <= 0 {
+		return
+	}
+	book.Bids = truncateLevels(book.Bids, depth)
+	book.Asks = truncateLevels(book.Asks, depth)
+}
+
+func truncateLevels(levels []domain.PriceLevel, depth int) []domain.PriceLevel {
+	if len(levels) <= depth {
+		return levels
+	}
+	return append(make([]domain.PriceLevel, 0, depth), levels[:depth]...)
+}
+
+// sortLevels puts levels in book order. It is an insertion sort: venue
+// snapshots arrive sorted, which it checks in one pass without moving
+// anything.
+func sortLevels(levels []domain.PriceLevel, descending bool) {
+	n := len(levels)
+	for i := 1; i < n; i++ {
+		for j := i; j > 0; j-- {
+			swap := false
+			if descending {
+				swap = levels[j].Price.GreaterThan(levels[j-1].Price)
+			} else {
+				swap = levels[j].Price.LessThan(levels[j-1].Price)
+			}
+			if swap {
+				levels[j], levels[j-1] = levels[j-1], levels[j]
+			} else {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package marketdata
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestApplyLevelDeltasKeepsBookOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, descending := range []bool{true, false} {
+		var levels []domain.PriceLevel
+		want := make(map[int64]int64) // price -> size
+		for i := 0; i < 5000; i++ {
+			price, size := 1000+rng.Int63n(100), rng.Int63n(4)
+			levels = applyLevelDeltas(levels, []domain.PriceLevel{level(price, size)}, descending, 0)
+			if size == 0 {
+				delete(want, price)
+			} else {
+				want[price] = size
+			}
+		}
+
+		if len(levels) != len(want) {
+			t.Fatalf("descending=%v: expected %d levels, got %d", descending, len(want), len(levels))
+		}
+		for i, l := range levels {
+			if i > 0 {
+				prev := levels[i-1].Price
+				if descending && !prev.GreaterThan(l.Price) || !descending && !prev.LessThan(l.Price) {
+					t.Fatalf("descending=%v: level %d at %s out of order after %s", descending, i, l.Price, prev)
+				}
+			}
+			if size := want[l.Price.IntPart()]; !l.Size.Equal(decimal.NewFromInt(size)) {
+				t.Errorf("descending=%v: expected size %d at %s, got %s", descending, size, l.Price, l.Size)
+			}
+		}
+	}
+}
+
+func TestSortBookBeforeDeltas(t *testing.T) {
+	book := &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{level(98, 1), level(100, 1), level(99, 1)},
+		Asks: []domain.PriceLevel{level(103, 1), level(101, 1), level(102, 1)},
+	}
+	sortBook(book)
+	applyDelta(book, domain.OrderBookDelta{Bids: []domain.PriceLevel{level(99, 0)}, Asks: []domain.PriceLevel{level(101, 5)}}, 0)
+
+	if len(book.Bids) != 2 || !book.Bids[0].Price.Equal(decimal.NewFromInt(100)) || !book.Bids[1].Price.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("expected bids 100, 98, got %v", book.Bids)
+	}
+	if ask, _ := book.BestAsk(); !ask.Price.Equal(decimal.NewFromInt(101)) || !ask.Size.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected 5 at 101 best ask, got %v", ask)
+	}
+}
+
+func TestSetMaxDepthTruncatesBooks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	svc := NewService(eventbus.New(10, logger), 500*time.Millisecond, 2*time.Second, logger)
+	svc.SetMaxDepth("kcex", "", 3)
+	svc.SetMaxDepth("kcex", "ETH-USDT", 0)
+
+	deep := func(symbol string) domain.OrderBookSnapshot {
+		snap := domain.OrderBookSnapshot{Venue: "kcex", Symbol: symbol}
+		for i := int64(0); i < 10; i++ {
+			snap.Bids = append(snap.Bids, level(100-i, 1))
+			snap.Asks = append(snap.Asks, level(101+i, 1))
+		}
+		return snap
+	}
+	svc.UpdateOrderBook(deep("BTC-USDT"))
+	svc.UpdateOrderBook(deep("ETH-USDT"))
+
+	if book, _ := svc.GetOrderBook("kcex", "ETH-USDT"); len(book.Bids) != 10 {
+		t.Errorf("expected the symbol override to keep all 10 levels, got %d", len(book.Bids))
+	}
+	book, _ := svc.GetOrderBook("kcex", "BTC-USDT")
+	if len(book.Bids) != 3 || len(book.Asks) != 3 || !book.Bids[2].Price.Equal(decimal.NewFromInt(98)) {
+		t.Fatalf("expected the top 3 levels a side, got bids %v asks %v", book.Bids, book.Asks)
+	}
+
+	// A level beyond the depth is ignored; one inside it pushes out the
+	// deepest.
+	svc.ApplyDelta(domain.OrderBookDelta{
+		Venue:  "kcex",
+		Symbol: "BTC-USDT",
+		Bids:   []domain.PriceLevel{level(90, 5)},
+		Asks:   []domain.PriceLevel{level(100, 0), level(102, 0), level(101, 2), level(109, 1)},
+	})
+	book, _ = svc.GetOrderBook("kcex", "BTC-USDT")
+	if len(book.Bids) != 3 || !book.Bids[2].Price.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("expected bids unchanged, got %v", book.Bids)
+	}
+	if len(book.Asks) != 3 || !book.Asks[1].Price.Equal(decimal.NewFromInt(103)) || !book.Asks[2].Price.Equal(decimal.NewFromInt(109)) {
+		t.Errorf("expected asks 101, 103, 109, got %v", book.Asks)
+	}
+	svc.ApplyDelta(domain.OrderBookDelta{
+		Venue:  "kcex",
+		Symbol: "BTC-USDT",
+		Asks:   []domain.PriceLevel{level(102, 1)},
+	})
+	book, _ = svc.GetOrderBook("kcex", "BTC-USDT")
+	if len(book.Asks) != 3 || !book.Asks[1].Price.Equal(decimal.NewFromInt(102)) || !book.Asks[2].Price.Equal(decimal.NewFromInt(103)) {
+		t.Errorf("expected asks 101, 102, 103, got %v", book.Asks)
+	}
+}
+
+// BenchmarkApplyDelta applies one-level deltas to a book of the given depth,
+// clustered near the touch the way venue streams are: mostly size changes,
+// with levels cancelled and re-added.
+func BenchmarkApplyDelta(b *testing.B) {
+	for _, depth := range []int{20, 50, 200} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			book := &domain.OrderBookSnapshot{
+				Bids: make([]domain.PriceLevel, 0, bookCapacity),
+				Asks: make([]domain.PriceLevel, 0, bookCapacity),
+			}
+			for i := 0; i < depth; i++ {
+				book.Bids = append(book.Bids, level(int64(10000-i), 1))
+				book.Asks = append(book.Asks, level(int64(10001+i), 1))
+			}
+			rng := rand.New(rand.NewSource(1))
+			deltas := make([]domain.OrderBookDelta, 1024)
+			for i := range deltas {
+				offset := int64(rng.ExpFloat64() * float64(depth) / 4)
+				if offset >= int64(depth) {
+					offset = int64(depth) - 1
+				}
+				size := rng.Int63n(5)
+				if i%2 == 0 {
+					deltas[i].Bids = []domain.PriceLevel{level(10000-offset, size)}
+				} else {
+					deltas[i].Asks = []domain.PriceLevel{level(10001+offset, size)}
+				}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				applyDelta(book, deltas[i%len(deltas)], 0)
+			}
+		})
+	}
+}
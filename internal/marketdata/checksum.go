@@ -0,0 +1,84 @@
+package marketdata
+
+import (
+	"hash/crc32"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// checksumDepth is how many levels per side venue checksums cover. KCEX's
+// matches the 20 levels its REST snapshot returns.
+const checksumDepth = 20
+
+// checksumDepths overrides checksumDepth for venues whose checksums cover a
+// different number of levels.
+var checksumDepths = map[string]int{
+	"okx": 25,
+}
+
+func checksumLevels(venue string) int {
+	if n, ok := checksumDepths[venue]; ok {
+		return n
+	}
+	return checksumDepth
+}
+
+// SetChecksumValidation checks books against the checksum venues send with
+// their deltas once every `every` deltas per feed, and resyncs a book that
+// disagrees. onMismatch, if set, is called for each mismatch. Only venues
+// with a snapshot source are checked. Call before deltas arrive.
+func (s *Service) SetChecksumValidation(every int, onMismatch func(venue, symbol string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checksumEvery = every
+	s.onChecksumMismatch = onMismatch
+}
+
+// checksumDue counts delta towards the feed's next validation and reports
+// whether it is the one to validate, one in every. The caller holds sh.mu.
+func checksumDue(sh *shard, key string, delta domain.OrderBookDelta, every int) bool {
+	if every <= 0 || delta.Checksum == 0 {
+		return false
+	}
+	sh.sinceChecksum[key]++
+	if sh.sinceChecksum[key] < every {
+		return false
+	}
+	sh.sinceChecksum[key] = 0
+	return true
+}
+
+// checksumCovered reports whether book holds every level the venue's
+// checksum covers. A book kept to depth may hold fewer after cancels near
+// the touch, while the venue still has the levels that moved up; such a
+// book is not checked until it fills again.
+func checksumCovered(book *domain.OrderBookSnapshot, depth, levels int) bool {
+	return depth <= 0 || len(book.Bids) >= levels && len(book.Asks) >= levels
+}
+
+// bookChecksum is the CRC32 (IEEE) of the top levels a side,
+// interleaved best bid, best ask, second bid and so on, each written as
+// price:size and joined with ':'. Numbers keep the precision the venue sent,
+// trailing zeros included, so the string matches the one the venue hashed.
+func bookChecksum(book *domain.OrderBookSnapshot, levels int) uint32 {
+	var parts []string
+	for i := 0; i < levels; i++ {
+		if i < len(book.Bids) {
+			parts = append(parts, venueString(book.Bids[i].Price), venueString(book.Bids[i].Size))
+		}
+		if i < len(book.Asks) {
+			parts = append(parts, venueString(book.Asks[i].Price), venueString(book.Asks[i].Size))
+		}
+	}
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))
+}
+
+func venueString(d decimal.Decimal) string {
+	if exp := d.Exponent(); exp < 0 {
+		return d.StringFixed(-exp)
+	}
+	return d.String()
+}
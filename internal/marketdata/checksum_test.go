@@ -0,0 +1,112 @@
+package marketdata
+
+import (
+	"context"
+	"hash/crc32"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestBookChecksumKeepsVenuePrecision(t *testing.T) {
+	book := &domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{
+			{Price: decimal.RequireFromString("100.10"), Size: decimal.RequireFromString("1.500")},
+			{Price: decimal.RequireFromString("100.00"), Size: decimal.RequireFromString("2")},
+		},
+		Asks: []domain.PriceLevel{
+			{Price: decimal.RequireFromString("100.20"), Size: decimal.RequireFromString("0.25")},
+		},
+	}
+	want := crc32.ChecksumIEEE([]byte("100.10:1.500:100.20:0.25:100.00:2"))
+	if got := bookChecksum(book, checksumDepth); got != want {
+		t.Errorf("expected checksum %d, got %d", want, got)
+	}
+}
+
+func TestChecksumMismatchResyncsAndAlerts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+
+	var snapshotSeq atomic.Uint64
+	snapshotSeq.Store(10)
+	svc.SetSnapshotSource("kcex", func(_ context.Context, _ string) (*domain.OrderBookSnapshot, error) {
+		return &domain.OrderBookSnapshot{
+			Bids:     []domain.PriceLevel{level(100, 1)},
+			Asks:     []domain.PriceLevel{level(101, 1)},
+			Sequence: snapshotSeq.Load(),
+		}, nil
+	})
+	var alerts []string
+	svc.SetChecksumValidation(2, func(venue, symbol string) { alerts = append(alerts, venue+":"+symbol) })
+
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 10, Sequence: 10})
+	<-books
+
+	good := domain.OrderBookSnapshot{
+		Bids: []domain.PriceLevel{level(100, 2)},
+		Asks: []domain.PriceLevel{level(101, 1)},
+	}
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 11, Sequence: 11,
+		Bids: []domain.PriceLevel{level(100, 2)}, Checksum: 1})
+	if snap := <-books; snap.Sequence != 11 {
+		t.Fatalf("expected the first delta applied unchecked, got sequence %d", snap.Sequence)
+	}
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 12, Sequence: 12,
+		Checksum: bookChecksum(&good, checksumDepth)})
+	if snap := <-books; snap.Sequence != 12 || len(alerts) != 0 {
+		t.Fatalf("expected a matching checksum to pass, got sequence %d and alerts %v", snap.Sequence, alerts)
+	}
+
+	// A wrong checksum is only noticed on the next validation, every second delta.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 13, Sequence: 13, Checksum: 7})
+	<-books
+	snapshotSeq.Store(13)
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 14, Sequence: 14, Checksum: 7})
+	if len(alerts) != 1 || alerts[0] != "kcex:BTC-USDT" {
+		t.Fatalf("expected one mismatch alert, got %v", alerts)
+	}
+	snap := <-books
+	if snap.Sequence != 14 {
+		t.Errorf("expected the book rebuilt and replayed to 14, got %d", snap.Sequence)
+	}
+	if bid, _ := snap.BestBid(); !bid.Size.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the snapshot's bid size 1, got %s", bid.Size)
+	}
+}
+
+func TestOKXChecksumCoversTwentyFiveLevels(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+	svc.SetSnapshotSource("okx", func(_ context.Context, _ string) (*domain.OrderBookSnapshot, error) {
+		t.Error("unexpected snapshot fetch")
+		return nil, context.Canceled
+	})
+	var alerts []string
+	svc.SetChecksumValidation(1, func(venue, symbol string) { alerts = append(alerts, venue+":"+symbol) })
+
+	book := domain.OrderBookSnapshot{}
+	for i := int64(0); i < 30; i++ {
+		book.Bids = append(book.Bids, level(1000-i, i+1))
+		book.Asks = append(book.Asks, level(1001+i, i+1))
+	}
+	if bookChecksum(&book, 25) == bookChecksum(&book, checksumDepth) {
+		t.Fatal("expected the 25th level to change the checksum")
+	}
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "okx", Symbol: "BTC-USDT", Snapshot: true, Sequence: 10,
+		Bids: book.Bids, Asks: book.Asks, Checksum: bookChecksum(&book, 25)})
+	if snap := <-books; snap.Sequence != 10 || len(alerts) != 0 {
+		t.Errorf("expected OKX's 25-level checksum to pass, got sequence %d and alerts %v", snap.Sequence, alerts)
+	}
+}
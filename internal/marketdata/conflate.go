@@ -0,0 +1,74 @@
+package marketdata
+
+import (
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// conflation is a feed's book event state while conflation is on.
+type conflation struct {
+	published time.Time // last book event
+	pending   bool      // a later book is held back until the interval ends
+}
+
+// SetConflation publishes at most one book event per feed every interval
+// to shed load: updates within it are held back, and the latest book is
+// published when it ends. Books are still stored as they arrive, so readers
+// of the service and View see every update. 0 publishes every update again.
+// It may be called while data flows.
+func (s *Service) SetConflation(interval time.Duration) {
+	if old := time.Duration(s.conflation.Swap(int64(interval))); (old > 0) != (interval > 0) {
+		s.logger.Warn("book event conflation changed", "interval", interval)
+	}
+}
+
+// publishBook publishes snap, the book just stored for key, unless
+// conflation holds it back. The caller does not hold sh.mu.
+func (s *Service) publishBook(sh *shard, key string, snap domain.OrderBookSnapshot) {
+	interval := time.Duration(s.conflation.Load())
+	if interval <= 0 {
+		s.bus.PublishOrderBook(snap)
+		return
+	}
+
+	now := time.Now()
+	sh.mu.Lock()
+	c, ok := sh.conflated[key]
+	if !ok {
+		c = &conflation{}
+		sh.conflated[key] = c
+	}
+	if wait := interval - now.Sub(c.published); wait > 0 {
+		if !c.pending {
+			c.pending = true
+			time.AfterFunc(wait, func() { s.flushConflated(sh, key) })
+		}
+		sh.mu.Unlock()
+		return
+	}
+	c.published = now
+	sh.mu.Unlock()
+
+	s.bus.PublishOrderBook(snap)
+}
+
+// flushConflated publishes the book held back for key at the end of its
+// interval, unless the feed has since stopped being publishable.
+func (s *Service) flushConflated(sh *shard, key string) {
+	sh.mu.Lock()
+	c := sh.conflated[key]
+	c.pending = false
+	c.published = time.Now()
+	book, ok := sh.books[key]
+	_, resyncing := sh.resyncing[key]
+	_, anomalous := sh.anomalous[key]
+	if !ok || resyncing || anomalous || sh.degraded[key] {
+		sh.mu.Unlock()
+		return
+	}
+	snap := *book
+	sh.mu.Unlock()
+
+	s.bus.PublishOrderBook(snap)
+}
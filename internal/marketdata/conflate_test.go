@@ -0,0 +1,58 @@
+package marketdata
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestConflationPublishesLatestBook(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(100, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, time.Second, 2*time.Second, logger)
+	svc.SetConflation(50 * time.Millisecond)
+
+	for i := int64(0); i < 10; i++ {
+		svc.UpdateOrderBook(domain.OrderBookSnapshot{
+			Venue:  "kcex",
+			Symbol: "BTC-USDT",
+			Bids:   []domain.PriceLevel{level(100+i, 1)},
+			Asks:   []domain.PriceLevel{level(200, 1)},
+		})
+	}
+	if book, _ := svc.GetOrderBook("kcex", "BTC-USDT"); !book.Bids[0].Price.Equal(decimal.NewFromInt(109)) {
+		t.Fatalf("expected every update stored, got bids %v", book.Bids)
+	}
+
+	first := <-books
+	if !first.Bids[0].Price.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected the first update published at once, got bids %v", first.Bids)
+	}
+	select {
+	case snap := <-books:
+		t.Fatalf("expected later updates held back, got bids %v", snap.Bids)
+	case <-time.After(20 * time.Millisecond):
+	}
+	select {
+	case snap := <-books:
+		if !snap.Bids[0].Price.Equal(decimal.NewFromInt(109)) {
+			t.Errorf("expected the latest book at the end of the interval, got bids %v", snap.Bids)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the held back book published")
+	}
+
+	svc.SetConflation(0)
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "kcex", Symbol: "BTC-USDT", Bids: []domain.PriceLevel{level(110, 1)}})
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "kcex", Symbol: "BTC-USDT", Bids: []domain.PriceLevel{level(111, 1)}})
+	if len(books) != 2 {
+		t.Errorf("expected every update published with conflation off, got %d", len(books))
+	}
+}
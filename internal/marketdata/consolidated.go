@@ -0,0 +1,123 @@
+package marketdata
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+// ConsolidatedBook merges the touches of each venue's book for a symbol into
+// one view: the best bid and offer per venue and across all of them, the
+// NBBO. Symbols are the internal ones the gateways publish books under, so
+// books for the same instrument line up whatever the venue calls it.
+// Each change to a venue's touch is published on the bus as a
+// domain.ConsolidatedQuote.
+type ConsolidatedBook struct {
+	mu     sync.RWMutex
+	quotes map[string]map[string]domain.VenueQuote // symbol -> venue -> touch
+
+	// blocked reports whether a feed is too stale to trade on; its venue
+	// is then left out of the NBBO. Nil leaves every venue in.
+	blocked func(venue, symbol string) bool
+
+	bus    *eventbus.EventBus
+	logger *slog.Logger
+}
+
+func NewConsolidatedBook(bus *eventbus.EventBus, blocked func(venue, symbol string) bool, logger *slog.Logger) *ConsolidatedBook {
+	return &ConsolidatedBook{
+		quotes:  make(map[string]map[string]domain.VenueQuote),
+		blocked: blocked,
+		bus:     bus,
+		logger:  logger,
+	}
+}
+
+// Run feeds Update from books until ctx is cancelled or books is closed.
+func (c *ConsolidatedBook) Run(ctx context.Context, books <-chan domain.OrderBookSnapshot) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case snap, ok := <-books:
+			if !ok {
+				return
+			}
+			c.Update(snap)
+		}
+	}
+}
+
+// Update records snap's touch and, when it differs from the venue's last
+// one, publishes the symbol's consolidated quote. Updates beneath the touch
+// are dropped.
+func (c *ConsolidatedBook) Update(snap domain.OrderBookSnapshot) {
+	quote := domain.VenueQuote{Venue: snap.Venue, Timestamp: snap.LocalTimestamp}
+	quote.Bid, _ = snap.BestBid()
+	quote.Ask, _ = snap.BestAsk()
+
+	c.mu.Lock()
+	venues, ok := c.quotes[snap.Symbol]
+	if !ok {
+		venues = make(map[string]domain.VenueQuote)
+		c.quotes[snap.Symbol] = venues
+	}
+	prev, seen := venues[snap.Venue]
+	venues[snap.Venue] = quote
+	if seen && sameLevel(prev.Bid, quote.Bid) && sameLevel(prev.Ask, quote.Ask) {
+		c.mu.Unlock()
+		return
+	}
+	consolidated := c.consolidate(snap.Symbol, venues)
+	c.mu.Unlock()
+
+	c.bus.PublishConsolidatedQuote(consolidated)
+}
+
+// Quote returns symbol's consolidated quote, or false if no venue has sent a
+// book for it.
+func (c *ConsolidatedBook) Quote(symbol string) (domain.ConsolidatedQuote, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	venues, ok := c.quotes[symbol]
+	if !ok {
+		return domain.ConsolidatedQuote{}, false
+	}
+	return c.consolidate(symbol, venues), true
+}
+
+// consolidate builds symbol's quote from venues. The caller holds c.mu.
+func (c *ConsolidatedBook) consolidate(symbol string, venues map[string]domain.VenueQuote) domain.ConsolidatedQuote {
+	q := domain.ConsolidatedQuote{Symbol: symbol, Venues: make([]domain.VenueQuote, 0, len(venues))}
+	for _, vq := range venues {
+		q.Venues = append(q.Venues, vq)
+	}
+	sort.Slice(q.Venues, func(i, j int) bool { return q.Venues[i].Venue < q.Venues[j].Venue })
+
+	for _, vq := range q.Venues {
+		if vq.Timestamp.After(q.Timestamp) {
+			q.Timestamp = vq.Timestamp
+		}
+		if c.blocked != nil && c.blocked(vq.Venue, symbol) {
+			continue
+		}
+		// Ties go to the venue quoting more size.
+		if vq.Bid.Price.IsPositive() && (q.BidVenue == "" || vq.Bid.Price.GreaterThan(q.BestBid.Price) ||
+			vq.Bid.Price.Equal(q.BestBid.Price) && vq.Bid.Size.GreaterThan(q.BestBid.Size)) {
+			q.BestBid, q.BidVenue = vq.Bid, vq.Venue
+		}
+		if vq.Ask.Price.IsPositive() && (q.AskVenue == "" || vq.Ask.Price.LessThan(q.BestAsk.Price) ||
+			vq.Ask.Price.Equal(q.BestAsk.Price) && vq.Ask.Size.GreaterThan(q.BestAsk.Size)) {
+			q.BestAsk, q.AskVenue = vq.Ask, vq.Venue
+		}
+	}
+	return q
+}
+
+func sameLevel(a, b domain.PriceLevel) bool {
+	return a.Price.Equal(b.Price) && a.Size.Equal(b.Size)
+}
@@ -0,0 +1,81 @@
+package marketdata
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func touch(venue string, bid, bidSize, ask, askSize int64) domain.OrderBookSnapshot {
+	snap := domain.OrderBookSnapshot{Venue: venue, Symbol: "BTC/USDT"}
+	if bid > 0 {
+		snap.Bids = []domain.PriceLevel{{Price: decimal.NewFromInt(bid), Size: decimal.NewFromInt(bidSize)}}
+	}
+	if ask > 0 {
+		snap.Asks = []domain.PriceLevel{{Price: decimal.NewFromInt(ask), Size: decimal.NewFromInt(askSize)}}
+	}
+	return snap
+}
+
+func TestConsolidatedBookNBBO(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	quotes := bus.SubscribeConsolidatedQuote()
+	blocked := map[string]bool{}
+	book := NewConsolidatedBook(bus, func(venue, _ string) bool { return blocked[venue] }, logger)
+
+	book.Update(touch("okx", 100, 1, 102, 1))
+	book.Update(touch("bybit", 101, 1, 103, 1))
+	book.Update(touch("binance", 99, 1, 102, 3))
+
+	q, ok := book.Quote("BTC/USDT")
+	if !ok {
+		t.Fatal("expected a quote for BTC/USDT")
+	}
+	if len(q.Venues) != 3 || q.Venues[0].Venue != "binance" || q.Venues[2].Venue != "okx" {
+		t.Errorf("expected three venues sorted by name, got %+v", q.Venues)
+	}
+	if q.BidVenue != "bybit" || !q.BestBid.Price.Equal(decimal.NewFromInt(101)) {
+		t.Errorf("expected the best bid 101 on bybit, got %s on %s", q.BestBid.Price, q.BidVenue)
+	}
+	// okx and binance both offer 102; binance shows more size.
+	if q.AskVenue != "binance" || !q.BestAsk.Price.Equal(decimal.NewFromInt(102)) {
+		t.Errorf("expected the best ask 102 on binance, got %s on %s", q.BestAsk.Price, q.AskVenue)
+	}
+	if q.Crossed() {
+		t.Error("expected the NBBO not crossed")
+	}
+	if n := len(quotes); n != 3 {
+		t.Errorf("expected a quote published per touch change, got %d", n)
+	}
+
+	// A change beneath the touch publishes nothing.
+	deeper := touch("okx", 100, 1, 102, 1)
+	deeper.Bids = append(deeper.Bids, domain.PriceLevel{Price: decimal.NewFromInt(98), Size: decimal.NewFromInt(4)})
+	book.Update(deeper)
+	if n := len(quotes); n != 3 {
+		t.Errorf("expected no quote for an update beneath the touch, got %d", n)
+	}
+
+	book.Update(touch("okx", 104, 1, 105, 1))
+	if q, _ := book.Quote("BTC/USDT"); !q.Crossed() || q.BidVenue != "okx" || q.AskVenue != "binance" {
+		t.Errorf("expected okx's bid to cross binance's offer, got %+v", q)
+	}
+
+	// A blocked feed leaves the NBBO, and an empty side quotes nothing.
+	blocked["okx"] = true
+	book.Update(touch("bybit", 101, 1, 0, 0))
+	q, _ = book.Quote("BTC/USDT")
+	if q.BidVenue != "bybit" || q.AskVenue != "binance" || q.Crossed() {
+		t.Errorf("expected okx left out and bybit's empty ask skipped, got bid on %s, ask on %s", q.BidVenue, q.AskVenue)
+	}
+
+	if _, ok := book.Quote("ETH/USDT"); ok {
+		t.Error("expected no quote for an unseen symbol")
+	}
+}
@@ -0,0 +1,114 @@
+package marketdata
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// SnapshotSource fetches a full order book for symbol over REST.
+type SnapshotSource func(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error)
+
+// Feed is a venue/symbol book the REST fallback may poll.
+type Feed struct {
+	Venue  string
+	Symbol string
+	Fetch  SnapshotSource
+}
+
+// RunRESTFallback keeps books usable while their stream is down. Every
+// interval it fetches a REST snapshot for each feed whose stream has passed
+// the block threshold, one request per feed per interval. Each venue is
+// polled by its own goroutine and each request times out after interval, so
+// a slow or unreachable venue does not hold up the others. Snapshots replace
+// the stored book so risk marks and reconciliation keep working, but they do
+// not count as fresh stream data: IsDataBlocked stays true, so entry signals
+// remain blocked, and they are not published to strategies.
+func (s *Service) RunRESTFallback(ctx context.Context, feeds []Feed, interval time.Duration) {
+	byVenue := make(map[string][]Feed)
+	for _, f := range feeds {
+		byVenue[f.Venue] = append(byVenue[f.Venue], f)
+	}
+
+	var wg sync.WaitGroup
+	for _, venueFeeds := range byVenue {
+		wg.Add(1)
+		go func(venueFeeds []Feed) {
+			defer wg.Done()
+			s.pollVenue(ctx, venueFeeds, interval)
+		}(venueFeeds)
+	}
+	wg.Wait()
+}
+
+// pollVenue polls one venue's feeds every interval until ctx is done.
+func (s *Service) pollVenue(ctx context.Context, feeds []Feed, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, f := range feeds {
+				s.pollFeed(ctx, f, interval)
+			}
+		}
+	}
+}
+
+func (s *Service) pollFeed(ctx context.Context, f Feed, timeout time.Duration) {
+	key := bookKey(f.Venue, f.Symbol)
+	sh := s.shard(key)
+
+	if !s.IsDataBlocked(f.Venue, f.Symbol) {
+		sh.mu.Lock()
+		recovered := sh.degraded[key]
+		delete(sh.degraded, key)
+		sh.mu.Unlock()
+		if recovered {
+			s.logger.Info("market data stream recovered, REST polling stopped", "feed", key)
+		}
+		return
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	snap, err := f.Fetch(fetchCtx, f.Symbol)
+	if err != nil {
+		s.logger.Warn("REST order book fallback failed", "feed", key, "error", err)
+		return
+	}
+	snap.Venue, snap.Symbol = f.Venue, f.Symbol
+	snap.LocalTimestamp = time.Now()
+	sortBook(snap)
+	s.mu.RLock()
+	truncateBook(snap, s.depthFor(f.Venue, f.Symbol))
+	s.mu.RUnlock()
+
+	sh.mu.Lock()
+	entered := !sh.degraded[key]
+	sh.degraded[key] = true
+	delete(sh.restored, key)
+	sh.books[key] = snap
+	sh.mu.Unlock()
+
+	if entered {
+		s.logger.Warn("market data degraded: stream blocked, polling REST depth", "feed", key)
+	}
+}
+
+// IsDegraded reports whether the book for venue/symbol is currently being
+// kept up from REST snapshots rather than the stream, or its latest stream
+// update failed the sanity filter.
+func (s *Service) IsDegraded(venue, symbol string) bool {
+	key := bookKey(venue, symbol)
+	sh := s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	_, anomalous := sh.anomalous[key]
+	return sh.degraded[key] || anomalous
+}
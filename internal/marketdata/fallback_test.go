@@ -0,0 +1,118 @@
+package marketdata
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestRESTFallbackKeepsMarksButBlocksEntries(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+
+	fetches := 0
+	feed := Feed{
+		Venue:  "kcex",
+		Symbol: "BTC/USDT",
+		Fetch: func(_ context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+			fetches++
+			return &domain.OrderBookSnapshot{
+				Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(60000), Size: decimal.NewFromInt(1)}},
+				Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(60010), Size: decimal.NewFromInt(1)}},
+			}, nil
+		},
+	}
+
+	svc.pollFeed(context.Background(), feed, time.Second)
+
+	book, ok := svc.GetOrderBook("kcex", "BTC/USDT")
+	if !ok {
+		t.Fatal("expected REST snapshot to be stored")
+	}
+	if bid, _ := book.BestBid(); !bid.Price.Equal(decimal.NewFromInt(60000)) {
+		t.Errorf("expected best bid 60000, got %s", bid.Price)
+	}
+	if !svc.IsDegraded("kcex", "BTC/USDT") {
+		t.Error("expected feed marked degraded")
+	}
+	if !svc.IsDataBlocked("kcex", "BTC/USDT") {
+		t.Error("REST snapshots must not unblock entry signals")
+	}
+	select {
+	case <-books:
+		t.Error("REST snapshots must not be published to strategies")
+	default:
+	}
+
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "kcex", Symbol: "BTC/USDT"})
+	<-books
+	svc.pollFeed(context.Background(), feed, time.Second)
+
+	if fetches != 1 {
+		t.Errorf("expected no REST poll once the stream is back, got %d fetches", fetches)
+	}
+	if svc.IsDegraded("kcex", "BTC/USDT") {
+		t.Error("expected degraded flag cleared after the stream recovered")
+	}
+}
+
+func TestRESTFallbackPollsVenuesIndependently(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	svc := NewService(eventbus.New(10, logger), 500*time.Millisecond, 2*time.Second, logger)
+
+	// The hung venue ignores its timeout, as a stuck client might.
+	release := make(chan struct{})
+	deadlines := make(chan bool, 100)
+	slow := Feed{
+		Venue:  "nobitex",
+		Symbol: "BTC/USDT",
+		Fetch: func(ctx context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+			_, ok := ctx.Deadline()
+			deadlines <- ok
+			<-release
+			return nil, ctx.Err()
+		},
+	}
+	fast := Feed{
+		Venue:  "kcex",
+		Symbol: "BTC/USDT",
+		Fetch: func(_ context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+			return &domain.OrderBookSnapshot{
+				Bids: []domain.PriceLevel{{Price: decimal.NewFromInt(60000), Size: decimal.NewFromInt(1)}},
+				Asks: []domain.PriceLevel{{Price: decimal.NewFromInt(60010), Size: decimal.NewFromInt(1)}},
+			}, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.RunRESTFallback(ctx, []Feed{slow, fast}, 50*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for !svc.IsDegraded("kcex", "BTC/USDT") {
+		select {
+		case <-deadline:
+			t.Fatal("kcex was not polled while nobitex hung")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	if hasDeadline := <-deadlines; !hasDeadline {
+		t.Error("expected each REST poll to carry its own timeout")
+	}
+
+	cancel()
+	close(release)
+	<-done
+}
@@ -0,0 +1,77 @@
+package marketdata
+
+import (
+	"strings"
+	"time"
+)
+
+// FeedType is a kind of feed whose age is tracked.
+type FeedType string
+
+const (
+	FeedBook    FeedType = "book"
+	FeedFunding FeedType = "funding"
+)
+
+// Freshness holds a feed's age thresholds. A feed older than Stale is
+// logged and counts against the freshness SLI; a book older than Block
+// blocks entries.
+type Freshness struct {
+	Stale time.Duration
+	Block time.Duration
+}
+
+type freshnessKey struct {
+	feed   FeedType
+	venue  string
+	symbol string
+}
+
+// SetFreshness overrides the thresholds for feeds of one type. An empty
+// venue or symbol matches any. When several overrides match a feed, the most
+// specific wins: venue and symbol, then symbol alone, then venue alone, then
+// the feed type. Feeds with no matching override use the thresholds given
+// to NewService.
+func (s *Service) SetFreshness(feed FeedType, venue, symbol string, f Freshness) {
+	s.mu.Lock()
+	s.freshness[freshnessKey{feed, venue, symbol}] = f
+	s.mu.Unlock()
+}
+
+// thresholds returns the thresholds for one feed. It may be called with a
+// shard's lock held.
+func (s *Service) thresholds(feed FeedType, venue, symbol string) Freshness {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range [...]freshnessKey{
+		{feed, venue, symbol},
+		{feed, "", symbol},
+		{feed, venue, ""},
+		{feed, "", ""},
+	} {
+		if f, ok := s.freshness[key]; ok {
+			return f
+		}
+	}
+	return Freshness{Stale: s.staleDuration, Block: s.blockDuration}
+}
+
+// thresholdsFor is thresholds for a "venue:symbol" feed key.
+func (s *Service) thresholdsFor(feed FeedType, key string) Freshness {
+	venue, symbol, _ := strings.Cut(key, ":")
+	return s.thresholds(feed, venue, symbol)
+}
+
+// IsFundingFresh reports whether the venue's funding rate for symbol was
+// updated within its stale threshold.
+func (s *Service) IsFundingFresh(venue, symbol string) bool {
+	key := bookKey(venue, symbol)
+	sh := s.shard(key)
+	sh.mu.RLock()
+	t, ok := sh.fundingUpdate[key]
+	sh.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	return time.Since(t) < s.thresholds(FeedFunding, venue, symbol).Stale
+}
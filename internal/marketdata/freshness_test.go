@@ -0,0 +1,45 @@
+package marketdata
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestFreshnessOverrides(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	svc := NewService(bus, 50*time.Millisecond, 100*time.Millisecond, logger)
+	svc.SetFreshness(FeedBook, "", "ETH/USDT", Freshness{Stale: time.Second, Block: 2 * time.Second})
+	svc.SetFreshness(FeedBook, "kcex", "ETH/USDT", Freshness{Stale: 10 * time.Millisecond, Block: 20 * time.Millisecond})
+	svc.SetFreshness(FeedFunding, "", "", Freshness{Stale: time.Minute, Block: 5 * time.Minute})
+
+	for _, venue := range []string{"okx", "kcex"} {
+		svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: venue, Symbol: "BTC/USDT"})
+		svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: venue, Symbol: "ETH/USDT"})
+	}
+	svc.UpdateFundingRate(domain.FundingRate{Venue: "okx", Symbol: "BTC-USDT-SWAP"})
+	time.Sleep(150 * time.Millisecond)
+
+	if !svc.IsDataBlocked("okx", "BTC/USDT") {
+		t.Error("expected a symbol without an override to use the global block threshold")
+	}
+	if svc.IsDataBlocked("okx", "ETH/USDT") || !svc.IsDataFresh("okx", "ETH/USDT") {
+		t.Error("expected the symbol override to keep a slow book fresh")
+	}
+	if !svc.IsDataBlocked("kcex", "ETH/USDT") {
+		t.Error("expected the venue and symbol override to win over the symbol override")
+	}
+	if !svc.IsFundingFresh("okx", "BTC-USDT-SWAP") {
+		t.Error("expected the funding feed to use its own thresholds")
+	}
+
+	// Only okx ETH/USDT and the funding feed are within their thresholds.
+	if fresh, total := svc.FeedFreshness(); fresh != 2 || total != 5 {
+		t.Errorf("expected 2 of 5 feeds fresh, got %d of %d", fresh, total)
+	}
+}
@@ -0,0 +1,138 @@
+package marketdata
+
+import (
+	"context"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+const (
+	// maxResyncBuffer caps the deltas held while a snapshot is fetched. The
+	// oldest are dropped first, which at worst costs another snapshot.
+	maxResyncBuffer  = 1000
+	resyncTimeout    = 5 * time.Second
+	resyncAttempts   = 3
+	resyncRetryDelay = 200 * time.Millisecond
+)
+
+// resync is an order book being rebuilt from a REST snapshot. Deltas that
+// arrive meanwhile are buffered and replayed on top of the snapshot.
+type resync struct {
+	deltas []domain.OrderBookDelta
+}
+
+func (r *resync) buffer(delta domain.OrderBookDelta) {
+	if len(r.deltas) >= maxResyncBuffer {
+		r.deltas = r.deltas[1:]
+	}
+	r.deltas = append(r.deltas, delta)
+}
+
+// SetSnapshotSource turns on sequence checking for venue's order book deltas
+// and uses fetch to rebuild a book when one is missed. Only deltas that carry
+// FirstSequence are checked. Call before deltas arrive.
+func (s *Service) SetSnapshotSource(venue string, fetch SnapshotSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshotSources[venue] = fetch
+}
+
+// startResync marks the feed as resyncing and fetches a snapshot in the
+// background. The caller holds sh.mu.
+func (s *Service) startResync(sh *shard, key string, delta domain.OrderBookDelta, fetch SnapshotSource) {
+	rs := &resync{}
+	rs.buffer(delta)
+	sh.resyncing[key] = rs
+	go s.resync(key, delta.Venue, delta.Symbol, fetch)
+}
+
+// resync fetches a snapshot, replays the buffered deltas newer than it and
+// installs the result. A snapshot older than the buffered deltas is fetched
+// again. Until it finishes the feed counts as blocked and no book is
+// published, so a book with a hole in it never reaches the strategies; nor is
+// a resynced book that still fails the sanity filter. If every attempt fails
+// the old book is kept and the next delta starts over.
+func (s *Service) resync(key, venue, symbol string, fetch SnapshotSource) {
+	for attempt := 1; attempt <= resyncAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(resyncRetryDelay)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), resyncTimeout)
+		snap, err := fetch(ctx, symbol)
+		cancel()
+		if err != nil {
+			s.logger.Warn("order book resync snapshot failed", "feed", key, "attempt", attempt, "error", err)
+			continue
+		}
+		snap.Venue, snap.Symbol = venue, symbol
+		sortBook(snap)
+		s.mu.RLock()
+		sanity, depth := s.sanity, s.depthFor(venue, symbol)
+		s.mu.RUnlock()
+		truncateBook(snap, depth)
+
+		sh := s.shard(key)
+		sh.mu.Lock()
+		if !replay(snap, sh.resyncing[key].deltas, depth) {
+			sh.mu.Unlock()
+			s.logger.Debug("order book snapshot older than buffered deltas, refetching",
+				"feed", key, "sequence", snap.Sequence)
+			continue
+		}
+		now := time.Now()
+		snap.LocalTimestamp = now
+		sh.books[key] = snap
+		sh.lastUpdate[key] = now
+		delete(sh.resyncing, key)
+		anomaly := sanity.check(sh, key, snap)
+		if anomaly == "" {
+			s.clearAnomaly(sh, key)
+		}
+		published := *snap
+		sh.mu.Unlock()
+
+		s.logger.Info("order book resynced", "feed", key, "sequence", published.Sequence)
+		if anomaly == "" {
+			s.publishBook(sh, key, published)
+		}
+		return
+	}
+
+	sh := s.shard(key)
+	sh.mu.Lock()
+	delete(sh.resyncing, key)
+	sh.mu.Unlock()
+	s.logger.Error("order book resync failed", "feed", key, "attempts", resyncAttempts)
+}
+
+// replay applies the deltas newer than snap to it. It reports false when they
+// do not follow on from the snapshot's sequence. A venue snapshot is always
+// applied, since a venue that restarts its sequence sends one. A snapshot
+// without a sequence (OKX's REST books has none) is placed among the deltas
+// by venue time, and takes the sequence of the last one it holds.
+func replay(snap *domain.OrderBookSnapshot, deltas []domain.OrderBookDelta, depth int) bool {
+	for _, d := range deltas {
+		if d.Snapshot {
+			applyDelta(snap, d, depth)
+			continue
+		}
+		if snap.Sequence == 0 {
+			if d.VenueTimestamp.After(snap.VenueTimestamp) {
+				applyDelta(snap, d, depth)
+			} else {
+				snap.Sequence = d.Sequence
+			}
+			continue
+		}
+		if d.Sequence <= snap.Sequence {
+			continue
+		}
+		if d.FirstSequence > snap.Sequence+1 {
+			return false
+		}
+		applyDelta(snap, d, depth)
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package marketdata
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func level(price, size int64) domain.PriceLevel {
+	return domain.PriceLevel{Price: decimal.NewFromInt(price), Size: decimal.NewFromInt(size)}
+}
+
+func TestSequenceGapTriggersResync(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	svc.SetSnapshotSource("kcex", func(_ context.Context, symbol string) (*domain.OrderBookSnapshot, error) {
+		fetches.Add(1)
+		<-release
+		return &domain.OrderBookSnapshot{
+			Bids:     []domain.PriceLevel{level(100, 1)},
+			Asks:     []domain.PriceLevel{level(101, 1)},
+			Sequence: 20,
+		}, nil
+	})
+
+	// The first delta has no book to apply to, so a snapshot is fetched.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 18, Sequence: 19, Bids: []domain.PriceLevel{level(99, 5)}})
+	if !svc.IsDataBlocked("kcex", "BTC-USDT") {
+		t.Fatal("expected the feed blocked while resyncing")
+	}
+	// Arrives during the resync: one already in the snapshot, one after it.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 20, Sequence: 20, Bids: []domain.PriceLevel{level(98, 5)}})
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 21, Sequence: 22, Asks: []domain.PriceLevel{level(101, 3)}})
+	close(release)
+
+	snap := <-books
+	if snap.Sequence != 22 {
+		t.Errorf("expected buffered deltas replayed up to 22, got %d", snap.Sequence)
+	}
+	if len(snap.Bids) != 1 {
+		t.Errorf("expected deltas covered by the snapshot skipped, got bids %v", snap.Bids)
+	}
+	if ask, _ := snap.BestAsk(); !ask.Size.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("expected replayed ask size 3, got %s", ask.Size)
+	}
+	if svc.IsDataBlocked("kcex", "BTC-USDT") {
+		t.Error("expected the feed unblocked after resync")
+	}
+
+	// In sequence: applied directly.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 23, Sequence: 23, Bids: []domain.PriceLevel{level(100, 2)}})
+	if snap := <-books; snap.Sequence != 23 {
+		t.Errorf("expected contiguous delta applied, got sequence %d", snap.Sequence)
+	}
+
+	// 24 and 25 were missed.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", FirstSequence: 26, Sequence: 26})
+	if !svc.IsDataBlocked("kcex", "BTC-USDT") {
+		t.Error("expected a sequence gap to block the feed")
+	}
+	select {
+	case snap := <-books:
+		t.Errorf("expected nothing published across a gap, got sequence %d", snap.Sequence)
+	default:
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for fetches.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if fetches.Load() < 2 {
+		t.Error("expected the gap to fetch a new snapshot")
+	}
+}
+
+func TestDeltasWithoutSequenceRangeAreNotChecked(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+	svc.SetSnapshotSource("binance", func(_ context.Context, _ string) (*domain.OrderBookSnapshot, error) {
+		t.Error("unexpected snapshot fetch")
+		return nil, context.Canceled
+	})
+
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "binance", Symbol: "BTCUSDT", Sequence: 5, Bids: []domain.PriceLevel{level(100, 1)}})
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "binance", Symbol: "BTCUSDT", Sequence: 9, Bids: []domain.PriceLevel{level(100, 2)}})
+	<-books
+	if snap := <-books; snap.Sequence != 9 {
+		t.Errorf("expected both deltas applied, got sequence %d", snap.Sequence)
+	}
+}
+
+func TestVenueSnapshotReplacesBook(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	svc.SetSnapshotSource("bybit", func(_ context.Context, _ string) (*domain.OrderBookSnapshot, error) {
+		fetches.Add(1)
+		<-release
+		return &domain.OrderBookSnapshot{
+			Bids:     []domain.PriceLevel{level(90, 1)},
+			Asks:     []domain.PriceLevel{level(91, 1)},
+			Sequence: 5,
+		}, nil
+	})
+
+	// A snapshot needs no book to apply to.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "bybit", Symbol: "BTCUSDT", Snapshot: true, Sequence: 100,
+		Bids: []domain.PriceLevel{level(100, 1), level(99, 1)}, Asks: []domain.PriceLevel{level(101, 1)}})
+	if snap := <-books; snap.Sequence != 100 || len(snap.Bids) != 2 {
+		t.Fatalf("expected the snapshot installed, got sequence %d bids %v", snap.Sequence, snap.Bids)
+	}
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "bybit", Symbol: "BTCUSDT", FirstSequence: 101, Sequence: 101, Asks: []domain.PriceLevel{level(102, 1)}})
+	<-books
+
+	// On reconnect the venue's sequence restarts with a new snapshot, which
+	// replaces every level rather than merging into them.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "bybit", Symbol: "BTCUSDT", Snapshot: true, Sequence: 1,
+		Bids: []domain.PriceLevel{level(95, 2)}, Asks: []domain.PriceLevel{level(96, 2)}})
+	snap := <-books
+	if snap.Sequence != 1 || len(snap.Bids) != 1 || len(snap.Asks) != 1 {
+		t.Fatalf("expected the book replaced at 1, got sequence %d bids %v asks %v", snap.Sequence, snap.Bids, snap.Asks)
+	}
+	if fetches.Load() != 0 {
+		t.Error("expected no REST snapshot while the stream is in sequence")
+	}
+
+	// 2 was dropped.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "bybit", Symbol: "BTCUSDT", FirstSequence: 3, Sequence: 3, Bids: []domain.PriceLevel{level(94, 1)}})
+	if !svc.IsDataBlocked("bybit", "BTCUSDT") {
+		t.Fatal("expected a dropped delta to block the feed")
+	}
+	// A venue snapshot during the resync is replayed over the REST one.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "bybit", Symbol: "BTCUSDT", Snapshot: true, Sequence: 1,
+		Bids: []domain.PriceLevel{level(93, 1)}, Asks: []domain.PriceLevel{level(97, 1)}})
+	close(release)
+
+	snap = <-books
+	if fetches.Load() != 1 {
+		t.Errorf("expected one REST snapshot, got %d", fetches.Load())
+	}
+	if snap.Sequence != 1 || len(snap.Bids) != 1 || !snap.Bids[0].Price.Equal(decimal.NewFromInt(93)) {
+		t.Errorf("expected the venue snapshot replayed, got sequence %d bids %v", snap.Sequence, snap.Bids)
+	}
+}
+
+func TestResyncPlacesUnsequencedSnapshotByVenueTime(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+
+	base := time.UnixMilli(1700000000000)
+	release := make(chan struct{})
+	svc.SetSnapshotSource("okx", func(_ context.Context, _ string) (*domain.OrderBookSnapshot, error) {
+		<-release
+		return &domain.OrderBookSnapshot{
+			Bids:           []domain.PriceLevel{level(100, 1)},
+			Asks:           []domain.PriceLevel{level(101, 1)},
+			VenueTimestamp: base.Add(time.Second),
+		}, nil
+	})
+
+	// The REST snapshot has no sequence: 11 is older than it, 12 newer.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "okx", Symbol: "BTC-USDT", FirstSequence: 11, Sequence: 11,
+		Bids: []domain.PriceLevel{level(100, 9)}, VenueTimestamp: base})
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "okx", Symbol: "BTC-USDT", FirstSequence: 12, Sequence: 12,
+		Asks: []domain.PriceLevel{level(101, 3)}, VenueTimestamp: base.Add(2 * time.Second)})
+	close(release)
+
+	snap := <-books
+	if snap.Sequence != 12 {
+		t.Errorf("expected the newer delta replayed to 12, got %d", snap.Sequence)
+	}
+	if bid, _ := snap.BestBid(); !bid.Size.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the delta older than the snapshot skipped, got bid size %s", bid.Size)
+	}
+	if ask, _ := snap.BestAsk(); !ask.Size.Equal(decimal.NewFromInt(3)) {
+		t.Errorf("expected replayed ask size 3, got %s", ask.Size)
+	}
+}
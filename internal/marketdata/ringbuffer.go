@@ -2,6 +2,9 @@ package marketdata
 
 import (
 	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
 
 	"github.com/crypto-trading/trading/internal/domain"
 )
@@ -57,3 +60,50 @@ func (rb *TradeRingBuffer) Len() int {
 	}
 	return int(head)
 }
+
+// TradeFlow summarizes the trades within a window.
+type TradeFlow struct {
+	Trades     int
+	BuyVolume  decimal.Decimal // taker buys, in base units
+	SellVolume decimal.Decimal
+	// VWAP is the volume-weighted average price; zero when nothing traded.
+	VWAP decimal.Decimal
+	// Imbalance is buy volume less sell volume over their sum, from −1 to
+	// 1; 0 when nothing traded.
+	Imbalance float64
+}
+
+// Flow summarizes the trades stamped at or after since. It reads the buffer
+// in place, newest first, and stops at the first older trade, so trades are
+// taken to arrive in time order.
+func (rb *TradeRingBuffer) Flow(since time.Time) TradeFlow {
+	flow := TradeFlow{BuyVolume: decimal.Zero, SellVolume: decimal.Zero, VWAP: decimal.Zero}
+	head := rb.head.Load()
+	count := head
+	if count > rb.cap {
+		count = rb.cap
+	}
+
+	notional := decimal.Zero
+	for i := head; i > head-count; i-- {
+		t := rb.trades[(i-1)%rb.cap].Load()
+		if t == nil {
+			continue
+		}
+		if t.Timestamp.Before(since) {
+			break
+		}
+		flow.Trades++
+		notional = notional.Add(t.Price.Mul(t.Size))
+		if t.Side == domain.SideBuy {
+			flow.BuyVolume = flow.BuyVolume.Add(t.Size)
+		} else {
+			flow.SellVolume = flow.SellVolume.Add(t.Size)
+		}
+	}
+	if volume := flow.BuyVolume.Add(flow.SellVolume); volume.IsPositive() {
+		flow.VWAP = notional.Div(volume)
+		flow.Imbalance = flow.BuyVolume.Sub(flow.SellVolume).Div(volume).InexactFloat64()
+	}
+	return flow
+}
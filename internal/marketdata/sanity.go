@@ -0,0 +1,137 @@
+package marketdata
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Anomaly names why the sanity filter turned a book update away.
+type Anomaly string
+
+const (
+	// AnomalyBadPrice is a level at a zero or negative price, or with a
+	// negative size.
+	AnomalyBadPrice Anomaly = "bad_price"
+	// AnomalyCrossed is a best bid at or above the best ask.
+	AnomalyCrossed Anomaly = "crossed"
+	// AnomalyOutlier is a touch further from the last trade than the filter
+	// allows.
+	AnomalyOutlier Anomaly = "outlier"
+)
+
+// sanityFilter holds the limits set with SetSanityFilter.
+type sanityFilter struct {
+	maxDeviation decimal.Decimal // fraction of the last trade price
+	maxTradeAge  time.Duration
+	onAnomaly    func(venue, symbol string, anomaly Anomaly)
+}
+
+// SetSanityFilter checks every book update before it is stored or
+// published, since a bad venue frame can otherwise show strategies an edge
+// that is not there. Snapshots with a level at a zero or negative price, a
+// crossed touch, or a touch more than maxDeviationPct percent from the last
+// trade are dropped; deltas with such a level are dropped, and deltas that
+// leave the book crossed or off the last trade are applied but not
+// published. Trades are only compared with when they are at most
+// maxTradeAge older than the book (0 disables the comparison). A feed whose
+// latest update was turned away counts as blocked and degraded until a sane
+// one arrives; a crossed book on a venue with a snapshot source is also
+// resynced. onAnomaly, if set, is called for every update turned away. Call
+// before books arrive.
+func (s *Service) SetSanityFilter(maxDeviationPct float64, maxTradeAge time.Duration, onAnomaly func(venue, symbol string, anomaly Anomaly)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sanity = &sanityFilter{
+		maxDeviation: decimal.NewFromFloat(maxDeviationPct).Div(decimal.NewFromInt(100)),
+		maxTradeAge:  maxTradeAge,
+		onAnomaly:    onAnomaly,
+	}
+}
+
+// badLevels returns AnomalyBadPrice if a level has a price at or below zero
+// or a negative size, or "" if none does.
+func badLevels(sides ...[]domain.PriceLevel) Anomaly {
+	for _, levels := range sides {
+		for _, l := range levels {
+			if !l.Price.IsPositive() || l.Size.IsNegative() {
+				return AnomalyBadPrice
+			}
+		}
+	}
+	return ""
+}
+
+// check returns what is wrong with book, or "" if nothing is or the filter
+// is off. The caller holds sh.mu.
+func (f *sanityFilter) check(sh *shard, key string, book *domain.OrderBookSnapshot) Anomaly {
+	if f == nil {
+		return ""
+	}
+	if a := badLevels(book.Bids, book.Asks); a != "" {
+		return a
+	}
+	bid, hasBid := book.BestBid()
+	ask, hasAsk := book.BestAsk()
+	if hasBid && hasAsk && !bid.Price.LessThan(ask.Price) {
+		return AnomalyCrossed
+	}
+	if f.maxTradeAge <= 0 || f.maxDeviation.IsZero() {
+		return ""
+	}
+	buf, ok := sh.tradeBuffers[key]
+	if !ok {
+		return ""
+	}
+	recent := buf.Recent(1)
+	if len(recent) == 0 || !recent[0].Price.IsPositive() {
+		return ""
+	}
+	last := recent[0]
+	at := book.VenueTimestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if at.Sub(last.Timestamp) > f.maxTradeAge {
+		return ""
+	}
+	limit := last.Price.Mul(f.maxDeviation)
+	for _, touch := range []struct {
+		level domain.PriceLevel
+		ok    bool
+	}{{bid, hasBid}, {ask, hasAsk}} {
+		if touch.ok && touch.level.Price.Sub(last.Price).Abs().GreaterThan(limit) {
+			return AnomalyOutlier
+		}
+	}
+	return ""
+}
+
+// flagAnomaly marks the feed as turned away and reports whether it was sane
+// until now. The caller holds sh.mu and calls reportAnomaly once it is
+// released.
+func flagAnomaly(sh *shard, key string, anomaly Anomaly) (entered bool) {
+	_, flagged := sh.anomalous[key]
+	sh.anomalous[key] = anomaly
+	return !flagged
+}
+
+func (s *Service) reportAnomaly(venue, symbol string, anomaly Anomaly, f *sanityFilter, entered bool) {
+	if entered {
+		s.logger.Warn("market data degraded: book update failed sanity check",
+			"feed", bookKey(venue, symbol), "anomaly", string(anomaly))
+	}
+	if f.onAnomaly != nil {
+		f.onAnomaly(venue, symbol, anomaly)
+	}
+}
+
+// clearAnomaly marks the feed sane again. The caller holds sh.mu.
+func (s *Service) clearAnomaly(sh *shard, key string) {
+	if _, flagged := sh.anomalous[key]; flagged {
+		delete(sh.anomalous, key)
+		s.logger.Info("market data sane again", "feed", key)
+	}
+}
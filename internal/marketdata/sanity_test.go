@@ -0,0 +1,109 @@
+package marketdata
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestSanityFilterDropsBadBooks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+	var anomalies []Anomaly
+	svc.SetSanityFilter(5, time.Minute, func(venue, symbol string, anomaly Anomaly) {
+		anomalies = append(anomalies, anomaly)
+	})
+
+	published := func() int {
+		n := 0
+		for {
+			select {
+			case <-books:
+				n++
+			default:
+				return n
+			}
+		}
+	}
+	snapshot := func(bid, ask int64) domain.OrderBookSnapshot {
+		return domain.OrderBookSnapshot{
+			Venue:  "kcex",
+			Symbol: "BTC-USDT",
+			Bids:   []domain.PriceLevel{level(bid, 1)},
+			Asks:   []domain.PriceLevel{level(ask, 1)},
+		}
+	}
+
+	svc.UpdateOrderBook(snapshot(100, 101))
+	if published() != 1 || svc.IsDataBlocked("kcex", "BTC-USDT") {
+		t.Fatal("expected a sane book published and usable")
+	}
+
+	// A crossed snapshot is dropped and blocks the feed.
+	svc.UpdateOrderBook(snapshot(102, 101))
+	if published() != 0 {
+		t.Fatal("expected the crossed book dropped")
+	}
+	if !svc.IsDataBlocked("kcex", "BTC-USDT") || !svc.IsDegraded("kcex", "BTC-USDT") {
+		t.Error("expected the feed blocked and degraded after a crossed book")
+	}
+	if book, _ := svc.GetOrderBook("kcex", "BTC-USDT"); !book.Bids[0].Price.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected the last sane book kept, got bids %v", book.Bids)
+	}
+
+	// A delta with a zero price is dropped.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", Asks: []domain.PriceLevel{level(0, 1)}})
+	if book, _ := svc.GetOrderBook("kcex", "BTC-USDT"); len(book.Asks) != 1 {
+		t.Errorf("expected the zero-priced level dropped, got asks %v", book.Asks)
+	}
+
+	// A sane book clears the flag.
+	svc.UpdateOrderBook(snapshot(100, 101))
+	if published() != 1 || svc.IsDataBlocked("kcex", "BTC-USDT") || svc.IsDegraded("kcex", "BTC-USDT") {
+		t.Fatal("expected a sane book to clear the flag")
+	}
+
+	// Against a trade at 100, a touch 10% away is an outlier; a delta that
+	// leaves the book there is applied but not published.
+	now := time.Now()
+	svc.RecordTrade(domain.Trade{Venue: "kcex", Symbol: "BTC-USDT", Price: decimal.NewFromInt(100), Timestamp: now})
+	svc.ApplyDelta(domain.OrderBookDelta{
+		Venue:          "kcex",
+		Symbol:         "BTC-USDT",
+		Asks:           []domain.PriceLevel{level(101, 0), level(110, 1)},
+		VenueTimestamp: now,
+	})
+	if published() != 0 || !svc.IsDataBlocked("kcex", "BTC-USDT") {
+		t.Fatal("expected an outlier touch held back and the feed blocked")
+	}
+
+	// The same touch is fine once the last trade is too old to compare with.
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:          "kcex",
+		Symbol:         "BTC-USDT",
+		Bids:           []domain.PriceLevel{level(100, 1)},
+		Asks:           []domain.PriceLevel{level(110, 1)},
+		VenueTimestamp: now.Add(2 * time.Minute),
+	})
+	if published() != 1 || svc.IsDataBlocked("kcex", "BTC-USDT") {
+		t.Error("expected a touch far from a stale trade accepted")
+	}
+
+	want := []Anomaly{AnomalyCrossed, AnomalyBadPrice, AnomalyOutlier}
+	if len(anomalies) != len(want) {
+		t.Fatalf("expected anomalies %v, got %v", want, anomalies)
+	}
+	for i := range want {
+		if anomalies[i] != want[i] {
+			t.Errorf("anomaly %d: expected %s, got %s", i, want[i], anomalies[i])
+		}
+	}
+}
@@ -3,27 +3,40 @@ package marketdata
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/eventbus"
 )
 
+// Service keeps the latest book, trades and funding rate of every venue's
+// symbol. Feed state is sharded by "venue:symbol" (see shard.go); mu guards
+// only the settings, which are set before data arrives.
 type Service struct {
-	mu    sync.RWMutex
-	books map[string]*domain.OrderBookSnapshot // key: "venue:symbol"
+	shards [shardCount]*shard
 
-	tradeBuffers map[string]*TradeRingBuffer // key: "venue:symbol"
-	fundingRates map[string]*domain.FundingRate
+	mu                 sync.RWMutex
+	snapshotSources    map[string]SnapshotSource // by venue; enables gap detection
+	checksumEvery      int
+	onChecksumMismatch func(venue, symbol string)
+	sanity             *sanityFilter              // see SetSanityFilter
+	freshness          map[freshnessKey]Freshness // see SetFreshness
+	maxDepth           map[depthKey]int           // see SetMaxDepth
+	conflation         atomic.Int64               // book event interval; see SetConflation
+	onBlocked          func(venue, symbol string, blocked bool)
 
-	lastUpdate   map[string]time.Time // key: "venue:symbol"
+	blocked map[string]bool // books past their block threshold; heartbeat goroutine only
 
 	bus    *eventbus.EventBus
 	logger *slog.Logger
 
-	staleDuration time.Duration
-	blockDuration time.Duration
+	staleDuration     time.Duration
+	blockDuration     time.Duration
 	heartbeatInterval time.Duration
 }
 
@@ -32,17 +45,21 @@ func NewService(
 	staleDuration, blockDuration time.Duration,
 	logger *slog.Logger,
 ) *Service {
-	return &Service{
-		books:             make(map[string]*domain.OrderBookSnapshot),
-		tradeBuffers:      make(map[string]*TradeRingBuffer),
-		fundingRates:      make(map[string]*domain.FundingRate),
-		lastUpdate:        make(map[string]time.Time),
+	s := &Service{
+		snapshotSources:   make(map[string]SnapshotSource),
+		freshness:         make(map[freshnessKey]Freshness),
+		maxDepth:          make(map[depthKey]int),
+		blocked:           make(map[string]bool),
 		bus:               bus,
 		logger:            logger,
 		staleDuration:     staleDuration,
 		blockDuration:     blockDuration,
 		heartbeatInterval: 500 * time.Millisecond,
 	}
+	for i := range s.shards {
+		s.shards[i] = newShard()
+	}
+	return s
 }
 
 func bookKey(venue, symbol string) string {
@@ -52,95 +69,138 @@ func bookKey(venue, symbol string) string {
 func (s *Service) UpdateOrderBook(snap domain.OrderBookSnapshot) {
 	key := bookKey(snap.Venue, snap.Symbol)
 	snap.LocalTimestamp = time.Now()
+	sortBook(&snap)
+	s.mu.RLock()
+	sanity, depth := s.sanity, s.depthFor(snap.Venue, snap.Symbol)
+	s.mu.RUnlock()
+	truncateBook(&snap, depth)
+
+	sh := s.shard(key)
+	sh.mu.Lock()
+	if anomaly := sanity.check(sh, key, &snap); anomaly != "" {
+		entered := flagAnomaly(sh, key, anomaly)
+		sh.mu.Unlock()
+		s.reportAnomaly(snap.Venue, snap.Symbol, anomaly, sanity, entered)
+		return
+	}
+	s.clearAnomaly(sh, key)
+	delete(sh.restored, key)
+	sh.books[key] = &snap
+	sh.lastUpdate[key] = snap.LocalTimestamp
+	sh.mu.Unlock()
 
-	s.mu.Lock()
-	s.books[key] = &snap
-	s.lastUpdate[key] = snap.LocalTimestamp
-	s.mu.Unlock()
-
-	s.bus.PublishOrderBook(snap)
+	s.publishBook(sh, key, snap)
 }
 
+// ApplyDelta updates the stored book with delta and publishes it; a
+// snapshot delta replaces the book. For venues with a snapshot source, a
+// delta that does not follow on from the book's sequence, or leaves the book
+// disagreeing with the venue's checksum, starts a resync instead (see resync.go). With a sanity filter set, a
+// delta that fails it is not published (see SetSanityFilter).
 func (s *Service) ApplyDelta(delta domain.OrderBookDelta) {
 	key := bookKey(delta.Venue, delta.Symbol)
 	now := time.Now()
 
-	s.mu.Lock()
-	book, exists := s.books[key]
+	s.mu.RLock()
+	fetch, checked := s.snapshotSources[delta.Venue]
+	checksumEvery, onMismatch := s.checksumEvery, s.onChecksumMismatch
+	sanity, depth := s.sanity, s.depthFor(delta.Venue, delta.Symbol)
+	s.mu.RUnlock()
+
+	sh := s.shard(key)
+	sh.mu.Lock()
+	if sanity != nil {
+		if anomaly := badLevels(delta.Bids, delta.Asks); anomaly != "" {
+			entered := flagAnomaly(sh, key, anomaly)
+			sh.mu.Unlock()
+			s.reportAnomaly(delta.Venue, delta.Symbol, anomaly, sanity, entered)
+			return
+		}
+	}
+	if rs, ok := sh.resyncing[key]; ok {
+		rs.buffer(delta)
+		sh.mu.Unlock()
+		return
+	}
+	if sh.restored[key] {
+		delete(sh.books, key)
+		delete(sh.restored, key)
+	}
+	book, exists := sh.books[key]
+	if checked && delta.FirstSequence > 0 {
+		switch {
+		case !exists || book.Sequence == 0 || delta.FirstSequence > book.Sequence+1:
+			if exists && book.Sequence > 0 {
+				s.logger.Warn("order book sequence gap, resyncing from snapshot",
+					"feed", key,
+					"book_sequence", book.Sequence,
+					"delta_first_sequence", delta.FirstSequence)
+			}
+			s.startResync(sh, key, delta, fetch)
+			sh.mu.Unlock()
+			return
+		case delta.Sequence <= book.Sequence:
+			sh.mu.Unlock()
+			return
+		}
+	}
 	if !exists {
 		book = &domain.OrderBookSnapshot{
 			Venue:  delta.Venue,
 			Symbol: delta.Symbol,
-			Bids:   make([]domain.PriceLevel, 0, 20),
-			Asks:   make([]domain.PriceLevel, 0, 20),
+			Bids:   make([]domain.PriceLevel, 0, bookCapacity),
+			Asks:   make([]domain.PriceLevel, 0, bookCapacity),
 		}
-		s.books[key] = book
+		sh.books[key] = book
 	}
 
-	book.Bids = applyLevelDeltas(book.Bids, delta.Bids, true)
-	book.Asks = applyLevelDeltas(book.Asks, delta.Asks, false)
-	book.Sequence = delta.Sequence
-	book.VenueTimestamp = delta.VenueTimestamp
-	book.LocalTimestamp = now
-	s.lastUpdate[key] = now
-	snap := *book
-	s.mu.Unlock()
-
-	s.bus.PublishOrderBook(snap)
-}
-
-func applyLevelDeltas(levels []domain.PriceLevel, deltas []domain.PriceLevel, descending bool) []domain.PriceLevel {
-	for _, d := range deltas {
-		found := false
-		for i, l := range levels {
-			if l.Price.Equal(d.Price) {
-				if d.Size.IsZero() {
-					levels = append(levels[:i], levels[i+1:]...)
-				} else {
-					levels[i].Size = d.Size
-				}
-				found = true
-				break
+	applyDelta(book, delta, depth)
+	levels := checksumLevels(delta.Venue)
+	if checked && checksumDue(sh, key, delta, checksumEvery) && checksumCovered(book, depth, levels) {
+		if sum := bookChecksum(book, levels); sum != delta.Checksum {
+			s.startResync(sh, key, delta, fetch)
+			sh.mu.Unlock()
+
+			s.logger.Warn("order book checksum mismatch, resyncing from snapshot",
+				"feed", key,
+				"sequence", delta.Sequence,
+				"venue_checksum", delta.Checksum,
+				"local_checksum", sum)
+			if onMismatch != nil {
+				onMismatch(delta.Venue, delta.Symbol)
 			}
-		}
-		if !found && !d.Size.IsZero() {
-			levels = append(levels, d)
+			return
 		}
 	}
-
-	sortLevels(levels, descending)
-	return levels
-}
-
-func sortLevels(levels []domain.PriceLevel, descending bool) {
-	n := len(levels)
-	for i := 1; i < n; i++ {
-		for j := i; j > 0; j-- {
-			swap := false
-			if descending {
-				swap = levels[j].Price.GreaterThan(levels[j-1].Price)
-			} else {
-				swap = levels[j].Price.LessThan(levels[j-1].Price)
-			}
-			if swap {
-				levels[j], levels[j-1] = levels[j-1], levels[j]
-			} else {
-				break
-			}
+	if anomaly := sanity.check(sh, key, book); anomaly != "" {
+		if anomaly == AnomalyCrossed && checked {
+			s.startResync(sh, key, delta, fetch)
 		}
+		entered := flagAnomaly(sh, key, anomaly)
+		sh.mu.Unlock()
+		s.reportAnomaly(delta.Venue, delta.Symbol, anomaly, sanity, entered)
+		return
 	}
+	s.clearAnomaly(sh, key)
+	book.LocalTimestamp = now
+	sh.lastUpdate[key] = now
+	snap := *book
+	sh.mu.Unlock()
+
+	s.publishBook(sh, key, snap)
 }
 
 func (s *Service) RecordTrade(trade domain.Trade) {
 	key := bookKey(trade.Venue, trade.Symbol)
 
-	s.mu.Lock()
-	buf, exists := s.tradeBuffers[key]
+	sh := s.shard(key)
+	sh.mu.Lock()
+	buf, exists := sh.tradeBuffers[key]
 	if !exists {
 		buf = NewTradeRingBuffer(1000)
-		s.tradeBuffers[key] = buf
+		sh.tradeBuffers[key] = buf
 	}
-	s.mu.Unlock()
+	sh.mu.Unlock()
 
 	buf.Push(&trade)
 	s.bus.PublishTrade(trade)
@@ -149,18 +209,21 @@ func (s *Service) RecordTrade(trade domain.Trade) {
 func (s *Service) UpdateFundingRate(rate domain.FundingRate) {
 	key := bookKey(rate.Venue, rate.Symbol)
 
-	s.mu.Lock()
-	s.fundingRates[key] = &rate
-	s.mu.Unlock()
+	sh := s.shard(key)
+	sh.mu.Lock()
+	sh.fundingRates[key] = &rate
+	sh.fundingUpdate[key] = time.Now()
+	sh.mu.Unlock()
 
 	s.bus.PublishFundingRate(rate)
 }
 
 func (s *Service) GetOrderBook(venue, symbol string) (*domain.OrderBookSnapshot, bool) {
 	key := bookKey(venue, symbol)
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	book, ok := s.books[key]
+	sh := s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	book, ok := sh.books[key]
 	if !ok {
 		return nil, false
 	}
@@ -168,11 +231,42 @@ func (s *Service) GetOrderBook(venue, symbol string) (*domain.OrderBookSnapshot,
 	return &snap, true
 }
 
+// VWAPForSize is OrderBookSnapshot.VWAPForSize on the current book, walked
+// in place rather than copied. ok is false if there is no book.
+func (s *Service) VWAPForSize(venue, symbol string, side domain.Side, size decimal.Decimal) (vwap, filled decimal.Decimal, ok bool) {
+	key := bookKey(venue, symbol)
+	sh := s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	book, ok := sh.books[key]
+	if !ok {
+		return decimal.Zero, decimal.Zero, false
+	}
+	vwap, filled = book.VWAPForSize(side, size)
+	return vwap, filled, true
+}
+
+// DepthWithinBps is OrderBookSnapshot.DepthWithinBps on the current book.
+// ok is false if there is no book.
+func (s *Service) DepthWithinBps(venue, symbol string, bps int) (bidSize, askSize decimal.Decimal, ok bool) {
+	key := bookKey(venue, symbol)
+	sh := s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	book, ok := sh.books[key]
+	if !ok {
+		return decimal.Zero, decimal.Zero, false
+	}
+	bidSize, askSize = book.DepthWithinBps(bps)
+	return bidSize, askSize, true
+}
+
 func (s *Service) GetFundingRate(venue, symbol string) (*domain.FundingRate, bool) {
 	key := bookKey(venue, symbol)
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	rate, ok := s.fundingRates[key]
+	sh := s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	rate, ok := sh.fundingRates[key]
 	if !ok {
 		return nil, false
 	}
@@ -182,48 +276,108 @@ func (s *Service) GetFundingRate(venue, symbol string) (*domain.FundingRate, boo
 
 func (s *Service) GetRecentTrades(venue, symbol string, n int) []*domain.Trade {
 	key := bookKey(venue, symbol)
-	s.mu.RLock()
-	buf, exists := s.tradeBuffers[key]
-	s.mu.RUnlock()
+	sh := s.shard(key)
+	sh.mu.RLock()
+	buf, exists := sh.tradeBuffers[key]
+	sh.mu.RUnlock()
 	if !exists {
 		return nil
 	}
 	return buf.Recent(n)
 }
 
+// TradeFlow summarizes the trades of venue's symbol over the last window,
+// without copying the trade buffer. It only sees the buffer's last 1000
+// trades. ok is false if no trade has been recorded.
+func (s *Service) TradeFlow(venue, symbol string, window time.Duration) (flow TradeFlow, ok bool) {
+	key := bookKey(venue, symbol)
+	sh := s.shard(key)
+	sh.mu.RLock()
+	buf, exists := sh.tradeBuffers[key]
+	sh.mu.RUnlock()
+	if !exists {
+		return TradeFlow{}, false
+	}
+	return buf.Flow(time.Now().Add(-window)), true
+}
+
 func (s *Service) IsDataFresh(venue, symbol string) bool {
 	key := bookKey(venue, symbol)
-	s.mu.RLock()
-	t, ok := s.lastUpdate[key]
-	s.mu.RUnlock()
-	if !ok {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	t, ok := sh.lastUpdate[key]
+	_, resyncing := sh.resyncing[key]
+	_, anomalous := sh.anomalous[key]
+	sh.mu.RUnlock()
+	if !ok || resyncing || anomalous {
 		return false
 	}
-	return time.Since(t) < s.staleDuration
+	return time.Since(t) < s.thresholds(FeedBook, venue, symbol).Stale
 }
 
 func (s *Service) IsDataBlocked(venue, symbol string) bool {
 	key := bookKey(venue, symbol)
-	s.mu.RLock()
-	t, ok := s.lastUpdate[key]
-	s.mu.RUnlock()
-	if !ok {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	t, ok := sh.lastUpdate[key]
+	_, resyncing := sh.resyncing[key]
+	_, anomalous := sh.anomalous[key]
+	sh.mu.RUnlock()
+	if !ok || resyncing || anomalous {
 		return true
 	}
-	return time.Since(t) > s.blockDuration
+	return time.Since(t) > s.thresholds(FeedBook, venue, symbol).Block
 }
 
 func (s *Service) DataAge(venue, symbol string) time.Duration {
 	key := bookKey(venue, symbol)
-	s.mu.RLock()
-	t, ok := s.lastUpdate[key]
-	s.mu.RUnlock()
+	sh := s.shard(key)
+	sh.mu.RLock()
+	t, ok := sh.lastUpdate[key]
+	sh.mu.RUnlock()
 	if !ok {
 		return time.Duration(1<<63 - 1)
 	}
 	return time.Since(t)
 }
 
+// FeedFreshness counts the book and funding feeds seen so far and how many
+// of them updated within their stale thresholds.
+func (s *Service) FeedFreshness() (fresh, total int) {
+	now := time.Now()
+	s.forEachUpdate(func(feed FeedType, key string, t time.Time) {
+		total++
+		if now.Sub(t) < s.thresholdsFor(feed, key).Stale {
+			fresh++
+		}
+	})
+	return fresh, total
+}
+
+// forEachUpdate calls fn with the last update time of every book and
+// funding feed, one shard at a time. fn runs with the shard's lock held.
+func (s *Service) forEachUpdate(fn func(feed FeedType, key string, t time.Time)) {
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		for key, t := range sh.lastUpdate {
+			fn(FeedBook, key, t)
+		}
+		for key, t := range sh.fundingUpdate {
+			fn(FeedFunding, key, t)
+		}
+		sh.mu.RUnlock()
+	}
+}
+
+// SetBlockCallback registers fn to be called from the heartbeat monitor
+// when a book crosses its block threshold, with blocked true, and when it
+// updates again, with blocked false. Call before RunHeartbeatMonitor.
+func (s *Service) SetBlockCallback(fn func(venue, symbol string, blocked bool)) {
+	s.mu.Lock()
+	s.onBlocked = fn
+	s.mu.Unlock()
+}
+
 func (s *Service) RunHeartbeatMonitor(ctx context.Context) {
 	ticker := time.NewTicker(s.heartbeatInterval)
 	defer ticker.Stop()
@@ -239,18 +393,45 @@ func (s *Service) RunHeartbeatMonitor(ctx context.Context) {
 }
 
 func (s *Service) checkStaleness() {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	now := time.Now()
-	for key, t := range s.lastUpdate {
+	changed := make(map[string]bool)
+	s.forEachUpdate(func(feed FeedType, key string, t time.Time) {
 		age := now.Sub(t)
-		if age > s.blockDuration {
+		limits := s.thresholdsFor(feed, key)
+		if feed == FeedBook && (age > limits.Block) != s.blocked[key] {
+			changed[key] = age > limits.Block
+		}
+		switch {
+		case feed == FeedFunding:
+			if age > limits.Stale {
+				s.logger.Warn("funding rate stale: exceeds warning threshold",
+					"feed", key, "age_ms", age.Milliseconds())
+			}
+		case age > limits.Block:
 			s.logger.Warn("market data blocked: exceeds block threshold",
 				"feed", key, "age_ms", age.Milliseconds())
-		} else if age > s.staleDuration {
+		case age > limits.Stale:
 			s.logger.Warn("market data stale: exceeds warning threshold",
 				"feed", key, "age_ms", age.Milliseconds())
 		}
+	})
+	if len(changed) == 0 {
+		return
+	}
+
+	s.mu.RLock()
+	onBlocked := s.onBlocked
+	s.mu.RUnlock()
+	for key, blocked := range changed {
+		if blocked {
+			s.blocked[key] = true
+		} else {
+			delete(s.blocked, key)
+			s.logger.Info("market data recovered: back under block threshold", "feed", key)
+		}
+		if onBlocked != nil {
+			venue, symbol, _ := strings.Cut(key, ":")
+			onBlocked(venue, symbol, blocked)
+		}
 	}
 }
@@ -1,6 +1,7 @@
 package marketdata
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
@@ -63,6 +64,34 @@ func TestOrderBookUpdate(t *testing.T) {
 	}
 }
 
+func TestServiceVWAPForSize(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	bus := eventbus.New(10, logger)
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+
+	if _, _, ok := svc.VWAPForSize("nobitex", "BTC/USDT", domain.SideBuy, decimal.NewFromInt(1)); ok {
+		t.Fatal("expected no quote without a book")
+	}
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "nobitex",
+		Symbol: "BTC/USDT",
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(1.5)}},
+		Asks: []domain.PriceLevel{
+			{Price: decimal.NewFromInt(50000), Size: decimal.NewFromInt(1)},
+			{Price: decimal.NewFromInt(50100), Size: decimal.NewFromInt(3)},
+		},
+	})
+
+	vwap, filled, ok := svc.VWAPForSize("nobitex", "BTC/USDT", domain.SideBuy, decimal.NewFromInt(2))
+	if !ok || !vwap.Equal(decimal.NewFromInt(50050)) || !filled.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected 2 filled at 50050, got %s at %s (ok=%v)", filled, vwap, ok)
+	}
+	bid, ask, ok := svc.DepthWithinBps("nobitex", "BTC/USDT", 10)
+	if !ok || !bid.Equal(decimal.NewFromFloat(1.5)) || !ask.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected 1.5/1 within 10 bps, got %s/%s (ok=%v)", bid, ask, ok)
+	}
+}
+
 func TestDataFreshness(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	bus := eventbus.New(10, logger)
@@ -88,6 +117,31 @@ func TestDataFreshness(t *testing.T) {
 	}
 }
 
+func TestBlockCallbackOnThresholdCrossings(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	svc := NewService(bus, 20*time.Millisecond, 50*time.Millisecond, logger)
+	var calls []string
+	svc.SetBlockCallback(func(venue, symbol string, blocked bool) {
+		calls = append(calls, fmt.Sprintf("%s:%s %t", venue, symbol, blocked))
+	})
+
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "test", Symbol: "BTC/USDT"})
+	svc.UpdateFundingRate(domain.FundingRate{Venue: "test", Symbol: "BTC-PERP"})
+	svc.checkStaleness()
+	time.Sleep(60 * time.Millisecond)
+	svc.checkStaleness()
+	svc.checkStaleness()
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "test", Symbol: "BTC/USDT"})
+	svc.checkStaleness()
+
+	// Funding feeds never block, and each crossing is reported once.
+	want := []string{"test:BTC/USDT true", "test:BTC/USDT false"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, calls)
+	}
+}
+
 func TestTradeRingBuffer(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	bus := eventbus.New(10, logger)
@@ -107,6 +161,56 @@ func TestTradeRingBuffer(t *testing.T) {
 	}
 }
 
+func TestTradeFlow(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	svc := NewService(bus, time.Second, 2*time.Second, logger)
+
+	if _, ok := svc.TradeFlow("test", "BTC/USDT", time.Minute); ok {
+		t.Error("expected no flow before any trade")
+	}
+
+	now := time.Now()
+	for _, tr := range []struct {
+		price, size int64
+		side        domain.Side
+		age         time.Duration
+	}{
+		{90, 10, domain.SideSell, 2 * time.Minute}, // outside the window
+		{100, 3, domain.SideBuy, 30 * time.Second},
+		{110, 1, domain.SideSell, 10 * time.Second},
+		{105, 4, domain.SideBuy, 0},
+	} {
+		svc.RecordTrade(domain.Trade{
+			Venue:     "test",
+			Symbol:    "BTC/USDT",
+			Price:     decimal.NewFromInt(tr.price),
+			Size:      decimal.NewFromInt(tr.size),
+			Side:      tr.side,
+			Timestamp: now.Add(-tr.age),
+		})
+	}
+
+	flow, ok := svc.TradeFlow("test", "BTC/USDT", time.Minute)
+	if !ok {
+		t.Fatal("expected flow")
+	}
+	if flow.Trades != 3 || !flow.BuyVolume.Equal(decimal.NewFromInt(7)) || !flow.SellVolume.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected 3 trades, 7 bought and 1 sold, got %+v", flow)
+	}
+	// (300 + 110 + 420) / 8
+	if !flow.VWAP.Equal(decimal.RequireFromString("103.75")) {
+		t.Errorf("expected VWAP 103.75, got %s", flow.VWAP)
+	}
+	if flow.Imbalance != 0.75 {
+		t.Errorf("expected imbalance 0.75, got %v", flow.Imbalance)
+	}
+
+	if flow, _ := svc.TradeFlow("test", "BTC/USDT", 5*time.Second); flow.Trades != 1 {
+		t.Errorf("expected only the latest trade in a short window, got %d", flow.Trades)
+	}
+}
+
 func TestMissingDataReturnsFalse(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	bus := eventbus.New(10, logger)
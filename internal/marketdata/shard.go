@@ -0,0 +1,61 @@
+package marketdata
+
+import (
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// shardCount is how many shards the Service spreads its feeds over. Feeds
+// on different shards are updated and read without waiting on each other,
+// so a burst of deltas on one symbol does not hold up strategies reading
+// another.
+const shardCount = 32
+
+// shard holds the state of the feeds whose "venue:symbol" key hashes to it,
+// under its own lock.
+type shard struct {
+	mu    sync.RWMutex
+	books map[string]*domain.OrderBookSnapshot
+
+	tradeBuffers map[string]*TradeRingBuffer
+	fundingRates map[string]*domain.FundingRate
+
+	lastUpdate    map[string]time.Time
+	fundingUpdate map[string]time.Time
+	degraded      map[string]bool // books kept up by RunRESTFallback
+	restored      map[string]bool // books from Restore not yet replaced by the feed
+
+	resyncing     map[string]*resync
+	sinceChecksum map[string]int     // deltas since the book was last validated
+	anomalous     map[string]Anomaly // feeds whose latest update was turned away
+	conflated     map[string]*conflation
+}
+
+func newShard() *shard {
+	return &shard{
+		books:         make(map[string]*domain.OrderBookSnapshot),
+		tradeBuffers:  make(map[string]*TradeRingBuffer),
+		fundingRates:  make(map[string]*domain.FundingRate),
+		lastUpdate:    make(map[string]time.Time),
+		fundingUpdate: make(map[string]time.Time),
+		degraded:      make(map[string]bool),
+		restored:      make(map[string]bool),
+		resyncing:     make(map[string]*resync),
+		sinceChecksum: make(map[string]int),
+		anomalous:     make(map[string]Anomaly),
+		conflated:     make(map[string]*conflation),
+	}
+}
+
+// shard returns the shard holding key's feed, picked by the key's FNV-1a
+// hash.
+func (s *Service) shard(key string) *shard {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return s.shards[h%shardCount]
+}
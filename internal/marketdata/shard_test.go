@@ -0,0 +1,106 @@
+package marketdata
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestShardKeepsFeedsApart(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	svc := NewService(eventbus.New(1000, logger), 500*time.Millisecond, 2*time.Second, logger)
+
+	// Writers on some feeds while readers watch others: run with -race.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			symbol := fmt.Sprintf("S%d-USDT", i)
+			for j := 0; j < 200; j++ {
+				if i%2 == 0 {
+					svc.ApplyDelta(domain.OrderBookDelta{
+						Venue:  "kcex",
+						Symbol: symbol,
+						Bids:   []domain.PriceLevel{level(int64(100+j%10), 1)},
+						Asks:   []domain.PriceLevel{level(int64(200+j%10), 1)},
+					})
+				} else {
+					svc.GetOrderBook("kcex", symbol)
+					svc.IsDataFresh("kcex", symbol)
+					svc.FeedFreshness()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 8; i += 2 {
+		symbol := fmt.Sprintf("S%d-USDT", i)
+		book, ok := svc.GetOrderBook("kcex", symbol)
+		if !ok || len(book.Bids) != 10 || len(book.Asks) != 10 {
+			t.Errorf("%s: expected 10 levels a side, got %v", symbol, book)
+		}
+	}
+	if _, total := svc.FeedFreshness(); total != 4 {
+		t.Errorf("expected 4 feeds, got %d", total)
+	}
+
+	used := make(map[*shard]bool)
+	for _, symbol := range []string{"BTC-USDT", "ETH-USDT", "SOL-USDT", "XRP-USDT", "DOGE-USDT"} {
+		for _, venue := range []string{"kcex", "nobitex", "wallex", "okx"} {
+			used[svc.shard(bookKey(venue, symbol))] = true
+		}
+	}
+	if len(used) < 10 {
+		t.Errorf("expected 20 feeds spread over shards, got %d shards", len(used))
+	}
+}
+
+// BenchmarkGetOrderBookDuringDeltas reads one book while another is being
+// updated as fast as deltas can be applied.
+func BenchmarkGetOrderBookDuringDeltas(b *testing.B) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	svc := NewService(eventbus.New(1, logger), time.Second, 2*time.Second, logger)
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "kcex",
+		Symbol: "ETH-USDT",
+		Bids:   []domain.PriceLevel{level(100, 1)},
+		Asks:   []domain.PriceLevel{level(101, 1)},
+	})
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := int64(0); ; j++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			svc.ApplyDelta(domain.OrderBookDelta{
+				Venue:  "kcex",
+				Symbol: "BTC-USDT",
+				Bids:   []domain.PriceLevel{level(1000-j%20, j%3)},
+			})
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			svc.GetOrderBook("kcex", "ETH-USDT")
+		}
+	})
+	b.StopTimer()
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package marketdata
+
+import (
+	"slices"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// View is read-only access to the Service's market data, for consumers such
+// as strategy modules that should read the shared books rather than keep
+// their own copies from bus events. Books and funding rates are returned as
+// copies; trades are shared with the Service and must not be modified.
+type View interface {
+	GetBook(venue, symbol string) (*domain.OrderBookSnapshot, bool)
+	GetFunding(venue, symbol string) (*domain.FundingRate, bool)
+	GetRecentTrades(venue, symbol string, n int) []*domain.Trade
+}
+
+// View returns a read-only view of s. Unlike s itself, it cannot be used to
+// feed data in.
+func (s *Service) View() View {
+	return serviceView{s}
+}
+
+type serviceView struct {
+	s *Service
+}
+
+// GetBook also copies the price levels, which ApplyDelta updates in place.
+func (v serviceView) GetBook(venue, symbol string) (*domain.OrderBookSnapshot, bool) {
+	key := bookKey(venue, symbol)
+	sh := v.s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	book, ok := sh.books[key]
+	if !ok {
+		return nil, false
+	}
+	snap := *book
+	snap.Bids = slices.Clone(book.Bids)
+	snap.Asks = slices.Clone(book.Asks)
+	return &snap, true
+}
+
+func (v serviceView) GetFunding(venue, symbol string) (*domain.FundingRate, bool) {
+	return v.s.GetFundingRate(venue, symbol)
+}
+
+func (v serviceView) GetRecentTrades(venue, symbol string, n int) []*domain.Trade {
+	return v.s.GetRecentTrades(venue, symbol, n)
+}
@@ -0,0 +1,56 @@
+package marketdata
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestViewBookIsUnaffectedByLaterDeltas(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	svc := NewService(bus, time.Second, 2*time.Second, logger)
+	view := svc.View()
+
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "okx",
+		Symbol: "BTC/USDT",
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(1)}},
+		Asks:   []domain.PriceLevel{{Price: decimal.NewFromInt(101), Size: decimal.NewFromInt(1)}},
+	})
+	book, ok := view.GetBook("okx", "BTC/USDT")
+	if !ok {
+		t.Fatal("expected the book to be visible through the view")
+	}
+
+	svc.ApplyDelta(domain.OrderBookDelta{
+		Venue:  "okx",
+		Symbol: "BTC/USDT",
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(5)}},
+	})
+	if !book.Bids[0].Size.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected the returned book to be a copy, bid size changed to %s", book.Bids[0].Size)
+	}
+	latest, _ := view.GetBook("okx", "BTC/USDT")
+	if !latest.Bids[0].Size.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected the view to read the latest book, got bid size %s", latest.Bids[0].Size)
+	}
+
+	if _, ok := view.GetBook("okx", "ETH/USDT"); ok {
+		t.Error("expected no book for an unseen symbol")
+	}
+	svc.UpdateFundingRate(domain.FundingRate{Venue: "okx", Symbol: "BTC-USDT-SWAP", Rate: decimal.NewFromFloat(0.0001)})
+	if rate, ok := view.GetFunding("okx", "BTC-USDT-SWAP"); !ok || !rate.Rate.Equal(decimal.NewFromFloat(0.0001)) {
+		t.Errorf("expected the latest funding rate, got %v", rate)
+	}
+	svc.RecordTrade(domain.Trade{Venue: "okx", Symbol: "BTC/USDT", Price: decimal.NewFromInt(100)})
+	if trades := view.GetRecentTrades("okx", "BTC/USDT", 10); len(trades) != 1 {
+		t.Errorf("expected 1 recent trade, got %d", len(trades))
+	}
+}
@@ -0,0 +1,122 @@
+package marketdata
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// secondsPerYear annualizes volatility. Crypto trades every day of the year.
+const secondsPerYear = 365 * 24 * 60 * 60
+
+// volState is the running estimate of one venue's symbol.
+type volState struct {
+	price   float64   // last trade price
+	at      time.Time // last trade time
+	first   time.Time // first trade time
+	sum     float64   // decayed sum of squared log returns, as of at
+	samples int
+}
+
+// Volatility estimates the realized volatility of every traded symbol from
+// the trade stream. Each trade's squared log return from the previous trade
+// is added to a sum that decays exponentially with time, with a half-life of
+// halfLife, so recent moves weigh most. Since a random walk with variance σ²
+// per second builds that sum up to σ²τ, where τ is the decay time constant,
+// the estimate does not depend on how often the symbol trades. Until a
+// symbol has traded for a few half-lives the sum has not built up, so it is
+// scaled by how much of it has.
+//
+// Time is market time: the trades' own timestamps. A symbol's sum is decayed
+// up to the latest trade seen on any symbol when queried, so a symbol that
+// goes quiet while the market trades on calms down, and backtests see the
+// same estimates as live trading.
+type Volatility struct {
+	mu        sync.RWMutex
+	tau       float64 // decay time constant in seconds
+	minTrades int
+	states    map[string]*volState // key: "venue:symbol"
+	latest    time.Time
+}
+
+// NewVolatility returns an estimator decaying with halfLife that reports a
+// symbol once it has seen minTrades returns.
+func NewVolatility(halfLife time.Duration, minTrades int) *Volatility {
+	return &Volatility{
+		tau:       halfLife.Seconds() / math.Ln2,
+		minTrades: minTrades,
+		states:    make(map[string]*volState),
+	}
+}
+
+// Run feeds the estimator from trades until ctx is done or trades closes.
+func (v *Volatility) Run(ctx context.Context, trades <-chan domain.Trade) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-trades:
+			if !ok {
+				return
+			}
+			v.Observe(t)
+		}
+	}
+}
+
+// Observe adds a trade to its symbol's estimate. Trades without a price are
+// ignored; a trade stamped before the symbol's last one counts as
+// simultaneous with it.
+func (v *Volatility) Observe(t domain.Trade) {
+	price := t.Price.InexactFloat64()
+	if price <= 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if t.Timestamp.After(v.latest) {
+		v.latest = t.Timestamp
+	}
+	key := bookKey(t.Venue, t.Symbol)
+	s, ok := v.states[key]
+	if !ok {
+		v.states[key] = &volState{price: price, at: t.Timestamp, first: t.Timestamp}
+		return
+	}
+	at := s.at
+	if t.Timestamp.After(at) {
+		at = t.Timestamp
+	}
+	r := math.Log(price / s.price)
+	s.sum = v.decay(s.sum, at.Sub(s.at)) + r*r
+	s.price, s.at = price, at
+	s.samples++
+}
+
+func (v *Volatility) decay(sum float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return sum
+	}
+	return sum * math.Exp(-elapsed.Seconds()/v.tau)
+}
+
+// Annualized returns venue's symbol's annualized realized volatility as a
+// fraction (0.8 is 80%), or false until it has seen enough trades.
+func (v *Volatility) Annualized(venue, symbol string) (float64, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	s, ok := v.states[bookKey(venue, symbol)]
+	if !ok || s.samples < v.minTrades || v.tau <= 0 {
+		return 0, false
+	}
+	built := 1 - math.Exp(-v.latest.Sub(s.first).Seconds()/v.tau)
+	if built <= 0 {
+		return 0, false
+	}
+	perSecond := v.decay(s.sum, v.latest.Sub(s.at)) / (v.tau * built)
+	return math.Sqrt(perSecond * secondsPerYear), true
+}
@@ -0,0 +1,65 @@
+package marketdata
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestVolatilityAnnualized(t *testing.T) {
+	v := NewVolatility(time.Minute, 10)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trade := func(symbol string, at time.Duration, price float64) {
+		v.Observe(domain.Trade{Venue: "kcex", Symbol: symbol, Price: decimal.NewFromFloat(price), Timestamp: start.Add(at)})
+	}
+
+	// A 10 bps move every second either way: a variance of 1e-6 a second.
+	price := 100.0
+	for i := 0; i <= 600; i++ {
+		trade("BTC/USDT", time.Duration(i)*time.Second, price)
+		if i < 5 {
+			if _, ok := v.Annualized("kcex", "BTC/USDT"); ok {
+				t.Fatal("expected no estimate before 10 trades")
+			}
+		}
+		if i%2 == 0 {
+			price *= math.Exp(0.001)
+		} else {
+			price *= math.Exp(-0.001)
+		}
+	}
+	want := math.Sqrt(1e-6 * secondsPerYear)
+	got, ok := v.Annualized("kcex", "BTC/USDT")
+	if !ok || math.Abs(got-want)/want > 0.02 {
+		t.Fatalf("expected an annualized volatility near %.3f, got %.3f (%v)", want, got, ok)
+	}
+
+	// Trading twice as often with the same variance a second gives the same
+	// estimate.
+	price = 100.0
+	for i := 0; i <= 1200; i++ {
+		trade("ETH/USDT", time.Duration(i)*500*time.Millisecond, price)
+		if i%2 == 0 {
+			price *= math.Exp(0.001 / math.Sqrt2)
+		} else {
+			price *= math.Exp(-0.001 / math.Sqrt2)
+		}
+	}
+	eth, _ := v.Annualized("kcex", "ETH/USDT")
+	if math.Abs(eth-want)/want > 0.02 {
+		t.Errorf("expected the trade rate not to matter, got %.3f against %.3f", eth, want)
+	}
+
+	// BTC goes quiet for a half-life while ETH trades on: its variance
+	// halves.
+	btc, _ := v.Annualized("kcex", "BTC/USDT")
+	trade("ETH/USDT", 660*time.Second, price)
+	quiet, _ := v.Annualized("kcex", "BTC/USDT")
+	if ratio := quiet * quiet / (btc * btc); math.Abs(ratio-0.5) > 0.01 {
+		t.Errorf("expected the variance to halve over a quiet half-life, got a ratio of %.3f", ratio)
+	}
+}
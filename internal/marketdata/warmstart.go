@@ -0,0 +1,80 @@
+package marketdata
+
+import (
+	"slices"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Snapshot copies the books and funding rates worth keeping across a
+// restart: those the feeds have delivered, not ones still held from Restore
+// or being resynced or turned away by the sanity filter.
+func (s *Service) Snapshot() ([]domain.OrderBookSnapshot, []domain.FundingRate) {
+	var (
+		books   []domain.OrderBookSnapshot
+		funding []domain.FundingRate
+	)
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		for key, book := range sh.books {
+			_, resyncing := sh.resyncing[key]
+			_, anomalous := sh.anomalous[key]
+			if sh.restored[key] || resyncing || anomalous {
+				continue
+			}
+			snap := *book
+			snap.Bids = slices.Clone(book.Bids)
+			snap.Asks = slices.Clone(book.Asks)
+			books = append(books, snap)
+		}
+		for key := range sh.fundingUpdate {
+			funding = append(funding, *sh.fundingRates[key])
+		}
+		sh.mu.RUnlock()
+	}
+	return books, funding
+}
+
+// Restore installs books and funding rates saved by an earlier run, so
+// marks and views have a starting point. They do not count as updates:
+// IsDataFresh and IsFundingFresh stay false and IsDataBlocked true until the
+// feeds deliver, and nothing is published. The first delta for a restored
+// book replaces it rather than applying to it. Feeds that already have data
+// are left alone. Call before data arrives.
+func (s *Service) Restore(books []domain.OrderBookSnapshot, funding []domain.FundingRate) {
+	for _, book := range books {
+		key := bookKey(book.Venue, book.Symbol)
+		s.mu.RLock()
+		depth := s.depthFor(book.Venue, book.Symbol)
+		s.mu.RUnlock()
+		sortBook(&book)
+		truncateBook(&book, depth)
+
+		sh := s.shard(key)
+		sh.mu.Lock()
+		if _, ok := sh.books[key]; !ok {
+			sh.books[key] = &book
+			sh.restored[key] = true
+		}
+		sh.mu.Unlock()
+	}
+	for _, rate := range funding {
+		key := bookKey(rate.Venue, rate.Symbol)
+		sh := s.shard(key)
+		sh.mu.Lock()
+		if _, ok := sh.fundingRates[key]; !ok {
+			sh.fundingRates[key] = &rate
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// IsRestored reports whether the book for venue/symbol is still the one
+// installed by Restore.
+func (s *Service) IsRestored(venue, symbol string) bool {
+	key := bookKey(venue, symbol)
+	sh := s.shard(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.restored[key]
+}
@@ -0,0 +1,64 @@
+package marketdata
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+func TestRestoredBooksStayStaleUntilRefreshed(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(10, logger)
+	books := bus.SubscribeOrderBook()
+	svc := NewService(bus, 500*time.Millisecond, 2*time.Second, logger)
+
+	saved := NewService(eventbus.New(10, logger), time.Second, 2*time.Second, logger)
+	saved.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "kcex", Symbol: "BTC-USDT", Bids: []domain.PriceLevel{level(100, 1)}, Asks: []domain.PriceLevel{level(101, 1)}})
+	saved.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "okx", Symbol: "BTC-USDT", Bids: []domain.PriceLevel{level(100, 1)}, Asks: []domain.PriceLevel{level(101, 1)}})
+	saved.UpdateFundingRate(domain.FundingRate{Venue: "okx", Symbol: "BTC-USDT", Rate: decimal.RequireFromString("0.0001")})
+	savedBooks, savedFunding := saved.Snapshot()
+	if len(savedBooks) != 2 || len(savedFunding) != 1 {
+		t.Fatalf("expected 2 books and 1 funding rate saved, got %d and %d", len(savedBooks), len(savedFunding))
+	}
+
+	svc.Restore(savedBooks, savedFunding)
+	if book, ok := svc.GetOrderBook("kcex", "BTC-USDT"); !ok || !book.Bids[0].Price.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("expected the restored book, got %+v", book)
+	}
+	if _, ok := svc.GetFundingRate("okx", "BTC-USDT"); !ok {
+		t.Error("expected the restored funding rate")
+	}
+	if svc.IsDataFresh("kcex", "BTC-USDT") || !svc.IsDataBlocked("kcex", "BTC-USDT") || svc.IsFundingFresh("okx", "BTC-USDT") {
+		t.Error("expected restored data to be stale")
+	}
+	if !svc.IsRestored("kcex", "BTC-USDT") {
+		t.Error("expected the book marked restored")
+	}
+	if len(books) != 0 {
+		t.Error("expected restored books not published")
+	}
+	if b, f := svc.Snapshot(); len(b) != 0 || len(f) != 0 {
+		t.Errorf("expected restored data left out of the next snapshot, got %d books and %d rates", len(b), len(f))
+	}
+
+	// A delta replaces the restored book rather than applying to it.
+	svc.ApplyDelta(domain.OrderBookDelta{Venue: "kcex", Symbol: "BTC-USDT", Bids: []domain.PriceLevel{level(99, 2)}})
+	snap := <-books
+	if len(snap.Bids) != 1 || !snap.Bids[0].Price.Equal(decimal.NewFromInt(99)) || len(snap.Asks) != 0 {
+		t.Errorf("expected the restored book dropped, got bids %v asks %v", snap.Bids, snap.Asks)
+	}
+	if svc.IsRestored("kcex", "BTC-USDT") || !svc.IsDataFresh("kcex", "BTC-USDT") {
+		t.Error("expected the feed fresh once it delivered")
+	}
+
+	svc.UpdateOrderBook(domain.OrderBookSnapshot{Venue: "okx", Symbol: "BTC-USDT", Bids: []domain.PriceLevel{level(102, 1)}})
+	if svc.IsRestored("okx", "BTC-USDT") || !svc.IsDataFresh("okx", "BTC-USDT") {
+		t.Error("expected a snapshot to replace the restored book")
+	}
+}
@@ -26,6 +26,7 @@ type AlertManager struct {
 	mu       sync.RWMutex
 	alerts   []Alert
 	channels []string
+	observer func(Alert)
 	logger   *slog.Logger
 }
 
@@ -37,6 +38,12 @@ func NewAlertManager(channels []string, logger *slog.Logger) *AlertManager {
 	}
 }
 
+// SetObserver registers fn to be called with every alert fired from now on,
+// for example to persist it. Call before any alert can fire.
+func (am *AlertManager) SetObserver(fn func(Alert)) {
+	am.observer = fn
+}
+
 func (am *AlertManager) Fire(level AlertLevel, name, condition, message string) {
 	alert := Alert{
 		Level:     level,
@@ -58,6 +65,9 @@ func (am *AlertManager) Fire(level AlertLevel, name, condition, message string)
 	)
 
 	am.dispatch(alert)
+	if am.observer != nil {
+		am.observer(alert)
+	}
 }
 
 func (am *AlertManager) dispatch(alert Alert) {
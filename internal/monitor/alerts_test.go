@@ -76,3 +76,16 @@ func TestAlertManagerAcknowledgeNonexistent(t *testing.T) {
 		t.Errorf("expected 1 alert still active, got %d", len(active))
 	}
 }
+
+func TestAlertManagerObserver(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	am := NewAlertManager([]string{"log"}, logger)
+
+	var seen []Alert
+	am.SetObserver(func(a Alert) { seen = append(seen, a) })
+	am.Fire(AlertLevelP2, "stress_limit_breach", "loss over limit", "scenario crash")
+
+	if len(seen) != 1 || seen[0].Name != "stress_limit_breach" || seen[0].FiredAt.IsZero() {
+		t.Fatalf("expected the observer to see the fired alert, got %+v", seen)
+	}
+}
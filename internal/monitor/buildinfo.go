@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// Version is the release version, stamped at build time with
+//
+//	-ldflags "-X github.com/crypto-trading/trading/internal/monitor.Version=v1.4.0"
+var Version = "dev"
+
+// BuildInfo identifies the binary that is running.
+type BuildInfo struct {
+	Version    string    `json:"version"`
+	Commit     string    `json:"commit"`                // empty if built outside a git checkout
+	CommitTime time.Time `json:"commit_time,omitempty"` // zero if unknown
+	Modified   bool      `json:"modified"`              // built from a tree with uncommitted changes
+	GoVersion  string    `json:"go_version"`
+}
+
+// ReadBuildInfo returns Version and the VCS details the Go toolchain embeds
+// in the binary.
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{Version: Version}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = bi.GoVersion
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.CommitTime, _ = time.Parse(time.RFC3339, s.Value)
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit hash, with a
+// "-dirty" suffix for modified trees.
+func (b BuildInfo) ShortCommit() string {
+	c := b.Commit
+	if len(c) > 12 {
+		c = c[:12]
+	}
+	if b.Modified {
+		c += "-dirty"
+	}
+	return c
+}
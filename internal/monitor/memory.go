@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"runtime/metrics"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MemoryPressure is how close the process is to its memory limit.
+type MemoryPressure string
+
+const (
+	MemoryNormal   MemoryPressure = "normal"
+	MemoryHigh     MemoryPressure = "high"
+	MemoryCritical MemoryPressure = "critical"
+)
+
+// MemoryMonitorConfig sets the memory limit MemoryMonitor watches and the
+// share of it, in percent, at which pressure is high and critical.
+type MemoryMonitorConfig struct {
+	Limit         int64 // bytes
+	HighPct       float64
+	CriticalPct   float64
+	CheckInterval time.Duration
+}
+
+// MemoryMonitor compares the memory the Go runtime holds with the memory
+// limit, the same measure the garbage collector works to keep under it, and
+// reports when the pressure changes. Once critical, pressure stays critical
+// until use is back under HighPct, so load shed at the critical level is
+// not restored while the process hovers at it.
+type MemoryMonitor struct {
+	cfg      MemoryMonitorConfig
+	inUse    func() uint64
+	onChange func(level MemoryPressure, inUse uint64)
+	gauge    prometheus.Gauge
+	logger   *slog.Logger
+
+	level MemoryPressure // Run's goroutine only
+}
+
+func NewMemoryMonitor(cfg MemoryMonitorConfig, logger *slog.Logger) *MemoryMonitor {
+	return &MemoryMonitor{
+		cfg:    cfg,
+		inUse:  runtimeMemoryInUse,
+		logger: logger,
+		level:  MemoryNormal,
+	}
+}
+
+// SetPressureCallback registers fn to be called with each change of
+// pressure. Call before Run.
+func (m *MemoryMonitor) SetPressureCallback(fn func(level MemoryPressure, inUse uint64)) {
+	m.onChange = fn
+}
+
+// SetUtilizationGauge sets g to the percentage of the limit in use at every
+// check. Call before Run.
+func (m *MemoryMonitor) SetUtilizationGauge(g prometheus.Gauge) {
+	m.gauge = g
+}
+
+// Run checks memory use every CheckInterval until ctx is done.
+func (m *MemoryMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+func (m *MemoryMonitor) check() {
+	inUse := m.inUse()
+	pct := float64(inUse) / float64(m.cfg.Limit) * 100
+	if m.gauge != nil {
+		m.gauge.Set(pct)
+	}
+	level := MemoryNormal
+	switch {
+	case pct >= m.cfg.CriticalPct:
+		level = MemoryCritical
+	case pct >= m.cfg.HighPct && m.level == MemoryCritical:
+		level = MemoryCritical
+	case pct >= m.cfg.HighPct:
+		level = MemoryHigh
+	}
+	if level == m.level {
+		return
+	}
+	m.level = level
+	m.logger.Warn("memory pressure changed",
+		"level", string(level),
+		"in_use_mb", inUse>>20,
+		"limit_mb", m.cfg.Limit>>20,
+		"pct", pct)
+	if m.onChange != nil {
+		m.onChange(level, inUse)
+	}
+}
+
+// runtimeMemoryInUse is the memory mapped by the Go runtime less what it has
+// returned to the OS, which is what the memory limit bounds. Unlike
+// runtime.ReadMemStats it does not stop the world.
+func runtimeMemoryInUse() uint64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMemoryMonitorPressureLevels(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	m := NewMemoryMonitor(MemoryMonitorConfig{Limit: 1000, HighPct: 80, CriticalPct: 90, CheckInterval: time.Second}, logger)
+	var inUse uint64
+	m.inUse = func() uint64 { return inUse }
+	var levels []MemoryPressure
+	m.SetPressureCallback(func(level MemoryPressure, _ uint64) { levels = append(levels, level) })
+
+	for _, use := range []uint64{500, 810, 820, 950, 850, 700, 850, 600} {
+		inUse = use
+		m.check()
+	}
+
+	// Critical holds at 850 and only clears under the high threshold.
+	want := []MemoryPressure{MemoryHigh, MemoryCritical, MemoryNormal, MemoryHigh, MemoryNormal}
+	if len(levels) != len(want) {
+		t.Fatalf("expected %v, got %v", want, levels)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Errorf("change %d: expected %s, got %s", i, want[i], levels[i])
+		}
+	}
+}
+
+func TestRuntimeMemoryInUse(t *testing.T) {
+	if runtimeMemoryInUse() == 0 {
+		t.Error("expected the runtime to report memory in use")
+	}
+}
@@ -24,11 +24,24 @@ type Metrics struct {
 	DailyPnLUSDT        prometheus.Gauge
 	VenueWSReconnect     *prometheus.CounterVec
 	VenueAPIError        *prometheus.CounterVec
+	VenueRateLimitRemaining *prometheus.GaugeVec
+	VenueClockOffset     *prometheus.GaugeVec
+	VenueRateLimitWait   *prometheus.HistogramVec
+	VenueRESTLatency     *prometheus.HistogramVec
+	VenueWSLatency       *prometheus.HistogramVec
+	VenueCallTotal       *prometheus.CounterVec
+	VenueCallLatency     *prometheus.HistogramVec
+	VenueCallItems       *prometheus.HistogramVec
+	MarketDataAnomaly    *prometheus.CounterVec
+	MarketDataBlocked    *prometheus.GaugeVec
+	BuildInfo            *prometheus.GaugeVec
 
 	DryRunSignalsTotal      prometheus.Counter
 	DryRunSimulatedFills    prometheus.Counter
 	DryRunPnLUSDT           prometheus.Gauge
 	DryRunEdgeRealizedBps   *prometheus.HistogramVec
+	ShadowDivergenceBps     *prometheus.GaugeVec
+	MemoryLimitUtilization  prometheus.Gauge
 }
 
 func NewMetrics(reg prometheus.Registerer) *Metrics {
@@ -119,6 +132,66 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			Help: "Total venue API errors",
 		}, []string{"venue", "endpoint", "error_code"}),
 
+		VenueRateLimitRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "venue_rate_limit_remaining",
+			Help: "Requests left in the venue rate limit budget",
+		}, []string{"venue", "endpoint"}),
+
+		VenueClockOffset: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "venue_clock_offset_ms",
+			Help: "Estimated venue clock minus local clock, used to correct signing timestamps",
+		}, []string{"venue"}),
+
+		VenueRateLimitWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "venue_rate_limit_wait_ms",
+			Help:    "Time REST requests queued for their rate limit bucket, by endpoint category",
+			Buckets: prometheus.ExponentialBuckets(0.1, 4, 10),
+		}, []string{"venue", "category"}),
+
+		VenueRESTLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "venue_rest_latency_ms",
+			Help:    "Venue REST round trip per attempt, by endpoint category",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		}, []string{"venue", "category"}),
+
+		VenueWSLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "venue_ws_latency_ms",
+			Help:    "Delay from the venue stamping a stream event to its arrival, by stream",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		}, []string{"venue", "stream"}),
+
+		VenueCallTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "venue_gateway_calls_total",
+			Help: "Venue gateway calls by method and result",
+		}, []string{"venue", "method", "result"}),
+
+		VenueCallLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "venue_gateway_call_latency_ms",
+			Help:    "Venue gateway call latency by method",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		}, []string{"venue", "method"}),
+
+		VenueCallItems: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "venue_gateway_call_items",
+			Help:    "Orders, balances, positions or book levels per venue gateway call",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+		}, []string{"venue", "method"}),
+
+		MarketDataAnomaly: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "market_data_anomaly_total",
+			Help: "Book updates dropped by the market data sanity filter, by anomaly",
+		}, []string{"venue", "symbol", "anomaly"}),
+
+		MarketDataBlocked: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "market_data_blocked",
+			Help: "1 while the book is past its block threshold, else 0",
+		}, []string{"venue", "symbol"}),
+
+		BuildInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "trader_build_info",
+			Help: "Always 1; labels identify the running build and configuration",
+		}, []string{"instance_id", "version", "commit", "config_hash", "trading_mode", "strategies", "venues"}),
+
 		DryRunSignalsTotal: prometheus.NewCounter(prometheus.CounterOpts{
 			Name: "dry_run_signals_total",
 			Help: "Total signals in dry run mode",
@@ -139,6 +212,16 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			Help:    "Realized edge on dry run trades",
 			Buckets: prometheus.LinearBuckets(-50, 5, 30),
 		}, []string{"strategy", "venue"}),
+
+		ShadowDivergenceBps: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "shadow_live_edge_divergence_bps",
+			Help: "Mean shadow minus mean live edge capture (realized minus expected edge) over recent completed cycles",
+		}, []string{"strategy"}),
+
+		MemoryLimitUtilization: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "memory_limit_utilization_pct",
+			Help: "Memory held by the Go runtime as a percentage of its memory limit",
+		}),
 	}
 
 	reg.MustRegister(
@@ -158,10 +241,23 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		m.DailyPnLUSDT,
 		m.VenueWSReconnect,
 		m.VenueAPIError,
+		m.VenueRateLimitRemaining,
+		m.VenueClockOffset,
+		m.VenueRateLimitWait,
+		m.VenueRESTLatency,
+		m.VenueWSLatency,
+		m.VenueCallTotal,
+		m.VenueCallLatency,
+		m.VenueCallItems,
+		m.MarketDataAnomaly,
+		m.MarketDataBlocked,
+		m.BuildInfo,
 		m.DryRunSignalsTotal,
 		m.DryRunSimulatedFills,
 		m.DryRunPnLUSDT,
 		m.DryRunEdgeRealizedBps,
+		m.ShadowDivergenceBps,
+		m.MemoryLimitUtilization,
 	)
 
 	return m
@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxProfilerSamples bounds the memory the profiler's window can use under a
+// burst of orders; the oldest samples go first.
+const maxProfilerSamples = 10000
+
+// ProfilerConfig sets when LatencyProfiler captures a CPU profile and where
+// it keeps them.
+type ProfilerConfig struct {
+	// Dir receives the profiles, named cpu-<UTC time>-p99-<ms>ms.pprof.
+	Dir string
+	// Threshold is the tick-to-ack p99 above which a profile is captured.
+	Threshold time.Duration
+	// Window is how far back samples count toward the p99, and MinSamples
+	// how many it needs before a p99 is trusted.
+	Window     time.Duration
+	MinSamples int
+	// CheckInterval is how often the p99 is computed.
+	CheckInterval time.Duration
+	// Duration is the length of each profile. Cooldown is the minimum time
+	// from the end of one profile to the start of the next, so a sustained
+	// regression does not profile the process continuously.
+	Duration time.Duration
+	Cooldown time.Duration
+	// MaxProfiles is how many profiles are kept in Dir; older ones are
+	// deleted. Zero keeps them all.
+	MaxProfiles int
+}
+
+// LatencyProfiler captures short CPU profiles while end-to-end latency is
+// degraded, so a transient regression can be diagnosed after the fact with
+// go tool pprof. Feed it with Observe and start it with Run.
+type LatencyProfiler struct {
+	cfg    ProfilerConfig
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	samples []profilerSample
+
+	lastCapture time.Time // end of the last profile; Run's goroutine only
+}
+
+type profilerSample struct {
+	at      time.Time
+	elapsed time.Duration
+}
+
+func NewLatencyProfiler(cfg ProfilerConfig, logger *slog.Logger) *LatencyProfiler {
+	return &LatencyProfiler{cfg: cfg, logger: logger}
+}
+
+// Observe records one tick-to-ack latency.
+func (p *LatencyProfiler) Observe(elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.samples) == maxProfilerSamples {
+		p.samples = append(p.samples[:0], p.samples[1:]...)
+	}
+	p.samples = append(p.samples, profilerSample{at: time.Now(), elapsed: elapsed})
+}
+
+// P99 returns the p99 of the samples in the window. ok is false when there
+// are fewer than MinSamples of them.
+func (p *LatencyProfiler) P99() (time.Duration, bool) {
+	cutoff := time.Now().Add(-p.cfg.Window)
+	p.mu.Lock()
+	keep := p.samples[:0]
+	for _, s := range p.samples {
+		if s.at.After(cutoff) {
+			keep = append(keep, s)
+		}
+	}
+	p.samples = keep
+	sorted := make([]time.Duration, len(keep))
+	for i, s := range keep {
+		sorted[i] = s.elapsed
+	}
+	p.mu.Unlock()
+
+	if len(sorted) == 0 || len(sorted) < p.cfg.MinSamples {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(0.99*float64(len(sorted)))) - 1
+	return sorted[max(idx, 0)], true
+}
+
+// Run checks the p99 every CheckInterval and captures a profile when it is
+// over the threshold, until ctx ends.
+func (p *LatencyProfiler) Run(ctx context.Context) {
+	if err := os.MkdirAll(p.cfg.Dir, 0o755); err != nil {
+		p.logger.Error("cpu profiler disabled: cannot create profile directory", "dir", p.cfg.Dir, "error", err)
+		return
+	}
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		p99, ok := p.P99()
+		if !ok || p99 <= p.cfg.Threshold {
+			continue
+		}
+		if !p.lastCapture.IsZero() && time.Since(p.lastCapture) < p.cfg.Cooldown {
+			continue
+		}
+		p.capture(ctx, p99)
+	}
+}
+
+// capture writes one CPU profile of Duration, or less if ctx ends first.
+func (p *LatencyProfiler) capture(ctx context.Context, p99 time.Duration) {
+	name := filepath.Join(p.cfg.Dir, fmt.Sprintf("cpu-%s-p99-%dms.pprof",
+		time.Now().UTC().Format("20060102T150405Z"), p99.Milliseconds()))
+	f, err := os.Create(name)
+	if err != nil {
+		p.logger.Error("failed to create cpu profile", "path", name, "error", err)
+		return
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		// Most likely a profile requested by hand is already running.
+		f.Close()
+		os.Remove(name)
+		p.logger.Warn("cpu profile not captured", "error", err)
+		return
+	}
+	p.logger.Warn("tick-to-ack p99 over threshold, capturing cpu profile",
+		"p99_ms", p99.Milliseconds(),
+		"threshold_ms", p.cfg.Threshold.Milliseconds(),
+		"duration", p.cfg.Duration,
+		"path", name)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(p.cfg.Duration):
+	}
+	pprof.StopCPUProfile()
+	if err := f.Close(); err != nil {
+		p.logger.Error("failed to write cpu profile", "path", name, "error", err)
+	}
+	p.lastCapture = time.Now()
+	p.prune()
+}
+
+// prune deletes the oldest profiles beyond MaxProfiles. Names sort by
+// capture time.
+func (p *LatencyProfiler) prune() {
+	if p.cfg.MaxProfiles <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(p.cfg.Dir, "cpu-*.pprof"))
+	if err != nil || len(matches) <= p.cfg.MaxProfiles {
+		return
+	}
+	sort.Strings(matches)
+	for _, old := range matches[:len(matches)-p.cfg.MaxProfiles] {
+		if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+			p.logger.Warn("failed to remove old cpu profile", "path", old, "error", err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLatencyProfilerP99NeedsMinSamples(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	p := NewLatencyProfiler(ProfilerConfig{Window: time.Minute, MinSamples: 100}, logger)
+
+	for i := 1; i <= 99; i++ {
+		p.Observe(time.Duration(i) * time.Millisecond)
+	}
+	if _, ok := p.P99(); ok {
+		t.Error("expected no p99 below the minimum sample count")
+	}
+	p.Observe(500 * time.Millisecond)
+	p99, ok := p.P99()
+	if !ok || p99 != 99*time.Millisecond {
+		t.Errorf("expected p99 of 99ms with one outlier in 100, got %s (ok=%v)", p99, ok)
+	}
+}
+
+func TestLatencyProfilerCapturesAndPrunes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	for _, old := range []string{"cpu-20200101T000000Z-p99-300ms.pprof", "cpu-20200102T000000Z-p99-300ms.pprof"} {
+		if err := os.WriteFile(filepath.Join(dir, old), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := NewLatencyProfiler(ProfilerConfig{
+		Dir:           dir,
+		Threshold:     100 * time.Millisecond,
+		Window:        time.Minute,
+		MinSamples:    1,
+		CheckInterval: 10 * time.Millisecond,
+		Duration:      50 * time.Millisecond,
+		Cooldown:      time.Hour,
+		MaxProfiles:   2,
+	}, logger)
+	p.Observe(300 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	p.Run(ctx)
+
+	profiles, _ := filepath.Glob(filepath.Join(dir, "cpu-*.pprof"))
+	if len(profiles) != 2 {
+		t.Fatalf("expected the oldest profile pruned to keep 2, got %v", profiles)
+	}
+	if filepath.Base(profiles[0]) != "cpu-20200102T000000Z-p99-300ms.pprof" {
+		t.Errorf("expected the oldest profile to be the one removed, got %v", profiles)
+	}
+	info, err := os.Stat(profiles[1])
+	if err != nil || info.Size() == 0 {
+		t.Errorf("expected a non-empty captured profile, got %v (err=%v)", info, err)
+	}
+}
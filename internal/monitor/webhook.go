@@ -0,0 +1,219 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Webhook event types.
+const (
+	WebhookSignalExecuted  = "signal_executed"
+	WebhookExecutionReport = "execution_report"
+)
+
+// Webhook request headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" under the shared secret, prefixed with "sha256=".
+// Receivers should reject timestamps far from their own clock.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	webhookQueueSize  = 1000
+	webhookAttempts   = 3
+	webhookRetryDelay = 500 * time.Millisecond
+)
+
+// WebhookEvent is the JSON body POSTed for each event. ID is stable across
+// delivery attempts so receivers can drop duplicates. Data is the payload's
+// versioned domain.Envelope, so its layout does not follow the in-memory
+// structs; receivers in Go can read it with domain.DecodeTradeSignal or
+// domain.DecodeExecutionReport.
+type WebhookEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookPublisher POSTs executed signals and execution reports to external
+// URLs so downstream systems can follow trading without polling the
+// database. Each URL has its own bounded queue and a worker, started by Run,
+// that delivers its events in order with retries, so a slow receiver holds
+// up neither the report feed nor the other URLs. When a URL's queue is full
+// new events for it are dropped rather than slowing execution down.
+type WebhookPublisher struct {
+	targets []webhookTarget
+	secret  []byte
+	client  *http.Client
+	logger  *slog.Logger
+
+	retryDelay time.Duration
+}
+
+// webhookTarget is one URL and the events waiting to be delivered to it.
+type webhookTarget struct {
+	url   string
+	queue chan WebhookEvent
+}
+
+func NewWebhookPublisher(urls []string, secret string, timeout time.Duration, logger *slog.Logger) *WebhookPublisher {
+	targets := make([]webhookTarget, len(urls))
+	for i, url := range urls {
+		targets[i] = webhookTarget{url: url, queue: make(chan WebhookEvent, webhookQueueSize)}
+	}
+	return &WebhookPublisher{
+		targets:    targets,
+		secret:     []byte(secret),
+		client:     &http.Client{Timeout: timeout},
+		logger:     logger,
+		retryDelay: webhookRetryDelay,
+	}
+}
+
+// PublishSignal queues a signal the execution engine has started executing.
+func (p *WebhookPublisher) PublishSignal(signal domain.TradeSignal) {
+	data, err := domain.EncodeTradeSignal(&signal)
+	if err != nil {
+		p.logger.Error("failed to encode webhook signal", "signal_id", signal.SignalID, "error", err)
+		return
+	}
+	p.enqueue(WebhookSignalExecuted, data)
+}
+
+// PublishReport queues the outcome of an executed signal.
+func (p *WebhookPublisher) PublishReport(report domain.ExecutionReport) {
+	data, err := domain.EncodeExecutionReport(&report)
+	if err != nil {
+		p.logger.Error("failed to encode webhook report", "signal_id", report.SignalID, "error", err)
+		return
+	}
+	p.enqueue(WebhookExecutionReport, data)
+}
+
+func (p *WebhookPublisher) enqueue(eventType string, data json.RawMessage) {
+	event := WebhookEvent{ID: uuid.New(), Type: eventType, Timestamp: time.Now().UTC(), Data: data}
+	for _, t := range p.targets {
+		select {
+		case t.queue <- event:
+		default:
+			p.logger.Warn("webhook queue full, dropping event", "url", t.url, "type", eventType, "event_id", event.ID)
+		}
+	}
+}
+
+// Run starts a delivery worker per URL and forwards reports to
+// PublishReport until ctx is done, then waits for the workers to stop.
+func (p *WebhookPublisher) Run(ctx context.Context, reports <-chan domain.ExecutionReport) {
+	var wg sync.WaitGroup
+	for _, t := range p.targets {
+		wg.Add(1)
+		go func(t webhookTarget) {
+			defer wg.Done()
+			p.deliverTo(ctx, t)
+		}(t)
+	}
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report, ok := <-reports:
+			if !ok {
+				reports = nil
+				continue
+			}
+			p.PublishReport(report)
+		}
+	}
+}
+
+// deliverTo posts t's queued events one at a time until ctx is done.
+func (p *WebhookPublisher) deliverTo(ctx context.Context, t webhookTarget) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-t.queue:
+			body, err := json.Marshal(event)
+			if err != nil {
+				p.logger.Error("failed to encode webhook event", "type", event.Type, "error", err)
+				continue
+			}
+			if err := p.post(ctx, t.url, event.Type, body); err != nil {
+				p.logger.Warn("webhook delivery failed",
+					"url", t.url,
+					"type", event.Type,
+					"event_id", event.ID,
+					"error", err)
+			}
+		}
+	}
+}
+
+// post sends body to url, retrying network errors, 429s and 5xx responses.
+func (p *WebhookPublisher) post(ctx context.Context, url, eventType string, body []byte) error {
+	var lastErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(p.retryDelay * time.Duration(attempt-1)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(WebhookEventHeader, eventType)
+		req.Header.Set(WebhookTimestampHeader, ts)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(p.secret, ts, body))
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("HTTP %d", resp.StatusCode)
+		default:
+			return fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+	}
+	return lastErr
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" under
+// secret, as sent in the signature header.
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
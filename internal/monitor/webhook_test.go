@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestWebhookPublisherSignsAndRetries(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	secret := []byte("s3cret")
+
+	type received struct {
+		event WebhookEvent
+		raw   map[string]json.RawMessage
+	}
+	got := make(chan received, 4)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		want := "sha256=" + SignWebhook(secret, r.Header.Get(WebhookTimestampHeader), body)
+		if sig := r.Header.Get(WebhookSignatureHeader); sig != want {
+			t.Errorf("bad signature %q, want %q", sig, want)
+		}
+		var rec received
+		json.Unmarshal(body, &rec.event)
+		json.Unmarshal(body, &rec.raw)
+		if r.Header.Get(WebhookEventHeader) != rec.event.Type {
+			t.Errorf("event header %q does not match body type %q", r.Header.Get(WebhookEventHeader), rec.event.Type)
+		}
+		got <- rec
+	}))
+	defer server.Close()
+
+	p := NewWebhookPublisher([]string{server.URL}, string(secret), time.Second, logger)
+	p.retryDelay = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reports := make(chan domain.ExecutionReport, 1)
+	go p.Run(ctx, reports)
+
+	signalID := uuid.New()
+	p.PublishSignal(domain.TradeSignal{SignalID: signalID, Strategy: domain.StrategyTriArb, Venue: "kcex"})
+	reports <- domain.ExecutionReport{SignalID: signalID, Status: "completed", RealizedEdgeBps: decimal.NewFromInt(12)}
+
+	first := <-got
+	if first.event.Type != WebhookSignalExecuted {
+		t.Errorf("expected the signal delivered first after a retry, got %s", first.event.Type)
+	}
+	second := <-got
+	if second.event.Type != WebhookExecutionReport {
+		t.Errorf("expected an execution report, got %s", second.event.Type)
+	}
+	// Payloads are the domain codecs' versioned envelopes.
+	signal, err := domain.DecodeTradeSignal(first.raw["data"])
+	if err != nil || signal.SignalID != signalID || signal.Venue != "kcex" {
+		t.Errorf("unexpected signal payload %s: %v", first.raw["data"], err)
+	}
+	report, err := domain.DecodeExecutionReport(second.raw["data"])
+	if err != nil || report.SignalID != signalID || report.Status != "completed" || !report.RealizedEdgeBps.Equal(decimal.NewFromInt(12)) {
+		t.Errorf("unexpected report payload %s: %v", second.raw["data"], err)
+	}
+	var env domain.Envelope
+	if err := json.Unmarshal(second.raw["data"], &env); err != nil || env.Schema != domain.SchemaExecutionReport || env.Version != domain.ExecutionReportSchemaVersion {
+		t.Errorf("expected an execution report envelope, got %s", second.raw["data"])
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 requests including the retried one, got %d", calls)
+	}
+}
+
+func TestWebhookPublisherSlowReceiverHoldsUpNothingElse(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	got := make(chan string, 4)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Get(WebhookEventHeader)
+	}))
+	defer fast.Close()
+
+	p := NewWebhookPublisher([]string{slow.URL, fast.URL}, "s3cret", 5*time.Second, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reports := make(chan domain.ExecutionReport)
+	go p.Run(ctx, reports)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case reports <- domain.ExecutionReport{SignalID: uuid.New(), Status: "completed"}:
+		case <-time.After(time.Second):
+			t.Fatalf("report %d not taken while a receiver is stalled", i)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case eventType := <-got:
+			if eventType != WebhookExecutionReport {
+				t.Errorf("unexpected event type %s", eventType)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("report %d not delivered to the other URL while one is stalled", i)
+		}
+	}
+}
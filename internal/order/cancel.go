@@ -0,0 +1,79 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// cancelAttempts bounds how many times a cancel is sent while the venue still
+// shows the order open.
+const cancelAttempts = 3
+
+// finishCancel settles an order after a cancel request to its venue.
+//
+// Without a way to look the order up, an accepted cancel marks it cancelled,
+// as the venue's ack is all there is to go on. Otherwise the venue's view of
+// the order decides. It is applied through HandleOrderUpdate, so a fill that
+// raced the cancel is recorded before the order closes. A cancel rejected
+// because the order had already filled or been cancelled counts as done.
+// While the venue still shows the order open, the cancel is sent again;
+// cancelling an order twice is harmless.
+func (m *Manager) finishCancel(ctx context.Context, gw gateway.VenueGateway, internalID uuid.UUID, venue, venueID string, cancelErr error) error {
+	provider, ok := gw.(gateway.OrderStatusProvider)
+	if !ok {
+		return m.cancelUnverified(internalID, cancelErr)
+	}
+
+	for attempt := 1; ; attempt++ {
+		update, err := provider.GetOrderStatus(ctx, venueID)
+		switch {
+		case errors.Is(err, gateway.ErrOrderStatusUnsupported):
+			return m.cancelUnverified(internalID, cancelErr)
+		case err != nil:
+			m.logger.Warn("failed to verify cancel",
+				"order_id", internalID, "venue", venue, "attempt", attempt, "error", err)
+		default:
+			update.Venue = venue
+			update.VenueID = venueID
+			m.HandleOrderUpdate(*update)
+			if update.Status.IsTerminal() {
+				if cancelErr != nil {
+					m.logger.Info("cancel rejected for an order that had already finished",
+						"order_id", internalID, "venue", venue, "status", update.Status)
+				}
+				return nil
+			}
+		}
+
+		if attempt == cancelAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.cancelRetryDelay):
+		}
+		_, cancelErr = gw.CancelOrder(ctx, venueID)
+	}
+
+	if cancelErr != nil {
+		return fmt.Errorf("cancel order: %w", cancelErr)
+	}
+	return fmt.Errorf("cancel of %s not confirmed by %s after %d attempts", internalID, venue, cancelAttempts)
+}
+
+// cancelUnverified marks the order cancelled if the venue accepted the cancel.
+func (m *Manager) cancelUnverified(internalID uuid.UUID, cancelErr error) error {
+	if cancelErr != nil {
+		return fmt.Errorf("cancel order: %w", cancelErr)
+	}
+	m.updateStatus(internalID, domain.OrderStatusCancelled)
+	return nil
+}
@@ -0,0 +1,141 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// statusGateway answers order lookups from a script, one reply per call; the
+// last reply repeats.
+type statusGateway struct {
+	mockGateway
+	replies []domain.OrderUpdate
+	lookups int
+	cancels int
+}
+
+func (g *statusGateway) CancelOrder(ctx context.Context, orderID string) (*domain.CancelAck, error) {
+	g.cancels++
+	return g.mockGateway.CancelOrder(ctx, orderID)
+}
+
+func (g *statusGateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	return gateway.CancelEach(ctx, orderIDs, g.CancelOrder)
+}
+
+func (g *statusGateway) GetOrderStatus(_ context.Context, orderID string) (*domain.OrderUpdate, error) {
+	reply := g.replies[min(g.lookups, len(g.replies)-1)]
+	g.lookups++
+	reply.VenueID = orderID
+	return &reply, nil
+}
+
+func newStatusTestManager(t *testing.T, replies ...domain.OrderUpdate) (*Manager, *statusGateway, uuid.UUID) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	gw := &statusGateway{replies: replies}
+	mgr := NewManager(map[string]gateway.VenueGateway{"test": gw}, eventbus.New(64, logger), logger)
+	mgr.cancelRetryDelay = 0
+
+	id := NewOrderID()
+	_, err := mgr.SubmitOrder(context.Background(), domain.OrderRequest{
+		InternalID: id,
+		SignalID:   uuid.New(),
+		Venue:      "test",
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideBuy,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(50000),
+		Size:       decimal.NewFromInt(1),
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	return mgr, gw, id
+}
+
+func TestCancelOrderIngestsRacingFill(t *testing.T) {
+	mgr, _, id := newStatusTestManager(t, domain.OrderUpdate{
+		Status:       domain.OrderStatusCancelled,
+		FilledSize:   decimal.RequireFromString("0.4"),
+		AvgFillPrice: decimal.NewFromInt(50000),
+	})
+
+	if err := mgr.CancelOrder(context.Background(), id); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	order, _ := mgr.GetOrder(id)
+	if order.Status != domain.OrderStatusCancelled || !order.FilledSize.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("expected cancelled with the 0.4 fill recorded, got %s filled %s", order.Status, order.FilledSize)
+	}
+}
+
+func TestCancelOrderRejectedBecauseFilled(t *testing.T) {
+	mgr, gw, id := newStatusTestManager(t, domain.OrderUpdate{
+		Status:       domain.OrderStatusFilled,
+		FilledSize:   decimal.NewFromInt(1),
+		AvgFillPrice: decimal.NewFromInt(49990),
+	})
+	gw.cancelErr = errors.New("order already filled")
+
+	if err := mgr.CancelOrder(context.Background(), id); err != nil {
+		t.Fatalf("expected the rejected cancel of a filled order to succeed, got %v", err)
+	}
+	order, _ := mgr.GetOrder(id)
+	if order.Status != domain.OrderStatusFilled || !order.AvgFillPrice.Equal(decimal.NewFromInt(49990)) {
+		t.Errorf("expected the fill ingested, got %s at %s", order.Status, order.AvgFillPrice)
+	}
+}
+
+func TestCancelOrderRetriesUntilConfirmed(t *testing.T) {
+	mgr, gw, id := newStatusTestManager(t,
+		domain.OrderUpdate{Status: domain.OrderStatusAcknowledged},
+		domain.OrderUpdate{Status: domain.OrderStatusCancelled},
+	)
+
+	if err := mgr.CancelOrder(context.Background(), id); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if gw.cancels != 2 || gw.lookups != 2 {
+		t.Errorf("expected the cancel re-sent once, got %d cancels and %d lookups", gw.cancels, gw.lookups)
+	}
+	if order, _ := mgr.GetOrder(id); order.Status != domain.OrderStatusCancelled {
+		t.Errorf("expected cancelled, got %s", order.Status)
+	}
+}
+
+func TestCancelOrderNotConfirmed(t *testing.T) {
+	mgr, gw, id := newStatusTestManager(t, domain.OrderUpdate{Status: domain.OrderStatusAcknowledged})
+
+	if err := mgr.CancelOrder(context.Background(), id); err == nil {
+		t.Fatal("expected an error when the venue never confirms the cancel")
+	}
+	if gw.cancels != cancelAttempts {
+		t.Errorf("expected %d cancel attempts, got %d", cancelAttempts, gw.cancels)
+	}
+	if order, _ := mgr.GetOrder(id); order.Status.IsTerminal() {
+		t.Errorf("expected the order left open, got %s", order.Status)
+	}
+}
+
+func TestCancelOrdersVerifiesEach(t *testing.T) {
+	mgr, _, id := newStatusTestManager(t, domain.OrderUpdate{
+		Status:     domain.OrderStatusFilled,
+		FilledSize: decimal.NewFromInt(1),
+	})
+
+	mgr.CancelOrders(context.Background(), []uuid.UUID{id}, "test")
+	if order, _ := mgr.GetOrder(id); order.Status != domain.OrderStatusFilled {
+		t.Errorf("expected the batch cancel to record the fill, got %s", order.Status)
+	}
+}
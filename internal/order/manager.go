@@ -2,8 +2,10 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 
@@ -22,9 +24,43 @@ type Manager struct {
 	venueIDMap     map[string]uuid.UUID // venueOrderID → internalID
 	idempotencyMap map[string]uuid.UUID // idempotencyKey → internalID
 
+	// fillBase holds, for orders a cancel-replace amend moved to a new
+	// venue order, what the venue orders before it filled. Fills the
+	// replacement reports count from zero and are added to it.
+	fillBase map[uuid.UUID]carriedFill
+
+	archive   OrderArchive
+	maxOrders int
+	spillDue  chan struct{}
+
+	// cancelRetryDelay is how long to wait before re-checking an order the
+	// venue still shows open after a cancel.
+	cancelRetryDelay time.Duration
+	// pollInterval is how often the orders of a venue without an order
+	// update stream are polled over REST.
+	pollInterval time.Duration
+
+	// instruments holds each venue's tick and step sizes; nil leaves
+	// requests as the caller built them.
+	instruments *domain.InstrumentRegistry
+
+	// onSymbolUnavailable is told about placements the venue rejected
+	// because the symbol can no longer be traded.
+	onSymbolUnavailable func(venue, symbol string, err error)
+
+	// preTrade, if set, may refuse a conformed request before it is
+	// tracked or sent.
+	preTrade func(req domain.OrderRequest) error
+
 	gateways map[string]gateway.VenueGateway
 	bus      *eventbus.EventBus
 	logger   *slog.Logger
+
+	// accounts holds the gateways of venue sub-accounts other than the
+	// default one in gateways, and routeAccount names the account each
+	// strategy trades on; see SetAccounts.
+	accounts     map[domain.VenueAccount]gateway.VenueGateway
+	routeAccount func(venue string, strategy domain.StrategyType) string
 }
 
 func NewManager(
@@ -33,16 +69,135 @@ func NewManager(
 	logger *slog.Logger,
 ) *Manager {
 	return &Manager{
-		orders:         make(map[uuid.UUID]*domain.Order),
-		venueIDMap:     make(map[string]uuid.UUID),
-		idempotencyMap: make(map[string]uuid.UUID),
-		gateways:       gateways,
-		bus:            bus,
-		logger:         logger,
+		orders:           make(map[uuid.UUID]*domain.Order),
+		venueIDMap:       make(map[string]uuid.UUID),
+		idempotencyMap:   make(map[string]uuid.UUID),
+		fillBase:         make(map[uuid.UUID]carriedFill),
+		cancelRetryDelay: 200 * time.Millisecond,
+		pollInterval:     2 * time.Second,
+		gateways:         gateways,
+		bus:              bus,
+		logger:           logger,
+	}
+}
+
+// SetInstruments rounds every submitted order's prices and size to its
+// venue's increments and rejects orders under the venue's minimums before
+// they are sent. Call before orders are submitted.
+func (m *Manager) SetInstruments(reg *domain.InstrumentRegistry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.instruments = reg
+}
+
+// SetAccounts adds the gateways of venue sub-accounts and routes each
+// request to the account route names for its venue and strategy. An empty
+// account is the venue's default gateway; a named one without a gateway in
+// accounts fails the request rather than falling back to the default. Call
+// before orders are submitted and before RunOrderUpdates.
+func (m *Manager) SetAccounts(accounts map[domain.VenueAccount]gateway.VenueGateway, route func(venue string, strategy domain.StrategyType) string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts = accounts
+	m.routeAccount = route
+}
+
+// gatewayFor returns the gateway of account on venue.
+func (m *Manager) gatewayFor(venue, account string) (gateway.VenueGateway, error) {
+	if account == "" {
+		gw, ok := m.gateways[venue]
+		if !ok {
+			return nil, fmt.Errorf("unknown venue: %s", venue)
+		}
+		return gw, nil
+	}
+	m.mu.RLock()
+	gw, ok := m.accounts[domain.VenueAccount{Venue: venue, Account: account}]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown account %s on %s", account, venue)
+	}
+	return gw, nil
+}
+
+// SetSymbolUnavailableCallback registers fn to be called, outside the
+// manager's lock, when a venue rejects an order because its symbol is
+// delisted, suspended or unknown. Call before orders are submitted.
+func (m *Manager) SetSymbolUnavailableCallback(fn func(venue, symbol string, err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onSymbolUnavailable = fn
+}
+
+// SetPreTradeCheck registers fn to vet every conformed request before it is
+// tracked or sent; a request fn returns an error for is refused with it.
+// Call before orders are submitted.
+func (m *Manager) SetPreTradeCheck(fn func(req domain.OrderRequest) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.preTrade = fn
+}
+
+// placeFailed reports a rejected placement to the symbol unavailable
+// callback if the venue no longer trades the symbol.
+func (m *Manager) placeFailed(req domain.OrderRequest, err error) {
+	if !errors.Is(err, gateway.ErrSymbolUnavailable) {
+		return
+	}
+	m.mu.RLock()
+	fn := m.onSymbolUnavailable
+	m.mu.RUnlock()
+	if fn != nil {
+		fn(req.Venue, req.Symbol, err)
 	}
 }
 
+// conform routes req to its strategy's account, applies the instrument
+// registry set by SetInstruments to it, then the check set by
+// SetPreTradeCheck.
+func (m *Manager) conform(req domain.OrderRequest) (domain.OrderRequest, error) {
+	m.mu.RLock()
+	reg := m.instruments
+	check := m.preTrade
+	route := m.routeAccount
+	m.mu.RUnlock()
+	if route != nil && req.Account == "" {
+		req.Account = route(req.Venue, req.Strategy)
+	}
+	if reg != nil {
+		var err error
+		if req, err = reg.Conform(req); err != nil {
+			return req, err
+		}
+	}
+	if check != nil {
+		if err := check(req); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// instrument looks up symbol on venue in the registry set by SetInstruments.
+func (m *Manager) instrument(venue, symbol string) (domain.Instrument, bool) {
+	m.mu.RLock()
+	reg := m.instruments
+	m.mu.RUnlock()
+	if reg == nil {
+		return domain.Instrument{}, false
+	}
+	return reg.Get(venue, symbol)
+}
+
 func (m *Manager) SubmitOrder(ctx context.Context, req domain.OrderRequest) (*domain.Order, error) {
+	if err := validateOrderFlags(req); err != nil {
+		return nil, err
+	}
+	req, err := m.conform(req)
+	if err != nil {
+		return nil, err
+	}
+
 	m.mu.Lock()
 	if existing, ok := m.idempotencyMap[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
 		order := m.orders[existing]
@@ -50,52 +205,274 @@ func (m *Manager) SubmitOrder(ctx context.Context, req domain.OrderRequest) (*do
 		return order, nil
 	}
 
-	order := &domain.Order{
-		InternalID: req.InternalID,
-		SignalID:   req.SignalID,
-		Venue:      req.Venue,
-		Symbol:     req.Symbol,
-		Side:       req.Side,
-		OrderType:  req.OrderType,
-		Price:      req.Price,
-		Size:       req.Size,
-		Status:     domain.OrderStatusPendingNew,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
+	order := newOrder(req)
 
 	m.orders[order.InternalID] = order
 	if req.IdempotencyKey != "" {
 		m.idempotencyMap[req.IdempotencyKey] = order.InternalID
 	}
+	m.noteInsertLocked()
+	pending := *order
 	m.mu.Unlock()
 
-	m.publishStateChange(order, "", domain.OrderStatusPendingNew)
+	m.publishStateChange(pending, "", domain.OrderStatusPendingNew)
 
-	gw, ok := m.gateways[req.Venue]
-	if !ok {
+	gw, err := m.gatewayFor(req.Venue, req.Account)
+	if err != nil {
 		m.updateStatus(order.InternalID, domain.OrderStatusSubmitFailed)
-		return nil, fmt.Errorf("unknown venue: %s", req.Venue)
+		return nil, err
 	}
 
 	m.updateStatus(order.InternalID, domain.OrderStatusSubmitted)
 
 	ack, err := gw.PlaceOrder(ctx, req)
 	if err != nil {
+		if existing, ok := m.lookupDuplicate(ctx, gw, req, err); ok {
+			m.adopt(order, existing)
+			return order, nil
+		}
 		m.updateStatus(order.InternalID, domain.OrderStatusSubmitFailed)
+		m.placeFailed(req, err)
 		return nil, fmt.Errorf("place order: %w", err)
 	}
 
+	m.applyAck(order, ack)
+	return order, nil
+}
+
+// SubmitResult is the outcome of one request in a SubmitOrders call.
+type SubmitResult struct {
+	Order *domain.Order
+	Err   error
+}
+
+// SubmitOrders places several orders with one PlaceOrders call per account, so
+// the legs of a multi-leg signal go out together rather than one round trip
+// at a time. Results are in request order. Requests whose idempotency key is
+// already tracked return the existing order without being resent. A request
+// that fails is released from its idempotency key so the caller can retry it
+// on its own. One the venue rejects as a duplicate client order ID adopts the
+// order already live under it, as SubmitOrder does.
+func (m *Manager) SubmitOrders(ctx context.Context, reqs []domain.OrderRequest) []SubmitResult {
+	reqs = slices.Clone(reqs) // conformed in place
+	results := make([]SubmitResult, len(reqs))
+	byAccount := make(map[domain.VenueAccount][]int)
+	var accounts []domain.VenueAccount
+
+	for i, req := range reqs {
+		if err := validateOrderFlags(req); err != nil {
+			results[i].Err = err
+			continue
+		}
+		req, err := m.conform(req)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		reqs[i] = req
+
+		m.mu.Lock()
+		if existing, ok := m.idempotencyMap[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+			results[i].Order = m.orders[existing]
+			m.mu.Unlock()
+			continue
+		}
+		order := newOrder(req)
+		m.orders[order.InternalID] = order
+		if req.IdempotencyKey != "" {
+			m.idempotencyMap[req.IdempotencyKey] = order.InternalID
+		}
+		m.noteInsertLocked()
+		pending := *order
+		m.mu.Unlock()
+		m.publishStateChange(pending, "", domain.OrderStatusPendingNew)
+
+		if _, err := m.gatewayFor(req.Venue, req.Account); err != nil {
+			m.failSubmit(order.InternalID, req.IdempotencyKey)
+			results[i].Err = err
+			continue
+		}
+		m.updateStatus(order.InternalID, domain.OrderStatusSubmitted)
+
+		results[i].Order = order
+		key := domain.VenueAccount{Venue: req.Venue, Account: req.Account}
+		if _, ok := byAccount[key]; !ok {
+			accounts = append(accounts, key)
+		}
+		byAccount[key] = append(byAccount[key], i)
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range accounts {
+		venue := key.Venue
+		idx := byAccount[key]
+		gw, _ := m.gatewayFor(key.Venue, key.Account)
+		batch := make([]domain.OrderRequest, len(idx))
+		for j, i := range idx {
+			batch[j] = reqs[i]
+		}
+
+		wg.Add(1)
+		go func(gw gateway.VenueGateway) {
+			defer wg.Done()
+			placed := gw.PlaceOrders(ctx, batch)
+			for j, i := range idx {
+				order := results[i].Order
+				if j >= len(placed) || placed[j].Err != nil {
+					err := fmt.Errorf("missing result from %s batch", venue)
+					if j < len(placed) {
+						err = placed[j].Err
+					}
+					if existing, ok := m.lookupDuplicate(ctx, gw, reqs[i], err); ok {
+						m.adopt(order, existing)
+						continue
+					}
+					m.failSubmit(order.InternalID, reqs[i].IdempotencyKey)
+					m.placeFailed(reqs[i], err)
+					results[i] = SubmitResult{Err: fmt.Errorf("place order: %w", err)}
+					continue
+				}
+				m.applyAck(order, placed[j].Ack)
+			}
+		}(gw)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func newOrder(req domain.OrderRequest) *domain.Order {
+	return &domain.Order{
+		InternalID:     req.InternalID,
+		SignalID:       req.SignalID,
+		Strategy:       req.Strategy,
+		Venue:          req.Venue,
+		Account:        req.Account,
+		Symbol:         req.Symbol,
+		InstrumentType: req.InstrumentType,
+		Side:           req.Side,
+		OrderType:      req.OrderType,
+		TimeInForce:    req.TimeInForce,
+		PostOnly:       req.PostOnly,
+		ReduceOnly:     req.ReduceOnly,
+		Price:          req.Price,
+		StopPrice:      req.StopPrice,
+		Size:           req.Size,
+		Status:         domain.OrderStatusPendingNew,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
+
+// applyAck records a successful placement. The order stream can get ahead
+// of the REST ack, and a fill or cancel it already applied stands.
+func (m *Manager) applyAck(order *domain.Order, ack *domain.OrderAck) {
 	m.mu.Lock()
 	order.VenueID = ack.VenueID
-	order.Status = ack.Status
 	order.UpdatedAt = time.Now()
 	m.venueIDMap[ack.VenueID] = order.InternalID
+	if order.Status != domain.OrderStatusSubmitted {
+		m.mu.Unlock()
+		return
+	}
+	order.Status = ack.Status
+	acked := *order
 	m.mu.Unlock()
 
-	m.publishStateChange(order, domain.OrderStatusSubmitted, ack.Status)
+	m.publishStateChange(acked, domain.OrderStatusSubmitted, ack.Status)
+}
 
-	return order, nil
+// lookupDuplicate finds the order behind a rejection of req for reusing its
+// client order ID. Idempotency keys are derived from the signal and leg, so
+// a leg retried after a restart can collide with the order its first attempt
+// left live at the venue; that order is the leg. ok is false if err is not
+// such a rejection or the venue cannot look the order up.
+func (m *Manager) lookupDuplicate(ctx context.Context, gw gateway.VenueGateway, req domain.OrderRequest, err error) (*domain.OrderUpdate, bool) {
+	if req.IdempotencyKey == "" || gateway.ErrorCategoryOf(err) != gateway.ErrorDuplicateOrder {
+		return nil, false
+	}
+	lookup, ok := gw.(gateway.ClientOrderLookup)
+	if !ok {
+		m.logger.Warn("duplicate client order ID, venue cannot look it up",
+			"venue", req.Venue, "symbol", req.Symbol, "client_order_id", req.IdempotencyKey)
+		return nil, false
+	}
+	existing, lerr := lookup.GetOrderByClientID(ctx, req.Symbol, req.IdempotencyKey)
+	if lerr != nil {
+		m.logger.Warn("duplicate client order ID, lookup failed",
+			"venue", req.Venue, "symbol", req.Symbol, "client_order_id", req.IdempotencyKey, "error", lerr)
+		return nil, false
+	}
+	m.logger.Warn("adopting live order behind duplicate client order ID",
+		"venue", req.Venue,
+		"symbol", req.Symbol,
+		"client_order_id", req.IdempotencyKey,
+		"venue_order_id", existing.VenueID,
+		"status", existing.Status,
+		"filled", existing.FilledSize.String(),
+	)
+	return existing, true
+}
+
+// adopt takes existing, the venue's view of an order placed earlier under
+// the same client order ID, as the ack for order and applies its fills and
+// status.
+func (m *Manager) adopt(order *domain.Order, existing *domain.OrderUpdate) {
+	m.applyAck(order, &domain.OrderAck{
+		InternalID: order.InternalID,
+		VenueID:    existing.VenueID,
+		Status:     domain.OrderStatusAcknowledged,
+		Timestamp:  existing.Timestamp,
+	})
+	update := *existing
+	update.Venue = order.Venue
+	m.HandleOrderUpdate(update)
+}
+
+// failSubmit marks an order SubmitFailed and frees its idempotency key.
+func (m *Manager) failSubmit(internalID uuid.UUID, idempotencyKey string) {
+	m.updateStatus(internalID, domain.OrderStatusSubmitFailed)
+	if idempotencyKey == "" {
+		return
+	}
+	m.mu.Lock()
+	if m.idempotencyMap[idempotencyKey] == internalID {
+		delete(m.idempotencyMap, idempotencyKey)
+	}
+	m.mu.Unlock()
+}
+
+// validateOrderFlags rejects TimeInForce/PostOnly combinations, reduce-only
+// spot orders and malformed stop orders that no venue accepts, before
+// anything is tracked or sent.
+func validateOrderFlags(req domain.OrderRequest) error {
+	if req.OrderType.IsStop() {
+		if !req.StopPrice.IsPositive() {
+			return fmt.Errorf("%s order requires a positive stop price", req.OrderType)
+		}
+		if req.OrderType == domain.OrderTypeStopLimit && !req.Price.IsPositive() {
+			return fmt.Errorf("stop-limit order requires a limit price")
+		}
+	} else if !req.StopPrice.IsZero() {
+		return fmt.Errorf("stop price set on %s order", req.OrderType)
+	}
+	switch req.TimeInForce {
+	case "", domain.TimeInForceGTC, domain.TimeInForceIOC, domain.TimeInForceFOK:
+	default:
+		return fmt.Errorf("unknown time in force %q", req.TimeInForce)
+	}
+	if req.PostOnly {
+		if req.OrderType != domain.OrderTypeLimit {
+			return fmt.Errorf("post-only requires a limit order")
+		}
+		if req.TimeInForce == domain.TimeInForceIOC || req.TimeInForce == domain.TimeInForceFOK {
+			return fmt.Errorf("post-only cannot be combined with %s", req.TimeInForce)
+		}
+	}
+	if req.ReduceOnly && req.InstrumentType != domain.InstrumentPerp {
+		return fmt.Errorf("reduce-only requires a perp order")
+	}
+	return nil
 }
 
 func (m *Manager) CancelOrder(ctx context.Context, internalID uuid.UUID) error {
@@ -107,22 +484,114 @@ func (m *Manager) CancelOrder(ctx context.Context, internalID uuid.UUID) error {
 	}
 	venueID := order.VenueID
 	venue := order.Venue
+	account := order.Account
 	m.mu.RUnlock()
 
-	gw, ok := m.gateways[venue]
+	gw, err := m.gatewayFor(venue, account)
+	if err != nil {
+		return err
+	}
+
+	_, err = gw.CancelOrder(ctx, venueID)
+	return m.finishCancel(ctx, gw, internalID, venue, venueID, err)
+}
+
+// carriedFill is a size filled at a notional (size × average price).
+type carriedFill struct {
+	size, notional decimal.Decimal
+}
+
+// AmendOrder changes the price and total size of a resting limit order. Only
+// acknowledged or partially filled orders can be amended, and the new size
+// must exceed what is already filled. On venues that cancel-replace, the
+// order keeps its internal ID and is re-keyed to the new venue ID, and what
+// it filled before the replacement is carried over to it.
+func (m *Manager) AmendOrder(ctx context.Context, internalID uuid.UUID, newPrice, newSize decimal.Decimal) error {
+	m.mu.RLock()
+	order, ok := m.orders[internalID]
 	if !ok {
-		return fmt.Errorf("unknown venue: %s", venue)
+		m.mu.RUnlock()
+		return fmt.Errorf("order not found: %s", internalID)
+	}
+	status := order.Status
+	orderType := order.OrderType
+	filled := order.FilledSize
+	venueID := order.VenueID
+	venue := order.Venue
+	account := order.Account
+	symbol := order.Symbol
+	side := order.Side
+	m.mu.RUnlock()
+
+	if inst, ok := m.instrument(venue, symbol); ok {
+		newPrice = inst.RoundPrice(newPrice, side)
+		newSize = inst.RoundSize(newSize)
+	}
+
+	if status != domain.OrderStatusAcknowledged && status != domain.OrderStatusPartialFill {
+		return fmt.Errorf("order %s cannot be amended in state %s", internalID, status)
+	}
+	if orderType != domain.OrderTypeLimit {
+		return fmt.Errorf("order %s is not a limit order", internalID)
+	}
+	if !newPrice.IsPositive() || newSize.LessThanOrEqual(filled) {
+		return fmt.Errorf("invalid amend for %s: price %s, size %s, filled %s", internalID, newPrice, newSize, filled)
 	}
 
-	_, err := gw.CancelOrder(ctx, venueID)
+	gw, err := m.gatewayFor(venue, account)
 	if err != nil {
-		return fmt.Errorf("cancel order: %w", err)
+		return err
+	}
+
+	ack, err := gw.AmendOrder(ctx, venueID, newPrice, newSize)
+	if err != nil {
+		// A failed cancel-replace can leave the original cancelled.
+		if ack != nil && ack.Status.IsTerminal() {
+			m.updateStatus(internalID, ack.Status)
+		}
+		return fmt.Errorf("amend order: %w", err)
 	}
 
-	m.updateStatus(internalID, domain.OrderStatusCancelled)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prevStatus := order.Status
+	m.carryFillLocked(order, ack)
+	if ack.VenueID != "" && ack.VenueID != order.VenueID {
+		m.fillBase[internalID] = carriedFill{size: order.FilledSize, notional: order.FilledSize.Mul(order.AvgFillPrice)}
+		delete(m.venueIDMap, order.VenueID)
+		order.VenueID = ack.VenueID
+		m.venueIDMap[ack.VenueID] = internalID
+	}
+	order.Price = newPrice
+	order.Size = newSize
+	// Fill progress is tracked by updates, so only a terminal ack status
+	// (e.g. the amended price crossed and filled) overrides ours.
+	if !prevStatus.IsTerminal() && ack.Status.IsTerminal() {
+		order.Status = ack.Status
+	}
+	order.UpdatedAt = time.Now()
+
+	m.publishStateChangeLocked(order, prevStatus, order.Status)
 	return nil
 }
 
+// carryFillLocked takes the fill the venue reported for the order it
+// amended, if that is ahead of the updates seen so far. The venue reports
+// it for its current order, which comes on top of the fill base.
+func (m *Manager) carryFillLocked(order *domain.Order, ack *domain.AmendAck) {
+	base := m.fillBase[order.InternalID]
+	total := base.size.Add(ack.FilledSize)
+	if !total.GreaterThan(order.FilledSize) {
+		return
+	}
+	order.FilledSize = total
+	order.AvgFillPrice = base.notional.Add(ack.FilledSize.Mul(ack.AvgFillPrice)).Div(total)
+	if order.Status == domain.OrderStatusAcknowledged {
+		order.Status = domain.OrderStatusPartialFill
+	}
+}
+
 func (m *Manager) CancelAllOrders(ctx context.Context) {
 	m.mu.RLock()
 	var activeOrders []uuid.UUID
@@ -133,12 +602,110 @@ func (m *Manager) CancelAllOrders(ctx context.Context) {
 	}
 	m.mu.RUnlock()
 
-	for _, id := range activeOrders {
-		if err := m.CancelOrder(ctx, id); err != nil {
-			m.logger.Error("failed to cancel order during kill switch",
-				"order_id", id, "error", err)
+	m.CancelOrders(ctx, activeOrders, "kill switch")
+}
+
+// CancelVenueOrders cancels every open order on venue, for example after the
+// trader lost contact with it and can no longer manage them. It returns an
+// error if any of them is still open afterwards.
+func (m *Manager) CancelVenueOrders(ctx context.Context, venue, reason string) error {
+	return m.cancelMatching(ctx, venue, reason, func(o *domain.Order) bool { return o.Venue == venue })
+}
+
+// CancelSymbolOrders cancels every open order in symbol on venue, for
+// example when the venue delists it. It returns an error if any of them is
+// still open afterwards.
+func (m *Manager) CancelSymbolOrders(ctx context.Context, venue, symbol, reason string) error {
+	return m.cancelMatching(ctx, venue+" "+symbol, reason, func(o *domain.Order) bool {
+		return o.Venue == venue && o.Symbol == symbol
+	})
+}
+
+// CancelStrategyOrders cancels every open order placed for strategy, on
+// venue if it is set, including resting passive quotes, for example when
+// the strategy is halted. It returns an error if any of them is still open
+// afterwards.
+func (m *Manager) CancelStrategyOrders(ctx context.Context, venue string, strategy domain.StrategyType, reason string) error {
+	scope := string(strategy)
+	if venue != "" {
+		scope = venue + " " + scope
+	}
+	return m.cancelMatching(ctx, scope, reason, func(o *domain.Order) bool {
+		return o.Strategy == strategy && (venue == "" || o.Venue == venue)
+	})
+}
+
+// cancelMatching cancels the open orders match selects; scope names them in
+// logs and errors.
+func (m *Manager) cancelMatching(ctx context.Context, scope, reason string, match func(*domain.Order) bool) error {
+	m.mu.RLock()
+	var ids []uuid.UUID
+	for id, order := range m.orders {
+		if match(order) && !order.Status.IsTerminal() {
+			ids = append(ids, id)
 		}
 	}
+	m.mu.RUnlock()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	m.logger.Warn("cancelling orders", "scope", scope, "count", len(ids), "reason", reason)
+	m.CancelOrders(ctx, ids, reason)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	open := 0
+	for _, id := range ids {
+		if order, ok := m.orders[id]; ok && !order.Status.IsTerminal() {
+			open++
+		}
+	}
+	if open > 0 {
+		return fmt.Errorf("%d of %d orders on %s still open", open, len(ids), scope)
+	}
+	return nil
+}
+
+// CancelOrders cancels the given orders with one CancelOrders call per account,
+// then settles each one as CancelOrder does, concurrently. Failures are
+// logged with reason and do not stop the others.
+func (m *Manager) CancelOrders(ctx context.Context, internalIDs []uuid.UUID, reason string) {
+	byAccount := make(map[domain.VenueAccount][]uuid.UUID)
+	venueIDsByAccount := make(map[domain.VenueAccount][]string)
+	m.mu.RLock()
+	for _, id := range internalIDs {
+		if order, ok := m.orders[id]; ok {
+			key := domain.VenueAccount{Venue: order.Venue, Account: order.Account}
+			byAccount[key] = append(byAccount[key], id)
+			venueIDsByAccount[key] = append(venueIDsByAccount[key], order.VenueID)
+		}
+	}
+	m.mu.RUnlock()
+
+	for key, ids := range byAccount {
+		venue := key.Venue
+		gw, err := m.gatewayFor(venue, key.Account)
+		if err != nil {
+			m.logger.Error("failed to cancel orders",
+				"venue", venue, "account", key.Account, "reason", reason, "error", err)
+			continue
+		}
+
+		venueIDs := venueIDsByAccount[key]
+		var wg sync.WaitGroup
+		for i, res := range gw.CancelOrders(ctx, venueIDs) {
+			wg.Add(1)
+			go func(id uuid.UUID, venueID string, cancelErr error) {
+				defer wg.Done()
+				if err := m.finishCancel(ctx, gw, id, venue, venueID, cancelErr); err != nil {
+					m.logger.Error("failed to cancel order",
+						"order_id", id, "reason", reason, "error", err)
+				}
+			}(ids[i], venueIDs[i], res.Err)
+		}
+		wg.Wait()
+	}
 }
 
 func (m *Manager) UpdateOrderFill(internalID uuid.UUID, filledSize, avgPrice decimal.Decimal) {
@@ -166,14 +733,134 @@ func (m *Manager) UpdateOrderFill(internalID uuid.UUID, filledSize, avgPrice dec
 	}
 }
 
+// HandleOrderUpdate applies a pushed order update from a venue's private
+// stream. The order is matched by venue order ID, falling back to the client
+// order ID for updates that arrive before the REST ack and to the archive for
+// spilled orders. Updates for orders already in a terminal state are ignored.
+func (m *Manager) HandleOrderUpdate(update domain.OrderUpdate) {
+	m.mu.Lock()
+	internalID, ok := m.venueIDMap[update.VenueID]
+	if !ok && update.ClientOrderID != "" {
+		internalID, ok = m.idempotencyMap[update.ClientOrderID]
+	}
+	if !ok && update.VenueID != "" && m.archive != nil {
+		m.mu.Unlock()
+		if !m.restoreArchived(update.Venue, update.VenueID) {
+			return
+		}
+		m.mu.Lock()
+		internalID, ok = m.venueIDMap[update.VenueID]
+	}
+	order, found := m.orders[internalID]
+	if !ok || !found || order.Venue != update.Venue {
+		m.mu.Unlock()
+		return
+	}
+	if order.VenueID == "" && update.VenueID != "" {
+		order.VenueID = update.VenueID
+		m.venueIDMap[update.VenueID] = internalID
+	}
+	terminal := order.Status.IsTerminal()
+	base, carried := m.fillBase[internalID]
+	// An update for a venue order an amend has since replaced is stale.
+	stale := carried && update.VenueID != "" && update.VenueID != order.VenueID
+	m.mu.Unlock()
+
+	if terminal || stale {
+		return
+	}
+
+	if update.FilledSize.IsPositive() {
+		filled, avgPrice := update.FilledSize, update.AvgFillPrice
+		if carried {
+			filled = base.size.Add(update.FilledSize)
+			avgPrice = base.notional.Add(update.FilledSize.Mul(update.AvgFillPrice)).Div(filled)
+		}
+		m.UpdateOrderFill(internalID, filled, avgPrice)
+	}
+
+	switch update.Status {
+	case domain.OrderStatusCancelled, domain.OrderStatusRejected:
+		if o, ok := m.GetOrder(internalID); ok && !o.Status.IsTerminal() {
+			m.updateStatus(internalID, update.Status)
+		}
+	case domain.OrderStatusFilled:
+		// A venue-reported fill is final even if rounding left
+		// FilledSize a hair below Size.
+		if o, ok := m.GetOrder(internalID); ok && o.Status != domain.OrderStatusFilled {
+			m.updateStatus(internalID, domain.OrderStatusFilled)
+		}
+	case domain.OrderStatusAcknowledged:
+		if o, ok := m.GetOrder(internalID); ok && o.Status == domain.OrderStatusSubmitted {
+			m.updateStatus(internalID, domain.OrderStatusAcknowledged)
+		}
+	}
+}
+
+// RunOrderUpdates subscribes to the private order stream of every gateway,
+// sub-accounts included, and feeds the updates into HandleOrderUpdate until
+// ctx is cancelled. The orders of venues without a stream are polled over
+// REST instead.
+func (m *Manager) RunOrderUpdates(ctx context.Context) {
+	streams := make(map[domain.VenueAccount]gateway.VenueGateway, len(m.gateways))
+	for name, gw := range m.gateways {
+		streams[domain.VenueAccount{Venue: name}] = gw
+	}
+	m.mu.RLock()
+	for key, gw := range m.accounts {
+		streams[key] = gw
+	}
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for key, gw := range streams {
+		name := key.Venue
+		ch, err := gw.SubscribeOrderUpdates(ctx)
+		if errors.Is(err, gateway.ErrOrderUpdatesUnsupported) {
+			m.logger.Info("order update stream not available, polling open orders", "venue", name, "account", key.Account)
+			wg.Add(1)
+			go func(key domain.VenueAccount, gw gateway.VenueGateway) {
+				defer wg.Done()
+				m.pollOrders(ctx, key, gw)
+			}(key, gw)
+			continue
+		}
+		if err != nil {
+			m.logger.Error("failed to subscribe to order updates", "venue", name, "account", key.Account, "error", err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, ch <-chan domain.OrderUpdate) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case update, ok := <-ch:
+					if !ok {
+						m.logger.Warn("order update stream closed", "venue", name)
+						return
+					}
+					m.HandleOrderUpdate(update)
+				}
+			}
+		}(name, ch)
+	}
+	wg.Wait()
+}
+
+// GetOrder returns a copy of an order, reading it back from the archive if
+// it has been spilled.
 func (m *Manager) GetOrder(internalID uuid.UUID) (*domain.Order, bool) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
 	order, ok := m.orders[internalID]
 	if !ok {
-		return nil, false
+		m.mu.RUnlock()
+		return m.loadArchived(internalID)
 	}
 	copy := *order
+	m.mu.RUnlock()
 	return &copy, true
 }
 
@@ -219,9 +906,11 @@ func (m *Manager) updateStatus(internalID uuid.UUID, newStatus domain.OrderStatu
 	m.publishStateChangeLocked(order, prevStatus, newStatus)
 }
 
-func (m *Manager) publishStateChange(order *domain.Order, prev, new domain.OrderStatus) {
+// publishStateChange publishes a change to order, a copy taken under m.mu:
+// the order itself can be updated by the order stream once m.mu is released.
+func (m *Manager) publishStateChange(order domain.Order, prev, new domain.OrderStatus) {
 	change := domain.OrderStateChange{
-		Order:      *order,
+		Order:      order,
 		PrevStatus: prev,
 		NewStatus:  new,
 		Timestamp:  time.Now(),
@@ -247,6 +936,7 @@ func (m *Manager) CleanupStaleOrders(maxAge time.Duration) {
 	for id, order := range m.orders {
 		if order.Status.IsTerminal() && order.UpdatedAt.Before(cutoff) {
 			delete(m.orders, id)
+			delete(m.fillBase, id)
 			if order.VenueID != "" {
 				delete(m.venueIDMap, order.VenueID)
 			}
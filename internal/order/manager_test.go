@@ -2,6 +2,8 @@ package order
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
@@ -18,12 +20,25 @@ import (
 type mockGateway struct {
 	placeErr  error
 	cancelErr error
+	amendErr  error
 	lastReq   domain.OrderRequest
+
+	// amendFilled is what AmendOrder reports the replaced order filled.
+	amendFilled decimal.Decimal
+
+	// failSymbol makes PlaceOrder fail for that symbol only.
+	failSymbol    string
+	placeBatches  [][]domain.OrderRequest
+	cancelBatches [][]string
+	cancelled     []string
 }
 
 func (m *mockGateway) Connect(_ context.Context) error { return nil }
 func (m *mockGateway) Close() error                    { return nil }
 func (m *mockGateway) Name() string                    { return "test" }
+func (m *mockGateway) Health(_ context.Context) domain.VenueHealth {
+	return domain.VenueHealth{}
+}
 func (m *mockGateway) SubscribeOrderBook(_ context.Context, _ string) (<-chan domain.OrderBookDelta, error) {
 	return nil, nil
 }
@@ -39,16 +54,35 @@ func (m *mockGateway) GetBalances(_ context.Context) (map[string]domain.Balance,
 func (m *mockGateway) GetPositions(_ context.Context) ([]domain.Position, error) {
 	return nil, nil
 }
-func (m *mockGateway) GetFeeTier(_ context.Context) (*domain.FeeTier, error) { return nil, nil }
+func (m *mockGateway) GetFeeTier(_ context.Context) (*domain.FeeTier, error)         { return nil, nil }
+func (m *mockGateway) GetInstruments(_ context.Context) ([]domain.Instrument, error) { return nil, nil }
+func (m *mockGateway) GetRateLimitStatus(_ context.Context) ([]domain.RateLimitStatus, error) {
+	return nil, nil
+}
+func (m *mockGateway) Withdraw(_ context.Context, _ domain.WithdrawRequest) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+func (m *mockGateway) GetDepositAddress(_ context.Context, _, _ string) (*domain.DepositAddress, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
+func (m *mockGateway) GetTransferStatus(_ context.Context, _ string) (*domain.Transfer, error) {
+	return nil, gateway.ErrTransfersUnsupported
+}
 func (m *mockGateway) GetOpenOrders(_ context.Context, _ string) ([]domain.Order, error) {
 	return nil, nil
 }
+func (m *mockGateway) SubscribeOrderUpdates(_ context.Context) (<-chan domain.OrderUpdate, error) {
+	return nil, gateway.ErrOrderUpdatesUnsupported
+}
 
 func (m *mockGateway) PlaceOrder(_ context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
 	m.lastReq = req
 	if m.placeErr != nil {
 		return nil, m.placeErr
 	}
+	if m.failSymbol != "" && req.Symbol == m.failSymbol {
+		return nil, fmt.Errorf("rejected %s", req.Symbol)
+	}
 	return &domain.OrderAck{
 		InternalID: req.InternalID,
 		VenueID:    "venue-" + req.InternalID.String()[:8],
@@ -58,6 +92,7 @@ func (m *mockGateway) PlaceOrder(_ context.Context, req domain.OrderRequest) (*d
 }
 
 func (m *mockGateway) CancelOrder(_ context.Context, orderID string) (*domain.CancelAck, error) {
+	m.cancelled = append(m.cancelled, orderID)
 	if m.cancelErr != nil {
 		return nil, m.cancelErr
 	}
@@ -67,6 +102,31 @@ func (m *mockGateway) CancelOrder(_ context.Context, orderID string) (*domain.Ca
 	}, nil
 }
 
+func (m *mockGateway) AmendOrder(_ context.Context, orderID string, newPrice, newSize decimal.Decimal) (*domain.AmendAck, error) {
+	if m.amendErr != nil {
+		return nil, m.amendErr
+	}
+	return &domain.AmendAck{
+		VenueID:      orderID + "-r",
+		Price:        newPrice,
+		Size:         newSize,
+		FilledSize:   m.amendFilled,
+		AvgFillPrice: newPrice,
+		Status:       domain.OrderStatusAcknowledged,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+func (m *mockGateway) PlaceOrders(ctx context.Context, reqs []domain.OrderRequest) []gateway.PlaceResult {
+	m.placeBatches = append(m.placeBatches, reqs)
+	return gateway.PlaceEach(ctx, reqs, m.PlaceOrder)
+}
+
+func (m *mockGateway) CancelOrders(ctx context.Context, orderIDs []string) []gateway.CancelResult {
+	m.cancelBatches = append(m.cancelBatches, orderIDs)
+	return gateway.CancelEach(ctx, orderIDs, m.CancelOrder)
+}
+
 var _ gateway.VenueGateway = (*mockGateway)(nil)
 
 func newTestManager() (*Manager, *mockGateway) {
@@ -138,6 +198,233 @@ func TestSubmitOrderIdempotency(t *testing.T) {
 	}
 }
 
+func TestSubmitOrderFlags(t *testing.T) {
+	mgr, mock := newTestManager()
+	ctx := context.Background()
+
+	base := domain.OrderRequest{
+		SignalID:  uuid.New(),
+		Venue:     "test",
+		Symbol:    "BTC/USDT",
+		Side:      domain.SideBuy,
+		OrderType: domain.OrderTypeLimit,
+		Price:     decimal.NewFromInt(50000),
+		Size:      decimal.NewFromFloat(0.1),
+	}
+
+	req := base
+	req.InternalID = NewOrderID()
+	req.TimeInForce = domain.TimeInForceIOC
+	order, err := mgr.SubmitOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order.TimeInForce != domain.TimeInForceIOC || mock.lastReq.TimeInForce != domain.TimeInForceIOC {
+		t.Errorf("expected IOC on order and gateway request, got %s/%s", order.TimeInForce, mock.lastReq.TimeInForce)
+	}
+
+	req = base
+	req.InternalID = NewOrderID()
+	req.Side = domain.SideSell
+	req.OrderType = domain.OrderTypeStopMarket
+	req.Price = decimal.Zero
+	req.StopPrice = decimal.NewFromInt(48000)
+	order, err = mgr.SubmitOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error for stop order: %v", err)
+	}
+	if !order.StopPrice.Equal(req.StopPrice) || !mock.lastReq.StopPrice.Equal(req.StopPrice) {
+		t.Errorf("expected stop price 48000 on order and gateway request, got %s/%s", order.StopPrice, mock.lastReq.StopPrice)
+	}
+
+	req = base
+	req.InternalID = NewOrderID()
+	req.Symbol = "BTCUSDT"
+	req.InstrumentType = domain.InstrumentPerp
+	req.ReduceOnly = true
+	order, err = mgr.SubmitOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error for reduce-only perp order: %v", err)
+	}
+	if !order.ReduceOnly || !mock.lastReq.ReduceOnly {
+		t.Error("expected reduce-only on order and gateway request")
+	}
+
+	invalid := []func(r *domain.OrderRequest){
+		func(r *domain.OrderRequest) { r.TimeInForce = "GTD" },
+		func(r *domain.OrderRequest) { r.PostOnly = true; r.OrderType = domain.OrderTypeMarket },
+		func(r *domain.OrderRequest) { r.PostOnly = true; r.TimeInForce = domain.TimeInForceIOC },
+		func(r *domain.OrderRequest) { r.ReduceOnly = true },
+		func(r *domain.OrderRequest) { r.StopPrice = decimal.NewFromInt(49000) },
+		func(r *domain.OrderRequest) { r.OrderType = domain.OrderTypeStopMarket },
+		func(r *domain.OrderRequest) {
+			r.OrderType = domain.OrderTypeStopLimit
+			r.StopPrice = decimal.NewFromInt(49000)
+			r.Price = decimal.Zero
+		},
+	}
+	for i, mutate := range invalid {
+		req := base
+		req.InternalID = NewOrderID()
+		mutate(&req)
+		if _, err := mgr.SubmitOrder(ctx, req); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+		if _, ok := mgr.GetOrder(req.InternalID); ok {
+			t.Errorf("case %d: invalid order should not be tracked", i)
+		}
+	}
+}
+
+func TestSubmitOrderConformsToInstrument(t *testing.T) {
+	mgr, mock := newTestManager()
+	reg := domain.NewInstrumentRegistry()
+	reg.Set("test", []domain.Instrument{{
+		Venue:       "test",
+		Symbol:      "BTC/USDT",
+		TickSize:    decimal.RequireFromString("0.1"),
+		StepSize:    decimal.RequireFromString("0.001"),
+		MinSize:     decimal.RequireFromString("0.001"),
+		MinNotional: decimal.NewFromInt(5),
+	}})
+	mgr.SetInstruments(reg)
+	ctx := context.Background()
+
+	req := domain.OrderRequest{
+		InternalID: NewOrderID(),
+		Venue:      "test",
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideBuy,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.RequireFromString("50000.17"),
+		Size:       decimal.RequireFromString("0.1234"),
+	}
+	order, err := mgr.SubmitOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mock.lastReq.Price.Equal(decimal.RequireFromString("50000.1")) || !mock.lastReq.Size.Equal(decimal.RequireFromString("0.123")) {
+		t.Errorf("expected venue to get 50000.1 x 0.123, got %s x %s", mock.lastReq.Price, mock.lastReq.Size)
+	}
+	if !order.Size.Equal(mock.lastReq.Size) {
+		t.Errorf("expected tracked order size %s, got %s", mock.lastReq.Size, order.Size)
+	}
+
+	req.InternalID = NewOrderID()
+	req.Size = decimal.RequireFromString("0.00005")
+	if _, err := mgr.SubmitOrder(ctx, req); !errors.Is(err, domain.ErrBelowMinSize) {
+		t.Errorf("expected ErrBelowMinSize, got %v", err)
+	}
+	if _, ok := mgr.GetOrder(req.InternalID); ok {
+		t.Error("expected a rejected order not to be tracked")
+	}
+}
+
+func TestSubmitOrderPreTradeCheck(t *testing.T) {
+	mgr, mock := newTestManager()
+	refused := errors.New("refused")
+	mgr.SetPreTradeCheck(func(req domain.OrderRequest) error {
+		if req.Side == domain.SideSell {
+			return refused
+		}
+		return nil
+	})
+	ctx := context.Background()
+
+	req := domain.OrderRequest{
+		InternalID: NewOrderID(),
+		Venue:      "test",
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideSell,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(50000),
+		Size:       decimal.NewFromFloat(0.1),
+	}
+	if _, err := mgr.SubmitOrder(ctx, req); !errors.Is(err, refused) {
+		t.Fatalf("expected the check's error, got %v", err)
+	}
+	if mock.lastReq.InternalID == req.InternalID {
+		t.Error("expected a refused order not to reach the venue")
+	}
+	if _, ok := mgr.GetOrder(req.InternalID); ok {
+		t.Error("expected a refused order not to be tracked")
+	}
+
+	buy := req
+	buy.InternalID, buy.Side = NewOrderID(), domain.SideBuy
+	req.InternalID = NewOrderID()
+	results := mgr.SubmitOrders(ctx, []domain.OrderRequest{buy, req})
+	if results[0].Err != nil {
+		t.Errorf("expected the buy placed, got %v", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, refused) {
+		t.Errorf("expected the sell refused in a batch, got %v", results[1].Err)
+	}
+}
+
+func TestSubmitOrders(t *testing.T) {
+	mgr, mock := newTestManager()
+	ctx := context.Background()
+	mock.failSymbol = "BTCUSDT"
+
+	leg := func(symbol, key string) domain.OrderRequest {
+		return domain.OrderRequest{
+			InternalID:     NewOrderID(),
+			Venue:          "test",
+			Symbol:         symbol,
+			Side:           domain.SideBuy,
+			OrderType:      domain.OrderTypeLimit,
+			Price:          decimal.NewFromInt(50000),
+			Size:           decimal.NewFromFloat(0.1),
+			IdempotencyKey: key,
+		}
+	}
+	reqs := []domain.OrderRequest{
+		leg("BTC/USDT", "batch-0"),
+		leg("BTCUSDT", "batch-1"),
+		{InternalID: NewOrderID(), Venue: "nonexistent", Symbol: "BTC/USDT"},
+	}
+
+	results := mgr.SubmitOrders(ctx, reqs)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if len(mock.placeBatches) != 1 || len(mock.placeBatches[0]) != 2 {
+		t.Fatalf("expected one batch of 2, got %v", mock.placeBatches)
+	}
+
+	if results[0].Err != nil || results[0].Order.Status != domain.OrderStatusAcknowledged {
+		t.Errorf("leg 0: got %+v", results[0])
+	}
+	if results[1].Err == nil || results[1].Order != nil {
+		t.Errorf("leg 1: expected failure, got %+v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Error("leg 2: expected unknown venue error")
+	}
+	if failed, _ := mgr.GetOrder(reqs[1].InternalID); failed.Status != domain.OrderStatusSubmitFailed {
+		t.Errorf("failed leg status: got %s", failed.Status)
+	}
+
+	// The failed leg's key is released, so a retry is sent rather than
+	// answered with the failed order.
+	mock.failSymbol = ""
+	retry := reqs[1]
+	retry.InternalID = NewOrderID()
+	order, err := mgr.SubmitOrder(ctx, retry)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if order.InternalID != retry.InternalID || order.Status != domain.OrderStatusAcknowledged {
+		t.Errorf("retry: got %+v", order)
+	}
+
+	mgr.CancelAllOrders(ctx)
+	if len(mock.cancelBatches) != 1 || len(mock.cancelBatches[0]) != 2 {
+		t.Errorf("expected one cancel batch of 2, got %v", mock.cancelBatches)
+	}
+}
+
 func TestSubmitOrderUnknownVenue(t *testing.T) {
 	mgr, _ := newTestManager()
 	ctx := context.Background()
@@ -155,6 +442,67 @@ func TestSubmitOrderUnknownVenue(t *testing.T) {
 	}
 }
 
+func TestSubmitOrderRoutesStrategyToAccount(t *testing.T) {
+	mgr, main := newTestManager()
+	basis := &mockGateway{}
+	mgr.SetAccounts(
+		map[domain.VenueAccount]gateway.VenueGateway{{Venue: "test", Account: "basis"}: basis},
+		func(venue string, strategy domain.StrategyType) string {
+			return map[domain.StrategyType]string{
+				domain.StrategyBasisArb:      "basis",
+				domain.StrategyCrossVenueArb: "missing",
+			}[strategy]
+		})
+	ctx := context.Background()
+
+	req := func(strategy domain.StrategyType) domain.OrderRequest {
+		return domain.OrderRequest{
+			InternalID: NewOrderID(),
+			Strategy:   strategy,
+			Venue:      "test",
+			Symbol:     "BTC/USDT",
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(50000),
+			Size:       decimal.NewFromFloat(0.1),
+		}
+	}
+
+	ord, err := mgr.SubmitOrder(ctx, req(domain.StrategyBasisArb))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ord.Account != "basis" || ord.Strategy != domain.StrategyBasisArb {
+		t.Errorf("order account/strategy = %q/%s, want basis/%s", ord.Account, ord.Strategy, domain.StrategyBasisArb)
+	}
+	if basis.lastReq.InternalID != ord.InternalID || main.lastReq.InternalID == ord.InternalID {
+		t.Error("basis-arb order should go to the basis account gateway only")
+	}
+
+	if err := mgr.CancelOrder(ctx, ord.InternalID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if len(basis.cancelled) != 1 || len(main.cancelled) != 0 {
+		t.Errorf("cancels basis/main = %d/%d, want 1/0", len(basis.cancelled), len(main.cancelled))
+	}
+
+	tri, err := mgr.SubmitOrder(ctx, req(domain.StrategyTriArb))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tri.Account != "" || main.lastReq.InternalID != tri.InternalID {
+		t.Error("tri-arb order should go to the default gateway")
+	}
+
+	// An account without a gateway must not fall back to the default one.
+	if _, err := mgr.SubmitOrder(ctx, req(domain.StrategyCrossVenueArb)); err == nil {
+		t.Error("expected error for an account without a gateway")
+	}
+	if main.lastReq.InternalID != tri.InternalID {
+		t.Error("order for a missing account reached the default gateway")
+	}
+}
+
 func TestGetOrder(t *testing.T) {
 	mgr, _ := newTestManager()
 	ctx := context.Background()
@@ -218,6 +566,186 @@ func TestUpdateOrderFill(t *testing.T) {
 	}
 }
 
+func TestAmendOrder(t *testing.T) {
+	mgr, mock := newTestManager()
+	ctx := context.Background()
+
+	id := NewOrderID()
+	req := domain.OrderRequest{
+		InternalID: id,
+		SignalID:   uuid.New(),
+		Venue:      "test",
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideBuy,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(50000),
+		Size:       decimal.NewFromFloat(1),
+	}
+	submitted, _ := mgr.SubmitOrder(ctx, req)
+	oldVenueID := submitted.VenueID
+
+	if err := mgr.AmendOrder(ctx, id, decimal.NewFromInt(50100), decimal.NewFromFloat(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order, _ := mgr.GetOrder(id)
+	if !order.Price.Equal(decimal.NewFromInt(50100)) || !order.Size.Equal(decimal.NewFromFloat(2)) {
+		t.Errorf("expected amended price/size, got %s/%s", order.Price, order.Size)
+	}
+	if order.VenueID != oldVenueID+"-r" {
+		t.Errorf("expected venue ID re-keyed, got %s", order.VenueID)
+	}
+
+	// Fills reported against the replacement ID still resolve.
+	mgr.HandleOrderUpdate(domain.OrderUpdate{Venue: "test", VenueID: oldVenueID + "-r", Status: domain.OrderStatusPartialFill, FilledSize: decimal.NewFromFloat(0.5)})
+	order, _ = mgr.GetOrder(id)
+	if order.Status != domain.OrderStatusPartialFill {
+		t.Errorf("expected partial fill via new venue ID, got %s", order.Status)
+	}
+
+	if err := mgr.AmendOrder(ctx, id, decimal.NewFromInt(50100), decimal.NewFromFloat(0.5)); err == nil {
+		t.Error("expected error amending size to the filled amount")
+	}
+
+	mock.amendErr = fmt.Errorf("venue down")
+	if err := mgr.AmendOrder(ctx, id, decimal.NewFromInt(50200), decimal.NewFromFloat(2)); err == nil {
+		t.Error("expected gateway error to propagate")
+	}
+
+	mgr.CancelOrder(ctx, id)
+	if err := mgr.AmendOrder(ctx, id, decimal.NewFromInt(50200), decimal.NewFromFloat(2)); err == nil {
+		t.Error("expected error amending a cancelled order")
+	}
+}
+
+func TestAmendOrderCarriesFillAcrossReplace(t *testing.T) {
+	mgr, mock := newTestManager()
+	ctx := context.Background()
+
+	id := NewOrderID()
+	submitted, _ := mgr.SubmitOrder(ctx, domain.OrderRequest{
+		InternalID: id,
+		SignalID:   uuid.New(),
+		Venue:      "test",
+		Symbol:     "BTC/USDT",
+		Side:       domain.SideBuy,
+		OrderType:  domain.OrderTypeLimit,
+		Price:      decimal.NewFromInt(50000),
+		Size:       decimal.NewFromInt(1),
+	})
+	oldVenueID := submitted.VenueID
+	mgr.HandleOrderUpdate(domain.OrderUpdate{Venue: "test", VenueID: oldVenueID, Status: domain.OrderStatusPartialFill,
+		FilledSize: decimal.NewFromFloat(0.2), AvgFillPrice: decimal.NewFromInt(50000)})
+
+	// The venue saw 0.3 filled by the time the original was cancelled.
+	mock.amendFilled = decimal.NewFromFloat(0.3)
+	if err := mgr.AmendOrder(ctx, id, decimal.NewFromInt(50000), decimal.NewFromInt(1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order, _ := mgr.GetOrder(id)
+	if !order.FilledSize.Equal(decimal.NewFromFloat(0.3)) {
+		t.Errorf("expected the replaced order's 0.3 carried over, got %s", order.FilledSize)
+	}
+
+	// The replacement's fills count from zero and add to what was carried.
+	mgr.HandleOrderUpdate(domain.OrderUpdate{Venue: "test", VenueID: oldVenueID + "-r", Status: domain.OrderStatusPartialFill,
+		FilledSize: decimal.NewFromFloat(0.5), AvgFillPrice: decimal.NewFromInt(50100)})
+	order, _ = mgr.GetOrder(id)
+	if !order.FilledSize.Equal(decimal.NewFromFloat(0.8)) || order.Status != domain.OrderStatusPartialFill {
+		t.Errorf("expected 0.8 filled in total, got %s %s", order.FilledSize, order.Status)
+	}
+	// (0.3 × 50000 + 0.5 × 50100) / 0.8 = 50062.5
+	if !order.AvgFillPrice.Equal(decimal.RequireFromString("50062.5")) {
+		t.Errorf("expected average price 50062.5, got %s", order.AvgFillPrice)
+	}
+
+	// A late update for the replaced order is not applied on top.
+	mgr.HandleOrderUpdate(domain.OrderUpdate{Venue: "test", VenueID: oldVenueID, Status: domain.OrderStatusCancelled,
+		FilledSize: decimal.NewFromFloat(0.3), AvgFillPrice: decimal.NewFromInt(50000)})
+	order, _ = mgr.GetOrder(id)
+	if !order.FilledSize.Equal(decimal.NewFromFloat(0.8)) || order.Status != domain.OrderStatusPartialFill {
+		t.Errorf("expected the stale update ignored, got %s %s", order.FilledSize, order.Status)
+	}
+
+	mgr.HandleOrderUpdate(domain.OrderUpdate{Venue: "test", VenueID: oldVenueID + "-r", Status: domain.OrderStatusFilled,
+		FilledSize: decimal.NewFromFloat(0.7), AvgFillPrice: decimal.NewFromInt(50100)})
+	order, _ = mgr.GetOrder(id)
+	if !order.FilledSize.Equal(decimal.NewFromInt(1)) || order.Status != domain.OrderStatusFilled {
+		t.Errorf("expected the order filled, got %s %s", order.FilledSize, order.Status)
+	}
+}
+
+func TestHandleOrderUpdate(t *testing.T) {
+	mgr, _ := newTestManager()
+	ctx := context.Background()
+
+	id := NewOrderID()
+	req := domain.OrderRequest{
+		InternalID:     id,
+		SignalID:       uuid.New(),
+		IdempotencyKey: "client-1",
+		Venue:          "test",
+		Symbol:         "BTC/USDT",
+		Side:           domain.SideBuy,
+		OrderType:      domain.OrderTypeLimit,
+		Price:          decimal.NewFromInt(50000),
+		Size:           decimal.NewFromFloat(1),
+	}
+	submitted, err := mgr.SubmitOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Matched by client order ID.
+	mgr.HandleOrderUpdate(domain.OrderUpdate{
+		Venue:         "test",
+		ClientOrderID: "client-1",
+		Status:        domain.OrderStatusPartialFill,
+		FilledSize:    decimal.NewFromFloat(0.4),
+		AvgFillPrice:  decimal.NewFromInt(50000),
+	})
+	order, _ := mgr.GetOrder(id)
+	if order.Status != domain.OrderStatusPartialFill || !order.FilledSize.Equal(decimal.NewFromFloat(0.4)) {
+		t.Errorf("expected partial fill of 0.4, got %s %s", order.Status, order.FilledSize)
+	}
+
+	// Updates for another venue are ignored.
+	mgr.HandleOrderUpdate(domain.OrderUpdate{
+		Venue:      "other",
+		VenueID:    submitted.VenueID,
+		Status:     domain.OrderStatusCancelled,
+		FilledSize: decimal.NewFromFloat(0.4),
+	})
+	order, _ = mgr.GetOrder(id)
+	if order.Status != domain.OrderStatusPartialFill {
+		t.Errorf("expected other-venue update to be ignored, got %s", order.Status)
+	}
+
+	// Cancel with a remaining partial fill keeps the filled size.
+	mgr.HandleOrderUpdate(domain.OrderUpdate{
+		Venue:        "test",
+		VenueID:      submitted.VenueID,
+		Status:       domain.OrderStatusCancelled,
+		FilledSize:   decimal.NewFromFloat(0.6),
+		AvgFillPrice: decimal.NewFromInt(50010),
+	})
+	order, _ = mgr.GetOrder(id)
+	if order.Status != domain.OrderStatusCancelled || !order.FilledSize.Equal(decimal.NewFromFloat(0.6)) {
+		t.Errorf("expected cancelled with 0.6 filled, got %s %s", order.Status, order.FilledSize)
+	}
+
+	// Late updates after a terminal state are dropped.
+	mgr.HandleOrderUpdate(domain.OrderUpdate{
+		Venue:      "test",
+		VenueID:    submitted.VenueID,
+		Status:     domain.OrderStatusFilled,
+		FilledSize: decimal.NewFromFloat(1),
+	})
+	order, _ = mgr.GetOrder(id)
+	if order.Status != domain.OrderStatusCancelled {
+		t.Errorf("expected terminal status to stick, got %s", order.Status)
+	}
+}
+
 func TestGetActiveOrders(t *testing.T) {
 	mgr, _ := newTestManager()
 	ctx := context.Background()
@@ -242,6 +770,205 @@ func TestGetActiveOrders(t *testing.T) {
 	}
 }
 
+func TestCancelVenueOrders(t *testing.T) {
+	mgr, mock := newTestManager()
+	ctx := context.Background()
+
+	submit := func() {
+		t.Helper()
+		_, err := mgr.SubmitOrder(ctx, domain.OrderRequest{
+			InternalID: NewOrderID(),
+			SignalID:   uuid.New(),
+			Venue:      "test",
+			Symbol:     "BTC/USDT",
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(50000),
+			Size:       decimal.NewFromFloat(0.1),
+		})
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	submit()
+	submit()
+
+	if err := mgr.CancelVenueOrders(ctx, "other", "venue disconnected"); err != nil || len(mock.cancelBatches) != 0 {
+		t.Fatalf("expected nothing to cancel on another venue, got err=%v batches=%v", err, mock.cancelBatches)
+	}
+	if err := mgr.CancelVenueOrders(ctx, "test", "venue disconnected"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.cancelBatches) != 1 || len(mock.cancelBatches[0]) != 2 {
+		t.Errorf("expected one batch of 2 cancels, got %v", mock.cancelBatches)
+	}
+	if active := mgr.GetActiveOrders(); len(active) != 0 {
+		t.Errorf("expected no active orders, got %d", len(active))
+	}
+
+	submit()
+	mock.cancelErr = errors.New("venue unavailable")
+	if err := mgr.CancelVenueOrders(ctx, "test", "venue disconnected"); err == nil {
+		t.Error("expected an error while the order stays open")
+	}
+}
+
+func TestCancelStrategyOrders(t *testing.T) {
+	mgr, mock := newTestManager()
+	ctx := context.Background()
+
+	submit := func(strategy domain.StrategyType) {
+		t.Helper()
+		_, err := mgr.SubmitOrder(ctx, domain.OrderRequest{
+			InternalID: NewOrderID(),
+			SignalID:   uuid.New(),
+			Strategy:   strategy,
+			Venue:      "test",
+			Symbol:     "BTC/USDT",
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(50000),
+			Size:       decimal.NewFromFloat(0.1),
+		})
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	// Each order has its own signal, as a resting passive quote outlives
+	// the signal that placed it; only the strategy tag ties them together.
+	submit(domain.StrategyBasisArb)
+	submit(domain.StrategyBasisArb)
+	submit(domain.StrategyTriArb)
+
+	if err := mgr.CancelStrategyOrders(ctx, "other", domain.StrategyBasisArb, "kill switch"); err != nil || len(mock.cancelBatches) != 0 {
+		t.Fatalf("expected nothing to cancel on another venue, got err=%v batches=%v", err, mock.cancelBatches)
+	}
+	if err := mgr.CancelStrategyOrders(ctx, "", domain.StrategyBasisArb, "kill switch"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.cancelBatches) != 1 || len(mock.cancelBatches[0]) != 2 {
+		t.Errorf("expected one batch of 2 cancels, got %v", mock.cancelBatches)
+	}
+	if active := mgr.GetActiveOrders(); len(active) != 1 || active[0].Strategy != domain.StrategyTriArb {
+		t.Errorf("expected only the tri arb order left open, got %d", len(active))
+	}
+}
+
+func TestCancelSymbolOrdersAndUnavailableCallback(t *testing.T) {
+	mgr, mock := newTestManager()
+	ctx := context.Background()
+
+	var unavailable []string
+	mgr.SetSymbolUnavailableCallback(func(venue, symbol string, err error) {
+		unavailable = append(unavailable, venue+":"+symbol)
+	})
+
+	submit := func(symbol string) error {
+		_, err := mgr.SubmitOrder(ctx, domain.OrderRequest{
+			InternalID: NewOrderID(),
+			SignalID:   uuid.New(),
+			Venue:      "test",
+			Symbol:     symbol,
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(100),
+			Size:       decimal.NewFromFloat(0.1),
+		})
+		return err
+	}
+	if err := submit("BTC/USDT"); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := submit("ETH/BTC"); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	if err := mgr.CancelSymbolOrders(ctx, "test", "ETH/BTC", "symbol delisted"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.cancelBatches) != 1 || len(mock.cancelBatches[0]) != 1 {
+		t.Errorf("expected only the ETH/BTC order cancelled, got %v", mock.cancelBatches)
+	}
+	if active := mgr.GetActiveOrders(); len(active) != 1 || active[0].Symbol != "BTC/USDT" {
+		t.Errorf("expected the BTC/USDT order left open, got %+v", active)
+	}
+
+	mock.placeErr = errors.New("insufficient balance")
+	submit("BTC/USDT")
+	mock.placeErr = fmt.Errorf("%w: code=51001", gateway.ErrSymbolUnavailable)
+	submit("ETH/BTC")
+	if len(unavailable) != 1 || unavailable[0] != "test:ETH/BTC" {
+		t.Errorf("expected only the unavailable symbol reported, got %v", unavailable)
+	}
+}
+
+// clientLookupGateway looks orders up by client order ID in orders.
+type clientLookupGateway struct {
+	mockGateway
+	orders map[string]domain.OrderUpdate
+}
+
+func (g *clientLookupGateway) GetOrderByClientID(_ context.Context, _, clientOrderID string) (*domain.OrderUpdate, error) {
+	update, ok := g.orders[clientOrderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", clientOrderID)
+	}
+	return &update, nil
+}
+
+func TestSubmitOrderAdoptsDuplicateClientOrderID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	gw := &clientLookupGateway{orders: map[string]domain.OrderUpdate{
+		"sig-leg-0": {
+			Venue:         "kcex",
+			VenueID:       "venue-live",
+			ClientOrderID: "sig-leg-0",
+			Status:        domain.OrderStatusPartialFill,
+			FilledSize:    decimal.NewFromFloat(0.04),
+			AvgFillPrice:  decimal.NewFromInt(50000),
+		},
+	}}
+	gw.placeErr = &gateway.VenueError{Venue: "test", Op: "order rejected", Code: "400100",
+		Message: "clientOid duplicated", Category: gateway.ErrorDuplicateOrder}
+	mgr := NewManager(map[string]gateway.VenueGateway{"test": gw}, eventbus.New(64, logger), logger)
+	ctx := context.Background()
+
+	req := func(key string) domain.OrderRequest {
+		return domain.OrderRequest{
+			InternalID:     NewOrderID(),
+			SignalID:       uuid.New(),
+			Venue:          "test",
+			Symbol:         "BTC/USDT",
+			Side:           domain.SideBuy,
+			OrderType:      domain.OrderTypeLimit,
+			Price:          decimal.NewFromInt(50000),
+			Size:           decimal.NewFromFloat(0.1),
+			IdempotencyKey: key,
+		}
+	}
+
+	order, err := mgr.SubmitOrder(ctx, req("sig-leg-0"))
+	if err != nil {
+		t.Fatalf("expected the live order adopted, got %v", err)
+	}
+	if order.VenueID != "venue-live" || order.Status != domain.OrderStatusPartialFill ||
+		!order.FilledSize.Equal(decimal.NewFromFloat(0.04)) {
+		t.Errorf("adopted order: venue ID %s, status %s, filled %s; want venue-live, %s, 0.04",
+			order.VenueID, order.Status, order.FilledSize, domain.OrderStatusPartialFill)
+	}
+
+	results := mgr.SubmitOrders(ctx, []domain.OrderRequest{req("sig-leg-1")})
+	if results[0].Err == nil {
+		t.Error("expected a duplicate the venue cannot find to fail")
+	}
+
+	mgr, mock := newTestManager()
+	mock.placeErr = gw.placeErr
+	if _, err := mgr.SubmitOrder(ctx, req("sig-leg-0")); err == nil {
+		t.Error("expected a duplicate on a venue without lookups to fail")
+	}
+}
+
 func TestCleanupStaleOrders(t *testing.T) {
 	mgr, _ := newTestManager()
 	ctx := context.Background()
@@ -0,0 +1,87 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// pollOrders stands in for the order update stream of a venue account that
+// has none, polling its open orders every pollInterval until ctx is
+// cancelled.
+func (m *Manager) pollOrders(ctx context.Context, acct domain.VenueAccount, gw gateway.VenueGateway) {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.pollOpenOrders(ctx, acct, gw)
+		}
+	}
+}
+
+// pollOpenOrders fetches the venue's open orders in every symbol the
+// account has live orders in and applies them through HandleOrderUpdate.
+// An order the venue no longer lists open has filled or been cancelled; it
+// is looked up by ID when the gateway can, and otherwise left to the next
+// reconciliation.
+func (m *Manager) pollOpenOrders(ctx context.Context, acct domain.VenueAccount, gw gateway.VenueGateway) {
+	live := make(map[string][]string) // symbol → venue IDs
+	m.mu.RLock()
+	for _, o := range m.orders {
+		if o.Venue == acct.Venue && o.Account == acct.Account && o.VenueID != "" && !o.Status.IsTerminal() {
+			live[o.Symbol] = append(live[o.Symbol], o.VenueID)
+		}
+	}
+	m.mu.RUnlock()
+
+	provider, canLookUp := gw.(gateway.OrderStatusProvider)
+	for symbol, venueIDs := range live {
+		open, err := gw.GetOpenOrders(ctx, symbol)
+		if err != nil {
+			m.logger.Warn("failed to poll open orders",
+				"venue", acct.Venue, "account", acct.Account, "symbol", symbol, "error", err)
+			continue
+		}
+
+		listed := make(map[string]bool, len(open))
+		for _, o := range open {
+			listed[o.VenueID] = true
+			m.HandleOrderUpdate(domain.OrderUpdate{
+				Venue:        acct.Venue,
+				VenueID:      o.VenueID,
+				Status:       o.Status,
+				FilledSize:   o.FilledSize,
+				AvgFillPrice: o.AvgFillPrice,
+				Timestamp:    time.Now(),
+			})
+		}
+
+		if !canLookUp {
+			continue
+		}
+		for _, venueID := range venueIDs {
+			if listed[venueID] {
+				continue
+			}
+			update, err := provider.GetOrderStatus(ctx, venueID)
+			if errors.Is(err, gateway.ErrOrderStatusUnsupported) {
+				break
+			}
+			if err != nil {
+				m.logger.Warn("failed to poll order status",
+					"venue", acct.Venue, "account", acct.Account, "venue_id", venueID, "error", err)
+				continue
+			}
+			update.Venue = acct.Venue
+			update.VenueID = venueID
+			m.HandleOrderUpdate(*update)
+		}
+	}
+}
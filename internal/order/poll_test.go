@@ -0,0 +1,120 @@
+package order
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// pollGateway has no order update stream; it lists open orders and answers
+// order lookups from fixed replies.
+type pollGateway struct {
+	statusGateway
+	mu   sync.Mutex
+	open []domain.Order
+}
+
+// PlaceOrder gives each order a venue ID of its own; the mock's short IDs
+// collide for orders placed in the same millisecond.
+func (g *pollGateway) PlaceOrder(ctx context.Context, req domain.OrderRequest) (*domain.OrderAck, error) {
+	ack, err := g.mockGateway.PlaceOrder(ctx, req)
+	if ack != nil {
+		ack.VenueID = "venue-" + req.InternalID.String()
+	}
+	return ack, err
+}
+
+func (g *pollGateway) GetOpenOrders(_ context.Context, _ string) ([]domain.Order, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]domain.Order(nil), g.open...), nil
+}
+
+func newPollTestManager(t *testing.T) (*Manager, *pollGateway, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	gw := &pollGateway{statusGateway: statusGateway{replies: []domain.OrderUpdate{{
+		Status:       domain.OrderStatusFilled,
+		FilledSize:   decimal.NewFromInt(1),
+		AvgFillPrice: decimal.NewFromInt(49990),
+	}}}}
+	mgr := NewManager(map[string]gateway.VenueGateway{"test": gw}, eventbus.New(64, logger), logger)
+
+	submit := func() uuid.UUID {
+		ord, err := mgr.SubmitOrder(context.Background(), domain.OrderRequest{
+			InternalID: NewOrderID(),
+			SignalID:   uuid.New(),
+			Venue:      "test",
+			Symbol:     "BTC/USDT",
+			Side:       domain.SideBuy,
+			OrderType:  domain.OrderTypeLimit,
+			Price:      decimal.NewFromInt(50000),
+			Size:       decimal.NewFromInt(1),
+		})
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return ord.InternalID
+	}
+	return mgr, gw, submit(), submit()
+}
+
+func TestPollOpenOrders(t *testing.T) {
+	mgr, gw, resting, gone := newPollTestManager(t)
+	restingOrder, _ := mgr.GetOrder(resting)
+	gw.open = []domain.Order{{
+		VenueID:      restingOrder.VenueID,
+		Status:       domain.OrderStatusPartialFill,
+		FilledSize:   decimal.RequireFromString("0.4"),
+		AvgFillPrice: decimal.NewFromInt(50000),
+	}}
+
+	mgr.pollOpenOrders(context.Background(), domain.VenueAccount{Venue: "test"}, gw)
+
+	order, _ := mgr.GetOrder(resting)
+	if order.Status != domain.OrderStatusPartialFill || !order.FilledSize.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("listed order: got %s filled %s, want a 0.4 partial fill", order.Status, order.FilledSize)
+	}
+	order, _ = mgr.GetOrder(gone)
+	if order.Status != domain.OrderStatusFilled || !order.AvgFillPrice.Equal(decimal.NewFromInt(49990)) {
+		t.Errorf("unlisted order: got %s at %s, want filled at 49990", order.Status, order.AvgFillPrice)
+	}
+	if gw.lookups != 1 {
+		t.Errorf("expected only the unlisted order to be looked up, got %d lookups", gw.lookups)
+	}
+}
+
+func TestRunOrderUpdatesPollsVenuesWithoutStream(t *testing.T) {
+	mgr, _, _, gone := newPollTestManager(t)
+	mgr.pollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		mgr.RunOrderUpdates(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if order, _ := mgr.GetOrder(gone); order.Status == domain.OrderStatusFilled {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("order never settled by polling")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,183 @@
+package order
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/persistence"
+)
+
+// OrderArchive stores terminal orders moved out of memory.
+type OrderArchive interface {
+	ArchiveOrders(orders []persistence.ArchivedOrder) error
+	LoadArchivedOrder(id uuid.UUID) (*persistence.ArchivedOrder, error)
+	LoadArchivedOrderByVenueID(venue, venueID string) (*persistence.ArchivedOrder, error)
+}
+
+// SetArchive caps the orders held in memory at maxOrders. Once the cap is
+// passed, RunSpiller moves the least recently updated terminal orders to
+// archive until a tenth of the cap is free again, and GetOrder reads spilled
+// orders back from it. Spilled orders drop out of GetOrdersBySignal and their
+// idempotency keys are forgotten, so the cap should leave room for far more
+// orders than are retried or looked up by signal at once. Their venue order
+// IDs are archived with them, and a venue update for one brings the order
+// back into memory. Active orders are never spilled. Call before orders are
+// submitted.
+func (m *Manager) SetArchive(archive OrderArchive, maxOrders int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.archive = archive
+	m.maxOrders = maxOrders
+	m.spillDue = make(chan struct{}, 1)
+}
+
+// noteInsertLocked wakes the spiller when the cap has been passed. The
+// caller holds m.mu.
+func (m *Manager) noteInsertLocked() {
+	if m.archive == nil || len(m.orders) <= m.maxOrders {
+		return
+	}
+	select {
+	case m.spillDue <- struct{}{}:
+	default:
+	}
+}
+
+// RunSpiller spills orders whenever the cap set by SetArchive is passed,
+// until ctx is cancelled. It returns at once if no archive is set.
+func (m *Manager) RunSpiller(ctx context.Context) {
+	m.mu.RLock()
+	due := m.spillDue
+	m.mu.RUnlock()
+	if due == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-due:
+			m.spill()
+		}
+	}
+}
+
+// spill archives the oldest terminal orders, then drops the ones that have
+// not changed since they were copied. The archive write happens without the
+// lock so order flow is not held up by disk I/O.
+func (m *Manager) spill() {
+	m.mu.RLock()
+	excess := len(m.orders) - m.maxOrders*9/10
+	if excess <= 0 {
+		m.mu.RUnlock()
+		return
+	}
+	keys := make(map[uuid.UUID]string, len(m.idempotencyMap))
+	for key, id := range m.idempotencyMap {
+		keys[id] = key
+	}
+	var batch []persistence.ArchivedOrder
+	for id, order := range m.orders {
+		if order.Status.IsTerminal() {
+			batch = append(batch, persistence.ArchivedOrder{Order: *order, IdempotencyKey: keys[id]})
+		}
+	}
+	total := len(m.orders)
+	m.mu.RUnlock()
+
+	if len(batch) == 0 {
+		m.logger.Warn("order map over its cap with no finished orders to spill",
+			"orders", total, "max", m.maxOrders)
+		return
+	}
+	sort.Slice(batch, func(i, j int) bool {
+		return batch[i].Order.UpdatedAt.Before(batch[j].Order.UpdatedAt)
+	})
+	if len(batch) > excess {
+		batch = batch[:excess]
+	}
+
+	if err := m.archive.ArchiveOrders(batch); err != nil {
+		m.logger.Error("failed to spill orders to archive", "orders", len(batch), "error", err)
+		return
+	}
+
+	m.mu.Lock()
+	spilled := 0
+	for _, a := range batch {
+		order, ok := m.orders[a.Order.InternalID]
+		if !ok || !order.UpdatedAt.Equal(a.Order.UpdatedAt) {
+			continue
+		}
+		delete(m.orders, order.InternalID)
+		delete(m.fillBase, order.InternalID)
+		if order.VenueID != "" && m.venueIDMap[order.VenueID] == order.InternalID {
+			delete(m.venueIDMap, order.VenueID)
+		}
+		if a.IdempotencyKey != "" && m.idempotencyMap[a.IdempotencyKey] == order.InternalID {
+			delete(m.idempotencyMap, a.IdempotencyKey)
+		}
+		spilled++
+	}
+	remaining := len(m.orders)
+	m.mu.Unlock()
+
+	m.logger.Debug("spilled orders to archive", "spilled", spilled, "in_memory", remaining)
+}
+
+// loadArchived reads a spilled order back. It returns false if there is no
+// archive or the order is not in it.
+func (m *Manager) loadArchived(internalID uuid.UUID) (*domain.Order, bool) {
+	m.mu.RLock()
+	archive := m.archive
+	m.mu.RUnlock()
+	if archive == nil {
+		return nil, false
+	}
+	a, err := archive.LoadArchivedOrder(internalID)
+	if err != nil {
+		m.logger.Warn("failed to read archived order", "order_id", internalID, "error", err)
+		return nil, false
+	}
+	if a == nil {
+		return nil, false
+	}
+	return &a.Order, true
+}
+
+// restoreArchived brings the order venue knows as venueID back into memory
+// from the archive, with its venue order ID and idempotency key, so a late
+// update for a spilled order is matched like any other. It returns false if
+// there is no archive or the order is not in it.
+func (m *Manager) restoreArchived(venue, venueID string) bool {
+	m.mu.RLock()
+	archive := m.archive
+	m.mu.RUnlock()
+	if archive == nil {
+		return false
+	}
+	a, err := archive.LoadArchivedOrderByVenueID(venue, venueID)
+	if err != nil {
+		m.logger.Warn("failed to read archived order", "venue", venue, "venue_id", venueID, "error", err)
+		return false
+	}
+	if a == nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.orders[a.Order.InternalID]; !ok {
+		order := a.Order
+		m.orders[order.InternalID] = &order
+		if a.IdempotencyKey != "" {
+			m.idempotencyMap[a.IdempotencyKey] = order.InternalID
+		}
+		m.noteInsertLocked()
+	}
+	m.venueIDMap[venueID] = a.Order.InternalID
+	return true
+}
@@ -0,0 +1,178 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/persistence"
+)
+
+type memArchive struct {
+	orders map[uuid.UUID]persistence.ArchivedOrder
+	err    error
+}
+
+func (a *memArchive) ArchiveOrders(orders []persistence.ArchivedOrder) error {
+	if a.err != nil {
+		return a.err
+	}
+	for _, o := range orders {
+		a.orders[o.Order.InternalID] = o
+	}
+	return nil
+}
+
+func (a *memArchive) LoadArchivedOrder(id uuid.UUID) (*persistence.ArchivedOrder, error) {
+	o, ok := a.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	return &o, nil
+}
+
+func (a *memArchive) LoadArchivedOrderByVenueID(venue, venueID string) (*persistence.ArchivedOrder, error) {
+	for _, o := range a.orders {
+		if o.Order.Venue == venue && o.Order.VenueID == venueID {
+			return &o, nil
+		}
+	}
+	return nil, nil
+}
+
+func submitTestOrders(t *testing.T, mgr *Manager, n int) []uuid.UUID {
+	t.Helper()
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = NewOrderID()
+		_, err := mgr.SubmitOrder(context.Background(), domain.OrderRequest{
+			InternalID:     ids[i],
+			SignalID:       uuid.New(),
+			Venue:          "test",
+			Symbol:         "BTC/USDT",
+			Side:           domain.SideBuy,
+			OrderType:      domain.OrderTypeLimit,
+			Price:          decimal.NewFromInt(50000),
+			Size:           decimal.NewFromFloat(1),
+			IdempotencyKey: ids[i].String(),
+		})
+		if err != nil {
+			t.Fatalf("submit order %d: %v", i, err)
+		}
+	}
+	return ids
+}
+
+func TestSpillMovesOldestTerminalOrdersToArchive(t *testing.T) {
+	mgr, _ := newTestManager()
+	archive := &memArchive{orders: make(map[uuid.UUID]persistence.ArchivedOrder)}
+	mgr.SetArchive(archive, 10)
+
+	ids := submitTestOrders(t, mgr, 12)
+	// The first order stays open; the rest fill in submission order.
+	for _, id := range ids[1:] {
+		mgr.UpdateOrderFill(id, decimal.NewFromFloat(1), decimal.NewFromInt(50000))
+	}
+
+	select {
+	case <-mgr.spillDue:
+	default:
+		t.Fatal("expected passing the cap to wake the spiller")
+	}
+	mgr.spill()
+
+	mgr.mu.RLock()
+	inMemory := len(mgr.orders)
+	_, activeKept := mgr.orders[ids[0]]
+	_, keyKept := mgr.idempotencyMap[ids[1].String()]
+	mgr.mu.RUnlock()
+
+	if inMemory != 9 {
+		t.Errorf("expected 9 orders left in memory, got %d", inMemory)
+	}
+	if !activeKept {
+		t.Error("active order was spilled")
+	}
+	if keyKept {
+		t.Error("spilled order's idempotency key still tracked")
+	}
+	for _, id := range ids[1:4] {
+		if _, ok := archive.orders[id]; !ok {
+			t.Errorf("expected oldest filled order %s archived", id)
+		}
+	}
+	if len(archive.orders) != 3 {
+		t.Errorf("expected 3 orders archived, got %d", len(archive.orders))
+	}
+
+	got, ok := mgr.GetOrder(ids[1])
+	if !ok || got.Status != domain.OrderStatusFilled {
+		t.Errorf("expected spilled order to be read back filled, got %+v, %v", got, ok)
+	}
+	if archive.orders[ids[1]].IdempotencyKey != ids[1].String() {
+		t.Errorf("idempotency key not archived: %+v", archive.orders[ids[1]])
+	}
+}
+
+func TestSpillKeepsOrdersWhenArchiveFails(t *testing.T) {
+	mgr, _ := newTestManager()
+	archive := &memArchive{orders: make(map[uuid.UUID]persistence.ArchivedOrder), err: errors.New("disk full")}
+	mgr.SetArchive(archive, 2)
+
+	ids := submitTestOrders(t, mgr, 4)
+	for _, id := range ids {
+		mgr.UpdateOrderFill(id, decimal.NewFromFloat(1), decimal.NewFromInt(50000))
+	}
+	mgr.spill()
+
+	for _, id := range ids {
+		if _, ok := mgr.GetOrder(id); !ok {
+			t.Errorf("order %s lost after a failed spill", id)
+		}
+	}
+}
+
+func TestOrderUpdateRestoresSpilledOrder(t *testing.T) {
+	mgr, _ := newTestManager()
+	archive := &memArchive{orders: make(map[uuid.UUID]persistence.ArchivedOrder)}
+	mgr.SetArchive(archive, 0)
+
+	ids := submitTestOrders(t, mgr, 1)
+	mgr.UpdateOrderFill(ids[0], decimal.NewFromFloat(1), decimal.NewFromInt(50000))
+	order, _ := mgr.GetOrder(ids[0])
+	mgr.spill()
+
+	mgr.mu.RLock()
+	_, mapped := mgr.venueIDMap[order.VenueID]
+	mgr.mu.RUnlock()
+	if mapped {
+		t.Fatal("expected the spilled order's venue id to leave memory")
+	}
+
+	mgr.HandleOrderUpdate(domain.OrderUpdate{
+		Venue:   "test",
+		VenueID: order.VenueID,
+		Status:  domain.OrderStatusFilled,
+	})
+
+	mgr.mu.RLock()
+	internalID := mgr.venueIDMap[order.VenueID]
+	restored, inMemory := mgr.orders[ids[0]]
+	_, keyKept := mgr.idempotencyMap[ids[0].String()]
+	mgr.mu.RUnlock()
+	if internalID != ids[0] || !inMemory || !keyKept {
+		t.Fatalf("expected the order restored with its venue id and key, got id=%s in_memory=%v key=%v", internalID, inMemory, keyKept)
+	}
+	if restored.Status != domain.OrderStatusFilled {
+		t.Errorf("restored order status: got %s, want FILLED", restored.Status)
+	}
+
+	mgr.HandleOrderUpdate(domain.OrderUpdate{Venue: "other", VenueID: "unknown", Status: domain.OrderStatusFilled})
+	if n := len(mgr.GetActiveOrders()); n != 0 {
+		t.Errorf("expected an unknown venue id to be ignored, got %d active orders", n)
+	}
+}
@@ -2,10 +2,17 @@ package persistence
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
 )
 
 type PostgresStore struct {
@@ -103,6 +110,57 @@ func (s *PostgresStore) RunMigrations(ctx context.Context) error {
 			changed_by VARCHAR(64) NOT NULL,
 			changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)`,
+		`CREATE TABLE IF NOT EXISTS backtest_runs (
+			id UUID PRIMARY KEY,
+			config_hash VARCHAR(16) NOT NULL,
+			data_from TIMESTAMPTZ NOT NULL,
+			data_to TIMESTAMPTZ NOT NULL,
+			events INTEGER NOT NULL,
+			cycles INTEGER NOT NULL,
+			hit_rate NUMERIC(6, 4) NOT NULL,
+			total_pnl NUMERIC(20, 8) NOT NULL,
+			max_drawdown NUMERIC(20, 8) NOT NULL,
+			report JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS market_klines (
+			venue VARCHAR(32) NOT NULL,
+			symbol VARCHAR(32) NOT NULL,
+			interval_seconds INTEGER NOT NULL,
+			open_time TIMESTAMPTZ NOT NULL,
+			open NUMERIC(20, 8) NOT NULL,
+			high NUMERIC(20, 8) NOT NULL,
+			low NUMERIC(20, 8) NOT NULL,
+			close NUMERIC(20, 8) NOT NULL,
+			volume NUMERIC(28, 8) NOT NULL,
+			PRIMARY KEY (venue, symbol, interval_seconds, open_time)
+		)`,
+		`CREATE TABLE IF NOT EXISTS market_trades (
+			venue VARCHAR(32) NOT NULL,
+			symbol VARCHAR(32) NOT NULL,
+			trade_id VARCHAR(64) NOT NULL,
+			side VARCHAR(4) NOT NULL,
+			price NUMERIC(20, 8) NOT NULL,
+			size NUMERIC(20, 8) NOT NULL,
+			executed_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (venue, symbol, trade_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_market_trades_executed_at ON market_trades(venue, symbol, executed_at)`,
+		`CREATE TABLE IF NOT EXISTS funding_rates (
+			venue VARCHAR(32) NOT NULL,
+			symbol VARCHAR(32) NOT NULL,
+			funding_time TIMESTAMPTZ NOT NULL,
+			rate NUMERIC(20, 12) NOT NULL,
+			PRIMARY KEY (venue, symbol, funding_time)
+		)`,
+		`CREATE TABLE IF NOT EXISTS funding_rate_updates (
+			venue VARCHAR(32) NOT NULL,
+			symbol VARCHAR(32) NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			rate NUMERIC(20, 12) NOT NULL,
+			next_funding_time TIMESTAMPTZ,
+			PRIMARY KEY (venue, symbol, updated_at)
+		)`,
 	}
 
 	for _, m := range migrations {
@@ -136,10 +194,234 @@ func (s *PostgresStore) WriteRiskEvent(payload interface{}) error {
 	if s == nil || s.pool == nil {
 		return nil
 	}
-	s.logger.Debug("risk event written to cold store")
+	event, ok := payload.(*domain.RiskEvent)
+	if !ok {
+		return fmt.Errorf("unexpected risk event payload %T", payload)
+	}
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return fmt.Errorf("marshal risk event details: %w", err)
+	}
+
+	_, err = s.pool.Exec(context.Background(),
+		`INSERT INTO risk_events (id, event_type, severity, details, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		event.ID, string(event.Type), string(event.Severity), details, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert risk event: %w", err)
+	}
+	return nil
+}
+
+// WriteDailyPnL upserts a day's closing PnL into daily_pnl, so a rollover
+// that runs twice for the same date overwrites rather than fails.
+func (s *PostgresStore) WriteDailyPnL(payload interface{}) error {
+	if s == nil || s.pool == nil {
+		return nil
+	}
+	snap, ok := payload.(*domain.DailyPnLSnapshot)
+	if !ok {
+		return fmt.Errorf("unexpected daily pnl payload %T", payload)
+	}
+
+	_, err := s.pool.Exec(context.Background(),
+		`INSERT INTO daily_pnl (date, realized_pnl, unrealized_pnl, total_pnl, num_cycles, num_trades, fees_paid, funding_net)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (date) DO UPDATE SET
+			realized_pnl = EXCLUDED.realized_pnl,
+			unrealized_pnl = EXCLUDED.unrealized_pnl,
+			total_pnl = EXCLUDED.total_pnl,
+			num_cycles = EXCLUDED.num_cycles,
+			num_trades = EXCLUDED.num_trades,
+			fees_paid = EXCLUDED.fees_paid,
+			funding_net = EXCLUDED.funding_net`,
+		snap.Date.Format("2006-01-02"),
+		snap.RealizedPnL.String(),
+		snap.UnrealizedPnL.String(),
+		snap.TotalPnL.String(),
+		snap.NumCycles,
+		snap.NumTrades,
+		snap.FeesPaid.String(),
+		snap.FundingNet.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert daily pnl: %w", err)
+	}
+	return nil
+}
+
+// BacktestRun is the summary of a backtest stored in backtest_runs; Report
+// holds the full report as JSON.
+type BacktestRun struct {
+	ID          uuid.UUID
+	ConfigHash  string
+	DataFrom    time.Time
+	DataTo      time.Time
+	Events      int
+	Cycles      int
+	HitRate     float64
+	TotalPnL    decimal.Decimal
+	MaxDrawdown decimal.Decimal
+	Report      json.RawMessage
+	CreatedAt   time.Time
+}
+
+// WriteBacktestRun stores a finished backtest's report.
+func (s *PostgresStore) WriteBacktestRun(payload interface{}) error {
+	if s == nil || s.pool == nil {
+		return nil
+	}
+	run, ok := payload.(*BacktestRun)
+	if !ok {
+		return fmt.Errorf("unexpected backtest run payload %T", payload)
+	}
+
+	_, err := s.pool.Exec(context.Background(),
+		`INSERT INTO backtest_runs (id, config_hash, data_from, data_to, events, cycles, hit_rate, total_pnl, max_drawdown, report, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		run.ID, run.ConfigHash, run.DataFrom, run.DataTo, run.Events, run.Cycles, run.HitRate,
+		run.TotalPnL.String(), run.MaxDrawdown.String(), run.Report, run.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert backtest run: %w", err)
+	}
+	return nil
+}
+
+// WriteKlines stores klines, skipping any already held, and returns how many
+// were new.
+func (s *PostgresStore) WriteKlines(ctx context.Context, klines []domain.Kline) (int, error) {
+	batch := &pgx.Batch{}
+	for _, k := range klines {
+		batch.Queue(`INSERT INTO market_klines (venue, symbol, interval_seconds, open_time, open, high, low, close, volume)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT DO NOTHING`,
+			k.Venue, k.Symbol, int(k.Interval.Seconds()), k.OpenTime,
+			k.Open.String(), k.High.String(), k.Low.String(), k.Close.String(), k.Volume.String())
+	}
+	n, err := s.sendBatch(ctx, batch)
+	if err != nil {
+		return n, fmt.Errorf("insert klines: %w", err)
+	}
+	return n, nil
+}
+
+// WriteMarketTrades stores public trades, skipping any already held, and
+// returns how many were new.
+func (s *PostgresStore) WriteMarketTrades(ctx context.Context, trades []domain.Trade) (int, error) {
+	batch := &pgx.Batch{}
+	for _, t := range trades {
+		batch.Queue(`INSERT INTO market_trades (venue, symbol, trade_id, side, price, size, executed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`,
+			t.Venue, t.Symbol, t.TradeID, string(t.Side), t.Price.String(), t.Size.String(), t.Timestamp)
+	}
+	n, err := s.sendBatch(ctx, batch)
+	if err != nil {
+		return n, fmt.Errorf("insert market trades: %w", err)
+	}
+	return n, nil
+}
+
+// WriteFundingRates stores settled funding rates, skipping any already
+// held, and returns how many were new.
+func (s *PostgresStore) WriteFundingRates(ctx context.Context, rates []domain.FundingRate) (int, error) {
+	batch := &pgx.Batch{}
+	for _, r := range rates {
+		batch.Queue(`INSERT INTO funding_rates (venue, symbol, funding_time, rate)
+			VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
+			r.Venue, r.Symbol, r.Timestamp, r.Rate.String())
+	}
+	n, err := s.sendBatch(ctx, batch)
+	if err != nil {
+		return n, fmt.Errorf("insert funding rates: %w", err)
+	}
+	return n, nil
+}
+
+// WriteFundingRate stores a live funding rate update in
+// funding_rate_updates. Unlike WriteFundingRates, which holds settled rates,
+// these are the rates the venues stream between settlements.
+func (s *PostgresStore) WriteFundingRate(payload interface{}) error {
+	if s == nil || s.pool == nil {
+		return nil
+	}
+	rate, ok := payload.(domain.FundingRate)
+	if !ok {
+		return fmt.Errorf("unexpected funding rate payload %T", payload)
+	}
+	var next *time.Time
+	if !rate.NextTime.IsZero() {
+		next = &rate.NextTime
+	}
+	_, err := s.pool.Exec(context.Background(),
+		`INSERT INTO funding_rate_updates (venue, symbol, updated_at, rate, next_funding_time)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`,
+		rate.Venue, rate.Symbol, rate.Timestamp, rate.Rate.String(), next,
+	)
+	if err != nil {
+		return fmt.Errorf("insert funding rate update: %w", err)
+	}
 	return nil
 }
 
+// GetFundingHistory returns the funding rate updates of venue's symbol
+// stored since then, oldest first. It returns nil, nil without a cold store.
+func (s *PostgresStore) GetFundingHistory(ctx context.Context, venue, symbol string, since time.Time) ([]domain.FundingRate, error) {
+	if s == nil || s.pool == nil {
+		return nil, nil
+	}
+	rows, err := s.pool.Query(ctx,
+		`SELECT updated_at, rate::text, next_funding_time FROM funding_rate_updates
+		WHERE venue = $1 AND symbol = $2 AND updated_at >= $3
+		ORDER BY updated_at`,
+		venue, symbol, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query funding history: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []domain.FundingRate
+	for rows.Next() {
+		var (
+			rate domain.FundingRate
+			raw  string
+			next *time.Time
+		)
+		if err := rows.Scan(&rate.Timestamp, &raw, &next); err != nil {
+			return nil, fmt.Errorf("scan funding history: %w", err)
+		}
+		if rate.Rate, err = decimal.NewFromString(raw); err != nil {
+			return nil, fmt.Errorf("parse funding rate %q: %w", raw, err)
+		}
+		if next != nil {
+			rate.NextTime = *next
+		}
+		rate.Venue, rate.Symbol = venue, symbol
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// sendBatch runs batch in one round trip and returns how many rows its
+// statements inserted.
+func (s *PostgresStore) sendBatch(ctx context.Context, batch *pgx.Batch) (int, error) {
+	if s == nil || s.pool == nil || batch.Len() == 0 {
+		return 0, nil
+	}
+	results := s.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	inserted := 0
+	for i := 0; i < batch.Len(); i++ {
+		tag, err := results.Exec()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += int(tag.RowsAffected())
+	}
+	return inserted, nil
+}
+
 func (s *PostgresStore) Close() {
 	if s != nil && s.pool != nil {
 		s.pool.Close()
@@ -7,7 +7,11 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	_ "modernc.org/sqlite"
+
+	"github.com/crypto-trading/trading/internal/domain"
 )
 
 type SQLiteStore struct {
@@ -64,6 +68,97 @@ func (s *SQLiteStore) migrate() error {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+		`CREATE TABLE IF NOT EXISTS stress_reports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			report_json TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS account_activity (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			venue TEXT NOT NULL,
+			type TEXT NOT NULL,
+			venue_ref TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			asset TEXT NOT NULL,
+			side TEXT NOT NULL,
+			price TEXT NOT NULL,
+			size TEXT NOT NULL,
+			amount TEXT NOT NULL,
+			fee TEXT NOT NULL,
+			fee_currency TEXT NOT NULL,
+			occurred_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (venue, type, venue_ref)
+		)`,
+		`CREATE TABLE IF NOT EXISTS order_archive (
+			id TEXT PRIMARY KEY,
+			idempotency_key TEXT NOT NULL,
+			order_json TEXT NOT NULL,
+			archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS order_archive_venue_ids (
+			venue TEXT NOT NULL,
+			venue_id TEXT NOT NULL,
+			id TEXT NOT NULL,
+			PRIMARY KEY (venue, venue_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS execution_reports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			signal_id TEXT NOT NULL,
+			strategy TEXT NOT NULL,
+			venue TEXT NOT NULL,
+			status TEXT NOT NULL,
+			report_json TEXT NOT NULL,
+			completed_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_execution_reports_completed_at ON execution_reports (completed_at)`,
+		`CREATE TABLE IF NOT EXISTS risk_rejections (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			signal_id TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			rejection_json TEXT NOT NULL,
+			rejected_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_risk_rejections_rejected_at ON risk_rejections (rejected_at)`,
+		`CREATE TABLE IF NOT EXISTS order_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			internal_id TEXT NOT NULL,
+			venue TEXT NOT NULL,
+			new_status TEXT NOT NULL,
+			event_json TEXT NOT NULL,
+			occurred_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_order_events_occurred_at ON order_events (occurred_at)`,
+		`CREATE TABLE IF NOT EXISTS alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			level TEXT NOT NULL,
+			name TEXT NOT NULL,
+			alert_json TEXT NOT NULL,
+			fired_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_alerts_fired_at ON alerts (fired_at)`,
+		`CREATE TABLE IF NOT EXISTS config_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hash TEXT NOT NULL,
+			source TEXT NOT NULL,
+			change_json TEXT NOT NULL,
+			changed_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_config_changes_changed_at ON config_changes (changed_at)`,
+		`CREATE TABLE IF NOT EXISTS book_snapshots (
+			venue TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			book_json TEXT NOT NULL,
+			saved_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (venue, symbol)
+		)`,
+		`CREATE TABLE IF NOT EXISTS funding_snapshots (
+			venue TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			rate_json TEXT NOT NULL,
+			saved_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (venue, symbol)
+		)`,
 	}
 
 	for _, m := range migrations {
@@ -75,7 +170,16 @@ func (s *SQLiteStore) migrate() error {
 }
 
 func (s *SQLiteStore) WriteRiskCheckpoint(payload interface{}) error {
-	data, err := json.Marshal(payload)
+	var data []byte
+	var err error
+	switch p := payload.(type) {
+	case *domain.RiskState:
+		// Risk state is stored in a versioned envelope so checkpoints written
+		// by older builds remain loadable after the struct changes.
+		data, err = domain.EncodeRiskState(p)
+	default:
+		data, err = json.Marshal(payload)
+	}
 	if err != nil {
 		return fmt.Errorf("marshal risk state: %w", err)
 	}
@@ -87,6 +191,628 @@ func (s *SQLiteStore) WriteRiskCheckpoint(payload interface{}) error {
 	return err
 }
 
+// WriteStressReport stores a nightly portfolio stress report.
+func (s *SQLiteStore) WriteStressReport(payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal stress report: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		"INSERT INTO stress_reports (report_json) VALUES (?)",
+		string(data),
+	)
+	return err
+}
+
+// WriteExecutionReport stores a finished execution cycle in a versioned
+// envelope, so latency and slippage can be compared across date ranges later.
+func (s *SQLiteStore) WriteExecutionReport(payload interface{}) error {
+	report, ok := payload.(domain.ExecutionReport)
+	if !ok {
+		return fmt.Errorf("unexpected execution report payload %T", payload)
+	}
+	data, err := domain.EncodeExecutionReport(&report)
+	if err != nil {
+		return fmt.Errorf("encode execution report: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO execution_reports (signal_id, strategy, venue, status, report_json, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		report.SignalID.String(), string(report.Strategy), report.Venue, report.Status, string(data),
+		report.CompletedAt.UTC().Format(sqliteTimeLayout),
+	)
+	return err
+}
+
+// ListExecutionReports returns the execution reports completed within
+// [since, until), oldest first. Rows that cannot be decoded are skipped.
+func (s *SQLiteStore) ListExecutionReports(since, until time.Time) ([]domain.ExecutionReport, error) {
+	rows, err := s.db.Query(
+		`SELECT id, report_json FROM execution_reports
+		WHERE completed_at >= ? AND completed_at < ?
+		ORDER BY completed_at, id`,
+		since.UTC().Format(sqliteTimeLayout),
+		until.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query execution reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []domain.ExecutionReport
+	for rows.Next() {
+		var (
+			id   int64
+			data string
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		report, err := domain.DecodeExecutionReport([]byte(data))
+		if err != nil {
+			s.logger.Warn("skipping unreadable execution report", "id", id, "error", err)
+			continue
+		}
+		reports = append(reports, *report)
+	}
+	return reports, rows.Err()
+}
+
+// WriteRiskRejection stores a signal the risk manager refused.
+func (s *SQLiteStore) WriteRiskRejection(payload interface{}) error {
+	rejection, ok := payload.(*domain.RiskRejection)
+	if !ok {
+		return fmt.Errorf("unexpected risk rejection payload %T", payload)
+	}
+	data, err := json.Marshal(rejection)
+	if err != nil {
+		return fmt.Errorf("marshal risk rejection: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO risk_rejections (signal_id, reason, rejection_json, rejected_at) VALUES (?, ?, ?, ?)`,
+		rejection.SignalID.String(), rejection.Reason, string(data),
+		rejection.RejectedAt.UTC().Format(sqliteTimeLayout),
+	)
+	return err
+}
+
+// ListRiskRejections returns the rejections made within [since, until),
+// oldest first. Rows that cannot be decoded are skipped.
+func (s *SQLiteStore) ListRiskRejections(since, until time.Time) ([]domain.RiskRejection, error) {
+	rows, err := s.db.Query(
+		`SELECT id, rejection_json FROM risk_rejections
+		WHERE rejected_at >= ? AND rejected_at < ?
+		ORDER BY rejected_at, id`,
+		since.UTC().Format(sqliteTimeLayout),
+		until.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query risk rejections: %w", err)
+	}
+	defer rows.Close()
+
+	var rejections []domain.RiskRejection
+	for rows.Next() {
+		var (
+			id        int64
+			data      string
+			rejection domain.RiskRejection
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &rejection); err != nil {
+			s.logger.Warn("skipping unreadable risk rejection", "id", id, "error", err)
+			continue
+		}
+		rejections = append(rejections, rejection)
+	}
+	return rejections, rows.Err()
+}
+
+// WriteOrderEvent stores one order state change, so an order's history can
+// be replayed after it has left the order manager's memory.
+func (s *SQLiteStore) WriteOrderEvent(payload interface{}) error {
+	change, ok := payload.(*domain.OrderStateChange)
+	if !ok {
+		return fmt.Errorf("unexpected order event payload %T", payload)
+	}
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("marshal order event: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO order_events (internal_id, venue, new_status, event_json, occurred_at) VALUES (?, ?, ?, ?, ?)`,
+		change.Order.InternalID.String(), change.Order.Venue, string(change.NewStatus), string(data),
+		change.Timestamp.UTC().Format(sqliteTimeLayout),
+	)
+	return err
+}
+
+// ListOrderEvents returns the order state changes within [since, until),
+// oldest first. Rows that cannot be decoded are skipped.
+func (s *SQLiteStore) ListOrderEvents(since, until time.Time) ([]domain.OrderStateChange, error) {
+	rows, err := s.db.Query(
+		`SELECT id, event_json FROM order_events
+		WHERE occurred_at >= ? AND occurred_at < ?
+		ORDER BY occurred_at, id`,
+		since.UTC().Format(sqliteTimeLayout),
+		until.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query order events: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []domain.OrderStateChange
+	for rows.Next() {
+		var (
+			id     int64
+			data   string
+			change domain.OrderStateChange
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &change); err != nil {
+			s.logger.Warn("skipping unreadable order event", "id", id, "error", err)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// AlertRecord is a fired alert as stored in the alerts table.
+type AlertRecord struct {
+	Level     string
+	Name      string
+	Condition string
+	Message   string
+	FiredAt   time.Time
+}
+
+// WriteAlert stores a fired alert.
+func (s *SQLiteStore) WriteAlert(payload interface{}) error {
+	alert, ok := payload.(*AlertRecord)
+	if !ok {
+		return fmt.Errorf("unexpected alert payload %T", payload)
+	}
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("marshal alert: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO alerts (level, name, alert_json, fired_at) VALUES (?, ?, ?, ?)`,
+		alert.Level, alert.Name, string(data),
+		alert.FiredAt.UTC().Format(sqliteTimeLayout),
+	)
+	return err
+}
+
+// ListAlerts returns the alerts fired within [since, until), oldest first.
+// Rows that cannot be decoded are skipped.
+func (s *SQLiteStore) ListAlerts(since, until time.Time) ([]AlertRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, alert_json FROM alerts
+		WHERE fired_at >= ? AND fired_at < ?
+		ORDER BY fired_at, id`,
+		since.UTC().Format(sqliteTimeLayout),
+		until.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []AlertRecord
+	for rows.Next() {
+		var (
+			id    int64
+			data  string
+			alert AlertRecord
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &alert); err != nil {
+			s.logger.Warn("skipping unreadable alert", "id", id, "error", err)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// ConfigChange records the configuration a process started with or reloaded
+// to. Keys names the settings that differ from the previous record; Config
+// is the effective configuration, kept so the next change can be diffed
+// against it. Credentials live in the environment and are never part of it.
+type ConfigChange struct {
+	Hash      string
+	Source    string // "startup" or "reload"
+	Keys      []string
+	Config    json.RawMessage
+	ChangedAt time.Time
+}
+
+// WriteConfigChange stores a configuration change.
+func (s *SQLiteStore) WriteConfigChange(payload interface{}) error {
+	change, ok := payload.(*ConfigChange)
+	if !ok {
+		return fmt.Errorf("unexpected config change payload %T", payload)
+	}
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("marshal config change: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO config_changes (hash, source, change_json, changed_at) VALUES (?, ?, ?, ?)`,
+		change.Hash, change.Source, string(data),
+		change.ChangedAt.UTC().Format(sqliteTimeLayout),
+	)
+	return err
+}
+
+// LatestConfigChange returns the most recently stored configuration change,
+// or nil, nil if none has been stored.
+func (s *SQLiteStore) LatestConfigChange() (*ConfigChange, error) {
+	var data string
+	err := s.db.QueryRow(
+		"SELECT change_json FROM config_changes ORDER BY id DESC LIMIT 1",
+	).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var change ConfigChange
+	if err := json.Unmarshal([]byte(data), &change); err != nil {
+		return nil, fmt.Errorf("decode config change: %w", err)
+	}
+	return &change, nil
+}
+
+// ListConfigChanges returns the configuration changes within [since, until),
+// oldest first. Rows that cannot be decoded are skipped.
+func (s *SQLiteStore) ListConfigChanges(since, until time.Time) ([]ConfigChange, error) {
+	rows, err := s.db.Query(
+		`SELECT id, change_json FROM config_changes
+		WHERE changed_at >= ? AND changed_at < ?
+		ORDER BY changed_at, id`,
+		since.UTC().Format(sqliteTimeLayout),
+		until.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query config changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []ConfigChange
+	for rows.Next() {
+		var (
+			id     int64
+			data   string
+			change ConfigChange
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &change); err != nil {
+			s.logger.Warn("skipping unreadable config change", "id", id, "error", err)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// WriteAccountActivity stores imported account history in one transaction
+// and returns how many rows were new. Events already present, keyed by venue,
+// type and venue reference, are skipped so overlapping imports are safe.
+func (s *SQLiteStore) WriteAccountActivity(activities []domain.AccountActivity) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO account_activity
+		(venue, type, venue_ref, symbol, asset, side, price, size, amount, fee, fee_currency, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, a := range activities {
+		res, err := stmt.Exec(
+			a.Venue, string(a.Type), a.VenueRef, a.Symbol, a.Asset, string(a.Side),
+			a.Price.String(), a.Size.String(), a.Amount.String(), a.Fee.String(), a.FeeCurrency,
+			a.Timestamp.UTC().Format(sqliteTimeLayout),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert %s %s %s: %w", a.Venue, a.Type, a.VenueRef, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			inserted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return inserted, nil
+}
+
+// ListAccountActivity returns stored account activity of the given type,
+// oldest first. Rows with unreadable amounts are skipped.
+func (s *SQLiteStore) ListAccountActivity(activityType domain.ActivityType) ([]domain.AccountActivity, error) {
+	rows, err := s.db.Query(
+		`SELECT id, venue, type, venue_ref, symbol, asset, side, price, size, amount, fee, fee_currency, occurred_at
+		FROM account_activity WHERE type = ?
+		ORDER BY occurred_at, id`,
+		string(activityType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query account activity: %w", err)
+	}
+	defer rows.Close()
+
+	var activities []domain.AccountActivity
+	for rows.Next() {
+		var (
+			id                       int64
+			a                        domain.AccountActivity
+			typ, side                string
+			price, size, amount, fee string
+			occurredAt               interface{}
+		)
+		if err := rows.Scan(&id, &a.Venue, &typ, &a.VenueRef, &a.Symbol, &a.Asset, &side,
+			&price, &size, &amount, &fee, &a.FeeCurrency, &occurredAt); err != nil {
+			return nil, err
+		}
+		a.Type = domain.ActivityType(typ)
+		a.Side = domain.Side(side)
+
+		var parseErr error
+		for _, f := range []struct {
+			dst *decimal.Decimal
+			src string
+		}{{&a.Price, price}, {&a.Size, size}, {&a.Amount, amount}, {&a.Fee, fee}} {
+			if *f.dst, parseErr = decimal.NewFromString(f.src); parseErr != nil {
+				break
+			}
+		}
+		if parseErr != nil {
+			s.logger.Warn("skipping unreadable account activity", "id", id, "error", parseErr)
+			continue
+		}
+
+		switch v := occurredAt.(type) {
+		case time.Time:
+			a.Timestamp = v
+		case string:
+			a.Timestamp, _ = time.Parse(sqliteTimeLayout, v)
+		}
+		activities = append(activities, a)
+	}
+	return activities, rows.Err()
+}
+
+// ArchivedOrder is a terminal order moved out of the order manager's memory,
+// with the idempotency key it was submitted under.
+type ArchivedOrder struct {
+	Order          domain.Order
+	IdempotencyKey string
+}
+
+// ArchiveOrders stores orders in one transaction, each in a versioned
+// envelope, with the venue order ID of each that has one. An order archived
+// again replaces the earlier row.
+func (s *SQLiteStore) ArchiveOrders(orders []ArchivedOrder) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO order_archive (id, idempotency_key, order_json) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+	idStmt, err := tx.Prepare(`INSERT OR REPLACE INTO order_archive_venue_ids (venue, venue_id, id) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare venue ids: %w", err)
+	}
+	defer idStmt.Close()
+
+	for _, a := range orders {
+		data, err := domain.EncodeOrder(&a.Order)
+		if err != nil {
+			return fmt.Errorf("encode order %s: %w", a.Order.InternalID, err)
+		}
+		if _, err := stmt.Exec(a.Order.InternalID.String(), a.IdempotencyKey, string(data)); err != nil {
+			return fmt.Errorf("insert order %s: %w", a.Order.InternalID, err)
+		}
+		if a.Order.VenueID == "" {
+			continue
+		}
+		if _, err := idStmt.Exec(a.Order.Venue, a.Order.VenueID, a.Order.InternalID.String()); err != nil {
+			return fmt.Errorf("insert venue id of order %s: %w", a.Order.InternalID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadArchivedOrder returns an archived order by internal ID, or nil, nil if
+// it was never archived.
+func (s *SQLiteStore) LoadArchivedOrder(id uuid.UUID) (*ArchivedOrder, error) {
+	var (
+		a    ArchivedOrder
+		data string
+	)
+	err := s.db.QueryRow(
+		"SELECT idempotency_key, order_json FROM order_archive WHERE id = ?",
+		id.String(),
+	).Scan(&a.IdempotencyKey, &data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	order, err := domain.DecodeOrder([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode archived order: %w", err)
+	}
+	a.Order = *order
+	return &a, nil
+}
+
+// LoadArchivedOrderByVenueID returns the archived order venue knows as
+// venueID, or nil, nil if no such order was archived.
+func (s *SQLiteStore) LoadArchivedOrderByVenueID(venue, venueID string) (*ArchivedOrder, error) {
+	var id string
+	err := s.db.QueryRow(
+		"SELECT id FROM order_archive_venue_ids WHERE venue = ? AND venue_id = ?",
+		venue, venueID,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	internalID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("decode archived order id: %w", err)
+	}
+	return s.LoadArchivedOrder(internalID)
+}
+
+// MarketSnapshot is the latest book and funding rate of every feed, saved so
+// a restart does not begin with no market data at all.
+type MarketSnapshot struct {
+	Books   []domain.OrderBookSnapshot
+	Funding []domain.FundingRate
+	SavedAt time.Time
+}
+
+// SaveMarketSnapshot replaces the saved market snapshot with snap in one
+// transaction.
+func (s *SQLiteStore) SaveMarketSnapshot(snap MarketSnapshot) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"book_snapshots", "funding_snapshots"} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+	savedAt := snap.SavedAt.UTC().Format(sqliteTimeLayout)
+	for _, book := range snap.Books {
+		data, err := json.Marshal(book)
+		if err != nil {
+			return fmt.Errorf("marshal book %s:%s: %w", book.Venue, book.Symbol, err)
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO book_snapshots (venue, symbol, book_json, saved_at) VALUES (?, ?, ?, ?)",
+			book.Venue, book.Symbol, string(data), savedAt,
+		); err != nil {
+			return fmt.Errorf("insert book %s:%s: %w", book.Venue, book.Symbol, err)
+		}
+	}
+	for _, rate := range snap.Funding {
+		data, err := json.Marshal(rate)
+		if err != nil {
+			return fmt.Errorf("marshal funding rate %s:%s: %w", rate.Venue, rate.Symbol, err)
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO funding_snapshots (venue, symbol, rate_json, saved_at) VALUES (?, ?, ?, ?)",
+			rate.Venue, rate.Symbol, string(data), savedAt,
+		); err != nil {
+			return fmt.Errorf("insert funding rate %s:%s: %w", rate.Venue, rate.Symbol, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadMarketSnapshot returns the saved market snapshot, or nil, nil if none
+// was saved. Rows that cannot be decoded are skipped.
+func (s *SQLiteStore) LoadMarketSnapshot() (*MarketSnapshot, error) {
+	var (
+		snap  MarketSnapshot
+		found bool
+	)
+	load := func(query string, decode func(data []byte) error) error {
+		rows, err := s.db.Query(query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				data    string
+				savedAt interface{}
+			)
+			if err := rows.Scan(&data, &savedAt); err != nil {
+				return err
+			}
+			found = true
+			switch v := savedAt.(type) {
+			case time.Time:
+				snap.SavedAt = v
+			case string:
+				snap.SavedAt, _ = time.Parse(sqliteTimeLayout, v)
+			}
+			if err := decode([]byte(data)); err != nil {
+				s.logger.Warn("skipping unreadable market snapshot row", "error", err)
+			}
+		}
+		return rows.Err()
+	}
+
+	err := load("SELECT book_json, saved_at FROM book_snapshots ORDER BY venue, symbol", func(data []byte) error {
+		var book domain.OrderBookSnapshot
+		if err := json.Unmarshal(data, &book); err != nil {
+			return err
+		}
+		snap.Books = append(snap.Books, book)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query book snapshots: %w", err)
+	}
+	err = load("SELECT rate_json, saved_at FROM funding_snapshots ORDER BY venue, symbol", func(data []byte) error {
+		var rate domain.FundingRate
+		if err := json.Unmarshal(data, &rate); err != nil {
+			return err
+		}
+		snap.Funding = append(snap.Funding, rate)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query funding snapshots: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &snap, nil
+}
+
 func (s *SQLiteStore) LoadLatestCheckpoint() ([]byte, error) {
 	var data string
 	err := s.db.QueryRow(
@@ -101,6 +827,92 @@ func (s *SQLiteStore) LoadLatestCheckpoint() ([]byte, error) {
 	return []byte(data), nil
 }
 
+// LoadLatestRiskState decodes the most recent checkpoint. It returns nil, nil
+// when no checkpoint has been written yet.
+func (s *SQLiteStore) LoadLatestRiskState() (*domain.RiskState, error) {
+	data, err := s.LoadLatestCheckpoint()
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return domain.DecodeRiskState(data)
+}
+
+// CheckpointRecord is a decoded row from risk_checkpoints.
+type CheckpointRecord struct {
+	ID        int64
+	CreatedAt time.Time
+	State     *domain.RiskState
+}
+
+// sqliteTimeLayout matches the format written by CURRENT_TIMESTAMP (UTC).
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// ListCheckpoints returns checkpoints created within [since, until], newest
+// first, capped at limit. Rows that cannot be decoded are skipped.
+func (s *SQLiteStore) ListCheckpoints(since, until time.Time, limit int) ([]CheckpointRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, state_json, created_at FROM risk_checkpoints
+		WHERE created_at >= ? AND created_at <= ?
+		ORDER BY id DESC LIMIT ?`,
+		since.UTC().Format(sqliteTimeLayout),
+		until.UTC().Format(sqliteTimeLayout),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var records []CheckpointRecord
+	for rows.Next() {
+		rec, err := scanCheckpoint(rows)
+		if err != nil {
+			s.logger.Warn("skipping unreadable risk checkpoint", "error", err)
+			continue
+		}
+		records = append(records, *rec)
+	}
+	return records, rows.Err()
+}
+
+// GetCheckpoint returns a single checkpoint by ID, or nil, nil if absent.
+func (s *SQLiteStore) GetCheckpoint(id int64) (*CheckpointRecord, error) {
+	row := s.db.QueryRow(
+		"SELECT id, state_json, created_at FROM risk_checkpoints WHERE id = ?",
+		id,
+	)
+	rec, err := scanCheckpoint(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rec, err
+}
+
+func scanCheckpoint(row interface{ Scan(...any) error }) (*CheckpointRecord, error) {
+	var (
+		rec       CheckpointRecord
+		data      string
+		createdAt interface{}
+	)
+	if err := row.Scan(&rec.ID, &data, &createdAt); err != nil {
+		return nil, err
+	}
+
+	switch v := createdAt.(type) {
+	case time.Time:
+		rec.CreatedAt = v
+	case string:
+		rec.CreatedAt, _ = time.Parse(sqliteTimeLayout, v)
+	}
+
+	state, err := domain.DecodeRiskState([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint %d: %w", rec.ID, err)
+	}
+	rec.State = state
+	return &rec, nil
+}
+
 func (s *SQLiteStore) CleanupOldCheckpoints(maxAge time.Duration) error {
 	cutoff := time.Now().Add(-maxAge)
 	_, err := s.db.Exec(
@@ -0,0 +1,406 @@
+package persistence
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "checkpoints.db"), logger)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStoreRiskCheckpointRoundTrip(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	key := domain.VenueAssetKey{Venue: "kcex", Asset: "BTC"}
+	state := &domain.RiskState{
+		Mode:             domain.RiskModeNormal,
+		DailyRealizedPnL: decimal.NewFromInt(42),
+		Positions: map[domain.VenueAssetKey]*domain.Position{
+			key: {Venue: "kcex", Asset: "BTC", Size: decimal.NewFromFloat(0.5)},
+		},
+	}
+	if err := store.WriteRiskCheckpoint(state); err != nil {
+		t.Fatalf("write checkpoint: %v", err)
+	}
+
+	got, err := store.LoadLatestRiskState()
+	if err != nil {
+		t.Fatalf("load checkpoint: %v", err)
+	}
+	if !got.DailyRealizedPnL.Equal(decimal.NewFromInt(42)) {
+		t.Errorf("realized pnl: got %s, want 42", got.DailyRealizedPnL)
+	}
+	if p := got.Positions[key]; p == nil || !p.Size.Equal(decimal.NewFromFloat(0.5)) {
+		t.Errorf("position: got %+v, want size 0.5", p)
+	}
+}
+
+func TestSQLiteStoreListCheckpoints(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	for i := 0; i < 3; i++ {
+		state := &domain.RiskState{Mode: domain.RiskModeNormal, DailyRealizedPnL: decimal.NewFromInt(int64(i))}
+		if err := store.WriteRiskCheckpoint(state); err != nil {
+			t.Fatalf("write checkpoint: %v", err)
+		}
+	}
+
+	now := time.Now()
+	records, err := store.ListCheckpoints(now.Add(-time.Hour), now.Add(time.Hour), 2)
+	if err != nil {
+		t.Fatalf("list checkpoints: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records (limit), got %d", len(records))
+	}
+	if records[0].ID <= records[1].ID {
+		t.Errorf("expected newest first, got ids %d, %d", records[0].ID, records[1].ID)
+	}
+	if !records[0].State.DailyRealizedPnL.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("newest pnl: got %s, want 2", records[0].State.DailyRealizedPnL)
+	}
+	if records[0].CreatedAt.IsZero() {
+		t.Error("expected created_at to be populated")
+	}
+
+	old, err := store.ListCheckpoints(now.Add(-2*time.Hour), now.Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("list checkpoints: %v", err)
+	}
+	if len(old) != 0 {
+		t.Errorf("expected no records in past window, got %d", len(old))
+	}
+
+	rec, err := store.GetCheckpoint(records[1].ID)
+	if err != nil || rec == nil {
+		t.Fatalf("get checkpoint: %v", err)
+	}
+	if missing, err := store.GetCheckpoint(9999); err != nil || missing != nil {
+		t.Errorf("expected nil, nil for missing checkpoint, got %v, %v", missing, err)
+	}
+}
+
+func TestSQLiteStoreWriteAccountActivityDedupes(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	batch := []domain.AccountActivity{
+		{Venue: "kcex", Type: domain.ActivityTrade, VenueRef: "t1", Symbol: "BTC/USDT", Side: domain.SideBuy,
+			Price: decimal.NewFromInt(60000), Size: decimal.NewFromFloat(0.1), Timestamp: ts},
+		{Venue: "kcex", Type: domain.ActivityDeposit, VenueRef: "d1", Asset: "USDT",
+			Amount: decimal.NewFromInt(1000), Timestamp: ts},
+	}
+
+	n, err := store.WriteAccountActivity(batch)
+	if err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("first write inserted %d, want 2", n)
+	}
+
+	// A second import overlapping the first only adds the new event.
+	batch = append(batch, domain.AccountActivity{
+		Venue: "kcex", Type: domain.ActivityTrade, VenueRef: "t2", Symbol: "BTC/USDT", Side: domain.SideSell,
+		Price: decimal.NewFromInt(61000), Size: decimal.NewFromFloat(0.1), Timestamp: ts.Add(time.Hour),
+	})
+	n, err = store.WriteAccountActivity(batch)
+	if err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("second write inserted %d, want 1", n)
+	}
+
+	var total int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM account_activity").Scan(&total); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("rows: got %d, want 3", total)
+	}
+
+	trades, err := store.ListAccountActivity(domain.ActivityTrade)
+	if err != nil {
+		t.Fatalf("list trades: %v", err)
+	}
+	if len(trades) != 2 || trades[0].VenueRef != "t1" || trades[1].VenueRef != "t2" {
+		t.Fatalf("expected trades t1, t2 oldest first, got %+v", trades)
+	}
+	if !trades[1].Price.Equal(decimal.NewFromInt(61000)) || trades[1].Side != domain.SideSell || !trades[1].Timestamp.Equal(ts.Add(time.Hour)) {
+		t.Errorf("unexpected trade round trip: %+v", trades[1])
+	}
+}
+
+func TestSQLiteStoreArchivedOrderRoundTrip(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	order := domain.Order{
+		InternalID:     uuid.New(),
+		VenueID:        "v-1",
+		SignalID:       uuid.New(),
+		Strategy:       domain.StrategyBasisArb,
+		Venue:          "kcex",
+		Account:        "sub-1",
+		Symbol:         "BTCUSDT",
+		InstrumentType: domain.InstrumentPerp,
+		Side:           domain.SideSell,
+		OrderType:      domain.OrderTypeLimit,
+		ReduceOnly:     true,
+		Price:          decimal.RequireFromString("60000.12345678"),
+		Size:           decimal.NewFromFloat(0.25),
+		FilledSize:     decimal.NewFromFloat(0.25),
+		AvgFillPrice:   decimal.RequireFromString("60000.5"),
+		Status:         domain.OrderStatusFilled,
+		UpdatedAt:      time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if err := store.ArchiveOrders([]ArchivedOrder{{Order: order, IdempotencyKey: "sig-1-leg-0"}}); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	// Rows are stored in the order codec's envelope.
+	var raw string
+	if err := store.db.QueryRow("SELECT order_json FROM order_archive WHERE id = ?", order.InternalID.String()).Scan(&raw); err != nil {
+		t.Fatalf("read row: %v", err)
+	}
+	var env domain.Envelope
+	if err := json.Unmarshal([]byte(raw), &env); err != nil || env.Schema != domain.SchemaOrder || env.Version != domain.OrderSchemaVersion {
+		t.Fatalf("expected an order envelope, got %s (%v)", raw, err)
+	}
+
+	got, err := store.LoadArchivedOrder(order.InternalID)
+	if err != nil || got == nil {
+		t.Fatalf("load by id: %v, %v", got, err)
+	}
+	if !got.Order.Price.Equal(order.Price) || got.Order.Status != order.Status || !got.Order.UpdatedAt.Equal(order.UpdatedAt) {
+		t.Errorf("round trip mismatch: got %+v, want %+v", got.Order, order)
+	}
+	if got.Order.SignalID != order.SignalID || got.Order.Strategy != order.Strategy || got.Order.Account != order.Account ||
+		got.Order.InstrumentType != order.InstrumentType || !got.Order.ReduceOnly {
+		t.Errorf("round trip lost order fields: got %+v, want %+v", got.Order, order)
+	}
+
+	if got.IdempotencyKey != "sig-1-leg-0" {
+		t.Errorf("idempotency key: got %q", got.IdempotencyKey)
+	}
+
+	byVenueID, err := store.LoadArchivedOrderByVenueID("kcex", "v-1")
+	if err != nil || byVenueID == nil || byVenueID.Order.InternalID != order.InternalID {
+		t.Fatalf("load by venue id: %+v, %v", byVenueID, err)
+	}
+
+	missing, err := store.LoadArchivedOrder(uuid.New())
+	if err != nil || missing != nil {
+		t.Errorf("expected nil, nil for an unknown order, got %+v, %v", missing, err)
+	}
+	missing, err = store.LoadArchivedOrderByVenueID("nobitex", "v-1")
+	if err != nil || missing != nil {
+		t.Errorf("expected nil, nil for another venue's order id, got %+v, %v", missing, err)
+	}
+}
+
+func TestSQLiteStoreMarketSnapshotRoundTrip(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	if snap, err := store.LoadMarketSnapshot(); err != nil || snap != nil {
+		t.Fatalf("expected nil, nil before any save, got %+v, %v", snap, err)
+	}
+
+	savedAt := time.Now().UTC().Truncate(time.Second)
+	book := domain.OrderBookSnapshot{
+		Venue:    "kcex",
+		Symbol:   "BTC/USDT",
+		Bids:     []domain.PriceLevel{{Price: decimal.RequireFromString("60000.5"), Size: decimal.NewFromInt(2)}},
+		Asks:     []domain.PriceLevel{{Price: decimal.RequireFromString("60001"), Size: decimal.NewFromInt(1)}},
+		Sequence: 42,
+	}
+	rate := domain.FundingRate{Venue: "kcex", Symbol: "BTC/USDT", Rate: decimal.RequireFromString("0.0001")}
+	if err := store.SaveMarketSnapshot(MarketSnapshot{Books: []domain.OrderBookSnapshot{book}, Funding: []domain.FundingRate{rate}, SavedAt: savedAt}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// A later save replaces the whole snapshot, dropping feeds it leaves out.
+	book.Symbol = "ETH/USDT"
+	if err := store.SaveMarketSnapshot(MarketSnapshot{Books: []domain.OrderBookSnapshot{book}, SavedAt: savedAt.Add(time.Minute)}); err != nil {
+		t.Fatalf("save again: %v", err)
+	}
+
+	snap, err := store.LoadMarketSnapshot()
+	if err != nil || snap == nil {
+		t.Fatalf("load: %v, %v", snap, err)
+	}
+	if len(snap.Books) != 1 || snap.Books[0].Symbol != "ETH/USDT" || len(snap.Funding) != 0 {
+		t.Fatalf("expected only the latest save, got %+v", snap)
+	}
+	got := snap.Books[0]
+	if !got.Bids[0].Price.Equal(book.Bids[0].Price) || got.Sequence != 42 {
+		t.Errorf("round trip mismatch: got %+v, want %+v", got, book)
+	}
+	if !snap.SavedAt.Equal(savedAt.Add(time.Minute)) {
+		t.Errorf("saved at: got %v, want %v", snap.SavedAt, savedAt.Add(time.Minute))
+	}
+}
+
+func TestSQLiteStoreListExecutionReports(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	for i, at := range []time.Time{day.Add(-time.Hour), day.Add(time.Hour), day.Add(2 * time.Hour), day.Add(24 * time.Hour)} {
+		report := domain.ExecutionReport{
+			SignalID:    uuid.New(),
+			Strategy:    domain.StrategyTriArb,
+			Venue:       "kcex",
+			SlippageBps: decimal.NewFromInt(int64(i)),
+			Status:      "completed",
+			StartedAt:   at.Add(-150 * time.Millisecond),
+			CompletedAt: at,
+			Legs: []domain.LegExecution{{
+				Symbol: "BTC/USDT", Side: domain.SideBuy,
+				ExpectedPrice: decimal.NewFromInt(60000), ActualPrice: decimal.RequireFromString("60001.5"),
+			}},
+		}
+		if err := store.WriteExecutionReport(report); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := store.WriteExecutionReport("not a report"); err == nil {
+		t.Error("expected an error for a foreign payload")
+	}
+
+	got, err := store.ListExecutionReports(day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 2 || !got[0].SlippageBps.Equal(decimal.NewFromInt(1)) || !got[1].SlippageBps.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected the two reports inside the range, oldest first, got %+v", got)
+	}
+	if got[0].CompletedAt.Sub(got[0].StartedAt) != 150*time.Millisecond {
+		t.Errorf("expected start and completion times to round trip, got %+v", got[0])
+	}
+	if len(got[0].Legs) != 1 || !got[0].Legs[0].ActualPrice.Equal(decimal.RequireFromString("60001.5")) {
+		t.Errorf("expected the legs to round trip, got %+v", got[0].Legs)
+	}
+
+	// Rows are stored in the execution report codec's envelope.
+	var raw string
+	if err := store.db.QueryRow("SELECT report_json FROM execution_reports LIMIT 1").Scan(&raw); err != nil {
+		t.Fatalf("read row: %v", err)
+	}
+	var env domain.Envelope
+	if err := json.Unmarshal([]byte(raw), &env); err != nil || env.Schema != domain.SchemaExecutionReport || env.Version != domain.ExecutionReportSchemaVersion {
+		t.Errorf("expected an execution report envelope, got %s (%v)", raw, err)
+	}
+}
+
+func TestSQLiteStoreListRiskRejections(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	for i, at := range []time.Time{day.Add(-time.Hour), day.Add(time.Hour), day.Add(2 * time.Hour), day.Add(24 * time.Hour)} {
+		rejection := &domain.RiskRejection{
+			SignalID:        uuid.New(),
+			Strategy:        domain.StrategyBasisArb,
+			Venue:           "kcex",
+			Reason:          "position_limit_exceeded",
+			ExpectedEdgeBps: decimal.NewFromInt(int64(i)),
+			NotionalUSDT:    decimal.NewFromInt(5000),
+			RejectedAt:      at,
+		}
+		if err := store.WriteRiskRejection(rejection); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := store.WriteRiskRejection("not a rejection"); err == nil {
+		t.Error("expected an error for a foreign payload")
+	}
+
+	got, err := store.ListRiskRejections(day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 2 || !got[0].ExpectedEdgeBps.Equal(decimal.NewFromInt(1)) || !got[1].ExpectedEdgeBps.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected the two rejections inside the range, oldest first, got %+v", got)
+	}
+	if got[0].Reason != "position_limit_exceeded" || !got[0].NotionalUSDT.Equal(decimal.NewFromInt(5000)) {
+		t.Errorf("expected the rejection to round trip, got %+v", got[0])
+	}
+}
+
+func TestSQLiteStoreIncidentHistory(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	for _, at := range []time.Time{day.Add(-time.Minute), day.Add(time.Minute)} {
+		change := &domain.OrderStateChange{
+			Order:      domain.Order{InternalID: uuid.New(), Venue: "kcex", Symbol: "BTC/USDT"},
+			PrevStatus: domain.OrderStatusAcknowledged,
+			NewStatus:  domain.OrderStatusFilled,
+			Timestamp:  at,
+		}
+		if err := store.WriteOrderEvent(change); err != nil {
+			t.Fatalf("write order event: %v", err)
+		}
+		if err := store.WriteAlert(&AlertRecord{Level: "P1", Name: "venue_unhealthy", FiredAt: at}); err != nil {
+			t.Fatalf("write alert: %v", err)
+		}
+	}
+	events, err := store.ListOrderEvents(day, day.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("list order events: %v", err)
+	}
+	if len(events) != 1 || events[0].NewStatus != domain.OrderStatusFilled || events[0].Order.Symbol != "BTC/USDT" {
+		t.Fatalf("expected the order event inside the range, got %+v", events)
+	}
+	alerts, err := store.ListAlerts(day, day.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("list alerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Name != "venue_unhealthy" {
+		t.Fatalf("expected the alert inside the range, got %+v", alerts)
+	}
+
+	latest, err := store.LatestConfigChange()
+	if err != nil || latest != nil {
+		t.Fatalf("expected no config change yet, got %+v, %v", latest, err)
+	}
+	for i, hash := range []string{"aaaa", "bbbb"} {
+		change := &ConfigChange{
+			Hash:      hash,
+			Source:    "reload",
+			Keys:      []string{"risk.daily_loss_cap_usdt"},
+			Config:    []byte(`{"System":{}}`),
+			ChangedAt: day.Add(time.Duration(i) * time.Minute),
+		}
+		if err := store.WriteConfigChange(change); err != nil {
+			t.Fatalf("write config change: %v", err)
+		}
+	}
+	latest, err = store.LatestConfigChange()
+	if err != nil || latest == nil || latest.Hash != "bbbb" || string(latest.Config) != `{"System":{}}` {
+		t.Fatalf("expected the latest config change, got %+v, %v", latest, err)
+	}
+	changes, err := store.ListConfigChanges(day, day.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("list config changes: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Hash != "aaaa" || len(changes[1].Keys) != 1 {
+		t.Fatalf("expected both config changes, oldest first, got %+v", changes)
+	}
+}
@@ -0,0 +1,182 @@
+package persistence
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Timeline event sources.
+const (
+	TimelineOrder     = "order"
+	TimelineRisk      = "risk"
+	TimelineAlert     = "alert"
+	TimelineExecution = "execution"
+	TimelineConfig    = "config"
+)
+
+// maxTimelineCheckpoints caps the risk checkpoints read for one timeline; at
+// the default interval it is several days of them.
+const maxTimelineCheckpoints = 100000
+
+// TimelineEvent is one entry of a post-incident timeline.
+type TimelineEvent struct {
+	At      time.Time
+	Source  string
+	Summary string
+}
+
+// Timeline merges everything persisted within [since, until) into one
+// chronological list for post-mortems: order state changes, risk mode and
+// kill switch transitions seen in the risk checkpoints, risk rejections,
+// alerts, finished execution cycles and configuration changes. Events at the
+// same instant keep the order of the sources in that list.
+func (s *SQLiteStore) Timeline(since, until time.Time) ([]TimelineEvent, error) {
+	var events []TimelineEvent
+
+	orders, err := s.ListOrderEvents(since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range orders {
+		events = append(events, TimelineEvent{At: c.Timestamp, Source: TimelineOrder, Summary: orderSummary(c)})
+	}
+
+	risk, err := s.riskTransitions(since, until)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, risk...)
+
+	rejections, err := s.ListRiskRejections(since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rejections {
+		summary := fmt.Sprintf("rejected %s signal on %s: %s", r.Strategy, r.Venue, r.Reason)
+		if r.Details != "" {
+			summary += " (" + r.Details + ")"
+		}
+		events = append(events, TimelineEvent{At: r.RejectedAt, Source: TimelineRisk, Summary: summary})
+	}
+
+	alerts, err := s.ListAlerts(since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range alerts {
+		summary := fmt.Sprintf("%s %s: %s", a.Level, a.Name, a.Condition)
+		if a.Message != "" {
+			summary += " - " + a.Message
+		}
+		events = append(events, TimelineEvent{At: a.FiredAt, Source: TimelineAlert, Summary: summary})
+	}
+
+	reports, err := s.ListExecutionReports(since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reports {
+		events = append(events, TimelineEvent{
+			At:     r.CompletedAt,
+			Source: TimelineExecution,
+			Summary: fmt.Sprintf("%s on %s %s in %s: %d legs, edge %s bps expected, %s realized, slippage %s bps",
+				r.Strategy, r.Venue, r.Status, r.CompletedAt.Sub(r.StartedAt).Round(time.Millisecond), len(r.Legs),
+				r.ExpectedEdgeBps.StringFixed(2), r.RealizedEdgeBps.StringFixed(2), r.SlippageBps.StringFixed(2)),
+		})
+	}
+
+	changes, err := s.ListConfigChanges(since, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range changes {
+		summary := fmt.Sprintf("%s with config %s", c.Source, c.Hash)
+		if len(c.Keys) > 0 {
+			summary += ", changed " + strings.Join(c.Keys, ", ")
+		}
+		events = append(events, TimelineEvent{At: c.ChangedAt, Source: TimelineConfig, Summary: summary})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events, nil
+}
+
+// riskTransitions reports the risk mode and kill switch changes between
+// consecutive checkpoints within [since, until]. The last checkpoint before
+// since is the starting point, so a change just before the window is not
+// reported as happening inside it.
+func (s *SQLiteStore) riskTransitions(since, until time.Time) ([]TimelineEvent, error) {
+	prior, err := s.ListCheckpoints(time.Time{}, since, 1)
+	if err != nil {
+		return nil, err
+	}
+	window, err := s.ListCheckpoints(since, until, maxTimelineCheckpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events []TimelineEvent
+		prev   *domain.RiskState
+	)
+	if len(prior) > 0 {
+		prev = prior[0].State
+	}
+	// ListCheckpoints returns newest first.
+	for i := len(window) - 1; i >= 0; i-- {
+		cp := window[i]
+		if !cp.CreatedAt.Before(until) {
+			continue
+		}
+		if prev != nil {
+			if cp.State.Mode != prev.Mode {
+				events = append(events, TimelineEvent{
+					At:      cp.CreatedAt,
+					Source:  TimelineRisk,
+					Summary: fmt.Sprintf("risk mode %s -> %s", prev.Mode, cp.State.Mode),
+				})
+			}
+			switch {
+			case cp.State.KillSwitchActive && !prev.KillSwitchActive:
+				events = append(events, TimelineEvent{
+					At:      cp.CreatedAt,
+					Source:  TimelineRisk,
+					Summary: "kill switch activated: " + cp.State.KillSwitchReason,
+				})
+			case !cp.State.KillSwitchActive && prev.KillSwitchActive:
+				events = append(events, TimelineEvent{At: cp.CreatedAt, Source: TimelineRisk, Summary: "kill switch reset"})
+			}
+		}
+		prev = cp.State
+	}
+	return events, nil
+}
+
+func orderSummary(c domain.OrderStateChange) string {
+	o := c.Order
+	summary := fmt.Sprintf("%s %s %s %s %s", o.Venue, o.Symbol, o.Side, o.OrderType, o.Size)
+	if o.Price.IsPositive() {
+		summary += " @ " + o.Price.String()
+	}
+	summary += fmt.Sprintf(" (%s): %s -> %s", o.InternalID.String()[:8], c.PrevStatus, c.NewStatus)
+	if o.FilledSize.IsPositive() {
+		summary += fmt.Sprintf(", filled %s @ %s", o.FilledSize, o.AvgFillPrice)
+	}
+	return summary
+}
+
+// WriteTimeline writes events as a table, with times in loc.
+func WriteTimeline(w io.Writer, events []TimelineEvent, loc *time.Location) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSOURCE\tEVENT")
+	for _, e := range events {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", e.At.In(loc).Format("2006-01-02 15:04:05.000 MST"), e.Source, e.Summary)
+	}
+	return tw.Flush()
+}
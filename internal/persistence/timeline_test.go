@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestSQLiteStoreTimeline(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	now := time.Now().UTC()
+
+	for _, state := range []*domain.RiskState{
+		{Mode: domain.RiskModeNormal},
+		{Mode: domain.RiskModeNormal},
+		{Mode: domain.RiskModeHalted, KillSwitchActive: true, KillSwitchReason: "daily loss cap"},
+	} {
+		if err := store.WriteRiskCheckpoint(state); err != nil {
+			t.Fatalf("write checkpoint: %v", err)
+		}
+	}
+	order := domain.Order{
+		InternalID: uuid.New(), Venue: "kcex", Symbol: "BTC/USDT", Side: domain.SideBuy,
+		OrderType: domain.OrderTypeLimit, Size: decimal.NewFromInt(1), Price: decimal.NewFromInt(60000),
+	}
+	writes := []error{
+		store.WriteOrderEvent(&domain.OrderStateChange{
+			Order: order, PrevStatus: domain.OrderStatusSubmitted, NewStatus: domain.OrderStatusAcknowledged,
+			Timestamp: now.Add(-30 * time.Minute),
+		}),
+		store.WriteConfigChange(&ConfigChange{
+			Hash: "abcd", Source: "reload", Keys: []string{"risk.daily_loss_cap_usdt"}, ChangedAt: now.Add(-20 * time.Minute),
+		}),
+		store.WriteAlert(&AlertRecord{Level: "P1", Name: "venue_unhealthy", Condition: "kcex down", FiredAt: now.Add(-10 * time.Minute)}),
+		store.WriteAlert(&AlertRecord{Level: "P1", Name: "too_early", FiredAt: now.Add(-2 * time.Hour)}),
+	}
+	for _, err := range writes {
+		if err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	events, err := store.Timeline(now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Source+": "+e.Summary)
+	}
+	want := []string{
+		"order: kcex BTC/USDT BUY LIMIT 1 @ 60000 (" + order.InternalID.String()[:8] + "): SUBMITTED -> ACKNOWLEDGED",
+		"config: reload with config abcd, changed risk.daily_loss_cap_usdt",
+		"alert: P1 venue_unhealthy: kcex down",
+		"risk: risk mode NORMAL -> HALTED",
+		"risk: kill switch activated: daily loss cap",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("timeline:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTimeline(&buf, events, time.UTC); err != nil {
+		t.Fatalf("write timeline: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != len(events)+1 || !strings.HasPrefix(lines[0], "TIME") {
+		t.Errorf("expected a header and one line per event, got:\n%s", buf.String())
+	}
+}
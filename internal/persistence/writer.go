@@ -14,6 +14,12 @@ const (
 	WriteTypeRiskEvent
 	WriteTypeConfigAudit
 	WriteTypeRiskCheckpoint
+	WriteTypeStressReport
+	WriteTypeRiskRejection
+	WriteTypeOrderEvent
+	WriteTypeAlert
+	WriteTypeConfigChange
+	WriteTypeFundingRate
 )
 
 type WriteRequest struct {
@@ -46,7 +52,8 @@ func NewAsyncWriter(
 }
 
 func (w *AsyncWriter) Write(req WriteRequest) {
-	if req.Type == WriteTypeRiskCheckpoint {
+	// Checkpoints and daily PnL closes are never dropped.
+	if req.Type == WriteTypeRiskCheckpoint || req.Type == WriteTypePnL {
 		w.riskCh <- req
 		return
 	}
@@ -87,6 +94,36 @@ func (w *AsyncWriter) handleWrite(req WriteRequest) {
 				w.logger.Error("failed to write risk checkpoint", "error", err)
 			}
 		}
+	case WriteTypeStressReport:
+		if w.sqliteStore != nil {
+			if err := w.sqliteStore.WriteStressReport(req.Payload); err != nil {
+				w.logger.Error("failed to write stress report", "error", err)
+			}
+		}
+	case WriteTypeRiskRejection:
+		if w.sqliteStore != nil {
+			if err := w.sqliteStore.WriteRiskRejection(req.Payload); err != nil {
+				w.logger.Error("failed to write risk rejection", "error", err)
+			}
+		}
+	case WriteTypeOrderEvent:
+		if w.sqliteStore != nil {
+			if err := w.sqliteStore.WriteOrderEvent(req.Payload); err != nil {
+				w.logger.Error("failed to write order event", "error", err)
+			}
+		}
+	case WriteTypeAlert:
+		if w.sqliteStore != nil {
+			if err := w.sqliteStore.WriteAlert(req.Payload); err != nil {
+				w.logger.Error("failed to write alert", "error", err)
+			}
+		}
+	case WriteTypeConfigChange:
+		if w.sqliteStore != nil {
+			if err := w.sqliteStore.WriteConfigChange(req.Payload); err != nil {
+				w.logger.Error("failed to write config change", "error", err)
+			}
+		}
 	case WriteTypeTrade:
 		if w.postgresStore != nil {
 			if err := w.postgresStore.WriteTrade(req.Payload); err != nil {
@@ -94,17 +131,34 @@ func (w *AsyncWriter) handleWrite(req WriteRequest) {
 			}
 		}
 	case WriteTypeCycle:
+		if w.sqliteStore != nil {
+			if err := w.sqliteStore.WriteExecutionReport(req.Payload); err != nil {
+				w.logger.Error("failed to write execution report", "error", err)
+			}
+		}
 		if w.postgresStore != nil {
 			if err := w.postgresStore.WriteCycle(req.Payload); err != nil {
 				w.logger.Error("failed to write cycle", "error", err)
 			}
 		}
+	case WriteTypePnL:
+		if w.postgresStore != nil {
+			if err := w.postgresStore.WriteDailyPnL(req.Payload); err != nil {
+				w.logger.Error("failed to write daily pnl", "error", err)
+			}
+		}
 	case WriteTypeRiskEvent:
 		if w.postgresStore != nil {
 			if err := w.postgresStore.WriteRiskEvent(req.Payload); err != nil {
 				w.logger.Error("failed to write risk event", "error", err)
 			}
 		}
+	case WriteTypeFundingRate:
+		if w.postgresStore != nil {
+			if err := w.postgresStore.WriteFundingRate(req.Payload); err != nil {
+				w.logger.Error("failed to write funding rate", "error", err)
+			}
+		}
 	default:
 		w.logger.Warn("unknown write type", "type", req.Type)
 	}
@@ -0,0 +1,119 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// importWindow is the longest range requested from a venue in one call. Venue
+// history endpoints commonly reject anything wider than a week.
+const importWindow = 7 * 24 * time.Hour
+
+// ActivityStore persists imported account activity, skipping events it has
+// already stored, and reports how many rows were new.
+type ActivityStore interface {
+	WriteAccountActivity(activities []domain.AccountActivity) (int, error)
+}
+
+// ImportResult summarises one venue's import.
+type ImportResult struct {
+	Venue    string
+	Fetched  int
+	Inserted int
+	ByType   map[domain.ActivityType]int
+}
+
+// Importer backfills historical trades, transfers and funding payments from
+// venue account endpoints so a fresh deployment starts with the cost basis
+// and PnL history of the account it takes over.
+type Importer struct {
+	gateways map[string]gateway.VenueGateway
+	store    ActivityStore
+	logger   *slog.Logger
+}
+
+func NewImporter(gateways map[string]gateway.VenueGateway, store ActivityStore, logger *slog.Logger) *Importer {
+	return &Importer{
+		gateways: gateways,
+		store:    store,
+		logger:   logger,
+	}
+}
+
+// Import pulls activity in [since, until) from every gateway that implements
+// gateway.AccountHistoryProvider. Venues without one are logged and skipped.
+// The first venue error aborts the run; rows already written stay, and a
+// rerun over the same range only adds what is missing.
+func (im *Importer) Import(ctx context.Context, since, until time.Time) ([]ImportResult, error) {
+	if !since.Before(until) {
+		return nil, fmt.Errorf("import range is empty: %s to %s", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	names := make([]string, 0, len(im.gateways))
+	for name := range im.gateways {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []ImportResult
+	for _, name := range names {
+		provider, ok := im.gateways[name].(gateway.AccountHistoryProvider)
+		if !ok {
+			im.logger.Info("venue has no account history endpoint, skipping import", "venue", name)
+			continue
+		}
+
+		res, err := im.importVenue(ctx, name, provider, since, until)
+		if err != nil {
+			return results, fmt.Errorf("import %s: %w", name, err)
+		}
+		im.logger.Info("account activity imported",
+			"venue", name,
+			"fetched", res.Fetched,
+			"inserted", res.Inserted,
+			"trades", res.ByType[domain.ActivityTrade],
+			"deposits", res.ByType[domain.ActivityDeposit],
+			"withdrawals", res.ByType[domain.ActivityWithdrawal],
+			"funding", res.ByType[domain.ActivityFunding],
+		)
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (im *Importer) importVenue(ctx context.Context, venue string, provider gateway.AccountHistoryProvider, since, until time.Time) (ImportResult, error) {
+	res := ImportResult{Venue: venue, ByType: make(map[domain.ActivityType]int)}
+
+	for start := since; start.Before(until); start = start.Add(importWindow) {
+		end := start.Add(importWindow)
+		if end.After(until) {
+			end = until
+		}
+
+		activities, err := provider.GetAccountActivity(ctx, start, end)
+		if err != nil {
+			return res, fmt.Errorf("fetch %s to %s: %w", start.Format(time.RFC3339), end.Format(time.RFC3339), err)
+		}
+		if len(activities) == 0 {
+			continue
+		}
+
+		inserted, err := im.store.WriteAccountActivity(activities)
+		if err != nil {
+			return res, fmt.Errorf("store: %w", err)
+		}
+
+		res.Fetched += len(activities)
+		res.Inserted += inserted
+		for _, a := range activities {
+			res.ByType[a.Type]++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package portfolio
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/gateway"
+)
+
+// historyGateway serves canned activity and records the windows it was
+// asked for. The embedded interface covers the methods the importer never
+// calls.
+type historyGateway struct {
+	gateway.VenueGateway
+	activity []domain.AccountActivity
+	windows  [][2]time.Time
+}
+
+func (g *historyGateway) GetAccountActivity(_ context.Context, since, until time.Time) ([]domain.AccountActivity, error) {
+	g.windows = append(g.windows, [2]time.Time{since, until})
+	var out []domain.AccountActivity
+	for _, a := range g.activity {
+		if !a.Timestamp.Before(since) && a.Timestamp.Before(until) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+type memoryActivityStore struct {
+	seen map[string]bool
+}
+
+func (s *memoryActivityStore) WriteAccountActivity(activities []domain.AccountActivity) (int, error) {
+	n := 0
+	for _, a := range activities {
+		key := a.Venue + "|" + string(a.Type) + "|" + a.VenueRef
+		if !s.seen[key] {
+			s.seen[key] = true
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestImporterImport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(10 * 24 * time.Hour)
+
+	kcex := &historyGateway{activity: []domain.AccountActivity{
+		{Venue: "kcex", Type: domain.ActivityTrade, VenueRef: "t1", Timestamp: since.Add(time.Hour)},
+		{Venue: "kcex", Type: domain.ActivityFunding, VenueRef: "f1", Timestamp: since.Add(8 * 24 * time.Hour)},
+		{Venue: "kcex", Type: domain.ActivityDeposit, VenueRef: "d1", Timestamp: since.Add(-time.Hour)},
+	}}
+	gateways := map[string]gateway.VenueGateway{
+		"kcex":    kcex,
+		"binance": &mockVenueGateway{},
+	}
+	store := &memoryActivityStore{seen: make(map[string]bool)}
+
+	im := NewImporter(gateways, store, logger)
+	results, err := im.Import(context.Background(), since, until)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if len(results) != 1 || results[0].Venue != "kcex" {
+		t.Fatalf("results: got %+v, want only kcex", results)
+	}
+	res := results[0]
+	if res.Fetched != 2 || res.Inserted != 2 {
+		t.Errorf("fetched/inserted: got %d/%d, want 2/2", res.Fetched, res.Inserted)
+	}
+	if res.ByType[domain.ActivityTrade] != 1 || res.ByType[domain.ActivityFunding] != 1 {
+		t.Errorf("by type: got %v", res.ByType)
+	}
+
+	// Ten days split into a 7-day window and a 3-day remainder.
+	if len(kcex.windows) != 2 {
+		t.Fatalf("windows: got %d, want 2", len(kcex.windows))
+	}
+	if !kcex.windows[0][1].Equal(kcex.windows[1][0]) || !kcex.windows[1][1].Equal(until) {
+		t.Errorf("windows not contiguous up to until: %v", kcex.windows)
+	}
+
+	// Re-running the same range fetches everything again but stores nothing.
+	results, err = im.Import(context.Background(), since, until)
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if results[0].Inserted != 0 {
+		t.Errorf("second import inserted %d, want 0", results[0].Inserted)
+	}
+}
+
+func TestImporterRejectsEmptyRange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	im := NewImporter(nil, &memoryActivityStore{}, logger)
+
+	now := time.Now()
+	if _, err := im.Import(context.Background(), now, now); err == nil {
+		t.Error("expected error for empty range")
+	}
+}
+
+// mockVenueGateway stands in for a venue without account history.
+type mockVenueGateway struct {
+	gateway.VenueGateway
+}
@@ -2,6 +2,8 @@ package portfolio
 
 import (
 	"log/slog"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,12 +16,15 @@ import (
 type Manager struct {
 	mu sync.RWMutex
 
-	spotBalances map[domain.VenueAssetKey]*domain.Balance
+	spotBalances  map[domain.VenueAssetKey]*domain.Balance
 	perpPositions map[domain.VenueAssetKey]*domain.Position
+	costBasis     map[basisKey]*CostBasis
 
-	realizedPnL   decimal.Decimal
-	unrealizedPnL decimal.Decimal
-	dailyPnLStart time.Time
+	realizedPnL     decimal.Decimal
+	realizedByQuote map[string]decimal.Decimal
+	unrealizedPnL   decimal.Decimal
+	dailyPnLStart   time.Time
+	loc             *time.Location
 
 	mdService *marketdata.Service
 	logger    *slog.Logger
@@ -27,35 +32,38 @@ type Manager struct {
 }
 
 func NewManager(mdService *marketdata.Service, mode string, logger *slog.Logger) *Manager {
-	now := time.Now().UTC()
-	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-
 	return &Manager{
-		spotBalances:  make(map[domain.VenueAssetKey]*domain.Balance),
-		perpPositions: make(map[domain.VenueAssetKey]*domain.Position),
-		dailyPnLStart: dayStart,
-		mdService:     mdService,
-		logger:        logger,
-		mode:          mode,
+		spotBalances:    make(map[domain.VenueAssetKey]*domain.Balance),
+		perpPositions:   make(map[domain.VenueAssetKey]*domain.Position),
+		costBasis:       make(map[basisKey]*CostBasis),
+		realizedByQuote: make(map[string]decimal.Decimal),
+		dailyPnLStart:   domain.TradingDayStart(time.Now(), time.UTC),
+		loc:             time.UTC,
+		mdService:       mdService,
+		logger:          logger,
+		mode:            mode,
 	}
 }
 
-func (m *Manager) UpdateBalance(venue, asset string, free, locked decimal.Decimal) {
-	key := domain.VenueAssetKey{Venue: venue, Asset: asset}
+// UpdateBalance sets the balance of asset held on venue's account, empty
+// for the venue's default account.
+func (m *Manager) UpdateBalance(venue, account, asset string, free, locked decimal.Decimal) {
+	key := domain.VenueAssetKey{Venue: venue, Account: account, Asset: asset}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.spotBalances[key] = &domain.Balance{
-		Venue:  venue,
-		Asset:  asset,
-		Free:   free,
-		Locked: locked,
-		Total:  free.Add(locked),
+		Venue:   venue,
+		Account: account,
+		Asset:   asset,
+		Free:    free,
+		Locked:  locked,
+		Total:   free.Add(locked),
 	}
 }
 
 func (m *Manager) UpdatePosition(pos domain.Position) {
-	key := domain.VenueAssetKey{Venue: pos.Venue, Asset: pos.Asset}
+	key := domain.VenueAssetKey{Venue: pos.Venue, Account: pos.Account, Asset: pos.Asset}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.perpPositions[key] = &pos
@@ -66,7 +74,7 @@ func (m *Manager) OnFillEvent(order domain.Order) {
 	defer m.mu.Unlock()
 
 	asset := extractAsset(order.Symbol)
-	key := domain.VenueAssetKey{Venue: order.Venue, Asset: asset}
+	key := domain.VenueAssetKey{Venue: order.Venue, Account: order.Account, Asset: asset}
 
 	if bal, ok := m.spotBalances[key]; ok {
 		if order.Side == domain.SideBuy {
@@ -80,6 +88,114 @@ func (m *Manager) OnFillEvent(order domain.Order) {
 	}
 }
 
+// CostBasis is the quantity bought on one venue and symbol that has not been
+// sold again, and its average cost in the symbol's quote currency.
+type CostBasis struct {
+	Size    decimal.Decimal
+	AvgCost decimal.Decimal
+}
+
+type basisKey struct {
+	venue  string
+	symbol string
+}
+
+// LoadTrades replays historical trades, such as those stored by the
+// Importer, into the cost basis on average cost. Each sale realizes its
+// proceeds over the average cost of the quantity sold, net of fees paid in
+// the quote currency. Realized PnL is kept per quote currency; USDT-quoted
+// sales inside the current trading day also count towards the daily
+// realized PnL. Call once at startup, before live fills arrive.
+func (m *Manager) LoadTrades(trades []domain.AccountActivity) {
+	sorted := make([]domain.AccountActivity, 0, len(trades))
+	for _, t := range trades {
+		if t.Type == domain.ActivityTrade {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range sorted {
+		quote := quoteCurrency(t.Symbol)
+		pnl := m.applyTradeLocked(t, quote)
+		if pnl.IsZero() {
+			continue
+		}
+		m.realizedByQuote[quote] = m.realizedByQuote[quote].Add(pnl)
+		if quote == "USDT" && !t.Timestamp.Before(m.dailyPnLStart) {
+			m.realizedPnL = m.realizedPnL.Add(pnl)
+		}
+	}
+}
+
+// applyTradeLocked moves the cost basis by one trade and returns the PnL it
+// realizes. A sale beyond the held quantity realizes nothing on the excess.
+func (m *Manager) applyTradeLocked(t domain.AccountActivity, quote string) decimal.Decimal {
+	key := basisKey{venue: t.Venue, symbol: t.Symbol}
+	basis, ok := m.costBasis[key]
+	if !ok {
+		basis = &CostBasis{}
+		m.costBasis[key] = basis
+	}
+
+	quoteFee := decimal.Zero
+	size := t.Size
+	switch t.FeeCurrency {
+	case quote:
+		quoteFee = t.Fee
+	case extractAsset(t.Symbol):
+		if t.Side == domain.SideBuy {
+			size = size.Sub(t.Fee)
+		}
+	}
+
+	if t.Side == domain.SideBuy {
+		held := basis.Size.Add(size)
+		if held.IsPositive() {
+			cost := basis.AvgCost.Mul(basis.Size).Add(t.Price.Mul(t.Size)).Add(quoteFee)
+			basis.AvgCost = cost.Div(held)
+		}
+		basis.Size = held
+		return decimal.Zero
+	}
+
+	sold := decimal.Min(size, basis.Size)
+	if !sold.IsPositive() {
+		return quoteFee.Neg()
+	}
+	pnl := t.Price.Sub(basis.AvgCost).Mul(sold).Sub(quoteFee)
+	basis.Size = basis.Size.Sub(sold)
+	if basis.Size.IsZero() {
+		basis.AvgCost = decimal.Zero
+	}
+	return pnl
+}
+
+// GetCostBasis returns the cost basis held on a venue in symbol.
+func (m *Manager) GetCostBasis(venue, symbol string) (CostBasis, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	basis, ok := m.costBasis[basisKey{venue: venue, symbol: symbol}]
+	if !ok {
+		return CostBasis{}, false
+	}
+	return *basis, true
+}
+
+// RealizedPnLByQuote returns the PnL realized by loaded trades in each quote
+// currency since the start of their history.
+func (m *Manager) RealizedPnLByQuote() map[string]decimal.Decimal {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]decimal.Decimal, len(m.realizedByQuote))
+	for quote, pnl := range m.realizedByQuote {
+		out[quote] = pnl
+	}
+	return out
+}
+
 func (m *Manager) AddRealizedPnL(pnl decimal.Decimal) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -127,8 +243,8 @@ func (m *Manager) GetNetExposure(asset string) decimal.Decimal {
 	return total
 }
 
-func (m *Manager) GetBalance(venue, asset string) (*domain.Balance, bool) {
-	key := domain.VenueAssetKey{Venue: venue, Asset: asset}
+func (m *Manager) GetBalance(venue, account, asset string) (*domain.Balance, bool) {
+	key := domain.VenueAssetKey{Venue: venue, Account: account, Asset: asset}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -140,8 +256,8 @@ func (m *Manager) GetBalance(venue, asset string) (*domain.Balance, bool) {
 	return &b, true
 }
 
-func (m *Manager) GetPosition(venue, asset string) (*domain.Position, bool) {
-	key := domain.VenueAssetKey{Venue: venue, Asset: asset}
+func (m *Manager) GetPosition(venue, account, asset string) (*domain.Position, bool) {
+	key := domain.VenueAssetKey{Venue: venue, Account: account, Asset: asset}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -176,14 +292,27 @@ func (m *Manager) ResetDaily() {
 	defer m.mu.Unlock()
 	m.realizedPnL = decimal.Zero
 	m.unrealizedPnL = decimal.Zero
-	m.dailyPnLStart = todayUTC()
+	m.dailyPnLStart = domain.TradingDayStart(time.Now(), m.loc)
 }
 
-func todayUTC() time.Time {
-	now := time.Now().UTC()
-	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+// SetTradingLocation sets the timezone whose midnight starts a new trading
+// day for the daily PnL window.
+func (m *Manager) SetTradingLocation(loc *time.Location) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loc = loc
+	m.dailyPnLStart = domain.TradingDayStart(time.Now(), loc)
 }
 
 func extractAsset(symbol string) string {
 	return domain.ExtractAsset(symbol)
 }
+
+// quoteCurrency returns the quote currency of symbol. Symbols without a
+// separator are quoted in USDT.
+func quoteCurrency(symbol string) string {
+	if idx := strings.IndexByte(symbol, '/'); idx >= 0 {
+		return symbol[idx+1:]
+	}
+	return "USDT"
+}
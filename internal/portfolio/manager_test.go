@@ -23,9 +23,9 @@ func newTestManager() *Manager {
 func TestUpdateBalance(t *testing.T) {
 	mgr := newTestManager()
 
-	mgr.UpdateBalance("nobitex", "BTC", decimal.NewFromFloat(1.5), decimal.NewFromFloat(0.5))
+	mgr.UpdateBalance("nobitex", "", "BTC", decimal.NewFromFloat(1.5), decimal.NewFromFloat(0.5))
 
-	bal, ok := mgr.GetBalance("nobitex", "BTC")
+	bal, ok := mgr.GetBalance("nobitex", "", "BTC")
 	if !ok {
 		t.Fatal("expected to find balance")
 	}
@@ -43,7 +43,7 @@ func TestUpdateBalance(t *testing.T) {
 func TestGetBalanceNotFound(t *testing.T) {
 	mgr := newTestManager()
 
-	_, ok := mgr.GetBalance("nobitex", "DOGE")
+	_, ok := mgr.GetBalance("nobitex", "", "DOGE")
 	if ok {
 		t.Error("expected not to find non-existent balance")
 	}
@@ -61,7 +61,7 @@ func TestUpdatePosition(t *testing.T) {
 	}
 	mgr.UpdatePosition(pos)
 
-	got, ok := mgr.GetPosition("kcex", "BTC")
+	got, ok := mgr.GetPosition("kcex", "", "BTC")
 	if !ok {
 		t.Fatal("expected to find position")
 	}
@@ -73,7 +73,7 @@ func TestUpdatePosition(t *testing.T) {
 func TestGetPositionNotFound(t *testing.T) {
 	mgr := newTestManager()
 
-	_, ok := mgr.GetPosition("kcex", "DOGE")
+	_, ok := mgr.GetPosition("kcex", "", "DOGE")
 	if ok {
 		t.Error("expected not to find non-existent position")
 	}
@@ -82,7 +82,7 @@ func TestGetPositionNotFound(t *testing.T) {
 func TestOnFillEventBuy(t *testing.T) {
 	mgr := newTestManager()
 
-	mgr.UpdateBalance("nobitex", "BTC",
+	mgr.UpdateBalance("nobitex", "", "BTC",
 		decimal.NewFromFloat(100000),
 		decimal.Zero)
 
@@ -95,17 +95,41 @@ func TestOnFillEventBuy(t *testing.T) {
 	}
 	mgr.OnFillEvent(order)
 
-	bal, _ := mgr.GetBalance("nobitex", "BTC")
+	bal, _ := mgr.GetBalance("nobitex", "", "BTC")
 	expected := decimal.NewFromFloat(100000).Sub(decimal.NewFromFloat(0.5).Mul(decimal.NewFromInt(50000)))
 	if !bal.Free.Equal(expected) {
 		t.Errorf("expected free %s, got %s", expected, bal.Free)
 	}
 }
 
+func TestOnFillEventKeepsAccountsApart(t *testing.T) {
+	mgr := newTestManager()
+
+	mgr.UpdateBalance("nobitex", "", "BTC", decimal.NewFromInt(100000), decimal.Zero)
+	mgr.UpdateBalance("nobitex", "basis", "BTC", decimal.NewFromInt(50000), decimal.Zero)
+
+	mgr.OnFillEvent(domain.Order{
+		Venue:        "nobitex",
+		Account:      "basis",
+		Symbol:       "BTC/USDT",
+		Side:         domain.SideBuy,
+		FilledSize:   decimal.NewFromFloat(0.5),
+		AvgFillPrice: decimal.NewFromInt(50000),
+	})
+
+	if bal, _ := mgr.GetBalance("nobitex", "", "BTC"); !bal.Free.Equal(decimal.NewFromInt(100000)) {
+		t.Errorf("default account free = %s, want it untouched", bal.Free)
+	}
+	bal, _ := mgr.GetBalance("nobitex", "basis", "BTC")
+	if !bal.Free.Equal(decimal.NewFromInt(25000)) || bal.Account != "basis" {
+		t.Errorf("basis account = %+v, want 25000 free", bal)
+	}
+}
+
 func TestOnFillEventSell(t *testing.T) {
 	mgr := newTestManager()
 
-	mgr.UpdateBalance("nobitex", "ETH",
+	mgr.UpdateBalance("nobitex", "", "ETH",
 		decimal.NewFromFloat(10000),
 		decimal.Zero)
 
@@ -118,7 +142,7 @@ func TestOnFillEventSell(t *testing.T) {
 	}
 	mgr.OnFillEvent(order)
 
-	bal, _ := mgr.GetBalance("nobitex", "ETH")
+	bal, _ := mgr.GetBalance("nobitex", "", "ETH")
 	expected := decimal.NewFromFloat(10000).Add(decimal.NewFromFloat(1).Mul(decimal.NewFromInt(3000)))
 	if !bal.Free.Equal(expected) {
 		t.Errorf("expected free %s, got %s", expected, bal.Free)
@@ -188,3 +212,47 @@ func TestGetAllPositions(t *testing.T) {
 		t.Errorf("expected 2 positions, got %d", len(all))
 	}
 }
+
+func TestLoadTradesBuildsCostBasis(t *testing.T) {
+	mgr := newTestManager()
+	yesterday := time.Now().Add(-48 * time.Hour)
+	trade := func(ref, symbol string, side domain.Side, price, size, fee float64, feeCurrency string, at time.Time) domain.AccountActivity {
+		return domain.AccountActivity{
+			Venue: "kcex", Type: domain.ActivityTrade, VenueRef: ref, Symbol: symbol, Side: side,
+			Price: decimal.NewFromFloat(price), Size: decimal.NewFromFloat(size),
+			Fee: decimal.NewFromFloat(fee), FeeCurrency: feeCurrency, Timestamp: at,
+		}
+	}
+
+	mgr.LoadTrades([]domain.AccountActivity{
+		// Out of order on purpose: trades are replayed by time.
+		trade("t3", "BTC/USDT", domain.SideSell, 62000, 1, 6, "USDT", time.Now()),
+		trade("t1", "BTC/USDT", domain.SideBuy, 60000, 1, 0, "", yesterday),
+		trade("t2", "BTC/USDT", domain.SideBuy, 63000, 1, 3, "USDT", yesterday.Add(time.Hour)),
+		trade("t4", "BTC/IRT", domain.SideBuy, 6e9, 0.5, 0, "", yesterday),
+		trade("t5", "BTC/IRT", domain.SideSell, 7e9, 0.5, 0, "", yesterday),
+		{Venue: "kcex", Type: domain.ActivityDeposit, VenueRef: "d1", Asset: "BTC", Amount: decimal.NewFromInt(5), Timestamp: yesterday},
+	})
+
+	// Bought 1 @ 60000 and 1 @ 63000 plus a 3 USDT fee: 61501.5 average.
+	basis, ok := mgr.GetCostBasis("kcex", "BTC/USDT")
+	if !ok {
+		t.Fatal("expected a BTC/USDT cost basis")
+	}
+	if !basis.Size.Equal(decimal.NewFromInt(1)) || !basis.AvgCost.Equal(decimal.NewFromFloat(61501.5)) {
+		t.Errorf("expected 1 BTC at 61501.5, got %s at %s", basis.Size, basis.AvgCost)
+	}
+
+	// Today's sale realizes (62000 - 61501.5) - 6 USDT.
+	if got := mgr.DailyRealizedPnL(); !got.Equal(decimal.NewFromFloat(492.5)) {
+		t.Errorf("expected 492.5 daily realized PnL, got %s", got)
+	}
+	byQuote := mgr.RealizedPnLByQuote()
+	if !byQuote["USDT"].Equal(decimal.NewFromFloat(492.5)) {
+		t.Errorf("expected 492.5 USDT realized, got %s", byQuote["USDT"])
+	}
+	// IRT PnL is kept apart and never reaches the USDT daily figure.
+	if !byQuote["IRT"].Equal(decimal.NewFromInt(5e8)) {
+		t.Errorf("expected 5e8 IRT realized, got %s", byQuote["IRT"])
+	}
+}
@@ -14,6 +14,7 @@ import (
 type Reconciler struct {
 	manager    *Manager
 	gateways   map[string]gateway.VenueGateway
+	accounts   map[domain.VenueAccount]gateway.VenueGateway
 	interval   time.Duration
 	threshold  float64
 	logger     *slog.Logger
@@ -40,6 +41,13 @@ func (r *Reconciler) SetMismatchCallback(fn func(venue string)) {
 	r.onMismatch = fn
 }
 
+// SetAccountGateways adds the gateways of venue sub-accounts other than the
+// default one, whose holdings are reconciled under their account. Call
+// before Run.
+func (r *Reconciler) SetAccountGateways(accounts map[domain.VenueAccount]gateway.VenueGateway) {
+	r.accounts = accounts
+}
+
 func (r *Reconciler) Run(ctx context.Context) {
 	ticker := time.NewTicker(r.interval)
 	defer ticker.Stop()
@@ -56,22 +64,29 @@ func (r *Reconciler) Run(ctx context.Context) {
 
 func (r *Reconciler) reconcileAll(ctx context.Context) {
 	for name, gw := range r.gateways {
-		r.reconcileVenue(ctx, name, gw)
+		r.reconcileVenue(ctx, domain.VenueAccount{Venue: name}, gw)
+	}
+	for key, gw := range r.accounts {
+		r.reconcileVenue(ctx, key, gw)
 	}
 }
 
-func (r *Reconciler) reconcileVenue(ctx context.Context, venue string, gw gateway.VenueGateway) {
+// reconcileVenue checks the holdings of one venue account. They are keyed by
+// the account the order manager routes to, so the default account stays
+// under the empty account whatever sub-account the gateway is tagged with.
+func (r *Reconciler) reconcileVenue(ctx context.Context, acct domain.VenueAccount, gw gateway.VenueGateway) {
+	venue, account := acct.Venue, acct.Account
 	balances, err := gw.GetBalances(ctx)
 	if err != nil {
 		r.logger.Error("reconciliation: failed to get balances",
-			"venue", venue, "error", err)
+			"venue", venue, "account", account, "error", err)
 		return
 	}
 
 	for asset, venueBal := range balances {
-		internalBal, ok := r.manager.GetBalance(venue, asset)
+		internalBal, ok := r.manager.GetBalance(venue, account, asset)
 		if !ok {
-			r.manager.UpdateBalance(venue, asset, venueBal.Free, venueBal.Locked)
+			r.manager.UpdateBalance(venue, account, asset, venueBal.Free, venueBal.Locked)
 			continue
 		}
 
@@ -82,6 +97,7 @@ func (r *Reconciler) reconcileVenue(ctx context.Context, venue string, gw gatewa
 			if pct.GreaterThan(decimal.NewFromFloat(r.threshold)) {
 				r.logger.Error("reconciliation mismatch detected",
 					"venue", venue,
+					"account", account,
 					"asset", asset,
 					"internal", internalBal.Total.String(),
 					"venue_actual", venueBal.Total.String(),
@@ -94,18 +110,19 @@ func (r *Reconciler) reconcileVenue(ctx context.Context, venue string, gw gatewa
 			}
 		}
 
-		r.manager.UpdateBalance(venue, asset, venueBal.Free, venueBal.Locked)
+		r.manager.UpdateBalance(venue, account, asset, venueBal.Free, venueBal.Locked)
 	}
 
 	positions, err := gw.GetPositions(ctx)
 	if err != nil {
 		r.logger.Error("reconciliation: failed to get positions",
-			"venue", venue, "error", err)
+			"venue", venue, "account", account, "error", err)
 		return
 	}
 
 	for _, venuePos := range positions {
-		internalPos, ok := r.manager.GetPosition(venue, venuePos.Asset)
+		venuePos.Venue, venuePos.Account = venue, account
+		internalPos, ok := r.manager.GetPosition(venue, account, venuePos.Asset)
 		if !ok {
 			r.manager.UpdatePosition(venuePos)
 			continue
@@ -118,6 +135,7 @@ func (r *Reconciler) reconcileVenue(ctx context.Context, venue string, gw gatewa
 			if pct.GreaterThan(decimal.NewFromFloat(r.threshold)) {
 				r.logger.Error("position reconciliation mismatch",
 					"venue", venue,
+					"account", account,
 					"asset", venuePos.Asset,
 					"internal_size", internalPos.Size.String(),
 					"venue_size", venuePos.Size.String(),
@@ -132,6 +150,7 @@ func (r *Reconciler) reconcileVenue(ctx context.Context, venue string, gw gatewa
 
 		r.manager.UpdatePosition(domain.Position{
 			Venue:          venue,
+			Account:        account,
 			Asset:          venuePos.Asset,
 			InstrumentType: venuePos.InstrumentType,
 			Size:           venuePos.Size,
@@ -142,5 +161,5 @@ func (r *Reconciler) reconcileVenue(ctx context.Context, venue string, gw gatewa
 		})
 	}
 
-	r.logger.Debug("reconciliation completed", "venue", venue)
+	r.logger.Debug("reconciliation completed", "venue", venue, "account", account)
 }
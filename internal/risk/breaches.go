@@ -0,0 +1,71 @@
+package risk
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// LimitBreaches sums up the signals one limit rejected over a period.
+type LimitBreaches struct {
+	Reason RejectionReason `json:"reason"`
+	Count  int             `json:"count"`
+	// ForgoneEdgeUSDT is what the rejected signals expected to make.
+	ForgoneEdgeUSDT decimal.Decimal `json:"forgone_edge_usdt"`
+	// ByHour counts the rejections per hour of day in the report's zone.
+	ByHour   [24]int        `json:"by_hour"`
+	PeakHour int            `json:"peak_hour"`
+	ByVenue  map[string]int `json:"by_venue"`
+}
+
+// BreachReport shows which risk limits bind most often, when, and at what
+// cost in forgone edge, to guide limit tuning.
+type BreachReport struct {
+	Since  time.Time       `json:"since"`
+	Until  time.Time       `json:"until"`
+	Total  int             `json:"total"`
+	Limits []LimitBreaches `json:"limits"` // most frequent first
+}
+
+// BuildBreachReport aggregates rejections made within [since, until) per
+// limit, bucketing them by hour of day in loc.
+func BuildBreachReport(rejections []domain.RiskRejection, since, until time.Time, loc *time.Location) BreachReport {
+	report := BreachReport{Since: since, Until: until}
+	byReason := make(map[RejectionReason]*LimitBreaches)
+	for _, r := range rejections {
+		if r.RejectedAt.Before(since) || !r.RejectedAt.Before(until) {
+			continue
+		}
+		reason := RejectionReason(r.Reason)
+		lb, ok := byReason[reason]
+		if !ok {
+			lb = &LimitBreaches{Reason: reason, ByVenue: make(map[string]int)}
+			byReason[reason] = lb
+		}
+		lb.Count++
+		lb.ForgoneEdgeUSDT = lb.ForgoneEdgeUSDT.Add(r.ForgoneEdge())
+		lb.ByHour[r.RejectedAt.In(loc).Hour()]++
+		lb.ByVenue[r.Venue]++
+		report.Total++
+	}
+
+	for _, lb := range byReason {
+		for h, n := range lb.ByHour {
+			if n > lb.ByHour[lb.PeakHour] {
+				lb.PeakHour = h
+			}
+		}
+		report.Limits = append(report.Limits, *lb)
+	}
+	sort.Slice(report.Limits, func(i, j int) bool {
+		a, b := report.Limits[i], report.Limits[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+	return report
+}
@@ -0,0 +1,57 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestBuildBreachReport(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	rejection := func(reason RejectionReason, venue string, hour int, edgeBps, notional int64) domain.RiskRejection {
+		return domain.RiskRejection{
+			Reason:          string(reason),
+			Venue:           venue,
+			ExpectedEdgeBps: decimal.NewFromInt(edgeBps),
+			NotionalUSDT:    decimal.NewFromInt(notional),
+			RejectedAt:      day.Add(time.Duration(hour)*time.Hour + time.Minute),
+		}
+	}
+
+	report := BuildBreachReport([]domain.RiskRejection{
+		rejection(RejectNotionalLimit, "kcex", 9, 20, 10000),
+		rejection(RejectPositionLimit, "kcex", 14, 30, 20000),
+		rejection(RejectPositionLimit, "nobitex", 14, 10, 10000),
+		rejection(RejectPositionLimit, "kcex", 3, 10, 10000),
+		rejection(RejectDailyLoss, "kcex", 26, 50, 10000), // the next day
+	}, day, day.AddDate(0, 0, 1), time.UTC)
+
+	if report.Total != 4 || len(report.Limits) != 2 {
+		t.Fatalf("expected 4 rejections over 2 limits, got %d over %d", report.Total, len(report.Limits))
+	}
+	pos := report.Limits[0]
+	if pos.Reason != RejectPositionLimit || pos.Count != 3 {
+		t.Errorf("expected the position limit first with 3, got %s with %d", pos.Reason, pos.Count)
+	}
+	// 30 bps of 20000 plus 10 bps of 10000 twice.
+	if !pos.ForgoneEdgeUSDT.Equal(decimal.NewFromInt(80)) {
+		t.Errorf("expected 80 USDT forgone, got %s", pos.ForgoneEdgeUSDT)
+	}
+	if pos.PeakHour != 14 || pos.ByHour[14] != 2 || pos.ByHour[3] != 1 {
+		t.Errorf("expected a peak of 2 at 14:00, got peak %d with hours %v", pos.PeakHour, pos.ByHour)
+	}
+	if pos.ByVenue["kcex"] != 2 || pos.ByVenue["nobitex"] != 1 {
+		t.Errorf("unexpected venue split %v", pos.ByVenue)
+	}
+
+	// Hours are taken in the report's zone.
+	tehran := time.FixedZone("IRST", 3*3600+1800)
+	report = BuildBreachReport([]domain.RiskRejection{rejection(RejectNotionalLimit, "kcex", 9, 20, 10000)},
+		day, day.AddDate(0, 0, 1), tehran)
+	if report.Limits[0].PeakHour != 12 {
+		t.Errorf("expected 09:01 UTC to be hour 12 in Tehran, got %d", report.Limits[0].PeakHour)
+	}
+}
@@ -0,0 +1,120 @@
+package risk
+
+import (
+	"sync"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/config"
+)
+
+// SLI names one of the service level indicators feeding the error budget.
+type SLI string
+
+const (
+	SLIAckLatency    SLI = "ack_latency"
+	SLIDataFreshness SLI = "data_freshness"
+	SLIOrderRejects  SLI = "order_rejects"
+)
+
+var allSLIs = []SLI{SLIAckLatency, SLIDataFreshness, SLIOrderRejects}
+
+// minBudgetEvents is how many events an SLI needs in the window before its
+// burn rate counts; below that a single miss would exhaust the budget.
+const minBudgetEvents = 20
+
+// budgetBucket is one minute of good/total counts per SLI.
+type budgetBucket struct {
+	start time.Time
+	good  map[SLI]int
+	total map[SLI]int
+}
+
+// ErrorBudget tracks SLO misses over a sliding window. An SLI's burn rate is
+// its miss ratio divided by the misses its target allows, so a burn rate of
+// 1 spends exactly the window's budget. The budget left is 1 minus the worst
+// burn rate across SLIs.
+type ErrorBudget struct {
+	mu      sync.Mutex
+	window  time.Duration
+	targets map[SLI]float64 // fraction of events that must be good
+	buckets []budgetBucket
+}
+
+func NewErrorBudget(cfg config.ErrorBudgetConfig) *ErrorBudget {
+	return &ErrorBudget{
+		window: cfg.Window(),
+		targets: map[SLI]float64{
+			SLIAckLatency:    cfg.LatencyTargetPct / 100,
+			SLIDataFreshness: cfg.FreshnessTargetPct / 100,
+			SLIOrderRejects:  cfg.RejectTargetPct / 100,
+		},
+	}
+}
+
+// Record adds good and total events for sli at time at.
+func (b *ErrorBudget) Record(sli SLI, good, total int, at time.Time) {
+	if total <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start := at.Truncate(time.Minute)
+	n := len(b.buckets)
+	if n == 0 || b.buckets[n-1].start.Before(start) {
+		b.buckets = append(b.buckets, budgetBucket{
+			start: start,
+			good:  make(map[SLI]int),
+			total: make(map[SLI]int),
+		})
+		n++
+	}
+	b.buckets[n-1].good[sli] += good
+	b.buckets[n-1].total[sli] += total
+	b.prune(at)
+}
+
+func (b *ErrorBudget) prune(now time.Time) {
+	cutoff := now.Add(-b.window)
+	i := 0
+	for i < len(b.buckets) && !b.buckets[i].start.Add(time.Minute).After(cutoff) {
+		i++
+	}
+	b.buckets = b.buckets[i:]
+}
+
+// BurnRates returns the burn rate of each SLI with enough events in the
+// window ending at now.
+func (b *ErrorBudget) BurnRates(now time.Time) map[SLI]float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(now)
+
+	rates := make(map[SLI]float64, len(allSLIs))
+	for _, sli := range allSLIs {
+		good, total := 0, 0
+		for _, bk := range b.buckets {
+			good += bk.good[sli]
+			total += bk.total[sli]
+		}
+		allowed := 1 - b.targets[sli]
+		if total < minBudgetEvents || allowed <= 0 {
+			continue
+		}
+		missRatio := float64(total-good) / float64(total)
+		rates[sli] = missRatio / allowed
+	}
+	return rates
+}
+
+// Remaining returns the fraction of the error budget left, which goes
+// negative once an SLI overspends, along with the SLI burning fastest.
+func (b *ErrorBudget) Remaining(now time.Time) (float64, SLI) {
+	worst, worstSLI := 0.0, SLI("")
+	for sli, rate := range b.BurnRates(now) {
+		if rate > worst {
+			worst, worstSLI = rate, sli
+		}
+	}
+	return 1 - worst, worstSLI
+}
@@ -0,0 +1,112 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func testErrorBudgetConfig() config.ErrorBudgetConfig {
+	return config.ErrorBudgetConfig{
+		Enabled:            true,
+		WindowMinutes:      60,
+		AckLatencyMs:       250,
+		LatencyTargetPct:   99,
+		FreshnessTargetPct: 99,
+		RejectTargetPct:    90,
+		RecoverPct:         50,
+	}
+}
+
+func TestErrorBudgetBurnRate(t *testing.T) {
+	b := NewErrorBudget(testErrorBudgetConfig())
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// 5 rejects in 100 orders against a 10% allowance burns half the budget.
+	b.Record(SLIOrderRejects, 95, 100, now)
+	remaining, sli := b.Remaining(now)
+	if sli != SLIOrderRejects || remaining < 0.49 || remaining > 0.51 {
+		t.Errorf("remaining: got %.3f (%s), want 0.5 (order_rejects)", remaining, sli)
+	}
+
+	// Too few events do not count, however bad.
+	b.Record(SLIAckLatency, 0, minBudgetEvents-1, now)
+	if _, ok := b.BurnRates(now)[SLIAckLatency]; ok {
+		t.Error("ack latency burn rate counted below minBudgetEvents")
+	}
+
+	// Events age out of the window.
+	later := now.Add(61 * time.Minute)
+	if remaining, _ := b.Remaining(later); remaining != 1 {
+		t.Errorf("remaining after window: got %.3f, want 1", remaining)
+	}
+}
+
+func TestManagerErrorBudgetConservativeMode(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.cfg.ErrorBudget = testErrorBudgetConfig()
+	mgr.errorBudget = NewErrorBudget(mgr.cfg.ErrorBudget)
+
+	now := time.Now()
+	change := func(status domain.OrderStatus, latency time.Duration) domain.OrderStateChange {
+		return domain.OrderStateChange{
+			Order:      domain.Order{Venue: "nobitex", Symbol: "BTC/USDT", CreatedAt: now.Add(-latency)},
+			PrevStatus: domain.OrderStatusSubmitted,
+			NewStatus:  status,
+			Timestamp:  now,
+		}
+	}
+
+	// 20 acks, 5 of them slow: 25% misses against a 1% latency allowance.
+	for i := 0; i < 20; i++ {
+		latency := 10 * time.Millisecond
+		if i < 5 {
+			latency = time.Second
+		}
+		mgr.OnOrderStateChange(change(domain.OrderStatusAcknowledged, latency))
+	}
+
+	mgr.mu.Lock()
+	mgr.checkErrorBudget(now)
+	mgr.mu.Unlock()
+	if !mgr.IsConservative() {
+		t.Fatalf("expected conservative mode, got %s", mgr.GetMode())
+	}
+
+	// Once the slow acks leave the window the budget is back and the mode
+	// steps down to WARNING, then to NORMAL once it has stayed recovered.
+	recoveredAt := now.Add(2 * time.Hour)
+	mgr.mu.Lock()
+	mgr.checkErrorBudget(recoveredAt)
+	mgr.mu.Unlock()
+	if mgr.GetMode() != domain.RiskModeWarning {
+		t.Fatalf("expected WARNING after recovery, got %s", mgr.GetMode())
+	}
+	mgr.mu.Lock()
+	mgr.checkErrorBudget(recoveredAt.Add(budgetRecoveryDwell / 2))
+	mgr.mu.Unlock()
+	if mgr.GetMode() != domain.RiskModeWarning {
+		t.Fatalf("expected WARNING during the dwell, got %s", mgr.GetMode())
+	}
+	mgr.mu.Lock()
+	mgr.checkErrorBudget(recoveredAt.Add(budgetRecoveryDwell))
+	mgr.mu.Unlock()
+	if mgr.GetMode() != domain.RiskModeNormal {
+		t.Errorf("expected NORMAL after the dwell, got %s", mgr.GetMode())
+	}
+
+	// A halt is never downgraded to conservative mode.
+	mgr.ActivateKillSwitch("test")
+	defer mgr.DeactivateKillSwitch()
+	for i := 0; i < 20; i++ {
+		mgr.OnOrderStateChange(change(domain.OrderStatusRejected, 0))
+	}
+	mgr.mu.Lock()
+	mgr.checkErrorBudget(now)
+	mgr.mu.Unlock()
+	if mgr.GetMode() != domain.RiskModeHalted {
+		t.Errorf("expected HALTED to stick, got %s", mgr.GetMode())
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/crypto-trading/trading/internal/domain"
 )
 
 type KillSwitch struct {
@@ -13,18 +16,66 @@ type KillSwitch struct {
 	active   bool
 	reason   string
 	activatedAt time.Time
+	scoped   map[KillScope]scopedHalt // halts narrower than the whole system
 	filePath string
 	logger   *slog.Logger
 }
 
+// KillScope narrows a kill switch activation to one venue, one strategy, or
+// one strategy on one venue. The zero KillScope is the whole system.
+type KillScope struct {
+	Venue    string              `json:"venue,omitempty"`
+	Strategy domain.StrategyType `json:"strategy,omitempty"`
+}
+
+// IsGlobal reports whether s covers the whole system.
+func (s KillScope) IsGlobal() bool {
+	return s.Venue == "" && s.Strategy == ""
+}
+
+// covers reports whether s halts strategy's signals on venue.
+func (s KillScope) covers(venue string, strategy domain.StrategyType) bool {
+	return (s.Venue == "" || s.Venue == venue) && (s.Strategy == "" || s.Strategy == strategy)
+}
+
+func (s KillScope) String() string {
+	var parts []string
+	if s.Venue != "" {
+		parts = append(parts, "venue "+s.Venue)
+	}
+	if s.Strategy != "" {
+		parts = append(parts, "strategy "+string(s.Strategy))
+	}
+	if len(parts) == 0 {
+		return "global"
+	}
+	return strings.Join(parts, ", ")
+}
+
+type scopedHalt struct {
+	Reason      string    `json:"reason"`
+	ActivatedAt time.Time `json:"activated_at"`
+}
+
+// killSwitchState is the kill switch file. Active is the global switch.
+// Another process writing Venue or Strategy alongside Active halts only that
+// scope; halts in force are persisted in Scoped.
 type killSwitchState struct {
 	Active      bool      `json:"active"`
 	Reason      string    `json:"reason"`
 	ActivatedAt time.Time `json:"activated_at"`
+	KillScope
+	Scoped []scopedState `json:"scoped,omitempty"`
+}
+
+type scopedState struct {
+	KillScope
+	scopedHalt
 }
 
 func NewKillSwitch(filePath string, logger *slog.Logger) *KillSwitch {
 	ks := &KillSwitch{
+		scoped:   make(map[KillScope]scopedHalt),
 		filePath: filePath,
 		logger:   logger,
 	}
@@ -44,15 +95,24 @@ func (ks *KillSwitch) loadState() {
 		return
 	}
 
-	ks.active = state.Active
+	ks.active = state.Active && state.KillScope.IsGlobal()
 	ks.reason = state.Reason
 	ks.activatedAt = state.ActivatedAt
+	for _, h := range state.Scoped {
+		ks.scoped[h.KillScope] = h.scopedHalt
+	}
 
 	if ks.active {
 		ks.logger.Warn("kill switch is ACTIVE from previous session",
 			"reason", ks.reason,
 			"activated_at", ks.activatedAt)
 	}
+	for scope, h := range ks.scoped {
+		ks.logger.Warn("scoped kill switch is ACTIVE from previous session",
+			"scope", scope.String(),
+			"reason", h.Reason,
+			"activated_at", h.ActivatedAt)
+	}
 }
 
 func (ks *KillSwitch) persistState() {
@@ -61,6 +121,9 @@ func (ks *KillSwitch) persistState() {
 		Reason:      ks.reason,
 		ActivatedAt: ks.activatedAt,
 	}
+	for scope, h := range ks.scoped {
+		state.Scoped = append(state.Scoped, scopedState{KillScope: scope, scopedHalt: h})
+	}
 
 	data, err := json.Marshal(state)
 	if err != nil {
@@ -87,17 +150,116 @@ func (ks *KillSwitch) Activate(reason string) {
 		"activated_at", ks.activatedAt)
 }
 
+// ActivateScope halts scope only; a zero scope is the global switch. It
+// reports whether scope was not halted already.
+func (ks *KillSwitch) ActivateScope(scope KillScope, reason string) bool {
+	if scope.IsGlobal() {
+		if ks.IsActive() {
+			return false
+		}
+		ks.Activate(reason)
+		return true
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if _, ok := ks.scoped[scope]; ok {
+		return false
+	}
+	ks.scoped[scope] = scopedHalt{Reason: reason, ActivatedAt: time.Now()}
+	ks.persistState()
+
+	ks.logger.Error("KILL SWITCH ACTIVATED",
+		"scope", scope.String(),
+		"reason", reason)
+	return true
+}
+
+// Deactivate clears the global switch and every scoped halt.
 func (ks *KillSwitch) Deactivate() {
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 
 	ks.active = false
 	ks.reason = ""
+	clear(ks.scoped)
 	ks.persistState()
 
 	ks.logger.Warn("KILL SWITCH DEACTIVATED")
 }
 
+// DeactivateScope clears the halt on scope alone; a zero scope clears the
+// global switch, leaving scoped halts in force.
+func (ks *KillSwitch) DeactivateScope(scope KillScope) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if scope.IsGlobal() {
+		ks.active = false
+		ks.reason = ""
+	} else {
+		delete(ks.scoped, scope)
+	}
+	ks.persistState()
+
+	ks.logger.Warn("KILL SWITCH DEACTIVATED", "scope", scope.String())
+}
+
+// pollFile re-reads the state file and adopts an activation written there
+// by another process, returning its scope and whether there was one. A file
+// that clears the switch is ignored until the next restart, so a stray write
+// can halt a running trader but not resume it.
+func (ks *KillSwitch) pollFile() (KillScope, bool) {
+	data, err := os.ReadFile(ks.filePath)
+	if err != nil {
+		return KillScope{}, false
+	}
+	var state killSwitchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		// Likely caught mid-write; the next poll reads it whole.
+		return KillScope{}, false
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if !state.Active || ks.active {
+		return KillScope{}, false
+	}
+	reason := state.Reason
+	if reason == "" {
+		reason = "activated externally via " + ks.filePath
+	}
+	if scope := state.KillScope; !scope.IsGlobal() {
+		if _, ok := ks.scoped[scope]; ok {
+			return KillScope{}, false
+		}
+		at := state.ActivatedAt
+		if at.IsZero() {
+			at = time.Now()
+		}
+		ks.scoped[scope] = scopedHalt{Reason: reason, ActivatedAt: at}
+		ks.persistState()
+
+		ks.logger.Error("KILL SWITCH ACTIVATED EXTERNALLY",
+			"scope", scope.String(),
+			"reason", reason,
+			"file", ks.filePath)
+		return scope, true
+	}
+
+	ks.active = true
+	ks.reason = reason
+	ks.activatedAt = state.ActivatedAt
+	if ks.activatedAt.IsZero() {
+		ks.activatedAt = time.Now()
+	}
+	ks.persistState()
+
+	ks.logger.Error("KILL SWITCH ACTIVATED EXTERNALLY",
+		"reason", ks.reason,
+		"file", ks.filePath)
+	return KillScope{}, true
+}
+
 func (ks *KillSwitch) IsActive() bool {
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
@@ -109,3 +271,30 @@ func (ks *KillSwitch) Reason() string {
 	defer ks.mu.RUnlock()
 	return ks.reason
 }
+
+// Halts reports whether the global switch or a scoped halt stops strategy's
+// signals on venue, and why.
+func (ks *KillSwitch) Halts(venue string, strategy domain.StrategyType) (string, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if ks.active {
+		return ks.reason, true
+	}
+	for scope, h := range ks.scoped {
+		if scope.covers(venue, strategy) {
+			return scope.String() + ": " + h.Reason, true
+		}
+	}
+	return "", false
+}
+
+// Scopes returns the scoped halts in force.
+func (ks *KillSwitch) Scopes() []KillScope {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	out := make([]KillScope, 0, len(ks.scoped))
+	for scope := range ks.scoped {
+		out = append(out, scope)
+	}
+	return out
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,15 +18,19 @@ import (
 type RejectionReason string
 
 const (
-	RejectPositionLimit    RejectionReason = "position_limit_exceeded"
-	RejectNotionalLimit    RejectionReason = "notional_limit_exceeded"
-	RejectDailyLoss        RejectionReason = "daily_loss_cap"
-	RejectGlobalOrders     RejectionReason = "global_order_limit"
-	RejectVenueOrders      RejectionReason = "venue_order_limit"
-	RejectSymbolOrders     RejectionReason = "symbol_order_limit"
-	RejectDataStale        RejectionReason = "data_stale"
-	RejectKillSwitch       RejectionReason = "kill_switch_active"
-	RejectHalted           RejectionReason = "system_halted"
+	RejectPositionLimit     RejectionReason = "position_limit_exceeded"
+	RejectNotionalLimit     RejectionReason = "notional_limit_exceeded"
+	RejectDailyLoss         RejectionReason = "daily_loss_cap"
+	RejectGlobalOrders      RejectionReason = "global_order_limit"
+	RejectVenueOrders       RejectionReason = "venue_order_limit"
+	RejectSymbolOrders      RejectionReason = "symbol_order_limit"
+	RejectDataStale         RejectionReason = "data_stale"
+	RejectKillSwitch        RejectionReason = "kill_switch_active"
+	RejectHalted            RejectionReason = "system_halted"
+	RejectCorrelationGroup  RejectionReason = "correlation_group_limit"
+	RejectSymbolBlocked     RejectionReason = "symbol_blocked"
+	RejectMarginUtilization RejectionReason = "margin_utilization_limit"
+	RejectVolatility        RejectionReason = "volatility_circuit_breaker"
 )
 
 type ValidationResult struct {
@@ -44,7 +49,25 @@ type Manager struct {
 	cfg        *config.RiskConfig
 	logger     *slog.Logger
 
-	onKillSwitch func()
+	// errorBudget is nil when the error budget is disabled.
+	errorBudget *ErrorBudget
+	// budgetRecoveredAt is when conservative mode stepped down to WARNING
+	// with the budget recovered, and zero when WARNING is not the budget's.
+	budgetRecoveredAt time.Time
+
+	// volatility reports a symbol's annualized realized volatility; nil when
+	// the volatility circuit breaker is off.
+	volatility func(venue, symbol string) (float64, bool)
+
+	// blockedSymbols maps "venue:symbol" to why trading on it stopped.
+	blockedSymbols map[string]string
+
+	// staleFeeds holds the "venue:symbol" books past their block threshold;
+	// while it is not empty the mode reads DATA_STALE (see SetFeedBlocked).
+	staleFeeds map[string]bool
+
+	onKillSwitch       func()
+	onScopedKillSwitch func(KillScope)
 }
 
 func NewManager(
@@ -53,7 +76,7 @@ func NewManager(
 	killSwitchPath string,
 	logger *slog.Logger,
 ) *Manager {
-	return &Manager{
+	m := &Manager{
 		state: &domain.RiskState{
 			Mode:            domain.RiskModeNormal,
 			Positions:       make(map[domain.VenueAssetKey]*domain.Position),
@@ -63,12 +86,18 @@ func NewManager(
 			},
 			VenueNotionals: make(map[string]decimal.Decimal),
 		},
-		pnlTracker: NewPnLTracker(),
-		killSwitch: NewKillSwitch(killSwitchPath, logger),
-		mdService:  mdService,
-		cfg:        cfg,
-		logger:     logger,
+		pnlTracker:     NewPnLTracker(),
+		killSwitch:     NewKillSwitch(killSwitchPath, logger),
+		mdService:      mdService,
+		cfg:            cfg,
+		logger:         logger,
+		blockedSymbols: make(map[string]string),
+		staleFeeds:     make(map[string]bool),
 	}
+	if cfg.ErrorBudget.Enabled {
+		m.errorBudget = NewErrorBudget(cfg.ErrorBudget)
+	}
+	return m
 }
 
 func (m *Manager) SetKillSwitchCallback(fn func()) {
@@ -77,6 +106,29 @@ func (m *Manager) SetKillSwitchCallback(fn func()) {
 	m.onKillSwitch = fn
 }
 
+// SetScopedKillSwitchCallback sets what to run when a venue or strategy is
+// halted on its own, typically cancelling that scope's open orders.
+func (m *Manager) SetScopedKillSwitchCallback(fn func(KillScope)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onScopedKillSwitch = fn
+}
+
+// SetVolatilitySource turns on the volatility circuit breaker: signals with
+// a leg whose symbol's annualized volatility, as vol reports it, is above
+// risk.volatility.max_annualized_pct are rejected until it calms.
+func (m *Manager) SetVolatilitySource(vol func(venue, symbol string) (float64, bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volatility = vol
+}
+
+// SetTradingLocation sets the timezone whose midnight starts a new trading
+// day for daily PnL and the loss cap.
+func (m *Manager) SetTradingLocation(loc *time.Location) {
+	m.pnlTracker.SetLocation(loc)
+}
+
 func (m *Manager) ValidateSignal(signal domain.TradeSignal) ValidationResult {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -84,30 +136,47 @@ func (m *Manager) ValidateSignal(signal domain.TradeSignal) ValidationResult {
 	if m.killSwitch.IsActive() {
 		return ValidationResult{Approved: false, Reason: RejectKillSwitch, Details: m.killSwitch.Reason()}
 	}
+	for i := range signal.Legs {
+		if reason, halted := m.killSwitch.Halts(signal.LegVenue(i), signal.Strategy); halted {
+			return ValidationResult{Approved: false, Reason: RejectKillSwitch, Details: reason}
+		}
+	}
 
 	if m.state.Mode == domain.RiskModeHalted {
 		return ValidationResult{Approved: false, Reason: RejectHalted}
 	}
 
-	for _, leg := range signal.Legs {
-		if m.mdService.IsDataBlocked(signal.Venue, leg.Symbol) {
+	for i, leg := range signal.Legs {
+		venue := signal.LegVenue(i)
+		if reason, ok := m.blockedSymbols[venue+":"+leg.Symbol]; ok {
+			return ValidationResult{
+				Approved: false,
+				Reason:   RejectSymbolBlocked,
+				Details:  fmt.Sprintf("%s:%s blocked: %s", venue, leg.Symbol, reason),
+			}
+		}
+	}
+
+	for i, leg := range signal.Legs {
+		venue := signal.LegVenue(i)
+		if m.mdService.IsDataBlocked(venue, leg.Symbol) {
 			return ValidationResult{
 				Approved: false,
 				Reason:   RejectDataStale,
-				Details:  fmt.Sprintf("data stale for %s:%s", signal.Venue, leg.Symbol),
+				Details:  fmt.Sprintf("data stale for %s:%s", venue, leg.Symbol),
 			}
 		}
 	}
 
-	for _, leg := range signal.Legs {
+	if result := m.checkVolatility(signal); !result.Approved {
+		return result
+	}
+
+	for i, leg := range signal.Legs {
 		asset := extractAsset(leg.Symbol)
 		maxPos, ok := m.cfg.MaxPosition[asset]
 		if ok {
-			key := domain.VenueAssetKey{Venue: signal.Venue, Asset: asset}
-			currentPos := decimal.Zero
-			if pos, exists := m.state.Positions[key]; exists {
-				currentPos = pos.Size.Abs()
-			}
+			currentPos := m.venuePosition(signal.LegVenue(i), asset).Abs()
 			newSize := currentPos.Add(leg.Size)
 			if newSize.GreaterThan(maxPos) {
 				return ValidationResult{
@@ -119,18 +188,34 @@ func (m *Manager) ValidateSignal(signal domain.TradeSignal) ValidationResult {
 		}
 	}
 
-	maxNotional, ok := m.cfg.MaxNotionalPerVenue[signal.Venue]
-	if ok {
-		currentNotional := m.state.VenueNotionals[signal.Venue]
+	if result := m.checkCorrelationGroups(signal); !result.Approved {
+		return result
+	}
+
+	if result := m.checkMarginUtilization(signal); !result.Approved {
+		return result
+	}
+
+	// A cross-venue signal is checked against the limits of every venue it
+	// trades on.
+	venues := signal.Venues()
+	for _, venue := range venues {
+		maxNotional, ok := m.cfg.MaxNotionalPerVenue[venue]
+		if !ok {
+			continue
+		}
+		currentNotional := m.state.VenueNotionals[venue]
 		additionalNotional := decimal.Zero
-		for _, leg := range signal.Legs {
-			additionalNotional = additionalNotional.Add(leg.Price.Mul(leg.Size))
+		for i, leg := range signal.Legs {
+			if signal.LegVenue(i) == venue {
+				additionalNotional = additionalNotional.Add(leg.Price.Mul(leg.Size))
+			}
 		}
 		if currentNotional.Add(additionalNotional).GreaterThan(maxNotional) {
 			return ValidationResult{
 				Approved: false,
 				Reason:   RejectNotionalLimit,
-				Details:  fmt.Sprintf("venue %s notional limit exceeded", signal.Venue),
+				Details:  fmt.Sprintf("venue %s notional limit exceeded", venue),
 			}
 		}
 	}
@@ -153,12 +238,14 @@ func (m *Manager) ValidateSignal(signal domain.TradeSignal) ValidationResult {
 		}
 	}
 
-	venueOrders := m.state.OpenOrderCounts.PerVenue[signal.Venue]
-	if venueOrders >= m.cfg.MaxOpenOrders.PerVenue {
-		return ValidationResult{
-			Approved: false,
-			Reason:   RejectVenueOrders,
-			Details:  fmt.Sprintf("venue %s orders %d >= %d", signal.Venue, venueOrders, m.cfg.MaxOpenOrders.PerVenue),
+	for _, venue := range venues {
+		venueOrders := m.state.OpenOrderCounts.PerVenue[venue]
+		if venueOrders >= m.cfg.MaxOpenOrders.PerVenue {
+			return ValidationResult{
+				Approved: false,
+				Reason:   RejectVenueOrders,
+				Details:  fmt.Sprintf("venue %s orders %d >= %d", venue, venueOrders, m.cfg.MaxOpenOrders.PerVenue),
+			}
 		}
 	}
 
@@ -176,6 +263,37 @@ func (m *Manager) ValidateSignal(signal domain.TradeSignal) ValidationResult {
 	return ValidationResult{Approved: true}
 }
 
+// OnFundingPayment records a funding payment on a perp position, positive
+// if received, in the day's realized PnL.
+func (m *Manager) OnFundingPayment(amount decimal.Decimal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pnlTracker.AddFunding(amount)
+}
+
+// OnExecutionReport counts an execution cycle, its filled orders and its
+// fees towards the day's activity.
+func (m *Manager) OnExecutionReport(report domain.ExecutionReport) {
+	trades := 0
+	for _, leg := range report.Legs {
+		if leg.ActualSize.IsPositive() {
+			trades++
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pnlTracker.AddCycle(report.Status == "completed", trades, report.TotalFees)
+}
+
+// OnLiquidation records the PnL of a forced close of perp positions,
+// negative for a loss, in the day's realized PnL.
+func (m *Manager) OnLiquidation(pnl decimal.Decimal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pnlTracker.AddRealizedPnL(pnl)
+	m.checkPnLLimits()
+}
+
 func (m *Manager) OnOrderFill(order domain.Order, pnl decimal.Decimal) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -183,7 +301,7 @@ func (m *Manager) OnOrderFill(order domain.Order, pnl decimal.Decimal) {
 	m.pnlTracker.AddRealizedPnL(pnl)
 
 	asset := extractAsset(order.Symbol)
-	key := domain.VenueAssetKey{Venue: order.Venue, Asset: asset}
+	key := domain.VenueAssetKey{Venue: order.Venue, Account: order.Account, Asset: asset}
 
 	if pos, exists := m.state.Positions[key]; exists {
 		if order.Side == domain.SideBuy {
@@ -199,8 +317,9 @@ func (m *Manager) OnOrderFill(order domain.Order, pnl decimal.Decimal) {
 		}
 		m.state.Positions[key] = &domain.Position{
 			Venue:          order.Venue,
+			Account:        order.Account,
 			Asset:          asset,
-			InstrumentType: domain.InstrumentSpot,
+			InstrumentType: order.InstrumentType,
 			Size:           size,
 			EntryPrice:     order.AvgFillPrice,
 			UpdatedAt:      time.Now(),
@@ -227,6 +346,10 @@ func (m *Manager) OnOrderStateChange(change domain.OrderStateChange) {
 		m.state.OpenOrderCounts.PerSymbol[order.Symbol]++
 	}
 
+	if m.errorBudget != nil && change.PrevStatus == domain.OrderStatusSubmitted {
+		m.recordOrderOutcome(change)
+	}
+
 	if isTerminal {
 		m.state.OpenOrderCounts.Global--
 		m.state.OpenOrderCounts.PerVenue[order.Venue]--
@@ -247,7 +370,7 @@ func (m *Manager) OnOrderStateChange(change domain.OrderStateChange) {
 func (m *Manager) checkPnLLimits() {
 	totalPnL := m.pnlTracker.TotalDailyPnL()
 	lossCap := m.cfg.DailyLossCapUSDT.Neg()
-	warningLevel := lossCap.Mul(decimal.NewFromInt(int64(m.cfg.WarningThresholdPct))).Div(decimal.NewFromInt(100))
+	warningLevel := m.pnlWarningLevel()
 
 	if totalPnL.LessThanOrEqual(lossCap) {
 		m.state.Mode = domain.RiskModeHalted
@@ -269,6 +392,11 @@ func (m *Manager) checkPnLLimits() {
 	}
 }
 
+// pnlWarningLevel is the daily PnL at or below which the mode is WARNING.
+func (m *Manager) pnlWarningLevel() decimal.Decimal {
+	return m.cfg.DailyLossCapUSDT.Neg().Mul(decimal.NewFromInt(int64(m.cfg.WarningThresholdPct))).Div(decimal.NewFromInt(100))
+}
+
 func (m *Manager) RunPeriodicCheck(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -277,24 +405,259 @@ func (m *Manager) RunPeriodicCheck(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case now := <-ticker.C:
+			if m.errorBudget != nil {
+				fresh, total := m.mdService.FeedFreshness()
+				m.errorBudget.Record(SLIDataFreshness, fresh, total, now)
+			}
+
 			m.mu.Lock()
 			m.checkPnLLimits()
+			m.checkErrorBudget(now)
+			m.mu.Unlock()
+		}
+	}
+}
+
+// killSwitchPollInterval is how often RunKillSwitchWatcher re-reads the kill
+// switch file.
+const killSwitchPollInterval = 250 * time.Millisecond
+
+// RunKillSwitchWatcher lets another process halt trading by writing
+// {"active": true} to the kill switch file, e.g. an operator on the host or a
+// watchdog that has lost contact with the admin API. The file is polled
+// rather than watched for events so it also works on volumes without inotify.
+// An external activation halts the system and runs the kill switch callback,
+// as if a limit had tripped here. Writing "venue" and/or "strategy" alongside
+// "active" halts only that scope and runs the scoped callback instead.
+func (m *Manager) RunKillSwitchWatcher(ctx context.Context) {
+	ticker := time.NewTicker(killSwitchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			scope, activated := m.killSwitch.pollFile()
+			if !activated {
+				continue
+			}
+			if !scope.IsGlobal() {
+				m.mu.RLock()
+				onScoped := m.onScopedKillSwitch
+				m.mu.RUnlock()
+				if onScoped != nil {
+					go onScoped(scope)
+				}
+				continue
+			}
+			m.mu.Lock()
+			m.state.Mode = domain.RiskModeHalted
+			onKillSwitch := m.onKillSwitch
 			m.mu.Unlock()
+			if onKillSwitch != nil {
+				go onKillSwitch()
+			}
+		}
+	}
+}
+
+// recordOrderOutcome feeds the reject and ack-latency SLIs from an order
+// leaving the submitted state.
+func (m *Manager) recordOrderOutcome(change domain.OrderStateChange) {
+	switch change.NewStatus {
+	case domain.OrderStatusRejected, domain.OrderStatusSubmitFailed:
+		m.errorBudget.Record(SLIOrderRejects, 0, 1, change.Timestamp)
+	default:
+		m.errorBudget.Record(SLIOrderRejects, 1, 1, change.Timestamp)
+		good := 0
+		if change.Timestamp.Sub(change.Order.CreatedAt) <= m.cfg.ErrorBudget.AckLatency() {
+			good = 1
+		}
+		m.errorBudget.Record(SLIAckLatency, good, 1, change.Timestamp)
+	}
+}
+
+// budgetRecoveryDwell is how long the error budget must stay recovered in
+// WARNING before conservative mode is fully lifted.
+const budgetRecoveryDwell = time.Minute
+
+// checkErrorBudget moves into conservative (degraded) mode once the error
+// budget is spent. Once RecoverPct of it is left again the mode steps down
+// to WARNING, and to NORMAL after the budget has stayed recovered for
+// budgetRecoveryDwell, unless daily PnL is itself at the warning level. It
+// never overrides a halt.
+func (m *Manager) checkErrorBudget(now time.Time) {
+	if m.errorBudget == nil {
+		return
+	}
+	remaining, sli := m.errorBudget.Remaining(now)
+	recovered := remaining >= m.cfg.ErrorBudget.RecoverPct/100
+
+	switch m.state.Mode {
+	case domain.RiskModeNormal, domain.RiskModeWarning:
+		if remaining <= 0 {
+			m.state.Mode = domain.RiskModeDegraded
+			m.budgetRecoveredAt = time.Time{}
+			m.logger.Warn("error budget exhausted, entering conservative mode",
+				"sli", string(sli),
+				"budget_remaining", remaining)
+			return
+		}
+		if m.state.Mode == domain.RiskModeNormal || m.budgetRecoveredAt.IsZero() {
+			m.budgetRecoveredAt = time.Time{}
+			return
+		}
+		switch {
+		case m.pnlTracker.TotalDailyPnL().LessThanOrEqual(m.pnlWarningLevel()):
+			// WARNING is now the PnL's; the day rollover lifts it.
+			m.budgetRecoveredAt = time.Time{}
+		case !recovered:
+			m.budgetRecoveredAt = now
+		case now.Sub(m.budgetRecoveredAt) >= budgetRecoveryDwell:
+			m.state.Mode = domain.RiskModeNormal
+			m.budgetRecoveredAt = time.Time{}
+			m.logger.Info("error budget stayed recovered, leaving warning",
+				"budget_remaining", remaining)
+		}
+	case domain.RiskModeDegraded:
+		if recovered {
+			m.state.Mode = domain.RiskModeWarning
+			m.budgetRecoveredAt = now
+			m.logger.Info("error budget recovered, stepping down from conservative mode to warning",
+				"budget_remaining", remaining)
+		}
+	}
+}
+
+// checkVolatility rejects signal if one of its legs trades a symbol more
+// volatile than the circuit breaker allows.
+func (m *Manager) checkVolatility(signal domain.TradeSignal) ValidationResult {
+	if m.volatility == nil || m.cfg.Volatility.MaxAnnualizedPct <= 0 {
+		return ValidationResult{Approved: true}
+	}
+	for i, leg := range signal.Legs {
+		venue := signal.LegVenue(i)
+		vol, ok := m.volatility(venue, leg.Symbol)
+		if ok && vol*100 > m.cfg.Volatility.MaxAnnualizedPct {
+			return ValidationResult{
+				Approved: false,
+				Reason:   RejectVolatility,
+				Details:  fmt.Sprintf("%s:%s annualized volatility %.0f%% > %.0f%%", venue, leg.Symbol, vol*100, m.cfg.Volatility.MaxAnnualizedPct),
+			}
 		}
 	}
+	return ValidationResult{Approved: true}
+}
+
+// IsConservative reports whether the error budget has put the system into
+// conservative mode, where strategies demand more edge and trade smaller.
+// Stale feeds do not lift it.
+func (m *Manager) IsConservative() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.Mode == domain.RiskModeDegraded
+}
+
+// SetFeedBlocked records that the book for symbol on venue has passed its
+// block threshold, or updated again. While any book is blocked the mode
+// reads DATA_STALE, unless halted; once the last recovers it reads what it
+// would have otherwise, so PnL warnings and conservative mode entered or
+// left meanwhile carry through. Signals with a leg on a blocked book are
+// rejected whatever the mode, and the rest are still approved.
+func (m *Manager) SetFeedBlocked(venue, symbol string, blocked bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := venue + ":" + symbol
+	if blocked == m.staleFeeds[key] {
+		return
+	}
+	before := m.mode()
+	if blocked {
+		m.staleFeeds[key] = true
+	} else {
+		delete(m.staleFeeds, key)
+	}
+	if after := m.mode(); after != before {
+		m.logger.Warn("risk mode changed on market data staleness",
+			"from", string(before),
+			"to", string(after),
+			"feed", key,
+			"stale_feeds", len(m.staleFeeds))
+	}
+}
+
+// mode is the mode as reported: state.Mode, overlaid with DATA_STALE while
+// a book is blocked. The caller holds m.mu.
+func (m *Manager) mode() domain.RiskMode {
+	if len(m.staleFeeds) > 0 && m.state.Mode != domain.RiskModeHalted {
+		return domain.RiskModeDataStale
+	}
+	return m.state.Mode
+}
+
+// DailyResetter is implemented by components that keep their own daily PnL
+// and must be reset together with the risk manager at day rollover.
+type DailyResetter interface {
+	ResetDaily()
 }
 
+// RolloverDay closes the trading day that ends at dayStart. The daily PnL
+// trackers are snapshotted and zeroed, and each of others is reset while the
+// risk lock is held, so no signal is validated against a half-reset state. A
+// PnL warning is cleared; a halt is left for an operator to resume.
+func (m *Manager) RolloverDay(dayStart time.Time, others ...DailyResetter) domain.DailyPnLSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	funding := m.pnlTracker.FundingPnL()
+	cycles, trades, fees := m.pnlTracker.Activity()
+	realized, unrealized := m.pnlTracker.Rollover(dayStart)
+	for _, r := range others {
+		r.ResetDaily()
+	}
+
+	m.state.DailyRealizedPnL = decimal.Zero
+	m.state.DailyUnrealizedPnL = decimal.Zero
+	if m.state.Mode == domain.RiskModeWarning {
+		m.state.Mode = domain.RiskModeNormal
+	}
+
+	m.logger.Info("daily PnL rolled over",
+		"day_start", dayStart,
+		"realized_pnl", realized.String(),
+		"unrealized_pnl", unrealized.String(),
+		"funding_net", funding.String(),
+		"cycles", cycles,
+		"trades", trades,
+		"fees", fees.String())
+
+	return domain.DailyPnLSnapshot{
+		Date:          dayStart.AddDate(0, 0, -1),
+		RealizedPnL:   realized,
+		UnrealizedPnL: unrealized,
+		TotalPnL:      realized.Add(unrealized),
+		NumCycles:     cycles,
+		NumTrades:     trades,
+		FeesPaid:      fees,
+		FundingNet:    funding,
+	}
+}
+
+// GetState returns a deep copy of the risk state.
 func (m *Manager) GetState() domain.RiskState {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return *m.state
+	state := m.state.Clone()
+	state.Mode = m.mode()
+	return *state
 }
 
 func (m *Manager) GetMode() domain.RiskMode {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.state.Mode
+	return m.mode()
 }
 
 func (m *Manager) IsKillSwitchActive() bool {
@@ -315,23 +678,235 @@ func (m *Manager) DeactivateKillSwitch() {
 	m.killSwitch.Deactivate()
 }
 
+// ActivateKillSwitchScope halts scope alone: signals of its strategy with a
+// leg on its venue are rejected and the scoped callback runs, while the rest
+// of the system trades on. A zero scope is ActivateKillSwitch, callback
+// included.
+func (m *Manager) ActivateKillSwitchScope(scope KillScope, reason string) {
+	if scope.IsGlobal() {
+		m.ActivateKillSwitch(reason)
+		m.mu.RLock()
+		onKillSwitch := m.onKillSwitch
+		m.mu.RUnlock()
+		if onKillSwitch != nil {
+			go onKillSwitch()
+		}
+		return
+	}
+	if !m.killSwitch.ActivateScope(scope, reason) {
+		return
+	}
+	m.mu.RLock()
+	onScoped := m.onScopedKillSwitch
+	m.mu.RUnlock()
+	if onScoped != nil {
+		go onScoped(scope)
+	}
+}
+
+// DeactivateKillSwitchScope lifts the halt on scope, leaving other halts in
+// force.
+func (m *Manager) DeactivateKillSwitchScope(scope KillScope) {
+	if scope.IsGlobal() {
+		m.mu.Lock()
+		m.state.Mode = domain.RiskModeNormal
+		m.mu.Unlock()
+	}
+	m.killSwitch.DeactivateScope(scope)
+}
+
+// KillSwitchScopes returns the venues and strategies halted on their own.
+func (m *Manager) KillSwitchScopes() []KillScope {
+	return m.killSwitch.Scopes()
+}
+
+// BlockSymbol rejects every signal with a leg in symbol on venue for the
+// rest of the session, for symbols the venue has suspended or delisted. It
+// reports whether the symbol was not blocked already.
+func (m *Manager) BlockSymbol(venue, symbol, reason string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := venue + ":" + symbol
+	if _, ok := m.blockedSymbols[key]; ok {
+		return false
+	}
+	m.blockedSymbols[key] = reason
+	m.logger.Warn("symbol blocked", "venue", venue, "symbol", symbol, "reason", reason)
+	return true
+}
+
+// IsSymbolBlocked reports whether symbol on venue has been blocked.
+func (m *Manager) IsSymbolBlocked(venue, symbol string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.blockedSymbols[venue+":"+symbol]
+	return ok
+}
+
 func (m *Manager) UpdatePosition(key domain.VenueAssetKey, pos *domain.Position) {
+	p := *pos
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.state.Positions[key] = pos
+	m.state.Positions[key] = &p
 }
 
+// GetCheckpointState returns a deep copy of the risk state taken under the
+// lock, so a checkpoint being encoded or stress-tested cannot change under
+// fills that arrive meanwhile.
 func (m *Manager) GetCheckpointState() *domain.RiskState {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	cp := *m.state
+	cp := m.state.Clone()
+	cp.Mode = m.mode()
 	cp.DailyRealizedPnL = m.pnlTracker.RealizedPnL()
 	cp.DailyUnrealizedPnL = m.pnlTracker.UnrealizedPnL()
 	cp.LastCheckpoint = time.Now()
 	cp.KillSwitchActive = m.killSwitch.IsActive()
 	cp.KillSwitchReason = m.killSwitch.Reason()
-	return &cp
+	return cp
+}
+
+// checkCorrelationGroups rejects a signal that would push the combined
+// exposure of any configured correlation group over its limit. Exposure is
+// the net signed USDT notional of every position in the group across all
+// venues, so a signal that reduces a group's exposure is always let through.
+func (m *Manager) checkCorrelationGroups(signal domain.TradeSignal) ValidationResult {
+	for name, group := range m.cfg.CorrelationGroups {
+		members := groupMembers(group.Assets)
+		additional, touched, err := m.signalGroupExposure(signal, members)
+		if !touched {
+			continue
+		}
+		if err != nil {
+			return ValidationResult{
+				Approved: false,
+				Reason:   RejectCorrelationGroup,
+				Details:  fmt.Sprintf("group %s: %v", name, err),
+			}
+		}
+
+		current := m.groupExposure(members)
+		exposure := current.Add(additional).Abs()
+		if exposure.GreaterThan(group.MaxExposureUSDT) && exposure.GreaterThan(current.Abs()) {
+			return ValidationResult{
+				Approved: false,
+				Reason:   RejectCorrelationGroup,
+				Details:  fmt.Sprintf("group %s exposure would be %s > %s", name, exposure.StringFixed(2), group.MaxExposureUSDT.String()),
+			}
+		}
+	}
+	return ValidationResult{Approved: true}
+}
+
+func groupMembers(assets []string) map[string]bool {
+	members := make(map[string]bool, len(assets))
+	for _, a := range assets {
+		members[a] = true
+	}
+	return members
+}
+
+// signalGroupExposure returns the signed USDT notional the signal's legs add
+// to a group: buys positive, sells negative. touched reports whether any leg
+// trades a member asset.
+func (m *Manager) signalGroupExposure(signal domain.TradeSignal, members map[string]bool) (exposure decimal.Decimal, touched bool, err error) {
+	for i, leg := range signal.Legs {
+		if !members[extractAsset(leg.Symbol)] {
+			continue
+		}
+		touched = true
+		notional, err := m.usdtNotional(signal.LegVenue(i), leg.Symbol, leg.Price.Mul(leg.Size))
+		if err != nil {
+			return decimal.Zero, true, err
+		}
+		if leg.Side == domain.SideSell {
+			notional = notional.Neg()
+		}
+		exposure = exposure.Add(notional)
+	}
+	return exposure, touched, nil
+}
+
+// venuePosition returns the net signed size of asset held on venue, summed
+// over all of the venue's accounts.
+func (m *Manager) venuePosition(venue, asset string) decimal.Decimal {
+	total := decimal.Zero
+	for key, pos := range m.state.Positions {
+		if key.Venue == venue && key.Asset == asset && pos != nil {
+			total = total.Add(pos.Size)
+		}
+	}
+	return total
+}
+
+// groupExposure returns the net signed USDT notional of the group's
+// positions.
+func (m *Manager) groupExposure(members map[string]bool) decimal.Decimal {
+	total := decimal.Zero
+	for key, pos := range m.state.Positions {
+		if !members[key.Asset] || pos == nil || pos.Size.IsZero() {
+			continue
+		}
+		mark := m.markPrice(key.Venue, key.Asset, pos.EntryPrice)
+		total = total.Add(pos.Size.Mul(mark))
+	}
+	return total
+}
+
+// usdtNotional converts a notional in symbol's quote currency to USDT at
+// the mid of the venue's USDT book for that currency: one quoted in it,
+// such as USDT/IRT, or else one quoting it in USDT, such as BTC/USDT.
+func (m *Manager) usdtNotional(venue, symbol string, notional decimal.Decimal) (decimal.Decimal, error) {
+	quote := quoteCurrency(symbol)
+	if quote == "USDT" {
+		return notional, nil
+	}
+	for _, rateSymbol := range []string{"USDT/" + quote, "USDT" + quote} {
+		if mid, ok := m.bookMid(venue, rateSymbol); ok {
+			return notional.Div(mid), nil
+		}
+	}
+	for _, rateSymbol := range []string{quote + "/USDT", quote + "USDT"} {
+		if mid, ok := m.bookMid(venue, rateSymbol); ok {
+			return notional.Mul(mid), nil
+		}
+	}
+	return decimal.Zero, fmt.Errorf("no USDT rate for %s on %s", quote, venue)
+}
+
+// bookMid returns the positive mid of the venue's book for symbol.
+func (m *Manager) bookMid(venue, symbol string) (decimal.Decimal, bool) {
+	book, ok := m.mdService.GetOrderBook(venue, symbol)
+	if !ok {
+		return decimal.Zero, false
+	}
+	mid, valid := book.MidPrice()
+	return mid, valid && mid.IsPositive()
+}
+
+// quoteCurrency returns the quote currency of symbol. Symbols without a
+// separator, such as perps, are quoted in USDT.
+func quoteCurrency(symbol string) string {
+	if idx := strings.IndexByte(symbol, '/'); idx >= 0 {
+		return symbol[idx+1:]
+	}
+	return "USDT"
+}
+
+// markPrice returns the mid of the asset's perp or spot USDT book on the
+// venue, falling back to the position's entry price.
+func (m *Manager) markPrice(venue, asset string, fallback decimal.Decimal) decimal.Decimal {
+	for _, symbol := range []string{asset + "USDT", asset + "/USDT"} {
+		book, ok := m.mdService.GetOrderBook(venue, symbol)
+		if !ok {
+			continue
+		}
+		if mid, valid := book.MidPrice(); valid {
+			return mid
+		}
+	}
+	return fallback
 }
 
 func extractAsset(symbol string) string {
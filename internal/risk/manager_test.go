@@ -1,8 +1,12 @@
 package risk
 
 import (
+	"bytes"
+	"context"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -78,6 +82,74 @@ func TestValidateSignal_Approved(t *testing.T) {
 	}
 }
 
+func TestValidateSignal_SymbolBlocked(t *testing.T) {
+	mgr := newTestManager(t)
+	signal := domain.TradeSignal{
+		SignalID: uuid.Must(uuid.NewV7()),
+		Strategy: domain.StrategyTriArb,
+		Venue:    "nobitex",
+		Legs: []domain.LegSpec{
+			{Symbol: "BTC/USDT", Side: domain.SideBuy, Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(0.1), OrderType: domain.OrderTypeLimit},
+		},
+	}
+
+	if !mgr.BlockSymbol("nobitex", "BTC/USDT", "delisted") {
+		t.Fatal("expected the first block to be new")
+	}
+	if mgr.BlockSymbol("nobitex", "BTC/USDT", "delisted") {
+		t.Error("expected a repeated block to report no change")
+	}
+	if result := mgr.ValidateSignal(signal); result.Approved || result.Reason != RejectSymbolBlocked {
+		t.Errorf("expected rejection for a blocked symbol, got %+v", result)
+	}
+
+	signal.Venue = "kcex"
+	if result := mgr.ValidateSignal(signal); result.Reason == RejectSymbolBlocked {
+		t.Error("expected the block limited to its venue")
+	}
+	if !mgr.IsSymbolBlocked("nobitex", "BTC/USDT") || mgr.IsSymbolBlocked("kcex", "BTC/USDT") {
+		t.Error("expected IsSymbolBlocked to match the block")
+	}
+
+	// A cross-venue leg is checked on the venue it trades on.
+	signal.Legs[0].Venue = "nobitex"
+	if result := mgr.ValidateSignal(signal); result.Reason != RejectSymbolBlocked {
+		t.Errorf("expected rejection for a leg on the blocked venue, got %+v", result)
+	}
+}
+
+func TestValidateSignal_VolatilityBreaker(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.cfg.Volatility.MaxAnnualizedPct = 150
+	signal := domain.TradeSignal{
+		SignalID: uuid.Must(uuid.NewV7()),
+		Strategy: domain.StrategyTriArb,
+		Venue:    "nobitex",
+		Legs: []domain.LegSpec{
+			{Symbol: "BTC/USDT", Side: domain.SideBuy, Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(0.1), OrderType: domain.OrderTypeLimit},
+		},
+	}
+
+	vol, known := 2.0, true
+	mgr.SetVolatilitySource(func(venue, symbol string) (float64, bool) {
+		return vol, known
+	})
+	if result := mgr.ValidateSignal(signal); result.Approved || result.Reason != RejectVolatility {
+		t.Errorf("expected rejection at 200%% volatility, got %+v", result)
+	}
+
+	vol = 1
+	if result := mgr.ValidateSignal(signal); !result.Approved {
+		t.Errorf("expected approval once volatility fell, got %+v", result)
+	}
+
+	// Without an estimate yet the breaker lets the signal through.
+	vol, known = 2, false
+	if result := mgr.ValidateSignal(signal); !result.Approved {
+		t.Errorf("expected approval without an estimate, got %+v", result)
+	}
+}
+
 func TestValidateSignal_PositionLimit(t *testing.T) {
 	mgr := newTestManager(t)
 
@@ -105,6 +177,198 @@ func TestValidateSignal_PositionLimit(t *testing.T) {
 	}
 }
 
+func TestValidateSignal_CorrelationGroupLimit(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.cfg.CorrelationGroups = map[string]config.CorrelationGroupConfig{
+		"high_beta": {
+			Assets:          []string{"ETH", "SOL"},
+			MaxExposureUSDT: decimal.NewFromInt(100000),
+		},
+	}
+	mgr.mdService.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "nobitex",
+		Symbol: "ETH/USDT",
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(2999), Size: decimal.NewFromInt(10)}},
+		Asks:   []domain.PriceLevel{{Price: decimal.NewFromInt(3001), Size: decimal.NewFromInt(10)}},
+	})
+
+	// 300 SOL on another venue, marked at entry: 45000 USDT of group exposure.
+	mgr.UpdatePosition(domain.VenueAssetKey{Venue: "kcex", Asset: "SOL"}, &domain.Position{
+		Venue:      "kcex",
+		Asset:      "SOL",
+		Size:       decimal.NewFromInt(300),
+		EntryPrice: decimal.NewFromInt(150),
+	})
+
+	ethBuy := func(size int64) domain.TradeSignal {
+		return domain.TradeSignal{
+			SignalID: uuid.Must(uuid.NewV7()),
+			Strategy: domain.StrategyTriArb,
+			Venue:    "nobitex",
+			Legs: []domain.LegSpec{
+				{
+					Symbol:    "ETH/USDT",
+					Side:      domain.SideBuy,
+					Price:     decimal.NewFromInt(3000),
+					Size:      decimal.NewFromInt(size),
+					OrderType: domain.OrderTypeLimit,
+				},
+			},
+		}
+	}
+
+	if result := mgr.ValidateSignal(ethBuy(10)); !result.Approved {
+		t.Errorf("expected 75000 group exposure to be approved, got %s - %s", result.Reason, result.Details)
+	}
+
+	result := mgr.ValidateSignal(ethBuy(20))
+	if result.Approved {
+		t.Fatal("expected 105000 group exposure to be rejected")
+	}
+	if result.Reason != RejectCorrelationGroup {
+		t.Errorf("expected reason %s, got %s", RejectCorrelationGroup, result.Reason)
+	}
+}
+
+func TestValidateSignal_CorrelationGroupMixedQuotes(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.cfg.CorrelationGroups = map[string]config.CorrelationGroupConfig{
+		"majors": {
+			Assets:          []string{"BTC"},
+			MaxExposureUSDT: decimal.NewFromInt(60000),
+		},
+	}
+	// Only the group limit is under test.
+	mgr.cfg.MaxPosition["BTC"] = decimal.NewFromInt(10)
+	delete(mgr.cfg.MaxNotionalPerVenue, "nobitex")
+	mgr.mdService.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "nobitex",
+		Symbol: "USDT/IRT",
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(999000), Size: decimal.NewFromInt(100000)}},
+		Asks:   []domain.PriceLevel{{Price: decimal.NewFromInt(1001000), Size: decimal.NewFromInt(100000)}},
+	})
+	mgr.mdService.UpdateOrderBook(domain.OrderBookSnapshot{
+		Venue:  "nobitex",
+		Symbol: "BTC/IRT",
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(49990000000), Size: decimal.NewFromInt(1)}},
+		Asks:   []domain.PriceLevel{{Price: decimal.NewFromInt(50010000000), Size: decimal.NewFromInt(1)}},
+	})
+
+	// 1 BTC long, marked at the BTC/USDT mid: 50000.5 USDT of exposure.
+	mgr.UpdatePosition(domain.VenueAssetKey{Venue: "nobitex", Asset: "BTC"}, &domain.Position{
+		Venue:      "nobitex",
+		Asset:      "BTC",
+		Size:       decimal.NewFromInt(1),
+		EntryPrice: decimal.NewFromInt(50000),
+	})
+
+	signal := func(legs ...domain.LegSpec) domain.TradeSignal {
+		return domain.TradeSignal{
+			SignalID: uuid.Must(uuid.NewV7()),
+			Strategy: domain.StrategyTriArb,
+			Venue:    "nobitex",
+			Legs:     legs,
+		}
+	}
+	leg := func(symbol string, side domain.Side, price, size float64) domain.LegSpec {
+		return domain.LegSpec{
+			Symbol:    symbol,
+			Side:      side,
+			Price:     decimal.NewFromFloat(price),
+			Size:      decimal.NewFromFloat(size),
+			OrderType: domain.OrderTypeLimit,
+		}
+	}
+
+	// Buying 0.1 BTC for 5e9 IRT adds 5000 USDT, not 5e9.
+	if result := mgr.ValidateSignal(signal(leg("BTC/IRT", domain.SideBuy, 50000000000, 0.1))); !result.Approved {
+		t.Errorf("expected 55000 USDT of exposure to be approved, got %s - %s", result.Reason, result.Details)
+	}
+	result := mgr.ValidateSignal(signal(leg("BTC/IRT", domain.SideBuy, 50000000000, 0.3)))
+	if result.Approved || result.Reason != RejectCorrelationGroup {
+		t.Errorf("expected 65000 USDT of exposure to be rejected, got %+v", result)
+	}
+
+	// A tri-arb cycle buys BTC for USDT and sells it for IRT: it nets out.
+	cycle := signal(
+		leg("BTC/USDT", domain.SideBuy, 50000, 0.5),
+		leg("BTC/IRT", domain.SideSell, 50000000000, 0.5),
+		leg("USDT/IRT", domain.SideBuy, 1000000, 25000),
+	)
+	if result := mgr.ValidateSignal(cycle); !result.Approved {
+		t.Errorf("expected a netting cycle to be approved, got %s - %s", result.Reason, result.Details)
+	}
+
+	// Over the limit, a sell that shrinks the exposure still goes through.
+	mgr.UpdatePosition(domain.VenueAssetKey{Venue: "nobitex", Asset: "BTC"}, &domain.Position{
+		Venue:      "nobitex",
+		Asset:      "BTC",
+		Size:       decimal.NewFromInt(2),
+		EntryPrice: decimal.NewFromInt(50000),
+	})
+	if result := mgr.ValidateSignal(signal(leg("BTC/USDT", domain.SideSell, 50000, 0.5))); !result.Approved {
+		t.Errorf("expected a reducing sell to be approved, got %s - %s", result.Reason, result.Details)
+	}
+	if result := mgr.ValidateSignal(signal(leg("BTC/USDT", domain.SideBuy, 50000, 0.1))); result.Approved {
+		t.Error("expected a buy over the limit to be rejected")
+	}
+}
+
+func TestValidateSignal_CorrelationGroupBTCQuoted(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.cfg.CorrelationGroups = map[string]config.CorrelationGroupConfig{
+		"high_beta": {
+			Assets:          []string{"ETH", "SOL"},
+			MaxExposureUSDT: decimal.NewFromInt(20000),
+		},
+	}
+	// Only the group limit is under test.
+	delete(mgr.cfg.MaxNotionalPerVenue, "nobitex")
+	for symbol, mid := range map[string]float64{"ETH/BTC": 0.06, "ETH/USDT": 3000} {
+		mgr.mdService.UpdateOrderBook(domain.OrderBookSnapshot{
+			Venue:  "nobitex",
+			Symbol: symbol,
+			Bids:   []domain.PriceLevel{{Price: decimal.NewFromFloat(mid * 0.999), Size: decimal.NewFromInt(100)}},
+			Asks:   []domain.PriceLevel{{Price: decimal.NewFromFloat(mid * 1.001), Size: decimal.NewFromInt(100)}},
+		})
+	}
+
+	signal := func(legs ...domain.LegSpec) domain.TradeSignal {
+		return domain.TradeSignal{
+			SignalID: uuid.Must(uuid.NewV7()),
+			Strategy: domain.StrategyTriArb,
+			Venue:    "nobitex",
+			Legs:     legs,
+		}
+	}
+	leg := func(symbol string, side domain.Side, price, size float64) domain.LegSpec {
+		return domain.LegSpec{
+			Symbol:    symbol,
+			Side:      side,
+			Price:     decimal.NewFromFloat(price),
+			Size:      decimal.NewFromFloat(size),
+			OrderType: domain.OrderTypeLimit,
+		}
+	}
+
+	// There is no USDT/BTC book, so the 0.06 BTC paid for 1 ETH is priced
+	// at the BTC/USDT mid: about 3000 USDT.
+	cycle := signal(
+		leg("BTC/USDT", domain.SideBuy, 50000, 0.06),
+		leg("ETH/BTC", domain.SideBuy, 0.06, 1),
+		leg("ETH/USDT", domain.SideSell, 3010, 1),
+	)
+	if result := mgr.ValidateSignal(cycle); !result.Approved {
+		t.Errorf("expected a BTC-quoted tri-arb cycle to be approved, got %s - %s", result.Reason, result.Details)
+	}
+
+	// 10 ETH for 0.6 BTC is about 30000 USDT, over the group limit.
+	result := mgr.ValidateSignal(signal(leg("ETH/BTC", domain.SideBuy, 0.06, 10)))
+	if result.Approved || result.Reason != RejectCorrelationGroup {
+		t.Errorf("expected 30000 USDT of exposure to be rejected, got %+v", result)
+	}
+}
+
 func TestValidateSignal_KillSwitch(t *testing.T) {
 	mgr := newTestManager(t)
 	mgr.ActivateKillSwitch("test reason")
@@ -139,6 +403,135 @@ func TestValidateSignal_KillSwitch(t *testing.T) {
 	}
 }
 
+func TestValidateSignal_ScopedKillSwitch(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.killSwitch = NewKillSwitch(filepath.Join(t.TempDir(), "killswitch.json"), mgr.logger)
+	var cancelled []KillScope
+	done := make(chan struct{}, 4)
+	mgr.SetScopedKillSwitchCallback(func(scope KillScope) {
+		cancelled = append(cancelled, scope)
+		done <- struct{}{}
+	})
+
+	signal := func(strategy domain.StrategyType, venue string) domain.TradeSignal {
+		return domain.TradeSignal{
+			SignalID: uuid.Must(uuid.NewV7()),
+			Strategy: strategy,
+			Venue:    venue,
+			Legs: []domain.LegSpec{{
+				Symbol:    "BTC/USDT",
+				Side:      domain.SideBuy,
+				Price:     decimal.NewFromInt(50000),
+				Size:      decimal.NewFromFloat(0.1),
+				OrderType: domain.OrderTypeLimit,
+			}},
+		}
+	}
+
+	mgr.ActivateKillSwitchScope(KillScope{Venue: "kcex"}, "venue misbehaving")
+	<-done
+	if r := mgr.ValidateSignal(signal(domain.StrategyTriArb, "kcex")); r.Approved || r.Reason != RejectKillSwitch {
+		t.Errorf("expected a kcex signal rejected, got %+v", r)
+	}
+	if r := mgr.ValidateSignal(signal(domain.StrategyTriArb, "nobitex")); !r.Approved {
+		t.Errorf("expected other venues to trade on, got %s", r.Reason)
+	}
+
+	mgr.ActivateKillSwitchScope(KillScope{Strategy: domain.StrategyBasisArb}, "strategy review")
+	<-done
+	if r := mgr.ValidateSignal(signal(domain.StrategyBasisArb, "nobitex")); r.Approved {
+		t.Error("expected the halted strategy rejected on every venue")
+	}
+	if mgr.IsKillSwitchActive() || mgr.GetMode() == domain.RiskModeHalted {
+		t.Error("expected a scoped halt to leave the global switch alone")
+	}
+	if len(cancelled) != 2 || cancelled[0].Venue != "kcex" || cancelled[1].Strategy != domain.StrategyBasisArb {
+		t.Errorf("expected each scope's orders cancelled, got %v", cancelled)
+	}
+
+	// Scoped halts survive a restart until lifted one by one.
+	reloaded := NewKillSwitch(mgr.killSwitch.filePath, mgr.logger)
+	if len(reloaded.Scopes()) != 2 {
+		t.Fatalf("expected 2 scoped halts reloaded, got %v", reloaded.Scopes())
+	}
+	mgr.DeactivateKillSwitchScope(KillScope{Venue: "kcex"})
+	if r := mgr.ValidateSignal(signal(domain.StrategyTriArb, "kcex")); r.Reason == RejectKillSwitch {
+		t.Errorf("expected the kcex halt lifted, got %s", r.Details)
+	}
+	if r := mgr.ValidateSignal(signal(domain.StrategyBasisArb, "kcex")); r.Reason != RejectKillSwitch {
+		t.Errorf("expected the strategy halt still in force, got %s", r.Reason)
+	}
+}
+
+func TestKillSwitchWatcher_ExternalScopedActivation(t *testing.T) {
+	mgr := newTestManager(t)
+	path := filepath.Join(t.TempDir(), "killswitch.json")
+	mgr.killSwitch = NewKillSwitch(path, mgr.logger)
+
+	scopes := make(chan KillScope, 1)
+	mgr.SetScopedKillSwitchCallback(func(scope KillScope) { scopes <- scope })
+	mgr.SetKillSwitchCallback(func() { t.Error("expected the global callback not to run") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mgr.RunKillSwitchWatcher(ctx)
+
+	if err := os.WriteFile(path, []byte(`{"active": true, "venue": "nobitex", "reason": "ops"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case scope := <-scopes:
+		if scope != (KillScope{Venue: "nobitex"}) {
+			t.Errorf("expected nobitex halted, got %s", scope)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the scoped callback within a second of the file changing")
+	}
+	if mgr.IsKillSwitchActive() || mgr.GetMode() == domain.RiskModeHalted {
+		t.Error("expected a scoped activation to leave the system running")
+	}
+	if reason, halted := mgr.killSwitch.Halts("nobitex", domain.StrategyTriArb); !halted || reason != "venue nobitex: ops" {
+		t.Errorf("expected nobitex halted for ops, got %v %q", halted, reason)
+	}
+}
+
+func TestKillSwitchWatcher_ExternalActivation(t *testing.T) {
+	mgr := newTestManager(t)
+	path := filepath.Join(t.TempDir(), "killswitch.json")
+	mgr.killSwitch = NewKillSwitch(path, mgr.logger)
+
+	cancelled := make(chan struct{})
+	mgr.SetKillSwitchCallback(func() { close(cancelled) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mgr.RunKillSwitchWatcher(ctx)
+
+	if err := os.WriteFile(path, []byte(`{"active": true, "reason": "watchdog"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the kill switch callback within a second of the file changing")
+	}
+	if !mgr.IsKillSwitchActive() || mgr.killSwitch.Reason() != "watchdog" {
+		t.Errorf("expected kill switch active with reason watchdog, got %v %q", mgr.IsKillSwitchActive(), mgr.killSwitch.Reason())
+	}
+	if mgr.GetMode() != domain.RiskModeHalted {
+		t.Errorf("expected halted mode, got %s", mgr.GetMode())
+	}
+
+	// Clearing the file does not resume trading.
+	if err := os.WriteFile(path, []byte(`{"active": false}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * killSwitchPollInterval)
+	if !mgr.IsKillSwitchActive() {
+		t.Error("expected an external deactivation to be ignored")
+	}
+}
+
 func TestDailyPnLTracking(t *testing.T) {
 	tracker := NewPnLTracker()
 
@@ -153,3 +546,243 @@ func TestDailyPnLTracking(t *testing.T) {
 		t.Errorf("expected %s, got %s", expected, tracker.TotalDailyPnL())
 	}
 }
+
+type fakeDailyResetter struct{ resets int }
+
+func (f *fakeDailyResetter) ResetDaily() { f.resets++ }
+
+func TestRolloverDay(t *testing.T) {
+	mgr := newTestManager(t)
+	other := &fakeDailyResetter{}
+
+	// -10500 is past the 80% warning level of the 12500 cap.
+	mgr.OnOrderFill(domain.Order{Venue: "nobitex", Symbol: "BTC/USDT"}, decimal.NewFromInt(-10500))
+	mgr.mu.Lock()
+	mgr.checkPnLLimits()
+	mgr.mu.Unlock()
+	if mgr.GetMode() != domain.RiskModeWarning {
+		t.Fatalf("expected warning mode before rollover, got %s", mgr.GetMode())
+	}
+
+	// One completed cycle with three fills and one that failed after its
+	// first leg filled; a funding payment on top.
+	filled := domain.LegExecution{ActualSize: decimal.NewFromInt(1)}
+	mgr.OnExecutionReport(domain.ExecutionReport{
+		Status:    "completed",
+		Legs:      []domain.LegExecution{filled, filled, filled},
+		TotalFees: decimal.RequireFromString("1.5"),
+	})
+	mgr.OnExecutionReport(domain.ExecutionReport{
+		Status:    "failed",
+		Legs:      []domain.LegExecution{filled, {}},
+		TotalFees: decimal.RequireFromString("0.5"),
+	})
+	mgr.OnFundingPayment(decimal.NewFromInt(12))
+
+	dayStart := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	snap := mgr.RolloverDay(dayStart, other)
+
+	if !snap.Date.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected snapshot for 2024-03-01, got %s", snap.Date)
+	}
+	if !snap.TotalPnL.Equal(decimal.NewFromInt(-10488)) {
+		t.Errorf("expected total -10488, got %s", snap.TotalPnL)
+	}
+	if snap.NumCycles != 1 || snap.NumTrades != 4 {
+		t.Errorf("expected 1 cycle and 4 trades, got %d and %d", snap.NumCycles, snap.NumTrades)
+	}
+	if !snap.FeesPaid.Equal(decimal.NewFromInt(2)) || !snap.FundingNet.Equal(decimal.NewFromInt(12)) {
+		t.Errorf("expected fees 2 and funding 12, got %s and %s", snap.FeesPaid, snap.FundingNet)
+	}
+	if cycles, trades, fees := mgr.pnlTracker.Activity(); cycles != 0 || trades != 0 || !fees.IsZero() {
+		t.Errorf("expected activity reset after rollover, got %d cycles, %d trades, %s fees", cycles, trades, fees)
+	}
+	if other.resets != 1 {
+		t.Errorf("expected other trackers reset once, got %d", other.resets)
+	}
+	if !mgr.pnlTracker.TotalDailyPnL().IsZero() {
+		t.Errorf("expected zero PnL after rollover, got %s", mgr.pnlTracker.TotalDailyPnL())
+	}
+	if mgr.GetMode() != domain.RiskModeNormal {
+		t.Errorf("expected warning cleared after rollover, got %s", mgr.GetMode())
+	}
+}
+
+func TestFeedBlockedEntersDataStale(t *testing.T) {
+	mgr := newTestManager(t)
+
+	mgr.SetFeedBlocked("kcex", "ETH/USDT", true)
+	mgr.SetFeedBlocked("okx", "ETH/USDT", true)
+	if mgr.GetMode() != domain.RiskModeDataStale || mgr.GetState().Mode != domain.RiskModeDataStale {
+		t.Fatalf("expected data stale mode, got %s", mgr.GetMode())
+	}
+
+	// Signals away from the stale feeds still trade.
+	signal := domain.TradeSignal{
+		SignalID: uuid.Must(uuid.NewV7()),
+		Strategy: domain.StrategyTriArb,
+		Venue:    "nobitex",
+		Legs:     []domain.LegSpec{{Symbol: "BTC/USDT", Side: domain.SideBuy, Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(0.1), OrderType: domain.OrderTypeLimit}},
+	}
+	if result := mgr.ValidateSignal(signal); !result.Approved {
+		t.Errorf("expected a signal on a fresh feed approved, got %s: %s", result.Reason, result.Details)
+	}
+
+	// A PnL warning reached meanwhile shows once the feeds recover.
+	mgr.OnOrderFill(domain.Order{Venue: "nobitex", Symbol: "BTC/USDT"}, decimal.NewFromInt(-10500))
+	mgr.mu.Lock()
+	mgr.checkPnLLimits()
+	mgr.mu.Unlock()
+	mgr.SetFeedBlocked("kcex", "ETH/USDT", false)
+	if mgr.GetMode() != domain.RiskModeDataStale {
+		t.Fatalf("expected data stale while one feed is blocked, got %s", mgr.GetMode())
+	}
+	mgr.SetFeedBlocked("okx", "ETH/USDT", false)
+	if mgr.GetMode() != domain.RiskModeWarning {
+		t.Fatalf("expected warning mode after recovery, got %s", mgr.GetMode())
+	}
+
+	// A halt is never masked.
+	mgr.SetFeedBlocked("kcex", "ETH/USDT", true)
+	mgr.ActivateKillSwitch("test")
+	defer mgr.DeactivateKillSwitch()
+	if mgr.GetMode() != domain.RiskModeHalted {
+		t.Errorf("expected halted mode, got %s", mgr.GetMode())
+	}
+}
+
+func TestCheckpointStateIsIsolatedFromLaterFills(t *testing.T) {
+	mgr := newTestManager(t)
+	fill := domain.Order{
+		Venue:        "nobitex",
+		Symbol:       "BTC/USDT",
+		Side:         domain.SideBuy,
+		FilledSize:   decimal.NewFromFloat(0.5),
+		AvgFillPrice: decimal.NewFromInt(50000),
+	}
+	mgr.OnOrderFill(fill, decimal.Zero)
+	mgr.OnOrderStateChange(domain.OrderStateChange{
+		Order:      fill,
+		PrevStatus: domain.OrderStatusPendingNew,
+		NewStatus:  domain.OrderStatusSubmitted,
+	})
+
+	cp := mgr.GetCheckpointState()
+	mgr.OnOrderFill(fill, decimal.Zero)
+	mgr.OnOrderStateChange(domain.OrderStateChange{
+		Order:      fill,
+		PrevStatus: domain.OrderStatusPendingNew,
+		NewStatus:  domain.OrderStatusSubmitted,
+	})
+
+	key := domain.VenueAssetKey{Venue: "nobitex", Asset: "BTC"}
+	if got := cp.Positions[key].Size; !got.Equal(decimal.NewFromFloat(0.5)) {
+		t.Errorf("checkpoint position changed after a later fill: %s", got)
+	}
+	if got := cp.VenueNotionals["nobitex"]; !got.Equal(decimal.NewFromInt(25000)) {
+		t.Errorf("checkpoint notional changed after a later fill: %s", got)
+	}
+	if got := cp.OpenOrderCounts.PerVenue["nobitex"]; got != 1 {
+		t.Errorf("checkpoint order count changed after a later order: %d", got)
+	}
+}
+
+func TestOnOrderFillKeysPositionsByAccount(t *testing.T) {
+	mgr := newTestManager(t)
+	fill := func(account string, size float64) domain.Order {
+		return domain.Order{
+			Venue:        "nobitex",
+			Account:      account,
+			Symbol:       "BTC/USDT",
+			Side:         domain.SideBuy,
+			FilledSize:   decimal.NewFromFloat(size),
+			AvgFillPrice: decimal.NewFromInt(50000),
+		}
+	}
+	mgr.OnOrderFill(fill("", 0.5), decimal.Zero)
+	mgr.OnOrderFill(fill("basis", 0.75), decimal.Zero)
+
+	cp := mgr.GetCheckpointState()
+	if got := cp.Positions[domain.VenueAssetKey{Venue: "nobitex", Asset: "BTC"}].Size; !got.Equal(decimal.NewFromFloat(0.5)) {
+		t.Errorf("default account position = %s, want 0.5", got)
+	}
+	pos := cp.Positions[domain.VenueAssetKey{Venue: "nobitex", Account: "basis", Asset: "BTC"}]
+	if pos == nil || pos.Account != "basis" || !pos.Size.Equal(decimal.NewFromFloat(0.75)) {
+		t.Fatalf("basis account position = %+v, want 0.75 on basis", pos)
+	}
+
+	// The position limit covers the venue as a whole: 1.25 held over both
+	// accounts leaves room for 0.25 of the 1.5 limit.
+	signal := func(size float64) domain.TradeSignal {
+		return domain.TradeSignal{
+			SignalID: uuid.Must(uuid.NewV7()),
+			Strategy: domain.StrategyTriArb,
+			Venue:    "nobitex",
+			Legs: []domain.LegSpec{{
+				Symbol:    "BTC/USDT",
+				Side:      domain.SideBuy,
+				Price:     decimal.NewFromInt(50000),
+				Size:      decimal.NewFromFloat(size),
+				OrderType: domain.OrderTypeLimit,
+			}},
+		}
+	}
+	if result := mgr.ValidateSignal(signal(0.25)); !result.Approved {
+		t.Errorf("expected 0.25 BTC to be approved, got %s - %s", result.Reason, result.Details)
+	}
+	if result := mgr.ValidateSignal(signal(0.3)); result.Reason != RejectPositionLimit {
+		t.Errorf("expected %s across accounts, got %+v", RejectPositionLimit, result)
+	}
+}
+
+// TestCheckpointStateUnderConcurrentFills encodes checkpoints while fills
+// land. Run with -race: a shallow copy shares the position pointers and maps
+// with the manager and is reported as a data race.
+func TestCheckpointStateUnderConcurrentFills(t *testing.T) {
+	mgr := newTestManager(t)
+	fill := domain.Order{
+		Venue:        "nobitex",
+		Symbol:       "BTC/USDT",
+		Side:         domain.SideBuy,
+		FilledSize:   decimal.NewFromFloat(0.001),
+		AvgFillPrice: decimal.NewFromInt(50000),
+	}
+	mgr.OnOrderFill(fill, decimal.Zero)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			mgr.OnOrderFill(fill, decimal.NewFromFloat(0.1))
+			mgr.OnOrderStateChange(domain.OrderStateChange{
+				Order:      fill,
+				PrevStatus: domain.OrderStatusPendingNew,
+				NewStatus:  domain.OrderStatusSubmitted,
+			})
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		cp := mgr.GetCheckpointState()
+		first, err := domain.EncodeRiskState(cp)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		second, err := domain.EncodeRiskState(cp)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if !bytes.Equal(first, second) {
+			t.Fatalf("checkpoint %d changed while being encoded", i)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
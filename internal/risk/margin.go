@@ -0,0 +1,154 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// scheduleMargin returns the initial and maintenance margin tiers charges on
+// a position of notional: each tier's rates apply to the part of the
+// notional inside it, and the last tier takes whatever is left.
+func scheduleMargin(tiers []config.MarginTierConfig, notional decimal.Decimal) (initial, maintenance decimal.Decimal) {
+	hundred := decimal.NewFromInt(100)
+	floor := decimal.Zero
+	for i, tier := range tiers {
+		if !notional.GreaterThan(floor) {
+			break
+		}
+		part := notional.Sub(floor)
+		if last := i == len(tiers)-1; !last && tier.MaxNotionalUSDT.IsPositive() {
+			part = decimal.Min(part, tier.MaxNotionalUSDT.Sub(floor))
+			floor = tier.MaxNotionalUSDT
+		} else {
+			floor = notional
+		}
+		initial = initial.Add(part.Mul(decimal.NewFromFloat(tier.InitialPct)).Div(hundred))
+		maintenance = maintenance.Add(part.Mul(decimal.NewFromFloat(tier.MaintenancePct)).Div(hundred))
+	}
+	return initial, maintenance
+}
+
+// marginForecast is a venue's perp margin now and once a signal's perp legs
+// have filled.
+type marginForecast struct {
+	venue                       string
+	collateral                  decimal.Decimal
+	initial, projectedInitial   decimal.Decimal
+	maintenance, projectedMaint decimal.Decimal
+}
+
+// utilization returns initial margin as a percentage of collateral.
+func (f marginForecast) utilization(initial decimal.Decimal) decimal.Decimal {
+	if !f.collateral.IsPositive() {
+		return decimal.Zero
+	}
+	return initial.Div(f.collateral).Mul(decimal.NewFromInt(100))
+}
+
+// forecastMargin estimates the margin of every venue with a margin schedule
+// that the signal trades perps on. A leg moves its asset's position on the
+// venue by its size, so a leg that reduces a position releases margin.
+// Positions are marked as for correlation groups; legs at their price. The
+// caller holds m.mu.
+func (m *Manager) forecastMargin(signal domain.TradeSignal) []marginForecast {
+	cfg := m.cfg.MarginUtilization
+	if !cfg.Enabled {
+		return nil
+	}
+
+	type perpLeg struct {
+		size, price decimal.Decimal
+	}
+	legs := make(map[domain.VenueAssetKey]perpLeg)
+	for i, leg := range signal.Legs {
+		if leg.InstrumentType != domain.InstrumentPerp {
+			continue
+		}
+		venue := signal.LegVenue(i)
+		if _, ok := cfg.Venues[venue]; !ok {
+			continue
+		}
+		key := domain.VenueAssetKey{Venue: venue, Asset: extractAsset(leg.Symbol)}
+		size := leg.Size
+		if leg.Side == domain.SideSell {
+			size = size.Neg()
+		}
+		l := legs[key]
+		l.size = l.size.Add(size)
+		l.price = leg.Price
+		legs[key] = l
+	}
+	if len(legs) == 0 {
+		return nil
+	}
+
+	venues := make(map[string]bool)
+	for key := range legs {
+		venues[key.Venue] = true
+	}
+	names := make([]string, 0, len(venues))
+	for venue := range venues {
+		names = append(names, venue)
+	}
+	sort.Strings(names)
+
+	forecasts := make([]marginForecast, 0, len(names))
+	for _, venue := range names {
+		schedule := cfg.Venues[venue]
+		f := marginForecast{venue: venue, collateral: schedule.CollateralUSDT}
+		for key, pos := range m.state.Positions {
+			if key.Venue != venue || pos == nil || pos.InstrumentType != domain.InstrumentPerp || pos.Size.IsZero() {
+				continue
+			}
+			mark := m.markPrice(key.Venue, key.Asset, pos.EntryPrice)
+			initial, maint := scheduleMargin(schedule.Tiers, pos.Size.Abs().Mul(mark))
+			f.initial = f.initial.Add(initial)
+			f.maintenance = f.maintenance.Add(maint)
+			if _, traded := legs[domain.VenueAssetKey{Venue: key.Venue, Asset: key.Asset}]; !traded {
+				f.projectedInitial = f.projectedInitial.Add(initial)
+				f.projectedMaint = f.projectedMaint.Add(maint)
+			}
+		}
+		for key, leg := range legs {
+			if key.Venue != venue {
+				continue
+			}
+			size := leg.size
+			for posKey, pos := range m.state.Positions {
+				if posKey.Venue == key.Venue && posKey.Asset == key.Asset && pos != nil && pos.InstrumentType == domain.InstrumentPerp {
+					size = size.Add(pos.Size)
+				}
+			}
+			initial, maint := scheduleMargin(schedule.Tiers, size.Abs().Mul(leg.price))
+			f.projectedInitial = f.projectedInitial.Add(initial)
+			f.projectedMaint = f.projectedMaint.Add(maint)
+		}
+		forecasts = append(forecasts, f)
+	}
+	return forecasts
+}
+
+// checkMarginUtilization rejects a signal that would push a venue's initial
+// margin past the utilization ceiling. A signal that lowers the margin it
+// finds over the ceiling is let through, so positions can still be cut.
+func (m *Manager) checkMarginUtilization(signal domain.TradeSignal) ValidationResult {
+	ceiling := decimal.NewFromFloat(m.cfg.MarginUtilization.MaxUtilizationPct)
+	for _, f := range m.forecastMargin(signal) {
+		projected := f.utilization(f.projectedInitial)
+		if projected.GreaterThan(ceiling) && f.projectedInitial.GreaterThan(f.initial) {
+			return ValidationResult{
+				Approved: false,
+				Reason:   RejectMarginUtilization,
+				Details: fmt.Sprintf("venue %s margin utilization would be %s%% > %s%% (initial %s, maintenance %s of %s collateral)",
+					f.venue, projected.StringFixed(2), ceiling.String(),
+					f.projectedInitial.StringFixed(2), f.projectedMaint.StringFixed(2), f.collateral.String()),
+			}
+		}
+	}
+	return ValidationResult{Approved: true}
+}
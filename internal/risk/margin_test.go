@@ -0,0 +1,115 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestScheduleMargin(t *testing.T) {
+	tiers := []config.MarginTierConfig{
+		{MaxNotionalUSDT: decimal.NewFromInt(50000), InitialPct: 1, MaintenancePct: 0.5},
+		{MaxNotionalUSDT: decimal.NewFromInt(250000), InitialPct: 2, MaintenancePct: 1},
+		{InitialPct: 5, MaintenancePct: 2.5},
+	}
+	cases := []struct {
+		notional             int64
+		initial, maintenance string
+	}{
+		{0, "0", "0"},
+		{40000, "400", "200"},
+		// 500 + 2% of 50000.
+		{100000, "1500", "750"},
+		// 500 + 4000 + 5% of 50000.
+		{300000, "7000", "3500"},
+	}
+	for _, tc := range cases {
+		initial, maint := scheduleMargin(tiers, decimal.NewFromInt(tc.notional))
+		if !initial.Equal(decimal.RequireFromString(tc.initial)) || !maint.Equal(decimal.RequireFromString(tc.maintenance)) {
+			t.Errorf("notional %d: expected %s/%s, got %s/%s", tc.notional, tc.initial, tc.maintenance, initial, maint)
+		}
+	}
+}
+
+func TestValidateSignal_MarginUtilization(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.cfg.MaxPosition = map[string]decimal.Decimal{}
+	mgr.cfg.MaxNotionalPerVenue = map[string]decimal.Decimal{}
+	mgr.cfg.MarginUtilization = config.MarginUtilizationConfig{
+		Enabled:           true,
+		MaxUtilizationPct: 50,
+		Venues: map[string]config.VenueMarginConfig{
+			"kcex": {
+				CollateralUSDT: decimal.NewFromInt(2000),
+				Tiers:          []config.MarginTierConfig{{InitialPct: 2, MaintenancePct: 1}},
+			},
+		},
+	}
+
+	for _, symbol := range []string{"BTCUSDT", "BTC/USDT"} {
+		mgr.mdService.UpdateOrderBook(domain.OrderBookSnapshot{
+			Venue:  "kcex",
+			Symbol: symbol,
+			Bids:   []domain.PriceLevel{{Price: decimal.NewFromInt(49999), Size: decimal.NewFromInt(5)}},
+			Asks:   []domain.PriceLevel{{Price: decimal.NewFromInt(50001), Size: decimal.NewFromInt(5)}},
+		})
+	}
+
+	// A 1 BTC short marked at the 50000 mid: 1000 of initial margin, 50% used.
+	mgr.UpdatePosition(domain.VenueAssetKey{Venue: "kcex", Asset: "BTC"}, &domain.Position{
+		Venue:          "kcex",
+		Asset:          "BTC",
+		InstrumentType: domain.InstrumentPerp,
+		Size:           decimal.NewFromInt(-1),
+		EntryPrice:     decimal.NewFromInt(50000),
+	})
+
+	perp := func(side domain.Side, size string) domain.TradeSignal {
+		return domain.TradeSignal{
+			SignalID: uuid.Must(uuid.NewV7()),
+			Strategy: domain.StrategyBasisArb,
+			Venue:    "kcex",
+			Legs: []domain.LegSpec{
+				{Symbol: "BTCUSDT", Side: side, InstrumentType: domain.InstrumentPerp,
+					Price: decimal.NewFromInt(50000), Size: decimal.RequireFromString(size), OrderType: domain.OrderTypeLimit},
+			},
+		}
+	}
+
+	result := mgr.ValidateSignal(perp(domain.SideSell, "0.2"))
+	if result.Approved || result.Reason != RejectMarginUtilization {
+		t.Fatalf("expected adding to the short rejected, got %+v", result)
+	}
+
+	// Buying back releases margin, even though utilization is at the ceiling.
+	if result := mgr.ValidateSignal(perp(domain.SideBuy, "0.2")); !result.Approved {
+		t.Errorf("expected a reducing leg approved, got %s - %s", result.Reason, result.Details)
+	}
+
+	// Spot legs use no margin.
+	spot := perp(domain.SideSell, "5")
+	spot.Legs[0].Symbol, spot.Legs[0].InstrumentType = "BTC/USDT", domain.InstrumentSpot
+	if result := mgr.ValidateSignal(spot); !result.Approved {
+		t.Errorf("expected a spot leg approved, got %s - %s", result.Reason, result.Details)
+	}
+
+	usage := mgr.LimitUsage(perp(domain.SideSell, "0.2"))
+	var margin *LimitUsage
+	for i := range usage {
+		if usage[i].Limit == RejectMarginUtilization {
+			margin = &usage[i]
+		}
+	}
+	if margin == nil || margin.Scope != "kcex" || !margin.Current.Equal(decimal.NewFromInt(50)) || !margin.Projected.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("expected kcex margin usage 50%% -> 60%%, got %+v", margin)
+	}
+
+	mgr.cfg.MarginUtilization.Enabled = false
+	if result := mgr.ValidateSignal(perp(domain.SideSell, "0.2")); !result.Approved {
+		t.Errorf("expected no margin check when disabled, got %s - %s", result.Reason, result.Details)
+	}
+}
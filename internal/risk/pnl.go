@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
 )
 
 type PnLTracker struct {
@@ -12,29 +14,57 @@ type PnLTracker struct {
 
 	dailyRealizedPnL   decimal.Decimal
 	dailyUnrealizedPnL decimal.Decimal
+	dailyFunding       decimal.Decimal // part of dailyRealizedPnL
+	dailyCycles        int
+	dailyTrades        int
+	dailyFees          decimal.Decimal
 	lastReset          time.Time
+	loc                *time.Location
 }
 
 func NewPnLTracker() *PnLTracker {
 	return &PnLTracker{
-		lastReset: todayUTC(),
+		lastReset: domain.TradingDayStart(time.Now(), time.UTC),
+		loc:       time.UTC,
 	}
 }
 
-func todayUTC() time.Time {
-	now := time.Now().UTC()
-	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+// SetLocation moves the trading day boundary to midnight in loc. It is meant
+// to be called at startup, before any PnL is recorded.
+func (p *PnLTracker) SetLocation(loc *time.Location) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loc = loc
+	p.lastReset = domain.TradingDayStart(time.Now(), loc)
 }
 
 func (p *PnLTracker) checkDailyReset() {
-	today := todayUTC()
+	today := domain.TradingDayStart(time.Now(), p.loc)
 	if today.After(p.lastReset) {
 		p.dailyRealizedPnL = decimal.Zero
 		p.dailyUnrealizedPnL = decimal.Zero
+		p.dailyFunding = decimal.Zero
+		p.dailyCycles, p.dailyTrades, p.dailyFees = 0, 0, decimal.Zero
 		p.lastReset = today
 	}
 }
 
+// Rollover closes the current day: it returns the realized and unrealized
+// PnL accumulated so far and resets them and the day's activity counts to
+// zero, starting a new day at dayStart.
+func (p *PnLTracker) Rollover(dayStart time.Time) (realized, unrealized decimal.Decimal) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	realized, unrealized = p.dailyRealizedPnL, p.dailyUnrealizedPnL
+	p.dailyRealizedPnL = decimal.Zero
+	p.dailyUnrealizedPnL = decimal.Zero
+	p.dailyFunding = decimal.Zero
+	p.dailyCycles, p.dailyTrades, p.dailyFees = 0, 0, decimal.Zero
+	p.lastReset = dayStart
+	return realized, unrealized
+}
+
 func (p *PnLTracker) AddRealizedPnL(amount decimal.Decimal) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -42,6 +72,16 @@ func (p *PnLTracker) AddRealizedPnL(amount decimal.Decimal) {
 	p.dailyRealizedPnL = p.dailyRealizedPnL.Add(amount)
 }
 
+// AddFunding records a funding payment, positive if received. It counts
+// towards realized PnL.
+func (p *PnLTracker) AddFunding(amount decimal.Decimal) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checkDailyReset()
+	p.dailyRealizedPnL = p.dailyRealizedPnL.Add(amount)
+	p.dailyFunding = p.dailyFunding.Add(amount)
+}
+
 func (p *PnLTracker) UpdateUnrealizedPnL(amount decimal.Decimal) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -66,3 +106,31 @@ func (p *PnLTracker) UnrealizedPnL() decimal.Decimal {
 	defer p.mu.RUnlock()
 	return p.dailyUnrealizedPnL
 }
+
+// AddCycle records an execution cycle: completed says whether it ran to the
+// end, trades is how many of its orders filled and fees what they cost.
+func (p *PnLTracker) AddCycle(completed bool, trades int, fees decimal.Decimal) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checkDailyReset()
+	if completed {
+		p.dailyCycles++
+	}
+	p.dailyTrades += trades
+	p.dailyFees = p.dailyFees.Add(fees)
+}
+
+// Activity returns today's completed cycles, filled orders and fees paid.
+func (p *PnLTracker) Activity() (cycles, trades int, fees decimal.Decimal) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.dailyCycles, p.dailyTrades, p.dailyFees
+}
+
+// FundingPnL returns the net funding received today, which RealizedPnL
+// includes.
+func (p *PnLTracker) FundingPnL() decimal.Decimal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.dailyFunding
+}
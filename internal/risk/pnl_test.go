@@ -2,8 +2,11 @@ package risk
 
 import (
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
 )
 
 func TestPnLTracker_AddRealized(t *testing.T) {
@@ -45,3 +48,35 @@ func TestPnLTracker_TotalPnL(t *testing.T) {
 		t.Errorf("expected %s, got %s", expected, total)
 	}
 }
+
+func TestPnLTracker_Rollover(t *testing.T) {
+	tracker := NewPnLTracker()
+	tracker.AddRealizedPnL(decimal.NewFromInt(250))
+	tracker.UpdateUnrealizedPnL(decimal.NewFromInt(-100))
+
+	realized, unrealized := tracker.Rollover(domain.TradingDayStart(time.Now(), time.UTC))
+	if !realized.Equal(decimal.NewFromInt(250)) || !unrealized.Equal(decimal.NewFromInt(-100)) {
+		t.Errorf("expected 250/-100, got %s/%s", realized, unrealized)
+	}
+	if !tracker.TotalDailyPnL().IsZero() {
+		t.Errorf("expected zero after rollover, got %s", tracker.TotalDailyPnL())
+	}
+}
+
+func TestPnLTracker_SetLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+14", 14*3600)
+	tracker := NewPnLTracker()
+	tracker.SetLocation(loc)
+
+	if !tracker.lastReset.Equal(domain.TradingDayStart(time.Now(), loc)) {
+		t.Errorf("expected day start in %s, got %s", loc, tracker.lastReset)
+	}
+
+	// A window opened yesterday in loc is reset on the next update.
+	tracker.AddRealizedPnL(decimal.NewFromInt(100))
+	tracker.lastReset = tracker.lastReset.AddDate(0, 0, -1)
+	tracker.AddRealizedPnL(decimal.NewFromInt(5))
+	if !tracker.RealizedPnL().Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected reset at local midnight, got %s", tracker.RealizedPnL())
+	}
+}
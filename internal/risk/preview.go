@@ -0,0 +1,152 @@
+package risk
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// LimitUsage is how much of one risk limit a signal would use. Current is
+// the usage now and Projected the usage once every leg is placed and filled.
+type LimitUsage struct {
+	Limit     RejectionReason `json:"limit"`
+	Scope     string          `json:"scope"`
+	Current   decimal.Decimal `json:"current"`
+	Projected decimal.Decimal `json:"projected"`
+	Threshold decimal.Decimal `json:"threshold"`
+}
+
+// LimitUsage lists every configured limit the signal touches, using the same
+// arithmetic as ValidateSignal. Position limits are checked leg by leg, so
+// an asset traded on several legs reports its largest leg. Open order limits
+// count one order per leg. The state is not changed.
+func (m *Manager) LimitUsage(signal domain.TradeSignal) []LimitUsage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var usage []LimitUsage
+
+	largestLeg := make(map[domain.VenueAssetKey]decimal.Decimal)
+	for i, leg := range signal.Legs {
+		asset := extractAsset(leg.Symbol)
+		if _, ok := m.cfg.MaxPosition[asset]; !ok {
+			continue
+		}
+		key := domain.VenueAssetKey{Venue: signal.LegVenue(i), Asset: asset}
+		if leg.Size.GreaterThan(largestLeg[key]) {
+			largestLeg[key] = leg.Size
+		}
+	}
+	keys := make([]domain.VenueAssetKey, 0, len(largestLeg))
+	for key := range largestLeg {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Venue != keys[j].Venue {
+			return keys[i].Venue < keys[j].Venue
+		}
+		return keys[i].Asset < keys[j].Asset
+	})
+	for _, key := range keys {
+		current := m.venuePosition(key.Venue, key.Asset).Abs()
+		usage = append(usage, LimitUsage{
+			Limit:     RejectPositionLimit,
+			Scope:     key.Venue + ":" + key.Asset,
+			Current:   current,
+			Projected: current.Add(largestLeg[key]),
+			Threshold: m.cfg.MaxPosition[key.Asset],
+		})
+	}
+
+	groups := make([]string, 0, len(m.cfg.CorrelationGroups))
+	for name := range m.cfg.CorrelationGroups {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	for _, name := range groups {
+		group := m.cfg.CorrelationGroups[name]
+		members := groupMembers(group.Assets)
+		additional, touched, err := m.signalGroupExposure(signal, members)
+		if !touched || err != nil {
+			continue
+		}
+		current := m.groupExposure(members)
+		usage = append(usage, LimitUsage{
+			Limit:     RejectCorrelationGroup,
+			Scope:     name,
+			Current:   current.Abs(),
+			Projected: current.Add(additional).Abs(),
+			Threshold: group.MaxExposureUSDT,
+		})
+	}
+
+	ceiling := decimal.NewFromFloat(m.cfg.MarginUtilization.MaxUtilizationPct)
+	for _, f := range m.forecastMargin(signal) {
+		usage = append(usage, LimitUsage{
+			Limit:     RejectMarginUtilization,
+			Scope:     f.venue,
+			Current:   f.utilization(f.initial),
+			Projected: f.utilization(f.projectedInitial),
+			Threshold: ceiling,
+		})
+	}
+
+	venues := signal.Venues()
+	for _, venue := range venues {
+		maxNotional, ok := m.cfg.MaxNotionalPerVenue[venue]
+		if !ok {
+			continue
+		}
+		current := m.state.VenueNotionals[venue]
+		additional := decimal.Zero
+		for i, leg := range signal.Legs {
+			if signal.LegVenue(i) == venue {
+				additional = additional.Add(leg.Price.Mul(leg.Size))
+			}
+		}
+		usage = append(usage, LimitUsage{
+			Limit:     RejectNotionalLimit,
+			Scope:     venue,
+			Current:   current,
+			Projected: current.Add(additional),
+			Threshold: maxNotional,
+		})
+	}
+
+	counts := m.state.OpenOrderCounts
+	usage = append(usage, orderUsage(RejectGlobalOrders, "global", counts.Global, len(signal.Legs), m.cfg.MaxOpenOrders.Global))
+	for _, venue := range venues {
+		legs := 0
+		for i := range signal.Legs {
+			if signal.LegVenue(i) == venue {
+				legs++
+			}
+		}
+		usage = append(usage, orderUsage(RejectVenueOrders, venue, counts.PerVenue[venue], legs, m.cfg.MaxOpenOrders.PerVenue))
+	}
+	perSymbol := make(map[string]int)
+	var symbols []string
+	for _, leg := range signal.Legs {
+		if perSymbol[leg.Symbol] == 0 {
+			symbols = append(symbols, leg.Symbol)
+		}
+		perSymbol[leg.Symbol]++
+	}
+	for _, symbol := range symbols {
+		usage = append(usage, orderUsage(RejectSymbolOrders, symbol, counts.PerSymbol[symbol], perSymbol[symbol], m.cfg.MaxOpenOrders.PerSymbol))
+	}
+
+	return usage
+}
+
+func orderUsage(limit RejectionReason, scope string, current, added, max int) LimitUsage {
+	return LimitUsage{
+		Limit:     limit,
+		Scope:     scope,
+		Current:   decimal.NewFromInt(int64(current)),
+		Projected: decimal.NewFromInt(int64(current + added)),
+		Threshold: decimal.NewFromInt(int64(max)),
+	}
+}
@@ -0,0 +1,51 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func TestLimitUsage(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.UpdatePosition(domain.VenueAssetKey{Venue: "nobitex", Asset: "BTC"}, &domain.Position{
+		Venue: "nobitex", Asset: "BTC", Size: decimal.NewFromFloat(0.5), EntryPrice: decimal.NewFromInt(50000),
+	})
+
+	signal := domain.TradeSignal{
+		SignalID: uuid.New(),
+		Strategy: domain.StrategyTriArb,
+		Venue:    "nobitex",
+		Legs: []domain.LegSpec{
+			{Symbol: "BTC/USDT", Side: domain.SideBuy, Price: decimal.NewFromInt(50000), Size: decimal.NewFromFloat(0.4)},
+			{Symbol: "BTC/USDT", Side: domain.SideSell, Price: decimal.NewFromInt(50010), Size: decimal.NewFromFloat(0.2)},
+		},
+	}
+	before := mgr.GetState()
+
+	byLimit := make(map[RejectionReason]LimitUsage)
+	for _, u := range mgr.LimitUsage(signal) {
+		byLimit[u.Limit] = u
+	}
+
+	pos := byLimit[RejectPositionLimit]
+	if pos.Scope != "nobitex:BTC" || !pos.Current.Equal(decimal.NewFromFloat(0.5)) || !pos.Projected.Equal(decimal.NewFromFloat(0.9)) || !pos.Threshold.Equal(decimal.NewFromFloat(1.5)) {
+		t.Errorf("unexpected position usage: %+v", pos)
+	}
+	if notional := byLimit[RejectNotionalLimit]; !notional.Projected.Equal(decimal.NewFromInt(30002)) {
+		t.Errorf("expected projected notional 30002, got %s", notional.Projected)
+	}
+	if sym := byLimit[RejectSymbolOrders]; sym.Scope != "BTC/USDT" || !sym.Projected.Equal(decimal.NewFromInt(2)) || !sym.Threshold.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("unexpected symbol order usage: %+v", sym)
+	}
+	if _, ok := byLimit[RejectCorrelationGroup]; ok {
+		t.Error("expected no correlation group usage without configured groups")
+	}
+
+	if after := mgr.GetState(); after.OpenOrderCounts.Global != before.OpenOrderCounts.Global {
+		t.Error("LimitUsage must not change the risk state")
+	}
+}
@@ -0,0 +1,238 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// StressScenario is a what-if shock applied to current positions.
+//
+// PriceShockPct moves every mark by the given percentage (-10 = marks fall
+// 10%). When FrozenVenue is set, only positions on that venue are shocked,
+// each against its own direction, since positions elsewhere can still be
+// flattened. FundingFlip projects the next funding payment with every perp
+// funding rate's sign reversed.
+type StressScenario struct {
+	Name          string          `json:"name"`
+	PriceShockPct decimal.Decimal `json:"price_shock_pct"`
+	FundingFlip   bool            `json:"funding_flip,omitempty"`
+	FrozenVenue   string          `json:"frozen_venue,omitempty"`
+}
+
+// PositionImpact is the projected PnL of one position under a scenario.
+// Account is empty for the venue's default account.
+type PositionImpact struct {
+	Venue       string          `json:"venue"`
+	Account     string          `json:"account,omitempty"`
+	Asset       string          `json:"asset"`
+	Size        decimal.Decimal `json:"size"`
+	Mark        decimal.Decimal `json:"mark"`
+	ShockedMark decimal.Decimal `json:"shocked_mark"`
+	PricePnL    decimal.Decimal `json:"price_pnl"`
+	FundingPnL  decimal.Decimal `json:"funding_pnl"`
+}
+
+// LimitBreach is a risk limit the scenario would violate.
+type LimitBreach struct {
+	Limit     RejectionReason `json:"limit"`
+	Scope     string          `json:"scope"`
+	Value     decimal.Decimal `json:"value"`
+	Threshold decimal.Decimal `json:"threshold"`
+}
+
+// StressResult is the outcome of one scenario.
+type StressResult struct {
+	Scenario      StressScenario   `json:"scenario"`
+	ProjectedPnL  decimal.Decimal  `json:"projected_pnl"`
+	ProjectedMode domain.RiskMode  `json:"projected_mode"`
+	Positions     []PositionImpact `json:"positions"`
+	Breaches      []LimitBreach    `json:"breaches"`
+}
+
+// StressReport collects the results of a stress run.
+type StressReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	DailyPnL    decimal.Decimal `json:"daily_pnl"`
+	Results     []StressResult  `json:"results"`
+}
+
+// HasBreaches reports whether any scenario breaches a limit.
+func (r *StressReport) HasBreaches() bool {
+	for _, res := range r.Results {
+		if len(res.Breaches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultStressScenarios builds the configured scenario set: one per price
+// shock, a funding flip, and one frozen-venue scenario per venue.
+func DefaultStressScenarios(cfg config.StressConfig, venues []string) []StressScenario {
+	var scenarios []StressScenario
+	for _, pct := range cfg.PriceShocksPct {
+		shock := decimal.NewFromFloat(pct)
+		sign := ""
+		if shock.IsPositive() {
+			sign = "+"
+		}
+		scenarios = append(scenarios, StressScenario{
+			Name:          fmt.Sprintf("price_%s%s%%", sign, shock.String()),
+			PriceShockPct: shock,
+		})
+	}
+	if cfg.FundingFlip {
+		scenarios = append(scenarios, StressScenario{Name: "funding_flip", FundingFlip: true})
+	}
+	if cfg.FrozenVenueShockPct > 0 {
+		shock := decimal.NewFromFloat(cfg.FrozenVenueShockPct)
+		for _, v := range venues {
+			scenarios = append(scenarios, StressScenario{
+				Name:          "frozen_" + v,
+				PriceShockPct: shock,
+				FrozenVenue:   v,
+			})
+		}
+	}
+	return scenarios
+}
+
+// RunStressTest shocks a snapshot of the current positions with each
+// scenario. A nil scenario list runs the configured defaults.
+func (m *Manager) RunStressTest(scenarios []StressScenario) *StressReport {
+	state := m.GetCheckpointState()
+
+	keys := make([]domain.VenueAssetKey, 0, len(state.Positions))
+	venueSet := make(map[string]struct{})
+	for k, pos := range state.Positions {
+		if pos == nil || pos.Size.IsZero() {
+			continue
+		}
+		keys = append(keys, k)
+		venueSet[k.Venue] = struct{}{}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Venue != keys[j].Venue {
+			return keys[i].Venue < keys[j].Venue
+		}
+		if keys[i].Asset != keys[j].Asset {
+			return keys[i].Asset < keys[j].Asset
+		}
+		return keys[i].Account < keys[j].Account
+	})
+
+	if scenarios == nil {
+		venues := make([]string, 0, len(venueSet))
+		for v := range venueSet {
+			venues = append(venues, v)
+		}
+		sort.Strings(venues)
+		scenarios = DefaultStressScenarios(m.cfg.Stress, venues)
+	}
+
+	report := &StressReport{
+		GeneratedAt: time.Now(),
+		DailyPnL:    state.DailyRealizedPnL.Add(state.DailyUnrealizedPnL),
+	}
+	for _, sc := range scenarios {
+		report.Results = append(report.Results, m.evaluateScenario(state, keys, sc, report.DailyPnL))
+	}
+	return report
+}
+
+func (m *Manager) evaluateScenario(state *domain.RiskState, keys []domain.VenueAssetKey, sc StressScenario, dailyPnL decimal.Decimal) StressResult {
+	hundred := decimal.NewFromInt(100)
+	res := StressResult{Scenario: sc}
+	venueNotional := make(map[string]decimal.Decimal)
+
+	for _, k := range keys {
+		pos := state.Positions[k]
+		mark := m.markPrice(k.Venue, k.Asset, pos.EntryPrice)
+
+		shock := sc.PriceShockPct
+		if sc.FrozenVenue != "" {
+			if k.Venue != sc.FrozenVenue {
+				shock = decimal.Zero
+			} else if pos.Size.IsPositive() {
+				shock = shock.Abs().Neg()
+			} else {
+				shock = shock.Abs()
+			}
+		}
+		shocked := mark.Add(mark.Mul(shock).Div(hundred))
+
+		impact := PositionImpact{
+			Venue:       k.Venue,
+			Account:     k.Account,
+			Asset:       k.Asset,
+			Size:        pos.Size,
+			Mark:        mark,
+			ShockedMark: shocked,
+			PricePnL:    shocked.Sub(mark).Mul(pos.Size),
+		}
+		if sc.FundingFlip && pos.InstrumentType == domain.InstrumentPerp {
+			// Longs pay a positive rate; with the sign flipped they pay -rate,
+			// so the projected payment is +size * mark * rate. Spot holdings
+			// pay no funding.
+			if rate, ok := m.fundingRate(k.Venue, k.Asset); ok {
+				impact.FundingPnL = pos.Size.Mul(shocked).Mul(rate)
+			}
+		}
+
+		res.ProjectedPnL = res.ProjectedPnL.Add(impact.PricePnL).Add(impact.FundingPnL)
+		venueNotional[k.Venue] = venueNotional[k.Venue].Add(pos.Size.Abs().Mul(shocked))
+		res.Positions = append(res.Positions, impact)
+	}
+
+	totalPnL := dailyPnL.Add(res.ProjectedPnL)
+	lossCap := m.cfg.DailyLossCapUSDT.Neg()
+	warningLevel := lossCap.Mul(decimal.NewFromInt(int64(m.cfg.WarningThresholdPct))).Div(hundred)
+
+	res.ProjectedMode = domain.RiskModeNormal
+	switch {
+	case totalPnL.LessThanOrEqual(lossCap):
+		res.ProjectedMode = domain.RiskModeHalted
+		res.Breaches = append(res.Breaches, LimitBreach{
+			Limit:     RejectDailyLoss,
+			Scope:     "global",
+			Value:     totalPnL,
+			Threshold: lossCap,
+		})
+	case totalPnL.LessThanOrEqual(warningLevel):
+		res.ProjectedMode = domain.RiskModeWarning
+	}
+
+	venues := make([]string, 0, len(venueNotional))
+	for v := range venueNotional {
+		venues = append(venues, v)
+	}
+	sort.Strings(venues)
+	for _, v := range venues {
+		maxNotional, ok := m.cfg.MaxNotionalPerVenue[v]
+		if !ok || !venueNotional[v].GreaterThan(maxNotional) {
+			continue
+		}
+		res.Breaches = append(res.Breaches, LimitBreach{
+			Limit:     RejectNotionalLimit,
+			Scope:     v,
+			Value:     venueNotional[v],
+			Threshold: maxNotional,
+		})
+	}
+
+	return res
+}
+
+func (m *Manager) fundingRate(venue, asset string) (decimal.Decimal, bool) {
+	rate, ok := m.mdService.GetFundingRate(venue, asset+"USDT")
+	if !ok {
+		return decimal.Zero, false
+	}
+	return rate.Rate, true
+}
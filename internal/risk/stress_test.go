@@ -0,0 +1,195 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/config"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+func setTestPosition(mgr *Manager, venue, asset string, size, entry decimal.Decimal) {
+	mgr.UpdatePosition(domain.VenueAssetKey{Venue: venue, Asset: asset}, &domain.Position{
+		Venue:      venue,
+		Asset:      asset,
+		Size:       size,
+		EntryPrice: entry,
+	})
+}
+
+func TestRunStressTest_PriceShock(t *testing.T) {
+	mgr := newTestManager(t)
+	setTestPosition(mgr, "nobitex", "BTC", decimal.NewFromInt(1), decimal.NewFromInt(48000))
+
+	report := mgr.RunStressTest([]StressScenario{
+		{Name: "down10", PriceShockPct: decimal.NewFromInt(-10)},
+	})
+
+	if len(report.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(report.Results))
+	}
+	res := report.Results[0]
+
+	// Mark is the book mid (50000.5), not the entry price.
+	want := decimal.RequireFromString("-5000.05")
+	if !res.ProjectedPnL.Equal(want) {
+		t.Errorf("projected pnl: got %s, want %s", res.ProjectedPnL, want)
+	}
+	if res.ProjectedMode != domain.RiskModeNormal {
+		t.Errorf("expected NORMAL mode, got %s", res.ProjectedMode)
+	}
+	if len(res.Breaches) != 0 {
+		t.Errorf("expected no breaches, got %+v", res.Breaches)
+	}
+	if report.HasBreaches() {
+		t.Error("HasBreaches should be false")
+	}
+}
+
+func TestRunStressTest_LimitBreaches(t *testing.T) {
+	mgr := newTestManager(t)
+	setTestPosition(mgr, "nobitex", "BTC", decimal.NewFromInt(5), decimal.NewFromInt(50000))
+
+	report := mgr.RunStressTest([]StressScenario{
+		{Name: "up5", PriceShockPct: decimal.NewFromInt(5)},
+		{Name: "down10", PriceShockPct: decimal.NewFromInt(-10)},
+	})
+
+	up := report.Results[0]
+	if up.ProjectedMode != domain.RiskModeNormal {
+		t.Errorf("up shock: expected NORMAL mode, got %s", up.ProjectedMode)
+	}
+	if len(up.Breaches) != 1 || up.Breaches[0].Limit != RejectNotionalLimit || up.Breaches[0].Scope != "nobitex" {
+		t.Errorf("up shock: expected nobitex notional breach, got %+v", up.Breaches)
+	}
+
+	down := report.Results[1]
+	if down.ProjectedMode != domain.RiskModeHalted {
+		t.Errorf("down shock: expected HALTED mode, got %s", down.ProjectedMode)
+	}
+	if len(down.Breaches) != 1 || down.Breaches[0].Limit != RejectDailyLoss {
+		t.Errorf("down shock: expected daily loss breach only, got %+v", down.Breaches)
+	}
+	if !report.HasBreaches() {
+		t.Error("HasBreaches should be true")
+	}
+}
+
+func TestRunStressTest_FrozenVenue(t *testing.T) {
+	mgr := newTestManager(t)
+	setTestPosition(mgr, "nobitex", "BTC", decimal.NewFromInt(1), decimal.NewFromInt(50000))
+	setTestPosition(mgr, "kcex", "ETH", decimal.NewFromInt(-10), decimal.NewFromInt(3000))
+
+	report := mgr.RunStressTest([]StressScenario{
+		{Name: "frozen_kcex", PriceShockPct: decimal.NewFromInt(10), FrozenVenue: "kcex"},
+	})
+	res := report.Results[0]
+
+	if len(res.Positions) != 2 {
+		t.Fatalf("expected 2 position impacts, got %d", len(res.Positions))
+	}
+	for _, p := range res.Positions {
+		switch p.Venue {
+		case "kcex":
+			// Short 10 ETH marked at entry (no book), shocked 10% against it.
+			if !p.PricePnL.Equal(decimal.NewFromInt(-3000)) {
+				t.Errorf("kcex pnl: got %s, want -3000", p.PricePnL)
+			}
+		case "nobitex":
+			if !p.PricePnL.IsZero() {
+				t.Errorf("unfrozen venue should not be shocked, got %s", p.PricePnL)
+			}
+		}
+	}
+}
+
+func TestRunStressTest_SubAccounts(t *testing.T) {
+	mgr := newTestManager(t)
+	for _, account := range []string{"hedge", ""} {
+		mgr.UpdatePosition(domain.VenueAssetKey{Venue: "kcex", Account: account, Asset: "ETH"}, &domain.Position{
+			Venue:      "kcex",
+			Account:    account,
+			Asset:      "ETH",
+			Size:       decimal.NewFromInt(1),
+			EntryPrice: decimal.NewFromInt(3000),
+		})
+	}
+
+	report := mgr.RunStressTest([]StressScenario{{Name: "down10", PriceShockPct: decimal.NewFromInt(-10)}})
+	res := report.Results[0]
+
+	if len(res.Positions) != 2 {
+		t.Fatalf("expected an impact per account, got %+v", res.Positions)
+	}
+	if res.Positions[0].Account != "" || res.Positions[1].Account != "hedge" {
+		t.Errorf("expected the default account then hedge, got %q and %q", res.Positions[0].Account, res.Positions[1].Account)
+	}
+	if !res.ProjectedPnL.Equal(decimal.NewFromInt(-600)) {
+		t.Errorf("projected pnl: got %s, want -600", res.ProjectedPnL)
+	}
+}
+
+func TestRunStressTest_FundingFlip(t *testing.T) {
+	mgr := newTestManager(t)
+	mgr.mdService.UpdateFundingRate(domain.FundingRate{
+		Venue:  "kcex",
+		Symbol: "BTCUSDT",
+		Rate:   decimal.RequireFromString("0.0001"),
+	})
+	mgr.mdService.UpdateFundingRate(domain.FundingRate{
+		Venue:  "nobitex",
+		Symbol: "BTCUSDT",
+		Rate:   decimal.RequireFromString("0.0001"),
+	})
+	mgr.UpdatePosition(domain.VenueAssetKey{Venue: "kcex", Asset: "BTC"}, &domain.Position{
+		Venue:          "kcex",
+		Asset:          "BTC",
+		InstrumentType: domain.InstrumentPerp,
+		Size:           decimal.NewFromInt(-2),
+		EntryPrice:     decimal.NewFromInt(50000),
+	})
+	setTestPosition(mgr, "nobitex", "BTC", decimal.NewFromInt(2), decimal.NewFromInt(50000))
+
+	report := mgr.RunStressTest([]StressScenario{{Name: "funding_flip", FundingFlip: true}})
+	res := report.Results[0]
+
+	// A short perp receives a positive rate; flipped it pays 2 * 50000 * 0.0001.
+	// The spot holding pays no funding whatever the rate.
+	for _, p := range res.Positions {
+		switch p.Venue {
+		case "kcex":
+			if !p.FundingPnL.Equal(decimal.NewFromInt(-10)) {
+				t.Errorf("perp funding pnl: got %s, want -10", p.FundingPnL)
+			}
+		case "nobitex":
+			if !p.FundingPnL.IsZero() {
+				t.Errorf("spot funding pnl: got %s, want 0", p.FundingPnL)
+			}
+		}
+	}
+}
+
+func TestDefaultStressScenarios(t *testing.T) {
+	cfg := config.StressConfig{
+		PriceShocksPct:      []float64{-10, 5},
+		FundingFlip:         true,
+		FrozenVenueShockPct: 10,
+	}
+
+	scenarios := DefaultStressScenarios(cfg, []string{"kcex", "nobitex"})
+
+	names := make([]string, len(scenarios))
+	for i, s := range scenarios {
+		names[i] = s.Name
+	}
+	want := []string{"price_-10%", "price_+5%", "funding_flip", "frozen_kcex", "frozen_nobitex"}
+	if len(names) != len(want) {
+		t.Fatalf("scenarios: got %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("scenario %d: got %s, want %s", i, names[i], want[i])
+		}
+	}
+}
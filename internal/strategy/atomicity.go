@@ -0,0 +1,19 @@
+package strategy
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/costmodel"
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// estimateAtomicity asks the cost model for the probability that all legs
+// fill. A cost model that cannot estimate it yields 1, leaving confidence
+// unchanged and the signal unfiltered by the execution engine's floor.
+func estimateAtomicity(cm costmodel.CostModelService, venue string, legs []domain.LegSpec, books []*domain.OrderBookSnapshot) decimal.Decimal {
+	est, ok := cm.(costmodel.AtomicityEstimator)
+	if !ok {
+		return decimal.NewFromInt(1)
+	}
+	return est.AtomicityProbability(venue, legs, books)
+}
@@ -12,15 +12,17 @@ import (
 	"github.com/crypto-trading/trading/internal/costmodel"
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/marketdata"
 )
 
 type BasisArbModule struct {
 	mu sync.RWMutex
 
-	spotBooks     map[string]*domain.OrderBookSnapshot // "venue:symbol" → spot book
-	perpBooks     map[string]*domain.OrderBookSnapshot // "venue:symbol" → perp book
-	fundingRates  map[string][]domain.FundingRate      // "venue:symbol" → recent funding rates
+	// fundingRates keeps a history the market data view does not: the view
+	// holds only each perp's latest rate.
+	fundingRates map[string][]domain.FundingRate // "venue:symbol" → recent funding rates
 
+	md        marketdata.View
 	costModel costmodel.CostModelService
 	bus       *eventbus.EventBus
 	logger    *slog.Logger
@@ -31,11 +33,28 @@ type BasisArbModule struct {
 	assets            []string
 	spotSymbolMap     map[string]string // asset → spot symbol
 	perpSymbolMap     map[string]string // asset → perp symbol
+	conservative      *ConservativeMode
+	crossVenue        *crossVenue
+	dynamicEdge       *DynamicEdge
+	decisions         *decisionCache[basisPair]
+}
+
+// basisPair identifies a pair evaluated on a venue.
+type basisPair struct {
+	venue, asset string
+}
+
+// crossVenue lets a pair whose spot or perp market is unavailable on one
+// venue trade that leg on another.
+type crossVenue struct {
+	extraEdgeBps decimal.Decimal
+	available    func(venue, symbol string) bool
 }
 
 func NewBasisArbModule(
 	venues []string,
 	assets []string,
+	md marketdata.View,
 	costModel costmodel.CostModelService,
 	bus *eventbus.EventBus,
 	minNetEdgeBps int,
@@ -50,9 +69,8 @@ func NewBasisArbModule(
 	}
 
 	return &BasisArbModule{
-		spotBooks:       make(map[string]*domain.OrderBookSnapshot),
-		perpBooks:       make(map[string]*domain.OrderBookSnapshot),
 		fundingRates:    make(map[string][]domain.FundingRate),
+		md:              md,
 		costModel:       costModel,
 		bus:             bus,
 		logger:          logger,
@@ -62,26 +80,84 @@ func NewBasisArbModule(
 		assets:          assets,
 		spotSymbolMap:   spotMap,
 		perpSymbolMap:   perpMap,
+		decisions:       newDecisionCache[basisPair](),
 	}
 }
 
-func (m *BasisArbModule) OnOrderBookUpdate(snap domain.OrderBookSnapshot) {
+// PerpSymbols returns the perp symbols whose funding rates the module
+// follows on each of its venues.
+func (m *BasisArbModule) PerpSymbols() []string {
+	symbols := make([]string, 0, len(m.assets))
+	for _, asset := range m.assets {
+		symbols = append(symbols, m.perpSymbolMap[asset])
+	}
+	return symbols
+}
+
+// SetConservativeMode makes the module demand more net edge and trade
+// smaller while c is active.
+func (m *BasisArbModule) SetConservativeMode(c *ConservativeMode) {
 	m.mu.Lock()
-	key := snap.Venue + ":" + snap.Symbol
-	isSpot := false
-	for _, sym := range m.spotSymbolMap {
-		if sym == snap.Symbol {
-			isSpot = true
-			break
-		}
+	defer m.mu.Unlock()
+	m.conservative = c
+}
+
+// SetDynamicEdge makes the module demand more net edge on pairs whose
+// markets are volatile.
+func (m *BasisArbModule) SetDynamicEdge(d *DynamicEdge) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dynamicEdge = d
+}
+
+// SetCrossVenue lets a pair whose spot or perp market is unavailable on a
+// venue, while the other market is still available there, take the
+// unavailable leg on the first other venue, in the module's venue order,
+// where it is available. Such a trade must clear extraEdgeBps more net edge
+// for the inventory it splits across two venues. available reports whether
+// a venue's market can be traded.
+func (m *BasisArbModule) SetCrossVenue(extraEdgeBps int, available func(venue, symbol string) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.crossVenue = &crossVenue{extraEdgeBps: decimal.NewFromInt(int64(extraEdgeBps)), available: available}
+}
+
+// legVenues returns the venues the spot and perp legs of a pair evaluated on
+// venue trade on: venue itself unless cross-venue trading substitutes one of
+// them. ok is false when the pair cannot be traded.
+func (m *BasisArbModule) legVenues(venue, spotSymbol, perpSymbol string) (spotVenue, perpVenue string, ok bool) {
+	cv := m.crossVenue
+	if cv == nil {
+		return venue, venue, true
 	}
-	if isSpot {
-		m.spotBooks[key] = &snap
-	} else {
-		m.perpBooks[key] = &snap
+	spotOK, perpOK := cv.available(venue, spotSymbol), cv.available(venue, perpSymbol)
+	switch {
+	case spotOK && perpOK:
+		return venue, venue, true
+	case spotOK:
+		perpVenue = m.alternateVenue(venue, perpSymbol)
+		return venue, perpVenue, perpVenue != ""
+	case perpOK:
+		spotVenue = m.alternateVenue(venue, spotSymbol)
+		return spotVenue, venue, spotVenue != ""
 	}
-	m.mu.Unlock()
+	return "", "", false
+}
+
+// alternateVenue returns the first venue other than venue where symbol can
+// be traded, or "" if there is none.
+func (m *BasisArbModule) alternateVenue(venue, symbol string) string {
+	for _, v := range m.venues {
+		if v != venue && m.crossVenue.available(v, symbol) {
+			return v
+		}
+	}
+	return ""
+}
 
+// OnOrderBookUpdate re-evaluates snap's venue, reading both books of each
+// pair from the market data view.
+func (m *BasisArbModule) OnOrderBookUpdate(snap domain.OrderBookSnapshot) {
 	m.evaluate(snap.Venue, snap.LocalTimestamp)
 }
 
@@ -103,109 +179,164 @@ func (m *BasisArbModule) evaluate(venue string, mdTimestamp time.Time) {
 		spotSymbol := m.spotSymbolMap[asset]
 		perpSymbol := m.perpSymbolMap[asset]
 
-		spotKey := venue + ":" + spotSymbol
-		perpKey := venue + ":" + perpSymbol
-
-		spotBook, spotOK := m.spotBooks[spotKey]
-		perpBook, perpOK := m.perpBooks[perpKey]
-		if !spotOK || !perpOK {
+		spotVenue, perpVenue, ok := m.legVenues(venue, spotSymbol, perpSymbol)
+		if !ok {
 			continue
 		}
 
-		spotMid, spotValid := spotBook.MidPrice()
-		perpMid, perpValid := perpBook.MidPrice()
-		if !spotValid || !perpValid {
+		spotBook, spotOK := m.md.GetBook(spotVenue, spotSymbol)
+		perpBook, perpOK := m.md.GetBook(perpVenue, perpSymbol)
+		if !spotOK || !perpOK {
 			continue
 		}
-
-		if spotMid.IsZero() {
+		key := basisPair{venue, asset}
+		extraBps := max(m.dynamicEdge.extraBps(spotVenue, spotSymbol), m.dynamicEdge.extraBps(perpVenue, perpSymbol))
+		state := m.decisionState(spotVenue, perpVenue, perpSymbol, spotBook, perpBook).word(uint64(extraBps))
+		if m.decisions.unchanged(key, state) {
 			continue
 		}
+		m.decisions.record(key, state, m.evaluatePair(venue, asset, spotVenue, perpVenue, spotBook, perpBook, extraBps, mdTimestamp))
+	}
+}
 
-		basis := perpMid.Sub(spotMid).Div(spotMid)
-		holdingDays := decimal.NewFromInt(int64(m.holdingHorizonH)).Div(decimal.NewFromInt(24))
-		if holdingDays.IsZero() {
-			continue
-		}
+// evaluatePair publishes a signal for asset's pair, evaluated on venue with
+// its legs on spotVenue and perpVenue, if the books offer enough net edge,
+// with extraBps added to the threshold, and reports whether it did.
+func (m *BasisArbModule) evaluatePair(venue, asset, spotVenue, perpVenue string, spotBook, perpBook *domain.OrderBookSnapshot, extraBps int64, mdTimestamp time.Time) bool {
+	spotSymbol := m.spotSymbolMap[asset]
+	perpSymbol := m.perpSymbolMap[asset]
+
+	spotMid, spotValid := spotBook.MidPrice()
+	perpMid, perpValid := perpBook.MidPrice()
+	if !spotValid || !perpValid {
+		return false
+	}
 
-		annualizedBasis := basis.Mul(decimal.NewFromInt(365)).Div(holdingDays)
-		_ = annualizedBasis
+	if spotMid.IsZero() {
+		return false
+	}
 
-		fundingCapture := m.estimateFundingCapture(venue, perpSymbol)
-		regime := m.classifyFundingRegime(venue, perpSymbol)
+	basis := perpMid.Sub(spotMid).Div(spotMid)
+	holdingDays := decimal.NewFromInt(int64(m.holdingHorizonH)).Div(decimal.NewFromInt(24))
+	if holdingDays.IsZero() {
+		return false
+	}
 
-		totalEdgeBps := basis.Abs().Add(fundingCapture.Abs()).Mul(decimal.NewFromInt(10000))
+	annualizedBasis := basis.Mul(decimal.NewFromInt(365)).Div(holdingDays)
+	_ = annualizedBasis
 
-		costEst, err := m.costModel.EstimateCost(venue, spotSymbol, domain.SideBuy, decimal.NewFromFloat(1), domain.OrderTypeLimit)
-		if err != nil {
-			continue
-		}
+	fundingCapture := m.estimateFundingCapture(perpVenue, perpSymbol)
+	regime := m.classifyFundingRegime(perpVenue, perpSymbol)
 
-		netEdgeBps := totalEdgeBps.Sub(costEst.TotalBps)
-		minEdge := decimal.NewFromInt(int64(m.minNetEdgeBps))
-
-		if netEdgeBps.GreaterThanOrEqual(minEdge) {
-			var spotSide, perpSide domain.Side
-			if perpMid.GreaterThan(spotMid) {
-				spotSide = domain.SideBuy
-				perpSide = domain.SideSell
-			} else {
-				spotSide = domain.SideSell
-				perpSide = domain.SideBuy
-			}
-
-			spotAsk, _ := spotBook.BestAsk()
-			perpBid, _ := perpBook.BestBid()
-
-			size := decimal.Min(spotAsk.Size, perpBid.Size)
-			if size.IsZero() {
-				continue
-			}
-
-			signalID, uuidErr := uuid.NewV7()
-			if uuidErr != nil {
-				signalID = uuid.New()
-			}
-
-			signal := domain.TradeSignal{
-				SignalID:  signalID,
-				Strategy:  domain.StrategyBasisArb,
-				Venue:     venue,
-				Legs: []domain.LegSpec{
-					{
-						Symbol:         spotSymbol,
-						Side:           spotSide,
-						InstrumentType: domain.InstrumentSpot,
-						Price:          spotAsk.Price,
-						Size:           size,
-						OrderType:      domain.OrderTypeLimit,
-					},
-					{
-						Symbol:         perpSymbol,
-						Side:           perpSide,
-						InstrumentType: domain.InstrumentPerp,
-						Price:          perpBid.Price,
-						Size:           size,
-						OrderType:      domain.OrderTypeLimit,
-					},
-				},
-				ExpectedEdgeBps:     netEdgeBps,
-				CostEstimate:        costEst,
-				Confidence:          costEst.Confidence,
-				CreatedAt:           time.Now(),
-				MarketDataTimestamp: mdTimestamp,
-			}
-
-			m.bus.PublishSignal(signal)
-			m.logger.Info("basis-arb signal detected",
-				"venue", venue,
-				"asset", asset,
-				"net_edge_bps", netEdgeBps.String(),
-				"regime", string(regime),
-				"signal_id", signal.SignalID.String(),
-			)
-		}
+	totalEdgeBps := basis.Abs().Add(fundingCapture.Abs()).Mul(decimal.NewFromInt(10000))
+
+	costEst, err := m.costModel.EstimateCost(spotVenue, spotSymbol, domain.SideBuy, decimal.NewFromFloat(1), domain.OrderTypeLimit)
+	if err != nil {
+		return false
+	}
+
+	netEdgeBps := totalEdgeBps.Sub(costEst.TotalBps)
+	minEdge := decimal.NewFromInt(m.conservative.minEdgeBps(int64(m.minNetEdgeBps)) + extraBps)
+	crossVenue := spotVenue != perpVenue
+	if crossVenue {
+		minEdge = minEdge.Add(m.crossVenue.extraEdgeBps)
+	}
+
+	if netEdgeBps.LessThan(minEdge) {
+		return false
+	}
+
+	var spotSide, perpSide domain.Side
+	if perpMid.GreaterThan(spotMid) {
+		spotSide = domain.SideBuy
+		perpSide = domain.SideSell
+	} else {
+		spotSide = domain.SideSell
+		perpSide = domain.SideBuy
+	}
+
+	spotAsk, _ := spotBook.BestAsk()
+	perpBid, _ := perpBook.BestBid()
+
+	size := m.conservative.scaleSize(decimal.Min(spotAsk.Size, perpBid.Size))
+	if size.IsZero() {
+		return false
+	}
+
+	signalID, uuidErr := uuid.NewV7()
+	if uuidErr != nil {
+		signalID = uuid.New()
+	}
+
+	legs := []domain.LegSpec{
+		{
+			Symbol:         spotSymbol,
+			Side:           spotSide,
+			InstrumentType: domain.InstrumentSpot,
+			Price:          spotAsk.Price,
+			Size:           size,
+			OrderType:      domain.OrderTypeLimit,
+		},
+		{
+			Symbol:         perpSymbol,
+			Side:           perpSide,
+			InstrumentType: domain.InstrumentPerp,
+			Price:          perpBid.Price,
+			Size:           size,
+			OrderType:      domain.OrderTypeLimit,
+		},
+	}
+	// The signal belongs to the venue being evaluated; only the
+	// substituted leg names its own.
+	if spotVenue != venue {
+		legs[0].Venue = spotVenue
+	}
+	if perpVenue != venue {
+		legs[1].Venue = perpVenue
+	}
+	atomicity := estimateAtomicity(m.costModel, venue, legs, []*domain.OrderBookSnapshot{spotBook, perpBook})
+
+	signal := domain.TradeSignal{
+		SignalID:            signalID,
+		Strategy:            domain.StrategyBasisArb,
+		Venue:               venue,
+		Legs:                legs,
+		ExpectedEdgeBps:     netEdgeBps,
+		CostEstimate:        costEst,
+		Confidence:          costEst.Confidence.Mul(atomicity),
+		Atomicity:           atomicity,
+		CreatedAt:           time.Now(),
+		MarketDataTimestamp: mdTimestamp,
+	}
+
+	m.bus.PublishSignal(signal)
+	m.logger.Info("basis-arb signal detected",
+		"venue", venue,
+		"asset", asset,
+		"net_edge_bps", netEdgeBps.String(),
+		"regime", string(regime),
+		"cross_venue", crossVenue,
+		"signal_id", signal.SignalID.String(),
+	)
+	return true
+}
+
+// decisionState fingerprints what a pair's decision depends on besides the
+// cost model: the legs' venues and books, the perp's funding history,
+// conservative mode and the cross-venue edge.
+func (m *BasisArbModule) decisionState(spotVenue, perpVenue, perpSymbol string, spotBook, perpBook *domain.OrderBookSnapshot) stateHash {
+	state := newStateHash().str(spotVenue).str(perpVenue).flag(m.conservative.on())
+	if m.crossVenue != nil {
+		state = state.decimal(m.crossVenue.extraEdgeBps)
+	}
+	state = state.book(spotBook, decisionDepth).book(perpBook, decisionDepth)
+	rates := m.fundingRates[perpVenue+":"+perpSymbol]
+	state = state.word(uint64(len(rates)))
+	if len(rates) > 0 {
+		last := rates[len(rates)-1]
+		state = state.decimal(last.Rate).word(uint64(last.Timestamp.UnixNano()))
 	}
+	return state
 }
 
 func (m *BasisArbModule) estimateFundingCapture(venue, symbol string) decimal.Decimal {
@@ -0,0 +1,77 @@
+package strategy
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+// venueView serves books keyed by "venue:symbol".
+type venueView map[string]*domain.OrderBookSnapshot
+
+func (v venueView) GetBook(venue, symbol string) (*domain.OrderBookSnapshot, bool) {
+	b, ok := v[venue+":"+symbol]
+	return b, ok
+}
+func (venueView) GetFunding(venue, symbol string) (*domain.FundingRate, bool) { return nil, false }
+func (venueView) GetRecentTrades(venue, symbol string, n int) []*domain.Trade { return nil }
+
+func TestBasisArbCrossVenueSubstitutesBlockedPerp(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	// The perp trades 100 bps over spot on both venues.
+	view := venueView{
+		"bybit:BTC/USDT": book("BTC/USDT", 99990, 1, 100000, 1),
+		"bybit:BTCUSDT":  book("BTCUSDT", 101000, 1, 101010, 1),
+		"okx:BTC/USDT":   book("BTC/USDT", 99990, 1, 100000, 1),
+		"okx:BTCUSDT":    book("BTCUSDT", 101000, 0.5, 101010, 0.5),
+	}
+	blocked := map[string]bool{"bybit:BTCUSDT": true}
+	available := func(venue, symbol string) bool { return !blocked[venue+":"+symbol] }
+
+	mod := NewBasisArbModule([]string{"bybit", "okx"}, []string{"BTC"}, view, &flatCost{}, bus, 20, 24, logger)
+	update := domain.OrderBookSnapshot{Venue: "bybit", Symbol: "BTC/USDT", LocalTimestamp: time.Now()}
+
+	mod.SetCrossVenue(5, available)
+	mod.OnOrderBookUpdate(update)
+
+	var signal domain.TradeSignal
+	select {
+	case signal = <-signals:
+	case <-time.After(time.Second):
+		t.Fatal("expected a cross-venue signal")
+	}
+	if signal.Venue != "bybit" || signal.LegVenue(0) != "bybit" || signal.LegVenue(1) != "okx" {
+		t.Fatalf("expected the spot leg on bybit and the perp on okx, got %s and %s", signal.LegVenue(0), signal.LegVenue(1))
+	}
+	if !signal.Legs[1].Size.Equal(decimal.NewFromFloat(0.5)) {
+		t.Errorf("expected size from the okx perp book, got %s", signal.Legs[1].Size)
+	}
+
+	// The extra edge a cross-venue trade demands can rule it out.
+	mod.SetCrossVenue(80, available)
+	mod.OnOrderBookUpdate(update)
+	select {
+	case signal := <-signals:
+		t.Fatalf("expected no signal below the cross-venue edge, got %+v", signal)
+	default:
+	}
+
+	// With no healthy alternate the pair is dropped.
+	blocked["okx:BTCUSDT"] = true
+	mod.SetCrossVenue(5, available)
+	mod.OnOrderBookUpdate(update)
+	select {
+	case signal := <-signals:
+		t.Fatalf("expected no signal without an alternate venue, got %+v", signal)
+	default:
+	}
+}
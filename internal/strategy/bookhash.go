@@ -0,0 +1,98 @@
+package strategy
+
+import (
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// decisionDepth is how many levels of each side of a book a decision is
+// taken to depend on. The modules price every leg at the touch, and a leg
+// sized to the touch can fill no deeper than the touch's price, so the
+// levels beneath cannot change their decisions.
+const decisionDepth = 1
+
+// stateHash fingerprints the inputs of a strategy decision without
+// allocating. It is FNV-1a over 64-bit words rather than bytes.
+type stateHash uint64
+
+const (
+	fnvOffset stateHash = 14695981039346656037
+	fnvPrime  stateHash = 1099511628211
+)
+
+func newStateHash() stateHash {
+	return fnvOffset
+}
+
+func (h stateHash) word(v uint64) stateHash {
+	return (h ^ stateHash(v)) * fnvPrime
+}
+
+// decimal mixes in d's coefficient and exponent. Equal values written with
+// different exponents hash differently, which only costs an evaluation.
+func (h stateHash) decimal(d decimal.Decimal) stateHash {
+	return h.word(uint64(d.CoefficientInt64())).word(uint64(d.Exponent()))
+}
+
+func (h stateHash) flag(b bool) stateHash {
+	if b {
+		return h.word(1)
+	}
+	return h.word(0)
+}
+
+func (h stateHash) str(s string) stateHash {
+	for i := 0; i < len(s); i++ {
+		h = h.word(uint64(s[i]))
+	}
+	return h.word(uint64(len(s)))
+}
+
+// book mixes in the price and size of the top depth levels of each side.
+func (h stateHash) book(b *domain.OrderBookSnapshot, depth int) stateHash {
+	for _, side := range [][]domain.PriceLevel{b.Bids, b.Asks} {
+		n := min(depth, len(side))
+		h = h.word(uint64(n))
+		for _, level := range side[:n] {
+			h = h.decimal(level.Price).decimal(level.Size)
+		}
+	}
+	return h
+}
+
+// decisionCache remembers the state in which each decision last found
+// nothing to trade, so a module can skip an update that leaves its inputs
+// unchanged: most book updates move levels no decision reads. A decision
+// that produced a signal is not remembered, so an opportunity still on the
+// books is signalled again, as a signal the risk manager turned away may be
+// taken next time.
+type decisionCache[K comparable] struct {
+	mu    sync.Mutex
+	quiet map[K]stateHash
+}
+
+func newDecisionCache[K comparable]() *decisionCache[K] {
+	return &decisionCache[K]{quiet: make(map[K]stateHash)}
+}
+
+// unchanged reports whether key last found nothing to trade in state.
+func (c *decisionCache[K]) unchanged(key K, state stateHash) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, ok := c.quiet[key]
+	return ok && prev == state
+}
+
+// record stores the outcome of evaluating key in state.
+func (c *decisionCache[K]) record(key K, state stateHash, signalled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if signalled {
+		delete(c.quiet, key)
+	} else {
+		c.quiet[key] = state
+	}
+}
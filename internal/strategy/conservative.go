@@ -0,0 +1,37 @@
+package strategy
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// ConservativeMode tightens signal generation while Active reports true,
+// which main wires to the risk manager's error budget: edge thresholds are
+// multiplied by EdgeMultiplier and signal sizes by SizeFactor. A nil
+// *ConservativeMode leaves thresholds and sizes unchanged.
+type ConservativeMode struct {
+	Active         func() bool
+	EdgeMultiplier float64
+	SizeFactor     float64
+}
+
+func (c *ConservativeMode) on() bool {
+	return c != nil && c.Active != nil && c.Active()
+}
+
+// minEdgeBps returns the edge threshold to apply in place of bps.
+func (c *ConservativeMode) minEdgeBps(bps int64) int64 {
+	if !c.on() || c.EdgeMultiplier <= 1 {
+		return bps
+	}
+	return int64(math.Ceil(float64(bps) * c.EdgeMultiplier))
+}
+
+// scaleSize returns the size to trade in place of size.
+func (c *ConservativeMode) scaleSize(size decimal.Decimal) decimal.Decimal {
+	if !c.on() || c.SizeFactor <= 0 || c.SizeFactor >= 1 {
+		return size
+	}
+	return size.Mul(decimal.NewFromFloat(c.SizeFactor))
+}
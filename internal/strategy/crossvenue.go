@@ -0,0 +1,223 @@
+package strategy
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/costmodel"
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/marketdata"
+)
+
+// CrossVenueArbModule trades a spot symbol quoted on several venues when one
+// venue's bid is above another's offer: it buys on the cheap venue and sells
+// on the rich one. The consolidated quote picks the venues, leaving out
+// those whose feeds are blocked, and the legs are priced from the venues'
+// books in the market data view.
+//
+// Each trade moves inventory from the buying venue to the selling one, so
+// besides both legs' costs the edge must pay for the transfers that
+// rebalance it, as amortized by the cost model.
+type CrossVenueArbModule struct {
+	mu sync.RWMutex
+
+	md        marketdata.View
+	costModel costmodel.CostModelService
+	bus       *eventbus.EventBus
+	logger    *slog.Logger
+
+	symbols         map[string]bool
+	minNetEdgeBps   int
+	maxNotionalUSDT decimal.Decimal
+	conservative    *ConservativeMode
+	dynamicEdge     *DynamicEdge
+	decisions       *decisionCache[string]
+}
+
+// NewCrossVenueArbModule creates a module trading symbols across the venues
+// they are quoted on. Signals are sized to the smaller touch, capped at
+// maxNotionalUSDT when it is positive.
+func NewCrossVenueArbModule(
+	symbols []string,
+	md marketdata.View,
+	costModel costmodel.CostModelService,
+	bus *eventbus.EventBus,
+	minNetEdgeBps int,
+	maxNotionalUSDT decimal.Decimal,
+	logger *slog.Logger,
+) *CrossVenueArbModule {
+	tracked := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		tracked[symbol] = true
+	}
+	return &CrossVenueArbModule{
+		md:              md,
+		costModel:       costModel,
+		bus:             bus,
+		logger:          logger,
+		symbols:         tracked,
+		minNetEdgeBps:   minNetEdgeBps,
+		maxNotionalUSDT: maxNotionalUSDT,
+		decisions:       newDecisionCache[string](),
+	}
+}
+
+// SetConservativeMode makes the module demand more net edge and trade
+// smaller while c is active.
+func (m *CrossVenueArbModule) SetConservativeMode(c *ConservativeMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conservative = c
+}
+
+// SetDynamicEdge makes the module demand more net edge on symbols whose
+// markets are volatile.
+func (m *CrossVenueArbModule) SetDynamicEdge(d *DynamicEdge) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dynamicEdge = d
+}
+
+// OnOrderBookUpdate does nothing: the module is driven by the consolidated
+// quotes built from the books.
+func (m *CrossVenueArbModule) OnOrderBookUpdate(domain.OrderBookSnapshot) {}
+
+func (m *CrossVenueArbModule) OnFundingRateUpdate(domain.FundingRate) {}
+
+// OnConsolidatedQuote re-evaluates quote's symbol between its best offer
+// and best bid venues.
+func (m *CrossVenueArbModule) OnConsolidatedQuote(quote domain.ConsolidatedQuote) {
+	if !m.symbols[quote.Symbol] || !quote.Crossed() || quote.AskVenue == quote.BidVenue {
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	buyVenue, sellVenue := quote.AskVenue, quote.BidVenue
+	buyBook, buyOK := m.md.GetBook(buyVenue, quote.Symbol)
+	sellBook, sellOK := m.md.GetBook(sellVenue, quote.Symbol)
+	if !buyOK || !sellOK {
+		return
+	}
+	extraBps := max(m.dynamicEdge.extraBps(buyVenue, quote.Symbol), m.dynamicEdge.extraBps(sellVenue, quote.Symbol))
+	state := newStateHash().str(buyVenue).str(sellVenue).flag(m.conservative.on()).word(uint64(extraBps)).
+		book(buyBook, decisionDepth).book(sellBook, decisionDepth)
+	if m.decisions.unchanged(quote.Symbol, state) {
+		return
+	}
+	m.decisions.record(quote.Symbol, state, m.evaluate(quote.Symbol, buyVenue, sellVenue, buyBook, sellBook, extraBps, quote.Timestamp))
+}
+
+// evaluate publishes a signal buying symbol on buyVenue and selling it on
+// sellVenue if the books offer enough net edge, with extraBps added to the
+// threshold, and reports whether it did.
+func (m *CrossVenueArbModule) evaluate(symbol, buyVenue, sellVenue string, buyBook, sellBook *domain.OrderBookSnapshot, extraBps int64, mdTimestamp time.Time) bool {
+	// The books may have moved since the quote was built; they are what
+	// the legs are priced at.
+	ask, askOK := buyBook.BestAsk()
+	bid, bidOK := sellBook.BestBid()
+	if !askOK || !bidOK || !ask.Price.IsPositive() || !bid.Price.GreaterThan(ask.Price) {
+		return false
+	}
+
+	size := decimal.Min(ask.Size, bid.Size)
+	if m.maxNotionalUSDT.IsPositive() {
+		size = decimal.Min(size, m.maxNotionalUSDT.Div(ask.Price))
+	}
+	size = m.conservative.scaleSize(size)
+	if !size.IsPositive() {
+		return false
+	}
+
+	buyCost, err := m.costModel.EstimateCost(buyVenue, symbol, domain.SideBuy, size, domain.OrderTypeLimit)
+	if err != nil {
+		return false
+	}
+	sellCost, err := m.costModel.EstimateCost(sellVenue, symbol, domain.SideSell, size, domain.OrderTypeLimit)
+	if err != nil {
+		return false
+	}
+	transferBps := transferCostBps(m.costModel, domain.ExtractAsset(symbol), buyVenue, sellVenue)
+
+	costEst := domain.CostEstimate{
+		FeeBps:      buyCost.FeeBps.Add(sellCost.FeeBps),
+		SlippageBps: buyCost.SlippageBps.Add(sellCost.SlippageBps),
+		TotalBps:    buyCost.TotalBps.Add(sellCost.TotalBps).Add(transferBps),
+		Confidence:  decimal.Min(buyCost.Confidence, sellCost.Confidence),
+	}
+	grossEdgeBps := bid.Price.Sub(ask.Price).Div(ask.Price).Mul(decimal.NewFromInt(10000))
+	netEdgeBps := grossEdgeBps.Sub(costEst.TotalBps)
+	minEdge := decimal.NewFromInt(m.conservative.minEdgeBps(int64(m.minNetEdgeBps)) + extraBps)
+	if netEdgeBps.LessThan(minEdge) {
+		return false
+	}
+
+	signalID, uuidErr := uuid.NewV7()
+	if uuidErr != nil {
+		signalID = uuid.New()
+	}
+
+	// The signal belongs to the buying venue; the sell leg names its own.
+	legs := []domain.LegSpec{
+		{
+			Symbol:         symbol,
+			Side:           domain.SideBuy,
+			InstrumentType: domain.InstrumentSpot,
+			Price:          ask.Price,
+			Size:           size,
+			OrderType:      domain.OrderTypeLimit,
+		},
+		{
+			Venue:          sellVenue,
+			Symbol:         symbol,
+			Side:           domain.SideSell,
+			InstrumentType: domain.InstrumentSpot,
+			Price:          bid.Price,
+			Size:           size,
+			OrderType:      domain.OrderTypeLimit,
+		},
+	}
+	atomicity := estimateAtomicity(m.costModel, buyVenue, legs, []*domain.OrderBookSnapshot{buyBook, sellBook})
+
+	signal := domain.TradeSignal{
+		SignalID:            signalID,
+		Strategy:            domain.StrategyCrossVenueArb,
+		Venue:               buyVenue,
+		Legs:                legs,
+		ExpectedEdgeBps:     netEdgeBps,
+		CostEstimate:        costEst,
+		Confidence:          costEst.Confidence.Mul(atomicity),
+		Atomicity:           atomicity,
+		CreatedAt:           time.Now(),
+		MarketDataTimestamp: mdTimestamp,
+	}
+
+	m.bus.PublishSignal(signal)
+	m.logger.Info("cross-venue-arb signal detected",
+		"symbol", symbol,
+		"buy_venue", buyVenue,
+		"sell_venue", sellVenue,
+		"gross_edge_bps", grossEdgeBps.String(),
+		"transfer_bps", transferBps.String(),
+		"net_edge_bps", netEdgeBps.String(),
+		"signal_id", signal.SignalID.String(),
+	)
+	return true
+}
+
+// transferCostBps asks the cost model what moving asset from fromVenue to
+// toVenue adds to a trade. A cost model that cannot estimate it adds
+// nothing.
+func transferCostBps(cm costmodel.CostModelService, asset, fromVenue, toVenue string) decimal.Decimal {
+	est, ok := cm.(costmodel.TransferCostEstimator)
+	if !ok {
+		return decimal.Zero
+	}
+	return est.TransferCostBps(asset, fromVenue, toVenue)
+}
@@ -0,0 +1,135 @@
+package strategy
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+)
+
+// transferCost adds a flat transfer cost to flatCost.
+type transferCost struct {
+	flatCost
+	bps int64
+}
+
+func (c *transferCost) TransferCostBps(asset, fromVenue, toVenue string) decimal.Decimal {
+	return decimal.NewFromInt(c.bps)
+}
+
+func TestCrossVenueArbBuysCheapSellsRich(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	// nobitex bids 30 bps over kcex's offer.
+	view := venueView{
+		"kcex:BTC/USDT":    book("BTC/USDT", 99990, 1, 100000, 1),
+		"nobitex:BTC/USDT": book("BTC/USDT", 100300, 0.5, 100310, 0.5),
+	}
+	quote := domain.ConsolidatedQuote{
+		Symbol:   "BTC/USDT",
+		BestBid:  view["nobitex:BTC/USDT"].Bids[0],
+		BidVenue: "nobitex",
+		BestAsk:  view["kcex:BTC/USDT"].Asks[0],
+		AskVenue: "kcex",
+	}
+
+	// Two legs at 10 bps each leave 10 bps.
+	cost := &transferCost{}
+	mod := NewCrossVenueArbModule([]string{"BTC/USDT"}, view, cost, bus, 5, decimal.Zero, logger)
+	mod.OnConsolidatedQuote(quote)
+
+	var signal domain.TradeSignal
+	select {
+	case signal = <-signals:
+	case <-time.After(time.Second):
+		t.Fatal("expected a cross-venue signal")
+	}
+	if signal.Strategy != domain.StrategyCrossVenueArb || signal.LegVenue(0) != "kcex" || signal.LegVenue(1) != "nobitex" {
+		t.Fatalf("expected to buy on kcex and sell on nobitex, got %s and %s", signal.LegVenue(0), signal.LegVenue(1))
+	}
+	if signal.Legs[0].Side != domain.SideBuy || signal.Legs[1].Side != domain.SideSell {
+		t.Errorf("expected buy then sell, got %s then %s", signal.Legs[0].Side, signal.Legs[1].Side)
+	}
+	if !signal.Legs[0].Size.Equal(decimal.NewFromFloat(0.5)) || !signal.ExpectedEdgeBps.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected 0.5 at 10 bps net, got %s at %s", signal.Legs[0].Size, signal.ExpectedEdgeBps)
+	}
+
+	// Amortizing the transfers that rebalance inventory rules it out.
+	cost.bps = 8
+	mod = NewCrossVenueArbModule([]string{"BTC/USDT"}, view, cost, bus, 5, decimal.Zero, logger)
+	mod.OnConsolidatedQuote(quote)
+	select {
+	case signal := <-signals:
+		t.Fatalf("expected no signal after transfer costs, got %+v", signal)
+	default:
+	}
+}
+
+func TestCrossVenueArbCapsNotional(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	view := venueView{
+		"kcex:BTC/USDT":    book("BTC/USDT", 99990, 1, 100000, 1),
+		"nobitex:BTC/USDT": book("BTC/USDT", 100300, 1, 100310, 1),
+	}
+	mod := NewCrossVenueArbModule([]string{"BTC/USDT"}, view, &flatCost{}, bus, 5, decimal.NewFromInt(10000), logger)
+	mod.OnConsolidatedQuote(domain.ConsolidatedQuote{
+		Symbol:   "BTC/USDT",
+		BestBid:  view["nobitex:BTC/USDT"].Bids[0],
+		BidVenue: "nobitex",
+		BestAsk:  view["kcex:BTC/USDT"].Asks[0],
+		AskVenue: "kcex",
+	})
+
+	select {
+	case signal := <-signals:
+		if !signal.Legs[0].Size.Equal(decimal.NewFromFloat(0.1)) {
+			t.Errorf("expected size capped to 0.1, got %s", signal.Legs[0].Size)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a cross-venue signal")
+	}
+}
+
+func TestCrossVenueArbIgnoresUncrossedQuotes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	view := venueView{
+		"kcex:BTC/USDT":    book("BTC/USDT", 99990, 1, 100000, 1),
+		"nobitex:BTC/USDT": book("BTC/USDT", 99995, 1, 100005, 1),
+	}
+	mod := NewCrossVenueArbModule([]string{"BTC/USDT", "ETH/USDT"}, view, &flatCost{}, bus, 5, decimal.Zero, logger)
+
+	for _, quote := range []domain.ConsolidatedQuote{
+		// The best bid is under the best offer.
+		{Symbol: "BTC/USDT", BestBid: view["nobitex:BTC/USDT"].Bids[0], BidVenue: "nobitex",
+			BestAsk: view["kcex:BTC/USDT"].Asks[0], AskVenue: "kcex"},
+		// A venue crossed with itself is a bad book, not an opportunity.
+		{Symbol: "BTC/USDT", BestBid: domain.PriceLevel{Price: decimal.NewFromInt(100100)}, BidVenue: "kcex",
+			BestAsk: view["kcex:BTC/USDT"].Asks[0], AskVenue: "kcex"},
+		// The quote is crossed but the books it was built from have moved.
+		{Symbol: "BTC/USDT", BestBid: domain.PriceLevel{Price: decimal.NewFromInt(100500)}, BidVenue: "nobitex",
+			BestAsk: view["kcex:BTC/USDT"].Asks[0], AskVenue: "kcex"},
+		// No books at all.
+		{Symbol: "ETH/USDT", BestBid: domain.PriceLevel{Price: decimal.NewFromInt(3010)}, BidVenue: "nobitex",
+			BestAsk: domain.PriceLevel{Price: decimal.NewFromInt(3000)}, AskVenue: "kcex"},
+	} {
+		mod.OnConsolidatedQuote(quote)
+	}
+	select {
+	case signal := <-signals:
+		t.Fatalf("expected no signal, got %+v", signal)
+	default:
+	}
+}
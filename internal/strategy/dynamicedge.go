@@ -0,0 +1,32 @@
+package strategy
+
+import "math"
+
+// DynamicEdge raises edge thresholds with the volatility of the symbols
+// traded, since the more the market moves the more a signal's edge can be
+// gone by the time its legs fill. A leg adds BpsPerVolPct bps for every
+// percentage point of its symbol's annualized volatility, up to MaxExtraBps
+// (0 is uncapped); a signal needs the edge of its most volatile leg. Legs
+// without an estimate add nothing. A nil *DynamicEdge adds nothing.
+type DynamicEdge struct {
+	Volatility   func(venue, symbol string) (float64, bool)
+	BpsPerVolPct float64
+	MaxExtraBps  int64
+}
+
+// extraBps returns the edge to add to the threshold of a leg on venue's
+// symbol.
+func (d *DynamicEdge) extraBps(venue, symbol string) int64 {
+	if d == nil || d.Volatility == nil || d.BpsPerVolPct <= 0 {
+		return 0
+	}
+	vol, ok := d.Volatility(venue, symbol)
+	if !ok {
+		return 0
+	}
+	extra := int64(math.Ceil(vol * 100 * d.BpsPerVolPct))
+	if d.MaxExtraBps > 0 && extra > d.MaxExtraBps {
+		return d.MaxExtraBps
+	}
+	return extra
+}
@@ -13,6 +13,13 @@ type Module interface {
 	OnFundingRateUpdate(rate domain.FundingRate)
 }
 
+// QuoteModule is a Module that also trades off consolidated quotes, the
+// touch of a symbol across venues.
+type QuoteModule interface {
+	Module
+	OnConsolidatedQuote(quote domain.ConsolidatedQuote)
+}
+
 type Engine struct {
 	modules []Module
 	bus     *eventbus.EventBus
@@ -34,6 +41,19 @@ func (e *Engine) Run(ctx context.Context) {
 	obCh := e.bus.SubscribeOrderBook()
 	frCh := e.bus.SubscribeFundingRate()
 
+	// Quotes are only subscribed to when a module reads them; a nil
+	// channel leaves that case of the select idle.
+	var quoteModules []QuoteModule
+	for _, m := range e.modules {
+		if qm, ok := m.(QuoteModule); ok {
+			quoteModules = append(quoteModules, qm)
+		}
+	}
+	var quoteCh <-chan domain.ConsolidatedQuote
+	if len(quoteModules) > 0 {
+		quoteCh = e.bus.SubscribeConsolidatedQuote()
+	}
+
 	e.logger.Info("strategy engine started", "modules", len(e.modules))
 
 	for {
@@ -57,6 +77,14 @@ func (e *Engine) Run(ctx context.Context) {
 			for _, m := range e.modules {
 				m.OnFundingRateUpdate(rate)
 			}
+
+		case quote, ok := <-quoteCh:
+			if !ok {
+				return
+			}
+			for _, m := range quoteModules {
+				m.OnConsolidatedQuote(quote)
+			}
 		}
 	}
 }
@@ -82,6 +82,44 @@ func TestEngineDispatchesFundingRateToModules(t *testing.T) {
 	}
 }
 
+type testQuoteModule struct {
+	testModule
+	quoteCount atomic.Int32
+}
+
+func (m *testQuoteModule) OnConsolidatedQuote(_ domain.ConsolidatedQuote) {
+	m.quoteCount.Add(1)
+}
+
+func TestEngineDispatchesQuotesToQuoteModules(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(64, logger)
+
+	engine := NewEngine(bus, logger)
+	plain := &testModule{}
+	quoted := &testQuoteModule{}
+	engine.RegisterModule(plain)
+	engine.RegisterModule(quoted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Run(ctx)
+
+	time.Sleep(20 * time.Millisecond)
+
+	bus.PublishConsolidatedQuote(domain.ConsolidatedQuote{Symbol: "BTC/USDT"})
+	bus.PublishOrderBook(domain.OrderBookSnapshot{Venue: "test", Symbol: "BTC/USDT"})
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if quoted.quoteCount.Load() != 1 {
+		t.Errorf("expected 1 consolidated quote, got %d", quoted.quoteCount.Load())
+	}
+	if plain.obCount.Load() != 1 || quoted.obCount.Load() != 1 {
+		t.Errorf("expected both modules to keep receiving books, got %d and %d", plain.obCount.Load(), quoted.obCount.Load())
+	}
+}
+
 func TestEngineStopsOnContextCancel(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
 	bus := eventbus.New(64, logger)
@@ -0,0 +1,205 @@
+package external
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Config describes an external strategy process and its sandbox.
+type Config struct {
+	Name string
+	Addr string // host:port, or unix:///path for a Unix socket
+	TLS  bool
+	// QueueSize is how many market events are buffered for the process.
+	// While it lags further behind, new events are dropped rather than
+	// holding up the strategy engine. Zero means defaultQueueSize.
+	QueueSize int
+	Sandbox   Sandbox
+}
+
+const defaultQueueSize = 256
+
+// Module is a strategy.Module that forwards market data in its sandbox to
+// an external process and publishes the signals it proposes that pass the
+// sandbox. Run keeps the stream to the process open.
+type Module struct {
+	cfg     Config
+	conn    *grpc.ClientConn
+	sandbox *sandbox
+	publish func(domain.TradeSignal)
+	logger  *slog.Logger
+
+	events  chan MarketEvent
+	dropped atomic.Int64 // events dropped since the last warning
+}
+
+// New creates the module for the process cfg describes; publish receives
+// its accepted signals. The process is not contacted until Run.
+func New(cfg Config, publish func(domain.TradeSignal), logger *slog.Logger) (*Module, error) {
+	switch {
+	case cfg.Name == "":
+		return nil, errors.New("external strategy: name is required")
+	case cfg.Addr == "":
+		return nil, errors.New("external strategy: address is required")
+	case len(cfg.Sandbox.Venues) == 0:
+		return nil, errors.New("external strategy: sandbox needs at least one venue")
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(cfg.Addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("external strategy %s: %w", cfg.Name, err)
+	}
+	return &Module{
+		cfg:     cfg,
+		conn:    conn,
+		sandbox: newSandbox(cfg.Sandbox),
+		publish: publish,
+		logger:  logger.With("external_strategy", cfg.Name),
+		events:  make(chan MarketEvent, cfg.QueueSize),
+	}, nil
+}
+
+func (m *Module) OnOrderBookUpdate(snap domain.OrderBookSnapshot) {
+	if m.sandbox.allows(snap.Venue, snap.Symbol) {
+		m.enqueue(MarketEvent{OrderBook: &snap})
+	}
+}
+
+func (m *Module) OnFundingRateUpdate(rate domain.FundingRate) {
+	if m.sandbox.allows(rate.Venue, rate.Symbol) {
+		m.enqueue(MarketEvent{Funding: &rate})
+	}
+}
+
+// enqueue hands ev to the stream without blocking the strategy engine.
+func (m *Module) enqueue(ev MarketEvent) {
+	select {
+	case m.events <- ev:
+	default:
+		if m.dropped.Add(1) == 1 {
+			m.logger.Warn("external strategy lagging, dropping market data")
+		}
+	}
+}
+
+// Run streams market events to the process and handles its proposals until
+// ctx is cancelled, reopening the stream with backoff whenever it breaks.
+func (m *Module) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		opened, err := m.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if opened {
+			backoff = time.Second
+		}
+		m.logger.Warn("external strategy stream broke, reopening", "error", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// Close drops the connection to the process; the process keeps running.
+func (m *Module) Close() error {
+	return m.conn.Close()
+}
+
+// session runs one stream until it fails. opened reports whether the process
+// accepted the stream at all.
+func (m *Module) session(ctx context.Context) (opened bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	desc := runStream
+	cs, err := m.conn.NewStream(ctx, &desc, fullMethod(methodRun))
+	if err != nil {
+		return false, err
+	}
+	// The process sends the header once it is ready for events.
+	if _, err := cs.Header(); err != nil {
+		return false, err
+	}
+	m.logger.Info("external strategy connected")
+
+	sendErr := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				sendErr <- ctx.Err()
+				return
+			case ev := <-m.events:
+				if err := cs.SendMsg(&ev); err != nil {
+					sendErr <- err
+					return
+				}
+				if n := m.dropped.Swap(0); n > 0 {
+					m.logger.Warn("external strategy caught up", "dropped_events", n)
+				}
+			}
+		}
+	}()
+
+	for {
+		var p Proposal
+		if err := cs.RecvMsg(&p); err != nil {
+			cancel()
+			<-sendErr
+			return true, err
+		}
+		m.propose(p)
+	}
+}
+
+// propose publishes p as a signal if it passes the sandbox.
+func (m *Module) propose(p Proposal) {
+	if err := m.sandbox.check(p, time.Now()); err != nil {
+		m.logger.Warn("external strategy proposal rejected", "venue", p.Venue, "reason", err)
+		return
+	}
+	signal := domain.TradeSignal{
+		SignalID:            uuid.New(),
+		Strategy:            p.Strategy,
+		Venue:               p.Venue,
+		Legs:                p.Legs,
+		ExpectedEdgeBps:     p.ExpectedEdgeBps,
+		Confidence:          p.Confidence,
+		Atomicity:           decimal.NewFromInt(1),
+		CreatedAt:           time.Now(),
+		MarketDataTimestamp: p.MarketDataTimestamp,
+	}
+	m.logger.Info("external strategy signal accepted",
+		"signal_id", signal.SignalID,
+		"strategy", signal.Strategy,
+		"venue", signal.Venue,
+		"legs", len(signal.Legs),
+	)
+	m.publish(signal)
+}
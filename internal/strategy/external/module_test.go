@@ -0,0 +1,150 @@
+package external
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// echoStrategy proposes buying each book it sees at the best ask, and
+// records the symbols it was sent.
+type echoStrategy struct {
+	mu   sync.Mutex
+	seen []string
+}
+
+func (s *echoStrategy) OnEvent(ev MarketEvent) []Proposal {
+	if ev.OrderBook == nil {
+		return nil
+	}
+	s.mu.Lock()
+	s.seen = append(s.seen, ev.OrderBook.Symbol)
+	s.mu.Unlock()
+	return []Proposal{{
+		Strategy: domain.StrategyTriArb,
+		Venue:    ev.OrderBook.Venue,
+		Legs: []domain.LegSpec{{
+			Symbol:         ev.OrderBook.Symbol,
+			Side:           domain.SideBuy,
+			InstrumentType: domain.InstrumentSpot,
+			OrderType:      domain.OrderTypeLimit,
+			Price:          ev.OrderBook.Asks[0].Price,
+			Size:           decimal.NewFromInt(1),
+		}},
+		ExpectedEdgeBps:     decimal.NewFromInt(20),
+		MarketDataTimestamp: ev.OrderBook.LocalTimestamp,
+	}}
+}
+
+func TestModuleForwardsSandboxedSignals(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	strat := &echoStrategy{}
+	srv := grpc.NewServer()
+	Register(srv, strat)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	signals := make(chan domain.TradeSignal, 4)
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	m, err := New(Config{
+		Name:      "proto",
+		Addr:      ln.Addr().String(),
+		QueueSize: 8,
+		Sandbox: Sandbox{
+			Venues:        []string{"kcex"},
+			Symbols:       []string{"BTC/USDT", "ETH/USDT"},
+			MaxNotional:   decimal.NewFromInt(60000),
+			SignalTimeout: time.Minute,
+		},
+	}, func(s domain.TradeSignal) { signals <- s }, logger)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	book := func(venue, symbol string, ask int64) domain.OrderBookSnapshot {
+		return domain.OrderBookSnapshot{
+			Venue:          venue,
+			Symbol:         symbol,
+			Asks:           []domain.PriceLevel{{Price: decimal.NewFromInt(ask), Size: decimal.NewFromInt(1)}},
+			LocalTimestamp: time.Now(),
+		}
+	}
+	m.OnOrderBookUpdate(book("bybit", "BTC/USDT", 50000)) // venue outside the sandbox
+	m.OnOrderBookUpdate(book("kcex", "SOL/USDT", 150))    // symbol outside the sandbox
+	m.OnOrderBookUpdate(book("kcex", "BTC/USDT", 70000))  // over the notional cap
+	m.OnOrderBookUpdate(book("kcex", "ETH/USDT", 3000))
+
+	select {
+	case s := <-signals:
+		if s.Venue != "kcex" || s.Legs[0].Symbol != "ETH/USDT" || s.Strategy != domain.StrategyTriArb {
+			t.Errorf("expected the ETH/USDT tri-arb signal on kcex, got %+v", s)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no signal from the external strategy")
+	}
+	select {
+	case s := <-signals:
+		t.Errorf("expected only one signal through the sandbox, also got %+v", s)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	strat.mu.Lock()
+	defer strat.mu.Unlock()
+	if len(strat.seen) != 2 {
+		t.Errorf("expected only the two sandboxed books to be sent, got %v", strat.seen)
+	}
+}
+
+func TestSandboxLimits(t *testing.T) {
+	sb := newSandbox(Sandbox{
+		Venues:              []string{"kcex"},
+		MaxLegs:             1,
+		MaxSignalsPerMinute: 1,
+		SignalTimeout:       time.Second,
+	})
+	now := time.Now()
+	leg := domain.LegSpec{Symbol: "BTC/USDT", Side: domain.SideSell, InstrumentType: domain.InstrumentSpot,
+		OrderType: domain.OrderTypeMarket, Size: decimal.NewFromInt(1)}
+	p := Proposal{Strategy: domain.StrategyBasisArb, Venue: "kcex", Legs: []domain.LegSpec{leg}, MarketDataTimestamp: now}
+
+	stale := p
+	stale.MarketDataTimestamp = now.Add(-2 * time.Second)
+	if err := sb.check(stale, now); err == nil {
+		t.Error("expected a proposal on stale data to be rejected")
+	}
+	wide := p
+	wide.Legs = []domain.LegSpec{leg, leg}
+	if err := sb.check(wide, now); err == nil {
+		t.Error("expected a proposal over the leg limit to be rejected")
+	}
+	if err := sb.check(p, now); err != nil {
+		t.Fatalf("expected the proposal to pass, got %v", err)
+	}
+	if err := sb.check(p, now.Add(30*time.Second)); err == nil {
+		t.Error("expected a second proposal within the minute to be rejected")
+	}
+	if err := sb.check(p, now.Add(61*time.Second)); err == nil {
+		t.Error("expected the proposal to be stale after a minute")
+	}
+	p.MarketDataTimestamp = now.Add(61 * time.Second)
+	if err := sb.check(p, now.Add(61*time.Second)); err != nil {
+		t.Errorf("expected the rate limit to reset after a minute, got %v", err)
+	}
+}
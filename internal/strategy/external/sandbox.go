@@ -0,0 +1,125 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// Sandbox bounds what an external strategy sees and may propose. It only
+// receives market data for its venues and symbols, and its proposals must
+// stay within them and within the limits below. A zero limit is not
+// enforced.
+type Sandbox struct {
+	Venues  []string
+	Symbols []string // empty allows every symbol on Venues
+	// MaxLegs caps the legs of one proposal.
+	MaxLegs int
+	// MaxNotional caps the summed price times size of a proposal's legs.
+	// Every leg then needs a price, market legs included.
+	MaxNotional decimal.Decimal
+	// MaxSignalsPerMinute caps the proposals accepted in any minute.
+	MaxSignalsPerMinute int
+	// SignalTimeout drops proposals built on market data older than this.
+	SignalTimeout time.Duration
+}
+
+// sandbox enforces a Sandbox for one strategy.
+type sandbox struct {
+	cfg     Sandbox
+	venues  map[string]bool
+	symbols map[string]bool
+
+	mu       sync.Mutex
+	accepted []time.Time // accept times within the last minute, oldest first
+}
+
+func newSandbox(cfg Sandbox) *sandbox {
+	s := &sandbox{cfg: cfg, venues: make(map[string]bool), symbols: make(map[string]bool)}
+	for _, v := range cfg.Venues {
+		s.venues[v] = true
+	}
+	for _, sym := range cfg.Symbols {
+		s.symbols[sym] = true
+	}
+	return s
+}
+
+// allows reports whether the strategy may see and trade symbol on venue.
+func (s *sandbox) allows(venue, symbol string) bool {
+	return s.venues[venue] && (len(s.symbols) == 0 || s.symbols[symbol])
+}
+
+// check returns why p may not become a signal at now, or nil. A proposal
+// that passes counts towards the rate limit.
+func (s *sandbox) check(p Proposal, now time.Time) error {
+	switch p.Strategy {
+	case domain.StrategyTriArb, domain.StrategyBasisArb:
+	default:
+		return fmt.Errorf("strategy must be %s or %s", domain.StrategyTriArb, domain.StrategyBasisArb)
+	}
+	if !s.venues[p.Venue] {
+		return fmt.Errorf("venue %s is outside the sandbox", p.Venue)
+	}
+	if len(p.Legs) == 0 {
+		return errors.New("at least one leg is required")
+	}
+	if s.cfg.MaxLegs > 0 && len(p.Legs) > s.cfg.MaxLegs {
+		return fmt.Errorf("%d legs, limit %d", len(p.Legs), s.cfg.MaxLegs)
+	}
+	if s.cfg.SignalTimeout > 0 {
+		if p.MarketDataTimestamp.IsZero() {
+			return errors.New("market data timestamp is required")
+		}
+		if age := now.Sub(p.MarketDataTimestamp); age > s.cfg.SignalTimeout {
+			return fmt.Errorf("built on market data %s old, limit %s", age.Round(time.Millisecond), s.cfg.SignalTimeout)
+		}
+	}
+
+	notional := decimal.Zero
+	for _, leg := range p.Legs {
+		if !s.allows(p.Venue, leg.Symbol) {
+			return fmt.Errorf("symbol %q is outside the sandbox", leg.Symbol)
+		}
+		if leg.Side != domain.SideBuy && leg.Side != domain.SideSell {
+			return errors.New("leg side must be BUY or SELL")
+		}
+		if leg.InstrumentType != domain.InstrumentSpot && leg.InstrumentType != domain.InstrumentPerp {
+			return errors.New("leg instrument type must be SPOT or PERP")
+		}
+		switch leg.OrderType {
+		case domain.OrderTypeLimit, domain.OrderTypeMarket:
+		default:
+			return errors.New("leg order type must be LIMIT or MARKET")
+		}
+		if !leg.Size.IsPositive() {
+			return errors.New("leg size must be positive")
+		}
+		if (leg.OrderType == domain.OrderTypeLimit || s.cfg.MaxNotional.IsPositive()) && !leg.Price.IsPositive() {
+			return errors.New("leg price must be positive")
+		}
+		notional = notional.Add(leg.Price.Mul(leg.Size))
+	}
+	if s.cfg.MaxNotional.IsPositive() && notional.GreaterThan(s.cfg.MaxNotional) {
+		return fmt.Errorf("notional %s, limit %s", notional.StringFixed(2), s.cfg.MaxNotional)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.MaxSignalsPerMinute > 0 {
+		cutoff := now.Add(-time.Minute)
+		for len(s.accepted) > 0 && !s.accepted[0].After(cutoff) {
+			s.accepted = s.accepted[1:]
+		}
+		if len(s.accepted) >= s.cfg.MaxSignalsPerMinute {
+			return fmt.Errorf("more than %d signals a minute", s.cfg.MaxSignalsPerMinute)
+		}
+		s.accepted = append(s.accepted, now)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package external
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Strategy is an external strategy written in Go. OnEvent is called with
+// each market event in the order the trader sent them and returns the
+// signals it proposes, if any.
+type Strategy interface {
+	OnEvent(ev MarketEvent) []Proposal
+}
+
+// Register serves strat on s as an external strategy process, for
+// strategies written in Go. Processes in other languages serve the same
+// service with a JSON codec.
+func Register(s grpc.ServiceRegistrar, strat Strategy) {
+	desc := runStream
+	desc.Handler = func(_ any, ss grpc.ServerStream) error {
+		if err := ss.SendHeader(metadata.MD{}); err != nil {
+			return err
+		}
+		for {
+			var ev MarketEvent
+			if err := ss.RecvMsg(&ev); err != nil {
+				return err
+			}
+			for _, p := range strat.OnEvent(ev) {
+				if err := ss.SendMsg(&p); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*any)(nil),
+		Streams:     []grpc.StreamDesc{desc},
+	}, strat)
+}
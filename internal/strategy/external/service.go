@@ -0,0 +1,74 @@
+// Package external runs strategies in other processes, such as Python
+// research prototypes. The trader streams market data to the process over
+// the gRPC service described here and the process streams back the signals
+// it proposes. Proposals only become signals after they pass the strategy's
+// sandbox; the trader's risk manager and execution engine then handle them
+// like any other signal, so the process never places an order itself.
+//
+// The service has a single bidirectional streaming method, Run. The trader
+// sends MarketEvent messages and receives Proposal messages. Messages are
+// JSON-encoded under the "json" content subtype (application/grpc+json),
+// as for gateway plugins: field names are the Go field names, decimals are
+// strings and times are RFC 3339. The process may listen on TCP or on a
+// Unix socket (addr "unix:///path/to.sock").
+package external
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/crypto-trading/trading/internal/domain"
+)
+
+// ServiceName is the fully qualified gRPC service a strategy process must
+// serve.
+const ServiceName = "trading.strategy.v1.Strategy"
+
+// methodRun is the bidirectional stream: market events in, proposals out.
+const methodRun = "Run"
+
+func fullMethod(method string) string { return "/" + ServiceName + "/" + method }
+
+// codecName is the content subtype both sides use.
+const codecName = "json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return codecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// MarketEvent is one market data update for the strategy. Exactly one of
+// OrderBook and Funding is set.
+type MarketEvent struct {
+	OrderBook *domain.OrderBookSnapshot `json:",omitempty"`
+	Funding   *domain.FundingRate       `json:",omitempty"`
+}
+
+// Proposal is a signal proposed by a strategy process. MarketDataTimestamp
+// is the timestamp of the event it was computed from (the book's
+// LocalTimestamp or the funding rate's Timestamp); the sandbox drops
+// proposals built on data older than its signal timeout.
+type Proposal struct {
+	Strategy            domain.StrategyType
+	Venue               string
+	Legs                []domain.LegSpec
+	ExpectedEdgeBps     decimal.Decimal
+	Confidence          decimal.Decimal
+	MarketDataTimestamp time.Time
+}
+
+// runStream describes Run to gRPC on both sides.
+var runStream = grpc.StreamDesc{
+	StreamName:    methodRun,
+	ServerStreams: true,
+	ClientStreams: true,
+}
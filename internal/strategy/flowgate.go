@@ -0,0 +1,46 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/marketdata"
+)
+
+// AdverseFlowGate holds tri-arb signals back while the flow on one of their
+// legs' books runs against the leg. A buy leg is exposed to selling, since
+// the later legs have to sell what it bought, so it is held when the book
+// leans to the asks by more than MaxImbalance or taker selling exceeds
+// MaxPressure; a sell leg is held on the mirror image. Zero disables a
+// threshold.
+type AdverseFlowGate struct {
+	Stats        func(venue, symbol string) (marketdata.MicroStats, bool)
+	MaxImbalance float64
+	MaxPressure  float64
+}
+
+// adverse returns why the first leg of path facing adverse flow does, or ""
+// if none does. Legs without statistics are let through.
+func (g *AdverseFlowGate) adverse(venue string, path TriangularPath) string {
+	if g == nil || g.Stats == nil {
+		return ""
+	}
+	for _, leg := range path.Legs {
+		stats, ok := g.Stats(venue, leg.Symbol)
+		if !ok {
+			continue
+		}
+		// Against a buy, flow is negative; flip it for a sell.
+		imbalance, pressure := stats.Imbalance, stats.Pressure
+		if leg.Side == domain.SideSell {
+			imbalance, pressure = -imbalance, -pressure
+		}
+		if g.MaxImbalance > 0 && -imbalance > g.MaxImbalance {
+			return fmt.Sprintf("%s %s: book imbalance %.2f", leg.Side, leg.Symbol, stats.Imbalance)
+		}
+		if g.MaxPressure > 0 && -pressure > g.MaxPressure {
+			return fmt.Sprintf("%s %s: trade pressure %.2f over %d trades", leg.Side, leg.Symbol, stats.Pressure, stats.Trades)
+		}
+	}
+	return ""
+}
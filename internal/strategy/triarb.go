@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"fmt"
 	"log/slog"
 	"sync"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/crypto-trading/trading/internal/costmodel"
 	"github.com/crypto-trading/trading/internal/domain"
 	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/marketdata"
 )
 
 type TriangularPath struct {
@@ -21,24 +23,33 @@ type TriangularPath struct {
 type TriangularLeg struct {
 	Symbol string
 	Side   domain.Side
+	// FX marks the implicit conversion between USDT and a venue's fiat
+	// currency on a path through fiat-quoted books. It is costed on its own
+	// instead of being assumed to cost what the first leg does.
+	FX bool
 }
 
 type TriArbModule struct {
 	mu sync.RWMutex
 
 	paths     []TriangularPath
-	books     map[string]*domain.OrderBookSnapshot
+	md        marketdata.View
 	costModel costmodel.CostModelService
 	bus       *eventbus.EventBus
 	logger    *slog.Logger
 
-	minEdgeBps int64
-	venue      string
+	minEdgeBps   int64
+	venue        string
+	conservative *ConservativeMode
+	flowGate     *AdverseFlowGate
+	dynamicEdge  *DynamicEdge
+	decisions    *decisionCache[TriangularPath]
 }
 
 func NewTriArbModule(
 	venue string,
 	paths []TriangularPath,
+	md marketdata.View,
 	costModel costmodel.CostModelService,
 	bus *eventbus.EventBus,
 	minEdgeBps int,
@@ -46,24 +57,64 @@ func NewTriArbModule(
 ) *TriArbModule {
 	return &TriArbModule{
 		paths:      paths,
-		books:      make(map[string]*domain.OrderBookSnapshot),
+		md:         md,
 		costModel:  costModel,
 		bus:        bus,
 		logger:     logger,
 		minEdgeBps: int64(minEdgeBps),
 		venue:      venue,
+		decisions:  newDecisionCache[TriangularPath](),
 	}
 }
 
+// SetConservativeMode makes the module demand more edge and trade smaller
+// while c is active.
+func (m *TriArbModule) SetConservativeMode(c *ConservativeMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conservative = c
+}
+
+// SetAdverseFlowGate makes the module hold back signals while g finds flow
+// running against one of their legs.
+func (m *TriArbModule) SetAdverseFlowGate(g *AdverseFlowGate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flowGate = g
+}
+
+// SetDynamicEdge makes the module demand more edge on paths through
+// volatile symbols.
+func (m *TriArbModule) SetDynamicEdge(d *DynamicEdge) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dynamicEdge = d
+}
+
+// RemoveSymbol drops every path with a leg in symbol, for a symbol the
+// venue no longer trades, and returns how many were removed.
+func (m *TriArbModule) RemoveSymbol(symbol string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var kept []TriangularPath
+	for _, path := range m.paths {
+		if !m.pathInvolves(path, symbol) {
+			kept = append(kept, path)
+		}
+	}
+	removed := len(m.paths) - len(kept)
+	m.paths = kept
+	return removed
+}
+
+// OnOrderBookUpdate re-evaluates the paths through snap's symbol. The books
+// themselves are read from the market data view, so every leg is priced from
+// the latest state.
 func (m *TriArbModule) OnOrderBookUpdate(snap domain.OrderBookSnapshot) {
 	if snap.Venue != m.venue {
 		return
 	}
 
-	m.mu.Lock()
-	m.books[snap.Symbol] = &snap
-	m.mu.Unlock()
-
 	m.evaluate(snap.Symbol, snap.LocalTimestamp)
 }
 
@@ -78,25 +129,60 @@ func (m *TriArbModule) evaluate(updatedSymbol string, mdTimestamp time.Time) {
 			continue
 		}
 
-		if !m.allBooksAvailable(path) {
+		books, ok := m.pathBooks(path)
+		if !ok {
 			continue
 		}
-
-		edgeBps := m.computeEdge(path)
-		threshold := domain.FixedFromBps(m.minEdgeBps)
-
-		if edgeBps.GT(threshold) {
-			signal := m.buildSignal(path, edgeBps, mdTimestamp)
-			if signal != nil {
-				m.bus.PublishSignal(*signal)
-				m.logger.Info("tri-arb signal detected",
-					"venue", m.venue,
-					"edge_bps", edgeBps.ToDecimal().String(),
-					"signal_id", signal.SignalID.String(),
-				)
-			}
+		// Conservative mode changes both the threshold and the size, and
+		// volatility the threshold.
+		var extraBps int64
+		for _, leg := range path.Legs {
+			extraBps = max(extraBps, m.dynamicEdge.extraBps(m.venue, leg.Symbol))
+		}
+		state := newStateHash().flag(m.conservative.on()).word(uint64(extraBps))
+		for _, book := range books {
+			state = state.book(book, decisionDepth)
 		}
+		if m.decisions.unchanged(path, state) {
+			continue
+		}
+		m.decisions.record(path, state, m.evaluatePath(path, books, extraBps, mdTimestamp))
+	}
+}
+
+// evaluatePath publishes a signal for path if books offer enough edge, with
+// extraBps added to the threshold, and reports whether it did. A signal held back for adverse flow also counts,
+// since the flow can turn while the books stay as they are.
+func (m *TriArbModule) evaluatePath(path TriangularPath, books []*domain.OrderBookSnapshot, extraBps int64, mdTimestamp time.Time) bool {
+	edgeBps, err := m.computeEdge(path, books)
+	if err != nil {
+		m.logger.Debug("tri-arb edge computation failed", "venue", m.venue, "error", err)
+		return false
+	}
+	threshold, err := domain.ScaledFromBps(m.conservative.minEdgeBps(m.minEdgeBps)+extraBps, pathRateScale(path))
+	if err != nil || !edgeBps.GT(threshold) {
+		return false
+	}
+	if reason := m.flowGate.adverse(m.venue, path); reason != "" {
+		m.logger.Debug("tri-arb signal held for adverse flow",
+			"venue", m.venue,
+			"edge_bps", edgeBps.ToDecimal().String(),
+			"reason", reason,
+		)
+		return true
+	}
+
+	signal := m.buildSignal(path, books, edgeBps, mdTimestamp)
+	if signal == nil {
+		return false
 	}
+	m.bus.PublishSignal(*signal)
+	m.logger.Info("tri-arb signal detected",
+		"venue", m.venue,
+		"edge_bps", edgeBps.ToDecimal().String(),
+		"signal_id", signal.SignalID.String(),
+	)
+	return true
 }
 
 func (m *TriArbModule) pathInvolves(path TriangularPath, symbol string) bool {
@@ -108,53 +194,133 @@ func (m *TriArbModule) pathInvolves(path TriangularPath, symbol string) bool {
 	return false
 }
 
-func (m *TriArbModule) allBooksAvailable(path TriangularPath) bool {
-	for _, leg := range path.Legs {
-		if _, ok := m.books[leg.Symbol]; !ok {
-			return false
+// pathBooks returns the book of each leg of path, read once so that the
+// edge and the signal are computed from the same state.
+func (m *TriArbModule) pathBooks(path TriangularPath) ([]*domain.OrderBookSnapshot, bool) {
+	books := make([]*domain.OrderBookSnapshot, len(path.Legs))
+	for i, leg := range path.Legs {
+		book, ok := m.md.GetBook(m.venue, leg.Symbol)
+		if !ok {
+			return nil, false
 		}
+		books[i] = book
 	}
-	return true
+	return books, true
 }
 
-func (m *TriArbModule) computeEdge(path TriangularPath) domain.FixedPrice {
-	impliedRate := domain.ToFixed(decimal.NewFromInt(1))
+// triArbRateScale is the decimal precision of the implied conversion rate.
+// Twelve places keeps sub-satoshi cross rates exact while leaving headroom for
+// a rate above 9,000,000 before the int64 range is exhausted.
+const triArbRateScale int32 = 12
+
+// fiatRateScale is the edge precision on paths with an FX leg. Between legs
+// they hold hundreds of thousands of rials per USDT, which would overflow
+// triArbRateScale.
+const fiatRateScale int32 = 8
+
+// fiatDivPrecision is the number of decimal places kept when dividing by an
+// ask on an FX path. The rate is only rounded to fiatRateScale once the path
+// is complete: 1 USDT over a BTC/USDT ask is about 1e-5, of which
+// fiatRateScale alone would keep three digits.
+const fiatDivPrecision int32 = 24
+
+func pathRateScale(path TriangularPath) int32 {
+	if hasFXLeg(path) {
+		return fiatRateScale
+	}
+	return triArbRateScale
+}
 
+func hasFXLeg(path TriangularPath) bool {
 	for _, leg := range path.Legs {
-		book := m.books[leg.Symbol]
+		if leg.FX {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *TriArbModule) computeEdge(path TriangularPath, books []*domain.OrderBookSnapshot) (domain.ScaledPrice, error) {
+	if hasFXLeg(path) {
+		return fiatEdge(path, books)
+	}
+	one := domain.ScaledPrice{Units: 1, Scale: 0}
+	impliedRate, err := one.Rescale(pathRateScale(path))
+	if err != nil {
+		return domain.ScaledPrice{}, err
+	}
+
+	for i, leg := range path.Legs {
+		book := books[i]
 		if leg.Side == domain.SideBuy {
 			ask, ok := book.BestAsk()
 			if !ok {
-				return 0
+				return domain.ScaledPrice{}, nil
+			}
+			price, err := domain.NewScaledPrice(ask.Price, domain.PriceScale(leg.Symbol))
+			if err != nil {
+				return domain.ScaledPrice{}, fmt.Errorf("%s ask: %w", leg.Symbol, err)
 			}
-			price := domain.ToFixed(ask.Price)
-			if price == 0 {
-				return 0
+			if price.IsZero() {
+				return domain.ScaledPrice{}, nil
+			}
+			if impliedRate, err = impliedRate.Div(price); err != nil {
+				return domain.ScaledPrice{}, fmt.Errorf("%s ask: %w", leg.Symbol, err)
 			}
-			impliedRate = impliedRate.Div(price)
 		} else {
 			bid, ok := book.BestBid()
 			if !ok {
-				return 0
+				return domain.ScaledPrice{}, nil
+			}
+			price, err := domain.NewScaledPrice(bid.Price, domain.PriceScale(leg.Symbol))
+			if err != nil {
+				return domain.ScaledPrice{}, fmt.Errorf("%s bid: %w", leg.Symbol, err)
+			}
+			if impliedRate, err = impliedRate.Mul(price); err != nil {
+				return domain.ScaledPrice{}, fmt.Errorf("%s bid: %w", leg.Symbol, err)
 			}
-			price := domain.ToFixed(bid.Price)
-			impliedRate = impliedRate.Mul(price)
 		}
 	}
 
-	one := domain.ToFixed(decimal.NewFromInt(1))
 	if impliedRate.GT(one) {
 		return impliedRate.Sub(one)
 	}
-	return 0
+	return domain.ScaledPrice{}, nil
 }
 
-func (m *TriArbModule) buildSignal(path TriangularPath, edgeBps domain.FixedPrice, mdTimestamp time.Time) *domain.TradeSignal {
+// fiatEdge is computeEdge for a path with an FX leg. The implied rate is
+// carried as a decimal and rounded to fiatRateScale only at the end.
+func fiatEdge(path TriangularPath, books []*domain.OrderBookSnapshot) (domain.ScaledPrice, error) {
+	rate := decimal.NewFromInt(1)
+	for i, leg := range path.Legs {
+		if leg.Side == domain.SideBuy {
+			ask, ok := books[i].BestAsk()
+			if !ok || !ask.Price.IsPositive() {
+				return domain.ScaledPrice{}, nil
+			}
+			rate = rate.DivRound(ask.Price, fiatDivPrecision)
+		} else {
+			bid, ok := books[i].BestBid()
+			if !ok {
+				return domain.ScaledPrice{}, nil
+			}
+			rate = rate.Mul(bid.Price)
+		}
+	}
+
+	edge := rate.Sub(decimal.NewFromInt(1))
+	if !edge.IsPositive() {
+		return domain.ScaledPrice{}, nil
+	}
+	return domain.NewScaledPrice(edge.Round(fiatRateScale), fiatRateScale)
+}
+
+func (m *TriArbModule) buildSignal(path TriangularPath, books []*domain.OrderBookSnapshot, edgeBps domain.ScaledPrice, mdTimestamp time.Time) *domain.TradeSignal {
 	legs := make([]domain.LegSpec, 3)
 	minSize := decimal.NewFromInt(999999999)
 
 	for i, leg := range path.Legs {
-		book := m.books[leg.Symbol]
+		book := books[i]
 		var price, size decimal.Decimal
 
 		if leg.Side == domain.SideBuy {
@@ -188,9 +354,16 @@ func (m *TriArbModule) buildSignal(path TriangularPath, edgeBps domain.FixedPric
 		}
 	}
 
-	for i := range legs {
-		if legs[i].Price.IsPositive() {
-			legs[i].Size = minSize.Div(legs[i].Price)
+	if hasFXLeg(path) {
+		if !sizeAlongPath(legs, m.conservative.scaleSize) {
+			return nil
+		}
+	} else {
+		minSize = m.conservative.scaleSize(minSize)
+		for i := range legs {
+			if legs[i].Price.IsPositive() {
+				legs[i].Size = minSize.Div(legs[i].Price)
+			}
 		}
 	}
 
@@ -199,6 +372,17 @@ func (m *TriArbModule) buildSignal(path TriangularPath, edgeBps domain.FixedPric
 		m.logger.Warn("cost estimate failed for tri-arb signal", "error", err)
 		return nil
 	}
+	for i, leg := range path.Legs {
+		if !leg.FX {
+			continue
+		}
+		fxCost, err := m.costModel.EstimateCost(m.venue, legs[i].Symbol, legs[i].Side, legs[i].Size, domain.OrderTypeLimit)
+		if err != nil {
+			m.logger.Warn("cost estimate failed for tri-arb FX leg", "symbol", legs[i].Symbol, "error", err)
+			return nil
+		}
+		costEst = addCosts(costEst, fxCost)
+	}
 
 	edgeDecimal := edgeBps.ToDecimal().Mul(decimal.NewFromInt(10000))
 	netEdge := edgeDecimal.Sub(costEst.TotalBps)
@@ -207,6 +391,8 @@ func (m *TriArbModule) buildSignal(path TriangularPath, edgeBps domain.FixedPric
 		return nil
 	}
 
+	atomicity := estimateAtomicity(m.costModel, m.venue, legs, books)
+
 	signalID, err := uuid.NewV7()
 	if err != nil {
 		signalID = uuid.New()
@@ -219,12 +405,53 @@ func (m *TriArbModule) buildSignal(path TriangularPath, edgeBps domain.FixedPric
 		Legs:                legs,
 		ExpectedEdgeBps:     netEdge,
 		CostEstimate:        costEst,
-		Confidence:          costEst.Confidence,
+		Confidence:          costEst.Confidence.Mul(atomicity),
+		Atomicity:           atomicity,
 		CreatedAt:           time.Now(),
 		MarketDataTimestamp: mdTimestamp,
 	}
 }
 
+// sizeAlongPath sizes legs, priced at the top of their books, by the amount
+// that flows through them: from one unit of the start currency each buy
+// spends what the previous leg left and each sell sells it. The cycle is as
+// large as the thinnest top level allows, scaled by scale. It reports false
+// if a leg has no price.
+func sizeAlongPath(legs []domain.LegSpec, scale func(decimal.Decimal) decimal.Decimal) bool {
+	perStart := make([]decimal.Decimal, len(legs))
+	amount := decimal.NewFromInt(1)
+	var start decimal.Decimal
+	for i, leg := range legs {
+		if !leg.Price.IsPositive() {
+			return false
+		}
+		if leg.Side == domain.SideBuy {
+			amount = amount.Div(leg.Price)
+			perStart[i] = amount
+		} else {
+			perStart[i] = amount
+			amount = amount.Mul(leg.Price)
+		}
+		if capacity := leg.Size.Div(perStart[i]); i == 0 || capacity.LessThan(start) {
+			start = capacity
+		}
+	}
+	start = scale(start)
+	for i := range legs {
+		legs[i].Size = start.Mul(perStart[i])
+	}
+	return true
+}
+
+// addCosts adds the cost of another leg to est. The confidences multiply.
+func addCosts(est, leg domain.CostEstimate) domain.CostEstimate {
+	est.FeeBps = est.FeeBps.Add(leg.FeeBps)
+	est.SlippageBps = est.SlippageBps.Add(leg.SlippageBps)
+	est.TotalBps = est.TotalBps.Add(leg.TotalBps)
+	est.Confidence = est.Confidence.Mul(leg.Confidence)
+	return est
+}
+
 func DefaultTriangularPaths(venue string) []TriangularPath {
 	return []TriangularPath{
 		{
@@ -277,3 +504,36 @@ func DefaultTriangularPaths(venue string) []TriangularPath {
 		},
 	}
 }
+
+// FiatTriangularPaths returns the paths through a venue's books quoted in
+// fiat, such as IRT on Nobitex, where most assets have no deep USDT book.
+// Each cycle starts and ends in USDT and converts between USDT and fiat on
+// the USDT/<fiat> book as its FX leg.
+func FiatTriangularPaths(venue, fiat string) []TriangularPath {
+	fx := "USDT/" + fiat
+	var paths []TriangularPath
+	for _, asset := range []string{"BTC", "ETH"} {
+		usdt, local := asset+"/USDT", asset+"/"+fiat
+		paths = append(paths,
+			// Buy the asset with USDT, sell it for fiat, buy USDT back.
+			TriangularPath{
+				Venue: venue,
+				Legs: [3]TriangularLeg{
+					{Symbol: usdt, Side: domain.SideBuy},
+					{Symbol: local, Side: domain.SideSell},
+					{Symbol: fx, Side: domain.SideBuy, FX: true},
+				},
+			},
+			// Sell USDT for fiat, buy the asset with it, sell it for USDT.
+			TriangularPath{
+				Venue: venue,
+				Legs: [3]TriangularLeg{
+					{Symbol: fx, Side: domain.SideSell, FX: true},
+					{Symbol: local, Side: domain.SideBuy},
+					{Symbol: usdt, Side: domain.SideSell},
+				},
+			},
+		)
+	}
+	return paths
+}
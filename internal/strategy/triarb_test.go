@@ -0,0 +1,279 @@
+package strategy
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/crypto-trading/trading/internal/domain"
+	"github.com/crypto-trading/trading/internal/eventbus"
+	"github.com/crypto-trading/trading/internal/marketdata"
+)
+
+type testView map[string]*domain.OrderBookSnapshot
+
+func (v testView) GetBook(venue, symbol string) (*domain.OrderBookSnapshot, bool) {
+	b, ok := v[symbol]
+	return b, ok
+}
+func (testView) GetFunding(venue, symbol string) (*domain.FundingRate, bool) { return nil, false }
+func (testView) GetRecentTrades(venue, symbol string, n int) []*domain.Trade { return nil }
+
+// flatCost charges bps (10 when zero) per estimate and records the symbols
+// costed.
+type flatCost struct {
+	bps     int64
+	symbols []string
+}
+
+func (c *flatCost) EstimateCost(venue, symbol string, side domain.Side, size decimal.Decimal, orderType domain.OrderType) (domain.CostEstimate, error) {
+	c.symbols = append(c.symbols, symbol)
+	bps := decimal.NewFromInt(10)
+	if c.bps != 0 {
+		bps = decimal.NewFromInt(c.bps)
+	}
+	return domain.CostEstimate{FeeBps: bps, TotalBps: bps, Confidence: decimal.NewFromInt(1)}, nil
+}
+
+func book(symbol string, bid, bidSize, ask, askSize float64) *domain.OrderBookSnapshot {
+	return &domain.OrderBookSnapshot{
+		Venue:  "nobitex",
+		Symbol: symbol,
+		Bids:   []domain.PriceLevel{{Price: decimal.NewFromFloat(bid), Size: decimal.NewFromFloat(bidSize)}},
+		Asks:   []domain.PriceLevel{{Price: decimal.NewFromFloat(ask), Size: decimal.NewFromFloat(askSize)}},
+	}
+}
+
+func TestTriArbFiatPathCostsFXLeg(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	// 1 USDT buys 1e-5 BTC, which sells for 1,000,000 IRT, which buys back
+	// 1.0101 USDT: about 101 bps before costs.
+	view := testView{
+		"BTC/USDT": book("BTC/USDT", 99990, 1, 100000, 1),
+		"BTC/IRT":  book("BTC/IRT", 100000000000, 0.5, 100100000000, 0.5),
+		"USDT/IRT": book("USDT/IRT", 985000, 100000, 990000, 100000),
+	}
+	cost := &flatCost{}
+	paths := FiatTriangularPaths("nobitex", "IRT")
+	mod := NewTriArbModule("nobitex", paths, view, cost, bus, 18, logger)
+
+	mod.OnOrderBookUpdate(domain.OrderBookSnapshot{Venue: "nobitex", Symbol: "USDT/IRT", LocalTimestamp: time.Now()})
+
+	var signal domain.TradeSignal
+	select {
+	case signal = <-signals:
+	case <-time.After(time.Second):
+		t.Fatal("expected a signal on the IRT path")
+	}
+
+	want := []struct {
+		symbol string
+		side   domain.Side
+		size   decimal.Decimal
+	}{
+		// The BTC/IRT bid's 0.5 BTC is the thinnest level: a 50,000 USDT cycle.
+		{"BTC/USDT", domain.SideBuy, decimal.NewFromFloat(0.5)},
+		{"BTC/IRT", domain.SideSell, decimal.NewFromFloat(0.5)},
+		{"USDT/IRT", domain.SideBuy, decimal.RequireFromString("50505.0505050505")},
+	}
+	for i, w := range want {
+		leg := signal.Legs[i]
+		if leg.Symbol != w.symbol || leg.Side != w.side {
+			t.Fatalf("leg %d: expected %s %s, got %s %s", i, w.side, w.symbol, leg.Side, leg.Symbol)
+		}
+		if leg.Size.Sub(w.size).Abs().GreaterThan(decimal.NewFromFloat(0.0001)) {
+			t.Errorf("leg %d: expected size %s, got %s", i, w.size, leg.Size)
+		}
+	}
+
+	// The first leg and the FX leg are each costed.
+	if len(cost.symbols) != 2 || cost.symbols[1] != "USDT/IRT" {
+		t.Errorf("expected the first and FX legs costed, got %v", cost.symbols)
+	}
+	if !signal.CostEstimate.TotalBps.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("expected 20 bps of costs, got %s", signal.CostEstimate.TotalBps)
+	}
+	if signal.ExpectedEdgeBps.Sub(decimal.NewFromFloat(81.01)).Abs().GreaterThan(decimal.NewFromFloat(0.01)) {
+		t.Errorf("expected about 81.01 bps net edge, got %s", signal.ExpectedEdgeBps)
+	}
+
+	select {
+	case s := <-signals:
+		t.Errorf("expected no signal on the reverse path, got %+v", s.Legs)
+	default:
+	}
+
+	// A BTC/USDT ask off a round number: 1/100037.5 has more digits than
+	// fiatRateScale keeps, and truncating it there would cost about 6 bps.
+	view["BTC/USDT"] = book("BTC/USDT", 100030, 1, 100037.5, 1)
+	mod = NewTriArbModule("nobitex", paths, view, &flatCost{}, bus, 18, logger)
+	mod.OnOrderBookUpdate(domain.OrderBookSnapshot{Venue: "nobitex", Symbol: "BTC/USDT", LocalTimestamp: time.Now()})
+
+	select {
+	case signal = <-signals:
+	case <-time.After(time.Second):
+		t.Fatal("expected a signal at a non-round BTC/USDT ask")
+	}
+	if signal.ExpectedEdgeBps.Sub(decimal.NewFromFloat(77.22)).Abs().GreaterThan(decimal.NewFromFloat(0.01)) {
+		t.Errorf("expected about 77.22 bps net edge, got %s", signal.ExpectedEdgeBps)
+	}
+}
+
+func TestTriArbSkipsUnchangedBooks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	view := testView{
+		"BTC/USDT": book("BTC/USDT", 99990, 1, 100000, 1),
+		"BTC/IRT":  book("BTC/IRT", 100000000000, 0.5, 100100000000, 0.5),
+		"USDT/IRT": book("USDT/IRT", 985000, 100000, 990000, 100000),
+	}
+	// Costs no path can clear, so every evaluation finds nothing to trade.
+	cost := &flatCost{bps: 1000}
+	mod := NewTriArbModule("nobitex", FiatTriangularPaths("nobitex", "IRT"), view, cost, bus, 18, logger)
+	update := domain.OrderBookSnapshot{Venue: "nobitex", Symbol: "USDT/IRT", LocalTimestamp: time.Now()}
+
+	count := func() int {
+		n := 0
+		for {
+			select {
+			case <-signals:
+				n++
+			case <-time.After(50 * time.Millisecond):
+				return n
+			}
+		}
+	}
+
+	mod.OnOrderBookUpdate(update)
+	costed := len(cost.symbols)
+	if costed == 0 || count() != 0 {
+		t.Fatalf("expected the paths evaluated without a signal, costed %d", costed)
+	}
+
+	// A change below the touch leaves the decision as it was.
+	view["BTC/USDT"].Asks = append(view["BTC/USDT"].Asks, domain.PriceLevel{Price: decimal.NewFromInt(100010), Size: decimal.NewFromInt(3)})
+	mod.OnOrderBookUpdate(update)
+	if len(cost.symbols) != costed {
+		t.Fatalf("expected unchanged touches to be skipped, costed %d more legs", len(cost.symbols)-costed)
+	}
+
+	// A changed touch is evaluated again and, now that the costs allow it,
+	// signalled.
+	cost.bps = 10
+	view["BTC/USDT"] = book("BTC/USDT", 99990, 1, 100000, 0.8)
+	mod.OnOrderBookUpdate(update)
+	if n := count(); n != 1 {
+		t.Fatalf("expected a changed touch to be evaluated again, got %d signals", n)
+	}
+
+	// An opportunity still on unchanged books is signalled again, since the
+	// last signal may have been turned away.
+	mod.OnOrderBookUpdate(update)
+	if n := count(); n != 1 {
+		t.Fatalf("expected the opportunity signalled again, got %d signals", n)
+	}
+}
+
+func TestTriArbAdverseFlowGate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	view := testView{
+		"BTC/USDT": book("BTC/USDT", 99990, 1, 100000, 0.8),
+		"BTC/IRT":  book("BTC/IRT", 100000000000, 0.5, 100100000000, 0.5),
+		"USDT/IRT": book("USDT/IRT", 985000, 100000, 990000, 100000),
+	}
+	mod := NewTriArbModule("nobitex", FiatTriangularPaths("nobitex", "IRT"), view, &flatCost{}, bus, 18, logger)
+	// Every path buys on one leg and sells on another, so a book leaning
+	// either way is against one of them.
+	lean := -0.9
+	mod.SetAdverseFlowGate(&AdverseFlowGate{
+		Stats: func(venue, symbol string) (marketdata.MicroStats, bool) {
+			return marketdata.MicroStats{Imbalance: lean}, true
+		},
+		MaxImbalance: 0.6,
+	})
+	update := domain.OrderBookSnapshot{Venue: "nobitex", Symbol: "USDT/IRT", LocalTimestamp: time.Now()}
+
+	count := func() int {
+		n := 0
+		for {
+			select {
+			case <-signals:
+				n++
+			case <-time.After(50 * time.Millisecond):
+				return n
+			}
+		}
+	}
+
+	mod.OnOrderBookUpdate(update)
+	if n := count(); n != 0 {
+		t.Fatalf("expected the signal held for adverse flow, got %d signals", n)
+	}
+
+	// Once the flow calms the same books are signalled.
+	lean = 0.1
+	mod.OnOrderBookUpdate(update)
+	if n := count(); n != 1 {
+		t.Fatalf("expected a signal once the flow calmed, got %d signals", n)
+	}
+}
+
+func TestTriArbDynamicEdge(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	bus := eventbus.New(8, logger)
+	signals := bus.SubscribeSignal()
+
+	view := testView{
+		"BTC/USDT": book("BTC/USDT", 99990, 1, 100000, 0.8),
+		"BTC/IRT":  book("BTC/IRT", 100000000000, 0.5, 100100000000, 0.5),
+		"USDT/IRT": book("USDT/IRT", 985000, 100000, 990000, 100000),
+	}
+	mod := NewTriArbModule("nobitex", FiatTriangularPaths("nobitex", "IRT"), view, &flatCost{}, bus, 18, logger)
+	vol := 2.0
+	mod.SetDynamicEdge(&DynamicEdge{
+		Volatility: func(venue, symbol string) (float64, bool) {
+			if symbol == "BTC/IRT" {
+				return vol, true
+			}
+			return 0.1, true
+		},
+		BpsPerVolPct: 1,
+	})
+	update := domain.OrderBookSnapshot{Venue: "nobitex", Symbol: "USDT/IRT", LocalTimestamp: time.Now()}
+
+	count := func() int {
+		n := 0
+		for {
+			select {
+			case <-signals:
+				n++
+			case <-time.After(50 * time.Millisecond):
+				return n
+			}
+		}
+	}
+
+	// 200% volatility on one leg asks 200 bps more edge than the path has.
+	mod.OnOrderBookUpdate(update)
+	if n := count(); n != 0 {
+		t.Fatalf("expected no signal in a volatile market, got %d signals", n)
+	}
+
+	// Once the market calms the same books are signalled.
+	vol = 0.1
+	mod.OnOrderBookUpdate(update)
+	if n := count(); n != 1 {
+		t.Fatalf("expected a signal once volatility fell, got %d signals", n)
+	}
+}
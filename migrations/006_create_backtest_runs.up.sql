@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS backtest_runs (
+    id            UUID PRIMARY KEY,
+    config_hash   VARCHAR(16) NOT NULL,
+    data_from     TIMESTAMPTZ NOT NULL,
+    data_to       TIMESTAMPTZ NOT NULL,
+    events        INTEGER NOT NULL,
+    cycles        INTEGER NOT NULL,
+    hit_rate      NUMERIC(6, 4) NOT NULL,
+    total_pnl     NUMERIC(20, 8) NOT NULL,
+    max_drawdown  NUMERIC(20, 8) NOT NULL,
+    report        JSONB NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_backtest_runs_created_at ON backtest_runs(created_at);
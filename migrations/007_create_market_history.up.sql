@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS market_klines (
+    venue             VARCHAR(32) NOT NULL,
+    symbol            VARCHAR(32) NOT NULL,
+    interval_seconds  INTEGER NOT NULL,
+    open_time         TIMESTAMPTZ NOT NULL,
+    open              NUMERIC(20, 8) NOT NULL,
+    high              NUMERIC(20, 8) NOT NULL,
+    low               NUMERIC(20, 8) NOT NULL,
+    close             NUMERIC(20, 8) NOT NULL,
+    volume            NUMERIC(28, 8) NOT NULL,
+    PRIMARY KEY (venue, symbol, interval_seconds, open_time)
+);
+
+CREATE TABLE IF NOT EXISTS market_trades (
+    venue        VARCHAR(32) NOT NULL,
+    symbol       VARCHAR(32) NOT NULL,
+    trade_id     VARCHAR(64) NOT NULL,
+    side         VARCHAR(4) NOT NULL,
+    price        NUMERIC(20, 8) NOT NULL,
+    size         NUMERIC(20, 8) NOT NULL,
+    executed_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (venue, symbol, trade_id)
+);
+
+CREATE INDEX idx_market_trades_executed_at ON market_trades(venue, symbol, executed_at);
+
+CREATE TABLE IF NOT EXISTS funding_rates (
+    venue         VARCHAR(32) NOT NULL,
+    symbol        VARCHAR(32) NOT NULL,
+    funding_time  TIMESTAMPTZ NOT NULL,
+    rate          NUMERIC(20, 12) NOT NULL,
+    PRIMARY KEY (venue, symbol, funding_time)
+);